@@ -2,6 +2,13 @@
 set -euo pipefail
 
 # Scans file content being written for potential secrets
+
+# The native scanner recognizes more token formats and tells random values from
+# placeholders; the patterns below are the fallback when it is not installed.
+if command -v claude-workspace >/dev/null 2>&1; then
+  exec claude-workspace scan --stdin-json
+fi
+
 INPUT=$(cat)
 CONTENT=$(echo "$INPUT" | jq -r '.tool_input.content // .tool_input.new_string // empty')
 FILE_PATH=$(echo "$INPUT" | jq -r '.tool_input.file_path // empty')
@@ -0,0 +1,13 @@
+#!/bin/bash
+set -euo pipefail
+
+# Reports when a dependency change leaves CLAUDE.md's Tech Stack, Build, or Test lines out of date
+
+# The check only reports drift; "claude-workspace enrich --stack" shows the
+# update and writes it once confirmed. Without the binary there is nothing to
+# compare against, so the hook does nothing.
+if command -v claude-workspace >/dev/null 2>&1; then
+  exec claude-workspace enrich --stack --stdin-json
+fi
+
+exit 0
@@ -2,8 +2,9 @@ package main
 
 import "embed"
 
-// PlatformFS embeds the _template directory (containing .claude and .mcp.json)
-// into the binary. The "all:" prefix includes dotfiles (files starting with ".").
+// PlatformFS embeds the _template directory (project and global assets plus
+// template profiles) into the binary. The "all:" prefix includes dotfiles
+// (files starting with ".").
 //
 //go:embed all:_template
 var PlatformFS embed.FS
@@ -1,4 +1,4 @@
-// Package agents discovers and lists available Claude Code agents.
+// Package agents discovers, lists, and validates Claude Code agent definitions.
 package agents
 
 import (
@@ -6,8 +6,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
@@ -24,54 +26,66 @@ type Agent struct {
 func Run(args []string) error {
 	subcmd := "list"
 	if len(args) > 0 {
-		subcmd = args[0]
+		subcmd, args = args[0], args[1:]
 	}
 	switch subcmd {
 	case "list":
+		if _, err := cli.Operands("agents list", "agents list", args, 0, 0); err != nil {
+			return err
+		}
 		return list()
+	case "show":
+		args, err := cli.Operands("agents show", "agents show <name>", args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return show(args[0])
+	case "validate":
+		args, err := cli.Operands("agents validate", "agents validate [name...]", args, 0, -1)
+		if err != nil {
+			return err
+		}
+		return validate(args)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown agents subcommand: %s\n", subcmd)
-		fmt.Fprintln(os.Stderr, "Usage: claude-workspace agents [list]")
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace agents [list|show|validate]")
 		return fmt.Errorf("unknown subcommand: %s", subcmd)
 	}
 }
 
-// list discovers agents from project, user-global, and platform sources and prints them.
+// discover returns the project agents under the current directory and the
+// user agents under ~/.claude/agents.
+func discover() (project, user []Agent) {
+	if cwd, err := os.Getwd(); err == nil {
+		if dir := filepath.Join(cwd, ".claude", "agents"); platform.FileExists(dir) {
+			project = DiscoverAgents(dir)
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if dir := filepath.Join(home, ".claude", "agents"); platform.FileExists(dir) {
+			user = DiscoverAgents(dir)
+		}
+	}
+	return project, user
+}
+
+// list discovers agents from project and user-global sources and prints them,
+// followed by the effective set Claude Code sees.
 func list() error {
 	platform.PrintBanner(os.Stdout, "Agents")
 	fmt.Println()
 
-	anyFound := false
-
-	// 1. Project agents
-	cwd, err := os.Getwd()
-	if err == nil {
-		agentsDir := filepath.Join(cwd, ".claude", "agents")
-		if platform.FileExists(agentsDir) {
-			projectAgents := DiscoverAgents(agentsDir)
-			if len(projectAgents) > 0 {
-				anyFound = true
-				platform.PrintSection(os.Stdout, "Project Agents (.claude/agents/)")
-				printAgentTable(projectAgents)
-			}
-		}
+	projectAgents, globalAgents := discover()
+	if len(projectAgents) > 0 {
+		platform.PrintSection(os.Stdout, "Project Agents (.claude/agents/)")
+		printAgentTable(projectAgents)
 	}
-
-	// 2. User-global agents
-	home, err := os.UserHomeDir()
-	if err == nil {
-		globalDir := filepath.Join(home, ".claude", "agents")
-		if platform.FileExists(globalDir) {
-			globalAgents := DiscoverAgents(globalDir)
-			if len(globalAgents) > 0 {
-				anyFound = true
-				platform.PrintSection(os.Stdout, "User Agents (~/.claude/agents/)")
-				printAgentTable(globalAgents)
-			}
-		}
+	if len(globalAgents) > 0 {
+		platform.PrintSection(os.Stdout, "User Agents (~/.claude/agents/)")
+		printAgentTable(globalAgents)
 	}
 
-	if !anyFound {
+	if len(projectAgents) == 0 && len(globalAgents) == 0 {
 		fmt.Println("  No agents found.")
 		fmt.Println()
 		fmt.Println("  Create a project agent:  .claude/agents/my-agent.md")
@@ -80,15 +94,177 @@ func list() error {
 		return nil
 	}
 
+	effective := Effective(projectAgents, globalAgents)
+	var shadowed []string
+	count := 0
+	for _, a := range effective {
+		if a.Shadowed {
+			shadowed = append(shadowed, a.Name)
+		} else {
+			count++
+		}
+	}
+	platform.PrintSection(os.Stdout, "Effective Agents")
+	fmt.Printf("  %d agent(s) available to Claude Code\n", count)
+	if len(shadowed) > 0 {
+		fmt.Printf("  Project agents override user agents: %s\n", strings.Join(shadowed, ", "))
+	}
+	fmt.Println()
+
 	// Tips
 	platform.PrintSection(os.Stdout, "Tips")
 	fmt.Println("  Agents are invoked automatically by Claude Code when matching tasks arise.")
 	fmt.Println("  Create new:  .claude/agents/my-agent.md")
+	fmt.Println("  Inspect:     claude-workspace agents show <name>")
+	fmt.Println("  Check:       claude-workspace agents validate")
+	fmt.Println()
+
+	return nil
+}
+
+// show prints the effective definition of the agent named name.
+func show(name string) error {
+	projectAgents, globalAgents := discover()
+	var found *ScopedAgent
+	var shadowed []ScopedAgent
+	for _, a := range Effective(projectAgents, globalAgents) {
+		if a.Name != name {
+			continue
+		}
+		a := a
+		if found == nil && !a.Shadowed {
+			found = &a
+		} else {
+			shadowed = append(shadowed, a)
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("no agent named %q (run 'claude-workspace agents list')", name)
+	}
+
+	platform.PrintBanner(os.Stdout, "Agent: "+found.Name)
 	fmt.Println()
+	fmt.Printf("  Scope:        %s\n", found.Scope)
+	fmt.Printf("  Path:         %s\n", found.Path)
+	fmt.Printf("  Model:        %s\n", valueOr(found.Model, "inherit (default)"))
+	fmt.Printf("  Tools:        %s\n", valueOr(found.Tools, "all tools (inherited)"))
+	fmt.Printf("  Description:  %s\n", found.Description)
+	for _, s := range shadowed {
+		fmt.Printf("  Overrides:    %s agent at %s\n", s.Scope, s.Path)
+	}
 
+	issues := Validate(found.Path)
+	if len(issues) > 0 {
+		platform.PrintSection(os.Stdout, "Problems")
+		printIssues(issues)
+	}
+
+	if data, err := os.ReadFile(found.Path); err == nil {
+		if body := promptBody(data); body != "" {
+			platform.PrintSection(os.Stdout, "Prompt")
+			for _, line := range strings.Split(body, "\n") {
+				fmt.Println(strings.TrimRight("  "+line, " "))
+			}
+		}
+	}
+	fmt.Println()
 	return nil
 }
 
+// validate checks every project and user agent definition, or only those
+// named in args, and returns an error when any has an error-level problem.
+func validate(args []string) error {
+	platform.PrintBanner(os.Stdout, "Agent Validation")
+	fmt.Println()
+
+	projectAgents, globalAgents := discover()
+	effective := Effective(projectAgents, globalAgents)
+	if len(args) > 0 {
+		var selected []ScopedAgent
+		for _, a := range effective {
+			if slices.Contains(args, a.Name) {
+				selected = append(selected, a)
+			}
+		}
+		for _, name := range args {
+			if !containsAgent(selected, name) {
+				return fmt.Errorf("no agent named %q", name)
+			}
+		}
+		effective = selected
+	}
+	if len(effective) == 0 {
+		fmt.Println("  No agents found.")
+		fmt.Println()
+		return nil
+	}
+
+	var issues []Issue
+	for _, a := range effective {
+		issues = append(issues, Validate(a.Path)...)
+	}
+	issues = append(issues, Duplicates(effective)...)
+
+	errCount := 0
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			errCount++
+		}
+	}
+	if len(issues) == 0 {
+		platform.PrintOK(os.Stdout, fmt.Sprintf("%d agent definition(s) are valid", len(effective)))
+		fmt.Println()
+		return nil
+	}
+	printIssues(issues)
+	fmt.Println()
+	if errCount > 0 {
+		return fmt.Errorf("%d agent definition error(s)", errCount)
+	}
+	return nil
+}
+
+func containsAgent(agents []ScopedAgent, name string) bool {
+	for _, a := range agents {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// printIssues prints validation problems, errors as failures and the rest as
+// warnings.
+func printIssues(issues []Issue) {
+	for _, i := range issues {
+		msg := filepath.Base(i.Path) + ": " + i.Message
+		if i.Severity == SeverityError {
+			platform.PrintFail(os.Stdout, msg)
+		} else {
+			platform.PrintWarn(os.Stdout, msg)
+		}
+	}
+}
+
+// promptBody returns the agent's system prompt: the file after its
+// frontmatter.
+func promptBody(data []byte) string {
+	content := strings.TrimLeft(string(data), "\ufeff \t\r\n")
+	if rest, ok := strings.CutPrefix(content, "---"); ok {
+		if _, body, found := strings.Cut(rest, "\n---"); found {
+			content = body
+		}
+	}
+	return strings.TrimSpace(content)
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
 // DiscoverAgents walks a directory for .md files and parses their frontmatter.
 func DiscoverAgents(root string) []Agent {
 	var agents []Agent
@@ -0,0 +1,193 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Scopes an agent definition can come from. Claude Code gives project agents
+// precedence over user agents with the same name.
+const (
+	ScopeProject = "project"
+	ScopeUser    = "user"
+)
+
+// Issue severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in an agent definition.
+type Issue struct {
+	Path     string
+	Severity string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", filepath.Base(i.Path), i.Severity, i.Message)
+}
+
+// KnownTools lists the built-in Claude Code tools an agent's tools field may
+// name. MCP tools (mcp__server__tool) are accepted as well.
+var KnownTools = []string{
+	"Agent", "Bash", "BashOutput", "Edit", "ExitPlanMode", "Glob", "Grep",
+	"KillShell", "LS", "MultiEdit", "NotebookEdit", "NotebookRead", "Read",
+	"SlashCommand", "Skill", "Task", "TodoWrite", "WebFetch", "WebSearch", "Write",
+}
+
+// modelAliases are the model values Claude Code accepts besides full model
+// IDs such as claude-sonnet-4-5.
+var modelAliases = []string{"sonnet", "opus", "haiku", "inherit"}
+
+// agentNamePattern matches the names Claude Code accepts: lowercase letters,
+// digits, and hyphens.
+var agentNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// splitFrontmatter returns the frontmatter "key: value" pairs of an agent
+// file. ok is false when the file does not open with a closed --- block.
+func splitFrontmatter(data []byte) (fields map[string]string, ok bool) {
+	lines := strings.Split(strings.TrimLeft(string(data), "\ufeff \t\r\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, false
+	}
+	fields = map[string]string{}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "---" {
+			return fields, true
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "- ") {
+			continue
+		}
+		if key, value, found := strings.Cut(line, ":"); found {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return nil, false
+}
+
+// toolNames splits an agent's tools field into tool names, dropping any
+// permission pattern such as Bash(git:*).
+func toolNames(tools string) []string {
+	tools = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(tools), "["), "]")
+	var names []string
+	for _, t := range strings.Split(tools, ",") {
+		t = strings.Trim(strings.TrimSpace(t), `"'`)
+		if i := strings.Index(t, "("); i >= 0 {
+			t = t[:i]
+		}
+		if t != "" {
+			names = append(names, t)
+		}
+	}
+	return names
+}
+
+// Validate checks an agent definition file: a frontmatter block with a valid
+// name and a description, a known model, and known tool names.
+func Validate(path string) []Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Issue{{Path: path, Severity: SeverityError, Message: err.Error()}}
+	}
+	return validateBytes(path, data)
+}
+
+func validateBytes(path string, data []byte) []Issue {
+	var issues []Issue
+	add := func(severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	fields, ok := splitFrontmatter(data)
+	if !ok {
+		add(SeverityError, "missing frontmatter: the file must start with a --- block and close it with ---")
+		return issues
+	}
+
+	fileName := strings.TrimSuffix(filepath.Base(path), ".md")
+	switch name := fields["name"]; {
+	case name == "":
+		add(SeverityError, "missing required field: name")
+	case !agentNamePattern.MatchString(name):
+		add(SeverityError, "invalid name %q: use lowercase letters, digits, and hyphens", name)
+	case name != fileName:
+		add(SeverityWarning, "name %q does not match the file name %s.md", name, fileName)
+	}
+
+	if fields["description"] == "" {
+		add(SeverityError, "missing required field: description (Claude uses it to decide when to delegate)")
+	}
+
+	if model := fields["model"]; model != "" && !slices.Contains(modelAliases, model) && !strings.HasPrefix(model, "claude-") {
+		add(SeverityError, "unknown model %q (use %s, or a full model ID)", model, strings.Join(modelAliases, ", "))
+	}
+
+	for _, key := range []string{"tools", "disallowedTools"} {
+		for _, tool := range toolNames(fields[key]) {
+			if !slices.Contains(KnownTools, tool) && !strings.HasPrefix(tool, "mcp__") {
+				add(SeverityError, "unknown tool %q in %s", tool, key)
+			}
+		}
+	}
+	return issues
+}
+
+// ScopedAgent is an agent together with the scope it was found in.
+type ScopedAgent struct {
+	Agent
+	Scope string
+	// Shadowed is set on a user agent hidden by a project agent of the same
+	// name.
+	Shadowed bool
+}
+
+// Effective merges project and user agents the way Claude Code does: a project
+// agent replaces a user agent with the same name. The result is sorted by name
+// and includes shadowed user agents, marked as such.
+func Effective(project, user []Agent) []ScopedAgent {
+	inProject := map[string]bool{}
+	var all []ScopedAgent
+	for _, a := range project {
+		inProject[a.Name] = true
+		all = append(all, ScopedAgent{Agent: a, Scope: ScopeProject})
+	}
+	for _, a := range user {
+		all = append(all, ScopedAgent{Agent: a, Scope: ScopeUser, Shadowed: inProject[a.Name]})
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Name != all[j].Name {
+			return all[i].Name < all[j].Name
+		}
+		return all[i].Scope == ScopeProject && all[j].Scope != ScopeProject
+	})
+	return all
+}
+
+// Duplicates reports agent names defined more than once within one scope, and
+// user agents shadowed by project agents.
+func Duplicates(agents []ScopedAgent) []Issue {
+	var issues []Issue
+	seen := map[string]string{} // scope/name -> first path
+	for _, a := range agents {
+		key := a.Scope + "/" + a.Name
+		if first, ok := seen[key]; ok {
+			issues = append(issues, Issue{Path: a.Path, Severity: SeverityError,
+				Message: fmt.Sprintf("duplicate %s agent name %q (also defined in %s)", a.Scope, a.Name, first)})
+			continue
+		}
+		seen[key] = a.Path
+		if a.Shadowed {
+			issues = append(issues, Issue{Path: a.Path, Severity: SeverityWarning,
+				Message: fmt.Sprintf("user agent %q is overridden by the project agent of the same name", a.Name)})
+		}
+	}
+	return issues
+}
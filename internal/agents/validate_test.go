@@ -0,0 +1,133 @@
+package agents
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		input string
+		want  []string // substrings of the expected issues, in order
+	}{
+		{
+			name:  "valid",
+			file:  "code-reviewer.md",
+			input: "---\nname: code-reviewer\ndescription: Reviews code\nmodel: sonnet\ntools: Read, Grep, Bash(git:*), mcp__github__get_issue\n---\n\nPrompt\n",
+		},
+		{
+			name:  "full model id and inherited tools",
+			file:  "planner.md",
+			input: "---\nname: planner\ndescription: Plans work\nmodel: claude-opus-4-1\n---\n",
+		},
+		{
+			name:  "no frontmatter",
+			file:  "notes.md",
+			input: "# Notes\n",
+			want:  []string{"missing frontmatter"},
+		},
+		{
+			name:  "unclosed frontmatter",
+			file:  "broken.md",
+			input: "---\nname: broken\ndescription: x\n",
+			want:  []string{"missing frontmatter"},
+		},
+		{
+			name:  "missing required fields",
+			file:  "empty.md",
+			input: "---\nmodel: haiku\n---\n",
+			want:  []string{"missing required field: name", "missing required field: description"},
+		},
+		{
+			name:  "bad name, model, and tools",
+			file:  "Bad_Agent.md",
+			input: "---\nname: Bad_Agent\ndescription: x\nmodel: gpt-4\ntools: Read, Grepp\ndisallowedTools: [Shell]\n---\n",
+			want:  []string{`invalid name "Bad_Agent"`, `unknown model "gpt-4"`, `unknown tool "Grepp" in tools`, `unknown tool "Shell" in disallowedTools`},
+		},
+		{
+			name:  "name differs from file",
+			file:  "reviewer.md",
+			input: "---\nname: code-reviewer\ndescription: x\n---\n",
+			want:  []string{"does not match the file name reviewer.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := validateBytes(tt.file, []byte(tt.input))
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got %d issues %v, want %d", len(issues), issues, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(issues[i].Message, want) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, issues[i].Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_TemplateAgents(t *testing.T) {
+	dir := filepath.Join("..", "..", "_template", "project", ".claude", "agents")
+	for _, a := range DiscoverAgents(dir) {
+		if issues := Validate(a.Path); len(issues) > 0 {
+			t.Errorf("%s: %v", a.Path, issues)
+		}
+	}
+}
+
+func TestEffectiveAndDuplicates(t *testing.T) {
+	project := []Agent{
+		{Name: "reviewer", Path: "/p/reviewer.md"},
+		{Name: "planner", Path: "/p/planner.md"},
+		{Name: "planner", Path: "/p/planner-v2.md"},
+	}
+	user := []Agent{
+		{Name: "reviewer", Path: "/u/reviewer.md"},
+		{Name: "writer", Path: "/u/writer.md"},
+	}
+
+	effective := Effective(project, user)
+	var got []string
+	for _, a := range effective {
+		entry := a.Scope + ":" + a.Name
+		if a.Shadowed {
+			entry += "(shadowed)"
+		}
+		got = append(got, entry)
+	}
+	want := "project:planner project:planner project:reviewer user:reviewer(shadowed) user:writer"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Effective = %v, want %s", got, want)
+	}
+
+	issues := Duplicates(effective)
+	if len(issues) != 2 {
+		t.Fatalf("Duplicates = %v, want 2 issues", issues)
+	}
+	if issues[0].Severity != SeverityError || !strings.Contains(issues[0].Message, `duplicate project agent name "planner"`) {
+		t.Errorf("issue 0 = %+v", issues[0])
+	}
+	if issues[1].Severity != SeverityWarning || issues[1].Path != "/u/reviewer.md" {
+		t.Errorf("issue 1 = %+v", issues[1])
+	}
+}
+
+func TestPromptBody(t *testing.T) {
+	data := []byte("---\nname: a\ndescription: b\n---\n\nYou are a reviewer.\n\n## Steps\n")
+	if got := promptBody(data); got != "You are a reviewer.\n\n## Steps" {
+		t.Errorf("promptBody = %q", got)
+	}
+	if got := promptBody([]byte("plain prompt\n")); got != "plain prompt" {
+		t.Errorf("promptBody without frontmatter = %q", got)
+	}
+}
+
+func TestValidate_MissingFile(t *testing.T) {
+	issues := Validate(filepath.Join(t.TempDir(), "gone.md"))
+	if len(issues) != 1 || issues[0].Severity != SeverityError {
+		t.Errorf("Validate(missing) = %v", issues)
+	}
+}
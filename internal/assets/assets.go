@@ -0,0 +1,312 @@
+// Package assets implements the "assets" command, which lists, shows, and
+// diffs the project template attach lays down: the assets embedded in the
+// running binary, with any template overrides applied.
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const usage = `Usage: claude-workspace assets <subcommand>
+  list [--kind <kind>] [--json]           List the template's assets (kinds: agents, skills, hooks, settings, instructions, other)
+  show <asset>                            Print an asset's content
+  diff [asset...] [--project <dir>]       Diff the template against a project's copy (default: current directory)
+  diff [asset...] --version <version>     Diff the template against another release's`
+
+// Asset kinds.
+const (
+	KindAgents       = "agents"
+	KindSkills       = "skills"
+	KindHooks        = "hooks"
+	KindSettings     = "settings"
+	KindInstructions = "instructions"
+	KindOther        = "other"
+)
+
+// kindOrder is the order list prints kinds in.
+var kindOrder = []string{KindAgents, KindSkills, KindHooks, KindSettings, KindInstructions, KindOther}
+
+// Asset is one file of the project template.
+type Asset struct {
+	Path        string `json:"path"` // slash-separated, relative to the project, e.g. ".claude/agents/planner.md"
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Size        int64  `json:"size"`
+	Override    bool   `json:"override,omitempty"` // comes from the template overrides directory
+}
+
+// Run routes the assets subcommand. version is the running CLI version.
+func Run(version string, args []string) error {
+	sub := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		sub, args = args[0], args[1:]
+	}
+	switch sub {
+	case "list":
+		return runList(platform.Stdout(), args)
+	case "show":
+		return runShow(os.Stdout, args)
+	case "diff":
+		return runDiff(platform.Stdout(), version, args)
+	case "help":
+		fmt.Fprintln(platform.Stdout(), usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown assets subcommand: %s\n%s", sub, usage)
+	}
+}
+
+// List returns the files of the template fsys, ordered by kind and path.
+// Files the applied template overrides add or replace are marked.
+func List(fsys fs.FS) ([]Asset, error) {
+	overridden := map[string]bool{}
+	for _, o := range platform.ListOverrides() {
+		if o.Layer == "project" {
+			overridden[o.Path] = true
+		}
+	}
+	var list []Asset
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		a := Asset{Path: p, Size: info.Size(), Override: overridden[p]}
+		a.Kind, a.Name = classify(p)
+		if strings.HasSuffix(p, ".md") && (a.Kind == KindAgents || a.Kind == KindSkills) {
+			if data, err := fs.ReadFile(fsys, p); err == nil {
+				a.Description = frontmatterValue(string(data), "description")
+			}
+		}
+		list = append(list, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rank := map[string]int{}
+	for i, k := range kindOrder {
+		rank[k] = i
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return rank[list[i].Kind] < rank[list[j].Kind]
+		}
+		return list[i].Path < list[j].Path
+	})
+	return list, nil
+}
+
+// classify returns the kind of the template file at p and the name it is
+// known by: the agent, skill, or hook name, or the file name.
+func classify(p string) (kind, name string) {
+	rel, inClaude := strings.CutPrefix(p, ".claude/")
+	dir, rest, _ := strings.Cut(rel, "/")
+	switch {
+	case inClaude && dir == "agents" && rest != "":
+		return KindAgents, strings.TrimSuffix(rest, ".md")
+	case inClaude && dir == "skills" && rest != "":
+		skill, file, _ := strings.Cut(rest, "/")
+		if file == "SKILL.md" || file == "" {
+			return KindSkills, skill
+		}
+		return KindSkills, skill + "/" + file
+	case inClaude && dir == "hooks" && rest != "":
+		return KindHooks, strings.TrimSuffix(rest, path.Ext(rest))
+	case p == ".mcp.json" || rel == "settings.json" || rel == "settings.local.json.example":
+		return KindSettings, path.Base(p)
+	case rel == "CLAUDE.md" || (inClaude && dir == "rules"):
+		return KindInstructions, path.Base(p)
+	}
+	return KindOther, path.Base(p)
+}
+
+// frontmatterValue returns the value of key in the YAML frontmatter of
+// content, or "".
+func frontmatterValue(content, key string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return ""
+	}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, key+":"); ok {
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return ""
+}
+
+// Resolve finds the asset arg names: its path (".claude/agents/planner.md"),
+// its kind and name ("agents/planner"), or a name only one asset has
+// ("planner").
+func Resolve(list []Asset, arg string) (Asset, error) {
+	arg = strings.TrimPrefix(strings.TrimSuffix(arg, "/"), "./")
+	var matches []Asset
+	for _, a := range list {
+		switch arg {
+		case a.Path:
+			return a, nil
+		case a.Kind + "/" + a.Name, a.Name:
+			matches = append(matches, a)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Asset{}, fmt.Errorf("no asset named %q; run 'claude-workspace assets list' to see them", arg)
+	case 1:
+		return matches[0], nil
+	}
+	paths := make([]string, len(matches))
+	for i, a := range matches {
+		paths[i] = a.Path
+	}
+	return Asset{}, fmt.Errorf("%q matches %s; give the path", arg, strings.Join(paths, ", "))
+}
+
+// newFlagSet returns the flag set of "assets <sub>", whose help is the
+// command's usage.
+func newFlagSet(sub, usageLine string) *cli.FlagSet {
+	f := cli.New("assets "+sub, usageLine)
+	f.Help = func(w io.Writer) { fmt.Fprintln(w, usage) }
+	return f
+}
+
+// runList implements "assets list".
+func runList(w io.Writer, args []string) error {
+	kind, asJSON := "", false
+	f := newFlagSet("list", "assets list [--kind <kind>] [--json]")
+	f.BoolVar(&asJSON, "--json", "Print JSON")
+	f.Func("--kind", "kind", "Only assets of this kind", func(v string) error {
+		if !slices.Contains(kindOrder, v) {
+			return fmt.Errorf("unknown kind %q (use %s)", v, strings.Join(kindOrder, ", "))
+		}
+		kind = v
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+
+	list, err := List(platform.FS)
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+	if kind != "" {
+		var filtered []Asset
+		for _, a := range list {
+			if a.Kind == kind {
+				filtered = append(filtered, a)
+			}
+		}
+		list = filtered
+	}
+	if asJSON {
+		if list == nil {
+			list = []Asset{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	printList(w, list)
+	return nil
+}
+
+// printList prints list grouped by kind.
+func printList(w io.Writer, list []Asset) {
+	platform.PrintBanner(w, "Template Assets")
+	if dir := platform.AppliedOverridesDir(); dir != "" {
+		fmt.Fprintln(w)
+		platform.PrintInfo(w, "Includes template overrides from "+dir+" (marked *)")
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(w, "\n  No assets found.")
+		return
+	}
+	for _, kind := range kindOrder {
+		var group []Asset
+		for _, a := range list {
+			if a.Kind == kind {
+				group = append(group, a)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+		platform.PrintSection(w, fmt.Sprintf("%s (%d)", strings.ToUpper(kind[:1])+kind[1:], len(group)))
+		for _, a := range group {
+			name := a.Name
+			if a.Override {
+				name += " *"
+			}
+			line := fmt.Sprintf("  %-26s %-44s %7s", name, a.Path, formatSize(a.Size))
+			if a.Description != "" {
+				line += "  " + truncate(a.Description, 60)
+			}
+			fmt.Fprintln(w, strings.TrimRight(line, " "))
+		}
+	}
+	fmt.Fprintf(w, "\n  %d assets. Show one with: claude-workspace assets show <name>\n\n", len(list))
+}
+
+// runShow implements "assets show", printing the asset's content as is so it
+// can be piped or redirected.
+func runShow(w io.Writer, args []string) error {
+	args, err := cli.Operands("assets show", "assets show <asset>", args, 1, 1)
+	if err != nil {
+		return err
+	}
+	list, err := List(platform.FS)
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+	a, err := Resolve(list, args[0])
+	if err != nil {
+		return err
+	}
+	data, err := fs.ReadFile(platform.FS, a.Path)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// formatSize formats n bytes for the list, e.g. "512 B" or "3.4 KB".
+func formatSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
+
+// truncate shortens s to n runes, ending it with "…" when cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
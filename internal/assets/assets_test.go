@@ -0,0 +1,91 @@
+package assets
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// useMapFS replaces the template with files for the test.
+func useMapFS(t *testing.T, files map[string]string) fstest.MapFS {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for path, content := range files {
+		fsys[path] = &fstest.MapFile{Data: []byte(content)}
+	}
+	old := platform.FS
+	platform.FS = fsys
+	t.Cleanup(func() { platform.FS = old })
+	return fsys
+}
+
+var templateFiles = map[string]string{
+	".claude/agents/planner.md":          "---\nname: planner\ndescription: \"Plans work\"\n---\nPlan.\n",
+	".claude/skills/onboarding/SKILL.md": "---\nname: onboarding\ndescription: Onboards\n---\n",
+	".claude/skills/onboarding/ref.md":   "reference",
+	".claude/hooks/auto-format.sh":       "#!/bin/bash\n",
+	".claude/settings.json":              "{}\n",
+	".claude/CLAUDE.md":                  "# Project\n",
+	".claude/rules/platform.md":          "rules\n",
+	".claude/.gitignore":                 "settings.local.json\n",
+	".mcp.json":                          "{}\n",
+}
+
+func TestList(t *testing.T) {
+	list, err := List(useMapFS(t, templateFiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range list {
+		got = append(got, a.Kind+":"+a.Name)
+	}
+	want := "agents:planner skills:onboarding skills:onboarding/ref.md hooks:auto-format settings:settings.json settings:.mcp.json " +
+		"instructions:CLAUDE.md instructions:platform.md other:.gitignore"
+	if strings.Join(got, " ") != want {
+		t.Errorf("List() =\n %s\nwant\n %s", strings.Join(got, " "), want)
+	}
+	if list[0].Description != "Plans work" || list[1].Description != "Onboards" || list[0].Size == 0 {
+		t.Errorf("descriptions: %+v", list[:2])
+	}
+}
+
+func TestResolve(t *testing.T) {
+	list, _ := List(useMapFS(t, templateFiles))
+	for arg, want := range map[string]string{
+		".claude/agents/planner.md": ".claude/agents/planner.md",
+		"./.mcp.json":               ".mcp.json",
+		"agents/planner":            ".claude/agents/planner.md",
+		"planner":                   ".claude/agents/planner.md",
+		"onboarding":                ".claude/skills/onboarding/SKILL.md",
+		"settings.json":             ".claude/settings.json",
+	} {
+		if a, err := Resolve(list, arg); err != nil || a.Path != want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", arg, a.Path, err, want)
+		}
+	}
+	if _, err := Resolve(list, "reviewer"); err == nil || !strings.Contains(err.Error(), "assets list") {
+		t.Errorf("unknown asset: %v", err)
+	}
+
+	list = append(list, Asset{Path: ".claude/hooks/planner.sh", Kind: KindHooks, Name: "planner"})
+	if _, err := Resolve(list, "planner"); err == nil || !strings.Contains(err.Error(), "give the path") {
+		t.Errorf("ambiguous asset: %v", err)
+	}
+}
+
+func TestRunList_Kind(t *testing.T) {
+	useMapFS(t, templateFiles)
+	var b strings.Builder
+	if err := runList(&b, []string{"--kind", "hooks"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := b.String(); !strings.Contains(out, "Hooks (1)") || strings.Contains(out, "planner") {
+		t.Errorf("output:\n%s", out)
+	}
+	if err := runList(&b, []string{"--kind", "widgets"}); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
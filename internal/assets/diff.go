@@ -0,0 +1,226 @@
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/templates"
+)
+
+// releaseArchiveURL is the source archive of a claude-workspace release or
+// branch; GitHub resolves %s as a tag or a branch name.
+const releaseArchiveURL = "https://github.com/lamchakchan/claude-workspace/archive/%s.tar.gz"
+
+// Statuses of a template file compared with another copy.
+const (
+	statusSame     = "same"
+	statusModified = "modified"
+	statusMissing  = "missing" // only in the template
+	statusExtra    = "extra"   // only in the other copy
+)
+
+// fileDiff is a template file compared with another copy.
+type fileDiff struct {
+	Path   string
+	Status string
+	// Before is the other copy and After the template's, for modified files.
+	Before, After []byte
+}
+
+// fetchVersion returns the project template of another release, downloaded
+// through the template cache; tests replace it. Progress goes to w.
+var fetchVersion = func(w io.Writer, version string) (fs.FS, error) {
+	src := templates.Source{Kind: templates.KindTarball, URL: fmt.Sprintf(releaseArchiveURL, releaseRef(version))}
+	t, err := templates.Fetch(w, src, templates.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", version, err)
+	}
+	return t.FS(), nil
+}
+
+// releaseRef returns the git ref of version: release tags start with "v", so
+// "1.4.0" becomes "v1.4.0". Anything else, such as a branch, is used as is.
+func releaseRef(version string) string {
+	if version != "" && version[0] >= '0' && version[0] <= '9' {
+		return "v" + version
+	}
+	return version
+}
+
+// runDiff implements "assets diff": the template against a project's copy of
+// it, or against the template of another release. Lines marked "+" are the
+// template's, "-" the other copy's.
+func runDiff(w io.Writer, version string, args []string) error {
+	projectDir, other := "", ""
+	f := newFlagSet("diff", "assets diff [<asset>...] [--project <dir> | --version <version>]")
+	f.StringVar(&projectDir, "--project", "dir", "Compare with this project's copy")
+	f.StringVar(&other, "--version", "version", "Compare with the template of this release")
+	names, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if projectDir != "" && other != "" {
+		return fmt.Errorf("--project and --version cannot be combined")
+	}
+
+	list, err := List(platform.FS)
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+	var otherFS fs.FS
+	label := ""
+	if other != "" {
+		if otherFS, err = fetchVersion(os.Stderr, other); err != nil {
+			return err
+		}
+		label = releaseRef(other)
+	} else {
+		if projectDir == "" {
+			projectDir = "."
+		}
+		abs, err := filepath.Abs(projectDir)
+		if err != nil {
+			return fmt.Errorf("resolving path: %w", err)
+		}
+		if !platform.FileExists(abs) {
+			return fmt.Errorf("project directory not found: %s", abs)
+		}
+		otherFS, label = os.DirFS(abs), abs
+	}
+
+	paths, err := diffPaths(list, names, otherFS, other != "")
+	if err != nil {
+		return err
+	}
+	diffs, err := compare(platform.FS, otherFS, paths)
+	if err != nil {
+		return err
+	}
+	printDiff(w, diffs, "template ("+version+")", label, other != "")
+	return nil
+}
+
+// diffPaths returns the paths to compare: those of the named assets, or every
+// template file. Comparing against another release also includes the files
+// only it has, and accepts a path only it has.
+func diffPaths(list []Asset, names []string, otherFS fs.FS, release bool) ([]string, error) {
+	var paths []string
+	if len(names) > 0 {
+		for _, name := range names {
+			a, err := Resolve(list, name)
+			if err != nil {
+				if _, serr := fs.Stat(otherFS, name); !release || serr != nil {
+					return nil, err
+				}
+				a.Path = name
+			}
+			paths = append(paths, a.Path)
+		}
+		return paths, nil
+	}
+	seen := map[string]bool{}
+	for _, a := range list {
+		paths = append(paths, a.Path)
+		seen[a.Path] = true
+	}
+	if release {
+		others, err := List(otherFS)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range others {
+			if !seen[a.Path] {
+				paths = append(paths, a.Path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// compare compares each of paths in template with the same path in other.
+func compare(template, other fs.FS, paths []string) ([]fileDiff, error) {
+	diffs := make([]fileDiff, 0, len(paths))
+	for _, p := range paths {
+		after, aerr := fs.ReadFile(template, p)
+		before, berr := fs.ReadFile(other, p)
+		for _, err := range []error{aerr, berr} {
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("reading %s: %w", p, err)
+			}
+		}
+		d := fileDiff{Path: p}
+		switch {
+		case aerr != nil && berr != nil:
+			continue
+		case berr != nil:
+			d.Status = statusMissing
+		case aerr != nil:
+			d.Status = statusExtra
+		case bytes.Equal(before, after):
+			d.Status = statusSame
+		default:
+			d.Status, d.Before, d.After = statusModified, before, after
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// printDiff prints the files that differ, grouped by status, then a line diff
+// of each modified file. name labels the template and label the other copy.
+func printDiff(w io.Writer, diffs []fileDiff, name, label string, release bool) {
+	platform.PrintBanner(w, fmt.Sprintf("Assets: %s vs %s", name, label))
+	if dir := platform.AppliedOverridesDir(); dir != "" {
+		fmt.Fprintln(w)
+		platform.PrintInfo(w, "The template includes overrides from "+dir)
+	}
+
+	missingTitle, missingSummary := "Not in project", "not in project"
+	if release {
+		missingTitle, missingSummary = "Only in "+name, "only in "+name
+	}
+	groups := []struct{ status, title string }{
+		{statusModified, "Differs"},
+		{statusMissing, missingTitle},
+		{statusExtra, "Only in " + label},
+	}
+	counts := map[string]int{}
+	for _, d := range diffs {
+		counts[d.Status]++
+	}
+	for _, g := range groups {
+		if counts[g.status] == 0 {
+			continue
+		}
+		platform.PrintSection(w, fmt.Sprintf("%s (%d)", g.title, counts[g.status]))
+		for _, d := range diffs {
+			if d.Status == g.status {
+				fmt.Fprintln(w, "  "+d.Path)
+			}
+		}
+	}
+	for _, d := range diffs {
+		if d.Status == statusModified {
+			platform.PrintSection(w, "Diff: "+d.Path)
+			platform.WriteLineDiff(w, string(d.Before), string(d.After))
+		}
+	}
+
+	summary := fmt.Sprintf("%d identical, %d differ, %d %s", counts[statusSame], counts[statusModified], counts[statusMissing], missingSummary)
+	if release {
+		summary += fmt.Sprintf(", %d only in %s", counts[statusExtra], label)
+	}
+	fmt.Fprintf(w, "\n%s %s\n", platform.Bold("Summary:"), summary)
+	if counts[statusModified] > 0 {
+		fmt.Fprintf(w, "  Lines marked + are the template's, - are %s's.\n", label)
+	}
+	fmt.Fprintln(w)
+}
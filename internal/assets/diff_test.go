@@ -0,0 +1,125 @@
+package assets
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReleaseRef(t *testing.T) {
+	for in, want := range map[string]string{"1.4.0": "v1.4.0", "v1.4.0": "v1.4.0", "main": "main"} {
+		if got := releaseRef(in); got != want {
+			t.Errorf("releaseRef(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	template := fstest.MapFS{
+		"a.md": {Data: []byte("same\n")},
+		"b.md": {Data: []byte("new\n")},
+		"c.md": {Data: []byte("only here\n")},
+	}
+	other := fstest.MapFS{
+		"a.md": {Data: []byte("same\n")},
+		"b.md": {Data: []byte("old\n")},
+		"d.md": {Data: []byte("gone\n")},
+	}
+	diffs, err := compare(template, other, []string{"a.md", "b.md", "c.md", "d.md", "e.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.Path+":"+d.Status)
+	}
+	if want := "a.md:same b.md:modified c.md:missing d.md:extra"; strings.Join(got, " ") != want {
+		t.Errorf("compare() = %s, want %s", strings.Join(got, " "), want)
+	}
+	if string(diffs[1].Before) != "old\n" || string(diffs[1].After) != "new\n" {
+		t.Errorf("modified contents: %+v", diffs[1])
+	}
+}
+
+func TestRunDiff_Project(t *testing.T) {
+	useMapFS(t, templateFiles)
+	dir := t.TempDir()
+	for path, content := range templateFiles {
+		if path == ".mcp.json" {
+			continue
+		}
+		if path == ".claude/agents/planner.md" {
+			content = strings.Replace(content, "Plan.", "Plan carefully.", 1)
+		}
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+
+	var b strings.Builder
+	if err := runDiff(&b, "1.5.0", []string{"--project", dir}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"Differs (1)", "Not in project (1)", ".mcp.json",
+		"- Plan carefully.", "+ Plan.",
+		"7 identical, 1 differ, 1 not in project",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	b.Reset()
+	if err := runDiff(&b, "1.5.0", []string{"planner", "--project", dir}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "0 identical, 1 differ, 0 not in project") {
+		t.Errorf("single asset:\n%s", b.String())
+	}
+}
+
+func TestRunDiff_Version(t *testing.T) {
+	useMapFS(t, templateFiles)
+	saved := fetchVersion
+	t.Cleanup(func() { fetchVersion = saved })
+	var fetched string
+	fetchVersion = func(_ io.Writer, version string) (fs.FS, error) {
+		fetched = version
+		old := fstest.MapFS{}
+		for path, content := range templateFiles {
+			old[path] = &fstest.MapFile{Data: []byte(content)}
+		}
+		delete(old, ".claude/hooks/auto-format.sh")
+		old[".claude/agents/retired.md"] = &fstest.MapFile{Data: []byte("retired\n")}
+		return old, nil
+	}
+
+	var b strings.Builder
+	if err := runDiff(&b, "1.5.0", []string{"--version", "1.4.0"}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"template (1.5.0) vs v1.4.0",
+		"Only in template (1.5.0) (1)", ".claude/hooks/auto-format.sh",
+		"Only in v1.4.0 (1)", ".claude/agents/retired.md",
+		"8 identical, 0 differ, 1 only in template (1.5.0), 1 only in v1.4.0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if fetched != "1.4.0" {
+		t.Errorf("fetched %q", fetched)
+	}
+
+	if err := runDiff(&b, "1.5.0", []string{"--version", "1.4.0", "--project", "."}); err == nil {
+		t.Error("--project with --version should fail")
+	}
+}
@@ -4,21 +4,63 @@
 package attach
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/manifest"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/slashcommands"
+	"github.com/lamchakchan/claude-workspace/internal/templates"
+	"golang.org/x/term"
 )
 
 // Run executes the attach command, overlaying platform configuration onto the
-// project at targetPath. It supports --symlink, --force, and --no-enrich flags
-// parsed from allArgs.
-func Run(targetPath string, allArgs []string) error {
+// project given in args, and writes a slash command for each build, test, or
+// deploy task the project's justfile, Makefile, Taskfile, or package.json
+// defines. It supports --symlink, --force, and --no-enrich flags, among
+// the others in parseArgs. --hardlink links the agents, skills, and hooks to
+// the shared asset cache with reflinks, falling back to copies,
+// for filesystems that refuse symlinks; --symlink falls back to it there. When the project contains a claude-workspace.yaml manifest, only the
+// agents, skills, hooks, and MCP servers it declares are provisioned. --profile
+// <name> starts from an embedded template profile, and --list-profiles prints
+// the available profiles. --monorepo additionally writes a CLAUDE.md scaffold
+// into each package of a pnpm, npm, go.work, Cargo, or Nx workspace and lists
+// them under "Key Packages" in the root CLAUDE.md; agents, skills, and hooks
+// are only attached at the root. --devcontainer creates or updates the
+// project's devcontainer.json to install claude-workspace and the Claude CLI in
+// the container and pass the API key and MCP credentials through from the host.
+// --governance appends entries for the platform files to the project's
+// .gitattributes and, with --owners <list>, a CODEOWNERS line assigning .claude
+// to them, asking before changing a file that exists. --template <source>
+// uses a git or https tarball template in place of the embedded assets;
+// without it, the workspace.templateSource setting is used if set. attach
+// records the files it wrote in LockFile; --check reports how the project has
+// drifted from the template since, and --reconcile updates the files that were
+// not edited locally. The lock file also records the release; --pin pins the
+// project to it, after which other releases refuse to attach until run with
+// --upgrade-assets, which reconciles the project with the running release and
+// keeps it pinned. Linked assets follow the shared cache, so --pin is refused
+// with --symlink and --hardlink. --dry-run prints
+// what attach would create, overwrite, merge, link, and skip, with a diff of
+// merged settings, and changes nothing. Otherwise the writes are staged in a temporary directory
+// and applied to the project together once every step has run; if applying
+// them fails, the changes already made are rolled back. version is the running
+// CLI version, recorded in the lock file.
+func Run(version string, args []string) error {
+	targetPath, opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if opts.listProfiles {
+		return listProfiles()
+	}
 	if targetPath == "" {
-		fmt.Fprintln(os.Stderr, "Usage: claude-workspace attach <project-path> [--symlink] [--force] [--no-enrich]")
-		os.Exit(1)
+		return fmt.Errorf("usage: claude-workspace %s", usage)
 	}
 
 	projectDir, err := filepath.Abs(targetPath)
@@ -26,127 +68,422 @@ func Run(targetPath string, allArgs []string) error {
 		return fmt.Errorf("resolving path: %w", err)
 	}
 
-	useSymlinks := contains(allArgs, "--symlink")
-	force := contains(allArgs, "--force")
-	noEnrich := contains(allArgs, "--no-enrich")
+	useSymlinks, useHardlinks := opts.symlink, opts.hardlink
+	force, noEnrich, monorepo := opts.force, opts.noEnrich, opts.monorepo
+	profile, source := opts.profile, opts.template
+	check, reconcile := opts.check, opts.reconcile
+	pin, upgradeAssets := opts.pin, opts.upgradeAssets
+	dryRun, devcontainer, governance := opts.dryRun, opts.devcontainer, opts.governance
+	var owners []string
+	if opts.owners != "" {
+		if !governance {
+			return fmt.Errorf("--owners requires --governance")
+		}
+		if owners, err = parseOwners(opts.owners); err != nil {
+			return err
+		}
+	}
+
+	if useSymlinks && useHardlinks {
+		return fmt.Errorf("--symlink and --hardlink cannot be combined")
+	}
 
 	if !platform.FileExists(projectDir) {
 		return fmt.Errorf("project directory not found: %s", projectDir)
 	}
 
-	platform.PrintBanner(os.Stdout, fmt.Sprintf("Attaching Claude Platform to: %s", projectDir))
-	fmt.Println()
+	lock, err := ReadLock(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// --upgrade-assets is a reconcile that may move a pinned project to this
+	// release.
+	if upgradeAssets {
+		if check {
+			return fmt.Errorf("--upgrade-assets cannot be combined with --check (--check shows what it would change)")
+		}
+		reconcile = true
+	}
+	if pin && check {
+		return fmt.Errorf("--pin cannot be combined with --check")
+	}
+	// A reconcile keeps the project's link mode; an attach takes the one given.
+	linked := useSymlinks || useHardlinks
+	if check || reconcile {
+		linked = lock != nil && (lock.Symlink || lock.Hardlink)
+	}
+	if err := checkPin(lock, pin, linked && !check); err != nil {
+		return err
+	}
+
+	// A drift check compares against the profile and template recorded at
+	// attach time unless others are given.
+	if check || reconcile {
+		if check && reconcile {
+			return fmt.Errorf("--check and --reconcile cannot be combined")
+		}
+		if dryRun {
+			return fmt.Errorf("--dry-run cannot be combined with --check, --reconcile, or --upgrade-assets (--check already changes nothing)")
+		}
+		if lock != nil && profile == "" {
+			profile = lock.Profile
+		}
+		if lock != nil && source == "" {
+			source = lock.Template
+		}
+	}
+
+	if source == "" && !check && !reconcile {
+		source = platform.ConfigString(platform.ConfigTemplateSource)
+	}
+
+	// Upgrading the binary leaves a pinned project's assets alone until the
+	// team opts in.
+	if lock.HeldBack(version) && !upgradeAssets && !check && !dryRun {
+		platform.PrintOutcome(platform.Stdout(), OutcomeHeldBack)
+		return fmt.Errorf("%s pins the project's assets to claude-workspace %s (this is %s); run attach --upgrade-assets to update them, or --check to see what would change", LockFile, lock.Version, version)
+	}
+
+	ws := platform.DetectWorkspace(projectDir)
+	if monorepo && ws == nil {
+		return fmt.Errorf("--monorepo: no pnpm-workspace.yaml, package.json workspaces, go.work, Cargo.toml [workspace], or nx.json with members found in %s", projectDir)
+	}
+	steps := 8
+	if monorepo {
+		steps++
+	}
+	if devcontainer {
+		steps++
+	}
+	if governance {
+		steps++
+	}
+
+	var tmpl *templates.Template
+	if source != "" {
+		tmpl, err = fetchTemplate(source, opts)
+		if err != nil {
+			return err
+		}
+		defer useAssetFS(platform.WithOverrides(tmpl.FS()))()
+	}
+
+	m, assetFS, err := manifest.Resolve(projectDir, profile)
+	if err != nil {
+		return err
+	}
+	defer useAssetFS(assetFS)()
+
+	if err := m.Validate(availableAssets()); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	cacheDir, _ := platform.AssetCacheDir()
+	if tmpl != nil {
+		cacheDir = tmpl.Assets
+	}
+	out := platform.Stdout()
+	if check || reconcile {
+		return runDrift(out, version, projectDir, m, tmpl, lock, reconcile, pin, cacheDir)
+	}
+	// Some filesystems and locked-down machines refuse symlinks; the assets
+	// are linked by other means, which still keeps them updated centrally.
+	symlinkFallback := useSymlinks && !platform.SymlinksSupported(projectDir)
+	if symlinkFallback {
+		useSymlinks, useHardlinks = false, true
+	}
+	if dryRun {
+		entries, notes, err := plan(projectDir, planOptions{symlink: useSymlinks, hardlink: useHardlinks, force: force, noEnrich: noEnrich, monorepo: monorepo, devcontainer: devcontainer, governance: governance, pin: lock.pinned(pin), owners: owners}, m, ws, lock)
+		if err != nil {
+			return err
+		}
+		if symlinkFallback {
+			notes = append(notes, fmt.Sprintf("%s does not support symlinks: --symlink would use --hardlink", projectDir))
+		}
+		if lock.HeldBack(version) {
+			notes = append(notes, fmt.Sprintf("The project is pinned to claude-workspace %s: attach refuses to run without --upgrade-assets", lock.Version))
+		}
+		printPlan(out, projectDir, entries, notes)
+		return nil
+	}
+	next := newLock(version, m, tmpl, useSymlinks)
+	next.Hardlink = useHardlinks
+	next.Pinned = lock.pinned(pin)
+
+	platform.PrintBanner(out, fmt.Sprintf("Attaching Claude Platform to: %s", projectDir))
+	fmt.Fprintln(out)
+
+	if tmpl != nil {
+		platform.PrintInfo(out, fmt.Sprintf("Using template: %s (%s)", tmpl.Source, shortRevision(tmpl.Revision)))
+	}
+	if m != nil && m.Profile != "" {
+		platform.PrintInfo(out, fmt.Sprintf("Using profile: %s", m.Profile))
+	}
+	if m != nil && m.Path != "" {
+		platform.PrintInfo(out, fmt.Sprintf("Using manifest: %s", filepath.Base(m.Path)))
+	}
+	if dir := platform.AppliedOverridesDir(); dir != "" {
+		platform.PrintInfo(out, fmt.Sprintf("Using template overrides: %s", dir))
+	}
+	if symlinkFallback {
+		platform.PrintWarningLine(out, fmt.Sprintf("%s does not support symlinks; using --hardlink instead", projectDir))
+	}
 
-	claudeDir := filepath.Join(projectDir, ".claude")
+	// Every step writes to a staging copy of the files attach touches. The
+	// difference is applied to the project at the end, and rolled back if
+	// applying it fails, so the project is never left partially attached.
+	var stagedWs *platform.Workspace
+	if monorepo {
+		stagedWs = ws
+	}
+	tx, err := beginTransaction(projectDir, stagedWs)
+	if err != nil {
+		return err
+	}
+	defer tx.discard()
+	stageDir := tx.path(".")
+	claudeDir := tx.path(".claude")
 
 	// Create directories
 	for _, dir := range []string{
-		claudeDir,
 		filepath.Join(claudeDir, "agents"),
 		filepath.Join(claudeDir, "skills"),
 		filepath.Join(claudeDir, "hooks"),
 		filepath.Join(claudeDir, "plans"),
 		filepath.Join(claudeDir, "rules"),
 	} {
-		_ = os.MkdirAll(dir, 0755)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return stepFailed(projectDir, err)
+		}
 	}
 
 	// Create .claude/plans/.gitkeep to keep directory tracked while contents are gitignored
 	gitkeepPath := filepath.Join(claudeDir, "plans", ".gitkeep")
 	if !platform.FileExists(gitkeepPath) {
-		_ = os.WriteFile(gitkeepPath, []byte{}, 0644)
+		if err := os.WriteFile(gitkeepPath, []byte{}, 0644); err != nil {
+			return stepFailed(projectDir, err)
+		}
 	}
 
-	// For symlink mode, extract assets first
+	// For symlink and --hardlink modes, extract assets first
 	var assetBase string
-	if useSymlinks {
-		assetBase, err = platform.ExtractForSymlink()
+	if useSymlinks || useHardlinks {
+		if tmpl != nil {
+			assetBase = tmpl.Assets
+			err = platform.ExtractForSymlinkTo(assetBase, shortRevision(tmpl.Revision))
+		} else {
+			assetBase, err = platform.ExtractForSymlink(version)
+		}
 		if err != nil {
 			return fmt.Errorf("extracting assets for symlink: %w", err)
 		}
 	}
 
-	// Copy or symlink agents
-	platform.PrintStep(os.Stdout, 1, 7, "Setting up agents...")
-	if useSymlinks {
-		copyOrLinkFromDisk(filepath.Join(assetBase, ".claude", "agents"), filepath.Join(claudeDir, "agents"), true, force, projectDir)
-	} else {
-		copyFromEmbed(".claude/agents", filepath.Join(claudeDir, "agents"), force, projectDir)
+	// Copy or symlink agents, skills, and hooks
+	for i, kind := range []string{manifest.KindAgents, manifest.KindSkills, manifest.KindHooks} {
+		platform.PrintStep(out, i+1, steps, fmt.Sprintf("Setting up %s...", kind))
+		rel := ".claude/" + kind
+		dest := filepath.Join(claudeDir, kind)
+		if useSymlinks || useHardlinks {
+			err = copyOrLinkFromDisk(filepath.Join(assetBase, filepath.FromSlash(rel)), dest, useSymlinks, force, inTemplate(rel, includeFunc(m, kind)))
+		} else {
+			err = copyFromEmbed(rel, dest, force, includeFunc(m, kind))
+		}
+		if err != nil {
+			return stepFailed(projectDir, err)
+		}
 	}
 
-	// Copy or symlink skills
-	platform.PrintStep(os.Stdout, 2, 7, "Setting up skills...")
-	if useSymlinks {
-		copyOrLinkFromDisk(filepath.Join(assetBase, ".claude", "skills"), filepath.Join(claudeDir, "skills"), true, force, projectDir)
-	} else {
-		copyFromEmbed(".claude/skills", filepath.Join(claudeDir, "skills"), force, projectDir)
+	// Create or merge settings.json
+	platform.PrintStep(out, 4, steps, "Setting up settings...")
+	if err := setupProjectSettings(claudeDir, force, m, lock, next); err != nil {
+		return stepFailed(projectDir, err)
 	}
 
-	// Copy or symlink hooks
-	platform.PrintStep(os.Stdout, 3, 7, "Setting up hooks...")
-	if useSymlinks {
-		copyOrLinkFromDisk(filepath.Join(assetBase, ".claude", "hooks"), filepath.Join(claudeDir, "hooks"), true, force, projectDir)
-	} else {
-		copyFromEmbed(".claude/hooks", filepath.Join(claudeDir, "hooks"), force, projectDir)
+	// Create or merge .mcp.json
+	platform.PrintStep(out, 5, steps, "Setting up MCP configuration...")
+	if err := setupMcpConfig(stageDir, force, m, lock, next); err != nil {
+		return stepFailed(projectDir, err)
 	}
 
-	// Create or merge settings.json
-	platform.PrintStep(os.Stdout, 4, 7, "Setting up settings...")
-	setupProjectSettings(claudeDir, force)
+	// Create project instructions (CLAUDE.md or rules/platform.md)
+	platform.PrintStep(out, 6, steps, "Setting up project instructions...")
+	instructionsPath, err := setupProjectInstructions(projectDir, claudeDir, force)
+	if err != nil {
+		return stepFailed(projectDir, err)
+	}
 
-	// Create or merge .mcp.json
-	platform.PrintStep(os.Stdout, 5, 7, "Setting up MCP configuration...")
-	setupMcpConfig(projectDir, force)
+	// Create slash commands for the project's build, test, and deploy tasks
+	platform.PrintStep(out, 7, steps, "Setting up slash commands...")
+	if _, err := slashcommands.GenerateTo(out, projectDir, stageDir, force); err != nil {
+		return stepFailed(projectDir, err)
+	}
 
-	// Create project instructions (CLAUDE.md or rules/platform.md)
-	platform.PrintStep(os.Stdout, 6, 7, "Setting up project instructions...")
-	instructionsPath := setupProjectInstructions(projectDir, claudeDir, force)
+	// The optional steps follow, numbered in the order they run
+	step := 7
+
+	// Create per-package instructions for monorepo members
+	var packagePaths []string
+	if monorepo {
+		step++
+		platform.PrintStep(out, step, steps, fmt.Sprintf("Setting up package instructions (%s, %d packages)...", ws.Config, len(ws.Members)))
+		if packagePaths, err = setupPackageInstructions(projectDir, stageDir, ws, force); err != nil {
+			return stepFailed(projectDir, err)
+		}
+	}
+
+	// Install claude-workspace in the project's devcontainer
+	if devcontainer {
+		step++
+		platform.PrintStep(out, step, steps, "Setting up devcontainer...")
+		if err := setupDevcontainer(projectDir, stageDir); err != nil {
+			return stepFailed(projectDir, err)
+		}
+	}
+
+	// Add .gitattributes and CODEOWNERS entries for review of platform changes
+	if governance {
+		step++
+		platform.PrintStep(out, step, steps, "Setting up review governance...")
+		var in *bufio.Reader
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			in = bufio.NewReader(os.Stdin)
+		}
+		if err := setupGovernance(out, in, stageDir, owners, force); err != nil {
+			return stepFailed(projectDir, err)
+		}
+	}
 
 	// Enrich instructions with AI-powered project analysis
-	enrichInstructions(projectDir, instructionsPath, noEnrich)
+	if err := enrichInstructions(projectDir, stageDir, instructionsPath, packagePaths, noEnrich, steps); err != nil {
+		return stepFailed(projectDir, err)
+	}
+
+	// The root CLAUDE.md indexes the packages, but only when attach wrote it
+	if monorepo && instructionsPath == filepath.Join(claudeDir, "CLAUDE.md") {
+		if err := platform.UpdateKeyPackagesFrom(projectDir, stageDir, ws, instructionsPath); err != nil {
+			return stepFailed(projectDir, fmt.Errorf("updating Key Packages: %w", err))
+		}
+	}
 
 	// Setup gitignore
-	setupGitignore(claudeDir)
+	if err := setupGitignore(claudeDir); err != nil {
+		return stepFailed(projectDir, err)
+	}
+
+	// Record what was attached, for --check and --reconcile
+	if err := recordAttach(stageDir, next, lock, m, cacheDir); err != nil {
+		return stepFailed(projectDir, err)
+	}
+
+	if tx.interrupted() {
+		return fmt.Errorf("attach interrupted; %s was not changed", projectDir)
+	}
+	changes, err := tx.changes()
+	if err != nil {
+		return fmt.Errorf("comparing staged files: %w", err)
+	}
+	if err := tx.commit(changes); err != nil {
+		return fmt.Errorf("attach failed: %w", err)
+	}
+	platform.PrintSuccess(out, fmt.Sprintf("Applied %d change(s) to %s", len(changes), projectDir))
+	if useHardlinks {
+		linkAssets(out, assetBase, projectDir)
+	}
 
-	platform.PrintBanner(os.Stdout, "Attachment Complete")
-	fmt.Printf("\n%s %s\n", platform.Bold("Platform attached to:"), projectDir)
+	platform.PrintBanner(out, "Attachment Complete")
+	fmt.Fprintf(out, "\n%s %s\n", platform.Bold("Platform attached to:"), projectDir)
 
-	platform.PrintSection(os.Stdout, "Start Claude Code")
-	platform.PrintCommand(os.Stdout, fmt.Sprintf("cd %s && claude", projectDir))
+	platform.PrintSection(out, "Start Claude Code")
+	platform.PrintCommand(out, fmt.Sprintf("cd %s && claude", projectDir))
 
-	platform.PrintSection(os.Stdout, "Customize for this project")
-	platform.PrintManual(os.Stdout, fmt.Sprintf("Edit %s for project instructions", filepath.Join(claudeDir, "CLAUDE.md")))
-	platform.PrintManual(os.Stdout, fmt.Sprintf("Add modular rules to %s", filepath.Join(claudeDir, "rules")))
-	platform.PrintManual(os.Stdout, "Copy .claude/settings.local.json.example to .claude/settings.local.json for personal overrides")
-	fmt.Println()
+	platform.PrintSection(out, "Customize for this project")
+	platform.PrintManual(out, fmt.Sprintf("Edit %s for project instructions", filepath.Join(projectDir, ".claude", "CLAUDE.md")))
+	platform.PrintManual(out, fmt.Sprintf("Add modular rules to %s", filepath.Join(projectDir, ".claude", "rules")))
+	platform.PrintManual(out, "Add slash commands for other tasks with `claude-workspace commands add <task>`")
+	platform.PrintManual(out, "Run `claude-workspace localize` to set up .claude/settings.local.json for personal overrides")
+	if monorepo && instructionsPath != filepath.Join(claudeDir, "CLAUDE.md") {
+		platform.PrintManual(out, "Run `claude-workspace enrich --monorepo` to add a Key Packages section to the existing CLAUDE.md")
+	}
+	if ws != nil && !monorepo {
+		platform.PrintInfo(out, fmt.Sprintf("Detected a %s workspace with %d packages. Re-run with --monorepo to add a CLAUDE.md to each package.", ws.Kind, len(ws.Members)))
+	}
+	fmt.Fprintln(out)
 
 	return nil
 }
 
-func enrichInstructions(projectDir, instructionsPath string, noEnrich bool) {
+// stepFailed returns the error for a step that failed before the staged
+// changes were applied, which leaves the project as it was.
+func stepFailed(projectDir string, err error) error {
+	return fmt.Errorf("attach failed: %w; %s was not changed", err, projectDir)
+}
+
+// fetchTemplate fetches the --template source, verified per --template-sha256
+// and --verify-signature.
+func fetchTemplate(source string, opts options) (*templates.Template, error) {
+	src, err := templates.ParseSource(source)
+	if err != nil {
+		return nil, err
+	}
+	return templates.Fetch(platform.Stdout(), src, templates.Options{
+		SHA256:          opts.templateSHA256,
+		VerifySignature: opts.verifySignature,
+	})
+}
+
+// shortRevision abbreviates a commit or checksum for display.
+func shortRevision(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+// enrichInstructions enriches the package scaffolds written by a --monorepo
+// attach, then the root instructions file. The paths are staged under
+// stageDir; claude explores the packages and project in projectDir. Without
+// an authenticated Claude CLI the scaffolds are kept as they are; a failed
+// enrichment is an error.
+func enrichInstructions(projectDir, stageDir, instructionsPath string, packagePaths []string, noEnrich bool, steps int) error {
+	out := platform.Stdout()
 	if noEnrich {
-		platform.PrintStep(os.Stdout, 7, 7, "Skipping enrichment (--no-enrich)")
-		return
+		platform.PrintStep(out, steps, steps, "Skipping enrichment (--no-enrich)")
+		return nil
 	}
-	if instructionsPath == "" {
-		return
+	if instructionsPath == "" && len(packagePaths) == 0 {
+		return nil
 	}
-	relTarget, _ := filepath.Rel(projectDir, instructionsPath)
-	platform.PrintStep(os.Stdout, 7, 7, fmt.Sprintf("Enriching %s with project context...", relTarget))
 	if reason := enrichSkipReason(); reason != "" {
-		platform.PrintWarningLine(os.Stdout, reason)
-		fmt.Printf("  Using static scaffold. Edit %s to customize.\n", relTarget)
-	} else if err := platform.EnrichClaudeMd(projectDir, instructionsPath); err != nil {
-		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Note: %v", err))
-		fmt.Printf("  Using static scaffold. Edit %s to customize.\n", relTarget)
+		platform.PrintStep(out, steps, steps, "Enriching project instructions...")
+		platform.PrintWarningLine(out, reason)
+		fmt.Fprintln(out, "  Using static scaffolds. Edit them to customize.")
+		return nil
+	}
+	for _, path := range packagePaths {
+		relTarget, _ := filepath.Rel(stageDir, path)
+		platform.PrintStep(out, steps, steps, fmt.Sprintf("Enriching %s with package context...", relTarget))
+		if err := platform.EnrichPackageClaudeMd(projectDir, filepath.Join(projectDir, filepath.Dir(relTarget)), path); err != nil {
+			return fmt.Errorf("enriching %s: %w (run with --no-enrich to keep the static scaffold)", relTarget, err)
+		}
 	}
+	if instructionsPath == "" {
+		return nil
+	}
+	relTarget, _ := filepath.Rel(stageDir, instructionsPath)
+	platform.PrintStep(out, steps, steps, fmt.Sprintf("Enriching %s with project context...", relTarget))
+	if err := platform.EnrichClaudeMd(projectDir, instructionsPath); err != nil {
+		return fmt.Errorf("enriching %s: %w (run with --no-enrich to keep the static scaffold)", relTarget, err)
+	}
+	return nil
 }
 
-// copyFromEmbed copies files from the embedded FS to disk.
-func copyFromEmbed(srcDir, destDir string, force bool, _ string) {
-	cwd, _ := os.Getwd()
-
-	err := fs.WalkDir(platform.FS, srcDir, func(path string, d fs.DirEntry, err error) error {
+// copyFromEmbed copies files from the embedded FS to disk. Files whose path
+// relative to srcDir is rejected by include are skipped.
+func copyFromEmbed(srcDir, destDir string, force bool, include func(rel string) bool) error {
+	out := platform.Stdout()
+	return fs.WalkDir(platform.FS, srcDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil || path == srcDir {
 			return err
 		}
@@ -155,14 +492,13 @@ func copyFromEmbed(srcDir, destDir string, force bool, _ string) {
 		}
 
 		rel, _ := filepath.Rel(srcDir, path)
+		if !include(rel) {
+			return nil
+		}
 		destFile := filepath.Join(destDir, rel)
 
 		if platform.FileExists(destFile) && !force {
-			relFromCwd, _ := filepath.Rel(cwd, destFile)
-			if relFromCwd == "" {
-				relFromCwd = destFile
-			}
-			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Skipping (exists): %s", relFromCwd))
+			platform.PrintWarningLine(out, fmt.Sprintf("Skipping (exists): %s", rel))
 			return nil
 		}
 
@@ -171,7 +507,9 @@ func copyFromEmbed(srcDir, destDir string, force bool, _ string) {
 			return err
 		}
 
-		_ = os.MkdirAll(filepath.Dir(destFile), 0755)
+		if err := os.MkdirAll(filepath.Dir(destFile), 0755); err != nil {
+			return err
+		}
 
 		perm := os.FileMode(0644)
 		if filepath.Ext(path) == ".sh" {
@@ -182,34 +520,52 @@ func copyFromEmbed(srcDir, destDir string, force bool, _ string) {
 			return err
 		}
 
-		platform.PrintSuccess(os.Stdout, fmt.Sprintf("Copied: %s", rel))
+		platform.PrintSuccess(out, fmt.Sprintf("Copied: %s", rel))
 		return nil
 	})
+}
 
+// linkAssets links the agents, skills, and hooks of projectDir that match
+// the assets in cacheDir to them, as attach --hardlink does once the copies
+// are in place, and prints how they were linked.
+func linkAssets(w io.Writer, cacheDir, projectDir string) {
+	counts, err := platform.LinkProjectAssets(cacheDir, projectDir)
 	if err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error: %v", err))
+		platform.PrintErrorLine(w, fmt.Sprintf("Error linking assets: %v (the copies are kept)", err))
+		return
+	}
+	var parts []string
+	total := 0
+	for _, method := range []string{platform.LinkReflink, platform.LinkCopy} {
+		if counts[method] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[method], method))
+			total += counts[method]
+		}
 	}
+	if total == 0 {
+		return
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Linked %d file(s) to the shared assets (%s)", total, strings.Join(parts, ", ")))
 }
 
-// copyOrLinkFromDisk copies or symlinks files from a disk directory.
-func copyOrLinkFromDisk(src, dest string, symlink, force bool, _ string) {
+// copyOrLinkFromDisk copies or symlinks files from a disk directory. Files whose
+// path relative to src is rejected by include are skipped.
+func copyOrLinkFromDisk(src, dest string, symlink, force bool, include func(rel string) bool) error {
+	out := platform.Stdout()
 	if !platform.FileExists(src) {
-		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Skipping: %s does not exist", src))
-		return
+		platform.PrintWarningLine(out, fmt.Sprintf("Skipping: %s does not exist", src))
+		return nil
 	}
 
-	cwd, _ := os.Getwd()
-
-	_ = platform.WalkFiles(src, func(relPath string) error {
+	return platform.WalkFiles(src, func(relPath string) error {
+		if !include(relPath) {
+			return nil
+		}
 		srcFile := filepath.Join(src, relPath)
 		destFile := filepath.Join(dest, relPath)
 
 		if platform.FileExists(destFile) && !force {
-			relFromCwd, _ := filepath.Rel(cwd, destFile)
-			if relFromCwd == "" {
-				relFromCwd = destFile
-			}
-			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Skipping (exists): %s", relFromCwd))
+			platform.PrintWarningLine(out, fmt.Sprintf("Skipping (exists): %s", relPath))
 			return nil
 		}
 
@@ -218,71 +574,118 @@ func copyOrLinkFromDisk(src, dest string, symlink, force bool, _ string) {
 				os.Remove(destFile)
 			}
 			if err := platform.SymlinkFile(srcFile, destFile); err != nil {
-				platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error symlinking %s: %v", relPath, err))
-				return nil
+				return fmt.Errorf("symlinking %s: %w", relPath, err)
 			}
-			platform.PrintSuccess(os.Stdout, fmt.Sprintf("Linked: %s", relPath))
+			platform.PrintSuccess(out, fmt.Sprintf("Linked: %s", relPath))
 		} else {
 			if err := platform.CopyFile(srcFile, destFile); err != nil {
-				platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error copying %s: %v", relPath, err))
-				return nil
+				return fmt.Errorf("copying %s: %w", relPath, err)
 			}
-			platform.PrintSuccess(os.Stdout, fmt.Sprintf("Copied: %s", relPath))
+			platform.PrintSuccess(out, fmt.Sprintf("Copied: %s", relPath))
 		}
 		return nil
 	})
 }
 
-func setupProjectSettings(claudeDir string, force bool) {
+// setupProjectSettings writes .claude/settings.json. With --force, an existing
+// file is three-way merged with the template it was created from, as recorded
+// in prev, and only overwritten when there is no record.
+func setupProjectSettings(claudeDir string, force bool, m *manifest.Manifest, prev, next *Lock) error {
 	settingsPath := filepath.Join(claudeDir, "settings.json")
+	exists := platform.FileExists(settingsPath)
 
-	if platform.FileExists(settingsPath) && !force {
-		platform.PrintWarningLine(os.Stdout, "Project settings already exist. Use --force to overwrite.")
-		return
+	out := platform.Stdout()
+	if exists && !force {
+		platform.PrintWarningLine(out, "Project settings already exist. Use --force to update them.")
+		return nil
 	}
 
 	// Read platform settings from embedded FS
 	data, err := platform.ReadAsset(".claude/settings.json")
 	if err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error reading embedded settings: %v", err))
-		return
+		return fmt.Errorf("reading embedded settings: %w", err)
 	}
 
-	if err := os.WriteFile(settingsPath, data, 0644); err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error writing settings: %v", err))
-		return
+	// Drop hook entries for scripts the manifest does not provision
+	data, err = m.RenderSettings(data)
+	if err != nil {
+		return fmt.Errorf("filtering settings hooks: %w", err)
+	}
+
+	merged := false
+	if exists {
+		projectDir := filepath.Dir(claudeDir)
+		if merged, err = mergeFile(out, projectDir, ".claude/settings.json", data, prev, next); err != nil {
+			return fmt.Errorf("merging settings: %w", err)
+		}
+	}
+	if !merged {
+		if err := platform.WriteSettingsData(settingsPath, data); err != nil {
+			return fmt.Errorf("writing settings: %w", err)
+		}
+		if exists {
+			platform.PrintWarningLine(out, fmt.Sprintf("Overwrote .claude/settings.json (no merge base in %s)", LockFile))
+		} else {
+			platform.PrintSuccess(out, "Created .claude/settings.json")
+		}
 	}
-	platform.PrintSuccess(os.Stdout, "Created .claude/settings.json")
 
 	// Copy the local settings example
 	if exampleData, err := platform.ReadAsset(".claude/settings.local.json.example"); err == nil {
 		destExample := filepath.Join(claudeDir, "settings.local.json.example")
 		if !platform.FileExists(destExample) || force {
-			_ = os.WriteFile(destExample, exampleData, 0644)
-			platform.PrintSuccess(os.Stdout, "Created .claude/settings.local.json.example")
+			if err := os.WriteFile(destExample, exampleData, 0644); err != nil {
+				return fmt.Errorf("writing settings.local.json.example: %w", err)
+			}
+			platform.PrintSuccess(out, "Created .claude/settings.local.json.example")
 		}
 	}
+	return nil
 }
 
-func setupMcpConfig(projectDir string, force bool) {
+// setupMcpConfig writes .mcp.json, merging it like setupProjectSettings.
+func setupMcpConfig(projectDir string, force bool, m *manifest.Manifest, prev, next *Lock) error {
 	mcpPath := filepath.Join(projectDir, ".mcp.json")
+	exists := platform.FileExists(mcpPath)
 
-	if platform.FileExists(mcpPath) && !force {
-		platform.PrintWarningLine(os.Stdout, "MCP config already exists. Use --force to overwrite.")
-		return
+	out := platform.Stdout()
+	if exists && !force {
+		platform.PrintWarningLine(out, "MCP config already exists. Use --force to update it.")
+		return nil
 	}
 
-	data, err := platform.ReadAsset(".mcp.json")
+	var data []byte
+	var needsCreds []string
+	var err error
+	if m.Declares(manifest.KindMCPServers) {
+		data, needsCreds, err = m.RenderMcpConfig()
+	} else {
+		data, err = platform.ReadAsset(".mcp.json")
+	}
 	if err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error reading embedded .mcp.json: %v", err))
-		return
+		return fmt.Errorf("reading embedded .mcp.json: %w", err)
 	}
 
-	if err := os.WriteFile(mcpPath, data, 0644); err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error writing .mcp.json: %v", err))
-		return
+	merged := false
+	if exists {
+		if merged, err = mergeFile(out, projectDir, ".mcp.json", data, prev, next); err != nil {
+			return fmt.Errorf("merging .mcp.json: %w", err)
+		}
+	}
+	if !merged {
+		if err := os.WriteFile(mcpPath, data, 0644); err != nil {
+			return fmt.Errorf("writing .mcp.json: %w", err)
+		}
+		if exists {
+			platform.PrintWarningLine(out, fmt.Sprintf("Overwrote .mcp.json (no merge base in %s)", LockFile))
+		} else {
+			platform.PrintSuccess(out, "Created .mcp.json")
+		}
 	}
-	platform.PrintSuccess(os.Stdout, "Created .mcp.json")
+	for _, name := range needsCreds {
+		platform.PrintManual(out, fmt.Sprintf("Set credentials for %q (see .mcp.json env/headers)", name))
+	}
+	return nil
 }
 
 // setupProjectInstructions writes the project scaffold to the appropriate target file.
@@ -290,78 +693,104 @@ func setupMcpConfig(projectDir string, force bool) {
 // If .claude/CLAUDE.md already exists and --force is not set, the scaffold is written
 // to .claude/rules/platform.md instead (non-destructive).
 // With --force, the scaffold always overwrites .claude/CLAUDE.md.
-// Returns the path of the written file, or "" if nothing was written.
-func setupProjectInstructions(projectDir, claudeDir string, force bool) string {
+// Returns the path of the written file.
+func setupProjectInstructions(projectDir, claudeDir string, force bool) (string, error) {
 	claudeMdPath := filepath.Join(claudeDir, "CLAUDE.md")
 	rulesPath := filepath.Join(claudeDir, "rules", "platform.md")
 
+	out := platform.Stdout()
 	// Determine where to write the scaffold and what content to use
 	if force || !platform.FileExists(claudeMdPath) {
 		// First-time setup or --force: write scaffold to CLAUDE.md, copy rules template
 		content := platform.GenerateClaudeMdScaffold(projectDir)
 		if err := os.WriteFile(claudeMdPath, []byte(content), 0644); err != nil {
-			platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error writing CLAUDE.md: %v", err))
-			return ""
+			return "", fmt.Errorf("writing CLAUDE.md: %w", err)
 		}
-		platform.PrintSuccess(os.Stdout, "Created .claude/CLAUDE.md (customize for your project)")
+		platform.PrintSuccess(out, "Created .claude/CLAUDE.md (customize for your project)")
 
 		// Also copy the platform rules template
 		if rulesData, err := platform.ReadAsset(".claude/rules/platform.md"); err == nil {
 			if !platform.FileExists(rulesPath) || force {
 				if err := os.WriteFile(rulesPath, rulesData, 0644); err != nil {
-					platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error writing rules/platform.md: %v", err))
-				} else {
-					platform.PrintSuccess(os.Stdout, "Created .claude/rules/platform.md")
+					return "", fmt.Errorf("writing rules/platform.md: %w", err)
 				}
+				platform.PrintSuccess(out, "Created .claude/rules/platform.md")
 			}
 		}
-		return claudeMdPath
+		return claudeMdPath, nil
 	}
 
 	// Existing CLAUDE.md without --force: write platform rules template to rules/platform.md
-	platform.PrintWarningLine(os.Stdout, "Project CLAUDE.md already exists. Writing platform conventions to .claude/rules/platform.md")
+	platform.PrintWarningLine(out, "Project CLAUDE.md already exists. Writing platform conventions to .claude/rules/platform.md")
 	rulesData, err := platform.ReadAsset(".claude/rules/platform.md")
 	if err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error reading platform rules template: %v", err))
-		return ""
+		return "", fmt.Errorf("reading platform rules template: %w", err)
 	}
 	if err := os.WriteFile(rulesPath, rulesData, 0644); err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error writing rules/platform.md: %v", err))
-		return ""
+		return "", fmt.Errorf("writing rules/platform.md: %w", err)
+	}
+	platform.PrintSuccess(out, "Created .claude/rules/platform.md")
+	return rulesPath, nil
+}
+
+// setupPackageInstructions writes a CLAUDE.md scaffold into each workspace
+// member that does not have one (or always, with force). Packages get no .claude
+// directory of their own: Claude Code loads a package's CLAUDE.md when it works
+// in that package, and the root agents, skills, and hooks apply everywhere.
+// The scaffolds are generated from projectDir and written to the same paths
+// under destDir. Returns the paths written.
+func setupPackageInstructions(projectDir, destDir string, ws *platform.Workspace, force bool) ([]string, error) {
+	var written []string
+	out := platform.Stdout()
+	for _, m := range ws.Members {
+		pkgDir := filepath.Join(projectDir, filepath.FromSlash(m))
+		path := filepath.Join(destDir, filepath.FromSlash(m), "CLAUDE.md")
+		if !force && (platform.FileExists(path) || platform.FileExists(filepath.Join(pkgDir, ".claude", "CLAUDE.md"))) {
+			platform.PrintWarningLine(out, fmt.Sprintf("Skipping (exists): %s/CLAUDE.md", m))
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(platform.GeneratePackageScaffold(projectDir, pkgDir)), 0644)
+		}
+		if err != nil {
+			return written, fmt.Errorf("writing %s/CLAUDE.md: %w", m, err)
+		}
+		platform.PrintSuccess(out, fmt.Sprintf("Created %s/CLAUDE.md", m))
+		written = append(written, path)
 	}
-	platform.PrintSuccess(os.Stdout, "Created .claude/rules/platform.md")
-	return rulesPath
+	return written, nil
 }
 
-func setupGitignore(claudeDir string) {
+func setupGitignore(claudeDir string) error {
 	gitignorePath := filepath.Join(claudeDir, ".gitignore")
 	existed := platform.FileExists(gitignorePath)
 
 	// If existing file already has a deny-all pattern (bare "*"), it's more
 	// restrictive than our template — no need to append specific entries.
 	if existed && platform.HasDenyAllPattern(gitignorePath) {
-		return
+		return nil
 	}
 
 	// Read required entries from the embedded template
 	data, err := platform.ReadAsset(".claude/.gitignore")
 	if err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error reading embedded .claude/.gitignore: %v", err))
-		return
+		return fmt.Errorf("reading embedded .claude/.gitignore: %w", err)
 	}
 
 	modified, err := platform.EnsureGitignoreEntries(gitignorePath, string(data))
 	if err != nil {
-		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error writing .claude/.gitignore: %v", err))
-		return
+		return fmt.Errorf("writing .claude/.gitignore: %w", err)
 	}
+	out := platform.Stdout()
 	if modified {
 		if existed {
-			platform.PrintSuccess(os.Stdout, "Updated .claude/.gitignore")
+			platform.PrintSuccess(out, "Updated .claude/.gitignore")
 		} else {
-			platform.PrintSuccess(os.Stdout, "Created .claude/.gitignore")
+			platform.PrintSuccess(out, "Created .claude/.gitignore")
 		}
 	}
+	return nil
 }
 
 // enrichSkipReason returns a non-empty message if CLAUDE.md enrichment should be
@@ -375,12 +804,3 @@ func enrichSkipReason() string {
 	}
 	return ""
 }
-
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
-}
@@ -7,34 +7,10 @@ import (
 	"testing"
 	"testing/fstest"
 
+	"github.com/lamchakchan/claude-workspace/internal/manifest"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
-func TestContains(t *testing.T) {
-	tests := []struct {
-		name  string
-		slice []string
-		item  string
-		want  bool
-	}{
-		{"found", []string{"a", "b", "c"}, "b", true},
-		{"not found", []string{"a", "b", "c"}, "d", false},
-		{"empty slice", []string{}, "a", false},
-		{"nil slice", nil, "a", false},
-		{"first element", []string{"a", "b"}, "a", true},
-		{"last element", []string{"a", "b"}, "b", true},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := contains(tt.slice, tt.item)
-			if got != tt.want {
-				t.Errorf("contains(%v, %q) = %v, want %v", tt.slice, tt.item, got, tt.want)
-			}
-		})
-	}
-}
-
 const testGitignoreTemplate = "settings.local.json\nCLAUDE.local.md\nagent-memory-local/\nMEMORY.md\n*.jsonl\naudits/\nplans/*.md\n!plans/.gitkeep\n!*.example\n"
 
 func setupMockFS(claudeGitignoreContent string) func() {
@@ -54,7 +30,9 @@ func TestSetupGitignore_CreatesFromTemplate(t *testing.T) {
 	claudeDir := filepath.Join(dir, ".claude")
 	_ = os.MkdirAll(claudeDir, 0755)
 
-	setupGitignore(claudeDir)
+	if err := setupGitignore(claudeDir); err != nil {
+		t.Fatal(err)
+	}
 
 	content, err := os.ReadFile(filepath.Join(claudeDir, ".gitignore"))
 	if err != nil {
@@ -82,7 +60,9 @@ func TestSetupGitignore_UpdatesExisting(t *testing.T) {
 	existing := "# Personal settings\nsettings.local.json\nCLAUDE.local.md\nagent-memory-local/\n!*.example\n"
 	_ = os.WriteFile(filepath.Join(claudeDir, ".gitignore"), []byte(existing), 0644)
 
-	setupGitignore(claudeDir)
+	if err := setupGitignore(claudeDir); err != nil {
+		t.Fatal(err)
+	}
 
 	content, _ := os.ReadFile(filepath.Join(claudeDir, ".gitignore"))
 	s := string(content)
@@ -114,7 +94,10 @@ func TestSetupProjectInstructions_NoCLAUDEMd(t *testing.T) {
 	_ = os.MkdirAll(filepath.Join(claudeDir, "rules"), 0755)
 
 	// No existing CLAUDE.md — should write scaffold to CLAUDE.md
-	got := setupProjectInstructions(dir, claudeDir, false)
+	got, err := setupProjectInstructions(dir, claudeDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if got != filepath.Join(claudeDir, "CLAUDE.md") {
 		t.Errorf("target = %q, want CLAUDE.md path", got)
@@ -147,7 +130,10 @@ func TestSetupProjectInstructions_ExistingCLAUDEMd(t *testing.T) {
 	existing := "# My Custom Instructions"
 	_ = os.WriteFile(filepath.Join(claudeDir, "CLAUDE.md"), []byte(existing), 0644)
 
-	got := setupProjectInstructions(dir, claudeDir, false)
+	got, err := setupProjectInstructions(dir, claudeDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Should target rules/platform.md
 	if got != filepath.Join(claudeDir, "rules", "platform.md") {
@@ -182,7 +168,10 @@ func TestSetupProjectInstructions_Force(t *testing.T) {
 	// Write existing CLAUDE.md
 	_ = os.WriteFile(filepath.Join(claudeDir, "CLAUDE.md"), []byte("# Old"), 0644)
 
-	got := setupProjectInstructions(dir, claudeDir, true)
+	got, err := setupProjectInstructions(dir, claudeDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// --force should always write to CLAUDE.md
 	if got != filepath.Join(claudeDir, "CLAUDE.md") {
@@ -194,6 +183,39 @@ func TestSetupProjectInstructions_Force(t *testing.T) {
 	}
 }
 
+func TestSetupPackageInstructions(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"pnpm-workspace.yaml":            "packages:\n  - packages/*\n",
+		"packages/ui/package.json":       `{"name":"@acme/ui"}`,
+		"packages/api/package.json":      `{"name":"@acme/api"}`,
+		"packages/api/.claude/CLAUDE.md": "# Existing\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte(content), 0644)
+	}
+	ws := platform.DetectWorkspace(dir)
+
+	written, err := setupPackageInstructions(dir, dir, ws, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "packages", "ui", "CLAUDE.md")}; len(written) != 1 || written[0] != want[0] {
+		t.Fatalf("written = %v, want %v", written, want)
+	}
+	if platform.FileExists(filepath.Join(dir, "packages", "api", "CLAUDE.md")) {
+		t.Error("package with its own .claude/CLAUDE.md should be skipped")
+	}
+	if platform.FileExists(filepath.Join(dir, "packages", "ui", ".claude")) {
+		t.Error("packages should not get a .claude directory")
+	}
+
+	if written, _ := setupPackageInstructions(dir, dir, ws, true); len(written) != 2 {
+		t.Errorf("with force, written = %v, want both packages", written)
+	}
+}
+
 func TestSetupGitignore_SkipsDenyAll(t *testing.T) {
 	templateContent := testGitignoreTemplate
 	restore := setupMockFS(templateContent)
@@ -207,10 +229,85 @@ func TestSetupGitignore_SkipsDenyAll(t *testing.T) {
 	denyAll := "*\n!.gitignore\n!CLAUDE.md\n"
 	_ = os.WriteFile(filepath.Join(claudeDir, ".gitignore"), []byte(denyAll), 0644)
 
-	setupGitignore(claudeDir)
+	if err := setupGitignore(claudeDir); err != nil {
+		t.Fatal(err)
+	}
 
 	content, _ := os.ReadFile(filepath.Join(claudeDir, ".gitignore"))
 	if string(content) != denyAll {
 		t.Error("should not modify a deny-all .gitignore")
 	}
 }
+
+func TestAssetName(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want string
+	}{
+		{"planner.md", "planner"},
+		{"auto-format.sh", "auto-format"},
+		{filepath.Join("onboarding", "SKILL.md"), "onboarding"},
+	}
+	for _, tt := range tests {
+		if got := assetName(tt.rel); got != tt.want {
+			t.Errorf("assetName(%q) = %q, want %q", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestCopyFromEmbed_ManifestFilter(t *testing.T) {
+	oldFS := platform.FS
+	platform.FS = fstest.MapFS{
+		".claude/agents/planner.md":  &fstest.MapFile{Data: []byte("# Planner")},
+		".claude/agents/explorer.md": &fstest.MapFile{Data: []byte("# Explorer")},
+	}
+	defer func() { platform.FS = oldFS }()
+
+	m, err := manifest.Parse([]byte("agents: [planner]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := copyFromEmbed(".claude/agents", dir, false, includeFunc(m, manifest.KindAgents)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !platform.FileExists(filepath.Join(dir, "planner.md")) {
+		t.Error("declared agent should be copied")
+	}
+	if platform.FileExists(filepath.Join(dir, "explorer.md")) {
+		t.Error("undeclared agent should not be copied")
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantTarget string
+		wantOpts   options
+		wantErr    bool
+	}{
+		{[]string{"/p", "--profile", "backend"}, "/p", options{profile: "backend"}, false},
+		{[]string{"/p", "--profile=minimal"}, "/p", options{profile: "minimal"}, false},
+		{[]string{"/p"}, "/p", options{}, false},
+		{[]string{"--list-profiles"}, "", options{listProfiles: true}, false},
+		{[]string{"--symlink", "--no-enrich", "/p", "--symlink=false"}, "/p", options{noEnrich: true}, false},
+		{[]string{"/p", "--governance", "--owners=@org/platform", "--template", "gh:org/repo", "--verify-signature"}, "/p",
+			options{governance: true, owners: "@org/platform", template: "gh:org/repo", verifySignature: true}, false},
+		{[]string{"/p", "--hardlink"}, "/p", options{hardlink: true}, false},
+		{[]string{"/p", "--profile"}, "", options{}, true},
+		{[]string{"/p", "--sym-link"}, "", options{}, true},
+		{[]string{"/p", "/q"}, "", options{}, true},
+	}
+	for _, tt := range tests {
+		target, opts, err := parseArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (target != tt.wantTarget || opts != tt.wantOpts) {
+			t.Errorf("parseArgs(%q) = %q, %+v; want %q, %+v", tt.args, target, opts, tt.wantTarget, tt.wantOpts)
+		}
+	}
+}
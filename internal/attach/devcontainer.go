@@ -0,0 +1,262 @@
+package attach
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// devcontainerImage is the image of a devcontainer.json that attach creates.
+const devcontainerImage = "mcr.microsoft.com/devcontainers/base:ubuntu"
+
+// devcontainerPostCreate installs claude-workspace in the container and runs
+// setup, which installs the Claude CLI and writes the platform's user-level
+// config. The project's .claude directory comes with the workspace mount.
+const devcontainerPostCreate = "curl -fsSL https://raw.githubusercontent.com/lamchakchan/claude-workspace/main/install.sh | bash" +
+	" && claude-workspace setup --non-interactive --api-key-env ANTHROPIC_API_KEY"
+
+// devcontainerCommandKey names the claude-workspace entry when it is added to
+// an object-form postCreateCommand, whose entries run in parallel.
+const devcontainerCommandKey = "claude-workspace"
+
+// envRef matches a ${VAR} or ${VAR:-default} reference in .mcp.json.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// devcontainerPath returns the devcontainer.json attach updates: the existing
+// .devcontainer/devcontainer.json or .devcontainer.json, or else
+// .devcontainer/devcontainer.json.
+func devcontainerPath(projectDir string) string {
+	for _, rel := range []string{".devcontainer/devcontainer.json", ".devcontainer.json"} {
+		if path := filepath.Join(projectDir, filepath.FromSlash(rel)); platform.FileExists(path) {
+			return path
+		}
+	}
+	return filepath.Join(projectDir, ".devcontainer", "devcontainer.json")
+}
+
+// setupDevcontainer creates or updates the project's devcontainer.json so the
+// container installs claude-workspace and the Claude CLI on creation and
+// receives the API key and the variables .mcp.json references from the host.
+// The files are read from and written to destDir, a tree laid out like
+// projectDir; the container is named after projectDir.
+func setupDevcontainer(projectDir, destDir string) error {
+	out := platform.Stdout()
+	path := devcontainerPath(destDir)
+	rel, _ := filepath.Rel(destDir, path)
+
+	before, config, hadComments, err := readDevcontainer(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", rel, err)
+	}
+	mcpData, _ := os.ReadFile(filepath.Join(destDir, ".mcp.json"))
+	after, changed, err := devcontainerConfig(config, filepath.Base(projectDir), devcontainerEnv(mcpData))
+	if err != nil {
+		return fmt.Errorf("updating %s: %w", rel, err)
+	}
+	if !changed {
+		fmt.Fprintf(out, "  %s already installs claude-workspace.\n", rel)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(rel), err)
+	}
+	data, err := marshalDevcontainer(after)
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", rel, err)
+	}
+	if before == nil {
+		platform.PrintSuccess(out, "Created "+rel)
+	} else {
+		platform.PrintSuccess(out, "Updated "+rel)
+		if hadComments {
+			platform.PrintWarningLine(out, fmt.Sprintf("Comments in %s were not kept", rel))
+		}
+	}
+	platform.PrintManual(out, "Rebuild the container to install claude-workspace and the Claude CLI in it")
+	return nil
+}
+
+// readDevcontainer reads a devcontainer.json, which may contain comments and
+// trailing commas. It returns nil data and an empty config if the file does
+// not exist.
+func readDevcontainer(path string) (data []byte, config map[string]interface{}, hadComments bool, err error) {
+	data, err = os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, map[string]interface{}{}, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	clean, hadComments := stripJSONC(data)
+	if err := json.Unmarshal(clean, &config); err != nil {
+		return nil, nil, false, fmt.Errorf("parsing: %w", err)
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	return data, config, hadComments, nil
+}
+
+// devcontainerEnv returns the variables to pass from the host: the API key,
+// and every variable .mcp.json references without a default.
+func devcontainerEnv(mcpData []byte) []string {
+	names := map[string]bool{"ANTHROPIC_API_KEY": true}
+	for _, m := range envRef.FindAllStringSubmatch(string(mcpData), -1) {
+		if m[2] == "" && m[1] != "CLAUDE_PROJECT_DIR" {
+			names[m[1]] = true
+		}
+	}
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// devcontainerConfig returns config with the claude-workspace postCreateCommand
+// and a containerEnv placeholder, "${localEnv:NAME}", for each of env. Existing
+// entries are kept: a postCreateCommand that does not mention claude-workspace
+// is run first, and variables already in containerEnv are not replaced. name
+// and an image are only set on a new, empty config. It reports whether
+// anything changed.
+func devcontainerConfig(config map[string]interface{}, name string, env []string) (map[string]interface{}, bool, error) {
+	result := make(map[string]interface{}, len(config)+3)
+	for k, v := range config {
+		result[k] = v
+	}
+	changed := false
+	if len(config) == 0 {
+		result["name"] = name
+		result["image"] = devcontainerImage
+		changed = true
+	}
+
+	switch cmd := result["postCreateCommand"].(type) {
+	case nil:
+		result["postCreateCommand"] = devcontainerPostCreate
+		changed = true
+	case string:
+		if !strings.Contains(cmd, "claude-workspace") {
+			result["postCreateCommand"] = cmd + " && " + devcontainerPostCreate
+			changed = true
+		}
+	case []interface{}:
+		if !mentionsWorkspace(cmd) {
+			result["postCreateCommand"] = map[string]interface{}{"project": cmd, devcontainerCommandKey: devcontainerPostCreate}
+			changed = true
+		}
+	case map[string]interface{}:
+		if !mentionsWorkspace(cmd) {
+			commands := make(map[string]interface{}, len(cmd)+1)
+			for k, v := range cmd {
+				commands[k] = v
+			}
+			commands[devcontainerCommandKey] = devcontainerPostCreate
+			result["postCreateCommand"] = commands
+			changed = true
+		}
+	default:
+		return nil, false, fmt.Errorf("postCreateCommand must be a string, array, or object")
+	}
+
+	containerEnv := map[string]interface{}{}
+	switch existing := result["containerEnv"].(type) {
+	case nil:
+	case map[string]interface{}:
+		for k, v := range existing {
+			containerEnv[k] = v
+		}
+	default:
+		return nil, false, fmt.Errorf("containerEnv must be an object")
+	}
+	for _, name := range env {
+		if _, ok := containerEnv[name]; !ok {
+			containerEnv[name] = "${localEnv:" + name + "}"
+			changed = true
+		}
+	}
+	result["containerEnv"] = containerEnv
+	return result, changed, nil
+}
+
+// marshalDevcontainer formats a devcontainer.json as indented JSON, leaving
+// the "&&" in shell commands unescaped.
+func marshalDevcontainer(config map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mentionsWorkspace reports whether a postCreateCommand in array or object
+// form already runs claude-workspace.
+func mentionsWorkspace(cmd interface{}) bool {
+	data, _ := json.Marshal(cmd)
+	return bytes.Contains(data, []byte("claude-workspace"))
+}
+
+// stripJSONC removes // and /* */ comments outside of strings, and commas
+// before a closing bracket, so that a JSON-with-comments file such as
+// devcontainer.json can be parsed. It reports whether there were comments.
+func stripJSONC(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	hadComments := false
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			hadComments = true
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			hadComments = true
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += end + 3
+			}
+		case c == '}' || c == ']':
+			trimmed := bytes.TrimRight(out, " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				out = append(trimmed[:len(trimmed)-1], out[len(trimmed):]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, hadComments
+}
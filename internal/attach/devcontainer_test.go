@@ -0,0 +1,164 @@
+package attach
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestStripJSONC(t *testing.T) {
+	in := `{
+  // The container name
+  "name": "app", /* inline */
+  "url": "http://example.com/a//b",
+  "quote": "say \"hi\" // not a comment",
+  "ports": [3000, 8080,],
+}
+`
+	out, hadComments := stripJSONC([]byte(in))
+	if !hadComments {
+		t.Error("hadComments = false, want true")
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("stripped output is not JSON: %v\n%s", err, out)
+	}
+	want := map[string]interface{}{
+		"name":  "app",
+		"url":   "http://example.com/a//b",
+		"quote": `say "hi" // not a comment`,
+		"ports": []interface{}{float64(3000), float64(8080)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, hadComments := stripJSONC([]byte(`{"a": 1,}`)); hadComments {
+		t.Error("trailing comma alone should not count as a comment")
+	}
+}
+
+func TestDevcontainerEnv(t *testing.T) {
+	mcp := `{"mcpServers": {
+  "fs": {"args": ["${CLAUDE_PROJECT_DIR:-.}"]},
+  "search": {"env": {"BRAVE_API_KEY": "${BRAVE_API_KEY}"}},
+  "api": {"url": "${API_HOST:-https://api.example.com}", "headers": {"Authorization": "Bearer ${API_TOKEN}"}}
+}}`
+	got := devcontainerEnv([]byte(mcp))
+	want := []string{"ANTHROPIC_API_KEY", "API_TOKEN", "BRAVE_API_KEY"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("devcontainerEnv() = %v, want %v", got, want)
+	}
+}
+
+func TestDevcontainerConfig(t *testing.T) {
+	env := []string{"ANTHROPIC_API_KEY", "BRAVE_API_KEY"}
+
+	t.Run("new", func(t *testing.T) {
+		got, changed, err := devcontainerConfig(map[string]interface{}{}, "app", env)
+		if err != nil || !changed {
+			t.Fatalf("devcontainerConfig() = %v, %v", changed, err)
+		}
+		if got["name"] != "app" || got["image"] != devcontainerImage || got["postCreateCommand"] != devcontainerPostCreate {
+			t.Errorf("new config = %v", got)
+		}
+		wantEnv := map[string]interface{}{
+			"ANTHROPIC_API_KEY": "${localEnv:ANTHROPIC_API_KEY}",
+			"BRAVE_API_KEY":     "${localEnv:BRAVE_API_KEY}",
+		}
+		if !reflect.DeepEqual(got["containerEnv"], wantEnv) {
+			t.Errorf("containerEnv = %v, want %v", got["containerEnv"], wantEnv)
+		}
+	})
+
+	t.Run("existing string command", func(t *testing.T) {
+		config := map[string]interface{}{
+			"build":             map[string]interface{}{"dockerfile": "Dockerfile"},
+			"postCreateCommand": "npm ci",
+			"containerEnv":      map[string]interface{}{"ANTHROPIC_API_KEY": "${localEnv:MY_KEY}"},
+		}
+		got, changed, err := devcontainerConfig(config, "app", env)
+		if err != nil || !changed {
+			t.Fatalf("devcontainerConfig() = %v, %v", changed, err)
+		}
+		if _, ok := got["image"]; ok {
+			t.Error("image should not be added to a config that has one")
+		}
+		if got["postCreateCommand"] != "npm ci && "+devcontainerPostCreate {
+			t.Errorf("postCreateCommand = %v", got["postCreateCommand"])
+		}
+		containerEnv := got["containerEnv"].(map[string]interface{})
+		if containerEnv["ANTHROPIC_API_KEY"] != "${localEnv:MY_KEY}" || containerEnv["BRAVE_API_KEY"] != "${localEnv:BRAVE_API_KEY}" {
+			t.Errorf("containerEnv = %v", containerEnv)
+		}
+		if config["postCreateCommand"] != "npm ci" {
+			t.Error("input config was modified")
+		}
+
+		if _, changed, _ := devcontainerConfig(got, "app", env); changed {
+			t.Error("second run should change nothing")
+		}
+	})
+
+	t.Run("array command", func(t *testing.T) {
+		config := map[string]interface{}{"image": "x", "postCreateCommand": []interface{}{"make", "deps"}}
+		got, _, err := devcontainerConfig(config, "app", env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{"project": []interface{}{"make", "deps"}, devcontainerCommandKey: devcontainerPostCreate}
+		if !reflect.DeepEqual(got["postCreateCommand"], want) {
+			t.Errorf("postCreateCommand = %v, want %v", got["postCreateCommand"], want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, _, err := devcontainerConfig(map[string]interface{}{"containerEnv": "x"}, "app", env); err == nil {
+			t.Error("expected an error for a non-object containerEnv")
+		}
+	})
+}
+
+func TestSetupDevcontainer(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".mcp.json"), `{"mcpServers": {"s": {"env": {"TOKEN": "${GH_TOKEN}"}}}}`)
+	writeFile(t, filepath.Join(dir, ".devcontainer.json"), "{\n  // keep me\n  \"image\": \"node:20\",\n}\n")
+
+	if err := setupDevcontainer(dir, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if platform.FileExists(filepath.Join(dir, ".devcontainer", "devcontainer.json")) {
+		t.Error("an existing .devcontainer.json should be updated in place")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".devcontainer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("devcontainer.json is not JSON: %v", err)
+	}
+	if got["image"] != "node:20" || got["postCreateCommand"] != devcontainerPostCreate {
+		t.Errorf("devcontainer.json = %v", got)
+	}
+	if env := got["containerEnv"].(map[string]interface{}); env["GH_TOKEN"] != "${localEnv:GH_TOKEN}" {
+		t.Errorf("containerEnv = %v", env)
+	}
+}
+
+func TestSetupDevcontainer_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".devcontainer.json"), "not json")
+
+	if err := setupDevcontainer(dir, dir); err == nil {
+		t.Error("expected an error for an unreadable devcontainer.json")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".devcontainer.json")); string(data) != "not json" {
+		t.Errorf("devcontainer.json = %q, want it left alone", data)
+	}
+}
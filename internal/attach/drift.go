@@ -0,0 +1,568 @@
+package attach
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/manifest"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/setup"
+	"github.com/lamchakchan/claude-workspace/internal/templates"
+)
+
+// LockFile records, relative to the project directory, what attach wrote. It
+// is committed with the project so that teammates share the baseline that
+// --check and --reconcile compare against.
+const LockFile = ".claude/.claude-workspace-lock.json"
+
+// Drift statuses of a tracked project file.
+const (
+	DriftCurrent  = "current"  // matches the template
+	DriftStale    = "stale"    // unchanged since attach; the template has changed
+	DriftModified = "modified" // edited locally since attach
+	DriftMissing  = "missing"  // in the template but not in the project
+	DriftObsolete = "obsolete" // unchanged since attach; no longer in the template
+)
+
+// Lock is the content of LockFile.
+type Lock struct {
+	Version  string `json:"version"`
+	Profile  string `json:"profile,omitempty"`
+	Template string `json:"template,omitempty"`
+	Revision string `json:"templateRevision,omitempty"`
+	Symlink  bool   `json:"symlink,omitempty"`
+	Hardlink bool   `json:"hardlink,omitempty"`
+	// Pinned holds the project's assets at Version: attach leaves them alone
+	// under any other release until it is run with --upgrade-assets.
+	Pinned     bool   `json:"pinned,omitempty"`
+	AttachedAt string `json:"attachedAt"`
+	// Files maps each asset path to the sha256 of the content attach wrote.
+	Files map[string]string `json:"files"`
+	// Base holds the template content of each mergeable file as of the last
+	// attach, the base of the three-way merge that updates it.
+	Base map[string]json.RawMessage `json:"base,omitempty"`
+}
+
+// mergeable lists the JSON files that attach updates with a three-way merge
+// instead of overwriting them.
+var mergeable = map[string]bool{".claude/settings.json": true, ".mcp.json": true}
+
+// base returns the recorded merge base for path, or nil.
+func (l *Lock) base(path string) []byte {
+	if l == nil || len(l.Base[path]) == 0 {
+		return nil
+	}
+	return l.Base[path]
+}
+
+// Drift is the status of one tracked file.
+type Drift struct {
+	Path   string
+	Status string
+	// Upstream is set on a modified file whose template has also changed
+	// since attach, so updating it means merging.
+	Upstream bool
+	// Merge is set when --reconcile can update the file with a three-way merge.
+	Merge bool
+}
+
+// ReadLock reads the project's lock file. It returns nil and no error when
+// the project has none (for example, it was attached by an older release).
+func ReadLock(projectDir string) (*Lock, error) {
+	path := filepath.Join(projectDir, filepath.FromSlash(LockFile))
+	if !platform.FileExists(path) {
+		return nil, nil
+	}
+	var lock Lock
+	if err := platform.ReadJSONFile(path, &lock); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", LockFile, err)
+	}
+	return &lock, nil
+}
+
+// newLock returns a lock describing an attach with the given template.
+func newLock(version string, m *manifest.Manifest, tmpl *templates.Template, symlink bool) *Lock {
+	lock := &Lock{Version: version, Symlink: symlink, Files: map[string]string{}, Base: map[string]json.RawMessage{}}
+	if m != nil {
+		lock.Profile = m.Profile
+	}
+	if tmpl != nil {
+		lock.Template = tmpl.Source.String()
+		lock.Revision = tmpl.Revision
+	}
+	return lock
+}
+
+// expectedAssets returns the content attach writes for every tracked file,
+// keyed by slash-separated path relative to the project. CLAUDE.md is not
+// tracked: it is generated from the project and usually enriched.
+func expectedAssets(m *manifest.Manifest) (map[string][]byte, error) {
+	expected := map[string][]byte{}
+	for kind, dir := range assetDirs {
+		include := includeFunc(m, kind)
+		err := platform.WalkAssets(dir, func(path string, d fs.DirEntry) error {
+			if d.IsDir() || !include(strings.TrimPrefix(path, dir+"/")) {
+				return nil
+			}
+			data, err := platform.ReadAsset(path)
+			if err != nil {
+				return err
+			}
+			expected[path] = data
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	for _, path := range []string{".claude/settings.json", ".claude/settings.local.json.example", ".mcp.json", ".claude/rules/platform.md"} {
+		data, err := platform.ReadAsset(path)
+		if err != nil {
+			continue
+		}
+		switch path {
+		case ".claude/settings.json":
+			data, err = m.RenderSettings(data)
+		case ".mcp.json":
+			if m.Declares(manifest.KindMCPServers) {
+				data, _, err = m.RenderMcpConfig()
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", path, err)
+		}
+		expected[path] = data
+	}
+	return expected, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// projectHash returns the sha256 of a project file. A symlink into the
+// attach --symlink asset cache hashes as want, since it always serves the
+// cached template; any other symlink hashes as its target path so that it
+// counts as a local change. ok is false when the file does not exist.
+func projectHash(projectDir, path, cacheDir, want string) (sum string, ok bool) {
+	dest := filepath.Join(projectDir, filepath.FromSlash(path))
+	info, err := os.Lstat(dest)
+	if err != nil {
+		return "", false
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, _ := os.Readlink(dest)
+		if cacheDir != "" && filepath.Clean(target) == filepath.Join(cacheDir, filepath.FromSlash(path)) {
+			return want, true
+		}
+		return "symlink:" + target, true
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		return "", true
+	}
+	return sha256Hex(data), true
+}
+
+// checkDrift classifies every file in expected, and every file the lock
+// records that the template no longer has. Without a lock, a file that differs
+// from the template is reported as modified, since its baseline is unknown.
+func checkDrift(projectDir string, lock *Lock, expected map[string][]byte, cacheDir string) []Drift {
+	recorded := map[string]string{}
+	if lock != nil {
+		recorded = lock.Files
+	}
+	var drift []Drift
+	for path, data := range expected {
+		want := sha256Hex(data)
+		have, ok := projectHash(projectDir, path, cacheDir, want)
+		base, tracked := recorded[path]
+		upstream := tracked && base != want
+		mergeBase := lock.base(path)
+		if mergeBase != nil {
+			// A merged file holds local settings, so whether the template
+			// moved on is judged against the template it was merged with.
+			upstream = !sameJSON(mergeBase, data)
+		}
+		d := Drift{Path: path, Merge: mergeBase != nil}
+		switch {
+		case !ok:
+			d.Status = DriftMissing
+		case have == want || (tracked && have == base && !upstream):
+			d.Status = DriftCurrent
+		case tracked && have == base:
+			d.Status = DriftStale
+		default:
+			d.Status = DriftModified
+			d.Upstream = upstream
+		}
+		drift = append(drift, d)
+	}
+	for path, base := range recorded {
+		if _, ok := expected[path]; ok {
+			continue
+		}
+		have, ok := projectHash(projectDir, path, cacheDir, base)
+		switch {
+		case !ok:
+			continue
+		case have == base:
+			drift = append(drift, Drift{Path: path, Status: DriftObsolete})
+		default:
+			drift = append(drift, Drift{Path: path, Status: DriftModified})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Path < drift[j].Path })
+	return drift
+}
+
+// writeLock records the files in expected that the project has unchanged, and
+// keeps the previous record of files that were left alone because they differ.
+// Files already in lock (those attach merged) are left as recorded.
+func writeLock(projectDir string, lock, prev *Lock, expected map[string][]byte, cacheDir string) error {
+	lock.AttachedAt = time.Now().UTC().Format(time.RFC3339)
+	for path, data := range expected {
+		if _, done := lock.Files[path]; done {
+			continue
+		}
+		want := sha256Hex(data)
+		have, ok := projectHash(projectDir, path, cacheDir, want)
+		switch {
+		case ok && have == want:
+			lock.Files[path] = want
+			if mergeable[path] {
+				lock.Base[path] = json.RawMessage(data)
+			}
+		case ok && prev != nil && prev.Files[path] != "":
+			lock.Files[path] = prev.Files[path]
+			if b := prev.base(path); b != nil {
+				lock.Base[path] = b
+			}
+		}
+	}
+	return platform.WriteJSONFile(filepath.Join(projectDir, filepath.FromSlash(LockFile)), lock)
+}
+
+// sameJSON reports whether two JSON documents have the same value. Merge bases
+// are compared this way because the lock file re-indents them.
+func sameJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// writeProjectFile writes data to path in the project. The settings file is
+// checked against the settings schema first and left alone if it would break it.
+func writeProjectFile(projectDir, path string, data []byte, perm os.FileMode) error {
+	dest := filepath.Join(projectDir, filepath.FromSlash(path))
+	if path == ".claude/settings.json" {
+		return platform.WriteSettingsData(dest, data)
+	}
+	return os.WriteFile(dest, data, perm)
+}
+
+// mergeFile three-way merges theirs, the current template content of a
+// mergeable file, into the project copy, with the template content recorded in
+// prev as the base. Values changed on both sides keep the project's value and
+// are reported. The result is recorded in next. merged is false, and nothing
+// is written, when prev has no base for the file.
+func mergeFile(w io.Writer, projectDir, path string, theirs []byte, prev, next *Lock) (merged bool, err error) {
+	result, conflicts, merged, err := mergeResult(projectDir, path, theirs, prev)
+	if !merged || err != nil {
+		return false, err
+	}
+	if err := writeProjectFile(projectDir, path, result, 0644); err != nil {
+		return false, err
+	}
+	next.Files[path] = sha256Hex(result)
+	next.Base[path] = json.RawMessage(theirs)
+
+	platform.PrintSuccess(w, "Merged: "+path)
+	for _, key := range conflicts {
+		platform.PrintWarningLine(w, fmt.Sprintf("Kept your %s in %s; the template also changed it", key, path))
+	}
+	return true, nil
+}
+
+// mergeResult returns the content mergeFile would write for path and the keys
+// that changed on both sides, without writing anything. ok is false when prev
+// has no base for the file.
+func mergeResult(projectDir, path string, theirs []byte, prev *Lock) (result []byte, conflicts []string, ok bool, err error) {
+	baseData := prev.base(path)
+	if baseData == nil {
+		return nil, nil, false, nil
+	}
+	dest := filepath.Join(projectDir, filepath.FromSlash(path))
+	var base, mine, tmpl map[string]interface{}
+	if err := json.Unmarshal(baseData, &base); err != nil {
+		return nil, nil, false, fmt.Errorf("parsing recorded base of %s: %w", path, err)
+	}
+	if err := platform.ReadJSONFile(dest, &mine); err != nil {
+		return nil, nil, false, err
+	}
+	if err := json.Unmarshal(theirs, &tmpl); err != nil {
+		return nil, nil, false, fmt.Errorf("parsing template %s: %w", path, err)
+	}
+
+	if reflect.DeepEqual(base, mine) {
+		// No local changes: take the template as is, keeping its formatting.
+		return theirs, nil, true, nil
+	}
+	merged, conflicts := setup.MergeSettingsThreeWay(base, mine, tmpl)
+	result, err = json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("marshaling JSON: %w", err)
+	}
+	return append(result, '\n'), conflicts, true, nil
+}
+
+// recordAttach writes the lock file after an attach. next already holds the
+// files attach merged and whether the project is pinned.
+func recordAttach(projectDir string, next, prev *Lock, m *manifest.Manifest, cacheDir string) error {
+	expected, err := expectedAssets(m)
+	if err == nil {
+		err = writeLock(projectDir, next, prev, expected, cacheDir)
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", LockFile, err)
+	}
+	out := platform.Stdout()
+	platform.PrintSuccess(out, "Recorded attached files in "+LockFile)
+	if next.Pinned {
+		platform.PrintSuccess(out, fmt.Sprintf("Pinned the project's assets to claude-workspace %s in %s", next.Version, LockFile))
+	}
+	return nil
+}
+
+// Outcomes of a drift check or reconcile, reported as the "outcome" event with
+// --json so that fleet and other scripts need not parse the text.
+const (
+	OutcomeUpToDate   = "up-to-date"
+	OutcomeUpdated    = "updated"
+	OutcomeIncomplete = "incomplete" // some files could not be updated
+	OutcomeDrift      = "drift"
+	OutcomeHeldBack   = "held-back" // pinned to another release
+)
+
+// runDrift implements attach --check and --reconcile. --check reports drift
+// and returns an error when --reconcile would change anything, unless the
+// project is pinned to another release; --reconcile updates stale files,
+// restores missing ones, and removes obsolete ones, leaving locally modified
+// files alone. settings.json and .mcp.json are three-way merged instead, so
+// they also take template changes when edited locally. pin (--pin) pins the
+// reconciled project to version.
+func runDrift(w io.Writer, version, projectDir string, m *manifest.Manifest, tmpl *templates.Template, lock *Lock, reconcile, pin bool, cacheDir string) error {
+	if !platform.FileExists(filepath.Join(projectDir, ".claude")) {
+		return fmt.Errorf("no .claude directory found in %s (is the platform attached?)", projectDir)
+	}
+	expected, err := expectedAssets(m)
+	if err != nil {
+		return err
+	}
+	drift := checkDrift(projectDir, lock, expected, cacheDir)
+
+	title := "Drift Check"
+	if reconcile {
+		title = "Reconcile"
+	}
+	platform.PrintBanner(w, fmt.Sprintf("%s: %s", title, projectDir))
+	fmt.Fprintln(w)
+	if lock == nil {
+		platform.PrintWarningLine(w, fmt.Sprintf("No %s: files that differ from the template are reported as modified.", LockFile))
+		fmt.Fprintln(w, "  Run attach --reconcile once to record a baseline.")
+	} else {
+		platform.PrintInfo(w, fmt.Sprintf("Attached with claude-workspace %s at %s", lock.Version, lock.AttachedAt))
+	}
+	if lock != nil && lock.Pinned {
+		platform.PrintInfo(w, fmt.Sprintf("Assets pinned to claude-workspace %s", lock.Version))
+	}
+	printDrift(w, drift)
+
+	pending := 0
+	for _, d := range drift {
+		if d.Status == DriftStale || d.Status == DriftMissing || d.Status == DriftObsolete || (d.Merge && d.Upstream) {
+			pending++
+		}
+	}
+	if !reconcile {
+		if pending > 0 && lock.HeldBack(version) {
+			// The pin is doing its job: the updates wait for an opt-in.
+			fmt.Fprintf(w, "\n  The project is pinned to claude-workspace %s; these wait for --upgrade-assets.\n", lock.Version)
+			platform.PrintCommand(w, fmt.Sprintf("claude-workspace attach %s --upgrade-assets", projectDir))
+			fmt.Fprintln(w)
+			platform.PrintOutcome(w, OutcomeHeldBack)
+			return nil
+		}
+		if pending > 0 {
+			platform.PrintCommand(w, fmt.Sprintf("claude-workspace attach %s --reconcile", projectDir))
+			fmt.Fprintln(w)
+			platform.PrintOutcome(w, OutcomeDrift)
+			return fmt.Errorf("%d file(s) out of date with the template", pending)
+		}
+		fmt.Fprintln(w)
+		platform.PrintOutcome(w, OutcomeUpToDate)
+		return nil
+	}
+
+	if lock != nil && (lock.Symlink || lock.Hardlink) {
+		if tmpl != nil {
+			err = platform.ExtractForSymlinkTo(cacheDir, shortRevision(tmpl.Revision))
+		} else {
+			cacheDir, err = platform.ExtractForSymlink(version)
+		}
+		if err != nil {
+			return fmt.Errorf("extracting assets for symlink: %w", err)
+		}
+	}
+	platform.PrintSection(w, "Changes")
+	next := newLock(version, m, tmpl, lock != nil && lock.Symlink)
+	next.Hardlink = lock != nil && lock.Hardlink
+	next.Pinned = lock.pinned(pin)
+	changed, failed := 0, 0
+	for _, d := range drift {
+		if d.Merge && (d.Status == DriftStale || d.Upstream) {
+			if _, err := mergeFile(w, projectDir, d.Path, expected[d.Path], lock, next); err != nil {
+				platform.PrintErrorLine(w, fmt.Sprintf("Error merging %s: %v", d.Path, err))
+				failed++
+				continue
+			}
+			changed++
+			continue
+		}
+		if err := reconcileFile(w, projectDir, d, expected[d.Path], lock, cacheDir); err != nil {
+			platform.PrintErrorLine(w, fmt.Sprintf("Error updating %s: %v", d.Path, err))
+			failed++
+			continue
+		}
+		if d.Status == DriftStale || d.Status == DriftMissing || d.Status == DriftObsolete {
+			changed++
+		}
+	}
+	if changed == 0 && failed == 0 {
+		fmt.Fprintln(w, "  Nothing to update.")
+	}
+	if next.Hardlink {
+		linkAssets(w, cacheDir, projectDir)
+	}
+
+	if err := writeLock(projectDir, next, lock, expected, cacheDir); err != nil {
+		return fmt.Errorf("writing %s: %w", LockFile, err)
+	}
+	if next.Pinned {
+		platform.PrintSuccess(w, fmt.Sprintf("Pinned the project's assets to claude-workspace %s", version))
+	}
+	fmt.Fprintln(w)
+	switch {
+	case failed > 0:
+		platform.PrintOutcome(w, OutcomeIncomplete)
+	case changed == 0:
+		platform.PrintOutcome(w, OutcomeUpToDate)
+	default:
+		platform.PrintOutcome(w, OutcomeUpdated)
+	}
+	return nil
+}
+
+// reconcileFile brings one file in line with the template if it is not
+// modified locally.
+func reconcileFile(w io.Writer, projectDir string, d Drift, data []byte, lock *Lock, cacheDir string) error {
+	dest := filepath.Join(projectDir, filepath.FromSlash(d.Path))
+	switch d.Status {
+	case DriftObsolete:
+		if err := os.Remove(dest); err != nil {
+			return err
+		}
+		platform.PrintSuccess(w, "Removed: "+d.Path)
+	case DriftStale, DriftMissing:
+		if lock != nil && lock.Symlink && isAssetDirPath(d.Path) {
+			if err := platform.SymlinkFile(filepath.Join(cacheDir, filepath.FromSlash(d.Path)), dest); err != nil {
+				return err
+			}
+			platform.PrintSuccess(w, "Linked: "+d.Path)
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		perm := os.FileMode(0644)
+		if filepath.Ext(dest) == ".sh" {
+			perm = 0755
+		}
+		if err := writeProjectFile(projectDir, d.Path, data, perm); err != nil {
+			return err
+		}
+		verb := "Updated"
+		if d.Status == DriftMissing {
+			verb = "Restored"
+		}
+		platform.PrintSuccess(w, verb+": "+d.Path)
+	}
+	return nil
+}
+
+// isAssetDirPath reports whether path is under a directory that attach
+// --symlink links rather than copies.
+func isAssetDirPath(path string) bool {
+	for _, dir := range assetDirs {
+		if strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// printDrift prints the files that are not current, grouped by status, and a
+// one-line summary.
+func printDrift(w io.Writer, drift []Drift) {
+	groups := []struct{ status, title string }{
+		{DriftStale, "Stale (unchanged locally; template updated)"},
+		{DriftMissing, "Missing"},
+		{DriftObsolete, "Obsolete (no longer in the template)"},
+		{DriftModified, "Modified locally (kept)"},
+	}
+	counts := map[string]int{}
+	for _, d := range drift {
+		counts[d.Status]++
+	}
+	for _, g := range groups {
+		if counts[g.status] == 0 {
+			continue
+		}
+		platform.PrintSection(w, g.title)
+		for _, d := range drift {
+			if d.Status != g.status {
+				continue
+			}
+			line := "  " + d.Path
+			switch {
+			case d.Upstream && d.Merge:
+				line += "  (template also changed; --reconcile will merge)"
+			case d.Upstream:
+				line += "  (template also changed; merge by hand)"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	var summary bytes.Buffer
+	for i, status := range []string{DriftCurrent, DriftStale, DriftModified, DriftMissing, DriftObsolete} {
+		if i > 0 {
+			summary.WriteString(", ")
+		}
+		fmt.Fprintf(&summary, "%d %s", counts[status], status)
+	}
+	fmt.Fprintf(w, "\n%s %s\n", platform.Bold("Summary:"), summary.String())
+}
@@ -0,0 +1,251 @@
+package attach
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func useMapFS(t *testing.T, files map[string]string) {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for path, content := range files {
+		fsys[path] = &fstest.MapFile{Data: []byte(content)}
+	}
+	old := platform.FS
+	platform.FS = fsys
+	t.Cleanup(func() { platform.FS = old })
+}
+
+// attachFiles writes the current template's tracked files into projectDir and
+// records them, as attach does.
+func attachFiles(t *testing.T, projectDir string) {
+	t.Helper()
+	expected, err := expectedAssets(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range expected {
+		dest := filepath.Join(projectDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeLock(projectDir, newLock("v1.0.0", nil, nil, false), nil, expected, ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func driftStatuses(t *testing.T, projectDir string, lock *Lock) map[string]Drift {
+	t.Helper()
+	expected, err := expectedAssets(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Drift{}
+	for _, d := range checkDrift(projectDir, lock, expected, "") {
+		got[d.Path] = d
+	}
+	return got
+}
+
+func TestCheckDriftAndReconcile(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{
+		".claude/agents/planner.md":  "planner v1",
+		".claude/agents/explorer.md": "explorer v1",
+		".claude/hooks/guard.sh":     "guard v1",
+		".claude/settings.json":      `{"permissions":{}}`,
+		".mcp.json":                  `{"mcpServers":{}}`,
+	})
+	attachFiles(t, projectDir)
+	lock, err := ReadLock(projectDir)
+	if err != nil || lock == nil || len(lock.Files) != 5 || lock.Version != "v1.0.0" {
+		t.Fatalf("ReadLock = %+v, %v", lock, err)
+	}
+
+	// A new release changes planner and guard, adds reviewer, and drops
+	// .mcp.json. Locally, guard is edited and explorer deleted.
+	useMapFS(t, map[string]string{
+		".claude/agents/planner.md":  "planner v2",
+		".claude/agents/explorer.md": "explorer v1",
+		".claude/agents/reviewer.md": "reviewer v2",
+		".claude/hooks/guard.sh":     "guard v2",
+		".claude/settings.json":      `{"permissions":{}}`,
+	})
+	if err := os.WriteFile(filepath.Join(projectDir, ".claude", "hooks", "guard.sh"), []byte("guard v1 + local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(projectDir, ".claude", "agents", "explorer.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Drift{
+		".claude/agents/planner.md":  {Status: DriftStale},
+		".claude/agents/explorer.md": {Status: DriftMissing},
+		".claude/agents/reviewer.md": {Status: DriftMissing},
+		".claude/hooks/guard.sh":     {Status: DriftModified, Upstream: true},
+		".claude/settings.json":      {Status: DriftCurrent},
+		".mcp.json":                  {Status: DriftObsolete},
+	}
+	got := driftStatuses(t, projectDir, lock)
+	if len(got) != len(want) {
+		t.Errorf("got %d files, want %d: %v", len(got), len(want), got)
+	}
+	for path, w := range want {
+		if got[path].Status != w.Status || got[path].Upstream != w.Upstream {
+			t.Errorf("%s = %+v, want %+v", path, got[path], w)
+		}
+	}
+
+	if err := runDrift(io.Discard, "v2.0.0", projectDir, nil, nil, lock, false, false, ""); err == nil {
+		t.Error("--check should fail when files are out of date")
+	}
+	if err := runDrift(io.Discard, "v2.0.0", projectDir, nil, nil, lock, true, false, ""); err != nil {
+		t.Fatalf("--reconcile: %v", err)
+	}
+
+	for path, content := range map[string]string{
+		".claude/agents/planner.md":  "planner v2",
+		".claude/agents/explorer.md": "explorer v1",
+		".claude/agents/reviewer.md": "reviewer v2",
+		".claude/hooks/guard.sh":     "guard v1 + local",
+	} {
+		data, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(path)))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v; want %q", path, data, err, content)
+		}
+	}
+	if platform.FileExists(filepath.Join(projectDir, ".mcp.json")) {
+		t.Error("obsolete .mcp.json should be removed")
+	}
+
+	lock, err = ReadLock(projectDir)
+	if err != nil || lock.Version != "v2.0.0" {
+		t.Fatalf("ReadLock after reconcile = %+v, %v", lock, err)
+	}
+	if err := runDrift(io.Discard, "v2.0.0", projectDir, nil, nil, lock, false, false, ""); err != nil {
+		t.Errorf("--check after --reconcile: %v", err)
+	}
+	if d := driftStatuses(t, projectDir, lock)[".claude/hooks/guard.sh"]; d.Status != DriftModified || !d.Upstream {
+		t.Errorf("guard.sh after reconcile = %+v; the local edit and its baseline should be kept", d)
+	}
+}
+
+func TestCheckDrift_NoLock(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{
+		".claude/agents/planner.md": "planner v1",
+		".claude/agents/writer.md":  "writer v1",
+	})
+	attachFiles(t, projectDir)
+	if err := os.WriteFile(filepath.Join(projectDir, ".claude", "agents", "writer.md"), []byte("writer v0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a lock, a file that differs cannot be told apart from a local
+	// edit, so it is never overwritten.
+	got := driftStatuses(t, projectDir, nil)
+	if got[".claude/agents/planner.md"].Status != DriftCurrent || got[".claude/agents/writer.md"].Status != DriftModified {
+		t.Errorf("statuses = %v", got)
+	}
+}
+
+func TestProjectHash_Symlink(t *testing.T) {
+	projectDir, cacheDir := t.TempDir(), t.TempDir()
+	path := ".claude/agents/planner.md"
+	cached := filepath.Join(cacheDir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cached, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := platform.SymlinkFile(cached, filepath.Join(projectDir, filepath.FromSlash(path))); err != nil {
+		t.Fatal(err)
+	}
+	if sum, ok := projectHash(projectDir, path, cacheDir, "want"); !ok || sum != "want" {
+		t.Errorf("link into the cache = %q, %v; want it to count as current", sum, ok)
+	}
+	if sum, _ := projectHash(projectDir, path, t.TempDir(), "want"); sum == "want" {
+		t.Error("link outside the cache should count as modified")
+	}
+}
+
+func TestMergeOnForceAndReconcile(t *testing.T) {
+	projectDir := t.TempDir()
+	claudeDir := filepath.Join(projectDir, ".claude")
+	useMapFS(t, map[string]string{
+		".claude/settings.json": `{"permissions": {"allow": ["Read"]}, "model": "sonnet"}`,
+		".mcp.json":             `{"mcpServers": {"fs": {"command": "npx"}}}`,
+	})
+	attachFiles(t, projectDir)
+	lock, _ := ReadLock(projectDir)
+	if lock.base(".claude/settings.json") == nil || lock.base(".mcp.json") == nil {
+		t.Fatalf("lock should record merge bases: %+v", lock.Base)
+	}
+
+	// The project adds an allow rule and an MCP server of its own.
+	settingsPath := filepath.Join(claudeDir, "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{"permissions": {"allow": ["Read", "Bash(make *)"]}, "model": "sonnet"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mcpPath := filepath.Join(projectDir, ".mcp.json")
+	if err := os.WriteFile(mcpPath, []byte(`{"mcpServers": {"fs": {"command": "npx"}, "db": {"command": "pg"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The template changes the model and adds a server.
+	useMapFS(t, map[string]string{
+		".claude/settings.json": `{"permissions": {"allow": ["Read"]}, "model": "opus"}`,
+		".mcp.json":             `{"mcpServers": {"fs": {"command": "npx"}, "git": {"command": "uvx"}}}`,
+	})
+	got := driftStatuses(t, projectDir, lock)
+	if d := got[".claude/settings.json"]; d.Status != DriftModified || !d.Upstream || !d.Merge {
+		t.Errorf("settings.json = %+v, want modified with a pending merge", d)
+	}
+
+	// attach --force merges instead of overwriting.
+	next := newLock("v2", nil, nil, false)
+	if err := setupProjectSettings(claudeDir, true, nil, lock, next); err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]interface{}
+	if err := platform.ReadJSONFile(settingsPath, &settings); err != nil {
+		t.Fatal(err)
+	}
+	allow := settings["permissions"].(map[string]interface{})["allow"].([]interface{})
+	if settings["model"] != "opus" || len(allow) != 2 {
+		t.Errorf("merged settings = %v, want the new model and both allow rules", settings)
+	}
+	if err := recordAttach(projectDir, next, lock, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// --reconcile merges .mcp.json, which attach left alone, and the result
+	// then checks clean.
+	lock, _ = ReadLock(projectDir)
+	if err := runDrift(io.Discard, "v2", projectDir, nil, nil, lock, true, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	var mcp struct {
+		MCPServers map[string]interface{} `json:"mcpServers"`
+	}
+	if err := platform.ReadJSONFile(mcpPath, &mcp); err != nil {
+		t.Fatal(err)
+	}
+	if len(mcp.MCPServers) != 3 {
+		t.Errorf("mcpServers = %v, want fs, db, and git", mcp.MCPServers)
+	}
+	lock, _ = ReadLock(projectDir)
+	if err := runDrift(io.Discard, "v2", projectDir, nil, nil, lock, false, false, ""); err != nil {
+		t.Errorf("--check after merging: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package attach
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// governanceHeader introduces the block attach --governance appends to
+// .gitattributes and CODEOWNERS.
+const governanceHeader = "# Claude Code platform configuration (claude-workspace attach --governance)"
+
+// governanceAttributes are the .gitattributes entries attach --governance
+// adds. The CLAUDE.md scaffold and platform rules are generated, so review
+// tools collapse them; settings and the lock file are JSON that a line-based
+// merge can silently break, so git leaves conflicting edits for a person to
+// resolve instead of merging them.
+var governanceAttributes = []string{
+	".claude/CLAUDE.md linguist-generated=true",
+	".claude/rules/platform.md linguist-generated=true",
+	".claude/settings.json text eol=lf -merge",
+	".mcp.json text eol=lf -merge",
+	LockFile + " linguist-generated=true -merge",
+}
+
+// codeownersPaths are the locations GitHub and GitLab read CODEOWNERS from,
+// in the order they look.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersPattern is the CODEOWNERS pattern assigned to the owners.
+const codeownersPattern = "/.claude/**"
+
+// parseOwners splits an --owners value on commas and spaces. Each owner must
+// be a @user, an @org/team, or an email address.
+func parseOwners(value string) ([]string, error) {
+	owners := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(owners) == 0 {
+		return nil, fmt.Errorf("--owners requires a @user, @org/team, or email address")
+	}
+	for _, o := range owners {
+		if !strings.Contains(o, "@") {
+			return nil, fmt.Errorf("--owners: %q is not a @user, @org/team, or email address", o)
+		}
+	}
+	return owners, nil
+}
+
+// codeownersPath returns the CODEOWNERS file under dir attach updates: the
+// existing one, or else .github/CODEOWNERS.
+func codeownersPath(dir string) string {
+	for _, rel := range codeownersPaths {
+		if path := filepath.Join(dir, filepath.FromSlash(rel)); platform.FileExists(path) {
+			return path
+		}
+	}
+	return filepath.Join(dir, ".github", "CODEOWNERS")
+}
+
+// patterns returns the first field of each entry line in a .gitattributes or
+// CODEOWNERS file, with a leading "/" removed.
+func patterns(content string) map[string]bool {
+	have := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && !strings.HasPrefix(fields[0], "#") {
+			have[strings.TrimPrefix(fields[0], "/")] = true
+		}
+	}
+	return have
+}
+
+// missingAttributes returns the governanceAttributes whose path has no entry
+// in content. Paths already listed keep their attributes.
+func missingAttributes(content string) []string {
+	have := patterns(content)
+	var missing []string
+	for _, entry := range governanceAttributes {
+		if !have[strings.TrimPrefix(strings.Fields(entry)[0], "/")] {
+			missing = append(missing, entry)
+		}
+	}
+	return missing
+}
+
+// missingCodeowners returns the CODEOWNERS line assigning .claude to owners,
+// or nil when content already assigns the directory to someone.
+func missingCodeowners(content string, owners []string) []string {
+	have := patterns(content)
+	for _, p := range []string{".claude/**", ".claude/", ".claude"} {
+		if have[p] {
+			return nil
+		}
+	}
+	return []string{codeownersPattern + " " + strings.Join(owners, " ")}
+}
+
+// appendEntries returns existing with lines added under governanceHeader,
+// separated from what was there by a blank line.
+func appendEntries(existing []byte, lines []string) []byte {
+	var b strings.Builder
+	b.Write(existing)
+	if len(existing) > 0 {
+		if existing[len(existing)-1] != '\n' {
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(governanceHeader + "\n")
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	return []byte(b.String())
+}
+
+// setupGovernance appends the governance entries to .gitattributes and, when
+// owners are given, to CODEOWNERS, under destDir. An existing file is only
+// changed with force or when the user agrees at the prompt read from in; with
+// a nil in (not a terminal) it is left alone.
+func setupGovernance(out io.Writer, in *bufio.Reader, destDir string, owners []string, force bool) error {
+	if err := updateGovernanceFile(out, in, destDir, filepath.Join(destDir, ".gitattributes"), missingAttributes, force); err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		platform.PrintManual(out, "Add --owners @org/team to require the platform team's review of .claude changes in CODEOWNERS")
+		return nil
+	}
+	return updateGovernanceFile(out, in, destDir, codeownersPath(destDir), func(content string) []string {
+		return missingCodeowners(content, owners)
+	}, force)
+}
+
+// updateGovernanceFile appends the entries missing returns for the file at
+// path, asking first when the file exists.
+func updateGovernanceFile(out io.Writer, in *bufio.Reader, destDir, path string, missing func(string) []string, force bool) error {
+	rel, _ := filepath.Rel(destDir, path)
+	rel = filepath.ToSlash(rel)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", rel, err)
+	}
+	lines := missing(string(existing))
+	if len(lines) == 0 {
+		fmt.Fprintf(out, "  %s already has the platform entries.\n", rel)
+		return nil
+	}
+
+	exists := err == nil
+	if exists && !force {
+		if in == nil {
+			platform.PrintWarningLine(out, fmt.Sprintf("Skipping %s (exists; run attach from a terminal to confirm, or with --force)", rel))
+			return nil
+		}
+		platform.PrintPrompt(out, fmt.Sprintf("  Append %d line(s) to %s? [y/N] ", len(lines), rel))
+		answer, _ := in.ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			platform.PrintWarningLine(out, fmt.Sprintf("Skipped %s", rel))
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(rel), err)
+	}
+	if err := os.WriteFile(path, appendEntries(existing, lines), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", rel, err)
+	}
+	if exists {
+		platform.PrintSuccess(out, "Updated "+rel)
+	} else {
+		platform.PrintSuccess(out, "Created "+rel)
+	}
+	return nil
+}
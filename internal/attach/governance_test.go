@@ -0,0 +1,141 @@
+package attach
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestParseOwners(t *testing.T) {
+	got, err := parseOwners("@acme/platform, @alice ops@example.com")
+	want := []string{"@acme/platform", "@alice", "ops@example.com"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("parseOwners() = %q, %v, want %q", got, err, want)
+	}
+	for _, value := range []string{"", " , ", "platform-team"} {
+		if _, err := parseOwners(value); err == nil {
+			t.Errorf("parseOwners(%q): expected error", value)
+		}
+	}
+}
+
+func TestMissingAttributes(t *testing.T) {
+	if got := missingAttributes(""); !reflect.DeepEqual(got, governanceAttributes) {
+		t.Errorf("empty file: got %q", got)
+	}
+	// Paths already listed keep their own attributes.
+	existing := "*.png binary\n/.claude/settings.json merge=union\n# .mcp.json -merge\n"
+	got := missingAttributes(existing)
+	for _, entry := range got {
+		if strings.HasPrefix(entry, ".claude/settings.json") {
+			t.Errorf("settings.json is already listed: %q", got)
+		}
+	}
+	if len(got) != len(governanceAttributes)-1 {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestMissingCodeowners(t *testing.T) {
+	owners := []string{"@acme/platform"}
+	if got := missingCodeowners("* @acme/devs\n", owners); !reflect.DeepEqual(got, []string{"/.claude/** @acme/platform"}) {
+		t.Errorf("got %q", got)
+	}
+	for _, existing := range []string{"/.claude/ @acme/ai\n", ".claude/** @bob\n"} {
+		if got := missingCodeowners(existing, owners); got != nil {
+			t.Errorf("missingCodeowners(%q) = %q, want nil", existing, got)
+		}
+	}
+}
+
+func TestAppendEntries(t *testing.T) {
+	got := string(appendEntries([]byte("* @acme/devs"), []string{"/.claude/** @acme/platform"}))
+	want := "* @acme/devs\n\n" + governanceHeader + "\n/.claude/** @acme/platform\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := string(appendEntries(nil, []string{"a"})); got != governanceHeader+"\na\n" {
+		t.Errorf("new file: got %q", got)
+	}
+}
+
+func TestSetupGovernance(t *testing.T) {
+	owners := []string{"@acme/platform"}
+
+	// New files are created without asking.
+	dir := t.TempDir()
+	if err := setupGovernance(io.Discard, nil, dir, owners, false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".github", "CODEOWNERS"))
+	if !strings.Contains(string(data), "/.claude/** @acme/platform") {
+		t.Errorf("CODEOWNERS = %q", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	if !strings.Contains(string(data), ".claude/CLAUDE.md linguist-generated=true") {
+		t.Errorf(".gitattributes = %q", data)
+	}
+
+	// Existing files are changed only when the user agrees, and never
+	// without a terminal to ask on.
+	dir = t.TempDir()
+	writeFile(t, filepath.Join(dir, ".gitattributes"), "*.png binary\n")
+	writeFile(t, filepath.Join(dir, "CODEOWNERS"), "* @acme/devs\n")
+	if err := setupGovernance(io.Discard, nil, dir, owners, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".gitattributes")); string(data) != "*.png binary\n" {
+		t.Errorf("changed without confirmation: %q", data)
+	}
+	if err := setupGovernance(io.Discard, bufio.NewReader(strings.NewReader("y\nn\n")), dir, owners, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".gitattributes")); !strings.HasPrefix(string(data), "*.png binary\n\n"+governanceHeader) {
+		t.Errorf("confirmed .gitattributes = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "CODEOWNERS")); string(data) != "* @acme/devs\n" {
+		t.Errorf("declined CODEOWNERS = %q", data)
+	}
+	if platform.FileExists(filepath.Join(dir, ".github", "CODEOWNERS")) {
+		t.Error("created .github/CODEOWNERS next to the existing CODEOWNERS")
+	}
+
+	// --force appends without asking, and a second run adds nothing.
+	if err := setupGovernance(io.Discard, nil, dir, owners, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := setupGovernance(io.Discard, nil, dir, owners, true); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "CODEOWNERS"))
+	if strings.Count(string(data), "/.claude/**") != 1 {
+		t.Errorf("forced CODEOWNERS = %q", data)
+	}
+}
+
+func TestPlan_Governance(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{".claude/settings.json": `{}`, ".mcp.json": `{}`, ".claude/.gitignore": "settings.local.json\n"})
+	writeFile(t, filepath.Join(projectDir, ".gitattributes"), "*.png binary\n")
+
+	entries, _, err := plan(projectDir, planOptions{noEnrich: true, governance: true, owners: []string{"@acme/platform"}}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := planActions(entries)
+	if e := got[".gitattributes"]; e.Action != PlanUpdate || !strings.Contains(string(e.After), governanceHeader) {
+		t.Errorf(".gitattributes: %+v", e)
+	}
+	if got[".github/CODEOWNERS"].Action != PlanCreate {
+		t.Errorf(".github/CODEOWNERS: %+v", got[".github/CODEOWNERS"])
+	}
+	if platform.FileExists(filepath.Join(projectDir, ".github")) {
+		t.Error("plan wrote CODEOWNERS")
+	}
+}
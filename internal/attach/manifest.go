@@ -0,0 +1,106 @@
+package attach
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/manifest"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// assetDirs maps manifest asset kinds to their embedded template directories.
+var assetDirs = map[string]string{
+	manifest.KindAgents: ".claude/agents",
+	manifest.KindSkills: ".claude/skills",
+	manifest.KindHooks:  ".claude/hooks",
+}
+
+// assetName returns the manifest name for a file relative to its asset
+// directory: the first path component with any extension removed. For example
+// "planner.md" -> "planner" and "onboarding/SKILL.md" -> "onboarding".
+func assetName(rel string) string {
+	first := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+	return strings.TrimSuffix(first, path.Ext(first))
+}
+
+// includeFunc returns a filter over asset-relative paths for the given kind.
+// A nil manifest (or an undeclared kind) includes everything.
+func includeFunc(m *manifest.Manifest, kind string) func(rel string) bool {
+	return func(rel string) bool {
+		return m.Includes(kind, assetName(rel))
+	}
+}
+
+// availableAssets lists the names that a manifest may reference, keyed by kind.
+func availableAssets() map[string][]string {
+	available := make(map[string][]string, len(assetDirs)+1)
+	for kind, dir := range assetDirs {
+		seen := make(map[string]bool)
+		_ = platform.WalkAssets(dir, func(p string, _ fs.DirEntry) error {
+			name := assetName(strings.TrimPrefix(p, dir+"/"))
+			if !seen[name] {
+				seen[name] = true
+				available[kind] = append(available[kind], name)
+			}
+			return nil
+		})
+	}
+
+	available[manifest.KindMCPServers] = manifest.AvailableMcpServers()
+	return available
+}
+
+// inTemplate wraps include so that only files present in the current template
+// filesystem under dir pass. The shared symlink cache can hold files from other
+// profiles or older releases, which must not be linked into this project.
+func inTemplate(dir string, include func(rel string) bool) func(rel string) bool {
+	return func(rel string) bool {
+		if _, err := fs.Stat(platform.FS, dir+"/"+filepath.ToSlash(rel)); err != nil {
+			return false
+		}
+		return include(rel)
+	}
+}
+
+// useAssetFS points platform.FS at fsys (for example a profile overlay) and
+// returns a function that restores the previous filesystem.
+func useAssetFS(fsys fs.FS) func() {
+	old := platform.FS
+	platform.FS = fsys
+	return func() { platform.FS = old }
+}
+
+// listProfiles prints the embedded template profiles with their descriptions.
+func listProfiles() error {
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Template Profiles")
+	fmt.Fprintln(out)
+
+	names := platform.ListProfiles()
+	if len(names) == 0 {
+		fmt.Fprintln(out, "  No profiles available.")
+		fmt.Fprintln(out)
+		return nil
+	}
+
+	maxName := 0
+	for _, name := range names {
+		if len(name) > maxName {
+			maxName = len(name)
+		}
+	}
+	for _, name := range names {
+		desc := ""
+		if pm, err := manifest.LoadProfile(name); err == nil {
+			desc = pm.Description
+		}
+		fmt.Fprintf(out, "  %-*s  %s\n", maxName, name, desc)
+	}
+	fmt.Fprintln(out)
+	platform.PrintCommand(out, "claude-workspace attach <project-path> --profile <name>")
+	fmt.Fprintln(out)
+	return nil
+}
@@ -0,0 +1,65 @@
+package attach
+
+import (
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+)
+
+const usage = "attach <project-path> [--symlink|--hardlink] [--force] [--no-enrich] [--profile <name>] [--monorepo] [--devcontainer] [--governance [--owners <list>]] [--template <source>] [--pin] [--dry-run] [--check|--reconcile|--upgrade-assets]"
+
+// options holds parsed flags for the attach command.
+type options struct {
+	symlink         bool
+	hardlink        bool
+	force           bool
+	noEnrich        bool
+	profile         string
+	listProfiles    bool
+	monorepo        bool
+	devcontainer    bool
+	governance      bool
+	owners          string // --owners: CODEOWNERS owners, with --governance
+	template        string // --template: git or https tarball template source
+	templateSHA256  string // --template-sha256: checksum the tarball must match
+	verifySignature bool
+	dryRun          bool
+	check           bool
+	reconcile       bool
+	pin             bool
+	upgradeAssets   bool
+}
+
+// parseArgs parses attach command arguments into the project path, "" if
+// none was given, and the flags.
+func parseArgs(args []string) (string, options, error) {
+	var o options
+	f := cli.New("attach", usage)
+	f.BoolVar(&o.symlink, "--symlink", "Use symlinks instead of copying assets")
+	f.BoolVar(&o.hardlink, "--hardlink", "Use reflinks or copies of the shared assets, relinked on upgrade, where symlinks are blocked")
+	f.BoolVar(&o.force, "--force", "Overwrite existing files")
+	f.BoolVar(&o.noEnrich, "--no-enrich", "Skip AI-powered CLAUDE.md enrichment")
+	f.StringVar(&o.profile, "--profile", "name", "Use a template profile (minimal, backend, data-science)")
+	f.BoolVar(&o.listProfiles, "--list-profiles", "List available template profiles")
+	f.BoolVar(&o.monorepo, "--monorepo", "Add a CLAUDE.md to each workspace package")
+	f.BoolVar(&o.devcontainer, "--devcontainer", "Install claude-workspace and Claude CLI in .devcontainer/devcontainer.json")
+	f.BoolVar(&o.governance, "--governance", "Add .gitattributes entries for platform files")
+	f.StringVar(&o.owners, "--owners", "list", "With --governance, assign .claude/** to these owners in CODEOWNERS")
+	f.StringVar(&o.template, "--template", "source", "Use a git repo[@ref] or https tarball as the template")
+	f.StringVar(&o.templateSHA256, "--template-sha256", "sum", "Require the tarball to match this SHA-256")
+	f.BoolVar(&o.verifySignature, "--verify-signature", "Require a valid signature on the git template commit")
+	f.BoolVar(&o.dryRun, "--dry-run", "Show what would change; change nothing")
+	f.BoolVar(&o.check, "--check", "Report files that are stale, modified, or missing vs. the template")
+	f.BoolVar(&o.reconcile, "--reconcile", "Update stale and missing files, keeping local edits")
+	f.BoolVar(&o.pin, "--pin", "Pin the project's assets to this release")
+	f.BoolVar(&o.upgradeAssets, "--upgrade-assets", "Reconcile a pinned project with this release and move the pin")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", o, err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return "", o, err
+	}
+	if len(positional) == 0 {
+		return "", o, nil
+	}
+	return positional[0], o, nil
+}
@@ -0,0 +1,31 @@
+package attach
+
+import "fmt"
+
+// HeldBack reports whether the project is pinned to a release other than
+// version, so attach must not change its assets without --upgrade-assets.
+func (l *Lock) HeldBack(version string) bool {
+	return l != nil && l.Pinned && l.Version != version
+}
+
+// pinned reports whether the project stays pinned after an attach: it was
+// already, or pin (--pin) is set.
+func (l *Lock) pinned(pin bool) bool {
+	return pin || (l != nil && l.Pinned)
+}
+
+// checkPin rejects a pin on a project whose assets are linked from the shared
+// cache: upgrading the binary switches the cache, and linked projects follow
+// it, so nothing would hold their assets at the pinned release. pin is the
+// --pin flag; linked is set when the attach links the assets.
+func checkPin(lock *Lock, pin, linked bool) error {
+	switch {
+	case !linked:
+		return nil
+	case pin:
+		return fmt.Errorf("--pin cannot be combined with --symlink or --hardlink: linked assets follow every upgrade of the shared cache")
+	case lock.pinned(false):
+		return fmt.Errorf("%s pins the project's assets to claude-workspace %s, which linked assets cannot stay at; attach without --symlink or --hardlink, or remove \"pinned\" from %s first", LockFile, lock.Version, LockFile)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package attach
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestLockPinned(t *testing.T) {
+	lock := &Lock{Version: "v1.0.0", Pinned: true}
+	if lock.HeldBack("v1.0.0") || !lock.HeldBack("v2.0.0") {
+		t.Error("a pinned project is held back only under another release")
+	}
+	lock.Pinned = false
+	if lock.HeldBack("v2.0.0") {
+		t.Error("an unpinned project is never held back")
+	}
+	var none *Lock
+	if none.HeldBack("v2.0.0") || none.pinned(false) || !none.pinned(true) {
+		t.Error("a project without a lock file is pinned only by --pin")
+	}
+}
+
+func TestCheckPin(t *testing.T) {
+	pinned := &Lock{Version: "v1.0.0", Pinned: true}
+	tests := []struct {
+		name        string
+		lock        *Lock
+		pin, linked bool
+		want        string
+	}{
+		{"pin, copied", nil, true, false, ""},
+		{"pinned, copied", pinned, false, false, ""},
+		{"linked", nil, false, true, ""},
+		{"pin, linked", nil, true, true, "--pin cannot be combined"},
+		{"pinned, linked", pinned, false, true, "pins the project's assets"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPin(tt.lock, tt.pin, tt.linked)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("checkPin() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDrift_Pinned(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{".claude/agents/planner.md": "planner v1"})
+	attachFiles(t, projectDir)
+	lock, _ := ReadLock(projectDir)
+	lock.Pinned = true
+
+	// Under a new release, the update waits for --upgrade-assets instead of
+	// failing the check.
+	useMapFS(t, map[string]string{".claude/agents/planner.md": "planner v2"})
+	if err := runDrift(io.Discard, "v2.0.0", projectDir, nil, nil, lock, false, false, ""); err != nil {
+		t.Errorf("--check of a pinned project: %v", err)
+	}
+	unpinned := *lock
+	unpinned.Pinned = false
+	if err := runDrift(io.Discard, "v2.0.0", projectDir, nil, nil, &unpinned, false, false, ""); err == nil {
+		t.Error("--check of an unpinned project should fail when files are out of date")
+	}
+
+	// --upgrade-assets reconciles and moves the pin to the new release.
+	if err := runDrift(io.Discard, "v2.0.0", projectDir, nil, nil, lock, true, false, ""); err != nil {
+		t.Fatalf("--upgrade-assets: %v", err)
+	}
+	lock, err := ReadLock(projectDir)
+	if err != nil || lock.Version != "v2.0.0" || !lock.Pinned {
+		t.Fatalf("lock file after upgrade = %+v, %v", lock, err)
+	}
+	if got := lock.Files[".claude/agents/planner.md"]; got != sha256Hex([]byte("planner v2")) {
+		t.Errorf("planner.md hash after upgrade = %q", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join(projectDir, ".claude", "agents", "planner.md")); string(data) != "planner v2" {
+		t.Errorf("planner.md = %q", data)
+	}
+}
+
+func TestRunDrift_Outcome(t *testing.T) {
+	platform.SetOutputMode(platform.OutputJSON)
+	t.Cleanup(func() { platform.SetOutputMode(platform.OutputText) })
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{".claude/agents/planner.md": "planner v1"})
+	attachFiles(t, projectDir)
+	lock, _ := ReadLock(projectDir)
+	useMapFS(t, map[string]string{".claude/agents/planner.md": "planner v2"})
+
+	outcome := func(lock *Lock, reconcile bool) string {
+		t.Helper()
+		var buf bytes.Buffer
+		_ = runDrift(&buf, "v2.0.0", projectDir, nil, nil, lock, reconcile, false, "")
+		var outcome string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var ev platform.Event
+			if json.Unmarshal([]byte(line), &ev) == nil && ev.Event == platform.EventOutcome {
+				outcome = ev.Message
+			}
+		}
+		return outcome
+	}
+	pinned := *lock
+	pinned.Pinned = true
+	if got := outcome(&pinned, false); got != OutcomeHeldBack {
+		t.Errorf("--check of a pinned project: outcome %q, want %q", got, OutcomeHeldBack)
+	}
+	if got := outcome(lock, false); got != OutcomeDrift {
+		t.Errorf("--check: outcome %q, want %q", got, OutcomeDrift)
+	}
+	if got := outcome(lock, true); got != OutcomeUpdated {
+		t.Errorf("--reconcile: outcome %q, want %q", got, OutcomeUpdated)
+	}
+	lock, _ = ReadLock(projectDir)
+	if got := outcome(lock, false); got != OutcomeUpToDate {
+		t.Errorf("--check after --reconcile: outcome %q, want %q", got, OutcomeUpToDate)
+	}
+	if got := outcome(lock, true); got != OutcomeUpToDate {
+		t.Errorf("--reconcile after --reconcile: outcome %q, want %q", got, OutcomeUpToDate)
+	}
+}
@@ -0,0 +1,440 @@
+package attach
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/manifest"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/slashcommands"
+)
+
+// Actions attach --dry-run reports for a file.
+const (
+	PlanCreate    = "create"    // written; does not exist yet
+	PlanOverwrite = "overwrite" // replaced with the template content
+	PlanLink      = "link"      // symlinked into the --symlink asset cache
+	PlanMerge     = "merge"     // three-way merged with the template
+	PlanUpdate    = "update"    // entries appended (.gitignore)
+	PlanSkip      = "skip"      // left alone because it exists
+)
+
+// PlanEntry is what attach would do to one file.
+type PlanEntry struct {
+	Path   string // slash-separated, relative to the project
+	Action string
+	Note   string
+	// Before and After hold the current and resulting content of a settings
+	// or MCP file that would be merged or overwritten, for a diff.
+	Before, After []byte
+}
+
+// planOptions are the attach flags that decide what gets written.
+type planOptions struct {
+	symlink, hardlink, force, noEnrich, monorepo, devcontainer, governance, pin bool
+
+	owners []string // --owners, for the CODEOWNERS entry
+}
+
+// plan returns what attach would do to projectDir, in the order attach does
+// it, and notes on steps that change no file directly, without writing
+// anything. It mirrors the setup functions in attach.go.
+func plan(projectDir string, opts planOptions, m *manifest.Manifest, ws *platform.Workspace, lock *Lock) ([]PlanEntry, []string, error) {
+	p := &planner{projectDir: projectDir, opts: opts}
+
+	for _, kind := range []string{manifest.KindAgents, manifest.KindSkills, manifest.KindHooks} {
+		if err := p.assets(assetDirs[kind], includeFunc(m, kind)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if opts.hardlink {
+		p.notes = append(p.notes, "Agents, skills, and hooks that match the asset cache would then be reflinked or copied from it")
+	}
+	if !platform.FileExists(filepath.Join(projectDir, ".claude", "plans", ".gitkeep")) {
+		p.add(PlanEntry{Path: ".claude/plans/.gitkeep", Action: PlanCreate})
+	}
+
+	settings, err := platform.ReadAsset(".claude/settings.json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading embedded settings: %w", err)
+	}
+	if settings, err = m.RenderSettings(settings); err != nil {
+		return nil, nil, fmt.Errorf("filtering settings hooks: %w", err)
+	}
+	if err := p.mergeable(".claude/settings.json", settings, lock); err != nil {
+		return nil, nil, err
+	}
+	// Like setupProjectSettings, which stops before the example when it
+	// keeps existing settings.
+	if !p.exists(".claude/settings.json") || opts.force {
+		if _, err := platform.ReadAsset(".claude/settings.local.json.example"); err == nil {
+			p.file(".claude/settings.local.json.example", "")
+		}
+	}
+
+	var mcpData []byte
+	var needsCreds []string
+	if m.Declares(manifest.KindMCPServers) {
+		mcpData, needsCreds, err = m.RenderMcpConfig()
+	} else {
+		mcpData, err = platform.ReadAsset(".mcp.json")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading embedded .mcp.json: %w", err)
+	}
+	if err := p.mergeable(".mcp.json", mcpData, lock); err != nil {
+		return nil, nil, err
+	}
+	for _, name := range needsCreds {
+		p.notes = append(p.notes, fmt.Sprintf("MCP server %q needs credentials (see .mcp.json env/headers)", name))
+	}
+
+	if opts.devcontainer {
+		if err := p.devcontainer(mcpData); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	instructions := p.instructions()
+	for _, spec := range slashcommands.Detect(projectDir) {
+		p.file(filepath.ToSlash(spec.Path()), "/"+spec.Name+" runs "+spec.Command)
+	}
+	var packages []string
+	if opts.monorepo && ws != nil {
+		packages = p.packageInstructions(ws)
+	}
+	if opts.governance {
+		if err := p.governance(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !opts.noEnrich && (instructions != "" || len(packages) > 0) {
+		targets := append(packages, instructions)
+		p.notes = append(p.notes, fmt.Sprintf("%s would be enriched by the Claude CLI, if it is installed and signed in", strings.Join(targets, ", ")))
+	}
+
+	if err := p.gitignore(); err != nil {
+		return nil, nil, err
+	}
+	action := PlanCreate
+	if lock != nil {
+		action = PlanOverwrite
+	}
+	note := "records the attached files"
+	if opts.pin {
+		note += " and pins them to this release"
+	}
+	p.add(PlanEntry{Path: LockFile, Action: action, Note: note})
+	return p.entries, p.notes, nil
+}
+
+// planner accumulates plan entries.
+type planner struct {
+	projectDir string
+	opts       planOptions
+	entries    []PlanEntry
+	notes      []string
+}
+
+func (p *planner) add(e PlanEntry) { p.entries = append(p.entries, e) }
+
+func (p *planner) exists(path string) bool {
+	return platform.FileExists(filepath.Join(p.projectDir, filepath.FromSlash(path)))
+}
+
+// file plans a file that attach writes unless it exists, or always with
+// --force.
+func (p *planner) file(path, note string) {
+	switch {
+	case !p.exists(path):
+		p.add(PlanEntry{Path: path, Action: PlanCreate, Note: note})
+	case p.opts.force:
+		p.add(PlanEntry{Path: path, Action: PlanOverwrite, Note: note})
+	default:
+		p.add(PlanEntry{Path: path, Action: PlanSkip, Note: "exists; --force replaces it"})
+	}
+}
+
+// assets plans the agents, skills, or hooks under dir, as copyFromEmbed and
+// copyOrLinkFromDisk write them.
+func (p *planner) assets(dir string, include func(rel string) bool) error {
+	err := platform.WalkAssets(dir, func(path string, d fs.DirEntry) error {
+		if d.IsDir() || !include(strings.TrimPrefix(path, dir+"/")) {
+			return nil
+		}
+		switch {
+		case p.exists(path) && !p.opts.force:
+			p.add(PlanEntry{Path: path, Action: PlanSkip, Note: "exists; --force replaces it"})
+		case p.opts.symlink:
+			note := ""
+			if p.exists(path) {
+				note = "replaces the existing file"
+			}
+			p.add(PlanEntry{Path: path, Action: PlanLink, Note: note})
+		case p.exists(path):
+			note := ""
+			if p.sameContent(path) {
+				note = "same content"
+			}
+			p.add(PlanEntry{Path: path, Action: PlanOverwrite, Note: note})
+		default:
+			p.add(PlanEntry{Path: path, Action: PlanCreate})
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// sameContent reports whether the project file at path matches the template.
+func (p *planner) sameContent(path string) bool {
+	want, err := platform.ReadAsset(path)
+	if err != nil {
+		return false
+	}
+	have, err := os.ReadFile(filepath.Join(p.projectDir, filepath.FromSlash(path)))
+	return err == nil && bytes.Equal(have, want)
+}
+
+// mergeable plans settings.json or .mcp.json, which an existing project keeps
+// unless --force is given; then it is merged when the lock file records a
+// merge base and overwritten otherwise.
+func (p *planner) mergeable(path string, data []byte, lock *Lock) error {
+	if !p.exists(path) {
+		p.add(PlanEntry{Path: path, Action: PlanCreate})
+		return nil
+	}
+	if !p.opts.force {
+		p.add(PlanEntry{Path: path, Action: PlanSkip, Note: "exists; --force merges the template into it"})
+		return nil
+	}
+	before, err := os.ReadFile(filepath.Join(p.projectDir, filepath.FromSlash(path)))
+	if err != nil {
+		return err
+	}
+	result, conflicts, ok, err := mergeResult(p.projectDir, path, data, lock)
+	if err != nil {
+		return fmt.Errorf("merging %s: %w", path, err)
+	}
+	if !ok {
+		p.add(PlanEntry{Path: path, Action: PlanOverwrite, Note: "no merge base in " + LockFile, Before: before, After: data})
+		return nil
+	}
+	note := ""
+	if len(conflicts) > 0 {
+		note = fmt.Sprintf("keeps your %s; the template also changed it", strings.Join(conflicts, ", "))
+	}
+	p.add(PlanEntry{Path: path, Action: PlanMerge, Note: note, Before: before, After: result})
+	return nil
+}
+
+// instructions plans the project CLAUDE.md and rules/platform.md as
+// setupProjectInstructions writes them, and returns the path that would be
+// enriched.
+func (p *planner) instructions() string {
+	const claudeMd, rules = ".claude/CLAUDE.md", ".claude/rules/platform.md"
+	if p.opts.force || !p.exists(claudeMd) {
+		p.file(claudeMd, "generated from the project")
+		if _, err := platform.ReadAsset(rules); err == nil {
+			p.file(rules, "")
+		}
+		return claudeMd
+	}
+	p.add(PlanEntry{Path: claudeMd, Action: PlanSkip, Note: "exists; platform conventions go to " + rules})
+	if p.exists(rules) {
+		p.add(PlanEntry{Path: rules, Action: PlanOverwrite})
+	} else {
+		p.add(PlanEntry{Path: rules, Action: PlanCreate})
+	}
+	return rules
+}
+
+// packageInstructions plans the CLAUDE.md of each workspace member, as
+// setupPackageInstructions writes them, and returns the paths written.
+func (p *planner) packageInstructions(ws *platform.Workspace) []string {
+	var written []string
+	for _, member := range ws.Members {
+		path := member + "/CLAUDE.md"
+		if !p.opts.force && (p.exists(path) || p.exists(member+"/.claude/CLAUDE.md")) {
+			p.add(PlanEntry{Path: path, Action: PlanSkip, Note: "exists"})
+			continue
+		}
+		p.file(path, "package scaffold")
+		written = append(written, path)
+	}
+	return written
+}
+
+// devcontainer plans the devcontainer.json setupDevcontainer writes. mcpData
+// is the .mcp.json attach would write, used when the project has none.
+func (p *planner) devcontainer(mcpData []byte) error {
+	path := devcontainerPath(p.projectDir)
+	rel, _ := filepath.Rel(p.projectDir, path)
+	rel = filepath.ToSlash(rel)
+
+	before, config, hadComments, err := readDevcontainer(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", rel, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(p.projectDir, ".mcp.json")); err == nil {
+		mcpData = data
+	}
+	result, changed, err := devcontainerConfig(config, filepath.Base(p.projectDir), devcontainerEnv(mcpData))
+	if err != nil {
+		return fmt.Errorf("updating %s: %w", rel, err)
+	}
+	switch {
+	case before == nil:
+		p.add(PlanEntry{Path: rel, Action: PlanCreate, Note: "installs claude-workspace on container creation"})
+	case !changed:
+		p.add(PlanEntry{Path: rel, Action: PlanSkip, Note: "already installs claude-workspace"})
+	default:
+		after, err := marshalDevcontainer(result)
+		if err != nil {
+			return err
+		}
+		note := ""
+		if hadComments {
+			note = "comments are not kept"
+		}
+		p.add(PlanEntry{Path: rel, Action: PlanUpdate, Note: note, Before: before, After: after})
+	}
+	return nil
+}
+
+// governance plans the .gitattributes and CODEOWNERS entries setupGovernance
+// appends.
+func (p *planner) governance() error {
+	if err := p.governanceFile(filepath.Join(p.projectDir, ".gitattributes"), missingAttributes); err != nil {
+		return err
+	}
+	if len(p.opts.owners) == 0 {
+		p.notes = append(p.notes, "CODEOWNERS is left alone without --owners")
+		return nil
+	}
+	return p.governanceFile(codeownersPath(p.projectDir), func(content string) []string {
+		return missingCodeowners(content, p.opts.owners)
+	})
+}
+
+// governanceFile plans appending the entries missing returns to path.
+func (p *planner) governanceFile(path string, missing func(string) []string) error {
+	rel, _ := filepath.Rel(p.projectDir, path)
+	rel = filepath.ToSlash(rel)
+	before, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lines := missing(string(before))
+	switch {
+	case len(lines) == 0:
+		p.add(PlanEntry{Path: rel, Action: PlanSkip, Note: "already has the platform entries"})
+	case before == nil:
+		p.add(PlanEntry{Path: rel, Action: PlanCreate, Note: fmt.Sprintf("adds %d line(s)", len(lines))})
+	default:
+		note := "asks before appending"
+		if p.opts.force {
+			note = ""
+		}
+		p.add(PlanEntry{Path: rel, Action: PlanUpdate, Note: note, Before: before, After: appendEntries(before, lines)})
+	}
+	return nil
+}
+
+// gitignore plans the entries setupGitignore adds to .claude/.gitignore.
+func (p *planner) gitignore() error {
+	path := filepath.Join(p.projectDir, ".claude", ".gitignore")
+	existed := platform.FileExists(path)
+	if existed && platform.HasDenyAllPattern(path) {
+		return nil
+	}
+	data, err := platform.ReadAsset(".claude/.gitignore")
+	if err != nil {
+		return fmt.Errorf("reading embedded .claude/.gitignore: %w", err)
+	}
+	missing, err := platform.MissingGitignoreEntries(path, string(data))
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+	note := "adds " + strings.Join(missing, " ")
+	if existed {
+		p.add(PlanEntry{Path: ".claude/.gitignore", Action: PlanUpdate, Note: note})
+	} else {
+		p.add(PlanEntry{Path: ".claude/.gitignore", Action: PlanCreate, Note: note})
+	}
+	return nil
+}
+
+// printPlan prints the plan grouped by action, the diff of every settings or
+// MCP file that would change, and a summary.
+func printPlan(w io.Writer, projectDir string, entries []PlanEntry, notes []string) {
+	platform.PrintBanner(w, fmt.Sprintf("Attach Plan (dry run): %s", projectDir))
+
+	groups := []struct{ action, title string }{
+		{PlanCreate, "Create"},
+		{PlanLink, "Symlink"},
+		{PlanOverwrite, "Overwrite"},
+		{PlanMerge, "Merge"},
+		{PlanUpdate, "Update"},
+		{PlanSkip, "Skip"},
+	}
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[e.Action]++
+	}
+	for _, g := range groups {
+		if counts[g.action] == 0 {
+			continue
+		}
+		platform.PrintSection(w, fmt.Sprintf("%s (%d)", g.title, counts[g.action]))
+		var group []PlanEntry
+		for _, e := range entries {
+			if e.Action == g.action {
+				group = append(group, e)
+			}
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Path < group[j].Path })
+		for _, e := range group {
+			line := "  " + e.Path
+			if e.Note != "" {
+				line += "  (" + e.Note + ")"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	for _, e := range entries {
+		if e.After == nil {
+			continue
+		}
+		platform.PrintSection(w, fmt.Sprintf("%s diff: %s", strings.ToUpper(e.Action[:1])+e.Action[1:], e.Path))
+		if strings.TrimSuffix(string(e.Before), "\n") == strings.TrimSuffix(string(e.After), "\n") {
+			fmt.Fprintln(w, "  No changes.")
+			continue
+		}
+		platform.WriteLineDiff(w, string(e.Before), string(e.After))
+	}
+
+	if len(notes) > 0 {
+		platform.PrintSection(w, "Notes")
+		for _, n := range notes {
+			platform.PrintInfo(w, n)
+		}
+	}
+
+	var summary []string
+	for _, g := range groups {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[g.action], g.action))
+	}
+	fmt.Fprintf(w, "\n%s %s\n", platform.Bold("Summary:"), strings.Join(summary, ", "))
+	fmt.Fprintln(w, "  Nothing was changed. Run attach without --dry-run to apply this plan.")
+	fmt.Fprintln(w)
+}
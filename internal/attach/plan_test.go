@@ -0,0 +1,166 @@
+package attach
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func planActions(entries []PlanEntry) map[string]PlanEntry {
+	got := map[string]PlanEntry{}
+	for _, e := range entries {
+		got[e.Path] = e
+	}
+	return got
+}
+
+func TestPlan(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{
+		".claude/agents/planner.md": "planner v1",
+		".claude/hooks/guard.sh":    "guard v1",
+		".claude/settings.json":     `{"model":"sonnet","permissions":{"allow":["Read"]}}`,
+		".mcp.json":                 `{"mcpServers":{}}`,
+		".claude/rules/platform.md": "rules",
+		".claude/.gitignore":        "settings.local.json\n",
+	})
+	writeFile(t, filepath.Join(projectDir, ".claude", "agents", "planner.md"), "my planner")
+	writeFile(t, filepath.Join(projectDir, "Makefile"), "test:\n\tgo test ./...\n")
+
+	entries, notes, err := plan(projectDir, planOptions{}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := planActions(entries)
+	for path, want := range map[string]string{
+		".claude/agents/planner.md": PlanSkip,
+		".claude/hooks/guard.sh":    PlanCreate,
+		".claude/settings.json":     PlanCreate,
+		".mcp.json":                 PlanCreate,
+		".claude/CLAUDE.md":         PlanCreate,
+		".claude/rules/platform.md": PlanCreate,
+		".claude/commands/test.md":  PlanCreate,
+		".claude/.gitignore":        PlanCreate,
+		LockFile:                    PlanCreate,
+	} {
+		if got[path].Action != want {
+			t.Errorf("%s: action %q, want %q", path, got[path].Action, want)
+		}
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "enriched") {
+		t.Errorf("notes = %q, want the enrichment note", notes)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(projectDir, ".claude")); len(entries) != 1 {
+		t.Errorf("plan wrote files: %v", entries)
+	}
+
+	entries, _, err = plan(projectDir, planOptions{force: true, symlink: true, noEnrich: true}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := planActions(entries)[".claude/agents/planner.md"]; e.Action != PlanLink || e.Note == "" {
+		t.Errorf("planner.md with --force --symlink = %+v, want a link replacing the file", e)
+	}
+
+	entries, notes, err = plan(projectDir, planOptions{hardlink: true, noEnrich: true}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := planActions(entries)[".claude/agents/planner.md"]; e.Action != PlanSkip {
+		t.Errorf("planner.md with --hardlink = %+v, want it kept like a copy", e)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "reflinked") {
+		t.Errorf("notes with --hardlink = %q, want the link note", notes)
+	}
+}
+
+func TestPlan_Devcontainer(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{
+		".claude/settings.json": `{}`,
+		".mcp.json":             `{"mcpServers":{"s":{"env":{"TOKEN":"${GH_TOKEN}"}}}}`,
+		".claude/.gitignore":    "settings.local.json\n",
+	})
+	entries, _, err := plan(projectDir, planOptions{noEnrich: true, devcontainer: true}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := planActions(entries)[".devcontainer/devcontainer.json"]; e.Action != PlanCreate {
+		t.Errorf("devcontainer.json = %+v, want create", e)
+	}
+
+	writeFile(t, filepath.Join(projectDir, ".devcontainer", "devcontainer.json"), `{"image": "node:20"}`)
+	entries, _, err = plan(projectDir, planOptions{noEnrich: true, devcontainer: true}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := planActions(entries)[".devcontainer/devcontainer.json"]
+	if e.Action != PlanUpdate || !strings.Contains(string(e.After), "${localEnv:GH_TOKEN}") {
+		t.Errorf("devcontainer.json = %+v, want an update passing GH_TOKEN", e)
+	}
+	if platform.FileExists(filepath.Join(projectDir, ".claude")) {
+		t.Error("plan wrote files")
+	}
+}
+
+func TestPlan_MergeDiff(t *testing.T) {
+	projectDir := t.TempDir()
+	useMapFS(t, map[string]string{
+		".claude/settings.json": `{"model":"sonnet","permissions":{"allow":["Read"]}}`,
+		".mcp.json":             `{"mcpServers":{}}`,
+	})
+	attachFiles(t, projectDir)
+	lock, _ := ReadLock(projectDir)
+	writeFile(t, filepath.Join(projectDir, ".claude", "settings.json"), `{"model":"opus","permissions":{"allow":["Read"]}}`)
+
+	// The template changes the model, which was also edited locally, and
+	// adds a permission.
+	useMapFS(t, map[string]string{
+		".claude/settings.json": `{"model":"haiku","permissions":{"allow":["Read","Grep"]}}`,
+		".mcp.json":             `{"mcpServers":{}}`,
+		".claude/.gitignore":    "settings.local.json\n",
+	})
+	entries, _, err := plan(projectDir, planOptions{noEnrich: true}, nil, nil, lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := planActions(entries)[".claude/settings.json"]; e.Action != PlanSkip {
+		t.Errorf("settings.json without --force = %q, want skip", e.Action)
+	}
+
+	entries, _, err = plan(projectDir, planOptions{force: true, noEnrich: true}, nil, nil, lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := planActions(entries)[".claude/settings.json"]
+	if e.Action != PlanMerge || !strings.Contains(e.Note, "model") {
+		t.Fatalf("settings.json with --force = %+v, want a merge keeping the local model", e)
+	}
+	if !strings.Contains(string(e.After), `"Grep"`) || !strings.Contains(string(e.After), `"opus"`) {
+		t.Errorf("merge result = %s", e.After)
+	}
+	if data, _ := os.ReadFile(filepath.Join(projectDir, ".claude", "settings.json")); !strings.Contains(string(data), "opus") || strings.Contains(string(data), "Grep") {
+		t.Errorf("plan changed settings.json: %s", data)
+	}
+
+	var out strings.Builder
+	printPlan(&out, projectDir, entries, nil)
+	for _, want := range []string{"Merge diff: .claude/settings.json", `+       "Grep"`, "Nothing was changed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
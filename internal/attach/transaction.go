@@ -0,0 +1,380 @@
+package attach
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// stagedDirs and stagedFiles are the project paths attach reads before it
+// writes them, relative to the project. They are copied into the staging
+// directory so each step sees the project as it is.
+var (
+	stagedDirs  = []string{".claude/agents", ".claude/skills", ".claude/hooks", ".claude/commands"}
+	stagedFiles = []string{
+		".claude/plans/.gitkeep",
+		".claude/settings.json",
+		".claude/settings.local.json.example",
+		".claude/CLAUDE.md",
+		".claude/rules/platform.md",
+		".claude/.gitignore",
+		LockFile,
+		".mcp.json",
+		".devcontainer/devcontainer.json",
+		".devcontainer.json",
+		".gitattributes",
+		".github/CODEOWNERS",
+		"CODEOWNERS",
+		"docs/CODEOWNERS",
+	}
+)
+
+// changeKind is how applying a staged path changes the project.
+type changeKind string
+
+const (
+	changeMkdir  changeKind = "mkdir"
+	changeCreate changeKind = "create"
+	changeModify changeKind = "modify"
+)
+
+// change is one entry of the change set a transaction applies.
+type change struct {
+	Path string // slash-separated, relative to the project
+	Kind changeKind
+}
+
+// undo records how to restore one project path applied by a transaction.
+type undo struct {
+	path    string
+	existed bool
+	data    []byte
+	mode    fs.FileMode
+	link    string
+}
+
+// transaction stages attach's writes in a temporary directory laid out like
+// the project, then applies the difference to the project in one step. Each
+// applied path is journaled, so a failure part way through restores the
+// project to its state before attach, and an interrupt before the changes are
+// applied leaves the project untouched.
+type transaction struct {
+	projectDir string
+	stageDir   string
+	journal    []undo
+	signals    chan os.Signal
+}
+
+// beginTransaction creates the staging directory for projectDir and seeds it
+// with the existing files attach reads, including each package's CLAUDE.md
+// when ws is not nil.
+func beginTransaction(projectDir string, ws *platform.Workspace) (*transaction, error) {
+	stageDir, err := os.MkdirTemp("", "claude-workspace-attach-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	t := &transaction{projectDir: projectDir, stageDir: stageDir, signals: make(chan os.Signal, 1)}
+	signal.Notify(t.signals, os.Interrupt, syscall.SIGTERM)
+
+	files := append([]string(nil), stagedFiles...)
+	if ws != nil {
+		for _, m := range ws.Members {
+			files = append(files, m+"/CLAUDE.md")
+		}
+	}
+	for _, rel := range stagedDirs {
+		if err = t.seedDir(rel); err != nil {
+			break
+		}
+	}
+	for _, rel := range files {
+		if err != nil {
+			break
+		}
+		err = t.seedFile(rel)
+	}
+	if err != nil {
+		t.discard()
+		return nil, fmt.Errorf("staging project files: %w", err)
+	}
+	return t, nil
+}
+
+// seedDir copies the project directory rel, if it exists, into the stage.
+func (t *transaction) seedDir(rel string) error {
+	root := filepath.Join(t.projectDir, filepath.FromSlash(rel))
+	if !platform.FileExists(root) {
+		return nil
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		r, _ := filepath.Rel(t.projectDir, path)
+		return t.seedFile(filepath.ToSlash(r))
+	})
+}
+
+// seedFile copies the project path rel, if it exists, into the stage. Symlinks
+// are copied as links.
+func (t *transaction) seedFile(rel string) error {
+	src := filepath.Join(t.projectDir, filepath.FromSlash(rel))
+	info, err := os.Lstat(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dst := t.path(rel)
+	if info.IsDir() {
+		return os.MkdirAll(dst, info.Mode().Perm())
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	}
+	if err := platform.CopyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode().Perm())
+}
+
+// path returns the staged location of the project path rel.
+func (t *transaction) path(rel string) string {
+	return filepath.Join(t.stageDir, filepath.FromSlash(rel))
+}
+
+// interrupted reports whether attach received an interrupt or termination
+// signal since the transaction began.
+func (t *transaction) interrupted() bool {
+	select {
+	case <-t.signals:
+		return true
+	default:
+		return false
+	}
+}
+
+// changes returns the change set: every staged directory missing from the
+// project and every staged file or link that is missing or differs, parents
+// before children.
+func (t *transaction) changes() ([]change, error) {
+	var set []change
+	err := filepath.WalkDir(t.stageDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == t.stageDir {
+			return err
+		}
+		rel, _ := filepath.Rel(t.stageDir, path)
+		rel = filepath.ToSlash(rel)
+		staged, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		current, err := os.Lstat(filepath.Join(t.projectDir, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err):
+			if staged.IsDir() {
+				set = append(set, change{rel, changeMkdir})
+			} else {
+				set = append(set, change{rel, changeCreate})
+			}
+		case err != nil:
+			return err
+		case staged.IsDir():
+		default:
+			same, err := sameEntry(path, staged, filepath.Join(t.projectDir, filepath.FromSlash(rel)), current)
+			if err != nil {
+				return err
+			}
+			if !same {
+				set = append(set, change{rel, changeModify})
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(set, func(i, j int) bool { return set[i].Path < set[j].Path })
+	return set, err
+}
+
+// sameEntry reports whether the staged file or link at a matches the project
+// path b.
+func sameEntry(a string, ai fs.FileInfo, b string, bi fs.FileInfo) (bool, error) {
+	if ai.Mode().Type() != bi.Mode().Type() {
+		return false, nil
+	}
+	if ai.Mode()&fs.ModeSymlink != 0 {
+		la, err := os.Readlink(a)
+		if err != nil {
+			return false, err
+		}
+		lb, err := os.Readlink(b)
+		return la == lb, err
+	}
+	if ai.Mode().Perm() != bi.Mode().Perm() || ai.Size() != bi.Size() {
+		return false, nil
+	}
+	da, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	db, err := os.ReadFile(b)
+	return bytes.Equal(da, db), err
+}
+
+// commit applies the change set to the project. Files and links are written
+// to a temporary name beside their target and renamed into place. If any
+// change fails, the ones already applied are undone and the error says
+// whether the project was restored.
+func (t *transaction) commit(set []change) error {
+	// Signals are still caught here, so an interrupt while the changes are
+	// applied does not leave the project half updated.
+	for _, c := range set {
+		if err := t.apply(c); err != nil {
+			err = fmt.Errorf("applying %s: %w", c.Path, err)
+			if rbErr := t.rollback(); rbErr != nil {
+				return fmt.Errorf("%w; rolling back failed, the project may be partially attached: %v", err, rbErr)
+			}
+			return fmt.Errorf("%w (rolled back; the project is unchanged)", err)
+		}
+	}
+	t.journal = nil
+	return nil
+}
+
+// apply makes one change, journaling what it replaces.
+func (t *transaction) apply(c change) error {
+	dst := filepath.Join(t.projectDir, filepath.FromSlash(c.Path))
+	src := t.path(c.Path)
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if c.Kind == changeMkdir {
+		if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		t.journal = append(t.journal, undo{path: dst})
+		return nil
+	}
+
+	u := undo{path: dst}
+	if c.Kind == changeModify {
+		if u, err = snapshot(dst); err != nil {
+			return err
+		}
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		var target string
+		if target, err = os.Readlink(src); err == nil {
+			err = replaceLink(dst, target)
+		}
+	} else {
+		var data []byte
+		if data, err = os.ReadFile(src); err == nil {
+			err = replaceFile(dst, data, info.Mode().Perm())
+		}
+	}
+	if err != nil {
+		return err
+	}
+	t.journal = append(t.journal, u)
+	return nil
+}
+
+// snapshot records the current file or link at path.
+func snapshot(path string) (undo, error) {
+	u := undo{path: path, existed: true}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return u, err
+	}
+	u.mode = info.Mode().Perm()
+	if info.Mode()&fs.ModeSymlink != 0 {
+		u.link, err = os.Readlink(path)
+		return u, err
+	}
+	if info.IsDir() {
+		return u, fmt.Errorf("%s is a directory", path)
+	}
+	u.data, err = os.ReadFile(path)
+	return u, err
+}
+
+// rollback undoes the journaled changes, newest first.
+func (t *transaction) rollback() error {
+	var errs []error
+	for i := len(t.journal) - 1; i >= 0; i-- {
+		u := t.journal[i]
+		var err error
+		switch {
+		case !u.existed:
+			err = os.Remove(u.path)
+		case u.link != "":
+			err = replaceLink(u.path, u.link)
+		default:
+			err = replaceFile(u.path, u.data, u.mode)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	t.journal = nil
+	return errors.Join(errs...)
+}
+
+// discard removes the staging directory and stops watching for signals.
+func (t *transaction) discard() {
+	signal.Stop(t.signals)
+	os.RemoveAll(t.stageDir)
+}
+
+// replaceFile atomically replaces path with data.
+func replaceFile(path string, data []byte, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// replaceLink atomically replaces path with a symlink to target.
+func replaceLink(path, target string) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp-link")
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package attach
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	dir := t.TempDir()
+	settings := filepath.Join(dir, ".claude", "settings.json")
+	os.MkdirAll(filepath.Dir(settings), 0755)
+	os.WriteFile(settings, []byte("old"), 0644)
+
+	tx, err := beginTransaction(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.discard()
+	if data, _ := os.ReadFile(tx.path(".claude/settings.json")); string(data) != "old" {
+		t.Fatalf("staged settings.json = %q, want the project's", data)
+	}
+	os.WriteFile(tx.path(".claude/settings.json"), []byte("new"), 0644)
+	os.MkdirAll(tx.path("a"), 0755)
+	os.WriteFile(tx.path("a/new.txt"), []byte("x"), 0644)
+	os.WriteFile(tx.path("b.txt"), []byte("y"), 0644)
+
+	set, err := tx.changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(set); got != "[{.claude/settings.json modify} {a mkdir} {a/new.txt create} {b.txt create}]" {
+		t.Fatalf("changes = %s", got)
+	}
+
+	// A directory in the way of the last change makes the commit fail.
+	blocker := filepath.Join(dir, "b.txt")
+	os.Mkdir(blocker, 0755)
+	if err := tx.commit(set); err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("commit = %v, want a rolled back error", err)
+	}
+	if data, _ := os.ReadFile(settings); string(data) != "old" {
+		t.Errorf("settings.json after rollback = %q, want %q", data, "old")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Errorf("created directory a was not removed: %v", err)
+	}
+
+	os.Remove(blocker)
+	if err := tx.commit(set); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(settings); string(data) != "new" {
+		t.Errorf("settings.json after commit = %q, want %q", data, "new")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a", "new.txt")); string(data) != "x" {
+		t.Errorf("a/new.txt after commit = %q", data)
+	}
+	if set, _ := tx.changes(); len(set) != 0 {
+		t.Errorf("changes after commit = %v, want none", set)
+	}
+
+	tx.discard()
+	if _, err := os.Stat(tx.stageDir); !os.IsNotExist(err) {
+		t.Errorf("staging directory left behind: %v", err)
+	}
+}
+
+func TestTransactionSeedsLinks(t *testing.T) {
+	dir := t.TempDir()
+	agents := filepath.Join(dir, ".claude", "agents")
+	os.MkdirAll(agents, 0755)
+	os.Symlink("/assets/explorer.md", filepath.Join(agents, "explorer.md"))
+
+	tx, err := beginTransaction(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.discard()
+	if target, err := os.Readlink(tx.path(".claude/agents/explorer.md")); err != nil || target != "/assets/explorer.md" {
+		t.Errorf("staged link = %q, %v", target, err)
+	}
+	if set, _ := tx.changes(); len(set) != 0 {
+		t.Errorf("changes of an untouched stage = %v, want none", set)
+	}
+}
@@ -0,0 +1,436 @@
+// Package audit implements "sessions audit": a chronological report of the
+// Bash commands, file writes, and MCP tool calls an agent made in recorded
+// sessions, flagging calls the current deny rules would block and calls that
+// touched sensitive paths.
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/policy"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+const usage = "sessions audit [<session-id> | --all] [--since YYYY-MM-DD] [--flagged] [--format text|json|csv] [--output path]"
+
+// Entry kinds.
+const (
+	KindBash  = "bash"
+	KindWrite = "write"
+	KindMCP   = "mcp"
+)
+
+// writeTools maps the tools that change files to the input field naming the
+// file.
+var writeTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// sensitivePaths match credentials, keys, and the configuration that grants
+// an agent its permissions, in a file path or anywhere in a command.
+var sensitivePaths = []*regexp.Regexp{
+	regexp.MustCompile(`(?:^|[\s/"'=<>])(\.env(?:\.[\w-]+)?)\b`),
+	regexp.MustCompile(`\.ssh/[^\s"']*|\bid_(?:rsa|dsa|ecdsa|ed25519)\b`),
+	regexp.MustCompile(`[^\s/"'.][^\s/"']*\.(?:pem|key|p12|pfx|keystore)\b`),
+	regexp.MustCompile(`\.aws/(?:credentials|config)|\.kube/config|\.docker/config\.json|\.gnupg/|\.git-credentials`),
+	regexp.MustCompile(`(?:^|[\s/"'])(\.(?:npmrc|pypirc|netrc))\b`),
+	regexp.MustCompile(`\.claude\.json|\.claude/settings(?:\.local)?\.json|\.mcp\.json`),
+	regexp.MustCompile(`\.github/workflows/[^\s"']*|\.git/(?:config|hooks/[^\s"']*)`),
+	regexp.MustCompile(`/etc/(?:shadow|passwd|sudoers[^\s"']*|ssh/[^\s"']*|hosts|crontab|cron\.[^\s"']*)`),
+}
+
+// safeEnvSuffixes are .env files meant to be committed.
+var safeEnvSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// Entry is one audited tool call.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+	Project string    `json:"project"`
+	Kind    string    `json:"kind"`
+	Tool    string    `json:"tool"`
+	Target  string    `json:"target"` // the command, the file, or the MCP input
+	Error   bool      `json:"error,omitempty"`
+	Flags   []string  `json:"flags,omitempty"`
+}
+
+// Report is the audit of a set of sessions.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Since       string    `json:"since,omitempty"`
+	Sessions    int       `json:"sessions"`
+	Flagged     int       `json:"flagged"`
+	Entries     []Entry   `json:"entries"`
+}
+
+type options struct {
+	id      string
+	all     bool
+	since   time.Time
+	flagged bool
+	format  string
+	output  string
+}
+
+func parseArgs(args []string) (options, error) {
+	var opts options
+	f := cli.New("sessions audit", usage)
+	f.BoolVar(&opts.all, "--all", "Audit all projects")
+	f.Func("--since", "YYYY-MM-DD", "Only calls made on or after the date", func(v string) error {
+		since, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return fmt.Errorf("--since must be a date like 2026-03-01, got %q", v)
+		}
+		opts.since = since
+		return nil
+	})
+	f.BoolVar(&opts.flagged, "--flagged", "Only flagged calls")
+	f.StringVar(&opts.format, "--format", "text|json|csv", "Output format (default: text, or from --output extension)")
+	f.StringVar(&opts.output, "--output", "path", "Write to a file instead of stdout")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return opts, err
+	}
+	if len(positional) == 1 {
+		opts.id = positional[0]
+	}
+	if opts.id != "" && opts.all {
+		return opts, fmt.Errorf("give a session ID or --all, not both")
+	}
+	if opts.format == "" {
+		switch strings.ToLower(filepath.Ext(opts.output)) {
+		case ".json":
+			opts.format = "json"
+		case ".csv":
+			opts.format = "csv"
+		default:
+			opts.format = "text"
+		}
+	}
+	if opts.format != "text" && opts.format != "json" && opts.format != "csv" {
+		return opts, fmt.Errorf("--format must be text, json, or csv")
+	}
+	return opts, nil
+}
+
+// Run handles "sessions audit". Without a session ID it audits the sessions
+// of the current project, or of every project with --all.
+func Run(args []string) error {
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	found, err := findSessions(home, opts)
+	if err != nil {
+		return err
+	}
+
+	a := newAuditor(home)
+	r := &Report{GeneratedAt: time.Now().UTC().Truncate(time.Second), Entries: []Entry{}}
+	if !opts.since.IsZero() {
+		r.Since = opts.since.Format("2006-01-02")
+	}
+	for _, s := range found {
+		t, err := sessions.ParseTranscript(s.Path)
+		if err != nil {
+			continue
+		}
+		if t.Project == "" {
+			t.Project = s.Project
+		}
+		entries := a.entries(t, opts.since)
+		if len(entries) > 0 || opts.id != "" {
+			r.Sessions++
+		}
+		for _, e := range entries {
+			if len(e.Flags) > 0 {
+				r.Flagged++
+			}
+			if !opts.flagged || len(e.Flags) > 0 {
+				r.Entries = append(r.Entries, e)
+			}
+		}
+	}
+	sort.SliceStable(r.Entries, func(i, j int) bool { return r.Entries[i].Time.Before(r.Entries[j].Time) })
+
+	w := io.Writer(platform.Stdout())
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", opts.output, err)
+		}
+		defer f.Close()
+		w = f
+	} else if opts.format != "text" {
+		w = os.Stdout
+	}
+	if err := render(w, r, opts.format, opts.all); err != nil {
+		return err
+	}
+	if opts.output != "" {
+		platform.PrintOK(os.Stderr, fmt.Sprintf("Wrote %d entr%s (%d flagged) to %s", len(r.Entries), plural(len(r.Entries)), r.Flagged, opts.output))
+	}
+	return nil
+}
+
+// findSessions returns the session named by opts.id, or the sessions of the
+// current project or every project, skipping files last written before
+// opts.since.
+func findSessions(home string, opts options) ([]sessions.Session, error) {
+	if opts.id != "" {
+		s, err := sessions.Find(opts.id)
+		if err != nil {
+			return nil, err
+		}
+		return []sessions.Session{s}, nil
+	}
+	projectsDir := filepath.Join(home, ".claude", "projects")
+	if !platform.FileExists(projectsDir) {
+		return nil, fmt.Errorf("no Claude Code session data found at %s", projectsDir)
+	}
+	dirs, err := sessions.ResolveProjectDirs(projectsDir, opts.all)
+	if err != nil {
+		return nil, err
+	}
+	var found []sessions.Session
+	for _, dir := range dirs {
+		list, err := sessions.ScanProjectSessions(dir, sessions.DecodeProjectPath(filepath.Base(dir)))
+		if err != nil {
+			continue
+		}
+		for _, s := range list {
+			if info, err := os.Stat(s.Path); err == nil && !opts.since.IsZero() && info.ModTime().Before(opts.since) {
+				continue
+			}
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+// auditor turns transcripts into entries, loading each project's permission
+// rules once.
+type auditor struct {
+	home     string
+	policies map[string]*policy.Policy
+}
+
+func newAuditor(home string) *auditor {
+	return &auditor{home: home, policies: make(map[string]*policy.Policy)}
+}
+
+// entries returns the audited calls of t made at or after since.
+func (a *auditor) entries(t *sessions.Transcript, since time.Time) []Entry {
+	var out []Entry
+	for _, m := range t.Messages {
+		if m.Timestamp.Before(since) {
+			continue
+		}
+		for _, b := range m.Blocks {
+			if b.Type != "tool_use" {
+				continue
+			}
+			e := Entry{Time: m.Timestamp, Session: t.ID, Project: t.Project, Tool: b.Tool, Error: b.IsError}
+			switch field, write := writeTools[b.Tool]; {
+			case b.Tool == "Bash":
+				e.Kind, e.Target = KindBash, inputString(b.Input, "command")
+			case write:
+				e.Kind, e.Target = KindWrite, inputString(b.Input, field)
+			case strings.HasPrefix(b.Tool, "mcp__"):
+				e.Kind, e.Target = KindMCP, compactJSON(b.Input)
+			default:
+				continue
+			}
+			e.Flags = a.flags(e, b.Input)
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// flags returns why an entry needs review: a deny rule in the project's
+// current settings that matches the call, and sensitive paths it touched.
+func (a *auditor) flags(e Entry, input json.RawMessage) []string {
+	var flags []string
+	if p := a.policy(e.Project); p != nil {
+		env := policy.Env{Home: a.home, Cwd: e.Project}
+		// The first verdict is the overall decision for a compound command.
+		if verdicts, err := p.Evaluate(policy.CallString(e.Tool, input), env); err == nil && verdicts[0].Decision == policy.DecisionDeny {
+			if v := verdicts[0]; v.Entry != nil {
+				flags = append(flags, fmt.Sprintf("matches deny rule %s (%s settings)", v.Entry.Rule, v.Entry.Scope))
+			}
+		}
+	}
+	for _, path := range sensitiveMatches(e.Target) {
+		flags = append(flags, "touches sensitive path "+path)
+	}
+	return flags
+}
+
+// policy returns the effective permission rules for project, or nil when
+// they cannot be read.
+func (a *auditor) policy(project string) *policy.Policy {
+	if p, ok := a.policies[project]; ok {
+		return p
+	}
+	p, err := policy.Load(a.home, project)
+	if err != nil {
+		p = nil
+	}
+	a.policies[project] = p
+	return p
+}
+
+// sensitiveMatches returns the sensitive paths mentioned in s, once each.
+func sensitiveMatches(s string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, re := range sensitivePaths {
+		for _, m := range re.FindAllStringSubmatch(s, -1) {
+			match := m[0]
+			if len(m) > 1 && m[1] != "" {
+				match = m[1]
+			}
+			match = strings.Trim(match, " \t\"'=<>")
+			if seen[match] || isSafeEnvFile(match) {
+				continue
+			}
+			seen[match] = true
+			out = append(out, match)
+		}
+	}
+	return out
+}
+
+func isSafeEnvFile(name string) bool {
+	if !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	for _, suffix := range safeEnvSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func inputString(raw json.RawMessage, field string) string {
+	var input map[string]interface{}
+	if json.Unmarshal(raw, &input) != nil {
+		return ""
+	}
+	s, _ := input[field].(string)
+	return s
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var v interface{}
+	if json.Unmarshal(raw, &v) != nil {
+		return string(raw)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// render writes the report as text, JSON, or CSV.
+func render(w io.Writer, r *Report, format string, all bool) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case "csv":
+		return writeCSV(w, r)
+	default:
+		writeText(w, r, all)
+		return nil
+	}
+}
+
+func writeCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "session", "project", "kind", "tool", "target", "error", "flags"})
+	for _, e := range r.Entries {
+		cw.Write([]string{
+			e.Time.Format(time.RFC3339), e.Session, e.Project, e.Kind, e.Tool, e.Target,
+			fmt.Sprint(e.Error), strings.Join(e.Flags, "; "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeText(w io.Writer, r *Report, all bool) {
+	platform.PrintBanner(w, "Session Audit")
+	period := ""
+	if r.Since != "" {
+		period = ", since " + r.Since
+	}
+	fmt.Fprintf(w, "  %d session(s), %d entr%s, %d flagged%s\n", r.Sessions, len(r.Entries), plural(len(r.Entries)), r.Flagged, period)
+	fmt.Fprintln(w, "  Deny rules are the current settings of each project.")
+	fmt.Fprintln(w)
+	if len(r.Entries) == 0 {
+		fmt.Fprintln(w, "  No Bash commands, file writes, or MCP tool calls to report.")
+		fmt.Fprintln(w)
+		return
+	}
+	for _, e := range r.Entries {
+		id := e.Session
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		if all && e.Project != "" {
+			id += " " + filepath.Base(e.Project)
+		}
+		target := e.Target
+		if e.Kind == KindMCP {
+			target = e.Tool + " " + target
+		}
+		status := ""
+		if e.Error {
+			status = " (failed)"
+		}
+		fmt.Fprintf(w, "  %s  %s  %-5s  %s%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), id, e.Kind, oneLine(target, 100), status)
+		for _, f := range e.Flags {
+			platform.PrintWarningLine(w, "  ! "+f)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// oneLine collapses whitespace and truncates s to n bytes.
+func oneLine(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > n {
+		s = s[:n-3] + "..."
+	}
+	return s
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
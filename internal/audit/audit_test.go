@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// auditJSONL is a session with a denied Bash command, a write to .env, a
+// failed MCP call, and a Read that is not audited.
+const auditJSONL = `{"type":"user","cwd":%q,"sessionId":"abc12345-0000","timestamp":"2026-03-02T09:00:00Z","message":{"role":"user","content":"Clean up"}}
+{"type":"assistant","timestamp":"2026-03-02T09:00:01Z","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"git push --force origin main"}}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:02Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"type":"assistant","timestamp":"2026-03-03T09:00:03Z","message":{"id":"m2","role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Write","input":{"file_path":"/srv/app/.env","content":"X=1"}},{"type":"tool_use","id":"t3","name":"Read","input":{"file_path":"/srv/app/go.mod"}}]}}
+{"type":"user","timestamp":"2026-03-03T09:00:04Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"ok"},{"type":"tool_result","tool_use_id":"t3","content":"ok"}]}}
+{"type":"assistant","timestamp":"2026-03-03T09:00:05Z","message":{"id":"m3","role":"assistant","content":[{"type":"tool_use","id":"t4","name":"mcp__github__create_issue","input":{"title":"Bug",  "body":"x"}}]}}
+{"type":"user","timestamp":"2026-03-03T09:00:06Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","content":"forbidden","is_error":true}]}}
+`
+
+func transcript(t *testing.T) (*sessions.Transcript, string) {
+	t.Helper()
+	project := t.TempDir()
+	os.MkdirAll(filepath.Join(project, ".claude"), 0755)
+	os.WriteFile(filepath.Join(project, ".claude", "settings.json"), []byte(`{"permissions": {"deny": ["Bash(git push --force *)"]}}`), 0644)
+	path := filepath.Join(t.TempDir(), "abc12345-0000.jsonl")
+	os.WriteFile(path, []byte(fmt.Sprintf(auditJSONL, project)), 0644)
+	tr, err := sessions.ParseTranscript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr, project
+}
+
+func TestEntries(t *testing.T) {
+	tr, project := transcript(t)
+	a := newAuditor(t.TempDir())
+
+	got := a.entries(tr, time.Time{})
+	if len(got) != 3 {
+		t.Fatalf("entries = %+v, want 3", got)
+	}
+	want := []struct {
+		kind, target string
+		err          bool
+		flags        []string
+	}{
+		{KindBash, "git push --force origin main", false, []string{"matches deny rule Bash(git push --force *) (project settings)"}},
+		{KindWrite, "/srv/app/.env", false, []string{"touches sensitive path .env"}},
+		{KindMCP, `{"body":"x","title":"Bug"}`, true, nil},
+	}
+	for i, w := range want {
+		e := got[i]
+		if e.Kind != w.kind || e.Target != w.target || e.Error != w.err || !reflect.DeepEqual(e.Flags, w.flags) || e.Project != project {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+
+	since := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	if got := a.entries(tr, since); len(got) != 2 || got[0].Kind != KindWrite {
+		t.Errorf("entries since %s = %+v", since, got)
+	}
+}
+
+func TestSensitiveMatches(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"cat .env && cp .env.local /tmp", []string{".env", ".env.local"}},
+		{"cp .env.example .env.sample", nil},
+		{"scp ~/.ssh/id_ed25519 host:", []string{".ssh/id_ed25519"}},
+		{"ssh-keygen -f id_rsa", []string{"id_rsa"}},
+		{"openssl x509 -in server.pem", []string{"server.pem"}},
+		{"/home/me/.aws/credentials", []string{".aws/credentials"}},
+		{"/srv/app/.github/workflows/ci.yml", []string{".github/workflows/ci.yml"}},
+		{"sudo tee /etc/sudoers.d/me", []string{"/etc/sudoers.d/me"}},
+		{"vim ~/.claude/settings.json", []string{".claude/settings.json"}},
+		{"go test ./... && git status", nil},
+		{"ls /etc/os-release keys.go", nil},
+	}
+	for _, tt := range tests {
+		if got := sensitiveMatches(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sensitiveMatches(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tr, _ := transcript(t)
+	r := &Report{Sessions: 1, Entries: newAuditor(t.TempDir()).entries(tr, time.Time{})}
+	r.Flagged = 2
+
+	var buf bytes.Buffer
+	if err := render(&buf, r, "text", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1 session(s), 3 entries, 2 flagged", "abc12345  bash   git push --force origin main", "! touches sensitive path .env", "mcp__github__create_issue {\"body\":\"x\",\"title\":\"Bug\"} (failed)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := render(&buf, r, "csv", false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[0] != "time,session,project,kind,tool,target,error,flags" || !strings.HasSuffix(lines[3], `"{""body"":""x"",""title"":""Bug""}",true,`) {
+		t.Errorf("CSV =\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := render(&buf, r, "json", false); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Entries) != 3 || decoded.Entries[1].Flags[0] != "touches sensitive path .env" {
+		t.Errorf("JSON = %s (%v)", buf.String(), err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	opts, err := parseArgs([]string{"--all", "--since", "2026-03-01", "--flagged", "--output", "audit.CSV"})
+	if err != nil || !opts.all || !opts.flagged || opts.format != "csv" || opts.since.Day() != 1 {
+		t.Errorf("parseArgs = %+v, %v", opts, err)
+	}
+	if opts, err := parseArgs([]string{"abc"}); err != nil || opts.id != "abc" || opts.format != "text" {
+		t.Errorf("parseArgs(abc) = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{"--since"}, {"--since", "March"}, {"--format", "xml"}, {"abc", "--all"}, {"a", "b"}, {"--json"}} {
+		if _, err := parseArgs(args); err == nil {
+			t.Errorf("parseArgs(%q): expected error", args)
+		}
+	}
+}
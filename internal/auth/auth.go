@@ -0,0 +1,76 @@
+// Package auth implements the "auth" command: rotating the Anthropic API key
+// Claude Code uses everywhere it is stored, and switching between named
+// sign-ins (profiles) for people who work across several organizations.
+package auth
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage:
+  claude-workspace auth rotate [--from-env <VAR>] [--no-validate] [--oauth]
+  claude-workspace auth profiles [list|save <name>|rm <name>]
+  claude-workspace auth use [<profile>] [--pin|--unpin] [--discard]`
+
+// Run routes the auth subcommand.
+func Run(args []string) error {
+	if len(args) == 0 || args[0] == "--help" || args[0] == "-h" {
+		printHelp(os.Stdout)
+		return nil
+	}
+	switch args[0] {
+	case "rotate":
+		return rotate(args[1:])
+	case "profiles":
+		return runProfiles(args[1:])
+	case "use":
+		return runUse(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown auth subcommand: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown subcommand: %s", args[0])
+	}
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprint(w, usage+`
+
+Subcommands:
+  rotate               Replace the Anthropic API key. The new key is entered
+                       with masked input and checked against the API first.
+                       primaryApiKey in ~/.claude.json, MCP server env vars and
+                       headers, the env of ~/.claude/settings.json, and
+                       credential store entries holding the old key are
+                       updated, each file after a backup.
+  profiles             List saved sign-ins; * marks the active one
+  profiles save <name> Save the current sign-in as a profile
+  profiles rm <name>   Delete a saved profile
+  use [<profile>]      Switch Claude Code to a profile. Without a name, switch
+                       to the profile this project is pinned to.
+
+Options:
+  --from-env <VAR>    rotate: read the new key from an environment variable
+                      instead of prompting (for scripts)
+  --no-validate       rotate: skip the API check (offline or gateway setups)
+  --oauth             rotate: sign in to Claude Code again with your Claude
+                      account instead of rotating an API key
+  --pin               use: also pin this project to the profile, in
+                      .claude/settings.local.json
+  --unpin             use: remove this project's pin
+  --discard           use: replace a sign-in that is not saved in any profile
+
+Profiles are kept in the credential store (see 'claude-workspace secrets').
+A switch saves the outgoing sign-in back to its profile first, so tokens
+Claude Code refreshed in the meantime are kept.
+
+Examples:
+
+  claude-workspace auth rotate
+  NEW_KEY=sk-ant-... claude-workspace auth rotate --from-env NEW_KEY
+  claude-workspace auth profiles save work
+  claude-workspace auth use customer-x --pin
+  claude-workspace auth use
+`)
+}
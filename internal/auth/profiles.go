@@ -0,0 +1,543 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+const (
+	// profileSecretPrefix starts the credential store name of each profile.
+	profileSecretPrefix = "AUTH_PROFILE_"
+	// configActiveProfile is the config.json key naming the profile whose
+	// credentials are in ~/.claude.json now.
+	configActiveProfile = "authProfile"
+	// PinEnvVar pins a project to a profile. It is set in the env block of
+	// .claude/settings.local.json, so Claude Code sessions see it too.
+	PinEnvVar = "CLAUDE_WORKSPACE_AUTH_PROFILE"
+	// keychainService is where Claude Code keeps its OAuth tokens on macOS.
+	keychainService = "Claude Code-credentials"
+)
+
+// authConfigKeys are the keys of ~/.claude.json that make up a sign-in.
+var authConfigKeys = []string{"primaryApiKey", "oauthAccount", "customApiKeyResponses"}
+
+var validProfileName = regexp.MustCompile(`^[a-z][a-z0-9-]{0,39}$`)
+
+// credentialsOnKeychain reports whether Claude Code keeps OAuth tokens in the
+// login Keychain rather than ~/.claude/.credentials.json. Tests clear it.
+var credentialsOnKeychain = runtime.GOOS == "darwin"
+
+// profile is a saved sign-in: the auth keys of ~/.claude.json and Claude
+// Code's OAuth tokens.
+type profile struct {
+	Name        string                     `json:"name"`
+	Config      map[string]json.RawMessage `json:"config"`
+	Credentials string                     `json:"credentials,omitempty"`
+	SavedAt     time.Time                  `json:"savedAt"`
+}
+
+func validateProfileName(name string) error {
+	if !validProfileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use lowercase letters, digits, and hyphens, starting with a letter (e.g. work, customer-x)", name)
+	}
+	return nil
+}
+
+func profileSecretName(name string) string {
+	return profileSecretPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// describe summarizes who a profile signs in as, without secrets.
+func (p *profile) describe() string {
+	var account struct {
+		Email string `json:"emailAddress"`
+		Org   string `json:"organizationName"`
+	}
+	if raw, ok := p.Config["oauthAccount"]; ok && json.Unmarshal(raw, &account) == nil && account.Email != "" {
+		if account.Org != "" {
+			return fmt.Sprintf("Claude account %s (%s)", account.Email, account.Org)
+		}
+		return "Claude account " + account.Email
+	}
+	var key string
+	if raw, ok := p.Config["primaryApiKey"]; ok && json.Unmarshal(raw, &key) == nil && key != "" {
+		return "API key " + maskKey(key)
+	}
+	return "not signed in"
+}
+
+// identity returns the account or API key p signs in as, for telling apart a
+// refreshed sign-in from a different one.
+func (p *profile) identity() string {
+	var account struct {
+		UUID  string `json:"accountUuid"`
+		Email string `json:"emailAddress"`
+	}
+	if raw, ok := p.Config["oauthAccount"]; ok && json.Unmarshal(raw, &account) == nil {
+		return "account:" + account.UUID + ":" + account.Email
+	}
+	var key string
+	if raw, ok := p.Config["primaryApiKey"]; ok && json.Unmarshal(raw, &key) == nil {
+		return "key:" + key
+	}
+	return ""
+}
+
+// signedIn reports whether p holds any credentials.
+func (p *profile) signedIn() bool {
+	return len(p.Config) > 0 || p.Credentials != ""
+}
+
+// profileStore keeps profiles in the credential store and tracks which one is
+// live in home.
+type profileStore struct {
+	home  string
+	store secrets.Store
+}
+
+func openProfiles(home string) (*profileStore, error) {
+	store, err := openSecretStore()
+	if err != nil {
+		return nil, fmt.Errorf("opening the credential store: %w", err)
+	}
+	return &profileStore{home: home, store: store}, nil
+}
+
+func (ps *profileStore) get(name string) (*profile, error) {
+	data, err := ps.store.Get(profileSecretName(name))
+	if errors.Is(err, secrets.ErrNotFound) {
+		return nil, fmt.Errorf("no auth profile named %q; list them with 'claude-workspace auth profiles'", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p profile
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return nil, fmt.Errorf("reading auth profile %q: %w", name, err)
+	}
+	return &p, nil
+}
+
+func (ps *profileStore) put(p *profile) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := ps.store.Set(profileSecretName(p.Name), string(data)); err != nil {
+		return fmt.Errorf("saving auth profile %q: %w", p.Name, err)
+	}
+	return nil
+}
+
+// list returns the saved profiles sorted by name.
+func (ps *profileStore) list() ([]*profile, error) {
+	names, err := ps.store.List()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*profile
+	for _, secret := range names {
+		if !strings.HasPrefix(secret, profileSecretPrefix) {
+			continue
+		}
+		name := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(secret, profileSecretPrefix), "_", "-"))
+		p, err := ps.get(name)
+		if err != nil {
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// capture reads the sign-in that is live now into a profile named name.
+func (ps *profileStore) capture(name string) (*profile, error) {
+	p := &profile{Name: name, Config: map[string]json.RawMessage{}, SavedAt: time.Now().UTC()}
+	claudeJSON := filepath.Join(ps.home, ".claude.json")
+	if platform.FileExists(claudeJSON) {
+		cfg, err := platform.ReadJSONFileRaw(claudeJSON)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range authConfigKeys {
+			if raw, ok := cfg[key]; ok && string(raw) != "null" {
+				p.Config[key] = raw
+			}
+		}
+	}
+	creds, err := readCredentials(ps.home)
+	if err != nil {
+		return nil, err
+	}
+	p.Credentials = creds
+	return p, nil
+}
+
+// apply makes p the live sign-in, replacing the auth keys of ~/.claude.json
+// and Claude Code's OAuth tokens. Everything else in ~/.claude.json is kept.
+func (ps *profileStore) apply(p *profile) error {
+	claudeJSON := filepath.Join(ps.home, ".claude.json")
+	err := platform.WithFileLock(claudeJSON, func() error {
+		cfg := map[string]json.RawMessage{}
+		mode := os.FileMode(0600)
+		if info, err := os.Stat(claudeJSON); err == nil {
+			mode = info.Mode().Perm()
+			if cfg, err = platform.ReadJSONFileRaw(claudeJSON); err != nil {
+				return err
+			}
+			if cfg == nil {
+				cfg = map[string]json.RawMessage{}
+			}
+		}
+		for _, key := range authConfigKeys {
+			if raw, ok := p.Config[key]; ok {
+				cfg[key] = raw
+			} else {
+				delete(cfg, key)
+			}
+		}
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := platform.WriteFileAtomic(claudeJSON, append(data, '\n'), mode); err != nil {
+			return fmt.Errorf("writing ~/.claude.json: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeCredentials(ps.home, p.Credentials)
+}
+
+func activeProfile() string {
+	return platform.ConfigString(configActiveProfile)
+}
+
+// pinnedProfile returns the profile the project in dir is pinned to.
+func pinnedProfile(dir string) string {
+	var settings struct {
+		Env map[string]interface{} `json:"env"`
+	}
+	path := filepath.Join(dir, ".claude", "settings.local.json")
+	if !platform.FileExists(path) || platform.ReadJSONFile(path, &settings) != nil {
+		return ""
+	}
+	name, _ := settings.Env[PinEnvVar].(string)
+	return name
+}
+
+func credentialsPath(home string) string {
+	return filepath.Join(home, ".claude", ".credentials.json")
+}
+
+// readCredentials returns Claude Code's OAuth tokens, or "" when there are
+// none.
+func readCredentials(home string) (string, error) {
+	if credentialsOnKeychain {
+		out, err := platform.Output("security", "find-generic-password", "-s", keychainService, "-w")
+		if err != nil {
+			return "", nil
+		}
+		return out, nil
+	}
+	data, err := os.ReadFile(credentialsPath(home))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading Claude Code credentials: %w", err)
+	}
+	return string(data), nil
+}
+
+// writeCredentials replaces Claude Code's OAuth tokens with creds, removing
+// them when creds is empty.
+func writeCredentials(home, creds string) error {
+	if credentialsOnKeychain {
+		platform.RunQuiet("security", "delete-generic-password", "-s", keychainService)
+		if creds == "" {
+			return nil
+		}
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", secrets.QuoteSecurityArg(keychainService), secrets.QuoteSecurityArg(os.Getenv("USER")), secrets.QuoteSecurityArg(creds))
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := platform.RunDirWithStdin(ctx, "", cmd, "security", "-i"); err != nil {
+			return fmt.Errorf("storing Claude Code credentials in the Keychain: %w", err)
+		}
+		return nil
+	}
+	path := credentialsPath(home)
+	if creds == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return platform.WriteFileAtomic(path, []byte(creds), 0600)
+}
+
+// runProfiles handles "auth profiles [list|save|rm]".
+func runProfiles(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	ps, err := openProfiles(home)
+	if err != nil {
+		return err
+	}
+	out := platform.Stdout()
+	sub := "list"
+	if len(args) > 0 {
+		sub, args = args[0], args[1:]
+	}
+	switch sub {
+	case "list":
+		if _, err := cli.Operands("auth profiles list", "auth profiles list", args, 0, 0); err != nil {
+			return err
+		}
+		cwd, _ := os.Getwd()
+		return ps.printList(out, cwd)
+	case "save":
+		args, err := cli.Operands("auth profiles save", "auth profiles save <name>", args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return ps.save(out, args[0])
+	case "rm", "remove":
+		args, err := cli.Operands("auth profiles rm", "auth profiles rm <name>", args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return ps.remove(out, args[0])
+	default:
+		return fmt.Errorf("unknown auth profiles subcommand: %s\nUsage: claude-workspace auth profiles [list|save <name>|rm <name>]", sub)
+	}
+}
+
+func (ps *profileStore) printList(w io.Writer, cwd string) error {
+	profiles, err := ps.list()
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "Auth Profiles")
+	fmt.Fprintln(w)
+	if len(profiles) == 0 {
+		fmt.Fprintln(w, "  No profiles saved. Save the current sign-in with:")
+		platform.PrintCommand(w, "claude-workspace auth profiles save <name>")
+		return nil
+	}
+	active, pinned := activeProfile(), pinnedProfile(cwd)
+	for _, p := range profiles {
+		marker := "  "
+		if p.Name == active {
+			marker = platform.Green("* ")
+		}
+		note := ""
+		if p.Name == pinned {
+			note = " [pinned here]"
+		}
+		fmt.Fprintf(w, "  %s%-16s %s%s\n", marker, p.Name, p.describe(), note)
+	}
+	fmt.Fprintln(w)
+	if pinned != "" && pinned != active {
+		platform.PrintWarn(w, fmt.Sprintf("This project is pinned to '%s' but '%s' is active; switch with 'claude-workspace auth use'", pinned, active))
+	} else if active != "" {
+		fmt.Fprintln(w, "  * active")
+	}
+	return nil
+}
+
+// save captures the live sign-in as name. The saved profile becomes the
+// active one, since its credentials are the ones in use.
+func (ps *profileStore) save(w io.Writer, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	p, err := ps.capture(name)
+	if err != nil {
+		return err
+	}
+	if !p.signedIn() {
+		return fmt.Errorf("Claude Code is not signed in; sign in with 'claude' first, then save the profile")
+	}
+	if err := ps.put(p); err != nil {
+		return err
+	}
+	if err := platform.WriteConfig(configActiveProfile, name); err != nil {
+		return err
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Saved the current sign-in (%s) as profile '%s'.", p.describe(), name))
+	return nil
+}
+
+func (ps *profileStore) remove(w io.Writer, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if _, err := ps.get(name); err != nil {
+		return err
+	}
+	if err := ps.store.Delete(profileSecretName(name)); err != nil {
+		return err
+	}
+	if activeProfile() == name {
+		// The sign-in stays live; it is just no longer saved anywhere.
+		if err := platform.DeleteConfig(configActiveProfile); err != nil {
+			return err
+		}
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Removed profile '%s'.", name))
+	return nil
+}
+
+type useOptions struct {
+	Name    string
+	Pin     bool
+	Unpin   bool
+	Discard bool
+}
+
+func parseUseArgs(args []string) (useOptions, error) {
+	var opts useOptions
+	f := cli.New("auth use", "auth use [<profile>] [--pin|--unpin] [--discard]")
+	f.Help = printHelp
+	f.BoolVar(&opts.Pin, "--pin", "Also pin this project to the profile")
+	f.BoolVar(&opts.Unpin, "--unpin", "Remove this project's pin")
+	f.BoolVar(&opts.Discard, "--discard", "Replace a sign-in that is not saved in any profile")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return opts, err
+	}
+	if len(positional) == 1 {
+		opts.Name = positional[0]
+	}
+	if opts.Unpin && (opts.Pin || opts.Name != "") {
+		return opts, fmt.Errorf("--unpin takes no profile and cannot be combined with --pin")
+	}
+	if opts.Pin && opts.Name == "" {
+		return opts, fmt.Errorf("--pin requires a profile name")
+	}
+	if opts.Name != "" {
+		if err := validateProfileName(opts.Name); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// runUse handles "auth use": switching the live sign-in to a profile, and
+// pinning the current project to one.
+func runUse(args []string) error {
+	opts, err := parseUseArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	out := platform.Stdout()
+
+	if opts.Unpin {
+		if err := config.DeleteSettingsValue("env."+PinEnvVar, config.ScopeLocal, home, cwd); err != nil {
+			return err
+		}
+		platform.PrintSuccess(out, "Removed the auth profile pin from .claude/settings.local.json.")
+		return nil
+	}
+	if opts.Name == "" {
+		if opts.Name = pinnedProfile(cwd); opts.Name == "" {
+			return fmt.Errorf("this project is not pinned to a profile; name one: claude-workspace auth use <profile>")
+		}
+		fmt.Fprintf(out, "  This project is pinned to '%s'.\n", opts.Name)
+	}
+
+	ps, err := openProfiles(home)
+	if err != nil {
+		return err
+	}
+	if err := ps.use(out, opts.Name, opts.Discard); err != nil {
+		return err
+	}
+	if opts.Pin {
+		if err := config.WriteSettingsValue("env."+PinEnvVar, opts.Name, config.ScopeLocal, home, cwd); err != nil {
+			return err
+		}
+		platform.PrintSuccess(out, fmt.Sprintf("Pinned this project to '%s' in .claude/settings.local.json.", opts.Name))
+	}
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		platform.PrintWarn(out, "ANTHROPIC_API_KEY is set in your environment and takes precedence over the profile; unset it to use the profile")
+	}
+	return nil
+}
+
+// use makes profile name live. The sign-in it replaces is saved back to the
+// active profile first, keeping any tokens Claude Code refreshed since the
+// last switch. A sign-in that is in no profile is only replaced with discard.
+func (ps *profileStore) use(w io.Writer, name string, discard bool) error {
+	target, err := ps.get(name)
+	if err != nil {
+		return err
+	}
+	current, err := ps.capture(activeProfile())
+	if err != nil {
+		return err
+	}
+	// Save back only when the live sign-in is still the active profile's
+	// account: after a manual /login as someone else it is not.
+	saved := false
+	if current.Name != "" {
+		if prev, err := ps.get(current.Name); err == nil && prev.identity() == current.identity() {
+			if err := ps.put(current); err != nil {
+				return err
+			}
+			saved = true
+		}
+	}
+	if !saved && current.signedIn() && !discard {
+		return errUnsaved(current)
+	}
+
+	if err := ps.apply(target); err != nil {
+		return err
+	}
+	if err := platform.WriteConfig(configActiveProfile, name); err != nil {
+		return err
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Switched to profile '%s' (%s). Restart running Claude Code sessions to use it.", name, target.describe()))
+	return nil
+}
+
+func errUnsaved(current *profile) error {
+	return fmt.Errorf("the current sign-in (%s) is not saved in a profile and would be lost\n"+
+		"Save it first with 'claude-workspace auth profiles save <name>', or pass --discard", current.describe())
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// withProfiles sets up a home directory with a file-based credentials store
+// and returns the profile store over it.
+func withProfiles(t *testing.T) (*profileStore, memStore) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	old := credentialsOnKeychain
+	credentialsOnKeychain = false
+	t.Cleanup(func() { credentialsOnKeychain = old })
+	store := memStore{}
+	withSecretStore(t, store)
+	return &profileStore{home: home, store: store}, store
+}
+
+func writeLive(t *testing.T, home, claudeJSON, creds string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(home, ".claude.json"), []byte(claudeJSON), 0600)
+	if err := writeCredentials(home, creds); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProfiles_SaveAndUse(t *testing.T) {
+	ps, store := withProfiles(t)
+	home := ps.home
+	writeLive(t, home, `{"numStartups": 7, "oauthAccount": {"emailAddress": "me@work.example", "organizationName": "Work"}}`, `{"token":"work-1"}`)
+
+	if err := ps.save(io.Discard, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if activeProfile() != "work" {
+		t.Errorf("active = %q after save", activeProfile())
+	}
+
+	// Sign in as someone else by hand, then save that too.
+	writeLive(t, home, `{"numStartups": 8, "primaryApiKey": "`+oldKey+`"}`, "")
+	if err := ps.save(io.Discard, "personal"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ps.use(io.Discard, "work", false); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := platform.ReadJSONFileRaw(filepath.Join(home, ".claude.json"))
+	if _, ok := cfg["primaryApiKey"]; ok || !strings.Contains(string(cfg["oauthAccount"]), "me@work.example") || string(cfg["numStartups"]) != "8" {
+		t.Errorf("~/.claude.json after use work: %v", cfg)
+	}
+	if creds, _ := readCredentials(home); creds != `{"token":"work-1"}` {
+		t.Errorf("credentials = %q", creds)
+	}
+
+	// Claude Code refreshes its token; switching away keeps the new one.
+	writeCredentials(home, `{"token":"work-2"}`)
+	if err := ps.use(io.Discard, "personal", false); err != nil {
+		t.Fatal(err)
+	}
+	if creds, _ := readCredentials(home); creds != "" {
+		t.Errorf("personal profile left OAuth credentials behind: %q", creds)
+	}
+	work, err := ps.get("work")
+	if err != nil || work.Credentials != `{"token":"work-2"}` {
+		t.Errorf("work profile after switching away = %+v, %v", work, err)
+	}
+	if activeProfile() != "personal" {
+		t.Errorf("active = %q", activeProfile())
+	}
+
+	profiles, _ := ps.list()
+	if len(profiles) != 2 || profiles[0].Name != "personal" || profiles[1].Name != "work" {
+		t.Errorf("list = %v", profiles)
+	}
+	if got := profiles[1].describe(); got != "Claude account me@work.example (Work)" {
+		t.Errorf("describe = %q", got)
+	}
+	if _, ok := store[profileSecretName("work")]; !ok {
+		t.Errorf("store = %v", store)
+	}
+}
+
+func TestProfiles_UnsavedSignIn(t *testing.T) {
+	ps, _ := withProfiles(t)
+	writeLive(t, ps.home, `{"primaryApiKey": "`+oldKey+`"}`, "")
+	ps.save(io.Discard, "work")
+	platform.DeleteConfig(configActiveProfile)
+	writeLive(t, ps.home, `{"primaryApiKey": "`+newKey+`"}`, "")
+
+	err := ps.use(io.Discard, "work", false)
+	if err == nil || !strings.Contains(err.Error(), "not saved in a profile") {
+		t.Fatalf("use over an unsaved sign-in = %v", err)
+	}
+	if err := ps.use(io.Discard, "work", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// A manual /login as someone else while "work" is active is not saved
+	// over the work profile.
+	writeLive(t, ps.home, `{"oauthAccount": {"accountUuid": "u-2", "emailAddress": "me@other.example"}}`, `{"token":"other"}`)
+	if err := ps.use(io.Discard, "work", false); err == nil {
+		t.Error("use after signing in as another account: expected error")
+	}
+	if work, _ := ps.get("work"); work.identity() != "key:"+oldKey {
+		t.Errorf("work profile overwritten: %+v", work)
+	}
+	if _, err := ps.get("nope"); err == nil {
+		t.Error("missing profile: expected error")
+	}
+}
+
+func TestProfiles_Remove(t *testing.T) {
+	ps, store := withProfiles(t)
+	writeLive(t, ps.home, `{"primaryApiKey": "`+oldKey+`"}`, "")
+	ps.save(io.Discard, "work")
+	if err := ps.remove(io.Discard, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store) != 0 || activeProfile() != "" {
+		t.Errorf("after remove: store = %v, active = %q", store, activeProfile())
+	}
+	if err := ps.save(io.Discard, "Work"); err == nil {
+		t.Error("invalid name: expected error")
+	}
+}
+
+func TestPinnedProfile(t *testing.T) {
+	dir := t.TempDir()
+	if got := pinnedProfile(dir); got != "" {
+		t.Errorf("no settings: pinned = %q", got)
+	}
+	os.MkdirAll(filepath.Join(dir, ".claude"), 0755)
+	os.WriteFile(filepath.Join(dir, ".claude", "settings.local.json"), []byte(`{"env": {"`+PinEnvVar+`": "customer-x"}}`), 0644)
+	if got := pinnedProfile(dir); got != "customer-x" {
+		t.Errorf("pinned = %q", got)
+	}
+}
+
+func TestParseUseArgs(t *testing.T) {
+	opts, err := parseUseArgs([]string{"customer-x", "--pin"})
+	if err != nil || opts.Name != "customer-x" || !opts.Pin {
+		t.Errorf("parseUseArgs = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{"--pin"}, {"work", "--unpin"}, {"Work"}, {"a", "b"}, {"--force"}} {
+		if _, err := parseUseArgs(args); err == nil {
+			t.Errorf("parseUseArgs(%q): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+// defaultBaseURL is the API a key is validated against unless
+// ANTHROPIC_BASE_URL points elsewhere.
+const defaultBaseURL = "https://api.anthropic.com"
+
+// approvedSuffixLen is how much of a key Claude Code keeps in
+// customApiKeyResponses to remember that the user approved it.
+const approvedSuffixLen = 20
+
+// Tests replace these.
+var (
+	openSecretStore = secrets.Open
+	promptSecret    = platform.PromptSecret
+)
+
+type rotateOptions struct {
+	FromEnv    string
+	NoValidate bool
+	OAuth      bool
+}
+
+func parseRotateArgs(args []string) (rotateOptions, error) {
+	var opts rotateOptions
+	f := cli.New("auth rotate", "auth rotate [--from-env <VAR>] [--no-validate] [--oauth]")
+	f.Help = printHelp
+	f.Func("--from-env", "VAR", "Read the new key from an environment variable", func(v string) error {
+		if v == "" {
+			return fmt.Errorf("--from-env requires a variable name")
+		}
+		opts.FromEnv = v
+		return nil
+	})
+	f.BoolVar(&opts.NoValidate, "--no-validate", "Skip the API check")
+	f.BoolVar(&opts.OAuth, "--oauth", "Sign in to Claude Code again with a Claude account")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	if opts.OAuth && (opts.FromEnv != "" || opts.NoValidate) {
+		return opts, fmt.Errorf("--oauth signs in with a Claude account; it cannot be combined with --from-env or --no-validate")
+	}
+	return opts, nil
+}
+
+func rotate(args []string) error {
+	opts, err := parseRotateArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	out := platform.Stdout()
+	if opts.OAuth {
+		platform.PrintBanner(out, "Sign In Again")
+		return relogin(out)
+	}
+	platform.PrintBanner(out, "Rotate API Key")
+	fmt.Fprintln(out)
+
+	claudeJSON := filepath.Join(home, ".claude.json")
+	oldKey, source, err := currentKey(claudeJSON)
+	if err != nil {
+		return err
+	}
+	if oldKey != "" {
+		fmt.Fprintf(out, "  Current key: %s (%s)\n", maskKey(oldKey), source)
+	} else if source != "" {
+		return fmt.Errorf("Claude Code is signed in with a Claude account, not an API key; run 'claude-workspace auth rotate --oauth' to sign in again")
+	}
+
+	newKey, err := readNewKey(opts.FromEnv)
+	if err != nil {
+		return err
+	}
+	baseURL := strings.TrimSuffix(os.Getenv("ANTHROPIC_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	if err := checkKey(newKey, oldKey, baseURL); err != nil {
+		return err
+	}
+	if !opts.NoValidate {
+		fmt.Fprintf(out, "  Validating the new key against %s...\n", baseURL)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		if err := validateKey(ctx, baseURL, newKey); err != nil {
+			return err
+		}
+	}
+
+	r := &rotation{oldKey: oldKey, newKey: newKey, stamp: time.Now().Format("20060102-150405")}
+	if err := r.run(home); err != nil {
+		return err
+	}
+	r.print(out, home)
+	if source == "$ANTHROPIC_API_KEY" {
+		platform.PrintWarn(out, "ANTHROPIC_API_KEY is set in your environment and takes precedence over ~/.claude.json; update it where it is set (shell rc file, secret manager)")
+	}
+	return nil
+}
+
+// currentKey returns the API key Claude Code uses now and where it comes from.
+// A config with only a Claude account login returns an empty key and a source.
+func currentKey(claudeJSON string) (key, source string, err error) {
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+		return key, "$ANTHROPIC_API_KEY", nil
+	}
+	if !platform.FileExists(claudeJSON) {
+		return "", "", nil
+	}
+	var cfg struct {
+		PrimaryAPIKey string          `json:"primaryApiKey"`
+		OAuthAccount  json.RawMessage `json:"oauthAccount"`
+	}
+	if err := platform.ReadJSONFile(claudeJSON, &cfg); err != nil {
+		return "", "", err
+	}
+	switch {
+	case cfg.PrimaryAPIKey != "":
+		return cfg.PrimaryAPIKey, "~/.claude.json", nil
+	case len(cfg.OAuthAccount) > 0 && string(cfg.OAuthAccount) != "null":
+		return "", "Claude account", nil
+	}
+	return "", "", nil
+}
+
+func readNewKey(fromEnv string) (string, error) {
+	if fromEnv != "" {
+		key := strings.TrimSpace(os.Getenv(fromEnv))
+		if key == "" {
+			return "", fmt.Errorf("$%s is not set", fromEnv)
+		}
+		return key, nil
+	}
+	key, err := promptSecret("  Enter new API key: ")
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", fmt.Errorf("no API key provided")
+	}
+	return key, nil
+}
+
+// checkKey rejects keys that cannot be right before any request is made.
+func checkKey(newKey, oldKey, baseURL string) error {
+	switch {
+	case strings.ContainsAny(newKey, " \t\r\n"):
+		return fmt.Errorf("the new key contains whitespace; paste only the key")
+	case newKey == oldKey:
+		return fmt.Errorf("the new key is the same as the current one")
+	case baseURL == defaultBaseURL && !strings.HasPrefix(newKey, "sk-ant-"):
+		return fmt.Errorf("the new key does not look like an Anthropic API key (sk-ant-...)")
+	}
+	return nil
+}
+
+// validateKey lists models with the key, which costs nothing and fails fast
+// for revoked or mistyped keys.
+func validateKey(ctx context.Context, baseURL, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v1/models?limit=1", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-key", key)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	resp, err := platform.HTTPClient(20 * time.Second).Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach %s to validate the key: %w\nPass --no-validate to skip the check", baseURL, err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("the API rejected the new key (%s); nothing was changed", resp.Status)
+	default:
+		return fmt.Errorf("validating the new key: unexpected response %s\nPass --no-validate to skip the check", resp.Status)
+	}
+}
+
+// relogin runs Claude Code's own login, for accounts that sign in with OAuth.
+func relogin(w io.Writer) error {
+	fmt.Fprintln(w, "\n  Opening Claude Code's login. Choose your Claude account, or the")
+	fmt.Fprintln(w, "  Anthropic Console to create an API key. Exit Claude Code when done.")
+	fmt.Fprintln(w)
+	exitCode, err := platform.RunSpawn("claude", "/login")
+	if err != nil {
+		return fmt.Errorf("could not run 'claude' command. Is Claude Code installed?")
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("claude exited with code %d", exitCode)
+	}
+	if !platform.IsClaudeAuthenticated() {
+		return fmt.Errorf("Claude Code is still not signed in")
+	}
+	platform.PrintSuccess(w, "Signed in. Restart running Claude Code sessions to use the new login.")
+	return nil
+}
+
+// rotation replaces an API key everywhere claude-workspace knows it to be
+// stored, backing up each file before changing it.
+type rotation struct {
+	oldKey, newKey string
+	stamp          string // suffix for backup files
+
+	changes []string
+	kept    []string // keys left alone because they are not the old key
+	backups []string
+}
+
+func (r *rotation) run(home string) error {
+	claudeJSON := filepath.Join(home, ".claude.json")
+	if err := r.editJSON(claudeJSON, "~/.claude.json", r.claudeConfig); err != nil {
+		return err
+	}
+	settings := filepath.Join(home, ".claude", "settings.json")
+	if platform.FileExists(settings) {
+		if err := r.editJSON(settings, "~/.claude/settings.json", r.settingsEnv); err != nil {
+			return err
+		}
+	}
+	return r.secretStore()
+}
+
+// editJSON applies edit to the JSON file at path and, if it changed
+// anything, backs the file up and replaces it atomically.
+func (r *rotation) editJSON(path, label string, edit func(root map[string]interface{}, label string) bool) error {
+	return platform.WithFileLock(path, func() error {
+		root := map[string]interface{}{}
+		mode := os.FileMode(0600)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+			if err := platform.ReadJSONFile(path, &root); err != nil {
+				return err
+			}
+			if root == nil {
+				root = map[string]interface{}{}
+			}
+		}
+		if !edit(root, label) {
+			return nil
+		}
+		data, err := json.MarshalIndent(root, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling %s: %w", label, err)
+		}
+		if platform.FileExists(path) {
+			backup := path + ".bak-" + r.stamp
+			if err := platform.CopyFile(path, backup); err != nil {
+				return fmt.Errorf("backing up %s: %w", label, err)
+			}
+			r.backups = append(r.backups, backup)
+		}
+		if err := platform.WriteFileAtomic(path, append(data, '\n'), mode); err != nil {
+			return fmt.Errorf("writing %s: %w", label, err)
+		}
+		return nil
+	})
+}
+
+// claudeConfig updates ~/.claude.json: the key Claude Code uses, its record
+// of approved keys, and MCP servers in every project.
+func (r *rotation) claudeConfig(root map[string]interface{}, label string) bool {
+	changed := false
+	// primaryApiKey is replaced only when it holds the key being rotated, or
+	// is unset with no key in use at all. When the old key comes from
+	// $ANTHROPIC_API_KEY, a different primaryApiKey is someone else's choice
+	// and is kept, and a missing one is not added.
+	current, _ := root["primaryApiKey"].(string)
+	if current != "" && current != r.oldKey {
+		r.kept = append(r.kept, fmt.Sprintf("%s: primaryApiKey holds another key (%s)", label, maskKey(current)))
+	}
+	if current == r.oldKey {
+		root["primaryApiKey"] = r.newKey
+		r.changes = append(r.changes, label+": primaryApiKey")
+		changed = true
+		// Approve the new key so Claude Code does not ask about it again.
+		if responses, ok := root["customApiKeyResponses"].(map[string]interface{}); ok {
+			approved := withoutSuffix(stringList(responses["approved"]), keySuffix(r.oldKey))
+			responses["approved"] = append(withoutSuffix(approved, keySuffix(r.newKey)), keySuffix(r.newKey))
+			responses["rejected"] = withoutSuffix(stringList(responses["rejected"]), keySuffix(r.newKey))
+		}
+	}
+
+	changed = r.mcpServers(root["mcpServers"], label, "") || changed
+	projects, _ := root["projects"].(map[string]interface{})
+	dirs := make([]string, 0, len(projects))
+	for dir := range projects {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		project, _ := projects[dir].(map[string]interface{})
+		changed = r.mcpServers(project["mcpServers"], label, dir) || changed
+	}
+	return changed
+}
+
+// mcpServers replaces the old key in the env vars and headers of each server.
+func (r *rotation) mcpServers(v interface{}, label, project string) bool {
+	servers, _ := v.(map[string]interface{})
+	if r.oldKey == "" || len(servers) == 0 {
+		return false
+	}
+	where := label
+	if project != "" {
+		where = fmt.Sprintf("%s (%s)", label, project)
+	}
+	changed := false
+	for _, name := range sortedKeys(servers) {
+		entry, _ := servers[name].(map[string]interface{})
+		for _, field := range []string{"env", "headers"} {
+			values, _ := entry[field].(map[string]interface{})
+			for _, key := range sortedKeys(values) {
+				if s, ok := values[key].(string); ok && strings.Contains(s, r.oldKey) {
+					values[key] = strings.ReplaceAll(s, r.oldKey, r.newKey)
+					r.changes = append(r.changes, fmt.Sprintf("%s: MCP server %s %s %s", where, name, field, key))
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// settingsEnv replaces the old key in the env block of a settings file.
+func (r *rotation) settingsEnv(root map[string]interface{}, label string) bool {
+	env, _ := root["env"].(map[string]interface{})
+	if r.oldKey == "" {
+		return false
+	}
+	changed := false
+	for _, key := range sortedKeys(env) {
+		if s, ok := env[key].(string); ok && s == r.oldKey {
+			env[key] = r.newKey
+			r.changes = append(r.changes, fmt.Sprintf("%s: env %s", label, key))
+			changed = true
+		}
+	}
+	return changed
+}
+
+// secretStore updates credential store entries that hold the old key, such
+// as those injected into MCP servers by "secrets exec".
+func (r *rotation) secretStore() error {
+	if r.oldKey == "" {
+		return nil
+	}
+	store, err := openSecretStore()
+	if err != nil {
+		return nil
+	}
+	names, err := store.List()
+	if err != nil {
+		return nil
+	}
+	for _, name := range names {
+		if value, err := store.Get(name); err != nil || value != r.oldKey {
+			continue
+		}
+		if err := store.Set(name, r.newKey); err != nil {
+			return fmt.Errorf("updating %s in the credential store: %w", name, err)
+		}
+		r.changes = append(r.changes, fmt.Sprintf("credential store (%s): %s", store.Backend(), name))
+	}
+	return nil
+}
+
+func (r *rotation) print(w io.Writer, home string) {
+	platform.PrintSection(w, "Updated")
+	for _, c := range r.changes {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+	if len(r.kept) > 0 {
+		platform.PrintSection(w, "Not Changed")
+		for _, k := range r.kept {
+			fmt.Fprintf(w, "  %s\n", k)
+		}
+	}
+	if len(r.backups) > 0 {
+		platform.PrintSection(w, "Backups")
+		for _, b := range r.backups {
+			fmt.Fprintf(w, "  %s\n", strings.Replace(b, home, "~", 1))
+		}
+	}
+	fmt.Fprintln(w)
+	platform.PrintSuccess(w, "API key rotated. Restart Claude Code sessions and MCP servers to use it.")
+	if r.oldKey != "" {
+		fmt.Fprintln(w, "  Revoke the old key in the Anthropic Console once everything works.")
+	}
+}
+
+// keySuffix returns the part of key Claude Code records when a key is approved.
+func keySuffix(key string) string {
+	if len(key) > approvedSuffixLen {
+		return key[len(key)-approvedSuffixLen:]
+	}
+	return key
+}
+
+func withoutSuffix(list []interface{}, suffix string) []interface{} {
+	kept := []interface{}{}
+	for _, v := range list {
+		if v != suffix || suffix == "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func stringList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// maskKey shows enough of a key to recognize it.
+func maskKey(key string) string {
+	if len(key) <= 12 {
+		return "****"
+	}
+	return key[:7] + "..." + key[len(key)-4:]
+}
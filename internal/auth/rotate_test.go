@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+const (
+	oldKey = "sk-ant-REDACTED"
+	newKey = "sk-ant-REDACTED"
+)
+
+type memStore map[string]string
+
+func (m memStore) Backend() string { return "memory" }
+func (m memStore) Get(name string) (string, error) {
+	if v, ok := m[name]; ok {
+		return v, nil
+	}
+	return "", secrets.ErrNotFound
+}
+func (m memStore) Set(name, value string) error { m[name] = value; return nil }
+func (m memStore) Delete(name string) error     { delete(m, name); return nil }
+func (m memStore) List() ([]string, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func withSecretStore(t *testing.T, store secrets.Store) {
+	t.Helper()
+	old := openSecretStore
+	openSecretStore = func() (secrets.Store, error) { return store, nil }
+	t.Cleanup(func() { openSecretStore = old })
+}
+
+func TestRotation(t *testing.T) {
+	home := t.TempDir()
+	claudeJSON := filepath.Join(home, ".claude.json")
+	os.WriteFile(claudeJSON, []byte(`{
+  "primaryApiKey": "`+oldKey+`",
+  "customApiKeyResponses": {"approved": ["`+keySuffix(oldKey)+`", "other"], "rejected": ["`+keySuffix(newKey)+`"]},
+  "mcpServers": {
+    "claude-proxy": {"command": "npx", "env": {"ANTHROPIC_API_KEY": "`+oldKey+`", "MODE": "fast"}},
+    "gw": {"type": "http", "url": "https://gw.example.com", "headers": {"Authorization": "Bearer `+oldKey+`"}}
+  },
+  "projects": {"/work/app": {"mcpServers": {"local": {"command": "x", "env": {"KEY": "`+oldKey+`"}}}}}
+}`), 0600)
+	os.MkdirAll(filepath.Join(home, ".claude"), 0755)
+	settings := filepath.Join(home, ".claude", "settings.json")
+	os.WriteFile(settings, []byte(`{"env": {"ANTHROPIC_API_KEY": "`+oldKey+`", "OTHER": "x"}}`), 0644)
+	store := memStore{"ANTHROPIC_API_KEY": oldKey, "BRAVE_API_KEY": "brave"}
+	withSecretStore(t, store)
+
+	r := &rotation{oldKey: oldKey, newKey: newKey, stamp: "20260101-000000"}
+	if err := r.run(home); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg map[string]interface{}
+	if err := platform.ReadJSONFile(claudeJSON, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg["primaryApiKey"] != newKey {
+		t.Errorf("primaryApiKey = %v", cfg["primaryApiKey"])
+	}
+	responses := cfg["customApiKeyResponses"].(map[string]interface{})
+	if got := responses["approved"].([]interface{}); len(got) != 2 || got[0] != "other" || got[1] != keySuffix(newKey) {
+		t.Errorf("approved = %v", got)
+	}
+	if got := responses["rejected"].([]interface{}); len(got) != 0 {
+		t.Errorf("rejected = %v", got)
+	}
+	data, _ := os.ReadFile(claudeJSON)
+	if strings.Contains(string(data), oldKey) {
+		t.Errorf("old key still in ~/.claude.json:\n%s", data)
+	}
+	if info, _ := os.Stat(claudeJSON); info.Mode().Perm() != 0600 {
+		t.Errorf("~/.claude.json mode = %v, want 0600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(settings); strings.Contains(string(data), oldKey) || !strings.Contains(string(data), `"OTHER": "x"`) {
+		t.Errorf("settings.json:\n%s", data)
+	}
+	if store["ANTHROPIC_API_KEY"] != newKey || store["BRAVE_API_KEY"] != "brave" {
+		t.Errorf("store = %v", store)
+	}
+
+	if len(r.backups) != 2 {
+		t.Fatalf("backups = %v", r.backups)
+	}
+	if data, _ := os.ReadFile(claudeJSON + ".bak-20260101-000000"); !strings.Contains(string(data), oldKey) {
+		t.Error("backup does not hold the old config")
+	}
+	want := []string{
+		"~/.claude.json: primaryApiKey",
+		"~/.claude.json: MCP server claude-proxy env ANTHROPIC_API_KEY",
+		"~/.claude.json: MCP server gw headers Authorization",
+		"~/.claude.json (/work/app): MCP server local env KEY",
+		"~/.claude/settings.json: env ANTHROPIC_API_KEY",
+		"credential store (memory): ANTHROPIC_API_KEY",
+	}
+	if strings.Join(r.changes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(r.changes, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRotation_KeyFromEnvironment(t *testing.T) {
+	// The key in use comes from $ANTHROPIC_API_KEY, so ~/.claude.json has no
+	// primaryApiKey to replace and gets none added.
+	home := t.TempDir()
+	claudeJSON := filepath.Join(home, ".claude.json")
+	os.WriteFile(claudeJSON, []byte(`{"numStartups": 3}`), 0644)
+	withSecretStore(t, memStore{})
+
+	r := &rotation{oldKey: oldKey, newKey: newKey, stamp: "x"}
+	if err := r.run(home); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.changes) != 0 || len(r.backups) != 0 {
+		t.Errorf("changes = %v, backups = %v; want none", r.changes, r.backups)
+	}
+}
+
+func TestRotation_OtherPrimaryKey(t *testing.T) {
+	// The key being rotated comes from $ANTHROPIC_API_KEY, while
+	// ~/.claude.json holds a different key: that one is not the old key and
+	// must not be overwritten.
+	const otherKey = "sk-ant-REDACTED"
+	home := t.TempDir()
+	claudeJSON := filepath.Join(home, ".claude.json")
+	os.WriteFile(claudeJSON, []byte(`{
+  "primaryApiKey": "`+otherKey+`",
+  "mcpServers": {"proxy": {"command": "x", "env": {"ANTHROPIC_API_KEY": "`+oldKey+`"}}}
+}`), 0600)
+	withSecretStore(t, memStore{})
+
+	r := &rotation{oldKey: oldKey, newKey: newKey, stamp: "x"}
+	if err := r.run(home); err != nil {
+		t.Fatal(err)
+	}
+	var cfg map[string]interface{}
+	if err := platform.ReadJSONFile(claudeJSON, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg["primaryApiKey"] != otherKey {
+		t.Errorf("primaryApiKey = %v, want the other key kept", cfg["primaryApiKey"])
+	}
+	if want := []string{"~/.claude.json: MCP server proxy env ANTHROPIC_API_KEY"}; strings.Join(r.changes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes = %v, want %v", r.changes, want)
+	}
+	if len(r.kept) != 1 || !strings.Contains(r.kept[0], "primaryApiKey") || strings.Contains(r.kept[0], otherKey) {
+		t.Errorf("kept = %v, want a masked note about primaryApiKey", r.kept)
+	}
+}
+
+func TestRotation_FirstKey(t *testing.T) {
+	// With no key in use, the new key becomes primaryApiKey.
+	home := t.TempDir()
+	claudeJSON := filepath.Join(home, ".claude.json")
+	os.WriteFile(claudeJSON, []byte(`{"numStartups": 3}`), 0600)
+	withSecretStore(t, memStore{})
+
+	r := &rotation{newKey: newKey, stamp: "x"}
+	if err := r.run(home); err != nil {
+		t.Fatal(err)
+	}
+	var cfg map[string]interface{}
+	if err := platform.ReadJSONFile(claudeJSON, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg["primaryApiKey"] != newKey || cfg["numStartups"] != float64(3) {
+		t.Errorf("~/.claude.json = %v", cfg)
+	}
+}
+
+func TestCurrentKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".claude.json")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	if key, source, _ := currentKey(path); key != "" || source != "" {
+		t.Errorf("no config: currentKey = %q, %q", key, source)
+	}
+	os.WriteFile(path, []byte(`{"oauthAccount": {"emailAddress": "a@example.com"}}`), 0644)
+	if key, source, _ := currentKey(path); key != "" || source != "Claude account" {
+		t.Errorf("account login: currentKey = %q, %q", key, source)
+	}
+	os.WriteFile(path, []byte(`{"primaryApiKey": "`+oldKey+`"}`), 0644)
+	if key, source, _ := currentKey(path); key != oldKey || source != "~/.claude.json" {
+		t.Errorf("stored key: currentKey = %q, %q", key, source)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", newKey)
+	if key, source, _ := currentKey(path); key != newKey || source != "$ANTHROPIC_API_KEY" {
+		t.Errorf("env key: currentKey = %q, %q", key, source)
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		key, base string
+		want      string
+	}{
+		{newKey, defaultBaseURL, ""},
+		{oldKey, defaultBaseURL, "same"},
+		{"sk-ant-abc def", defaultBaseURL, "whitespace"},
+		{"gateway-token", defaultBaseURL, "does not look like"},
+		{"gateway-token", "https://llm.example.com", ""},
+	}
+	for _, tt := range tests {
+		err := checkKey(tt.key, oldKey, tt.base)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("checkKey(%q, %s) = %v, want %q", tt.key, tt.base, err, tt.want)
+		}
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Header.Get("x-api-key") {
+		case newKey:
+			w.Write([]byte(`{"data": []}`))
+		case "overloaded":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	if err := validateKey(ctx, srv.URL, newKey); err != nil {
+		t.Errorf("valid key: %v", err)
+	}
+	if err := validateKey(ctx, srv.URL, oldKey); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("revoked key: %v", err)
+	}
+	if err := validateKey(ctx, srv.URL, "overloaded"); err == nil || !strings.Contains(err.Error(), "--no-validate") {
+		t.Errorf("server error: %v", err)
+	}
+}
+
+func TestParseRotateArgs(t *testing.T) {
+	opts, err := parseRotateArgs([]string{"--from-env", "NEW_KEY", "--no-validate"})
+	if err != nil || opts.FromEnv != "NEW_KEY" || !opts.NoValidate || opts.OAuth {
+		t.Errorf("parseRotateArgs = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{"--from-env"}, {"--oauth", "--no-validate"}, {"--key", "x"}} {
+		if _, err := parseRotateArgs(args); err == nil {
+			t.Errorf("parseRotateArgs(%q): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,581 @@
+// Package backup implements the "backup" command: one archive of Claude
+// Code's user configuration, memory, and the .claude directories of the
+// projects it knows, to restore after an experiment goes wrong or on a new
+// machine.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/memory"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const usage = `Usage:
+  claude-workspace backup create [--output <file>] [--exclude-secrets] [--no-memory]
+  claude-workspace backup restore <file> [--dry-run] [--no-memory]
+  claude-workspace backup list`
+
+const (
+	// format and formatVersion identify a backup archive. Bump formatVersion
+	// when the layout changes in a way older releases cannot restore.
+	format        = "claude-workspace-backup"
+	formatVersion = 1
+
+	manifestName = "manifest.json"
+	memoryName   = "memory.json"
+
+	// maxEntrySize bounds a single file read from an archive.
+	maxEntrySize = 64 << 20
+)
+
+// homeFiles are the user configuration files backed up, relative to the home
+// directory. Their archive names are "home/" followed by this path.
+var homeFiles = []string{".claude.json", ".claude/settings.json", ".claude/CLAUDE.md"}
+
+// sharedFiles are the home files other claude-workspace commands edit under
+// platform.LockFile; they are read and written under the same lock.
+var sharedFiles = map[string]bool{".claude.json": true, ".claude/settings.json": true}
+
+// Manifest describes a backup. It is the first entry of the archive.
+type Manifest struct {
+	Format          string    `json:"format"`
+	Version         int       `json:"version"`
+	CreatedAt       string    `json:"createdAt"`
+	CLIVersion      string    `json:"cliVersion"`
+	Home            string    `json:"home"`
+	SecretsExcluded bool      `json:"secretsExcluded"`
+	Files           []Entry   `json:"files"`
+	Projects        []Project `json:"projects,omitempty"`
+}
+
+// Entry is a regular file in the archive.
+type Entry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Redacted lists the JSON paths of the secrets left out of the file.
+	Redacted [][]string `json:"redacted,omitempty"`
+}
+
+// Project is a project whose .claude directory is in the archive under
+// Dir + "/.claude".
+type Project struct {
+	Path string `json:"path"`
+	Dir  string `json:"dir"`
+}
+
+// file is an archive entry with its contents.
+type file struct {
+	hdr  *tar.Header
+	data []byte
+}
+
+// Run routes the backup subcommand.
+func Run(version string, args []string) error {
+	if len(args) == 0 || args[0] == "--help" || args[0] == "-h" {
+		printHelp(os.Stdout)
+		return nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	w := platform.Stdout()
+	switch args[0] {
+	case "create":
+		opts, err := parseCreateArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		platform.PrintBanner(w, "Backup")
+		fmt.Fprintln(w)
+		out, m, err := create(home, version, opts)
+		if err != nil {
+			return err
+		}
+		printCreated(w, home, out, m)
+		return nil
+	case "restore":
+		opts, err := parseRestoreArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		return restore(w, home, version, opts)
+	case "list", "ls":
+		if _, err := cli.Operands("backup "+args[0], "backup list", args[1:], 0, 0); err != nil {
+			return err
+		}
+		return list(w, home)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown backup subcommand: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown subcommand: %s", args[0])
+	}
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprint(w, usage+`
+
+Subcommands:
+  create             Archive ~/.claude.json, ~/.claude/settings.json, the
+                     global CLAUDE.md, a memory export, and the .claude
+                     directory of every project listed in ~/.claude.json
+  restore <file>     Put the files in a backup back in place. A backup of the
+                     current state is written first, so a restore can be
+                     undone by restoring that one.
+  list               List the backups in ~/.claude-workspace/backups
+
+Options:
+  --output <file>     create: where to write the archive (default:
+                      ~/.claude-workspace/backups/claude-backup-<time>.tar.gz)
+  --exclude-secrets   create: leave out primaryApiKey and credential-like MCP
+                      and settings env values and headers; restore keeps the
+                      values set at the time of the restore
+  --no-memory         create: skip the memory export; restore: skip the
+                      memory import
+  --dry-run           restore: show what would change without writing
+
+Files the backup does not contain are left alone by restore. Projects that
+no longer exist are skipped.
+
+Examples:
+
+  claude-workspace backup create
+  claude-workspace backup create --exclude-secrets --output ~/claude-config.tar.gz
+  claude-workspace backup restore ~/claude-config.tar.gz --dry-run
+  claude-workspace backup list
+`)
+}
+
+// newFlagSet returns the flag set of "backup <sub>", whose help is the
+// command's.
+func newFlagSet(sub, usageLine string) *cli.FlagSet {
+	f := cli.New("backup "+sub, usageLine)
+	f.Help = printHelp
+	return f
+}
+
+type createOptions struct {
+	Output         string
+	ExcludeSecrets bool
+	NoMemory       bool
+}
+
+func parseCreateArgs(args []string) (createOptions, error) {
+	var opts createOptions
+	f := newFlagSet("create", "backup create [--output <file>] [--exclude-secrets] [--no-memory]")
+	f.Func("--output", "file", "Where to write the archive", func(v string) error {
+		if v == "" {
+			return fmt.Errorf("--output requires a file")
+		}
+		opts.Output = v
+		return nil
+	})
+	f.Alias("-o", "--output")
+	f.BoolVar(&opts.ExcludeSecrets, "--exclude-secrets", "Leave out API keys and credential-like values")
+	f.BoolVar(&opts.NoMemory, "--no-memory", "Skip the memory export")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// Dir is the directory, under the home directory, that backups are written
+// to when no --output is given.
+const Dir = ".claude-workspace/backups"
+
+// backupDir returns Dir under home.
+func backupDir(home string) string {
+	return filepath.Join(home, filepath.FromSlash(Dir))
+}
+
+// create writes a backup of the configuration under home and returns its
+// path and manifest.
+func create(home, version string, opts createOptions) (string, *Manifest, error) {
+	out := opts.Output
+	name := "claude-backup-" + time.Now().Format("20060102-150405") + ".tar.gz"
+	if out == "" {
+		out = filepath.Join(backupDir(home), name)
+	} else if info, err := os.Stat(out); err == nil && info.IsDir() {
+		out = filepath.Join(out, name)
+	}
+	m, files, err := collect(home, version, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := writeArchive(out, m, files); err != nil {
+		return "", nil, fmt.Errorf("writing %s: %w", out, err)
+	}
+	return out, m, nil
+}
+
+// collect reads everything a backup holds and builds its manifest.
+func collect(home, version string, opts createOptions) (*Manifest, []file, error) {
+	m := &Manifest{
+		Format:          format,
+		Version:         formatVersion,
+		CreatedAt:       time.Now().UTC().Format(time.RFC3339),
+		CLIVersion:      version,
+		Home:            home,
+		SecretsExcluded: opts.ExcludeSecrets,
+	}
+	var files []file
+	add := func(hdr *tar.Header, data []byte, redacted [][]string) {
+		if hdr.Typeflag == tar.TypeReg {
+			sum := sha256.Sum256(data)
+			hdr.Size = int64(len(data))
+			m.Files = append(m.Files, Entry{Name: hdr.Name, Size: hdr.Size, SHA256: hex.EncodeToString(sum[:]), Redacted: redacted})
+		}
+		files = append(files, file{hdr: hdr, data: data})
+	}
+
+	var claudeJSON []byte
+	for _, rel := range homeFiles {
+		p := filepath.Join(home, filepath.FromSlash(rel))
+		data, info, err := readHomeFile(p, sharedFiles[rel])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if rel == ".claude.json" {
+			claudeJSON = data
+		}
+		var redacted [][]string
+		if opts.ExcludeSecrets && sharedFiles[rel] {
+			if data, redacted, err = redactSecrets(data); err != nil {
+				return nil, nil, fmt.Errorf("parsing %s: %w", p, err)
+			}
+		}
+		add(fileHeader("home/"+rel, info.Mode().Perm(), info.ModTime()), data, redacted)
+	}
+
+	if !opts.NoMemory {
+		data, err := memory.Snapshot()
+		if err != nil {
+			return nil, nil, fmt.Errorf("exporting memory: %w", err)
+		}
+		add(fileHeader(memoryName, 0600, time.Now()), data, nil)
+	}
+
+	for _, project := range knownProjects(claudeJSON) {
+		root := filepath.Join(project, ".claude")
+		if info, err := os.Lstat(root); err != nil || !info.IsDir() {
+			continue
+		}
+		dir := "projects/" + strconv.Itoa(len(m.Projects)+1)
+		m.Projects = append(m.Projects, Project{Path: project, Dir: dir})
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(project, p)
+			name := dir + "/" + filepath.ToSlash(rel)
+			switch {
+			case d.IsDir():
+				add(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: int64(info.Mode().Perm()), ModTime: info.ModTime()}, nil, nil)
+			case info.Mode()&fs.ModeSymlink != 0:
+				target, err := os.Readlink(p)
+				if err != nil {
+					return err
+				}
+				add(&tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target, Mode: 0777, ModTime: info.ModTime()}, nil, nil)
+			case info.Mode().IsRegular():
+				data, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				add(fileHeader(name, info.Mode().Perm(), info.ModTime()), data, nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", root, err)
+		}
+	}
+	return m, files, nil
+}
+
+// readHomeFile reads a home configuration file, under its lock when other
+// commands edit it.
+func readHomeFile(p string, locked bool) (data []byte, info fs.FileInfo, err error) {
+	// Stat first: taking the lock creates the file's directory.
+	if info, err = os.Stat(p); err != nil {
+		return nil, nil, err
+	}
+	read := func() error {
+		if info, err = os.Stat(p); err == nil {
+			data, err = os.ReadFile(p)
+		}
+		return err
+	}
+	if locked {
+		err = platform.WithFileLock(p, read)
+	} else {
+		err = read()
+	}
+	return data, info, err
+}
+
+func fileHeader(name string, perm fs.FileMode, modTime time.Time) *tar.Header {
+	return &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: int64(perm), ModTime: modTime}
+}
+
+// knownProjects returns the project paths recorded in ~/.claude.json, sorted.
+func knownProjects(claudeJSON []byte) []string {
+	var root struct {
+		Projects map[string]json.RawMessage `json:"projects"`
+	}
+	if len(claudeJSON) == 0 || json.Unmarshal(claudeJSON, &root) != nil {
+		return nil
+	}
+	projects := make([]string, 0, len(root.Projects))
+	for p := range root.Projects {
+		if filepath.IsAbs(p) {
+			projects = append(projects, filepath.Clean(p))
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// writeArchive writes the manifest and files to path as a gzipped tar,
+// through a temporary file so an interrupted backup leaves nothing behind.
+// The archive may hold credentials, so only the owner can read it.
+func writeArchive(path string, m *Manifest, files []file) error {
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling manifest: %w", err)
+	}
+	manifest = append(manifest, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	entries := append([]file{{hdr: fileHeader(manifestName, 0600, time.Now()), data: manifest}}, files...)
+	entries[0].hdr.Size = int64(len(manifest))
+	for _, f := range entries {
+		if err == nil {
+			err = tw.WriteHeader(f.hdr)
+		}
+		if err == nil && len(f.data) > 0 {
+			_, err = tw.Write(f.data)
+		}
+	}
+	if cerr := tw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readArchive reads a backup, checking its manifest, the names of its
+// entries, and the checksum of every file.
+func readArchive(p string) (*Manifest, []file, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s is not a backup archive: %w", p, err)
+	}
+	defer gz.Close()
+
+	var m *Manifest
+	var files []file
+	links := map[string]bool{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", p, err)
+		}
+		name := path.Clean(hdr.Name)
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, nil, fmt.Errorf("unsafe path in backup: %s", hdr.Name)
+		}
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if links[dir] {
+				return nil, nil, fmt.Errorf("unsafe path in backup: %s is inside the symlink %s", hdr.Name, dir)
+			}
+		}
+		hdr.Name = name
+		var data []byte
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			if hdr.Size > maxEntrySize {
+				return nil, nil, fmt.Errorf("%s in backup is too large (%d bytes)", name, hdr.Size)
+			}
+			if data, err = io.ReadAll(tr); err != nil {
+				return nil, nil, fmt.Errorf("reading %s from backup: %w", name, err)
+			}
+		case tar.TypeSymlink:
+			links[name] = true
+		case tar.TypeDir:
+		default:
+			continue
+		}
+		if m == nil {
+			if name != manifestName {
+				return nil, nil, fmt.Errorf("%s is not a claude-workspace backup: no manifest", p)
+			}
+			if m, err = parseManifest(data); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		files = append(files, file{hdr: hdr, data: data})
+	}
+	if m == nil {
+		return nil, nil, fmt.Errorf("%s is not a claude-workspace backup: no manifest", p)
+	}
+
+	want := make(map[string]Entry, len(m.Files))
+	for _, e := range m.Files {
+		want[e.Name] = e
+	}
+	for _, f := range files {
+		if f.hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		e, ok := want[f.hdr.Name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s in backup is not listed in its manifest", f.hdr.Name)
+		}
+		sum := sha256.Sum256(f.data)
+		if hex.EncodeToString(sum[:]) != e.SHA256 {
+			return nil, nil, fmt.Errorf("%s in backup is corrupt: checksum mismatch", f.hdr.Name)
+		}
+		delete(want, f.hdr.Name)
+	}
+	for name := range want {
+		return nil, nil, fmt.Errorf("backup is incomplete: %s is missing", name)
+	}
+	return m, files, nil
+}
+
+// parseManifest decodes a manifest and refuses formats this release cannot
+// restore.
+func parseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing backup manifest: %w", err)
+	}
+	if m.Format != format {
+		return nil, fmt.Errorf("not a claude-workspace backup (format %q)", m.Format)
+	}
+	if m.Version < 1 || m.Version > formatVersion {
+		return nil, fmt.Errorf("backup format version %d is not supported by this claude-workspace (supports up to %d); run 'claude-workspace upgrade'", m.Version, formatVersion)
+	}
+	return &m, nil
+}
+
+func printCreated(w io.Writer, home, out string, m *Manifest) {
+	projects := len(m.Projects)
+	platform.PrintOK(w, fmt.Sprintf("Backed up %d file(s), including %d project(s)", len(m.Files), projects))
+	if m.SecretsExcluded {
+		n := 0
+		for _, e := range m.Files {
+			n += len(e.Redacted)
+		}
+		platform.PrintInfo(w, fmt.Sprintf("%d secret(s) left out", n))
+	} else {
+		platform.PrintWarn(w, "The backup includes credentials; keep it private or use --exclude-secrets")
+	}
+	fmt.Fprintln(w)
+	platform.PrintSuccess(w, "Saved "+shortenHome(home, out))
+	platform.PrintCommand(w, "claude-workspace backup restore "+shortenHome(home, out))
+}
+
+// list prints the backups in the default backup directory, newest first.
+func list(w io.Writer, home string) error {
+	dir := backupDir(home)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	platform.PrintBanner(w, "Backups")
+	fmt.Fprintln(w)
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".tar.gz") {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(w, "  No backups in %s\n", shortenHome(home, dir))
+		platform.PrintCommand(w, "claude-workspace backup create")
+		return nil
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for _, name := range names {
+		m, _, err := readArchive(filepath.Join(dir, name))
+		if err != nil {
+			platform.PrintWarn(w, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		detail := fmt.Sprintf("%d file(s), %d project(s)", len(m.Files), len(m.Projects))
+		if m.SecretsExcluded {
+			detail += ", secrets excluded"
+		}
+		fmt.Fprintf(w, "  %-48s %s  %s\n", name, m.CreatedAt, detail)
+	}
+	return nil
+}
+
+// shortenHome replaces the home directory prefix of p with ~.
+func shortenHome(home, p string) string {
+	if rel, err := filepath.Rel(home, p); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+		return "~/" + rel
+	}
+	return p
+}
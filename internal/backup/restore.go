@@ -0,0 +1,333 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/memory"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+type restoreOptions struct {
+	File     string
+	DryRun   bool
+	NoMemory bool
+}
+
+func parseRestoreArgs(args []string) (restoreOptions, error) {
+	var opts restoreOptions
+	f := newFlagSet("restore", "backup restore <file> [--dry-run] [--no-memory]")
+	f.BoolVar(&opts.DryRun, "--dry-run", "Show what would change without writing")
+	f.BoolVar(&opts.NoMemory, "--no-memory", "Skip the memory import")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return opts, err
+	}
+	opts.File = positional[0]
+	return opts, nil
+}
+
+// action is one entry of a backup restored to its place.
+type action struct {
+	f        file
+	target   string
+	label    string
+	shared   bool       // a sharedFiles entry, written under its lock
+	project  bool       // inside a project's .claude directory
+	redacted [][]string // secrets left out of the backup, kept from the current file
+	status   string     // "create", "update", or "" when unchanged
+	missing  [][]string // redacted secrets the current file does not have
+}
+
+// restorePlan is what restoring a backup changes.
+type restorePlan struct {
+	actions []*action
+	memory  []byte
+	skipped []string // projects that no longer exist
+}
+
+func restore(w io.Writer, home, version string, opts restoreOptions) error {
+	m, files, err := readArchive(opts.File)
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "Restore Backup")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Backup:  %s\n", shortenHome(home, opts.File))
+	fmt.Fprintf(w, "  Created: %s by claude-workspace %s\n", m.CreatedAt, m.CLIVersion)
+	if m.Home != home {
+		platform.PrintInfo(w, fmt.Sprintf("Made under %s; home files are restored into %s", m.Home, home))
+	}
+
+	p, err := planRestore(home, m, files, opts.NoMemory)
+	if err != nil {
+		return err
+	}
+	for _, project := range p.skipped {
+		platform.PrintWarn(w, fmt.Sprintf("Skipping %s: the project no longer exists", project))
+	}
+
+	platform.PrintSection(w, "Changes")
+	changed, unchanged := 0, 0
+	for _, a := range p.actions {
+		if a.status == "" {
+			unchanged++
+			continue
+		}
+		changed++
+		fmt.Fprintf(w, "  %-7s %s\n", a.status, a.label)
+		for _, path := range a.missing {
+			platform.PrintWarningLine(w, fmt.Sprintf("          %s is not in the backup and not set now; it will be missing", strings.Join(path, ".")))
+		}
+	}
+	if p.memory != nil {
+		fmt.Fprintf(w, "  %-7s %s\n", "import", "memory (auto-memory and memory MCP)")
+	}
+	if changed == 0 && p.memory == nil {
+		fmt.Fprintln(w, "  Nothing to restore: the configuration matches the backup.")
+		return nil
+	}
+	if unchanged > 0 {
+		fmt.Fprintf(w, "  (%d unchanged)\n", unchanged)
+	}
+	if opts.DryRun {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "  Dry run: nothing was written.")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	undo := filepath.Join(backupDir(home), "claude-backup-"+time.Now().Format("20060102-150405")+"-pre-restore.tar.gz")
+	if _, _, err := create(home, version, createOptions{Output: undo, NoMemory: p.memory == nil}); err != nil {
+		return fmt.Errorf("backing up the current configuration before restoring: %w", err)
+	}
+	platform.PrintOK(w, "Current configuration saved to "+shortenHome(home, undo))
+
+	for _, a := range p.actions {
+		if a.status == "" {
+			continue
+		}
+		if err := a.apply(); err != nil {
+			return fmt.Errorf("restoring %s: %w\nRun 'claude-workspace backup restore %s' to undo the files already restored", a.label, err, shortenHome(home, undo))
+		}
+	}
+	platform.PrintOK(w, fmt.Sprintf("Restored %d file(s)", changed))
+
+	if p.memory != nil {
+		if err := restoreMemory(p.memory); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	platform.PrintManual(w, "Restart running Claude Code sessions to pick up the restored configuration")
+	platform.PrintManual(w, "To undo: claude-workspace backup restore "+shortenHome(home, undo))
+	return nil
+}
+
+// planRestore maps each entry of a backup to where it is restored and
+// whether that changes anything.
+func planRestore(home string, m *Manifest, files []file, noMemory bool) (*restorePlan, error) {
+	redacted := make(map[string][][]string)
+	for _, e := range m.Files {
+		redacted[e.Name] = e.Redacted
+	}
+	projects := make(map[string]string)
+	for _, project := range m.Projects {
+		projects[project.Dir] = project.Path
+	}
+
+	p := &restorePlan{}
+	skipped := make(map[string]bool)
+	for _, f := range files {
+		name := f.hdr.Name
+		a := &action{f: f, redacted: redacted[name]}
+		switch {
+		case name == memoryName:
+			if !noMemory {
+				p.memory = f.data
+			}
+			continue
+		case strings.HasPrefix(name, "home/"):
+			rel := strings.TrimPrefix(name, "home/")
+			if !isHomeFile(rel) {
+				return nil, fmt.Errorf("unexpected entry in backup: %s", name)
+			}
+			a.target = filepath.Join(home, filepath.FromSlash(rel))
+			a.label = "~/" + rel
+			a.shared = sharedFiles[rel]
+		case strings.HasPrefix(name, "projects/"):
+			parts := strings.SplitN(name, "/", 3)
+			project, ok := projects[strings.Join(parts[:2], "/")]
+			if len(parts) < 3 || !ok || (parts[2] != ".claude" && !strings.HasPrefix(parts[2], ".claude/")) {
+				return nil, fmt.Errorf("unexpected entry in backup: %s", name)
+			}
+			if info, err := os.Stat(project); err != nil || !info.IsDir() {
+				if !skipped[project] {
+					skipped[project] = true
+					p.skipped = append(p.skipped, project)
+				}
+				continue
+			}
+			a.target = filepath.Join(project, filepath.FromSlash(parts[2]))
+			a.label = shortenHome(home, a.target)
+			a.project = true
+		default:
+			return nil, fmt.Errorf("unexpected entry in backup: %s", name)
+		}
+		if err := a.check(); err != nil {
+			return nil, fmt.Errorf("checking %s: %w", a.label, err)
+		}
+		p.actions = append(p.actions, a)
+	}
+	return p, nil
+}
+
+func isHomeFile(rel string) bool {
+	for _, f := range homeFiles {
+		if f == rel {
+			return true
+		}
+	}
+	return false
+}
+
+// check sets the status of a by comparing the backup entry with what is at
+// its target now.
+func (a *action) check() error {
+	current, err := os.Lstat(a.target)
+	if os.IsNotExist(err) {
+		a.status = "create"
+		if a.f.hdr.Typeflag == tar.TypeReg {
+			_, a.missing, err = a.content()
+		} else {
+			err = nil
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	a.status = "update"
+	switch a.f.hdr.Typeflag {
+	case tar.TypeDir:
+		if current.IsDir() {
+			a.status = ""
+		}
+	case tar.TypeSymlink:
+		if target, err := os.Readlink(a.target); err == nil && target == a.f.hdr.Linkname {
+			a.status = ""
+		}
+	case tar.TypeReg:
+		if a.project && current.Mode()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		data, missing, err := a.content()
+		if err != nil {
+			return err
+		}
+		a.missing = missing
+		info, err := os.Stat(a.target)
+		if err != nil {
+			return err
+		}
+		if now, err := os.ReadFile(a.target); err == nil && bytes.Equal(now, data) && info.Mode().Perm() == a.perm() {
+			a.status = ""
+		}
+	}
+	return nil
+}
+
+// content returns the data to write for a file entry: the backed-up file,
+// with the secrets left out of the backup taken from the current file.
+func (a *action) content() ([]byte, [][]string, error) {
+	if len(a.redacted) == 0 {
+		return a.f.data, nil, nil
+	}
+	current, err := os.ReadFile(a.target)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	return carryOverSecrets(a.f.data, current, a.redacted)
+}
+
+func (a *action) perm() fs.FileMode {
+	return fs.FileMode(a.f.hdr.Mode).Perm()
+}
+
+// apply writes the entry to its target. Files are replaced atomically; home
+// files that are symlinks are written through, while a symlink in a project
+// is replaced, so restoring never rewrites shared assets a link points to.
+func (a *action) apply() error {
+	switch a.f.hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(a.target, a.perm()|0700)
+	case tar.TypeSymlink:
+		if err := os.MkdirAll(filepath.Dir(a.target), 0755); err != nil {
+			return err
+		}
+		tmp := filepath.Join(filepath.Dir(a.target), "."+filepath.Base(a.target)+".tmp-link")
+		os.Remove(tmp)
+		if err := os.Symlink(a.f.hdr.Linkname, tmp); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, a.target); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(a.target), 0755); err != nil {
+		return err
+	}
+	write := func() error {
+		data, _, err := a.content()
+		if err != nil {
+			return err
+		}
+		if a.project {
+			if info, err := os.Lstat(a.target); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+				if err := os.Remove(a.target); err != nil {
+					return err
+				}
+			}
+		}
+		return platform.WriteFileAtomic(a.target, data, a.perm())
+	}
+	if a.shared {
+		return platform.WithFileLock(a.target, write)
+	}
+	return write()
+}
+
+// restoreMemory imports the auto-memory and memory MCP layers of a backup.
+// The global CLAUDE.md is restored as a file, and project CLAUDE.md files
+// belong to their repositories, so those layers are not imported.
+func restoreMemory(data []byte) error {
+	tmp, err := os.CreateTemp("", "claude-workspace-memory-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := memory.Restore(tmp.Name(), memory.ParseScope("auto,mcp")); err != nil {
+		return fmt.Errorf("importing memory: %w", err)
+	}
+	return nil
+}
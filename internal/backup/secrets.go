@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/scan"
+)
+
+// secretName matches env var and header names whose values are credentials.
+var secretName = regexp.MustCompile(`(?i)KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|AUTH|PRIVATE|DSN|DATABASE_URL`)
+
+// isSecret reports whether the env var or header name holds a credential:
+// a literal value (not a ${VAR} reference, number, or boolean) under a
+// credential-like name, or any value that looks like a known token.
+func isSecret(name, value string) bool {
+	v := strings.TrimSpace(value)
+	if v == "" || strings.Contains(v, "${") {
+		return false
+	}
+	if len(scan.Content("", name+"="+v)) > 0 {
+		return true
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil || v == "true" || v == "false" {
+		return false
+	}
+	return secretName.MatchString(name)
+}
+
+// redactSecrets removes credentials from the JSON document data:
+// primaryApiKey and secret values in any "env" or "headers" object, which
+// covers MCP servers at every scope and the env block of settings.json. It
+// returns the document re-encoded, or unchanged when nothing was removed,
+// with the JSON paths of the removed values.
+func redactSecrets(data []byte) ([]byte, [][]string, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	var removed [][]string
+	if _, ok := root["primaryApiKey"]; ok {
+		delete(root, "primaryApiKey")
+		removed = append(removed, []string{"primaryApiKey"})
+	}
+	redact(root, nil, &removed)
+	if len(removed) == 0 {
+		return data, nil, nil
+	}
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(out, '\n'), removed, nil
+}
+
+func redact(obj map[string]interface{}, at []string, removed *[][]string) {
+	for _, k := range sortedKeys(obj) {
+		child, ok := obj[k].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		p := append(append([]string(nil), at...), k)
+		if k != "env" && k != "headers" {
+			redact(child, p, removed)
+			continue
+		}
+		for _, name := range sortedKeys(child) {
+			if v, ok := child[name].(string); ok && isSecret(name, v) {
+				delete(child, name)
+				*removed = append(*removed, append(append([]string(nil), p...), name))
+			}
+		}
+	}
+}
+
+// carryOverSecrets fills the redacted paths of the JSON document data with
+// the values current holds at the same paths. It returns the paths current
+// has no value for.
+func carryOverSecrets(data, current []byte, redacted [][]string) ([]byte, [][]string, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	var now map[string]interface{}
+	if len(current) > 0 {
+		// A current file that does not parse has no values to carry over.
+		json.Unmarshal(current, &now)
+	}
+	var missing [][]string
+	for _, p := range redacted {
+		v, ok := lookup(now, p)
+		parent, pok := lookup(root, p[:len(p)-1])
+		obj, _ := parent.(map[string]interface{})
+		if !ok || !pok || obj == nil {
+			missing = append(missing, p)
+			continue
+		}
+		obj[p[len(p)-1]] = v
+	}
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(out, '\n'), missing, nil
+}
+
+// lookup returns the value at path p in a decoded JSON object.
+func lookup(root map[string]interface{}, p []string) (interface{}, bool) {
+	var v interface{} = root
+	for _, k := range p {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, root != nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,560 @@
+// Package bugreport implements the "bugreport" command, which gathers what an
+// issue report needs — versions, environment, doctor results, settings, and
+// recent command logs — into one redacted Markdown document ready to paste
+// into an issue or gist, or a tarball to attach.
+package bugreport
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/doctor"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// defaultLogs is how many recent command logs are included.
+const defaultLogs = 5
+
+// maxLogLines is how much of the end of each log the Markdown report shows.
+// The tarball keeps whole logs.
+const maxLogLines = 200
+
+// toolTimeout bounds each "<tool> --version" run.
+const toolTimeout = 5 * time.Second
+
+// masked replaces a credential value.
+const masked = "****"
+
+// tools are the commands whose versions are reported, with the arguments
+// that print them.
+var tools = [][]string{
+	{"claude", "--version"},
+	{"node", "--version"},
+	{"npm", "--version"},
+	{"git", "--version"},
+	{"engram", "--version"},
+	{"sqlite3", "--version"},
+	{"age", "--version"},
+}
+
+// envPrefixes select the environment variables that are reported.
+var envPrefixes = []string{"CLAUDE_", "ANTHROPIC_", "CLAUDE_WORKSPACE_", "DISABLE_", "MCP_"}
+
+// envNames are further environment variables that are reported.
+var envNames = []string{"SHELL", "TERM", "TERM_PROGRAM", "LANG", "CI", "GITHUB_ACTIONS", "NO_COLOR", "NODE_OPTIONS",
+	"npm_config_registry", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+// proxyNames may hold credentials in their URLs, so only whether they are
+// set is reported.
+var proxyNames = map[string]bool{"HTTP_PROXY": true, "HTTPS_PROXY": true, "http_proxy": true, "https_proxy": true}
+
+// secretName matches setting and variable names whose values are credentials.
+var secretName = regexp.MustCompile(`(?i)KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|AUTH|PRIVATE|DSN|DATABASE_URL|COOKIE`)
+
+// entry is a named value in a report section.
+type entry struct {
+	Name  string
+	Value string
+}
+
+// document is a file included in the report.
+type document struct {
+	Name    string // as shown in the report, with the home directory as ~
+	Content string
+	Lang    string // Markdown code fence language
+}
+
+// Bundle is everything a bug report holds, already redacted.
+type Bundle struct {
+	GeneratedAt time.Time
+	Version     string
+	System      []entry
+	Tools       []entry
+	Env         []entry
+	Doctor      *doctor.Report
+	DoctorError string
+	Settings    []document
+	Logs        []document
+}
+
+// options holds the parsed bugreport flags.
+type options struct {
+	format   string
+	output   string
+	logs     int
+	noDoctor bool
+}
+
+// Run implements "bugreport [--format markdown|tar] [--output path] [--logs n] [--no-doctor]".
+func Run(version string, args []string) error {
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, _ := os.Getwd()
+	logDir, err := platform.LogDir()
+	if err != nil {
+		return err
+	}
+
+	if !opts.noDoctor {
+		platform.PrintInfo(os.Stderr, "Collecting bug report (running doctor)...")
+	}
+	b := collect(version, home, cwd, logDir, opts)
+
+	if opts.format == "tar" {
+		if opts.output == "" {
+			opts.output = "claude-workspace-bugreport-" + b.GeneratedAt.Format("20060102-150405") + ".tar.gz"
+		}
+		if err := writeTarball(opts.output, b); err != nil {
+			return fmt.Errorf("writing %s: %w", opts.output, err)
+		}
+		platform.PrintOK(os.Stderr, "Wrote bug report to "+opts.output)
+		platform.PrintInfo(os.Stderr, "Review it before attaching it to an issue: secrets are masked, but check for anything else private.")
+		return nil
+	}
+
+	w := io.Writer(platform.Stdout())
+	if opts.output != "" {
+		f, err := os.OpenFile(opts.output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", opts.output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	writeMarkdown(w, b, true)
+	if opts.output != "" {
+		platform.PrintOK(os.Stderr, "Wrote bug report to "+opts.output)
+		platform.PrintInfo(os.Stderr, "Review it before pasting it into an issue: secrets are masked, but check for anything else private.")
+	}
+	return nil
+}
+
+// parseArgs parses the bugreport arguments. Without --format, an --output
+// ending in .tar.gz or .tgz selects the tarball.
+func parseArgs(args []string) (options, error) {
+	opts := options{logs: defaultLogs}
+	f := cli.New("bugreport", "bugreport [--output <file>] [--format markdown|tar] [--logs N] [--no-doctor]")
+	f.StringVar(&opts.format, "--format", "markdown|tar", "Output format")
+	f.StringVar(&opts.output, "--output", "file", "Where to write the report")
+	f.Func("--logs", "N", "How many recent logs to include", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("--logs must be a number of logs, 0 or more")
+		}
+		opts.logs = n
+		return nil
+	})
+	f.BoolVar(&opts.noDoctor, "--no-doctor", "Skip the doctor run")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	if opts.format == "" {
+		opts.format = "markdown"
+		if out := strings.ToLower(opts.output); strings.HasSuffix(out, ".tar.gz") || strings.HasSuffix(out, ".tgz") {
+			opts.format = "tar"
+		}
+	}
+	if opts.format != "markdown" && opts.format != "tar" {
+		return opts, fmt.Errorf("--format must be markdown or tar")
+	}
+	return opts, nil
+}
+
+// collect gathers the bug report for the project in cwd. Everything in it
+// passes through a redactor first.
+func collect(version, home, cwd, logDir string, opts options) *Bundle {
+	r := newRedactor(home)
+	b := &Bundle{GeneratedAt: time.Now(), Version: version}
+
+	b.System = []entry{
+		{"OS", runtime.GOOS},
+		{"Arch", runtime.GOARCH},
+		{"Go runtime", runtime.Version()},
+		{"Working directory", r.text(cwd)},
+	}
+	if platform.FileExists(filepath.Join(cwd, ".claude")) {
+		b.System = append(b.System, entry{"Project", "attached (.claude/ present)"})
+	}
+
+	for _, t := range tools {
+		b.Tools = append(b.Tools, entry{t[0], r.text(toolVersion(t[0], t[1:]...))})
+	}
+	b.Env = environment(os.Environ(), r)
+
+	if !opts.noDoctor {
+		if report, err := doctor.Check(); err != nil {
+			b.DoctorError = r.text(err.Error())
+		} else {
+			for i := range report.Results {
+				res := &report.Results[i]
+				res.Message, res.Remediation = r.text(res.Message), r.text(res.Remediation)
+			}
+			b.Doctor = report
+		}
+	}
+
+	b.Settings = settingsFiles(home, cwd, r)
+	b.Logs = recentLogs(logDir, opts.logs, r)
+	return b
+}
+
+// toolVersion returns the first line a tool prints for its version, or why
+// there is none.
+func toolVersion(name string, args ...string) string {
+	if !platform.Exists(name) {
+		return "not installed"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+	out, err := platform.OutputContext(ctx, name, args...)
+	if err != nil {
+		return "installed (version unavailable: " + err.Error() + ")"
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	return line
+}
+
+// environment returns the reported variables of env, sorted, with
+// credentials masked.
+func environment(env []string, r *redactor) []entry {
+	var entries []entry
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if !reportedVar(name) {
+			continue
+		}
+		switch {
+		case proxyNames[name]:
+			value = "(set)"
+		case isSecret(name, value):
+			value = masked
+		default:
+			value = r.text(value)
+		}
+		entries = append(entries, entry{name, value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func reportedVar(name string) bool {
+	for _, p := range envPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	for _, n := range envNames {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecret reports whether a value under name is a credential: a literal (not
+// a ${VAR} reference, number, or boolean) under a credential-like name.
+func isSecret(name, value string) bool {
+	v := strings.TrimSpace(value)
+	if v == "" || strings.Contains(v, "${") || v == "true" || v == "false" {
+		return false
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return false
+	}
+	return secretName.MatchString(name)
+}
+
+// settingsFiles returns the user and project settings and MCP configuration,
+// masked. Of ~/.claude.json, which also holds history and account details,
+// only the user-scope MCP servers are included.
+func settingsFiles(home, cwd string, r *redactor) []document {
+	paths := []string{
+		filepath.Join(home, ".claude", "settings.json"),
+		filepath.Join(cwd, ".claude", "settings.json"),
+		filepath.Join(cwd, ".claude", "settings.local.json"),
+		filepath.Join(cwd, ".mcp.json"),
+	}
+	var docs []document
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, document{Name: r.text(p), Content: maskJSON(data, r), Lang: "json"})
+	}
+	claudeJSON := filepath.Join(home, ".claude.json")
+	if data, err := os.ReadFile(claudeJSON); err == nil {
+		var root struct {
+			MCPServers json.RawMessage `json:"mcpServers"`
+		}
+		if json.Unmarshal(data, &root) == nil && len(root.MCPServers) > 0 {
+			docs = append(docs, document{Name: r.text(claudeJSON) + " (mcpServers)", Content: maskJSON(root.MCPServers, r), Lang: "json"})
+		}
+	}
+	return docs
+}
+
+// maskJSON returns the JSON document data indented, with credential values
+// masked and token-shaped strings redacted. A document that does not parse
+// is redacted as text.
+func maskJSON(data []byte, r *redactor) string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return r.text(string(data))
+	}
+	out, err := json.MarshalIndent(maskValue("", v, r), "", "  ")
+	if err != nil {
+		return r.text(string(data))
+	}
+	return string(out)
+}
+
+func maskValue(name string, v interface{}, r *redactor) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = maskValue(k, child, r)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = maskValue("", child, r)
+		}
+		return v
+	case string:
+		if isSecret(name, v) {
+			return masked
+		}
+		return r.text(v)
+	}
+	return v
+}
+
+// recentLogs returns the n most recent command logs in dir, newest first,
+// redacted. The log of the running bugreport is left out.
+func recentLogs(dir string, n int, r *redactor) []document {
+	if n == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type logFile struct {
+		name string
+		mod  time.Time
+	}
+	var logs []logFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".log") || strings.HasPrefix(e.Name(), "bugreport-") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			logs = append(logs, logFile{e.Name(), info.ModTime()})
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].mod.After(logs[j].mod) })
+	var docs []document
+	for _, l := range logs {
+		if len(docs) == n {
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(dir, l.name))
+		if err != nil {
+			continue
+		}
+		docs = append(docs, document{Name: l.name, Content: r.text(string(data))})
+	}
+	return docs
+}
+
+// redactor removes credentials and the home directory from text.
+type redactor struct {
+	home    string
+	secrets *sessions.Redactor
+}
+
+func newRedactor(home string) *redactor {
+	return &redactor{home: home, secrets: sessions.NewRedactor(nil)}
+}
+
+// text returns s with token-shaped secrets replaced and the home directory
+// shown as ~.
+func (r *redactor) text(s string) string {
+	s = r.secrets.String(s)
+	if r.home != "" && r.home != "/" {
+		s = strings.ReplaceAll(s, r.home, "~")
+	}
+	return s
+}
+
+// writeMarkdown writes the report as Markdown. truncate shortens each log to
+// its last maxLogLines lines, for pasting into an issue.
+func writeMarkdown(w io.Writer, b *Bundle, truncate bool) {
+	fmt.Fprintln(w, "# claude-workspace bug report")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Generated %s by claude-workspace %s.\n", b.GeneratedAt.UTC().Format(time.RFC3339), b.Version)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## What happened")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "<!-- The command you ran, what you expected, and what happened instead. -->")
+	fmt.Fprintln(w)
+
+	writeTable(w, "System", b.System)
+	writeTable(w, "Tools", b.Tools)
+
+	fmt.Fprintln(w, "## Environment")
+	fmt.Fprintln(w)
+	if len(b.Env) == 0 {
+		fmt.Fprintln(w, "No Claude Code or claude-workspace variables set.")
+	} else {
+		fmt.Fprintln(w, "```")
+		for _, e := range b.Env {
+			fmt.Fprintf(w, "%s=%s\n", e.Name, e.Value)
+		}
+		fmt.Fprintln(w, "```")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## Doctor")
+	fmt.Fprintln(w)
+	switch {
+	case b.DoctorError != "":
+		fmt.Fprintf(w, "Doctor could not run: %s\n", b.DoctorError)
+	case b.Doctor == nil:
+		fmt.Fprintln(w, "Not run (--no-doctor).")
+	default:
+		fmt.Fprintf(w, "%d issue(s), %d warning(s).\n\n", b.Doctor.Issues, b.Doctor.Warnings)
+		fmt.Fprintln(w, "```")
+		for _, res := range b.Doctor.Results {
+			section := res.Section
+			if res.Project != "" {
+				section += " (" + res.Project + ")"
+			}
+			fmt.Fprintf(w, "[%s] %s: %s\n", res.Status, section, res.Message)
+		}
+		fmt.Fprintln(w, "```")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## Settings")
+	fmt.Fprintln(w)
+	if len(b.Settings) == 0 {
+		fmt.Fprintln(w, "No settings files found.")
+		fmt.Fprintln(w)
+	}
+	for _, d := range b.Settings {
+		fmt.Fprintf(w, "### %s\n\n```%s\n%s\n```\n\n", d.Name, d.Lang, strings.TrimRight(d.Content, "\n"))
+	}
+
+	fmt.Fprintln(w, "## Recent command logs")
+	fmt.Fprintln(w)
+	if len(b.Logs) == 0 {
+		fmt.Fprintln(w, "No command logs found.")
+		fmt.Fprintln(w)
+	}
+	for _, d := range b.Logs {
+		content := strings.TrimRight(d.Content, "\n")
+		if lines := strings.Split(content, "\n"); truncate && len(lines) > maxLogLines {
+			content = fmt.Sprintf("... (%d earlier lines omitted)\n%s", len(lines)-maxLogLines, strings.Join(lines[len(lines)-maxLogLines:], "\n"))
+		}
+		fmt.Fprintf(w, "<details><summary>%s</summary>\n\n```\n%s\n```\n\n</details>\n\n", d.Name, content)
+	}
+}
+
+func writeTable(w io.Writer, title string, entries []entry) {
+	fmt.Fprintf(w, "## %s\n\n| | |\n|---|---|\n", title)
+	for _, e := range entries {
+		fmt.Fprintf(w, "| %s | %s |\n", e.Name, strings.ReplaceAll(e.Value, "|", `\|`))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeTarball writes the report as a gzipped tar: bugreport.md with whole
+// logs, doctor.json, and the settings and logs as separate files. Like the
+// logs it is made from, only the owner can read it.
+func writeTarball(path string, b *Bundle) error {
+	var md strings.Builder
+	writeMarkdown(&md, b, false)
+	files := []struct {
+		name string
+		data string
+	}{{"bugreport.md", md.String()}}
+	if b.Doctor != nil {
+		data, err := json.MarshalIndent(b.Doctor, "", "  ")
+		if err != nil {
+			return err
+		}
+		files = append(files, struct{ name, data string }{"doctor.json", string(data) + "\n"})
+	}
+	for _, d := range b.Settings {
+		name := strings.NewReplacer("~/", "", "/", "_", " ", "_", "(", "", ")", "").Replace(d.Name)
+		files = append(files, struct{ name, data string }{"settings/" + name, d.Content + "\n"})
+	}
+	for _, d := range b.Logs {
+		files = append(files, struct{ name, data string }{"logs/" + d.Name, d.Content})
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	root := "claude-workspace-bugreport-" + b.GeneratedAt.Format("20060102-150405") + "/"
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if err == nil {
+			err = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: root + f.name, Mode: 0600, Size: int64(len(f.data)), ModTime: b.GeneratedAt})
+		}
+		if err == nil {
+			_, err = io.WriteString(tw, f.data)
+		}
+	}
+	if cerr := tw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
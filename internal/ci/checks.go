@@ -0,0 +1,322 @@
+package ci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/agents"
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/hooks"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/policy"
+	"github.com/lamchakchan/claude-workspace/internal/skills"
+)
+
+// shellcheckLine matches a line of "shellcheck -f gcc" output:
+// file:line:column: severity: message [SCnnnn].
+var shellcheckLine = regexp.MustCompile(`^(.+?):(\d+):\d+: (\w+): (.*)$`)
+
+// projectScript matches a script under the project directory in a hook
+// command, such as "$CLAUDE_PROJECT_DIR"/.claude/hooks/guard.sh.
+var projectScript = regexp.MustCompile(`\$\{?CLAUDE_PROJECT_DIR\}?"?/([^\s"';|&]+)`)
+
+// checkSettings checks that .claude/settings.json exists, parses, matches the
+// settings schema, and holds valid permission rules and hook entries.
+func checkSettings(dir string) []Finding {
+	const file = ".claude/settings.json"
+	var findings []Finding
+	add := func(line int, severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{File: file, Line: line, Severity: severity, Check: "settings", Message: fmt.Sprintf(format, args...)})
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	if os.IsNotExist(err) {
+		add(0, SeverityError, "project settings not found; run: claude-workspace attach")
+		return findings
+	}
+	if err != nil {
+		add(0, SeverityError, "%v", err)
+		return findings
+	}
+	settings, line, err := decodeObject(data)
+	if err != nil {
+		add(line, SeverityError, "invalid JSON: %v", err)
+		return findings
+	}
+
+	for _, issue := range config.ValidateSettings(settings) {
+		severity := SeverityWarning
+		if issue.Error {
+			severity = SeverityError
+		}
+		add(lineOf(data, quoteAll(strings.Split(issue.Key, "."))...), severity, "%s", issue.Message)
+	}
+
+	var permissions map[string]json.RawMessage
+	if json.Unmarshal(settings["permissions"], &permissions) == nil {
+		for _, list := range []string{"allow", "ask", "deny"} {
+			var rules []interface{}
+			if json.Unmarshal(permissions[list], &rules) != nil {
+				continue
+			}
+			for _, r := range rules {
+				rule, ok := r.(string)
+				if !ok {
+					add(lineOf(data, `"permissions"`, `"`+list+`"`), SeverityError, "permissions.%s entries must be strings", list)
+					continue
+				}
+				if _, err := policy.ParseRule(rule); err != nil {
+					quoted, _ := json.Marshal(rule)
+					add(lineOf(data, `"permissions"`, `"`+list+`"`, string(quoted)), SeverityError, "permissions.%s: %v", list, err)
+				}
+			}
+		}
+	}
+
+	findings = append(findings, checkHookEntries(dir, file, data, settings["hooks"])...)
+	return findings
+}
+
+// checkHookEntries checks the hook events in settings.json and that scripts
+// in the project that hook commands run are present.
+func checkHookEntries(dir, file string, data []byte, raw json.RawMessage) []Finding {
+	if raw == nil {
+		return nil
+	}
+	var findings []Finding
+	add := func(line int, format string, args ...interface{}) {
+		findings = append(findings, Finding{File: file, Line: line, Severity: SeverityError, Check: "hooks", Message: fmt.Sprintf(format, args...)})
+	}
+	var events map[string][]struct {
+		Hooks []struct {
+			Type    string `json:"type"`
+			Command string `json:"command"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(raw, &events); err != nil {
+		add(lineOf(data, `"hooks"`), `"hooks" must map event names to lists of {"matcher", "hooks"} groups`)
+		return findings
+	}
+	names := make([]string, 0, len(events))
+	for event := range events {
+		names = append(names, event)
+	}
+	sort.Strings(names)
+	for _, event := range names {
+		if !slices.Contains(hooks.Events, event) {
+			add(lineOf(data, `"hooks"`, `"`+event+`"`), "unknown hook event %q", event)
+			continue
+		}
+		for _, group := range events[event] {
+			for _, h := range group.Hooks {
+				if h.Type != "command" {
+					continue
+				}
+				m := projectScript.FindStringSubmatch(h.Command)
+				if m == nil || platform.FileExists(filepath.Join(dir, filepath.FromSlash(m[1]))) {
+					continue
+				}
+				add(lineOf(data, `"hooks"`, `"`+event+`"`, m[1]), "%s hook runs %s, which does not exist", event, m[1])
+			}
+		}
+	}
+	return findings
+}
+
+// checkHooks checks that the scripts in .claude/hooks are executable and,
+// when shellcheck is installed, free of shellcheck warnings and errors.
+func checkHooks(dir string) []Finding {
+	hooksDir := filepath.Join(dir, ".claude", "hooks")
+	entries, err := os.ReadDir(hooksDir)
+	if err != nil {
+		return nil
+	}
+	var findings []Finding
+	var scripts []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sh") {
+			continue
+		}
+		scripts = append(scripts, e.Name())
+		if !platform.IsExecutable(filepath.Join(hooksDir, e.Name())) {
+			findings = append(findings, Finding{
+				File: ".claude/hooks/" + e.Name(), Severity: SeverityError, Check: "hooks",
+				Message: "hook script is not executable; run: chmod +x .claude/hooks/" + e.Name() + " (and git add --chmod=+x it)",
+			})
+		}
+	}
+	if len(scripts) == 0 {
+		return findings
+	}
+	if !platform.Exists("shellcheck") {
+		return append(findings, Finding{
+			File: ".claude/hooks", Severity: SeverityNotice, Check: "hooks",
+			Message: "shellcheck is not installed; hook scripts were not linted",
+		})
+	}
+	out, _ := platform.OutputDir(hooksDir, "shellcheck", append([]string{"-f", "gcc", "-S", "warning"}, scripts...)...)
+	return append(findings, parseShellcheck(out)...)
+}
+
+// parseShellcheck converts "shellcheck -f gcc" output for scripts in
+// .claude/hooks into findings.
+func parseShellcheck(out string) []Finding {
+	var findings []Finding
+	for _, line := range strings.Split(out, "\n") {
+		m := shellcheckLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		severity := SeverityWarning
+		if m[3] == "error" {
+			severity = SeverityError
+		}
+		n, _ := strconv.Atoi(m[2])
+		findings = append(findings, Finding{
+			File: ".claude/hooks/" + filepath.Base(m[1]), Line: n, Severity: severity, Check: "hooks",
+			Message: "shellcheck: " + m[4],
+		})
+	}
+	return findings
+}
+
+// checkAgents validates the frontmatter of every agent in .claude/agents.
+func checkAgents(dir string) []Finding {
+	agentsDir := filepath.Join(dir, ".claude", "agents")
+	if !platform.FileExists(agentsDir) {
+		return nil
+	}
+	project := agents.DiscoverAgents(agentsDir)
+	var issues []agents.Issue
+	for _, a := range project {
+		issues = append(issues, agents.Validate(a.Path)...)
+	}
+	issues = append(issues, agents.Duplicates(agents.Effective(project, nil))...)
+
+	findings := make([]Finding, 0, len(issues))
+	for _, i := range issues {
+		findings = append(findings, Finding{
+			File: relPath(dir, i.Path), Severity: severity(i.Severity == agents.SeverityError), Check: "agents", Message: i.Message,
+		})
+	}
+	return findings
+}
+
+// checkSkills validates the frontmatter of every SKILL.md in .claude/skills.
+func checkSkills(dir string) []Finding {
+	var findings []Finding
+	skillsDir := filepath.Join(dir, ".claude", "skills")
+	_ = filepath.WalkDir(skillsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != "SKILL.md" {
+			return nil
+		}
+		for _, i := range skills.Validate(path) {
+			findings = append(findings, Finding{
+				File: relPath(dir, path), Severity: severity(i.Severity == skills.SeverityError), Check: "skills", Message: i.Message,
+			})
+		}
+		return nil
+	})
+	return findings
+}
+
+// checkMCP checks that .mcp.json parses and holds no plaintext secrets.
+func checkMCP(dir string) []Finding {
+	const file = ".mcp.json"
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []Finding{{File: file, Severity: SeverityError, Check: "mcp", Message: err.Error()}}
+	}
+	config, line, err := decodeObject(data)
+	if err != nil {
+		return []Finding{{File: file, Line: line, Severity: SeverityError, Check: "mcp", Message: "invalid JSON: " + err.Error()}}
+	}
+	var servers map[string]map[string]interface{}
+	if err := json.Unmarshal(config["mcpServers"], &servers); err != nil {
+		return []Finding{{File: file, Line: lineOf(data, `"mcpServers"`), Severity: SeverityError, Check: "mcp",
+			Message: `"mcpServers" must map server names to server objects`}}
+	}
+
+	var findings []Finding
+	for _, s := range plaintextSecrets(servers) {
+		findings = append(findings, Finding{
+			File: file, Line: lineOf(data, quoteAll(s.path)...), Severity: SeverityError, Check: "mcp",
+			Message: fmt.Sprintf("%s looks like a plaintext secret; commit a reference such as ${%s} and set the variable outside the repository",
+				strings.Join(s.path, "."), s.envName),
+		})
+	}
+	return findings
+}
+
+// checkClaudeMD checks that the project has a CLAUDE.md and that each one
+// Claude Code loads at startup is at most maxBytes long.
+func checkClaudeMD(dir string, maxBytes int) []Finding {
+	var findings []Finding
+	found := false
+	for _, file := range []string{"CLAUDE.md", ".claude/CLAUDE.md"} {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		found = true
+		if info.Size() > int64(maxBytes) {
+			findings = append(findings, Finding{
+				File: file, Severity: SeverityError, Check: "claude-md",
+				Message: fmt.Sprintf("%d bytes is over the %d-byte limit; it is loaded into every session, so move detail into skills or linked docs", info.Size(), maxBytes),
+			})
+		}
+	}
+	if !found {
+		findings = append(findings, Finding{
+			File: "CLAUDE.md", Severity: SeverityError, Check: "claude-md",
+			Message: "no CLAUDE.md or .claude/CLAUDE.md; run: claude-workspace attach",
+		})
+	}
+	return findings
+}
+
+// decodeObject decodes a JSON object. On a syntax error it also returns the
+// line the error is on.
+func decodeObject(data []byte) (map[string]json.RawMessage, int, error) {
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal(data, &obj)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return nil, lineAt(data, int(syntaxErr.Offset)), err
+	case err != nil:
+		return nil, 1, errors.New("the file must contain a JSON object")
+	case obj == nil:
+		return nil, 1, errors.New("the file must contain a JSON object, not null")
+	}
+	return obj, 0, nil
+}
+
+func severity(isError bool) string {
+	if isError {
+		return SeverityError
+	}
+	return SeverityWarning
+}
+
+// quoteAll returns each of keys as a JSON string.
+func quoteAll(keys []string) []string {
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		b, _ := json.Marshal(k)
+		quoted[i] = string(b)
+	}
+	return quoted
+}
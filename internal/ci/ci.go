@@ -0,0 +1,234 @@
+// Package ci implements the "ci" command, which checks a repository's Claude
+// platform configuration without prompting or touching anything outside the
+// repository, so pipelines can gate pull requests on it. Findings are printed
+// as GitHub Actions annotations or as file:line: severity: message lines a
+// problem matcher can pick up.
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Finding severities. Errors fail "ci verify"; warnings fail it with
+// --strict; notices never do.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNotice  = "notice"
+)
+
+// Output formats for findings.
+const (
+	FormatText   = "text"
+	FormatGitHub = "github"
+)
+
+// defaultMaxClaudeMD is the CLAUDE.md size limit in bytes. Claude Code warns
+// when a memory file passes about 40k characters, since it is loaded into
+// every session.
+const defaultMaxClaudeMD = 40000
+
+// ErrFindings is returned by "ci verify" when it finds errors (or, with
+// --strict, warnings), so the process exits 1 without an extra error line.
+var ErrFindings = errors.New("platform configuration check failed")
+
+// Finding is one problem "ci verify" reports.
+type Finding struct {
+	File     string // relative to the project directory, slash-separated
+	Line     int    // 1-based; 0 when the finding is about the whole file
+	Severity string
+	Check    string // settings, hooks, agents, skills, mcp, claude-md, or a lint-claudemd rule
+	Message  string
+}
+
+// options holds the parsed "ci verify" flags.
+type options struct {
+	dir         string
+	format      string
+	strict      bool
+	maxClaudeMD int
+}
+
+// Run routes the ci subcommand.
+func Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: claude-workspace ci verify [path] [--format text|github] [--strict] [--max-claude-md <bytes>]")
+	}
+	switch args[0] {
+	case "verify":
+		opts, err := parseArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		return verify(platform.Stdout(), opts)
+	default:
+		return fmt.Errorf("unknown ci subcommand: %s (usage: claude-workspace ci verify [path])", args[0])
+	}
+}
+
+func parseArgs(args []string) (options, error) {
+	opts := options{dir: ".", format: FormatText, maxClaudeMD: defaultMaxClaudeMD}
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		opts.format = FormatGitHub
+	}
+	f := cli.New("ci verify", "ci verify [path] [--format text|github] [--strict] [--max-claude-md <bytes>]")
+	FormatFlag(f, &opts.format)
+	f.BoolVar(&opts.strict, "--strict", "Fail on warnings too")
+	f.Func("--max-claude-md", "bytes", "CLAUDE.md size limit", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("--max-claude-md must be a positive number of bytes, got %q", v)
+		}
+		opts.maxClaudeMD = n
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return opts, err
+	}
+	if len(positional) == 1 {
+		opts.dir = positional[0]
+	}
+	return opts, nil
+}
+
+// verify runs every check on opts.dir and prints the findings to w.
+func verify(w io.Writer, opts options) error {
+	info, err := os.Stat(opts.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", opts.dir)
+	}
+
+	findings := Verify(opts.dir, opts.maxClaudeMD)
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+		WriteFinding(w, opts.format, opts.dir, f)
+	}
+
+	summary := fmt.Sprintf("%d error(s), %d warning(s)", counts[SeverityError], counts[SeverityWarning])
+	failed := counts[SeverityError] > 0 || (opts.strict && counts[SeverityWarning] > 0)
+	switch {
+	case len(findings) == 0:
+		fmt.Fprintln(w, "claude-workspace ci verify: platform configuration OK")
+	case failed:
+		fmt.Fprintf(w, "claude-workspace ci verify: %s\n", summary)
+		return ErrFindings
+	default:
+		fmt.Fprintf(w, "claude-workspace ci verify: %s; passing\n", summary)
+	}
+	return nil
+}
+
+// Verify runs every check on the project in dir and returns the findings,
+// sorted by file and line.
+func Verify(dir string, maxClaudeMD int) []Finding {
+	var findings []Finding
+	findings = append(findings, checkSettings(dir)...)
+	findings = append(findings, checkHooks(dir)...)
+	findings = append(findings, checkAgents(dir)...)
+	findings = append(findings, checkSkills(dir)...)
+	findings = append(findings, checkMCP(dir)...)
+	findings = append(findings, checkClaudeMD(dir, maxClaudeMD)...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// FormatFlag declares --format on f, setting *p to FormatText or
+// FormatGitHub.
+func FormatFlag(f *cli.FlagSet, p *string) {
+	f.Func("--format", FormatText+"|"+FormatGitHub, "Output format", func(v string) error {
+		if v != FormatText && v != FormatGitHub {
+			return fmt.Errorf("unknown format %q (valid: %s, %s)", v, FormatText, FormatGitHub)
+		}
+		*p = v
+		return nil
+	})
+}
+
+// WriteFinding prints f in format, with its file relative to dir. Paths are
+// given relative to the working directory, which is where GitHub Actions
+// resolves annotation paths from.
+func WriteFinding(w io.Writer, format, dir string, f Finding) {
+	file := f.File
+	if dir != "." && file != "" {
+		file = filepath.ToSlash(filepath.Join(dir, file))
+	}
+	if format == FormatGitHub {
+		props := "file=" + escapeProperty(file)
+		if f.Line > 0 {
+			props += ",line=" + strconv.Itoa(f.Line)
+		}
+		props += ",title=" + escapeProperty("claude-workspace ("+f.Check+")")
+		fmt.Fprintf(w, "::%s %s::%s\n", f.Severity, props, escapeData(f.Message))
+		return
+	}
+	location := file
+	if f.Line > 0 {
+		location += ":" + strconv.Itoa(f.Line)
+	}
+	fmt.Fprintf(w, "%s: %s: %s [%s]\n", location, f.Severity, f.Message, f.Check)
+}
+
+// escapeData escapes a GitHub Actions workflow command message.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a GitHub Actions workflow command property value.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// relPath returns path relative to dir with forward slashes.
+func relPath(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// lineOf returns the 1-based line of the first occurrence of each needle in
+// data, searching for each after the previous one, or 0 if any is missing.
+// lineOf(data, `"github"`, `"TOKEN"`) finds the TOKEN key of the github entry.
+func lineOf(data []byte, needles ...string) int {
+	offset := 0
+	for _, needle := range needles {
+		i := strings.Index(string(data[offset:]), needle)
+		if i < 0 {
+			return 0
+		}
+		offset += i
+	}
+	return lineAt(data, offset)
+}
+
+// lineAt returns the 1-based line of the byte offset in data.
+func lineAt(data []byte, offset int) int {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	return strings.Count(string(data[:offset]), "\n") + 1
+}
@@ -0,0 +1,123 @@
+package ci
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tokenPatterns match well-known credential formats wherever they appear.
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]{20,}`),                              // Anthropic
+	regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{32,}`),                      // OpenAI
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}`),                           // GitHub
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{40,}`),                         // GitHub fine-grained
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),                             // GitLab
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),                        // Slack
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                          // AWS access key ID
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),                                // Google API key
+	regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{20,}`),                      // Stripe
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),                     // PEM private key
+	regexp.MustCompile(`(?i)\b(?:bearer|basic|token)\s+[A-Za-z0-9._~+/=-]{16,}`), // literal Authorization value
+}
+
+// sensitiveName matches env and header names that hold credentials.
+var sensitiveName = regexp.MustCompile(`(?i)(key|token|secret|passw(or)?d|credential|auth)`)
+
+// sensitiveFlag matches a command-line argument that sets a credential,
+// such as --api-key=abc123.
+var sensitiveFlag = regexp.MustCompile(`(?i)^--?[a-z0-9-]*(key|token|secret|password|credential)[a-z0-9-]*=(.+)$`)
+
+// secretRef locates a plaintext secret in .mcp.json.
+type secretRef struct {
+	path    []string // e.g. mcpServers, github, env, GITHUB_TOKEN
+	envName string   // suggested variable to reference instead
+}
+
+// plaintextSecrets returns the values in the mcpServers entries of .mcp.json
+// that look like credentials rather than ${VAR} references: any value in the
+// env, headers, args, or url of a server that matches a known token format,
+// and any literal value of an env var or header whose name suggests a
+// credential.
+func plaintextSecrets(servers map[string]map[string]interface{}) []secretRef {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var refs []secretRef
+	for _, server := range names {
+		entry := servers[server]
+		for _, field := range []string{"env", "headers"} {
+			values, _ := entry[field].(map[string]interface{})
+			keys := make([]string, 0, len(values))
+			for k := range values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				v, _ := values[k].(string)
+				if literalSecret(v, sensitiveName.MatchString(k)) {
+					refs = append(refs, secretRef{path: []string{"mcpServers", server, field, k}, envName: envName(server, k)})
+				}
+			}
+		}
+		args, _ := entry["args"].([]interface{})
+		for _, a := range args {
+			arg, _ := a.(string)
+			if m := sensitiveFlag.FindStringSubmatch(arg); (m != nil && literalSecret(m[2], true)) || literalSecret(arg, false) {
+				refs = append(refs, secretRef{path: []string{"mcpServers", server, "args"}, envName: envName(server, "token")})
+				break
+			}
+		}
+		if url, _ := entry["url"].(string); literalSecret(url, false) {
+			refs = append(refs, secretRef{path: []string{"mcpServers", server, "url"}, envName: envName(server, "token")})
+		}
+	}
+	return refs
+}
+
+// literalSecret reports whether v is a credential written out in full. With
+// named set, any non-placeholder literal counts; otherwise v must match a
+// known token format. Values that reference a variable never count.
+func literalSecret(v string, named bool) bool {
+	v = strings.TrimSpace(v)
+	if v == "" || strings.Contains(v, "${") {
+		return false
+	}
+	for _, p := range tokenPatterns {
+		if p.MatchString(v) {
+			return true
+		}
+	}
+	if !named {
+		return false
+	}
+	lower := strings.ToLower(v)
+	for _, placeholder := range []string{"<", "your", "changeme", "xxx", "placeholder", "example"} {
+		if strings.Contains(lower, placeholder) {
+			return false
+		}
+	}
+	return len(v) >= 8
+}
+
+// envName suggests an environment variable for a secret stored under key in
+// server's entry: key itself when it is already a variable name such as
+// GITHUB_TOKEN, and otherwise SERVER_KEY.
+func envName(server, key string) string {
+	name := key
+	if !strings.Contains(key, "_") {
+		name = server + "_" + key
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, name)
+}
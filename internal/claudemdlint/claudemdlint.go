@@ -0,0 +1,716 @@
+// Package claudemdlint implements the "lint-claudemd" command, which checks
+// CLAUDE.md files against the structure attach and enrich produce: a title,
+// the Project, Conventions, and Key Directories sections, no code blocks
+// outside a section, a size that fits in every session, and build and test
+// commands that can run in the project. Findings use the formats of "ci
+// verify" so the command can gate enrichment output in CI, and --fix repairs
+// the mechanical problems.
+package claudemdlint
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/ci"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const usage = "lint-claudemd [path] [--global] [--fix] [--format text|github] [--strict] [--max-bytes <bytes>]"
+
+// defaultMaxBytes matches the CLAUDE.md size limit of "ci verify".
+const defaultMaxBytes = 40000
+
+// Lint rules, reported as the check of each finding.
+const (
+	ruleMaxLength       = "max-length"
+	ruleCodeFence       = "code-fence"
+	ruleTitle           = "title"
+	ruleRequiredSection = "required-section"
+	ruleDuplicate       = "duplicate-section"
+	ruleEmptySection    = "empty-section"
+	ruleCommand         = "command"
+	ruleWhitespace      = "whitespace"
+)
+
+// requiredSection is a section every project CLAUDE.md needs. Any of its
+// aliases counts, so files written from the project template pass too.
+type requiredSection struct {
+	heading     string
+	aliases     []string
+	placeholder string
+}
+
+var requiredSections = []requiredSection{
+	{"Project", []string{"Project", "Project Context", "Package"}, ""},
+	{"Conventions", []string{"Conventions", "Team Conventions"}, "<!-- Add your team's coding conventions here -->"},
+	{"Key Directories", []string{"Key Directories", "Directory Layout"}, "<!-- Map your project's important directories -->"},
+}
+
+// programMarkers lists, for each program a build or test command can start
+// with, the files that show the project uses it. Patterns may contain *.
+var programMarkers = map[string][]string{
+	"make":      {"Makefile", "GNUmakefile", "makefile"},
+	"just":      {"justfile", "Justfile", ".justfile"},
+	"task":      {"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"},
+	"go":        {"go.mod", "go.work"},
+	"cargo":     {"Cargo.toml"},
+	"npm":       {"package.json"},
+	"npx":       {"package.json"},
+	"yarn":      {"yarn.lock", ".yarnrc.yml", ".yarnrc"},
+	"pnpm":      {"pnpm-lock.yaml", "pnpm-workspace.yaml"},
+	"bun":       {"bun.lockb", "bun.lock"},
+	"bunx":      {"bun.lockb", "bun.lock"},
+	"mvn":       {"pom.xml"},
+	"./mvnw":    {"mvnw"},
+	"gradle":    {"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
+	"./gradlew": {"gradlew"},
+	"dotnet":    {"*.csproj", "*.fsproj", "*.sln"},
+	"mix":       {"mix.exs"},
+	"bundle":    {"Gemfile"},
+	"rails":     {"Gemfile"},
+	"rspec":     {"Gemfile", ".rspec"},
+	"rake":      {"Rakefile", "rakefile", "Rakefile.rb"},
+	"composer":  {"composer.json"},
+	"swift":     {"Package.swift"},
+	"sbt":       {"build.sbt"},
+	"cmake":     {"CMakeLists.txt"},
+	"bazel":     {"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"},
+	"bazelisk":  {"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"},
+	"poetry":    {"pyproject.toml"},
+}
+
+var (
+	// commandRe matches a "Build: `make build`" line as the scaffold writes
+	// it, optionally as a list item.
+	commandRe          = regexp.MustCompile("^\\s*(?:[-*]\\s+)?(Build|Test|Lint):\\s*`([^`]+)`")
+	commandSeparatorRe = regexp.MustCompile(`&&|\|\||;|\|`)
+	envAssignRe        = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	fenceRe            = regexp.MustCompile("^(`{3,}|~{3,})")
+	commentRe          = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// options holds the parsed lint-claudemd flags.
+type options struct {
+	path     string
+	global   bool
+	fix      bool
+	format   string
+	strict   bool
+	maxBytes int
+}
+
+// target is one CLAUDE.md to lint.
+type target struct {
+	path       string
+	display    string // path as printed in findings
+	projectDir string // directory the documented commands run in
+	global     bool   // ~/.claude/CLAUDE.md: no project sections or commands
+	required   bool   // check for the required sections
+	sibling    string // another CLAUDE.md Claude Code loads with this one
+}
+
+// Run parses the lint-claudemd flags and lints the files they select.
+func Run(args []string) error {
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	return run(platform.Stdout(), opts, home)
+}
+
+func parseArgs(args []string) (options, error) {
+	opts := options{path: ".", format: ci.FormatText, maxBytes: defaultMaxBytes}
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		opts.format = ci.FormatGitHub
+	}
+	f := cli.New("lint-claudemd", usage)
+	f.BoolVar(&opts.global, "--global", "Lint ~/.claude/CLAUDE.md instead of a project")
+	f.BoolVar(&opts.fix, "--fix", "Fix what can be fixed before linting")
+	f.BoolVar(&opts.strict, "--strict", "Fail on warnings too")
+	ci.FormatFlag(f, &opts.format)
+	f.Func("--max-bytes", "bytes", "Size limit per file", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("--max-bytes must be a positive number of bytes, got %q", v)
+		}
+		opts.maxBytes = n
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return opts, err
+	}
+	pathSet := len(positional) == 1
+	if pathSet {
+		opts.path = positional[0]
+	}
+	if opts.global && pathSet {
+		return opts, fmt.Errorf("--global cannot be combined with a path")
+	}
+	return opts, nil
+}
+
+// run lints the files opts selects, fixing them first with --fix, and prints
+// the findings to w.
+func run(w io.Writer, opts options, home string) error {
+	targets, err := resolveTargets(opts, home)
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	total := 0
+	var names []string
+	for _, t := range targets {
+		names = append(names, t.display)
+		data, err := os.ReadFile(t.path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", t.display, err)
+		}
+		content := string(data)
+		if opts.fix {
+			if fixed := fix(content, t); fixed != content {
+				if err := os.WriteFile(t.path, []byte(fixed), 0644); err != nil {
+					return fmt.Errorf("writing %s: %w", t.display, err)
+				}
+				platform.PrintSuccess(w, "Fixed "+t.display)
+				content = fixed
+			}
+		}
+		for _, f := range lint(content, t, opts.maxBytes) {
+			counts[f.Severity]++
+			total++
+			ci.WriteFinding(w, opts.format, ".", f)
+		}
+	}
+
+	summary := fmt.Sprintf("%d error(s), %d warning(s)", counts[ci.SeverityError], counts[ci.SeverityWarning])
+	failed := counts[ci.SeverityError] > 0 || (opts.strict && counts[ci.SeverityWarning] > 0)
+	switch {
+	case total == 0:
+		fmt.Fprintf(w, "claude-workspace lint-claudemd: %s OK\n", strings.Join(names, ", "))
+	case failed:
+		fmt.Fprintf(w, "claude-workspace lint-claudemd: %s\n", summary)
+		return ci.ErrFindings
+	default:
+		fmt.Fprintf(w, "claude-workspace lint-claudemd: %s; passing\n", summary)
+	}
+	return nil
+}
+
+// resolveTargets returns the files to lint: ~/.claude/CLAUDE.md with
+// --global, the file opts.path names, or the CLAUDE.md and .claude/CLAUDE.md
+// of the directory it names.
+func resolveTargets(opts options, home string) ([]target, error) {
+	globalPath := filepath.Join(home, ".claude", "CLAUDE.md")
+	if opts.global {
+		if !platform.FileExists(globalPath) {
+			return nil, fmt.Errorf("no global CLAUDE.md at %s; run: claude-workspace setup", globalPath)
+		}
+		return []target{{path: globalPath, display: globalPath, projectDir: home, global: true}}, nil
+	}
+
+	info, err := os.Stat(opts.path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		dir := filepath.Dir(opts.path)
+		if filepath.Base(dir) == ".claude" {
+			dir = filepath.Dir(dir)
+		}
+		abs, _ := filepath.Abs(opts.path)
+		global := abs == globalPath
+		return []target{{
+			path: opts.path, display: filepath.ToSlash(filepath.Clean(opts.path)),
+			projectDir: dir, global: global, required: !global,
+		}}, nil
+	}
+
+	var targets []target
+	for _, name := range []string{"CLAUDE.md", ".claude/CLAUDE.md"} {
+		path := filepath.Join(opts.path, filepath.FromSlash(name))
+		if platform.FileExists(path) {
+			targets = append(targets, target{path: path, display: filepath.ToSlash(path), projectDir: opts.path, required: true})
+		}
+	}
+	switch len(targets) {
+	case 0:
+		return nil, fmt.Errorf("no CLAUDE.md or .claude/CLAUDE.md in %s; run: claude-workspace attach", opts.path)
+	case 2:
+		// Claude Code loads both, so the required sections may be split
+		// between them. Check them together against .claude/CLAUDE.md, where
+		// attach writes them.
+		data, err := os.ReadFile(targets[0].path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", targets[0].display, err)
+		}
+		targets[0].required = false
+		targets[1].sibling = string(data)
+	}
+	return targets, nil
+}
+
+// document is a CLAUDE.md split into lines, with its fenced code blocks and
+// the ## headings outside them.
+type document struct {
+	lines    []string
+	inFence  []bool // the line is in a code block or is one of its fences
+	fences   []fence
+	headings []heading
+}
+
+// fence is a fenced code block; close is -1 when it is never closed.
+type fence struct{ open, close int }
+
+// heading is a "## " heading and its 0-based line.
+type heading struct {
+	name string
+	line int
+}
+
+func parse(content string) document {
+	d := document{lines: strings.Split(strings.TrimSuffix(content, "\n"), "\n")}
+	marker := ""
+	for i, line := range d.lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if marker != "" {
+			d.inFence = append(d.inFence, true)
+			if strings.HasPrefix(trimmed, marker) && strings.TrimSpace(strings.TrimLeft(trimmed, marker[:1])) == "" {
+				d.fences[len(d.fences)-1].close = i
+				marker = ""
+			}
+			continue
+		}
+		if m := fenceRe.FindString(trimmed); m != "" {
+			marker = m
+			d.fences = append(d.fences, fence{open: i, close: -1})
+			d.inFence = append(d.inFence, true)
+			continue
+		}
+		d.inFence = append(d.inFence, false)
+		if name, ok := strings.CutPrefix(line, "## "); ok {
+			d.headings = append(d.headings, heading{strings.TrimSpace(name), i})
+		}
+	}
+	return d
+}
+
+// firstNonBlank returns the index of the first non-blank line, or 0.
+func (d document) firstNonBlank() int {
+	for i, line := range d.lines {
+		if strings.TrimSpace(line) != "" {
+			return i
+		}
+	}
+	return 0
+}
+
+// sectionEnd returns the line after the section whose heading is at line.
+func (d document) sectionEnd(line int) int {
+	for _, h := range d.headings {
+		if h.line > line {
+			return h.line
+		}
+	}
+	return len(d.lines)
+}
+
+// unwrapFence returns the contents of content when the whole file is one
+// fenced code block, which is how model output sometimes arrives, and the
+// line the contents start on.
+func unwrapFence(content string) (string, int, bool) {
+	d := parse(content)
+	if len(d.fences) == 0 {
+		return content, 0, false
+	}
+	last := len(d.lines) - 1
+	for last > 0 && strings.TrimSpace(d.lines[last]) == "" {
+		last--
+	}
+	f := d.fences[0]
+	if f.open != d.firstNonBlank() || f.close != last {
+		return content, 0, false
+	}
+	inner := strings.Join(d.lines[f.open+1:f.close], "\n")
+	if inner != "" {
+		inner += "\n"
+	}
+	return inner, f.open + 1, true
+}
+
+// lint returns the problems in content, sorted by line.
+func lint(content string, t target, maxBytes int) []ci.Finding {
+	var findings []ci.Finding
+	if len(content) > maxBytes {
+		findings = append(findings, ci.Finding{
+			File: t.display, Severity: ci.SeverityError, Check: ruleMaxLength,
+			Message: fmt.Sprintf("%d bytes is over the %d-byte limit; it is loaded into every session, so move detail into skills or linked docs", len(content), maxBytes),
+		})
+	}
+	if inner, offset, ok := unwrapFence(content); ok {
+		findings = append(findings, ci.Finding{
+			File: t.display, Line: offset, Severity: ci.SeverityError, Check: ruleCodeFence,
+			Message: "the whole file is wrapped in a code block, so Claude reads it as an example rather than instructions",
+		})
+		for _, f := range lintBody(inner, t) {
+			if f.Line > 0 {
+				f.Line += offset
+			}
+			findings = append(findings, f)
+		}
+	} else {
+		findings = append(findings, lintBody(content, t)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// lintBody runs every rule but max-length on content.
+func lintBody(content string, t target) []ci.Finding {
+	d := parse(content)
+	var findings []ci.Finding
+	add := func(line int, severity, rule, format string, a ...any) {
+		findings = append(findings, ci.Finding{File: t.display, Line: line, Severity: severity, Check: rule, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if first := d.firstNonBlank(); !strings.HasPrefix(d.lines[first], "# ") {
+		add(first+1, ci.SeverityWarning, ruleTitle, "file does not start with a # title")
+	}
+
+	firstSection := len(d.lines)
+	if len(d.headings) > 0 {
+		firstSection = d.headings[0].line
+	}
+	for _, f := range d.fences {
+		if f.open < firstSection {
+			add(f.open+1, ci.SeverityError, ruleCodeFence, "code block before the first ## section; introduce the file in prose and move examples under a section")
+		}
+		if f.close < 0 {
+			add(f.open+1, ci.SeverityError, ruleCodeFence, "code block is never closed, so the rest of the file reads as code")
+		}
+	}
+
+	seen := map[string]int{}
+	for _, h := range d.headings {
+		if first, ok := seen[h.name]; ok {
+			add(h.line+1, ci.SeverityWarning, ruleDuplicate, "%q repeats the section on line %d; merge them", "## "+h.name, first+1)
+			continue
+		}
+		seen[h.name] = h.line
+	}
+
+	if !t.global {
+		if t.required {
+			for _, name := range missingSections(d, t.sibling) {
+				add(0, ci.SeverityWarning, ruleRequiredSection, "no %q section; run: claude-workspace enrich (or lint-claudemd --fix to add a placeholder)", "## "+name)
+			}
+		}
+		for _, s := range requiredSections {
+			for _, h := range d.headings {
+				if hasAlias(s, h.name) && sectionEmpty(d, h.line) {
+					add(h.line+1, ci.SeverityNotice, ruleEmptySection, "%q has no content yet; run: claude-workspace enrich", "## "+h.name)
+				}
+			}
+		}
+		for i, line := range d.lines {
+			m := commandRe.FindStringSubmatch(line)
+			if m == nil || d.inFence[i] {
+				continue
+			}
+			if problem := missingMarker(t.projectDir, m[2]); problem != "" {
+				msg := fmt.Sprintf("%s command `%s` %s", m[1], m[2], problem)
+				if detected := detectedCommand(t.projectDir, m[1]); detected != "" {
+					msg += fmt.Sprintf("; detected: `%s`", detected)
+				}
+				add(i+1, ci.SeverityError, ruleCommand, "%s", msg)
+			}
+		}
+	}
+
+	return append(findings, whitespaceFindings(content, d, t.display)...)
+}
+
+// whitespaceFindings reports trailing whitespace, runs of blank lines, and a
+// missing or repeated final newline, once each at their first occurrence.
+func whitespaceFindings(content string, d document, file string) []ci.Finding {
+	var findings []ci.Finding
+	add := func(line int, format string, a ...any) {
+		findings = append(findings, ci.Finding{File: file, Line: line, Severity: ci.SeverityNotice, Check: ruleWhitespace, Message: fmt.Sprintf(format, a...)})
+	}
+	trailing, trailingAt := 0, 0
+	blankRuns, blankAt := 0, 0
+	for i, line := range d.lines {
+		if line != strings.TrimRight(line, " \t") {
+			if trailing == 0 {
+				trailingAt = i + 1
+			}
+			trailing++
+		}
+		if i > 0 && isBlank(d, i) && isBlank(d, i-1) && (i < 2 || !isBlank(d, i-2)) {
+			if blankRuns == 0 {
+				blankAt = i + 1
+			}
+			blankRuns++
+		}
+	}
+	if trailing > 0 {
+		add(trailingAt, "%d line(s) end in whitespace", trailing)
+	}
+	if blankRuns > 0 {
+		add(blankAt, "%d place(s) with more than one blank line in a row", blankRuns)
+	}
+	if content != "" && (!strings.HasSuffix(content, "\n") || strings.HasSuffix(content, "\n\n")) {
+		add(len(d.lines), "file does not end with a single newline")
+	}
+	return findings
+}
+
+// isBlank reports whether line i is blank and outside a code block.
+func isBlank(d document, i int) bool {
+	return !d.inFence[i] && strings.TrimSpace(d.lines[i]) == ""
+}
+
+// missingSections returns the required sections neither d nor sibling has.
+func missingSections(d document, sibling string) []string {
+	headings := d.headings
+	if sibling != "" {
+		headings = append(append([]heading(nil), headings...), parse(sibling).headings...)
+	}
+	var missing []string
+	for _, s := range requiredSections {
+		found := false
+		for _, h := range headings {
+			found = found || hasAlias(s, h.name)
+		}
+		if !found {
+			missing = append(missing, s.heading)
+		}
+	}
+	return missing
+}
+
+func hasAlias(s requiredSection, name string) bool {
+	for _, alias := range s.aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionEmpty reports whether the section whose heading is at line holds
+// nothing but blank lines and HTML comments.
+func sectionEmpty(d document, line int) bool {
+	body := strings.Join(d.lines[line+1:d.sectionEnd(line)], "\n")
+	return strings.TrimSpace(commentRe.ReplaceAllString(body, "")) == ""
+}
+
+// missingMarker returns why cmd cannot run in projectDir, such as "runs make,
+// but there is no Makefile", or "" when every program it starts has its
+// project files. It follows cd into subdirectories.
+func missingMarker(projectDir, cmd string) string {
+	dir := projectDir
+	for _, segment := range commandSeparatorRe.Split(cmd, -1) {
+		fields := strings.Fields(segment)
+		for len(fields) > 0 && envAssignRe.MatchString(fields[0]) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		program := fields[0]
+		if program == "cd" {
+			if len(fields) < 2 || filepath.IsAbs(fields[1]) || strings.ContainsAny(fields[1], "$~`") {
+				return ""
+			}
+			dir = filepath.Join(dir, fields[1])
+			continue
+		}
+		markers, ok := programMarkers[program]
+		if !ok || hasMarker(dir, program) {
+			continue
+		}
+		// A Go module at the project root also covers its subdirectories.
+		if program == "go" && hasMarker(projectDir, program) {
+			continue
+		}
+		where := "there is no "
+		if rel, err := filepath.Rel(projectDir, dir); err == nil && rel != "." {
+			where = filepath.ToSlash(rel) + "/ has no "
+		}
+		return fmt.Sprintf("runs %s, but %s%s", program, where, orList(markers))
+	}
+	return ""
+}
+
+// hasMarker reports whether dir has one of program's marker files, or, for
+// yarn, pnpm, and bun, a package.json packageManager naming it.
+func hasMarker(dir, program string) bool {
+	for _, marker := range programMarkers[program] {
+		if strings.Contains(marker, "*") {
+			if matches, _ := filepath.Glob(filepath.Join(dir, marker)); len(matches) > 0 {
+				return true
+			}
+		} else if platform.FileExists(filepath.Join(dir, marker)) {
+			return true
+		}
+	}
+	switch program {
+	case "yarn", "pnpm", "bun", "bunx":
+		var pkg struct {
+			PackageManager string `json:"packageManager"`
+		}
+		if platform.ReadJSONFile(filepath.Join(dir, "package.json"), &pkg) == nil {
+			return strings.HasPrefix(pkg.PackageManager, strings.TrimSuffix(program, "x")+"@")
+		}
+	}
+	return false
+}
+
+// orList joins items as "a, b, or c".
+func orList(items []string) string {
+	switch len(items) {
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " or " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", or " + items[len(items)-1]
+}
+
+// detectedCommand returns the command the project detectors find for kind
+// (Build, Test, or Lint) in projectDir.
+func detectedCommand(projectDir, kind string) string {
+	build, test, lint := platform.DetectCommands(projectDir)
+	switch kind {
+	case "Build":
+		return build
+	case "Test":
+		return test
+	}
+	return lint
+}
+
+// fix repairs what can be repaired without judgment: it unwraps a file
+// wrapped in a code block, normalizes whitespace, adds placeholders for
+// missing required sections, and replaces commands that cannot run with the
+// detected ones.
+func fix(content string, t target) string {
+	if inner, _, ok := unwrapFence(content); ok {
+		content = inner
+	}
+	content = fixWhitespace(content)
+	if t.global {
+		return content
+	}
+	if t.required {
+		content = addMissingSections(content, t)
+	}
+	return fixCommands(content, t.projectDir)
+}
+
+// fixWhitespace trims trailing whitespace, collapses runs of blank lines
+// outside code blocks, and ends the file with one newline.
+func fixWhitespace(content string) string {
+	d := parse(content)
+	var out []string
+	for i, line := range d.lines {
+		if i > 0 && isBlank(d, i) && isBlank(d, i-1) {
+			continue
+		}
+		out = append(out, strings.TrimRight(line, " \t"))
+	}
+	fixed := strings.TrimRight(strings.Join(out, "\n"), "\n")
+	if fixed == "" {
+		return ""
+	}
+	return fixed + "\n"
+}
+
+// addMissingSections adds each missing required section with a placeholder:
+// Project under the title, filled with the name and detected commands, and
+// the others after it in scaffold order.
+func addMissingSections(content string, t target) string {
+	missing := missingSections(parse(content), t.sibling)
+	for _, name := range missing {
+		if name != "Project" {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString("## Project\n")
+		fmt.Fprintf(&sb, "Name: %s\n", filepath.Base(absDir(t.projectDir)))
+		build, test, lint := platform.DetectCommands(t.projectDir)
+		for _, c := range [][2]string{{"Build", build}, {"Test", test}, {"Lint", lint}} {
+			if c[1] != "" {
+				fmt.Fprintf(&sb, "%s: `%s`\n", c[0], c[1])
+			}
+		}
+		d := parse(content)
+		if first := d.firstNonBlank(); strings.HasPrefix(d.lines[first], "# ") {
+			head := strings.Join(d.lines[:first+1], "\n") + "\n\n"
+			rest := strings.TrimLeft(strings.Join(d.lines[first+1:], "\n"), "\n")
+			content = head + sb.String()
+			if rest != "" {
+				content += "\n" + rest + "\n"
+			}
+		} else if content != "" {
+			content = sb.String() + "\n" + content
+		} else {
+			content = sb.String()
+		}
+	}
+	// SetMarkdownSection inserts after ## Project, so add in reverse order.
+	for i := len(missing) - 1; i >= 0; i-- {
+		for _, s := range requiredSections {
+			if s.heading == missing[i] && s.placeholder != "" {
+				content = platform.SetMarkdownSection(content, s.heading, "## "+s.heading+"\n"+s.placeholder)
+			}
+		}
+	}
+	return content
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// fixCommands replaces each Build, Test, or Lint command that cannot run in
+// projectDir with the detected one, when there is one that can.
+func fixCommands(content, projectDir string) string {
+	d := parse(content)
+	changed := false
+	for i, line := range d.lines {
+		m := commandRe.FindStringSubmatch(line)
+		if m == nil || d.inFence[i] || missingMarker(projectDir, m[2]) == "" {
+			continue
+		}
+		detected := detectedCommand(projectDir, m[1])
+		if detected == "" || detected == m[2] || missingMarker(projectDir, detected) != "" {
+			continue
+		}
+		d.lines[i] = strings.Replace(line, "`"+m[2]+"`", "`"+detected+"`", 1)
+		changed = true
+	}
+	if !changed {
+		return content
+	}
+	return strings.Join(d.lines, "\n") + "\n"
+}
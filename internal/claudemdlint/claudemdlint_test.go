@@ -0,0 +1,171 @@
+package claudemdlint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/ci"
+)
+
+const goodClaudeMD = "# Project Instructions\n\n## Project\nName: app\nBuild: `go build ./...`\nTest: `go test ./...`\n\n## Conventions\n- Wrap errors.\n\n## Key Directories\n- cmd/ - Entry points\n"
+
+func goProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// rules returns "line:rule", with the line zero-padded, for each finding.
+func rules(findings []ci.Finding) []string {
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%02d:%s", f.Line, f.Check))
+	}
+	return got
+}
+
+func TestLint(t *testing.T) {
+	dir := goProject(t)
+	project := target{display: "CLAUDE.md", projectDir: dir, required: true}
+
+	tests := []struct {
+		name    string
+		content string
+		target  target
+		want    []string
+	}{
+		{"clean", goodClaudeMD, project, nil},
+		{"global skips project rules", "# Global\n\n## Defaults\n- Be brief.\n", target{display: "CLAUDE.md", global: true}, nil},
+		{"wrapped in a fence", "```markdown\n" + goodClaudeMD + "```\n", project, []string{"01:code-fence"}},
+		{"fence before first section", "# App\n\n```\nmake\n```\n\n## Project\nName: app\n\n## Conventions\n- x\n\n## Key Directories\n- y\n", project, []string{"03:code-fence"}},
+		{"unclosed fence", goodClaudeMD + "\n```go\nfunc main() {}\n", project, []string{"14:code-fence"}},
+		{"no title", strings.TrimPrefix(goodClaudeMD, "# Project Instructions\n\n"), project, []string{"01:title"}},
+		{"missing sections", "# App\n\n## Project Context\nName: app\n", project, []string{"00:required-section", "00:required-section"}},
+		{"duplicate and empty sections", goodClaudeMD + "\n## Conventions\n<!-- Add your team's coding conventions here -->\n", project, []string{"14:duplicate-section", "14:empty-section"}},
+		{"command without marker", strings.Replace(goodClaudeMD, "`go build ./...`", "`cd web && npm run build`", 1), project, []string{"05:command"}},
+		{"whitespace", strings.Replace(goodClaudeMD, "Name: app\n", "Name: app  \n\n\n", 1) + "\n", project, []string{"04:whitespace", "06:whitespace", "15:whitespace"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(lint(tt.content, tt.target, defaultMaxBytes))
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("lint = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := rules(lint(goodClaudeMD, project, 100)); len(got) != 1 || got[0] != "00:max-length" {
+		t.Errorf("lint over the size limit = %q", got)
+	}
+	sibling := project
+	sibling.sibling = "# Global\n\n## Conventions\n- x\n\n## Key Directories\n- y\n"
+	if got := lint("# App\n\n## Project\nName: app\n", sibling, defaultMaxBytes); len(got) != 0 {
+		t.Errorf("sections in the sibling file should count: %q", rules(got))
+	}
+}
+
+func TestMissingMarker(t *testing.T) {
+	dir := goProject(t)
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "web", "package.json"), []byte(`{"packageManager": "pnpm@9.0.0"}`), 0644)
+
+	tests := []struct {
+		cmd  string
+		want string
+	}{
+		{"go test ./...", ""},
+		{"CGO_ENABLED=0 go build ./... | tee out.log", ""},
+		{"cd web && pnpm test", ""},
+		{"cd web && go vet ./...", ""},
+		{"golangci-lint run", ""},
+		{"make build", "runs make, but there is no Makefile, GNUmakefile, or makefile"},
+		{"cd web && yarn test", "runs yarn, but web/ has no yarn.lock, .yarnrc.yml, or .yarnrc"},
+		{"npm test", "runs npm, but there is no package.json"},
+		{"cd $APP && npm test", ""},
+	}
+	for _, tt := range tests {
+		if got := missingMarker(dir, tt.cmd); got != tt.want {
+			t.Errorf("missingMarker(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestFix(t *testing.T) {
+	dir := goProject(t)
+	project := target{display: "CLAUDE.md", projectDir: dir, required: true}
+	content := "```markdown\n# App  \n\n\n## Project\nName: app\nBuild: `make build`\nTest: `go test ./...`\n```\n"
+
+	fixed := fix(content, project)
+	want := "# App\n\n## Project\nName: app\nBuild: `go build ./...`\nTest: `go test ./...`\n\n" +
+		"## Conventions\n<!-- Add your team's coding conventions here -->\n\n" +
+		"## Key Directories\n<!-- Map your project's important directories -->\n"
+	if fixed != want {
+		t.Errorf("fix =\n%s\nwant\n%s", fixed, want)
+	}
+	if fix(fixed, project) != fixed {
+		t.Error("fix is not idempotent")
+	}
+	if got := rules(lint(fixed, project, defaultMaxBytes)); strings.Join(got, " ") != "08:empty-section 11:empty-section" {
+		t.Errorf("lint after fix = %q", got)
+	}
+
+	fixed = fix("Notes only.\n", project)
+	if !strings.HasPrefix(fixed, "## Project\nName: "+filepath.Base(dir)+"\nBuild: `go build ./...`\n") {
+		t.Errorf("fix without a title =\n%s", fixed)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := goProject(t)
+	os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("# Team\n\n## Conventions\n- x\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".claude"), 0755)
+	claudePath := filepath.Join(dir, ".claude", "CLAUDE.md")
+	os.WriteFile(claudePath, []byte("# App\n\n## Project\nName: app\nBuild: `make build`\n"), 0644)
+
+	var out bytes.Buffer
+	err := run(&out, options{path: dir, format: ci.FormatText, maxBytes: defaultMaxBytes}, t.TempDir())
+	if !errors.Is(err, ci.ErrFindings) {
+		t.Fatalf("run = %v, want ErrFindings\n%s", err, out.String())
+	}
+	for _, want := range []string{
+		`.claude/CLAUDE.md:5: error: Build command ` + "`make build`" + ` runs make, but there is no Makefile, GNUmakefile, or makefile; detected: ` + "`go build ./...`" + ` [command]`,
+		`.claude/CLAUDE.md: warning: no "## Key Directories" section`,
+		"1 error(s), 1 warning(s)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Conventions") {
+		t.Errorf("Conventions in CLAUDE.md should satisfy .claude/CLAUDE.md:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := run(&out, options{path: dir, fix: true, format: ci.FormatText, maxBytes: defaultMaxBytes}, t.TempDir()); err != nil {
+		t.Fatalf("run --fix = %v\n%s", err, out.String())
+	}
+	data, _ := os.ReadFile(claudePath)
+	if !strings.Contains(string(data), "Build: `go build ./...`") || !strings.Contains(string(data), "## Key Directories") {
+		t.Errorf("fixed file:\n%s", data)
+	}
+	if !strings.Contains(out.String(), "Fixed") || !strings.Contains(out.String(), "0 error(s), 0 warning(s); passing") {
+		t.Errorf("run --fix output:\n%s", out.String())
+	}
+
+	if err := run(&out, options{path: t.TempDir(), format: ci.FormatText, maxBytes: defaultMaxBytes}, t.TempDir()); err == nil || !strings.Contains(err.Error(), "no CLAUDE.md") {
+		t.Errorf("run on a directory without CLAUDE.md = %v", err)
+	}
+	for _, args := range [][]string{{"--format", "xml"}, {"--max-bytes", "0"}, {"--global", "."}, {"a", "b"}, {"--verbose"}} {
+		if _, err := parseArgs(args); err == nil {
+			t.Errorf("parseArgs(%q): expected error", args)
+		}
+	}
+}
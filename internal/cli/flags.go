@@ -0,0 +1,260 @@
+// Package cli parses the flags of claude-workspace commands the same way
+// everywhere: "--name value" and "--name=value" are equivalent, flags and
+// positional arguments may be mixed, "--" ends the flags, an unknown flag is
+// an error, and -h or --help prints help generated from the declared flags.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// ErrHelp is returned by Parse when -h or --help was given and the help has
+// been printed. Callers return it unchanged; main exits successfully on it.
+var ErrHelp = errors.New("help requested")
+
+// FlagSet is the set of flags one command accepts.
+type FlagSet struct {
+	// Help, when set, prints the command's help in place of the text
+	// generated from the usage line and the declared flags.
+	Help func(w io.Writer)
+
+	// StopAt, when set, is called with the positional arguments so far and
+	// each new one. Returning true ends flag parsing: the argument and
+	// everything after it are left to Rest, as they are after "--".
+	StopAt func(positional []string, arg string) bool
+
+	command string
+	usage   string
+	flags   map[string]*flagDef
+	order   []*flagDef
+	rest    []string
+	dashed  bool
+}
+
+type flagDef struct {
+	name        string
+	alias       string
+	placeholder string // "" for boolean flags
+	help        string
+	def         string
+	set         func(string) error
+	seen        bool
+}
+
+// New returns an empty flag set for command ("mcp add"), whose usage line,
+// without the "claude-workspace" prefix, is usage.
+func New(command, usage string) *FlagSet {
+	return &FlagSet{command: command, usage: usage, flags: map[string]*flagDef{}}
+}
+
+func (f *FlagSet) add(d *flagDef) {
+	if _, dup := f.flags[d.name]; dup {
+		panic("cli: flag " + d.name + " declared twice for " + f.command)
+	}
+	f.flags[d.name] = d
+	f.order = append(f.order, d)
+}
+
+// BoolVar declares a flag that sets *p. "--name=false" turns it off again.
+func (f *FlagSet) BoolVar(p *bool, name, help string) {
+	f.add(&flagDef{name: name, help: help, set: func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s takes no value, got %q", name, v)
+		}
+		*p = b
+		return nil
+	}})
+}
+
+// StringVar declares a flag whose value is stored in *p. The value *p holds
+// when the flag is declared is its default.
+func (f *FlagSet) StringVar(p *string, name, placeholder, help string) {
+	f.add(&flagDef{name: name, placeholder: placeholder, help: help, def: *p, set: func(v string) error {
+		*p = v
+		return nil
+	}})
+}
+
+// IntVar declares a flag whose value, a whole number, is stored in *p. The
+// value *p holds when the flag is declared is its default.
+func (f *FlagSet) IntVar(p *int, name, placeholder, help string) {
+	var def string
+	if *p != 0 {
+		def = strconv.Itoa(*p)
+	}
+	f.add(&flagDef{name: name, placeholder: placeholder, help: help, def: def, set: func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", name, v)
+		}
+		*p = n
+		return nil
+	}})
+}
+
+// StringsVar declares a repeatable flag; each value is appended to *p.
+func (f *FlagSet) StringsVar(p *[]string, name, placeholder, help string) {
+	f.add(&flagDef{name: name, placeholder: placeholder, help: help + " (repeatable)", set: func(v string) error {
+		*p = append(*p, v)
+		return nil
+	}})
+}
+
+// Func declares a flag whose value is handled by fn, which may reject it.
+// With an empty placeholder the flag takes no value, like a BoolVar flag, and
+// fn receives "true" or the value given after "=".
+func (f *FlagSet) Func(name, placeholder, help string, fn func(string) error) {
+	f.add(&flagDef{name: name, placeholder: placeholder, help: help, set: fn})
+}
+
+// Alias makes short (such as "-n") another name for the declared flag name.
+func (f *FlagSet) Alias(short, name string) {
+	d, ok := f.flags[name]
+	if !ok {
+		panic("cli: alias " + short + " for undeclared flag " + name)
+	}
+	d.alias = short
+	f.flags[short] = d
+}
+
+// Parse parses args and returns the positional arguments. Parsing stops at
+// "--" or where StopAt says; the arguments after that are returned by Rest.
+func (f *FlagSet) Parse(args []string) ([]string, error) {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			f.rest, f.dashed = args[i+1:], true
+			break
+		}
+		if !isFlag(arg) {
+			if f.StopAt != nil && f.StopAt(positional, arg) {
+				f.rest = args[i:]
+				break
+			}
+			positional = append(positional, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		d, ok := f.flags[name]
+		if !ok {
+			if name == "--help" || name == "-h" {
+				f.PrintHelp(platform.Stdout())
+				return nil, ErrHelp
+			}
+			return nil, fmt.Errorf("unknown flag: %s (run 'claude-workspace %s --help' for usage)", name, f.command)
+		}
+		switch {
+		case d.placeholder == "" && !hasValue:
+			value = "true"
+		case d.placeholder != "" && !hasValue:
+			i++
+			if i >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", name)
+			}
+			value = args[i]
+		}
+		if err := d.set(value); err != nil {
+			return nil, err
+		}
+		d.seen = true
+	}
+	return positional, nil
+}
+
+// isFlag reports whether arg is a flag rather than a positional argument:
+// "-" (standard input) and negative numbers are positional.
+func isFlag(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+// CheckArgs returns an error unless there are at least min and, when max is
+// not negative, at most max positional arguments.
+func (f *FlagSet) CheckArgs(positional []string, min, max int) error {
+	if max >= 0 && len(positional) > max {
+		return fmt.Errorf("unexpected argument: %s (usage: claude-workspace %s)", positional[max], f.usage)
+	}
+	if len(positional) < min {
+		return fmt.Errorf("usage: claude-workspace %s", f.usage)
+	}
+	return nil
+}
+
+// Operands parses the arguments of a command that takes no flags, such as
+// "secrets rm <NAME>", and returns them, including any after "--", if there
+// are between min and max (max -1 for no limit).
+func Operands(command, usage string, args []string, min, max int) ([]string, error) {
+	f := New(command, usage)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	positional = append(positional, f.rest...)
+	return positional, f.CheckArgs(positional, min, max)
+}
+
+// Rest returns the arguments after "--", or from where StopAt ended parsing.
+func (f *FlagSet) Rest() []string {
+	return f.rest
+}
+
+// Dashed reports whether the arguments included "--".
+func (f *FlagSet) Dashed() bool {
+	return f.dashed
+}
+
+// Changed reports whether the flag name was given.
+func (f *FlagSet) Changed(name string) bool {
+	d, ok := f.flags[name]
+	return ok && d.seen
+}
+
+// Usage returns the usage line.
+func (f *FlagSet) Usage() string {
+	return "Usage: claude-workspace " + f.usage
+}
+
+// PrintHelp writes the command's help to w.
+func (f *FlagSet) PrintHelp(w io.Writer) {
+	if f.Help != nil {
+		f.Help(w)
+		return
+	}
+	fmt.Fprintln(w, f.Usage())
+	if len(f.order) == 0 {
+		return
+	}
+	labels := make([]string, len(f.order))
+	width := 0
+	for i, d := range f.order {
+		label := d.name
+		if d.alias != "" {
+			label = d.alias + ", " + d.name
+		}
+		if d.placeholder != "" {
+			label += " <" + d.placeholder + ">"
+		}
+		labels[i] = label
+		width = max(width, len(label))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	for i, d := range f.order {
+		help := d.help
+		if d.def != "" {
+			help += " (default: " + d.def + ")"
+		}
+		fmt.Fprintf(w, "  %-*s  %s\n", width, labels[i], help)
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testOpts struct {
+	scope   string
+	force   bool
+	lines   int
+	headers []string
+}
+
+func newTestSet(o *testOpts) *FlagSet {
+	f := New("test", "test <name> [flags]")
+	o.scope, o.lines = "user", 50
+	f.StringVar(&o.scope, "--scope", "scope", "Where to save")
+	f.BoolVar(&o.force, "--force", "Overwrite")
+	f.IntVar(&o.lines, "--lines", "n", "Lines to show")
+	f.Alias("-n", "--lines")
+	f.StringsVar(&o.headers, "--header", "'Key: Value'", "HTTP header")
+	return f
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     testOpts
+		wantPos  []string
+		wantRest []string
+		wantErr  string
+	}{
+		{name: "defaults", args: []string{"srv"}, want: testOpts{scope: "user", lines: 50}, wantPos: []string{"srv"}},
+		{name: "separate and equals forms", args: []string{"--scope", "project", "srv", "--lines=10", "--force"},
+			want: testOpts{scope: "project", force: true, lines: 10}, wantPos: []string{"srv"}},
+		{name: "alias", args: []string{"-n", "5"}, want: testOpts{scope: "user", lines: 5}},
+		{name: "repeatable", args: []string{"--header", "A: 1", "--header=B: 2"},
+			want: testOpts{scope: "user", lines: 50, headers: []string{"A: 1", "B: 2"}}},
+		{name: "last value wins", args: []string{"--scope", "a", "--scope", "b"}, want: testOpts{scope: "b", lines: 50}},
+		{name: "bool turned off", args: []string{"--force", "--force=false"}, want: testOpts{scope: "user", lines: 50}},
+		{name: "double dash ends flags", args: []string{"srv", "--", "npx", "--force"},
+			want: testOpts{scope: "user", lines: 50}, wantPos: []string{"srv"}, wantRest: []string{"npx", "--force"}},
+		{name: "stdin and negative numbers are positional", args: []string{"-", "-3"},
+			want: testOpts{scope: "user", lines: 50}, wantPos: []string{"-", "-3"}},
+		{name: "value may look like a flag", args: []string{"--scope", "--force"}, want: testOpts{scope: "--force", lines: 50}},
+		{name: "unknown flag", args: []string{"--nope"}, wantErr: "unknown flag: --nope (run 'claude-workspace test --help' for usage)"},
+		{name: "unknown flag with value", args: []string{"--nope=1"}, wantErr: "unknown flag: --nope"},
+		{name: "missing value", args: []string{"--scope"}, wantErr: "--scope requires a value"},
+		{name: "bad number", args: []string{"--lines", "ten"}, wantErr: `--lines must be a number, got "ten"`},
+		{name: "bad bool", args: []string{"--force=maybe"}, wantErr: "--force takes no value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o testOpts
+			f := newTestSet(&o)
+			pos, err := f.Parse(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(o, tt.want) {
+				t.Errorf("opts = %+v, want %+v", o, tt.want)
+			}
+			if !reflect.DeepEqual(pos, tt.wantPos) || !reflect.DeepEqual(f.Rest(), tt.wantRest) {
+				t.Errorf("positional = %q, rest = %q; want %q, %q", pos, f.Rest(), tt.wantPos, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestParse_StopAt(t *testing.T) {
+	var o testOpts
+	f := newTestSet(&o)
+	f.StopAt = func(positional []string, arg string) bool { return len(positional) == 1 }
+	pos, err := f.Parse([]string{"srv", "--force", "npx", "-y", "--force=false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !o.force || !reflect.DeepEqual(pos, []string{"srv"}) || !reflect.DeepEqual(f.Rest(), []string{"npx", "-y", "--force=false"}) {
+		t.Errorf("force = %v, positional = %q, rest = %q", o.force, pos, f.Rest())
+	}
+	if f.Dashed() {
+		t.Error("Dashed() = true without --")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	var o testOpts
+	f := newTestSet(&o)
+	if _, err := f.Parse([]string{"-n", "50"}); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Changed("--lines") || f.Changed("--scope") {
+		t.Errorf("Changed(--lines) = %v, Changed(--scope) = %v", f.Changed("--lines"), f.Changed("--scope"))
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	f := New("test", "test <name>")
+	if err := f.CheckArgs([]string{"a"}, 1, 1); err != nil {
+		t.Errorf("CheckArgs(a) = %v", err)
+	}
+	if err := f.CheckArgs(nil, 1, 1); err == nil || err.Error() != "usage: claude-workspace test <name>" {
+		t.Errorf("CheckArgs() = %v", err)
+	}
+	if err := f.CheckArgs([]string{"a", "b"}, 1, 1); err == nil || !strings.HasPrefix(err.Error(), "unexpected argument: b") {
+		t.Errorf("CheckArgs(a, b) = %v", err)
+	}
+	if err := f.CheckArgs([]string{"a", "b", "c"}, 0, -1); err != nil {
+		t.Errorf("CheckArgs with no maximum = %v", err)
+	}
+}
+
+func TestOperands(t *testing.T) {
+	if got, err := Operands("test", "test <name>", []string{"--", "-x"}, 1, 1); err != nil || len(got) != 1 || got[0] != "-x" {
+		t.Errorf("Operands(-- -x) = %q, %v", got, err)
+	}
+	for _, args := range [][]string{nil, {"a", "b"}, {"--force", "a"}} {
+		if _, err := Operands("test", "test <name>", args, 1, 1); err == nil {
+			t.Errorf("Operands(%q) should fail", args)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	var o testOpts
+	f := newTestSet(&o)
+	if _, err := f.Parse([]string{"srv", "--help"}); !errors.Is(err, ErrHelp) {
+		t.Errorf("--help error = %v, want ErrHelp", err)
+	}
+
+	var buf bytes.Buffer
+	f.PrintHelp(&buf)
+	want := `Usage: claude-workspace test <name> [flags]
+
+Flags:
+  --scope <scope>          Where to save (default: user)
+  --force                  Overwrite
+  -n, --lines <n>          Lines to show (default: 50)
+  --header <'Key: Value'>  HTTP header (repeatable)
+`
+	if buf.String() != want {
+		t.Errorf("help =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	f.Help = func(w io.Writer) { io.WriteString(w, "custom\n") }
+	buf.Reset()
+	f.PrintHelp(&buf)
+	if buf.String() != "custom\n" {
+		t.Errorf("custom help = %q", buf.String())
+	}
+}
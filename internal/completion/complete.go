@@ -0,0 +1,186 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcp"
+	"github.com/lamchakchan/claude-workspace/internal/plans"
+	"github.com/lamchakchan/claude-workspace/internal/sandbox"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// Directives tell the shell script what to do besides offering candidates.
+const (
+	directiveNone  = "none"  // offer only the candidates
+	directiveFiles = "files" // complete file paths
+	directiveDirs  = "dirs"  // complete directory paths
+)
+
+// maxSessions bounds how many session IDs are offered.
+const maxSessions = 50
+
+// candidate is a completion value with an optional description.
+type candidate struct {
+	value string
+	desc  string
+}
+
+// printCompletions writes the completions for words, the arguments after the
+// program name with the word being completed last, in the format the shell
+// scripts read: one "value<TAB>description" line per candidate, then a
+// ":directive" line.
+func printCompletions(w io.Writer, words []string) error {
+	cands, directive := complete(words)
+	for _, c := range cands {
+		if c.desc != "" {
+			fmt.Fprintf(w, "%s\t%s\n", c.value, c.desc)
+		} else {
+			fmt.Fprintln(w, c.value)
+		}
+	}
+	fmt.Fprintf(w, ":%s\n", directive)
+	return nil
+}
+
+// complete walks the command tree along words and returns the candidates for
+// the last word.
+func complete(words []string) ([]candidate, string) {
+	cur := ""
+	if len(words) > 0 {
+		cur = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	cmd := root
+	var pos []string
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if w == "--" {
+			// The rest is a command line for another program (mcp add).
+			return nil, directiveFiles
+		}
+		if strings.HasPrefix(w, "-") {
+			name, _, hasValue := strings.Cut(w, "=")
+			if f := lookupFlag(cmd, name); f != nil && f.value != "" && !hasValue {
+				if i == len(words)-1 {
+					return values(f.value, cur, pos)
+				}
+				i++
+			}
+			continue
+		}
+		if len(pos) == 0 {
+			if s := cmd.sub(w); s != nil {
+				cmd = s
+				continue
+			}
+		}
+		pos = append(pos, w)
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		var cands []candidate
+		for _, f := range cmd.flags {
+			if strings.HasPrefix(f.name, cur) {
+				cands = append(cands, candidate{value: f.name})
+			}
+		}
+		return cands, directiveNone
+	}
+
+	if len(pos) == 0 && len(cmd.subs) > 0 {
+		var cands []candidate
+		for _, s := range cmd.subs {
+			if strings.HasPrefix(s.name, cur) {
+				cands = append(cands, candidate{value: s.name, desc: s.desc})
+			}
+		}
+		// Commands like "sandbox <path> <branch>" also take arguments
+		// directly; fall back to them once no subcommand matches.
+		if len(cands) > 0 || len(cmd.args) == 0 {
+			return cands, directiveNone
+		}
+	}
+	if len(pos) < len(cmd.args) {
+		return values(cmd.args[len(pos)], cur, pos)
+	}
+	return nil, directiveNone
+}
+
+// lookupFlag finds a flag on cmd, or a global flag on the root command.
+func lookupFlag(cmd *command, name string) *flag {
+	if f := cmd.flag(name); f != nil {
+		return f
+	}
+	return root.flag(name)
+}
+
+// values returns the candidates for a value of the given kind. pos holds the
+// positional arguments before it.
+func values(kind, cur string, pos []string) ([]candidate, string) {
+	var names []string
+	switch kind {
+	case valueFile:
+		return nil, directiveFiles
+	case valueDir:
+		return nil, directiveDirs
+	case valueMCPServer:
+		return mcpServers(cur), directiveNone
+	case valueSession:
+		names, _ = sessions.RecentIDs(maxSessions)
+	case valuePlan:
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, directiveNone
+		}
+		found, _ := plans.List(plans.Dir(cwd), true)
+		for _, p := range found {
+			names = append(names, p.Name())
+		}
+	case valueSandbox:
+		if len(pos) == 0 {
+			return nil, directiveNone
+		}
+		_, sandboxes, err := sandbox.Sandboxes(pos[len(pos)-1])
+		if err != nil {
+			return nil, directiveNone
+		}
+		for _, sb := range sandboxes {
+			names = append(names, sb.Branch)
+		}
+	default:
+		if strings.Contains(kind, "|") {
+			names = strings.Split(kind, "|")
+		}
+	}
+
+	var cands []candidate
+	for _, n := range names {
+		if strings.HasPrefix(n, cur) {
+			cands = append(cands, candidate{value: n})
+		}
+	}
+	return cands, directiveNone
+}
+
+// mcpServers returns the configured MCP servers matching prefix, described by
+// their scope. A name configured in several scopes is offered once.
+func mcpServers(prefix string) []candidate {
+	servers, err := mcp.DiscoverServers()
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var cands []candidate
+	for _, s := range servers {
+		if seen[s.Name] || !strings.HasPrefix(s.Name, prefix) {
+			continue
+		}
+		seen[s.Name] = true
+		cands = append(cands, candidate{value: s.Name, desc: s.Scope})
+	}
+	return cands
+}
@@ -0,0 +1,219 @@
+// Package completion implements the "completion" command, which prints shell
+// completion scripts for bash, zsh, and fish and installs them into the
+// user's shell. The scripts call back into "completion __complete", so
+// commands, flags, and values such as MCP server names, session IDs, and
+// sandbox branches always match the installed binary and the current machine.
+package completion
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const bashScript = `# bash completion for claude-workspace
+_claude_workspace() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" line directive=none
+    COMPREPLY=()
+    while IFS= read -r line; do
+        case "$line" in
+            :*) directive="${line#:}" ;;
+            *) COMPREPLY+=("${line%%$'\t'*}") ;;
+        esac
+    done < <(claude-workspace completion __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)
+
+    case "$directive" in
+        files|dirs)
+            local kind=-f
+            [[ "$directive" == dirs ]] && kind=-d
+            while IFS= read -r line; do
+                COMPREPLY+=("$line")
+            done < <(compgen "$kind" -- "$cur")
+            compopt -o filenames 2>/dev/null
+            ;;
+    esac
+}
+complete -F _claude_workspace claude-workspace
+`
+
+const zshScript = `#compdef claude-workspace
+# zsh completion for claude-workspace
+_claude_workspace() {
+    local line directive=none
+    local -a candidates
+    for line in "${(@f)$(claude-workspace completion __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        case "$line" in
+            :*) directive="${line#:}" ;;
+            *$'\t'*) candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}") ;;
+            *) candidates+=("${line//:/\\:}") ;;
+        esac
+    done
+
+    case "$directive" in
+        files) _files ;;
+        dirs) _files -/ ;;
+        *) _describe 'claude-workspace' candidates ;;
+    esac
+}
+if (( ! $+functions[compdef] )); then
+    autoload -Uz compinit && compinit
+fi
+compdef _claude_workspace claude-workspace
+`
+
+const fishScript = `# fish completion for claude-workspace
+function __claude_workspace_complete
+    set -l tokens (commandline -opc)
+    set -l cur (commandline -ct)
+    set -l directive none
+    for line in (claude-workspace completion __complete $tokens[2..-1] "$cur" 2>/dev/null)
+        switch $line
+            case ':*'
+                set directive (string sub -s 2 -- $line)
+            case '*'
+                echo $line
+        end
+    end
+    switch $directive
+        case files
+            __fish_complete_path "$cur"
+        case dirs
+            __fish_complete_directories "$cur"
+    end
+end
+complete -c claude-workspace -f -a '(__claude_workspace_complete)'
+`
+
+// scripts maps each supported shell to its completion script.
+var scripts = map[string]string{
+	"bash": bashScript,
+	"zsh":  zshScript,
+	"fish": fishScript,
+}
+
+// Run is the entry point for the completion command.
+func Run(args []string) error {
+	return RunTo(os.Stdout, args)
+}
+
+// RunTo is like Run but writes to w instead of os.Stdout.
+func RunTo(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		printHelp(w)
+		return fmt.Errorf("a shell is required (bash, zsh, or fish)")
+	}
+	switch args[0] {
+	case "__complete":
+		return printCompletions(w, args[1:])
+	case "install":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("getting home directory: %w", err)
+		}
+		shell := ""
+		if len(args) > 1 {
+			shell = args[1]
+		}
+		return Install(w, home, shell)
+	case "--help", "-h", "help":
+		printHelp(w)
+		return nil
+	}
+	script, ok := scripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", args[0])
+	}
+	fmt.Fprint(w, script)
+	return nil
+}
+
+func printHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace completion <bash|zsh|fish|install> [shell]
+
+Print a shell completion script, or install it for your shell.
+
+Subcommands:
+  bash|zsh|fish      Print the completion script for the shell
+  install [shell]    Load completion in new shells (default: your login shell)
+
+Examples:
+  claude-workspace completion install
+  eval "$(claude-workspace completion bash)"    # current shell only
+  claude-workspace completion fish > ~/.config/fish/completions/claude-workspace.fish
+`)
+}
+
+// Install sets up completion for shell ("" for the login shell) in new
+// shells. For bash and zsh it adds a line to the RC file that loads the
+// script from the installed binary, so upgrades need no reinstall; for fish
+// it writes a loader to ~/.config/fish/completions.
+func Install(w io.Writer, home, shell string) error {
+	shell, path, line, err := target(home, shell)
+	if err != nil {
+		return err
+	}
+
+	if shell == "fish" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(line+"\n"), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	} else {
+		modified, err := platform.AppendLineToRC(path, line)
+		if err != nil {
+			return fmt.Errorf("updating %s: %w", path, err)
+		}
+		if !modified {
+			fmt.Fprintf(w, "  Shell completion for %s is already installed in %s.\n", shell, path)
+			return nil
+		}
+	}
+	platform.PrintOK(w, fmt.Sprintf("Shell completion for %s installed in %s", shell, path))
+	fmt.Fprintln(w, "  It takes effect in new shells.")
+	return nil
+}
+
+// Installed reports whether completion for shell ("" for the login shell) is
+// already set up.
+func Installed(home, shell string) bool {
+	_, path, line, err := target(home, shell)
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), line)
+}
+
+// target resolves shell ("" for the login shell) and returns the file its
+// completion is installed in and the line that loads it.
+func target(home, shell string) (name, path, line string, err error) {
+	if shell == "" {
+		path, shell = platform.DetectShellRC(home)
+	}
+	switch shell {
+	case "bash":
+		if path == "" {
+			path = filepath.Join(home, ".bashrc")
+		}
+	case "zsh":
+		if path == "" {
+			path = filepath.Join(home, ".zshrc")
+		}
+	case "fish":
+		return shell, FishFile(home), "claude-workspace completion fish | source", nil
+	default:
+		return "", "", "", fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", shell)
+	}
+	return shell, path, fmt.Sprintf(`command -v claude-workspace >/dev/null 2>&1 && eval "$(claude-workspace completion %s)"`, shell), nil
+}
+
+// FishFile returns the completion loader Install writes for fish.
+func FishFile(home string) string {
+	return filepath.Join(home, ".config", "fish", "completions", "claude-workspace.fish")
+}
@@ -0,0 +1,165 @@
+package completion
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func candidateValues(cands []candidate) []string {
+	out := make([]string, len(cands))
+	for i, c := range cands {
+		out[i] = c.value
+	}
+	return out
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		words         []string
+		want          []string
+		wantDirective string
+	}{
+		{[]string{"se"}, []string{"setup", "sessions", "secrets"}, directiveNone},
+		{[]string{"mcp", "re"}, []string{"remote", "remove", "registry"}, directiveNone},
+		{[]string{"mcp", "add", "--sc"}, []string{"--scope"}, directiveNone},
+		{[]string{"mcp", "add", "--scope", ""}, []string{"user", "project", "local"}, directiveNone},
+		{[]string{"mcp", "add", "db", "--", "np"}, nil, directiveFiles},
+		{[]string{"attach", "--profile", "b"}, []string{"backend"}, directiveNone},
+		{[]string{"attach", "--profile", "backend", ""}, nil, directiveDirs},
+		{[]string{"attach", "/src/app", ""}, nil, directiveNone},
+		{[]string{"hooks", "run", "lint", "--input", ""}, nil, directiveFiles},
+		{[]string{"--ca-cert", "ca.pem", "doc"}, []string{"doctor"}, directiveNone},
+		{[]string{"upgrade", "--channel", ""}, []string{"stable", "beta", "nightly"}, directiveNone},
+		{[]string{"memory", "sync", "p"}, []string{"push", "pull"}, directiveNone},
+		{[]string{"completion", ""}, []string{"bash", "zsh", "fish", "install"}, directiveNone},
+		// "sandbox <path> <branch>" is the legacy form of sandbox create.
+		{[]string{"sandbox", "/sr"}, nil, directiveDirs},
+		{[]string{"sandbox", "/src/app", ""}, nil, directiveNone},
+	}
+	for _, tt := range tests {
+		got, directive := complete(tt.words)
+		if !reflect.DeepEqual(candidateValues(got), tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+			t.Errorf("complete(%q) = %q, want %q", tt.words, candidateValues(got), tt.want)
+		}
+		if directive != tt.wantDirective {
+			t.Errorf("complete(%q) directive = %q, want %q", tt.words, directive, tt.wantDirective)
+		}
+	}
+}
+
+func TestComplete_DynamicValues(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+	claudeJSON := `{"mcpServers": {"brave-search": {}, "sentry": {}, "bravo": {}}}`
+	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(claudeJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".mcp.json", []byte(`{"mcpServers": {"brave-search": {}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sessionDir := filepath.Join(home, ".claude", "projects", "-src-app")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"8a3f1b2c-0000", "8b00c0de-0000", "1234abcd-0000"} {
+		if err := os.WriteFile(filepath.Join(sessionDir, id+".jsonl"), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, _ := complete([]string{"mcp", "remove", "bra"})
+	if want := []string{"brave-search", "bravo"}; !reflect.DeepEqual(candidateValues(got), want) {
+		t.Errorf("MCP server completion = %q, want %q (each name once)", candidateValues(got), want)
+	}
+	if got[0].desc != "user" {
+		t.Errorf("MCP server description = %q, want its scope", got[0].desc)
+	}
+
+	got, _ = complete([]string{"sessions", "export", "8"})
+	ids := candidateValues(got)
+	if len(ids) != 2 || !strings.HasPrefix(ids[0], "8") || !strings.HasPrefix(ids[1], "8") {
+		t.Errorf("session completion = %q, want the two IDs starting with 8", ids)
+	}
+	// "sessions <id>" is the legacy form of sessions show.
+	if got, _ := complete([]string{"sessions", "123"}); !reflect.DeepEqual(candidateValues(got), []string{"1234abcd-0000"}) {
+		t.Errorf("sessions <id> completion = %q", candidateValues(got))
+	}
+}
+
+func TestPrintCompletions(t *testing.T) {
+	var out bytes.Buffer
+	if err := RunTo(&out, []string{"__complete", "statusline", "--theme", "m"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "minimal\nmono\n:none\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunTo(&out, []string{"__complete", "doc"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "doctor\tCheck platform configuration health\n:none\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestScripts(t *testing.T) {
+	for shell := range scripts {
+		var out bytes.Buffer
+		if err := RunTo(&out, []string{shell}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "claude-workspace completion __complete") {
+			t.Errorf("%s script does not call back into __complete", shell)
+		}
+		if path, err := exec.LookPath(shell); err == nil {
+			cmd := exec.Command(path, "-n")
+			if shell == "fish" {
+				cmd = exec.Command(path, "--no-execute")
+			}
+			cmd.Stdin = strings.NewReader(out.String())
+			if msg, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("%s script has a syntax error: %v\n%s", shell, err, msg)
+			}
+		}
+	}
+	if err := RunTo(&bytes.Buffer{}, []string{"powershell"}); err == nil {
+		t.Error("an unsupported shell should be an error")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("SHELL", "/bin/zsh")
+
+	if Installed(home, "") {
+		t.Fatal("completion should not be installed in a fresh home")
+	}
+	for i := 0; i < 2; i++ {
+		if err := Install(&bytes.Buffer{}, home, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(home, ".zshrc"))
+	if strings.Count(string(data), `eval "$(claude-workspace completion zsh)"`) != 1 {
+		t.Errorf(".zshrc should load zsh completion once:\n%s", data)
+	}
+	if !Installed(home, "") || Installed(home, "bash") {
+		t.Error("only zsh completion should be installed")
+	}
+
+	if err := Install(&bytes.Buffer{}, home, "fish"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(home, ".config", "fish", "completions", "claude-workspace.fish"))
+	if !strings.Contains(string(data), "claude-workspace completion fish | source") || !Installed(home, "fish") {
+		t.Errorf("fish completion loader = %q", data)
+	}
+}
@@ -0,0 +1,438 @@
+package completion
+
+// Value kinds for positional arguments and flag values. Anything else
+// containing "|" is a list of choices; any other string is free text with
+// nothing to suggest.
+const (
+	valueFile      = "file"       // a file path
+	valueDir       = "dir"        // a directory path
+	valueText      = "text"       // free text
+	valueMCPServer = "mcp-server" // a configured MCP server name
+	valueSession   = "session"    // a Claude Code session ID
+	valueSandbox   = "sandbox"    // a sandbox branch of the project named in the previous argument
+	valuePlan      = "plan"       // a plan in the current project's plans directory
+)
+
+const (
+	scopeChoices = "user|project|local"
+	hookEvents   = "PreToolUse|PostToolUse|UserPromptSubmit|Notification|Stop|SubagentStop|PreCompact|SessionStart|SessionEnd"
+	profiles     = "minimal|backend|data-science"
+	shellChoices = "bash|zsh|fish"
+	planStatuses = "draft|approved|in-progress|complete"
+)
+
+// flag is a command-line flag. value is empty for boolean flags and
+// otherwise the kind of value the flag takes.
+type flag struct {
+	name  string
+	value string
+}
+
+// command is a node in the command tree that completion walks.
+type command struct {
+	name  string
+	desc  string
+	args  []string // value kinds of positional arguments, in order
+	flags []flag
+	subs  []*command
+}
+
+// sub returns the subcommand named name, or nil.
+func (c *command) sub(name string) *command {
+	for _, s := range c.subs {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// flag returns the flag named name, or nil.
+func (c *command) flag(name string) *flag {
+	for i := range c.flags {
+		if c.flags[i].name == name {
+			return &c.flags[i]
+		}
+	}
+	return nil
+}
+
+func b(name string) flag        { return flag{name: name} }
+func v(name, value string) flag { return flag{name: name, value: value} }
+
+// mcpAuthFlags are the authentication flags shared by mcp add and mcp remote.
+var mcpAuthFlags = []flag{
+	v("--api-key", valueText), b("--bearer"), b("--oauth"), v("--client-id", valueText),
+	b("--client-secret"), v("--header", valueText), b("--no-secret-store"),
+	b("--device"), b("--no-browser"), v("--callback-port", valueText),
+}
+
+// root mirrors the commands and flags in main.go's help text. Keep the two
+// in sync when adding a command or flag.
+var root = &command{
+	name: "claude-workspace",
+	flags: []flag{
+		b("--help"), b("--version"), v("--ca-cert", valueFile),
+		b("--json"), b("--quiet"), b("--no-color"), b("--verbose"),
+	},
+	subs: []*command{
+		{name: "setup", desc: "First-time setup & API key provisioning", flags: []flag{
+			b("--offline"), v("--claude-binary", valueFile), b("--non-interactive"), v("--config", valueFile),
+			v("--api-key-env", valueText), v("--org-policy", valueText), v("--org-policy-key", valueFile),
+			v("--tools", valueText), v("--mcp-servers", valueText), b("--no-modify-rc"), b("--force"),
+		}},
+		{name: "attach", desc: "Attach platform config to a project", args: []string{valueDir}, flags: []flag{
+			b("--symlink"), b("--hardlink"), b("--force"), b("--no-enrich"), v("--profile", profiles), b("--list-profiles"),
+			b("--monorepo"), b("--devcontainer"), b("--governance"), v("--owners", valueText), v("--template", valueText), v("--template-sha256", valueText),
+			b("--verify-signature"), b("--dry-run"), b("--check"), b("--reconcile"), b("--pin"), b("--upgrade-assets"),
+		}},
+		{name: "detach", desc: "Remove platform config from a project", args: []string{valueDir}, flags: []flag{
+			b("--force"), b("--keep-claude-md"), v("--profile", profiles), v("--template", valueText),
+		}},
+		{name: "enrich", desc: "Re-generate .claude/CLAUDE.md with AI analysis", args: []string{valueDir}, flags: []flag{
+			b("--scaffold-only"), b("--static-deep"), v("--section", "key-directories|conventions|important-files"),
+			b("--monorepo"), b("--agents"), b("--skills"), b("--stack"), b("--yes"),
+		}},
+		{name: "assets", desc: "Inspect the template attach lays down", subs: []*command{
+			{name: "list", desc: "List the template's assets", flags: []flag{
+				v("--kind", "agents|skills|hooks|settings|instructions|other"), b("--json"),
+			}},
+			{name: "show", desc: "Print an asset's content", args: []string{valueText}},
+			{name: "diff", desc: "Diff the template against a project or release", args: []string{valueText}, flags: []flag{
+				v("--project", valueDir), v("--version", valueText),
+			}},
+		}},
+		{name: "localize", desc: "Set up .claude/settings.local.json by answering questions", args: []string{valueDir}, flags: []flag{
+			b("--dry-run"),
+		}},
+		{name: "sandbox", desc: "Manage sandboxed branch worktrees", args: []string{valueDir, valueText}, flags: []flag{b("--launch")}, subs: []*command{
+			{name: "create", desc: "Create a sandboxed branch worktree", args: []string{valueDir, valueText}, flags: []flag{
+				b("--launch"), b("--container"), v("--engine", "docker|podman"), v("--allow-host", valueText), v("--env", valueText),
+			}},
+			{name: "batch", desc: "Create many sandboxes in parallel from a task file", args: []string{valueDir}, flags: []flag{
+				v("--tasks", valueFile), v("--jobs", valueText),
+			}},
+			{name: "list", desc: "List sandboxes with branch, age, and dirty status", args: []string{valueDir}},
+			{name: "status", desc: "Show a sandbox's changes and upstream status", args: []string{valueDir, valueSandbox}},
+			{name: "finish", desc: "Test, push, and open a pull request for a sandbox", args: []string{valueDir, valueSandbox}, flags: []flag{
+				b("--skip-tests"), v("--test-cmd", valueText), v("--base", valueText), b("--draft"), b("--remove"),
+			}},
+			{name: "remove", desc: "Remove a sandboxed branch worktree", args: []string{valueDir, valueSandbox}, flags: []flag{
+				b("--delete-branch"), b("--force"),
+			}},
+			{name: "prune", desc: "Remove sandboxes whose branches are merged or gone", args: []string{valueDir}, flags: []flag{b("--dry-run")}},
+		}},
+		{name: "mcp", desc: "Manage MCP servers", subs: []*command{
+			{name: "add", desc: "Add an MCP server (local or remote)", args: []string{valueText}, flags: append([]flag{
+				v("--scope", scopeChoices), v("--transport", "stdio|http|sse"), v("--env", valueText), v("--from-registry", valueText),
+				b("--supervise"),
+			}, mcpAuthFlags...)},
+			{name: "remote", desc: "Connect to a remote MCP server/gateway", args: []string{valueText}, flags: append([]flag{
+				v("--scope", scopeChoices), v("--name", valueText),
+			}, mcpAuthFlags...)},
+			{name: "list", desc: "List all configured MCP servers"},
+			{name: "remove", desc: "Remove an MCP server", args: []string{valueMCPServer}, flags: []flag{v("--scope", scopeChoices)}},
+			{name: "update", desc: "Change an MCP server's URL, headers, env, or keys", args: []string{valueMCPServer}, flags: []flag{
+				v("--url", valueText), v("--header", valueText), v("--env", valueText), v("--api-key", valueText),
+				b("--bearer"), b("--oauth"), b("--device"), b("--no-browser"), v("--scope", scopeChoices),
+				b("--supervise"), b("--no-supervise"),
+			}},
+			{name: "registry", desc: "Manage the organization registry of approved MCP servers", subs: []*command{
+				{name: "set", desc: "Set the registry URL or file", args: []string{valueFile}},
+				{name: "show", desc: "Show the registry and its servers"},
+				{name: "unset", desc: "Remove the registry"},
+			}},
+			{name: "serve", desc: "Run an MCP server under a supervisor", args: []string{valueMCPServer}, flags: []flag{
+				v("--max-restarts", valueText),
+			}},
+			{name: "ps", desc: "List supervised MCP servers and their health"},
+			{name: "logs", desc: "Show a supervised MCP server's log", args: []string{valueMCPServer}, flags: []flag{
+				v("--lines", valueText), b("--follow"),
+			}},
+			{name: "gateway", desc: "Serve the configured stdio servers through one HTTP endpoint", flags: []flag{
+				v("--listen", valueText), v("--servers", valueText), v("--allow", valueText), v("--token-env", valueText),
+				b("--no-auth"), v("--audit-log", valueFile), v("--tls-cert", valueFile), v("--tls-key", valueFile),
+			}},
+			{name: "export", desc: "Write the configured servers to a shareable bundle", flags: []flag{
+				v("--output", valueFile), v("--scope", scopeChoices), v("--servers", valueText),
+			}},
+			{name: "import", desc: "Add the servers in a bundle, prompting for their secrets", args: []string{valueFile}, flags: []flag{
+				v("--scope", scopeChoices), v("--servers", valueText),
+			}},
+		}},
+		{name: "upgrade", desc: "Upgrade claude-workspace and Claude Code CLI", flags: []flag{
+			b("--self-only"), b("--cli-only"), b("--check"), b("--yes"), v("--channel", "stable|beta|nightly"),
+			b("--rollback"), v("--from-file", valueFile), b("--skip-signature"),
+		}},
+		{name: "uninstall", desc: "Remove claude-workspace from this machine", flags: []flag{
+			b("--strip-rc"), b("--dry-run"), b("--yes"),
+		}},
+		{name: "doctor", desc: "Check platform configuration health", args: []string{valueDir}, flags: []flag{
+			b("--json"), b("--fix"), b("--dry-run"), b("--offline"), v("--timeout", valueText), v("--project", valueDir), b("--all"),
+		}},
+		{name: "ci", desc: "Check a repository's platform config for CI", subs: []*command{
+			{name: "verify", desc: "Check a repository's platform config for CI", args: []string{valueDir}, flags: []flag{
+				v("--format", "text|github"), b("--strict"), v("--max-claude-md", valueText),
+			}},
+		}},
+		{name: "lint-claudemd", desc: "Check CLAUDE.md structure, size, and commands", args: []string{valueFile}, flags: []flag{
+			b("--global"), b("--fix"), v("--format", "text|github"), b("--strict"), v("--max-bytes", valueText),
+		}},
+		{name: "fleet", desc: "Run a command across many repositories in parallel", subs: []*command{
+			{name: "attach", desc: "Attach platform config to every repository", flags: []flag{
+				v("--repos", valueFile), v("--scan", valueDir), v("--max-parallel", valueText),
+			}},
+			{name: "upgrade", desc: "Update stale template files in every repository", flags: []flag{
+				v("--repos", valueFile), v("--scan", valueDir), v("--max-parallel", valueText),
+			}},
+			{name: "check", desc: "Report repositories that drifted from the template", flags: []flag{
+				v("--repos", valueFile), v("--scan", valueDir), v("--max-parallel", valueText),
+			}},
+			{name: "doctor", desc: "Run doctor in every repository", flags: []flag{
+				v("--repos", valueFile), v("--scan", valueDir), v("--max-parallel", valueText),
+			}},
+		}},
+		{name: "report", desc: "Inventory this machine's platform setup", flags: []flag{
+			v("--output", valueFile), v("--format", "text|json|html"), v("--scan", valueDir),
+		}},
+		{name: "bugreport", desc: "Collect a redacted report for filing an issue", flags: []flag{
+			v("--output", valueFile), v("--format", "markdown|tar"), v("--logs", valueText), b("--no-doctor"),
+		}},
+		{name: "agents", desc: "List, inspect, and validate agents", subs: []*command{
+			{name: "list", desc: "List agents and the effective set"},
+			{name: "show", desc: "Show an agent's effective definition", args: []string{valueText}},
+			{name: "validate", desc: "Check agent frontmatter, models, and tools", args: []string{valueText}},
+		}},
+		{name: "skills", desc: "List, install, and remove skills", subs: []*command{
+			{name: "list", desc: "List skills, installed versions, and personal commands"},
+			{name: "install", desc: "Install a skill bundle", args: []string{valueFile}, flags: []flag{v("--sha256", valueText), b("--force")}},
+			{name: "remove", desc: "Remove an installed skill", args: []string{valueText}, flags: []flag{b("--force")}},
+		}},
+		{name: "commands", desc: "List and add project slash commands", subs: []*command{
+			{name: "list", desc: "List slash commands and project tasks without one"},
+			{name: "add", desc: "Add a slash command for a project task", args: []string{valueText}, flags: []flag{
+				v("--run", valueText), v("--description", valueText), b("--careful"), b("--force"),
+			}},
+		}},
+		{name: "hooks", desc: "List, toggle, scaffold, and test hooks", subs: []*command{
+			{name: "list", desc: "List hook scripts and configured hooks"},
+			{name: "enable", desc: "Restore a hook in settings.json", args: []string{valueText}, flags: []flag{v("--event", hookEvents)}},
+			{name: "disable", desc: "Remove a hook from settings.json", args: []string{valueText}, flags: []flag{v("--event", hookEvents)}},
+			{name: "add", desc: "Scaffold a hook script and register it", args: []string{valueText}, flags: []flag{
+				v("--event", hookEvents), v("--matcher", valueText),
+			}},
+			{name: "run", desc: "Run a hook locally with sample event JSON", args: []string{valueText}, flags: []flag{
+				v("--event", hookEvents), v("--input", valueFile),
+			}},
+		}},
+		{name: "hook-relay", desc: "Forward hook events to an HTTP endpoint or SQLite", flags: relayFlags, subs: []*command{
+			{name: "install", desc: "Register the relay in settings.json", flags: relayInstallFlags},
+		}},
+		{name: "events", desc: "Query hook events recorded with hook-relay --local", flags: eventsFlags, subs: []*command{
+			{name: "list", desc: "Sessions with recorded events", flags: eventsFlags},
+			{name: "query", desc: "Recorded events, oldest first", flags: eventsFlags},
+		}},
+		{name: "statusline", desc: "Configure Claude Code statusline", flags: statuslineFlags, subs: []*command{
+			{name: "preview", desc: "Render sample lines without changing settings", flags: statuslineFlags},
+		}},
+		{name: "sessions", desc: "Browse, review, export, and resume sessions", args: []string{valueSession}, subs: []*command{
+			{name: "list", desc: "List sessions", flags: []flag{b("--all"), v("--limit", valueText)}},
+			{name: "show", desc: "Show all user prompts from a session", args: []string{valueSession}},
+			{name: "export", desc: "Export the full transcript", args: []string{valueSession}, flags: []flag{
+				v("--format", "md|json|html"), v("--output", valueFile),
+			}},
+			{name: "redact", desc: "Write a copy of a session with secrets replaced", args: []string{valueSession}, flags: []flag{
+				v("--rules", valueFile), v("--format", "jsonl|md|json|html"), v("--output", valueFile),
+			}},
+			{name: "stats", desc: "Usage metrics across sessions", flags: []flag{
+				b("--all"), v("--days", valueText), v("--top", valueText), b("--json"),
+			}},
+			{name: "audit", desc: "Audit Bash commands, file writes, and MCP calls", args: []string{valueSession}, flags: []flag{
+				b("--all"), v("--since", valueText), b("--flagged"), v("--format", "text|json|csv"), v("--output", valueFile),
+			}},
+			{name: "resume", desc: "Resume a session with claude", args: []string{valueSession}},
+			{name: "browse", desc: "Interactive session browser"},
+		}},
+		{name: "plans", desc: "Scaffold, list, archive, and link plan files", subs: []*command{
+			{name: "list", desc: "List active plans with status and progress", flags: []flag{
+				b("--all"), v("--status", planStatuses),
+			}},
+			{name: "show", desc: "Show a plan with its linked sessions", args: []string{valuePlan}},
+			{name: "new", desc: "Scaffold a plan from the template", args: []string{valueText}, flags: []flag{
+				v("--owner", valueText), v("--status", planStatuses),
+			}},
+			{name: "archive", desc: "Move finished plans to the archive", args: []string{valuePlan}, flags: []flag{
+				b("--completed"), b("--force"),
+			}},
+			{name: "link", desc: "Link sessions to a plan", args: []string{valuePlan, valueSession}},
+		}},
+		{name: "memory", desc: "Inspect and manage memory layers", subs: []*command{
+			{name: "show", desc: "Show memory layers", flags: []flag{v("--scope", "user|project|local|auto|mcp|all")}},
+			{name: "configure", desc: "Choose the memory MCP provider", flags: []flag{
+				v("--provider", "mcp-memory-libsql|engram|none"), v("--db-path", valueFile), b("--yes"), b("--migrate"), b("--dry-run"),
+			}},
+			{name: "export", desc: "Export all layers to structured JSON", flags: []flag{
+				v("--output", valueFile), b("--encrypt"), v("--passphrase-env", valueText), v("--recipient", valueText),
+			}},
+			{name: "import", desc: "Import layers from an export", args: []string{valueFile}, flags: []flag{
+				v("--scope", "user|project|local|auto|mcp|all"), b("--merge"), b("--confirm"),
+				v("--passphrase-env", valueText), v("--identity", valueFile),
+			}},
+			{name: "diff", desc: "Compare an export against the current layers", args: []string{valueFile}, flags: []flag{
+				v("--scope", "user|project|local|auto|mcp|all"), v("--passphrase-env", valueText), v("--identity", valueFile),
+			}},
+			{name: "prune", desc: "Remove old auto and MCP memories", flags: []flag{
+				v("--older-than", valueText), v("--scope", "auto|mcp"), b("--confirm"),
+			}},
+			{name: "tokens", desc: "Estimate tokens of always-loaded memory files", flags: []flag{v("--budget", valueText)}},
+			{name: "sync", desc: "Sync memory between machines", subs: []*command{
+				{name: "init", desc: "Clone a private repo to sync memory", flags: []flag{v("--remote", valueText)}},
+				{name: "push", desc: "Upload memory layers", flags: []flag{b("--force")}},
+				{name: "pull", desc: "Merge remote memory layers", flags: []flag{b("--force")}},
+			}},
+			{name: "snapshot", desc: "Scheduled memory snapshots", subs: []*command{
+				{name: "status", desc: "Show the schedule and latest snapshot"},
+				{name: "enable", desc: "Take snapshots on a schedule", flags: []flag{
+					v("--interval", "hourly|daily|weekly"), v("--keep", valueText), v("--via", "launchd|systemd|hook"),
+				}},
+				{name: "disable", desc: "Remove the snapshot schedule"},
+				{name: "run", desc: "Take a snapshot now", flags: []flag{b("--if-due")}},
+				{name: "list", desc: "List snapshots"},
+				{name: "restore", desc: "Import a snapshot", args: []string{valueText}, flags: []flag{
+					v("--scope", "user|project|local|auto|mcp|all"), b("--merge"), b("--confirm"),
+				}},
+			}},
+		}},
+		{name: "cost", desc: "View Claude Code usage and costs", flags: costFlags, subs: []*command{
+			{name: "daily", desc: "Usage by day", flags: costFlags},
+			{name: "weekly", desc: "Usage by week", flags: costFlags},
+			{name: "monthly", desc: "Usage by month", flags: costFlags},
+			{name: "session", desc: "Usage by conversation session", flags: costFlags},
+			{name: "blocks", desc: "Usage by 5-hour billing window", flags: append([]flag{b("--active"), b("--watch"), v("--interval", valueText)}, costFlags...)},
+			{name: "budget", desc: "Show and set budgets", subs: []*command{
+				{name: "show", desc: "Show budgets and current spending"},
+				{name: "set", desc: "Set budgets", flags: []flag{v("--monthly", valueText), v("--per-session", valueText)}},
+				{name: "clear", desc: "Remove all budgets"},
+			}},
+			{name: "export", desc: "Export spend by project and model", flags: []flag{
+				v("--format", "csv|json"), v("--since", valueText), v("--until", valueText), v("--output", valueFile),
+			}},
+			{name: "report", desc: "Spend grouped by project and by model", flags: []flag{
+				v("--since", valueText), v("--until", valueText), v("--input", valueFile),
+			}},
+			{name: "by-project", desc: "Spend by project directory", flags: []flag{
+				v("--since", valueText), v("--until", valueText), b("--json"),
+			}},
+		}},
+		{name: "plugins", desc: "Manage Claude Code plugins", subs: []*command{
+			{name: "list", desc: "List installed plugins"},
+			{name: "add", desc: "Install a plugin", args: []string{valueText}, flags: []flag{v("--scope", "user|project")}},
+			{name: "remove", desc: "Remove an installed plugin", args: []string{valueText}, flags: []flag{v("--scope", "user|project")}},
+			{name: "available", desc: "List available plugins from marketplaces"},
+			{name: "marketplace", desc: "Manage plugin marketplaces", subs: []*command{
+				{name: "list", desc: "List configured plugin marketplaces"},
+				{name: "add", desc: "Add a plugin marketplace", args: []string{valueDir}},
+				{name: "remove", desc: "Remove a configured marketplace", args: []string{valueText}},
+			}},
+		}},
+		{name: "secrets", desc: "Manage MCP credentials in the OS credential store", subs: []*command{
+			{name: "list", desc: "List stored secret names"},
+			{name: "set", desc: "Store a secret", args: []string{valueText}},
+			{name: "rm", desc: "Remove a stored secret", args: []string{valueText}},
+		}},
+		{name: "auth", desc: "Rotate the API key and switch between sign-in profiles", subs: []*command{
+			{name: "rotate", desc: "Replace the API key, with validation and backups", flags: []flag{
+				v("--from-env", valueText), b("--no-validate"), b("--oauth"),
+			}},
+			{name: "profiles", desc: "List saved sign-ins", subs: []*command{
+				{name: "list", desc: "List saved sign-ins"},
+				{name: "save", desc: "Save the current sign-in as a profile", args: []string{valueText}},
+				{name: "rm", desc: "Delete a saved profile", args: []string{valueText}},
+			}},
+			{name: "use", desc: "Switch Claude Code to a profile", args: []string{valueText}, flags: []flag{
+				b("--pin"), b("--unpin"), b("--discard"),
+			}},
+		}},
+		{name: "backup", desc: "Back up and restore Claude configuration", subs: []*command{
+			{name: "create", desc: "Archive configuration, memory, and project .claude directories", flags: []flag{
+				v("--output", valueFile), b("--exclude-secrets"), b("--no-memory"),
+			}},
+			{name: "restore", desc: "Restore a backup", args: []string{valueFile}, flags: []flag{b("--dry-run"), b("--no-memory")}},
+			{name: "list", desc: "List saved backups"},
+		}},
+		{name: "scan", desc: "Scan a project for secrets and credentials", args: []string{valueDir}, flags: []flag{b("--stdin-json")}},
+		{name: "config", desc: "View and edit all Claude Code configuration", subs: []*command{
+			{name: "view", desc: "Formatted output of all config"},
+			{name: "get", desc: "Show a single key with all scope layers", args: []string{valueText}},
+			{name: "set", desc: "Set a config value", args: []string{valueText, valueText}, flags: []flag{v("--scope", scopeChoices)}},
+			{name: "unset", desc: "Remove a config value", args: []string{valueText}, flags: []flag{v("--scope", scopeChoices)}},
+			{name: "list", desc: "Show claude-workspace's own preferences"},
+		}},
+		{name: "models", desc: "Manage model routing presets and settings", subs: []*command{
+			{name: "show", desc: "Show the effective model settings"},
+			{name: "presets", desc: "List the model presets"},
+			{name: "use", desc: "Apply a preset", args: []string{"platform|balanced|cost-saver|max-quality"}, flags: []flag{v("--scope", "global|project|local")}},
+			{name: "set", desc: "Set one model setting", args: []string{"main|subagent|effort|autocompact", valueText}, flags: []flag{v("--scope", "global|project|local")}},
+			{name: "reset", desc: "Remove the model settings from a scope", flags: []flag{v("--scope", "global|project|local")}},
+		}},
+		{name: "policy", desc: "Manage permission allow/ask/deny rules", subs: []*command{
+			{name: "show", desc: "List the rules in each settings file", flags: []flag{b("--effective")}},
+			{name: "add-allow", desc: "Add an allow rule", args: []string{valueText}, flags: []flag{v("--scope", "global|project|local")}},
+			{name: "add-ask", desc: "Add an ask rule", args: []string{valueText}, flags: []flag{v("--scope", "global|project|local")}},
+			{name: "add-deny", desc: "Add a deny rule", args: []string{valueText}, flags: []flag{v("--scope", "global|project|local")}},
+			{name: "remove", desc: "Remove a rule", args: []string{valueText}, flags: []flag{v("--scope", "global|project|local")}},
+			{name: "test", desc: "Show whether a tool call is allowed, asked, or denied", args: []string{valueText}},
+			{name: "simulate", desc: "Replay a session's tool calls through the rules", args: []string{valueSession}, flags: []flag{v("--with", valueFile)}},
+			{name: "apply", desc: "Merge an org policy file's rules into a scope", flags: []flag{
+				v("--from", valueFile), v("--scope", "global|project|local"),
+			}},
+			{name: "org", desc: "Manage the signed org policy", subs: []*command{
+				{name: "show", desc: "Show the enforced org policy"},
+				{name: "set", desc: "Verify, enforce, and save the org policy", args: []string{valueText}, flags: []flag{v("--key", valueFile)}},
+				{name: "sync", desc: "Re-fetch and enforce the org policy"},
+				{name: "unset", desc: "Stop enforcing the org policy"},
+			}},
+		}},
+		{name: "notify", desc: "Send notifications when long operations finish", subs: []*command{
+			{name: "list", desc: "List notification channels"},
+			{name: "add", desc: "Add a desktop, Slack, or webhook channel", args: []string{valueText}, flags: []flag{
+				v("--type", "desktop|slack|webhook"), v("--url", valueText), v("--events", valueText), b("--force"),
+			}},
+			{name: "remove", desc: "Remove a channel", args: []string{valueText}},
+			{name: "test", desc: "Send a test notification", args: []string{valueText}},
+			{name: "min-duration", desc: "Only report operations that run at least this long", args: []string{valueText}},
+		}},
+		{name: "completion", desc: "Print or install shell completion", subs: []*command{
+			{name: "bash", desc: "Print the bash completion script"},
+			{name: "zsh", desc: "Print the zsh completion script"},
+			{name: "fish", desc: "Print the fish completion script"},
+			{name: "install", desc: "Install completion into your shell", args: []string{shellChoices}},
+		}},
+	},
+}
+
+var statuslineFlags = []flag{
+	b("--force"), v("--segments", valueText), v("--theme", "default|minimal|mono"),
+	v("--cost-warn", valueText), v("--cost-critical", valueText),
+	v("--context-warn", valueText), v("--context-critical", valueText),
+}
+
+var relayFlags = []flag{
+	v("--url", valueText), v("--sqlite", valueFile), b("--local"), v("--token-env", valueText),
+	v("--timeout", valueText), b("--no-content"),
+}
+
+var relayInstallFlags = []flag{
+	v("--url", valueText), v("--sqlite", valueFile), b("--local"), v("--token-env", valueText),
+	v("--timeout", valueText), b("--no-content"),
+	v("--events", valueText), v("--scope", "global|project|local"),
+}
+
+var eventsFlags = []flag{
+	v("--project", valueDir), v("--since", valueText), v("--tool", valueText), v("--event", hookEvents),
+	v("--session", valueText), v("--limit", valueText), v("--db", valueFile), b("--json"),
+}
+
+var costFlags = []flag{
+	b("--breakdown"), v("--since", valueText), v("--until", valueText), b("--json"), b("--enforce"),
+}
@@ -3,10 +3,12 @@
 package config
 
 import (
-	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 )
 
 // Run executes the config command, writing output to os.Stdout.
@@ -21,6 +23,10 @@ func Run(args []string) error {
 //	view                  — non-interactive formatted output of all config
 //	get <key>             — show a single key with layer breakdown
 //	set <key> <value>     — set a value (--scope user|project|local)
+//	list                  — claude-workspace's own settings (workspace.<name>)
+//
+// get, set, and unset/delete on a "workspace.<name>" key edit
+// claude-workspace's own settings instead of Claude Code's.
 func RunTo(w io.Writer, args []string) error {
 	if len(args) == 0 {
 		// No subcommand: TUI mode is launched by main.go; nothing to do here.
@@ -30,6 +36,13 @@ func RunTo(w io.Writer, args []string) error {
 	reg := GlobalRegistry()
 
 	subcmd := args[0]
+	if len(args) > 1 && strings.HasPrefix(args[1], workspacePrefix) {
+		switch subcmd {
+		case "get", "set", "delete", "unset":
+			return runWorkspace(w, subcmd, args[1:])
+		}
+	}
+
 	switch subcmd {
 	case "view":
 		snap, err := ReadAll()
@@ -55,33 +68,42 @@ func RunTo(w io.Writer, args []string) error {
 	case "delete", "unset":
 		return runDelete(args[1:])
 
+	case "list":
+		return runList(w)
+
 	default:
-		return fmt.Errorf("unknown config subcommand %q (available: view, get, set, delete)", subcmd)
+		return fmt.Errorf("unknown config subcommand %q (available: view, get, set, delete, list)", subcmd)
 	}
 }
 
+// scopeFlag declares --scope on f. After f.Parse, *scope is the chosen
+// layer, ScopeUser by default.
+func scopeFlag(f *cli.FlagSet, help string) *ConfigScope {
+	scope := ScopeUser
+	f.Func("--scope", "user|project|local", help, func(v string) error {
+		switch ConfigScope(v) {
+		case ScopeUser, ScopeProject, ScopeLocal:
+			scope = ConfigScope(v)
+			return nil
+		}
+		return fmt.Errorf("invalid scope %q: must be user, project, or local", v)
+	})
+	return &scope
+}
+
 // runDelete handles "config delete <key> [--scope user|project|local]".
 func runDelete(args []string) error {
-	fs := flag.NewFlagSet("config delete", flag.ContinueOnError)
-	scope := fs.String("scope", "user", "config scope to delete from: user, project, or local")
-	if err := fs.Parse(args); err != nil {
-		return fmt.Errorf("parsing flags: %w", err)
+	f := cli.New("config delete", "config delete <key> [--scope user|project|local]")
+	scope := scopeFlag(f, "Config scope to delete from")
+	remaining, err := f.Parse(args)
+	if err != nil {
+		return err
 	}
-
-	remaining := fs.Args()
-	if len(remaining) < 1 {
-		return fmt.Errorf("usage: config delete <key> [--scope user|project|local]")
+	if err := f.CheckArgs(remaining, 1, 1); err != nil {
+		return err
 	}
 	key := remaining[0]
 
-	configScope := ConfigScope(*scope)
-	switch configScope {
-	case ScopeUser, ScopeProject, ScopeLocal:
-		// valid
-	default:
-		return fmt.Errorf("invalid scope %q: must be user, project, or local", *scope)
-	}
-
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("getting home directory: %w", err)
@@ -91,37 +113,28 @@ func runDelete(args []string) error {
 		return fmt.Errorf("getting working directory: %w", err)
 	}
 
-	if err := DeleteSettingsValue(key, configScope, home, cwd); err != nil {
+	if err := DeleteSettingsValue(key, *scope, home, cwd); err != nil {
 		return fmt.Errorf("deleting config: %w", err)
 	}
 
-	fmt.Fprintf(os.Stdout, "Deleted %s from %s scope\n", key, configScope)
+	fmt.Fprintf(os.Stdout, "Deleted %s from %s scope\n", key, *scope)
 	return nil
 }
 
 // runSet handles "config set <key> <value> [--scope user|project|local]".
 func runSet(args []string) error {
-	fs := flag.NewFlagSet("config set", flag.ContinueOnError)
-	scope := fs.String("scope", "user", "config scope to write to: user, project, or local")
-	if err := fs.Parse(args); err != nil {
-		return fmt.Errorf("parsing flags: %w", err)
+	f := cli.New("config set", "config set <key> <value> [--scope user|project|local]")
+	scope := scopeFlag(f, "Config scope to write to")
+	remaining, err := f.Parse(args)
+	if err != nil {
+		return err
 	}
-
-	remaining := fs.Args()
-	if len(remaining) < 2 {
-		return fmt.Errorf("usage: config set <key> <value> [--scope user|project|local]")
+	if err := f.CheckArgs(remaining, 2, 2); err != nil {
+		return err
 	}
 	key := remaining[0]
 	value := remaining[1]
 
-	configScope := ConfigScope(*scope)
-	switch configScope {
-	case ScopeUser, ScopeProject, ScopeLocal:
-		// valid
-	default:
-		return fmt.Errorf("invalid scope %q: must be user, project, or local", *scope)
-	}
-
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("getting home directory: %w", err)
@@ -131,10 +144,10 @@ func runSet(args []string) error {
 		return fmt.Errorf("getting working directory: %w", err)
 	}
 
-	if err := WriteSettingsValue(key, value, configScope, home, cwd); err != nil {
+	if err := WriteSettingsValue(key, value, *scope, home, cwd); err != nil {
 		return fmt.Errorf("writing config: %w", err)
 	}
 
-	fmt.Fprintf(os.Stdout, "Set %s = %s (scope: %s)\n", key, value, configScope)
+	fmt.Fprintf(os.Stdout, "Set %s = %s (scope: %s)\n", key, value, *scope)
 	return nil
 }
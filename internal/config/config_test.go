@@ -81,6 +81,13 @@ func TestRunSet_InvalidScope(t *testing.T) {
 	}
 }
 
+func TestRunSet_ScopeAfterValue(t *testing.T) {
+	err := runSet([]string{"model", "claude-opus-4-6", "--scope", "managed"})
+	if err == nil || !strings.Contains(err.Error(), "managed") {
+		t.Errorf("a --scope after the value should be parsed, got: %v", err)
+	}
+}
+
 func TestRunSet_MissingArgs(t *testing.T) {
 	err := runSet([]string{"model"}) // only key, no value
 	if err == nil {
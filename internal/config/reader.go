@@ -110,18 +110,25 @@ func managedSettingsPath() string {
 	return "/etc/claude-code/managed-settings.json"
 }
 
+// SettingsFile returns the settings.json path Claude Code reads for scope,
+// including the read-only managed scope. It returns "" for other scopes.
+func SettingsFile(scope ConfigScope, home, cwd string) string {
+	if scope == ScopeManaged {
+		return managedSettingsPath()
+	}
+	path, err := settingsPath(scope, home, cwd)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
 // readAllSettings reads settings.json from every scope (managed, user, project, local).
 // Returns a map of scope → flattened key → json.RawMessage.
 func readAllSettings(home, cwd string) (map[ConfigScope]map[string]json.RawMessage, error) {
-	paths := map[ConfigScope]string{
-		ScopeManaged: managedSettingsPath(),
-		ScopeUser:    filepath.Join(home, ".claude", "settings.json"),
-		ScopeProject: filepath.Join(cwd, ".claude", "settings.json"),
-		ScopeLocal:   filepath.Join(cwd, ".claude", "settings.local.json"),
-	}
-
 	layers := make(map[ConfigScope]map[string]json.RawMessage, 4)
-	for scope, path := range paths {
+	for _, scope := range allScopes {
+		path := SettingsFile(scope, home, cwd)
 		if !platform.FileExists(path) {
 			continue
 		}
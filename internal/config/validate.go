@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SettingsIssue is a problem ValidateSettings found in a settings.json file.
+type SettingsIssue struct {
+	Key     string // dot-path key the issue is about
+	Error   bool   // false for issues Claude Code tolerates, such as unknown keys
+	Message string
+}
+
+// ValidateSettings checks decoded settings.json contents against the settings
+// keys in the registry: each known key must hold a value of its type, enum
+// keys one of their values, and keys managed settings alone may set must not
+// appear. Unknown top-level keys are reported as warnings, since Claude Code
+// ignores them and newer releases may add keys the registry does not know.
+func ValidateSettings(settings map[string]json.RawMessage) []SettingsIssue {
+	keys := buildSettingsKeys()
+	known := map[string]bool{}
+	for _, k := range keys {
+		top, _, _ := strings.Cut(k.Key, ".")
+		known[top] = true
+	}
+
+	var issues []SettingsIssue
+	var unknown []string
+	for key := range settings {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		issues = append(issues, SettingsIssue{Key: key, Message: fmt.Sprintf("unknown setting %q", key)})
+	}
+
+	flat := flattenJSON("", settings)
+	for _, k := range keys {
+		raw, ok := flat[k.Key]
+		if !ok {
+			continue
+		}
+		if k.ReadOnly {
+			issues = append(issues, SettingsIssue{Key: k.Key, Message: fmt.Sprintf("%q only takes effect in managed settings", k.Key)})
+		}
+		if msg := checkType(k, raw); msg != "" {
+			issues = append(issues, SettingsIssue{Key: k.Key, Error: true, Message: fmt.Sprintf("%q %s", k.Key, msg)})
+		}
+	}
+	return issues
+}
+
+// checkType returns why raw is not a valid value for k, or "".
+func checkType(k ConfigKey, raw json.RawMessage) string {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "is not valid JSON"
+	}
+	if v == nil {
+		return ""
+	}
+	switch k.Type {
+	case TypeString:
+		if _, ok := v.(string); !ok {
+			return "must be a string"
+		}
+	case TypeBool:
+		if _, ok := v.(bool); !ok {
+			return "must be true or false"
+		}
+	case TypeInt:
+		if n, ok := v.(float64); !ok || n != float64(int64(n)) {
+			return "must be an integer"
+		}
+	case TypeStringArray:
+		if _, ok := v.([]interface{}); !ok {
+			return "must be an array"
+		}
+	case TypeObject:
+		if _, ok := v.(map[string]interface{}); !ok {
+			return "must be an object"
+		}
+	case TypeEnum:
+		s, ok := v.(string)
+		if !ok || !slices.Contains(k.EnumValues, s) {
+			return fmt.Sprintf("must be one of %s", strings.Join(k.EnumValues, ", "))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateSettings(t *testing.T) {
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(`{
+  "$schema": "https://json.schemastore.org/claude-code-settings.json",
+  "model": 4,
+  "effortLevel": "max",
+  "cleanupPeriodDays": 30,
+  "permissions": {"allow": "Bash(git:*)", "deny": ["Read(.env)"]},
+  "sandbox": {"enabled": "yes", "network": {"allowedDomains": ["example.com"]}},
+  "allowManagedHooksOnly": true,
+  "futureSetting": {}
+}`), &settings); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]SettingsIssue{}
+	for _, issue := range ValidateSettings(settings) {
+		got[issue.Key] = issue
+	}
+	want := map[string]struct {
+		error   bool
+		message string
+	}{
+		"model":                 {true, "must be a string"},
+		"effortLevel":           {true, "must be one of low, medium, high"},
+		"permissions.allow":     {true, "must be an array"},
+		"sandbox.enabled":       {true, "must be true or false"},
+		"allowManagedHooksOnly": {false, "only takes effect in managed settings"},
+		"futureSetting":         {false, `unknown setting "futureSetting"`},
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d issues, want %d: %v", len(got), len(want), got)
+	}
+	for key, w := range want {
+		issue, ok := got[key]
+		if !ok {
+			t.Errorf("no issue for %s", key)
+			continue
+		}
+		if issue.Error != w.error || !strings.Contains(issue.Message, w.message) {
+			t.Errorf("%s: got %+v, want error=%v and %q", key, issue, w.error, w.message)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpregistry"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/templates"
+	"github.com/lamchakchan/claude-workspace/internal/upgrade"
+)
+
+// workspacePrefix marks keys that configure claude-workspace itself rather
+// than Claude Code, e.g. "config set workspace.upgradeChannel beta".
+const workspacePrefix = "workspace."
+
+// workspaceSetting is a claude-workspace preference. Most are stored under
+// key in ~/.claude-workspace/config.json; the MCP registry keeps its own file
+// and supplies get, set, and unset.
+type workspaceSetting struct {
+	name   string
+	desc   string
+	values []string // allowed values, if limited
+
+	key      string                       // config.json key for stored settings
+	validate func(string) (string, error) // checks and normalizes a new value
+
+	get   func() string
+	set   func(string) error
+	unset func() error
+}
+
+// workspaceSettings lists the preferences "config list" shows, in order.
+var workspaceSettings = []*workspaceSetting{
+	{
+		name: "templateSource", key: platform.ConfigTemplateSource,
+		desc:     "Template attach uses when --template is not given (git repo[@ref] or https tarball)",
+		validate: validateTemplate,
+	},
+	{
+		name: "attachFlags", key: platform.ConfigAttachFlags,
+		desc:     "Flags added to every attach, e.g. \"--symlink --no-enrich\"; flags on the command line win",
+		validate: validateAttachFlags,
+	},
+	{
+		name: "upgradeChannel", key: "upgradeChannel",
+		desc:   "Release channel upgrade follows (same as upgrade --channel)",
+		values: upgrade.Channels,
+	},
+	{
+		name: "mcpRegistry",
+		desc: "Organization registry of approved MCP servers (same as mcp registry set)",
+		get:  registryURL, set: setRegistry, unset: mcpregistry.ClearOrgRegistry,
+	},
+	{
+		name: "color", key: platform.ConfigColor,
+		desc:   "Colored output: auto (on a terminal), always, or never; NO_COLOR and --no-color win",
+		values: []string{"auto", "always", "never"},
+	},
+	{
+		name: "proxy", key: platform.ConfigProxy,
+		desc:     "HTTP(S) proxy URL, used when HTTPS_PROXY and HTTP_PROXY are unset",
+		validate: validateProxy,
+	},
+	{
+		name: "noProxy", key: platform.ConfigNoProxy,
+		desc: "Hosts that bypass the proxy, used when NO_PROXY is unset",
+	},
+	{
+		name: "caCert", key: platform.ConfigCACert,
+		desc:     "PEM file of extra root CAs (same as --ca-cert)",
+		validate: validateCACert,
+	},
+	{
+		name: "memoryTokenBudget", key: platform.ConfigMemoryTokenBudget,
+		desc:     "Tokens of always-loaded CLAUDE.md and memory \"memory tokens\" allows before warning (default 10000)",
+		validate: validatePositiveInt,
+	},
+}
+
+// lookupWorkspaceSetting finds the setting for a "workspace.<name>" key.
+func lookupWorkspaceSetting(key string) (*workspaceSetting, error) {
+	name := strings.TrimPrefix(key, workspacePrefix)
+	for _, s := range workspaceSettings {
+		if s.name == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown setting %q; use 'config list' to see all settings", key)
+}
+
+func (s *workspaceSetting) value() string {
+	if s.get != nil {
+		return s.get()
+	}
+	return platform.ConfigString(s.key)
+}
+
+func (s *workspaceSetting) store(value string) error {
+	if len(s.values) > 0 && !slices.Contains(s.values, value) {
+		return fmt.Errorf("invalid value %q for %s%s (valid: %s)", value, workspacePrefix, s.name, strings.Join(s.values, ", "))
+	}
+	if s.validate != nil {
+		v, err := s.validate(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s%s: %w", workspacePrefix, s.name, err)
+		}
+		value = v
+	}
+	if s.set != nil {
+		return s.set(value)
+	}
+	return platform.WriteConfig(s.key, value)
+}
+
+func (s *workspaceSetting) remove() error {
+	if s.unset != nil {
+		return s.unset()
+	}
+	return platform.DeleteConfig(s.key)
+}
+
+// runList prints every workspace setting with its current value.
+func runList(w io.Writer) error {
+	path, err := platform.ConfigPath()
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "claude-workspace Settings")
+	fmt.Fprintf(w, "  Stored in %s\n", path)
+	for _, s := range workspaceSettings {
+		v := s.value()
+		if v == "" {
+			v = valNone
+		}
+		fmt.Fprintf(w, "\n  %-30s %s\n", workspacePrefix+s.name, v)
+		fmt.Fprintf(w, "    %s\n", s.desc)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  Change one with: claude-workspace config set workspace.<name> <value>")
+	return nil
+}
+
+// runWorkspace handles get, set, and unset for a "workspace.<name>" key.
+func runWorkspace(w io.Writer, subcmd string, args []string) error {
+	s, err := lookupWorkspaceSetting(args[0])
+	if err != nil {
+		return err
+	}
+	key := workspacePrefix + s.name
+	switch subcmd {
+	case "get":
+		if v := s.value(); v != "" {
+			fmt.Fprintln(w, v)
+		}
+		return nil
+	case "set":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: config set %s <value>", key)
+		}
+		if err := s.store(args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Set %s = %s\n", key, s.value())
+		return nil
+	default:
+		if err := s.remove(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Unset %s\n", key)
+		return nil
+	}
+}
+
+func validateTemplate(v string) (string, error) {
+	if _, err := templates.ParseSource(v); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+func validateAttachFlags(v string) (string, error) {
+	fields := strings.Fields(v)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "-") {
+		return "", fmt.Errorf("expected attach flags such as \"--symlink --no-enrich\"")
+	}
+	return strings.Join(fields, " "), nil
+}
+
+func validateProxy(v string) (string, error) {
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("expected a proxy URL such as http://proxy.example.com:8080")
+	}
+	return v, nil
+}
+
+func validatePositiveInt(v string) (string, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("expected a positive whole number")
+	}
+	return strconv.Itoa(n), nil
+}
+
+func validateCACert(v string) (string, error) {
+	abs, err := filepath.Abs(v)
+	if err != nil {
+		return "", fmt.Errorf("resolving path: %w", err)
+	}
+	if !platform.FileExists(abs) {
+		return "", fmt.Errorf("file not found: %s", abs)
+	}
+	return abs, nil
+}
+
+func registryURL() string {
+	reg, err := mcpregistry.LoadOrgRegistry()
+	if err != nil || reg == nil {
+		return ""
+	}
+	return reg.URL
+}
+
+// setRegistry validates the catalog at location and saves it, as
+// "mcp registry set" does.
+func setRegistry(location string) error {
+	if !strings.Contains(location, "://") {
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return fmt.Errorf("resolving path: %w", err)
+		}
+		location = abs
+	}
+	_, data, err := mcpregistry.FetchCatalog(location)
+	if err != nil {
+		return err
+	}
+	return mcpregistry.SaveOrgRegistry(location, data)
+}
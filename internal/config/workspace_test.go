@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestWorkspaceSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	var buf strings.Builder
+	if err := RunTo(&buf, []string{"set", "workspace.upgradeChannel", "beta"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunTo(&buf, []string{"set", "workspace.attachFlags", "  --symlink   --no-enrich "}); err != nil {
+		t.Fatal(err)
+	}
+	if got := platform.ConfigString(platform.ConfigAttachFlags); got != "--symlink --no-enrich" {
+		t.Errorf("attachFlags = %q, want normalized flags", got)
+	}
+
+	buf.Reset()
+	if err := RunTo(&buf, []string{"get", "workspace.upgradeChannel"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "beta\n" {
+		t.Errorf("get = %q, want the bare value", buf.String())
+	}
+
+	buf.Reset()
+	if err := RunTo(&buf, []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"workspace.upgradeChannel", "beta", "workspace.proxy", valNone, filepath.Join(home, ".claude-workspace", "config.json")} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("list output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if err := RunTo(&buf, []string{"unset", "workspace.upgradeChannel"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := platform.ConfigString("upgradeChannel"); got != "" {
+		t.Errorf("upgradeChannel = %q after unset", got)
+	}
+}
+
+func TestWorkspaceSettings_Invalid(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("ca.pem", []byte("pem"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"set", "workspace.upgradeChannel", "edge"},
+		{"set", "workspace.color", "sometimes"},
+		{"set", "workspace.proxy", "proxy.example.com"},
+		{"set", "workspace.attachFlags", "symlink"},
+		{"set", "workspace.caCert", "missing.pem"},
+		{"set", "workspace.nope", "x"},
+		{"get", "workspace.nope"},
+	} {
+		if err := RunTo(&strings.Builder{}, args); err == nil {
+			t.Errorf("RunTo(%q) should fail", args)
+		}
+	}
+
+	if err := RunTo(&strings.Builder{}, []string{"set", "workspace.caCert", "ca.pem"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := platform.ConfigString(platform.ConfigCACert); !filepath.IsAbs(got) {
+		t.Errorf("caCert = %q, want an absolute path", got)
+	}
+}
@@ -3,7 +3,6 @@ package config
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -14,29 +13,21 @@ import (
 // WriteSettingsValue writes a single key=value to the appropriate settings.json file
 // for the given scope (user, project, or local). Managed, env, and default scopes return an error.
 // It reads the existing file, sets the value (creating nested objects for dot-paths),
-// and writes back using platform.WriteJSONFile.
+// and writes back with updateSettings.
 func WriteSettingsValue(key, value string, scope ConfigScope, home, cwd string) error {
 	path, err := settingsPath(scope, home, cwd)
 	if err != nil {
 		return err
 	}
 
-	root, err := readOrCreateJSON(path)
-	if err != nil {
-		return err
-	}
-
 	parsed, err := parseWriteValue(key, value)
 	if err != nil {
 		return fmt.Errorf("parsing value for %q: %w", key, err)
 	}
 
-	setNestedValue(root, key, parsed)
-
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
-	}
-	return platform.WriteJSONFile(path, root)
+	return updateSettings(path, func(root map[string]interface{}) {
+		setNestedValue(root, key, parsed)
+	})
 }
 
 // AppendToArray appends a string value to a settings.json array key.
@@ -47,19 +38,9 @@ func AppendToArray(key, value string, scope ConfigScope, home, cwd string) error
 		return err
 	}
 
-	root, err := readOrCreateJSON(path)
-	if err != nil {
-		return err
-	}
-
-	arr := getNestedArray(root, key)
-	arr = append(arr, value)
-	setNestedValue(root, key, arr)
-
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
-	}
-	return platform.WriteJSONFile(path, root)
+	return updateSettings(path, func(root map[string]interface{}) {
+		setNestedValue(root, key, append(getNestedArray(root, key), value))
+	})
 }
 
 // RemoveFromArray removes a matching string value from a settings.json array key.
@@ -69,25 +50,17 @@ func RemoveFromArray(key, value string, scope ConfigScope, home, cwd string) err
 		return err
 	}
 
-	root, err := readOrCreateJSON(path)
-	if err != nil {
-		return err
-	}
-
-	arr := getNestedArray(root, key)
-	filtered := make([]interface{}, 0, len(arr))
-	for _, item := range arr {
-		if s, ok := item.(string); ok && s == value {
-			continue
+	return updateSettings(path, func(root map[string]interface{}) {
+		arr := getNestedArray(root, key)
+		filtered := make([]interface{}, 0, len(arr))
+		for _, item := range arr {
+			if s, ok := item.(string); ok && s == value {
+				continue
+			}
+			filtered = append(filtered, item)
 		}
-		filtered = append(filtered, item)
-	}
-	setNestedValue(root, key, filtered)
-
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
-	}
-	return platform.WriteJSONFile(path, root)
+		setNestedValue(root, key, filtered)
+	})
 }
 
 // DeleteSettingsValue removes a key from the specified scope's settings.json file.
@@ -103,17 +76,23 @@ func DeleteSettingsValue(key string, scope ConfigScope, home, cwd string) error
 		return nil
 	}
 
-	root, err := readOrCreateJSON(path)
-	if err != nil {
-		return err
-	}
-
-	deleteNestedValue(root, key)
+	return updateSettings(path, func(root map[string]interface{}) {
+		deleteNestedValue(root, key)
+	})
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
-	}
-	return platform.WriteJSONFile(path, root)
+// updateSettings applies edit to the settings.json file at path, creating it
+// and its directory if needed. The read and write happen under the file's
+// lock, so concurrent commands do not drop each other's changes.
+func updateSettings(path string, edit func(root map[string]interface{})) error {
+	return platform.WithFileLock(path, func() error {
+		root, err := readOrCreateJSON(path)
+		if err != nil {
+			return err
+		}
+		edit(root)
+		return platform.WriteSettingsFile(path, root)
+	})
 }
 
 // deleteNestedValue removes the key at the dot-separated path from root.
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -129,6 +130,26 @@ func TestWriteSettingsValue_LocalScope(t *testing.T) {
 	}
 }
 
+func TestAppendToArray_RefusesInvalidSettings(t *testing.T) {
+	tmp := t.TempDir()
+	home := filepath.Join(tmp, "home")
+	path := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"model": "opus"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := AppendToArray("model", "sonnet", ScopeUser, home, tmp)
+	if err == nil || !strings.Contains(err.Error(), "model: must be string") {
+		t.Fatalf("err = %v, want a schema violation at model", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"model": "opus"}` {
+		t.Errorf("settings.json was rewritten: %s", data)
+	}
+}
+
 func TestParseWriteValue_Bool(t *testing.T) {
 	tests := []struct {
 		value string
@@ -495,7 +516,7 @@ func TestDeleteSettingsValue_NestedKey(t *testing.T) {
 	if err := WriteSettingsValue("sandbox.enabled", "true", ScopeProject, home, cwd); err != nil {
 		t.Fatalf("WriteSettingsValue: %v", err)
 	}
-	if err := WriteSettingsValue("sandbox.timeout", "30000", ScopeProject, home, cwd); err != nil {
+	if err := WriteSettingsValue("sandbox.autoAllowBashIfSandboxed", "true", ScopeProject, home, cwd); err != nil {
 		t.Fatalf("WriteSettingsValue: %v", err)
 	}
 	if err := DeleteSettingsValue("sandbox.enabled", ScopeProject, home, cwd); err != nil {
@@ -514,8 +535,8 @@ func TestDeleteSettingsValue_NestedKey(t *testing.T) {
 	if _, exists := sb["enabled"]; exists {
 		t.Error("expected sandbox.enabled to be deleted")
 	}
-	if sb["timeout"] == nil {
-		t.Error("expected sandbox.timeout to be preserved")
+	if sb["autoAllowBashIfSandboxed"] == nil {
+		t.Error("expected sandbox.autoAllowBashIfSandboxed to be preserved")
 	}
 }
 
@@ -0,0 +1,443 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/notify"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// warnRatio is the fraction of a budget at which a warning is shown.
+const warnRatio = 0.8
+
+// ErrBudgetExceeded is returned by "cost --enforce" when spending is over a
+// configured budget (exit 1), so wrapper scripts can refuse to start a session.
+var ErrBudgetExceeded = errors.New("cost budget exceeded")
+
+// Budget holds spending limits in USD. A zero limit is not enforced.
+type Budget struct {
+	Monthly    float64 `json:"monthly,omitempty"`
+	PerSession float64 `json:"perSession,omitempty"`
+}
+
+// IsZero reports whether no limits are configured.
+func (b Budget) IsZero() bool {
+	return b.Monthly <= 0 && b.PerSession <= 0
+}
+
+// BudgetAlert describes a budget whose warning threshold has been crossed.
+type BudgetAlert struct {
+	Name     string // "Monthly" or "Session"
+	Spent    float64
+	Limit    float64
+	Exceeded bool
+}
+
+func (a BudgetAlert) String() string {
+	pct := 100 * a.Spent / a.Limit
+	if a.Exceeded {
+		return fmt.Sprintf("%s budget exceeded: $%.2f of $%.2f (%.0f%%)", a.Name, a.Spent, a.Limit, pct)
+	}
+	return fmt.Sprintf("%s budget: $%.2f of $%.2f spent (%.0f%%)", a.Name, a.Spent, a.Limit, pct)
+}
+
+// CheckBudget compares monthly and session spending against b and returns an
+// alert for each limit that is at least warnRatio spent.
+func CheckBudget(b Budget, monthly, session float64) []BudgetAlert {
+	var alerts []BudgetAlert
+	check := func(name string, spent, limit float64) {
+		if limit > 0 && spent >= limit*warnRatio {
+			alerts = append(alerts, BudgetAlert{Name: name, Spent: spent, Limit: limit, Exceeded: spent >= limit})
+		}
+	}
+	check("Monthly", monthly, b.Monthly)
+	check("Session", session, b.PerSession)
+	return alerts
+}
+
+// budgetKey is the key holding the budgets in ~/.claude-workspace/config.json.
+const budgetKey = "budgets"
+
+// budgetFile is where budgets are stored: path is config.json, and legacy is
+// ~/.claude/workspace.json, which held them before. The legacy file is still
+// read while config.json has no budgets, and loses its budgets on the next
+// save.
+type budgetFile struct {
+	path, legacy string
+}
+
+func defaultBudgetFile() (budgetFile, error) {
+	path, err := platform.ConfigPath()
+	if err != nil {
+		return budgetFile{}, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return budgetFile{}, fmt.Errorf("getting home directory: %w", err)
+	}
+	return budgetFile{path: path, legacy: filepath.Join(home, ".claude", "workspace.json")}, nil
+}
+
+// LoadBudget reads the configured budget. A missing config file is not an error.
+func LoadBudget() (Budget, error) {
+	f, err := defaultBudgetFile()
+	if err != nil {
+		return Budget{}, err
+	}
+	return f.load()
+}
+
+func (f budgetFile) load() (Budget, error) {
+	b, found, err := readBudget(f.path)
+	if err != nil || found || f.legacy == "" {
+		return b, err
+	}
+	b, _, err = readBudget(f.legacy)
+	return b, err
+}
+
+// readBudget reads the budgets key of the JSON file at path, reporting
+// whether it is there.
+func readBudget(path string) (Budget, bool, error) {
+	var b Budget
+	if !platform.FileExists(path) {
+		return b, false, nil
+	}
+	cfg, err := platform.ReadJSONFileRaw(path)
+	if err != nil {
+		return b, false, err
+	}
+	raw, ok := cfg[budgetKey]
+	if !ok {
+		return b, false, nil
+	}
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return b, false, fmt.Errorf("parsing budgets in %s: %w", path, err)
+	}
+	return b, true, nil
+}
+
+// save writes b to config.json, preserving other keys, and removes the
+// budgets from the legacy file. A zero budget removes the budgets key.
+func (f budgetFile) save(b Budget) error {
+	cfg := map[string]json.RawMessage{}
+	if platform.FileExists(f.path) {
+		existing, err := platform.ReadJSONFileRaw(f.path)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			cfg = existing
+		}
+	}
+	if b.IsZero() {
+		delete(cfg, budgetKey)
+	} else {
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("marshaling budgets: %w", err)
+		}
+		cfg[budgetKey] = raw
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(f.path), err)
+	}
+	if err := platform.WriteJSONFile(f.path, cfg); err != nil {
+		return err
+	}
+	return f.dropLegacy()
+}
+
+// dropLegacy removes the budgets from the legacy file, and the file once
+// nothing else is left in it.
+func (f budgetFile) dropLegacy() error {
+	if f.legacy == "" || !platform.FileExists(f.legacy) {
+		return nil
+	}
+	cfg, err := platform.ReadJSONFileRaw(f.legacy)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg[budgetKey]; !ok {
+		return nil
+	}
+	delete(cfg, budgetKey)
+	if len(cfg) == 0 {
+		return os.Remove(f.legacy)
+	}
+	return platform.WriteJSONFile(f.legacy, cfg)
+}
+
+// runBudget implements "cost budget [show|set|clear]".
+func runBudget(w io.Writer, args []string) error {
+	file, err := defaultBudgetFile()
+	if err != nil {
+		return err
+	}
+	sub := "show"
+	if len(args) > 0 {
+		sub, args = args[0], args[1:]
+	}
+	if sub == "show" || sub == "clear" {
+		f := cli.New("cost budget "+sub, "cost budget "+sub)
+		positional, err := f.Parse(args)
+		if err != nil {
+			return err
+		}
+		if err := f.CheckArgs(positional, 0, 0); err != nil {
+			return err
+		}
+	}
+	switch sub {
+	case "show":
+		return showBudget(w, file)
+	case "set":
+		return setBudget(w, file, args)
+	case "clear":
+		if err := file.save(Budget{}); err != nil {
+			return err
+		}
+		platform.PrintOK(w, "Budgets cleared")
+		return nil
+	default:
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace cost budget [show|set|clear]")
+		return fmt.Errorf("unknown budget subcommand: %s", sub)
+	}
+}
+
+// setBudget updates the limits given by --monthly and --per-session, leaving
+// the other limit unchanged. A value of 0 removes that limit.
+func setBudget(w io.Writer, file budgetFile, args []string) error {
+	b, err := file.load()
+	if err != nil {
+		return err
+	}
+	amount := func(name string, p *float64) func(string) error {
+		return func(v string) error {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s must be a non-negative amount in USD", name)
+			}
+			*p = n
+			return nil
+		}
+	}
+	f := cli.New("cost budget set", "cost budget set [--monthly USD] [--per-session USD]")
+	f.Func("--monthly", "USD", "Monthly limit; 0 removes it", amount("--monthly", &b.Monthly))
+	f.Func("--per-session", "USD", "Per-session limit; 0 removes it", amount("--per-session", &b.PerSession))
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	if !f.Changed("--monthly") && !f.Changed("--per-session") {
+		return fmt.Errorf("--monthly or --per-session is required (%s)", f.Usage())
+	}
+	if err := file.save(b); err != nil {
+		return err
+	}
+	platform.PrintOK(w, "Budgets saved to "+file.path)
+	printBudget(w, b)
+	return nil
+}
+
+func showBudget(w io.Writer, file budgetFile) error {
+	b, err := file.load()
+	if err != nil {
+		return err
+	}
+	if b.IsZero() {
+		fmt.Fprintln(w, "  No budgets configured.")
+		fmt.Fprintln(w, "  Set one with: claude-workspace cost budget set --monthly 200 --per-session 5")
+		return nil
+	}
+	printBudget(w, b)
+
+	monthly, session, err := currentSpend(context.Background())
+	if err != nil {
+		fmt.Fprintf(w, "\n  Current spending unavailable: %v\n", err)
+		return nil
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  This month:      $%.2f\n", monthly)
+	fmt.Fprintf(w, "  Latest session:  $%.2f\n", session)
+	printBudgetAlerts(w, CheckBudget(b, monthly, session))
+	return nil
+}
+
+func printBudget(w io.Writer, b Budget) {
+	limit := func(v float64) string {
+		if v <= 0 {
+			return "none"
+		}
+		return fmt.Sprintf("$%.2f", v)
+	}
+	fmt.Fprintf(w, "  Monthly budget:  %s\n", limit(b.Monthly))
+	fmt.Fprintf(w, "  Per session:     %s\n", limit(b.PerSession))
+}
+
+func printBudgetAlerts(w io.Writer, alerts []BudgetAlert) {
+	for _, a := range alerts {
+		if a.Exceeded {
+			platform.PrintErrorLine(w, a.String())
+		} else {
+			platform.PrintWarningLine(w, a.String())
+		}
+	}
+}
+
+// enforceBudget checks current spending against the configured budget and
+// prints any alerts to w. With enforce, it returns ErrBudgetExceeded when a
+// limit has been reached.
+func enforceBudget(w io.Writer, enforce bool) error {
+	b, err := LoadBudget()
+	if err != nil {
+		return err
+	}
+	if b.IsZero() {
+		if enforce {
+			fmt.Fprintln(w, "  No budgets configured; nothing to enforce.")
+		}
+		return nil
+	}
+	monthly, session, err := currentSpend(context.Background())
+	if err != nil {
+		if enforce {
+			return fmt.Errorf("checking budgets: %w", err)
+		}
+		platform.PrintWarningLine(w, fmt.Sprintf("Could not check budgets: %v", err))
+		return nil
+	}
+	alerts := CheckBudget(b, monthly, session)
+	if len(alerts) > 0 {
+		fmt.Fprintln(w)
+	}
+	printBudgetAlerts(w, alerts)
+	notifyExceeded(alerts, time.Now())
+	if enforce {
+		for _, a := range alerts {
+			if a.Exceeded {
+				return ErrBudgetExceeded
+			}
+		}
+	}
+	return nil
+}
+
+// notifyExceeded sends a cost-budget notification for each exceeded budget,
+// once per month for the monthly budget and once per day for the session one.
+func notifyExceeded(alerts []BudgetAlert, now time.Time) {
+	for _, a := range alerts {
+		if !a.Exceeded {
+			continue
+		}
+		period := now.Format("2006-01")
+		if a.Name != "Monthly" {
+			period = now.Format("2006-01-02")
+		}
+		notify.Alert("cost-budget:"+a.Name+":"+period, notify.Event{
+			Event:   notify.EventCostBudget,
+			Title:   a.Name + " budget exceeded",
+			Message: a.String() + ".",
+		})
+	}
+}
+
+// currentSpend returns this month's total cost and the cost of the most
+// recently active session, as reported by ccusage.
+func currentSpend(ctx context.Context) (monthly, session float64, err error) {
+	now := time.Now()
+	since := now.Format("200601") + "01"
+
+	out, err := RunCaptureContext(ctx, []string{"monthly", "--json", "--since", since})
+	if err != nil {
+		return 0, 0, err
+	}
+	if monthly, err = monthSpend(out, now.Format("2006-01")); err != nil {
+		return 0, 0, err
+	}
+
+	out, err = RunCaptureContext(ctx, []string{"session", "--json", "--since", since})
+	if err != nil {
+		return 0, 0, err
+	}
+	if session, err = latestSessionSpend(out); err != nil {
+		return 0, 0, err
+	}
+	return monthly, session, nil
+}
+
+// TodaySpend returns today's total cost across all projects, as reported by
+// ccusage.
+func TodaySpend(ctx context.Context) (float64, error) {
+	now := time.Now()
+	out, err := RunCaptureContext(ctx, []string{"daily", "--json", "--since", now.Format("20060102")})
+	if err != nil {
+		return 0, err
+	}
+	return daySpend(out, now.Format("2006-01-02"))
+}
+
+// daySpend returns the total cost for date ("YYYY-MM-DD") from ccusage
+// "daily --json" output, or 0 if the day has no usage.
+func daySpend(data, date string) (float64, error) {
+	records, err := parseRecords("daily", data)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		if r.Date == date {
+			return r.TotalCost, nil
+		}
+	}
+	return 0, nil
+}
+
+// monthSpend returns the total cost for month ("YYYY-MM") from ccusage
+// "monthly --json" output, or 0 if the month has no usage.
+func monthSpend(data, month string) (float64, error) {
+	records, err := parseRecords("monthly", data)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		if r.Month == month {
+			return r.TotalCost, nil
+		}
+	}
+	return 0, nil
+}
+
+// latestSessionSpend returns the cost of the most recently active session in
+// ccusage "session --json" output, or 0 if there are none.
+func latestSessionSpend(data string) (float64, error) {
+	// Older ccusage releases used "session" as the envelope key.
+	records, err := parseRecords("sessions", data)
+	if err != nil {
+		var fallbackErr error
+		if records, fallbackErr = parseRecords("session", data); fallbackErr != nil {
+			return 0, err
+		}
+	}
+	var latest *costRecord
+	for i := range records {
+		if latest == nil || records[i].LastActivity > latest.LastActivity {
+			latest = &records[i]
+		}
+	}
+	if latest == nil {
+		return 0, nil
+	}
+	return latest.TotalCost, nil
+}
@@ -0,0 +1,203 @@
+package cost
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestCheckBudget(t *testing.T) {
+	b := Budget{Monthly: 200, PerSession: 5}
+
+	if alerts := CheckBudget(b, 100, 1); len(alerts) != 0 {
+		t.Errorf("under threshold: got %d alerts, want 0", len(alerts))
+	}
+
+	alerts := CheckBudget(b, 170, 5.5)
+	if len(alerts) != 2 {
+		t.Fatalf("got %d alerts, want 2", len(alerts))
+	}
+	if alerts[0].Name != "Monthly" || alerts[0].Exceeded {
+		t.Errorf("monthly alert = %+v, want warning only", alerts[0])
+	}
+	if alerts[1].Name != "Session" || !alerts[1].Exceeded {
+		t.Errorf("session alert = %+v, want exceeded", alerts[1])
+	}
+	if !strings.Contains(alerts[1].String(), "exceeded") {
+		t.Errorf("String() = %q, want it to mention exceeded", alerts[1].String())
+	}
+
+	if alerts := CheckBudget(Budget{}, 1000, 1000); len(alerts) != 0 {
+		t.Errorf("no limits: got %d alerts, want 0", len(alerts))
+	}
+}
+
+func TestSaveAndLoadBudget_PreservesOtherKeys(t *testing.T) {
+	file := budgetFile{path: filepath.Join(t.TempDir(), "config.json")}
+	if err := os.WriteFile(file.path, []byte(`{"other":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.save(Budget{Monthly: 200}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	b, err := file.load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if b.Monthly != 200 || b.PerSession != 0 {
+		t.Errorf("loaded %+v, want Monthly=200", b)
+	}
+
+	if err := file.save(Budget{}); err != nil {
+		t.Fatalf("save(zero): %v", err)
+	}
+	data, _ := os.ReadFile(file.path)
+	if strings.Contains(string(data), "budgets") {
+		t.Errorf("zero budget should remove the budgets key:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"other"`) {
+		t.Errorf("other keys should be preserved:\n%s", data)
+	}
+}
+
+func TestLoadBudget_MissingFile(t *testing.T) {
+	dir := t.TempDir()
+	b, err := budgetFile{path: filepath.Join(dir, "missing.json"), legacy: filepath.Join(dir, "legacy.json")}.load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !b.IsZero() {
+		t.Errorf("got %+v, want zero budget", b)
+	}
+}
+
+func TestLoadBudget_Legacy(t *testing.T) {
+	dir := t.TempDir()
+	file := budgetFile{path: filepath.Join(dir, "config.json"), legacy: filepath.Join(dir, "workspace.json")}
+	if err := os.WriteFile(file.legacy, []byte(`{"budgets":{"monthly":150,"perSession":4}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Until config.json has budgets, the old file is read.
+	if b, err := file.load(); err != nil || b.Monthly != 150 || b.PerSession != 4 {
+		t.Fatalf("load() = %+v, %v; want the legacy budgets", b, err)
+	}
+
+	// The next save moves them, and the emptied old file is removed.
+	var buf bytes.Buffer
+	if err := setBudget(&buf, file, []string{"--per-session", "6"}); err != nil {
+		t.Fatal(err)
+	}
+	if platform.FileExists(file.legacy) {
+		t.Error("the legacy file should be removed once its budgets have moved")
+	}
+	if b, found, err := readBudget(file.path); err != nil || !found || b.Monthly != 150 || b.PerSession != 6 {
+		t.Errorf("config.json budgets = %+v, %v, %v; want Monthly=150 PerSession=6", b, found, err)
+	}
+
+	// A cleared budget stays cleared rather than falling back again.
+	if err := os.WriteFile(file.legacy, []byte(`{"budgets":{"monthly":1},"other":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.save(Budget{}); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := file.load(); err != nil || !b.IsZero() {
+		t.Errorf("load() after clear = %+v, %v; want zero", b, err)
+	}
+	if data, _ := os.ReadFile(file.legacy); strings.Contains(string(data), "budgets") || !strings.Contains(string(data), "other") {
+		t.Errorf("legacy file after clear = %s; want only its other keys", data)
+	}
+}
+
+func TestSetBudget(t *testing.T) {
+	file := budgetFile{path: filepath.Join(t.TempDir(), "config.json")}
+	var buf bytes.Buffer
+
+	if err := setBudget(&buf, file, []string{"--monthly", "200", "--per-session", "5"}); err != nil {
+		t.Fatalf("setBudget: %v", err)
+	}
+	if err := setBudget(&buf, file, []string{"--per-session", "7.5"}); err != nil {
+		t.Fatalf("setBudget: %v", err)
+	}
+	b, _ := file.load()
+	if b.Monthly != 200 || b.PerSession != 7.5 {
+		t.Errorf("got %+v, want Monthly=200 PerSession=7.5", b)
+	}
+
+	for _, args := range [][]string{nil, {"--monthly"}, {"--monthly", "-1"}, {"--monthly", "abc"}, {"--daily", "3"}} {
+		if err := setBudget(&buf, file, args); err == nil {
+			t.Errorf("setBudget(%v): expected error", args)
+		}
+	}
+}
+
+func TestMonthSpend(t *testing.T) {
+	input := `{"monthly":[{"month":"2026-09","totalCost":12.5},{"month":"2026-10","totalCost":42.25}]}`
+	got, err := monthSpend(input, "2026-10")
+	if err != nil {
+		t.Fatalf("monthSpend: %v", err)
+	}
+	if math.Abs(got-42.25) > 0.001 {
+		t.Errorf("got %f, want 42.25", got)
+	}
+	if got, _ := monthSpend(input, "2026-11"); got != 0 {
+		t.Errorf("missing month: got %f, want 0", got)
+	}
+}
+
+func TestDaySpend(t *testing.T) {
+	input := `{"daily":[{"date":"2026-10-15","totalCost":3.5},{"date":"2026-10-16","totalCost":7.25}]}`
+	if got, err := daySpend(input, "2026-10-16"); err != nil || got != 7.25 {
+		t.Errorf("daySpend() = %f, %v; want 7.25", got, err)
+	}
+	if got, _ := daySpend(input, "2026-10-17"); got != 0 {
+		t.Errorf("missing day: got %f, want 0", got)
+	}
+	if _, err := daySpend(`{"monthly":[]}`, "2026-10-16"); err == nil {
+		t.Error("expected error for missing daily key")
+	}
+}
+
+func TestLatestSessionSpend(t *testing.T) {
+	input := `{"sessions":[
+		{"sessionId":"a","totalCost":3.0,"lastActivity":"2026-10-01"},
+		{"sessionId":"b","totalCost":1.5,"lastActivity":"2026-10-14"},
+		{"sessionId":"c","totalCost":9.0,"lastActivity":"2026-10-07"}
+	]}`
+	got, err := latestSessionSpend(input)
+	if err != nil {
+		t.Fatalf("latestSessionSpend: %v", err)
+	}
+	if got != 1.5 {
+		t.Errorf("got %f, want 1.5", got)
+	}
+
+	legacy := `{"session":[{"sessionId":"a","totalCost":2.0,"lastActivity":"2026-10-01"}]}`
+	if got, err := latestSessionSpend(legacy); err != nil || got != 2.0 {
+		t.Errorf("legacy key: got %f, %v; want 2.0", got, err)
+	}
+
+	if _, err := latestSessionSpend(`{"other":[]}`); err == nil {
+		t.Error("expected error for missing sessions key")
+	}
+}
+
+func TestStripFlag(t *testing.T) {
+	args, found := stripFlag([]string{"monthly", "--enforce", "--json"}, "--enforce")
+	if !found {
+		t.Error("expected --enforce to be found")
+	}
+	if strings.Join(args, " ") != "monthly --json" {
+		t.Errorf("args = %v, want [monthly --json]", args)
+	}
+	if _, found := stripFlag([]string{"daily"}, "--enforce"); found {
+		t.Error("unexpected --enforce")
+	}
+}
@@ -27,6 +27,8 @@ type costRecord struct {
 	Name      string  `json:"name"`
 	ID        string  `json:"id"`
 	TotalCost float64 `json:"totalCost"`
+
+	LastActivity string `json:"lastActivity"` // session entries only
 }
 
 // label returns the best available label from the record's fields.
@@ -52,31 +54,57 @@ func (r *costRecord) label() string {
 // ParseCostJSON parses the JSON output from any ccusage subcommand.
 // The subcommand name (e.g., "daily", "weekly") is used as the JSON envelope key.
 func ParseCostJSON(subcommand string, data string) ([]ChartEntry, error) {
+	records, err := parseRecords(subcommand, data)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ChartEntry, 0, len(records))
+	for _, r := range records {
+		result = append(result, ChartEntry{Label: r.label(), Value: r.TotalCost})
+	}
+	return result, nil
+}
+
+// parseRecords decodes the entries stored under key in ccusage JSON output.
+func parseRecords(key string, data string) ([]costRecord, error) {
 	var raw map[string]json.RawMessage
 	if err := json.Unmarshal([]byte(data), &raw); err != nil {
-		return nil, fmt.Errorf("parsing %s JSON: %w", subcommand, err)
+		return nil, fmt.Errorf("parsing %s JSON: %w", key, err)
 	}
 
-	arrayData, ok := raw[subcommand]
+	arrayData, ok := raw[key]
 	if !ok {
-		return nil, fmt.Errorf("missing %q key in JSON", subcommand)
+		return nil, fmt.Errorf("missing %q key in JSON", key)
 	}
 
 	var records []costRecord
 	if err := json.Unmarshal(arrayData, &records); err != nil {
-		return nil, fmt.Errorf("parsing %s entries: %w", subcommand, err)
+		return nil, fmt.Errorf("parsing %s entries: %w", key, err)
 	}
-
-	result := make([]ChartEntry, 0, len(records))
-	for _, r := range records {
-		result = append(result, ChartEntry{Label: r.label(), Value: r.TotalCost})
-	}
-	return result, nil
+	return records, nil
 }
 
 // Run is the entry point for the cost command.
 // args is os.Args[2:] (everything after "cost").
 func Run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "budget":
+			return runBudget(os.Stdout, args[1:])
+		case "export":
+			return runExport(args[1:])
+		case "report":
+			return runReport(os.Stdout, args[1:])
+		case "by-project":
+			return runByProject(os.Stdout, args[1:])
+		case "blocks":
+			if _, watch := stripFlag(args, "--watch"); watch {
+				return runWatch(os.Stdout, args[1:])
+			}
+		}
+	}
+	args, enforce := stripFlag(args, "--enforce")
+
 	runtime, prefix := detectRuntime()
 	if runtime == "" {
 		fmt.Fprintln(os.Stderr, "  bun or npx is required to run ccusage.")
@@ -87,7 +115,26 @@ func Run(args []string) error {
 	cmdArgs := make([]string, 0, len(prefix)+len(args))
 	cmdArgs = append(cmdArgs, prefix...)
 	cmdArgs = append(cmdArgs, args...)
-	return platform.Run(runtime, cmdArgs...)
+	if err := platform.Run(runtime, cmdArgs...); err != nil {
+		return err
+	}
+	// Budget alerts go to stderr so --json output stays machine-readable.
+	return enforceBudget(os.Stderr, enforce)
+}
+
+// stripFlag removes every occurrence of flag from args and reports whether it
+// was present.
+func stripFlag(args []string, flag string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for _, a := range args {
+		if a == flag {
+			found = true
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, found
 }
 
 // RunCapture runs ccusage and returns the output as a string.
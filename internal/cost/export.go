@@ -0,0 +1,421 @@
+package cost
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// UsageRow is the spend for one project and model over the export period.
+type UsageRow struct {
+	Project             string  `json:"project"`
+	Model               string  `json:"model"`
+	InputTokens         int64   `json:"inputTokens"`
+	OutputTokens        int64   `json:"outputTokens"`
+	CacheCreationTokens int64   `json:"cacheCreationTokens"`
+	CacheReadTokens     int64   `json:"cacheReadTokens"`
+	Cost                float64 `json:"cost"`
+}
+
+// TotalTokens returns the sum of all token counts in the row.
+func (r UsageRow) TotalTokens() int64 {
+	return r.InputTokens + r.OutputTokens + r.CacheCreationTokens + r.CacheReadTokens
+}
+
+func (r *UsageRow) add(o UsageRow) {
+	r.InputTokens += o.InputTokens
+	r.OutputTokens += o.OutputTokens
+	r.CacheCreationTokens += o.CacheCreationTokens
+	r.CacheReadTokens += o.CacheReadTokens
+	r.Cost += o.Cost
+}
+
+// Export is the JSON document written by "cost export --format json". Exports
+// from several team members can be combined with "cost report --input".
+type Export struct {
+	User        string     `json:"user,omitempty"`
+	Since       string     `json:"since,omitempty"`
+	Until       string     `json:"until,omitempty"`
+	GeneratedAt string     `json:"generatedAt"`
+	TotalCost   float64    `json:"totalCost"`
+	Rows        []UsageRow `json:"rows"`
+}
+
+// sessionUsage is a ccusage "session --json" entry with its per-model costs.
+type sessionUsage struct {
+	SessionID           string   `json:"sessionId"`
+	ProjectPath         string   `json:"projectPath"`
+	InputTokens         int64    `json:"inputTokens"`
+	OutputTokens        int64    `json:"outputTokens"`
+	CacheCreationTokens int64    `json:"cacheCreationTokens"`
+	CacheReadTokens     int64    `json:"cacheReadTokens"`
+	TotalCost           float64  `json:"totalCost"`
+	ModelsUsed          []string `json:"modelsUsed"`
+	ModelBreakdowns     []struct {
+		ModelName           string  `json:"modelName"`
+		InputTokens         int64   `json:"inputTokens"`
+		OutputTokens        int64   `json:"outputTokens"`
+		CacheCreationTokens int64   `json:"cacheCreationTokens"`
+		CacheReadTokens     int64   `json:"cacheReadTokens"`
+		Cost                float64 `json:"cost"`
+	} `json:"modelBreakdowns"`
+}
+
+// project returns the session's project directory. ccusage names sessions
+// after Claude Code's encoded project directory ("-Users-me-app"), and only
+// some releases report a usable projectPath.
+func (s *sessionUsage) project() string {
+	switch {
+	case strings.HasPrefix(s.ProjectPath, "/"):
+		return s.ProjectPath
+	case strings.HasPrefix(s.SessionID, "-"):
+		return sessions.DecodeProjectPath(s.SessionID)
+	case s.ProjectPath != "" && s.ProjectPath != "Unknown Project":
+		return s.ProjectPath
+	case s.SessionID != "":
+		return s.SessionID
+	default:
+		return "unknown"
+	}
+}
+
+// usageRows converts ccusage "session --json" output into rows aggregated by
+// project and model, sorted by project then model.
+func usageRows(data string) ([]UsageRow, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("parsing session JSON: %w", err)
+	}
+	arrayData, ok := raw["sessions"]
+	if !ok {
+		// Older ccusage releases used "session" as the envelope key.
+		if arrayData, ok = raw["session"]; !ok {
+			return nil, fmt.Errorf("missing %q key in JSON", "sessions")
+		}
+	}
+	var entries []sessionUsage
+	if err := json.Unmarshal(arrayData, &entries); err != nil {
+		return nil, fmt.Errorf("parsing session entries: %w", err)
+	}
+
+	var rows []UsageRow
+	for _, s := range entries {
+		project := s.project()
+		if len(s.ModelBreakdowns) == 0 {
+			model := strings.Join(s.ModelsUsed, ", ")
+			if model == "" {
+				model = "unknown"
+			}
+			rows = append(rows, UsageRow{
+				Project: project, Model: model,
+				InputTokens: s.InputTokens, OutputTokens: s.OutputTokens,
+				CacheCreationTokens: s.CacheCreationTokens, CacheReadTokens: s.CacheReadTokens,
+				Cost: s.TotalCost,
+			})
+			continue
+		}
+		for _, m := range s.ModelBreakdowns {
+			rows = append(rows, UsageRow{
+				Project: project, Model: m.ModelName,
+				InputTokens: m.InputTokens, OutputTokens: m.OutputTokens,
+				CacheCreationTokens: m.CacheCreationTokens, CacheReadTokens: m.CacheReadTokens,
+				Cost: m.Cost,
+			})
+		}
+	}
+	return mergeRows(rows), nil
+}
+
+// mergeRows sums rows with the same project and model.
+func mergeRows(rows []UsageRow) []UsageRow {
+	index := make(map[[2]string]int)
+	merged := make([]UsageRow, 0, len(rows))
+	for _, r := range rows {
+		key := [2]string{r.Project, r.Model}
+		if i, ok := index[key]; ok {
+			merged[i].add(r)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, r)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Project != merged[j].Project {
+			return merged[i].Project < merged[j].Project
+		}
+		return merged[i].Model < merged[j].Model
+	})
+	return merged
+}
+
+// exportOptions are the flags shared by "cost export" and "cost report".
+type exportOptions struct {
+	format string
+	since  string
+	until  string
+	output string
+	inputs []string
+}
+
+// parseExportArgs parses flags for "cost export", "cost report", and "cost
+// by-project" with f, declaring only the flags named in allowed.
+func parseExportArgs(f *cli.FlagSet, args []string, allowed ...string) (exportOptions, error) {
+	opts := exportOptions{format: "csv"}
+	date := func(name string, p *string) func(string) error {
+		return func(v string) error {
+			if _, err := time.Parse("20060102", v); err != nil {
+				return fmt.Errorf("%s must be a date in YYYYMMDD format", name)
+			}
+			*p = v
+			return nil
+		}
+	}
+	for _, name := range allowed {
+		switch name {
+		case "--format":
+			f.Func(name, "csv|json", "Output format (default: csv)", func(v string) error {
+				if v != "csv" && v != "json" {
+					return fmt.Errorf("--format must be csv or json")
+				}
+				opts.format = v
+				return nil
+			})
+		case "--since":
+			f.Func(name, "YYYYMMDD", "First day to include", date(name, &opts.since))
+		case "--until":
+			f.Func(name, "YYYYMMDD", "Last day to include", date(name, &opts.until))
+		case "--output":
+			f.StringVar(&opts.output, name, "path", "Write to a file instead of stdout")
+		case "--input":
+			f.StringsVar(&opts.inputs, name, "export.json", "Combine this JSON export instead of reading local usage")
+		}
+	}
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	return opts, f.CheckArgs(positional, 0, 0)
+}
+
+// loadUsage runs ccusage for the period in opts and returns rows by project
+// and model.
+func loadUsage(opts exportOptions) ([]UsageRow, error) {
+	return loadUsageContext(context.Background(), opts)
+}
+
+func loadUsageContext(ctx context.Context, opts exportOptions) ([]UsageRow, error) {
+	args := []string{"session", "--json", "--breakdown"}
+	if opts.since != "" {
+		args = append(args, "--since", opts.since)
+	}
+	if opts.until != "" {
+		args = append(args, "--until", opts.until)
+	}
+	out, err := RunCaptureContext(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("running ccusage: %w", err)
+	}
+	return usageRows(out)
+}
+
+// runExport implements "cost export [--format csv|json] [--since D] [--until D] [--output path]".
+func runExport(args []string) error {
+	f := cli.New("cost export", "cost export [--format csv|json] [--since YYYYMMDD] [--until YYYYMMDD] [--output path]")
+	opts, err := parseExportArgs(f, args, "--format", "--since", "--until", "--output")
+	if err != nil {
+		return err
+	}
+	rows, err := loadUsage(opts)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", opts.output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if opts.format == "json" {
+		err = writeExportJSON(w, newExport(rows, opts))
+	} else {
+		err = writeExportCSV(w, rows)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.output != "" {
+		platform.PrintOK(os.Stderr, fmt.Sprintf("Exported %d row(s) to %s", len(rows), opts.output))
+	}
+	return nil
+}
+
+func newExport(rows []UsageRow, opts exportOptions) Export {
+	e := Export{
+		User:        os.Getenv("USER"),
+		Since:       opts.since,
+		Until:       opts.until,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Rows:        rows,
+	}
+	if e.Rows == nil {
+		e.Rows = []UsageRow{}
+	}
+	for _, r := range rows {
+		e.TotalCost += r.Cost
+	}
+	return e
+}
+
+func writeExportJSON(w io.Writer, e Export) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+func writeExportCSV(w io.Writer, rows []UsageRow) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"project", "model", "input_tokens", "output_tokens",
+		"cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost_usd"})
+	for _, r := range rows {
+		_ = cw.Write([]string{
+			r.Project, r.Model,
+			strconv.FormatInt(r.InputTokens, 10),
+			strconv.FormatInt(r.OutputTokens, 10),
+			strconv.FormatInt(r.CacheCreationTokens, 10),
+			strconv.FormatInt(r.CacheReadTokens, 10),
+			strconv.FormatInt(r.TotalTokens(), 10),
+			strconv.FormatFloat(r.Cost, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// runReport implements "cost report [--since D] [--until D] [--input export.json ...]".
+// With --input, the report combines JSON exports (e.g. one per team member)
+// instead of reading local usage.
+func runReport(w io.Writer, args []string) error {
+	f := cli.New("cost report", "cost report [--since YYYYMMDD] [--until YYYYMMDD] [--input export.json ...]")
+	opts, err := parseExportArgs(f, args, "--since", "--until", "--input")
+	if err != nil {
+		return err
+	}
+	if len(opts.inputs) > 0 && (opts.since != "" || opts.until != "") {
+		return fmt.Errorf("--since and --until cannot be combined with --input; set them when exporting")
+	}
+
+	var rows []UsageRow
+	if len(opts.inputs) > 0 {
+		rows, err = readExports(opts.inputs)
+	} else {
+		rows, err = loadUsage(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	title := "Cost Report"
+	if opts.since != "" || opts.until != "" {
+		title += fmt.Sprintf(" (%s – %s)", orDash(opts.since), orDash(opts.until))
+	}
+	platform.PrintBanner(w, title)
+	if len(opts.inputs) > 0 {
+		fmt.Fprintf(w, "\n  Combined from %d export(s)\n", len(opts.inputs))
+	}
+	printReport(w, rows)
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "…"
+	}
+	return s
+}
+
+// readExports reads and combines JSON files written by "cost export --format json".
+func readExports(paths []string) ([]UsageRow, error) {
+	var rows []UsageRow
+	for _, p := range paths {
+		var e Export
+		if err := platform.ReadJSONFile(p, &e); err != nil {
+			return nil, err
+		}
+		rows = append(rows, e.Rows...)
+	}
+	return mergeRows(rows), nil
+}
+
+// reportGroup is one line of a report section.
+type reportGroup struct {
+	Name   string
+	Tokens int64
+	Cost   float64
+}
+
+// groupBy totals rows by key, sorted by cost (highest first).
+func groupBy(rows []UsageRow, key func(UsageRow) string) []reportGroup {
+	index := make(map[string]int)
+	var groups []reportGroup
+	for _, r := range rows {
+		k := key(r)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, reportGroup{Name: k})
+		}
+		groups[i].Tokens += r.TotalTokens()
+		groups[i].Cost += r.Cost
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Cost > groups[j].Cost })
+	return groups
+}
+
+func printReport(w io.Writer, rows []UsageRow) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "\n  No usage found for this period.")
+		return
+	}
+	var total float64
+	for _, r := range rows {
+		total += r.Cost
+	}
+	printGroups(w, "By Project", "PROJECT", groupBy(rows, func(r UsageRow) string { return r.Project }), total)
+	printGroups(w, "By Model", "MODEL", groupBy(rows, func(r UsageRow) string { return r.Model }), total)
+	fmt.Fprintf(w, "\n  %s $%.2f\n\n", platform.Bold("Total:"), total)
+}
+
+func printGroups(w io.Writer, title, header string, groups []reportGroup, total float64) {
+	width := len(header)
+	for _, g := range groups {
+		if len(g.Name) > width {
+			width = len(g.Name)
+		}
+	}
+	platform.PrintSection(w, title)
+	fmt.Fprintf(w, "  %-*s  %14s  %10s  %6s\n", width, header, "TOKENS", "COST", "SHARE")
+	for _, g := range groups {
+		share := 0.0
+		if total > 0 {
+			share = 100 * g.Cost / total
+		}
+		fmt.Fprintf(w, "  %-*s  %14d  %10s  %5.1f%%\n", width, g.Name, g.Tokens, fmt.Sprintf("$%.2f", g.Cost), share)
+	}
+}
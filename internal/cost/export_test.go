@@ -0,0 +1,153 @@
+package cost
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const sessionJSON = `{"sessions":[
+	{"sessionId":"-Users-me-app","projectPath":"Unknown Project","totalCost":3.5,
+	 "modelBreakdowns":[
+		{"modelName":"claude-opus-4","inputTokens":100,"outputTokens":50,"cost":3.0},
+		{"modelName":"claude-haiku-4","inputTokens":10,"outputTokens":5,"cost":0.5}]},
+	{"sessionId":"-Users-me-app","projectPath":"Unknown Project","totalCost":1.0,
+	 "modelBreakdowns":[{"modelName":"claude-opus-4","inputTokens":20,"outputTokens":10,"cacheReadTokens":70,"cost":1.0}]},
+	{"sessionId":"api","projectPath":"/srv/api","totalCost":2.0,"inputTokens":40,"modelsUsed":["claude-sonnet-4"]}
+]}`
+
+func TestUsageRows(t *testing.T) {
+	rows, err := usageRows(sessionJSON)
+	if err != nil {
+		t.Fatalf("usageRows: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3: %+v", len(rows), rows)
+	}
+
+	// Sorted by project then model.
+	want := []struct {
+		project, model string
+		cost           float64
+		tokens         int64
+	}{
+		{"/Users/me/app", "claude-haiku-4", 0.5, 15},
+		{"/Users/me/app", "claude-opus-4", 4.0, 250},
+		{"/srv/api", "claude-sonnet-4", 2.0, 40},
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.Project != w.project || r.Model != w.model {
+			t.Errorf("rows[%d] = %s/%s, want %s/%s", i, r.Project, r.Model, w.project, w.model)
+		}
+		if math.Abs(r.Cost-w.cost) > 0.001 {
+			t.Errorf("rows[%d].Cost = %f, want %f", i, r.Cost, w.cost)
+		}
+		if r.TotalTokens() != w.tokens {
+			t.Errorf("rows[%d].TotalTokens() = %d, want %d", i, r.TotalTokens(), w.tokens)
+		}
+	}
+}
+
+func TestUsageRows_MissingKey(t *testing.T) {
+	if _, err := usageRows(`{"daily":[]}`); err == nil {
+		t.Error("expected error for missing sessions key")
+	}
+}
+
+func TestParseExportArgs(t *testing.T) {
+	opts, err := parseExportArgs(cli.New("cost export", ""), []string{"--format", "json", "--since=20260101", "--until", "20260131", "--output", "out.json"},
+		"--format", "--since", "--until", "--output")
+	if err != nil {
+		t.Fatalf("parseExportArgs: %v", err)
+	}
+	if opts.format != "json" || opts.since != "20260101" || opts.until != "20260131" || opts.output != "out.json" {
+		t.Errorf("got %+v", opts)
+	}
+
+	if opts, _ := parseExportArgs(cli.New("cost export", ""), nil, "--format"); opts.format != "csv" {
+		t.Errorf("default format = %q, want csv", opts.format)
+	}
+
+	for _, args := range [][]string{
+		{"--format", "xml"},
+		{"--since", "2026-01-01"},
+		{"--until"},
+		{"--input", "a.json"}, // not allowed for export
+	} {
+		if _, err := parseExportArgs(cli.New("cost export", ""), args, "--format", "--since", "--until", "--output"); err == nil {
+			t.Errorf("parseExportArgs(%v): expected error", args)
+		}
+	}
+}
+
+func TestWriteExportCSV(t *testing.T) {
+	rows := []UsageRow{{Project: "/srv/a,b", Model: "opus", InputTokens: 1, OutputTokens: 2, Cost: 1.23456}}
+	var buf bytes.Buffer
+	if err := writeExportCSV(&buf, rows); err != nil {
+		t.Fatalf("writeExportCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "project,model,") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[1] != `"/srv/a,b",opus,1,2,0,0,3,1.2346` {
+		t.Errorf("row = %q", lines[1])
+	}
+}
+
+func TestReadExports_CombinesFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	_ = platform.WriteJSONFile(a, newExport([]UsageRow{{Project: "/p", Model: "opus", Cost: 1}}, exportOptions{}))
+	_ = platform.WriteJSONFile(b, newExport([]UsageRow{
+		{Project: "/p", Model: "opus", Cost: 2},
+		{Project: "/q", Model: "sonnet", Cost: 0.5},
+	}, exportOptions{}))
+
+	rows, err := readExports([]string{a, b})
+	if err != nil {
+		t.Fatalf("readExports: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Cost != 3 || rows[1].Project != "/q" {
+		t.Errorf("got %+v", rows)
+	}
+}
+
+func TestGroupBy_SortsByCost(t *testing.T) {
+	rows := []UsageRow{
+		{Project: "/a", Model: "opus", Cost: 1},
+		{Project: "/b", Model: "opus", Cost: 5},
+		{Project: "/a", Model: "sonnet", Cost: 0.5},
+	}
+	groups := groupBy(rows, func(r UsageRow) string { return r.Model })
+	if len(groups) != 2 || groups[0].Name != "opus" || groups[0].Cost != 6 {
+		t.Errorf("got %+v", groups)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	var buf bytes.Buffer
+	printReport(&buf, []UsageRow{{Project: "/a", Model: "opus", Cost: 3}, {Project: "/b", Model: "opus", Cost: 1}})
+	out := buf.String()
+	for _, want := range []string{"By Project", "By Model", "/a", "75.0%", "$4.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printReport(&buf, nil)
+	if !strings.Contains(buf.String(), "No usage found") {
+		t.Errorf("empty report = %q", buf.String())
+	}
+}
@@ -0,0 +1,142 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const byProjectUsage = "cost by-project [--since YYYYMMDD] [--until YYYYMMDD] [--json]"
+
+// ProjectCost is the spend attributed to one project directory: the
+// directory Claude Code was started in for each session.
+type ProjectCost struct {
+	Project string  `json:"project"`
+	Tokens  int64   `json:"tokens"`
+	Cost    float64 `json:"cost"`
+}
+
+// ByProject totals rows by project, highest cost first.
+func ByProject(rows []UsageRow) []ProjectCost {
+	groups := groupBy(rows, func(r UsageRow) string { return r.Project })
+	out := make([]ProjectCost, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, ProjectCost{Project: g.Name, Tokens: g.Tokens, Cost: g.Cost})
+	}
+	return out
+}
+
+// ProjectSpend returns spend by project from the day since (YYYYMMDD) on, as
+// reported by ccusage.
+func ProjectSpend(ctx context.Context, since string) ([]ProjectCost, error) {
+	rows, err := loadUsageContext(ctx, exportOptions{since: since})
+	if err != nil {
+		return nil, err
+	}
+	return ByProject(rows), nil
+}
+
+// projectKey normalizes a project directory for comparison. ccusage reports
+// some projects only by Claude Code's encoded directory name, which turns
+// "/" and "." into "-", so a decoded path may not match the original.
+func projectKey(path string) string {
+	return strings.NewReplacer("/", "-", ".", "-").Replace(strings.TrimSuffix(path, "/"))
+}
+
+// SameProject reports whether a and b name the same project directory.
+func SameProject(a, b string) bool {
+	return a != "" && b != "" && projectKey(a) == projectKey(b)
+}
+
+// SpendFor returns the spend of project in costs, or 0 when it has none.
+func SpendFor(costs []ProjectCost, project string) float64 {
+	var total float64
+	for _, c := range costs {
+		if SameProject(c.Project, project) {
+			total += c.Cost
+		}
+	}
+	return total
+}
+
+// runByProject implements "cost by-project": spend grouped by the project
+// directory of each session, for charging usage to the right team.
+func runByProject(w io.Writer, args []string) error {
+	var asJSON bool
+	f := cli.New("cost by-project", byProjectUsage)
+	f.BoolVar(&asJSON, "--json", "Print the spend as one JSON document")
+	opts, err := parseExportArgs(f, args, "--since", "--until")
+	if err != nil {
+		return err
+	}
+	rows, err := loadUsage(opts)
+	if err != nil {
+		return err
+	}
+	costs := ByProject(rows)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(newByProjectReport(costs, opts))
+	}
+	cwd, _ := os.Getwd()
+	printByProject(w, costs, opts, cwd)
+	return nil
+}
+
+// byProjectReport is the JSON document printed by "cost by-project --json".
+type byProjectReport struct {
+	Since       string        `json:"since,omitempty"`
+	Until       string        `json:"until,omitempty"`
+	GeneratedAt string        `json:"generatedAt"`
+	TotalCost   float64       `json:"totalCost"`
+	Projects    []ProjectCost `json:"projects"`
+}
+
+func newByProjectReport(costs []ProjectCost, opts exportOptions) byProjectReport {
+	r := byProjectReport{
+		Since:       opts.since,
+		Until:       opts.until,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Projects:    costs,
+	}
+	if r.Projects == nil {
+		r.Projects = []ProjectCost{}
+	}
+	for _, c := range costs {
+		r.TotalCost += c.Cost
+	}
+	return r
+}
+
+// printByProject prints costs as a table, marking the project containing cwd.
+func printByProject(w io.Writer, costs []ProjectCost, opts exportOptions, cwd string) {
+	title := "Cost by Project"
+	if opts.since != "" || opts.until != "" {
+		title += fmt.Sprintf(" (%s – %s)", orDash(opts.since), orDash(opts.until))
+	}
+	platform.PrintBanner(w, title)
+	if len(costs) == 0 {
+		fmt.Fprintln(w, "\n  No usage found for this period.")
+		return
+	}
+	var total float64
+	groups := make([]reportGroup, 0, len(costs))
+	for _, c := range costs {
+		total += c.Cost
+		name := c.Project
+		if SameProject(c.Project, cwd) {
+			name += " (current)"
+		}
+		groups = append(groups, reportGroup{Name: name, Tokens: c.Tokens, Cost: c.Cost})
+	}
+	printGroups(w, "By Project", "PROJECT", groups, total)
+	fmt.Fprintf(w, "\n  %s $%.2f\n\n", platform.Bold("Total:"), total)
+}
@@ -0,0 +1,65 @@
+package cost
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestByProject(t *testing.T) {
+	rows := []UsageRow{
+		{Project: "/a", Model: "opus", InputTokens: 10, OutputTokens: 5, Cost: 1},
+		{Project: "/b", Model: "opus", InputTokens: 100, Cost: 5},
+		{Project: "/a", Model: "sonnet", CacheReadTokens: 20, Cost: 0.5},
+	}
+	got := ByProject(rows)
+	if len(got) != 2 || got[0].Project != "/b" || got[1].Project != "/a" || got[1].Cost != 1.5 || got[1].Tokens != 35 {
+		t.Errorf("ByProject = %+v", got)
+	}
+}
+
+func TestSameProject(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"/Users/me/app", "/Users/me/app/", true},
+		{"/Users/me/my.app", "/Users/me/my/app", true},
+		{"/Users/me/app", "/Users/me/api", false},
+		{"", "", false},
+	} {
+		if got := SameProject(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameProject(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSpendFor(t *testing.T) {
+	costs := []ProjectCost{{Project: "/srv/api", Cost: 2}, {Project: "/srv/web", Cost: 3}}
+	if got := SpendFor(costs, "/srv/web"); got != 3 {
+		t.Errorf("SpendFor(/srv/web) = %v, want 3", got)
+	}
+	if got := SpendFor(costs, "/srv/cli"); got != 0 {
+		t.Errorf("SpendFor(/srv/cli) = %v, want 0", got)
+	}
+}
+
+func TestPrintByProject(t *testing.T) {
+	var buf bytes.Buffer
+	costs := []ProjectCost{{Project: "/srv/api", Cost: 3}, {Project: "/srv/web", Cost: 1}}
+	printByProject(&buf, costs, exportOptions{since: "20260301"}, "/srv/web")
+	out := buf.String()
+	for _, want := range []string{"Cost by Project (20260301 – …)", "/srv/api", "/srv/web (current)", "75.0%", "$4.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "/srv/api (current)") {
+		t.Errorf("wrong project marked current:\n%s", out)
+	}
+
+	r := newByProjectReport(nil, exportOptions{})
+	if r.Projects == nil || r.TotalCost != 0 {
+		t.Errorf("empty report = %+v", r)
+	}
+}
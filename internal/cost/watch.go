@@ -0,0 +1,218 @@
+package cost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const watchUsage = "cost blocks --watch [--interval <seconds>]"
+
+// defaultWatchInterval is how often "cost blocks --watch" refreshes.
+const defaultWatchInterval = 10 * time.Second
+
+// clearScreen moves the cursor home and clears the terminal, so each refresh
+// replaces the last.
+const clearScreen = "\033[H\033[2J"
+
+// usageBlock is a ccusage "blocks --json" entry: the usage in one 5-hour
+// billing window.
+type usageBlock struct {
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	IsActive    bool      `json:"isActive"`
+	IsGap       bool      `json:"isGap"`
+	Entries     int       `json:"entries"`
+	TotalTokens int64     `json:"totalTokens"`
+	CostUSD     float64   `json:"costUSD"`
+	Models      []string  `json:"models"`
+	BurnRate    *struct {
+		TokensPerMinute float64 `json:"tokensPerMinute"`
+		CostPerHour     float64 `json:"costPerHour"`
+	} `json:"burnRate"`
+	Projection *struct {
+		TotalTokens float64 `json:"totalTokens"`
+		TotalCost   float64 `json:"totalCost"`
+	} `json:"projection"`
+}
+
+// blockStatus is the active block with its rates worked out.
+type blockStatus struct {
+	usageBlock
+	costPerHour     float64
+	tokensPerMinute float64
+	projectedCost   float64
+	projectedTokens int64
+}
+
+// fetchBlocks returns ccusage's JSON for the active block; tests replace it.
+var fetchBlocks = func(ctx context.Context) (string, error) {
+	return RunCaptureContext(ctx, []string{"blocks", "--active", "--json"})
+}
+
+// parseWatchArgs reads the flags of "cost blocks --watch".
+func parseWatchArgs(args []string) (time.Duration, error) {
+	interval := defaultWatchInterval
+	var watch, active bool
+	f := cli.New("cost blocks", watchUsage)
+	f.BoolVar(&watch, "--watch", "Redraw the current block until interrupted")
+	f.BoolVar(&active, "--active", "Show only the active block (always the case with --watch)")
+	f.Func("--interval", "seconds", "Seconds between redraws, or a duration such as 1m (default: 10)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if n, nerr := strconv.Atoi(v); nerr == nil {
+			d, err = time.Duration(n)*time.Second, nil
+		}
+		if err != nil || d < time.Second {
+			return fmt.Errorf("--interval must be at least 1 second, got %q", v)
+		}
+		interval = d
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return 0, err
+	}
+	return interval, f.CheckArgs(positional, 0, 0)
+}
+
+// runWatch implements "cost blocks --watch": it redraws the current 5-hour
+// block's usage, burn rate, and projected total every interval until
+// interrupted.
+func runWatch(w io.Writer, args []string) error {
+	interval, err := parseWatchArgs(args)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	tty := platform.IsTTY()
+	for {
+		// Render off screen first so the terminal is cleared and redrawn at
+		// once, without flicker while ccusage runs.
+		var frame bytes.Buffer
+		status, ferr := fetchStatus(ctx, time.Now())
+		if ctx.Err() != nil {
+			return nil
+		}
+		renderBlock(&frame, status, ferr, time.Now(), interval)
+		if tty {
+			io.WriteString(w, clearScreen)
+		}
+		w.Write(frame.Bytes())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchStatus returns the active block, or nil when there is none.
+func fetchStatus(ctx context.Context, now time.Time) (*blockStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	data, err := fetchBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return activeBlock(data, now)
+}
+
+// activeBlock picks the active block from ccusage "blocks --json" output and
+// works out its burn rate and projected total, from ccusage's figures when it
+// reports them and from the time elapsed in the block otherwise.
+func activeBlock(data string, now time.Time) (*blockStatus, error) {
+	var out struct {
+		Blocks []usageBlock `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(data), &out); err != nil {
+		return nil, fmt.Errorf("parsing blocks JSON: %w", err)
+	}
+	for _, b := range out.Blocks {
+		if !b.IsActive || b.IsGap {
+			continue
+		}
+		s := &blockStatus{usageBlock: b}
+		if b.BurnRate != nil {
+			s.costPerHour, s.tokensPerMinute = b.BurnRate.CostPerHour, b.BurnRate.TokensPerMinute
+		} else if elapsed := now.Sub(b.StartTime); elapsed > time.Minute {
+			s.costPerHour = b.CostUSD / elapsed.Hours()
+			s.tokensPerMinute = float64(b.TotalTokens) / elapsed.Minutes()
+		}
+		if b.Projection != nil {
+			s.projectedCost, s.projectedTokens = b.Projection.TotalCost, int64(b.Projection.TotalTokens)
+		} else {
+			remaining := b.EndTime.Sub(now)
+			if remaining < 0 {
+				remaining = 0
+			}
+			s.projectedCost = b.CostUSD + s.costPerHour*remaining.Hours()
+			s.projectedTokens = b.TotalTokens + int64(s.tokensPerMinute*remaining.Minutes())
+		}
+		return s, nil
+	}
+	return nil, nil
+}
+
+// renderBlock writes one screen of "cost blocks --watch".
+func renderBlock(w io.Writer, s *blockStatus, err error, now time.Time, interval time.Duration) {
+	platform.PrintBanner(w, "Current 5-Hour Block")
+	fmt.Fprintln(w)
+	switch {
+	case err != nil:
+		platform.PrintWarningLine(w, fmt.Sprintf("Could not read usage: %v", err))
+	case s == nil:
+		fmt.Fprintln(w, "  No active block. One starts with the next Claude Code request.")
+	default:
+		start, end := s.StartTime.Local(), s.EndTime.Local()
+		fmt.Fprintf(w, "  Started      %s (%s ago)\n", start.Format("15:04"), formatSpan(now.Sub(start)))
+		fmt.Fprintf(w, "  Resets       %s (in %s)\n", end.Format("15:04"), formatSpan(end.Sub(now)))
+		elapsed := 0.0
+		if total := end.Sub(start); total > 0 {
+			elapsed = min(1, max(0, float64(now.Sub(start))/float64(total)))
+		}
+		fmt.Fprintf(w, "  Elapsed      %s %3.0f%%\n", progressBar(elapsed, 30), 100*elapsed)
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  Cost         $%.2f\n", s.CostUSD)
+		fmt.Fprintf(w, "  Tokens       %s\n", platform.FormatCount(s.TotalTokens))
+		fmt.Fprintf(w, "  Burn rate    $%.2f/h, %s tokens/min\n", s.costPerHour, platform.FormatCount(int64(s.tokensPerMinute)))
+		fmt.Fprintf(w, "  Projected    $%.2f, %s tokens by %s\n", s.projectedCost, platform.FormatCount(s.projectedTokens), end.Format("15:04"))
+		if len(s.Models) > 0 {
+			fmt.Fprintf(w, "  Models       %s\n", strings.Join(s.Models, ", "))
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Updated %s, refreshing every %s. Press Ctrl+C to stop.\n", now.Local().Format("15:04:05"), interval)
+}
+
+// progressBar draws fraction of width cells.
+func progressBar(fraction float64, width int) string {
+	filled := int(fraction*float64(width) + 0.5)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// formatSpan formats d as hours and minutes, e.g. "2h 05m" or "42m".
+func formatSpan(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Minute)
+	if h := int(d.Hours()); h > 0 {
+		return fmt.Sprintf("%dh %02dm", h, int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
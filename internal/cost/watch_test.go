@@ -0,0 +1,96 @@
+package cost
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const blocksJSON = `{"blocks": [
+  {"id": "2026-03-10T12:00:00.000Z", "startTime": "2026-03-10T12:00:00.000Z", "endTime": "2026-03-10T17:00:00.000Z",
+   "isActive": false, "isGap": true, "entries": 0, "totalTokens": 0, "costUSD": 0, "models": []},
+  {"id": "2026-03-10T14:00:00.000Z", "startTime": "2026-03-10T14:00:00.000Z", "endTime": "2026-03-10T19:00:00.000Z",
+   "isActive": true, "isGap": false, "entries": 120, "totalTokens": 3000000, "costUSD": 12.5,
+   "models": ["claude-opus-4", "claude-sonnet-4"]%s}
+]}`
+
+func TestActiveBlock(t *testing.T) {
+	now := time.Date(2026, 3, 10, 16, 30, 0, 0, time.UTC)
+
+	// Without ccusage's rates, they come from the 2h30m elapsed.
+	s, err := activeBlock(strings.Replace(blocksJSON, "%s", "", 1), now)
+	if err != nil || s == nil {
+		t.Fatalf("activeBlock = %v, %v", s, err)
+	}
+	if s.costPerHour != 5 || s.tokensPerMinute != 20000 || s.projectedCost != 25 || s.projectedTokens != 6000000 {
+		t.Errorf("computed rates: %+v", s)
+	}
+
+	rates := `, "burnRate": {"tokensPerMinute": 30000, "costPerHour": 6.25}, "projection": {"totalTokens": 7500000, "totalCost": 28.12, "remainingMinutes": 150}`
+	s, _ = activeBlock(strings.Replace(blocksJSON, "%s", rates, 1), now)
+	if s.costPerHour != 6.25 || s.tokensPerMinute != 30000 || math.Abs(s.projectedCost-28.12) > 1e-9 || s.projectedTokens != 7500000 {
+		t.Errorf("ccusage rates: %+v", s)
+	}
+
+	if s, err := activeBlock(`{"blocks": []}`, now); s != nil || err != nil {
+		t.Errorf("no blocks: %v, %v", s, err)
+	}
+	if _, err := activeBlock(`not json`, now); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestRenderBlock(t *testing.T) {
+	now := time.Date(2026, 3, 10, 16, 30, 0, 0, time.Local)
+	s, _ := activeBlock(strings.Replace(blocksJSON, "%s", "", 1), now)
+	s.StartTime, s.EndTime = now.Add(-150*time.Minute), now.Add(150*time.Minute)
+
+	var b strings.Builder
+	renderBlock(&b, s, nil, now, 10*time.Second)
+	out := b.String()
+	for _, want := range []string{
+		"Started      14:00 (2h 30m ago)",
+		"Resets       19:00 (in 2h 30m)",
+		"░░░]  50%",
+		"Cost         $12.50",
+		"Tokens       3,000,000",
+		"Burn rate    $5.00/h, 20,000 tokens/min",
+		"Projected    $25.00, 6,000,000 tokens by 19:00",
+		"claude-opus-4, claude-sonnet-4",
+		"refreshing every 10s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	b.Reset()
+	renderBlock(&b, nil, nil, now, time.Minute)
+	if !strings.Contains(b.String(), "No active block") {
+		t.Errorf("no block:\n%s", b.String())
+	}
+	b.Reset()
+	renderBlock(&b, nil, errors.New("npx not found"), now, time.Minute)
+	if !strings.Contains(b.String(), "npx not found") {
+		t.Errorf("error:\n%s", b.String())
+	}
+}
+
+func TestParseWatchArgs(t *testing.T) {
+	for args, want := range map[string]time.Duration{
+		"--watch":                        defaultWatchInterval,
+		"--watch --interval 5":           5 * time.Second,
+		"--active --watch --interval 1m": time.Minute,
+	} {
+		if got, err := parseWatchArgs(strings.Fields(args)); err != nil || got != want {
+			t.Errorf("parseWatchArgs(%q) = %v, %v, want %v", args, got, err, want)
+		}
+	}
+	for _, args := range []string{"--watch --interval 0", "--watch --interval 500ms", "--watch --interval", "--watch --json"} {
+		if _, err := parseWatchArgs(strings.Fields(args)); err == nil {
+			t.Errorf("parseWatchArgs(%q): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package detach implements the "detach" command, which reverses "attach" by
+// removing platform-provided agents, skills, hooks, settings, MCP config, and
+// CLAUDE.md from a project directory. Files the user has modified locally are
+// preserved unless --force is given.
+package detach
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lamchakchan/claude-workspace/internal/attach"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/manifest"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/templates"
+)
+
+// fileStatus classifies a project file against the platform asset it came from.
+type fileStatus int
+
+const (
+	statusMissing  fileStatus = iota // file does not exist in the project
+	statusPristine                   // identical to the platform asset (or a symlink into the asset cache)
+	statusModified                   // edited locally or replaced with a foreign symlink
+)
+
+const usage = "detach <project-path> [--force] [--keep-claude-md] [--profile <name>] [--template <source>]"
+
+// options holds the parsed detach flags.
+type options struct {
+	force          bool
+	keepClaudeMd   bool
+	profile        string             // --profile: profile used at attach time
+	template       string             // --template: remote template used at attach time
+	templateSHA256 string             // --template-sha256: checksum it was verified with
+	manifest       *manifest.Manifest // project manifest used at attach time, if any
+}
+
+// parseArgs parses detach command arguments into the project path and flags.
+func parseArgs(args []string) (string, options, error) {
+	var o options
+	f := cli.New("detach", usage)
+	f.BoolVar(&o.force, "--force", "Also remove locally modified files")
+	f.BoolVar(&o.keepClaudeMd, "--keep-claude-md", "Keep .claude/CLAUDE.md")
+	f.StringVar(&o.profile, "--profile", "name", "Profile used at attach time")
+	f.StringVar(&o.template, "--template", "source", "Remote template used at attach time")
+	f.StringVar(&o.templateSHA256, "--template-sha256", "sum", "SHA-256 the template was attached with")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", o, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return "", o, err
+	}
+	return positional[0], o, nil
+}
+
+// result tallies what detach did so a summary can be printed at the end.
+type result struct {
+	removed int
+	skipped []string
+}
+
+// Run executes the detach command, removing platform configuration from the
+// project given in args. It supports --force, --keep-claude-md, and --profile
+// flags. --profile should match the profile used at attach
+// time unless the project manifest already names it. --template (with the same
+// --template-sha256, if any) compares against a cached remote template instead
+// of the embedded assets; without it, the template recorded at attach time is
+// used.
+func Run(args []string) error {
+	targetPath, opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	projectDir, err := filepath.Abs(targetPath)
+	if err != nil {
+		return fmt.Errorf("resolving path: %w", err)
+	}
+
+	oldFS := platform.FS
+	defer func() { platform.FS = oldFS }()
+
+	cacheDir, _ := platform.AssetCacheDir()
+	if source := templateSource(projectDir, opts.template); source != "" {
+		src, err := templates.ParseSource(source)
+		if err != nil {
+			return err
+		}
+		tmpl, err := templates.Lookup(src, templates.Options{SHA256: opts.templateSHA256})
+		if err != nil {
+			return err
+		}
+		platform.FS = platform.WithOverrides(tmpl.FS())
+		cacheDir = tmpl.Assets
+	}
+
+	m, assetFS, err := manifest.Resolve(projectDir, opts.profile)
+	if err != nil {
+		return err
+	}
+	platform.FS = assetFS
+	opts.manifest = m
+
+	claudeDir := filepath.Join(projectDir, ".claude")
+	if !platform.FileExists(claudeDir) {
+		return fmt.Errorf("no .claude directory found in %s (is the platform attached?)", projectDir)
+	}
+
+	platform.PrintBanner(os.Stdout, fmt.Sprintf("Detaching Claude Platform from: %s", projectDir))
+	fmt.Println()
+
+	res := &result{}
+
+	platform.PrintStep(os.Stdout, 1, 5, "Removing agents...")
+	detachAssetDir(projectDir, ".claude/agents", cacheDir, opts, res)
+
+	platform.PrintStep(os.Stdout, 2, 5, "Removing skills...")
+	detachAssetDir(projectDir, ".claude/skills", cacheDir, opts, res)
+
+	platform.PrintStep(os.Stdout, 3, 5, "Removing hooks...")
+	detachAssetDir(projectDir, ".claude/hooks", cacheDir, opts, res)
+
+	platform.PrintStep(os.Stdout, 4, 5, "Removing settings and MCP configuration...")
+	for _, asset := range []string{".claude/settings.json", ".claude/settings.local.json.example", ".mcp.json"} {
+		detachAssetFile(projectDir, asset, cacheDir, opts, res)
+	}
+
+	platform.PrintStep(os.Stdout, 5, 5, "Removing project instructions...")
+	detachAssetFile(projectDir, ".claude/rules/platform.md", cacheDir, opts, res)
+	detachClaudeMd(projectDir, opts, res)
+	removeIfEmptyFile(filepath.Join(claudeDir, "plans", ".gitkeep"), res)
+	removeLockFile(projectDir, res)
+	// Stop moving the files of a project attached with --hardlink to new
+	// assets; a project that was not is not recorded.
+	if cacheDir != "" {
+		if err := platform.UnregisterLinkedProject(cacheDir, projectDir); err != nil {
+			platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error: %v", err))
+		}
+	}
+
+	for _, dir := range []string{"agents", "skills", "hooks", "rules", "plans"} {
+		pruneEmptyDirs(filepath.Join(claudeDir, dir))
+	}
+
+	platform.PrintBanner(os.Stdout, "Detach Complete")
+	fmt.Printf("\n%s %d file(s) from %s\n", platform.Bold("Removed:"), res.removed, projectDir)
+
+	if len(res.skipped) > 0 {
+		platform.PrintSection(os.Stdout, "Kept (modified locally)")
+		for _, rel := range res.skipped {
+			platform.PrintManual(os.Stdout, rel)
+		}
+		fmt.Println()
+		fmt.Println("  Re-run with --force to remove these files as well.")
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// detachAssetDir removes every project file under srcDir that originated from
+// the embedded platform assets.
+func detachAssetDir(projectDir, srcDir, cacheDir string, opts options, res *result) {
+	err := platform.WalkAssets(srcDir, func(path string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		detachAssetFile(projectDir, path, cacheDir, opts, res)
+		return nil
+	})
+	if err != nil {
+		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error: %v", err))
+	}
+}
+
+// detachAssetFile removes the project copy of a single embedded asset, unless it
+// has been modified locally and --force is not set.
+func detachAssetFile(projectDir, assetPath, cacheDir string, opts options, res *result) {
+	destPath := filepath.Join(projectDir, filepath.FromSlash(assetPath))
+	expected, err := expectedContent(assetPath, opts.manifest)
+	if err != nil {
+		return
+	}
+	status := classify(destPath, expected, filepath.Join(cacheDir, filepath.FromSlash(assetPath)))
+	removeClassified(destPath, assetPath, status, opts.force, res)
+}
+
+// expectedContent returns the content attach writes for assetPath, accounting
+// for manifest filtering of settings.json hooks and .mcp.json servers.
+func expectedContent(assetPath string, m *manifest.Manifest) ([]byte, error) {
+	data, err := platform.ReadAsset(assetPath)
+	if err != nil {
+		return nil, err
+	}
+	switch assetPath {
+	case ".claude/settings.json":
+		return m.RenderSettings(data)
+	case ".mcp.json":
+		if m.Declares(manifest.KindMCPServers) {
+			data, _, err = m.RenderMcpConfig()
+		}
+	}
+	return data, err
+}
+
+// detachClaudeMd removes .claude/CLAUDE.md unless --keep-claude-md is set. The
+// file is compared against a freshly generated scaffold; enriched or hand-edited
+// instructions count as local modifications.
+func detachClaudeMd(projectDir string, opts options, res *result) {
+	rel := ".claude/CLAUDE.md"
+	path := filepath.Join(projectDir, ".claude", "CLAUDE.md")
+	if opts.keepClaudeMd {
+		if platform.FileExists(path) {
+			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Keeping: %s (--keep-claude-md)", rel))
+		}
+		return
+	}
+	scaffold := platform.GenerateClaudeMdScaffold(projectDir)
+	status := classify(path, []byte(scaffold), "")
+
+	// attach --monorepo adds a Key Packages section to the scaffold and writes a
+	// scaffold into each package. Package files that differ from the scaffold
+	// may predate attach, so they are kept even with --force.
+	ws := platform.DetectWorkspace(projectDir)
+	if ws == nil {
+		removeClassified(path, rel, status, opts.force, res)
+		return
+	}
+	if status == statusModified {
+		withPackages := platform.SetMarkdownSection(scaffold, "Key Packages", platform.KeyPackagesSection(projectDir, ws, nil))
+		status = classify(path, []byte(withPackages), "")
+	}
+	removeClassified(path, rel, status, opts.force, res)
+	for _, m := range ws.Members {
+		pkgDir := filepath.Join(projectDir, filepath.FromSlash(m))
+		pkgPath := filepath.Join(pkgDir, "CLAUDE.md")
+		expected := []byte(platform.GeneratePackageScaffold(projectDir, pkgDir))
+		if classify(pkgPath, expected, "") == statusPristine {
+			removeClassified(pkgPath, m+"/CLAUDE.md", statusPristine, opts.force, res)
+		}
+	}
+}
+
+// classify reports whether the file at path matches the expected content. A
+// symlink resolving into cachePath (the attach --symlink asset cache) counts as
+// pristine regardless of content.
+func classify(path string, expected []byte, cachePath string) fileStatus {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return statusMissing
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err == nil && cachePath != "" && filepath.Clean(target) == filepath.Clean(cachePath) {
+			return statusPristine
+		}
+		return statusModified
+	}
+	if info.IsDir() {
+		return statusModified
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(data, expected) {
+		return statusModified
+	}
+	return statusPristine
+}
+
+// removeClassified deletes path according to its status and records the outcome.
+func removeClassified(path, rel string, status fileStatus, force bool, res *result) {
+	switch status {
+	case statusMissing:
+		return
+	case statusModified:
+		if !force {
+			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Skipping (modified): %s", rel))
+			res.skipped = append(res.skipped, rel)
+			return
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		platform.PrintErrorLine(os.Stdout, fmt.Sprintf("Error removing %s: %v", rel, err))
+		return
+	}
+	res.removed++
+	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Removed: %s", rel))
+}
+
+// removeIfEmptyFile deletes a zero-length placeholder file such as plans/.gitkeep.
+func removeIfEmptyFile(path string, res *result) {
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != 0 {
+		return
+	}
+	if os.Remove(path) == nil {
+		res.removed++
+	}
+}
+
+// removeLockFile deletes the record of attached files that attach writes for
+// --check and --reconcile.
+func removeLockFile(projectDir string, res *result) {
+	if os.Remove(filepath.Join(projectDir, filepath.FromSlash(attach.LockFile))) == nil {
+		res.removed++
+	}
+}
+
+// pruneEmptyDirs removes root and any of its subdirectories that are empty,
+// deepest first. Directories that still contain files are left in place.
+func pruneEmptyDirs(root string) {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err == nil && len(entries) == 0 {
+			_ = os.Remove(dirs[i])
+		}
+	}
+}
+
+// templateSource returns the template to compare against: flag (--template),
+// else the one recorded in the lock file at attach time, else the
+// workspace.templateSource setting for projects attached without a lock file.
+func templateSource(projectDir, flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if lock, err := attach.ReadLock(projectDir); err == nil && lock != nil {
+		return lock.Template
+	}
+	return platform.ConfigString(platform.ConfigTemplateSource)
+}
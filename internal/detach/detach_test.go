@@ -0,0 +1,228 @@
+package detach
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func setupMockFS() func() {
+	oldFS := platform.FS
+	platform.FS = fstest.MapFS{
+		".claude/agents/planner.md":       &fstest.MapFile{Data: []byte("# Planner\n")},
+		".claude/hooks/guard.sh":          &fstest.MapFile{Data: []byte("#!/bin/bash\n")},
+		".claude/skills/onboard/SKILL.md": &fstest.MapFile{Data: []byte("# Onboard\n")},
+		".claude/settings.json":           &fstest.MapFile{Data: []byte("{}\n")},
+		".mcp.json":                       &fstest.MapFile{Data: []byte("{\"mcpServers\":{}}\n")},
+	}
+	return func() { platform.FS = oldFS }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache", "a.md")
+	writeFile(t, cache, "cached")
+
+	pristine := filepath.Join(dir, "pristine.md")
+	writeFile(t, pristine, "same")
+	modified := filepath.Join(dir, "modified.md")
+	writeFile(t, modified, "changed")
+	linked := filepath.Join(dir, "linked.md")
+	_ = os.Symlink(cache, linked)
+	foreign := filepath.Join(dir, "foreign.md")
+	_ = os.Symlink(pristine, foreign)
+
+	tests := []struct {
+		name string
+		path string
+		want fileStatus
+	}{
+		{"missing", filepath.Join(dir, "nope.md"), statusMissing},
+		{"pristine copy", pristine, statusPristine},
+		{"modified copy", modified, statusModified},
+		{"symlink into cache", linked, statusPristine},
+		{"foreign symlink", foreign, statusModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.path, []byte("same"), cache); got != tt.want {
+				t.Errorf("classify(%s) = %d, want %d", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetachAssetDir_KeepsModifiedWithoutForce(t *testing.T) {
+	restore := setupMockFS()
+	defer restore()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".claude", "agents", "planner.md"), "# Planner\n")
+	writeFile(t, filepath.Join(dir, ".claude", "hooks", "guard.sh"), "#!/bin/bash\necho edited\n")
+	writeFile(t, filepath.Join(dir, ".claude", "agents", "custom.md"), "# Mine\n")
+
+	res := &result{}
+	detachAssetDir(dir, ".claude/agents", "", options{}, res)
+	detachAssetDir(dir, ".claude/hooks", "", options{}, res)
+
+	if platform.FileExists(filepath.Join(dir, ".claude", "agents", "planner.md")) {
+		t.Error("pristine agent should be removed")
+	}
+	if !platform.FileExists(filepath.Join(dir, ".claude", "hooks", "guard.sh")) {
+		t.Error("modified hook should be kept without --force")
+	}
+	if !platform.FileExists(filepath.Join(dir, ".claude", "agents", "custom.md")) {
+		t.Error("user-created agent must never be removed")
+	}
+	if res.removed != 1 || len(res.skipped) != 1 {
+		t.Errorf("removed=%d skipped=%v, want 1 removed and 1 skipped", res.removed, res.skipped)
+	}
+}
+
+func TestDetachAssetDir_ForceRemovesModified(t *testing.T) {
+	restore := setupMockFS()
+	defer restore()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".claude", "hooks", "guard.sh"), "#!/bin/bash\necho edited\n")
+
+	res := &result{}
+	detachAssetDir(dir, ".claude/hooks", "", options{force: true}, res)
+
+	if platform.FileExists(filepath.Join(dir, ".claude", "hooks", "guard.sh")) {
+		t.Error("modified hook should be removed with --force")
+	}
+}
+
+func TestDetachClaudeMd_Keep(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".claude", "CLAUDE.md")
+	writeFile(t, path, platform.GenerateClaudeMdScaffold(dir))
+
+	detachClaudeMd(dir, options{keepClaudeMd: true}, &result{})
+	if !platform.FileExists(path) {
+		t.Error("CLAUDE.md should be kept with --keep-claude-md")
+	}
+
+	detachClaudeMd(dir, options{}, &result{})
+	if platform.FileExists(path) {
+		t.Error("unmodified scaffold CLAUDE.md should be removed")
+	}
+}
+
+func TestDetachClaudeMd_EnrichedRequiresForce(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".claude", "CLAUDE.md")
+	writeFile(t, path, "# Project Instructions\n\nEnriched content\n")
+
+	detachClaudeMd(dir, options{}, &result{})
+	if !platform.FileExists(path) {
+		t.Error("enriched CLAUDE.md should be kept without --force")
+	}
+
+	detachClaudeMd(dir, options{force: true}, &result{})
+	if platform.FileExists(path) {
+		t.Error("enriched CLAUDE.md should be removed with --force")
+	}
+}
+
+func TestDetachClaudeMd_Monorepo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.work"), "use (\n\t./api\n\t./web\n)\n")
+	writeFile(t, filepath.Join(dir, "api", "go.mod"), "module example.com/api\n")
+	writeFile(t, filepath.Join(dir, "web", "go.mod"), "module example.com/web\n")
+	ws := platform.DetectWorkspace(dir)
+
+	root := filepath.Join(dir, ".claude", "CLAUDE.md")
+	writeFile(t, root, platform.SetMarkdownSection(platform.GenerateClaudeMdScaffold(dir), "Key Packages", platform.KeyPackagesSection(dir, ws, nil)))
+	apiPath := filepath.Join(dir, "api", "CLAUDE.md")
+	writeFile(t, apiPath, platform.GeneratePackageScaffold(dir, filepath.Join(dir, "api")))
+	webPath := filepath.Join(dir, "web", "CLAUDE.md")
+	writeFile(t, webPath, "# Web\n\nHand-written notes\n")
+
+	detachClaudeMd(dir, options{force: true}, &result{})
+	if platform.FileExists(root) {
+		t.Error("root scaffold with Key Packages should be removed")
+	}
+	if platform.FileExists(apiPath) {
+		t.Error("pristine package scaffold should be removed")
+	}
+	if !platform.FileExists(webPath) {
+		t.Error("modified package CLAUDE.md should be kept even with --force")
+	}
+}
+
+func TestPruneEmptyDirs(t *testing.T) {
+	dir := t.TempDir()
+	skills := filepath.Join(dir, "skills")
+	_ = os.MkdirAll(filepath.Join(skills, "empty", "nested"), 0755)
+	writeFile(t, filepath.Join(skills, "kept", "SKILL.md"), "x")
+
+	pruneEmptyDirs(skills)
+
+	if platform.FileExists(filepath.Join(skills, "empty")) {
+		t.Error("empty subtree should be pruned")
+	}
+	if !platform.FileExists(filepath.Join(skills, "kept", "SKILL.md")) {
+		t.Error("non-empty directory should be kept")
+	}
+}
+
+func TestTemplateSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := platform.WriteConfig(platform.ConfigTemplateSource, "https://example.com/default.tar.gz"); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+
+	if got := templateSource(project, ""); got != "https://example.com/default.tar.gz" {
+		t.Errorf("without a lock file, templateSource() = %q, want the configured default", got)
+	}
+	if got := templateSource(project, "https://example.com/flag.tar.gz"); got != "https://example.com/flag.tar.gz" {
+		t.Errorf("templateSource() = %q, want --template", got)
+	}
+	writeFile(t, filepath.Join(project, ".claude", ".claude-workspace-lock.json"), `{"version": "1.0.0", "files": {}}`)
+	if got := templateSource(project, ""); got != "" {
+		t.Errorf("templateSource() = %q, want the embedded assets recorded in the lock file", got)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantTarget string
+		wantOpts   options
+		wantErr    bool
+	}{
+		{[]string{"/p"}, "/p", options{}, false},
+		{[]string{"--force", "/p", "--profile=backend"}, "/p", options{force: true, profile: "backend"}, false},
+		{[]string{"/p", "--template", "gh:org/repo", "--template-sha256=abc", "--keep-claude-md"}, "/p",
+			options{keepClaudeMd: true, template: "gh:org/repo", templateSHA256: "abc"}, false},
+		{nil, "", options{}, true},
+		{[]string{"/p", "--keep-claudemd"}, "", options{}, true},
+		{[]string{"/p", "/q"}, "", options{}, true},
+	}
+	for _, tt := range tests {
+		target, opts, err := parseArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (target != tt.wantTarget || opts != tt.wantOpts) {
+			t.Errorf("parseArgs(%q) = %q, %+v; want %q, %+v", tt.args, target, opts, tt.wantTarget, tt.wantOpts)
+		}
+	}
+}
@@ -1,113 +1,340 @@
 // Package doctor implements the "doctor" command, which performs health checks
 // on the platform configuration including CLI tools, global settings, project
-// setup, agents, skills, hooks, MCP servers, and authentication.
+// setup, org policy, agents, skills, hooks, MCP servers, and authentication.
 package doctor
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/lamchakchan/claude-workspace/internal/agents"
+	"github.com/lamchakchan/claude-workspace/internal/attach"
+	"github.com/lamchakchan/claude-workspace/internal/memory"
+	"github.com/lamchakchan/claude-workspace/internal/orgpolicy"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 	"github.com/lamchakchan/claude-workspace/internal/setup"
 	"github.com/lamchakchan/claude-workspace/internal/tools"
 	"github.com/lamchakchan/claude-workspace/internal/upgrade"
 )
 
-// Run executes the doctor command, printing to os.Stdout.
-func Run() error {
-	return RunTo(os.Stdout)
+// Run executes the doctor command, printing to os.Stdout. With --fix, safe
+// remediations for failed checks are applied afterwards; --dry-run lists them
+// without making changes. With --json, a Report is printed instead of text and
+// ErrUnhealthy is returned when any check fails. --offline skips the checks
+// that reach MCP servers and the npm registry, and --timeout bounds how long
+// the checks may take in total.
+func Run(args []string) error {
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		report, err := check(opts)
+		if err != nil {
+			return err
+		}
+		if err := writeJSON(os.Stdout, report); err != nil {
+			return err
+		}
+		notifyRegressions(report)
+		if !report.Healthy {
+			return ErrUnhealthy
+		}
+		return nil
+	}
+	return run(os.Stdout, bufio.NewReader(os.Stdin), opts)
 }
 
 // RunTo executes the doctor command, writing all output to w.
 func RunTo(w io.Writer) error {
-	platform.PrintBanner(w, "Claude Platform Health Check")
+	return run(w, nil, options{})
+}
 
-	issues := 0
-	warnings := 0
+// Check runs every health check without printing and returns the results.
+func Check() (*Report, error) {
+	return check(options{})
+}
 
-	home, err := os.UserHomeDir()
+func check(opts options) (*Report, error) {
+	c := &checker{w: io.Discard, offline: opts.offline}
+	if err := runChecks(c, opts); err != nil {
+		return nil, err
+	}
+	return c.report(), nil
+}
+
+func writeJSON(w io.Writer, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
-		return fmt.Errorf("getting home directory: %w", err)
+		return err
 	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
 
-	cwd, _ := os.Getwd()
+// run performs the checks and, when opts.fix is set, applies the collected
+// remedies. Remedies that need confirmation read answers from in.
+func run(w io.Writer, in *bufio.Reader, opts options) error {
+	platform.PrintBanner(w, "Claude Platform Health Check")
 
-	i, wa := checkClaudeCLI(w, home)
-	issues += i
-	warnings += wa
+	c := &checker{w: w, offline: opts.offline}
+	if err := runChecks(c, opts); err != nil {
+		return err
+	}
+	report := c.report()
+	notifyRegressions(report)
 
-	i, wa = checkClaudeWorkspace(w)
-	issues += i
-	warnings += wa
+	// Summary
+	platform.PrintBanner(w, "Summary")
+	if report.Issues == 0 && report.Warnings == 0 {
+		fmt.Fprintln(w, platform.BoldGreen("All checks passed. Platform is healthy."))
+	} else {
+		if report.Issues > 0 {
+			fmt.Fprintln(w, platform.Red(fmt.Sprintf("Issues: %d (must fix)", report.Issues)))
+		}
+		if report.Warnings > 0 {
+			fmt.Fprintln(w, platform.Yellow(fmt.Sprintf("Warnings: %d (optional)", report.Warnings)))
+		}
+	}
+	fmt.Fprintln(w)
 
-	i, wa = checkGit(w)
-	issues += i
-	warnings += wa
+	switch {
+	case opts.fix:
+		applied, failed := applyFixes(w, in, c.fixes, opts.dryRun)
+		if applied > 0 {
+			fmt.Fprintln(w, "  Run 'claude-workspace doctor' again to verify.")
+			fmt.Fprintln(w)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d fix(es) failed", failed)
+		}
+	case len(c.fixes) > 0:
+		platform.PrintInfo(w, fmt.Sprintf("%d issue(s) can be fixed automatically. Run: claude-workspace doctor --fix", len(c.fixes)))
+		fmt.Fprintln(w)
+	}
 
-	i, wa = checkNode(w)
-	issues += i
-	warnings += wa
+	return nil
+}
 
-	i, wa = checkGlobalConfig(w, home)
-	issues += i
-	warnings += wa
+// defaultTimeout is how long the checks may take in total without --timeout.
+const defaultTimeout = 10 * time.Second
 
-	i, wa = checkProjectConfig(w, cwd)
-	issues += i
-	warnings += wa
+// checkTimeout bounds each section of checks that does not use the network.
+// They run local commands such as "claude --version", which should answer
+// well within it.
+const checkTimeout = 5 * time.Second
 
-	i, wa = checkAgents(w, cwd)
-	issues += i
-	warnings += wa
+// checkGroup is a section of checks that runs concurrently with the others.
+type checkGroup struct {
+	section string // section name, reported if the checks time out
+	network bool   // reaches the network, so only the overall budget bounds it
+	run     func(c *checker)
+}
 
-	i, wa = checkSkills(w, cwd)
-	issues += i
-	warnings += wa
+// runChecks runs every health check, recording results on c in section
+// order. Sections run concurrently and each is printed as it finishes;
+// opts.timeout (defaultTimeout when 0) bounds the whole run. The project
+// sections run once for each project in opts, or for the current directory.
+func runChecks(c *checker, opts options) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	projects, err := resolveProjects(opts, home)
+	if err != nil {
+		return err
+	}
 
-	i, wa = checkHooks(w, cwd)
-	issues += i
-	warnings += wa
+	groups := []checkGroup{
+		{"Claude Code CLI", false, func(c *checker) { checkClaudeCLI(c, home) }},
+		{"claude-workspace CLI", true, checkClaudeWorkspace},
+		{"Template Overrides", false, func(c *checker) {
+			checkTemplateOverrides(c, platform.AppliedOverridesDir(), platform.ListOverrides())
+		}},
+		{"Git", false, checkGit},
+		{"Node.js", false, checkNode},
+		{"Global Configuration", false, func(c *checker) { checkGlobalConfig(c, home) }},
+		{"Org Policy", false, checkOrgPolicy},
+	}
+	for _, p := range projects {
+		groups = append(groups, projectGroups(p.dir, p.label)...)
+	}
+	groups = append(groups,
+		checkGroup{"Memory Provider", false, func(c *checker) { checkMemoryProvider(c, memory.ConfiguredProvider(home), tools.Engram()) }},
+		checkGroup{"Authentication", false, func(c *checker) { checkAuth(c, home) }},
+	)
+	runGroups(c, groups, opts.timeout)
+	return nil
+}
 
-	i, wa = checkHookConfig(w, cwd)
-	issues += i
-	warnings += wa
+// projectGroups returns the sections of checks that inspect the project in
+// dir. A non-empty label names the project in section headings and results,
+// for when doctor checks projects other than the current directory.
+func projectGroups(dir, label string) []checkGroup {
+	in := func(check func(c *checker, dir string)) func(c *checker) {
+		return func(c *checker) {
+			c.project = label
+			check(c, dir)
+		}
+	}
+	section := func(name string) string {
+		if label == "" {
+			return name
+		}
+		return name + " (" + label + ")"
+	}
+	home, _ := os.UserHomeDir()
+	return []checkGroup{
+		{section("Project Configuration"), false, in(checkProjectConfig)},
+		{section("Agents"), false, in(func(c *checker, dir string) { checkAgents(c, dir, home) })},
+		{section("Skills"), false, in(checkSkills)},
+		{section("Hooks"), false, in(checkHooks)},
+		{section("Hook Configuration"), false, in(checkHookConfig)},
+		{section("MCP Servers"), true, in(checkMCPServers)},
+	}
+}
 
-	i, wa = checkMCPServers(w, cwd)
-	issues += i
-	warnings += wa
+// project is a directory whose project checks run, with the label that
+// names it in the output ("" for the implicit current directory).
+type project struct {
+	dir, label string
+}
 
-	i, wa = checkAuth(w, home)
-	issues += i
-	warnings += wa
+// resolveProjects returns the projects opts asks for: the paths given and,
+// with --all, every attached project recorded in ~/.claude.json. Without
+// either it is the current directory, unlabeled.
+func resolveProjects(opts options, home string) ([]project, error) {
+	paths := append([]string(nil), opts.projects...)
+	if opts.all {
+		known, err := attachedProjects(filepath.Join(home, ".claude.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(known) == 0 && len(paths) == 0 {
+			return nil, fmt.Errorf("--all: no attached projects are recorded in ~/.claude.json")
+		}
+		paths = append(paths, known...)
+	}
+	if len(paths) == 0 {
+		cwd, _ := os.Getwd()
+		return []project{{dir: cwd}}, nil
+	}
+	var projects []project
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		dir, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("project is not a directory: %s", p)
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			projects = append(projects, project{dir: dir, label: shortenHome(dir, home)})
+		}
+	}
+	return projects, nil
+}
 
-	// Summary
-	platform.PrintBanner(w, "Summary")
-	if issues == 0 && warnings == 0 {
-		fmt.Fprintln(w, platform.BoldGreen("All checks passed. Platform is healthy."))
-	} else {
-		if issues > 0 {
-			fmt.Fprintln(w, platform.Red(fmt.Sprintf("Issues: %d (must fix)", issues)))
+// attachedProjects returns the projects recorded in the Claude Code config
+// at path that still exist and have a .claude directory, sorted.
+func attachedProjects(path string) ([]string, error) {
+	if !platform.FileExists(path) {
+		return nil, nil
+	}
+	var root struct {
+		Projects map[string]json.RawMessage `json:"projects"`
+	}
+	if err := platform.ReadJSONFile(path, &root); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var projects []string
+	for p := range root.Projects {
+		if filepath.IsAbs(p) && platform.FileExists(filepath.Join(p, ".claude")) {
+			projects = append(projects, filepath.Clean(p))
 		}
-		if warnings > 0 {
-			fmt.Fprintln(w, platform.Yellow(fmt.Sprintf("Warnings: %d (optional)", warnings)))
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// shortenHome replaces a leading home directory in path with "~".
+func shortenHome(path, home string) string {
+	if rel, err := filepath.Rel(home, path); err == nil && home != "" && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// runGroups runs each group on its own checker, copying its output to c.w as
+// soon as it finishes and its results to c in group order. A group that takes
+// longer than its timeout is abandoned and reported as a warning.
+func runGroups(c *checker, groups []checkGroup, budget time.Duration) {
+	if budget <= 0 {
+		budget = defaultTimeout
+	}
+	type finished struct {
+		i   int
+		sub *checker
+		out *bytes.Buffer
+	}
+	done := make(chan finished, len(groups))
+	for i, g := range groups {
+		limit := budget
+		if !g.network {
+			limit = min(checkTimeout, budget)
 		}
+		go func() {
+			out := &bytes.Buffer{}
+			sub := &checker{w: out, offline: c.offline}
+			ran := make(chan struct{})
+			go func() {
+				g.run(sub)
+				close(ran)
+			}()
+			select {
+			case <-ran:
+				done <- finished{i, sub, out}
+			case <-time.After(limit):
+				// The checks keep running, so report on a fresh checker.
+				timeoutOut := &bytes.Buffer{}
+				timedOut := &checker{w: timeoutOut, offline: c.offline}
+				timedOut.begin(g.section)
+				remediation := "Re-run with a larger --timeout"
+				if g.network {
+					remediation += ", or with --offline to skip network checks"
+				}
+				timedOut.warn("timeout", fmt.Sprintf("No result after %s; checks skipped", limit), remediation)
+				done <- finished{i, timedOut, timeoutOut}
+			}
+		}()
 	}
-	fmt.Fprintln(w)
 
-	return nil
+	subs := make([]*checker, len(groups))
+	for range groups {
+		f := <-done
+		c.w.Write(f.out.Bytes())
+		subs[f.i] = f.sub
+	}
+	for _, sub := range subs {
+		c.results = append(c.results, sub.results...)
+		c.fixes = append(c.fixes, sub.fixes...)
+	}
 }
 
 // checkClaudeCLI verifies the Claude Code CLI is installed and checks for npm shadow installs.
-func checkClaudeCLI(w io.Writer, home string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Claude Code CLI")
+func checkClaudeCLI(c *checker, home string) {
+	c.begin("Claude Code CLI")
 	claudeBin := "claude"
 	if !platform.Exists(claudeBin) {
 		// The official installer places claude in ~/.local/bin which may not be in PATH
@@ -117,210 +344,343 @@ func checkClaudeCLI(w io.Writer, home string) (int, int) {
 		}
 	}
 	if ver, err := platform.Output(claudeBin, "--version"); err == nil {
-		pass(w, "Installed: "+ver)
+		c.pass("claude-cli", "Installed: "+ver)
 		// Check if installed via npm (may shadow official binary)
 		npmInfo := setup.DetectNpmClaude()
 		if npmInfo.Detected {
-			warn(w, "Claude Code is installed via npm (@anthropic-ai/claude-code)")
-			fmt.Fprintln(w, "    The npm version may shadow the official binary in PATH.")
-			fmt.Fprintln(w, "    Fix: npm uninstall -g @anthropic-ai/claude-code")
-			warnings++
+			c.warn("claude-npm-install", "Claude Code is installed via npm (@anthropic-ai/claude-code)",
+				"The npm version may shadow the official binary in PATH.\nFix: npm uninstall -g @anthropic-ai/claude-code")
 		}
 	} else {
-		fail(w, "Claude Code CLI not found")
-		fmt.Fprintln(w, "    Install: curl -fsSL https://claude.ai/install.sh | bash")
-		issues++
+		c.fail("claude-cli", "Claude Code CLI not found", "Install: "+tools.ClaudeInstallCmd, installClaudeRemedy())
 	}
-
-	return issues, warnings
 }
 
 // checkClaudeWorkspace verifies the claude-workspace binary is in PATH and checks for updates.
-func checkClaudeWorkspace(w io.Writer) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "claude-workspace CLI")
+func checkClaudeWorkspace(c *checker) {
+	c.begin("claude-workspace CLI")
 	if platform.Exists("claude-workspace") {
-		pass(w, "claude-workspace is in PATH")
+		c.pass("claude-workspace", "claude-workspace is in PATH")
 	} else {
-		warn(w, "claude-workspace not found in PATH")
-		fmt.Fprintln(w, "    Run: claude-workspace setup")
-		warnings++
+		c.warn("claude-workspace", "claude-workspace not found in PATH", "Run: claude-workspace setup")
 	}
 
 	// Soft update check (3s timeout, skip on failure)
-	checkForUpdate(w)
+	checkForUpdate(c)
+}
 
-	return issues, warnings
+// checkTemplateOverrides reports the template overrides directory in effect
+// (dir, or "" when none is applied) and which assets come from it. The section
+// is omitted when no overrides are configured.
+func checkTemplateOverrides(c *checker, dir string, files []platform.OverrideFile) {
+	envDir := os.Getenv(platform.OverridesEnv)
+	if dir == "" && envDir == "" {
+		return
+	}
+	c.begin("Template Overrides")
+	if dir == "" {
+		c.warn("overrides", fmt.Sprintf("%s points to a missing directory: %s", platform.OverridesEnv, envDir), "")
+		return
+	}
+	if len(files) == 0 {
+		c.warn("overrides", "No files under project/ or global/ in "+dir,
+			"Overrides mirror the embedded template, e.g. "+filepath.Join(dir, "project", ".claude", "agents", "<name>.md"))
+		return
+	}
+	c.pass("overrides", fmt.Sprintf("Using %d override(s) from %s", len(files), dir))
+	for _, f := range files {
+		name := f.Layer + "/" + f.Path
+		if strings.HasSuffix(f.Path, ".json") {
+			data, err := os.ReadFile(filepath.Join(dir, f.Layer, filepath.FromSlash(f.Path)))
+			if err != nil || !json.Valid(data) {
+				c.fail("override:"+name, "Invalid JSON in override: "+name, "")
+				continue
+			}
+		}
+		if f.Replaces {
+			c.info("override:"+name, "Replaces embedded: "+name, "")
+		} else {
+			c.info("override:"+name, "Adds: "+name, "")
+		}
+	}
 }
 
 // checkGit verifies Git is installed.
-func checkGit(w io.Writer) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Git")
+func checkGit(c *checker) {
+	c.begin("Git")
 	if ver, err := platform.Output("git", "--version"); err == nil {
-		pass(w, ver)
+		c.pass("git", ver)
 	} else {
-		fail(w, "Git not found")
-		issues++
+		c.fail("git", "Git not found", "")
 	}
-
-	return issues, warnings
 }
 
 // checkNode verifies Node.js is installed and meets minimum version requirements.
-func checkNode(w io.Writer) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Node.js")
+func checkNode(c *checker) {
+	c.begin("Node.js")
 	nodeTool := tools.Node()
 	switch {
 	case nodeTool.IsInstalled():
 		if ver, err := platform.Output("node", "--version"); err == nil {
-			pass(w, "Node.js: "+ver)
+			c.pass("node", "Node.js: "+ver)
 		} else {
-			pass(w, "Node.js: installed")
+			c.pass("node", "Node.js: installed")
 		}
 		if platform.Exists("npx") {
-			pass(w, "npx: available")
+			c.pass("npx", "npx: available")
 		} else {
-			warn(w, "npx not found (required for MCP servers)")
-			warnings++
+			c.warn("npx", "npx not found (required for MCP servers)", "")
 		}
 	case platform.Exists("node"):
 		// node exists but doesn't meet minimum version
 		ver, _ := platform.Output("node", "--version")
-		warn(w, fmt.Sprintf("Node.js %s is below minimum version (v%d+)", ver, tools.NodeMinMajor))
-		fmt.Fprintln(w, "    Upgrade Node.js: https://nodejs.org")
-		warnings++
+		c.warn("node", fmt.Sprintf("Node.js %s is below minimum version (v%d+)", ver, tools.NodeMinMajor),
+			"Upgrade Node.js: https://nodejs.org")
 	default:
-		fail(w, "Node.js not found (required for MCP servers)")
-		fmt.Fprintln(w, "    Install: https://nodejs.org or run: claude-workspace setup")
-		issues++
+		c.fail("node", "Node.js not found (required for MCP servers)", "Install: https://nodejs.org or run: claude-workspace setup")
 	}
+}
 
-	return issues, warnings
+// checkMemoryProvider reports the configured memory MCP provider and, when it
+// is engram or engram is installed, that engram is recent enough for the
+// memory commands.
+func checkMemoryProvider(c *checker, provider string, engram tools.Tool) {
+	c.begin("Memory Provider")
+	if provider == "none" {
+		c.info("memory-provider", "No memory MCP provider configured", "Choose one with: claude-workspace memory configure")
+	} else {
+		c.pass("memory-provider", "Memory provider: "+provider)
+	}
+	installed := platform.Exists(engram.Name)
+	if provider != "engram" && !installed {
+		return
+	}
+	ver, err := engram.Version()
+	switch {
+	case !installed:
+		c.fail("engram", "engram is the memory provider but is not installed",
+			"Run: claude-workspace memory configure --provider engram, or: "+engram.InstallHint())
+	case err != nil:
+		c.warn("engram", fmt.Sprintf("Could not determine the engram version: %v", err), "")
+	case !tools.EngramCompatible(ver):
+		c.warn("engram", fmt.Sprintf("engram %s is older than the supported %s (memory export, import and --migrate may fail)", ver, tools.EngramMinVersion),
+			"Run: claude-workspace setup --tools engram")
+	default:
+		c.pass("engram", "engram: "+ver)
+	}
 }
 
 // checkGlobalConfig verifies global settings.json and CLAUDE.md exist and are valid.
-func checkGlobalConfig(w io.Writer, home string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Global Configuration")
+func checkGlobalConfig(c *checker, home string) {
+	c.begin("Global Configuration")
 
 	globalSettingsPath := filepath.Join(home, ".claude", "settings.json")
 	if platform.FileExists(globalSettingsPath) {
-		pass(w, "~/.claude/settings.json exists")
-		var settings map[string]json.RawMessage
+		c.pass("global-settings", "~/.claude/settings.json exists")
+		var settings map[string]interface{}
 		if err := platform.ReadJSONFile(globalSettingsPath, &settings); err == nil {
-			var env map[string]string
-			if raw, ok := settings["env"]; ok {
-				if json.Unmarshal(raw, &env) == nil {
-					if model, ok := env["CLAUDE_CODE_SUBAGENT_MODEL"]; ok {
-						pass(w, "Subagent model: "+model)
-					}
-					if env["CLAUDE_CODE_EXPERIMENTAL_AGENT_TEAMS"] == "1" {
-						pass(w, "Agent teams: enabled")
-					}
+			if env, ok := settings["env"].(map[string]interface{}); ok {
+				if model, ok := env["CLAUDE_CODE_SUBAGENT_MODEL"].(string); ok {
+					c.pass("subagent-model", "Subagent model: "+model)
+				}
+				if env["CLAUDE_CODE_EXPERIMENTAL_AGENT_TEAMS"] == "1" {
+					c.pass("agent-teams", "Agent teams: enabled")
 				}
 			}
+			if needsSettingsMerge(settings, setup.GetDefaultGlobalSettings()) {
+				c.warn("global-settings-defaults", "Global settings are missing platform defaults. Run 'claude-workspace setup'", "",
+					globalSettingsRemedy(globalSettingsPath))
+			}
 		} else {
-			warn(w, "Could not parse global settings")
-			warnings++
+			c.warn("global-settings-parse", "Could not parse global settings", "")
 		}
 	} else {
-		warn(w, "~/.claude/settings.json not found. Run 'claude-workspace setup'")
-		warnings++
+		c.warn("global-settings", "~/.claude/settings.json not found. Run 'claude-workspace setup'", "",
+			globalSettingsRemedy(globalSettingsPath))
 	}
 
 	globalClaudeMd := filepath.Join(home, ".claude", "CLAUDE.md")
 	if platform.FileExists(globalClaudeMd) {
-		pass(w, "~/.claude/CLAUDE.md exists")
+		c.pass("global-claude-md", "~/.claude/CLAUDE.md exists")
 	} else {
-		warn(w, "~/.claude/CLAUDE.md not found")
-		warnings++
+		c.warn("global-claude-md", "~/.claude/CLAUDE.md not found", "")
+	}
+}
+
+// checkOrgPolicy verifies that the settings required by the organization
+// policy are still in ~/.claude/settings.json and that the policy applied is
+// the latest version. It is skipped when no policy is configured.
+func checkOrgPolicy(c *checker) {
+	cfg, err := orgpolicy.Load()
+	if err != nil {
+		c.begin("Org Policy")
+		c.warn("org-policy", fmt.Sprintf("Could not read the org policy configuration: %v", err), "")
+		return
+	}
+	if cfg == nil {
+		return
+	}
+	c.begin("Org Policy")
+
+	drift, err := orgpolicy.Drift(cfg)
+	switch {
+	case err != nil:
+		c.warn("org-policy", fmt.Sprintf("Could not check org policy %s: %v", cfg.Label(), err), "")
+	case len(drift) > 0:
+		c.fail("org-policy", fmt.Sprintf("%d setting(s) required by org policy %s were removed or changed", len(drift), cfg.Label()),
+			strings.Join(drift, "\n")+"\nRun: claude-workspace policy org sync", orgPolicyRemedy())
+	default:
+		c.pass("org-policy", fmt.Sprintf("Org policy %s enforced (synced %s)", cfg.Label(), cfg.SyncedAt))
 	}
 
-	return issues, warnings
+	current, _, err := orgpolicy.CheckLatest(cfg)
+	switch {
+	case errors.Is(err, orgpolicy.ErrSignatureInvalid):
+		c.fail("org-policy-signature", err.Error(), "The policy may have been tampered with; contact your platform team.")
+	case err != nil:
+		c.warn("org-policy-fetch", fmt.Sprintf("Could not fetch the latest org policy: %v", err), "")
+	case !current:
+		c.warn("org-policy-update", "A newer version of the org policy is available", "Run: claude-workspace policy org sync", orgPolicyRemedy())
+	}
 }
 
 // checkProjectConfig runs table-driven checks for expected project configuration files.
-func checkProjectConfig(w io.Writer, cwd string) (int, int) {
-	issues := 0
-	warnings := 0
+func checkProjectConfig(c *checker, cwd string) {
+	c.begin("Project Configuration")
 
-	platform.PrintSectionLabel(w, "Project Configuration")
+	// Fixes are only offered for projects that already have a .claude directory;
+	// anything else needs a full "claude-workspace attach".
+	attached := platform.FileExists(filepath.Join(cwd, ".claude"))
 
 	checks := []struct {
 		path     string
 		label    string
 		required bool
+		fix      func(path string) remedy
 	}{
-		{".claude/settings.json", "Project settings", true},
-		{".claude/CLAUDE.md", "Project CLAUDE.md", true},
-		{".mcp.json", "MCP configuration", false},
-		{".claude/agents", "Agents directory", false},
-		{".claude/skills", "Skills directory", false},
-		{".claude/hooks", "Hooks directory", false},
-		{"plans", "Plans directory", false},
+		{".claude/settings.json", "Project settings", true, projectSettingsRemedy},
+		{".claude/CLAUDE.md", "Project CLAUDE.md", true, nil},
+		{".mcp.json", "MCP configuration", false, nil},
+		{".claude/agents", "Agents directory", false, mkdirRemedy},
+		{".claude/skills", "Skills directory", false, mkdirRemedy},
+		{".claude/hooks", "Hooks directory", false, mkdirRemedy},
+		{"plans", "Plans directory", false, nil},
 	}
 
 	for _, check := range checks {
 		fullPath := filepath.Join(cwd, check.path)
-		switch {
-		case platform.FileExists(fullPath):
-			pass(w, check.label+": "+check.path)
-		case check.required:
-			fail(w, check.label+" not found: "+check.path)
-			issues++
-		default:
-			warn(w, check.label+" not found: "+check.path)
-			warnings++
+		name := "project:" + check.path
+		if platform.FileExists(fullPath) {
+			c.pass(name, check.label+": "+check.path)
+			continue
+		}
+		var fixes []remedy
+		if attached && check.fix != nil {
+			fixes = append(fixes, check.fix(fullPath))
+		}
+		if check.required {
+			c.fail(name, check.label+" not found: "+check.path, "", fixes...)
+		} else {
+			c.warn(name, check.label+" not found: "+check.path, "", fixes...)
+		}
+	}
+
+	if attached {
+		checkGitignore(c, filepath.Join(cwd, ".claude", ".gitignore"))
+		if lock, err := attach.ReadLock(cwd); err != nil {
+			c.warn("platform-version", fmt.Sprintf("Could not read %s: %v", attach.LockFile, err), "")
+		} else if lock != nil {
+			checkPlatformVersion(c, lock, installedVersion())
 		}
 	}
+}
 
-	return issues, warnings
+// checkPlatformVersion reports the claude-workspace release the project's
+// assets come from, and warns when installed, the installed release, is newer.
+func checkPlatformVersion(c *checker, lock *attach.Lock, installed string) {
+	from := "from"
+	if lock.Pinned {
+		from = "pinned to"
+	}
+	// A dev build on either side has no release to compare.
+	known := installed != "" && installed != "dev" && lock.Version != "" && lock.Version != "dev"
+	behind := known && upgrade.UpdateAvailable(lock.Version, installed)
+	switch {
+	case behind && lock.Pinned:
+		c.warn("platform-version", fmt.Sprintf("Project assets are pinned to claude-workspace %s; %s is installed", lock.Version, installed),
+			"Review with: claude-workspace attach . --check, then run: claude-workspace attach . --upgrade-assets")
+	case behind:
+		c.warn("platform-version", fmt.Sprintf("Project assets are from claude-workspace %s; %s is installed", lock.Version, installed),
+			"Run: claude-workspace attach . --reconcile")
+	default:
+		c.pass("platform-version", fmt.Sprintf("Project assets %s claude-workspace %s", from, lock.Version))
+	}
 }
 
-// checkAgents scans the agents directory for .md agent definition files.
-func checkAgents(w io.Writer, cwd string) (int, int) {
-	issues := 0
-	warnings := 0
+// checkGitignore verifies .claude/.gitignore contains the template's entries.
+func checkGitignore(c *checker, path string) {
+	if platform.HasDenyAllPattern(path) {
+		c.pass("gitignore", ".claude/.gitignore ignores everything by default")
+		return
+	}
+	data, err := platform.ReadAsset(".claude/.gitignore")
+	if err != nil {
+		return
+	}
+	missing, err := platform.MissingGitignoreEntries(path, string(data))
+	switch {
+	case err != nil:
+		c.warn("gitignore", "Could not read .claude/.gitignore", "")
+	case len(missing) > 0:
+		c.warn("gitignore", fmt.Sprintf(".claude/.gitignore is missing entries: %s", strings.Join(missing, ", ")), "",
+			gitignoreRemedy(path, string(data)))
+	default:
+		c.pass("gitignore", ".claude/.gitignore is up to date")
+	}
+}
 
-	platform.PrintSectionLabel(w, "Agents")
+// checkAgents validates the agent definitions in the agents directory and
+// reports project agents that override user agents in home.
+func checkAgents(c *checker, cwd, home string) {
+	c.begin("Agents")
 	agentsDir := filepath.Join(cwd, ".claude", "agents")
-	if platform.FileExists(agentsDir) {
-		entries, err := os.ReadDir(agentsDir)
-		if err == nil {
-			var agents []string
-			for _, e := range entries {
-				if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
-					agents = append(agents, strings.TrimSuffix(e.Name(), ".md"))
-				}
-			}
-			if len(agents) > 0 {
-				pass(w, fmt.Sprintf("Found %d agents: %s", len(agents), strings.Join(agents, ", ")))
-			} else {
-				warn(w, "No agent definitions found")
-				warnings++
-			}
-		}
+	if !platform.FileExists(agentsDir) {
+		return
 	}
+	projectAgents := agents.DiscoverAgents(agentsDir)
+	if len(projectAgents) == 0 {
+		c.warn("agents", "No agent definitions found", "")
+		return
+	}
+	names := make([]string, 0, len(projectAgents))
+	for _, a := range projectAgents {
+		names = append(names, a.Name)
+	}
+	c.pass("agents", fmt.Sprintf("Found %d agents: %s", len(projectAgents), strings.Join(names, ", ")))
 
-	return issues, warnings
+	var userAgents []agents.Agent
+	if userDir := filepath.Join(home, ".claude", "agents"); platform.FileExists(userDir) {
+		userAgents = agents.DiscoverAgents(userDir)
+	}
+	var issues []agents.Issue
+	for _, a := range projectAgents {
+		issues = append(issues, agents.Validate(a.Path)...)
+	}
+	issues = append(issues, agents.Duplicates(agents.Effective(projectAgents, userAgents))...)
+	for _, i := range issues {
+		check := "agent:" + filepath.Base(i.Path)
+		msg := filepath.Base(i.Path) + ": " + i.Message
+		if i.Severity == agents.SeverityError {
+			c.fail(check, msg, "Run: claude-workspace agents validate")
+		} else {
+			c.warn(check, msg, "")
+		}
+	}
 }
 
 // checkSkills walks the skills directory for SKILL.md definition files.
-func checkSkills(w io.Writer, cwd string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Skills")
+func checkSkills(c *checker, cwd string) {
+	c.begin("Skills")
 	skillsDir := filepath.Join(cwd, ".claude", "skills")
 	if platform.FileExists(skillsDir) {
 		var skills []string
@@ -335,130 +695,99 @@ func checkSkills(w io.Writer, cwd string) (int, int) {
 			return nil
 		})
 		if len(skills) > 0 {
-			pass(w, fmt.Sprintf("Found %d skills: %s", len(skills), strings.Join(skills, ", ")))
+			c.pass("skills", fmt.Sprintf("Found %d skills: %s", len(skills), strings.Join(skills, ", ")))
 		} else {
-			warn(w, "No skill definitions found")
-			warnings++
+			c.warn("skills", "No skill definitions found", "")
 		}
 	}
-
-	return issues, warnings
 }
 
-// checkHooks verifies hook shell scripts in the hooks directory are executable.
-func checkHooks(w io.Writer, cwd string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Hooks")
+// checkHooks verifies hook shell scripts in the hooks directory are executable,
+// lints them with shellcheck when it is installed, and checks that the tools
+// they depend on are available.
+func checkHooks(c *checker, cwd string) {
+	c.begin("Hooks")
 	hooksDir := filepath.Join(cwd, ".claude", "hooks")
-	if platform.FileExists(hooksDir) {
-		entries, err := os.ReadDir(hooksDir)
-		if err == nil {
-			for _, e := range entries {
-				if !e.IsDir() && strings.HasSuffix(e.Name(), ".sh") {
-					hookPath := filepath.Join(hooksDir, e.Name())
-					if platform.IsExecutable(hookPath) {
-						pass(w, e.Name()+": executable")
-					} else {
-						fail(w, fmt.Sprintf("%s: not executable. Run: chmod +x %s", e.Name(), hookPath))
-						issues++
-					}
-				}
-			}
+	if !platform.FileExists(hooksDir) {
+		return
+	}
+	entries, err := os.ReadDir(hooksDir)
+	if err != nil {
+		return
+	}
+	var scripts []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sh") {
+			scripts = append(scripts, e.Name())
 		}
 	}
-
-	return issues, warnings
-}
-
-// checkHookConfig validates that settings.json hook references are parseable.
-func checkHookConfig(w io.Writer, cwd string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Hook Configuration")
-	settingsPath := filepath.Join(cwd, ".claude", "settings.json")
-	if platform.FileExists(settingsPath) {
-		var settings map[string]json.RawMessage
-		if err := platform.ReadJSONFile(settingsPath, &settings); err == nil {
-			if raw, ok := settings["hooks"]; ok {
-				var hooks map[string]json.RawMessage
-				if json.Unmarshal(raw, &hooks) == nil {
-					hookCount := countHookCommands(hooks)
-					pass(w, fmt.Sprintf("%d hook commands configured", hookCount))
-				}
-			}
+	lint := platform.Exists("shellcheck")
+	for _, name := range scripts {
+		hookPath := filepath.Join(hooksDir, name)
+		if platform.IsExecutable(hookPath) {
+			c.pass("hook:"+name, name+": executable")
 		} else {
-			warn(w, "Could not validate hook configuration")
-			warnings++
+			c.fail("hook:"+name, name+": not executable", "Run: chmod +x "+hookPath, chmodRemedy(hookPath))
+		}
+		if lint {
+			lintHook(c, hooksDir, name)
 		}
+		checkHookDeps(c, hookPath, name)
+	}
+	if len(scripts) > 0 && !lint {
+		sc := tools.Shellcheck()
+		c.info("hook-lint", "shellcheck not installed; hook scripts were not linted", "Install: "+sc.InstallHint())
 	}
-
-	return issues, warnings
 }
 
-// checkMCPServers validates the .mcp.json configuration file.
-func checkMCPServers(w io.Writer, cwd string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "MCP Servers")
-	mcpPath := filepath.Join(cwd, ".mcp.json")
-	if platform.FileExists(mcpPath) {
-		var mcpConfig struct {
-			MCPServers map[string]json.RawMessage `json:"mcpServers"`
-		}
-		if err := platform.ReadJSONFile(mcpPath, &mcpConfig); err == nil {
-			servers := make([]string, 0, len(mcpConfig.MCPServers))
-			for name := range mcpConfig.MCPServers {
-				servers = append(servers, name)
-			}
-			if len(servers) > 0 {
-				pass(w, fmt.Sprintf("%d MCP servers configured: %s", len(servers), strings.Join(servers, ", ")))
-			} else {
-				warn(w, "No MCP servers configured in .mcp.json")
-				warnings++
-			}
-		} else {
-			fail(w, "Could not parse .mcp.json")
-			issues++
-		}
+// checkHookConfig validates the settings.json hook configuration: events,
+// matchers, and the commands each hook runs.
+func checkHookConfig(c *checker, cwd string) {
+	c.begin("Hook Configuration")
+	settingsPath := filepath.Join(cwd, ".claude", "settings.json")
+	if !platform.FileExists(settingsPath) {
+		return
 	}
-
-	return issues, warnings
+	var settings map[string]json.RawMessage
+	if err := platform.ReadJSONFile(settingsPath, &settings); err != nil {
+		c.warn("hook-config", "Could not validate hook configuration", "")
+		return
+	}
+	raw, ok := settings["hooks"]
+	if !ok {
+		return
+	}
+	var events map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &events); err != nil {
+		c.fail("hook-config", `settings.json "hooks" must be an object keyed by event name`, "")
+		return
+	}
+	c.pass("hook-config", fmt.Sprintf("%d hook commands configured", countHookCommands(events)))
+	checkHookEntries(c, cwd, events)
 }
 
 // checkAuth verifies API key or OAuth authentication is configured.
-func checkAuth(w io.Writer, home string) (int, int) {
-	issues := 0
-	warnings := 0
-
-	platform.PrintSectionLabel(w, "Authentication")
+func checkAuth(c *checker, home string) {
+	c.begin("Authentication")
 	if os.Getenv("ANTHROPIC_API_KEY") != "" {
-		pass(w, "ANTHROPIC_API_KEY is set")
+		c.pass("auth", "ANTHROPIC_API_KEY is set")
+		return
+	}
+	claudeConfig := filepath.Join(home, ".claude.json")
+	if !platform.FileExists(claudeConfig) {
+		c.warn("auth", "No authentication configured. Run: claude-workspace setup", "")
+		return
+	}
+	var config map[string]json.RawMessage
+	if err := platform.ReadJSONFile(claudeConfig, &config); err != nil {
+		c.warn("auth", "Could not read authentication config", "")
+		return
+	}
+	if _, ok := config["oauthAccount"]; ok {
+		c.pass("auth", "OAuth authentication configured")
 	} else {
-		claudeConfig := filepath.Join(home, ".claude.json")
-		if platform.FileExists(claudeConfig) {
-			var config map[string]json.RawMessage
-			if err := platform.ReadJSONFile(claudeConfig, &config); err == nil {
-				if _, ok := config["oauthAccount"]; ok {
-					pass(w, "OAuth authentication configured")
-				} else {
-					warn(w, "No API key or OAuth found. Run: claude-workspace setup")
-					warnings++
-				}
-			} else {
-				warn(w, "Could not read authentication config")
-				warnings++
-			}
-		} else {
-			warn(w, "No authentication configured. Run: claude-workspace setup")
-			warnings++
-		}
+		c.warn("auth", "No API key or OAuth found. Run: claude-workspace setup", "")
 	}
-
-	return issues, warnings
 }
 
 func countHookCommands(hooks map[string]json.RawMessage) int {
@@ -482,26 +811,14 @@ func countHookCommands(hooks map[string]json.RawMessage) int {
 	return count
 }
 
-func pass(w io.Writer, msg string) {
-	platform.PrintOK(w, msg)
-}
-
-func fail(w io.Writer, msg string) {
-	platform.PrintFail(w, msg)
-}
-
-func warn(w io.Writer, msg string) {
-	platform.PrintWarn(w, msg)
-}
-
-func checkForUpdate(w io.Writer) {
+func checkForUpdate(c *checker) {
 	type result struct {
 		release *upgrade.Release
 		err     error
 	}
 	ch := make(chan result, 1)
 	go func() {
-		r, err := upgrade.FetchLatest()
+		r, err := upgrade.FetchChannel(upgrade.LoadChannel())
 		ch <- result{r, err}
 	}()
 
@@ -510,14 +827,19 @@ func checkForUpdate(w io.Writer) {
 		if res.err != nil {
 			return // silently skip on failure
 		}
-		currentVer, _ := platform.Output("claude-workspace", "--version")
-		// currentVer looks like "claude-workspace vX.Y.Z" — extract the version
-		currentVer = strings.TrimPrefix(currentVer, "claude-workspace ")
-		if currentVer != "" && currentVer != res.release.TagName {
-			platform.PrintInfo(w, fmt.Sprintf("Update available: %s → %s", currentVer, res.release.TagName))
-			fmt.Fprintln(w, "    Run: claude-workspace upgrade")
+		currentVer := installedVersion()
+		if currentVer != "" && upgrade.UpdateAvailable(currentVer, res.release.TagName) {
+			c.info("update", fmt.Sprintf("Update available: %s → %s", currentVer, res.release.TagName), "Run: claude-workspace upgrade")
 		}
 	case <-time.After(3 * time.Second):
 		return // timeout, skip silently
 	}
 }
+
+// installedVersion returns the version of the claude-workspace in PATH, or ""
+// when it cannot be run.
+func installedVersion() string {
+	out, _ := platform.Output("claude-workspace", "--version")
+	// out looks like "claude-workspace vX.Y.Z" — extract the version
+	return strings.TrimPrefix(out, "claude-workspace ")
+}
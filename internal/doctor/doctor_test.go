@@ -1,8 +1,22 @@
 package doctor
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/attach"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
 )
 
 func TestCountHookCommands(t *testing.T) {
@@ -81,3 +95,482 @@ func TestCountHookCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    options
+		wantErr bool
+	}{
+		{name: "no flags", args: nil, want: options{}},
+		{name: "fix", args: []string{"--fix"}, want: options{fix: true}},
+		{name: "fix dry run", args: []string{"--fix", "--dry-run"}, want: options{fix: true, dryRun: true}},
+		{name: "json", args: []string{"--json"}, want: options{json: true}},
+		{name: "json offline", args: []string{"--json", "--offline"}, want: options{json: true, offline: true}},
+		{name: "dry run without fix", args: []string{"--dry-run"}, wantErr: true},
+		{name: "json with fix", args: []string{"--json", "--fix"}, wantErr: true},
+		{name: "timeout", args: []string{"--timeout", "30s"}, want: options{timeout: 30 * time.Second}},
+		{name: "timeout equals", args: []string{"--json", "--timeout=2m"}, want: options{json: true, timeout: 2 * time.Minute}},
+		{name: "timeout without value", args: []string{"--timeout"}, wantErr: true},
+		{name: "timeout not a duration", args: []string{"--timeout", "10"}, wantErr: true},
+		{name: "unknown flag", args: []string{"--bogus"}, wantErr: true},
+		{name: "projects", args: []string{"--project", "api", "--project=web", "cli"}, want: options{projects: []string{"api", "web", "cli"}}},
+		{name: "all", args: []string{"--all", "--json"}, want: options{all: true, json: true}},
+		{name: "project without value", args: []string{"--project"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArgs(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeHook(t *testing.T, cwd string) string {
+	t.Helper()
+	hooksDir := filepath.Join(cwd, ".claude", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(hooksDir, "guard.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckHooks_FixesNonExecutable(t *testing.T) {
+	cwd := t.TempDir()
+	path := writeHook(t, cwd)
+
+	c := &checker{w: io.Discard}
+	checkHooks(c, cwd)
+	if issues := c.report().Issues; issues != 1 || len(c.fixes) != 1 {
+		t.Fatalf("issues = %d, fixes = %d, want 1 and 1", issues, len(c.fixes))
+	}
+
+	var buf bytes.Buffer
+	applied, failed := applyFixes(&buf, nil, c.fixes, false)
+	if applied != 1 || failed != 0 {
+		t.Fatalf("applied = %d, failed = %d", applied, failed)
+	}
+	if !platform.IsExecutable(path) {
+		t.Error("hook is still not executable")
+	}
+	if !strings.Contains(buf.String(), "chmod +x "+path) {
+		t.Errorf("output does not describe the change:\n%s", buf.String())
+	}
+}
+
+func TestApplyFixes_DryRunMakesNoChanges(t *testing.T) {
+	cwd := t.TempDir()
+	path := writeHook(t, cwd)
+
+	c := &checker{w: io.Discard}
+	checkHooks(c, cwd)
+
+	var buf bytes.Buffer
+	applied, _ := applyFixes(&buf, nil, c.fixes, true)
+	if applied != 0 {
+		t.Errorf("applied = %d in dry run", applied)
+	}
+	if platform.IsExecutable(path) {
+		t.Error("dry run changed the hook's mode")
+	}
+	if !strings.Contains(buf.String(), "Would chmod +x") {
+		t.Errorf("dry run output missing planned fix:\n%s", buf.String())
+	}
+}
+
+func TestApplyFixes_Confirmation(t *testing.T) {
+	ran := false
+	fixes := remedies{{desc: "install", confirm: "Install?", apply: func() error { ran = true; return nil }}}
+
+	applyFixes(io.Discard, nil, fixes, false)
+	if ran {
+		t.Error("remedy needing confirmation ran without input")
+	}
+
+	applyFixes(io.Discard, bufio.NewReader(strings.NewReader("n\n")), fixes, false)
+	if ran {
+		t.Error("remedy ran after the user declined")
+	}
+
+	applied, _ := applyFixes(io.Discard, bufio.NewReader(strings.NewReader("y\n")), fixes, false)
+	if !ran || applied != 1 {
+		t.Errorf("remedy did not run after the user accepted (applied = %d)", applied)
+	}
+}
+
+func TestCheckProjectConfig_FixesOnlyAttachedProjects(t *testing.T) {
+	oldFS := platform.FS
+	platform.FS = fstest.MapFS{
+		".claude/settings.json": {Data: []byte(`{"hooks": {}}`)},
+		".claude/.gitignore":    {Data: []byte("settings.local.json\n")},
+	}
+	defer func() { platform.FS = oldFS }()
+
+	c := &checker{w: io.Discard}
+	checkProjectConfig(c, t.TempDir())
+	if len(c.fixes) != 0 {
+		t.Errorf("got %d fixes for a project without .claude", len(c.fixes))
+	}
+
+	cwd := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(cwd, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	c = &checker{w: io.Discard}
+	checkProjectConfig(c, cwd)
+	if _, failed := applyFixes(io.Discard, nil, c.fixes, false); failed != 0 {
+		t.Fatalf("%d fixes failed", failed)
+	}
+
+	for _, p := range []string{".claude/settings.json", ".claude/agents", ".claude/skills", ".claude/hooks", ".claude/.gitignore"} {
+		if !platform.FileExists(filepath.Join(cwd, p)) {
+			t.Errorf("%s was not created", p)
+		}
+	}
+
+	c = &checker{w: io.Discard}
+	checkProjectConfig(c, cwd)
+	if len(c.fixes) != 0 {
+		t.Errorf("got %d fixes after fixing", len(c.fixes))
+	}
+}
+
+func TestNeedsSettingsMerge(t *testing.T) {
+	defaults := map[string]interface{}{
+		"env":         map[string]interface{}{"A": "1"},
+		"permissions": map[string]interface{}{"deny": []interface{}{"Read(.env)"}},
+	}
+	tests := []struct {
+		name     string
+		existing map[string]interface{}
+		want     bool
+	}{
+		{name: "empty", existing: map[string]interface{}{}, want: true},
+		{
+			name: "already merged",
+			existing: map[string]interface{}{
+				"env":         map[string]interface{}{"A": "2"},
+				"permissions": map[string]interface{}{"deny": []interface{}{"Bash(rm)", "Read(.env)"}},
+			},
+			want: false,
+		},
+		{
+			name: "missing deny rule",
+			existing: map[string]interface{}{
+				"env":         map[string]interface{}{"A": "1"},
+				"permissions": map[string]interface{}{"deny": []interface{}{"Bash(rm)"}},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsSettingsMerge(tt.existing, defaults); got != tt.want {
+				t.Errorf("needsSettingsMerge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_Report(t *testing.T) {
+	var buf bytes.Buffer
+	c := &checker{w: &buf}
+	c.begin("Hooks")
+	c.pass("hook:a.sh", "a.sh: executable")
+	c.fail("hook:b.sh", "b.sh: not executable", "Run: chmod +x b.sh", chmodRemedy("b.sh"))
+	c.begin("Agents")
+	c.warn("agents", "No agent definitions found", "")
+
+	r := c.report()
+	if r.Healthy || r.Issues != 1 || r.Warnings != 1 {
+		t.Errorf("Healthy = %v, Issues = %d, Warnings = %d", r.Healthy, r.Issues, r.Warnings)
+	}
+	if len(r.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(r.Results))
+	}
+	want := Result{
+		Section:     "Hooks",
+		Check:       "hook:b.sh",
+		Status:      StatusFail,
+		Severity:    SeverityError,
+		Message:     "b.sh: not executable",
+		Remediation: "Run: chmod +x b.sh",
+		Fixable:     true,
+	}
+	if r.Results[1] != want {
+		t.Errorf("Results[1] = %+v, want %+v", r.Results[1], want)
+	}
+	if r.Results[2].Section != "Agents" || r.Results[2].Severity != SeverityWarning {
+		t.Errorf("Results[2] = %+v", r.Results[2])
+	}
+	if !strings.Contains(buf.String(), "    Run: chmod +x b.sh") {
+		t.Errorf("remediation not printed:\n%s", buf.String())
+	}
+}
+
+func TestRunGroups(t *testing.T) {
+	var buf bytes.Buffer
+	c := &checker{w: &buf}
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	runGroups(c, []checkGroup{
+		{"Slow", false, func(c *checker) {
+			c.begin("Slow")
+			<-release
+			c.pass("slow", "never reported")
+		}},
+		{"Network", true, func(c *checker) {
+			c.begin("Network")
+			time.Sleep(20 * time.Millisecond)
+			c.fail("net", "unreachable", "", chmodRemedy("x.sh"))
+		}},
+		{"Local", false, func(c *checker) {
+			c.begin("Local")
+			c.pass("local", "ok")
+		}},
+	}, 100*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("runGroups took %s with a 100ms budget", elapsed)
+	}
+
+	var got []string
+	for _, r := range c.results {
+		got = append(got, r.Section+"/"+r.Check+"/"+r.Status)
+	}
+	want := []string{"Slow/timeout/warn", "Network/net/fail", "Local/local/pass"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+	if len(c.fixes) != 1 {
+		t.Errorf("got %d fixes, want 1", len(c.fixes))
+	}
+	out := buf.String()
+	if strings.Index(out, "Local") > strings.Index(out, "Network") || strings.Index(out, "Network") > strings.Index(out, "Slow") {
+		t.Errorf("sections should print as they finish:\n%s", out)
+	}
+	if !strings.Contains(out, "No result after 100ms") || strings.Contains(out, "never reported") {
+		t.Errorf("timed-out section:\n%s", out)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	c := &checker{w: io.Discard}
+	c.begin("Git")
+	c.pass("git", "git version 2.43.0")
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, c.report()); err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if !got.Healthy || len(got.Results) != 1 || got.Results[0].Check != "git" {
+		t.Errorf("round-tripped report = %+v", got)
+	}
+	if strings.Contains(buf.String(), "remediation") {
+		t.Errorf("empty remediation should be omitted:\n%s", buf.String())
+	}
+}
+
+func TestCheckTemplateOverrides(t *testing.T) {
+	t.Setenv(platform.OverridesEnv, "")
+	c := &checker{w: io.Discard}
+	checkTemplateOverrides(c, "", nil)
+	if len(c.results) != 0 {
+		t.Errorf("no overrides configured: got %d results, want none", len(c.results))
+	}
+
+	t.Setenv(platform.OverridesEnv, "/missing/overrides")
+	c = &checker{w: io.Discard}
+	checkTemplateOverrides(c, "", nil)
+	if r := c.report(); r.Warnings != 1 {
+		t.Errorf("missing directory: warnings = %d, want 1", r.Warnings)
+	}
+
+	dir := t.TempDir()
+	settings := filepath.Join(dir, "project", ".claude", "settings.json")
+	_ = os.MkdirAll(filepath.Dir(settings), 0755)
+	_ = os.WriteFile(settings, []byte("{not json"), 0644)
+	var buf bytes.Buffer
+	c = &checker{w: &buf}
+	checkTemplateOverrides(c, dir, []platform.OverrideFile{
+		{Layer: "project", Path: ".claude/agents/security.md"},
+		{Layer: "project", Path: ".claude/settings.json", Replaces: true},
+		{Layer: "global", Path: "CLAUDE.md", Replaces: true},
+	})
+	if r := c.report(); r.Issues != 1 {
+		t.Errorf("issues = %d, want 1 for the invalid settings.json", r.Issues)
+	}
+	for _, want := range []string{"Using 3 override(s) from " + dir, "Adds: project/.claude/agents/security.md", "Replaces embedded: global/CLAUDE.md"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCheckAgents_Validates(t *testing.T) {
+	cwd, home := t.TempDir(), t.TempDir()
+	for dir, files := range map[string]map[string]string{
+		filepath.Join(cwd, ".claude", "agents"): {
+			"reviewer.md": "---\nname: reviewer\ndescription: Reviews code\ntools: Read, Grepp\n---\n",
+			"planner.md":  "---\nname: planner\ndescription: Plans work\n---\n",
+		},
+		filepath.Join(home, ".claude", "agents"): {
+			"planner.md": "---\nname: planner\ndescription: Personal planner\n---\n",
+		},
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	c := &checker{w: io.Discard}
+	checkAgents(c, cwd, home)
+	report := c.report()
+	if report.Issues != 1 || report.Warnings != 1 {
+		t.Errorf("issues = %d, warnings = %d, want 1 and 1: %+v", report.Issues, report.Warnings, report.Results)
+	}
+}
+
+func TestCheckPlatformVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		lock      attach.Lock
+		installed string
+		status    string
+		want      string
+	}{
+		{"current", attach.Lock{Version: "v1.4.0"}, "v1.4.0", StatusPass, "from claude-workspace v1.4.0"},
+		{"pinned, current", attach.Lock{Version: "v1.4.0", Pinned: true}, "v1.4.0", StatusPass, "pinned to"},
+		{"behind", attach.Lock{Version: "v1.3.0"}, "v1.4.0", StatusWarn, "--reconcile"},
+		{"pinned, behind", attach.Lock{Version: "v1.3.0", Pinned: true}, "v1.4.0", StatusWarn, "--upgrade-assets"},
+		{"installed is older", attach.Lock{Version: "v1.5.0", Pinned: true}, "v1.4.0", StatusPass, "pinned to"},
+		{"dev build", attach.Lock{Version: "v1.3.0"}, "dev", StatusPass, "from"},
+		{"not installed", attach.Lock{Version: "v1.3.0"}, "", StatusPass, "from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &checker{w: io.Discard}
+			checkPlatformVersion(c, &tt.lock, tt.installed)
+			r := c.results[0]
+			if r.Status != tt.status || !strings.Contains(r.Message+r.Remediation, tt.want) {
+				t.Errorf("got %s %q (%s), want %s containing %q", r.Status, r.Message, r.Remediation, tt.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMemoryProvider(t *testing.T) {
+	// "go" stands in for an installed engram binary.
+	engram := func(name, ver string) tools.Tool {
+		return tools.Tool{Name: name, InstallCmd: "brew install engram", VersionFn: func() (string, error) {
+			if ver == "" {
+				return "", fmt.Errorf("no version")
+			}
+			return ver, nil
+		}}
+	}
+	tests := []struct {
+		name     string
+		provider string
+		engram   tools.Tool
+		status   string
+		want     string
+	}{
+		{"libsql, no engram", "mcp-memory-libsql", engram("nonexistent-engram-xyz", ""), StatusPass, "mcp-memory-libsql"},
+		{"engram missing", "engram", engram("nonexistent-engram-xyz", ""), StatusFail, "memory configure --provider engram"},
+		{"engram current", "engram", engram("go", tools.EngramMinVersion), StatusPass, "engram: " + tools.EngramMinVersion},
+		{"engram too old", "engram", engram("go", "0.3.1"), StatusWarn, "setup --tools engram"},
+		{"engram version unknown", "none", engram("go", ""), StatusWarn, "Could not determine"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &checker{w: io.Discard}
+			checkMemoryProvider(c, tt.provider, tt.engram)
+			r := c.results[len(c.results)-1]
+			if r.Status != tt.status || !strings.Contains(r.Message+r.Remediation, tt.want) {
+				t.Errorf("got %s %q (%s), want %s containing %q", r.Status, r.Message, r.Remediation, tt.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveProjects(t *testing.T) {
+	home := t.TempDir()
+	api := filepath.Join(home, "code", "api")
+	web := filepath.Join(home, "code", "web")
+	for _, dir := range []string{filepath.Join(api, ".claude"), web} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claudeJSON := `{"projects":{"` + api + `":{},"` + web + `":{},"/gone":{}}}`
+	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(claudeJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// --all adds only attached projects, and a path given twice is checked once.
+	got, err := resolveProjects(options{projects: []string{web, api}, all: true}, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []project{{web, "~/code/web"}, {api, "~/code/api"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveProjects = %+v, want %+v", got, want)
+	}
+
+	cwd, _ := os.Getwd()
+	if got, err := resolveProjects(options{}, home); err != nil || len(got) != 1 || got[0] != (project{dir: cwd}) {
+		t.Errorf("without projects = %+v, %v; want the current directory, unlabeled", got, err)
+	}
+	if _, err := resolveProjects(options{projects: []string{filepath.Join(home, "missing")}}, home); err == nil {
+		t.Error("expected error for a missing project")
+	}
+	if _, err := resolveProjects(options{all: true}, t.TempDir()); err == nil {
+		t.Error("--all without recorded projects: expected error")
+	}
+}
+
+func TestProjectGroups_Labeled(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	c := &checker{w: &buf}
+	groups := projectGroups(dir, "~/code/api")
+	runGroups(c, groups[:1], time.Second)
+	if len(c.results) == 0 {
+		t.Fatal("no results")
+	}
+	for _, r := range c.results {
+		if r.Project != "~/code/api" || r.Section != "Project Configuration" {
+			t.Errorf("result %+v: want project ~/code/api in section Project Configuration", r)
+		}
+	}
+	if !strings.Contains(buf.String(), "Project Configuration (~/code/api)") {
+		t.Errorf("output:\n%s", buf.String())
+	}
+}
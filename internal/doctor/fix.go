@@ -0,0 +1,221 @@
+package doctor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/orgpolicy"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/setup"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+// options holds the parsed doctor flags.
+type options struct {
+	fix     bool
+	dryRun  bool
+	json    bool
+	offline bool
+	timeout time.Duration // overall budget; 0 means defaultTimeout
+	// projects are the directories whose project checks run; none means
+	// the current directory. all adds every attached project Claude Code
+	// has recorded in ~/.claude.json.
+	projects []string
+	all      bool
+}
+
+func parseArgs(args []string) (options, error) {
+	var opts options
+	f := cli.New("doctor", "doctor [--project <path>]... [<path>...] [--all] [--json | --fix [--dry-run]] [--offline] [--timeout <duration>]")
+	f.BoolVar(&opts.fix, "--fix", "Apply safe fixes for failed checks")
+	f.BoolVar(&opts.dryRun, "--dry-run", "With --fix, list the fixes without applying them")
+	f.BoolVar(&opts.json, "--json", "Print the results as JSON")
+	f.BoolVar(&opts.offline, "--offline", "Skip checks that need the network")
+	f.BoolVar(&opts.all, "--all", "Also check every attached project")
+	f.StringsVar(&opts.projects, "--project", "path", "Project to check")
+	f.Func("--timeout", "duration", "Overall time budget, e.g. 10s", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("--timeout must be a positive duration such as 10s, got %q", v)
+		}
+		opts.timeout = d
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	opts.projects = append(opts.projects, positional...)
+	if opts.dryRun && !opts.fix {
+		return opts, fmt.Errorf("--dry-run requires --fix")
+	}
+	if opts.json && opts.fix {
+		return opts, fmt.Errorf("--json cannot be combined with --fix")
+	}
+	return opts, nil
+}
+
+// remedy is a safe, automatic fix for a failed check, applied by "doctor --fix".
+type remedy struct {
+	desc    string // what the fix changes, printed before applying
+	confirm string // when set, the user is asked this before applying
+	apply   func() error
+}
+
+// remedies collects the fixes found while running checks.
+type remedies []remedy
+
+// applyFixes applies each remedy, or only lists them when dryRun is set.
+// Remedies that need confirmation are skipped when in is nil. It returns the
+// number of remedies applied and the number that failed.
+func applyFixes(w io.Writer, in *bufio.Reader, list remedies, dryRun bool) (applied, failed int) {
+	if dryRun {
+		platform.PrintBanner(w, "Fixes (dry run)")
+	} else {
+		platform.PrintBanner(w, "Fixes")
+	}
+	fmt.Fprintln(w)
+
+	if len(list) == 0 {
+		fmt.Fprintln(w, "  Nothing to fix automatically.")
+		fmt.Fprintln(w)
+		return 0, 0
+	}
+
+	for _, r := range list {
+		if dryRun {
+			platform.PrintInfo(w, "Would "+r.desc)
+			continue
+		}
+		if r.confirm != "" {
+			if in == nil {
+				platform.PrintWarn(w, "Skipped (needs confirmation): "+r.desc)
+				continue
+			}
+			platform.PrintPrompt(w, "  "+r.confirm+" [y/N] ")
+			answer, _ := in.ReadString('\n')
+			answer = strings.TrimSpace(strings.ToLower(answer))
+			if answer != "y" && answer != "yes" {
+				platform.PrintWarn(w, "Skipped: "+r.desc)
+				continue
+			}
+		}
+		if err := r.apply(); err != nil {
+			platform.PrintFail(w, fmt.Sprintf("Could not %s: %v", r.desc, err))
+			failed++
+			continue
+		}
+		platform.PrintOK(w, "Fixed: "+r.desc)
+		applied++
+	}
+	fmt.Fprintln(w)
+	return applied, failed
+}
+
+// installClaudeRemedy offers to run the official Claude Code installer.
+func installClaudeRemedy() remedy {
+	return remedy{
+		desc:    "install Claude Code CLI (" + tools.ClaudeInstallCmd + ")",
+		confirm: "Run the official Claude Code installer now?",
+		apply: func() error {
+			claude := tools.Claude()
+			return claude.Install()
+		},
+	}
+}
+
+// chmodRemedy makes a hook script executable.
+func chmodRemedy(path string) remedy {
+	return remedy{
+		desc: "chmod +x " + path,
+		apply: func() error {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			return os.Chmod(path, info.Mode()|0111)
+		},
+	}
+}
+
+// mkdirRemedy creates a missing directory.
+func mkdirRemedy(path string) remedy {
+	return remedy{
+		desc:  "create directory " + path,
+		apply: func() error { return os.MkdirAll(path, 0755) },
+	}
+}
+
+// projectSettingsRemedy restores a missing .claude/settings.json from the
+// embedded template.
+func projectSettingsRemedy(path string) remedy {
+	return remedy{
+		desc: "create " + path + " from the platform template",
+		apply: func() error {
+			data, err := platform.ReadAsset(".claude/settings.json")
+			if err != nil {
+				return fmt.Errorf("reading embedded settings.json: %w", err)
+			}
+			return platform.WriteSettingsData(path, data)
+		},
+	}
+}
+
+// globalSettingsRemedy re-runs the global settings merge, creating the file
+// from the platform defaults when it does not exist.
+func globalSettingsRemedy(path string) remedy {
+	return remedy{
+		desc: "merge platform defaults into " + path,
+		apply: func() error {
+			// Taking the lock creates the directory if needed
+			return platform.WithFileLock(path, func() error {
+				existing := map[string]interface{}{}
+				if platform.FileExists(path) {
+					if err := platform.ReadJSONFile(path, &existing); err != nil {
+						return fmt.Errorf("parsing %s: %w", path, err)
+					}
+				}
+				return platform.WriteSettingsFile(path, setup.MergeSettings(existing, setup.GetDefaultGlobalSettings()))
+			})
+		},
+	}
+}
+
+// orgPolicyRemedy syncs the org policy, restoring the settings it requires.
+func orgPolicyRemedy() remedy {
+	return remedy{
+		desc:  "sync the org policy into ~/.claude/settings.json",
+		apply: func() error { return orgpolicy.Sync(io.Discard, false) },
+	}
+}
+
+// gitignoreRemedy appends the template's required entries to .claude/.gitignore.
+func gitignoreRemedy(path, required string) remedy {
+	return remedy{
+		desc: "add missing entries to " + path,
+		apply: func() error {
+			_, err := platform.EnsureGitignoreEntries(path, required)
+			return err
+		},
+	}
+}
+
+// needsSettingsMerge reports whether merging the platform defaults into
+// existing would change it.
+func needsSettingsMerge(existing, defaults map[string]interface{}) bool {
+	before, err := json.Marshal(existing)
+	if err != nil {
+		return false
+	}
+	after, err := json.Marshal(setup.MergeSettings(existing, defaults))
+	if err != nil {
+		return false
+	}
+	return string(before) != string(after)
+}
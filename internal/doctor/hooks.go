@@ -0,0 +1,278 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/hooks"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+// maxLintFindings caps the shellcheck findings printed per hook.
+const maxLintFindings = 3
+
+// hookTools are the external tools hook scripts commonly depend on.
+var hookTools = []tools.Tool{tools.JQ(), tools.Prettier()}
+
+// matcherEvents are the hook events whose matcher Claude Code evaluates. Other
+// events ignore the matcher.
+var matcherEvents = map[string]bool{
+	"PreToolUse": true, "PostToolUse": true, "Notification": true,
+	"PreCompact": true, "SessionStart": true, "SessionEnd": true,
+}
+
+// guardPattern matches "command -v TOOL", "which TOOL", and "hash TOOL"
+// availability tests.
+var guardPattern = regexp.MustCompile(`(?:command -v|which|hash)\s+([A-Za-z0-9_.-]+)`)
+
+// scriptInterpreters are commands that run the script named by their first
+// argument, so the script itself need not be executable.
+var scriptInterpreters = map[string]bool{
+	"bash": true, "sh": true, "zsh": true, "python": true, "python3": true, "node": true,
+}
+
+// lintHook runs shellcheck on a hook script, reporting warnings and errors.
+func lintHook(c *checker, hooksDir, name string) {
+	out, err := platform.OutputDir(hooksDir, "shellcheck", "-f", "gcc", "-S", "warning", name)
+	if err == nil {
+		c.pass("hook-lint:"+name, name+": shellcheck clean")
+		return
+	}
+	findings := strings.Split(out, "\n")
+	if out == "" {
+		c.warn("hook-lint:"+name, name+": shellcheck could not check the script", "")
+		return
+	}
+	detail := findings
+	if len(detail) > maxLintFindings {
+		detail = append(detail[:maxLintFindings:maxLintFindings], fmt.Sprintf("... and %d more", len(findings)-maxLintFindings))
+	}
+	detail = append(detail, "Run: shellcheck "+filepath.Join(hooksDir, name))
+	c.warn("hook-lint:"+name, fmt.Sprintf("%s: %d shellcheck finding(s)", name, len(findings)), strings.Join(detail, "\n"))
+}
+
+// checkHookDeps warns when a hook script needs jq or prettier and the tool is
+// not installed. Uses guarded by an availability test are not counted.
+func checkHookDeps(c *checker, path, name string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, t := range hookTools {
+		if !unguardedUse(string(data), t.Name) || t.IsInstalled() {
+			continue
+		}
+		c.warn("hook-deps:"+name+":"+t.Name, fmt.Sprintf("%s: uses %s, which is not installed", name, t.Name), "Install: "+t.InstallHint())
+	}
+}
+
+// unguardedUse reports whether script runs tool outside an "if command -v
+// tool" block (or the like). A script that exits early when the tool is
+// missing, as in "command -v jq >/dev/null || exit 0", counts as guarded.
+func unguardedUse(script, tool string) bool {
+	use := regexp.MustCompile(`(?:^|[\s|;&(])` + regexp.QuoteMeta(tool) + `(?:$|[\s;)|])`)
+	var guards []string // tool guarded by each enclosing if, or ""
+	for _, raw := range strings.Split(script, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		guarded := ""
+		if m := guardPattern.FindStringSubmatch(line); m != nil {
+			guarded = m[1]
+		}
+		switch {
+		case strings.HasPrefix(line, "if "):
+			if guarded == tool && strings.Contains(line, "! ") {
+				return false // "if ! command -v tool; then exit; fi"
+			}
+			guards = append(guards, guarded)
+			continue
+		case strings.HasPrefix(line, "elif "):
+			if len(guards) > 0 {
+				guards[len(guards)-1] = guarded
+			}
+			continue
+		case line == "else" || strings.HasPrefix(line, "else "):
+			if len(guards) > 0 {
+				guards[len(guards)-1] = ""
+			}
+			continue
+		case line == "fi" || strings.HasPrefix(line, "fi;") || strings.HasPrefix(line, "fi "):
+			if len(guards) > 0 {
+				guards = guards[:len(guards)-1]
+			}
+			continue
+		}
+		if guarded == tool {
+			if strings.Contains(line, "||") {
+				return false
+			}
+			continue
+		}
+		if !use.MatchString(line) || strings.Contains(line, "npx "+tool) {
+			continue
+		}
+		inGuard := false
+		for _, g := range guards {
+			if g == tool {
+				inGuard = true
+			}
+		}
+		if !inGuard {
+			return true
+		}
+	}
+	return false
+}
+
+// hookGroup is one matcher group of a settings.json hook event, decoded
+// loosely so that schema problems can be reported rather than rejected.
+type hookGroup struct {
+	Matcher interface{}              `json:"matcher"`
+	Hooks   []map[string]interface{} `json:"hooks"`
+}
+
+// checkHookEntries validates hook events, matchers, and commands in the
+// settings.json "hooks" value against the Claude Code hooks schema, and checks
+// that the scripts the commands run exist and can be executed.
+func checkHookEntries(c *checker, cwd string, events map[string]json.RawMessage) {
+	for _, event := range sortedKeys(events) {
+		check := "hook-config:" + event
+		if !knownEvent(event) {
+			c.warn(check, "Unknown hook event: "+event, "Valid events: "+strings.Join(hooks.Events, ", "))
+			continue
+		}
+		var groups []hookGroup
+		if err := json.Unmarshal(events[event], &groups); err != nil {
+			c.fail(check, event+": expected a list of {matcher, hooks} entries", "")
+			continue
+		}
+		for i, g := range groups {
+			where := fmt.Sprintf("%s[%d]", event, i)
+			checkMatcher(c, event, where, g.Matcher)
+			if len(g.Hooks) == 0 {
+				c.warn("hook-config:"+where, where+": no hooks listed", "")
+			}
+			for _, h := range g.Hooks {
+				checkHookEntry(c, cwd, where, h)
+			}
+		}
+	}
+}
+
+// checkMatcher validates a matcher: a string that is empty, "*", or a regular
+// expression over tool names.
+func checkMatcher(c *checker, event, where string, matcher interface{}) {
+	if matcher == nil {
+		return
+	}
+	m, ok := matcher.(string)
+	if !ok {
+		c.fail("hook-matcher:"+where, where+": matcher must be a string", "")
+		return
+	}
+	if m == "" || m == "*" {
+		return
+	}
+	if !matcherEvents[event] {
+		c.warn("hook-matcher:"+where, fmt.Sprintf("%s: matcher %q is ignored for %s", where, m, event), "Remove the matcher; this event always runs its hooks.")
+		return
+	}
+	if _, err := regexp.Compile(m); err != nil {
+		c.fail("hook-matcher:"+where, fmt.Sprintf("%s: invalid matcher %q: %v", where, m, err), "Matchers are regular expressions, e.g. \"Bash\" or \"Write|Edit\".")
+	}
+}
+
+// checkHookEntry validates one hook object and the script its command runs.
+func checkHookEntry(c *checker, cwd, where string, h map[string]interface{}) {
+	typ, _ := h["type"].(string)
+	command, _ := h["command"].(string)
+	check := "hook-command:" + where
+	if name := hooks.HookName(command); name != "" {
+		check = "hook-command:" + name
+	}
+	switch typ {
+	case "command":
+	case "prompt":
+		if p, _ := h["prompt"].(string); p == "" {
+			c.fail(check, where+": prompt hook has no prompt", "")
+		}
+		return
+	default:
+		c.fail(check, fmt.Sprintf("%s: unknown hook type %q", where, typ), `Use "type": "command".`)
+		return
+	}
+	if timeout, ok := h["timeout"]; ok {
+		if n, isNum := timeout.(float64); !isNum || n <= 0 {
+			c.fail(check, fmt.Sprintf("%s: timeout must be a positive number of seconds", where), "")
+		}
+	}
+	if strings.TrimSpace(command) == "" {
+		c.fail(check, where+": command is empty", "")
+		return
+	}
+
+	path, needExec := hookScriptPath(command, cwd)
+	if path == "" {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		if first := strings.Fields(command)[0]; !strings.Contains(path, "/") && !scriptInterpreters[first] {
+			if !platform.Exists(path) {
+				c.fail(check, fmt.Sprintf("%s: command %s not found in PATH", where, path), "")
+			}
+			return
+		}
+		path = filepath.Join(cwd, path)
+	}
+	switch {
+	case !platform.FileExists(path):
+		c.fail(check, fmt.Sprintf("%s: hook script not found: %s", where, path), "Restore it or run: claude-workspace hooks disable "+hooks.HookName(command))
+	case needExec && !platform.IsExecutable(path) && filepath.Dir(path) != filepath.Join(cwd, ".claude", "hooks"):
+		// Scripts in .claude/hooks are already covered by checkHooks.
+		c.fail(check, fmt.Sprintf("%s: hook script not executable: %s", where, path), "Run: chmod +x "+path, chmodRemedy(path))
+	}
+}
+
+// hookScriptPath returns the script or program a hook command runs, with
+// $CLAUDE_PROJECT_DIR expanded to cwd, and whether it must be executable.
+func hookScriptPath(command, cwd string) (string, bool) {
+	expanded := strings.NewReplacer("${CLAUDE_PROJECT_DIR}", cwd, "$CLAUDE_PROJECT_DIR", cwd).Replace(command)
+	fields := strings.Fields(expanded)
+	if len(fields) == 0 {
+		return "", false
+	}
+	unquote := strings.NewReplacer(`"`, "", `'`, "").Replace
+	if scriptInterpreters[fields[0]] {
+		if len(fields) < 2 || strings.HasPrefix(fields[1], "-") {
+			return "", false
+		}
+		return unquote(fields[1]), false
+	}
+	return unquote(fields[0]), true
+}
+
+func knownEvent(event string) bool {
+	for _, e := range hooks.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+package doctor
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnguardedUse(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		tool   string
+		want   bool
+	}{
+		{"plain use", "INPUT=$(cat)\nCMD=$(echo \"$INPUT\" | jq -r '.tool_input.command')\n", "jq", true},
+		{"not used", "echo hello\n", "jq", false},
+		{"comment only", "# parse with jq\necho hi\n", "jq", false},
+		{"substring of another word", "echo jquery\n", "jq", false},
+		{"guarded if block", "if command -v prettier &>/dev/null; then\n  prettier --write \"$F\"\nfi\n", "prettier", false},
+		{"guarded elif block", "if command -v biome >/dev/null; then\n  biome format\nelif command -v prettier >/dev/null; then\n  prettier --write x\nfi\n", "prettier", false},
+		{"else branch is unguarded", "if command -v prettier >/dev/null; then\n  true\nelse\n  prettier --write x\nfi\n", "prettier", true},
+		{"use after the guard closes", "if command -v jq >/dev/null; then\n  jq . f\nfi\njq . g\n", "jq", true},
+		{"early exit guard", "command -v jq >/dev/null || exit 0\njq . f\n", "jq", false},
+		{"negated if guard", "if ! command -v jq >/dev/null; then\n  exit 0\nfi\njq . f\n", "jq", false},
+		{"run through npx", "npx prettier --write \"$F\"\n", "prettier", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unguardedUse(tt.script, tt.tool); got != tt.want {
+				t.Errorf("unguardedUse(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnguardedUse_TemplateHooks(t *testing.T) {
+	data, err := os.ReadFile("../../_template/project/.claude/hooks/auto-format.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unguardedUse(string(data), "jq") {
+		t.Error("auto-format.sh reads its input with jq unconditionally")
+	}
+	if unguardedUse(string(data), "prettier") {
+		t.Error("auto-format.sh only runs prettier when it is installed")
+	}
+}
+
+func TestHookScriptPath(t *testing.T) {
+	tests := []struct {
+		command  string
+		wantPath string
+		wantExec bool
+	}{
+		{`"$CLAUDE_PROJECT_DIR"/.claude/hooks/guard.sh`, "/proj/.claude/hooks/guard.sh", true},
+		{`${CLAUDE_PROJECT_DIR}/scripts/check.sh --strict`, "/proj/scripts/check.sh", true},
+		{`bash .claude/hooks/guard.sh`, ".claude/hooks/guard.sh", false},
+		{`python3 -c 'print(1)'`, "", false},
+		{`npx prettier --check`, "npx", true},
+	}
+	for _, tt := range tests {
+		path, exec := hookScriptPath(tt.command, "/proj")
+		if path != tt.wantPath || exec != tt.wantExec {
+			t.Errorf("hookScriptPath(%q) = %q, %v, want %q, %v", tt.command, path, exec, tt.wantPath, tt.wantExec)
+		}
+	}
+}
+
+// resultsFor returns the status of each recorded check whose id starts with prefix.
+func resultsFor(c *checker, prefix string) map[string]string {
+	got := map[string]string{}
+	for _, r := range c.results {
+		if strings.HasPrefix(r.Check, prefix) {
+			got[r.Check] = r.Status
+		}
+	}
+	return got
+}
+
+func TestCheckHookConfig(t *testing.T) {
+	cwd := t.TempDir()
+	writeHook(t, cwd) // .claude/hooks/guard.sh, not executable
+	scripts := filepath.Join(cwd, "scripts")
+	if err := os.MkdirAll(scripts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(scripts, "lint.sh"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := map[string]interface{}{
+		"hooks": map[string]interface{}{
+			"PreToolUse": []interface{}{
+				map[string]interface{}{"matcher": "Bash", "hooks": []interface{}{
+					map[string]interface{}{"type": "command", "command": `"$CLAUDE_PROJECT_DIR"/.claude/hooks/guard.sh`},
+					map[string]interface{}{"type": "command", "command": `"$CLAUDE_PROJECT_DIR"/.claude/hooks/gone.sh`},
+				}},
+				map[string]interface{}{"matcher": "Write(", "hooks": []interface{}{
+					map[string]interface{}{"type": "command", "command": `"$CLAUDE_PROJECT_DIR"/scripts/lint.sh`, "timeout": -1},
+				}},
+			},
+			"Stop": []interface{}{
+				map[string]interface{}{"matcher": "Bash", "hooks": []interface{}{
+					map[string]interface{}{"type": "webhook", "command": "x"},
+				}},
+			},
+			"BeforeAnything": []interface{}{},
+		},
+	}
+	data, _ := json.Marshal(settings)
+	if err := os.WriteFile(filepath.Join(cwd, ".claude", "settings.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &checker{w: io.Discard}
+	checkHookConfig(c, cwd)
+
+	want := map[string]string{
+		"hook-config":                "pass",
+		"hook-config:BeforeAnything": "warn",
+		"hook-command:gone":          "fail",
+		"hook-matcher:PreToolUse[1]": "fail",
+		"hook-command:lint":          "fail",
+		"hook-matcher:Stop[0]":       "warn",
+		"hook-command:x":             "fail",
+	}
+	got := resultsFor(c, "hook-")
+	for check, status := range want {
+		if got[check] != status {
+			t.Errorf("%s = %q, want %q (all: %v)", check, got[check], status, got)
+		}
+	}
+	if _, ok := got["hook-command:guard"]; ok {
+		t.Error("guard.sh permissions are checkHooks' job and should not be reported twice")
+	}
+
+	// lint.sh is outside .claude/hooks, so checkHookConfig offers the chmod
+	// fix; it also has the invalid timeout.
+	var lintFails int
+	for _, r := range c.results {
+		if r.Check == "hook-command:lint" {
+			lintFails++
+		}
+	}
+	if lintFails != 2 || len(c.fixes) != 1 {
+		t.Errorf("lint.sh: %d failures and %d fixes, want 2 and 1", lintFails, len(c.fixes))
+	}
+}
+
+func TestCheckHooks_LintAndDeps(t *testing.T) {
+	cwd := t.TempDir()
+	hooksDir := filepath.Join(cwd, ".claude", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\nINPUT=$(cat)\necho $INPUT | jq .\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, "guard.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A PATH with only a fake shellcheck: jq is missing and the lint fails.
+	bin := t.TempDir()
+	fake := "#!/bin/sh\necho \"guard.sh:3:6: warning: Double quote to prevent globbing. [SC2086]\"\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(bin, "shellcheck"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	c := &checker{w: io.Discard}
+	checkHooks(c, cwd)
+	got := resultsFor(c, "hook")
+	want := map[string]string{
+		"hook:guard.sh":         "pass",
+		"hook-lint:guard.sh":    "warn",
+		"hook-deps:guard.sh:jq": "warn",
+	}
+	for check, status := range want {
+		if got[check] != status {
+			t.Errorf("%s = %q, want %q (all: %v)", check, got[check], status, got)
+		}
+	}
+	for _, r := range c.results {
+		if r.Check == "hook-lint:guard.sh" && !strings.Contains(r.Remediation, "SC2086") {
+			t.Errorf("lint remediation missing finding: %q", r.Remediation)
+		}
+	}
+
+	// Without shellcheck the scripts are not linted and doctor says so.
+	t.Setenv("PATH", t.TempDir())
+	c = &checker{w: io.Discard}
+	checkHooks(c, cwd)
+	if got := resultsFor(c, "hook-lint"); got["hook-lint"] != "info" || len(got) != 1 {
+		t.Errorf("without shellcheck: %v, want a single info result", got)
+	}
+}
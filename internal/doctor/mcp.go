@@ -0,0 +1,312 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpsupervisor"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+// probeTimeout bounds each network check of an MCP server. Servers are
+// probed concurrently, so this is also roughly the most the section takes.
+const probeTimeout = 5 * time.Second
+
+// defaultNPMRegistry is used when no registry is configured in the
+// environment.
+const defaultNPMRegistry = "https://registry.npmjs.org"
+
+// initializeRequest is the MCP request sent to remote servers to see whether
+// they answer.
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"claude-workspace-doctor","version":"1.0.0"}}}`
+
+// installHints suggest how to get common MCP server launchers.
+var installHints = map[string]string{
+	"npx":    "Install Node.js: https://nodejs.org",
+	"node":   "Install Node.js: https://nodejs.org",
+	"uvx":    "Install uv: https://docs.astral.sh/uv/",
+	"uv":     "Install uv: https://docs.astral.sh/uv/",
+	"docker": "Install Docker: https://docs.docker.com/get-docker/",
+}
+
+// mcpServerEntry is a server in .mcp.json.
+type mcpServerEntry struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// probe is the outcome of checking one server.
+type probe struct {
+	status      string // one of the Status* values
+	msg         string
+	remediation string
+}
+
+// checkMCPServers validates .mcp.json and checks that each server can start
+// or be reached.
+func checkMCPServers(c *checker, cwd string) {
+	c.begin("MCP Servers")
+	mcpPath := filepath.Join(cwd, ".mcp.json")
+	if !platform.FileExists(mcpPath) {
+		return
+	}
+	var mcpConfig struct {
+		MCPServers map[string]mcpServerEntry `json:"mcpServers"`
+	}
+	if err := platform.ReadJSONFile(mcpPath, &mcpConfig); err != nil {
+		c.fail("mcp-config", "Could not parse .mcp.json", "")
+		return
+	}
+	names := make([]string, 0, len(mcpConfig.MCPServers))
+	for name := range mcpConfig.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		c.warn("mcp-config", "No MCP servers configured in .mcp.json", "")
+		return
+	}
+	c.pass("mcp-config", fmt.Sprintf("%d MCP servers configured: %s", len(names), strings.Join(names, ", ")))
+
+	home, _ := os.UserHomeDir()
+	probes := make([]probe, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			probes[i] = probeServer(name, mcpConfig.MCPServers[name], home, c.offline)
+		}(i, name)
+	}
+	wg.Wait()
+	for i, name := range names {
+		p, check := probes[i], "mcp:"+name
+		switch p.status {
+		case StatusPass:
+			c.pass(check, p.msg)
+		case StatusInfo:
+			c.info(check, p.msg, p.remediation)
+		case StatusWarn:
+			c.warn(check, p.msg, p.remediation)
+		default:
+			c.fail(check, p.msg, p.remediation)
+		}
+	}
+}
+
+// probeServer checks one server: that a stdio server's command exists (and
+// its npx package resolves), or that a remote server answers.
+func probeServer(name string, s mcpServerEntry, home string, offline bool) probe {
+	if s.URL != "" || s.Type == "http" || s.Type == "sse" {
+		if offline {
+			return probe{StatusInfo, fmt.Sprintf("%s: %s not probed (--offline)", name, s.URL), ""}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		defer cancel()
+		return probeRemote(ctx, name, s)
+	}
+
+	command, args := expandVars(s.Command), make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		args[i] = expandVars(arg)
+	}
+	if inner, ok := mcpsupervisor.Unwrap(args); ok {
+		command, args = inner[0], inner[1:]
+	}
+	if secrets.WrappedNames(args) != nil {
+		for i, arg := range args {
+			if arg == "--" && i+1 < len(args) {
+				command, args = args[i+1], args[i+2:]
+				break
+			}
+		}
+	}
+	if command == "" {
+		return probe{StatusFail, name + ": no command or url configured", "Fix the entry in .mcp.json"}
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		hint := installHints[filepath.Base(command)]
+		if hint == "" {
+			hint = fmt.Sprintf("Install %s or fix the command in .mcp.json", command)
+		}
+		return probe{StatusFail, fmt.Sprintf("%s: command '%s' not found", name, command), hint}
+	}
+	if filepath.Base(command) != "npx" {
+		return probe{StatusPass, fmt.Sprintf("%s: %s found", name, command), ""}
+	}
+
+	pkg := npxPackage(args)
+	switch {
+	case pkg == "":
+		return probe{StatusPass, name + ": npx found", ""}
+	case npxCached(home, pkg):
+		return probe{StatusPass, fmt.Sprintf("%s: npx package %s is cached", name, pkg), ""}
+	case offline:
+		return probe{StatusInfo, fmt.Sprintf("%s: npx package %s is not cached; registry not checked (--offline)", name, pkg), ""}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	return probeNPMPackage(ctx, name, pkg)
+}
+
+// npxPackage returns the package name npx runs, without a version: the value
+// of -p/--package, or else the first argument that is not an npx flag.
+func npxPackage(args []string) string {
+	spec := ""
+	for i := 0; i < len(args) && spec == ""; i++ {
+		arg := args[i]
+		switch {
+		case arg == "-p" || arg == "--package":
+			if i+1 < len(args) {
+				spec = args[i+1]
+			}
+		case strings.HasPrefix(arg, "--package="):
+			spec = strings.TrimPrefix(arg, "--package=")
+		case arg == "--":
+			if i+1 < len(args) {
+				spec = args[i+1]
+			}
+		case strings.HasPrefix(arg, "-"):
+			// -y, --yes, --quiet, ...
+		default:
+			spec = arg
+		}
+	}
+	// Strip a version or tag: pkg@1.2.3, @scope/pkg@latest.
+	if at := strings.LastIndex(spec, "@"); at > 0 {
+		spec = spec[:at]
+	}
+	// Local paths and URLs are not registry packages.
+	if strings.ContainsAny(spec, ":\\") || strings.HasPrefix(spec, ".") || strings.HasPrefix(spec, "/") {
+		return ""
+	}
+	return spec
+}
+
+// npxCached reports whether npx has already installed pkg, in which case it
+// starts without the registry.
+func npxCached(home, pkg string) bool {
+	cache := os.Getenv("npm_config_cache")
+	if cache == "" {
+		cache = filepath.Join(home, ".npm")
+	}
+	matches, _ := filepath.Glob(filepath.Join(cache, "_npx", "*", "node_modules", filepath.FromSlash(pkg), "package.json"))
+	return len(matches) > 0
+}
+
+// npmRegistry returns the registry npx installs from.
+func npmRegistry() string {
+	for _, name := range []string{"npm_config_registry", "NPM_CONFIG_REGISTRY"} {
+		if r := os.Getenv(name); r != "" {
+			return strings.TrimSuffix(r, "/")
+		}
+	}
+	return defaultNPMRegistry
+}
+
+// probeNPMPackage looks pkg up in the npm registry.
+func probeNPMPackage(ctx context.Context, name, pkg string) probe {
+	registry := npmRegistry()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registry+"/"+url.PathEscape(pkg), nil)
+	if err != nil {
+		return probe{StatusWarn, fmt.Sprintf("%s: could not check npx package %s: %v", name, pkg, err), ""}
+	}
+	// The abbreviated document is all npm needs to install, and much smaller.
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
+	resp, err := platform.HTTPClient(probeTimeout).Do(req)
+	if err != nil {
+		return probe{StatusWarn, fmt.Sprintf("%s: could not reach %s to check npx package %s", name, registry, pkg), "Check your network or proxy settings"}
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return probe{StatusPass, fmt.Sprintf("%s: npx package %s resolves in %s", name, pkg, registry), ""}
+	case resp.StatusCode == http.StatusNotFound:
+		return probe{StatusFail, fmt.Sprintf("%s: npx package %s not found in %s", name, pkg, registry), "Check the package name in .mcp.json"}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return probe{StatusWarn, fmt.Sprintf("%s: %s requires authentication for npx package %s (HTTP %d)", name, registry, pkg, resp.StatusCode), "Log in with: npm login --registry " + registry}
+	default:
+		return probe{StatusWarn, fmt.Sprintf("%s: %s answered HTTP %d for npx package %s", name, registry, resp.StatusCode, pkg), ""}
+	}
+}
+
+// probeRemote sends an initialize request to an HTTP server, or opens the
+// event stream of an SSE server, and reports how it answered.
+func probeRemote(ctx context.Context, name string, s mcpServerEntry) probe {
+	target := expandVars(s.URL)
+	method, body := http.MethodPost, []byte(initializeRequest)
+	if s.Type == "sse" {
+		method, body = http.MethodGet, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return probe{StatusFail, fmt.Sprintf("%s: invalid url %q", name, s.URL), "Fix the url in .mcp.json"}
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	hasAuth := false
+	for key, value := range s.Headers {
+		req.Header.Set(key, expandVars(value))
+		hasAuth = hasAuth || strings.EqualFold(key, "Authorization")
+	}
+	resp, err := platform.HTTPClient(probeTimeout).Do(req)
+	if err != nil {
+		return probe{StatusFail, fmt.Sprintf("%s: %s is unreachable: %v", name, s.URL, unwrapURLError(err)), "Check the url and your network or VPN"}
+	}
+	resp.Body.Close()
+	code := resp.StatusCode
+	switch {
+	case code >= 200 && code < 300:
+		return probe{StatusPass, fmt.Sprintf("%s: %s answered (HTTP %d)", name, s.URL, code), ""}
+	case (code == http.StatusUnauthorized || code == http.StatusForbidden) && hasAuth:
+		return probe{StatusWarn, fmt.Sprintf("%s: %s rejected the configured credentials (HTTP %d)", name, s.URL, code),
+			fmt.Sprintf("Renew them with: claude-workspace mcp update %s --oauth (or --bearer)", name)}
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return probe{StatusPass, fmt.Sprintf("%s: %s is reachable and requires sign-in (HTTP %d)", name, s.URL, code), ""}
+	case code == http.StatusNotFound:
+		return probe{StatusFail, fmt.Sprintf("%s: %s was not found (HTTP 404)", name, s.URL), "Check the url in .mcp.json"}
+	case code < 500:
+		return probe{StatusWarn, fmt.Sprintf("%s: %s answered HTTP %d to an MCP request; check the transport type", name, s.URL, code), ""}
+	default:
+		return probe{StatusWarn, fmt.Sprintf("%s: %s returned a server error (HTTP %d)", name, s.URL, code), ""}
+	}
+}
+
+// unwrapURLError drops the method and URL that net/http repeats in errors.
+func unwrapURLError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return ue.Err
+	}
+	return err
+}
+
+// expandVars expands ${VAR} and ${VAR:-default} as Claude Code does in
+// .mcp.json.
+func expandVars(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return os.Expand(s, func(ref string) string {
+		name, def, hasDef := strings.Cut(ref, ":-")
+		if v := os.Getenv(name); v != "" || !hasDef {
+			return v
+		}
+		return def
+	})
+}
@@ -0,0 +1,156 @@
+package doctor
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNpxPackage(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-y", "@modelcontextprotocol/server-github"}, "@modelcontextprotocol/server-github"},
+		{[]string{"--yes", "server-brave-search@1.2.0", "--port", "3000"}, "server-brave-search"},
+		{[]string{"-p", "@scope/tools@latest", "tools-mcp"}, "@scope/tools"},
+		{[]string{"--package=mcp-remote", "mcp-remote", "https://x"}, "mcp-remote"},
+		{[]string{"./local-server"}, ""},
+		{[]string{"github:org/repo"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := npxPackage(tt.args); got != tt.want {
+			t.Errorf("npxPackage(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// fakeNpx puts an npx executable on PATH.
+func fakeNpx(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "npx"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestProbeServer_Stdio(t *testing.T) {
+	fakeNpx(t)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/@scope/server" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer registry.Close()
+	t.Setenv("npm_config_registry", registry.URL)
+	home := t.TempDir()
+	t.Setenv("npm_config_cache", "")
+	cached := filepath.Join(home, ".npm", "_npx", "abc123", "node_modules", "cached-server")
+	os.MkdirAll(cached, 0755)
+	os.WriteFile(filepath.Join(cached, "package.json"), []byte(`{}`), 0644)
+
+	tests := []struct {
+		name    string
+		entry   mcpServerEntry
+		offline bool
+		status  string
+		msg     string
+	}{
+		{"missing", mcpServerEntry{Command: "no-such-launcher-xyz"}, false, StatusFail, "command 'no-such-launcher-xyz' not found"},
+		{"plain", mcpServerEntry{Command: "sh", Args: []string{"-c", "true"}}, false, StatusPass, "sh found"},
+		{"cached", mcpServerEntry{Command: "npx", Args: []string{"-y", "cached-server"}}, false, StatusPass, "is cached"},
+		{"resolves", mcpServerEntry{Command: "npx", Args: []string{"-y", "@scope/server@1.0.0"}}, false, StatusPass, "resolves in"},
+		{"unknown", mcpServerEntry{Command: "npx", Args: []string{"-y", "typo-server"}}, false, StatusFail, "not found in"},
+		{"offline", mcpServerEntry{Command: "npx", Args: []string{"-y", "typo-server"}}, true, StatusInfo, "--offline"},
+		{"wrapped", mcpServerEntry{Command: "/usr/local/bin/claude-workspace", Args: []string{"secrets", "exec", "TOKEN", "--", "no-such-launcher-xyz"}}, false, StatusFail, "no-such-launcher-xyz"},
+	}
+	for _, tt := range tests {
+		p := probeServer(tt.name, tt.entry, home, tt.offline)
+		if p.status != tt.status || !strings.Contains(p.msg, tt.msg) {
+			t.Errorf("%s: probe = %+v, want %s containing %q", tt.name, p, tt.status, tt.msg)
+		}
+	}
+}
+
+func TestProbeServer_Remote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mcp":
+			var req struct {
+				Method string `json:"method"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if r.Method != http.MethodPost || req.Method != "initialize" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		case "/private":
+			if r.Header.Get("Authorization") == "Bearer good" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/sse":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("TEST_MCP_TOKEN", "bad")
+
+	tests := []struct {
+		name   string
+		entry  mcpServerEntry
+		status string
+		msg    string
+	}{
+		{"http", mcpServerEntry{Type: "http", URL: srv.URL + "/mcp"}, StatusPass, "answered (HTTP 200)"},
+		{"sse", mcpServerEntry{Type: "sse", URL: srv.URL + "/sse"}, StatusPass, "answered"},
+		{"needs sign-in", mcpServerEntry{Type: "http", URL: srv.URL + "/private"}, StatusPass, "requires sign-in"},
+		{"bad token", mcpServerEntry{Type: "http", URL: srv.URL + "/private", Headers: map[string]string{"Authorization": "Bearer ${TEST_MCP_TOKEN}"}}, StatusWarn, "rejected the configured credentials"},
+		{"good token", mcpServerEntry{Type: "http", URL: srv.URL + "/private", Headers: map[string]string{"Authorization": "Bearer ${MISSING_VAR:-good}"}}, StatusPass, "answered"},
+		{"wrong path", mcpServerEntry{Type: "http", URL: srv.URL + "/nope"}, StatusFail, "not found"},
+		{"unreachable", mcpServerEntry{Type: "http", URL: "http://127.0.0.1:1/mcp"}, StatusFail, "unreachable"},
+	}
+	for _, tt := range tests {
+		p := probeServer(tt.name, tt.entry, "", false)
+		if p.status != tt.status || !strings.Contains(p.msg, tt.msg) {
+			t.Errorf("%s: probe = %+v, want %s containing %q", tt.name, p, tt.status, tt.msg)
+		}
+	}
+}
+
+func TestCheckMCPServers_PerServer(t *testing.T) {
+	cwd := t.TempDir()
+	os.WriteFile(filepath.Join(cwd, ".mcp.json"), []byte(`{"mcpServers": {
+		"zeta": {"command": "no-such-launcher-xyz"},
+		"alpha": {"type": "http", "url": "https://mcp.example.com/mcp"}
+	}}`), 0644)
+
+	c := &checker{w: io.Discard, offline: true}
+	checkMCPServers(c, cwd)
+	r := c.report()
+	var checks []string
+	for _, res := range r.Results {
+		checks = append(checks, res.Check+"="+res.Status)
+	}
+	want := "mcp-config=pass mcp:alpha=info mcp:zeta=fail"
+	if strings.Join(checks, " ") != want {
+		t.Errorf("results = %s, want %s", strings.Join(checks, " "), want)
+	}
+	if !strings.Contains(r.Results[0].Message, "alpha, zeta") {
+		t.Errorf("server list not sorted: %s", r.Results[0].Message)
+	}
+}
@@ -0,0 +1,169 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/notify"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Status values for a check Result.
+const (
+	StatusPass = "pass"
+	StatusInfo = "info"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Severity values for a check Result. Failures are errors that must be fixed;
+// warnings are optional.
+const (
+	SeverityNone    = "none"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// ErrUnhealthy is returned by "doctor --json" when any check fails (exit 1), so
+// CI pipelines can gate on the exit code without parsing the report.
+var ErrUnhealthy = errors.New("platform health check failed")
+
+// Result is the outcome of a single health check.
+type Result struct {
+	Section     string `json:"section"`
+	Project     string `json:"project,omitempty"` // set when doctor checks named projects
+	Check       string `json:"check"`             // stable identifier, e.g. "claude-cli" or "hook:guard.sh"
+	Status      string `json:"status"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+	Fixable     bool   `json:"fixable,omitempty"` // "doctor --fix" can remediate it
+}
+
+// Report is the machine-readable output of "doctor --json".
+type Report struct {
+	Healthy  bool     `json:"healthy"`
+	Issues   int      `json:"issues"`
+	Warnings int      `json:"warnings"`
+	Results  []Result `json:"results"`
+}
+
+// checker prints each check result to w and records it, along with any
+// remedies "doctor --fix" can apply.
+type checker struct {
+	w       io.Writer
+	offline bool   // skip checks that need the network
+	project string // the project being checked, when named
+	section string
+	results []Result
+	fixes   remedies
+}
+
+// begin starts a new section of checks.
+func (c *checker) begin(section string) {
+	c.section = section
+	if c.project != "" {
+		section += " (" + c.project + ")"
+	}
+	platform.PrintSectionLabel(c.w, section)
+}
+
+func (c *checker) pass(check, msg string) {
+	platform.PrintOK(c.w, msg)
+	c.record(check, StatusPass, SeverityNone, msg, "", nil)
+}
+
+func (c *checker) info(check, msg, remediation string) {
+	platform.PrintInfo(c.w, msg)
+	c.record(check, StatusInfo, SeverityNone, msg, remediation, nil)
+}
+
+func (c *checker) warn(check, msg, remediation string, fixes ...remedy) {
+	platform.PrintWarn(c.w, msg)
+	c.record(check, StatusWarn, SeverityWarning, msg, remediation, fixes)
+}
+
+func (c *checker) fail(check, msg, remediation string, fixes ...remedy) {
+	platform.PrintFail(c.w, msg)
+	c.record(check, StatusFail, SeverityError, msg, remediation, fixes)
+}
+
+func (c *checker) record(check, status, severity, msg, remediation string, fixes []remedy) {
+	for _, line := range strings.Split(remediation, "\n") {
+		if line != "" {
+			fmt.Fprintln(c.w, "    "+line)
+		}
+	}
+	c.fixes = append(c.fixes, fixes...)
+	c.results = append(c.results, Result{
+		Section:     c.section,
+		Project:     c.project,
+		Check:       check,
+		Status:      status,
+		Severity:    severity,
+		Message:     msg,
+		Remediation: remediation,
+		Fixable:     len(fixes) > 0,
+	})
+}
+
+// report summarizes the recorded results.
+func (c *checker) report() *Report {
+	r := &Report{Results: c.results}
+	if r.Results == nil {
+		r.Results = []Result{}
+	}
+	for _, res := range c.results {
+		switch res.Status {
+		case StatusFail:
+			r.Issues++
+		case StatusWarn:
+			r.Warnings++
+		}
+	}
+	r.Healthy = r.Issues == 0
+	return r
+}
+
+// notifyRegressions sends a doctor-regression notification when checks fail
+// that passed the last time doctor ran in this directory. Failing checks are
+// only recorded while a channel subscribes to the event.
+func notifyRegressions(r *Report) {
+	if !notify.Enabled(notify.EventDoctorRegression) {
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	messages := make(map[string]string)
+	var failing []string
+	for _, res := range r.Results {
+		if res.Status == StatusFail {
+			failing = append(failing, res.Check)
+			messages[res.Check] = res.Message
+		}
+	}
+	regressed, err := notify.Regressed("doctor:"+cwd, failing)
+	if err != nil {
+		platform.Logger().Warn("doctor: recording results for notifications", "error", err.Error())
+		return
+	}
+	if len(regressed) == 0 {
+		return
+	}
+	lines := make([]string, len(regressed))
+	for i, check := range regressed {
+		lines[i] = "- " + messages[check]
+	}
+	notify.Send(notify.Event{
+		Event:   notify.EventDoctorRegression,
+		Status:  notify.StatusAlert,
+		Title:   fmt.Sprintf("doctor: %d check(s) started failing in %s", len(regressed), filepath.Base(cwd)),
+		Message: strings.Join(lines, "\n"),
+	})
+}
@@ -0,0 +1,258 @@
+package enrich
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/agents"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/skills"
+)
+
+// assetKind is a kind of .claude asset that enrich can propose.
+type assetKind struct {
+	label  string // "agent" or "skill"
+	dir    string // directory under .claude/
+	format string // file format shown to Claude in the prompt
+}
+
+var (
+	agentKind = assetKind{
+		label: "agent",
+		dir:   "agents",
+		format: `---
+name: <kebab-case-name>
+description: <when Claude should delegate to this agent; start with what it does, then "Use when ...">
+tools: <comma-separated tools, e.g. Read, Grep, Glob, Bash, Edit, Write>
+model: sonnet
+---
+
+<system prompt: the agent's role, the project-specific files, commands and conventions it must follow, and a step-by-step process>`,
+	}
+	skillKind = assetKind{
+		label: "skill",
+		dir:   "skills",
+		format: `---
+name: <kebab-case-name>
+description: <what the skill does and when to use it>
+---
+
+# <Title>
+
+<step-by-step instructions that reference the project's real files and commands>`,
+	}
+)
+
+// path returns the location of an asset named name, relative to .claude/.
+func (k assetKind) path(name string) string {
+	if k == skillKind {
+		return filepath.Join(k.dir, name, "SKILL.md")
+	}
+	return filepath.Join(k.dir, name+".md")
+}
+
+// Proposal is an agent or skill file suggested by Claude.
+type Proposal struct {
+	Kind        assetKind
+	Name        string
+	Description string
+	Content     string
+}
+
+const (
+	proposalStart = "=== PROPOSAL: "
+	proposalEnd   = "=== END PROPOSAL ==="
+)
+
+var assetNameRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// enrichAssets asks Claude for project-specific agents and/or skills and lets
+// the user review each one before it is written under .claude/. Existing
+// files are never overwritten.
+func enrichAssets(w io.Writer, in *bufio.Reader, projectDir string, kinds []assetKind, autoYes bool) error {
+	claudeDir := filepath.Join(projectDir, ".claude")
+	for i, kind := range kinds {
+		platform.PrintStep(w, i+1, len(kinds), fmt.Sprintf("Proposing project-specific %ss...", kind.label))
+		prompt := buildAssetPrompt(projectDir, kind, existingAssets(claudeDir, kind))
+		stdout, _, err := platform.RunClaudeAnalysis(projectDir, prompt)
+		if err != nil {
+			return err
+		}
+		proposals, err := parseProposals(stdout, kind)
+		if err != nil {
+			return err
+		}
+		if len(proposals) == 0 {
+			platform.PrintInfo(w, fmt.Sprintf("No %ss proposed; the existing ones already cover this project", kind.label))
+			continue
+		}
+		if err := reviewProposals(w, in, claudeDir, proposals, autoYes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// existingAssets lists "name: description" for the assets of kind already in
+// claudeDir, so Claude does not propose duplicates.
+func existingAssets(claudeDir string, kind assetKind) []string {
+	var out []string
+	root := filepath.Join(claudeDir, kind.dir)
+	if kind == skillKind {
+		for _, s := range skills.DiscoverSkills(root) {
+			out = append(out, s.Name+": "+s.Description)
+		}
+		return out
+	}
+	for _, a := range agents.DiscoverAgents(root) {
+		out = append(out, a.Name+": "+a.Description)
+	}
+	return out
+}
+
+func buildAssetPrompt(projectDir string, kind assetKind, existing []string) string {
+	existingList := "(none)"
+	if len(existing) > 0 {
+		existingList = "- " + strings.Join(existing, "\n- ")
+	}
+	return fmt.Sprintf(`You are analyzing a software project to propose project-specific Claude Code %[1]ss.
+
+The project is located at: %[2]s
+
+The project already has these %[1]ss (generic ones ship with the platform):
+%[3]s
+
+Your task:
+1. Explore the project: README, CLAUDE.md, dependency files, directory layout, build and test tooling, and source files
+2. Identify 1-4 recurring, project-specific workflows that would clearly benefit from a dedicated %[1]s
+   (for example, a "migrations" agent for a Rails app, or a "regenerate-protos" skill for a gRPC service)
+3. Do NOT propose anything that duplicates an existing %[1]s listed above
+4. Output each proposal in exactly this form, with nothing before, between, or after the proposals:
+
+%[4]s<name>
+%[5]s
+%[6]s
+
+Rules:
+- Names are lowercase kebab-case
+- Only reference files, commands, and conventions you have verified exist in the project
+- Keep each file under 120 lines
+- If nothing project-specific is worth adding, output nothing`,
+		kind.label, projectDir, existingList, proposalStart, kind.format, proposalEnd)
+}
+
+// parseProposals extracts the proposals from Claude's output. Proposals with
+// an invalid name or no frontmatter are skipped.
+func parseProposals(out string, kind assetKind) ([]Proposal, error) {
+	var proposals []Proposal
+	seen := map[string]bool{}
+	for {
+		start := strings.Index(out, proposalStart)
+		if start < 0 {
+			break
+		}
+		out = out[start+len(proposalStart):]
+		header, body, ok := strings.Cut(out, "\n")
+		if !ok {
+			break
+		}
+		end := strings.Index(body, proposalEnd)
+		if end < 0 {
+			return nil, fmt.Errorf("proposal %q is missing %q", strings.TrimSpace(header), proposalEnd)
+		}
+		out = body[end+len(proposalEnd):]
+
+		name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(header), "==="))
+		content := strings.TrimSpace(body[:end])
+		if !assetNameRe.MatchString(name) || seen[name] || !strings.HasPrefix(content, "---") {
+			continue
+		}
+		seen[name] = true
+		proposals = append(proposals, Proposal{
+			Kind:        kind,
+			Name:        name,
+			Description: frontmatterValue(content, "description"),
+			Content:     content + "\n",
+		})
+	}
+	return proposals, nil
+}
+
+// frontmatterValue returns the value of key in the YAML frontmatter of content.
+func frontmatterValue(content, key string) string {
+	lines := strings.Split(content, "\n")
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+		if v, ok := strings.CutPrefix(line, key+":"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// reviewProposals shows each proposal and writes the accepted ones. With
+// autoYes every proposal is accepted; without a terminal and without autoYes,
+// proposals are only listed.
+func reviewProposals(w io.Writer, in *bufio.Reader, claudeDir string, proposals []Proposal, autoYes bool) error {
+	interactive := in != nil
+	written := 0
+	for _, p := range proposals {
+		rel := filepath.Join(".claude", p.Kind.path(p.Name))
+		target := filepath.Join(claudeDir, p.Kind.path(p.Name))
+		platform.PrintSection(w, fmt.Sprintf("%s: %s", p.Kind.label, p.Name))
+		if p.Description != "" {
+			fmt.Fprintf(w, "  %s\n", p.Description)
+		}
+		fmt.Fprintf(w, "  %s (%d lines)\n", rel, strings.Count(p.Content, "\n"))
+
+		if platform.FileExists(target) {
+			platform.PrintWarn(w, rel+" already exists; skipping")
+			continue
+		}
+
+		accept := autoYes
+		for !accept && interactive {
+			platform.PrintPrompt(w, "  Write it? [y/N/v=view] ")
+			line, err := in.ReadString('\n')
+			answer := strings.ToLower(strings.TrimSpace(line))
+			if answer == "v" && err == nil {
+				fmt.Fprintln(w)
+				fmt.Fprint(w, p.Content)
+				fmt.Fprintln(w)
+				continue
+			}
+			accept = answer == "y" || answer == "yes"
+			break
+		}
+		if !accept {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, []byte(p.Content), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", rel, err)
+		}
+		platform.PrintOK(w, "Wrote "+rel)
+		written++
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case !interactive && !autoYes:
+		fmt.Fprintln(w, "  Nothing written. Re-run in a terminal to review each proposal, or with --yes to accept all.")
+	case written == 0:
+		fmt.Fprintln(w, "  Nothing written.")
+	default:
+		platform.PrintSuccess(w, fmt.Sprintf("Wrote %d file(s). Review them and commit what you want to keep.", written))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package enrich
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleProposals = `Here is what I found.
+
+=== PROPOSAL: migrations
+---
+name: migrations
+description: Writes and reviews Rails migrations. Use when changing the schema.
+tools: Read, Grep, Bash
+---
+
+Run bin/rails db:migrate after editing db/migrate.
+=== END PROPOSAL ===
+
+=== PROPOSAL: Bad Name
+---
+name: bad
+---
+=== END PROPOSAL ===
+
+=== PROPOSAL: no-frontmatter
+just text
+=== END PROPOSAL ===
+`
+
+func TestParseProposals(t *testing.T) {
+	proposals, err := parseProposals(sampleProposals, agentKind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proposals) != 1 {
+		t.Fatalf("got %d proposals, want 1 (invalid ones skipped): %+v", len(proposals), proposals)
+	}
+	p := proposals[0]
+	if p.Name != "migrations" || !strings.HasPrefix(p.Description, "Writes and reviews") {
+		t.Errorf("proposal = %+v", p)
+	}
+	if !strings.HasSuffix(p.Content, "db/migrate.\n") || strings.Contains(p.Content, "END PROPOSAL") {
+		t.Errorf("content = %q", p.Content)
+	}
+
+	if _, err := parseProposals("=== PROPOSAL: x\n---\nname: x\n---\n", agentKind); err == nil {
+		t.Error("unterminated proposal should be an error")
+	}
+	if got, _ := parseProposals("nothing to add", skillKind); len(got) != 0 {
+		t.Errorf("got %d proposals from empty output", len(got))
+	}
+}
+
+func TestAssetKindPath(t *testing.T) {
+	if got := agentKind.path("migrations"); got != filepath.Join("agents", "migrations.md") {
+		t.Errorf("agent path = %s", got)
+	}
+	if got := skillKind.path("regen"); got != filepath.Join("skills", "regen", "SKILL.md") {
+		t.Errorf("skill path = %s", got)
+	}
+}
+
+func TestBuildAssetPrompt(t *testing.T) {
+	prompt := buildAssetPrompt("/src/app", skillKind, []string{"pr-workflow: Open PRs"})
+	for _, want := range []string{"project-specific Claude Code skills", "/src/app", "- pr-workflow: Open PRs", proposalStart + "<name>", proposalEnd} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestReviewProposals(t *testing.T) {
+	claudeDir := filepath.Join(t.TempDir(), ".claude")
+	proposals := []Proposal{
+		{Kind: skillKind, Name: "regen", Content: "---\nname: regen\n---\nbody\n"},
+		{Kind: agentKind, Name: "migrations", Content: "---\nname: migrations\n---\n"},
+		{Kind: agentKind, Name: "existing", Content: "---\nname: existing\n---\nnew\n"},
+	}
+	existing := filepath.Join(claudeDir, "agents", "existing.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// View then accept the skill, reject the agent; the existing file is never asked about.
+	var out strings.Builder
+	in := bufio.NewReader(strings.NewReader("v\ny\nn\n"))
+	if err := reviewProposals(&out, in, claudeDir, proposals, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(claudeDir, "skills", "regen", "SKILL.md")); err != nil || !strings.Contains(string(data), "body") {
+		t.Errorf("accepted skill not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(claudeDir, "agents", "migrations.md")); !os.IsNotExist(err) {
+		t.Error("rejected agent was written")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Error("existing agent was overwritten")
+	}
+	if !strings.Contains(out.String(), "already exists") {
+		t.Errorf("output missing skip notice:\n%s", out.String())
+	}
+
+	// Non-interactive without --yes only lists.
+	out.Reset()
+	other := filepath.Join(t.TempDir(), ".claude")
+	if err := reviewProposals(&out, nil, other, proposals[:1], false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Error("non-interactive review wrote files")
+	}
+	if !strings.Contains(out.String(), "--yes") {
+		t.Errorf("output missing --yes hint:\n%s", out.String())
+	}
+
+	// --yes writes without asking.
+	if err := reviewProposals(&out, nil, other, proposals[1:2], true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(other, "agents", "migrations.md")); err != nil {
+		t.Errorf("--yes did not write: %v", err)
+	}
+}
+
+func TestRun_AssetsWithScaffoldOnly(t *testing.T) {
+	if err := Run(t.TempDir(), []string{"--agents", "--scaffold-only"}); err == nil {
+		t.Error("expected error combining --agents with --scaffold-only")
+	}
+}
@@ -1,23 +1,84 @@
 // Package enrich implements the "enrich" command, which generates or regenerates
-// a project's .claude/CLAUDE.md file using AI-powered analysis of the codebase.
+// a project's .claude/CLAUDE.md file using AI-powered analysis of the codebase,
+// and proposes project-specific agents and skills.
 package enrich
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/slashcommands"
 )
 
 // Run executes the enrich command for the given project path. It generates a
 // static scaffold if one does not exist, then optionally enriches it with AI
-// analysis. Pass --scaffold-only in args to skip AI enrichment.
+// analysis. Pass --scaffold-only in args to skip AI enrichment, or
+// --static-deep to fill Key Directories, Important Files, and Conventions from
+// a static analysis of the project instead of calling claude. When
+// projectPath is "", a positional argument in args names the project.
 //
 // If .claude/CLAUDE.md already exists, the scaffold and enrichment target
-// .claude/rules/platform.md instead (non-destructive).
+// .claude/rules/platform.md instead (non-destructive). Slash commands are
+// added under .claude/commands for the build, test, and deploy tasks the
+// project defines, keeping any that exist.
+//
+// With --section, only the named sections of an existing .claude/CLAUDE.md
+// are regenerated, with claude or, with --static-deep, statically, and the
+// rest of the file is kept byte for byte.
+//
+// With --stack, the Tech Stack, Build, Test, and Lint lines of
+// .claude/CLAUDE.md are checked against the static project detectors and the
+// lines that drifted are shown and, once confirmed (--yes), rewritten. The
+// PostToolUse hook attach installs runs "--stack --stdin-json" after
+// dependency changes; it only reports drift.
+//
+// With --agents and/or --skills, CLAUDE.md is left alone and Claude proposes
+// project-specific agents or skills instead, each reviewed before it is written
+// (--yes accepts all).
+//
+// With --monorepo, each package of the pnpm, npm, go.work, Cargo, or Nx
+// workspace gets its own CLAUDE.md scaffold and enrichment, and the root
+// CLAUDE.md gets a "Key Packages" section summarizing them.
 func Run(projectPath string, args []string) error {
-	scaffoldOnly := contains(args, "--scaffold-only")
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if projectPath == "" {
+		projectPath = opts.path
+	}
+	mode := aiEnrich
+	switch {
+	case opts.scaffoldOnly && opts.staticDeep:
+		return fmt.Errorf("--scaffold-only cannot be combined with --static-deep")
+	case opts.scaffoldOnly:
+		mode = scaffoldOnly
+	case opts.staticDeep:
+		mode = staticDeep
+	}
+	var kinds []assetKind
+	if opts.agents {
+		kinds = append(kinds, agentKind)
+	}
+	if opts.skills {
+		kinds = append(kinds, skillKind)
+	}
+	if len(kinds) > 0 && mode != aiEnrich {
+		return fmt.Errorf("--scaffold-only and --static-deep cannot be combined with --agents or --skills")
+	}
+	if len(opts.sections) > 0 && (mode == scaffoldOnly || len(kinds) > 0 || opts.monorepo) {
+		return fmt.Errorf("--section cannot be combined with --scaffold-only, --monorepo, --agents, or --skills")
+	}
+	if opts.stack && (mode != aiEnrich || len(kinds) > 0 || opts.monorepo || len(opts.sections) > 0) {
+		return fmt.Errorf("--stack cannot be combined with other enrich modes")
+	}
+	if opts.stdinJSON {
+		return runStackHook(os.Stdin, os.Stdout, os.Getenv("CLAUDE_PROJECT_DIR"))
+	}
 
 	// Resolve project dir (default to cwd)
 	projectDir := projectPath
@@ -29,7 +90,6 @@ func Run(projectPath string, args []string) error {
 		projectDir = cwd
 	}
 
-	var err error
 	projectDir, err = filepath.Abs(projectDir)
 	if err != nil {
 		return fmt.Errorf("resolving path: %w", err)
@@ -39,6 +99,85 @@ func Run(projectPath string, args []string) error {
 		return fmt.Errorf("project directory not found: %s", projectDir)
 	}
 
+	if len(kinds) > 0 || opts.stack {
+		var in *bufio.Reader
+		if platform.IsTTY() {
+			in = bufio.NewReader(os.Stdin)
+		}
+		if opts.stack {
+			return refreshStack(os.Stdout, in, projectDir, opts.yes)
+		}
+		return enrichAssets(os.Stdout, in, projectDir, kinds, opts.yes)
+	}
+
+	if len(opts.sections) > 0 {
+		return enrichSections(projectDir, opts.sections, mode)
+	}
+
+	if !opts.monorepo {
+		return enrichProject(projectDir, mode)
+	}
+
+	ws := platform.DetectWorkspace(projectDir)
+	if ws == nil {
+		return fmt.Errorf("--monorepo: no pnpm-workspace.yaml, package.json workspaces, go.work, Cargo.toml [workspace], or nx.json with members found in %s", projectDir)
+	}
+	platform.PrintInfo(os.Stdout, fmt.Sprintf("Workspace: %s (%d packages)", ws.Config, len(ws.Members)))
+	for _, m := range ws.Members {
+		enrichPackage(projectDir, filepath.Join(projectDir, filepath.FromSlash(m)), mode)
+	}
+	if err := enrichProject(projectDir, mode); err != nil {
+		return err
+	}
+	claudeMdPath := filepath.Join(projectDir, ".claude", "CLAUDE.md")
+	if err := platform.UpdateKeyPackages(projectDir, ws, claudeMdPath); err != nil {
+		return fmt.Errorf("updating Key Packages: %w", err)
+	}
+	platform.PrintSuccess(os.Stdout, "Updated Key Packages in .claude/CLAUDE.md")
+	return nil
+}
+
+// enrichMode is how enrich fills in a scaffold.
+type enrichMode int
+
+const (
+	aiEnrich     enrichMode = iota // claude -p rewrites the file
+	scaffoldOnly                   // the static scaffold is left as is
+	staticDeep                     // sections are filled from static analysis
+)
+
+// enrichPackage scaffolds and enriches the CLAUDE.md of one monorepo member.
+// An existing CLAUDE.md is left alone.
+func enrichPackage(rootDir, pkgDir string, mode enrichMode) {
+	path := filepath.Join(pkgDir, "CLAUDE.md")
+	rel, _ := filepath.Rel(rootDir, path)
+	if platform.FileExists(path) {
+		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("%s already exists. Skipping.", rel))
+		return
+	}
+	if err := os.WriteFile(path, []byte(platform.GeneratePackageScaffold(rootDir, pkgDir)), 0644); err != nil {
+		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Writing %s: %v", rel, err))
+		return
+	}
+	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Created %s scaffold", rel))
+	switch mode {
+	case scaffoldOnly:
+		return
+	case staticDeep:
+		if err := platform.EnrichPackageClaudeMdStatic(pkgDir, path); err != nil {
+			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Note: %v", err))
+		}
+		return
+	}
+	platform.PrintStep(os.Stdout, 1, 1, fmt.Sprintf("Enriching %s with package context...", rel))
+	if err := platform.EnrichPackageClaudeMd(rootDir, pkgDir, path); err != nil {
+		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Note: %v", err))
+		fmt.Printf("  Using static scaffold. Edit %s to customize.\n", rel)
+	}
+}
+
+// enrichProject scaffolds and enriches the project's own instructions file.
+func enrichProject(projectDir string, mode enrichMode) error {
 	claudeDir := filepath.Join(projectDir, ".claude")
 	claudeMdPath := filepath.Join(claudeDir, "CLAUDE.md")
 
@@ -70,7 +209,11 @@ func Run(projectPath string, args []string) error {
 		scaffoldGenerated = true
 	}
 
-	if scaffoldOnly {
+	if _, err := slashcommands.Generate(os.Stdout, projectDir, false); err != nil {
+		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Note: %v", err))
+	}
+
+	if mode == scaffoldOnly {
 		if !scaffoldGenerated {
 			relTarget, _ := filepath.Rel(projectDir, targetPath)
 			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("%s already exists. Skipping scaffold generation.", relTarget))
@@ -78,8 +221,16 @@ func Run(projectPath string, args []string) error {
 		return nil
 	}
 
-	// Run AI enrichment
 	relTarget, _ := filepath.Rel(projectDir, targetPath)
+	if mode == staticDeep {
+		platform.PrintStep(os.Stdout, 1, 1, fmt.Sprintf("Filling %s from static analysis...", relTarget))
+		if err := platform.EnrichClaudeMdStatic(projectDir, targetPath); err != nil {
+			return fmt.Errorf("static enrichment: %w", err)
+		}
+		return nil
+	}
+
+	// Run AI enrichment
 	platform.PrintStep(os.Stdout, 1, 1, fmt.Sprintf("Enriching %s with project context...", relTarget))
 	if err := platform.EnrichClaudeMd(projectDir, targetPath); err != nil {
 		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Note: %v", err))
@@ -92,11 +243,16 @@ func Run(projectPath string, args []string) error {
 	return nil
 }
 
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
+// enrichSections regenerates only the given sections of the project's
+// .claude/CLAUDE.md.
+func enrichSections(projectDir string, headings []string, mode enrichMode) error {
+	claudeMdPath := filepath.Join(projectDir, ".claude", "CLAUDE.md")
+	if !platform.FileExists(claudeMdPath) {
+		return fmt.Errorf("--section: %s not found (run enrich without --section first)", claudeMdPath)
+	}
+	if mode == staticDeep {
+		return platform.EnrichSectionsStatic(projectDir, claudeMdPath, headings)
 	}
-	return false
+	platform.PrintStep(os.Stdout, 1, 1, fmt.Sprintf("Regenerating %s in .claude/CLAUDE.md...", strings.Join(headings, ", ")))
+	return platform.EnrichSections(projectDir, claudeMdPath, headings)
 }
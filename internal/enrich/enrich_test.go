@@ -115,3 +115,115 @@ func TestRun_DefaultsToCwd(t *testing.T) {
 		t.Fatalf("Run() with empty path unexpected error: %v", err)
 	}
 }
+
+func TestRun_MonorepoScaffoldOnly(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"Cargo.toml":             "[workspace]\nmembers = [\"crates/*\"]\n",
+		"crates/core/Cargo.toml": "[package]\nname = \"core\"\n",
+		"crates/cli/Cargo.toml":  "[package]\nname = \"cli\"\n",
+		"crates/cli/CLAUDE.md":   "# CLI\n\nPurpose: Command-line entry point\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte(content), 0644)
+	}
+
+	if err := Run(dir, []string{"--monorepo", "--scaffold-only"}); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	core, err := os.ReadFile(filepath.Join(dir, "crates", "core", "CLAUDE.md"))
+	if err != nil || !strings.Contains(string(core), "# Package Instructions: crates/core") {
+		t.Errorf("crates/core/CLAUDE.md = %q, %v", core, err)
+	}
+	cli, _ := os.ReadFile(filepath.Join(dir, "crates", "cli", "CLAUDE.md"))
+	if !strings.HasPrefix(string(cli), "# CLI") {
+		t.Error("existing package CLAUDE.md should not be overwritten")
+	}
+
+	root, err := os.ReadFile(filepath.Join(dir, ".claude", "CLAUDE.md"))
+	if err != nil {
+		t.Fatalf("root CLAUDE.md not created: %v", err)
+	}
+	for _, want := range []string{"## Key Packages", "- `crates/cli/` - cli: Command-line entry point", "- `crates/core/` - core: Rust"} {
+		if !strings.Contains(string(root), want) {
+			t.Errorf("root CLAUDE.md missing %q:\n%s", want, root)
+		}
+	}
+}
+
+func TestRun_MonorepoWithoutWorkspace(t *testing.T) {
+	if err := Run(t.TempDir(), []string{"--monorepo", "--scaffold-only"}); err == nil {
+		t.Error("expected error when no workspace is found")
+	}
+}
+
+func TestRun_StaticDeep(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	_ = os.MkdirAll(filepath.Join(dir, "internal", "store"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "internal", "store", "store.go"), []byte("// Package store persists orders in Postgres.\npackage store\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, ".golangci.yml"), []byte("linters:\n  enable: [errcheck]\n"), 0644)
+
+	if err := Run(dir, []string{"--static-deep"}); err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".claude", "CLAUDE.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"internal/store/ - Persists orders in Postgres",
+		"## Important Files\n<!-- claude-workspace:begin important-files -->\n- go.mod - Go module definition and dependencies",
+		"- Go code is linted with golangci-lint (.golangci.yml)",
+		"## Team Execution",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("CLAUDE.md missing %q:\n%s", want, content)
+		}
+	}
+
+	for _, args := range [][]string{{"--static-deep", "--scaffold-only"}, {"--static-deep", "--agents"}} {
+		if err := Run(dir, args); err == nil {
+			t.Errorf("Run(%q): expected error", args)
+		}
+	}
+}
+
+func TestRun_SectionStatic(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	_ = os.MkdirAll(filepath.Join(dir, "internal", "store"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "internal", "store", "store.go"), []byte("// Package store persists orders in Postgres.\npackage store\n"), 0644)
+	claudeMd := filepath.Join(dir, ".claude", "CLAUDE.md")
+
+	if err := Run(dir, []string{"--section", "key-directories"}); err == nil {
+		t.Error("Run(--section) without a CLAUDE.md: expected error")
+	}
+
+	if err := Run(dir, []string{"--static-deep"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(claudeMd)
+	edited := strings.Replace(string(data), "## Important Notes\n", "## Important Notes\n- Hand-written note\n", 1)
+	edited = strings.Replace(edited, "<!-- claude-workspace:end key-directories -->\n", "<!-- claude-workspace:end key-directories -->\nSee also docs/.\n", 1)
+	_ = os.WriteFile(claudeMd, []byte(edited), 0644)
+	_ = os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+
+	if err := Run("", []string{dir, "--section=key-directories", "--static-deep"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(claudeMd)
+	want := strings.Replace(edited, "- internal/ - Private packages\n", "- docs/ - Documentation\n- internal/ - Private packages\n", 1)
+	if string(data) != want {
+		t.Errorf("CLAUDE.md after --section =\n%s\nwant\n%s", data, want)
+	}
+
+	for _, args := range [][]string{{"--section", "notes"}, {"--section", "conventions", "--scaffold-only"}, {"--section", "conventions", "--monorepo"}} {
+		if err := Run(dir, args); err == nil {
+			t.Errorf("Run(%q): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package enrich
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const usage = "enrich [project-path] [--scaffold-only | --static-deep] [--section <name>] [--monorepo] [--agents] [--skills] [--stack] [--yes]"
+
+// options holds parsed flags for the enrich command.
+type options struct {
+	path         string // project path given as an argument, "" for the cwd
+	scaffoldOnly bool
+	staticDeep   bool
+	monorepo     bool
+	agents       bool
+	skills       bool
+	yes          bool
+	sections     []string // --section: headings of the sections to regenerate
+	stack        bool     // --stack: refresh the Tech Stack, Build, Test, and Lint lines
+	stdinJSON    bool     // --stdin-json: with --stack, run as a PostToolUse hook
+}
+
+// parseArgs parses enrich command arguments.
+func parseArgs(args []string) (options, error) {
+	var o options
+	f := cli.New("enrich", usage)
+	f.BoolVar(&o.scaffoldOnly, "--scaffold-only", "Generate static scaffold only (skip AI enrichment)")
+	f.BoolVar(&o.staticDeep, "--static-deep", "Fill directories, files, and conventions without AI")
+	f.Func("--section", "name", "Regenerate only this section: key-directories, conventions, or important-files (repeatable, or comma-separated)", func(v string) error {
+		for _, key := range strings.Split(v, ",") {
+			heading := platform.SectionHeading(strings.TrimSpace(key))
+			if heading == "" {
+				return fmt.Errorf("unknown section %q (use key-directories, conventions, or important-files)", key)
+			}
+			o.sections = appendUnique(o.sections, heading)
+		}
+		return nil
+	})
+	f.BoolVar(&o.monorepo, "--monorepo", "Enrich each workspace package and list them at the root")
+	f.BoolVar(&o.agents, "--agents", "Propose project-specific agents for review")
+	f.BoolVar(&o.skills, "--skills", "Propose project-specific skills for review")
+	f.BoolVar(&o.stack, "--stack", "Update Tech Stack, Build, Test, and Lint lines that no longer match the project")
+	f.BoolVar(&o.stdinJSON, "--stdin-json", "With --stack, read a PostToolUse hook event and report drift without writing")
+	f.BoolVar(&o.yes, "--yes", "Write every proposal or update without asking")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return o, err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return o, err
+	}
+	if len(positional) == 1 {
+		o.path = positional[0]
+	}
+	if o.stdinJSON && (!o.stack || o.path != "") {
+		return o, fmt.Errorf("--stdin-json is only for the hook: claude-workspace enrich --stack --stdin-json")
+	}
+	return o, nil
+}
+
+// appendUnique appends s to list unless it is already there.
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,215 @@
+package enrich
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// stackFiles are the files whose changes can change what the project
+// detectors report for the Tech Stack, Build, Test, and Lint lines.
+var stackFiles = map[string]bool{
+	"package.json": true, "pnpm-workspace.yaml": true, "nx.json": true,
+	"go.mod": true, "go.work": true, "Cargo.toml": true,
+	"pyproject.toml": true, "requirements.txt": true,
+	"pom.xml": true, "build.gradle": true, "build.gradle.kts": true,
+	"Gemfile": true, "composer.json": true, "mix.exs": true, "Package.swift": true,
+	"build.sbt": true, "CMakeLists.txt": true, "MODULE.bazel": true, "WORKSPACE": true,
+	"Makefile": true, "justfile": true, "Taskfile.yml": true,
+}
+
+// installRe matches shell commands that add or remove dependencies, which
+// change a stack file without a Write or Edit call.
+var installRe = regexp.MustCompile(`\b(?:(?:npm|pnpm|yarn|bun) (?:add|install|i|remove|uninstall|rm)|go (?:get|mod)|cargo (?:add|remove)|(?:uv|poetry) (?:add|remove)|bundle (?:add|remove)|composer (?:require|remove))\b`)
+
+// stackChange is one scaffold line that no longer matches the project.
+type stackChange struct {
+	Field string // "Tech Stack", "Build", "Test", or "Lint"
+	Old   string // "" when CLAUDE.md has no such line
+	New   string
+}
+
+// stackChanges compares the Tech Stack, Build, Test, and Lint lines of
+// content with what the project detectors find in projectDir. A Tech Stack
+// line only changes when it leaves out something detected, since an enriched
+// line often says more than the detectors do; a command line changes when the
+// detected command differs. Lines the detectors have nothing for are kept.
+func stackChanges(projectDir, content string) []stackChange {
+	var changes []stackChange
+	stack := platform.DetectTechStack(projectDir)
+	if stack != "Unknown" {
+		old := platform.MarkdownField(content, "Tech Stack")
+		if missing := missingTech(old, stack); len(missing) > 0 {
+			updated := stack
+			if old != "" && old != "Unknown" {
+				updated = old + ", " + strings.Join(missing, ", ")
+			}
+			changes = append(changes, stackChange{"Tech Stack", old, updated})
+		}
+	}
+	build, test, lint := platform.DetectCommands(projectDir)
+	for _, c := range []struct{ field, cmd string }{{"Build", build}, {"Test", test}, {"Lint", lint}} {
+		if c.cmd == "" {
+			continue
+		}
+		old := platform.MarkdownField(content, c.field)
+		if strings.Trim(old, "`") != c.cmd {
+			changes = append(changes, stackChange{c.field, old, "`" + c.cmd + "`"})
+		}
+	}
+	return changes
+}
+
+// missingTech returns the parts of the detected stack, such as "Go" in
+// "Go, TypeScript", that line does not mention.
+func missingTech(line, detected string) []string {
+	// A workspace stack ends in "(pnpm workspace, 3 packages)".
+	detected, _, _ = strings.Cut(detected, " (")
+	lower := strings.ToLower(line)
+	var missing []string
+	for _, tech := range strings.Split(detected, ", ") {
+		if tech != "" && !strings.Contains(lower, strings.ToLower(tech)) {
+			missing = append(missing, tech)
+		}
+	}
+	return missing
+}
+
+// applyStackChanges writes changes into content. A missing command line is
+// added after the last line before it in the scaffold's order.
+func applyStackChanges(content string, changes []stackChange) string {
+	order := []string{"Tech Stack", "Build", "Test", "Lint"}
+	for _, c := range changes {
+		after := ""
+		for _, f := range order {
+			if f == c.Field {
+				break
+			}
+			if platform.MarkdownField(content, f) != "" {
+				after = f
+			}
+		}
+		content = platform.SetMarkdownField(content, c.Field, c.New, after)
+	}
+	return content
+}
+
+// printStackChanges lists changes as "Field: old -> new" lines.
+func printStackChanges(w io.Writer, changes []stackChange) {
+	for _, c := range changes {
+		old := c.Old
+		if old == "" {
+			old = "(none)"
+		}
+		fmt.Fprintf(w, "    %s: %s -> %s\n", c.Field, old, c.New)
+	}
+}
+
+// refreshStack implements "enrich --stack": it shows the scaffold lines of
+// .claude/CLAUDE.md that the project has drifted from and, once confirmed,
+// rewrites just those lines. Without a terminal, --yes is needed to write.
+func refreshStack(w io.Writer, in *bufio.Reader, projectDir string, yes bool) error {
+	path := filepath.Join(projectDir, ".claude", "CLAUDE.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("--stack: %s not found (run enrich without --stack first)", path)
+		}
+		return fmt.Errorf("reading CLAUDE.md: %w", err)
+	}
+	changes := stackChanges(projectDir, string(data))
+	if len(changes) == 0 {
+		platform.PrintOK(w, "Tech Stack, Build, Test, and Lint in .claude/CLAUDE.md match the project")
+		return nil
+	}
+	platform.PrintInfo(w, "The project no longer matches .claude/CLAUDE.md:")
+	printStackChanges(w, changes)
+	if !yes {
+		if in == nil {
+			fmt.Fprintln(w, "  Run with --yes to update these lines.")
+			return nil
+		}
+		platform.PrintPrompt(w, "  Update these lines? [y/N] ")
+		answer, _ := in.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			platform.PrintWarn(w, "Left .claude/CLAUDE.md unchanged")
+			return nil
+		}
+	}
+	if err := os.WriteFile(path, []byte(applyStackChanges(string(data), changes)), 0644); err != nil {
+		return fmt.Errorf("writing CLAUDE.md: %w", err)
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Updated %d line(s) in .claude/CLAUDE.md", len(changes)))
+	return nil
+}
+
+// stackHookEvent is the part of a Claude Code PostToolUse event the stack
+// hook reads.
+type stackHookEvent struct {
+	Cwd       string `json:"cwd"`
+	ToolInput struct {
+		FilePath string `json:"file_path"`
+		Command  string `json:"command"`
+	} `json:"tool_input"`
+}
+
+// stackHookOutput is the hook's reply: systemMessage is shown to the user
+// and additionalContext to Claude.
+type stackHookOutput struct {
+	SystemMessage      string `json:"systemMessage"`
+	HookSpecificOutput struct {
+		HookEventName     string `json:"hookEventName"`
+		AdditionalContext string `json:"additionalContext"`
+	} `json:"hookSpecificOutput"`
+}
+
+// runStackHook implements "enrich --stack --stdin-json", the PostToolUse hook
+// that reports, and never writes, stack drift after a tool call changes a
+// stack file or installs a dependency. projectDir is $CLAUDE_PROJECT_DIR, or
+// "" to use the event's working directory. Input that is not an event, and
+// every error, is ignored, so the hook never gets in the agent's way.
+func runStackHook(stdin io.Reader, stdout io.Writer, projectDir string) error {
+	var event stackHookEvent
+	if err := json.NewDecoder(stdin).Decode(&event); err != nil {
+		return nil
+	}
+	switch {
+	case event.ToolInput.FilePath != "":
+		if !stackFiles[filepath.Base(event.ToolInput.FilePath)] {
+			return nil
+		}
+	case !installRe.MatchString(event.ToolInput.Command):
+		return nil
+	}
+	if projectDir == "" {
+		projectDir = event.Cwd
+	}
+	data, err := os.ReadFile(filepath.Join(projectDir, ".claude", "CLAUDE.md"))
+	if err != nil {
+		return nil
+	}
+	changes := stackChanges(projectDir, string(data))
+	if len(changes) == 0 {
+		return nil
+	}
+	var fields []string
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	var out stackHookOutput
+	out.SystemMessage = fmt.Sprintf("CLAUDE.md is out of date (%s). Run `claude-workspace enrich --stack` to review and update it.", strings.Join(fields, ", "))
+	out.HookSpecificOutput.HookEventName = "PostToolUse"
+	var detail strings.Builder
+	printStackChanges(&detail, changes)
+	out.HookSpecificOutput.AdditionalContext = "The project's dependencies changed, and these lines of .claude/CLAUDE.md no longer match it:\n" + detail.String()
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
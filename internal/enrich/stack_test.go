@@ -0,0 +1,127 @@
+package enrich
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stackProject creates a project whose CLAUDE.md was written before go.mod
+// and a Makefile test target were added.
+func stackProject(t *testing.T) (dir, claudeMd string) {
+	t.Helper()
+	dir = t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	_ = os.MkdirAll(filepath.Join(dir, ".claude"), 0755)
+	claudeMd = filepath.Join(dir, ".claude", "CLAUDE.md")
+	content := "# Project Instructions\n\n## Project\nName: app\nTech Stack: TypeScript 5, React\nBuild: `go build ./...`\nTest: `go test ./...`\n\n## Notes\n- Hand-written\n"
+	_ = os.WriteFile(claudeMd, []byte(content), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "Makefile"), []byte("test:\n\tgo test -race ./...\n"), 0644)
+	return dir, claudeMd
+}
+
+func TestStackChanges(t *testing.T) {
+	dir, claudeMd := stackProject(t)
+	data, _ := os.ReadFile(claudeMd)
+	changes := stackChanges(dir, string(data))
+	got := map[string]stackChange{}
+	for _, c := range changes {
+		got[c.Field] = c
+	}
+	if c := got["Test"]; c.Old != "`go test ./...`" || c.New != "`make test`" {
+		t.Errorf("Test change = %+v", c)
+	}
+	if c := got["Tech Stack"]; c.New != "TypeScript 5, React, Go" {
+		t.Errorf("Tech Stack change = %+v, want missing technologies appended", c)
+	}
+
+	updated := applyStackChanges(string(data), changes)
+	if again := stackChanges(dir, updated); len(again) != 0 {
+		t.Errorf("changes after applying them = %+v", again)
+	}
+	if !strings.Contains(updated, "Test: `make test`\n") || !strings.HasSuffix(updated, "## Notes\n- Hand-written\n") {
+		t.Errorf("updated CLAUDE.md =\n%s", updated)
+	}
+}
+
+func TestRefreshStack(t *testing.T) {
+	dir, claudeMd := stackProject(t)
+	before, _ := os.ReadFile(claudeMd)
+
+	var out bytes.Buffer
+	if err := refreshStack(&out, bufio.NewReader(strings.NewReader("n\n")), dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(claudeMd); !bytes.Equal(data, before) {
+		t.Error("declined update changed CLAUDE.md")
+	}
+	if err := refreshStack(&out, nil, dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(claudeMd); !bytes.Equal(data, before) {
+		t.Error("update without a terminal or --yes changed CLAUDE.md")
+	}
+
+	if err := refreshStack(&out, bufio.NewReader(strings.NewReader("y\n")), dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(claudeMd); !strings.Contains(string(data), "Test: `make test`") {
+		t.Errorf("confirmed update not written:\n%s", data)
+	}
+	out.Reset()
+	if err := refreshStack(&out, nil, dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "match the project") {
+		t.Errorf("second run output = %q", out.String())
+	}
+
+	if err := refreshStack(&out, nil, t.TempDir(), true); err == nil {
+		t.Error("refreshStack without a CLAUDE.md: expected error")
+	}
+}
+
+func TestRunStackHook(t *testing.T) {
+	dir, claudeMd := stackProject(t)
+	before, _ := os.ReadFile(claudeMd)
+	tests := []struct {
+		name  string
+		event string
+		want  bool
+	}{
+		{"dependency file written", `{"cwd":"/elsewhere","tool_name":"Edit","tool_input":{"file_path":"` + filepath.Join(dir, "go.mod") + `"}}`, true},
+		{"other file written", `{"tool_name":"Write","tool_input":{"file_path":"` + filepath.Join(dir, "main.go") + `"}}`, false},
+		{"package manager run", `{"tool_name":"Bash","tool_input":{"command":"cd web && npm install typescript"}}`, true},
+		{"other command run", `{"tool_name":"Bash","tool_input":{"command":"go test ./..."}}`, false},
+		{"not an event", `not json`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runStackHook(strings.NewReader(tt.event), &out, dir); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.want {
+				if out.Len() != 0 {
+					t.Errorf("hook output = %q, want none", out.String())
+				}
+				return
+			}
+			var reply stackHookOutput
+			if err := json.Unmarshal(out.Bytes(), &reply); err != nil {
+				t.Fatalf("hook output %q: %v", out.String(), err)
+			}
+			if !strings.Contains(reply.SystemMessage, "enrich --stack") || reply.HookSpecificOutput.HookEventName != "PostToolUse" ||
+				!strings.Contains(reply.HookSpecificOutput.AdditionalContext, "Test: `go test ./...` -> `make test`") {
+				t.Errorf("hook reply = %+v", reply)
+			}
+		})
+	}
+	if data, _ := os.ReadFile(claudeMd); !bytes.Equal(data, before) {
+		t.Error("hook changed CLAUDE.md")
+	}
+}
@@ -0,0 +1,249 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const usage = `Usage: claude-workspace events [list|query] [flags]
+  list                        Sessions with recorded events, most recent first (default)
+  query                       Recorded events, oldest first
+Flags:
+  --project <dir>             Only events in this directory or below it (. for the current one)
+  --since <24h|7d|YYYY-MM-DD> Only events since a time
+  --tool <name>               Only calls of this tool; * and ? are wildcards (e.g. mcp__*)
+  --event <event>             Only this hook event (e.g. PostToolUse)
+  --session <id>              Only this session, by ID or prefix
+  --limit <n>                 Show at most n sessions (default: 20) or events (default: 100); 0 for all
+  --db <path>                 Read this database instead of ~/.claude-workspace/events.db
+  --json                      Print JSON`
+
+type options struct {
+	filter Filter
+	db     string
+	json   bool
+}
+
+func parseArgs(args []string, now time.Time, defaultLimit int) (options, error) {
+	opts := options{filter: Filter{Limit: defaultLimit}}
+	f := cli.New("events", "events [list|query] [flags]")
+	f.Help = func(w io.Writer) { fmt.Fprintln(w, usage) }
+	f.Func("--project", "dir", "Only events in this directory or below it", func(v string) error {
+		dir, err := filepath.Abs(v)
+		if err != nil {
+			return err
+		}
+		opts.filter.Project = dir
+		return nil
+	})
+	f.Func("--since", "24h|7d|YYYY-MM-DD", "Only events since a time", func(v string) error {
+		since, err := parseSince(v, now)
+		if err != nil {
+			return err
+		}
+		opts.filter.Since = since
+		return nil
+	})
+	f.StringVar(&opts.filter.Tool, "--tool", "name", "Only calls of this tool")
+	f.StringVar(&opts.filter.Event, "--event", "event", "Only this hook event")
+	f.StringVar(&opts.filter.Session, "--session", "id", "Only this session, by ID or prefix")
+	f.Func("--limit", "n", "Show at most n sessions or events; 0 for all", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("--limit must be a non-negative number, got %q", v)
+		}
+		opts.filter.Limit = n
+		return nil
+	})
+	f.StringVar(&opts.db, "--db", "path", "Read this database instead of ~/.claude-workspace/events.db")
+	f.BoolVar(&opts.json, "--json", "Print JSON")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	return opts, f.CheckArgs(positional, 0, 0)
+}
+
+// parseSince parses a --since value: a duration back from now such as 90m,
+// 24h, or 7d, or a date.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("--since must be a duration like 24h or 7d, or a date like 2026-03-01, got %q", s)
+}
+
+// Run implements the events command.
+func Run(args []string) error {
+	sub := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		sub, args = args[0], args[1:]
+	}
+	switch sub {
+	case "list", "query":
+	case "help":
+		fmt.Fprintln(platform.Stdout(), usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown events subcommand: %s\n%s", sub, usage)
+	}
+	defaultLimit := 20
+	if sub == "query" {
+		defaultLimit = 100
+	}
+	opts, err := parseArgs(args, time.Now(), defaultLimit)
+	if err != nil {
+		return err
+	}
+	path := opts.db
+	if path == "" {
+		if path, err = DefaultPath(); err != nil {
+			return err
+		}
+	}
+
+	w := platform.Stdout()
+	if !platform.FileExists(path) {
+		if opts.json {
+			fmt.Fprintln(os.Stdout, "[]")
+			return nil
+		}
+		platform.PrintBanner(w, "Events")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  No events recorded in %s.\n", path)
+		platform.PrintManual(w, "Record this project's hook events with: claude-workspace hook-relay install --local")
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	if sub == "list" {
+		sessions, err := Sessions(path, opts.filter)
+		if err != nil {
+			return err
+		}
+		if opts.json {
+			return writeJSON(sessions)
+		}
+		writeSessions(w, sessions, opts.filter)
+		return nil
+	}
+	events, err := Query(path, opts.filter)
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		return writeJSON(events)
+	}
+	writeEvents(w, events, opts.filter)
+	return nil
+}
+
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeSessions(w io.Writer, sessions []Session, f Filter) {
+	platform.PrintBanner(w, "Recorded Sessions")
+	fmt.Fprintln(w)
+	if len(sessions) == 0 {
+		fmt.Fprintf(w, "  No sessions with events%s.\n", describe(f))
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintf(w, "  %-8s  %-20s  %-16s  %-16s  %6s  %10s\n", "SESSION", "PROJECT", "FIRST", "LAST", "EVENTS", "TOOL CALLS")
+	for _, s := range sessions {
+		fmt.Fprintf(w, "  %-8s  %-20s  %-16s  %-16s  %6d  %10d\n", shortID(s.SessionID), truncate(filepath.Base(s.Project), 20),
+			s.First.Local().Format("2006-01-02 15:04"), s.Last.Local().Format("2006-01-02 15:04"), s.Events, s.ToolCalls)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  Show a session's events: claude-workspace events query --session <id>")
+	fmt.Fprintln(w)
+}
+
+func writeEvents(w io.Writer, events []Event, f Filter) {
+	platform.PrintBanner(w, "Events")
+	fmt.Fprintf(w, "  %d event(s)%s\n", len(events), describe(f))
+	if f.Limit > 0 && len(events) == f.Limit {
+		fmt.Fprintf(w, "  Showing the most recent %d; use --limit to see more.\n", f.Limit)
+	}
+	fmt.Fprintln(w)
+	for _, e := range events {
+		id := fmt.Sprintf("%-8s", shortID(e.SessionID))
+		if f.Project == "" {
+			id += fmt.Sprintf("  %-16s", truncate(filepath.Base(e.Project), 16))
+		}
+		fmt.Fprintf(w, "  %s  %s  %-16s %-12s %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), id, e.Event, e.Tool, truncate(Summary(e), 100))
+	}
+	if len(events) > 0 {
+		fmt.Fprintln(w)
+	}
+}
+
+// describe returns the filters in f that narrow by project or time, as a
+// phrase.
+func describe(f Filter) string {
+	var s string
+	if f.Project != "" {
+		s += " in " + f.Project
+	}
+	if !f.Since.IsZero() {
+		s += " since " + f.Since.Local().Format("2006-01-02 15:04")
+	}
+	return s
+}
+
+// summaryFields are the tool input fields that best describe a call, in order.
+var summaryFields = []string{"command", "file_path", "notebook_path", "pattern", "url", "query", "description", "prompt"}
+
+// Summary returns a one-line description of e: the command, file, or other
+// main input of a tool call, or the detail of other events.
+func Summary(e Event) string {
+	var input map[string]interface{}
+	s := e.Detail
+	if json.Unmarshal(e.Input, &input) == nil {
+		s = string(e.Input)
+		for _, field := range summaryFields {
+			if v, ok := input[field].(string); ok && v != "" {
+				s = v
+				break
+			}
+		}
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	if id == "" {
+		return "-"
+	}
+	return id
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"24h": now.Add(-24 * time.Hour),
+		"90m": now.Add(-90 * time.Minute),
+		"7d":  now.AddDate(0, 0, -7),
+	} {
+		if got, err := parseSince(in, now); err != nil || !got.Equal(want) {
+			t.Errorf("parseSince(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if got, err := parseSince("2026-03-01", now); err != nil || got.Format("2006-01-02 15:04") != "2026-03-01 00:00" {
+		t.Errorf("parseSince(date) = %v, %v", got, err)
+	}
+	for _, in := range []string{"yesterday", "-1d", "3w"} {
+		if _, err := parseSince(in, now); err == nil {
+			t.Errorf("parseSince(%q): expected error", in)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	opts, err := parseArgs([]string{"--tool", "Bash", "--since", "24h", "--project", "/work/api/", "--json"}, time.Now(), 100)
+	if err != nil || opts.filter.Tool != "Bash" || opts.filter.Project != "/work/api" || opts.filter.Since.IsZero() || opts.filter.Limit != 100 || !opts.json {
+		t.Errorf("parseArgs = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{"--tool"}, {"--limit", "-1"}, {"--since", "soon"}, {"Bash"}} {
+		if _, err := parseArgs(args, time.Now(), 20); err == nil {
+			t.Errorf("parseArgs(%q): expected error", args)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		e    Event
+		want string
+	}{
+		{Event{Tool: "Bash", Input: json.RawMessage(`{"command":"go test\n  ./...","timeout":60}`)}, "go test ./..."},
+		{Event{Tool: "Edit", Input: json.RawMessage(`{"file_path":"/p/main.go","old_string":"a"}`)}, "/p/main.go"},
+		{Event{Tool: "mcp__github__create_issue", Input: json.RawMessage(`{"title":"Bug"}`)}, `{"title":"Bug"}`},
+		{Event{Event: "Stop", Detail: "done"}, "done"},
+	}
+	for _, tt := range tests {
+		if got := Summary(tt.e); got != tt.want {
+			t.Errorf("Summary(%s) = %q, want %q", tt.e.Tool, got, tt.want)
+		}
+	}
+}
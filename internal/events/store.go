@@ -0,0 +1,245 @@
+// Package events keeps Claude Code hook events in a SQLite database: the
+// local store ~/.claude-workspace/events.db that "hook-relay --local" writes
+// and the "events" command reads, or any database given to "hook-relay
+// --sqlite". Databases are read and written with the sqlite3 CLI.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Event is a Claude Code hook event as forwarded by "hook-relay".
+type Event struct {
+	Time      time.Time       `json:"time"`
+	Event     string          `json:"event"`
+	SessionID string          `json:"sessionId"`
+	Project   string          `json:"project"`
+	Cwd       string          `json:"cwd"`
+	User      string          `json:"user"`
+	Host      string          `json:"host"`
+	Tool      string          `json:"tool,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`    // tool_input
+	Response  json.RawMessage `json:"response,omitempty"` // tool_response
+	Detail    string          `json:"detail,omitempty"`   // the prompt, message, source, reason, or trigger
+}
+
+// DefaultPath returns the path of the local event store.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "events.db"), nil
+}
+
+// schema creates the table events are appended to.
+const schema = `CREATE TABLE IF NOT EXISTS hook_events (
+  time TEXT NOT NULL,
+  event TEXT NOT NULL,
+  session_id TEXT,
+  project TEXT,
+  cwd TEXT,
+  user TEXT,
+  host TEXT,
+  tool TEXT,
+  input TEXT,
+  response TEXT,
+  detail TEXT
+);
+CREATE INDEX IF NOT EXISTS hook_events_time ON hook_events(time);
+`
+
+// timeLayout is how times are stored, so that they sort as text.
+const timeLayout = "2006-01-02T15:04:05Z"
+
+// Append adds e to the database at path, creating it as needed.
+func Append(ctx context.Context, path string, e *Event) error {
+	if !platform.Exists("sqlite3") {
+		return fmt.Errorf("the sqlite3 CLI is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	values := []string{
+		e.Time.UTC().Format(timeLayout), e.Event, e.SessionID, e.Project, e.Cwd,
+		e.User, e.Host, e.Tool, string(e.Input), string(e.Response), e.Detail,
+	}
+	for i, v := range values {
+		values[i] = sqlString(v)
+	}
+	// .timeout waits for another relay's insert instead of failing on a lock.
+	sql := ".timeout 2000\n" + schema +
+		"INSERT INTO hook_events VALUES (" + strings.Join(values, ", ") + ");\n"
+	_, stderr, err := platform.RunDirWithStdinCapture(ctx, "", sql, nil, "sqlite3", path)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return fmt.Errorf("writing %s: %s", path, msg)
+		}
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Filter selects events. Zero fields match everything.
+type Filter struct {
+	Event   string    // hook event, e.g. PreToolUse
+	Tool    string    // tool name; may use * and ? wildcards, e.g. mcp__*
+	Project string    // absolute project directory, matching events in it or below it
+	Session string    // session ID or a prefix of one
+	Since   time.Time // events at or after
+	Limit   int       // the most recent Limit events; 0 for all
+}
+
+// where returns the SQL condition for f.
+func (f Filter) where() string {
+	conds := []string{"1"}
+	if f.Event != "" {
+		conds = append(conds, "event = "+sqlString(f.Event))
+	}
+	if f.Tool != "" {
+		conds = append(conds, "tool GLOB "+sqlString(f.Tool))
+	}
+	if f.Project != "" {
+		dir := strings.TrimSuffix(f.Project, "/") + "/"
+		// instr rather than substr with len(dir): SQLite counts characters,
+		// not bytes, so a byte length misses paths with non-ASCII names.
+		conds = append(conds, fmt.Sprintf("(project = %s OR cwd = %s OR instr(cwd, %s) = 1)",
+			sqlString(f.Project), sqlString(f.Project), sqlString(dir)))
+	}
+	if f.Session != "" {
+		conds = append(conds, "instr(session_id, "+sqlString(f.Session)+") = 1")
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, "time >= "+sqlString(f.Since.UTC().Format(timeLayout)))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// row is an event as the sqlite3 CLI prints it with -json.
+type row struct {
+	Time      string `json:"time"`
+	Event     string `json:"event"`
+	SessionID string `json:"session_id"`
+	Project   string `json:"project"`
+	Cwd       string `json:"cwd"`
+	User      string `json:"user"`
+	Host      string `json:"host"`
+	Tool      string `json:"tool"`
+	Input     string `json:"input"`
+	Response  string `json:"response"`
+	Detail    string `json:"detail"`
+}
+
+// Query returns the events in the database at path matching f, oldest first.
+func Query(path string, f Filter) ([]Event, error) {
+	sql := "SELECT * FROM (SELECT rowid AS id, * FROM hook_events WHERE " + f.where() + " ORDER BY time DESC, rowid DESC"
+	if f.Limit > 0 {
+		sql += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+	sql += ") ORDER BY time, id"
+	var rows []row
+	if err := query(path, sql, &rows); err != nil {
+		return nil, err
+	}
+	out := make([]Event, 0, len(rows))
+	for _, r := range rows {
+		e := Event{
+			Event: r.Event, SessionID: r.SessionID, Project: r.Project, Cwd: r.Cwd,
+			User: r.User, Host: r.Host, Tool: r.Tool, Detail: r.Detail,
+		}
+		e.Time, _ = time.Parse(time.RFC3339, r.Time)
+		if json.Valid([]byte(r.Input)) {
+			e.Input = json.RawMessage(r.Input)
+		}
+		if json.Valid([]byte(r.Response)) {
+			e.Response = json.RawMessage(r.Response)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// Session summarizes the events recorded for one session.
+type Session struct {
+	SessionID string    `json:"sessionId"`
+	Project   string    `json:"project"`
+	First     time.Time `json:"first"`
+	Last      time.Time `json:"last"`
+	Events    int       `json:"events"`
+	ToolCalls int       `json:"toolCalls"`
+}
+
+// Sessions summarizes the sessions with events matching f, most recent
+// first. f.Limit is a number of sessions.
+func Sessions(path string, f Filter) ([]Session, error) {
+	// A tool call is relayed as PreToolUse, PostToolUse, or both, depending
+	// on which events the relay is installed for.
+	sql := `SELECT session_id, max(project) AS project, min(time) AS first, max(time) AS last, count(*) AS events,
+  max(sum(event = 'PreToolUse'), sum(event = 'PostToolUse')) AS tool_calls
+FROM hook_events WHERE ` + f.where() + `
+GROUP BY session_id ORDER BY last DESC`
+	if f.Limit > 0 {
+		sql += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+	var rows []struct {
+		SessionID string `json:"session_id"`
+		Project   string `json:"project"`
+		First     string `json:"first"`
+		Last      string `json:"last"`
+		Events    int    `json:"events"`
+		ToolCalls int    `json:"tool_calls"`
+	}
+	if err := query(path, sql, &rows); err != nil {
+		return nil, err
+	}
+	out := make([]Session, 0, len(rows))
+	for _, r := range rows {
+		s := Session{SessionID: r.SessionID, Project: r.Project, Events: r.Events, ToolCalls: r.ToolCalls}
+		s.First, _ = time.Parse(time.RFC3339, r.First)
+		s.Last, _ = time.Parse(time.RFC3339, r.Last)
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// query runs sql read-only against the database at path and decodes the
+// rows into v.
+func query(path, sql string, v interface{}) error {
+	if !platform.Exists("sqlite3") {
+		return fmt.Errorf("the sqlite3 CLI is required to read %s", path)
+	}
+	if !platform.FileExists(path) {
+		return fmt.Errorf("no events recorded: %s does not exist", path)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, stderr, err := platform.RunDirWithStdinCapture(ctx, "", ".timeout 2000\n"+sql+";\n", nil, "sqlite3", "-readonly", "-json", path)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return fmt.Errorf("reading %s: %s", path, msg)
+		}
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	// sqlite3 prints nothing for an empty result.
+	if strings.TrimSpace(out) == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(out), v)
+}
+
+// sqlString quotes s as an SQL string literal; "" becomes NULL.
+func sqlString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// mkStore returns a database holding a day of events from two sessions.
+func mkStore(t *testing.T, now time.Time) string {
+	t.Helper()
+	if !platform.Exists("sqlite3") {
+		t.Skip("sqlite3 not installed")
+	}
+	path := filepath.Join(t.TempDir(), "events.db")
+	for _, e := range []Event{
+		{Time: now.Add(-30 * time.Hour), Event: "PreToolUse", SessionID: "aaaa1111", Project: "/work/api", Cwd: "/work/api", Tool: "Bash", Input: json.RawMessage(`{"command":"make test"}`)},
+		{Time: now.Add(-2 * time.Hour), Event: "PreToolUse", SessionID: "bbbb2222", Project: "/work/api", Cwd: "/work/api/cmd", Tool: "Edit", Input: json.RawMessage(`{"file_path":"/work/api/cmd/main.go"}`)},
+		{Time: now.Add(-2 * time.Hour), Event: "PostToolUse", SessionID: "bbbb2222", Project: "/work/api", Cwd: "/work/api/cmd", Tool: "Edit", Response: json.RawMessage(`{"ok":true}`)},
+		{Time: now.Add(-time.Hour), Event: "PreToolUse", SessionID: "bbbb2222", Project: "/work/api", Cwd: "/work/api", Tool: "mcp__github__create_issue", Input: json.RawMessage(`{"title":"it's broken"}`)},
+		{Time: now.Add(-time.Hour), Event: "PreToolUse", SessionID: "cccc3333", Project: "/work/api-docs", Cwd: "/work/api-docs", Tool: "Bash", Input: json.RawMessage(`{"command":"ls"}`)},
+		{Time: now.Add(-time.Minute), Event: "Stop", SessionID: "bbbb2222", Project: "/work/api", Cwd: "/work/api", Detail: "done"},
+	} {
+		if err := Append(context.Background(), path, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestQuery(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	path := mkStore(t, now)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string // session:tool of each event
+	}{
+		{"all", Filter{}, []string{"aaaa1111:Bash", "bbbb2222:Edit", "bbbb2222:Edit", "bbbb2222:mcp__github__create_issue", "cccc3333:Bash", "bbbb2222:"}},
+		{"since", Filter{Since: now.Add(-24 * time.Hour), Tool: "Bash"}, []string{"cccc3333:Bash"}},
+		{"project", Filter{Project: "/work/api", Event: "PreToolUse"}, []string{"aaaa1111:Bash", "bbbb2222:Edit", "bbbb2222:mcp__github__create_issue"}},
+		{"subdirectory", Filter{Project: "/work/api/cmd"}, []string{"bbbb2222:Edit", "bbbb2222:Edit"}},
+		{"wildcard", Filter{Tool: "mcp__*"}, []string{"bbbb2222:mcp__github__create_issue"}},
+		{"session prefix", Filter{Session: "bbbb", Limit: 2}, []string{"bbbb2222:mcp__github__create_issue", "bbbb2222:"}},
+		{"none", Filter{Tool: "Read"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := Query(path, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range events {
+				got = append(got, e.SessionID+":"+e.Tool)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	events, _ := Query(path, Filter{Tool: "mcp__*"})
+	if e := events[0]; !e.Time.Equal(now.Add(-time.Hour)) || string(e.Input) != `{"title":"it's broken"}` || e.Cwd != "/work/api" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestQuery_NonASCII(t *testing.T) {
+	if !platform.Exists("sqlite3") {
+		t.Skip("sqlite3 not installed")
+	}
+	path := filepath.Join(t.TempDir(), "events.db")
+	for _, e := range []Event{
+		{Time: time.Now(), Event: "PreToolUse", SessionID: "ééé-1", Project: "/work", Cwd: "/work/café/sub", Tool: "Bash"},
+		{Time: time.Now(), Event: "PreToolUse", SessionID: "ééé-2", Project: "/work/café-docs", Cwd: "/work/café-docs", Tool: "Read"},
+	} {
+		if err := Append(context.Background(), path, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []Filter{{Project: "/work/café"}, {Session: "ééé-1"}, {Session: "ééé-"}} {
+		events, err := Query(path, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) == 0 || events[0].Tool != "Bash" {
+			t.Errorf("Query(%+v) = %+v, want the Bash event first", f, events)
+		}
+	}
+	if events, _ := Query(path, Filter{Project: "/work/café"}); len(events) != 1 {
+		t.Errorf("Query(/work/café) = %d events, want 1: /work/café-docs is another project", len(events))
+	}
+}
+
+func TestSessions(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	path := mkStore(t, now)
+
+	sessions, err := Sessions(path, Filter{Project: "/work/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+	if s := sessions[0]; s.SessionID != "bbbb2222" || s.Events != 4 || s.ToolCalls != 2 || !s.Last.Equal(now.Add(-time.Minute)) || !s.First.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("most recent session = %+v", s)
+	}
+	if sessions[1].SessionID != "aaaa1111" {
+		t.Errorf("second session = %+v", sessions[1])
+	}
+}
+
+func TestFilterWhere(t *testing.T) {
+	f := Filter{Tool: "x' OR '1'='1", Project: "/work/o'brien"}
+	where := f.where()
+	if !strings.Contains(where, "tool GLOB 'x'' OR ''1''=''1'") || !strings.Contains(where, "'/work/o''brien/'") {
+		t.Errorf("where = %s", where)
+	}
+}
@@ -0,0 +1,414 @@
+// Package fleet implements the "fleet" command, which runs attach, drift
+// checks, upgrades, and doctor across many repositories at once. Each
+// repository is handled by a separate claude-workspace process so the
+// commands, which assume they own the working directory and stdout, can run
+// concurrently.
+package fleet
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/attach"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/doctor"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// defaultParallel is the number of repositories processed at once.
+const defaultParallel = 4
+
+// maxScanDepth is how many directory levels below --scan are searched for
+// repositories, e.g. ~/code/<org>/<repo>.
+const maxScanDepth = 3
+
+// Result statuses.
+const (
+	StatusOK     = "ok"
+	StatusDrift  = "drift"
+	StatusFailed = "failed"
+)
+
+const usage = "fleet <attach|upgrade|check|doctor> (--repos <file> | --scan <dir>) [--max-parallel N] [-- <attach flags>]"
+
+// action is one fleet subcommand: the claude-workspace arguments to run in a
+// repository and how to read the outcome.
+type action struct {
+	args     func(repo string, extra []string) []string
+	classify func(stdout, stderr string, err error) (status, detail string)
+}
+
+var actions = map[string]action{
+	"attach": {
+		// Enrichment starts a Claude session per repository; run
+		// "claude-workspace enrich" afterwards where it is wanted.
+		args: func(repo string, extra []string) []string {
+			return append([]string{"attach", repo, "--no-enrich"}, extra...)
+		},
+		classify: func(stdout, stderr string, err error) (string, string) {
+			if err != nil {
+				return StatusFailed, errorLine(stderr, err)
+			}
+			return StatusOK, "attached"
+		},
+	},
+	// Drift checks and upgrades run with --json and are classified by the
+	// "outcome" event attach reports, not by the wording of its output.
+	"upgrade": {
+		args: func(repo string, extra []string) []string {
+			return append([]string{"--json", "attach", repo, "--reconcile"}, extra...)
+		},
+		classify: func(stdout, stderr string, err error) (string, string) {
+			outcome, msg := readEvents(stdout)
+			switch {
+			case outcome == attach.OutcomeHeldBack:
+				// Pinned projects wait for "attach --upgrade-assets".
+				return StatusOK, "pinned; not upgraded"
+			case err != nil:
+				return StatusFailed, failure(msg, stderr, err)
+			case outcome == attach.OutcomeIncomplete:
+				return StatusFailed, "some files could not be updated"
+			case outcome == attach.OutcomeUpToDate:
+				return StatusOK, "up to date"
+			case outcome == attach.OutcomeUpdated:
+				return StatusOK, "updated"
+			}
+			return StatusFailed, "attach reported no outcome"
+		},
+	},
+	"check": {
+		args: func(repo string, extra []string) []string {
+			return append([]string{"--json", "attach", repo, "--check"}, extra...)
+		},
+		classify: func(stdout, stderr string, err error) (string, string) {
+			outcome, msg := readEvents(stdout)
+			switch {
+			case err == nil && outcome == attach.OutcomeHeldBack:
+				return StatusOK, "pinned; updates held back"
+			case err == nil && outcome == attach.OutcomeUpToDate:
+				return StatusOK, "up to date"
+			case err != nil && outcome == attach.OutcomeDrift:
+				return StatusDrift, failure(msg, stderr, err)
+			case err == nil:
+				return StatusFailed, "attach reported no outcome"
+			}
+			return StatusFailed, failure(msg, stderr, err)
+		},
+	},
+	"doctor": {
+		args: func(repo string, extra []string) []string {
+			return append([]string{"doctor", "--json", "--project", repo}, extra...)
+		},
+		classify: func(stdout, stderr string, err error) (string, string) {
+			var report doctor.Report
+			if jsonErr := json.Unmarshal([]byte(stdout), &report); jsonErr != nil {
+				if err == nil {
+					err = jsonErr
+				}
+				return StatusFailed, errorLine(stderr, err)
+			}
+			detail := fmt.Sprintf("%d issue(s), %d warning(s)", report.Issues, report.Warnings)
+			if !report.Healthy {
+				return StatusFailed, detail
+			}
+			return StatusOK, detail
+		},
+	},
+}
+
+// runFunc runs claude-workspace with args in dir and returns its output.
+type runFunc func(dir string, args ...string) (stdout, stderr string, err error)
+
+// result is the outcome of one repository.
+type result struct {
+	Repo    string
+	Status  string
+	Detail  string
+	Elapsed time.Duration
+}
+
+// options holds the parsed fleet flags.
+type options struct {
+	action      string
+	reposFile   string
+	scanDir     string
+	maxParallel int
+	extra       []string
+}
+
+// Run implements "fleet <action> (--repos <file> | --scan <dir>)".
+func Run(args []string) error {
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+
+	var repos []string
+	if opts.reposFile != "" {
+		repos, err = ReadRepos(opts.reposFile)
+	} else {
+		repos, err = Scan(opts.scanDir)
+	}
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories found")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating claude-workspace: %w", err)
+	}
+	run := func(dir string, args ...string) (string, string, error) {
+		// --no-color keeps escape codes out of the output that is parsed.
+		return platform.RunDirWithStdinCapture(context.Background(), dir, "", nil, exe, append([]string{"--no-color"}, args...)...)
+	}
+
+	out := platform.Stdout()
+	platform.PrintBanner(out, fmt.Sprintf("Fleet %s: %d Repositories", opts.action, len(repos)))
+	fmt.Fprintf(out, "\n  Running up to %d at a time...\n", opts.maxParallel)
+
+	results := runAll(repos, actions[opts.action], opts.extra, opts.maxParallel, run)
+	drift, failed := printSummary(out, results)
+	switch {
+	case failed > 0:
+		return fmt.Errorf("%d of %d repositories failed", failed, len(results))
+	case drift > 0:
+		return fmt.Errorf("%d of %d repositories are out of date with the template", drift, len(results))
+	}
+	return nil
+}
+
+func parseArgs(args []string) (options, error) {
+	opts := options{maxParallel: defaultParallel}
+	if len(args) == 0 {
+		return opts, fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+	opts.action = args[0]
+	if _, ok := actions[opts.action]; !ok {
+		return opts, fmt.Errorf("unknown fleet subcommand: %s (usage: claude-workspace %s)", opts.action, usage)
+	}
+
+	f := cli.New("fleet "+opts.action, usage)
+	f.StringVar(&opts.reposFile, "--repos", "file", "File listing one repository path per line")
+	f.StringVar(&opts.scanDir, "--scan", "dir", "Directory to scan for git repositories")
+	f.Func("--max-parallel", "N", "Repositories to process at once", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--max-parallel must be a positive number")
+		}
+		opts.maxParallel = n
+		return nil
+	})
+	positional, err := f.Parse(args[1:])
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	opts.extra = f.Rest()
+
+	if (opts.reposFile == "") == (opts.scanDir == "") {
+		return opts, fmt.Errorf("exactly one of --repos or --scan is required (usage: claude-workspace %s)", usage)
+	}
+	return opts, nil
+}
+
+// ReadRepos reads a repository list: one path per line, with blank lines and
+// "#" comments ignored. Relative paths are resolved against the file's
+// directory, and "~/" against the home directory.
+func ReadRepos(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading repository list: %w", err)
+	}
+	defer f.Close()
+	return parseRepos(f, filepath.Dir(path))
+}
+
+func parseRepos(r io.Reader, baseDir string) ([]string, error) {
+	var repos []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			line = filepath.Join(home, rest)
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(baseDir, line)
+		}
+		line = filepath.Clean(line)
+		if !seen[line] {
+			seen[line] = true
+			repos = append(repos, line)
+		}
+	}
+	return repos, scanner.Err()
+}
+
+// Scan returns the git repositories under dir, sorted by path. Repositories
+// are not searched for nested ones, and worktrees (whose .git is a file) are
+// skipped so sandboxes are not treated as separate projects.
+func Scan(dir string) ([]string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", dir)
+	}
+
+	var repos []string
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if info, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			if info.IsDir() {
+				repos = append(repos, path)
+			}
+			return filepath.SkipDir
+		}
+		if rel, _ := filepath.Rel(root, path); rel != "." && strings.Count(rel, string(filepath.Separator))+1 >= maxScanDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	sort.Strings(repos)
+	return repos, err
+}
+
+// runAll runs act in every repository with a pool of parallel workers.
+// Results are returned in repository order.
+func runAll(repos []string, act action, extra []string, parallel int, run runFunc) []*result {
+	results := make([]*result, len(repos))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallel && w < len(repos); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = runRepo(repos[i], act, extra, run)
+			}
+		}()
+	}
+	for i := range repos {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+func runRepo(repo string, act action, extra []string, run runFunc) *result {
+	start := time.Now()
+	r := &result{Repo: repo}
+	defer func() { r.Elapsed = time.Since(start) }()
+
+	if info, err := os.Stat(repo); err != nil || !info.IsDir() {
+		r.Status, r.Detail = StatusFailed, "directory not found"
+		return r
+	}
+	stdout, stderr, err := run(repo, act.args(repo, extra)...)
+	r.Status, r.Detail = act.classify(stdout, stderr, err)
+	return r
+}
+
+// readEvents reads the JSON events of a child run with --json and returns the
+// outcome it reported and the message of its last error event.
+func readEvents(stdout string) (outcome, errMsg string) {
+	for _, line := range strings.Split(stdout, "\n") {
+		var ev platform.Event
+		if json.Unmarshal([]byte(line), &ev) != nil {
+			continue
+		}
+		switch ev.Event {
+		case platform.EventOutcome:
+			outcome = ev.Message
+		case platform.EventError:
+			errMsg = ev.Message
+		}
+	}
+	return outcome, errMsg
+}
+
+// failure returns the error message of a failed child: its last error event,
+// or else its "Error: ..." line on stderr.
+func failure(errMsg, stderr string, err error) string {
+	if errMsg != "" {
+		return errMsg
+	}
+	return errorLine(stderr, err)
+}
+
+// errorLine returns the message of the child's "Error: ..." line, or err's
+// when there is none.
+func errorLine(stderr string, err error) string {
+	for _, line := range strings.Split(stderr, "\n") {
+		if msg, ok := strings.CutPrefix(strings.TrimSpace(line), "Error: "); ok {
+			return msg
+		}
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// printSummary prints a table of results and returns the number of drifted
+// and failed repositories.
+func printSummary(out io.Writer, results []*result) (drift, failed int) {
+	maxRepo := len("REPOSITORY")
+	for _, r := range results {
+		if len(r.Repo) > maxRepo {
+			maxRepo = len(r.Repo)
+		}
+	}
+
+	platform.PrintBanner(out, "Fleet Summary")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  %-*s  %-6s  %6s  %s\n", maxRepo, "REPOSITORY", "RESULT", "TIME", "DETAIL")
+	ok := 0
+	for _, r := range results {
+		status := fmt.Sprintf("%-6s", r.Status)
+		switch r.Status {
+		case StatusOK:
+			status = platform.Green(status)
+			ok++
+		case StatusDrift:
+			status = platform.Yellow(status)
+			drift++
+		default:
+			status = platform.Red(status)
+			failed++
+		}
+		fmt.Fprintf(out, "  %-*s  %s  %5.1fs  %s\n", maxRepo, r.Repo, status, r.Elapsed.Seconds(), r.Detail)
+	}
+	fmt.Fprintf(out, "\n  %d ok, %d drifted, %d failed\n\n", ok, drift, failed)
+	return drift, failed
+}
@@ -0,0 +1,177 @@
+package fleet
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseArgs(t *testing.T) {
+	opts, err := parseArgs([]string{"attach", "--scan", "~/code", "--max-parallel", "8", "--", "--profile", "backend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := options{action: "attach", scanDir: "~/code", maxParallel: 8, extra: []string{"--profile", "backend"}}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("parseArgs() = %+v, want %+v", opts, want)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"deploy", "--scan", "."},
+		{"check"},
+		{"check", "--repos", "r.txt", "--scan", "."},
+		{"check", "--scan", ".", "--max-parallel", "0"},
+		{"check", "--scan"},
+		{"check", "--scan", ".", "extra"},
+	} {
+		if _, err := parseArgs(args); err == nil {
+			t.Errorf("parseArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestParseRepos(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	in := "# services\n/srv/api\n\n  web  \n~/code/cli\n/srv/api\n"
+	got, err := parseRepos(strings.NewReader(in), "/etc/fleet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/srv/api", "/etc/fleet/web", filepath.Join(home, "code", "cli")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRepos() = %q, want %q", got, want)
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"api/.git",
+		"org/web/.git",
+		"org/web/vendor/nested/.git", // inside a repository
+		"a/b/c/.git",
+		"a/b/c/d/.git", // too deep
+		".cache/tool/.git",
+		"notes",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A worktree has a .git file rather than a directory.
+	if err := os.MkdirAll(filepath.Join(root, "api-worktrees", "feature"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "api-worktrees", "feature", ".git"), []byte("gitdir: x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "a", "b", "c"), filepath.Join(root, "api"), filepath.Join(root, "org", "web")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() = %q, want %q", got, want)
+	}
+	if _, err := Scan(filepath.Join(root, "missing")); err == nil {
+		t.Error("Scan() of a missing directory should fail")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	exit1 := errors.New("exit status 1")
+	tests := []struct {
+		action, stdout, stderr string
+		err                    error
+		status, detail         string
+	}{
+		{"attach", "", "", nil, StatusOK, "attached"},
+		{"attach", "", "Error: not a directory: x\nDetails were logged to y", exit1, StatusFailed, "not a directory: x"},
+		{"upgrade", `{"event":"message","message":"Nothing to update."}` + "\n" + `{"event":"outcome","message":"up-to-date"}`, "", nil, StatusOK, "up to date"},
+		{"upgrade", `{"event":"outcome","message":"updated"}`, "", nil, StatusOK, "updated"},
+		{"upgrade", `{"event":"error","message":"Error updating .claude/x: denied"}` + "\n" + `{"event":"outcome","message":"incomplete"}`, "", nil, StatusFailed, "some files could not be updated"},
+		{"upgrade", `{"event":"outcome","message":"held-back"}` + "\n" + `{"event":"error","message":".claude/.claude-workspace-lock.json pins the project's assets to claude-workspace v1.3.0 (this is v1.4.0)"}`, "", exit1, StatusOK, "pinned; not upgraded"},
+		// The same words in another failure must not read as a pin that holds the upgrade back.
+		{"upgrade", `{"event":"error","message":".claude/.claude-workspace-lock.json pins the project's assets to claude-workspace v1.3.0, which linked assets cannot stay at"}`, "", exit1, StatusFailed, ".claude/.claude-workspace-lock.json pins the project's assets to claude-workspace v1.3.0, which linked assets cannot stay at"},
+		{"upgrade", "", "", nil, StatusFailed, "attach reported no outcome"},
+		{"check", `{"event":"outcome","message":"up-to-date"}`, "", nil, StatusOK, "up to date"},
+		{"check", `{"event":"message","message":"The project is pinned to claude-workspace v1.3.0; these wait for --upgrade-assets."}` + "\n" + `{"event":"outcome","message":"held-back"}`, "", nil, StatusOK, "pinned; updates held back"},
+		{"check", `{"event":"outcome","message":"drift"}` + "\n" + `{"event":"error","message":"3 file(s) out of date with the template"}`, "", exit1, StatusDrift, "3 file(s) out of date with the template"},
+		{"check", `{"event":"error","message":"no .claude directory found in x (is the platform attached?)"}`, "", exit1, StatusFailed, "no .claude directory found in x (is the platform attached?)"},
+		{"check", "", "panic: boom", exit1, StatusFailed, "exit status 1"},
+		{"doctor", `{"healthy":true,"issues":0,"warnings":2}`, "", nil, StatusOK, "0 issue(s), 2 warning(s)"},
+		{"doctor", `{"healthy":false,"issues":1,"warnings":0}`, "", exit1, StatusFailed, "1 issue(s), 0 warning(s)"},
+		{"doctor", "", "", exit1, StatusFailed, "exit status 1"},
+	}
+	for _, tt := range tests {
+		status, detail := actions[tt.action].classify(tt.stdout, tt.stderr, tt.err)
+		if status != tt.status || detail != tt.detail {
+			t.Errorf("%s classify(%q, %q) = %s, %q; want %s, %q", tt.action, tt.stdout, tt.stderr, status, detail, tt.status, tt.detail)
+		}
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	root := t.TempDir()
+	var repos []string
+	for _, name := range []string{"a", "b", "c", "d"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		repos = append(repos, dir)
+	}
+	repos = append(repos, filepath.Join(root, "missing"))
+
+	var running, peak atomic.Int32
+	run := func(dir string, args ...string) (string, string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if want := []string{"--json", "attach", dir, "--check"}; !reflect.DeepEqual(args, want) {
+			t.Errorf("args = %q, want %q", args, want)
+		}
+		if filepath.Base(dir) == "b" {
+			return `{"event":"outcome","message":"drift"}` + "\n" + `{"event":"error","message":"2 file(s) out of date with the template"}`, "", errors.New("exit status 1")
+		}
+		return `{"event":"outcome","message":"up-to-date"}`, "", nil
+	}
+
+	results := runAll(repos, actions["check"], nil, 2, run)
+	var statuses []string
+	for _, r := range results {
+		statuses = append(statuses, r.Status)
+	}
+	if want := []string{StatusOK, StatusDrift, StatusOK, StatusOK, StatusFailed}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %q, want %q", statuses, want)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("ran %d repositories at once, want at most 2", p)
+	}
+
+	var buf bytes.Buffer
+	drift, failed := printSummary(&buf, results)
+	if drift != 1 || failed != 1 {
+		t.Errorf("printSummary() = %d drifted, %d failed; want 1, 1", drift, failed)
+	}
+	if !strings.Contains(buf.String(), "3 ok, 1 drifted, 1 failed") {
+		t.Errorf("summary missing totals:\n%s", buf.String())
+	}
+}
@@ -1,4 +1,5 @@
-// Package hooks discovers and lists Claude Code hook scripts and hook configuration.
+// Package hooks discovers, lists, and manages Claude Code hook scripts and hook
+// configuration.
 package hooks
 
 import (
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
@@ -35,14 +37,187 @@ func Run(args []string) error {
 	}
 	switch subcmd {
 	case "list":
+		f := cli.New("hooks list", "hooks list")
+		positional, err := f.Parse(args[min(1, len(args)):])
+		if err != nil {
+			return err
+		}
+		if err := f.CheckArgs(positional, 0, 0); err != nil {
+			return err
+		}
 		return list()
+	case "enable", "disable":
+		return setEnabled(subcmd, args[1:])
+	case "add":
+		return add(args[1:])
+	case "run":
+		return run(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown hooks subcommand: %s\n", subcmd)
-		fmt.Fprintln(os.Stderr, "Usage: claude-workspace hooks [list]")
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace hooks [list|enable|disable|add|run]")
 		return fmt.Errorf("unknown subcommand: %s", subcmd)
 	}
 }
 
+// parseHookArgs splits subcommand arguments into the hook name and the values
+// of the flags in valueFlags (e.g. "--event").
+func parseHookArgs(subcmd string, args []string, valueFlags ...string) (string, map[string]string, error) {
+	values := map[string]string{}
+	f := cli.New("hooks "+subcmd, "hooks "+subcmd+" <name>")
+	for _, name := range valueFlags {
+		f.Func(name, hookFlags[name][0], hookFlags[name][1], func(v string) error {
+			values[name] = v
+			return nil
+		})
+	}
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return "", nil, err
+	}
+	return positional[0], values, nil
+}
+
+// hookFlags holds the placeholder and help of each flag parseHookArgs takes.
+var hookFlags = map[string][2]string{
+	"--event":   {"event", "Hook event (" + strings.Join(Events, ", ") + ")"},
+	"--matcher": {"pattern", "Tool name pattern the hook runs for"},
+	"--input":   {"file", "Event JSON to send the hook; - reads stdin"},
+}
+
+// setEnabled handles "hooks enable" and "hooks disable".
+func setEnabled(subcmd string, args []string) error {
+	name, flags, err := parseHookArgs(subcmd, args, "--event")
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	enabled := subcmd == "enable"
+	n, err := SetEnabled(cwd, name, flags["--event"], enabled)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		platform.PrintSuccess(os.Stdout, fmt.Sprintf("Enabled %s (%d %s restored to .claude/settings.json)", name, n, plural(n, "entry", "entries")))
+	} else {
+		platform.PrintSuccess(os.Stdout, fmt.Sprintf("Disabled %s (%d %s moved to %s)", name, n, plural(n, "entry", "entries"), DisabledFile))
+		fmt.Printf("  Re-enable with: claude-workspace hooks enable %s\n", name)
+	}
+	return nil
+}
+
+// add handles "hooks add".
+func add(args []string) error {
+	name, flags, err := parseHookArgs("add", args, "--event", "--matcher")
+	if err != nil {
+		return err
+	}
+	if flags["--event"] == "" {
+		return fmt.Errorf("--event is required (one of: %s)", strings.Join(Events, ", "))
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	path, err := Add(cwd, AddOptions{Name: name, Event: flags["--event"], Matcher: flags["--matcher"]})
+	if err != nil {
+		return err
+	}
+	rel, _ := filepath.Rel(cwd, path)
+	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Created %s and registered it for %s", rel, flags["--event"]))
+	fmt.Printf("  Edit the script, then test it with: claude-workspace hooks run %s\n", name)
+	return nil
+}
+
+// run handles "hooks run".
+func run(args []string) error {
+	name, flags, err := parseHookArgs("run", args, "--event", "--input")
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	opts := RunOptions{Name: name, Event: flags["--event"]}
+	switch path := flags["--input"]; path {
+	case "":
+	case "-":
+		opts.Input = os.Stdin
+	default:
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("reading hook input: %w", err)
+		}
+		defer f.Close()
+		opts.Input = f
+	}
+
+	res, err := RunHook(cwd, opts)
+	if res != nil {
+		printRunResult(res)
+	}
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("hook %s exited with status %d", name, res.ExitCode)
+	}
+	return nil
+}
+
+// printRunResult shows the input a hook received and how it responded.
+func printRunResult(res *RunResult) {
+	platform.PrintBanner(os.Stdout, "Hook Run")
+	fmt.Println()
+	fmt.Printf("  Event:    %s\n", res.Event)
+	if res.Matcher != "" {
+		fmt.Printf("  Matcher:  %s\n", res.Matcher)
+	}
+	fmt.Printf("  Command:  %s\n", res.Command)
+
+	platform.PrintSection(os.Stdout, "Input")
+	printIndented(string(res.Input))
+	if res.Stdout != "" {
+		platform.PrintSection(os.Stdout, "Stdout")
+		printIndented(res.Stdout)
+	}
+	if res.Stderr != "" {
+		platform.PrintSection(os.Stdout, "Stderr")
+		printIndented(res.Stderr)
+	}
+
+	platform.PrintSection(os.Stdout, "Result")
+	msg := fmt.Sprintf("Exit %d — %s", res.ExitCode, res.Outcome())
+	switch res.ExitCode {
+	case 0:
+		platform.PrintOK(os.Stdout, msg)
+	case 2:
+		platform.PrintFail(os.Stdout, msg)
+	default:
+		platform.PrintWarn(os.Stdout, msg)
+	}
+	fmt.Println()
+}
+
+func printIndented(s string) {
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
 // list discovers hook scripts and configuration from multiple sources and prints them.
 func list() error {
 	platform.PrintBanner(os.Stdout, "Hooks")
@@ -75,13 +250,23 @@ func list() error {
 				printConfigTable(configs)
 			}
 		}
+
+		disabledPath := filepath.Join(cwd, DisabledFile)
+		if platform.FileExists(disabledPath) {
+			configs := DiscoverHookConfig(disabledPath)
+			if len(configs) > 0 {
+				anyFound = true
+				platform.PrintSection(os.Stdout, "Disabled Hooks ("+DisabledFile+")")
+				printConfigTable(configs)
+			}
+		}
 	}
 
 	if !anyFound {
 		fmt.Println("  No hooks found.")
 		fmt.Println()
-		fmt.Println("  Create a hook script: .claude/hooks/my-hook.sh (must be executable)")
-		fmt.Println("  Configure hooks:      .claude/settings.json under \"hooks\" key")
+		fmt.Println("  Scaffold a hook: claude-workspace hooks add my-hook --event PreToolUse --matcher Bash")
+		fmt.Println("  Configure hooks: .claude/settings.json under \"hooks\" key")
 		fmt.Println()
 		return nil
 	}
@@ -89,8 +274,9 @@ func list() error {
 	// Tips
 	platform.PrintSection(os.Stdout, "Tips")
 	fmt.Println("  Hooks are shell scripts that run before/after tool use or on events.")
-	fmt.Println("  Create new:  .claude/hooks/my-hook.sh (must be executable)")
-	fmt.Println("  Configure:   .claude/settings.json under \"hooks\" key")
+	fmt.Println("  Create new:  claude-workspace hooks add <name> --event <event>")
+	fmt.Println("  Test:        claude-workspace hooks run <name>")
+	fmt.Println("  Toggle:      claude-workspace hooks disable|enable <name>")
 	fmt.Println()
 
 	return nil
@@ -253,9 +439,13 @@ func printConfigTable(configs []HookConfig) {
 		return
 	}
 
+	maxName := len("NAME")
 	maxEvent := len("EVENT")
 	maxMatcher := len("MATCHER")
 	for _, c := range configs {
+		if n := len(HookName(c.Command)); n > maxName {
+			maxName = n
+		}
 		if len(c.Event) > maxEvent {
 			maxEvent = len(c.Event)
 		}
@@ -264,13 +454,13 @@ func printConfigTable(configs []HookConfig) {
 		}
 	}
 
-	fmt.Printf("  %-*s  %-*s  %s\n", maxEvent, "EVENT", maxMatcher, "MATCHER", "STATUS MESSAGE")
+	fmt.Printf("  %-*s  %-*s  %-*s  %s\n", maxName, "NAME", maxEvent, "EVENT", maxMatcher, "MATCHER", "STATUS MESSAGE")
 	for _, c := range configs {
 		msg := c.StatusMessage
 		if len(msg) > 50 {
 			msg = msg[:47] + "..."
 		}
-		fmt.Printf("  %-*s  %-*s  %s\n", maxEvent, c.Event, maxMatcher, c.Matcher, msg)
+		fmt.Printf("  %-*s  %-*s  %-*s  %s\n", maxName, HookName(c.Command), maxEvent, c.Event, maxMatcher, c.Matcher, msg)
 	}
 	fmt.Println()
 }
@@ -0,0 +1,335 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// DisabledFile is where "hooks disable" keeps hook entries taken out of
+// .claude/settings.json, relative to the project. JSON has no comments, so the
+// entries are moved here, grouped by event and matcher as in settings.json,
+// and "hooks enable" moves them back.
+const DisabledFile = ".claude/hooks/disabled.json"
+
+// Events lists the Claude Code hook events that "hooks add" accepts.
+var Events = []string{
+	"PreToolUse", "PostToolUse", "UserPromptSubmit", "Notification", "Stop",
+	"SubagentStop", "PreCompact", "SessionStart", "SessionEnd",
+	"TaskCompleted", "TeammateIdle",
+}
+
+// hookNamePattern matches names accepted by "hooks add".
+var hookNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// eventEntry is one matcher group under an event in the settings.json "hooks"
+// key. Hook objects are kept as maps so fields such as "timeout" survive a
+// round trip.
+type eventEntry struct {
+	Matcher string                   `json:"matcher,omitempty"`
+	Hooks   []map[string]interface{} `json:"hooks"`
+}
+
+// hookTable is the settings.json "hooks" value: event name to matcher groups.
+type hookTable map[string][]eventEntry
+
+// HookName returns the name a hook command is managed by: the base name,
+// without extension, of the script it runs.
+func HookName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	target := fields[0]
+	for _, f := range fields {
+		if strings.Contains(f, "/") || strings.HasSuffix(strings.Trim(f, `"'`), ".sh") {
+			target = f
+			break
+		}
+	}
+	base := filepath.Base(strings.Trim(target, `"'`))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// validEvent reports whether event is a known hook event.
+func validEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// readHookTable reads the "hooks" key of a settings-shaped JSON file, along
+// with the file's other keys. A missing file reads as empty.
+func readHookTable(path string) (map[string]json.RawMessage, hookTable, error) {
+	raw := map[string]json.RawMessage{}
+	table := hookTable{}
+	if !platform.FileExists(path) {
+		return raw, table, nil
+	}
+	raw, err := platform.ReadJSONFileRaw(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if raw == nil {
+		raw = map[string]json.RawMessage{}
+	}
+	if hooksRaw, ok := raw["hooks"]; ok {
+		if err := json.Unmarshal(hooksRaw, &table); err != nil {
+			return nil, nil, fmt.Errorf("parsing hooks in %s: %w", path, err)
+		}
+	}
+	return raw, table, nil
+}
+
+// writeHookTable stores table under "hooks" in path, keeping the other keys.
+// The key is dropped when table is empty.
+func writeHookTable(path string, raw map[string]json.RawMessage, table hookTable) error {
+	if len(table) == 0 {
+		delete(raw, "hooks")
+	} else {
+		data, err := json.Marshal(table)
+		if err != nil {
+			return err
+		}
+		raw["hooks"] = data
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return platform.WriteSettingsFile(path, raw)
+}
+
+// moveHooks moves the hooks named name (on event, or on any event when event
+// is "") from src to dst, keeping their event and matcher. It returns the
+// number of hooks moved.
+func moveHooks(src, dst hookTable, name, event string) int {
+	moved := 0
+	for ev, entries := range src {
+		if event != "" && ev != event {
+			continue
+		}
+		var kept []eventEntry
+		for _, entry := range entries {
+			var remaining []map[string]interface{}
+			for _, h := range entry.Hooks {
+				command, _ := h["command"].(string)
+				if HookName(command) != name {
+					remaining = append(remaining, h)
+					continue
+				}
+				dst.add(ev, entry.Matcher, h)
+				moved++
+			}
+			if len(remaining) > 0 {
+				entry.Hooks = remaining
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(src, ev)
+		} else {
+			src[ev] = kept
+		}
+	}
+	return moved
+}
+
+// add appends hook to the matcher group for matcher under event, creating
+// the group if needed.
+func (t hookTable) add(event, matcher string, hook map[string]interface{}) {
+	for i := range t[event] {
+		if t[event][i].Matcher == matcher {
+			t[event][i].Hooks = append(t[event][i].Hooks, hook)
+			return
+		}
+	}
+	t[event] = append(t[event], eventEntry{Matcher: matcher, Hooks: []map[string]interface{}{hook}})
+}
+
+// names returns the sorted hook names in t.
+func (t hookTable) names() []string {
+	seen := map[string]bool{}
+	for _, entries := range t {
+		for _, entry := range entries {
+			for _, h := range entry.Hooks {
+				command, _ := h["command"].(string)
+				seen[HookName(command)] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetEnabled disables a hook by moving its entries from .claude/settings.json
+// to DisabledFile, or enables it by moving them back. event limits the change
+// to one event; "" applies it to every event the hook is registered for. It
+// returns the number of entries moved.
+func SetEnabled(projectDir, name, event string, enabled bool) (int, error) {
+	name = strings.TrimSuffix(name, ".sh")
+	settingsPath := filepath.Join(projectDir, ".claude", "settings.json")
+	disabledPath := filepath.Join(projectDir, DisabledFile)
+
+	// The lock on settings.json also covers DisabledFile, which only
+	// SetEnabled writes.
+	moved := 0
+	err := platform.WithFileLock(settingsPath, func() error {
+		settingsRaw, active, err := readHookTable(settingsPath)
+		if err != nil {
+			return err
+		}
+		disabledRaw, disabled, err := readHookTable(disabledPath)
+		if err != nil {
+			return err
+		}
+
+		src, dst := active, disabled
+		if enabled {
+			src, dst = disabled, active
+		}
+		if moved = moveHooks(src, dst, name, event); moved == 0 {
+			return notFound(name, event, enabled, src, dst)
+		}
+
+		if err := writeHookTable(settingsPath, settingsRaw, active); err != nil {
+			return fmt.Errorf("writing %s: %w", settingsPath, err)
+		}
+		if len(disabled) == 0 {
+			if err := os.Remove(disabledPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		if err := writeHookTable(disabledPath, disabledRaw, disabled); err != nil {
+			return fmt.Errorf("writing %s: %w", disabledPath, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// notFound explains why SetEnabled found nothing to move.
+func notFound(name, event string, enabled bool, src, dst hookTable) error {
+	from, to := "enabled", "disabled"
+	if enabled {
+		from, to = "disabled", "enabled"
+	}
+	for _, n := range dst.names() {
+		if n == name {
+			return fmt.Errorf("hook %q is already %s", name, to)
+		}
+	}
+	where := ""
+	if event != "" {
+		where = " for " + event
+	}
+	if names := src.names(); len(names) > 0 {
+		return fmt.Errorf("no %s hook %q%s (%s hooks: %s)", from, name, where, from, strings.Join(names, ", "))
+	}
+	return fmt.Errorf("no %s hook %q%s", from, name, where)
+}
+
+// AddOptions describes a hook scaffolded by "hooks add".
+type AddOptions struct {
+	Name    string
+	Event   string
+	Matcher string // tool name pattern for PreToolUse and PostToolUse; "" matches all
+}
+
+// Add writes .claude/hooks/<name>.sh from the hook template and registers it
+// for opts.Event in .claude/settings.json. It returns the script path.
+func Add(projectDir string, opts AddOptions) (string, error) {
+	if !hookNamePattern.MatchString(opts.Name) {
+		return "", fmt.Errorf("invalid hook name %q: use lowercase letters, digits, and dashes", opts.Name)
+	}
+	if !validEvent(opts.Event) {
+		return "", fmt.Errorf("unknown event %q (valid: %s)", opts.Event, strings.Join(Events, ", "))
+	}
+
+	scriptPath := filepath.Join(projectDir, ".claude", "hooks", opts.Name+".sh")
+	if platform.FileExists(scriptPath) {
+		return "", fmt.Errorf("%s already exists", scriptPath)
+	}
+	settingsPath := filepath.Join(projectDir, ".claude", "settings.json")
+	err := platform.WithFileLock(settingsPath, func() error {
+		raw, table, err := readHookTable(settingsPath)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(scriptPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(scriptPath, []byte(scriptTemplate(opts)), 0755); err != nil {
+			return fmt.Errorf("writing %s: %w", scriptPath, err)
+		}
+
+		table.add(opts.Event, opts.Matcher, map[string]interface{}{
+			"type":          "command",
+			"command":       `"$CLAUDE_PROJECT_DIR"/.claude/hooks/` + opts.Name + ".sh",
+			"statusMessage": "Running " + opts.Name + "...",
+		})
+		if err := writeHookTable(settingsPath, raw, table); err != nil {
+			return fmt.Errorf("writing %s: %w", settingsPath, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return scriptPath, nil
+}
+
+// scriptTemplate returns the starting script for a new hook.
+func scriptTemplate(opts AddOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#!/bin/bash\nset -euo pipefail\n\n# TODO: describe what %s checks (%s", opts.Name, opts.Event)
+	if opts.Matcher != "" {
+		fmt.Fprintf(&b, " on %s", opts.Matcher)
+	}
+	b.WriteString(")\n")
+	b.WriteString(`#
+# Claude Code sends the event as JSON on stdin. Exit 0 to continue; exit 2 to
+# block, with the reason on stderr for Claude to read. Any other status is
+# reported to the user without blocking.
+# Test it with: claude-workspace hooks run ` + opts.Name + "\n")
+	b.WriteString("INPUT=$(cat)\n")
+	switch opts.Event {
+	case "PreToolUse", "PostToolUse":
+		b.WriteString(`TOOL=$(echo "$INPUT" | jq -r '.tool_name // empty')
+
+case "$TOOL" in
+  Bash)
+    COMMAND=$(echo "$INPUT" | jq -r '.tool_input.command // empty')
+    ;;
+  Write|Edit)
+    FILE_PATH=$(echo "$INPUT" | jq -r '.tool_input.file_path // empty')
+    ;;
+esac
+`)
+	case "UserPromptSubmit":
+		b.WriteString(`PROMPT=$(echo "$INPUT" | jq -r '.prompt // empty')
+`)
+	default:
+		b.WriteString(`EVENT=$(echo "$INPUT" | jq -r '.hook_event_name // empty')
+`)
+	}
+	b.WriteString("\nexit 0\n")
+	return b.String()
+}
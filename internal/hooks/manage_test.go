@@ -0,0 +1,169 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookName(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{`"$CLAUDE_PROJECT_DIR"/.claude/hooks/block-dangerous.sh`, "block-dangerous"},
+		{`bash .claude/hooks/auto-format.sh --fix`, "auto-format"},
+		{`lint.sh`, "lint"},
+		{`/usr/local/bin/check-secrets`, "check-secrets"},
+		{`npx prettier --check`, "npx"},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		if got := HookName(tt.command); got != tt.want {
+			t.Errorf("HookName(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+// mkProject creates a project with .claude/settings.json holding settings.
+func mkProject(t *testing.T, settings map[string]interface{}) string {
+	t.Helper()
+	dir := t.TempDir()
+	claudeDir := filepath.Join(dir, ".claude")
+	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mkSettingsJSON(t, claudeDir, settings)
+	return dir
+}
+
+func TestSetEnabled(t *testing.T) {
+	dir := mkProject(t, map[string]interface{}{
+		"model": "opus",
+		"hooks": map[string]interface{}{
+			"PreToolUse": []interface{}{
+				map[string]interface{}{
+					"matcher": "Bash",
+					"hooks": []interface{}{
+						map[string]interface{}{"type": "command", "command": ".claude/hooks/block-dangerous.sh", "timeout": 30},
+						map[string]interface{}{"type": "command", "command": ".claude/hooks/audit.sh"},
+					},
+				},
+			},
+			"PostToolUse": []interface{}{
+				map[string]interface{}{
+					"matcher": "Write|Edit",
+					"hooks": []interface{}{
+						map[string]interface{}{"type": "command", "command": ".claude/hooks/audit.sh"},
+					},
+				},
+			},
+		},
+	})
+	settingsPath := filepath.Join(dir, ".claude", "settings.json")
+	disabledPath := filepath.Join(dir, DisabledFile)
+
+	n, err := SetEnabled(dir, "block-dangerous", "", false)
+	if err != nil || n != 1 {
+		t.Fatalf("disable: n=%d err=%v", n, err)
+	}
+	assertHookConfigs(t, DiscoverHookConfig(settingsPath), []HookConfig{
+		{Event: "PreToolUse", Matcher: "Bash", Command: ".claude/hooks/audit.sh"},
+		{Event: "PostToolUse", Matcher: "Write|Edit", Command: ".claude/hooks/audit.sh"},
+	})
+	assertHookConfigs(t, DiscoverHookConfig(disabledPath), []HookConfig{
+		{Event: "PreToolUse", Matcher: "Bash", Command: ".claude/hooks/block-dangerous.sh"},
+	})
+
+	if _, err := SetEnabled(dir, "block-dangerous", "", false); err == nil || !strings.Contains(err.Error(), "already disabled") {
+		t.Errorf("second disable error = %v, want already disabled", err)
+	}
+	if _, err := SetEnabled(dir, "missing", "", false); err == nil || !strings.Contains(err.Error(), "audit") {
+		t.Errorf("unknown hook error = %v, want it to list available hooks", err)
+	}
+
+	// --event limits the change to one event.
+	if n, err := SetEnabled(dir, "audit", "PostToolUse", false); err != nil || n != 1 {
+		t.Fatalf("disable audit for PostToolUse: n=%d err=%v", n, err)
+	}
+	assertHookConfigs(t, DiscoverHookConfig(settingsPath), []HookConfig{
+		{Event: "PreToolUse", Matcher: "Bash", Command: ".claude/hooks/audit.sh"},
+	})
+
+	for _, name := range []string{"block-dangerous", "audit"} {
+		if _, err := SetEnabled(dir, name, "", true); err != nil {
+			t.Fatalf("enable %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(disabledPath); !os.IsNotExist(err) {
+		t.Errorf("%s should be removed once empty", DisabledFile)
+	}
+
+	raw := map[string]interface{}{}
+	data, _ := os.ReadFile(settingsPath)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["model"] != "opus" {
+		t.Errorf("other settings not preserved: %v", raw)
+	}
+	pre := raw["hooks"].(map[string]interface{})["PreToolUse"].([]interface{})
+	var timeout interface{}
+	for _, h := range pre[0].(map[string]interface{})["hooks"].([]interface{}) {
+		if h.(map[string]interface{})["command"] == ".claude/hooks/block-dangerous.sh" {
+			timeout = h.(map[string]interface{})["timeout"]
+		}
+	}
+	if timeout != float64(30) {
+		t.Errorf("timeout = %v after round trip, want 30", timeout)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	dir := mkProject(t, map[string]interface{}{"model": "opus"})
+
+	path, err := Add(dir, AddOptions{Name: "no-curl", Event: "PreToolUse", Matcher: "Bash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("script mode = %v, want executable", info.Mode())
+	}
+	data, _ := os.ReadFile(path)
+	if desc := parseScriptDescriptionBytes(data); !strings.Contains(desc, "no-curl") {
+		t.Errorf("template description = %q", desc)
+	}
+
+	assertHookConfigs(t, DiscoverHookConfig(filepath.Join(dir, ".claude", "settings.json")), []HookConfig{
+		{Event: "PreToolUse", Matcher: "Bash", Command: `"$CLAUDE_PROJECT_DIR"/.claude/hooks/no-curl.sh`, StatusMessage: "Running no-curl..."},
+	})
+
+	if _, err := Add(dir, AddOptions{Name: "no-curl", Event: "PreToolUse"}); err == nil {
+		t.Error("Add should refuse to overwrite an existing script")
+	}
+}
+
+func TestAdd_Invalid(t *testing.T) {
+	dir := mkProject(t, map[string]interface{}{})
+	tests := []struct {
+		name string
+		opts AddOptions
+	}{
+		{"bad name", AddOptions{Name: "My Hook", Event: "Stop"}},
+		{"path in name", AddOptions{Name: "../evil", Event: "Stop"}},
+		{"unknown event", AddOptions{Name: "ok", Event: "BeforeEverything"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Add(dir, tt.opts); err == nil {
+				t.Errorf("Add(%+v) should fail", tt.opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/events"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+const relayUsage = `Usage: claude-workspace hook-relay (--url <url> | --sqlite <path> | --local) [--token-env <VAR>] [--timeout <duration>] [--no-content]
+       claude-workspace hook-relay install (--url <url> | --sqlite <path> | --local) [--token-env <VAR>] [--no-content]
+                                           [--events <a,b>] [--scope global|project|local]`
+
+// RelayEvents are the events "hook-relay install" registers by default.
+var RelayEvents = []string{"PreToolUse", "PostToolUse", "Stop"}
+
+// relayTimeout is how long a relay may spend delivering one event by default.
+// It runs before and after every tool call, so it must stay short.
+const relayTimeout = 3 * time.Second
+
+// relayHookTimeout is the timeout, in seconds, set on installed relay hooks so
+// Claude Code never waits long on one.
+const relayHookTimeout = 10
+
+// maxRelayField is the longest string value forwarded; longer values, such as
+// the content of a Write call, are cut.
+const maxRelayField = 2000
+
+// hookInput is the part of a hook event the relay reads.
+type hookInput struct {
+	SessionID    string          `json:"session_id"`
+	Cwd          string          `json:"cwd"`
+	Event        string          `json:"hook_event_name"`
+	Tool         string          `json:"tool_name"`
+	ToolInput    json.RawMessage `json:"tool_input"`
+	ToolResponse json.RawMessage `json:"tool_response"`
+	Prompt       string          `json:"prompt"`
+	Message      string          `json:"message"`
+	Source       string          `json:"source"`
+	Reason       string          `json:"reason"`
+	Trigger      string          `json:"trigger"`
+}
+
+type relayOptions struct {
+	url       string
+	sqlite    string
+	local     bool // the local store read by "events"
+	tokenEnv  string
+	timeout   time.Duration
+	noContent bool
+	events    []string // install only
+	scope     config.ConfigScope
+}
+
+func parseRelayArgs(args []string, install bool) (relayOptions, error) {
+	opts := relayOptions{timeout: relayTimeout, events: RelayEvents, scope: config.ScopeProject}
+	name := "hook-relay"
+	if install {
+		name = "hook-relay install"
+	}
+	f := cli.New(name, strings.TrimPrefix(relayUsage, "Usage: claude-workspace "))
+	f.Help = func(w io.Writer) { fmt.Fprintln(w, relayUsage) }
+	f.Func("--url", "url", "Forward events to this HTTP endpoint", func(v string) error {
+		u, err := url.Parse(v)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			return fmt.Errorf("invalid --url %q: use an http(s) URL", v)
+		}
+		opts.url = v
+		return nil
+	})
+	f.StringVar(&opts.sqlite, "--sqlite", "path", "Append events to this SQLite database")
+	f.BoolVar(&opts.local, "--local", "Record events in the local store read by 'events'")
+	f.StringVar(&opts.tokenEnv, "--token-env", "VAR", "Send the token in this variable as a bearer token")
+	f.Func("--timeout", "duration", "How long one delivery may take (default: 3s)", func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid --timeout %q: use a duration like 3s", v)
+		}
+		opts.timeout = d
+		return nil
+	})
+	f.BoolVar(&opts.noContent, "--no-content", "Leave out prompts, tool input, and tool output")
+	if install {
+		f.Func("--events", "a,b", "Events to relay (default: "+strings.Join(RelayEvents, ",")+")", func(v string) error {
+			opts.events = nil
+			for _, e := range strings.Split(v, ",") {
+				if e = strings.TrimSpace(e); !validEvent(e) {
+					return fmt.Errorf("unknown event %q (valid: %s)", e, strings.Join(Events, ", "))
+				}
+				opts.events = append(opts.events, e)
+			}
+			return nil
+		})
+		f.Func("--scope", "global|project|local", "Settings file to register the hooks in (default: project)", func(v string) error {
+			scope, err := parseSettingsScope(v)
+			opts.scope = scope
+			return err
+		})
+	}
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	if opts.url == "" && opts.sqlite == "" && !opts.local {
+		return opts, fmt.Errorf("give --url, --sqlite, or --local\n%s", relayUsage)
+	}
+	return opts, nil
+}
+
+// parseSettingsScope maps a --scope value to a writable settings layer.
+func parseSettingsScope(s string) (config.ConfigScope, error) {
+	switch s {
+	case "user", "global":
+		return config.ScopeUser, nil
+	case "project":
+		return config.ScopeProject, nil
+	case "local":
+		return config.ScopeLocal, nil
+	default:
+		return "", fmt.Errorf("invalid scope %q: must be global (user), project, or local", s)
+	}
+}
+
+// Relay implements "hook-relay": it reads a hook event on stdin and forwards
+// it, normalized and with secrets redacted, to an HTTP endpoint, a SQLite
+// database, the local event store, or several of them. "hook-relay install"
+// registers it in settings.json.
+//
+// A relay never blocks the agent: it prints nothing on stdout, and a delivery
+// that fails is reported on stderr while the command still succeeds.
+func Relay(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "install":
+			return installRelay(platform.Stdout(), args[1:])
+		case "help":
+			fmt.Fprintln(platform.Stdout(), relayUsage)
+			return nil
+		}
+	}
+	opts, err := parseRelayArgs(args, false)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, 16<<20))
+	if err != nil {
+		return nil
+	}
+	e, err := normalizeEvent(data, time.Now(), opts.noContent)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hook-relay: %v\n", err)
+		return nil
+	}
+	for _, err := range deliverEvent(e, opts) {
+		fmt.Fprintf(os.Stderr, "hook-relay: %v\n", err)
+	}
+	return nil
+}
+
+// normalizeEvent turns hook input into an events.Event. String values in the
+// tool input and response are redacted and cut to maxRelayField; with
+// noContent they are left out, along with prompts and messages.
+func normalizeEvent(data []byte, now time.Time, noContent bool) (*events.Event, error) {
+	var in hookInput
+	if err := json.Unmarshal(data, &in); err != nil || in.Event == "" {
+		return nil, fmt.Errorf("input is not a Claude Code hook event")
+	}
+	e := &events.Event{
+		Time:      now.UTC(),
+		Event:     in.Event,
+		SessionID: in.SessionID,
+		Project:   os.Getenv("CLAUDE_PROJECT_DIR"),
+		Cwd:       in.Cwd,
+		User:      currentUser(),
+		Tool:      in.Tool,
+	}
+	if e.Project == "" {
+		e.Project = in.Cwd
+	}
+	e.Host, _ = os.Hostname()
+	if noContent {
+		return e, nil
+	}
+
+	r := sessions.NewRedactor(nil)
+	e.Input = relayJSON(in.ToolInput, r)
+	e.Response = relayJSON(in.ToolResponse, r)
+	for _, detail := range []string{in.Prompt, in.Message, in.Source, in.Reason, in.Trigger} {
+		if detail != "" {
+			e.Detail = truncateField(r.String(detail))
+			break
+		}
+	}
+	return e, nil
+}
+
+// relayJSON returns raw with every string value redacted and cut, or nil when
+// raw is empty or not JSON.
+func relayJSON(raw json.RawMessage, r *sessions.Redactor) json.RawMessage {
+	var v interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil || v == nil {
+		return nil
+	}
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch t := v.(type) {
+		case string:
+			return truncateField(r.String(t))
+		case map[string]interface{}:
+			for k, child := range t {
+				t[k] = walk(child)
+			}
+		case []interface{}:
+			for i, child := range t {
+				t[i] = walk(child)
+			}
+		}
+		return v
+	}
+	data, err := json.Marshal(walk(v))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func truncateField(s string) string {
+	if len(s) <= maxRelayField {
+		return s
+	}
+	return fmt.Sprintf("%s... [%d bytes cut]", s[:maxRelayField], len(s)-maxRelayField)
+}
+
+func currentUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// deliverEvent sends e to every configured destination and returns the
+// failures.
+func deliverEvent(e *events.Event, opts relayOptions) []error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	var errs []error
+	if opts.url != "" {
+		if err := postEvent(ctx, e, opts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.sqlite != "" {
+		if err := events.Append(ctx, expandHome(opts.sqlite), e); err != nil {
+			errs = append(errs, fmt.Errorf("--sqlite: %w", err))
+		}
+	}
+	if opts.local {
+		path, err := events.DefaultPath()
+		if err == nil {
+			err = events.Append(ctx, path, e)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--local: %w", err))
+		}
+	}
+	return errs
+}
+
+func postEvent(ctx context.Context, e *events.Event, opts relayOptions) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claude-workspace-hook-relay")
+	if opts.tokenEnv != "" {
+		if token := os.Getenv(opts.tokenEnv); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	resp, err := platform.HTTPClient(opts.timeout).Do(req)
+	if err != nil {
+		return fmt.Errorf("sending to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~/ in path with the home directory, since
+// the path may reach the relay quoted.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// shellSafe matches arguments that need no quoting in a hook command.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./~-]+$`)
+
+// relayCommand returns the hook command that runs the relay with opts.
+func relayCommand(opts relayOptions) string {
+	args := []string{"claude-workspace", "hook-relay"}
+	if opts.url != "" {
+		args = append(args, "--url", opts.url)
+	}
+	if opts.sqlite != "" {
+		args = append(args, "--sqlite", opts.sqlite)
+	}
+	if opts.local {
+		args = append(args, "--local")
+	}
+	if opts.tokenEnv != "" {
+		args = append(args, "--token-env", opts.tokenEnv)
+	}
+	if opts.timeout != relayTimeout {
+		args = append(args, "--timeout", opts.timeout.String())
+	}
+	if opts.noContent {
+		args = append(args, "--no-content")
+	}
+	for i, a := range args {
+		if !shellSafe.MatchString(a) {
+			args[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// isRelayCommand reports whether a hook command runs the relay.
+func isRelayCommand(command string) bool {
+	fields := strings.Fields(command)
+	return len(fields) > 1 && filepath.Base(fields[0]) == "claude-workspace" && fields[1] == "hook-relay"
+}
+
+// installRelay registers the relay for opts.events in the settings.json of
+// opts.scope, replacing relay hooks installed before.
+func installRelay(w io.Writer, args []string) error {
+	opts, err := parseRelayArgs(args, true)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	path := config.SettingsFile(opts.scope, home, cwd)
+	command := relayCommand(opts)
+	err = platform.WithFileLock(path, func() error {
+		raw, table, err := readHookTable(path)
+		if err != nil {
+			return err
+		}
+		removeRelayHooks(table)
+		for _, event := range opts.events {
+			table.add(event, "", map[string]interface{}{
+				"type":    "command",
+				"command": command,
+				"timeout": relayHookTimeout,
+			})
+		}
+		return writeHookTable(path, raw, table)
+	})
+	if err != nil {
+		return err
+	}
+	platform.PrintOK(w, fmt.Sprintf("Registered hook-relay for %s in %s", strings.Join(opts.events, ", "), path))
+	fmt.Fprintf(w, "  Command: %s\n", command)
+	if opts.tokenEnv != "" {
+		platform.PrintManual(w, fmt.Sprintf("Set %s in the environment Claude Code runs in", opts.tokenEnv))
+	}
+	platform.PrintManual(w, "Restart running Claude Code sessions to pick up the hooks")
+	return nil
+}
+
+// removeRelayHooks drops every relay hook from table.
+func removeRelayHooks(table hookTable) {
+	for event, entries := range table {
+		var kept []eventEntry
+		for _, entry := range entries {
+			var remaining []map[string]interface{}
+			for _, h := range entry.Hooks {
+				if command, _ := h["command"].(string); !isRelayCommand(command) {
+					remaining = append(remaining, h)
+				}
+			}
+			if len(remaining) > 0 {
+				entry.Hooks = remaining
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			delete(table, event)
+		} else {
+			table[event] = kept
+		}
+	}
+}
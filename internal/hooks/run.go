@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// runTimeout bounds a hook started by "hooks run".
+const runTimeout = 60 * time.Second
+
+// RunResult is the outcome of running a hook locally.
+type RunResult struct {
+	Event    string
+	Matcher  string
+	Command  string
+	Input    []byte
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// RunOptions selects the hook to run and its input.
+type RunOptions struct {
+	Name  string
+	Event string    // event to run the hook for; "" picks the first it is registered for
+	Input io.Reader // event JSON; nil sends a sample for the event
+}
+
+// RunHook runs the hook named opts.Name from projectDir the way Claude Code
+// would: its command through the shell, with CLAUDE_PROJECT_DIR set and the
+// event JSON on stdin. Hooks registered in .claude/settings.json (or disabled
+// ones) run their configured command; otherwise .claude/hooks/<name>.sh is run
+// directly.
+func RunHook(projectDir string, opts RunOptions) (*RunResult, error) {
+	name := strings.TrimSuffix(opts.Name, ".sh")
+	res, err := findHook(projectDir, name, opts.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Input != nil {
+		if res.Input, err = io.ReadAll(opts.Input); err != nil {
+			return nil, fmt.Errorf("reading hook input: %w", err)
+		}
+		if !json.Valid(res.Input) {
+			return nil, fmt.Errorf("hook input is not valid JSON")
+		}
+	} else {
+		res.Input = SampleInput(projectDir, res.Event, res.Matcher)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", res.Command)
+	cmd.Dir = projectDir
+	cmd.Env = append(os.Environ(), "CLAUDE_PROJECT_DIR="+projectDir)
+	cmd.Stdin = bytes.NewReader(res.Input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err = cmd.Run()
+	res.Stdout, res.Stderr = stdout.String(), stderr.String()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return res, fmt.Errorf("hook timed out after %s", runTimeout)
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		return res, fmt.Errorf("running hook: %w", err)
+	}
+	return res, nil
+}
+
+// findHook locates the command, event, and matcher for the hook named name.
+func findHook(projectDir, name, event string) (*RunResult, error) {
+	for _, file := range []string{filepath.Join(".claude", "settings.json"), DisabledFile} {
+		_, table, err := readHookTable(filepath.Join(projectDir, file))
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range Events {
+			if event != "" && ev != event {
+				continue
+			}
+			for _, entry := range table[ev] {
+				for _, h := range entry.Hooks {
+					command, _ := h["command"].(string)
+					if HookName(command) == name {
+						return &RunResult{Event: ev, Matcher: entry.Matcher, Command: command}, nil
+					}
+				}
+			}
+		}
+	}
+
+	script := filepath.Join(projectDir, ".claude", "hooks", name+".sh")
+	if !platform.FileExists(script) {
+		if event != "" {
+			return nil, fmt.Errorf("no hook %q registered for %s and no %s", name, event, script)
+		}
+		return nil, fmt.Errorf("no hook %q in .claude/settings.json and no %s", name, script)
+	}
+	if event == "" {
+		event = "PreToolUse"
+	}
+	return &RunResult{Event: event, Command: `"$CLAUDE_PROJECT_DIR"/.claude/hooks/` + name + ".sh"}, nil
+}
+
+// SampleInput returns example event JSON for event, shaped like what Claude
+// Code sends. For tool events the tool is the first name in matcher.
+func SampleInput(projectDir, event, matcher string) []byte {
+	input := map[string]interface{}{
+		"session_id":      "hooks-run-sample",
+		"transcript_path": filepath.Join(os.TempDir(), "hooks-run-sample.jsonl"),
+		"cwd":             projectDir,
+		"hook_event_name": event,
+	}
+
+	switch event {
+	case "PreToolUse", "PostToolUse":
+		tool := sampleTool(matcher)
+		input["tool_name"] = tool
+		input["tool_input"] = sampleToolInput(projectDir, tool)
+		if event == "PostToolUse" {
+			input["tool_response"] = map[string]interface{}{"success": true}
+		}
+	case "UserPromptSubmit":
+		input["prompt"] = "Summarize the recent changes in this project."
+	case "Notification":
+		input["message"] = "Claude needs your permission to use Bash"
+	case "Stop", "SubagentStop":
+		input["stop_hook_active"] = false
+	case "PreCompact":
+		input["trigger"] = "manual"
+		input["custom_instructions"] = ""
+	case "SessionStart":
+		input["source"] = "startup"
+	case "SessionEnd":
+		input["reason"] = "exit"
+	}
+
+	data, _ := json.MarshalIndent(input, "", "  ")
+	return data
+}
+
+// sampleTool picks a concrete tool name from a matcher such as "Write|Edit".
+func sampleTool(matcher string) string {
+	first, _, _ := strings.Cut(matcher, "|")
+	first = strings.TrimSpace(first)
+	if first == "" || first == "*" || strings.ContainsAny(first, ".*+?[]()^$") {
+		return "Bash"
+	}
+	return first
+}
+
+func sampleToolInput(projectDir, tool string) map[string]interface{} {
+	file := filepath.Join(projectDir, "example.txt")
+	switch tool {
+	case "Bash":
+		return map[string]interface{}{"command": "echo hello", "description": "Print a greeting"}
+	case "Write":
+		return map[string]interface{}{"file_path": file, "content": "hello\n"}
+	case "Edit":
+		return map[string]interface{}{"file_path": file, "old_string": "hello", "new_string": "goodbye"}
+	case "MultiEdit":
+		return map[string]interface{}{"file_path": file, "edits": []map[string]string{{"old_string": "hello", "new_string": "goodbye"}}}
+	case "Read":
+		return map[string]interface{}{"file_path": file}
+	case "Glob", "Grep":
+		return map[string]interface{}{"pattern": "TODO"}
+	case "WebFetch":
+		return map[string]interface{}{"url": "https://example.com", "prompt": "Summarize the page"}
+	}
+	return map[string]interface{}{}
+}
+
+// Outcome describes what Claude Code does with a hook's exit code.
+func (r *RunResult) Outcome() string {
+	switch r.ExitCode {
+	case 0:
+		return "success: Claude Code continues"
+	case 2:
+		if r.Event == "PreToolUse" || r.Event == "UserPromptSubmit" {
+			return "blocked: the action is stopped and stderr is shown to Claude"
+		}
+		return "blocking error: stderr is shown to Claude"
+	}
+	return "non-blocking error: stderr is shown to the user and Claude Code continues"
+}
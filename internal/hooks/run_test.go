@@ -0,0 +1,116 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSampleInput(t *testing.T) {
+	tests := []struct {
+		event, matcher string
+		key            string
+		want           interface{}
+	}{
+		{"PreToolUse", "Bash", "tool_name", "Bash"},
+		{"PreToolUse", "Write|Edit", "tool_name", "Write"},
+		{"PreToolUse", "", "tool_name", "Bash"},
+		{"PreToolUse", "mcp__.*", "tool_name", "Bash"},
+		{"UserPromptSubmit", "", "hook_event_name", "UserPromptSubmit"},
+		{"SessionStart", "", "source", "startup"},
+	}
+	for _, tt := range tests {
+		var input map[string]interface{}
+		if err := json.Unmarshal(SampleInput("/proj", tt.event, tt.matcher), &input); err != nil {
+			t.Fatal(err)
+		}
+		if input[tt.key] != tt.want {
+			t.Errorf("SampleInput(%s, %q)[%s] = %v, want %v", tt.event, tt.matcher, tt.key, input[tt.key], tt.want)
+		}
+		if input["cwd"] != "/proj" {
+			t.Errorf("cwd = %v, want /proj", input["cwd"])
+		}
+	}
+
+	var post map[string]interface{}
+	_ = json.Unmarshal(SampleInput("/proj", "PostToolUse", "Edit"), &post)
+	if _, ok := post["tool_response"]; !ok {
+		t.Error("PostToolUse sample should include tool_response")
+	}
+	if ti, _ := post["tool_input"].(map[string]interface{}); ti["old_string"] == nil {
+		t.Errorf("Edit tool_input = %v, want old_string", post["tool_input"])
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	dir := mkProject(t, map[string]interface{}{})
+	if _, err := Add(dir, AddOptions{Name: "no-curl", Event: "PreToolUse", Matcher: "Bash"}); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, ".claude", "hooks", "no-curl.sh")
+	body := `#!/bin/sh
+# Blocks curl
+INPUT=$(cat)
+case "$INPUT" in
+  *curl*) echo "curl is not allowed" >&2; exit 2 ;;
+esac
+echo "ok from $CLAUDE_PROJECT_DIR"
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := RunHook(dir, RunOptions{Name: "no-curl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ExitCode != 0 || res.Event != "PreToolUse" || res.Matcher != "Bash" {
+		t.Errorf("sample run = %+v", res)
+	}
+	if !strings.Contains(res.Stdout, "ok from "+dir) {
+		t.Errorf("stdout = %q, want CLAUDE_PROJECT_DIR set", res.Stdout)
+	}
+
+	input := strings.NewReader(`{"tool_name":"Bash","tool_input":{"command":"curl example.com"}}`)
+	res, err = RunHook(dir, RunOptions{Name: "no-curl", Input: input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ExitCode != 2 || !strings.Contains(res.Stderr, "not allowed") {
+		t.Errorf("blocked run = %+v", res)
+	}
+	if !strings.HasPrefix(res.Outcome(), "blocked") {
+		t.Errorf("Outcome() = %q", res.Outcome())
+	}
+
+	if _, err := RunHook(dir, RunOptions{Name: "no-curl", Input: strings.NewReader("not json")}); err == nil {
+		t.Error("RunHook should reject invalid JSON input")
+	}
+	if _, err := RunHook(dir, RunOptions{Name: "missing"}); err == nil {
+		t.Error("RunHook should fail for an unknown hook")
+	}
+}
+
+func TestRunHook_UnregisteredScript(t *testing.T) {
+	dir := mkProject(t, map[string]interface{}{})
+	hooksDir := filepath.Join(dir, ".claude", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hooksDir, "warn.sh"), []byte("#!/bin/sh\ncat >/dev/null\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := RunHook(dir, RunOptions{Name: "warn.sh", Event: "Stop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ExitCode != 1 || res.Event != "Stop" {
+		t.Errorf("result = %+v", res)
+	}
+	if !strings.HasPrefix(res.Outcome(), "non-blocking") {
+		t.Errorf("Outcome() = %q", res.Outcome())
+	}
+}
@@ -0,0 +1,386 @@
+// Package localize implements the "localize" command, which builds a
+// project's .claude/settings.local.json from prompts: extra directories Claude
+// Code may access, personal model overrides, local env vars, and whether the
+// project's MCP servers connect without asking.
+package localize
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/models"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// LocalFile is the settings file localize writes, relative to the project.
+const LocalFile = ".claude/settings.local.json"
+
+// subagentEnv is the env var that overrides the subagent model.
+const subagentEnv = "CLAUDE_CODE_SUBAGENT_MODEL"
+
+// Run executes the localize command. Each question shows the current value,
+// which Enter keeps and "-" clears; answers are checked as they are given and
+// the result against the settings schema before anything is written. With
+// --dry-run the result is shown but not written.
+func Run(args []string) error {
+	dryRun := false
+	f := cli.New("localize", "localize [project-path] [--dry-run]")
+	f.BoolVar(&dryRun, "--dry-run", "Show the result without writing it")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return err
+	}
+	target := "."
+	if len(positional) == 1 {
+		target = positional[0]
+	}
+	projectDir, err := filepath.Abs(target)
+	if err != nil {
+		return fmt.Errorf("resolving path: %w", err)
+	}
+	if !platform.FileExists(filepath.Join(projectDir, ".claude")) {
+		return fmt.Errorf("no .claude directory found in %s (run claude-workspace attach first)", projectDir)
+	}
+	return run(platform.Stdout(), bufio.NewReader(os.Stdin), projectDir, dryRun)
+}
+
+// run asks the questions on w, reading answers from in, and writes the
+// result to the project's LocalFile.
+func run(w io.Writer, in *bufio.Reader, projectDir string, dryRun bool) error {
+	path := filepath.Join(projectDir, filepath.FromSlash(LocalFile))
+	before, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", LocalFile, err)
+	}
+	root, orig := map[string]interface{}{}, map[string]interface{}{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &root); err != nil {
+			return fmt.Errorf("parsing %s: %w", LocalFile, err)
+		}
+		_ = json.Unmarshal(before, &orig)
+	}
+
+	platform.PrintBanner(w, "Local Settings: "+projectDir)
+	fmt.Fprintf(w, "\n  Answers go to %s, your personal settings for this project.\n", LocalFile)
+	fmt.Fprintln(w, "  Press Enter to keep the value in brackets, or enter - to clear it.")
+
+	q := &questions{w: w, in: in, projectDir: projectDir}
+	q.directories(root)
+	q.models(root)
+	q.env(root)
+	q.mcp(root)
+
+	after, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling settings: %w", err)
+	}
+	after = append(after, '\n')
+	if err := validate(w, after); err != nil {
+		return err
+	}
+
+	platform.PrintSection(w, "Result")
+	if reflect.DeepEqual(orig, root) {
+		fmt.Fprintf(w, "  No changes to %s.\n\n", LocalFile)
+		return nil
+	}
+	platform.WriteLineDiff(w, string(before), string(after))
+	if dryRun {
+		fmt.Fprintf(w, "\n  Dry run: %s was not written.\n\n", LocalFile)
+		return nil
+	}
+	if !q.confirm(fmt.Sprintf("Write %s?", LocalFile), true) {
+		fmt.Fprintf(w, "\n  %s was not written.\n\n", LocalFile)
+		return nil
+	}
+	if err := platform.WithFileLock(path, func() error { return platform.WriteSettingsData(path, after) }); err != nil {
+		return fmt.Errorf("writing %s: %w", LocalFile, err)
+	}
+	platform.PrintSuccess(w, "Wrote "+LocalFile)
+	checkIgnored(w, projectDir)
+	fmt.Fprintln(w)
+	return nil
+}
+
+// validate checks the settings against the schema, printing warnings and
+// returning an error for values Claude Code would reject.
+func validate(w io.Writer, data []byte) error {
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return err
+	}
+	var errs []string
+	for _, issue := range config.ValidateSettings(settings) {
+		if issue.Error {
+			errs = append(errs, issue.Message)
+			continue
+		}
+		platform.PrintWarningLine(w, issue.Message)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s would not be valid: %s", LocalFile, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// checkIgnored warns when the project's .claude/.gitignore does not keep the
+// local settings out of git.
+func checkIgnored(w io.Writer, projectDir string) {
+	gitignore := filepath.Join(projectDir, ".claude", ".gitignore")
+	if platform.HasDenyAllPattern(gitignore) {
+		return
+	}
+	if missing, err := platform.MissingGitignoreEntries(gitignore, "settings.local.json\n"); err == nil && len(missing) > 0 {
+		platform.PrintWarningLine(w, ".claude/.gitignore does not ignore settings.local.json; add it before committing")
+	}
+}
+
+// questions asks for each group of settings and applies the answers to the
+// decoded settings.
+type questions struct {
+	w          io.Writer
+	in         *bufio.Reader
+	projectDir string
+	eof        bool // input ended; every remaining question keeps its value
+}
+
+// ask prints prompt and returns the trimmed answer, or "" once input ends.
+func (q *questions) ask(prompt string) string {
+	if q.eof {
+		return ""
+	}
+	platform.PrintPrompt(q.w, "  "+prompt+" ")
+	line, err := q.in.ReadString('\n')
+	if err != nil {
+		q.eof = true
+		fmt.Fprintln(q.w)
+	}
+	return strings.TrimSpace(line)
+}
+
+// askValid asks until check accepts the answer, which is returned; "" keeps
+// the current value and "-" clears it.
+func (q *questions) askValid(prompt string, check func(string) error) string {
+	for {
+		answer := q.ask(prompt)
+		if answer == "" || answer == "-" {
+			return answer
+		}
+		err := check(answer)
+		if err == nil {
+			return answer
+		}
+		platform.PrintErrorLine(q.w, err.Error())
+		if q.eof {
+			return ""
+		}
+	}
+}
+
+// confirm asks a yes/no question; Enter and the end of input answer def.
+func (q *questions) confirm(prompt string, def bool) bool {
+	options := "[y/N]"
+	if def {
+		options = "[Y/n]"
+	}
+	switch strings.ToLower(q.ask(prompt + " " + options)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// directories asks for permissions.additionalDirectories. Each directory
+// must exist; relative paths are resolved against the project.
+func (q *questions) directories(root map[string]interface{}) {
+	platform.PrintSection(q.w, "Additional directories")
+	fmt.Fprintln(q.w, "  Directories outside the project Claude Code may read and edit, comma-separated.")
+	perms, _ := root["permissions"].(map[string]interface{})
+	current := toStrings(perms["additionalDirectories"])
+	var dirs []string
+	answer := q.askValid(fmt.Sprintf("Directories [%s]:", orNone(strings.Join(current, ", "))), func(answer string) error {
+		var err error
+		dirs, err = q.parseDirs(answer)
+		return err
+	})
+	switch answer {
+	case "":
+		return
+	case "-":
+		dirs = nil
+	}
+	if perms == nil {
+		perms = map[string]interface{}{}
+	}
+	if len(dirs) == 0 {
+		delete(perms, "additionalDirectories")
+	} else {
+		list := make([]interface{}, len(dirs))
+		for i, d := range dirs {
+			list[i] = d
+		}
+		perms["additionalDirectories"] = list
+	}
+	setOrDelete(root, "permissions", perms)
+}
+
+// parseDirs splits a comma-separated answer into directories, expanding a
+// leading ~ and checking that each exists.
+func (q *questions) parseDirs(answer string) ([]string, error) {
+	var dirs []string
+	for _, d := range strings.Split(answer, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(d, "~"); ok && (rest == "" || rest[0] == '/') {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("expanding %s: %w", d, err)
+			}
+			d = home + rest
+		}
+		resolved := d
+		if !filepath.IsAbs(resolved) {
+			resolved = filepath.Join(q.projectDir, resolved)
+		}
+		if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("not a directory: %s", d)
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+// models asks for the main and subagent model overrides.
+func (q *questions) models(root map[string]interface{}) {
+	platform.PrintSection(q.w, "Models")
+	fmt.Fprintln(q.w, "  Personal overrides of the project's models: an alias (opus, sonnet, haiku, opusplan) or a full model ID.")
+	current, _ := root["model"].(string)
+	switch answer := q.askValid(fmt.Sprintf("Main model [%s]:", orNone(current)), models.CheckModel); answer {
+	case "":
+	case "-":
+		delete(root, "model")
+	default:
+		root["model"] = answer
+	}
+
+	env, _ := root["env"].(map[string]interface{})
+	current, _ = env[subagentEnv].(string)
+	switch answer := q.askValid(fmt.Sprintf("Subagent model [%s]:", orNone(current)), models.CheckModel); answer {
+	case "":
+	case "-":
+		delete(env, subagentEnv)
+		setOrDelete(root, "env", env)
+	default:
+		if env == nil {
+			env = map[string]interface{}{}
+		}
+		env[subagentEnv] = answer
+		root["env"] = env
+	}
+}
+
+// env asks for local env vars, one NAME=value per line, until an empty line.
+// NAME= (or NAME=-) removes a variable.
+func (q *questions) env(root map[string]interface{}) {
+	platform.PrintSection(q.w, "Environment variables")
+	fmt.Fprintln(q.w, "  Variables set in every Claude Code session in this project, one NAME=value per line.")
+	fmt.Fprintln(q.w, "  NAME= removes one; an empty line finishes. Values are stored in plain text:")
+	fmt.Fprintln(q.w, "  keep secrets in `claude-workspace secrets` instead.")
+	env, _ := root["env"].(map[string]interface{})
+	if env == nil {
+		env = map[string]interface{}{}
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if name != subagentEnv {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(q.w, "    %s=%v\n", name, env[name])
+	}
+	for {
+		answer := q.askValid("NAME=value:", checkEnvAssignment)
+		if answer == "" || answer == "-" {
+			break
+		}
+		name, value, _ := strings.Cut(answer, "=")
+		name = strings.TrimSpace(name)
+		if value = strings.TrimSpace(value); value == "" || value == "-" {
+			delete(env, name)
+			continue
+		}
+		// Env values are always strings in settings.json.
+		env[name] = value
+	}
+	setOrDelete(root, "env", env)
+}
+
+// checkEnvAssignment returns an error unless answer is NAME=value with a
+// valid variable name.
+func checkEnvAssignment(answer string) error {
+	name, _, ok := strings.Cut(answer, "=")
+	if !ok {
+		return fmt.Errorf("expected NAME=value, got %q", answer)
+	}
+	if name = strings.TrimSpace(name); !platform.IsEnvVarName(name) {
+		return fmt.Errorf("invalid variable name %q: use letters, digits, and underscores", name)
+	}
+	return nil
+}
+
+// mcp asks whether every server in .mcp.json connects without a prompt.
+func (q *questions) mcp(root map[string]interface{}) {
+	platform.PrintSection(q.w, "MCP servers")
+	current, set := root["enableAllProjectMcpServers"].(bool)
+	if q.confirm("Connect every server in .mcp.json without asking?", current) {
+		root["enableAllProjectMcpServers"] = true
+	} else if set {
+		root["enableAllProjectMcpServers"] = false
+	}
+}
+
+// setOrDelete sets root[key] to m, or removes the key when m is empty.
+func setOrDelete(root map[string]interface{}, key string, m map[string]interface{}) {
+	if len(m) == 0 {
+		delete(root, key)
+		return
+	}
+	root[key] = m
+}
+
+// toStrings returns the strings in a decoded JSON array.
+func toStrings(v interface{}) []string {
+	items, _ := v.([]interface{})
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package localize
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func projectWith(t *testing.T, local string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if local != "" {
+		if err := os.WriteFile(filepath.Join(dir, ".claude", "settings.local.json"), []byte(local), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readLocal(t *testing.T, dir string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ".claude", "settings.local.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func answers(lines ...string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+}
+
+func TestRun(t *testing.T) {
+	dir := projectWith(t, `{"permissions":{"allow":["Read"]},"env":{"FOO":"1"}}`)
+	shared := t.TempDir()
+
+	// An invalid directory or model is explained and asked again.
+	in := answers(
+		"missing-dir,"+shared, shared,
+		"gpt-4", "sonnet",
+		"",
+		"BAD-NAME=1", "BAR=2", "FOO=", "",
+		"y",
+		"",
+	)
+	if err := run(io.Discard, in, dir, false); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"permissions": map[string]interface{}{
+			"allow":                 []interface{}{"Read"},
+			"additionalDirectories": []interface{}{shared},
+		},
+		"model":                      "sonnet",
+		"env":                        map[string]interface{}{"BAR": "2"},
+		"enableAllProjectMcpServers": true,
+	}
+	if got := readLocal(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("settings.local.json = %v\nwant %v", got, want)
+	}
+
+	// Clearing every value removes the keys that held only them.
+	if err := run(io.Discard, answers("-", "-", "", "BAR=", "", "n", "y"), dir, false); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{
+		"permissions":                map[string]interface{}{"allow": []interface{}{"Read"}},
+		"enableAllProjectMcpServers": false,
+	}
+	if got := readLocal(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("after clearing = %v\nwant %v", got, want)
+	}
+}
+
+func TestRun_DryRunAndDecline(t *testing.T) {
+	dir := projectWith(t, "")
+	path := filepath.Join(dir, ".claude", "settings.local.json")
+
+	if err := run(io.Discard, answers("", "opus"), dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("--dry-run wrote the file")
+	}
+	if err := run(io.Discard, answers("", "opus", "", "", "", "n"), dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("declined write created the file")
+	}
+
+	// No answers at all keeps everything, which writes nothing.
+	var out strings.Builder
+	if err := run(&out, bufio.NewReader(strings.NewReader("")), dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No changes") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestRun_InvalidExisting(t *testing.T) {
+	dir := projectWith(t, `{"enableAllProjectMcpServers":"yes"}`)
+	err := run(io.Discard, answers("", "", "", "", ""), dir, false)
+	if err == nil || !strings.Contains(err.Error(), "enableAllProjectMcpServers") {
+		t.Errorf("err = %v, want the schema error", err)
+	}
+}
+
+func TestCheckEnvAssignment(t *testing.T) {
+	for _, ok := range []string{"FOO=bar", "_X=", "A1 = b c"} {
+		if err := checkEnvAssignment(ok); err != nil {
+			t.Errorf("checkEnvAssignment(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"FOO", "1A=b", "MY-VAR=1", "=x"} {
+		if err := checkEnvAssignment(bad); err == nil {
+			t.Errorf("checkEnvAssignment(%q): expected error", bad)
+		}
+	}
+}
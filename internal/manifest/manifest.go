@@ -0,0 +1,275 @@
+// Package manifest loads the per-project claude-workspace.yaml manifest, which
+// declares the agents, skills, hooks, and MCP servers a project wants. When a
+// manifest is present, attach provisions only the declared assets instead of
+// copying everything from the embedded template. Embedded template profiles
+// use the same format in their profile.yaml.
+package manifest
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// FileName is the manifest file name looked up in the project root.
+const FileName = "claude-workspace.yaml"
+
+// altFileName is accepted as a fallback spelling of FileName.
+const altFileName = "claude-workspace.yml"
+
+// Scalar keys accepted in a manifest.
+const (
+	keyProfile     = "profile"
+	keyDescription = "description"
+)
+
+// Asset kinds that a manifest can select. The values double as the YAML keys.
+const (
+	KindAgents     = "agents"
+	KindSkills     = "skills"
+	KindHooks      = "hooks"
+	KindMCPServers = "mcpServers"
+)
+
+// kinds lists every selectable asset kind in display order.
+var kinds = []string{KindAgents, KindSkills, KindHooks, KindMCPServers}
+
+// Manifest is a parsed claude-workspace.yaml. A kind that is omitted from the
+// file selects every available asset of that kind; a kind that is present
+// (even as an empty list) selects exactly the listed names.
+type Manifest struct {
+	Path        string
+	Profile     string // embedded template profile to start from
+	Description string // human-readable summary, shown for profiles
+	Agents      []string
+	Skills      []string
+	Hooks       []string
+	MCPServers  []string
+
+	declared map[string]bool
+}
+
+// Find returns the manifest path in projectDir, or "" if none exists.
+func Find(projectDir string) string {
+	for _, name := range []string{FileName, altFileName} {
+		path := filepath.Join(projectDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Load reads the manifest from projectDir. It returns (nil, nil) when the
+// project has no manifest.
+func Load(projectDir string) (*Manifest, error) {
+	path := Find(projectDir)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	m.Path = path
+	return m, nil
+}
+
+// Parse parses manifest content. Unknown keys are rejected so typos surface
+// instead of silently selecting everything.
+func Parse(data []byte) (*Manifest, error) {
+	entries, err := platform.ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{declared: make(map[string]bool)}
+	for _, e := range entries {
+		switch {
+		case e.Key == keyProfile:
+			m.Profile = e.Value
+			continue
+		case e.Key == keyDescription:
+			m.Description = e.Value
+			continue
+		case !slices.Contains(kinds, e.Key):
+			return nil, fmt.Errorf("line %d: unknown key %q (valid keys: %s)", e.Line, e.Key, strings.Join(kinds, ", "))
+		}
+		names, err := e.Values()
+		if err != nil {
+			return nil, err
+		}
+		switch e.Key {
+		case KindAgents:
+			m.Agents = names
+		case KindSkills:
+			m.Skills = names
+		case KindHooks:
+			m.Hooks = names
+		case KindMCPServers:
+			m.MCPServers = names
+		}
+		m.declared[e.Key] = true
+	}
+	return m, nil
+}
+
+// LoadProfile parses the profile.yaml of the named embedded template profile.
+func LoadProfile(name string) (*Manifest, error) {
+	data, err := platform.ReadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing profile %q: %w", name, err)
+	}
+	if m.Profile != "" {
+		return nil, fmt.Errorf("profile %q: profiles cannot reference another profile", name)
+	}
+	m.Profile = name
+	return m, nil
+}
+
+// Resolve returns the effective manifest and template filesystem for a project.
+// The profile is taken from the profile argument (e.g. attach --profile) or,
+// when that is empty, from the project manifest's profile key. With a profile,
+// the project manifest is layered over the profile's selections and the
+// returned filesystem includes the profile's extra assets; otherwise the
+// project manifest (possibly nil) and platform.FS are returned unchanged.
+func Resolve(projectDir, profile string) (*Manifest, fs.FS, error) {
+	m, err := Load(projectDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if profile == "" && m != nil {
+		profile = m.Profile
+	}
+	if profile == "" {
+		return m, platform.FS, nil
+	}
+
+	pm, err := LoadProfile(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	assetFS, err := platform.ProfileFS(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Over(pm), assetFS, nil
+}
+
+// Over returns a manifest that layers m on top of base: kinds declared in m
+// win, and kinds m omits fall back to base. Either side may be nil.
+func (m *Manifest) Over(base *Manifest) *Manifest {
+	if m == nil {
+		return base
+	}
+	if base == nil {
+		return m
+	}
+	merged := &Manifest{
+		Path:        m.Path,
+		Profile:     base.Profile,
+		Description: m.Description,
+		declared:    make(map[string]bool),
+	}
+	for _, kind := range kinds {
+		src := base
+		if m.Declares(kind) {
+			src = m
+		}
+		if !src.Declares(kind) {
+			continue
+		}
+		merged.declared[kind] = true
+		names := src.names(kind)
+		switch kind {
+		case KindAgents:
+			merged.Agents = names
+		case KindSkills:
+			merged.Skills = names
+		case KindHooks:
+			merged.Hooks = names
+		case KindMCPServers:
+			merged.MCPServers = names
+		}
+	}
+	return merged
+}
+
+// Declares reports whether the manifest explicitly selects assets of kind.
+func (m *Manifest) Declares(kind string) bool {
+	return m != nil && m.declared[kind]
+}
+
+// Includes reports whether the named asset of kind should be provisioned.
+// A nil manifest includes everything.
+func (m *Manifest) Includes(kind, name string) bool {
+	if !m.Declares(kind) {
+		return true
+	}
+	for _, n := range m.names(kind) {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that every declared name exists in available, which maps an
+// asset kind to the names that can be provisioned. Kinds missing from available
+// are not checked.
+func (m *Manifest) Validate(available map[string][]string) error {
+	if m == nil {
+		return nil
+	}
+	var problems []string
+	for _, kind := range kinds {
+		known, ok := available[kind]
+		if !ok || !m.Declares(kind) {
+			continue
+		}
+		set := make(map[string]bool, len(known))
+		for _, k := range known {
+			set[k] = true
+		}
+		for _, name := range m.names(kind) {
+			if !set[name] {
+				sorted := append([]string(nil), known...)
+				sort.Strings(sorted)
+				problems = append(problems, fmt.Sprintf("unknown %s entry %q (available: %s)", kind, name, strings.Join(sorted, ", ")))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// names returns the declared list for kind.
+func (m *Manifest) names(kind string) []string {
+	switch kind {
+	case KindAgents:
+		return m.Agents
+	case KindSkills:
+		return m.Skills
+	case KindHooks:
+		return m.Hooks
+	case KindMCPServers:
+		return m.MCPServers
+	}
+	return nil
+}
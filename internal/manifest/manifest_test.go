@@ -0,0 +1,205 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`# Team manifest
+agents:
+  - planner
+  - "code-reviewer"   # quoted
+skills: [onboarding, 'pr-workflow']
+hooks: []
+`)
+	m, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if strings.Join(m.Agents, ",") != "planner,code-reviewer" {
+		t.Errorf("Agents = %v", m.Agents)
+	}
+	if strings.Join(m.Skills, ",") != "onboarding,pr-workflow" {
+		t.Errorf("Skills = %v", m.Skills)
+	}
+	if !m.Declares(KindHooks) || len(m.Hooks) != 0 {
+		t.Errorf("hooks should be declared and empty, got %v", m.Hooks)
+	}
+	if m.Declares(KindMCPServers) {
+		t.Error("mcpServers should not be declared")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"unknown key", "agent:\n  - planner\n", "unknown key"},
+		{"scalar kind", "agents: planner\n", "must be a list"},
+		{"stray indentation", "  - planner\n", "unexpected indentation"},
+		{"duplicate key", "agents: []\nagents: []\n", "duplicate key"},
+		{"unterminated flow", "skills: [a, b\n", "unterminated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	var nilManifest *Manifest
+	if !nilManifest.Includes(KindAgents, "planner") {
+		t.Error("nil manifest should include everything")
+	}
+
+	m, _ := Parse([]byte("agents:\n  - planner\nhooks: []\n"))
+	if !m.Includes(KindAgents, "planner") {
+		t.Error("declared agent should be included")
+	}
+	if m.Includes(KindAgents, "explorer") {
+		t.Error("undeclared agent should be excluded")
+	}
+	if m.Includes(KindHooks, "auto-format") {
+		t.Error("empty hooks list should exclude all hooks")
+	}
+	if !m.Includes(KindSkills, "onboarding") {
+		t.Error("omitted kind should include everything")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	m, _ := Parse([]byte("agents: [planner, nope]\n"))
+	err := m.Validate(map[string][]string{KindAgents: {"planner", "explorer"}})
+	if err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("Validate() error = %v, want unknown agent error", err)
+	}
+
+	m, _ = Parse([]byte("agents: [planner]\n"))
+	if err := m.Validate(map[string][]string{KindAgents: {"planner"}}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Load(dir)
+	if err != nil || m != nil {
+		t.Fatalf("Load() without manifest = %v, %v; want nil, nil", m, err)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "claude-workspace.yml"), []byte("skills: [onboarding]\n"), 0644)
+	m, err = Load(dir)
+	if err != nil || m == nil {
+		t.Fatalf("Load() = %v, %v", m, err)
+	}
+	if filepath.Base(m.Path) != "claude-workspace.yml" {
+		t.Errorf("Path = %q, want .yml fallback", m.Path)
+	}
+}
+
+func TestRenderSettings(t *testing.T) {
+	settings := []byte(`{
+  "model": "opus",
+  "hooks": {
+    "PreToolUse": [
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "\"$CLAUDE_PROJECT_DIR\"/.claude/hooks/keep.sh"}]},
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "\"$CLAUDE_PROJECT_DIR\"/.claude/hooks/drop.sh"}]}
+    ],
+    "TeammateIdle": [
+      {"hooks": [{"type": "command", "command": "\"$CLAUDE_PROJECT_DIR\"/.claude/hooks/drop.sh"}]}
+    ]
+  }
+}`)
+	m, _ := Parse([]byte("hooks: [keep]\n"))
+	out, err := m.RenderSettings(settings)
+	if err != nil {
+		t.Fatalf("RenderSettings() error = %v", err)
+	}
+
+	var got struct {
+		Model string                       `json:"model"`
+		Hooks map[string][]json.RawMessage `json:"hooks"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if got.Model != "opus" {
+		t.Error("non-hook settings should be preserved")
+	}
+	if len(got.Hooks["PreToolUse"]) != 1 || !strings.Contains(string(got.Hooks["PreToolUse"][0]), "keep.sh") {
+		t.Errorf("PreToolUse = %s, want only keep.sh", got.Hooks["PreToolUse"])
+	}
+	if _, ok := got.Hooks["TeammateIdle"]; ok {
+		t.Error("event with no remaining hooks should be dropped")
+	}
+}
+
+func TestRenderSettings_NoHooksDeclared(t *testing.T) {
+	settings := []byte("{\"hooks\": {}}\n")
+	m, _ := Parse([]byte("agents: [planner]\n"))
+	out, err := m.RenderSettings(settings)
+	if err != nil || string(out) != string(settings) {
+		t.Errorf("RenderSettings() should return input unchanged, got %q, %v", out, err)
+	}
+}
+
+func TestHookScriptName(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{`"$CLAUDE_PROJECT_DIR"/.claude/hooks/auto-format.sh`, "auto-format"},
+		{`.claude/hooks/guard.sh --strict`, "guard"},
+		{`npx prettier --write`, ""},
+	}
+	for _, tt := range tests {
+		if got := hookScriptName(tt.command); got != tt.want {
+			t.Errorf("hookScriptName(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestOver(t *testing.T) {
+	base, _ := Parse([]byte("agents: [planner, explorer]\nhooks: [auto-format]\n"))
+	base.Profile = "backend"
+	project, _ := Parse([]byte("agents: [planner]\nmcpServers: [filesystem]\n"))
+
+	m := project.Over(base)
+	if strings.Join(m.Agents, ",") != "planner" {
+		t.Errorf("Agents = %v, want project selection to win", m.Agents)
+	}
+	if strings.Join(m.Hooks, ",") != "auto-format" {
+		t.Errorf("Hooks = %v, want profile selection as fallback", m.Hooks)
+	}
+	if !m.Declares(KindMCPServers) || m.Declares(KindSkills) {
+		t.Error("declared kinds should be the union of both manifests")
+	}
+	if m.Profile != "backend" {
+		t.Errorf("Profile = %q, want backend", m.Profile)
+	}
+
+	var nilManifest *Manifest
+	if nilManifest.Over(base) != base {
+		t.Error("nil.Over(base) should return base")
+	}
+}
+
+func TestParse_Scalars(t *testing.T) {
+	m, err := Parse([]byte("profile: backend\ndescription: \"Team defaults\"\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Profile != "backend" || m.Description != "Team defaults" {
+		t.Errorf("Profile = %q, Description = %q", m.Profile, m.Description)
+	}
+}
@@ -0,0 +1,204 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpregistry"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// AvailableMcpServers lists the MCP server names a manifest may select: the
+// servers in the embedded .mcp.json plus every recipe in the MCP registry.
+func AvailableMcpServers() []string {
+	servers, _ := embeddedMcpServers()
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	for _, r := range mcpRecipes() {
+		if _, ok := servers[r.Key]; !ok {
+			names = append(names, r.Key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// embeddedMcpServers returns the server entries from the embedded .mcp.json.
+func embeddedMcpServers() (map[string]json.RawMessage, error) {
+	data, err := platform.ReadAsset(".mcp.json")
+	if err != nil {
+		return nil, err
+	}
+	var cfg struct {
+		McpServers map[string]json.RawMessage `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing embedded .mcp.json: %w", err)
+	}
+	return cfg.McpServers, nil
+}
+
+// mcpRecipes returns all pre-defined MCP server recipes, or nil if none are embedded.
+func mcpRecipes() []mcpregistry.Recipe {
+	categories, err := mcpregistry.LoadAll(platform.McpConfigFS)
+	if err != nil {
+		return nil
+	}
+	var recipes []mcpregistry.Recipe
+	for _, c := range categories {
+		recipes = append(recipes, c.Recipes...)
+	}
+	return recipes
+}
+
+// recipeServerConfig converts a recipe into an .mcp.json server entry. Env
+// values are written as ${VAR} references so secrets never land in the file.
+func recipeServerConfig(r *mcpregistry.Recipe) map[string]interface{} {
+	entry := map[string]interface{}{}
+	if r.Transport == mcpregistry.TransportHTTP {
+		entry["type"] = "http"
+		entry["url"] = r.URL
+		if len(r.Headers) > 0 {
+			entry["headers"] = r.Headers
+		}
+		return entry
+	}
+	entry["command"] = r.Command
+	entry["args"] = r.Args
+	env := make(map[string]string, len(r.EnvVars))
+	for k := range r.EnvVars {
+		env[k] = "${" + k + "}"
+	}
+	entry["env"] = env
+	return entry
+}
+
+// RenderMcpConfig renders .mcp.json containing only the servers the
+// manifest selects. Servers are taken from the embedded .mcp.json first, then
+// from the MCP recipe registry. It also returns the names of recipe servers
+// that need credentials configured.
+func (m *Manifest) RenderMcpConfig() ([]byte, []string, error) {
+	embedded, err := embeddedMcpServers()
+	if err != nil {
+		return nil, nil, err
+	}
+	recipes := make(map[string]*mcpregistry.Recipe)
+	for _, r := range mcpRecipes() {
+		r := r
+		recipes[r.Key] = &r
+	}
+
+	servers := make(map[string]interface{}, len(m.MCPServers))
+	var needsCreds []string
+	for _, name := range m.MCPServers {
+		if raw, ok := embedded[name]; ok {
+			servers[name] = raw
+			continue
+		}
+		r, ok := recipes[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown MCP server %q", name)
+		}
+		servers[name] = recipeServerConfig(r)
+		if len(r.EnvVars) > 0 || len(r.Headers) > 0 {
+			needsCreds = append(needsCreds, name)
+		}
+	}
+	sort.Strings(needsCreds)
+
+	data, err := json.MarshalIndent(map[string]interface{}{"mcpServers": servers}, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(data, '\n'), needsCreds, nil
+}
+
+// RenderSettings removes hook commands that reference hook scripts the
+// manifest excludes, dropping matcher groups and events left empty. Settings
+// content is returned unchanged when the manifest does not declare hooks.
+func (m *Manifest) RenderSettings(data []byte) ([]byte, error) {
+	if !m.Declares(KindHooks) {
+		return data, nil
+	}
+
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	hooksRaw, ok := settings["hooks"]
+	if !ok {
+		return data, nil
+	}
+
+	var events map[string][]map[string]json.RawMessage
+	if err := json.Unmarshal(hooksRaw, &events); err != nil {
+		return nil, err
+	}
+
+	filtered := make(map[string][]map[string]json.RawMessage, len(events))
+	for event, groups := range events {
+		var keptGroups []map[string]json.RawMessage
+		for _, group := range groups {
+			var hooks []struct {
+				Command string `json:"command"`
+			}
+			var rawHooks []json.RawMessage
+			_ = json.Unmarshal(group["hooks"], &hooks)
+			_ = json.Unmarshal(group["hooks"], &rawHooks)
+
+			var kept []json.RawMessage
+			for i, h := range hooks {
+				if name := hookScriptName(h.Command); name == "" || m.Includes(KindHooks, name) {
+					kept = append(kept, rawHooks[i])
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			keptRaw, err := json.Marshal(kept)
+			if err != nil {
+				return nil, err
+			}
+			group["hooks"] = keptRaw
+			keptGroups = append(keptGroups, group)
+		}
+		if len(keptGroups) > 0 {
+			filtered[event] = keptGroups
+		}
+	}
+
+	if len(filtered) == 0 {
+		delete(settings, "hooks")
+	} else {
+		hooksOut, err := json.Marshal(filtered)
+		if err != nil {
+			return nil, err
+		}
+		settings["hooks"] = hooksOut
+	}
+
+	out, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// hookScriptName extracts the script name (without .sh) from a hook command
+// that runs a file under .claude/hooks/, or "" for any other command.
+func hookScriptName(command string) string {
+	const marker = ".claude/hooks/"
+	idx := strings.Index(command, marker)
+	if idx < 0 {
+		return ""
+	}
+	rest := command[idx+len(marker):]
+	if end := strings.IndexAny(rest, " \"'"); end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.TrimSuffix(rest, ".sh")
+}
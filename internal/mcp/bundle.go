@@ -0,0 +1,417 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/mcpregistry"
+	"github.com/lamchakchan/claude-workspace/internal/mcpsupervisor"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/scan"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+// secretEnvName matches env var and header names whose values are treated as
+// secrets when exporting, whatever the value looks like.
+var secretEnvName = regexp.MustCompile(`(?i)KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|AUTH|PRIVATE|DSN|DATABASE_URL`)
+
+// isSecret reports whether the value of env var or header name must not be
+// exported.
+func isSecret(name, value string) bool {
+	return secretEnvName.MatchString(name) || len(scan.Content("", name+"="+value)) > 0
+}
+
+type exportConfig struct {
+	Output  string
+	Scope   string // "" exports every scope
+	Servers []string
+}
+
+func parseExportArgs(args []string) (*exportConfig, error) {
+	cfg := &exportConfig{}
+	f := cli.New("mcp export", "mcp export [--output <file>] [--scope <scope>] [--servers <a,b>]")
+	f.Help = printMcpExportHelp
+	f.StringVar(&cfg.Output, "--output", "file", "Write the bundle to a file (default: stdout)")
+	f.Alias("-o", "--output")
+	f.StringVar(&cfg.Scope, flagScope, "local|project|user", "Only export servers of this scope")
+	f.Func("--servers", "a,b", "Only export these servers", func(v string) error {
+		cfg.Servers = append(cfg.Servers, splitList(v)...)
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return nil, err
+	}
+	switch cfg.Scope {
+	case "", scopeUser, scopeLocal, scopeProject:
+	default:
+		return nil, fmt.Errorf("unknown scope %q (valid: local, project, user)", cfg.Scope)
+	}
+	return cfg, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Export implements "mcp export": it writes the configured servers as a
+// bundle in the organization registry catalog format, with every secret
+// replaced by a prompt for "mcp import".
+func Export(args []string) error {
+	cfg, err := parseExportArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+
+	bundle, notes, err := buildBundle(home, cwd, cfg)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	// The conversion drops every value it recognizes as secret; this is the
+	// backstop for ones it does not.
+	if findings := scan.Content("bundle", string(data)); len(findings) > 0 {
+		return fmt.Errorf("refusing to export: the bundle still contains a potential secret (%s: %s on line %d)",
+			findings[0].Rule, findings[0].Secret, findings[0].Line)
+	}
+
+	if cfg.Output == "" {
+		_, err := os.Stdout.Write(data)
+		for _, note := range notes {
+			fmt.Fprintf(os.Stderr, "note: %s\n", note)
+		}
+		return err
+	}
+	if err := os.WriteFile(cfg.Output, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", cfg.Output, err)
+	}
+	out := platform.Stdout()
+	platform.PrintSuccess(out, fmt.Sprintf("Exported %d MCP server(s) to %s", len(bundle.Servers), cfg.Output))
+	for _, s := range bundle.Servers {
+		detail := s.Transport
+		if env := s.EnvNames(); len(env) > 0 {
+			detail += ", env: " + strings.Join(env, " ")
+		}
+		fmt.Fprintf(out, "    %s (%s)\n", s.Name, detail)
+	}
+	for _, note := range notes {
+		platform.PrintWarn(out, note)
+	}
+	fmt.Fprintln(out, "\n  Share the file with your team; they add the servers with:")
+	platform.PrintCommand(out, "claude-workspace mcp import "+cfg.Output)
+	return nil
+}
+
+// buildBundle converts the configured servers into a catalog. Servers defined
+// in several scopes are exported once, from the scope Claude Code uses: local,
+// then project, then user.
+func buildBundle(home, projectDir string, cfg *exportConfig) (*mcpregistry.Catalog, []string, error) {
+	type source struct {
+		scope string
+		entry map[string]interface{}
+	}
+	found := map[string]source{}
+	for _, scope := range []string{scopeUser, scopeProject, scopeLocal} {
+		if cfg.Scope != "" && scope != cfg.Scope {
+			continue
+		}
+		store, err := openServerStore(scope, home, projectDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name := range store.servers {
+			if entry := store.lookup(name); entry != nil {
+				found[name] = source{scope, entry}
+			}
+		}
+	}
+
+	names := cfg.Servers
+	if len(names) == 0 {
+		for name := range found {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("no MCP servers are configured to export")
+	}
+	bundle := &mcpregistry.Catalog{}
+	var notes []string
+	for _, name := range names {
+		src, ok := found[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("MCP server '%s' is not configured", name)
+		}
+		s, serverNotes := exportServer(name, src.scope, src.entry)
+		bundle.Servers = append(bundle.Servers, s)
+		notes = append(notes, serverNotes...)
+	}
+	return bundle, notes, nil
+}
+
+// exportServer converts a config entry into a catalog entry without secrets.
+// Secret env vars, including those injected by "secrets exec", become masked
+// prompts; an Authorization header becomes bearer auth. It returns notes on
+// anything that could not be carried over.
+func exportServer(name, scope string, entry map[string]interface{}) (mcpregistry.CatalogServer, []string) {
+	s := mcpregistry.CatalogServer{Name: name, Scope: scope, Env: map[string]mcpregistry.EnvSpec{}}
+	var notes []string
+
+	if isRemoteEntry(entry) {
+		s.URL, _ = entry["url"].(string)
+		s.Transport, _ = entry["type"].(string)
+		if s.Transport == "" {
+			s.Transport = transportHTTP
+		}
+		if _, ok := entry["oauth"]; ok {
+			s.Auth = mcpregistry.AuthOAuth
+		}
+		headers, _ := entry["headers"].(map[string]interface{})
+		for _, key := range sortedKeys(headers) {
+			value, _ := headers[key].(string)
+			switch {
+			case strings.EqualFold(key, "authorization"):
+				s.Auth = mcpregistry.AuthBearer
+			case isSecret(key, value):
+				notes = append(notes, fmt.Sprintf("%s: header %s holds a secret and was left out; add it after importing with 'mcp update %s --header'", name, key, name))
+			default:
+				if s.Headers == nil {
+					s.Headers = map[string]string{}
+				}
+				s.Headers[key] = value
+			}
+		}
+	} else {
+		command, _ := entry["command"].(string)
+		args := stringSlice(entry["args"])
+		if inner, ok := mcpsupervisor.Unwrap(args); ok {
+			command, args = inner[0], inner[1:]
+			notes = append(notes, fmt.Sprintf("%s: supervision is not exported; re-enable it with 'mcp update %s --supervise'", name, name))
+		}
+		if names := secrets.WrappedNames(args); names != nil {
+			for _, secret := range names {
+				s.Env[secret] = mcpregistry.EnvSpec{Secret: true}
+			}
+			for i, arg := range args {
+				if arg == "--" {
+					command, args = args[i+1], args[i+2:]
+					break
+				}
+			}
+		}
+		s.Transport = transportStdio
+		s.Command = command
+		s.Args = args
+	}
+
+	env, _ := entry["env"].(map[string]interface{})
+	for _, key := range sortedKeys(env) {
+		value, _ := env[key].(string)
+		if isSecret(key, value) || strings.Contains(value, "${") {
+			s.Env[key] = mcpregistry.EnvSpec{Secret: isSecret(key, value)}
+			continue
+		}
+		s.Env[key] = mcpregistry.EnvSpec{Default: value}
+	}
+	if len(s.Env) == 0 {
+		s.Env = nil
+	}
+	return s, notes
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type importConfig struct {
+	Location string
+	Scope    string
+	Servers  []string
+}
+
+func parseImportArgs(args []string) (*importConfig, error) {
+	cfg := &importConfig{}
+	f := cli.New("mcp import", "mcp import <bundle.json|url> [--scope <scope>] [--servers <a,b>]")
+	f.Help = printMcpImportHelp
+	f.StringVar(&cfg.Scope, flagScope, "local|project|user", "Add every server with this scope")
+	f.Func("--servers", "a,b", "Only import these servers", func(v string) error {
+		cfg.Servers = append(cfg.Servers, splitList(v)...)
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) == 0 {
+		printMcpImportHelp(platform.Stdout())
+		return nil, fmt.Errorf("bundle file or URL is required")
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return nil, err
+	}
+	cfg.Location = positional[0]
+	switch cfg.Scope {
+	case "", scopeUser, scopeLocal, scopeProject:
+	default:
+		return nil, fmt.Errorf("unknown scope %q (valid: local, project, user)", cfg.Scope)
+	}
+	return cfg, nil
+}
+
+// Import implements "mcp import": it adds each server in a bundle made by
+// "mcp export" (or any registry catalog), prompting for its secrets.
+func Import(args []string) error {
+	cfg, err := parseImportArgs(args)
+	if err != nil {
+		return err
+	}
+	bundle, _, err := mcpregistry.FetchCatalog(cfg.Location)
+	if err != nil {
+		return err
+	}
+	configured, err := DiscoverServers()
+	if err != nil {
+		return err
+	}
+	selected, err := selectImports(bundle, cfg.Servers, configured)
+	if err != nil {
+		return err
+	}
+
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Import MCP Servers")
+	reader := bufio.NewReader(os.Stdin)
+	var skipped []string
+	for _, s := range selected {
+		if s.skip {
+			skipped = append(skipped, s.server.Name)
+			continue
+		}
+		platform.PrintSection(out, s.server.Name)
+		if s.server.Description != "" {
+			fmt.Fprintf(out, "  %s\n", s.server.Description)
+		}
+		if err := importServer(s.server, cfg.Scope, reader); err != nil {
+			return fmt.Errorf("importing %s: %w", s.server.Name, err)
+		}
+	}
+	printImportSummary(out, len(selected)-len(skipped), skipped)
+	return nil
+}
+
+type importItem struct {
+	server *mcpregistry.CatalogServer
+	skip   bool // already configured
+}
+
+// selectImports picks the bundle servers to import, marking those already
+// configured in any scope so they are left alone.
+func selectImports(bundle *mcpregistry.Catalog, names []string, configured []Server) ([]importItem, error) {
+	exists := make(map[string]bool, len(configured))
+	for _, s := range configured {
+		exists[s.Name] = true
+	}
+	if len(names) == 0 {
+		names = bundle.Names()
+	}
+	items := make([]importItem, 0, len(names))
+	for _, name := range names {
+		s := bundle.Find(name)
+		if s == nil {
+			return nil, fmt.Errorf("server %q is not in the bundle (available: %s)", name, strings.Join(bundle.Names(), ", "))
+		}
+		items = append(items, importItem{server: s, skip: exists[name]})
+	}
+	return items, nil
+}
+
+func importServer(s *mcpregistry.CatalogServer, scope string, reader *bufio.Reader) error {
+	cfg := catalogAddConfig(s, scope)
+	if err := promptRegistryEnv(cfg, s, reader); err != nil {
+		return err
+	}
+	if err := cfg.promptCredentials(cfg.Name); err != nil {
+		return err
+	}
+	return addServer(cfg)
+}
+
+func printImportSummary(w io.Writer, imported int, skipped []string) {
+	fmt.Fprintln(w)
+	for _, name := range skipped {
+		platform.PrintInfo(w, fmt.Sprintf("%s is already configured; skipped (use 'mcp remove %s' first to replace it)", name, name))
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Imported %d MCP server(s).", imported))
+	fmt.Fprintln(w, "  Run '/mcp' in Claude Code to verify the connections.")
+}
+
+func printMcpExportHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp export [options]
+
+Write the configured MCP servers to a bundle a teammate can import. Secrets
+are never exported: env vars and headers that hold them become prompts.
+
+Options:
+  --output, -o <file>           Write the bundle to a file (default: stdout)
+  --scope local|project|user    Only export servers from this scope
+                                (default: every scope for the current directory)
+  --servers <a,b>               Only export these servers
+
+Examples:
+
+  claude-workspace mcp export --output mcp-bundle.json
+  claude-workspace mcp export --scope user --servers github,postgres -o team.json
+`)
+}
+
+func printMcpImportHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp import <bundle.json|url> [options]
+
+Add the servers in a bundle made by 'mcp export', prompting (masked) for each
+required secret. Servers that are already configured are skipped.
+
+Options:
+  --scope local|project|user    Where to add the servers (default: the scope
+                                each server was exported from)
+  --servers <a,b>               Only import these servers
+
+Examples:
+
+  claude-workspace mcp import mcp-bundle.json
+  claude-workspace mcp import https://platform.example.com/mcp-bundle.json --scope user
+`)
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpregistry"
+)
+
+func TestExportServer(t *testing.T) {
+	exe := "/usr/local/bin/claude-workspace"
+	tests := []struct {
+		name      string
+		entry     map[string]interface{}
+		want      mcpregistry.CatalogServer
+		wantNotes int
+	}{
+		{
+			name: "stdio env",
+			entry: map[string]interface{}{
+				"command": "npx",
+				"args":    []interface{}{"-y", "pg-mcp"},
+				"env": map[string]interface{}{
+					"PGHOST":      "db.internal",
+					"PGPASSWORD":  "hunter2",
+					"PGDATABASE":  "${PGDATABASE}",
+					"OPENAI_HINT": "sk-proj-abcdefghijklmnopqrstuvwxyz0123456789ABCD",
+				},
+			},
+			want: mcpregistry.CatalogServer{Name: "db", Scope: scopeUser, Transport: transportStdio, Command: "npx", Args: []string{"-y", "pg-mcp"},
+				Env: map[string]mcpregistry.EnvSpec{
+					"PGHOST":      {Default: "db.internal"},
+					"PGPASSWORD":  {Secret: true},
+					"PGDATABASE":  {},
+					"OPENAI_HINT": {Secret: true},
+				}},
+		},
+		{
+			name: "supervised secrets exec",
+			entry: map[string]interface{}{
+				"command": exe,
+				"args": []interface{}{"mcp", "serve", "db", "--",
+					exe, "secrets", "exec", "DB_TOKEN", "--", "npx", "pkg"},
+			},
+			want: mcpregistry.CatalogServer{Name: "db", Scope: scopeUser, Transport: transportStdio, Command: "npx", Args: []string{"pkg"},
+				Env: map[string]mcpregistry.EnvSpec{"DB_TOKEN": {Secret: true}}},
+			wantNotes: 1,
+		},
+		{
+			name: "remote headers",
+			entry: map[string]interface{}{
+				"type": "http",
+				"url":  "https://mcp.example.com/mcp",
+				"headers": map[string]interface{}{
+					"Authorization": "Bearer abc",
+					"X-Api-Key":     "abc",
+					"X-Team":        "platform",
+				},
+			},
+			want: mcpregistry.CatalogServer{Name: "db", Scope: scopeUser, Transport: "http", URL: "https://mcp.example.com/mcp",
+				Headers: map[string]string{"X-Team": "platform"}, Auth: mcpregistry.AuthBearer},
+			wantNotes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes := exportServer("db", scopeUser, tt.entry)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exportServer =\n %+v\nwant\n %+v", got, tt.want)
+			}
+			if len(notes) != tt.wantNotes {
+				t.Errorf("notes = %q, want %d", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestBuildBundle(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(home, ".claude.json"), `{
+		"mcpServers": {
+			"postgres": {"command": "npx", "args": ["-y", "user-postgres"]},
+			"sentry": {"type": "http", "url": "https://mcp.sentry.dev/mcp"}
+		},
+		"projects": {"`+project+`": {"mcpServers": {
+			"postgres": {"command": "npx", "args": ["-y", "local-postgres"]}
+		}}}
+	}`)
+
+	bundle, _, err := buildBundle(home, project, &exportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(bundle.Names(), ","); got != "postgres,sentry" {
+		t.Errorf("names = %s", got)
+	}
+	if pg := bundle.Find("postgres"); pg.Scope != scopeLocal || pg.Args[1] != "local-postgres" {
+		t.Errorf("postgres = %+v, want the local entry", pg)
+	}
+
+	bundle, _, err = buildBundle(home, project, &exportConfig{Scope: scopeUser, Servers: []string{"postgres"}})
+	if err != nil || len(bundle.Servers) != 1 || bundle.Servers[0].Args[1] != "user-postgres" {
+		t.Errorf("--scope user --servers postgres = %+v, %v", bundle, err)
+	}
+	if _, _, err := buildBundle(home, project, &exportConfig{Servers: []string{"nope"}}); err == nil {
+		t.Error("unknown server: expected error")
+	}
+
+	// The bundle must round-trip through the catalog parser used by import.
+	bundle, _, _ = buildBundle(home, project, &exportConfig{})
+	data, _ := json.Marshal(bundle)
+	if _, err := mcpregistry.ParseCatalog(data); err != nil {
+		t.Errorf("ParseCatalog(exported bundle): %v", err)
+	}
+}
+
+func TestSelectImports(t *testing.T) {
+	bundle := &mcpregistry.Catalog{Servers: []mcpregistry.CatalogServer{
+		{Name: "github", Transport: transportStdio, Command: "github-mcp"},
+		{Name: "postgres", Transport: transportStdio, Command: "pg-mcp"},
+	}}
+	items, err := selectImports(bundle, nil, []Server{{Name: "github"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || !items[0].skip || items[1].skip {
+		t.Errorf("items = %+v, want github skipped", items)
+	}
+	if _, err := selectImports(bundle, []string{"nope"}, nil); err == nil {
+		t.Error("unknown server: expected error")
+	}
+}
+
+func TestParseExportImportArgs(t *testing.T) {
+	cfg, err := parseExportArgs([]string{"-o", "team.json", "--scope", "user", "--servers", "a, b"})
+	if err != nil || cfg.Output != "team.json" || cfg.Scope != scopeUser || strings.Join(cfg.Servers, ",") != "a,b" {
+		t.Errorf("parseExportArgs = %+v, %v", cfg, err)
+	}
+	for _, args := range [][]string{{"--output"}, {"--scope", "managed"}, {"extra"}} {
+		if _, err := parseExportArgs(args); err == nil {
+			t.Errorf("parseExportArgs(%q) should fail", args)
+		}
+	}
+
+	imp, err := parseImportArgs([]string{"bundle.json", "--scope", "project"})
+	if err != nil || imp.Location != "bundle.json" || imp.Scope != scopeProject {
+		t.Errorf("parseImportArgs = %+v, %v", imp, err)
+	}
+	for _, args := range [][]string{nil, {"a.json", "b.json"}, {"a.json", "--servers"}} {
+		if _, err := parseImportArgs(args); err == nil {
+			t.Errorf("parseImportArgs(%q) should fail", args)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 const (
 	scopeUser    = "user"
+	scopeLocal   = "local"
 	scopeProject = "project"
 	scopeManaged = "managed"
 )
@@ -0,0 +1,323 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/mcpgateway"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const (
+	defaultGatewayListen   = "127.0.0.1:8900"
+	defaultGatewayTokenEnv = "MCP_GATEWAY_TOKENS"
+)
+
+type gatewayConfig struct {
+	Listen    string
+	Servers   []string
+	Allow     []string
+	TokenEnv  string
+	NoAuth    bool
+	AuditPath string
+	TLSCert   string
+	TLSKey    string
+}
+
+func parseGatewayArgs(args []string) (*gatewayConfig, error) {
+	cfg := &gatewayConfig{Listen: defaultGatewayListen, TokenEnv: defaultGatewayTokenEnv}
+	f := cli.New("mcp gateway", "mcp gateway [options]")
+	f.Help = printMcpGatewayHelp
+	f.StringVar(&cfg.Listen, "--listen", "host:port", "Address to listen on")
+	f.Func("--servers", "a,b", "Servers to expose (default: all stdio servers)", func(v string) error {
+		cfg.Servers = append(cfg.Servers, splitList(v)...)
+		return nil
+	})
+	f.StringsVar(&cfg.Allow, "--allow", "pattern", "Only expose matching <server>__<tool> tools")
+	f.StringVar(&cfg.TokenEnv, "--token-env", "VAR", "Env var with the accepted bearer tokens")
+	f.BoolVar(&cfg.NoAuth, "--no-auth", "Accept requests without a token (loopback only)")
+	f.StringVar(&cfg.AuditPath, "--audit-log", "file", "Append a JSON line per tool call")
+	f.StringVar(&cfg.TLSCert, "--tls-cert", "file", "Serve HTTPS with this certificate")
+	f.StringVar(&cfg.TLSKey, "--tls-key", "file", "Key of --tls-cert")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return nil, err
+	}
+	if cfg.TokenEnv == "" {
+		return nil, fmt.Errorf("--token-env requires a value")
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.Listen); err != nil {
+		return nil, fmt.Errorf("--listen expects host:port or :port, got %q", cfg.Listen)
+	}
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	if cfg.NoAuth && !isLoopback(cfg.Listen) {
+		return nil, fmt.Errorf("--no-auth is only allowed when listening on a loopback address, not %s", cfg.Listen)
+	}
+	return cfg, nil
+}
+
+// isLoopback reports whether addr only accepts connections from this machine.
+func isLoopback(addr string) bool {
+	host, _, _ := net.SplitHostPort(addr)
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// gatewayTokens reads the accepted bearer tokens from a comma-separated env
+// var. Several tokens let a team rotate one without cutting off the others.
+func gatewayTokens(envVar string) []string {
+	var tokens []string
+	for _, t := range strings.Split(os.Getenv(envVar), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// gatewayServers returns the stdio servers configured for projectDir, with
+// local entries taking precedence over project and then user ones, as in
+// Claude Code. Remote servers are returned separately in skipped: clients can
+// reach them directly. With names, only those servers are returned.
+func gatewayServers(home, projectDir string, names []string) (servers []mcpgateway.Server, skipped []string, err error) {
+	entries := map[string]map[string]interface{}{}
+	for _, scope := range []string{scopeUser, scopeProject, scopeLocal} {
+		store, err := openServerStore(scope, home, projectDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name := range store.servers {
+			if entry := store.lookup(name); entry != nil {
+				entries[name] = entry
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		entry := entries[name]
+		switch {
+		case entry == nil:
+			return nil, nil, fmt.Errorf("MCP server '%s' is not configured for %s", name, projectDir)
+		case isRemoteEntry(entry):
+			skipped = append(skipped, name)
+			continue
+		}
+		command, _ := entry["command"].(string)
+		s := mcpgateway.Server{Name: name, Command: []string{expandVars(command)}, Env: map[string]string{}}
+		for _, arg := range stringSlice(entry["args"]) {
+			s.Command = append(s.Command, expandVars(arg))
+		}
+		env, _ := entry["env"].(map[string]interface{})
+		for k, v := range env {
+			value, _ := v.(string)
+			s.Env[k] = expandVars(value)
+		}
+		servers = append(servers, s)
+	}
+	return servers, skipped, nil
+}
+
+// expandVars expands ${VAR} and ${VAR:-default} the way Claude Code does in
+// MCP server configs.
+func expandVars(s string) string {
+	return os.Expand(s, func(ref string) string {
+		name, def, hasDefault := strings.Cut(ref, ":-")
+		if value, ok := os.LookupEnv(name); ok && (value != "" || !hasDefault) {
+			return value
+		}
+		return def
+	})
+}
+
+// Gateway implements "mcp gateway": it serves the configured stdio servers
+// through one authenticated HTTP endpoint until interrupted.
+func Gateway(version string, args []string) error {
+	cfg, err := parseGatewayArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	servers, skipped, err := gatewayServers(home, cwd, cfg.Servers)
+	if err != nil {
+		return err
+	}
+
+	opts := mcpgateway.Options{
+		Servers:   servers,
+		Allow:     cfg.Allow,
+		NoAuth:    cfg.NoAuth,
+		AuditPath: cfg.AuditPath,
+		Version:   version,
+	}
+	if !cfg.NoAuth {
+		if opts.Tokens = gatewayTokens(cfg.TokenEnv); len(opts.Tokens) == 0 {
+			return fmt.Errorf("no bearer tokens: set %s to a comma-separated list of tokens (or pass --token-env VAR)", cfg.TokenEnv)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", cfg.Listen, err)
+	}
+	g, err := mcpgateway.New(ctx, opts)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	defer g.Close()
+
+	printGatewayStatus(cfg, g, len(opts.Tokens), skipped, listener.Addr().String())
+
+	srv := &http.Server{Handler: g, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		if cfg.TLSCert != "" {
+			serveErr <- srv.ServeTLS(listener, cfg.TLSCert, cfg.TLSKey)
+		} else {
+			serveErr <- srv.Serve(listener)
+		}
+	}()
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("serving MCP gateway: %w", err)
+		}
+	case <-ctx.Done():
+		fmt.Fprintln(platform.Stdout(), "\nStopping MCP gateway...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}
+	return nil
+}
+
+func printGatewayStatus(cfg *gatewayConfig, g *mcpgateway.Gateway, tokens int, skipped []string, addr string) {
+	out := platform.Stdout()
+	scheme := "http"
+	if cfg.TLSCert != "" {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, addr, mcpgateway.Endpoint)
+	connectURL := url
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			if name, err := os.Hostname(); err == nil {
+				connectURL = fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(name, port), mcpgateway.Endpoint)
+			}
+		}
+	}
+
+	platform.PrintBanner(out, "MCP Gateway")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  Listening:  %s\n", url)
+	fmt.Fprintf(out, "  Servers:    %s\n", strings.Join(g.Servers(), ", "))
+	allowed := "all tools"
+	if len(cfg.Allow) > 0 {
+		allowed = strings.Join(cfg.Allow, ", ")
+	}
+	fmt.Fprintf(out, "  Allowed:    %s\n", allowed)
+	if cfg.NoAuth {
+		fmt.Fprintln(out, "  Auth:       none (loopback only)")
+	} else {
+		fmt.Fprintf(out, "  Auth:       bearer (%d token(s) from $%s)\n", tokens, cfg.TokenEnv)
+	}
+	fmt.Fprintf(out, "  Audit log:  %s\n", g.AuditPath())
+	fmt.Fprintln(out)
+
+	for _, name := range skipped {
+		platform.PrintInfo(out, fmt.Sprintf("%s is a remote server; clients can connect to it directly", name))
+	}
+	failed := make([]string, 0, len(g.Failed))
+	for name := range g.Failed {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		platform.PrintWarn(out, fmt.Sprintf("%s did not start: %v", name, g.Failed[name]))
+	}
+	if cfg.TLSCert == "" && !isLoopback(cfg.Listen) {
+		platform.PrintWarn(out, "Serving plain HTTP on a network address; put a TLS proxy in front or pass --tls-cert and --tls-key")
+	}
+
+	fmt.Fprintln(out, "\n  Connect Claude Code with:")
+	if cfg.NoAuth {
+		platform.PrintCommand(out, fmt.Sprintf("claude-workspace mcp remote %s --name gateway", connectURL))
+	} else {
+		platform.PrintCommand(out, fmt.Sprintf("claude-workspace mcp remote %s --name gateway --bearer", connectURL))
+	}
+	fmt.Fprintln(out, "\nPress Ctrl-C to stop.")
+	platform.FlushOutput()
+}
+
+func printMcpGatewayHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp gateway [options]
+
+Serve the locally configured stdio MCP servers through one HTTP MCP endpoint.
+Tools are exposed as <server>__<tool>. Every request needs a bearer token and
+is recorded in an audit log.
+
+Options:
+  --listen <host:port>          Address to listen on (default: 127.0.0.1:8900;
+                                use :8900 to accept connections from other machines)
+  --servers <a,b>               Servers to expose (default: every stdio server
+                                configured for the current directory)
+  --allow <pattern>             Only expose tools matching <server>__<tool>
+                                globs, e.g. 'github__get_*' (repeatable)
+  --token-env <VAR>             Env var holding the accepted bearer tokens,
+                                comma-separated (default: MCP_GATEWAY_TOKENS)
+  --no-auth                     Accept requests without a token (loopback only)
+  --audit-log <path>            Audit log (default:
+                                ~/.claude-workspace/logs/mcp-gateway/audit.jsonl)
+  --tls-cert <file>             Serve HTTPS with this certificate (with --tls-key)
+  --tls-key <file>              Private key for --tls-cert
+
+Examples:
+
+  # Serve two servers to the team, read-only GitHub tools only
+  MCP_GATEWAY_TOKENS=$(openssl rand -hex 32) claude-workspace mcp gateway \
+    --listen :8900 --servers github,postgres \
+    --allow 'github__get_*' --allow 'github__search_*' --allow 'postgres__*'
+
+  # Keep the token in the credential store
+  claude-workspace secrets exec MCP_GATEWAY_TOKENS -- claude-workspace mcp gateway --listen :8900
+
+  # Connect a Claude Code session (prompts for the token)
+  claude-workspace mcp remote http://gateway.internal:8900/mcp --name gateway --bearer
+`)
+}
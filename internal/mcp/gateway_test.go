@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGatewayArgs(t *testing.T) {
+	cfg, err := parseGatewayArgs([]string{"--listen", ":9000", "--servers", "github, postgres", "--allow", "github__get_*", "--allow", "postgres__*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Listen != ":9000" || cfg.TokenEnv != defaultGatewayTokenEnv {
+		t.Errorf("Listen = %q, TokenEnv = %q", cfg.Listen, cfg.TokenEnv)
+	}
+	if !reflect.DeepEqual(cfg.Servers, []string{"github", "postgres"}) || !reflect.DeepEqual(cfg.Allow, []string{"github__get_*", "postgres__*"}) {
+		t.Errorf("Servers = %q, Allow = %q", cfg.Servers, cfg.Allow)
+	}
+
+	if cfg, err := parseGatewayArgs(nil); err != nil || cfg.Listen != defaultGatewayListen {
+		t.Errorf("defaults = %+v, %v", cfg, err)
+	}
+	if _, err := parseGatewayArgs([]string{"--no-auth"}); err != nil {
+		t.Errorf("--no-auth on loopback: unexpected error %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--listen"},
+		{"--listen", "8900"},
+		{"--no-auth", "--listen", ":8900"},
+		{"--tls-cert", "cert.pem"},
+		{"--bogus"},
+	} {
+		if _, err := parseGatewayArgs(args); err == nil {
+			t.Errorf("parseGatewayArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestGatewayServers(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("DB_HOST", "db.internal")
+	writeTestFile(t, filepath.Join(home, ".claude.json"), `{
+		"mcpServers": {
+			"postgres": {"command": "npx", "args": ["-y", "user-postgres"]},
+			"sentry": {"type": "http", "url": "https://mcp.sentry.dev/mcp"}
+		},
+		"projects": {"`+project+`": {"mcpServers": {
+			"postgres": {"command": "npx", "args": ["-y", "local-postgres", "--host", "${DB_HOST}"], "env": {"PGPORT": "${PGPORT:-5432}"}}
+		}}}
+	}`)
+	writeTestFile(t, filepath.Join(project, ".mcp.json"), `{"mcpServers": {"github": {"command": "github-mcp"}}}`)
+
+	servers, skipped, err := gatewayServers(home, project, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range servers {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "github,postgres" || strings.Join(skipped, ",") != "sentry" {
+		t.Errorf("servers = %v, skipped = %v", names, skipped)
+	}
+	pg := servers[1]
+	if want := []string{"npx", "-y", "local-postgres", "--host", "db.internal"}; !reflect.DeepEqual(pg.Command, want) {
+		t.Errorf("postgres command = %q, want the local entry %q", pg.Command, want)
+	}
+	if pg.Env["PGPORT"] != "5432" {
+		t.Errorf("PGPORT = %q, want the default 5432", pg.Env["PGPORT"])
+	}
+
+	if servers, _, err := gatewayServers(home, project, []string{"github"}); err != nil || len(servers) != 1 {
+		t.Errorf("--servers github = %+v, %v", servers, err)
+	}
+	if _, _, err := gatewayServers(home, project, []string{"nope"}); err == nil {
+		t.Error("unknown server: expected error")
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8900": true,
+		"localhost:8900": true,
+		"[::1]:8900":     true,
+		":8900":          false,
+		"0.0.0.0:8900":   false,
+		"10.0.0.5:8900":  false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
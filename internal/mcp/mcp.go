@@ -11,10 +11,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"syscall"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/mcpsupervisor"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
-	"golang.org/x/term"
 )
 
 const (
@@ -22,6 +22,9 @@ const (
 	flagClientSec = "--client-secret"
 	flagScope     = "--scope"
 
+	flagFromRegistry = "--from-registry"
+	flagSupervise    = "--supervise"
+
 	transportStdio = "stdio"
 	transportHTTP  = "http"
 	transportSSE   = "sse"
@@ -35,43 +38,40 @@ type authOpts struct {
 	ClientID           string
 	PromptClientSecret bool
 	ClientSecret       string
+
+	// Device, NoBrowser, and CallbackPort control how --oauth signs in.
+	Device       bool
+	NoBrowser    bool
+	CallbackPort string
 }
 
-// parseFlag parses an auth-related CLI flag. Returns true if consumed.
-func (a *authOpts) parseFlag(args []string, i *int) bool {
-	switch args[*i] {
-	case flagHeader:
-		(*i)++
-		if *i < len(args) {
-			a.Headers = append(a.Headers, args[*i])
-		}
-		return true
-	case "--bearer":
-		a.PromptBearer = true
-		return true
-	case "--oauth":
+// addFlags declares the authentication flags on f. --device and --no-browser
+// imply --oauth; implyOAuth applies that after parsing.
+func (a *authOpts) addFlags(f *cli.FlagSet) {
+	f.StringsVar(&a.Headers, flagHeader, "'Key: Value'", "Add an HTTP header")
+	f.BoolVar(&a.PromptBearer, "--bearer", "Prompt for a Bearer token (masked input)")
+	f.BoolVar(&a.UseOAuth, "--oauth", "Sign in with OAuth now")
+	f.BoolVar(&a.Device, "--device", "Sign in with a device code instead (headless machines)")
+	f.BoolVar(&a.NoBrowser, "--no-browser", "Print the sign-in URL instead of opening a browser")
+	f.StringVar(&a.CallbackPort, "--callback-port", "port", "Fixed localhost port for the OAuth redirect")
+	f.StringVar(&a.ClientID, "--client-id", "id", "OAuth client ID (for pre-registered apps)")
+	f.BoolVar(&a.PromptClientSecret, flagClientSec, "Prompt for the OAuth client secret (masked input)")
+}
+
+func (a *authOpts) implyOAuth() {
+	if a.Device || a.NoBrowser {
 		a.UseOAuth = true
-		return true
-	case "--client-id":
-		(*i)++
-		if *i < len(args) {
-			a.ClientID = args[*i]
-		}
-		return true
-	case flagClientSec:
-		a.PromptClientSecret = true
-		return true
 	}
-	return false
 }
 
 // promptCredentials prompts for bearer token and/or client secret if requested.
 func (a *authOpts) promptCredentials(serverName string) error {
+	out := platform.Stdout()
 	if a.PromptBearer {
-		fmt.Printf("\nBearer token required for '%s'.\n", serverName)
-		fmt.Println("Stored securely in your Claude config.")
-		fmt.Println()
-		token, err := promptSecret("Enter Bearer token: ")
+		fmt.Fprintf(out, "\nBearer token required for '%s'.\n", serverName)
+		fmt.Fprintln(out, "Stored securely in your Claude config.")
+		fmt.Fprintln(out)
+		token, err := platform.PromptSecret("Enter Bearer token: ")
 		if err != nil {
 			return err
 		}
@@ -81,10 +81,10 @@ func (a *authOpts) promptCredentials(serverName string) error {
 		a.Headers = append(a.Headers, "Authorization: Bearer "+token)
 	}
 	if a.PromptClientSecret {
-		fmt.Printf("\nOAuth client secret required for '%s'.\n", serverName)
-		fmt.Println("Stored securely in your Claude config.")
-		fmt.Println()
-		secret, err := promptSecret("Enter OAuth client secret: ")
+		fmt.Fprintf(out, "\nOAuth client secret required for '%s'.\n", serverName)
+		fmt.Fprintln(out, "Stored securely in your Claude config.")
+		fmt.Fprintln(out)
+		secret, err := platform.PromptSecret("Enter OAuth client secret: ")
 		if err != nil {
 			return err
 		}
@@ -105,6 +105,23 @@ type addConfig struct {
 	CommandArgs  []string
 	McpURL       string
 	APIKeyEnvVar string
+
+	// SecretEnv holds prompted secrets. Where possible they are saved to the
+	// OS credential store instead of being registered as plain env vars.
+	SecretEnv     map[string]string
+	NoSecretStore bool
+
+	// Supervise launches the server through "mcp serve", which restarts it
+	// when it crashes and logs its stderr.
+	Supervise bool
+}
+
+// addSecret records a prompted secret value for env var name.
+func (cfg *addConfig) addSecret(name, value string) {
+	if cfg.SecretEnv == nil {
+		cfg.SecretEnv = map[string]string{}
+	}
+	cfg.SecretEnv[name] = value
 }
 
 type remoteConfig struct {
@@ -115,75 +132,43 @@ type remoteConfig struct {
 	Transport string
 }
 
-func promptSecret(prompt string) (string, error) {
-	platform.PrintPrompt(os.Stdout, prompt)
-
-	fd := int(syscall.Stdin)
-	oldState, err := term.MakeRaw(fd)
-	if err != nil {
-		// Fall back to hidden input if raw mode is unavailable.
-		password, err := term.ReadPassword(fd)
-		fmt.Println()
-		if err != nil {
-			return "", fmt.Errorf("reading secret: %w", err)
-		}
-		return strings.TrimSpace(string(password)), nil
-	}
-	defer func() { _ = term.Restore(fd, oldState) }()
-
-	var buf []byte
-	b := make([]byte, 1)
-	for {
-		_, err := os.Stdin.Read(b)
-		if err != nil {
-			fmt.Println()
-			return "", fmt.Errorf("reading secret: %w", err)
-		}
-		switch b[0] {
-		case '\r', '\n': // Enter
-			fmt.Println()
-			return strings.TrimSpace(string(buf)), nil
-		case '\x03': // Ctrl+C
-			fmt.Println()
-			return "", fmt.Errorf("interrupted")
-		case '\x04': // Ctrl+D / EOF
-			fmt.Println()
-			return strings.TrimSpace(string(buf)), nil
-		case '\x7f', '\x08': // Backspace / Delete
-			if len(buf) > 0 {
-				buf = buf[:len(buf)-1]
-				fmt.Print("\b \b")
-			}
-		default:
-			if b[0] >= 0x20 && b[0] < 0x7f { // printable ASCII
-				buf = append(buf, b[0])
-				fmt.Print("*")
-			}
-		}
-	}
-}
-
 func parseAddArgs(args []string) (*addConfig, error) {
-	if len(args) < 1 {
-		printMcpAddHelp()
-		return nil, fmt.Errorf("server name is required")
-	}
-
 	cfg := &addConfig{
-		Name:    args[0],
 		Scope:   "local",
 		EnvVars: map[string]string{},
 	}
-
-	i := 1
-	for i < len(args) {
-		if args[i] == "--" {
-			cfg.CommandArgs = args[i+1:]
-			break
-		}
-		parseAddFlag(args, &i, cfg)
-		i++
+	f := cli.New("mcp add", "mcp add <name> [options] [-- <command> [args...]]")
+	f.Help = printMcpAddHelp
+	f.StringVar(&cfg.Scope, flagScope, "local|project|user", "Where to save config")
+	f.StringVar(&cfg.Transport, "--transport", "stdio|http|sse", "Transport type (default: auto-detected)")
+	f.Func("--env", "KEY=VALUE", "Set an environment variable (repeatable)", envFlag(cfg.EnvVars))
+	f.StringVar(&cfg.APIKeyEnvVar, "--api-key", "ENV_VAR_NAME", "Prompt for an API key, stored as this env var")
+	f.BoolVar(&cfg.NoSecretStore, "--no-secret-store", "Keep --api-key values in ~/.claude.json")
+	f.BoolVar(&cfg.Supervise, flagSupervise, "Launch through 'mcp serve'")
+	cfg.addFlags(f)
+	// The server's command may follow its name without "--": it starts at
+	// the first argument after the name that is not a URL.
+	f.StopAt = func(positional []string, arg string) bool {
+		return len(positional) > 0 && !isURL(arg)
+	}
+
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) == 0 {
+		printMcpAddHelp(platform.Stdout())
+		return nil, fmt.Errorf("server name is required")
+	}
+	if err := f.CheckArgs(positional, 1, 2); err != nil {
+		return nil, err
 	}
+	cfg.Name = positional[0]
+	if len(positional) == 2 {
+		cfg.McpURL = positional[1]
+	}
+	cfg.CommandArgs = f.Rest()
+	cfg.implyOAuth()
 
 	if cfg.Transport == "" {
 		if cfg.McpURL != "" {
@@ -192,81 +177,65 @@ func parseAddArgs(args []string) (*addConfig, error) {
 			cfg.Transport = transportStdio
 		}
 	}
+	if cfg.Supervise {
+		if err := checkSupervise(cfg.Name, cfg.Scope, cfg.Transport == transportStdio); err != nil {
+			return nil, err
+		}
+	}
 
 	return cfg, nil
 }
 
-func parseAddFlag(args []string, i *int, cfg *addConfig) {
-	switch args[*i] {
-	case flagScope:
-		(*i)++
-		if *i < len(args) {
-			cfg.Scope = args[*i]
-		}
-	case "--transport":
-		(*i)++
-		if *i < len(args) {
-			cfg.Transport = args[*i]
-		}
-	case "--env":
-		(*i)++
-		if *i < len(args) {
-			envPair := args[*i]
-			if eqIdx := strings.Index(envPair, "="); eqIdx > 0 {
-				cfg.EnvVars[envPair[:eqIdx]] = envPair[eqIdx+1:]
-			}
-		}
-	case "--api-key":
-		(*i)++
-		if *i < len(args) {
-			cfg.APIKeyEnvVar = args[*i]
-		}
-	default:
-		if !cfg.parseFlag(args, i) {
-			if strings.HasPrefix(args[*i], "http://") || strings.HasPrefix(args[*i], "https://") {
-				cfg.McpURL = args[*i]
-			} else {
-				cfg.CommandArgs = args[*i:]
-				*i = len(args) - 1
-			}
+func isURL(arg string) bool {
+	return strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://")
+}
+
+// envFlag returns the handler of an --env KEY=VALUE flag that records each
+// variable in env.
+func envFlag(env map[string]string) func(string) error {
+	return func(v string) error {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("--env expects KEY=VALUE, got %q", v)
 		}
+		env[key] = value
+		return nil
 	}
 }
 
 func parseRemoteArgs(mcpURL string, extraArgs []string) (*remoteConfig, error) {
-	if mcpURL == "" {
-		printMcpRemoteHelp()
-		return nil, fmt.Errorf("URL is required")
-	}
-
 	cfg := &remoteConfig{
-		McpURL: mcpURL,
-		Scope:  "user",
+		Scope: "user",
 	}
+	f := cli.New("mcp remote", "mcp remote <url> [options]")
+	f.Help = printMcpRemoteHelp
+	f.StringVar(&cfg.Name, "--name", "name", "Server name (default: derived from URL)")
+	f.StringVar(&cfg.Scope, flagScope, "local|project|user", "Where to save")
+	cfg.addFlags(f)
 
-	for i := 0; i < len(extraArgs); i++ {
-		switch extraArgs[i] {
-		case "--name":
-			i++
-			if i < len(extraArgs) {
-				cfg.Name = extraArgs[i]
-			}
-		case flagScope:
-			i++
-			if i < len(extraArgs) {
-				cfg.Scope = extraArgs[i]
-			}
-		default:
-			cfg.parseFlag(extraArgs, &i)
-		}
+	if mcpURL != "" {
+		extraArgs = append([]string{mcpURL}, extraArgs...)
 	}
+	positional, err := f.Parse(extraArgs)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) == 0 {
+		printMcpRemoteHelp(platform.Stdout())
+		return nil, fmt.Errorf("URL is required")
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return nil, err
+	}
+	cfg.McpURL = positional[0]
+	cfg.implyOAuth()
 
 	if cfg.Name == "" {
-		cfg.Name = deriveServerName(mcpURL)
+		cfg.Name = deriveServerName(cfg.McpURL)
 	}
 
 	cfg.Transport = transportHTTP
-	if strings.HasSuffix(mcpURL, "/sse") {
+	if strings.HasSuffix(cfg.McpURL, "/sse") {
 		cfg.Transport = transportSSE
 	}
 
@@ -351,64 +320,51 @@ func buildRemoteClaudeArgs(cfg *remoteConfig) []string {
 	return claudeArgs
 }
 
-func maskSensitiveArgs(args []string) []string {
-	safeArgs := make([]string, len(args))
-	for idx, arg := range args {
-		if idx > 0 {
-			prev := args[idx-1]
-			if prev == "-e" && strings.Contains(arg, "=") {
-				eqIdx := strings.Index(arg, "=")
-				safeArgs[idx] = arg[:eqIdx] + "=****"
-				continue
-			}
-			if prev == flagHeader && strings.Contains(strings.ToLower(arg), "bearer") {
-				safeArgs[idx] = "Authorization: Bearer ****"
-				continue
-			}
-			if prev == flagClientSec {
-				safeArgs[idx] = "****"
-				continue
-			}
-		}
-		safeArgs[idx] = arg
-	}
-	return safeArgs
-}
-
 func promptAPIKey(cfg *addConfig) error {
 	if cfg.APIKeyEnvVar == "" {
 		return nil
 	}
-	fmt.Printf("\nAPI key required for '%s' server.\n", cfg.Name)
-	fmt.Printf("The key will be stored as env var: %s\n", cfg.APIKeyEnvVar)
-	fmt.Println("Stored in your Claude config (~/.claude.json), NOT in project files.")
-	fmt.Println()
-
-	keyValue, err := promptSecret(fmt.Sprintf("Enter %s: ", cfg.APIKeyEnvVar))
+	keyValue, err := promptAPIKeyValue(cfg.Name, cfg.APIKeyEnvVar)
 	if err != nil {
 		return err
 	}
+	cfg.addSecret(cfg.APIKeyEnvVar, keyValue)
+	return nil
+}
+
+// promptAPIKeyValue prompts for the value of an API key env var with masked input.
+func promptAPIKeyValue(serverName, envVar string) (string, error) {
+	out := platform.Stdout()
+	fmt.Fprintf(out, "\nAPI key required for '%s' server.\n", serverName)
+	fmt.Fprintf(out, "The key will be stored as env var: %s\n", envVar)
+	fmt.Fprintln(out, "Stored in your OS credential store when possible, NOT in project files.")
+	fmt.Fprintln(out)
+
+	keyValue, err := platform.PromptSecret(fmt.Sprintf("Enter %s: ", envVar))
+	if err != nil {
+		return "", err
+	}
 	if keyValue == "" {
-		return fmt.Errorf("no API key provided")
+		return "", fmt.Errorf("no API key provided")
 	}
-	cfg.EnvVars[cfg.APIKeyEnvVar] = keyValue
-	return nil
+	return keyValue, nil
 }
 
 func printAddResult(cfg *addConfig, exitCode int) {
+	out := platform.Stdout()
 	if exitCode == 0 {
-		fmt.Fprintf(os.Stdout, "\n%s\n", platform.Green(fmt.Sprintf("MCP server '%s' added successfully.", cfg.Name)))
-		if cfg.UseOAuth {
-			fmt.Println("Next: Run '/mcp' in Claude Code to complete OAuth authentication.")
+		fmt.Fprintf(out, "\n%s\n", platform.Green(fmt.Sprintf("MCP server '%s' added successfully.", cfg.Name)))
+		if cfg.UseOAuth && !hasAuthHeader(cfg.Headers) {
+			fmt.Fprintln(out, "Next: Run '/mcp' in Claude Code to complete OAuth authentication.")
 		} else {
-			fmt.Println("Run '/mcp' in Claude Code to verify the connection.")
+			fmt.Fprintln(out, "Run '/mcp' in Claude Code to verify the connection.")
 		}
 		if cfg.Scope == "project" && len(cfg.EnvVars) > 0 {
-			fmt.Println("\n  NOTE: Server added to .mcp.json (project scope).")
-			fmt.Println("  API keys are in your LOCAL Claude config, not in .mcp.json.")
-			fmt.Println("  Team members must set these env vars in their own environment:")
+			fmt.Fprintln(out, "\n  NOTE: Server added to .mcp.json (project scope).")
+			fmt.Fprintln(out, "  API keys are in your LOCAL Claude config, not in .mcp.json.")
+			fmt.Fprintln(out, "  Team members must set these env vars in their own environment:")
 			for key := range cfg.EnvVars {
-				fmt.Printf("    export %s=<value>\n", key)
+				fmt.Fprintf(out, "    export %s=<value>\n", key)
 			}
 		}
 	} else {
@@ -416,8 +372,13 @@ func printAddResult(cfg *addConfig, exitCode int) {
 	}
 }
 
-// Add adds a local or remote MCP server to the project or user config.
+// Add adds a local or remote MCP server to the project or user config. With
+// --from-registry, the server definition comes from the organization registry.
 func Add(args []string) error {
+	if len(args) > 0 && (args[0] == flagFromRegistry || strings.HasPrefix(args[0], flagFromRegistry+"=")) {
+		return addFromRegistry(args)
+	}
+
 	cfg, err := parseAddArgs(args)
 	if err != nil {
 		return err
@@ -431,15 +392,35 @@ func Add(args []string) error {
 		return err
 	}
 
+	return addServer(cfg)
+}
+
+// addServer registers cfg with the Claude CLI once all credentials are collected.
+func addServer(cfg *addConfig) error {
+	out := platform.Stdout()
+	if cfg.Transport != transportStdio && cfg.nativeOAuth(cfg.Scope) {
+		if _, err := cfg.signIn(cfg.Name, cfg.McpURL); err != nil {
+			return err
+		}
+	}
+	storeSecrets(out, cfg)
+	if cfg.Supervise && len(cfg.CommandArgs) > 0 {
+		wrapped, err := mcpsupervisor.WrapCommand(cfg.Name, cfg.CommandArgs)
+		if err != nil {
+			return err
+		}
+		cfg.CommandArgs = wrapped
+	}
+
 	claudeArgs, err := buildAddClaudeArgs(cfg)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Adding MCP server '%s' (%s, scope: %s)...\n", cfg.Name, cfg.Transport, cfg.Scope)
+	fmt.Fprintf(out, "Adding MCP server '%s' (%s, scope: %s)...\n", cfg.Name, cfg.Transport, cfg.Scope)
 
-	safeArgs := maskSensitiveArgs(claudeArgs)
-	fmt.Printf("  > claude %s\n\n", strings.Join(safeArgs, " "))
+	safeArgs := platform.MaskArgs(claudeArgs)
+	fmt.Fprintf(out, "  > claude %s\n\n", strings.Join(safeArgs, " "))
 
 	exitCode, err := platform.RunSpawn("claude", claudeArgs...)
 	if err != nil {
@@ -451,19 +432,20 @@ func Add(args []string) error {
 }
 
 func printRemoteStatus(cfg *remoteConfig) {
-	fmt.Printf("\nConnecting to remote MCP server '%s'...\n", cfg.Name)
-	fmt.Printf("  URL:       %s\n", cfg.McpURL)
-	fmt.Printf("  Transport: %s\n", cfg.Transport)
-	fmt.Printf("  Scope:     %s\n", cfg.Scope)
+	out := platform.Stdout()
+	fmt.Fprintf(out, "\nConnecting to remote MCP server '%s'...\n", cfg.Name)
+	fmt.Fprintf(out, "  URL:       %s\n", cfg.McpURL)
+	fmt.Fprintf(out, "  Transport: %s\n", cfg.Transport)
+	fmt.Fprintf(out, "  Scope:     %s\n", cfg.Scope)
 	switch {
 	case cfg.UseOAuth || cfg.ClientID != "":
-		fmt.Println("  Auth:      OAuth 2.0")
+		fmt.Fprintln(out, "  Auth:      OAuth 2.0")
 	case hasAuthHeader(cfg.Headers):
-		fmt.Println("  Auth:      Bearer token")
+		fmt.Fprintln(out, "  Auth:      Bearer token")
 	default:
-		fmt.Println("  Auth:      OAuth (via /mcp in session)")
+		fmt.Fprintln(out, "  Auth:      OAuth (via /mcp in session)")
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 func hasAuthHeader(headers []string) bool {
@@ -485,6 +467,11 @@ func Remote(mcpURL string, extraArgs []string) error {
 	if err := cfg.promptCredentials(cfg.Name); err != nil {
 		return err
 	}
+	if cfg.nativeOAuth(cfg.Scope) {
+		if _, err := cfg.signIn(cfg.Name, cfg.McpURL); err != nil {
+			return err
+		}
+	}
 
 	claudeArgs := buildRemoteClaudeArgs(cfg)
 	printRemoteStatus(cfg)
@@ -494,10 +481,11 @@ func Remote(mcpURL string, extraArgs []string) error {
 		return fmt.Errorf("could not run 'claude' command. Is Claude Code installed?")
 	}
 
+	out := platform.Stdout()
 	if exitCode == 0 {
-		fmt.Fprintf(os.Stdout, "\n%s\n", platform.Green(fmt.Sprintf("Remote MCP server '%s' connected.", cfg.Name)))
-		if cfg.UseOAuth || cfg.ClientID != "" || !cfg.PromptBearer {
-			fmt.Printf("Next: Run '/mcp' in Claude Code → select '%s' → Authenticate\n", cfg.Name)
+		fmt.Fprintf(out, "\n%s\n", platform.Green(fmt.Sprintf("Remote MCP server '%s' connected.", cfg.Name)))
+		if !hasAuthHeader(cfg.Headers) {
+			fmt.Fprintf(out, "Next: Run '/mcp' in Claude Code → select '%s' → Authenticate\n", cfg.Name)
 		}
 	} else {
 		fmt.Fprintf(os.Stderr, "\n%s\n", platform.Red(fmt.Sprintf("Failed to connect. Exit code: %d", exitCode)))
@@ -508,7 +496,7 @@ func Remote(mcpURL string, extraArgs []string) error {
 
 // List lists all configured MCP servers, printing to stdout.
 func List() error {
-	return ListTo(os.Stdout)
+	return ListTo(platform.Stdout())
 }
 
 // ListTo lists all configured MCP servers, writing to w.
@@ -569,7 +557,7 @@ func ListTo(w io.Writer) error {
 	platform.PrintSection(w, "Quick Add Commands")
 	fmt.Fprintln(w, "  Local server (no auth):        claude-workspace mcp add <name> --scope user -- <cmd>")
 	fmt.Fprintln(w, "  Local server (API key):        claude-workspace mcp add <name> --scope user --api-key API_KEY -- <cmd>")
-	fmt.Fprintln(w, "  Remote server (OAuth):         claude-workspace mcp remote <url> --scope user")
+	fmt.Fprintln(w, "  Remote server (OAuth):         claude-workspace mcp remote <url> --scope user --oauth")
 	fmt.Fprintln(w, "  Remote server (Bearer):        claude-workspace mcp remote <url> --scope user --bearer")
 	fmt.Fprintln(w, "  Remote server (client creds):  claude-workspace mcp remote <url> --scope user --oauth --client-id <id> --client-secret")
 	fmt.Fprintln(w, "  Remote server (custom header): claude-workspace mcp remote <url> --scope user --header 'Key: Value'")
@@ -580,27 +568,45 @@ func ListTo(w io.Writer) error {
 	return nil
 }
 
-func printMcpAddHelp() {
-	fmt.Print(`Usage: claude-workspace mcp add <name> [options] [-- <command> [args...]]
+func printMcpAddHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp add <name> [options] [-- <command> [args...]]
 
 Add a local or remote MCP server with secure API key handling.
 
 Authentication Options:
   --api-key ENV_VAR_NAME        Prompt for API key (masked input), stored as env var
   --bearer                      Prompt for Bearer token (masked input), added as header
-  --oauth                       Use OAuth 2.0 (authenticate via /mcp in Claude Code)
+  --oauth                       Sign in with OAuth now: opens the browser and
+                                receives the redirect on localhost
+  --device                      Sign in with a device code instead (headless machines)
+  --no-browser                  Print the sign-in URL instead of opening a browser
+  --callback-port <port>        Fixed localhost port for the OAuth redirect
   --client-id <id>              OAuth client ID (for pre-registered apps)
   --client-secret               Prompt for OAuth client secret (masked input)
 
+Organization Registry:
+  --from-registry <name>        Add an approved server from the registry set with
+                                'mcp registry set <url>' (must be the first option)
+
 Other Options:
   --scope local|project|user    Where to save config (default: local)
   --transport stdio|http|sse    Transport type (default: auto-detected)
   --env KEY=VALUE               Set environment variable (repeatable, visible)
   --header 'Key: Value'         Add HTTP header
+  --no-secret-store             Keep --api-key values in ~/.claude.json instead
+                                of the OS credential store
+  --supervise                   Launch through 'mcp serve': restart the server
+                                when it crashes or stops answering pings, and
+                                log its stderr (local stdio servers only)
 
 Security:
   - --api-key and --bearer use masked input (characters not shown)
-  - Secrets are stored in ~/.claude.json, NEVER in .mcp.json
+  - --api-key values for local stdio servers are saved with 'secrets set' and
+    injected at launch; other secrets are stored in ~/.claude.json
+  - Secrets are NEVER written to .mcp.json
+  - OAuth access tokens are stored as an Authorization header in
+    ~/.claude.json; refresh tokens go to the OS credential store. Project
+    servers authenticate via /mcp in each developer's session instead
   - When using --scope project, only the server definition goes in .mcp.json
   - .mcp.json supports ${VAR} syntax for team members to supply their own keys
 
@@ -610,12 +616,12 @@ Examples:
   claude-workspace mcp add brave-search --scope user --api-key BRAVE_API_KEY \
     -- npx -y @modelcontextprotocol/server-brave-search
 
-  # Database with connection string as secret
-  claude-workspace mcp add postgres --scope user --api-key DATABASE_URL \
+  # Database with connection string as secret, restarted if it crashes
+  claude-workspace mcp add postgres --scope user --api-key DATABASE_URL --supervise \
     -- npx -y @bytebase/dbhub
 
   # Remote server with OAuth (GitHub, Sentry, Notion, etc.)
-  claude-workspace mcp add github --scope user --transport http \
+  claude-workspace mcp add github --scope user --oauth --transport http \
     https://api.githubcopilot.com/mcp/
 
   # Remote server with Bearer token
@@ -625,17 +631,23 @@ Examples:
   # Share server with team (key stays local)
   claude-workspace mcp add sentry --scope project \
     --transport http https://mcp.sentry.dev/mcp
+
+  # Approved server from your organization's registry
+  claude-workspace mcp add --from-registry sentry
 `)
 }
 
-func printMcpRemoteHelp() {
-	fmt.Print(`Usage: claude-workspace mcp remote <url> [options]
+func printMcpRemoteHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp remote <url> [options]
 
 Connect to a remote MCP server or gateway.
 
 Authentication Options:
   --bearer                        Prompt for Bearer token (masked input)
-  --oauth                         Use OAuth 2.0 flow
+  --oauth                         Sign in with OAuth now (opens the browser)
+  --device                        Sign in with a device code (headless machines)
+  --no-browser                    Print the sign-in URL instead of opening a browser
+  --callback-port <port>          Fixed localhost port for the OAuth redirect
   --client-id <id>                OAuth client ID
   --client-secret                 Prompt for OAuth client secret
 
@@ -652,6 +664,10 @@ Examples:
   claude-workspace mcp remote https://mcp.notion.com/mcp --scope user --name notion
   claude-workspace mcp remote https://mcp.linear.app/mcp --scope user --name linear
 
+  # Sign in with OAuth here, from a script or over SSH
+  claude-workspace mcp remote https://mcp.sentry.dev/mcp --scope user --name sentry --oauth
+  claude-workspace mcp remote https://mcp-gateway.company.com/mcp --name company --device
+
   # Bearer token (prompted securely)
   claude-workspace mcp remote https://mcp.example.com --scope user --bearer
 
@@ -668,27 +684,30 @@ Examples:
 type removeConfig struct {
 	Name  string
 	Scope string
+
+	scopeSet bool // --scope was given; otherwise the scope is auto-detected
 }
 
 func parseRemoveArgs(args []string) (*removeConfig, error) {
-	if len(args) < 1 || args[0] == "--help" || args[0] == "-h" {
-		printMcpRemoveHelp()
-		return nil, fmt.Errorf("server name is required")
-	}
-
 	cfg := &removeConfig{
-		Name:  args[0],
 		Scope: "user",
 	}
-
-	for i := 1; i < len(args); i++ {
-		if args[i] == flagScope {
-			i++
-			if i < len(args) {
-				cfg.Scope = args[i]
-			}
-		}
+	f := cli.New("mcp remove", "mcp remove <name> [options]")
+	f.Help = printMcpRemoveHelp
+	f.StringVar(&cfg.Scope, flagScope, "local|project|user", "Which config to remove from")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) == 0 {
+		printMcpRemoveHelp(platform.Stdout())
+		return nil, fmt.Errorf("server name is required")
 	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return nil, err
+	}
+	cfg.Name = positional[0]
+	cfg.scopeSet = f.Changed(flagScope)
 
 	return cfg, nil
 }
@@ -697,17 +716,27 @@ func buildRemoveClaudeArgs(cfg *removeConfig) []string {
 	return []string{"mcp", "remove", cfg.Name, flagScope, cfg.Scope}
 }
 
-// Remove removes an MCP server from the specified scope.
+// Remove removes an MCP server from the specified scope. Without --scope, the
+// server is looked up across the user, local, and project configs.
 func Remove(args []string) error {
 	cfg, err := parseRemoveArgs(args)
 	if err != nil {
 		return err
 	}
 
+	if !cfg.scopeSet {
+		scope, err := detectServerScope(cfg.Name)
+		if err != nil {
+			return err
+		}
+		cfg.Scope = scope
+	}
+
 	claudeArgs := buildRemoveClaudeArgs(cfg)
 
-	fmt.Printf("Removing MCP server '%s' (scope: %s)...\n", cfg.Name, cfg.Scope)
-	fmt.Printf("  > claude %s\n\n", strings.Join(claudeArgs, " "))
+	out := platform.Stdout()
+	fmt.Fprintf(out, "Removing MCP server '%s' (scope: %s)...\n", cfg.Name, cfg.Scope)
+	fmt.Fprintf(out, "  > claude %s\n\n", strings.Join(claudeArgs, " "))
 
 	exitCode, err := platform.RunSpawn("claude", claudeArgs...)
 	if err != nil {
@@ -715,7 +744,7 @@ func Remove(args []string) error {
 	}
 
 	if exitCode == 0 {
-		fmt.Fprintf(os.Stdout, "\n%s\n", platform.Green(fmt.Sprintf("MCP server '%s' removed.", cfg.Name)))
+		fmt.Fprintf(out, "\n%s\n", platform.Green(fmt.Sprintf("MCP server '%s' removed.", cfg.Name)))
 	} else {
 		fmt.Fprintf(os.Stderr, "\n%s\n", platform.Red(fmt.Sprintf("Failed to remove MCP server. Exit code: %d", exitCode)))
 	}
@@ -723,17 +752,18 @@ func Remove(args []string) error {
 	return nil
 }
 
-func printMcpRemoveHelp() {
-	fmt.Print(`Usage: claude-workspace mcp remove <name> [options]
+func printMcpRemoveHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp remove <name> [options]
 
 Remove an MCP server from your configuration.
 
 Options:
-  --scope local|project|user    Which config to remove from (default: user)
+  --scope local|project|user    Which config to remove from (default: the
+                                scope the server is configured in)
 
 Examples:
 
-  # Remove a server from user config (default)
+  # Remove a server from whichever config defines it
   claude-workspace mcp remove brave-search
 
   # Remove a server from project config
@@ -154,6 +154,25 @@ func TestParseAddArgs(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "supervise flag",
+			args: []string{"srv", "--supervise", "--", "cmd"},
+			check: func(t *testing.T, cfg *addConfig) {
+				if !cfg.Supervise {
+					t.Error("Supervise = false, want true")
+				}
+			},
+		},
+		{
+			name:    "supervise rejected for project scope",
+			args:    []string{"srv", "--supervise", "--scope", "project", "--", "cmd"},
+			wantErr: true,
+		},
+		{
+			name:    "supervise rejected for remote servers",
+			args:    []string{"srv", "--supervise", "https://example.com/mcp"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -574,57 +593,6 @@ func TestBuildRemoteClaudeArgs(t *testing.T) {
 	}
 }
 
-func TestMaskSensitiveArgs(t *testing.T) {
-	tests := []struct {
-		name string
-		args []string
-		want []string
-	}{
-		{
-			name: "env values masked",
-			args: []string{"mcp", "add", "-e", "API_KEY=secret123", "my-server"},
-			want: []string{"mcp", "add", "-e", "API_KEY=****", "my-server"},
-		},
-		{
-			name: "bearer headers masked",
-			args: []string{"mcp", "add", "--header", "Authorization: Bearer mysecrettoken", "my-server"},
-			want: []string{"mcp", "add", "--header", "Authorization: Bearer ****", "my-server"},
-		},
-		{
-			name: "non-sensitive args preserved",
-			args: []string{"mcp", "add", "--transport", "http", "--scope", "user", "my-server"},
-			want: []string{"mcp", "add", "--transport", "http", "--scope", "user", "my-server"},
-		},
-		{
-			name: "multiple env vars all masked",
-			args: []string{"mcp", "add", "-e", "KEY1=val1", "-e", "KEY2=val2", "srv"},
-			want: []string{"mcp", "add", "-e", "KEY1=****", "-e", "KEY2=****", "srv"},
-		},
-		{
-			name: "non-bearer header not masked",
-			args: []string{"mcp", "add", "--header", "X-Custom: value", "srv"},
-			want: []string{"mcp", "add", "--header", "X-Custom: value", "srv"},
-		},
-		{
-			name: "client-secret value masked",
-			args: []string{"mcp", "add", "--client-id", "my-id", "--client-secret", "supersensitivevalue", "srv"},
-			want: []string{"mcp", "add", "--client-id", "my-id", "--client-secret", "****", "srv"},
-		},
-		{
-			name: "empty args",
-			args: []string{},
-			want: []string{},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := maskSensitiveArgs(tt.args)
-			assertSliceEqual(t, got, tt.want)
-		})
-	}
-}
-
 func TestParseRemoveArgs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -657,6 +625,9 @@ func TestParseRemoveArgs(t *testing.T) {
 				if cfg.Scope != scopeUser {
 					t.Errorf("Scope = %q, want %q", cfg.Scope, scopeUser)
 				}
+				if cfg.scopeSet {
+					t.Error("scopeSet = true, want false so the scope is auto-detected")
+				}
 			},
 		},
 		{
@@ -669,6 +640,9 @@ func TestParseRemoveArgs(t *testing.T) {
 				if cfg.Scope != scopeProject {
 					t.Errorf("Scope = %q, want %q", cfg.Scope, scopeProject)
 				}
+				if !cfg.scopeSet {
+					t.Error("scopeSet = false, want true when --scope is given")
+				}
 			},
 		},
 		{
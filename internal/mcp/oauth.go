@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpoauth"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// signInTimeout bounds how long sign-in waits for the user to approve it.
+const signInTimeout = 5 * time.Minute
+
+// Tests replace these to sign in against a fake authorization server.
+var (
+	discoverOAuth = mcpoauth.Discover
+	oauthLogin    = mcpoauth.Login
+)
+
+// nativeOAuth reports whether to sign in here rather than leave OAuth to /mcp
+// in Claude Code. Tokens are personal, so project-scoped servers, whose config
+// is shared through .mcp.json, still authenticate in each developer's session.
+func (a *authOpts) nativeOAuth(scope string) bool {
+	return (a.UseOAuth || a.ClientID != "") && scope != scopeProject
+}
+
+// oauthSecretName returns the credential store name that holds the refresh
+// grant for server.
+func oauthSecretName(server string) string {
+	var b strings.Builder
+	b.WriteString("MCP_OAUTH_")
+	for _, r := range strings.ToUpper(server) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// signIn runs the OAuth flow for the remote server at mcpURL, adds the access
+// token as an Authorization header, and saves the refresh grant to the
+// credential store. When the server's OAuth endpoints cannot be discovered it
+// warns and returns false, leaving authentication to /mcp in Claude Code.
+func (a *authOpts) signIn(name, mcpURL string) (bool, error) {
+	out := platform.Stdout()
+	port := 0
+	if a.CallbackPort != "" {
+		var err error
+		if port, err = strconv.Atoi(a.CallbackPort); err != nil || port <= 0 || port > 65535 {
+			return false, fmt.Errorf("--callback-port expects a port number, got %q", a.CallbackPort)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), signInTimeout)
+	defer cancel()
+	server, err := discoverOAuth(ctx, mcpURL)
+	if err != nil {
+		platform.PrintWarn(out, fmt.Sprintf("Could not find the OAuth endpoints of %s (%v); run '/mcp' in Claude Code to authenticate instead", mcpURL, err))
+		return false, nil
+	}
+
+	opts := mcpoauth.Options{
+		Client:       mcpoauth.Client{ID: a.ClientID, Secret: a.ClientSecret},
+		Device:       a.Device,
+		CallbackPort: port,
+		Out:          out,
+	}
+	// Without a local browser, prefer the device flow; otherwise the user
+	// opens the URL elsewhere and pastes back where it redirected.
+	headless := a.NoBrowser || os.Getenv("SSH_CONNECTION") != ""
+	switch {
+	case !headless:
+		opts.OpenURL = platform.OpenBrowser
+	case !a.Device && server.Metadata.DeviceAuthorizationEndpoint != "":
+		opts.Device = true
+	case !a.Device:
+		opts.Paste = os.Stdin
+	}
+
+	fmt.Fprintf(out, "\nSigning in to '%s' with OAuth...\n\n", name)
+	tok, grant, err := oauthLogin(ctx, server, opts)
+	if err != nil {
+		return false, err
+	}
+	a.Headers = append(a.Headers, "Authorization: Bearer "+tok.AccessToken)
+	saveGrant(out, name, grant)
+	printTokenExpiry(out, name, tok)
+	return true, nil
+}
+
+// renewToken sets a new Authorization header for an OAuth server: from the
+// saved refresh grant when there is one, otherwise by signing in again.
+func (a *authOpts) renewToken(name, mcpURL string) error {
+	out := platform.Stdout()
+	if grant := loadGrant(name); grant != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		tok, err := grant.Refresh(ctx)
+		if err == nil {
+			a.Headers = append(a.Headers, "Authorization: Bearer "+tok.AccessToken)
+			saveGrant(out, name, grant)
+			fmt.Fprintln(out, "Renewed the access token with the saved refresh token.")
+			printTokenExpiry(out, name, tok)
+			return nil
+		}
+		platform.PrintInfo(out, fmt.Sprintf("The saved refresh token was not accepted (%v); signing in again", err))
+	}
+	ok, err := a.signIn(name, mcpURL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("could not sign in to '%s'", name)
+	}
+	return nil
+}
+
+// saveGrant stores grant for later renewal. Sign-in still succeeds without it.
+func saveGrant(w io.Writer, name string, grant *mcpoauth.Grant) {
+	if grant == nil {
+		return
+	}
+	data, _ := json.Marshal(grant)
+	store, err := openSecretStore()
+	if err == nil {
+		err = store.Set(oauthSecretName(name), string(data))
+	}
+	if err != nil {
+		platform.PrintWarningLine(w, fmt.Sprintf("Could not save the refresh token (%v); renewing the token will need a new sign-in", err))
+	}
+}
+
+// loadGrant returns the saved refresh grant for name, or nil.
+func loadGrant(name string) *mcpoauth.Grant {
+	store, err := openSecretStore()
+	if err != nil {
+		return nil
+	}
+	data, err := store.Get(oauthSecretName(name))
+	if err != nil {
+		return nil
+	}
+	var grant mcpoauth.Grant
+	if json.Unmarshal([]byte(data), &grant) != nil || grant.RefreshToken == "" {
+		return nil
+	}
+	return &grant
+}
+
+func printTokenExpiry(w io.Writer, name string, tok *mcpoauth.Token) {
+	if tok.Expiry.IsZero() {
+		fmt.Fprintln(w, "Signed in.")
+		return
+	}
+	fmt.Fprintf(w, "Signed in. The access token expires in %s; renew it with:\n", formatUptime(time.Until(tok.Expiry)))
+	platform.PrintCommand(w, fmt.Sprintf("claude-workspace mcp update %s --oauth", name))
+}
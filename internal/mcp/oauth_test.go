@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpoauth"
+)
+
+// withOAuth replaces discovery and login with fakes. login receives the
+// options Login was called with.
+func withOAuth(t *testing.T, discoverErr error, login func(mcpoauth.Options) (*mcpoauth.Token, *mcpoauth.Grant, error)) {
+	t.Helper()
+	oldDiscover, oldLogin := discoverOAuth, oauthLogin
+	discoverOAuth = func(_ context.Context, mcpURL string) (*mcpoauth.Server, error) {
+		if discoverErr != nil {
+			return nil, discoverErr
+		}
+		return &mcpoauth.Server{URL: mcpURL, Metadata: mcpoauth.Metadata{TokenEndpoint: "https://auth.example.com/token"}}, nil
+	}
+	oauthLogin = func(_ context.Context, _ *mcpoauth.Server, opts mcpoauth.Options) (*mcpoauth.Token, *mcpoauth.Grant, error) {
+		return login(opts)
+	}
+	t.Cleanup(func() { discoverOAuth, oauthLogin = oldDiscover, oldLogin })
+}
+
+func TestSignIn(t *testing.T) {
+	store := memStore{}
+	withSecretStore(t, store, nil)
+	t.Setenv("SSH_CONNECTION", "")
+	var got mcpoauth.Options
+	withOAuth(t, nil, func(opts mcpoauth.Options) (*mcpoauth.Token, *mcpoauth.Grant, error) {
+		got = opts
+		return &mcpoauth.Token{AccessToken: "access-1", Expiry: time.Now().Add(time.Hour)},
+			&mcpoauth.Grant{Client: mcpoauth.Client{ID: "client-1"}, RefreshToken: "refresh-1"}, nil
+	})
+
+	a := &authOpts{UseOAuth: true, ClientID: "preset", CallbackPort: "8765"}
+	ok, err := a.signIn("My Gateway", "https://gw.example.com/mcp")
+	if err != nil || !ok {
+		t.Fatalf("signIn = %v, %v", ok, err)
+	}
+	if got.Client.ID != "preset" || got.CallbackPort != 8765 || got.OpenURL == nil || got.Device {
+		t.Errorf("Login options = %+v", got)
+	}
+	if len(a.Headers) != 1 || a.Headers[0] != "Authorization: Bearer access-1" {
+		t.Errorf("Headers = %q", a.Headers)
+	}
+	if !strings.Contains(store["MCP_OAUTH_MY_GATEWAY"], `"refresh_token":"refresh-1"`) {
+		t.Errorf("saved grant = %q", store["MCP_OAUTH_MY_GATEWAY"])
+	}
+
+	// Over SSH there is no browser to open: the redirect is pasted instead.
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")
+	if _, err := (&authOpts{UseOAuth: true}).signIn("gw", "https://gw.example.com/mcp"); err != nil {
+		t.Fatal(err)
+	}
+	if got.OpenURL != nil || got.Paste == nil {
+		t.Errorf("headless Login options = %+v", got)
+	}
+
+	if _, err := (&authOpts{UseOAuth: true, CallbackPort: "http"}).signIn("gw", "https://gw.example.com/mcp"); err == nil {
+		t.Error("bad --callback-port: expected error")
+	}
+}
+
+func TestSignIn_Fallbacks(t *testing.T) {
+	withSecretStore(t, memStore{}, nil)
+	withOAuth(t, errors.New("no metadata"), nil)
+	a := &authOpts{UseOAuth: true}
+	if ok, err := a.signIn("gw", "https://gw.example.com/mcp"); ok || err != nil || len(a.Headers) != 0 {
+		t.Errorf("undiscoverable server: signIn = %v, %v, headers %q; want fallback to /mcp", ok, err, a.Headers)
+	}
+
+	withOAuth(t, nil, func(mcpoauth.Options) (*mcpoauth.Token, *mcpoauth.Grant, error) {
+		return nil, nil, errors.New("access_denied")
+	})
+	if _, err := a.signIn("gw", "https://gw.example.com/mcp"); err == nil {
+		t.Error("declined sign-in: expected error")
+	}
+}
+
+func TestRenewToken_Refresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("refresh_token") != "refresh-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"access-2","token_type":"Bearer","refresh_token":"refresh-2"}`)
+	}))
+	defer srv.Close()
+
+	store := memStore{}
+	withSecretStore(t, store, nil)
+	grant, _ := json.Marshal(mcpoauth.Grant{Client: mcpoauth.Client{ID: "c"}, TokenEndpoint: srv.URL, RefreshToken: "refresh-1"})
+	store[oauthSecretName("gw")] = string(grant)
+	withOAuth(t, nil, func(mcpoauth.Options) (*mcpoauth.Token, *mcpoauth.Grant, error) {
+		t.Error("signed in again although the refresh token was valid")
+		return nil, nil, errors.New("unexpected")
+	})
+
+	a := &authOpts{UseOAuth: true}
+	if err := a.renewToken("gw", "https://gw.example.com/mcp"); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Headers) != 1 || a.Headers[0] != "Authorization: Bearer access-2" {
+		t.Errorf("Headers = %q", a.Headers)
+	}
+	if !strings.Contains(store[oauthSecretName("gw")], `"refresh_token":"refresh-2"`) {
+		t.Errorf("rotated refresh token not saved: %q", store[oauthSecretName("gw")])
+	}
+}
+
+func TestNativeOAuth(t *testing.T) {
+	tests := []struct {
+		a     authOpts
+		scope string
+		want  bool
+	}{
+		{authOpts{UseOAuth: true}, scopeUser, true},
+		{authOpts{ClientID: "abc"}, scopeLocal, true},
+		{authOpts{UseOAuth: true}, scopeProject, false},
+		{authOpts{PromptBearer: true}, scopeUser, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.nativeOAuth(tt.scope); got != tt.want {
+			t.Errorf("nativeOAuth(%+v, %s) = %v, want %v", tt.a, tt.scope, got, tt.want)
+		}
+	}
+}
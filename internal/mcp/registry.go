@@ -0,0 +1,272 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/mcpregistry"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Registry implements "mcp registry", which manages the organization catalog of
+// approved MCP servers used by "mcp add --from-registry".
+func Registry(args []string) error {
+	subcmd := "show"
+	if len(args) > 0 {
+		subcmd = args[0]
+	}
+	out := platform.Stdout()
+	switch subcmd {
+	case "set":
+		f := cli.New("mcp registry set", "mcp registry set <url|file>")
+		f.Help = printMcpRegistryHelp
+		positional, err := f.Parse(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(positional) == 0 {
+			printMcpRegistryHelp(out)
+			return fmt.Errorf("registry URL is required")
+		}
+		if err := f.CheckArgs(positional, 1, 1); err != nil {
+			return err
+		}
+		return registrySet(positional[0])
+	case "show", "list":
+		return registryShow(out)
+	case "unset":
+		if err := mcpregistry.ClearOrgRegistry(); err != nil {
+			return err
+		}
+		platform.PrintSuccess(out, "MCP registry removed.")
+		return nil
+	case "--help", "-h", "help":
+		printMcpRegistryHelp(out)
+		return nil
+	default:
+		printMcpRegistryHelp(out)
+		return fmt.Errorf("unknown registry subcommand: %s (available: set, show, unset)", subcmd)
+	}
+}
+
+// registrySet validates the catalog at location and saves it as the registry.
+func registrySet(location string) error {
+	if !strings.Contains(location, "://") {
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return fmt.Errorf("resolving path: %w", err)
+		}
+		location = abs
+	}
+	catalog, data, err := mcpregistry.FetchCatalog(location)
+	if err != nil {
+		return err
+	}
+	if err := mcpregistry.SaveOrgRegistry(location, data); err != nil {
+		return err
+	}
+	label := location
+	if catalog.Name != "" {
+		label = fmt.Sprintf("%s (%s)", catalog.Name, location)
+	}
+	out := platform.Stdout()
+	platform.PrintSuccess(out, fmt.Sprintf("MCP registry set: %s", label))
+	fmt.Fprintf(out, "  %d approved server(s) available. Run 'claude-workspace mcp registry show' to list them.\n", len(catalog.Servers))
+	return nil
+}
+
+// registryShow prints the configured registry and its approved servers to w.
+func registryShow(w io.Writer) error {
+	catalog, reg, stale, err := mcpregistry.LoadOrgCatalog()
+	if err != nil {
+		return err
+	}
+
+	platform.PrintBanner(w, "MCP Registry")
+	fmt.Fprintln(w)
+	if catalog.Name != "" {
+		fmt.Fprintf(w, "  Name:    %s\n", catalog.Name)
+	}
+	fmt.Fprintf(w, "  Source:  %s\n", reg.URL)
+	if stale {
+		platform.PrintWarningLine(w, fmt.Sprintf("Registry unreachable; showing cached catalog from %s", reg.FetchedAt))
+	}
+
+	platform.PrintSection(w, "Approved Servers")
+	printCatalogServers(w, catalog)
+
+	fmt.Fprintln(w)
+	platform.PrintCommand(w, "claude-workspace mcp add --from-registry <name>")
+	fmt.Fprintln(w)
+	return nil
+}
+
+// printCatalogServers writes one line per catalog server.
+func printCatalogServers(w io.Writer, catalog *mcpregistry.Catalog) {
+	if len(catalog.Servers) == 0 {
+		fmt.Fprintln(w, "  (none)")
+		return
+	}
+	maxName := 0
+	for _, s := range catalog.Servers {
+		if len(s.Name) > maxName {
+			maxName = len(s.Name)
+		}
+	}
+	for _, s := range catalog.Servers {
+		detail := s.Transport
+		if s.Auth != mcpregistry.AuthNone {
+			detail += ", " + s.Auth
+		}
+		if env := s.EnvNames(); len(env) > 0 {
+			detail += ", env: " + strings.Join(env, " ")
+		}
+		fmt.Fprintf(w, "  %-*s  %s (%s)\n", maxName, s.Name, s.Description, detail)
+	}
+}
+
+// parseFromRegistryArgs extracts the server name and optional --scope from
+// "--from-registry <name> [--scope <scope>]".
+func parseFromRegistryArgs(args []string) (name, scope string, err error) {
+	f := cli.New("mcp add", "mcp add --from-registry <name> [--scope <scope>]")
+	f.StringVar(&name, flagFromRegistry, "name", "Approved server to add")
+	f.StringVar(&scope, flagScope, "local|project|user", "Where to save config (default: the registry's)")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", "", err
+	}
+	if len(positional) > 0 {
+		return "", "", fmt.Errorf("%s only accepts %s (got %s)", flagFromRegistry, flagScope, positional[0])
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("%s requires a server name", flagFromRegistry)
+	}
+	return name, scope, nil
+}
+
+// catalogAddConfig converts a registry entry into an add configuration. Env
+// vars are left empty for promptRegistryEnv to fill in.
+func catalogAddConfig(s *mcpregistry.CatalogServer, scope string) *addConfig {
+	if scope == "" {
+		scope = s.Scope
+	}
+	cfg := &addConfig{
+		Name:      s.Name,
+		Scope:     scope,
+		Transport: s.Transport,
+		McpURL:    s.URL,
+		EnvVars:   map[string]string{},
+	}
+	if s.Command != "" {
+		cfg.CommandArgs = append([]string{s.Command}, s.Args...)
+	}
+	keys := make([]string, 0, len(s.Headers))
+	for key := range s.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cfg.Headers = append(cfg.Headers, key+": "+s.Headers[key])
+	}
+	switch s.Auth {
+	case mcpregistry.AuthOAuth:
+		cfg.UseOAuth = true
+	case mcpregistry.AuthBearer:
+		cfg.PromptBearer = true
+	}
+	return cfg
+}
+
+// promptRegistryEnv collects the env vars a registry entry requires. Secrets use
+// masked input; other values use the catalog default or a plain prompt.
+func promptRegistryEnv(cfg *addConfig, s *mcpregistry.CatalogServer, reader *bufio.Reader) error {
+	out := platform.Stdout()
+	for _, name := range s.EnvNames() {
+		spec := s.Env[name]
+		if spec.Secret {
+			value, err := promptAPIKeyValue(cfg.Name, name)
+			if err != nil {
+				return err
+			}
+			cfg.addSecret(name, value)
+			continue
+		}
+		if spec.Default != "" {
+			cfg.EnvVars[name] = spec.Default
+			continue
+		}
+		if spec.Description != "" {
+			fmt.Fprintf(out, "\n%s: %s\n", name, spec.Description)
+		}
+		platform.PrintPrompt(out, fmt.Sprintf("Enter %s: ", name))
+		line, _ := reader.ReadString('\n')
+		value := strings.TrimSpace(line)
+		if value == "" {
+			return fmt.Errorf("no value provided for %s", name)
+		}
+		cfg.EnvVars[name] = value
+	}
+	return nil
+}
+
+// addFromRegistry adds an approved server from the organization registry.
+func addFromRegistry(args []string) error {
+	name, scope, err := parseFromRegistryArgs(args)
+	if err != nil {
+		return err
+	}
+
+	catalog, _, stale, err := mcpregistry.LoadOrgCatalog()
+	if err != nil {
+		return err
+	}
+	out := platform.Stdout()
+	if stale {
+		platform.PrintWarningLine(out, "Registry unreachable; using cached catalog")
+	}
+
+	entry := catalog.Find(name)
+	if entry == nil {
+		return fmt.Errorf("server %q is not in the MCP registry (available: %s)", name, strings.Join(catalog.Names(), ", "))
+	}
+	if entry.Description != "" {
+		platform.PrintInfo(out, fmt.Sprintf("%s: %s", entry.Name, entry.Description))
+	}
+
+	cfg := catalogAddConfig(entry, scope)
+	if err := promptRegistryEnv(cfg, entry, bufio.NewReader(os.Stdin)); err != nil {
+		return err
+	}
+	if err := cfg.promptCredentials(cfg.Name); err != nil {
+		return err
+	}
+
+	return addServer(cfg)
+}
+
+func printMcpRegistryHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp registry <set|show|unset> [url]
+
+Manage your organization's registry of approved MCP servers.
+
+Subcommands:
+  set <url|path>    Fetch, validate, and save the registry catalog
+  show              List approved servers (default; refreshes the cache)
+  unset             Forget the configured registry
+
+The catalog is fetched again on each use and cached in ~/.claude-workspace,
+so installs keep working when the registry is unreachable.
+
+Examples:
+
+  claude-workspace mcp registry set https://platform.example.com/mcp-registry.json
+  claude-workspace mcp registry show
+  claude-workspace mcp add --from-registry sentry
+`)
+}
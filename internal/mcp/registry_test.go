@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpregistry"
+)
+
+func TestParseFromRegistryArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantName  string
+		wantScope string
+		wantErr   bool
+	}{
+		{name: "separate value", args: []string{"--from-registry", "sentry"}, wantName: "sentry"},
+		{name: "equals form", args: []string{"--from-registry=sentry"}, wantName: "sentry"},
+		{name: "with scope", args: []string{"--from-registry", "sentry", "--scope", "project"}, wantName: "sentry", wantScope: "project"},
+		{name: "missing name", args: []string{"--from-registry"}, wantErr: true},
+		{name: "other flags rejected", args: []string{"--from-registry", "sentry", "--bearer"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, scope, err := parseFromRegistryArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != tt.wantName || scope != tt.wantScope {
+				t.Errorf("got (%q, %q), want (%q, %q)", name, scope, tt.wantName, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestCatalogAddConfig(t *testing.T) {
+	remote := &mcpregistry.CatalogServer{
+		Name: "gateway", Transport: "http", URL: "https://mcp.example.com",
+		Auth: mcpregistry.AuthBearer, Scope: "user",
+		Headers: map[string]string{"X-Team": "platform", "X-Org": "acme"},
+	}
+	cfg := catalogAddConfig(remote, "")
+	if cfg.Scope != "user" || cfg.McpURL != remote.URL || !cfg.PromptBearer || cfg.UseOAuth {
+		t.Errorf("remote config = %+v", cfg)
+	}
+	assertSliceEqual(t, cfg.Headers, []string{"X-Org: acme", "X-Team: platform"})
+
+	local := &mcpregistry.CatalogServer{
+		Name: "postgres", Transport: "stdio", Command: "npx", Args: []string{"-y", "@bytebase/dbhub"},
+		Auth: mcpregistry.AuthNone, Scope: "user",
+	}
+	cfg = catalogAddConfig(local, "project")
+	if cfg.Scope != "project" {
+		t.Errorf("Scope = %q, want explicit scope to win", cfg.Scope)
+	}
+	args, err := buildAddClaudeArgs(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"mcp", "add", "--transport", "stdio", "--scope", "project", "postgres", "--", "npx", "-y", "@bytebase/dbhub"}
+	assertSliceEqual(t, args, want)
+}
+
+func TestPromptRegistryEnv_DefaultsAndPlainValues(t *testing.T) {
+	entry := &mcpregistry.CatalogServer{
+		Name: "jira",
+		Env: map[string]mcpregistry.EnvSpec{
+			"JIRA_URL":   {Default: "https://acme.atlassian.net"},
+			"JIRA_EMAIL": {Description: "Your work email"},
+		},
+	}
+	cfg := &addConfig{Name: "jira", EnvVars: map[string]string{}}
+	reader := bufio.NewReader(strings.NewReader("me@acme.com\n"))
+	if err := promptRegistryEnv(cfg, entry, reader); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.EnvVars["JIRA_URL"] != "https://acme.atlassian.net" {
+		t.Errorf("JIRA_URL = %q, want catalog default", cfg.EnvVars["JIRA_URL"])
+	}
+	if cfg.EnvVars["JIRA_EMAIL"] != "me@acme.com" {
+		t.Errorf("JIRA_EMAIL = %q, want prompted value", cfg.EnvVars["JIRA_EMAIL"])
+	}
+
+	cfg = &addConfig{Name: "jira", EnvVars: map[string]string{}}
+	if err := promptRegistryEnv(cfg, entry, bufio.NewReader(strings.NewReader("\n"))); err == nil {
+		t.Error("expected error for empty required value")
+	}
+}
+
+func TestPrintCatalogServers(t *testing.T) {
+	c, err := mcpregistry.ParseCatalog([]byte(`{"servers":[
+		{"name":"sentry","description":"Errors","url":"https://mcp.sentry.dev/mcp","auth":"oauth"},
+		{"name":"db","description":"Postgres","command":"npx","env":{"DATABASE_URL":{"secret":true}}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	printCatalogServers(&buf, c)
+	out := buf.String()
+	for _, want := range []string{"sentry  Errors (http, oauth)", "db      Postgres (stdio, env: DATABASE_URL)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
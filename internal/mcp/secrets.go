@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpsupervisor"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+// openSecretStore opens the credential store; tests replace it.
+var openSecretStore = secrets.Open
+
+// storeSecrets saves cfg.SecretEnv to the credential store and wraps the server
+// command with "claude-workspace secrets exec" so the values are injected at
+// launch instead of living in ~/.claude.json. Project-scoped and remote servers
+// cannot be wrapped portably, so for those (and with --no-secret-store, or when
+// the store fails) the secrets become plain env vars as before.
+func storeSecrets(w io.Writer, cfg *addConfig) {
+	if len(cfg.SecretEnv) == 0 {
+		return
+	}
+	names := make([]string, 0, len(cfg.SecretEnv))
+	for name := range cfg.SecretEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := wrapWithSecrets(w, cfg, names); err != nil {
+		platform.PrintWarningLine(w, fmt.Sprintf("Credential store unavailable (%v); storing secrets in Claude config", err))
+	}
+	if cfg.SecretEnv == nil {
+		return
+	}
+	for _, name := range names {
+		cfg.EnvVars[name] = cfg.SecretEnv[name]
+	}
+	cfg.SecretEnv = nil
+}
+
+// wrapWithSecrets stores the secrets and rewrites cfg.CommandArgs. It leaves
+// cfg unchanged when wrapping does not apply and clears cfg.SecretEnv on success.
+func wrapWithSecrets(w io.Writer, cfg *addConfig, names []string) error {
+	if cfg.NoSecretStore || cfg.Scope == scopeProject || cfg.Transport != transportStdio || len(cfg.CommandArgs) == 0 {
+		return nil
+	}
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := secrets.ValidateName(name); err != nil {
+			return err
+		}
+		if err := store.Set(name, cfg.SecretEnv[name]); err != nil {
+			return err
+		}
+	}
+	wrapped, err := secrets.WrapCommand(names, cfg.CommandArgs)
+	if err != nil {
+		return err
+	}
+	cfg.CommandArgs = wrapped
+	cfg.SecretEnv = nil
+	fmt.Fprintf(w, "Saved %s to the %s credential store.\n", strings.Join(names, ", "), store.Backend())
+	return nil
+}
+
+// wrappedSecretNames returns the secrets injected into a configured server by
+// "secrets exec", or nil if the server is not wrapped. A supervised server is
+// looked at through its "mcp serve" wrapper.
+func wrappedSecretNames(entry map[string]interface{}) []string {
+	args := stringSlice(entry["args"])
+	if command, ok := mcpsupervisor.Unwrap(args); ok {
+		args = command[1:]
+	}
+	return secrets.WrappedNames(args)
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+// memStore is an in-memory secrets.Store.
+type memStore map[string]string
+
+func (m memStore) Backend() string { return "memory" }
+func (m memStore) Get(name string) (string, error) {
+	if v, ok := m[name]; ok {
+		return v, nil
+	}
+	return "", secrets.ErrNotFound
+}
+func (m memStore) Set(name, value string) error { m[name] = value; return nil }
+func (m memStore) Delete(name string) error     { delete(m, name); return nil }
+func (m memStore) List() ([]string, error)      { return nil, nil }
+
+func withSecretStore(t *testing.T, store secrets.Store, err error) {
+	t.Helper()
+	old := openSecretStore
+	openSecretStore = func() (secrets.Store, error) { return store, err }
+	t.Cleanup(func() { openSecretStore = old })
+}
+
+func TestStoreSecrets_WrapsLocalStdio(t *testing.T) {
+	store := memStore{}
+	withSecretStore(t, store, nil)
+
+	cfg := &addConfig{
+		Name: "brave", Scope: scopeUser, Transport: transportStdio,
+		EnvVars:     map[string]string{},
+		CommandArgs: []string{"npx", "-y", "server-brave-search"},
+	}
+	cfg.addSecret("BRAVE_API_KEY", "k")
+	storeSecrets(&bytes.Buffer{}, cfg)
+
+	if store["BRAVE_API_KEY"] != "k" {
+		t.Errorf("secret not saved to store: %v", store)
+	}
+	if _, leaked := cfg.EnvVars["BRAVE_API_KEY"]; leaked {
+		t.Error("secret should not be registered as a plain env var")
+	}
+	if got := strings.Join(cfg.CommandArgs[1:], " "); got != "secrets exec BRAVE_API_KEY -- npx -y server-brave-search" {
+		t.Errorf("CommandArgs = %q", got)
+	}
+}
+
+func TestStoreSecrets_FallsBackToEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *addConfig
+		storeErr error
+		wantWarn bool
+	}{
+		{
+			name: "project scope",
+			cfg:  &addConfig{Scope: scopeProject, Transport: transportStdio, CommandArgs: []string{"npx"}},
+		},
+		{
+			name: "remote server",
+			cfg:  &addConfig{Scope: scopeUser, Transport: transportHTTP, McpURL: "https://x"},
+		},
+		{
+			name: "opted out",
+			cfg:  &addConfig{Scope: scopeUser, Transport: transportStdio, CommandArgs: []string{"npx"}, NoSecretStore: true},
+		},
+		{
+			name:     "store unavailable",
+			cfg:      &addConfig{Scope: scopeUser, Transport: transportStdio, CommandArgs: []string{"npx"}},
+			storeErr: errors.New("locked"),
+			wantWarn: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withSecretStore(t, memStore{}, tt.storeErr)
+			tt.cfg.EnvVars = map[string]string{}
+			tt.cfg.addSecret("KEY", "v")
+			var buf bytes.Buffer
+			storeSecrets(&buf, tt.cfg)
+
+			if tt.cfg.EnvVars["KEY"] != "v" {
+				t.Errorf("EnvVars[KEY] = %q, want fallback value", tt.cfg.EnvVars["KEY"])
+			}
+			if len(tt.cfg.CommandArgs) > 1 {
+				t.Errorf("command should not be wrapped: %v", tt.cfg.CommandArgs)
+			}
+			if gotWarn := strings.Contains(buf.String(), "unavailable"); gotWarn != tt.wantWarn {
+				t.Errorf("warning printed = %v, want %v (%q)", gotWarn, tt.wantWarn, buf.String())
+			}
+		})
+	}
+}
+
+func TestWrappedSecretNames(t *testing.T) {
+	wrapped := map[string]interface{}{
+		"command": "/usr/local/bin/claude-workspace",
+		"args":    []interface{}{"secrets", "exec", "A", "B", "--", "npx", "pkg"},
+	}
+	if got := wrappedSecretNames(wrapped); strings.Join(got, ",") != "A,B" {
+		t.Errorf("wrappedSecretNames = %v", got)
+	}
+	supervised := map[string]interface{}{
+		"command": "/usr/local/bin/claude-workspace",
+		"args": []interface{}{"mcp", "serve", "db", "--",
+			"/usr/local/bin/claude-workspace", "secrets", "exec", "A", "--", "npx", "pkg"},
+	}
+	if got := wrappedSecretNames(supervised); strings.Join(got, ",") != "A" {
+		t.Errorf("wrappedSecretNames(supervised) = %v", got)
+	}
+	plain := map[string]interface{}{"command": "npx", "args": []interface{}{"-y", "pkg"}}
+	if got := wrappedSecretNames(plain); got != nil {
+		t.Errorf("wrappedSecretNames(plain) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/mcpsupervisor"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Serve implements "mcp serve <name> [--max-restarts N] -- <command> [args...]",
+// the launch command of a supervised server. Claude Code runs it in place of
+// the server; stdout carries only the server's JSON-RPC messages.
+func Serve(args []string) error {
+	opts, err := parseServeArgs(args)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return mcpsupervisor.Run(ctx, opts, os.Stdin, os.Stdout)
+}
+
+func parseServeArgs(args []string) (mcpsupervisor.Options, error) {
+	var opts mcpsupervisor.Options
+	f := cli.New("mcp serve", "mcp serve <name> [--max-restarts N] -- <command> [args...]")
+	f.IntVar(&opts.MaxRestarts, "--max-restarts", "N", "Give up after N restarts in a row (default: 5)")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return opts, err
+	}
+	if f.Changed("--max-restarts") && opts.MaxRestarts < 1 {
+		return opts, fmt.Errorf("--max-restarts must be a positive number, got %d", opts.MaxRestarts)
+	}
+	opts.Name, opts.Command = positional[0], f.Rest()
+	if len(opts.Command) == 0 {
+		return opts, fmt.Errorf("usage: claude-workspace mcp serve <name> [--max-restarts N] -- <command> [args...]")
+	}
+	return opts, nil
+}
+
+// PS implements "mcp ps", listing the supervised servers of running Claude
+// Code sessions.
+func PS(args []string) error {
+	f := cli.New("mcp ps", "mcp ps")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	statuses, err := mcpsupervisor.List()
+	if err != nil {
+		return err
+	}
+	printStatuses(platform.Stdout(), statuses, time.Now())
+	return nil
+}
+
+func printStatuses(w io.Writer, statuses []mcpsupervisor.Status, now time.Time) {
+	platform.PrintBanner(w, "Supervised MCP Servers")
+	fmt.Fprintln(w)
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "  No supervised servers are running.")
+		fmt.Fprintln(w, "  Supervise a local server with: claude-workspace mcp update <name> --supervise")
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintf(w, "  %-20s %-8s %-8s %-13s %-9s %-8s %s\n", "NAME", "PID", "SERVER", "STATE", "RESTARTS", "UPTIME", "LAST EXIT")
+	for _, st := range statuses {
+		server, uptime := "-", "-"
+		if st.ChildPID != 0 {
+			server = strconv.Itoa(st.ChildPID)
+			uptime = formatUptime(now.Sub(st.ChildStarted))
+		}
+		lastExit := "-"
+		if st.LastExit != "" {
+			lastExit = fmt.Sprintf("%s (%s ago)", st.LastExit, formatUptime(now.Sub(st.LastExitTime)))
+		}
+		state := st.State
+		switch state {
+		case mcpsupervisor.StateRunning:
+			state = platform.Green(fmt.Sprintf("%-13s", state))
+		case mcpsupervisor.StateFailed, mcpsupervisor.StateUnresponsive:
+			state = platform.Red(fmt.Sprintf("%-13s", state))
+		default:
+			state = platform.Yellow(fmt.Sprintf("%-13s", state))
+		}
+		fmt.Fprintf(w, "  %-20s %-8d %-8s %s %-9d %-8s %s\n", st.Name, st.PID, server, state, st.Restarts, uptime, lastExit)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  Each Claude Code session runs its own copy of a server.")
+	fmt.Fprintln(w, "  Logs: claude-workspace mcp logs <name>")
+	fmt.Fprintln(w)
+}
+
+// formatUptime renders d compactly: 45s, 12m, 3h20m, 2d4h.
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// Logs implements "mcp logs <name> [--lines N] [--follow]".
+func Logs(args []string) error {
+	name, lines, follow, err := parseLogsArgs(args)
+	if err != nil {
+		return err
+	}
+	path, err := mcpsupervisor.LogPath(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no log for MCP server '%s' (only supervised servers are logged; see 'claude-workspace mcp update %s --supervise')", name, name)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	out := platform.Stdout()
+	offset, err := tailLines(out, f, lines)
+	if err != nil || !follow {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return followFile(ctx, out, f, offset)
+}
+
+func parseLogsArgs(args []string) (name string, lines int, follow bool, err error) {
+	lines = 50
+	f := cli.New("mcp logs", "mcp logs <name> [--lines N] [--follow]")
+	f.IntVar(&lines, "--lines", "N", "Number of lines to show")
+	f.Alias("-n", "--lines")
+	f.BoolVar(&follow, "--follow", "Keep printing new lines")
+	f.Alias("-f", "--follow")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", 0, false, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return "", 0, false, err
+	}
+	if lines < 0 {
+		return "", 0, false, fmt.Errorf("--lines must be 0 or more, got %d", lines)
+	}
+	return positional[0], lines, follow, nil
+}
+
+// tailLines writes the last n lines of f to w and returns the offset of the
+// end of the file.
+func tailLines(w io.Writer, f *os.File, n int) (int64, error) {
+	var ring []string
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if line != "" && n > 0 {
+			if len(ring) == n {
+				ring = ring[1:]
+			}
+			ring = append(ring, line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return offset, err
+		}
+	}
+	for _, line := range ring {
+		io.WriteString(w, line)
+	}
+	return offset, nil
+}
+
+// followFile writes whatever is appended to f after offset until ctx is done.
+func followFile(ctx context.Context, w io.Writer, f *os.File, offset int64) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			offset = 0 // rotated or truncated
+		}
+		if info.Size() == offset {
+			continue
+		}
+		n, err := io.Copy(w, io.NewSectionReader(f, offset, info.Size()-offset))
+		offset += n
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// superviseEntry rewrites a stdio server entry to launch through "mcp serve",
+// or, with on false, restores the original command. It returns a description
+// of the change, or "" when the entry is already in the requested state.
+func superviseEntry(name string, entry map[string]interface{}, on bool) (string, error) {
+	command, _ := entry["command"].(string)
+	args := stringSlice(entry["args"])
+	inner, supervised := mcpsupervisor.Unwrap(args)
+	switch {
+	case on == supervised:
+		return "", nil
+	case on:
+		wrapped, err := mcpsupervisor.WrapCommand(name, append([]string{command}, args...))
+		if err != nil {
+			return "", err
+		}
+		setCommand(entry, wrapped)
+		return "supervised: restarted on crash, logged to ~/.claude-workspace/logs/mcp/" + name + ".log", nil
+	default:
+		setCommand(entry, inner)
+		return "supervised: no", nil
+	}
+}
+
+// checkSupervise rejects supervision where it cannot work: remote servers have
+// no process to supervise, and .mcp.json is shared, so it must not name the
+// path of this machine's claude-workspace binary.
+func checkSupervise(name, scope string, stdio bool) error {
+	if !stdio {
+		return fmt.Errorf("MCP server '%s' is remote; --supervise only applies to stdio servers", name)
+	}
+	if scope == scopeProject {
+		return fmt.Errorf("--supervise is not available for project-scoped servers: .mcp.json is shared and would embed this machine's claude-workspace path")
+	}
+	return nil
+}
+
+func setCommand(entry map[string]interface{}, command []string) {
+	entry["command"] = command[0]
+	args := make([]interface{}, len(command)-1)
+	for i, a := range command[1:] {
+		args[i] = a
+	}
+	entry["args"] = args
+}
+
+func stringSlice(v interface{}) []string {
+	raw, _ := v.([]interface{})
+	out := make([]string, 0, len(raw))
+	for _, a := range raw {
+		s, _ := a.(string)
+		out = append(out, s)
+	}
+	return out
+}
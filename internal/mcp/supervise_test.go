@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/mcpsupervisor"
+)
+
+func TestParseServeArgs(t *testing.T) {
+	opts, err := parseServeArgs([]string{"db", "--max-restarts", "3", "--", "npx", "-y", "--max-restarts", "pkg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Name != "db" || opts.MaxRestarts != 3 || !reflect.DeepEqual(opts.Command, []string{"npx", "-y", "--max-restarts", "pkg"}) {
+		t.Errorf("parseServeArgs = %+v", opts)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"db"},
+		{"db", "--"},
+		{"--", "npx"},
+		{"db", "extra", "--", "npx"},
+		{"db", "--max-restarts", "0", "--", "npx"},
+		{"db", "--bogus", "--", "npx"},
+	} {
+		if _, err := parseServeArgs(args); err == nil {
+			t.Errorf("parseServeArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestSuperviseEntry(t *testing.T) {
+	entry := map[string]interface{}{"command": "npx", "args": []interface{}{"-y", "pkg"}}
+
+	change, err := superviseEntry("db", entry, true)
+	if err != nil || !strings.HasPrefix(change, "supervised: restarted on crash") {
+		t.Fatalf("superviseEntry(on) = %q, %v", change, err)
+	}
+	command, ok := mcpsupervisor.Unwrap(stringSlice(entry["args"]))
+	if !ok || !reflect.DeepEqual(command, []string{"npx", "-y", "pkg"}) {
+		t.Errorf("wrapped args = %v", entry["args"])
+	}
+	if change, _ := superviseEntry("db", entry, true); change != "" {
+		t.Errorf("supervising twice reported %q", change)
+	}
+
+	if change, err := superviseEntry("db", entry, false); err != nil || change != "supervised: no" {
+		t.Fatalf("superviseEntry(off) = %q, %v", change, err)
+	}
+	if entry["command"] != "npx" || !reflect.DeepEqual(entry["args"], []interface{}{"-y", "pkg"}) {
+		t.Errorf("unwrapped entry = %v", entry)
+	}
+}
+
+func TestCheckTransport_Supervise(t *testing.T) {
+	on, off := true, false
+	stdio := map[string]interface{}{"command": "npx"}
+	remote := map[string]interface{}{"type": "http", "url": "https://example.com"}
+
+	if err := checkTransport(stdio, &updateConfig{Name: "db", Scope: scopeUser, Supervise: &on}); err != nil {
+		t.Errorf("supervise stdio server: unexpected error %v", err)
+	}
+	if err := checkTransport(stdio, &updateConfig{Name: "db", Scope: scopeProject, Supervise: &on}); err == nil {
+		t.Error("supervise project server: expected error")
+	}
+	if err := checkTransport(stdio, &updateConfig{Name: "db", Scope: scopeProject, Supervise: &off}); err != nil {
+		t.Errorf("unsupervise project server: unexpected error %v", err)
+	}
+	if err := checkTransport(remote, &updateConfig{Name: "api", Scope: scopeUser, Supervise: &on}); err == nil {
+		t.Error("supervise remote server: expected error")
+	}
+}
+
+func TestParseLogsArgs(t *testing.T) {
+	name, lines, follow, err := parseLogsArgs([]string{"db", "-n", "10", "--follow"})
+	if err != nil || name != "db" || lines != 10 || !follow {
+		t.Errorf("parseLogsArgs = %q, %d, %v, %v", name, lines, follow, err)
+	}
+	if _, lines, _, _ := parseLogsArgs([]string{"db"}); lines != 50 {
+		t.Errorf("default lines = %d, want 50", lines)
+	}
+	for _, args := range [][]string{{}, {"db", "--lines"}, {"db", "--lines", "x"}, {"db", "other"}} {
+		if _, _, _, err := parseLogsArgs(args); err == nil {
+			t.Errorf("parseLogsArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.log")
+	writeTestFile(t, path, "one\ntwo\nthree\nfour")
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var out bytes.Buffer
+	offset, err := tailLines(&out, f, 2)
+	if err != nil || out.String() != "three\nfour" || offset != 18 {
+		t.Errorf("tailLines = %q, %d, %v", out.String(), offset, err)
+	}
+}
+
+func TestPrintStatuses(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	printStatuses(&out, []mcpsupervisor.Status{{
+		Name: "db", PID: 4242, ChildPID: 4250, State: mcpsupervisor.StateRunning,
+		ChildStarted: now.Add(-3*time.Hour - 20*time.Minute), Restarts: 1,
+		LastExit: "exit status 1", LastExitTime: now.Add(-3 * time.Hour),
+	}}, now)
+	for _, want := range []string{"db", "4242", "4250", "running", "3h20m", "exit status 1 (3h0m ago)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	printStatuses(&out, nil, now)
+	if !strings.Contains(out.String(), "No supervised servers") {
+		t.Errorf("empty output = %q", out.String())
+	}
+}
@@ -0,0 +1,461 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// serverStore is the mcpServers section of one scope's config file, loaded for
+// in-place edits. User servers live at the top level of ~/.claude.json, local
+// servers under projects[<dir>] in the same file, and project servers in
+// ./.mcp.json.
+type serverStore struct {
+	scope      string
+	path       string
+	projectDir string
+	root       map[string]interface{}
+	servers    map[string]interface{}
+}
+
+// openServerStore loads the config file backing scope. A missing file yields an
+// empty store.
+func openServerStore(scope, home, projectDir string) (*serverStore, error) {
+	s := &serverStore{scope: scope, projectDir: projectDir}
+	switch scope {
+	case scopeUser, scopeLocal:
+		s.path = filepath.Join(home, ".claude.json")
+	case scopeProject:
+		s.path = filepath.Join(projectDir, ".mcp.json")
+	default:
+		return nil, fmt.Errorf("unknown scope %q (valid: local, project, user)", scope)
+	}
+
+	if platform.FileExists(s.path) {
+		if err := platform.ReadJSONFile(s.path, &s.root); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", s.path, err)
+		}
+	}
+	if s.root == nil {
+		s.root = make(map[string]interface{})
+	}
+
+	s.servers = scopeServers(s.root, scope, projectDir)
+	return s, nil
+}
+
+// scopeServers returns the mcpServers section for scope in root, the parsed
+// config file, or nil if it has none.
+func scopeServers(root map[string]interface{}, scope, projectDir string) map[string]interface{} {
+	parent := root
+	if scope == scopeLocal {
+		projects, _ := root["projects"].(map[string]interface{})
+		parent, _ = projects[projectDir].(map[string]interface{})
+	}
+	servers, _ := parent["mcpServers"].(map[string]interface{})
+	return servers
+}
+
+// lookup returns the config entry for name, or nil if the store has none.
+func (s *serverStore) lookup(name string) map[string]interface{} {
+	entry, _ := s.servers[name].(map[string]interface{})
+	return entry
+}
+
+// save writes the store's entry for name back to its config file. The file is
+// re-read under its lock first, so everything else in it, including changes
+// other commands made since the store was opened, is kept.
+func (s *serverStore) save(name string) error {
+	return platform.WithFileLock(s.path, func() error {
+		var root map[string]interface{}
+		if platform.FileExists(s.path) {
+			if err := platform.ReadJSONFile(s.path, &root); err != nil {
+				return fmt.Errorf("reading %s: %w", s.path, err)
+			}
+		}
+		servers := scopeServers(root, s.scope, s.projectDir)
+		if servers == nil {
+			return fmt.Errorf("MCP server '%s' was removed from %s while it was being updated", name, s.path)
+		}
+		servers[name] = s.servers[name]
+		if err := platform.WriteJSONFile(s.path, root); err != nil {
+			return fmt.Errorf("writing %s: %w", s.path, err)
+		}
+		return nil
+	})
+}
+
+// detectServerScope finds the scope that defines name for the current directory.
+func detectServerScope(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+	scope, err := findServerScope(name, home, cwd)
+	if err != nil {
+		return "", err
+	}
+	if scope == "" {
+		for _, s := range discoverManagedServers() {
+			if s.Name == name {
+				return "", fmt.Errorf("MCP server '%s' is managed by your organization and cannot be changed", name)
+			}
+		}
+		return "", fmt.Errorf("MCP server '%s' not found in user, local, or project config", name)
+	}
+	return scope, nil
+}
+
+// findServerScope returns the single editable scope that defines name, or ""
+// if none does. A server defined in more than one scope is ambiguous and must
+// be disambiguated with --scope.
+func findServerScope(name, home, projectDir string) (string, error) {
+	var found []string
+	for _, scope := range []string{scopeLocal, scopeProject, scopeUser} {
+		store, err := openServerStore(scope, home, projectDir)
+		if err != nil {
+			return "", err
+		}
+		if store.lookup(name) != nil {
+			found = append(found, scope)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("MCP server '%s' is defined in several scopes (%s); pass --scope to choose one", name, strings.Join(found, ", "))
+	}
+}
+
+type updateConfig struct {
+	authOpts
+	Name         string
+	Scope        string
+	McpURL       string
+	EnvVars      map[string]string
+	APIKeyEnvVar string
+
+	// Supervise is set by --supervise or --no-supervise; nil leaves the
+	// launch command alone.
+	Supervise *bool
+
+	scopeSet bool // --scope was given; otherwise the scope is auto-detected
+}
+
+func parseUpdateArgs(args []string) (*updateConfig, error) {
+	cfg := &updateConfig{
+		EnvVars: map[string]string{},
+	}
+	f := cli.New("mcp update", "mcp update <name> [options]")
+	f.Help = printMcpUpdateHelp
+	f.StringVar(&cfg.Scope, flagScope, "local|project|user", "Which config to change (default: auto-detected)")
+	f.StringVar(&cfg.McpURL, "--url", "url", "New URL (http/sse servers)")
+	f.Func("--env", "KEY=VALUE", "Set an environment variable (repeatable)", envFlag(cfg.EnvVars))
+	f.StringVar(&cfg.APIKeyEnvVar, "--api-key", "ENV_VAR_NAME", "Prompt for a new API key")
+	f.Func(flagSupervise, "", "Launch through 'mcp serve'", superviseFlag(&cfg.Supervise, true))
+	f.Func("--no-supervise", "", "Launch the server directly again", superviseFlag(&cfg.Supervise, false))
+	cfg.addFlags(f)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) == 0 {
+		printMcpUpdateHelp(platform.Stdout())
+		return nil, fmt.Errorf("server name is required")
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return nil, err
+	}
+	cfg.Name = positional[0]
+	cfg.scopeSet = f.Changed(flagScope)
+	cfg.implyOAuth()
+
+	if cfg.ClientID != "" || cfg.PromptClientSecret {
+		return nil, fmt.Errorf("OAuth client settings cannot be changed in place; use 'mcp remove' and 'mcp add' instead")
+	}
+	for _, header := range cfg.Headers {
+		if _, _, ok := splitHeader(header); !ok {
+			return nil, fmt.Errorf("--header expects 'Key: Value', got %q", header)
+		}
+	}
+	if cfg.McpURL == "" && len(cfg.EnvVars) == 0 && cfg.APIKeyEnvVar == "" && len(cfg.Headers) == 0 && !cfg.PromptBearer && !cfg.UseOAuth && cfg.Supervise == nil {
+		printMcpUpdateHelp(platform.Stdout())
+		return nil, fmt.Errorf("nothing to update")
+	}
+
+	return cfg, nil
+}
+
+// superviseFlag returns the handler of --supervise (on) or --no-supervise,
+// which set *p to whether the server is supervised.
+func superviseFlag(p **bool, on bool) func(string) error {
+	return func(v string) error {
+		given, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("--supervise and --no-supervise take no value, got %q", v)
+		}
+		supervise := given == on
+		*p = &supervise
+		return nil
+	}
+}
+
+// splitHeader splits "Key: Value" into its trimmed parts.
+func splitHeader(header string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(header, ":")
+	key = strings.TrimSpace(key)
+	return key, strings.TrimSpace(value), ok && key != ""
+}
+
+// isRemoteEntry reports whether a server entry uses the http or sse transport.
+func isRemoteEntry(entry map[string]interface{}) bool {
+	typ, _ := entry["type"].(string)
+	_, hasURL := entry["url"]
+	return typ == transportHTTP || typ == transportSSE || hasURL
+}
+
+// checkTransport rejects URL and header changes for stdio servers, and
+// supervision changes for remote ones.
+func checkTransport(entry map[string]interface{}, cfg *updateConfig) error {
+	if !isRemoteEntry(entry) && (cfg.McpURL != "" || len(cfg.Headers) > 0 || cfg.PromptBearer || cfg.UseOAuth) {
+		return fmt.Errorf("MCP server '%s' uses the stdio transport; --url, --header, --bearer, and --oauth only apply to http/sse servers", cfg.Name)
+	}
+	if cfg.Supervise != nil && *cfg.Supervise {
+		return checkSupervise(cfg.Name, cfg.Scope, !isRemoteEntry(entry))
+	}
+	if cfg.Supervise != nil && isRemoteEntry(entry) {
+		return fmt.Errorf("MCP server '%s' is remote; --no-supervise only applies to stdio servers", cfg.Name)
+	}
+	return nil
+}
+
+// applyUpdate merges the requested changes into a server entry and returns a
+// masked description of each change.
+func applyUpdate(entry map[string]interface{}, cfg *updateConfig) []string {
+	var changes []string
+	if cfg.McpURL != "" {
+		entry["url"] = cfg.McpURL
+		changes = append(changes, "url: "+cfg.McpURL)
+	}
+
+	if len(cfg.Headers) > 0 {
+		headers, _ := entry["headers"].(map[string]interface{})
+		if headers == nil {
+			headers = make(map[string]interface{})
+		}
+		for _, header := range cfg.Headers {
+			key, value, _ := splitHeader(header)
+			headers[key] = value
+			display := value
+			if strings.EqualFold(key, "authorization") {
+				display = "****"
+			}
+			changes = append(changes, fmt.Sprintf("header: %s: %s", key, display))
+		}
+		entry["headers"] = headers
+	}
+
+	if len(cfg.EnvVars) > 0 {
+		env, _ := entry["env"].(map[string]interface{})
+		if env == nil {
+			env = make(map[string]interface{})
+		}
+		keys := make([]string, 0, len(cfg.EnvVars))
+		for key := range cfg.EnvVars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			env[key] = cfg.EnvVars[key]
+			display := "****"
+			if strings.HasPrefix(cfg.EnvVars[key], "${") {
+				display = cfg.EnvVars[key]
+			}
+			changes = append(changes, fmt.Sprintf("env: %s=%s", key, display))
+		}
+		entry["env"] = env
+	}
+
+	return changes
+}
+
+// Update changes the URL, headers, or env vars of an existing MCP server, or
+// rotates its API key or Bearer token, by editing the config file of the scope
+// that defines it. Secrets are prompted with masked input and never written to
+// .mcp.json.
+func Update(args []string) error {
+	cfg, err := parseUpdateArgs(args)
+	if err != nil {
+		return err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+
+	if !cfg.scopeSet {
+		if cfg.Scope, err = detectServerScope(cfg.Name); err != nil {
+			return err
+		}
+	}
+
+	store, err := openServerStore(cfg.Scope, home, cwd)
+	if err != nil {
+		return err
+	}
+	entry := store.lookup(cfg.Name)
+	if entry == nil {
+		return fmt.Errorf("MCP server '%s' not found in %s config (%s)", cfg.Name, cfg.Scope, store.path)
+	}
+	if err := checkTransport(entry, cfg); err != nil {
+		return err
+	}
+
+	if cfg.Scope == scopeProject {
+		if cfg.PromptBearer {
+			return fmt.Errorf("refusing to write a Bearer token to .mcp.json; use --header 'Authorization: Bearer ${VAR}' instead")
+		}
+		if cfg.UseOAuth {
+			return fmt.Errorf("refusing to write an OAuth token to .mcp.json; authenticate via '/mcp' in Claude Code instead")
+		}
+		if cfg.APIKeyEnvVar != "" {
+			cfg.EnvVars[cfg.APIKeyEnvVar] = "${" + cfg.APIKeyEnvVar + "}"
+		}
+	}
+
+	var stored []string
+	if cfg.Scope != scopeProject {
+		if cfg.APIKeyEnvVar != "" {
+			keyValue, err := promptAPIKeyValue(cfg.Name, cfg.APIKeyEnvVar)
+			if err != nil {
+				return err
+			}
+			if slices.Contains(wrappedSecretNames(entry), cfg.APIKeyEnvVar) {
+				secretStore, err := openSecretStore()
+				if err != nil {
+					return err
+				}
+				if err := secretStore.Set(cfg.APIKeyEnvVar, keyValue); err != nil {
+					return err
+				}
+				stored = append(stored, fmt.Sprintf("secret: %s (%s credential store)", cfg.APIKeyEnvVar, secretStore.Backend()))
+			} else {
+				cfg.EnvVars[cfg.APIKeyEnvVar] = keyValue
+			}
+		}
+		if err := cfg.promptCredentials(cfg.Name); err != nil {
+			return err
+		}
+		if cfg.UseOAuth {
+			mcpURL := cfg.McpURL
+			if mcpURL == "" {
+				mcpURL, _ = entry["url"].(string)
+			}
+			if err := cfg.renewToken(cfg.Name, mcpURL); err != nil {
+				return err
+			}
+		}
+	}
+
+	changes := append(stored, applyUpdate(entry, cfg)...)
+	if cfg.Supervise != nil {
+		change, err := superviseEntry(cfg.Name, entry, *cfg.Supervise)
+		if err != nil {
+			return err
+		}
+		if change != "" {
+			changes = append(changes, change)
+		}
+	}
+
+	out := platform.Stdout()
+	fmt.Fprintf(out, "Updating MCP server '%s' (scope: %s)...\n", cfg.Name, cfg.Scope)
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change)
+	}
+
+	if len(changes) > len(stored) {
+		if err := store.save(cfg.Name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\n%s\n", platform.Green(fmt.Sprintf("MCP server '%s' updated.", cfg.Name)))
+	fmt.Fprintln(out, "Restart Claude Code sessions, then run '/mcp' to verify the connection.")
+	if cfg.Scope == scopeProject && cfg.APIKeyEnvVar != "" {
+		fmt.Fprintln(out, "\n  NOTE: .mcp.json now references ${"+cfg.APIKeyEnvVar+"}.")
+		fmt.Fprintln(out, "  Team members must set it in their own environment:")
+		fmt.Fprintf(out, "    export %s=<value>\n", cfg.APIKeyEnvVar)
+	}
+	return nil
+}
+
+func printMcpUpdateHelp(w io.Writer) {
+	fmt.Fprint(w, `Usage: claude-workspace mcp update <name> [options]
+
+Change an existing MCP server without removing and re-adding it.
+
+Options:
+  --url <url>                   Replace the server URL (http/sse servers)
+  --header 'Key: Value'         Set or replace an HTTP header (repeatable)
+  --env KEY=VALUE               Set an environment variable (repeatable, visible)
+  --api-key ENV_VAR_NAME        Rotate an API key (masked input), stored as env var
+  --bearer                      Rotate the Bearer token (masked input)
+  --oauth                       Renew the OAuth access token with the saved
+                                refresh token, or sign in again
+  --device, --no-browser        Sign in with a device code, or print the sign-in
+                                URL instead of opening a browser
+  --supervise                   Launch a stdio server through 'mcp serve', which
+                                restarts it when it crashes and logs its stderr
+  --no-supervise                Launch the server directly again
+  --scope local|project|user    Which config to edit (default: the scope the
+                                server is configured in)
+
+Security:
+  - --api-key and --bearer use masked input (characters not shown)
+  - --api-key rotates the credential store entry for servers launched
+    through 'claude-workspace secrets exec'
+  - For project-scoped servers, --api-key writes a ${VAR} reference to
+    .mcp.json instead of the secret, and --bearer is rejected
+
+Examples:
+
+  # Rotate an API key
+  claude-workspace mcp update brave-search --api-key BRAVE_API_KEY
+
+  # Point a remote server at a new URL
+  claude-workspace mcp update company --url https://mcp-gateway.company.com/v2
+
+  # Rotate a Bearer token
+  claude-workspace mcp update my-api --bearer
+
+  # Renew an expired OAuth token
+  claude-workspace mcp update sentry --oauth
+
+  # Change an environment variable
+  claude-workspace mcp update postgres --env PGSSLMODE=require
+
+  # Restart a flaky server automatically
+  claude-workspace mcp update postgres --supervise
+`)
+}
@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenServerStore(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(home, ".claude.json"), `{
+		"mcpServers": {"github": {"type": "http", "url": "https://example.com/mcp"}},
+		"projects": {"`+project+`": {"mcpServers": {"postgres": {"command": "npx"}}}}
+	}`)
+	writeTestFile(t, filepath.Join(project, ".mcp.json"), `{"mcpServers": {"sentry": {"type": "http"}}}`)
+
+	tests := []struct {
+		scope string
+		want  string
+		miss  string
+	}{
+		{scope: scopeUser, want: "github", miss: "postgres"},
+		{scope: scopeLocal, want: "postgres", miss: "github"},
+		{scope: scopeProject, want: "sentry", miss: "github"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			store, err := openServerStore(tt.scope, home, project)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if store.lookup(tt.want) == nil {
+				t.Errorf("lookup(%q) = nil, want entry", tt.want)
+			}
+			if store.lookup(tt.miss) != nil {
+				t.Errorf("lookup(%q) found entry in %s scope", tt.miss, tt.scope)
+			}
+		})
+	}
+
+	if _, err := openServerStore("managed", home, project); err == nil {
+		t.Error("expected error for non-editable scope")
+	}
+}
+
+func TestOpenServerStore_MissingFile(t *testing.T) {
+	store, err := openServerStore(scopeUser, t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.lookup("anything") != nil {
+		t.Error("expected empty store")
+	}
+}
+
+func TestServerStore_SavePreservesOtherKeys(t *testing.T) {
+	home := t.TempDir()
+	path := filepath.Join(home, ".claude.json")
+	writeTestFile(t, path, `{"theme": "dark", "mcpServers": {"github": {"type": "http", "url": "https://old"}}}`)
+
+	store, err := openServerStore(scopeUser, home, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.lookup("github")["url"] = "https://new"
+	// Another command changes the file after the store was opened.
+	writeTestFile(t, path, `{"theme": "dark", "mcpServers": {"github": {"type": "http", "url": "https://old"}, "linear": {"type": "http", "url": "https://linear"}}}`)
+	if err := store.save("github"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Theme      string `json:"theme"`
+		MCPServers map[string]struct {
+			URL string `json:"url"`
+		} `json:"mcpServers"`
+	}
+	if err := platform.ReadJSONFile(path, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Theme != "dark" {
+		t.Errorf("theme = %q, want %q", got.Theme, "dark")
+	}
+	if got.MCPServers["github"].URL != "https://new" {
+		t.Errorf("url = %q, want %q", got.MCPServers["github"].URL, "https://new")
+	}
+	if got.MCPServers["linear"].URL != "https://linear" {
+		t.Errorf("a server added concurrently was lost: %+v", got.MCPServers)
+	}
+}
+
+func TestFindServerScope(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	writeTestFile(t, filepath.Join(home, ".claude.json"), `{"mcpServers": {"github": {}, "shared": {}}}`)
+	writeTestFile(t, filepath.Join(project, ".mcp.json"), `{"mcpServers": {"sentry": {}, "shared": {}}}`)
+
+	tests := []struct {
+		name    string
+		server  string
+		want    string
+		wantErr bool
+	}{
+		{name: "user only", server: "github", want: scopeUser},
+		{name: "project only", server: "sentry", want: scopeProject},
+		{name: "not found", server: "missing", want: ""},
+		{name: "ambiguous", server: "shared", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findServerScope(tt.server, home, project)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("findServerScope(%q) = %q, want %q", tt.server, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUpdateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		check   func(t *testing.T, cfg *updateConfig)
+	}{
+		{name: "empty args returns error", args: []string{}, wantErr: true},
+		{name: "help flag returns error", args: []string{"--help"}, wantErr: true},
+		{name: "no changes returns error", args: []string{"github"}, wantErr: true},
+		{name: "client id rejected", args: []string{"github", "--client-id", "abc"}, wantErr: true},
+		{name: "client secret rejected", args: []string{"github", "--client-secret"}, wantErr: true},
+		{name: "unknown flag rejected", args: []string{"github", "--bogus"}, wantErr: true},
+		{name: "malformed env rejected", args: []string{"github", "--env", "NOVALUE"}, wantErr: true},
+		{name: "malformed header rejected", args: []string{"github", "--header", "NoColon"}, wantErr: true},
+		{
+			name: "url and scope",
+			args: []string{"github", "--url", "https://new.example.com/mcp", "--scope", scopeProject},
+			check: func(t *testing.T, cfg *updateConfig) {
+				if cfg.McpURL != "https://new.example.com/mcp" {
+					t.Errorf("McpURL = %q", cfg.McpURL)
+				}
+				if cfg.Scope != scopeProject || !cfg.scopeSet {
+					t.Errorf("Scope = %q (set=%v), want %q", cfg.Scope, cfg.scopeSet, scopeProject)
+				}
+			},
+		},
+		{
+			name: "oauth renews the token",
+			args: []string{"github", "--device"},
+			check: func(t *testing.T, cfg *updateConfig) {
+				if !cfg.UseOAuth || !cfg.Device {
+					t.Errorf("UseOAuth = %v, Device = %v", cfg.UseOAuth, cfg.Device)
+				}
+			},
+		},
+		{
+			name: "env, header, api key, and bearer",
+			args: []string{"api", "--env", "A=1", "--header", "X-Key: v", "--api-key", "API_KEY", "--bearer"},
+			check: func(t *testing.T, cfg *updateConfig) {
+				if cfg.EnvVars["A"] != "1" {
+					t.Errorf("EnvVars[A] = %q, want %q", cfg.EnvVars["A"], "1")
+				}
+				if len(cfg.Headers) != 1 || cfg.Headers[0] != "X-Key: v" {
+					t.Errorf("Headers = %v", cfg.Headers)
+				}
+				if cfg.APIKeyEnvVar != "API_KEY" || !cfg.PromptBearer {
+					t.Errorf("APIKeyEnvVar = %q, PromptBearer = %v", cfg.APIKeyEnvVar, cfg.PromptBearer)
+				}
+				if cfg.scopeSet {
+					t.Error("scopeSet = true without --scope")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseUpdateArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
+
+func TestCheckTransport(t *testing.T) {
+	stdio := map[string]interface{}{"command": "npx"}
+	remote := map[string]interface{}{"type": "http", "url": "https://example.com"}
+
+	if err := checkTransport(stdio, &updateConfig{Name: "db", EnvVars: map[string]string{"A": "1"}}); err != nil {
+		t.Errorf("env change on stdio server: unexpected error %v", err)
+	}
+	if err := checkTransport(stdio, &updateConfig{Name: "db", McpURL: "https://x"}); err == nil {
+		t.Error("url change on stdio server: expected error")
+	}
+	if err := checkTransport(stdio, &updateConfig{Name: "db", authOpts: authOpts{PromptBearer: true}}); err == nil {
+		t.Error("bearer on stdio server: expected error")
+	}
+	if err := checkTransport(remote, &updateConfig{Name: "api", McpURL: "https://x"}); err != nil {
+		t.Errorf("url change on http server: unexpected error %v", err)
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	entry := map[string]interface{}{
+		"type":    "http",
+		"url":     "https://old.example.com",
+		"headers": map[string]interface{}{"Authorization": "Bearer old", "X-Team": "a"},
+	}
+	cfg := &updateConfig{
+		Name:     "api",
+		McpURL:   "https://new.example.com",
+		authOpts: authOpts{Headers: []string{"Authorization: Bearer new"}},
+		EnvVars:  map[string]string{"TOKEN": "secret", "REF": "${REF}"},
+	}
+
+	changes := applyUpdate(entry, cfg)
+
+	if entry["url"] != "https://new.example.com" {
+		t.Errorf("url = %v", entry["url"])
+	}
+	headers := entry["headers"].(map[string]interface{})
+	if headers["Authorization"] != "Bearer new" {
+		t.Errorf("Authorization = %v, want %q", headers["Authorization"], "Bearer new")
+	}
+	if headers["X-Team"] != "a" {
+		t.Errorf("X-Team header was not preserved: %v", headers["X-Team"])
+	}
+	env := entry["env"].(map[string]interface{})
+	if env["TOKEN"] != "secret" {
+		t.Errorf("env TOKEN = %v", env["TOKEN"])
+	}
+
+	want := []string{
+		"url: https://new.example.com",
+		"header: Authorization: ****",
+		"env: REF=${REF}",
+		"env: TOKEN=****",
+	}
+	assertSliceEqual(t, changes, want)
+}
@@ -0,0 +1,95 @@
+package mcpgateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// maxAuditResult is the largest response recorded in full in the audit log;
+// larger ones are recorded by size only.
+const maxAuditResult = 8 << 10
+
+// Audit outcomes.
+const (
+	outcomeOK           = "ok"
+	outcomeError        = "error"
+	outcomeDenied       = "denied"
+	outcomeUnauthorized = "unauthorized"
+)
+
+// auditRecord is one line of the audit log: a request a client made and what
+// the gateway answered.
+type auditRecord struct {
+	Time        time.Time       `json:"time"`
+	Client      string          `json:"client"`
+	Token       string          `json:"token,omitempty"` // fingerprint, never the token
+	Method      string          `json:"method,omitempty"`
+	Server      string          `json:"server,omitempty"`
+	Tool        string          `json:"tool,omitempty"`
+	Arguments   json.RawMessage `json:"arguments,omitempty"`
+	Outcome     string          `json:"outcome"`
+	Error       string          `json:"error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	ResultBytes int             `json:"resultBytes,omitempty"`
+	DurationMS  int64           `json:"durationMs"`
+}
+
+// auditLog appends records as JSON lines. Values that look like secrets are
+// redacted with the same rules as "sessions redact" before they are written.
+type auditLog struct {
+	mu       sync.Mutex
+	w        io.WriteCloser
+	redactor *sessions.Redactor
+}
+
+// DefaultAuditPath returns ~/.claude-workspace/logs/mcp-gateway/audit.jsonl.
+func DefaultAuditPath() (string, error) {
+	dir, err := platform.LogDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcp-gateway", "audit.jsonl"), nil
+}
+
+func openAuditLog(path string) (*auditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return &auditLog{w: f, redactor: sessions.NewRedactor(nil)}, nil
+}
+
+func (a *auditLog) record(r auditRecord) {
+	if len(r.Result) > maxAuditResult {
+		r.ResultBytes = len(r.Result)
+		r.Result = nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.w.Write(append(a.redactor.Line(line), '\n'))
+}
+
+func (a *auditLog) Close() error { return a.w.Close() }
+
+// fingerprint identifies a token in the audit log without revealing it.
+func fingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,231 @@
+package mcpgateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// protocolVersion is the MCP revision the gateway speaks, to clients and to
+// the servers behind it.
+const protocolVersion = "2025-06-18"
+
+// stopTimeout is how long a server may take to exit after its stdin is closed
+// before it is killed.
+const stopTimeout = 5 * time.Second
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// JSON-RPC error codes used by the gateway.
+const (
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternal       = -32603
+)
+
+// rpcMessage is any JSON-RPC message: a request, notification, or response.
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// backend is a stdio MCP server the gateway runs and talks to as a client.
+// Calls may be made from any goroutine.
+type backend struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	mu      sync.Mutex // guards writes to stdin, pending, and nextID
+	pending map[int64]chan rpcMessage
+	nextID  int64
+	done    chan struct{} // closed when the server exits
+	exitErr error
+}
+
+// startBackend launches a server and performs the initialize handshake.
+// The server's stderr is appended to logw.
+func startBackend(ctx context.Context, s Server, version string, logw io.Writer) (*backend, error) {
+	if len(s.Command) == 0 {
+		return nil, fmt.Errorf("MCP server %s has no command", s.Name)
+	}
+	cmd := exec.Command(s.Command[0], s.Command[1:]...)
+	cmd.Env = os.Environ()
+	for k, v := range s.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stderr = logw
+	// Launchers like npx start the real server as their own child; a process
+	// group lets a kill reach both.
+	platform.SetProcessGroup(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting MCP server %s: %w", s.Name, err)
+	}
+	b := &backend{
+		name:    s.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan rpcMessage),
+		done:    make(chan struct{}),
+	}
+	go b.read(stdout)
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "claude-workspace-gateway", "version": version},
+	})
+	if _, err := b.call(ctx, "initialize", params); err != nil {
+		b.close()
+		return nil, fmt.Errorf("initializing MCP server %s: %w", s.Name, err)
+	}
+	if err := b.write(rpcMessage{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		b.close()
+		return nil, fmt.Errorf("initializing MCP server %s: %w", s.Name, err)
+	}
+	return b, nil
+}
+
+// call sends a request and waits for its response. A JSON-RPC error from the
+// server is returned as an *rpcError.
+func (b *backend) call(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, error) {
+	reply := make(chan rpcMessage, 1)
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.pending[id] = reply
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, id)
+		b.mu.Unlock()
+	}()
+
+	rawID, _ := json.Marshal(id)
+	if err := b.write(rpcMessage{JSONRPC: "2.0", ID: rawID, Method: method, Params: params}); err != nil {
+		return nil, err
+	}
+	select {
+	case m := <-reply:
+		if m.Error != nil {
+			return nil, m.Error
+		}
+		return m.Result, nil
+	case <-b.done:
+		return nil, fmt.Errorf("MCP server %s exited (%v)", b.name, b.exitErr)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *backend) write(m rpcMessage) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.done:
+		return fmt.Errorf("MCP server %s exited (%v)", b.name, b.exitErr)
+	default:
+	}
+	if _, err := b.stdin.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing to MCP server %s: %w", b.name, err)
+	}
+	return nil
+}
+
+// read dispatches the server's responses until it exits. Requests from the
+// server are answered directly: the gateway offers clients no sampling or
+// roots, so only ping is supported.
+func (b *backend) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var m rpcMessage
+		if json.Unmarshal(scanner.Bytes(), &m) != nil {
+			continue
+		}
+		switch {
+		case m.Method != "" && m.ID != nil:
+			reply := rpcMessage{JSONRPC: "2.0", ID: m.ID}
+			if m.Method == "ping" {
+				reply.Result = json.RawMessage(`{}`)
+			} else {
+				reply.Error = &rpcError{Code: codeMethodNotFound, Message: "method not supported by the gateway: " + m.Method}
+			}
+			b.write(reply)
+		case m.Method == "" && m.ID != nil:
+			var id int64
+			if json.Unmarshal(m.ID, &id) != nil {
+				continue
+			}
+			b.mu.Lock()
+			reply := b.pending[id]
+			b.mu.Unlock()
+			if reply != nil {
+				reply <- m
+			}
+		}
+	}
+	err := b.cmd.Wait()
+	b.mu.Lock()
+	b.exitErr = err
+	if b.exitErr == nil {
+		b.exitErr = fmt.Errorf("exit status 0")
+	}
+	close(b.done)
+	b.mu.Unlock()
+}
+
+// running reports whether the server has not exited.
+func (b *backend) running() bool {
+	select {
+	case <-b.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// close closes the server's stdin and waits for it to exit, killing it if it
+// does not.
+func (b *backend) close() {
+	b.mu.Lock()
+	b.stdin.Close()
+	b.mu.Unlock()
+	select {
+	case <-b.done:
+	case <-time.After(stopTimeout):
+		platform.KillProcessGroup(b.cmd.Process)
+		<-b.done
+	}
+}
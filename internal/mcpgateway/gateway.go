@@ -0,0 +1,384 @@
+// Package mcpgateway serves the locally configured stdio MCP servers through
+// a single HTTP MCP endpoint. The gateway runs each server as a child process
+// and acts as its client. Tools are exposed as "<server>__<tool>". Every
+// request is checked against a set of bearer tokens and a tool allowlist, and
+// recorded in an audit log, so a platform team can offer one controlled
+// endpoint instead of having every developer register each server.
+package mcpgateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Endpoint is the path the gateway serves MCP on.
+	Endpoint = "/mcp"
+
+	// separator joins a server name and a tool name in the tools the gateway
+	// exposes, e.g. "github__create_issue".
+	separator = "__"
+
+	maxRequestSize = 4 << 20
+	listTimeout    = 30 * time.Second
+)
+
+// supportedVersions are the protocol revisions a client may negotiate. Both
+// use the Streamable HTTP transport.
+var supportedVersions = map[string]bool{"2025-03-26": true, protocolVersion: true}
+
+// Server is a stdio MCP server to run behind the gateway.
+type Server struct {
+	Name    string
+	Command []string
+	Env     map[string]string
+}
+
+// Options configures a gateway.
+type Options struct {
+	Servers []Server
+
+	// Allow holds glob patterns over "<server>__<tool>", such as
+	// "github__get_*". When empty, every tool is allowed.
+	Allow []string
+
+	// Tokens are the accepted bearer tokens. At least one is required unless
+	// NoAuth is set.
+	Tokens []string
+	NoAuth bool
+
+	AuditPath string // defaults to DefaultAuditPath
+	Version   string // reported to clients and servers
+}
+
+// Gateway is an http.Handler serving MCP at Endpoint.
+type Gateway struct {
+	opts     Options
+	tokens   [][sha256.Size]byte
+	backends map[string]*backend
+	order    []string // server names in the order they were configured
+	audit    *auditLog
+	logs     []io.Closer
+
+	// Failed maps each server that could not be started to the reason.
+	Failed map[string]error
+}
+
+// New validates opts, opens the audit log, and starts the servers. A server
+// that fails to start is recorded in Failed and left out; New fails only when
+// none start.
+func New(ctx context.Context, opts Options) (*Gateway, error) {
+	if len(opts.Tokens) == 0 && !opts.NoAuth {
+		return nil, fmt.Errorf("no bearer tokens configured")
+	}
+	for _, pattern := range opts.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --allow pattern %q: %w", pattern, err)
+		}
+	}
+	if len(opts.Servers) == 0 {
+		return nil, fmt.Errorf("no stdio MCP servers to serve")
+	}
+	if opts.AuditPath == "" {
+		p, err := DefaultAuditPath()
+		if err != nil {
+			return nil, err
+		}
+		opts.AuditPath = p
+	}
+	audit, err := openAuditLog(opts.AuditPath)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gateway{
+		opts:     opts,
+		backends: make(map[string]*backend),
+		audit:    audit,
+		Failed:   make(map[string]error),
+	}
+	for _, token := range opts.Tokens {
+		g.tokens = append(g.tokens, sha256.Sum256([]byte(token)))
+	}
+
+	logDir := filepath.Dir(opts.AuditPath)
+	for _, s := range opts.Servers {
+		if strings.Contains(s.Name, separator) {
+			g.Failed[s.Name] = fmt.Errorf("server names used by the gateway cannot contain %q", separator)
+			continue
+		}
+		logf, err := os.OpenFile(filepath.Join(logDir, s.Name+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			g.Failed[s.Name] = err
+			continue
+		}
+		g.logs = append(g.logs, logf)
+		b, err := startBackend(ctx, s, opts.Version, logf)
+		if err != nil {
+			g.Failed[s.Name] = err
+			continue
+		}
+		g.backends[s.Name] = b
+		g.order = append(g.order, s.Name)
+	}
+	if len(g.backends) == 0 {
+		g.Close()
+		return nil, fmt.Errorf("no MCP server could be started")
+	}
+	return g, nil
+}
+
+// Servers returns the names of the servers the gateway started.
+func (g *Gateway) Servers() []string { return g.order }
+
+// AuditPath returns the path of the audit log.
+func (g *Gateway) AuditPath() string { return g.opts.AuditPath }
+
+// Close stops the servers and closes the logs.
+func (g *Gateway) Close() {
+	var wg sync.WaitGroup
+	for _, b := range g.backends {
+		wg.Add(1)
+		go func(b *backend) {
+			defer wg.Done()
+			b.close()
+		}(b)
+	}
+	wg.Wait()
+	for _, l := range g.logs {
+		l.Close()
+	}
+	g.audit.Close()
+}
+
+// ServeHTTP implements the Streamable HTTP transport for single JSON-RPC
+// messages. Responses are always plain JSON; the gateway never opens an SSE
+// stream, so GET is refused as the specification allows.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Endpoint {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	start := time.Now()
+	rec := auditRecord{Time: start, Client: r.RemoteAddr}
+	token, ok := g.authorize(r)
+	if !ok {
+		rec.Outcome = outcomeUnauthorized
+		g.audit.record(rec)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="claude-workspace"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if token != "" {
+		rec.Token = fingerprint(token)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var m rpcMessage
+	if err := json.Unmarshal(body, &m); err != nil || m.JSONRPC != "2.0" {
+		writeJSON(w, http.StatusBadRequest, rpcMessage{JSONRPC: "2.0", Error: &rpcError{Code: codeInvalidRequest, Message: "expected a single JSON-RPC 2.0 message"}})
+		return
+	}
+	if m.ID == nil || m.Method == "" {
+		// Notifications and responses need no answer.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	rec.Method = m.Method
+	result, err := g.dispatch(r.Context(), m, &rec)
+	reply := rpcMessage{JSONRPC: "2.0", ID: m.ID, Result: result}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeInternal, Message: err.Error()}
+		}
+		reply.Result = nil
+		reply.Error = rpcErr
+		rec.Error = rpcErr.Message
+		if rec.Outcome == "" {
+			rec.Outcome = outcomeError
+		}
+	}
+	if rec.Outcome == "" {
+		rec.Outcome = outcomeOK
+	}
+	rec.DurationMS = time.Since(start).Milliseconds()
+	g.audit.record(rec)
+	writeJSON(w, http.StatusOK, reply)
+}
+
+// authorize checks the request's bearer token and returns it.
+func (g *Gateway) authorize(r *http.Request) (string, bool) {
+	if g.opts.NoAuth {
+		return "", true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(token))
+	match := 0
+	for _, t := range g.tokens {
+		match |= subtle.ConstantTimeCompare(sum[:], t[:])
+	}
+	return token, match == 1
+}
+
+func (g *Gateway) dispatch(ctx context.Context, m rpcMessage, rec *auditRecord) (json.RawMessage, error) {
+	switch m.Method {
+	case "initialize":
+		return g.initialize(m.Params)
+	case "ping":
+		return json.RawMessage(`{}`), nil
+	case "tools/list":
+		return g.listTools(ctx)
+	case "tools/call":
+		return g.callTool(ctx, m.Params, rec)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not supported by the gateway: " + m.Method}
+	}
+}
+
+func (g *Gateway) initialize(params json.RawMessage) (json.RawMessage, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	json.Unmarshal(params, &p)
+	version := protocolVersion
+	if supportedVersions[p.ProtocolVersion] {
+		version = p.ProtocolVersion
+	}
+	return json.Marshal(map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+		"serverInfo":      map[string]string{"name": "claude-workspace-gateway", "version": g.opts.Version},
+		"instructions":    "Tools are named <server>__<tool> after the MCP server that provides them: " + strings.Join(g.order, ", ") + ".",
+	})
+}
+
+// listTools collects the allowed tools of every running server. A server
+// that fails to answer is left out rather than failing the whole list.
+func (g *Gateway) listTools(ctx context.Context) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+	lists := make([][]map[string]json.RawMessage, len(g.order))
+	var wg sync.WaitGroup
+	for i, name := range g.order {
+		b := g.backends[name]
+		if !b.running() {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, b *backend) {
+			defer wg.Done()
+			lists[i], _ = serverTools(ctx, b)
+		}(i, b)
+	}
+	wg.Wait()
+
+	tools := []map[string]json.RawMessage{}
+	for i, name := range g.order {
+		for _, tool := range lists[i] {
+			var toolName string
+			if json.Unmarshal(tool["name"], &toolName) != nil || !g.allowed(name, toolName) {
+				continue
+			}
+			tool["name"], _ = json.Marshal(name + separator + toolName)
+			tools = append(tools, tool)
+		}
+	}
+	return json.Marshal(map[string]interface{}{"tools": tools})
+}
+
+// serverTools pages through a server's tools/list.
+func serverTools(ctx context.Context, b *backend) ([]map[string]json.RawMessage, error) {
+	var tools []map[string]json.RawMessage
+	var cursor string
+	for {
+		params := json.RawMessage(`{}`)
+		if cursor != "" {
+			params, _ = json.Marshal(map[string]string{"cursor": cursor})
+		}
+		raw, err := b.call(ctx, "tools/list", params)
+		if err != nil {
+			return tools, err
+		}
+		var page struct {
+			Tools      []map[string]json.RawMessage `json:"tools"`
+			NextCursor string                       `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return tools, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// callTool routes a tools/call to the server named by the tool's prefix.
+func (g *Gateway) callTool(ctx context.Context, params json.RawMessage, rec *auditRecord) (json.RawMessage, error) {
+	var p map[string]json.RawMessage
+	var name string
+	if json.Unmarshal(params, &p) != nil || json.Unmarshal(p["name"], &name) != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "tools/call requires a tool name"}
+	}
+	rec.Arguments = p["arguments"]
+	server, tool, ok := strings.Cut(name, separator)
+	rec.Server, rec.Tool = server, tool
+	b := g.backends[server]
+	if !ok || b == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + name}
+	}
+	if !g.allowed(server, tool) {
+		rec.Outcome = outcomeDenied
+		return nil, &rpcError{Code: codeInvalidParams, Message: "tool " + name + " is not allowed by this gateway"}
+	}
+	p["name"], _ = json.Marshal(tool)
+	forward, _ := json.Marshal(p)
+	result, err := b.call(ctx, "tools/call", forward)
+	rec.Result = result
+	return result, err
+}
+
+func (g *Gateway) allowed(server, tool string) bool {
+	if len(g.opts.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range g.opts.Allow {
+		if ok, _ := path.Match(pattern, server+separator+tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
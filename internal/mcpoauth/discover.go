@@ -0,0 +1,225 @@
+// Package mcpoauth signs in to remote MCP servers with OAuth as the MCP
+// authorization spec describes. It discovers the authorization server from
+// the MCP server, registers a client when none is given, and obtains a token
+// with the authorization code flow (PKCE and a localhost redirect) or, on
+// machines without a browser, the device authorization flow. Tokens can be
+// renewed later from the saved Grant.
+package mcpoauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// httpClient sends every request made during sign-in.
+var httpClient = platform.HTTPClient(30 * time.Second)
+
+// Metadata is the part of an authorization server's RFC 8414 metadata the
+// flows use.
+type Metadata struct {
+	Issuer                      string   `json:"issuer,omitempty"`
+	AuthorizationEndpoint       string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint               string   `json:"token_endpoint"`
+	RegistrationEndpoint        string   `json:"registration_endpoint,omitempty"`
+	DeviceAuthorizationEndpoint string   `json:"device_authorization_endpoint,omitempty"`
+	ScopesSupported             []string `json:"scopes_supported,omitempty"`
+}
+
+// Server is a remote MCP server and the authorization server protecting it.
+type Server struct {
+	URL string
+	// Resource is the RFC 8707 resource indicator sent with authorization and
+	// token requests, so the token is only valid for this server.
+	Resource string
+	// Scopes are requested at sign-in when the server publishes them.
+	Scopes   []string
+	Metadata Metadata
+}
+
+// protectedResource is RFC 9728 protected resource metadata.
+type protectedResource struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported"`
+}
+
+var resourceMetadataParam = regexp.MustCompile(`resource_metadata="([^"]+)"`)
+
+// Discover finds the authorization server for the MCP server at mcpURL. It
+// follows the protected resource metadata the server advertises, and falls
+// back to the server's own origin as used by earlier revisions of the spec.
+func Discover(ctx context.Context, mcpURL string) (*Server, error) {
+	u, err := url.Parse(mcpURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid MCP server URL %q", mcpURL)
+	}
+	s := &Server{URL: mcpURL, Resource: canonicalResource(u)}
+
+	issuer := origin(u)
+	prm, err := fetchProtectedResource(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if prm != nil {
+		if len(prm.AuthorizationServers) > 0 {
+			issuer = prm.AuthorizationServers[0]
+		}
+		if prm.Resource != "" {
+			s.Resource = prm.Resource
+		}
+		s.Scopes = prm.ScopesSupported
+	}
+
+	meta, err := fetchServerMetadata(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case meta != nil:
+		s.Metadata = *meta
+	case prm != nil:
+		return nil, fmt.Errorf("authorization server %s publishes no OAuth metadata", issuer)
+	default:
+		// Servers written against the 2025-03-26 spec may serve the default
+		// endpoints without metadata.
+		s.Metadata = Metadata{
+			AuthorizationEndpoint: issuer + "/authorize",
+			TokenEndpoint:         issuer + "/token",
+			RegistrationEndpoint:  issuer + "/register",
+		}
+	}
+	if s.Metadata.TokenEndpoint == "" {
+		return nil, fmt.Errorf("authorization server %s has no token endpoint", issuer)
+	}
+	return s, nil
+}
+
+// fetchProtectedResource returns the metadata named in the server's 401
+// challenge, or else the first found at a well-known location, or nil.
+func fetchProtectedResource(ctx context.Context, u *url.URL) (*protectedResource, error) {
+	var candidates []string
+	if link := probeChallenge(ctx, u.String()); link != "" {
+		candidates = append(candidates, link)
+	}
+	base := origin(u) + "/.well-known/oauth-protected-resource"
+	if p := strings.TrimSuffix(u.EscapedPath(), "/"); p != "" {
+		candidates = append(candidates, base+p)
+	}
+	candidates = append(candidates, base)
+
+	for _, c := range candidates {
+		var prm protectedResource
+		found, err := getJSON(ctx, c, &prm)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return &prm, nil
+		}
+	}
+	return nil, nil
+}
+
+// probeChallenge makes an unauthenticated request to the MCP server and returns
+// the resource_metadata URL from its WWW-Authenticate header, if any.
+func probeChallenge(ctx context.Context, mcpURL string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mcpURL, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return ""
+	}
+	for _, h := range resp.Header.Values("WWW-Authenticate") {
+		if m := resourceMetadataParam.FindStringSubmatch(h); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// fetchServerMetadata tries the RFC 8414 and OpenID Connect discovery
+// locations for issuer and returns nil if none answers.
+func fetchServerMetadata(ctx context.Context, issuer string) (*Metadata, error) {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid authorization server %q", issuer)
+	}
+	o := origin(u)
+	var candidates []string
+	if p := strings.TrimSuffix(u.EscapedPath(), "/"); p != "" {
+		candidates = []string{
+			o + "/.well-known/oauth-authorization-server" + p,
+			o + "/.well-known/openid-configuration" + p,
+			o + p + "/.well-known/openid-configuration",
+		}
+	} else {
+		candidates = []string{
+			o + "/.well-known/oauth-authorization-server",
+			o + "/.well-known/openid-configuration",
+		}
+	}
+	for _, c := range candidates {
+		var meta Metadata
+		found, err := getJSON(ctx, c, &meta)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return &meta, nil
+		}
+	}
+	return nil, nil
+}
+
+// getJSON decodes the JSON document at u into v. A non-200 response is
+// reported as not found rather than as an error, since discovery tries several
+// locations.
+func getJSON(ctx context.Context, u string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("fetching %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return false, fmt.Errorf("parsing %s: %w", u, err)
+	}
+	return true, nil
+}
+
+func origin(u *url.URL) string {
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
+
+// canonicalResource returns the MCP server URL in the canonical form used as
+// its resource indicator: lower-case scheme and host, no fragment.
+func canonicalResource(u *url.URL) string {
+	c := *u
+	c.Scheme = strings.ToLower(c.Scheme)
+	c.Host = strings.ToLower(c.Host)
+	c.Fragment = ""
+	return c.String()
+}
@@ -0,0 +1,458 @@
+package mcpoauth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clientName is how this tool identifies itself when registering a client.
+const clientName = "claude-workspace"
+
+// deviceGrantType is the RFC 8628 grant type for polling the token endpoint.
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// pollUnit scales the device flow's polling interval; tests shorten it.
+var pollUnit = time.Second
+
+// Client is an OAuth client registered with the authorization server.
+type Client struct {
+	ID     string `json:"client_id"`
+	Secret string `json:"client_secret,omitempty"`
+	// AuthMethod is the token endpoint auth method; "client_secret_post"
+	// sends the secret in the form, anything else uses HTTP Basic.
+	AuthMethod string `json:"token_endpoint_auth_method,omitempty"`
+}
+
+// Token is a token endpoint response.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	// Expiry is when the access token expires, or zero if the server did not
+	// say.
+	Expiry time.Time `json:"-"`
+}
+
+// Grant is what is kept after sign-in to renew the token without the user.
+type Grant struct {
+	Client
+	TokenEndpoint string `json:"token_endpoint"`
+	Resource      string `json:"resource,omitempty"`
+	RefreshToken  string `json:"refresh_token"`
+}
+
+// Options configures Login.
+type Options struct {
+	// Client is a pre-registered client. When its ID is empty, a client is
+	// registered with the authorization server.
+	Client Client
+	// Device uses the device authorization flow instead of a browser redirect.
+	Device bool
+	// CallbackPort fixes the localhost port of the redirect URI, for clients
+	// registered with one. 0 picks a free port.
+	CallbackPort int
+	// OpenURL opens a URL in the browser. When it is nil or fails, the URL is
+	// printed for the user to open.
+	OpenURL func(string) error
+	// Paste, when set, is read for the address the browser was redirected to,
+	// for users whose browser runs on another machine.
+	Paste io.Reader
+	Out   io.Writer
+}
+
+// tokenError is an OAuth error response.
+type tokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *tokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// Login signs the user in and returns the token, along with the grant to save
+// for renewing it (nil when the server issued no refresh token).
+func Login(ctx context.Context, s *Server, opts Options) (*Token, *Grant, error) {
+	var (
+		tok    *Token
+		client Client
+		err    error
+	)
+	if opts.Device {
+		tok, client, err = s.deviceLogin(ctx, opts)
+	} else {
+		tok, client, err = s.codeLogin(ctx, opts)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok.RefreshToken == "" {
+		return tok, nil, nil
+	}
+	return tok, &Grant{Client: client, TokenEndpoint: s.Metadata.TokenEndpoint, Resource: s.Resource, RefreshToken: tok.RefreshToken}, nil
+}
+
+// Refresh obtains a new access token with the grant's refresh token. If the
+// server rotates refresh tokens, g is updated and must be saved again.
+func (g *Grant) Refresh(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {g.RefreshToken}}
+	if g.Resource != "" {
+		form.Set("resource", g.Resource)
+	}
+	tok, err := requestToken(ctx, g.TokenEndpoint, g.Client, form)
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken != "" {
+		g.RefreshToken = tok.RefreshToken
+	}
+	return tok, nil
+}
+
+// register registers a public client (RFC 7591).
+func (s *Server) register(ctx context.Context, redirectURIs, grantTypes []string) (Client, error) {
+	if s.Metadata.RegistrationEndpoint == "" {
+		return Client{}, fmt.Errorf("the authorization server does not support client registration; pass the --client-id of a registered app")
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"client_name":                clientName,
+		"redirect_uris":              redirectURIs,
+		"grant_types":                grantTypes,
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Metadata.RegistrationEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return Client{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Client{}, fmt.Errorf("registering client: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return Client{}, fmt.Errorf("registering client: %s", responseError(resp.Status, data))
+	}
+	var c Client
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return Client{}, fmt.Errorf("registering client: invalid response")
+	}
+	return c, nil
+}
+
+// codeLogin runs the authorization code flow with PKCE, receiving the code on
+// a localhost listener.
+func (s *Server) codeLogin(ctx context.Context, opts Options) (*Token, Client, error) {
+	if s.Metadata.AuthorizationEndpoint == "" {
+		return nil, Client{}, fmt.Errorf("the authorization server has no authorization endpoint")
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.CallbackPort))
+	if err != nil {
+		return nil, Client{}, fmt.Errorf("listening for the sign-in redirect: %w", err)
+	}
+	defer ln.Close()
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/callback", ln.Addr().(*net.TCPAddr).Port)
+
+	client := opts.Client
+	if client.ID == "" {
+		if client, err = s.register(ctx, []string{redirectURI}, []string{"authorization_code", "refresh_token"}); err != nil {
+			return nil, Client{}, err
+		}
+	}
+
+	verifier := randomString(32)
+	state := randomString(16)
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ID},
+		"redirect_uri":          {redirectURI},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"state":                 {state},
+	}
+	if s.Resource != "" {
+		q.Set("resource", s.Resource)
+	}
+	if len(s.Scopes) > 0 {
+		q.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	authURL := s.Metadata.AuthorizationEndpoint
+	if strings.Contains(authURL, "?") {
+		authURL += "&" + q.Encode()
+	} else {
+		authURL += "?" + q.Encode()
+	}
+
+	results := make(chan callbackResult, 2)
+	srv := &http.Server{Handler: callbackHandler(state, results), ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	if opts.OpenURL != nil && opts.OpenURL(authURL) == nil {
+		fmt.Fprintf(opts.Out, "Opened your browser to sign in. If it did not open, visit:\n\n  %s\n\n", authURL)
+	} else {
+		fmt.Fprintf(opts.Out, "Open this URL in a browser to sign in:\n\n  %s\n\n", authURL)
+	}
+	if opts.Paste != nil {
+		fmt.Fprintln(opts.Out, "If the browser runs on another machine, the redirect to 127.0.0.1 will fail to load;")
+		fmt.Fprintln(opts.Out, "paste the address it was redirected to here instead.")
+		go readPastedRedirect(opts.Paste, state, results)
+	}
+	fmt.Fprintln(opts.Out, "Waiting for sign-in...")
+
+	var res callbackResult
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, Client{}, fmt.Errorf("sign-in not completed: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return nil, Client{}, res.err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	if s.Resource != "" {
+		form.Set("resource", s.Resource)
+	}
+	tok, err := requestToken(ctx, s.Metadata.TokenEndpoint, client, form)
+	return tok, client, err
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// callbackHandler receives the authorization server's redirect.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		code, err := parseCallback(r.URL.Query(), state)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			fmt.Fprintf(w, "<html><body><h3>Sign-in failed</h3><p>%s</p></body></html>", html.EscapeString(err.Error()))
+		} else {
+			fmt.Fprint(w, "<html><body><h3>Signed in</h3><p>You can close this window and return to the terminal.</p></body></html>")
+		}
+		select {
+		case results <- callbackResult{code, err}:
+		default:
+		}
+	})
+}
+
+// readPastedRedirect reads a redirect URL typed into the terminal.
+func readPastedRedirect(r io.Reader, state string, results chan<- callbackResult) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.RawQuery == "" {
+			continue
+		}
+		code, err := parseCallback(u.Query(), state)
+		select {
+		case results <- callbackResult{code, err}:
+		default:
+		}
+		return
+	}
+}
+
+// parseCallback returns the authorization code from redirect parameters.
+func parseCallback(q url.Values, state string) (string, error) {
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("sign-in failed: %s", (&tokenError{Code: e, Description: q.Get("error_description")}).Error())
+	}
+	if q.Get("state") != state {
+		return "", fmt.Errorf("sign-in failed: the redirect's state does not match this sign-in")
+	}
+	code := q.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("sign-in failed: the redirect has no authorization code")
+	}
+	return code, nil
+}
+
+// deviceAuthorization is an RFC 8628 device authorization response.
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// deviceLogin runs the device authorization flow: the user approves the sign-in
+// on any device while this one polls the token endpoint.
+func (s *Server) deviceLogin(ctx context.Context, opts Options) (*Token, Client, error) {
+	if s.Metadata.DeviceAuthorizationEndpoint == "" {
+		return nil, Client{}, fmt.Errorf("the authorization server does not support device sign-in; sign in with a browser instead")
+	}
+	client := opts.Client
+	if client.ID == "" {
+		var err error
+		if client, err = s.register(ctx, nil, []string{deviceGrantType, "refresh_token"}); err != nil {
+			return nil, Client{}, err
+		}
+	}
+
+	form := url.Values{}
+	if s.Resource != "" {
+		form.Set("resource", s.Resource)
+	}
+	if len(s.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	data, status, err := postForm(ctx, s.Metadata.DeviceAuthorizationEndpoint, client, form)
+	if err != nil {
+		return nil, Client{}, fmt.Errorf("starting device sign-in: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, Client{}, fmt.Errorf("starting device sign-in: %s", responseError(http.StatusText(status), data))
+	}
+	var da deviceAuthorization
+	if err := json.Unmarshal(data, &da); err != nil || da.DeviceCode == "" || da.VerificationURI == "" {
+		return nil, Client{}, fmt.Errorf("starting device sign-in: invalid response")
+	}
+
+	fmt.Fprintf(opts.Out, "To sign in, visit:\n\n  %s\n\nand enter the code:  %s\n\n", da.VerificationURI, da.UserCode)
+	if da.VerificationURIComplete != "" && opts.OpenURL != nil {
+		opts.OpenURL(da.VerificationURIComplete)
+	}
+	fmt.Fprintln(opts.Out, "Waiting for sign-in...")
+
+	interval := time.Duration(da.Interval) * pollUnit
+	if da.Interval <= 0 {
+		interval = 5 * pollUnit
+	}
+	expiresIn := da.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 600
+	}
+	deadline := time.Now().Add(time.Duration(expiresIn) * pollUnit)
+	poll := url.Values{"grant_type": {deviceGrantType}, "device_code": {da.DeviceCode}}
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, Client{}, fmt.Errorf("sign-in not completed: %w", ctx.Err())
+		}
+		tok, err := requestToken(ctx, s.Metadata.TokenEndpoint, client, poll)
+		var te *tokenError
+		switch {
+		case err == nil:
+			return tok, client, nil
+		case errors.As(err, &te) && te.Code == "authorization_pending":
+		case errors.As(err, &te) && te.Code == "slow_down":
+			interval += 5 * pollUnit
+		default:
+			return nil, Client{}, err
+		}
+		if time.Now().After(deadline) {
+			return nil, Client{}, fmt.Errorf("sign-in not completed: the code expired")
+		}
+	}
+}
+
+// requestToken posts a token request and returns the token, or the server's
+// OAuth error as a *tokenError.
+func requestToken(ctx context.Context, endpoint string, client Client, form url.Values) (*Token, error) {
+	data, status, err := postForm(ctx, endpoint, client, form)
+	if err != nil {
+		return nil, fmt.Errorf("requesting token: %w", err)
+	}
+	if status != http.StatusOK {
+		var te tokenError
+		if json.Unmarshal(data, &te) == nil && te.Code != "" {
+			return nil, &te
+		}
+		return nil, fmt.Errorf("requesting token: %s", responseError(http.StatusText(status), data))
+	}
+	var tok Token
+	if err := json.Unmarshal(data, &tok); err != nil || tok.AccessToken == "" {
+		return nil, fmt.Errorf("requesting token: invalid response")
+	}
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return nil, fmt.Errorf("requesting token: unsupported token type %q", tok.TokenType)
+	}
+	if tok.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	}
+	return &tok, nil
+}
+
+// postForm posts form to endpoint with the client's credentials.
+func postForm(ctx context.Context, endpoint string, client Client, form url.Values) ([]byte, int, error) {
+	form.Set("client_id", client.ID)
+	if client.Secret != "" && client.AuthMethod == "client_secret_post" {
+		form.Set("client_secret", client.Secret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if client.Secret != "" && client.AuthMethod != "client_secret_post" {
+		req.SetBasicAuth(url.QueryEscape(client.ID), url.QueryEscape(client.Secret))
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	return data, resp.StatusCode, err
+}
+
+// responseError describes a failed response, preferring an OAuth error body.
+func responseError(status string, body []byte) string {
+	var te tokenError
+	if json.Unmarshal(body, &te) == nil && te.Code != "" {
+		return te.Error()
+	}
+	return status
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,267 @@
+package mcpoauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAuthServer is an MCP server and authorization server in one, with just
+// enough of each to drive the flows.
+type fakeAuthServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu         sync.Mutex
+	challenges map[string]string // code -> PKCE challenge
+	pending    int               // device polls to answer with authorization_pending
+	refreshes  int
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	f := &fakeAuthServer{t: t, challenges: map[string]string{}}
+	mux := http.NewServeMux()
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer resource_metadata="`+f.URL+`/meta/prm"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/meta/prm", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(protectedResource{
+			Resource:             f.URL + "/mcp",
+			AuthorizationServers: []string{f.URL + "/tenant"},
+			ScopesSupported:      []string{"mcp:tools"},
+		})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server/tenant", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                      f.URL + "/tenant",
+			AuthorizationEndpoint:       f.URL + "/authorize",
+			TokenEndpoint:               f.URL + "/token",
+			RegistrationEndpoint:        f.URL + "/register",
+			DeviceAuthorizationEndpoint: f.URL + "/device",
+		})
+	})
+	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RedirectURIs []string `json:"redirect_uris"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"client_id":"client-%d"}`, len(req.RedirectURIs))
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("resource") != f.URL+"/mcp" || q.Get("scope") != "mcp:tools" || q.Get("code_challenge_method") != "S256" {
+			t.Errorf("authorize query = %s", q.Encode())
+		}
+		f.mu.Lock()
+		f.challenges["code-1"] = q.Get("code_challenge")
+		f.mu.Unlock()
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code=code-1&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"device_code":"dev-1","user_code":"ABCD-EFGH","verification_uri":"https://example.com/device","interval":1,"expires_in":60}`)
+	})
+	mux.HandleFunc("/token", f.token)
+	return f
+}
+
+func (f *fakeAuthServer) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fail := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":%q}`, code)
+	}
+	switch r.Form.Get("grant_type") {
+	case "authorization_code":
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if f.challenges[r.Form.Get("code")] != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			fail("invalid_grant")
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"access-1","token_type":"Bearer","refresh_token":"refresh-1","expires_in":3600}`)
+	case deviceGrantType:
+		if f.pending > 0 {
+			f.pending--
+			fail("authorization_pending")
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"access-device","token_type":"bearer"}`)
+	case "refresh_token":
+		if r.Form.Get("refresh_token") != "refresh-1" || r.Form.Get("client_id") != "client-1" {
+			fail("invalid_grant")
+			return
+		}
+		f.refreshes++
+		fmt.Fprint(w, `{"access_token":"access-2","token_type":"Bearer","refresh_token":"refresh-2"}`)
+	default:
+		fail("unsupported_grant_type")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	f := newFakeAuthServer(t)
+	s, err := Discover(context.Background(), f.URL+"/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Resource != f.URL+"/mcp" || s.Metadata.TokenEndpoint != f.URL+"/token" || strings.Join(s.Scopes, " ") != "mcp:tools" {
+		t.Errorf("Discover = %+v", s)
+	}
+
+	// A server with no metadata at all gets the default endpoints on its origin.
+	bare := httptest.NewServer(http.NotFoundHandler())
+	defer bare.Close()
+	s, err = Discover(context.Background(), bare.URL+"/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Metadata.AuthorizationEndpoint != bare.URL+"/authorize" || s.Metadata.TokenEndpoint != bare.URL+"/token" {
+		t.Errorf("fallback metadata = %+v", s.Metadata)
+	}
+
+	if _, err := Discover(context.Background(), "ftp://example.com"); err == nil {
+		t.Error("non-HTTP URL: expected error")
+	}
+}
+
+func TestLogin_AuthorizationCode(t *testing.T) {
+	f := newFakeAuthServer(t)
+	s, err := Discover(context.Background(), f.URL+"/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The "browser" follows the authorization redirect back to the listener.
+	openURL := func(u string) error {
+		go func() {
+			resp, err := http.Get(u)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	tok, grant, err := Login(ctx, s, Options{OpenURL: openURL, Out: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "access-1" || tok.Expiry.IsZero() {
+		t.Errorf("token = %+v", tok)
+	}
+	if grant == nil || grant.ID != "client-1" || grant.RefreshToken != "refresh-1" || grant.Resource != f.URL+"/mcp" {
+		t.Fatalf("grant = %+v", grant)
+	}
+
+	tok, err = grant.Refresh(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "access-2" || grant.RefreshToken != "refresh-2" {
+		t.Errorf("after refresh: token = %+v, grant = %+v", tok, grant)
+	}
+	if _, err := grant.Refresh(ctx); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("refresh with a used token = %v, want invalid_grant", err)
+	}
+}
+
+func TestLogin_PastedRedirect(t *testing.T) {
+	f := newFakeAuthServer(t)
+	s, err := Discover(context.Background(), f.URL+"/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The browser is elsewhere: fetch the authorization URL without following
+	// the redirect, and paste where it pointed.
+	pr, pw := io.Pipe()
+	openURL := func(u string) error {
+		go func() {
+			client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+			resp, err := client.Get(u)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			fmt.Fprintln(pw, resp.Header.Get("Location"))
+		}()
+		return fmt.Errorf("no browser")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var out strings.Builder
+	tok, _, err := Login(ctx, s, Options{OpenURL: openURL, Paste: pr, Out: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "access-1" || !strings.Contains(out.String(), "Open this URL") {
+		t.Errorf("token = %+v, output:\n%s", tok, out.String())
+	}
+}
+
+func TestLogin_Device(t *testing.T) {
+	defer func(u time.Duration) { pollUnit = u }(pollUnit)
+	pollUnit = time.Millisecond
+
+	f := newFakeAuthServer(t)
+	f.pending = 2
+	s, err := Discover(context.Background(), f.URL+"/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	tok, grant, err := Login(context.Background(), s, Options{Device: true, Out: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "access-device" || grant != nil {
+		t.Errorf("token = %+v, grant = %+v", tok, grant)
+	}
+	if !strings.Contains(out.String(), "ABCD-EFGH") || !strings.Contains(out.String(), "https://example.com/device") {
+		t.Errorf("output does not show the code and URL:\n%s", out.String())
+	}
+
+	s.Metadata.DeviceAuthorizationEndpoint = ""
+	if _, _, err := Login(context.Background(), s, Options{Device: true, Out: io.Discard}); err == nil {
+		t.Error("no device endpoint: expected error")
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"code=abc&state=s1", ""},
+		{"code=abc&state=other", "state"},
+		{"state=s1", "no authorization code"},
+		{"error=access_denied&error_description=User+declined&state=s1", "access_denied: User declined"},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		code, err := parseCallback(q, "s1")
+		if tt.want == "" {
+			if err != nil || code != "abc" {
+				t.Errorf("parseCallback(%s) = %q, %v", tt.query, code, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseCallback(%s) error = %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package mcpregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Auth types an organization catalog entry can declare.
+const (
+	AuthNone   = "none"
+	AuthOAuth  = "oauth"
+	AuthBearer = "bearer"
+)
+
+// maxCatalogSize bounds how much of a registry response is read.
+const maxCatalogSize = 1 << 20
+
+// Catalog is an organization-hosted list of approved MCP servers. A platform
+// team publishes it as JSON at an internal URL; users point claude-workspace at
+// it with "mcp registry set <url>".
+type Catalog struct {
+	Name    string          `json:"name,omitempty"`
+	Servers []CatalogServer `json:"servers"`
+}
+
+// CatalogServer is one approved server in an organization catalog. Exactly one
+// of URL (http/sse) or Command (stdio) is set.
+type CatalogServer struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Transport   string             `json:"transport,omitempty"` // stdio, http, or sse; inferred when empty
+	URL         string             `json:"url,omitempty"`
+	Command     string             `json:"command,omitempty"`
+	Args        []string           `json:"args,omitempty"`
+	Headers     map[string]string  `json:"headers,omitempty"` // static, non-secret headers
+	Env         map[string]EnvSpec `json:"env,omitempty"`
+	Auth        string             `json:"auth,omitempty"`  // none, oauth, or bearer
+	Scope       string             `json:"scope,omitempty"` // suggested scope; defaults to user
+}
+
+// EnvSpec describes an environment variable a catalog server requires.
+// Secret values are prompted with masked input; non-secret values use Default
+// when set and are prompted otherwise.
+type EnvSpec struct {
+	Description string `json:"description,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// ParseCatalog decodes and validates catalog JSON.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing registry catalog: %w", err)
+	}
+	seen := make(map[string]bool, len(c.Servers))
+	for i := range c.Servers {
+		s := &c.Servers[i]
+		if err := s.normalize(); err != nil {
+			return nil, err
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("registry catalog lists %q more than once", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	sort.Slice(c.Servers, func(i, j int) bool { return c.Servers[i].Name < c.Servers[j].Name })
+	return &c, nil
+}
+
+// normalize validates s and fills in the inferred transport, auth, and scope.
+func (s *CatalogServer) normalize() error {
+	if s.Name == "" {
+		return fmt.Errorf("registry catalog has a server without a name")
+	}
+	if (s.URL == "") == (s.Command == "") {
+		return fmt.Errorf("registry server %q must set exactly one of url or command", s.Name)
+	}
+	switch s.Transport {
+	case "":
+		s.Transport = string(TransportStdio)
+		if s.URL != "" {
+			s.Transport = string(TransportHTTP)
+		}
+	case "stdio", "http", "sse":
+		if (s.Transport == "stdio") != (s.Command != "") {
+			return fmt.Errorf("registry server %q: transport %q does not match its url/command", s.Name, s.Transport)
+		}
+	default:
+		return fmt.Errorf("registry server %q: unknown transport %q (valid: stdio, http, sse)", s.Name, s.Transport)
+	}
+	switch s.Auth {
+	case "":
+		s.Auth = AuthNone
+	case AuthNone, AuthOAuth, AuthBearer:
+	default:
+		return fmt.Errorf("registry server %q: unknown auth %q (valid: none, oauth, bearer)", s.Name, s.Auth)
+	}
+	if (s.Auth == AuthOAuth || s.Auth == AuthBearer) && s.URL == "" {
+		return fmt.Errorf("registry server %q: %s auth requires a url", s.Name, s.Auth)
+	}
+	if s.Scope == "" {
+		s.Scope = "user"
+	}
+	return nil
+}
+
+// Find returns the catalog entry named name, or nil.
+func (c *Catalog) Find(name string) *CatalogServer {
+	for i := range c.Servers {
+		if c.Servers[i].Name == name {
+			return &c.Servers[i]
+		}
+	}
+	return nil
+}
+
+// Names returns the server names in the catalog, sorted.
+func (c *Catalog) Names() []string {
+	names := make([]string, 0, len(c.Servers))
+	for _, s := range c.Servers {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// EnvNames returns the server's env var names, sorted.
+func (s *CatalogServer) EnvNames() []string {
+	names := make([]string, 0, len(s.Env))
+	for name := range s.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FetchCatalog reads a catalog from an http(s) URL or a local file path.
+func FetchCatalog(location string) (*Catalog, []byte, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		data, err = fetchURL(location)
+	} else {
+		data, err = os.ReadFile(strings.TrimPrefix(location, "file://"))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := ParseCatalog(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, data, nil
+}
+
+func fetchURL(url string) ([]byte, error) {
+	client := platform.HTTPClient(10 * time.Second)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("registry returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCatalogSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading registry response: %w", err)
+	}
+	return data, nil
+}
+
+// OrgRegistry is the locally saved registry configuration.
+type OrgRegistry struct {
+	URL       string `json:"url"`
+	FetchedAt string `json:"fetchedAt,omitempty"`
+}
+
+// orgDir returns ~/.claude-workspace, where registry state is stored.
+func orgDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace"), nil
+}
+
+func registryPaths() (configPath, cachePath string, err error) {
+	dir, err := orgDir()
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(dir, "mcp-registry.json"), filepath.Join(dir, "mcp-registry-cache.json"), nil
+}
+
+// LoadOrgRegistry returns the saved registry configuration, or nil if none is set.
+func LoadOrgRegistry() (*OrgRegistry, error) {
+	configPath, _, err := registryPaths()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", configPath, err)
+	}
+	var reg OrgRegistry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", configPath, err)
+	}
+	return &reg, nil
+}
+
+// SaveOrgRegistry records the registry URL and caches the catalog it served,
+// so "mcp add --from-registry" keeps working when the registry is unreachable.
+func SaveOrgRegistry(url string, catalogData []byte) error {
+	configPath, cachePath, err := registryPaths()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(configPath), err)
+	}
+	reg := OrgRegistry{URL: url, FetchedAt: time.Now().UTC().Format(time.RFC3339)}
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", configPath, err)
+	}
+	if err := os.WriteFile(cachePath, catalogData, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", cachePath, err)
+	}
+	return nil
+}
+
+// ClearOrgRegistry removes the saved registry configuration and cache.
+func ClearOrgRegistry() error {
+	configPath, cachePath, err := registryPaths()
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{configPath, cachePath} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// LoadOrgCatalog fetches the catalog from the saved registry, refreshing the
+// cache. When the registry cannot be reached, the cached copy is returned with
+// stale set to true. It returns an error if no registry is configured.
+func LoadOrgCatalog() (c *Catalog, reg *OrgRegistry, stale bool, err error) {
+	reg, err = LoadOrgRegistry()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if reg == nil {
+		return nil, nil, false, fmt.Errorf("no MCP registry configured (run: claude-workspace mcp registry set <url>)")
+	}
+
+	c, data, fetchErr := FetchCatalog(reg.URL)
+	if fetchErr == nil {
+		_ = SaveOrgRegistry(reg.URL, data)
+		return c, reg, false, nil
+	}
+
+	_, cachePath, err := registryPaths()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	cached, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, nil, false, fetchErr
+	}
+	c, err = ParseCatalog(cached)
+	if err != nil {
+		return nil, nil, false, fetchErr
+	}
+	return c, reg, true, nil
+}
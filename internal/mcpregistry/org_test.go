@@ -0,0 +1,141 @@
+package mcpregistry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCatalog = `{
+  "name": "Acme",
+  "servers": [
+    {"name": "sentry", "url": "https://mcp.sentry.dev/mcp", "auth": "oauth"},
+    {"name": "postgres", "command": "npx", "args": ["-y", "@bytebase/dbhub"],
+     "env": {"DATABASE_URL": {"secret": true}, "PGSSLMODE": {"default": "require"}}, "scope": "local"}
+  ]
+}`
+
+func TestParseCatalog(t *testing.T) {
+	c, err := ParseCatalog([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("ParseCatalog() error: %v", err)
+	}
+	if got := strings.Join(c.Names(), ","); got != "postgres,sentry" {
+		t.Errorf("Names() = %q, want sorted %q", got, "postgres,sentry")
+	}
+
+	sentry := c.Find("sentry")
+	if sentry == nil {
+		t.Fatal("Find(sentry) = nil")
+	}
+	if sentry.Transport != "http" || sentry.Scope != "user" {
+		t.Errorf("sentry transport/scope = %q/%q, want http/user", sentry.Transport, sentry.Scope)
+	}
+
+	pg := c.Find("postgres")
+	if pg.Transport != "stdio" || pg.Auth != AuthNone || pg.Scope != "local" {
+		t.Errorf("postgres transport/auth/scope = %q/%q/%q", pg.Transport, pg.Auth, pg.Scope)
+	}
+	if got := strings.Join(pg.EnvNames(), ","); got != "DATABASE_URL,PGSSLMODE" {
+		t.Errorf("EnvNames() = %q", got)
+	}
+	if c.Find("missing") != nil {
+		t.Error("Find(missing) should be nil")
+	}
+}
+
+func TestParseCatalog_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"invalid json", `{`, "parsing registry catalog"},
+		{"missing name", `{"servers":[{"url":"https://x"}]}`, "without a name"},
+		{"url and command", `{"servers":[{"name":"a","url":"https://x","command":"npx"}]}`, "exactly one of url or command"},
+		{"neither url nor command", `{"servers":[{"name":"a"}]}`, "exactly one of url or command"},
+		{"duplicate", `{"servers":[{"name":"a","url":"https://x"},{"name":"a","url":"https://y"}]}`, "more than once"},
+		{"unknown auth", `{"servers":[{"name":"a","url":"https://x","auth":"magic"}]}`, "unknown auth"},
+		{"oauth on stdio", `{"servers":[{"name":"a","command":"npx","auth":"oauth"}]}`, "requires a url"},
+		{"unknown transport", `{"servers":[{"name":"a","url":"https://x","transport":"ws"}]}`, "unknown transport"},
+		{"mismatched transport", `{"servers":[{"name":"a","command":"npx","transport":"http"}]}`, "does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCatalog([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCatalog() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchCatalog_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/registry.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testCatalog))
+	}))
+	defer srv.Close()
+
+	c, data, err := FetchCatalog(srv.URL + "/registry.json")
+	if err != nil {
+		t.Fatalf("FetchCatalog() error: %v", err)
+	}
+	if len(c.Servers) != 2 || string(data) != testCatalog {
+		t.Errorf("FetchCatalog() returned %d servers", len(c.Servers))
+	}
+
+	if _, _, err := FetchCatalog(srv.URL + "/missing.json"); err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
+
+func TestOrgRegistry_SaveLoadCacheFallback(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if reg, err := LoadOrgRegistry(); err != nil || reg != nil {
+		t.Fatalf("LoadOrgRegistry() with nothing saved = %v, %v; want nil, nil", reg, err)
+	}
+	if _, _, _, err := LoadOrgCatalog(); err == nil {
+		t.Error("LoadOrgCatalog() without a registry should fail")
+	}
+
+	catalogPath := filepath.Join(home, "catalog.json")
+	if err := os.WriteFile(catalogPath, []byte(testCatalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveOrgRegistry(catalogPath, []byte(testCatalog)); err != nil {
+		t.Fatalf("SaveOrgRegistry() error: %v", err)
+	}
+
+	c, reg, stale, err := LoadOrgCatalog()
+	if err != nil || stale {
+		t.Fatalf("LoadOrgCatalog() = stale %v, err %v", stale, err)
+	}
+	if reg.URL != catalogPath || c.Name != "Acme" {
+		t.Errorf("LoadOrgCatalog() = %q from %q", c.Name, reg.URL)
+	}
+
+	// With the source gone, the cached copy is served and marked stale.
+	if err := os.Remove(catalogPath); err != nil {
+		t.Fatal(err)
+	}
+	c, _, stale, err = LoadOrgCatalog()
+	if err != nil || !stale || c.Find("sentry") == nil {
+		t.Errorf("LoadOrgCatalog() fallback = stale %v, err %v", stale, err)
+	}
+
+	if err := ClearOrgRegistry(); err != nil {
+		t.Fatalf("ClearOrgRegistry() error: %v", err)
+	}
+	if reg, _ := LoadOrgRegistry(); reg != nil {
+		t.Error("registry still configured after ClearOrgRegistry()")
+	}
+}
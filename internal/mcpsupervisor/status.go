@@ -0,0 +1,192 @@
+package mcpsupervisor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Server states recorded in a status file.
+const (
+	StateRunning      = "running"
+	StateUnresponsive = "unresponsive"
+	StateRestarting   = "restarting"
+	StateFailed       = "failed"
+)
+
+// maxLogSize is the size at which a server log is rotated to <name>.log.1
+// when a supervisor starts.
+const maxLogSize = 5 << 20
+
+// failedTTL is how long the status of a supervisor that gave up is kept.
+const failedTTL = 24 * time.Hour
+
+// Status is the state of one supervisor, as shown by "mcp ps". Every Claude
+// Code session runs its own copy of a server, so a name can appear more than
+// once.
+type Status struct {
+	Name         string    `json:"name"`
+	PID          int       `json:"pid"`                // the supervisor
+	ChildPID     int       `json:"childPid,omitempty"` // the server; 0 while restarting
+	Command      []string  `json:"command"`
+	State        string    `json:"state"`
+	Started      time.Time `json:"started"`
+	ChildStarted time.Time `json:"childStarted"`
+	Restarts     int       `json:"restarts"`
+	LastExit     string    `json:"lastExit,omitempty"`
+	LastExitTime time.Time `json:"lastExitTime"`
+	LastPing     time.Time `json:"lastPing"`
+	Log          string    `json:"log"`
+}
+
+// RunDir returns ~/.claude-workspace/mcp, where supervisors keep their status
+// files.
+func RunDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "mcp"), nil
+}
+
+// LogPath returns the log file of the server called name.
+func LogPath(name string) (string, error) {
+	dir, err := platform.LogDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcp", name+".log"), nil
+}
+
+// openEventLog opens the server's log for appending, rotating it first if it
+// has grown past maxLogSize.
+func openEventLog(name string) (*eventLog, error) {
+	path, err := LogPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogSize {
+		os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening log: %w", err)
+	}
+	return &eventLog{path: path, f: f}, nil
+}
+
+// statusFile is a supervisor's status, written to <RunDir>/<name>.<pid>.json
+// whenever it changes. Failures to write it are ignored: it is informational.
+type statusFile struct {
+	path   string
+	status Status
+	kept   bool
+}
+
+func newStatusFile(opts Options, logPath string) *statusFile {
+	f := &statusFile{status: Status{
+		Name:    opts.Name,
+		PID:     os.Getpid(),
+		Command: opts.Command,
+		State:   StateRunning,
+		Started: time.Now(),
+		Log:     logPath,
+	}}
+	if dir, err := RunDir(); err == nil && os.MkdirAll(dir, 0755) == nil {
+		f.path = filepath.Join(dir, fmt.Sprintf("%s.%d.json", opts.Name, os.Getpid()))
+	}
+	return f
+}
+
+func (f *statusFile) update(change func(*Status)) {
+	change(&f.status)
+	if f.path != "" {
+		platform.WriteJSONFile(f.path, f.status)
+	}
+}
+
+// keep leaves the file in place when the supervisor exits, so "mcp ps" can
+// show that the server failed.
+func (f *statusFile) keep() { f.kept = true }
+
+func (f *statusFile) remove() {
+	if f.path != "" && !f.kept {
+		os.Remove(f.path)
+	}
+}
+
+// List returns the status of every supervisor, sorted by name and start time.
+// Files left by supervisors that no longer run are removed, except those of
+// supervisors that gave up, which are kept for a day.
+func List() ([]Status, error) {
+	dir, err := RunDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		var st Status
+		if err := platform.ReadJSONFile(path, &st); err != nil {
+			continue
+		}
+		if !platform.ProcessAlive(st.PID) {
+			if st.State != StateFailed || time.Since(st.Started) > failedTTL {
+				os.Remove(path)
+				continue
+			}
+			st.State = StateFailed
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].Started.Before(statuses[j].Started)
+	})
+	return statuses, nil
+}
+
+// WrapCommand returns a launch command that runs command under a supervisor.
+func WrapCommand(name string, command []string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locating claude-workspace binary: %w", err)
+	}
+	return append([]string{exe, "mcp", "serve", name, "--"}, command...), nil
+}
+
+// Unwrap returns the server command from the args of a launch command made by
+// WrapCommand (the args that follow the executable), or ok=false if args are
+// not a supervisor's.
+func Unwrap(args []string) (command []string, ok bool) {
+	if len(args) < 3 || args[0] != "mcp" || args[1] != "serve" {
+		return nil, false
+	}
+	for i, arg := range args[2:] {
+		if arg == "--" && i+3 < len(args) {
+			return args[i+3:], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,529 @@
+// Package mcpsupervisor runs a local stdio MCP server as a managed child
+// process. The supervisor sits between Claude Code and the server, passing
+// JSON-RPC messages through unchanged. When the server crashes or stops
+// answering pings, it is restarted and the session's initialize handshake is
+// replayed, so Claude Code keeps a working connection instead of a dead one.
+// Each supervisor records its state in a status file and appends the server's
+// stderr to a log under ~/.claude-workspace/logs/mcp/.
+package mcpsupervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const (
+	// DefaultMaxRestarts is how many crashes in a row are restarted before the
+	// supervisor gives up. A server that stays up for stableAfter resets the
+	// count.
+	DefaultMaxRestarts = 5
+	stableAfter        = time.Minute
+
+	defaultBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+
+	defaultPingInterval = 30 * time.Second
+	defaultPingTimeout  = 10 * time.Second
+	// maxMissedPings unanswered pings mark a hung server, which is restarted.
+	maxMissedPings = 2
+
+	// stopTimeout is how long a server may take to exit after its stdin is
+	// closed before it is killed.
+	stopTimeout = 5 * time.Second
+
+	// idPrefix marks the JSON-RPC IDs of requests the supervisor sends itself;
+	// their responses are not passed on to Claude Code.
+	idPrefix = "claude-workspace-"
+)
+
+// Options configures a supervisor.
+type Options struct {
+	Name        string
+	Command     []string
+	MaxRestarts int
+
+	// Backoff is the delay before the first restart; it doubles with each
+	// crash in a row, up to 30 seconds.
+	Backoff      time.Duration
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+}
+
+// message is the part of a JSON-RPC message the supervisor routes on.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+func parseMessage(line []byte) message {
+	var m message
+	_ = json.Unmarshal(line, &m)
+	if string(m.ID) == "null" {
+		m.ID = nil
+	}
+	return m
+}
+
+// child is one run of the server process.
+type child struct {
+	gen     int
+	cmd     *exec.Cmd
+	inbox   chan []byte // lines for the server's stdin
+	started time.Time
+	killed  string // why the supervisor killed it, if it did
+}
+
+// kill stops the server and any processes it started.
+func (c *child) kill() {
+	if c.cmd.Process != nil {
+		platform.KillProcessGroup(c.cmd.Process)
+	}
+}
+
+type childLine struct {
+	gen  int
+	line []byte
+}
+
+type childExit struct {
+	gen int
+	err error
+}
+
+type supervisor struct {
+	opts   Options
+	out    io.Writer
+	log    *eventLog
+	status *statusFile
+
+	gen      int
+	child    *child // nil while waiting to restart
+	ready    bool   // the server is up and past any replayed handshake
+	queue    [][]byte
+	pending  map[string]json.RawMessage // requests sent to the server and not yet answered
+	crashes  int
+	restarts int
+
+	initRequest []byte // the session's initialize request, replayed after a restart
+	initialized []byte // the session's initialized notification
+	probe       string // ID of the outstanding ping or replayed initialize
+	probeSent   time.Time
+	missed      int
+	nextID      int
+
+	fromChild chan childLine
+	exited    chan childExit
+}
+
+// Run supervises the server described by opts, relaying messages between it
+// and stdin/stdout, until stdin is closed, ctx is canceled, or the server
+// crashes more than opts.MaxRestarts times in a row.
+func Run(ctx context.Context, opts Options, stdin io.Reader, stdout io.Writer) error {
+	if opts.Name == "" || len(opts.Command) == 0 {
+		return fmt.Errorf("server name and command are required")
+	}
+	if opts.MaxRestarts == 0 {
+		opts.MaxRestarts = DefaultMaxRestarts
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.PingInterval == 0 {
+		opts.PingInterval = defaultPingInterval
+	}
+	if opts.PingTimeout == 0 {
+		opts.PingTimeout = defaultPingTimeout
+	}
+
+	log, err := openEventLog(opts.Name)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+	s := &supervisor{
+		opts:      opts,
+		out:       stdout,
+		log:       log,
+		status:    newStatusFile(opts, log.path),
+		pending:   make(map[string]json.RawMessage),
+		fromChild: make(chan childLine),
+		exited:    make(chan childExit),
+	}
+	defer s.status.remove()
+
+	in := make(chan []byte)
+	go readLines(stdin, in)
+
+	s.log.Printf("supervisor: starting %s", strings.Join(opts.Command, " "))
+	s.start()
+	tick := opts.PingInterval
+	if opts.PingTimeout < tick {
+		tick = opts.PingTimeout
+	}
+	ticker := time.NewTicker(tick / 2)
+	defer ticker.Stop()
+	var restart <-chan time.Time
+	for {
+		select {
+		case line, ok := <-in:
+			if !ok {
+				s.log.Printf("supervisor: client disconnected, stopping")
+				s.stop()
+				return nil
+			}
+			s.fromClient(line)
+		case l := <-s.fromChild:
+			if s.child != nil && l.gen == s.child.gen {
+				s.fromServer(l.line)
+			}
+		case e := <-s.exited:
+			if s.child == nil || e.gen != s.child.gen {
+				continue
+			}
+			delay, err := s.handleExit(e.err)
+			if err != nil {
+				return err
+			}
+			restart = time.After(delay)
+		case <-restart:
+			restart = nil
+			s.restarts++
+			s.start()
+		case <-ticker.C:
+			s.checkHealth()
+		case <-ctx.Done():
+			s.log.Printf("supervisor: %v, stopping", ctx.Err())
+			if s.child != nil {
+				s.child.kill()
+			}
+			return nil
+		}
+	}
+}
+
+// start launches a new server process. After a restart, the session's
+// initialize request is replayed first, and client messages are queued until
+// the server has answered it.
+func (s *supervisor) start() {
+	s.gen++
+	c := &child{gen: s.gen, inbox: make(chan []byte, 1024), started: time.Now()}
+	c.cmd = exec.Command(s.opts.Command[0], s.opts.Command[1:]...)
+	// Launchers like npx start the real server as their own child; a process
+	// group lets a kill reach both.
+	platform.SetProcessGroup(c.cmd)
+	s.child = c
+	s.ready = false
+	s.missed = 0
+	s.probe = ""
+	s.probeSent = c.started
+
+	stdin, err := c.cmd.StdinPipe()
+	if err == nil {
+		var stdout, stderr io.ReadCloser
+		if stdout, err = c.cmd.StdoutPipe(); err == nil {
+			if stderr, err = c.cmd.StderrPipe(); err == nil {
+				err = c.cmd.Start()
+			}
+		}
+		if err == nil {
+			go writeLines(c.inbox, stdin)
+			go s.watch(c.gen, c.cmd, stdout, stderr)
+		}
+	}
+	if err != nil {
+		s.log.Printf("supervisor: starting server: %v", err)
+		go func() { s.exited <- childExit{c.gen, err} }()
+		return
+	}
+	s.log.Printf("supervisor: server started (pid %d)", c.cmd.Process.Pid)
+	s.status.update(func(st *Status) {
+		st.ChildPID = c.cmd.Process.Pid
+		st.ChildStarted = c.started
+		st.State = StateRunning
+		st.Restarts = s.restarts
+	})
+
+	if s.initRequest == nil {
+		s.becomeReady()
+		return
+	}
+	s.log.Printf("supervisor: replaying initialize")
+	s.sendProbe(s.initRequest)
+}
+
+// watch relays a server's stdout and stderr and reports when it exits.
+func (s *supervisor) watch(gen int, cmd *exec.Cmd, stdout, stderr io.Reader) {
+	done := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			s.log.Printf("%s", scanner.Text())
+		}
+		close(done)
+	}()
+	lines := make(chan []byte)
+	go readLines(stdout, lines)
+	for line := range lines {
+		s.fromChild <- childLine{gen, line}
+	}
+	<-done
+	s.exited <- childExit{gen, cmd.Wait()}
+}
+
+// becomeReady sends the messages queued while the server was starting.
+func (s *supervisor) becomeReady() {
+	s.ready = true
+	queued := s.queue
+	s.queue = nil
+	for _, line := range queued {
+		s.send(line)
+	}
+}
+
+func (s *supervisor) fromClient(line []byte) {
+	m := parseMessage(line)
+	switch m.Method {
+	case "initialize":
+		s.initRequest = line
+	case "notifications/initialized":
+		s.initialized = line
+	}
+	if !s.ready {
+		s.queue = append(s.queue, line)
+		return
+	}
+	s.send(line)
+}
+
+// send writes a client message to the server, tracking requests so they can
+// be answered if the server dies before it replies.
+func (s *supervisor) send(line []byte) {
+	if m := parseMessage(line); m.ID != nil && m.Method != "" {
+		s.pending[string(m.ID)] = m.ID
+	}
+	s.child.inbox <- line
+}
+
+func (s *supervisor) fromServer(line []byte) {
+	m := parseMessage(line)
+	if m.ID != nil && m.Method == "" {
+		id := string(m.ID)
+		if strings.HasPrefix(id, `"`+idPrefix) {
+			if id == s.probe {
+				s.probeAnswered()
+			}
+			return
+		}
+		delete(s.pending, id)
+	}
+	s.write(line)
+}
+
+// probeAnswered handles the reply to a ping or to the replayed initialize.
+func (s *supervisor) probeAnswered() {
+	s.probe = ""
+	s.missed = 0
+	s.status.update(func(st *Status) {
+		st.State = StateRunning
+		st.LastPing = time.Now()
+	})
+	if !s.ready {
+		if s.initialized != nil {
+			s.child.inbox <- s.initialized
+		}
+		s.becomeReady()
+	}
+}
+
+// sendProbe sends a request of the supervisor's own, replacing its ID.
+func (s *supervisor) sendProbe(request []byte) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(request, &fields); err != nil {
+		return
+	}
+	s.nextID++
+	id, _ := json.Marshal(fmt.Sprintf("%s%d", idPrefix, s.nextID))
+	fields["id"] = id
+	line, _ := json.Marshal(fields)
+	s.probe = string(id)
+	s.probeSent = time.Now()
+	s.child.inbox <- line
+}
+
+// checkHealth pings a running server and restarts one that stopped
+// answering.
+func (s *supervisor) checkHealth() {
+	if s.child == nil || s.child.killed != "" {
+		return
+	}
+	if s.probe == "" {
+		// Pings start once the session has initialized; servers may reject
+		// requests before that.
+		if s.ready && s.initRequest != nil && time.Since(s.probeSent) >= s.opts.PingInterval {
+			s.sendProbe([]byte(`{"jsonrpc":"2.0","method":"ping"}`))
+		}
+		return
+	}
+	if time.Since(s.probeSent) < s.opts.PingTimeout {
+		return
+	}
+	s.missed++
+	s.probe = ""
+	s.log.Printf("supervisor: no response within %s (%d of %d)", s.opts.PingTimeout, s.missed, maxMissedPings)
+	s.status.update(func(st *Status) { st.State = StateUnresponsive })
+	if s.missed >= maxMissedPings {
+		s.child.killed = "not responding"
+		s.child.kill()
+		return
+	}
+	if s.ready {
+		s.sendProbe([]byte(`{"jsonrpc":"2.0","method":"ping"}`))
+	} else {
+		s.sendProbe(s.initRequest)
+	}
+}
+
+// handleExit answers the requests the server took down with it and decides
+// when to restart it. It returns an error when the server keeps crashing.
+func (s *supervisor) handleExit(err error) (time.Duration, error) {
+	c := s.child
+	s.child = nil
+	s.ready = false
+	close(c.inbox)
+
+	reason := "exited"
+	switch {
+	case c.killed != "":
+		reason = c.killed
+	case err != nil:
+		reason = err.Error()
+	}
+	s.log.Printf("supervisor: server stopped (%s)", reason)
+	for _, id := range s.pending {
+		s.writeError(id, fmt.Sprintf("MCP server %s stopped (%s) and is restarting; retry the request", s.opts.Name, reason))
+	}
+	s.pending = make(map[string]json.RawMessage)
+
+	if time.Since(c.started) >= stableAfter {
+		s.crashes = 0
+	}
+	s.crashes++
+	if s.crashes > s.opts.MaxRestarts {
+		for _, line := range s.queue {
+			if m := parseMessage(line); m.ID != nil && m.Method != "" {
+				s.writeError(m.ID, fmt.Sprintf("MCP server %s failed", s.opts.Name))
+			}
+		}
+		s.log.Printf("supervisor: giving up after %d crashes in a row", s.crashes)
+		s.status.update(func(st *Status) {
+			st.State = StateFailed
+			st.LastExit = reason
+			st.ChildPID = 0
+		})
+		s.status.keep()
+		return 0, fmt.Errorf("MCP server %s crashed %d times in a row (%s); see %s", s.opts.Name, s.crashes, reason, s.log.path)
+	}
+
+	delay := s.opts.Backoff << (s.crashes - 1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	s.log.Printf("supervisor: restarting in %s", delay)
+	s.status.update(func(st *Status) {
+		st.State = StateRestarting
+		st.LastExit = reason
+		st.LastExitTime = time.Now()
+		st.ChildPID = 0
+	})
+	return delay, nil
+}
+
+// stop closes the server's stdin and waits for it to exit, killing it if it
+// does not. Replies the server sends meanwhile are still passed on.
+func (s *supervisor) stop() {
+	if s.child == nil {
+		return
+	}
+	s.probe = "" // nothing more may be sent to the server
+	close(s.child.inbox)
+	timeout := time.After(stopTimeout)
+	for {
+		select {
+		case e := <-s.exited:
+			if e.gen == s.child.gen {
+				return
+			}
+		case l := <-s.fromChild:
+			if l.gen == s.child.gen {
+				s.fromServer(l.line)
+			}
+		case <-timeout:
+			s.child.kill()
+			timeout = nil
+		}
+	}
+}
+
+func (s *supervisor) write(line []byte) {
+	s.out.Write(append(line, '\n'))
+}
+
+func (s *supervisor) writeError(id json.RawMessage, msg string) {
+	line, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]interface{}{"code": -32603, "message": msg},
+	})
+	s.write(line)
+}
+
+// readLines sends each newline-terminated line of r to lines, without the
+// newline, and closes lines at EOF.
+func readLines(r io.Reader, lines chan<- []byte) {
+	defer close(lines)
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if trimmed := strings.TrimRight(string(line), "\r\n"); trimmed != "" {
+			lines <- []byte(trimmed)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeLines writes each line from inbox to w, closing w when inbox is
+// closed. Once a write fails the rest are discarded; the server's exit is
+// reported separately.
+func writeLines(inbox <-chan []byte, w io.WriteCloser) {
+	defer w.Close()
+	var err error
+	for line := range inbox {
+		if err == nil {
+			_, err = w.Write(append(line, '\n'))
+		}
+	}
+}
+
+// eventLog appends timestamped lines to a server's log file.
+type eventLog struct {
+	path string
+	f    *os.File
+}
+
+func (l *eventLog) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(l.f, "%s [%d] %s\n", time.Now().Format(time.RFC3339), os.Getpid(), fmt.Sprintf(format, args...))
+}
+
+func (l *eventLog) Close() error { return l.f.Close() }
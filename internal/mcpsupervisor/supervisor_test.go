@@ -0,0 +1,214 @@
+package mcpsupervisor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHelperServer is not a test: it is the fake MCP server the supervisor
+// tests launch, by running the test binary with FAKE_MCP_SERVER set. It
+// answers initialize, ping, and tools/call, exits on "crash", and stops
+// reading on "hang". Requests before the handshake completes are errors, so
+// the tests notice when a restart skips the replay.
+func TestHelperServer(t *testing.T) {
+	if os.Getenv("FAKE_MCP_SERVER") == "" {
+		return
+	}
+	initialized := false
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var m struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.Unmarshal(scanner.Bytes(), &m)
+		reply := func(result string) {
+			fmt.Printf(`{"jsonrpc":"2.0","id":%s,"result":%s}`+"\n", m.ID, result)
+		}
+		switch m.Method {
+		case "initialize":
+			reply(`{"protocolVersion":"2025-06-18"}`)
+		case "notifications/initialized":
+			initialized = true
+		case "ping":
+			reply(`{}`)
+		case "crash":
+			fmt.Fprintln(os.Stderr, "fatal: crashing on request")
+			os.Exit(3)
+		case "hang":
+			select {}
+		default:
+			if !initialized {
+				fmt.Printf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32002,"message":"not initialized"}}`+"\n", m.ID)
+				continue
+			}
+			reply(fmt.Sprintf(`{"pid":%d}`, os.Getpid()))
+		}
+	}
+	os.Exit(0)
+}
+
+type response struct {
+	ID     int `json:"id"`
+	Result struct {
+		PID int `json:"pid"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// client drives a supervisor through pipes, as Claude Code would.
+type client struct {
+	t     *testing.T
+	in    io.WriteCloser
+	lines chan []byte
+	done  chan error
+}
+
+func startSupervisor(t *testing.T, opts Options) *client {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("FAKE_MCP_SERVER", "1")
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, in: inW, lines: make(chan []byte, 16), done: make(chan error, 1)}
+	go func() {
+		c.done <- Run(context.Background(), opts, inR, outW)
+		outW.Close()
+	}()
+	go readLines(outR, c.lines)
+	return c
+}
+
+func (c *client) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) call(id int, method string) response {
+	c.t.Helper()
+	c.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q}`, id, method))
+	select {
+	case line, ok := <-c.lines:
+		if !ok {
+			c.t.Fatalf("%s: supervisor closed stdout", method)
+		}
+		var r response
+		if err := json.Unmarshal(line, &r); err != nil {
+			c.t.Fatalf("%s: invalid response %s", method, line)
+		}
+		if r.ID != id {
+			c.t.Fatalf("%s: response %s, want id %d", method, line, id)
+		}
+		return r
+	case <-time.After(10 * time.Second):
+		c.t.Fatalf("%s: no response", method)
+	}
+	return response{}
+}
+
+func fakeServer() []string {
+	return []string{os.Args[0], "-test.run=^TestHelperServer$"}
+}
+
+func TestRun_RestartsAndReplaysHandshake(t *testing.T) {
+	c := startSupervisor(t, Options{
+		Name: "fake", Command: fakeServer(),
+		Backoff: 10 * time.Millisecond, PingInterval: 50 * time.Millisecond, PingTimeout: 200 * time.Millisecond,
+	})
+	if r := c.call(1, "initialize"); r.Error != nil {
+		t.Fatalf("initialize: %s", r.Error.Message)
+	}
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	first := c.call(2, "tools/call")
+	if first.Error != nil || first.Result.PID == 0 {
+		t.Fatalf("tools/call = %+v", first)
+	}
+
+	// The request that crashed the server is answered with an error; the
+	// next one reaches a restarted server that has been initialized again.
+	if r := c.call(3, "crash"); r.Error == nil || !strings.Contains(r.Error.Message, "restarting") {
+		t.Errorf("crash = %+v, want a restart error", r)
+	}
+	second := c.call(4, "tools/call")
+	if second.Error != nil || second.Result.PID == first.Result.PID {
+		t.Errorf("after crash, tools/call = %+v (first pid %d)", second, first.Result.PID)
+	}
+
+	// A server that stops answering pings is killed and restarted.
+	if r := c.call(5, "hang"); r.Error == nil || !strings.Contains(r.Error.Message, "not responding") {
+		t.Errorf("hang = %+v, want a not-responding error", r)
+	}
+	third := c.call(6, "tools/call")
+	if third.Error != nil || third.Result.PID == second.Result.PID {
+		t.Errorf("after hang, tools/call = %+v", third)
+	}
+
+	statuses, err := List()
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("List() = %+v, %v", statuses, err)
+	}
+	if st := statuses[0]; st.Name != "fake" || st.Restarts != 2 || st.State != StateRunning || st.ChildPID != third.Result.PID {
+		t.Errorf("status = %+v", st)
+	}
+	logPath, _ := LogPath("fake")
+	data, _ := os.ReadFile(logPath)
+	for _, want := range []string{"fatal: crashing on request", "server stopped (exit status 3)", "replaying initialize", "server stopped (not responding)"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log missing %q:\n%s", want, data)
+		}
+	}
+
+	c.in.Close()
+	if err := <-c.done; err != nil {
+		t.Errorf("Run() = %v after the client disconnected", err)
+	}
+	if statuses, _ := List(); len(statuses) != 0 {
+		t.Errorf("status file left behind: %+v", statuses)
+	}
+}
+
+func TestRun_GivesUp(t *testing.T) {
+	c := startSupervisor(t, Options{Name: "broken", Command: []string{"false"}, MaxRestarts: 2, Backoff: time.Millisecond})
+	select {
+	case err := <-c.done:
+		if err == nil || !strings.Contains(err.Error(), "crashed 3 times in a row") {
+			t.Errorf("Run() = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("supervisor did not give up")
+	}
+	statuses, _ := List()
+	if len(statuses) != 1 || statuses[0].State != StateFailed || statuses[0].Restarts != 2 {
+		t.Errorf("List() = %+v, want one failed supervisor", statuses)
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"mcp", "serve", "github", "--", "npx", "-y", "server"}, []string{"npx", "-y", "server"}},
+		{[]string{"mcp", "serve", "github", "--"}, nil},
+		{[]string{"secrets", "exec", "TOKEN", "--", "npx"}, nil},
+		{[]string{"-y", "server"}, nil},
+	}
+	for _, tt := range tests {
+		got, ok := Unwrap(tt.args)
+		if !reflect.DeepEqual(got, tt.want) || ok != (tt.want != nil) {
+			t.Errorf("Unwrap(%q) = %q, %v; want %q", tt.args, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// An export encrypted with a passphrase is a sealedExport: the export JSON
+// sealed with AES-256-GCM under a key derived from the passphrase with
+// PBKDF2-SHA256. An export encrypted to recipients is an armored age file,
+// written and read with the age CLI, so it opens with the team's existing
+// age or SSH keys.
+const (
+	cipherAESGCM      = "aes-256-gcm"
+	kdfPBKDF2         = "pbkdf2-sha256"
+	pbkdf2Iterations  = 600000
+	pbkdf2MaxIter     = 10 * pbkdf2Iterations // a file asking for more would hang import
+	ageHeader         = "age-encryption.org/"
+	ageArmorHeader    = "-----BEGIN AGE ENCRYPTED FILE-----"
+	passphraseMinSize = 8
+)
+
+// sealedExport is the file written by "memory export --encrypt".
+type sealedExport struct {
+	Encrypted  string `json:"encrypted"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryption holds the flags that encrypt an export or decrypt one.
+type encryption struct {
+	encrypt       bool     // --encrypt: seal with a passphrase
+	passphraseEnv string   // --passphrase-env: read the passphrase from this variable
+	recipients    []string // --recipient: age recipients or files of them
+	identity      string   // --identity: age identity file to decrypt with
+}
+
+// addFlags declares the encryption flags on f.
+func (e *encryption) addFlags(f *cli.FlagSet) {
+	f.BoolVar(&e.encrypt, "--encrypt", "Encrypt with a passphrase")
+	f.Func("--passphrase-env", "var", "Read the passphrase from this environment variable", nonEmpty("--passphrase-env", &e.passphraseEnv))
+	f.Func("--recipient", "key", "Encrypt to this age recipient, or a file of them (repeatable)", func(v string) error {
+		if v == "" {
+			return fmt.Errorf("--recipient requires a value")
+		}
+		e.recipients = append(e.recipients, v)
+		return nil
+	})
+	f.Func("--identity", "file", "Decrypt with this age identity file", nonEmpty("--identity", &e.identity))
+}
+
+// nonEmpty returns a flag handler that stores a value in *p, rejecting "".
+func nonEmpty(name string, p *string) func(string) error {
+	return func(v string) error {
+		if v == "" {
+			return fmt.Errorf("%s requires a value", name)
+		}
+		*p = v
+		return nil
+	}
+}
+
+// seal encrypts an export as the flags ask, or returns it unchanged when they
+// ask for no encryption. toStdout means the result is written to stdout, where
+// the passphrase prompt cannot go.
+func (e encryption) seal(plain []byte, toStdout bool) ([]byte, error) {
+	if len(e.recipients) > 0 {
+		if e.encrypt || e.passphraseEnv != "" {
+			return nil, fmt.Errorf("--recipient cannot be combined with --encrypt or --passphrase-env")
+		}
+		args := []string{"--encrypt", "--armor"}
+		for _, r := range e.recipients {
+			if platform.FileExists(r) {
+				args = append(args, "-R", r)
+			} else {
+				args = append(args, "-r", r)
+			}
+		}
+		out, err := runAge(plain, args...)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	}
+	if !e.encrypt && e.passphraseEnv == "" {
+		return plain, nil
+	}
+	if toStdout && e.passphraseEnv == "" && isTerminal() {
+		return nil, fmt.Errorf("--encrypt needs --output or --passphrase-env when writing to stdout")
+	}
+	passphrase, err := e.passphrase(true)
+	if err != nil {
+		return nil, err
+	}
+	return sealPassphrase(plain, passphrase)
+}
+
+// open returns the export JSON in raw, read from path, decrypting it when it
+// is encrypted.
+func (e encryption) open(path string, raw []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if bytes.HasPrefix(trimmed, []byte(ageHeader)) || bytes.HasPrefix(trimmed, []byte(ageArmorHeader)) {
+		if e.identity == "" {
+			return nil, fmt.Errorf("%s is encrypted with age; pass --identity <key-file> to decrypt it", path)
+		}
+		return runAge(raw, "--decrypt", "-i", e.identity)
+	}
+	var sealed sealedExport
+	if json.Unmarshal(raw, &sealed) != nil || sealed.Encrypted == "" {
+		return raw, nil
+	}
+	passphrase, err := e.passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := openPassphrase(&sealed, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// passphrase returns the passphrase from --passphrase-env, the terminal, or a
+// line of stdin. confirm asks for it twice on a terminal.
+func (e encryption) passphrase(confirm bool) (string, error) {
+	if e.passphraseEnv != "" {
+		p := os.Getenv(e.passphraseEnv)
+		if p == "" {
+			return "", fmt.Errorf("%s is not set", e.passphraseEnv)
+		}
+		return p, checkPassphrase(p, confirm)
+	}
+	if !isTerminal() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("reading passphrase: %w", err)
+		}
+		p := strings.TrimSpace(line)
+		if p == "" {
+			return "", fmt.Errorf("no passphrase given: use --passphrase-env or pipe it on stdin")
+		}
+		return p, checkPassphrase(p, confirm)
+	}
+	p, err := platform.PromptSecret("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if err := checkPassphrase(p, confirm); err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := platform.PromptSecret("Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return p, nil
+}
+
+// checkPassphrase rejects a passphrase too short to encrypt with. Any
+// passphrase may decrypt, since the export decides what is right.
+func checkPassphrase(p string, encrypting bool) error {
+	if encrypting && len(p) < passphraseMinSize {
+		return fmt.Errorf("passphrase must be at least %d characters", passphraseMinSize)
+	}
+	return nil
+}
+
+// sealPassphrase encrypts plain under passphrase with a fresh salt and nonce.
+func sealPassphrase(plain []byte, passphrase string) ([]byte, error) {
+	s := &sealedExport{Encrypted: cipherAESGCM, KDF: kdfPBKDF2, Iterations: pbkdf2Iterations, Salt: make([]byte, 16)}
+	if _, err := io.ReadFull(rand.Reader, s.Salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	gcm, err := s.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	s.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, s.Nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	s.Ciphertext = gcm.Seal(nil, s.Nonce, plain, nil)
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// openPassphrase decrypts a sealed export with passphrase.
+func openPassphrase(s *sealedExport, passphrase string) ([]byte, error) {
+	if s.Encrypted != cipherAESGCM || s.KDF != kdfPBKDF2 {
+		return nil, fmt.Errorf("unsupported encryption %s with %s", s.Encrypted, s.KDF)
+	}
+	gcm, err := s.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("corrupt nonce")
+	}
+	plain, err := gcm.Open(nil, s.Nonce, s.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase or corrupt file")
+	}
+	return plain, nil
+}
+
+// cipher returns the AES-GCM cipher keyed from passphrase with s's KDF
+// parameters.
+func (s *sealedExport) cipher(passphrase string) (cipher.AEAD, error) {
+	if s.Iterations < 1 || len(s.Salt) == 0 {
+		return nil, fmt.Errorf("corrupt key derivation parameters")
+	}
+	if s.Iterations > pbkdf2MaxIter {
+		return nil, fmt.Errorf("key derivation asks for %d iterations, more than the %d allowed", s.Iterations, pbkdf2MaxIter)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, s.Salt, s.Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// runAge runs the age CLI with input on stdin and returns its output.
+func runAge(input []byte, args ...string) ([]byte, error) {
+	if !platform.Exists("age") {
+		return nil, fmt.Errorf("age is not installed (https://age-encryption.org); it is needed for --recipient and --identity")
+	}
+	out, stderr, err := platform.RunDirWithStdinCapture(context.Background(), "", string(input), nil, "age", args...)
+	if err != nil {
+		if stderr != "" {
+			return nil, fmt.Errorf("age: %s", stderr)
+		}
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	return []byte(out), nil
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(syscall.Stdin))
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+)
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	t.Setenv("MEMORY_PASS", "correct horse battery")
+	enc := encryption{passphraseEnv: "MEMORY_PASS"}
+	plain := []byte(`{"version":1,"layers":{}}` + "\n")
+
+	sealed, err := enc.seal(plain, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "layers") {
+		t.Fatalf("sealed export contains plaintext:\n%s", sealed)
+	}
+	var s sealedExport
+	if err := json.Unmarshal(sealed, &s); err != nil || s.Encrypted != cipherAESGCM || s.KDF != kdfPBKDF2 {
+		t.Fatalf("sealed export = %s (%v)", sealed, err)
+	}
+
+	got, err := enc.open("export.json", sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(plain) {
+		t.Errorf("opened = %q, want %q", got, plain)
+	}
+
+	t.Setenv("MEMORY_PASS", "wrong passphrase")
+	if _, err := enc.open("export.json", sealed); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("open with the wrong passphrase: err = %v", err)
+	}
+}
+
+func TestEncryption_Plain(t *testing.T) {
+	plain := []byte(`{"version":1}`)
+	sealed, err := encryption{}.seal(plain, true)
+	if err != nil || string(sealed) != string(plain) {
+		t.Errorf("seal without flags = %q, %v; want the export unchanged", sealed, err)
+	}
+	opened, err := encryption{identity: "key.txt"}.open("export.json", plain)
+	if err != nil || string(opened) != string(plain) {
+		t.Errorf("open of a plain export = %q, %v", opened, err)
+	}
+}
+
+func TestEncryption_Errors(t *testing.T) {
+	t.Setenv("SHORT_PASS", "short")
+	if _, err := (encryption{passphraseEnv: "SHORT_PASS"}).seal([]byte("{}"), false); err == nil {
+		t.Error("seal accepted a short passphrase")
+	}
+	if _, err := (encryption{passphraseEnv: "UNSET_MEMORY_PASS"}).seal([]byte("{}"), false); err == nil {
+		t.Error("seal accepted an unset --passphrase-env")
+	}
+	if _, err := (encryption{encrypt: true, recipients: []string{"age1xyz"}}).seal([]byte("{}"), false); err == nil {
+		t.Error("seal accepted --recipient with --encrypt")
+	}
+	huge := &sealedExport{Encrypted: cipherAESGCM, KDF: kdfPBKDF2, Iterations: 1 << 40, Salt: []byte("salt")}
+	if _, err := openPassphrase(huge, "long enough"); err == nil || !strings.Contains(err.Error(), "iterations") {
+		t.Errorf("openPassphrase with %d iterations: err = %v", huge.Iterations, err)
+	}
+	armored := []byte(ageArmorHeader + "\nYWdl\n-----END AGE ENCRYPTED FILE-----\n")
+	if _, err := (encryption{}).open("export.age", armored); err == nil || !strings.Contains(err.Error(), "--identity") {
+		t.Errorf("open of an age file without --identity: err = %v", err)
+	}
+}
+
+func TestParseEncryptionFlags(t *testing.T) {
+	var enc encryption
+	f := cli.New("memory export", "memory export")
+	enc.addFlags(f)
+	args := []string{"--encrypt", "--passphrase-env", "P", "--recipient=age1a", "--recipient", "keys.txt", "--identity", "id.txt"}
+	if _, err := f.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if !enc.encrypt || enc.passphraseEnv != "P" || enc.identity != "id.txt" || strings.Join(enc.recipients, ",") != "age1a,keys.txt" {
+		t.Errorf("parsed %+v", enc)
+	}
+	for _, args := range [][]string{{"--identity"}, {"--identity", ""}, {"--other"}} {
+		f := cli.New("memory export", "memory export")
+		(&encryption{}).addFlags(f)
+		if _, err := f.Parse(args); err == nil {
+			t.Errorf("Parse(%q): expected error", args)
+		}
+	}
+}
+
+func TestImportMemoryEncrypted(t *testing.T) {
+	t.Setenv("MEMORY_PASS", "correct horse battery")
+	enc := encryption{passphraseEnv: "MEMORY_PASS"}
+	dir := t.TempDir()
+	exportPath := filepath.Join(dir, "export.json")
+	restoreDir := filepath.Join(dir, "memory")
+
+	raw, _ := json.Marshal(ExportData{
+		Version: 1,
+		Layers: ExportLayers{AutoMemory: &ExportAutoMem{
+			BasePath: restoreDir,
+			Files:    map[string]string{"MEMORY.md": "# Memory\n"},
+		}},
+	})
+	sealed, err := enc.seal(raw, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(exportPath, sealed, 0600)
+
+	if err := importMemory(exportPath, ParseScope("auto"), true, false, enc); err != nil {
+		t.Fatalf("importMemory: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(restoreDir, "MEMORY.md")); string(got) != "# Memory\n" {
+		t.Errorf("restored MEMORY.md = %q", got)
+	}
+}
@@ -0,0 +1,445 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// mergeLines returns current with every line of snapshot that it lacks
+// inserted at the matching position, and the number of lines added. No line of
+// current is removed or changed.
+func mergeLines(current, snapshot string) (string, int) {
+	ops := platform.DiffLines(platform.SplitLines(current), platform.SplitLines(snapshot))
+	lines := make([]string, 0, len(ops))
+	added := 0
+	for _, op := range ops {
+		if op.Kind == '+' {
+			added++
+		}
+		lines = append(lines, op.Line)
+	}
+	if added == 0 {
+		return current, 0
+	}
+	return strings.Join(lines, "\n") + "\n", added
+}
+
+// memoryGraph is the knowledge graph format used by mcp-memory-libsql
+// (read_graph output and create_entities/create_relations input).
+type memoryGraph struct {
+	Entities  []graphEntity   `json:"entities"`
+	Relations []graphRelation `json:"relations"`
+}
+
+type graphEntity struct {
+	Name         string   `json:"name"`
+	EntityType   string   `json:"entityType"`
+	Observations []string `json:"observations"`
+}
+
+type graphRelation struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	RelationType string `json:"relationType"`
+}
+
+// parseGraph decodes MCP export data as a knowledge graph. It reports false
+// when the data is in another format (such as an engram export). Missing data
+// is an empty graph.
+func parseGraph(raw *json.RawMessage) (*memoryGraph, bool) {
+	g := &memoryGraph{}
+	if raw == nil {
+		return g, true
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(*raw, &probe); err != nil {
+		return nil, false
+	}
+	if _, ok := probe["entities"]; !ok {
+		return nil, false
+	}
+	if err := json.Unmarshal(*raw, g); err != nil {
+		return nil, false
+	}
+	return g, true
+}
+
+func (g *memoryGraph) entity(name string) *graphEntity {
+	for i := range g.Entities {
+		if g.Entities[i].Name == name {
+			return &g.Entities[i]
+		}
+	}
+	return nil
+}
+
+func (g *memoryGraph) isEmpty() bool {
+	return len(g.Entities) == 0 && len(g.Relations) == 0
+}
+
+// observationCount returns the number of observations across all entities.
+func (g *memoryGraph) observationCount() int {
+	n := 0
+	for _, e := range g.Entities {
+		n += len(e.Observations)
+	}
+	return n
+}
+
+// graphAdditions returns what other has that base lacks: whole entities,
+// observations of entities both have (listed under that entity), and relations.
+func graphAdditions(base, other *memoryGraph) *memoryGraph {
+	add := &memoryGraph{}
+	for _, e := range other.Entities {
+		existing := base.entity(e.Name)
+		if existing == nil {
+			add.Entities = append(add.Entities, e)
+			continue
+		}
+		have := make(map[string]bool, len(existing.Observations))
+		for _, o := range existing.Observations {
+			have[o] = true
+		}
+		var obs []string
+		for _, o := range e.Observations {
+			if !have[o] {
+				obs = append(obs, o)
+			}
+		}
+		if len(obs) > 0 {
+			add.Entities = append(add.Entities, graphEntity{Name: e.Name, EntityType: e.EntityType, Observations: obs})
+		}
+	}
+	haveRel := make(map[graphRelation]bool, len(base.Relations))
+	for _, r := range base.Relations {
+		haveRel[r] = true
+	}
+	for _, r := range other.Relations {
+		if !haveRel[r] {
+			add.Relations = append(add.Relations, r)
+		}
+	}
+	return add
+}
+
+func runDiff(args []string) error {
+	scope := "all"
+	var enc encryption
+	f := cli.New("memory diff", "memory diff <export.json> [--scope <layers>] [--passphrase-env <var>] [--identity <file>]")
+	f.StringVar(&scope, "--scope", "layers", "Layers to compare")
+	enc.addFlags(f)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
+	}
+	return diffMemory(positional[0], ParseScope(scope), enc)
+}
+
+// diffMemory compares an exported snapshot, decrypted with enc if needed,
+// against the current layers.
+func diffMemory(file string, scope map[LayerName]bool, enc encryption) error {
+	snapshot, err := readExportFile(file, enc)
+	if err != nil {
+		return err
+	}
+	layers, err := DiscoverLayers()
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	// Exporting the MCP graph may invoke claude, so skip it when out of scope.
+	inScope := layers[:0]
+	for _, l := range layers {
+		if scope[l.Name] {
+			inScope = append(inScope, l)
+		}
+	}
+	current := buildExport(inScope, cwd)
+
+	w := os.Stdout
+	platform.PrintBanner(w, "Memory Diff")
+	fmt.Fprintf(w, "\n  Snapshot: %s (exported %s)\n", file, snapshot.ExportedAt)
+	fmt.Fprintf(w, "  %s only in snapshot   %s only in current layers\n", platform.Red("-"), platform.Green("+"))
+	if writeMemoryDiff(w, snapshot, current, scope) == 0 {
+		fmt.Fprintln(w)
+		platform.PrintOK(w, "No differences")
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// writeMemoryDiff prints the differences between two exports for the layers
+// in scope and returns the number of layers that differ.
+func writeMemoryDiff(w io.Writer, snapshot, current *ExportData, scope map[LayerName]bool) int {
+	changed := 0
+	fileLayers := []struct {
+		name          LayerName
+		label         string
+		before, after *ExportFile
+	}{
+		{LayerUserClaudeMD, "User CLAUDE.md", snapshot.Layers.UserClaudeMD, current.Layers.UserClaudeMD},
+		{LayerProjectClaudeMD, "Project CLAUDE.md", snapshot.Layers.ProjectClaudeMD, current.Layers.ProjectClaudeMD},
+		{LayerLocalMD, "CLAUDE.local.md", snapshot.Layers.LocalMD, current.Layers.LocalMD},
+	}
+	for _, fl := range fileLayers {
+		if !scope[fl.name] {
+			continue
+		}
+		before, after := fileContent(fl.before), fileContent(fl.after)
+		if before == after {
+			continue
+		}
+		changed++
+		platform.PrintSection(w, fl.label)
+		platform.WriteLineDiff(w, before, after)
+	}
+
+	if scope[LayerAutoMemory] && writeAutoMemoryDiff(w, autoMemFiles(snapshot.Layers.AutoMemory), autoMemFiles(current.Layers.AutoMemory)) {
+		changed++
+	}
+	if scope[LayerMemoryMCP] && writeMCPDiff(w, snapshot.Layers.MemoryMCP, current.Layers.MemoryMCP) {
+		changed++
+	}
+	return changed
+}
+
+func fileContent(ef *ExportFile) string {
+	if ef == nil || ef.Content == nil {
+		return ""
+	}
+	return *ef.Content
+}
+
+func autoMemFiles(am *ExportAutoMem) map[string]string {
+	if am == nil {
+		return nil
+	}
+	return am.Files
+}
+
+func writeAutoMemoryDiff(w io.Writer, before, after map[string]string) bool {
+	names := map[string]bool{}
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	printed := false
+	for _, name := range sortedKeys(names) {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		if inBefore && inAfter && a == b {
+			continue
+		}
+		if !printed {
+			platform.PrintSection(w, "Auto-memory")
+			printed = true
+		}
+		switch {
+		case !inAfter:
+			fmt.Fprintf(w, "  %s\n", platform.Red(fmt.Sprintf("- %s (%d lines)", name, countLines(b))))
+		case !inBefore:
+			fmt.Fprintf(w, "  %s\n", platform.Green(fmt.Sprintf("+ %s (%d lines)", name, countLines(a))))
+		default:
+			platform.PrintSectionLabel(w, name)
+			platform.WriteLineDiff(w, b, a)
+		}
+	}
+	return printed
+}
+
+func writeMCPDiff(w io.Writer, before, after *ExportMCP) bool {
+	var beforeData, afterData *json.RawMessage
+	if before != nil {
+		beforeData = before.Data
+	}
+	if after != nil {
+		afterData = after.Data
+	}
+	if beforeData == nil && afterData == nil {
+		return false
+	}
+
+	bg, okBefore := parseGraph(beforeData)
+	ag, okAfter := parseGraph(afterData)
+	if !okBefore || !okAfter {
+		// Not a knowledge graph: only whole-document comparison is possible.
+		if beforeData != nil && afterData != nil && string(*beforeData) == string(*afterData) {
+			return false
+		}
+		platform.PrintSection(w, "Memory MCP")
+		platform.PrintWarn(w, "Provider data differs (entity-level diff is only available for mcp-memory-libsql)")
+		return true
+	}
+
+	removed, added := graphAdditions(ag, bg), graphAdditions(bg, ag)
+	if removed.isEmpty() && added.isEmpty() {
+		return false
+	}
+	platform.PrintSection(w, "Memory MCP")
+	writeGraphChanges(w, "-", platform.Red, removed, ag)
+	writeGraphChanges(w, "+", platform.Green, added, bg)
+	return true
+}
+
+// writeGraphChanges prints entities, observations and relations from changes.
+// Entities missing entirely from other are shown whole; for the rest only the
+// differing observations are listed.
+func writeGraphChanges(w io.Writer, sign string, color func(string) string, changes, other *memoryGraph) {
+	sort.Slice(changes.Entities, func(i, j int) bool { return changes.Entities[i].Name < changes.Entities[j].Name })
+	for _, e := range changes.Entities {
+		if other.entity(e.Name) == nil {
+			fmt.Fprintf(w, "  %s\n", color(fmt.Sprintf("%s entity %s (%s, %d observations)", sign, e.Name, e.EntityType, len(e.Observations))))
+			continue
+		}
+		for _, o := range e.Observations {
+			fmt.Fprintf(w, "  %s\n", color(fmt.Sprintf("%s %s: %s", sign, e.Name, o)))
+		}
+	}
+	for _, r := range changes.Relations {
+		fmt.Fprintf(w, "  %s\n", color(fmt.Sprintf("%s relation %s -[%s]-> %s", sign, r.From, r.RelationType, r.To)))
+	}
+}
+
+// mergeImport applies only the content in data that is missing from the
+// current layers: new lines in file layers, new auto-memory files and lines,
+// and new MCP entities, observations and relations.
+func mergeImport(w io.Writer, data *ExportData, scope map[LayerName]bool, confirm bool) error {
+	platform.PrintBanner(w, "Memory Merge Preview")
+
+	type fileMerge struct {
+		label, path, content string
+		added                int
+	}
+	var merges []fileMerge
+	addFile := func(label, path, snapshot string) {
+		current := readFileContent(path)
+		merged, added := mergeLines(current, snapshot)
+		if added > 0 {
+			merges = append(merges, fileMerge{label, path, merged, added})
+		}
+	}
+
+	fileLayers := []struct {
+		name  LayerName
+		label string
+		ef    *ExportFile
+	}{
+		{LayerUserClaudeMD, "User CLAUDE.md", data.Layers.UserClaudeMD},
+		{LayerProjectClaudeMD, "Project CLAUDE.md", data.Layers.ProjectClaudeMD},
+		{LayerLocalMD, "CLAUDE.local.md", data.Layers.LocalMD},
+	}
+	for _, fl := range fileLayers {
+		if scope[fl.name] && fl.ef != nil && fl.ef.Content != nil {
+			addFile(fl.label, fl.ef.Path, *fl.ef.Content)
+		}
+	}
+	if am := data.Layers.AutoMemory; scope[LayerAutoMemory] && am != nil {
+		for _, name := range sortedKeys(am.Files) {
+			addFile("Auto-memory "+name, filepath.Join(am.BasePath, name), am.Files[name])
+		}
+	}
+
+	var mcp *ExportMCP
+	var mcpAdded *memoryGraph
+	if scope[LayerMemoryMCP] && data.Layers.MemoryMCP != nil && data.Layers.MemoryMCP.Data != nil {
+		var err error
+		if mcp, mcpAdded, err = mcpAdditions(data.Layers.MemoryMCP); err != nil {
+			platform.PrintWarn(w, fmt.Sprintf("Memory MCP: %v", err))
+		}
+	}
+
+	for _, m := range merges {
+		fmt.Fprintf(w, "  Will add: %d line(s) to %s\n", m.added, m.path)
+	}
+	if mcp != nil {
+		fmt.Fprintf(w, "  Will add: %d entit(ies), %d observation(s), %d relation(s) via %s\n",
+			len(mcpAdded.Entities), mcpAdded.observationCount(), len(mcpAdded.Relations), mcp.Provider)
+	}
+	if len(merges) == 0 && mcp == nil {
+		fmt.Fprintln(w, "  Nothing to merge: the current layers already contain everything in the export.")
+		return nil
+	}
+	if !confirm {
+		fmt.Fprintf(w, "\n  Re-run with --confirm to apply, or add --scope to limit.\n")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	for _, m := range merges {
+		if err := writeFileContent(m.path, m.content); err != nil {
+			platform.PrintFail(w, fmt.Sprintf("%s: %v", m.label, err))
+		} else {
+			platform.PrintOK(w, fmt.Sprintf("%s: %d line(s) added", m.label, m.added))
+		}
+	}
+	if mcp != nil {
+		importMemoryMCP(w, map[LayerName]bool{LayerMemoryMCP: true}, mcp)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// mcpAdditions returns the import payload for the part of the exported graph
+// missing from the current one, and that part itself, or nils if there is
+// nothing to add. In the payload, entities that already exist carry their
+// current observations too, since create_entities may replace an entity.
+func mcpAdditions(snapshot *ExportMCP) (*ExportMCP, *memoryGraph, error) {
+	layers, err := DiscoverLayers()
+	if err != nil {
+		return nil, nil, err
+	}
+	var currentLayer *Layer
+	for i := range layers {
+		if layers[i].Name == LayerMemoryMCP {
+			currentLayer = &layers[i]
+		}
+	}
+	if currentLayer == nil || currentLayer.Provider != snapshot.Provider {
+		return nil, nil, fmt.Errorf("export uses %s, which is not the configured provider", snapshot.Provider)
+	}
+	snapGraph, ok := parseGraph(snapshot.Data)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s data cannot be merged; import without --merge to replace it", snapshot.Provider)
+	}
+	current := exportMCPLayer(currentLayer)
+	if current.Data == nil {
+		return nil, nil, fmt.Errorf("could not read the current %s data", snapshot.Provider)
+	}
+	curGraph, ok := parseGraph(current.Data)
+	if !ok {
+		return nil, nil, fmt.Errorf("current %s data is not a knowledge graph", snapshot.Provider)
+	}
+
+	add := graphAdditions(curGraph, snapGraph)
+	if add.isEmpty() {
+		return nil, nil, nil
+	}
+	payload := &memoryGraph{Entities: append([]graphEntity{}, add.Entities...), Relations: add.Relations}
+	for i := range payload.Entities {
+		if existing := curGraph.entity(payload.Entities[i].Name); existing != nil {
+			payload.Entities[i].Observations = append(append([]string{}, existing.Observations...), payload.Entities[i].Observations...)
+		}
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling additions: %w", err)
+	}
+	msg := json.RawMessage(raw)
+	return &ExportMCP{Provider: snapshot.Provider, Data: &msg}, add, nil
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name, current, snapshot, want string
+		added                         int
+	}{
+		{"identical", "a\nb\n", "a\nb\n", "a\nb\n", 0},
+		{"snapshot subset", "a\nb\nc\n", "a\nc\n", "a\nb\nc\n", 0},
+		{"inserts in place", "a\nc\n", "a\nb\nc\nd\n", "a\nb\nc\nd\n", 2},
+		{"keeps local edits", "a\nlocal\n", "a\nremote\n", "a\nlocal\nremote\n", 1},
+		{"empty current", "", "a\n", "a\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, added := mergeLines(tt.current, tt.snapshot)
+			if got != tt.want || added != tt.added {
+				t.Errorf("mergeLines() = %q, %d; want %q, %d", got, added, tt.want, tt.added)
+			}
+		})
+	}
+}
+
+func TestParseGraph(t *testing.T) {
+	raw := json.RawMessage(`{"entities":[{"name":"go","entityType":"convention","observations":["use gofmt"]}],"relations":[]}`)
+	g, ok := parseGraph(&raw)
+	if !ok || len(g.Entities) != 1 || g.Entities[0].Observations[0] != "use gofmt" {
+		t.Errorf("parseGraph = %+v, %v", g, ok)
+	}
+
+	other := json.RawMessage(`{"observations":[{"id":1}]}`)
+	if _, ok := parseGraph(&other); ok {
+		t.Error("parseGraph accepted data without entities")
+	}
+	if g, ok := parseGraph(nil); !ok || !g.isEmpty() {
+		t.Error("parseGraph(nil) should be an empty graph")
+	}
+}
+
+func TestGraphAdditions(t *testing.T) {
+	base := &memoryGraph{
+		Entities:  []graphEntity{{Name: "go", EntityType: "convention", Observations: []string{"use gofmt"}}},
+		Relations: []graphRelation{{From: "go", To: "make", RelationType: "uses"}},
+	}
+	other := &memoryGraph{
+		Entities: []graphEntity{
+			{Name: "go", EntityType: "convention", Observations: []string{"use gofmt", "table tests"}},
+			{Name: "git", EntityType: "workflow", Observations: []string{"rebase"}},
+		},
+		Relations: []graphRelation{
+			{From: "go", To: "make", RelationType: "uses"},
+			{From: "git", To: "go", RelationType: "versions"},
+		},
+	}
+	add := graphAdditions(base, other)
+	if len(add.Entities) != 2 {
+		t.Fatalf("added entities = %+v, want 2", add.Entities)
+	}
+	if obs := add.Entities[0].Observations; len(obs) != 1 || obs[0] != "table tests" {
+		t.Errorf("added observations for go = %v, want [table tests]", obs)
+	}
+	if len(add.Relations) != 1 || add.Relations[0].From != "git" {
+		t.Errorf("added relations = %+v", add.Relations)
+	}
+	if !graphAdditions(other, base).isEmpty() {
+		t.Error("base adds nothing to other")
+	}
+}
+
+func TestWriteMemoryDiff(t *testing.T) {
+	before, after := "keep\nold\n", "keep\nnew\n"
+	oldGraph := json.RawMessage(`{"entities":[{"name":"a","entityType":"pattern","observations":["x"]}]}`)
+	newGraph := json.RawMessage(`{"entities":[{"name":"a","entityType":"pattern","observations":["x","y"]},{"name":"b","entityType":"preference","observations":[]}]}`)
+	snapshot := &ExportData{Layers: ExportLayers{
+		UserClaudeMD: &ExportFile{Content: &before},
+		AutoMemory:   &ExportAutoMem{Files: map[string]string{"MEMORY.md": "m\n", "gone.md": "g\n"}},
+		MemoryMCP:    &ExportMCP{Provider: providerLibsql, Data: &oldGraph},
+	}}
+	current := &ExportData{Layers: ExportLayers{
+		UserClaudeMD: &ExportFile{Content: &after},
+		AutoMemory:   &ExportAutoMem{Files: map[string]string{"MEMORY.md": "m\n", "new.md": "n\n"}},
+		MemoryMCP:    &ExportMCP{Provider: providerLibsql, Data: &newGraph},
+	}}
+
+	var out strings.Builder
+	if n := writeMemoryDiff(&out, snapshot, current, ParseScope("all")); n != 3 {
+		t.Errorf("changed layers = %d, want 3", n)
+	}
+	for _, want := range []string{"- old", "+ new", "- gone.md", "+ new.md", "+ a: y", "+ entity b"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("diff output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "MEMORY.md") {
+		t.Error("unchanged auto-memory file should not be listed")
+	}
+
+	out.Reset()
+	if n := writeMemoryDiff(&out, snapshot, snapshot, ParseScope("all")); n != 0 || out.Len() != 0 {
+		t.Errorf("identical exports: %d changes, output %q", n, out.String())
+	}
+}
+
+func TestMergeImport(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "CLAUDE.md")
+	autoDir := filepath.Join(dir, "memory")
+	if err := writeFileContent(userPath, "# Prefs\nlocal rule\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileContent(filepath.Join(autoDir, "MEMORY.md"), "local note\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	userContent := "# Prefs\nexported rule\n"
+	data := &ExportData{Version: 1, Layers: ExportLayers{
+		UserClaudeMD: &ExportFile{Path: userPath, Content: &userContent},
+		AutoMemory: &ExportAutoMem{BasePath: autoDir, Files: map[string]string{
+			"MEMORY.md": "local note\n",
+			"new.md":    "fresh\n",
+		}},
+	}}
+
+	// Preview only.
+	var out strings.Builder
+	if err := mergeImport(&out, data, ParseScope("user,auto"), false); err != nil {
+		t.Fatal(err)
+	}
+	if platform.FileExists(filepath.Join(autoDir, "new.md")) {
+		t.Error("new.md written without --confirm")
+	}
+	if !strings.Contains(out.String(), "Will add: 1 line(s) to "+userPath) {
+		t.Errorf("preview output:\n%s", out.String())
+	}
+
+	if err := mergeImport(&out, data, ParseScope("user,auto"), true); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFileContent(userPath); got != "# Prefs\nlocal rule\nexported rule\n" {
+		t.Errorf("merged CLAUDE.md = %q", got)
+	}
+	if got := readFileContent(filepath.Join(autoDir, "new.md")); got != "fresh\n" {
+		t.Errorf("new.md = %q", got)
+	}
+	if got := readFileContent(filepath.Join(autoDir, "MEMORY.md")); got != "local note\n" {
+		t.Errorf("MEMORY.md = %q, want unchanged", got)
+	}
+}
@@ -1,7 +1,9 @@
 package memory
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -48,19 +50,60 @@ type ExportMCP struct {
 	Data     *json.RawMessage `json:"data"` // raw JSON from provider export
 }
 
-// export writes all memory layers to JSON.
-func export(outputPath string) error {
-	layers, err := DiscoverLayers()
+// export writes all memory layers to JSON, encrypted as enc asks. An
+// encrypted export is written readable only by its owner.
+func export(outputPath string, enc encryption) error {
+	jsonData, err := Snapshot()
+	if err != nil {
+		return err
+	}
+	toStdout := outputPath == "" || outputPath == "-"
+	sealed, err := enc.seal(jsonData, toStdout)
 	if err != nil {
 		return err
 	}
 
+	if toStdout {
+		_, err = os.Stdout.Write(sealed)
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if !bytes.Equal(sealed, jsonData) {
+		perm = 0600
+	}
+	return os.WriteFile(outputPath, sealed, perm)
+}
+
+// Snapshot returns all memory layers as the JSON "memory export" writes.
+func Snapshot() ([]byte, error) {
+	layers, err := DiscoverLayers()
+	if err != nil {
+		return nil, err
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("getting working directory: %w", err)
+		return nil, fmt.Errorf("getting working directory: %w", err)
 	}
 
-	data := ExportData{
+	jsonData, err := json.MarshalIndent(buildExport(layers, cwd), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling export: %w", err)
+	}
+	return append(jsonData, '\n'), nil
+}
+
+// Restore applies the layers in scope from an export file without a preview,
+// as "memory import --confirm" does.
+func Restore(filePath string, scope map[LayerName]bool) error {
+	return importMemory(filePath, scope, true, false, encryption{})
+}
+
+// buildExport assembles ExportData from discovered layers. cwd is recorded as
+// the project of the project CLAUDE.md layer.
+func buildExport(layers []Layer, cwd string) *ExportData {
+	data := &ExportData{
 		Version:    1,
 		ExportedAt: time.Now().UTC().Format(time.RFC3339),
 		Platform:   "claude-workspace",
@@ -82,19 +125,27 @@ func export(outputPath string) error {
 			data.Layers.MemoryMCP = exportMCPLayer(l)
 		}
 	}
+	return data
+}
 
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+// readExportFile reads and validates a file written by "memory export",
+// decrypting it with enc when it is encrypted.
+func readExportFile(path string, enc encryption) (*ExportData, error) {
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("marshaling export: %w", err)
+		return nil, fmt.Errorf("reading %s: %w", path, err)
 	}
-	jsonData = append(jsonData, '\n')
-
-	if outputPath == "" || outputPath == "-" {
-		_, err = os.Stdout.Write(jsonData)
-		return err
+	if raw, err = enc.open(path, raw); err != nil {
+		return nil, err
 	}
-
-	return os.WriteFile(outputPath, jsonData, 0644)
+	var data ExportData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if data.Version != 1 {
+		return nil, fmt.Errorf("unsupported export version: %d", data.Version)
+	}
+	return &data, nil
 }
 
 // exportFileLayer builds an ExportFile from a discovered file layer.
@@ -111,6 +162,12 @@ func exportFileLayer(l *Layer, project string) *ExportFile {
 // exportMCPLayer builds an ExportMCP from a discovered MCP layer,
 // fetching data from the appropriate provider.
 func exportMCPLayer(l *Layer) *ExportMCP {
+	return exportMCP(l, true)
+}
+
+// exportMCP builds an ExportMCP from a discovered MCP layer. askClaude lets
+// it fall back to asking the claude CLI for a graph it cannot read directly.
+func exportMCP(l *Layer, askClaude bool) *ExportMCP {
 	em := &ExportMCP{Provider: l.Provider}
 	switch l.Provider {
 	case "engram":
@@ -120,6 +177,17 @@ func exportMCPLayer(l *Layer) *ExportMCP {
 			}
 		}
 	case "mcp-memory-libsql":
+		raw, err := exportLibsqlNative(l.Path)
+		if err == nil {
+			em.Data = raw
+			break
+		}
+		if !askClaude {
+			break
+		}
+		if !errors.Is(err, errUnknownSchema) && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "  Note: could not read %s directly (%v); asking Claude instead.\n", l.Path, err)
+		}
 		if !platform.Exists("claude") {
 			fmt.Fprintln(os.Stderr, "  Warning: claude CLI not available — cannot export mcp-memory-libsql data.")
 			fmt.Fprintln(os.Stderr, "  To back up memories, use Claude with: mcp__mcp-memory-libsql__read_graph")
@@ -135,26 +203,23 @@ func exportMCPLayer(l *Layer) *ExportMCP {
 	return em
 }
 
-// importMemory restores layers from a previously exported JSON file.
-func importMemory(filePath string, scope map[LayerName]bool, confirm bool) error {
-	raw, err := os.ReadFile(filePath)
+// importMemory restores layers from a previously exported JSON file,
+// decrypting it with enc if needed. With merge, only content missing from the
+// current layers is added; nothing is overwritten or removed.
+func importMemory(filePath string, scope map[LayerName]bool, confirm, merge bool, enc encryption) error {
+	data, err := readExportFile(filePath, enc)
 	if err != nil {
-		return fmt.Errorf("reading import file: %w", err)
+		return err
 	}
 
-	var data ExportData
-	if err := json.Unmarshal(raw, &data); err != nil {
-		return fmt.Errorf("parsing import file: %w", err)
-	}
+	w := os.Stdout
 
-	if data.Version != 1 {
-		return fmt.Errorf("unsupported export version: %d", data.Version)
+	if merge {
+		return mergeImport(w, data, scope, confirm)
 	}
 
-	w := os.Stdout
-
 	platform.PrintBanner(w, "Memory Import Preview")
-	count := previewImport(w, &data, scope)
+	count := previewImport(w, data, scope)
 
 	if count == 0 {
 		fmt.Fprintln(w, "  Nothing to import for the selected scope.")
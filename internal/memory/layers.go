@@ -186,8 +186,13 @@ func discoverMemoryMCP(home string) Layer {
 			}
 		}
 	case providerLibsql:
-		// No CLI stats command — direct users to the Claude tool instead
-		l.Stats = "run: mcp__mcp-memory-libsql__read_graph"
+		// No CLI stats command; read the database directly when its schema is
+		// known, otherwise direct users to the Claude tool.
+		if g, err := readLibsqlGraph(l.Path); err == nil {
+			l.Stats = graphStats(g)
+		} else {
+			l.Stats = "run: mcp__mcp-memory-libsql__read_graph"
+		}
 	}
 
 	return l
@@ -221,6 +226,13 @@ func libsqlDBPath(raw json.RawMessage, defaultPath string) string {
 	return path
 }
 
+// ConfiguredProvider returns the memory MCP provider configured for home:
+// "mcp-memory-libsql", "engram", "memory", or "none".
+func ConfiguredProvider(home string) string {
+	provider, _ := detectProvider(home)
+	return provider
+}
+
 // detectProvider reads ~/.claude.json to find the configured memory MCP server.
 // Returns (provider name, data path). Priority: mcp-memory-libsql > engram > memory.
 func detectProvider(home string) (string, string) {
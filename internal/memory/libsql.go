@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// errUnknownSchema means a database does not have the tables and columns
+// mcp-memory-libsql is known to create, so callers fall back to asking Claude
+// to read the graph through the MCP server.
+var errUnknownSchema = errors.New("unrecognized mcp-memory-libsql schema")
+
+// readLibsqlGraph reads the knowledge graph directly from an mcp-memory-libsql
+// database file, without starting the MCP server or Claude.
+func readLibsqlGraph(path string) (*memoryGraph, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	if !db.hasColumns("entities", "name", "entity_type") ||
+		!db.hasColumns("observations", "entity_name", "content") ||
+		!db.hasColumns("relations", "source", "target", "relation_type") {
+		return nil, errUnknownSchema
+	}
+
+	entities, err := db.rows("entities")
+	if err != nil {
+		return nil, fmt.Errorf("reading entities: %w", err)
+	}
+	observations, err := db.rows("observations")
+	if err != nil {
+		return nil, fmt.Errorf("reading observations: %w", err)
+	}
+	relations, err := db.rows("relations")
+	if err != nil {
+		return nil, fmt.Errorf("reading relations: %w", err)
+	}
+
+	g := &memoryGraph{Entities: make([]graphEntity, 0, len(entities)), Relations: make([]graphRelation, 0, len(relations))}
+	index := make(map[string]int, len(entities))
+	for _, row := range entities {
+		name := text(row["name"])
+		index[name] = len(g.Entities)
+		g.Entities = append(g.Entities, graphEntity{Name: name, EntityType: text(row["entity_type"]), Observations: []string{}})
+	}
+	for _, row := range observations {
+		if i, ok := index[text(row["entity_name"])]; ok {
+			g.Entities[i].Observations = append(g.Entities[i].Observations, text(row["content"]))
+		}
+	}
+	for _, row := range relations {
+		g.Relations = append(g.Relations, graphRelation{
+			From:         text(row["source"]),
+			To:           text(row["target"]),
+			RelationType: text(row["relation_type"]),
+		})
+	}
+	return g, nil
+}
+
+// exportLibsqlNative returns the graph in the database at path as read_graph
+// JSON.
+func exportLibsqlNative(path string) (*json.RawMessage, error) {
+	g, err := readLibsqlGraph(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	raw := json.RawMessage(data)
+	return &raw, nil
+}
+
+// graphStats summarizes a graph for the layer overview.
+func graphStats(g *memoryGraph) string {
+	return fmt.Sprintf("%d entities, %d observations, %d relations", len(g.Entities), g.observationCount(), len(g.Relations))
+}
+
+// writeGraph prints a graph grouped by entity type.
+func writeGraph(w io.Writer, g *memoryGraph) {
+	if len(g.Entities) == 0 {
+		fmt.Fprintln(w, "  (no entities)")
+		return
+	}
+	byType := map[string][]graphEntity{}
+	for _, e := range g.Entities {
+		byType[e.EntityType] = append(byType[e.EntityType], e)
+	}
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Fprintf(w, "\n  %s (%d)\n", t, len(byType[t]))
+		for _, e := range byType[t] {
+			fmt.Fprintf(w, "    %s\n", e.Name)
+			for _, o := range e.Observations {
+				fmt.Fprintf(w, "      - %s\n", o)
+			}
+		}
+	}
+	if len(g.Relations) > 0 {
+		fmt.Fprintf(w, "\n  relations (%d)\n", len(g.Relations))
+		for _, r := range g.Relations {
+			fmt.Fprintf(w, "    %s %s %s\n", r.From, strings.ReplaceAll(r.RelationType, "_", " "), r.To)
+		}
+	}
+}
+
+// text converts a column value to a string.
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
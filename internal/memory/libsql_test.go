@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadVarint(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want int64
+		n    int
+	}{
+		{[]byte{0x05}, 5, 1},
+		{[]byte{0x81, 0x00}, 128, 2},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, -1, 9},
+		{[]byte{0x81}, 0, 0},
+	}
+	for _, tt := range tests {
+		got, n := readVarint(tt.in)
+		if got != tt.want || n != tt.n {
+			t.Errorf("readVarint(%x) = %d, %d; want %d, %d", tt.in, got, n, tt.want, tt.n)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	cols, rowid := parseColumns(`CREATE TABLE "observations" (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_name TEXT NOT NULL,
+		embedding F32_BLOB(4),
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (entity_name) REFERENCES entities(name)
+	)`)
+	if got := strings.Join(cols, ","); got != "id,entity_name,embedding,created_at" {
+		t.Errorf("columns = %s", got)
+	}
+	if rowid != 0 {
+		t.Errorf("rowid column = %d, want 0", rowid)
+	}
+	if _, rowid := parseColumns("CREATE TABLE entities (name TEXT PRIMARY KEY, entity_type TEXT)"); rowid != -1 {
+		t.Errorf("TEXT primary key should not alias the rowid, got %d", rowid)
+	}
+}
+
+func TestReadLibsqlGraph(t *testing.T) {
+	g, err := readLibsqlGraph(filepath.Join("testdata", "libsql.db"))
+	if err != nil {
+		t.Fatalf("readLibsqlGraph: %v", err)
+	}
+	if len(g.Entities) != 2 || g.Entities[0].Name != "go-conventions" || g.Entities[1].EntityType != "workflow" {
+		t.Fatalf("entities = %+v", g.Entities)
+	}
+	goObs := g.Entities[0].Observations
+	if len(goObs) != 301 || goObs[0] != "Go: one package per subcommand" || goObs[300] != "Go: note 299" {
+		t.Errorf("go-conventions has %d observations (first %q), want 301 in insertion order", len(goObs), goObs[0])
+	}
+	gitObs := g.Entities[1].Observations
+	if len(gitObs) != 2 || len(gitObs[1]) != len("long:")+3000 || !strings.HasSuffix(gitObs[1], "xxx") {
+		t.Errorf("overflowing observation not read in full: %d observations", len(gitObs))
+	}
+	if len(g.Relations) != 1 || g.Relations[0] != (graphRelation{From: "git-workflow", To: "go-conventions", RelationType: "applies_to"}) {
+		t.Errorf("relations = %+v", g.Relations)
+	}
+}
+
+func TestReadLibsqlGraphWAL(t *testing.T) {
+	g, err := readLibsqlGraph(filepath.Join("testdata", "libsql-wal.db"))
+	if err != nil {
+		t.Fatalf("readLibsqlGraph: %v", err)
+	}
+	var names []string
+	for _, e := range g.Entities {
+		names = append(names, e.Name)
+	}
+	if got := strings.Join(names, ","); got != "checkpointed,wal-only" {
+		t.Errorf("entities = %s, want committed WAL rows included", got)
+	}
+	if e := g.entity("wal-only"); e == nil || len(e.Observations) != 1 {
+		t.Errorf("wal-only entity = %+v", e)
+	}
+}
+
+func TestReadLibsqlGraphErrors(t *testing.T) {
+	dir := t.TempDir()
+	notDB := filepath.Join(dir, "memory.db")
+	if err := os.WriteFile(notDB, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readLibsqlGraph(notDB); !errors.Is(err, errNotSQLite) {
+		t.Errorf("non-database error = %v, want errNotSQLite", err)
+	}
+	if _, err := readLibsqlGraph(filepath.Join(dir, "missing.db")); !os.IsNotExist(err) {
+		t.Errorf("missing file error = %v, want not-exist", err)
+	}
+
+	// A valid database without the mcp-memory-libsql tables.
+	other := filepath.Join("testdata", "other.db")
+	if _, err := readLibsqlGraph(other); !errors.Is(err, errUnknownSchema) {
+		t.Errorf("other schema error = %v, want errUnknownSchema", err)
+	}
+	db, err := openSQLite(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.rows("notes")
+	if err != nil || len(rows) != 1 || rows[0]["id"] != int64(1) || rows[0]["body"] != "hi" {
+		t.Errorf("rows(notes) = %v, %v", rows, err)
+	}
+}
+
+// TestReadLibsqlGraphCorrupt reads torn and damaged copies of a database,
+// as a read racing the MCP server's writes can see, and expects errors, never
+// a panic.
+func TestReadLibsqlGraphCorrupt(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "libsql.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	dir := t.TempDir()
+	read := func(name string, db []byte) {
+		t.Helper()
+		path := filepath.Join(dir, name+".db")
+		if err := os.WriteFile(path, db, 0644); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("%s: readLibsqlGraph panicked: %v", name, r)
+			}
+		}()
+		_, _ = readLibsqlGraph(path)
+	}
+
+	// Every cell pointer, cell count, and right-most child pointing past
+	// the page.
+	for n := 1; n*pageSize <= len(data); n++ {
+		hdr := (n - 1) * pageSize
+		if n == 1 {
+			hdr += 100
+		}
+		for _, field := range []int{3, 8, 10} {
+			db := bytes.Clone(data)
+			binary.BigEndian.PutUint16(db[hdr+field:], 5000)
+			read(fmt.Sprintf("page%d-field%d", n, field), db)
+		}
+	}
+	// Truncated at every page and mid-page, and with bytes flipped.
+	for end := 100; end < len(data); end += pageSize / 2 {
+		read(fmt.Sprintf("truncated%d", end), data[:end])
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range 200 {
+		db := bytes.Clone(data)
+		for range 8 {
+			db[100+rng.IntN(len(db)-100)] = byte(rng.IntN(256))
+		}
+		read(fmt.Sprintf("flipped%d", i), db)
+	}
+}
+
+func TestWriteGraph(t *testing.T) {
+	g, err := readLibsqlGraph(filepath.Join("testdata", "libsql.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	writeGraph(&out, g)
+	for _, want := range []string{"convention (1)", "workflow (1)", "- Git: rebase before merging", "git-workflow applies to go-conventions"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if got := graphStats(g); got != "2 entities, 303 observations, 1 relations" {
+		t.Errorf("graphStats = %q", got)
+	}
+}
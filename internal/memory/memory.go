@@ -1,16 +1,21 @@
 // Package memory implements the "memory" command for inspecting and managing
 // Claude Code's layered memory system, including overview, show, export, import,
-// and provider configuration subcommands.
+// diff, prune, token estimates, git-backed sync, scheduled snapshots, and
+// provider configuration subcommands.
 package memory
 
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
 )
 
 // knownMemoryProviders is the set of memory MCP server keys this platform manages.
@@ -31,49 +36,68 @@ func Run(args []string) error {
 		return runImport(args[1:])
 	case "configure":
 		return runConfigure(args[1:])
+	case "diff":
+		return runDiff(args[1:])
+	case "prune":
+		return runPrune(args[1:])
+	case "sync":
+		return runSync(args[1:])
+	case "tokens":
+		return runTokens(args[1:])
+	case "snapshot":
+		return runSnapshot(args[1:])
 	default:
-		return fmt.Errorf("unknown memory subcommand: %s\nAvailable: show, export, import, configure", args[0])
+		return fmt.Errorf("unknown memory subcommand: %s\nAvailable: show, export, import, diff, prune, tokens, configure, sync, snapshot", args[0])
 	}
 }
 
 func runShow(args []string) error {
 	scope := "all"
-	for i := 0; i < len(args); i++ {
-		if args[i] == "--scope" && i+1 < len(args) {
-			i++
-			scope = args[i]
-		}
+	f := cli.New("memory show", "memory show [--scope <layers>]")
+	f.StringVar(&scope, "--scope", "layers", "Layers to show: all, user, project, local, auto, mcp")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
 	}
 	return show(ParseScope(scope))
 }
 
 func runExport(args []string) error {
 	output := ""
-	for i := 0; i < len(args); i++ {
-		if args[i] == "--output" && i+1 < len(args) {
-			i++
-			output = args[i]
-		}
+	var enc encryption
+	f := cli.New("memory export", "memory export [--output <file>] [--encrypt | --passphrase-env <var> | --recipient <key>]")
+	f.StringVar(&output, "--output", "file", "Write to this file instead of stdout")
+	enc.addFlags(f)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
 	}
-	return export(output)
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	return export(output, enc)
 }
 
 func runImport(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("usage: claude-workspace memory import <file> [--scope=...] [--confirm]")
-	}
-	file := args[0]
 	scope := "auto,mcp"
-	confirm := false
-	for i := 1; i < len(args); i++ {
-		if args[i] == "--confirm" {
-			confirm = true
-		} else if args[i] == "--scope" && i+1 < len(args) {
-			i++
-			scope = args[i]
-		}
+	confirm, merge := false, false
+	var enc encryption
+	f := cli.New("memory import", "memory import <file> [--scope <layers>] [--merge] [--confirm] [--passphrase-env <var>] [--identity <file>]")
+	f.StringVar(&scope, "--scope", "layers", "Layers to import")
+	f.BoolVar(&merge, "--merge", "Merge into existing memory instead of replacing it")
+	f.BoolVar(&confirm, "--confirm", "Apply the import; without it, only preview")
+	enc.addFlags(f)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
 	}
-	return importMemory(file, ParseScope(scope), confirm)
+	return importMemory(positional[0], ParseScope(scope), confirm, merge, enc)
 }
 
 // overview displays a summary of all memory layers.
@@ -194,6 +218,10 @@ func showEngramContent(w *os.File, l *Layer) {
 
 func showLibsqlContent(w *os.File, l *Layer) {
 	fmt.Fprintf(w, "  DB: %s\n", shortenHome(l.Path))
+	if g, err := readLibsqlGraph(l.Path); err == nil {
+		writeGraph(w, g)
+		return
+	}
 	if !platform.Exists("claude") {
 		fmt.Fprintf(w, "  Search requires Claude: %s\n", platform.Bold("mcp__mcp-memory-libsql__search_nodes"))
 		fmt.Fprintf(w, "  Read all: %s\n", platform.Bold("mcp__mcp-memory-libsql__read_graph"))
@@ -281,33 +309,30 @@ type configureOpts struct {
 	provider string
 	dbPath   string
 	autoYes  bool
+	migrate  bool
+	dryRun   bool
 }
 
-func parseConfigureFlags(args []string) configureOpts {
+func parseConfigureFlags(args []string) (configureOpts, error) {
 	var opts configureOpts
-	for i := 0; i < len(args); i++ {
-		switch {
-		case args[i] == "--provider" && i+1 < len(args):
-			i++
-			opts.provider = args[i]
-		case strings.HasPrefix(args[i], "--provider="):
-			opts.provider = strings.TrimPrefix(args[i], "--provider=")
-		case args[i] == "--db-path" && i+1 < len(args):
-			i++
-			opts.dbPath = args[i]
-		case strings.HasPrefix(args[i], "--db-path="):
-			opts.dbPath = strings.TrimPrefix(args[i], "--db-path=")
-		case args[i] == "--yes", args[i] == "-y":
-			opts.autoYes = true
-		}
+	f := cli.New("memory configure", "memory configure [--provider <name>] [--db-path <path>] [--migrate [--dry-run]] [--yes]")
+	f.StringVar(&opts.provider, "--provider", "name", "Memory provider: mcp-memory-libsql or engram")
+	f.StringVar(&opts.dbPath, "--db-path", "path", "Database path for mcp-memory-libsql")
+	f.BoolVar(&opts.autoYes, "--yes", "Answer yes to prompts")
+	f.Alias("-y", "--yes")
+	f.BoolVar(&opts.migrate, "--migrate", "Move memories from the current provider to the new one")
+	f.BoolVar(&opts.dryRun, "--dry-run", "With --migrate, show what would move; change nothing")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
 	}
-	return opts
+	return opts, f.CheckArgs(positional, 0, 0)
 }
 
 func promptProvider(w *os.File, reader *bufio.Reader) (string, error) {
 	fmt.Fprintln(w, "  Choose a memory provider:")
 	fmt.Fprintln(w, "    1) mcp-memory-libsql  (recommended — no extra install, uses npx)")
-	fmt.Fprintln(w, "    2) engram              (optional — requires: brew install gentleman-programming/tap/engram)")
+	fmt.Fprintln(w, "    2) engram              (optional — installed if missing)")
 	fmt.Fprintln(w, "    3) none                (remove all memory MCP config)")
 	platform.PrintPrompt(w, "  Provider [1]: ")
 	line, _ := reader.ReadString('\n')
@@ -344,6 +369,37 @@ func resolveDBPath(w *os.File, reader *bufio.Reader, home, flagDBPath string, au
 	return line
 }
 
+// ensureEngram offers to install engram, or upgrade it when it is too old for
+// the memory commands, before it is configured. Declining or a failed install
+// leaves the manual install command; the provider is configured either way.
+func ensureEngram(w io.Writer, reader *bufio.Reader, autoYes bool, engram tools.Tool) {
+	if engram.IsInstalled() {
+		return
+	}
+	question := "engram is not installed. Install it now? [Y/n]: "
+	if ver, err := engram.Version(); err == nil {
+		question = fmt.Sprintf("engram %s is older than the supported %s. Upgrade it now? [Y/n]: ", ver, tools.EngramMinVersion)
+	}
+	if !autoYes {
+		platform.PrintPrompt(w, "  "+question)
+		line, _ := reader.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "" && answer != "y" && answer != "yes" {
+			platform.PrintManual(w, "Install engram before using it:")
+			platform.PrintCommand(w, engram.InstallHint())
+			return
+		}
+	}
+	if err := engram.Install(); err != nil || !engram.IsInstalled() {
+		if err == nil {
+			err = fmt.Errorf("engram %s or newer is still not on PATH", tools.EngramMinVersion)
+		}
+		platform.PrintWarn(w, fmt.Sprintf("Could not install engram: %v", err))
+		platform.PrintCommand(w, engram.InstallHint())
+		return
+	}
+	platform.PrintOK(w, "Installed engram")
+}
+
 func buildProviderEntry(provider, dbPath string) map[string]interface{} {
 	switch provider {
 	case providerLibsql:
@@ -388,7 +444,10 @@ func printConfigureResult(w *os.File, provider, dbPath, previousProvider string)
 // runConfigure implements the `memory configure` subcommand.
 // It interactively (or via flags) sets the active memory MCP provider in ~/.claude.json.
 func runConfigure(args []string) error {
-	opts := parseConfigureFlags(args)
+	opts, err := parseConfigureFlags(args)
+	if err != nil {
+		return err
+	}
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -396,16 +455,6 @@ func runConfigure(args []string) error {
 	}
 	claudeConfig := filepath.Join(home, ".claude.json")
 
-	var config map[string]interface{}
-	if platform.FileExists(claudeConfig) {
-		if err := platform.ReadJSONFile(claudeConfig, &config); err != nil {
-			return fmt.Errorf("reading %s: %w", claudeConfig, err)
-		}
-	}
-	if config == nil {
-		config = make(map[string]interface{})
-	}
-
 	currentProvider, currentPath := detectProvider(home)
 	w := os.Stdout
 	platform.PrintBanner(w, "Memory Configure")
@@ -430,24 +479,74 @@ func runConfigure(args []string) error {
 		}
 	}
 
+	if provider == providerEngram {
+		ensureEngram(w, reader, opts.autoYes, tools.Engram())
+	}
+
 	dbPath := ""
 	if provider == providerLibsql {
 		dbPath = resolveDBPath(w, reader, home, opts.dbPath, opts.autoYes)
 	}
 
-	config = removeMemoryProviders(config, knownMemoryProviders)
-
-	if newEntry := buildProviderEntry(provider, dbPath); newEntry != nil {
-		existing, _ := config["mcpServers"].(map[string]interface{})
-		if existing == nil {
-			existing = make(map[string]interface{})
+	// With --migrate the old provider's data is exported before anything
+	// changes, so a failed export leaves the configuration as it was.
+	var mig *migration
+	if opts.migrate || opts.dryRun {
+		now := time.Now()
+		if mig, err = planMigration(w, currentProvider, currentPath, provider, dbPath, now); err != nil {
+			return err
+		}
+		if mig != nil {
+			writeMigrationReport(w, mig)
+		}
+		if opts.dryRun {
+			fmt.Fprintln(w)
+			platform.PrintInfo(w, "Dry run: nothing was changed")
+			return nil
+		}
+		if mig != nil && mig.data != nil {
+			if err := checkMigrationTarget(provider); err != nil {
+				return err
+			}
+			path, err := saveMigrationSource(home, mig, now)
+			if err != nil {
+				return err
+			}
+			platform.PrintOK(w, fmt.Sprintf("Saved %s data to %s", mig.from, shortenHome(path)))
 		}
-		existing[provider] = newEntry
-		config["mcpServers"] = existing
 	}
 
-	if err := platform.WriteJSONFile(claudeConfig, config); err != nil {
-		return fmt.Errorf("writing %s: %w", claudeConfig, err)
+	// ~/.claude.json is read only now, after the prompts, and under its lock,
+	// so changes made while they were answered are not lost.
+	err = platform.WithFileLock(claudeConfig, func() error {
+		var config map[string]interface{}
+		if platform.FileExists(claudeConfig) {
+			if err := platform.ReadJSONFile(claudeConfig, &config); err != nil {
+				return fmt.Errorf("reading %s: %w", claudeConfig, err)
+			}
+		}
+		if config == nil {
+			config = make(map[string]interface{})
+		}
+
+		config = removeMemoryProviders(config, knownMemoryProviders)
+
+		if newEntry := buildProviderEntry(provider, dbPath); newEntry != nil {
+			existing, _ := config["mcpServers"].(map[string]interface{})
+			if existing == nil {
+				existing = make(map[string]interface{})
+			}
+			existing[provider] = newEntry
+			config["mcpServers"] = existing
+		}
+
+		if err := platform.WriteJSONFile(claudeConfig, config); err != nil {
+			return fmt.Errorf("writing %s: %w", claudeConfig, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	if provider == providerLibsql {
@@ -457,6 +556,10 @@ func runConfigure(args []string) error {
 	}
 
 	printConfigureResult(w, provider, dbPath, currentProvider)
+
+	if mig != nil && mig.data != nil {
+		importMemoryMCP(w, map[LayerName]bool{LayerMemoryMCP: true}, &ExportMCP{Provider: provider, Data: mig.data})
+	}
 	return nil
 }
 
@@ -1,11 +1,16 @@
 package memory
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/tools"
 )
 
 func TestCountLines(t *testing.T) {
@@ -469,7 +474,7 @@ func TestImportMemoryUnsupportedVersion(t *testing.T) {
 	raw, _ := json.Marshal(data)
 	_ = os.WriteFile(path, raw, 0644)
 
-	err := importMemory(path, ParseScope("all"), false)
+	err := importMemory(path, ParseScope("all"), false, false, encryption{})
 	if err == nil {
 		t.Fatal("expected error for unsupported version, got nil")
 	}
@@ -497,7 +502,7 @@ func TestImportMemoryDryRun(t *testing.T) {
 	_ = os.WriteFile(exportPath, raw, 0644)
 
 	// confirm=false: preview only, no files should be written.
-	if err := importMemory(exportPath, ParseScope("auto"), false); err != nil {
+	if err := importMemory(exportPath, ParseScope("auto"), false, false, encryption{}); err != nil {
 		t.Fatalf("importMemory dry run: %v", err)
 	}
 	if _, err := os.Stat(restoreDir); err == nil {
@@ -527,7 +532,7 @@ func TestImportMemoryAutoMemoryRestore(t *testing.T) {
 	raw, _ := json.MarshalIndent(data, "", "  ")
 	_ = os.WriteFile(exportPath, raw, 0644)
 
-	if err := importMemory(exportPath, ParseScope("auto"), true); err != nil {
+	if err := importMemory(exportPath, ParseScope("auto"), true, false, encryption{}); err != nil {
 		t.Fatalf("importMemory: %v", err)
 	}
 
@@ -567,7 +572,7 @@ func TestImportMemoryScopeFiltering(t *testing.T) {
 	_ = os.WriteFile(exportPath, raw, 0644)
 
 	// Only restore auto scope — user CLAUDE.md should not be written.
-	if err := importMemory(exportPath, ParseScope("auto"), true); err != nil {
+	if err := importMemory(exportPath, ParseScope("auto"), true, false, encryption{}); err != nil {
 		t.Fatalf("importMemory: %v", err)
 	}
 
@@ -578,3 +583,41 @@ func TestImportMemoryScopeFiltering(t *testing.T) {
 		t.Error("auto-memory MEMORY.md should have been written")
 	}
 }
+
+func TestEnsureEngram(t *testing.T) {
+	fakeEngram := func(installed *bool, installErr error) tools.Tool {
+		return tools.Tool{
+			Name:       "engram",
+			InstallCmd: "brew install engram",
+			CheckFn:    func() bool { return *installed },
+			InstallFn: func() error {
+				*installed = installErr == nil
+				return installErr
+			},
+			VersionFn: func() (string, error) { return "", errors.New("not installed") },
+		}
+	}
+	tests := []struct {
+		name       string
+		input      string
+		autoYes    bool
+		installErr error
+		want       string
+		installed  bool
+	}{
+		{"accepted", "\n", false, nil, "Installed engram", true},
+		{"auto yes", "", true, nil, "Installed engram", true},
+		{"declined", "n\n", false, nil, "brew install engram", false},
+		{"install fails", "y\n", false, errors.New("no network"), "Could not install engram: no network", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed := false
+			var out bytes.Buffer
+			ensureEngram(&out, bufio.NewReader(strings.NewReader(tt.input)), tt.autoYes, fakeEngram(&installed, tt.installErr))
+			if installed != tt.installed || !strings.Contains(out.String(), tt.want) {
+				t.Errorf("installed = %v, output:\n%s\nwant %v and %q", installed, out.String(), tt.installed, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// MigrationDir is the directory, under the home directory, that
+// "memory configure --migrate" keeps a copy of the old provider's data in.
+const MigrationDir = ".claude-workspace/migrations"
+
+// engramExport is the document written by "engram export" and read by
+// "engram import". Only the fields a migration maps are decoded.
+type engramExport struct {
+	Version      string              `json:"version,omitempty"`
+	ExportedAt   string              `json:"exported_at,omitempty"`
+	Sessions     []engramSession     `json:"sessions"`
+	Observations []engramObservation `json:"observations"`
+	Prompts      []json.RawMessage   `json:"prompts"`
+}
+
+type engramSession struct {
+	ID        string `json:"id"`
+	Project   string `json:"project"`
+	Directory string `json:"directory"`
+	StartedAt string `json:"started_at"`
+}
+
+type engramObservation struct {
+	SessionID string `json:"session_id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Project   string `json:"project,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	TopicKey  string `json:"topic_key,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// engramTimeLayout is how engram stores timestamps (SQLite datetime()).
+const engramTimeLayout = "2006-01-02 15:04:05"
+
+// migrationSession is the engram session that migrated observations are
+// recorded under.
+const migrationSession = "claude-workspace-migration"
+
+// migration is the old provider's data converted for the new one, with the
+// counts the dry-run report shows.
+type migration struct {
+	from, to     string
+	source       *json.RawMessage // as exported by the old provider
+	data         *json.RawMessage // in the new provider's import format
+	entities     int              // knowledge graph entities read or written
+	observations int              // graph observations, or engram observations
+	relations    int
+	written      int      // records the new provider receives
+	skipped      []string // data with no counterpart in the new provider
+}
+
+// convertMemory converts data exported from provider from into the import
+// format of provider to. A knowledge graph becomes one engram observation per
+// entity, with its observations and outgoing relations as the content; engram
+// observations become entities named by their topic key or title.
+func convertMemory(from, to string, data *json.RawMessage, now time.Time) (*migration, error) {
+	m := &migration{from: from, to: to, source: data}
+	if data == nil {
+		return m, nil
+	}
+	switch from {
+	case providerLibsql:
+		g, ok := parseGraph(data)
+		if !ok {
+			return nil, fmt.Errorf("%s data is not a knowledge graph", from)
+		}
+		m.entities, m.observations, m.relations = len(g.Entities), g.observationCount(), len(g.Relations)
+		switch to {
+		case providerLibsql:
+			m.data, m.written = data, len(g.Entities)
+			return m, nil
+		case providerEngram:
+			out := graphToEngram(g, now)
+			m.written = len(out.Observations)
+			return m, m.marshal(out)
+		}
+	case providerEngram:
+		var ex engramExport
+		if err := json.Unmarshal(*data, &ex); err != nil {
+			return nil, fmt.Errorf("parsing engram export: %w", err)
+		}
+		m.observations = len(ex.Observations)
+		switch to {
+		case providerEngram:
+			m.data, m.written = data, len(ex.Observations)
+			return m, nil
+		case providerLibsql:
+			g := engramToGraph(&ex)
+			m.entities, m.written = len(g.Entities), len(g.Entities)
+			if n := len(ex.Sessions); n > 0 {
+				m.skipped = append(m.skipped, plural(n, "session summary", "session summaries"))
+			}
+			if n := len(ex.Prompts); n > 0 {
+				m.skipped = append(m.skipped, plural(n, "saved prompt", "saved prompts"))
+			}
+			return m, m.marshal(g)
+		}
+	}
+	return nil, fmt.Errorf("cannot migrate memories from %s to %s", from, to)
+}
+
+func (m *migration) marshal(v interface{}) error {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(out)
+	m.data = &raw
+	return nil
+}
+
+// graphToEngram maps each entity to an engram observation in one migration
+// session. Relations are listed under the entity they start from.
+func graphToEngram(g *memoryGraph, now time.Time) *engramExport {
+	stamp := now.UTC().Format(engramTimeLayout)
+	out := &engramExport{
+		Sessions:     []engramSession{{ID: migrationSession, Project: "claude-workspace", StartedAt: stamp}},
+		Observations: make([]engramObservation, 0, len(g.Entities)),
+		Prompts:      []json.RawMessage{},
+	}
+	outgoing := map[string][]graphRelation{}
+	for _, r := range g.Relations {
+		outgoing[r.From] = append(outgoing[r.From], r)
+	}
+	for _, e := range g.Entities {
+		var b strings.Builder
+		for _, o := range e.Observations {
+			fmt.Fprintf(&b, "- %s\n", o)
+		}
+		for _, r := range outgoing[e.Name] {
+			fmt.Fprintf(&b, "- %s %s\n", r.RelationType, r.To)
+		}
+		typ := e.EntityType
+		if typ == "" {
+			typ = "manual"
+		}
+		out.Observations = append(out.Observations, engramObservation{
+			SessionID: migrationSession,
+			Type:      typ,
+			Title:     e.Name,
+			Content:   strings.TrimSuffix(b.String(), "\n"),
+			Scope:     "personal",
+			TopicKey:  e.Name,
+			CreatedAt: stamp,
+			UpdatedAt: stamp,
+		})
+	}
+	return out
+}
+
+// engramToGraph maps engram observations to entities. Observations sharing a
+// topic key (or, without one, a title) become observations of one entity; the
+// project an observation belongs to is kept as an observation of its own.
+func engramToGraph(ex *engramExport) *memoryGraph {
+	g := &memoryGraph{Entities: []graphEntity{}, Relations: []graphRelation{}}
+	index := map[string]int{}
+	for i, o := range ex.Observations {
+		name := o.TopicKey
+		if name == "" {
+			name = o.Title
+		}
+		if name == "" {
+			name = fmt.Sprintf("engram observation %d", i+1)
+		}
+		j, ok := index[name]
+		if !ok {
+			typ := o.Type
+			if typ == "" {
+				typ = "observation"
+			}
+			j = len(g.Entities)
+			index[name] = j
+			g.Entities = append(g.Entities, graphEntity{Name: name, EntityType: typ, Observations: []string{}})
+		}
+		e := &g.Entities[j]
+		if o.Project != "" {
+			e.Observations = appendUnique(e.Observations, "project: "+o.Project)
+		}
+		if content := strings.TrimSpace(o.Content); content != "" {
+			e.Observations = appendUnique(e.Observations, content)
+		}
+	}
+	return g
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// writeMigrationReport prints what a migration transfers and leaves behind.
+func writeMigrationReport(w io.Writer, m *migration) {
+	platform.PrintSection(w, fmt.Sprintf("Migration: %s → %s", m.from, m.to))
+	if m.data == nil {
+		platform.PrintInfo(w, fmt.Sprintf("No %s data found; nothing to transfer", m.from))
+		return
+	}
+	var read, wrote string
+	if m.from == providerEngram {
+		read = plural(m.observations, "engram observation", "engram observations")
+	} else {
+		read = fmt.Sprintf("%s with %s and %s", plural(m.entities, "entity", "entities"),
+			plural(m.observations, "observation", "observations"), plural(m.relations, "relation", "relations"))
+	}
+	if m.to == providerEngram {
+		wrote = plural(m.written, "engram observation", "engram observations")
+	} else {
+		wrote = plural(m.written, "entity", "entities")
+	}
+	platform.PrintOK(w, fmt.Sprintf("%s → %s", read, wrote))
+	if m.from == providerLibsql && m.to == providerEngram && m.relations > 0 {
+		platform.PrintInfo(w, "Relations are kept as lines of the observation of the entity they start from")
+	}
+	if len(m.skipped) > 0 {
+		sort.Strings(m.skipped)
+		platform.PrintWarn(w, "Not transferred (no "+m.to+" equivalent): "+strings.Join(m.skipped, ", "))
+	}
+}
+
+// planMigration exports the current provider's data and converts it for
+// provider. It returns nil when there is nothing to migrate.
+func planMigration(w io.Writer, current, currentPath, provider, dbPath string, now time.Time) (*migration, error) {
+	switch {
+	case current == providerNone:
+		platform.PrintInfo(w, "No memory provider configured; nothing to migrate")
+		return nil, nil
+	case provider == providerNone:
+		platform.PrintWarn(w, fmt.Sprintf("Nothing to migrate to; %s data is left in place", current))
+		return nil, nil
+	case current == provider && (provider != providerLibsql || currentPath == dbPath):
+		platform.PrintInfo(w, fmt.Sprintf("Provider unchanged (%s); nothing to migrate", provider))
+		return nil, nil
+	}
+	if current == providerEngram && !platform.Exists("engram") {
+		return nil, fmt.Errorf("cannot read engram memories: engram is not installed")
+	}
+	if current == providerLibsql && !platform.FileExists(currentPath) {
+		return convertMemory(current, provider, nil, now)
+	}
+	mcp := exportMCP(&Layer{Name: LayerMemoryMCP, Provider: current, Path: currentPath}, true)
+	if mcp.Data == nil {
+		return nil, fmt.Errorf("could not export %s memories; the provider was not changed", current)
+	}
+	return convertMemory(current, provider, mcp.Data, now)
+}
+
+// checkMigrationTarget reports why provider's import cannot run here, if it
+// cannot.
+func checkMigrationTarget(provider string) error {
+	switch {
+	case provider == providerEngram && !platform.Exists("engram"):
+		return fmt.Errorf("engram is not installed; install it before migrating memories to it")
+	case provider == providerLibsql && !platform.Exists("claude"):
+		return fmt.Errorf("the claude CLI is needed to import memories into %s", providerLibsql)
+	}
+	return nil
+}
+
+// saveMigrationSource writes the old provider's data as a memory export under
+// home, so "memory import <file> --scope mcp" can restore it if the import
+// into the new provider goes wrong.
+func saveMigrationSource(home string, m *migration, now time.Time) (string, error) {
+	data := &ExportData{
+		Version:    1,
+		ExportedAt: now.UTC().Format(time.RFC3339),
+		Platform:   "claude-workspace",
+		Layers:     ExportLayers{MemoryMCP: &ExportMCP{Provider: m.from, Data: m.source}},
+	}
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshaling migration backup: %w", err)
+	}
+	dir := filepath.Join(home, filepath.FromSlash(MigrationDir))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("memory-%s-%s.json", m.from, now.Format(snapshotIDLayout)))
+	if err := platform.WriteFileAtomic(path, append(raw, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rawJSON(s string) *json.RawMessage {
+	raw := json.RawMessage(s)
+	return &raw
+}
+
+func TestConvertMemory_LibsqlToEngram(t *testing.T) {
+	graph := rawJSON(`{"entities":[
+		{"name":"Go style","entityType":"preference","observations":["tabs","short names"]},
+		{"name":"api","entityType":"","observations":[]}
+	],"relations":[{"from":"api","to":"Go style","relationType":"follows"}]}`)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	m, err := convertMemory(providerLibsql, providerEngram, graph, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.entities != 2 || m.observations != 2 || m.relations != 1 || m.written != 2 {
+		t.Errorf("counts = %+v", m)
+	}
+	var ex engramExport
+	if err := json.Unmarshal(*m.data, &ex); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Sessions) != 1 || ex.Sessions[0].ID != migrationSession {
+		t.Errorf("sessions = %+v", ex.Sessions)
+	}
+	want := []engramObservation{
+		{SessionID: migrationSession, Type: "preference", Title: "Go style", Content: "- tabs\n- short names", Scope: "personal",
+			TopicKey: "Go style", CreatedAt: "2026-05-01 12:00:00", UpdatedAt: "2026-05-01 12:00:00"},
+		{SessionID: migrationSession, Type: "manual", Title: "api", Content: "- follows Go style", Scope: "personal",
+			TopicKey: "api", CreatedAt: "2026-05-01 12:00:00", UpdatedAt: "2026-05-01 12:00:00"},
+	}
+	if len(ex.Observations) != len(want) {
+		t.Fatalf("observations = %+v", ex.Observations)
+	}
+	for i := range want {
+		if ex.Observations[i] != want[i] {
+			t.Errorf("observation %d = %+v, want %+v", i, ex.Observations[i], want[i])
+		}
+	}
+}
+
+func TestConvertMemory_EngramToLibsql(t *testing.T) {
+	export := rawJSON(`{"version":"1","sessions":[{"id":"s1","project":"api"}],"prompts":[{"id":1},{"id":2}],"observations":[
+		{"session_id":"s1","type":"decision","title":"Use sqlc","content":"Chose sqlc over gorm","project":"api","topic_key":"db/orm"},
+		{"session_id":"s1","type":"decision","title":"Use sqlc again","content":"Generated code lives in db/","project":"api","topic_key":"db/orm"},
+		{"session_id":"s1","type":"","title":"","content":"  "}
+	]}`)
+
+	m, err := convertMemory(providerEngram, providerLibsql, export, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := parseGraph(m.data)
+	if !ok {
+		t.Fatalf("converted data is not a graph: %s", *m.data)
+	}
+	if len(g.Entities) != 2 || m.observations != 3 || m.written != 2 {
+		t.Fatalf("entities = %+v, counts = %+v", g.Entities, m)
+	}
+	orm := g.entity("db/orm")
+	if orm == nil || orm.EntityType != "decision" ||
+		strings.Join(orm.Observations, "|") != "project: api|Chose sqlc over gorm|Generated code lives in db/" {
+		t.Errorf("db/orm = %+v", orm)
+	}
+	if e := g.entity("engram observation 3"); e == nil || e.EntityType != "observation" || len(e.Observations) != 0 {
+		t.Errorf("untitled observation = %+v", e)
+	}
+	if got := strings.Join(m.skipped, ", "); got != "1 session summary, 2 saved prompts" {
+		t.Errorf("skipped = %q", got)
+	}
+}
+
+func TestConvertMemory_SameFormatAndErrors(t *testing.T) {
+	graph := rawJSON(`{"entities":[{"name":"a","entityType":"t","observations":["x"]}],"relations":[]}`)
+	m, err := convertMemory(providerLibsql, providerLibsql, graph, time.Now())
+	if err != nil || m.data != graph || m.written != 1 {
+		t.Errorf("libsql → libsql = %+v, %v; want the graph unchanged", m, err)
+	}
+	if m, err := convertMemory(providerEngram, providerLibsql, nil, time.Now()); err != nil || m.data != nil {
+		t.Errorf("migration of no data = %+v, %v", m, err)
+	}
+	if _, err := convertMemory(providerLibsql, providerEngram, rawJSON(`{"observations":[]}`), time.Now()); err == nil {
+		t.Error("libsql data that is not a graph: expected error")
+	}
+	if _, err := convertMemory(providerEngram, providerNone, rawJSON(`{}`), time.Now()); err == nil {
+		t.Error("migration to none: expected error")
+	}
+}
+
+func TestWriteMigrationReport(t *testing.T) {
+	graph := rawJSON(`{"entities":[{"name":"a","entityType":"t","observations":["x","y"]}],"relations":[{"from":"a","to":"a","relationType":"r"}]}`)
+	m, err := convertMemory(providerLibsql, providerEngram, graph, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	writeMigrationReport(&buf, m)
+	out := buf.String()
+	for _, want := range []string{"mcp-memory-libsql → engram", "1 entity with 2 observations and 1 relation → 1 engram observation", "Relations are kept"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSaveMigrationSource(t *testing.T) {
+	home := t.TempDir()
+	graph := rawJSON(`{"entities":[],"relations":[]}`)
+	m, err := convertMemory(providerLibsql, providerEngram, graph, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := saveMigrationSource(home, m, time.Date(2026, 5, 1, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "memory-mcp-memory-libsql-20260501-120000.json") {
+		t.Errorf("path = %s", path)
+	}
+	data, err := readExportFile(path, encryption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcp := data.Layers.MemoryMCP
+	if mcp == nil || mcp.Provider != providerLibsql {
+		t.Fatalf("saved MCP layer = %+v", mcp)
+	}
+	if g, ok := parseGraph(mcp.Data); !ok || len(g.Entities) != 0 {
+		t.Errorf("saved graph = %s", *mcp.Data)
+	}
+}
@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// defaultPruneAge is how old memories must be before "memory prune" removes them.
+const defaultPruneAge = 90 * 24 * time.Hour
+
+// pruneSchema describes where a provider's database keeps timestamped memories.
+// Timestamps are compared with SQLite's datetime(), which accepts both
+// "YYYY-MM-DD HH:MM:SS" and RFC 3339 values.
+type pruneSchema struct {
+	table   string   // table holding individual memories
+	column  string   // creation timestamp column in table
+	cleanup []string // statements run after deleting, with %s replaced by the cutoff
+}
+
+var pruneSchemas = map[string]pruneSchema{
+	providerLibsql: {
+		table:  "observations",
+		column: "created_at",
+		cleanup: []string{
+			// Old entities left with no observations, then relations to them.
+			"DELETE FROM entities WHERE datetime(created_at) < datetime('%s') AND name NOT IN (SELECT entity_name FROM observations);",
+			"DELETE FROM relations WHERE source NOT IN (SELECT name FROM entities) OR target NOT IN (SELECT name FROM entities);",
+		},
+	},
+	providerEngram: {table: "observations", column: "created_at"},
+}
+
+// staleFile is an auto-memory file that has not been modified since the cutoff.
+type staleFile struct {
+	Name    string
+	ModTime time.Time
+}
+
+func runPrune(args []string) error {
+	age := defaultPruneAge
+	scope := "auto,mcp"
+	confirm := false
+	f := cli.New("memory prune", "memory prune [--older-than 90d] [--scope auto|mcp] [--confirm]")
+	f.Func("--older-than", "age", "Prune entries not modified for this long, such as 90d or 12w (default: 90d)", func(v string) error {
+		d, err := parseAge(v)
+		age = d
+		return err
+	})
+	f.StringVar(&scope, "--scope", "layers", "Layers to prune")
+	f.BoolVar(&confirm, "--confirm", "Delete; without it, only list what would go")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	return prune(ParseScope(scope), time.Now().Add(-age), confirm)
+}
+
+// parseAge parses an age such as "90d", "12w", or any time.ParseDuration value.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v <= 0 {
+				break
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 90d, 12w, or 720h)", s)
+	}
+	return d, nil
+}
+
+// prune removes auto-memory files and memory MCP observations created before
+// cutoff. Without confirm it only reports what would be removed.
+func prune(scope map[LayerName]bool, cutoff time.Time, confirm bool) error {
+	if !scope[LayerAutoMemory] && !scope[LayerMemoryMCP] {
+		return fmt.Errorf("prune supports --scope auto, mcp, or both")
+	}
+	layers, err := DiscoverLayers()
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	platform.PrintBanner(w, "Memory Prune")
+	fmt.Fprintf(w, "\n  Removing memories older than %s\n", cutoff.Format("2006-01-02"))
+
+	removed := 0
+	for i := range layers {
+		l := &layers[i]
+		switch {
+		case l.Name == LayerAutoMemory && scope[LayerAutoMemory]:
+			platform.PrintSection(w, "Auto-memory")
+			removed += pruneAutoMemory(w, l.Path, cutoff, confirm)
+		case l.Name == LayerMemoryMCP && scope[LayerMemoryMCP]:
+			platform.PrintSection(w, "Memory MCP")
+			removed += pruneMCP(w, l, cutoff, confirm)
+		}
+	}
+
+	if removed > 0 && !confirm {
+		fmt.Fprintf(w, "\n  Re-run with --confirm to remove, or adjust --older-than.\n")
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// staleAutoMemory lists files in dir last modified before cutoff. MEMORY.md is
+// never included: it is the index Claude loads at startup.
+func staleAutoMemory(dir string, cutoff time.Time) ([]staleFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stale []staleFile
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == "MEMORY.md" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			stale = append(stale, staleFile{Name: e.Name(), ModTime: info.ModTime()})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].ModTime.Before(stale[j].ModTime) })
+	return stale, nil
+}
+
+// pruneAutoMemory reports or removes stale auto-memory files and returns how many.
+func pruneAutoMemory(w io.Writer, dir string, cutoff time.Time, confirm bool) int {
+	stale, err := staleAutoMemory(dir, cutoff)
+	if err != nil {
+		platform.PrintFail(w, fmt.Sprintf("Reading %s: %v", shortenHome(dir), err))
+		return 0
+	}
+	if len(stale) == 0 {
+		platform.PrintOK(w, "No stale files in "+shortenHome(dir))
+		return 0
+	}
+	for _, f := range stale {
+		label := fmt.Sprintf("%s (last modified %s)", f.Name, f.ModTime.Format("2006-01-02"))
+		if !confirm {
+			fmt.Fprintf(w, "  Will remove: %s\n", label)
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, f.Name)); err != nil {
+			platform.PrintFail(w, fmt.Sprintf("%s: %v", f.Name, err))
+		} else {
+			platform.PrintOK(w, "Removed "+label)
+		}
+	}
+	if confirm {
+		platform.PrintInfo(w, "Remove references to pruned files from MEMORY.md if it links to them")
+	}
+	return len(stale)
+}
+
+// pruneMCP reports or deletes old observations in the provider's database
+// using the sqlite3 CLI, and returns how many were found.
+func pruneMCP(w io.Writer, l *Layer, cutoff time.Time, confirm bool) int {
+	schema, ok := pruneSchemas[l.Provider]
+	switch {
+	case l.Provider == providerNone:
+		platform.PrintWarn(w, "No memory MCP server configured")
+		return 0
+	case !ok:
+		platform.PrintWarn(w, fmt.Sprintf("Pruning is not supported for provider %q", l.Provider))
+		return 0
+	case !l.Exists:
+		platform.PrintOK(w, fmt.Sprintf("%s (no data yet)", shortenHome(l.Path)))
+		return 0
+	case !platform.Exists("sqlite3"):
+		platform.PrintWarn(w, "Pruning memory MCP data requires the sqlite3 CLI")
+		if l.Provider == providerLibsql {
+			fmt.Fprintf(w, "  Or remove entities in Claude with: %s\n", platform.Bold("mcp__mcp-memory-libsql__delete_entity"))
+		}
+		return 0
+	}
+
+	ts := cutoff.UTC().Format("2006-01-02 15:04:05")
+	has, err := sqliteQuery(l.Path, true, fmt.Sprintf(
+		"SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = '%s';", schema.table, schema.column))
+	if err != nil || has != "1" {
+		platform.PrintWarn(w, fmt.Sprintf("Unrecognized %s schema in %s; nothing pruned", l.Provider, shortenHome(l.Path)))
+		return 0
+	}
+	where := fmt.Sprintf("datetime(%s) < datetime('%s')", schema.column, ts)
+	out, err := sqliteQuery(l.Path, true, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s;", schema.table, where))
+	if err != nil {
+		platform.PrintFail(w, fmt.Sprintf("Querying %s: %v", shortenHome(l.Path), err))
+		return 0
+	}
+	count, _ := strconv.Atoi(out)
+	if count == 0 {
+		platform.PrintOK(w, "No stale observations in "+shortenHome(l.Path))
+		return 0
+	}
+	if !confirm {
+		fmt.Fprintf(w, "  Will remove: %d observation(s) from %s\n", count, shortenHome(l.Path))
+		return count
+	}
+
+	stmts := []string{"BEGIN;", fmt.Sprintf("DELETE FROM %s WHERE %s;", schema.table, where)}
+	for _, s := range schema.cleanup {
+		stmts = append(stmts, fmt.Sprintf(s, ts))
+	}
+	stmts = append(stmts, "COMMIT;")
+	if _, err := sqliteQuery(l.Path, false, strings.Join(stmts, "\n")); err != nil {
+		platform.PrintFail(w, fmt.Sprintf("Pruning %s: %v", shortenHome(l.Path), err))
+		return 0
+	}
+	platform.PrintOK(w, fmt.Sprintf("Removed %d observation(s) from %s", count, shortenHome(l.Path)))
+	return count
+}
+
+// sqliteQuery runs sql against db with the sqlite3 CLI.
+func sqliteQuery(db string, readOnly bool, sql string) (string, error) {
+	args := []string{}
+	if readOnly {
+		args = append(args, "-readonly")
+	}
+	args = append(args, db, sql)
+	return platform.Output("sqlite3", args...)
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func writeAged(t *testing.T, dir, name string, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStaleAutoMemory(t *testing.T) {
+	dir := t.TempDir()
+	writeAged(t, dir, "MEMORY.md", 200*24*time.Hour)
+	writeAged(t, dir, "old.md", 120*24*time.Hour)
+	writeAged(t, dir, "older.md", 150*24*time.Hour)
+	writeAged(t, dir, "fresh.md", time.Hour)
+
+	stale, err := staleAutoMemory(dir, time.Now().Add(-defaultPruneAge))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range stale {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "older.md,old.md" {
+		t.Errorf("stale files = %s, want older.md,old.md (oldest first, MEMORY.md kept)", got)
+	}
+
+	if stale, err := staleAutoMemory(filepath.Join(dir, "missing"), time.Now()); err != nil || stale != nil {
+		t.Errorf("missing dir = %v, %v; want nil, nil", stale, err)
+	}
+}
+
+func TestPruneAutoMemory(t *testing.T) {
+	dir := t.TempDir()
+	writeAged(t, dir, "old.md", 120*24*time.Hour)
+	writeAged(t, dir, "fresh.md", time.Hour)
+	cutoff := time.Now().Add(-defaultPruneAge)
+
+	var out strings.Builder
+	if n := pruneAutoMemory(&out, dir, cutoff, false); n != 1 {
+		t.Errorf("dry run found %d files, want 1", n)
+	}
+	if !strings.Contains(out.String(), "Will remove: old.md") {
+		t.Errorf("dry run output:\n%s", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.md")); err != nil {
+		t.Error("dry run removed old.md")
+	}
+
+	pruneAutoMemory(io.Discard, dir, cutoff, true)
+	if _, err := os.Stat(filepath.Join(dir, "old.md")); !os.IsNotExist(err) {
+		t.Error("old.md was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fresh.md")); err != nil {
+		t.Error("fresh.md was removed")
+	}
+}
+
+func TestPruneMCPUnsupported(t *testing.T) {
+	var out strings.Builder
+	l := &Layer{Name: LayerMemoryMCP, Provider: "memory", Exists: true}
+	if n := pruneMCP(&out, l, time.Now(), true); n != 0 {
+		t.Errorf("pruneMCP = %d, want 0", n)
+	}
+	if !strings.Contains(out.String(), "not supported") {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,667 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Memory snapshots are "memory export" files taken on a schedule, so memory
+// survives a machine rebuild. "memory snapshot run" writes one to
+// ~/.claude-workspace/snapshots/memory-<id>.json, where the id is its local
+// time, and removes the oldest beyond the number to keep. "memory snapshot
+// enable" has a scheduler run it: a launchd agent on macOS, a systemd user
+// timer on Linux, or a SessionEnd hook in ~/.claude/settings.json where
+// neither is available.
+
+const snapshotUsage = `Usage:
+  claude-workspace memory snapshot [status]
+  claude-workspace memory snapshot enable [--interval hourly|daily|weekly] [--keep <n>] [--via launchd|systemd|hook]
+  claude-workspace memory snapshot disable
+  claude-workspace memory snapshot run [--if-due]
+  claude-workspace memory snapshot list
+  claude-workspace memory snapshot restore <id|latest> [--scope=...] [--merge] [--confirm]`
+
+const (
+	snapshotPrefix   = "memory-"
+	snapshotIDLayout = "20060102-150405"
+	scheduleFile     = "schedule.json"
+	defaultKeep      = 14
+	defaultInterval  = "daily"
+
+	// snapshotLabel names the launchd agent and snapshotUnit the systemd
+	// service and timer.
+	snapshotLabel = "com.claude-workspace.memory-snapshot"
+	snapshotUnit  = "claude-workspace-memory-snapshot"
+
+	viaLaunchd = "launchd"
+	viaSystemd = "systemd"
+	viaHook    = "hook"
+
+	// snapshotHookCommand is the SessionEnd hook of --via hook.
+	snapshotHookCommand = "claude-workspace memory snapshot run --if-due"
+	snapshotHookTimeout = 60
+)
+
+// snapshotIntervals maps each --interval to how often a snapshot is due.
+var snapshotIntervals = map[string]time.Duration{
+	"hourly": time.Hour,
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// snapshotSchedule is the schedule "memory snapshot enable" installed,
+// recorded in the snapshot directory.
+type snapshotSchedule struct {
+	Interval  string `json:"interval"`
+	Keep      int    `json:"keep"`
+	Via       string `json:"via"`
+	EnabledAt string `json:"enabledAt"`
+}
+
+// snapshots holds where snapshots and scheduler files go. Tests replace its
+// fields.
+type snapshots struct {
+	home    string
+	dir     string
+	goos    string
+	exe     string // absolute path of this binary, for launchd and systemd
+	systemd bool   // a systemd user instance is available
+	// run runs launchctl and systemctl.
+	run func(name string, args ...string) error
+}
+
+func newSnapshots() (*snapshots, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locating claude-workspace binary: %w", err)
+	}
+	s := snapshotsIn(home)
+	s.exe = exe
+	s.systemd = platform.Exists("systemctl") && platform.FileExists("/run/systemd/system")
+	return s, nil
+}
+
+// runSnapshot implements "memory snapshot".
+func runSnapshot(args []string) error {
+	s, err := newSnapshots()
+	if err != nil {
+		return err
+	}
+	w := platform.Stdout()
+	sub := "status"
+	if len(args) > 0 {
+		sub, args = args[0], args[1:]
+	}
+	switch sub {
+	case "status":
+		return s.status(w)
+	case "enable":
+		sched := snapshotSchedule{Interval: defaultInterval, Keep: defaultKeep}
+		f := cli.New("memory snapshot enable", "memory snapshot enable [--interval hourly|daily|weekly] [--keep <n>] [--via launchd|systemd|hook]")
+		f.Func("--interval", "interval", "hourly, daily, or weekly (default: daily)", nonEmpty("--interval", &sched.Interval))
+		f.Func("--keep", "n", "Snapshots to keep (default: 14)", func(v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return fmt.Errorf("--keep must be a positive number, got %q", v)
+			}
+			sched.Keep = n
+			return nil
+		})
+		f.Func("--via", "scheduler", "launchd, systemd, or hook (default: the first available)", nonEmpty("--via", &sched.Via))
+		positional, err := f.Parse(args)
+		if err != nil {
+			return err
+		}
+		if err := f.CheckArgs(positional, 0, 0); err != nil {
+			return err
+		}
+		return s.enable(w, sched)
+	case "disable":
+		return s.disable(w)
+	case "run":
+		ifDue := false
+		f := cli.New("memory snapshot run", "memory snapshot run [--if-due]")
+		f.BoolVar(&ifDue, "--if-due", "Skip unless the schedule's interval has passed")
+		positional, err := f.Parse(args)
+		if err != nil {
+			return err
+		}
+		if err := f.CheckArgs(positional, 0, 0); err != nil {
+			return err
+		}
+		layers, err := DiscoverLayers()
+		if err != nil {
+			return err
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		_, err = s.take(w, layers, cwd, time.Now(), ifDue)
+		return err
+	case "list", "ls":
+		return s.list(w)
+	case "restore":
+		if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+			return fmt.Errorf("usage: claude-workspace memory snapshot restore <id|latest> [--scope=...] [--merge] [--confirm]")
+		}
+		path, err := s.find(args[0])
+		if err != nil {
+			return err
+		}
+		return runImport(append([]string{path}, args[1:]...))
+	case "--help", "-h":
+		fmt.Fprintln(w, snapshotUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown snapshot subcommand: %s\n%s", sub, snapshotUsage)
+	}
+}
+
+// readSchedule returns the installed schedule, or nil when there is none.
+func (s *snapshots) readSchedule() (*snapshotSchedule, error) {
+	path := filepath.Join(s.dir, scheduleFile)
+	if !platform.FileExists(path) {
+		return nil, nil
+	}
+	var sched snapshotSchedule
+	if err := platform.ReadJSONFile(path, &sched); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &sched, nil
+}
+
+// ids returns the ids of the snapshots taken, oldest first.
+func (s *snapshots) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), ".json")
+		if _, err := time.ParseInLocation(snapshotIDLayout, id, time.Local); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *snapshots) path(id string) string {
+	return filepath.Join(s.dir, snapshotPrefix+id+".json")
+}
+
+// find returns the path of the snapshot with id, or of the newest for
+// "latest".
+func (s *snapshots) find(id string) (string, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return "", err
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no memory snapshots in %s", s.dir)
+	}
+	if id == "latest" {
+		return s.path(ids[len(ids)-1]), nil
+	}
+	for _, have := range ids {
+		if have == strings.TrimSuffix(strings.TrimPrefix(id, snapshotPrefix), ".json") {
+			return s.path(have), nil
+		}
+	}
+	return "", fmt.Errorf("no memory snapshot %s (see claude-workspace memory snapshot list)", id)
+}
+
+// take writes a snapshot of layers, taken in cwd, and removes the oldest
+// beyond the schedule's number to keep. With ifDue, nothing is taken until the
+// schedule's interval has nearly passed since the last snapshot, so a
+// scheduler that fires a little early, or a hook that fires often, does not
+// take extra ones. It returns the new snapshot's path, or "" if none was due.
+func (s *snapshots) take(w io.Writer, layers []Layer, cwd string, now time.Time, ifDue bool) (string, error) {
+	sched, err := s.readSchedule()
+	if err != nil {
+		return "", err
+	}
+	if sched == nil {
+		sched = &snapshotSchedule{Interval: defaultInterval, Keep: defaultKeep}
+	}
+	ids, err := s.ids()
+	if err != nil {
+		return "", err
+	}
+	id := now.Format(snapshotIDLayout)
+	if len(ids) > 0 {
+		last, _ := time.ParseInLocation(snapshotIDLayout, ids[len(ids)-1], time.Local)
+		interval := snapshotIntervals[sched.Interval]
+		if ids[len(ids)-1] >= id || (ifDue && now.Sub(last) < interval*9/10) {
+			fmt.Fprintf(w, "Latest memory snapshot %s is recent; none taken.\n", ids[len(ids)-1])
+			return "", nil
+		}
+	}
+
+	var fileLayers []Layer
+	var mcp *ExportMCP
+	for _, l := range layers {
+		if l.Name != LayerMemoryMCP {
+			fileLayers = append(fileLayers, l)
+		} else if l.Provider != "" && l.Provider != providerNone {
+			// Claude is not asked for the graph: a snapshot must not start a
+			// session, least of all from a SessionEnd hook.
+			mcp = exportMCP(&l, false)
+		}
+	}
+	data := buildExport(fileLayers, cwd)
+	data.ExportedAt = now.UTC().Format(time.RFC3339)
+	data.Layers.MemoryMCP = mcp
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshaling snapshot: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return "", fmt.Errorf("creating %s: %w", s.dir, err)
+	}
+	path := s.path(id)
+	if err := platform.WriteFileAtomic(path, append(raw, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	platform.PrintOK(w, "Memory snapshot "+id+" saved to "+shortenHome(path))
+
+	ids = append(ids, id)
+	for sched.Keep > 0 && len(ids) > sched.Keep {
+		if err := os.Remove(s.path(ids[0])); err != nil && !os.IsNotExist(err) {
+			return path, fmt.Errorf("removing old snapshot: %w", err)
+		}
+		ids = ids[1:]
+	}
+	return path, nil
+}
+
+// list prints the snapshots, newest first.
+func (s *snapshots) list(w io.Writer) error {
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "Memory Snapshots")
+	fmt.Fprintln(w)
+	if len(ids) == 0 {
+		fmt.Fprintf(w, "  No snapshots in %s\n", shortenHome(s.dir))
+		platform.PrintCommand(w, "claude-workspace memory snapshot enable")
+		return nil
+	}
+	for i := len(ids) - 1; i >= 0; i-- {
+		id := ids[i]
+		detail := ""
+		if data, err := readExportFile(s.path(id), encryption{}); err != nil {
+			detail = "unreadable: " + err.Error()
+		} else if data.Layers.ProjectClaudeMD != nil && data.Layers.ProjectClaudeMD.Project != "" {
+			detail = shortenHome(data.Layers.ProjectClaudeMD.Project)
+		}
+		size := ""
+		if info, err := os.Stat(s.path(id)); err == nil {
+			size = fmt.Sprintf("%.1f KB", float64(info.Size())/1024)
+		}
+		fmt.Fprintf(w, "  %-17s %10s  %s\n", id, size, detail)
+	}
+	fmt.Fprintln(w)
+	platform.PrintCommand(w, "claude-workspace memory snapshot restore <id> --merge")
+	return nil
+}
+
+// status prints the schedule and the latest snapshot.
+func (s *snapshots) status(w io.Writer) error {
+	sched, err := s.readSchedule()
+	if err != nil {
+		return err
+	}
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "Memory Snapshots")
+	fmt.Fprintln(w)
+	if sched == nil {
+		fmt.Fprintln(w, "  Schedule:  not enabled")
+	} else {
+		fmt.Fprintf(w, "  Schedule:  %s via %s, keeping %d\n", sched.Interval, sched.Via, sched.Keep)
+	}
+	fmt.Fprintf(w, "  Directory: %s\n", shortenHome(s.dir))
+	if len(ids) == 0 {
+		fmt.Fprintln(w, "  Snapshots: none")
+	} else {
+		fmt.Fprintf(w, "  Snapshots: %d, latest %s\n", len(ids), ids[len(ids)-1])
+	}
+	if sched == nil {
+		platform.PrintCommand(w, "claude-workspace memory snapshot enable --interval daily --keep 14")
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// enable installs sched, replacing a schedule installed before.
+func (s *snapshots) enable(w io.Writer, sched snapshotSchedule) error {
+	if _, ok := snapshotIntervals[sched.Interval]; !ok {
+		return fmt.Errorf("unknown interval %q: use hourly, daily, or weekly", sched.Interval)
+	}
+	switch sched.Via {
+	case "":
+		sched.Via = s.defaultVia()
+	case viaLaunchd, viaSystemd, viaHook:
+	default:
+		return fmt.Errorf("unknown scheduler %q: use launchd, systemd, or hook", sched.Via)
+	}
+	if prev, err := s.readSchedule(); err != nil {
+		return err
+	} else if prev != nil {
+		if err := s.uninstall(prev.Via); err != nil {
+			return err
+		}
+	}
+
+	var where string
+	var err error
+	switch sched.Via {
+	case viaLaunchd:
+		where, err = s.installLaunchd(sched)
+	case viaSystemd:
+		where, err = s.installSystemd(sched)
+	case viaHook:
+		where, err = s.installHook()
+	}
+	if err != nil {
+		return fmt.Errorf("installing the %s schedule: %w", sched.Via, err)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", s.dir, err)
+	}
+	sched.EnabledAt = time.Now().UTC().Format(time.RFC3339)
+	if err := platform.WriteJSONFile(filepath.Join(s.dir, scheduleFile), sched); err != nil {
+		return err
+	}
+
+	platform.PrintOK(w, fmt.Sprintf("Memory snapshots enabled: %s via %s, keeping %d", sched.Interval, sched.Via, sched.Keep))
+	fmt.Fprintf(w, "  Installed: %s\n", shortenHome(where))
+	fmt.Fprintf(w, "  Snapshots: %s\n", shortenHome(s.dir))
+	if sched.Via == viaHook {
+		platform.PrintManual(w, "Snapshots are taken when a Claude Code session ends, at most "+sched.Interval)
+	} else {
+		platform.PrintManual(w, "Scheduled snapshots cover user memory and the memory MCP; project layers are those of your home directory")
+	}
+	return nil
+}
+
+// disable removes the installed schedule. Snapshots already taken are kept.
+func (s *snapshots) disable(w io.Writer) error {
+	sched, err := s.readSchedule()
+	if err != nil {
+		return err
+	}
+	if sched == nil {
+		fmt.Fprintln(w, "Memory snapshots are not enabled.")
+		return nil
+	}
+	if err := s.uninstall(sched.Via); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.dir, scheduleFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	platform.PrintOK(w, "Memory snapshots disabled ("+sched.Via+"); existing snapshots kept in "+shortenHome(s.dir))
+	return nil
+}
+
+// defaultVia picks the scheduler for this machine.
+func (s *snapshots) defaultVia() string {
+	switch {
+	case s.goos == "darwin":
+		return viaLaunchd
+	case s.goos == "linux" && s.systemd:
+		return viaSystemd
+	}
+	return viaHook
+}
+
+func (s *snapshots) launchdPlist() string {
+	return filepath.Join(s.home, "Library", "LaunchAgents", snapshotLabel+".plist")
+}
+
+func (s *snapshots) systemdDir() string {
+	return filepath.Join(s.home, ".config", "systemd", "user")
+}
+
+// installLaunchd writes and loads a launchd agent that runs the snapshot
+// every interval, and at login to catch up after the machine was off.
+func (s *snapshots) installLaunchd(sched snapshotSchedule) (string, error) {
+	path := s.launchdPlist()
+	var args strings.Builder
+	for _, a := range []string{s.exe, "memory", "snapshot", "run", "--if-due"} {
+		fmt.Fprintf(&args, "\n    <string>%s</string>", xmlEscape(a))
+	}
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>%s</string>
+  <key>ProgramArguments</key>
+  <array>%s
+  </array>
+  <key>WorkingDirectory</key>
+  <string>%s</string>
+  <key>StartInterval</key>
+  <integer>%d</integer>
+  <key>RunAtLoad</key>
+  <true/>
+</dict>
+</plist>
+`, snapshotLabel, args.String(), xmlEscape(s.home), int(snapshotIntervals[sched.Interval].Seconds()))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(plist), 0644); err != nil {
+		return "", err
+	}
+	_ = s.run("launchctl", "unload", path)
+	return path, s.run("launchctl", "load", "-w", path)
+}
+
+// installSystemd writes a oneshot service and a persistent timer, so a run
+// missed while the machine was off happens at the next boot, and starts the
+// timer.
+func (s *snapshots) installSystemd(sched snapshotSchedule) (string, error) {
+	dir := s.systemdDir()
+	service := fmt.Sprintf(`[Unit]
+Description=claude-workspace memory snapshot
+
+[Service]
+Type=oneshot
+WorkingDirectory=%%h
+ExecStart=%s memory snapshot run --if-due
+`, strconv.Quote(s.exe))
+	timer := fmt.Sprintf(`[Unit]
+Description=Take a claude-workspace memory snapshot %s
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, sched.Interval, sched.Interval)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, snapshotUnit+".service"), []byte(service), 0644); err != nil {
+		return "", err
+	}
+	timerPath := filepath.Join(dir, snapshotUnit+".timer")
+	if err := os.WriteFile(timerPath, []byte(timer), 0644); err != nil {
+		return "", err
+	}
+	if err := s.run("systemctl", "--user", "daemon-reload"); err != nil {
+		return "", err
+	}
+	return timerPath, s.run("systemctl", "--user", "enable", "--now", snapshotUnit+".timer")
+}
+
+// installHook adds the snapshot SessionEnd hook to the user settings.
+func (s *snapshots) installHook() (string, error) {
+	path := filepath.Join(s.home, ".claude", "settings.json")
+	return path, editUserHooks(path, func(hooks map[string]interface{}) {
+		removeSnapshotHooks(hooks)
+		entries, _ := hooks["SessionEnd"].([]interface{})
+		hooks["SessionEnd"] = append(entries, map[string]interface{}{
+			"hooks": []interface{}{map[string]interface{}{
+				"type":    "command",
+				"command": snapshotHookCommand,
+				"timeout": snapshotHookTimeout,
+			}},
+		})
+	})
+}
+
+// uninstall removes what installing the via scheduler added.
+func (s *snapshots) uninstall(via string) error {
+	switch via {
+	case viaLaunchd:
+		path := s.launchdPlist()
+		if platform.FileExists(path) {
+			_ = s.run("launchctl", "unload", "-w", path)
+			return os.Remove(path)
+		}
+	case viaSystemd:
+		_ = s.run("systemctl", "--user", "disable", "--now", snapshotUnit+".timer")
+		for _, ext := range []string{".timer", ".service"} {
+			if err := os.Remove(filepath.Join(s.systemdDir(), snapshotUnit+ext)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		_ = s.run("systemctl", "--user", "daemon-reload")
+	case viaHook:
+		path := filepath.Join(s.home, ".claude", "settings.json")
+		if platform.FileExists(path) {
+			return editUserHooks(path, removeSnapshotHooks)
+		}
+	}
+	return nil
+}
+
+// editUserHooks applies edit to the "hooks" object of the settings file at
+// path under its lock, dropping the key if edit leaves it empty.
+func editUserHooks(path string, edit func(hooks map[string]interface{})) error {
+	return platform.WithFileLock(path, func() error {
+		settings := map[string]interface{}{}
+		if platform.FileExists(path) {
+			if err := platform.ReadJSONFile(path, &settings); err != nil {
+				return fmt.Errorf("reading %s: %w", path, err)
+			}
+		}
+		hooks, _ := settings["hooks"].(map[string]interface{})
+		if hooks == nil {
+			hooks = map[string]interface{}{}
+		}
+		edit(hooks)
+		if len(hooks) == 0 {
+			delete(settings, "hooks")
+		} else {
+			settings["hooks"] = hooks
+		}
+		return platform.WriteSettingsFile(path, settings)
+	})
+}
+
+// removeSnapshotHooks drops the snapshot hook from every SessionEnd entry,
+// and entries it leaves empty.
+func removeSnapshotHooks(hooks map[string]interface{}) {
+	entries, _ := hooks["SessionEnd"].([]interface{})
+	var kept []interface{}
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			kept = append(kept, e)
+			continue
+		}
+		list, _ := entry["hooks"].([]interface{})
+		var remaining []interface{}
+		for _, h := range list {
+			if hook, _ := h.(map[string]interface{}); hook == nil || !isSnapshotCommand(hook["command"]) {
+				remaining = append(remaining, h)
+			}
+		}
+		if len(remaining) > 0 {
+			entry["hooks"] = remaining
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == 0 {
+		delete(hooks, "SessionEnd")
+	} else {
+		hooks["SessionEnd"] = kept
+	}
+}
+
+// isSnapshotCommand reports whether a hook command runs the snapshot.
+func isSnapshotCommand(command interface{}) bool {
+	s, _ := command.(string)
+	fields := strings.Fields(s)
+	return len(fields) > 3 && filepath.Base(fields[0]) == "claude-workspace" &&
+		fields[1] == "memory" && fields[2] == "snapshot" && fields[3] == "run"
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// SnapshotDir is the directory, under the home directory, that memory
+// snapshots are kept in.
+const SnapshotDir = ".claude-workspace/snapshots"
+
+// SnapshotSchedule returns the scheduler ("launchd", "systemd", or "hook")
+// taking memory snapshots for home, or "" when none is enabled.
+func SnapshotSchedule(home string) (string, error) {
+	sched, err := snapshotsIn(home).readSchedule()
+	if err != nil || sched == nil {
+		return "", err
+	}
+	return sched.Via, nil
+}
+
+// DisableSnapshots removes the snapshot schedule of home, if any, keeping the
+// snapshots.
+func DisableSnapshots(home string) error {
+	return snapshotsIn(home).disable(io.Discard)
+}
+
+// snapshotsIn returns the snapshots of home with the real schedulers.
+func snapshotsIn(home string) *snapshots {
+	return &snapshots{
+		home: home,
+		dir:  filepath.Join(home, filepath.FromSlash(SnapshotDir)),
+		goos: runtime.GOOS,
+		run:  platform.RunQuiet,
+	}
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSnapshots(t *testing.T, goos string, systemd bool) (*snapshots, *[]string) {
+	t.Helper()
+	home := t.TempDir()
+	var ran []string
+	return &snapshots{
+		home:    home,
+		dir:     filepath.Join(home, ".claude-workspace", "snapshots"),
+		goos:    goos,
+		exe:     "/opt/bin/claude-workspace",
+		systemd: systemd,
+		run: func(name string, args ...string) error {
+			ran = append(ran, name+" "+strings.Join(args, " "))
+			return nil
+		},
+	}, &ran
+}
+
+func TestSnapshotTakeAndRotate(t *testing.T) {
+	s, _ := newTestSnapshots(t, "linux", false)
+	userMD := filepath.Join(s.home, ".claude", "CLAUDE.md")
+	if err := writeFileContent(userMD, "prefs\n"); err != nil {
+		t.Fatal(err)
+	}
+	layers := []Layer{discoverFileLayer(LayerUserClaudeMD, "User CLAUDE.md", userMD)}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	sched, _ := json.Marshal(snapshotSchedule{Interval: "daily", Keep: 2, Via: viaHook})
+	if err := os.WriteFile(filepath.Join(s.dir, scheduleFile), sched, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	for day := 0; day < 3; day++ {
+		path, err := s.take(io.Discard, layers, s.home, start.AddDate(0, 0, day), true)
+		if err != nil || path == "" {
+			t.Fatalf("day %d: take = %q, %v", day, path, err)
+		}
+	}
+	// A hook firing again the same day takes nothing; without --if-due it does.
+	if path, err := s.take(io.Discard, layers, s.home, start.AddDate(0, 0, 2).Add(time.Hour), true); err != nil || path != "" {
+		t.Errorf("take --if-due within the interval = %q, %v", path, err)
+	}
+	if path, err := s.take(io.Discard, layers, s.home, start.AddDate(0, 0, 2).Add(time.Hour), false); err != nil || path == "" {
+		t.Errorf("take within the interval = %q, %v", path, err)
+	}
+
+	ids, err := s.ids()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "20260303-090000,20260303-100000"; strings.Join(ids, ",") != want {
+		t.Errorf("ids after rotation = %v, want %s", ids, want)
+	}
+	latest, err := s.find("latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := readExportFile(latest, encryption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := data.Layers.UserClaudeMD.Content; c == nil || *c != "prefs\n" {
+		t.Errorf("snapshot user CLAUDE.md = %v", c)
+	}
+	if info, _ := os.Stat(latest); info.Mode().Perm() != 0600 {
+		t.Errorf("snapshot mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := s.find("20260101-000000"); err == nil {
+		t.Error("find of an unknown id: expected error")
+	}
+}
+
+func TestSnapshotEnableSystemd(t *testing.T) {
+	s, ran := newTestSnapshots(t, "linux", true)
+	if err := s.enable(io.Discard, snapshotSchedule{Interval: "weekly", Keep: 5}); err != nil {
+		t.Fatal(err)
+	}
+	timer := readFile(t, filepath.Join(s.systemdDir(), snapshotUnit+".timer"))
+	if !strings.Contains(timer, "OnCalendar=weekly") || !strings.Contains(timer, "Persistent=true") {
+		t.Errorf("timer:\n%s", timer)
+	}
+	service := readFile(t, filepath.Join(s.systemdDir(), snapshotUnit+".service"))
+	if !strings.Contains(service, `ExecStart="/opt/bin/claude-workspace" memory snapshot run --if-due`) {
+		t.Errorf("service:\n%s", service)
+	}
+	if got := strings.Join(*ran, "; "); !strings.Contains(got, "systemctl --user enable --now "+snapshotUnit+".timer") {
+		t.Errorf("ran %s", got)
+	}
+	sched, err := s.readSchedule()
+	if err != nil || sched == nil || sched.Via != viaSystemd || sched.Keep != 5 {
+		t.Fatalf("schedule = %+v, %v", sched, err)
+	}
+
+	if err := s.disable(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.systemdDir(), snapshotUnit+".timer")); !os.IsNotExist(err) {
+		t.Error("timer left after disable")
+	}
+	if sched, _ := s.readSchedule(); sched != nil {
+		t.Errorf("schedule left after disable: %+v", sched)
+	}
+}
+
+func TestSnapshotEnableHook(t *testing.T) {
+	s, ran := newTestSnapshots(t, "linux", false)
+	settings := filepath.Join(s.home, ".claude", "settings.json")
+	other := `{"model":"opus","hooks":{"SessionEnd":[{"hooks":[{"type":"command","command":"echo bye"}]}]}}`
+	if err := writeFileContent(settings, other); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enabling twice replaces the hook instead of adding a second one.
+	for i := 0; i < 2; i++ {
+		if err := s.enable(io.Discard, snapshotSchedule{Interval: "daily", Keep: 14}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*ran) != 0 {
+		t.Errorf("hook scheduler ran %v", *ran)
+	}
+	if got := readFile(t, settings); strings.Count(got, snapshotHookCommand) != 1 || !strings.Contains(got, "echo bye") {
+		t.Errorf("settings.json:\n%s", got)
+	}
+
+	if err := s.disable(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal([]byte(readFile(t, settings)), &root); err != nil {
+		t.Fatal(err)
+	}
+	entries := root["hooks"].(map[string]interface{})["SessionEnd"].([]interface{})
+	if len(entries) != 1 || root["model"] != "opus" {
+		t.Errorf("settings after disable = %v", root)
+	}
+}
+
+func TestSnapshotEnableLaunchdAndErrors(t *testing.T) {
+	s, ran := newTestSnapshots(t, "darwin", false)
+	if err := s.enable(io.Discard, snapshotSchedule{Interval: "hourly", Keep: 3}); err != nil {
+		t.Fatal(err)
+	}
+	plist := readFile(t, s.launchdPlist())
+	for _, want := range []string{"<string>" + snapshotLabel + "</string>", "<integer>3600</integer>", "<string>--if-due</string>"} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %s:\n%s", want, plist)
+		}
+	}
+	if got := strings.Join(*ran, "; "); !strings.Contains(got, "launchctl load -w "+s.launchdPlist()) {
+		t.Errorf("ran %s", got)
+	}
+
+	if err := s.enable(io.Discard, snapshotSchedule{Interval: "monthly", Keep: 3}); err == nil {
+		t.Error("enable accepted an unknown interval")
+	}
+	if err := s.enable(io.Discard, snapshotSchedule{Interval: "daily", Keep: 3, Via: "cron"}); err == nil {
+		t.Error("enable accepted an unknown scheduler")
+	}
+}
@@ -0,0 +1,440 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// A minimal read-only reader for SQLite database files, which is also the
+// on-disk format of libsql. It supports what the memory providers need: listing
+// tables and scanning every row of a table, including rows whose payload spills
+// onto overflow pages, and pages committed to a write-ahead log that has not
+// been checkpointed yet. Indexes, views, and WITHOUT ROWID tables are not read.
+//
+// Format reference: https://www.sqlite.org/fileformat2.html
+
+var errNotSQLite = errors.New("not a SQLite database")
+
+// sqliteDB is an SQLite database file loaded into memory.
+type sqliteDB struct {
+	data     []byte
+	pageSize int
+	usable   int            // page size minus reserved bytes
+	wal      map[int][]byte // page number → latest committed page image from the WAL
+	tables   map[string]sqliteTable
+}
+
+// sqliteTable is a rowid table from the schema.
+type sqliteTable struct {
+	rootPage int
+	columns  []string
+	rowidCol int // index of the INTEGER PRIMARY KEY column aliasing the rowid, or -1
+}
+
+// openSQLite reads the database at path and its -wal file, if any. The files
+// are only read, never locked or modified.
+func openSQLite(path string) (*sqliteDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 100 || string(data[:16]) != "SQLite format 3\x00" {
+		return nil, errNotSQLite
+	}
+	db := &sqliteDB{data: data}
+	db.pageSize = int(binary.BigEndian.Uint16(data[16:18]))
+	if db.pageSize == 1 {
+		db.pageSize = 65536
+	}
+	db.usable = db.pageSize - int(data[20])
+	if db.pageSize < 512 || db.pageSize&(db.pageSize-1) != 0 || db.usable < 480 {
+		return nil, fmt.Errorf("invalid page size %d (corrupt database?)", db.pageSize)
+	}
+	if enc := binary.BigEndian.Uint32(data[56:60]); enc != 0 && enc != 1 {
+		return nil, fmt.Errorf("unsupported text encoding %d (only UTF-8 is supported)", enc)
+	}
+	if wal, err := os.ReadFile(path + "-wal"); err == nil {
+		db.wal = readWAL(wal, db.pageSize)
+	}
+	if err := db.loadSchema(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// readWAL returns the page images from the committed transactions in a
+// write-ahead log. Frames after the last commit, or from an older generation of
+// the log (a salt mismatch), are ignored.
+func readWAL(wal []byte, pageSize int) map[int][]byte {
+	const headerSize, frameHeaderSize = 32, 24
+	if len(wal) < headerSize || int(binary.BigEndian.Uint32(wal[8:12])) != pageSize {
+		return nil
+	}
+	salt := wal[16:24]
+	committed := map[int][]byte{}
+	pending := map[int][]byte{}
+	for off := headerSize; off+frameHeaderSize+pageSize <= len(wal); off += frameHeaderSize + pageSize {
+		frame := wal[off : off+frameHeaderSize]
+		if !bytes.Equal(frame[8:16], salt) {
+			break
+		}
+		pending[int(binary.BigEndian.Uint32(frame[0:4]))] = wal[off+frameHeaderSize : off+frameHeaderSize+pageSize]
+		if binary.BigEndian.Uint32(frame[4:8]) != 0 { // commit frame
+			for n, p := range pending {
+				committed[n] = p
+			}
+			pending = map[int][]byte{}
+		}
+	}
+	return committed
+}
+
+// page returns page n (1-based).
+func (db *sqliteDB) page(n int) ([]byte, error) {
+	if p, ok := db.wal[n]; ok {
+		return p, nil
+	}
+	start := (n - 1) * db.pageSize
+	if n < 1 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("page %d out of range", n)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// loadSchema reads the table definitions from sqlite_schema (page 1).
+func (db *sqliteDB) loadSchema() error {
+	db.tables = map[string]sqliteTable{}
+	return db.scan(1, func(_ int64, rec []any) error {
+		if len(rec) < 5 || rec[0] != "table" {
+			return nil
+		}
+		name, _ := rec[1].(string)
+		root, _ := rec[3].(int64)
+		sql, _ := rec[4].(string)
+		if root == 0 || strings.Contains(strings.ToUpper(sql), "WITHOUT ROWID") {
+			return nil
+		}
+		cols, rowidCol := parseColumns(sql)
+		db.tables[name] = sqliteTable{rootPage: int(root), columns: cols, rowidCol: rowidCol}
+		return nil
+	})
+}
+
+// rows returns every row of table as column name → value. Values are int64,
+// float64, string, []byte, or nil.
+func (db *sqliteDB) rows(table string) ([]map[string]any, error) {
+	t, ok := db.tables[table]
+	if !ok {
+		return nil, fmt.Errorf("no such table: %s", table)
+	}
+	var rows []map[string]any
+	err := db.scan(t.rootPage, func(rowid int64, rec []any) error {
+		row := make(map[string]any, len(t.columns))
+		for i, col := range t.columns {
+			switch {
+			case i == t.rowidCol:
+				row[col] = rowid
+			case i < len(rec):
+				row[col] = rec[i]
+			default:
+				row[col] = nil // column added by ALTER TABLE after the row was written
+			}
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// hasColumns reports whether table exists with all of the given columns.
+func (db *sqliteDB) hasColumns(table string, cols ...string) bool {
+	t, ok := db.tables[table]
+	if !ok {
+		return false
+	}
+	for _, want := range cols {
+		found := false
+		for _, c := range t.columns {
+			if strings.EqualFold(c, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// scan walks the table b-tree rooted at root in rowid order, calling fn with
+// each row's rowid and decoded record. The file is read without a lock while
+// the MCP server may be writing it, so a torn or truncated page is expected:
+// every offset read from a page is checked, and an inconsistent one is an
+// error rather than a panic.
+func (db *sqliteDB) scan(root int, fn func(rowid int64, rec []any) error) error {
+	return db.scanPage(root, fn, 0)
+}
+
+func (db *sqliteDB) scanPage(n int, fn func(int64, []any) error, depth int) error {
+	if depth > 64 {
+		return fmt.Errorf("b-tree too deep (corrupt database?)")
+	}
+	page, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if n == 1 {
+		hdr = 100 // page 1 starts with the database header
+	}
+	if hdr+12 > len(page) {
+		return fmt.Errorf("page %d: truncated header", n)
+	}
+	kind := page[hdr]
+	cells := int(binary.BigEndian.Uint16(page[hdr+3 : hdr+5]))
+	ptrs := hdr + 8
+	if kind == 0x05 {
+		ptrs = hdr + 12
+	}
+	if ptrs+2*cells > len(page) {
+		return fmt.Errorf("page %d: cell pointers out of range", n)
+	}
+	// cell returns the offset of cell i, checked to leave at least need bytes.
+	cell := func(i, need int) (int, error) {
+		off := int(binary.BigEndian.Uint16(page[ptrs+2*i:]))
+		if off < ptrs+2*cells || off+need > len(page) {
+			return 0, fmt.Errorf("page %d cell %d: offset %d out of range", n, i, off)
+		}
+		return off, nil
+	}
+
+	switch kind {
+	case 0x05: // interior table page
+		for i := 0; i < cells; i++ {
+			off, err := cell(i, 4)
+			if err != nil {
+				return err
+			}
+			child := int(binary.BigEndian.Uint32(page[off : off+4]))
+			if err := db.scanPage(child, fn, depth+1); err != nil {
+				return err
+			}
+		}
+		right := int(binary.BigEndian.Uint32(page[hdr+8 : hdr+12]))
+		return db.scanPage(right, fn, depth+1)
+	case 0x0d: // leaf table page
+		for i := 0; i < cells; i++ {
+			off, err := cell(i, 1)
+			if err != nil {
+				return err
+			}
+			size, k := readVarint(page[off:])
+			off += k
+			rowid, k2 := readVarint(page[off:])
+			off += k2
+			if k == 0 || k2 == 0 || size < 0 || off > len(page) {
+				return fmt.Errorf("page %d cell %d: bad cell header", n, i)
+			}
+			payload, err := db.payload(page[off:], int(size))
+			if err != nil {
+				return err
+			}
+			rec, err := decodeRecord(payload)
+			if err != nil {
+				return fmt.Errorf("page %d cell %d: %w", n, i, err)
+			}
+			if err := fn(rowid, rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("page %d: unexpected b-tree page type 0x%02x", n, kind)
+	}
+}
+
+// payload assembles a table leaf cell's payload of the given size, following
+// overflow pages when it does not fit on the page.
+func (db *sqliteDB) payload(cell []byte, size int) ([]byte, error) {
+	maxLocal := db.usable - 35
+	if size <= maxLocal {
+		if size > len(cell) {
+			return nil, fmt.Errorf("cell payload out of range")
+		}
+		return cell[:size], nil
+	}
+	if size > len(db.data)+len(db.wal)*db.pageSize {
+		return nil, fmt.Errorf("cell payload of %d bytes is larger than the database", size)
+	}
+	minLocal := (db.usable-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(db.usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	if local+4 > len(cell) {
+		return nil, fmt.Errorf("cell payload out of range")
+	}
+	out := make([]byte, 0, size)
+	out = append(out, cell[:local]...)
+	next := int(binary.BigEndian.Uint32(cell[local : local+4]))
+	for len(out) < size {
+		if next == 0 {
+			return nil, fmt.Errorf("overflow chain ended early")
+		}
+		p, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := min(size-len(out), db.usable-4)
+		if 4+chunk > len(p) {
+			return nil, fmt.Errorf("overflow page %d out of range", next)
+		}
+		next = int(binary.BigEndian.Uint32(p[0:4]))
+		out = append(out, p[4:4+chunk]...)
+	}
+	return out, nil
+}
+
+// decodeRecord decodes an SQLite record into its column values.
+func decodeRecord(payload []byte) ([]any, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || int(headerSize) > len(payload) {
+		return nil, fmt.Errorf("bad record header")
+	}
+	var types []int64
+	for off := n; off < int(headerSize); {
+		t, k := readVarint(payload[off:])
+		if k == 0 {
+			return nil, fmt.Errorf("bad record header")
+		}
+		types = append(types, t)
+		off += k
+	}
+
+	values := make([]any, 0, len(types))
+	body := payload[headerSize:]
+	for _, t := range types {
+		var size int
+		switch {
+		case t >= 1 && t <= 4:
+			size = int(t)
+		case t == 5:
+			size = 6
+		case t == 6 || t == 7:
+			size = 8
+		case t >= 12:
+			size = int(t-12) / 2
+		}
+		if size > len(body) {
+			return nil, fmt.Errorf("record value out of range")
+		}
+		v := body[:size]
+		body = body[size:]
+
+		switch {
+		case t == 0:
+			values = append(values, nil)
+		case t >= 1 && t <= 6:
+			// Big-endian two's complement integer of 1-8 bytes.
+			var x int64
+			if v[0]&0x80 != 0 {
+				x = -1
+			}
+			for _, b := range v {
+				x = x<<8 | int64(b)
+			}
+			values = append(values, x)
+		case t == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(v)))
+		case t == 8:
+			values = append(values, int64(0))
+		case t == 9:
+			values = append(values, int64(1))
+		case t >= 12 && t%2 == 0:
+			values = append(values, v)
+		case t >= 13:
+			values = append(values, string(v))
+		default:
+			return nil, fmt.Errorf("reserved serial type %d", t)
+		}
+	}
+	return values, nil
+}
+
+// readVarint decodes an SQLite varint and returns it with its length in bytes
+// (0 if b is too short).
+func readVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return int64(v<<8 | uint64(b[i])), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return int64(v), i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parseColumns extracts column names from a CREATE TABLE statement and the
+// index of the INTEGER PRIMARY KEY column, whose value is the rowid (-1 if none).
+func parseColumns(sql string) ([]string, int) {
+	open, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if open < 0 || end <= open {
+		return nil, -1
+	}
+	var cols []string
+	rowidCol := -1
+	for _, def := range splitTopLevel(sql[open+1 : end]) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "CONSTRAINT":
+			continue // table constraint, not a column
+		}
+		upper := strings.ToUpper(strings.Join(fields, " "))
+		if len(fields) > 1 && strings.ToUpper(fields[1]) == "INTEGER" && strings.Contains(upper, "PRIMARY KEY") && !strings.Contains(upper, " DESC") {
+			rowidCol = len(cols)
+		}
+		cols = append(cols, strings.Trim(fields[0], "\"`[]'"))
+	}
+	return cols, rowidCol
+}
+
+// splitTopLevel splits s on commas that are not inside parentheses or quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,484 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Memory sync keeps personal memory layers in a private git repository so they
+// follow a developer between machines. The repository holds two kinds of
+// ExportData files:
+//
+//	user.json               user CLAUDE.md and the memory MCP graph
+//	projects/<project>.json CLAUDE.local.md and auto-memory for one project
+//
+// Project CLAUDE.md is not synced; it already travels with the project's own
+// repository. Projects are keyed by their path relative to the home directory,
+// so ~/code/app on a laptop and ~/code/app on a desktop share one file.
+//
+// Each entry (a file layer, one auto-memory file, or the MCP graph) is merged
+// three-way against the content hash recorded at the last sync, which is kept
+// in the clone's .git directory so it never leaves the machine.
+
+const (
+	syncUserFile  = "user.json"
+	syncStateFile = "claude-workspace-sync.json"
+)
+
+// syncAction is the outcome of comparing one entry's local and remote content.
+type syncAction int
+
+const (
+	syncNone     syncAction = iota // identical, or only the local side changed
+	syncTake                       // only the remote side changed
+	syncConflict                   // both sides changed since the last sync
+)
+
+// syncState records the content hash of every entry as of the last sync.
+type syncState struct {
+	Base map[string]string `json:"base"`
+}
+
+// syncRepoDir returns the local clone of the sync repository.
+func syncRepoDir(home string) string {
+	return filepath.Join(home, ".claude", "memory-sync")
+}
+
+// runSync implements "memory sync <init|push|pull>".
+func runSync(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace memory sync <init|push|pull>")
+		return fmt.Errorf("missing sync subcommand")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	dir := syncRepoDir(home)
+	w := os.Stdout
+
+	switch args[0] {
+	case "init":
+		remote := ""
+		f := cli.New("memory sync init", "memory sync init --remote <git-url>")
+		f.StringVar(&remote, "--remote", "git-url", "Git repository to sync memory through")
+		positional, err := f.Parse(args[1:])
+		if err != nil {
+			return err
+		}
+		if err := f.CheckArgs(positional, 0, 0); err != nil {
+			return err
+		}
+		if remote == "" {
+			return fmt.Errorf("usage: claude-workspace memory sync init --remote <git-url>")
+		}
+		return syncInit(w, dir, remote)
+	case "push", "pull":
+		force := false
+		f := cli.New("memory sync "+args[0], "memory sync "+args[0]+" [--force]")
+		f.BoolVar(&force, "--force", "Overwrite conflicting changes")
+		positional, err := f.Parse(args[1:])
+		if err != nil {
+			return err
+		}
+		if err := f.CheckArgs(positional, 0, 0); err != nil {
+			return err
+		}
+		layers, err := DiscoverLayers()
+		if err != nil {
+			return err
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		project := syncProjectFile(home, cwd)
+		if args[0] == "push" {
+			return syncPush(w, dir, project, layers, force)
+		}
+		return syncPull(w, dir, project, layers, force)
+	default:
+		return fmt.Errorf("unknown sync subcommand: %s\nAvailable: init, push, pull", args[0])
+	}
+}
+
+// syncProjectFile returns the repository path of the project file for cwd.
+func syncProjectFile(home, cwd string) string {
+	key := cwd
+	if rel, err := filepath.Rel(home, cwd); err == nil && !strings.HasPrefix(rel, "..") {
+		key = rel
+	}
+	key = strings.Trim(encodeProjectPath(filepath.ToSlash(key)), "-")
+	if key == "" || key == "." {
+		key = "home"
+	}
+	return filepath.ToSlash(filepath.Join("projects", key+".json"))
+}
+
+// syncInit clones remote into dir, or repoints an existing clone at remote.
+func syncInit(w io.Writer, dir, remote string) error {
+	if platform.FileExists(filepath.Join(dir, ".git")) {
+		if _, err := git(dir, "remote", "get-url", "origin"); err != nil {
+			_, err = git(dir, "remote", "add", "origin", remote)
+			if err != nil {
+				return err
+			}
+		} else if _, err := git(dir, "remote", "set-url", "origin", remote); err != nil {
+			return err
+		}
+		platform.PrintOK(w, "Sync remote set to "+remote)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(dir), err)
+	}
+	if _, err := git(filepath.Dir(dir), "clone", remote, dir); err != nil {
+		return err
+	}
+	platform.PrintOK(w, "Cloned "+remote+" to "+dir)
+	fmt.Fprintln(w, "  Next: claude-workspace memory sync push")
+	return nil
+}
+
+// syncPush uploads the local memory layers. It refuses when the remote has
+// changes that have not been pulled, unless force is set.
+func syncPush(w io.Writer, dir, projectFile string, layers []Layer, force bool) error {
+	if err := syncFetch(dir); err != nil {
+		return err
+	}
+	state := loadSyncState(dir)
+	userData, projectData := syncSnapshot(layers, true)
+	files := map[string]*ExportData{syncUserFile: userData, projectFile: projectData}
+
+	platform.PrintBanner(w, "Memory Sync Push")
+	var blocked, changed []string
+	for _, name := range sortedKeys(files) {
+		remote, err := readSyncFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		local, remoteEntries := syncEntries(name, files[name]), syncEntries(name, remote)
+		keys := map[string]bool{}
+		for key := range local {
+			keys[key] = true
+		}
+		for key := range remoteEntries {
+			keys[key] = true
+		}
+		differs := false
+		for _, key := range sortedKeys(keys) {
+			if hashContent(local[key]) != hashContent(remoteEntries[key]) {
+				differs = true
+			}
+			if mergeAction(local[key], remoteEntries[key], state.Base[key]) != syncNone {
+				blocked = append(blocked, key)
+			}
+		}
+		if differs {
+			changed = append(changed, name)
+		}
+	}
+	if len(blocked) > 0 && !force {
+		for _, key := range blocked {
+			platform.PrintWarn(w, "Remote has unpulled changes: "+key)
+		}
+		fmt.Fprintln(w, "\n  Run 'claude-workspace memory sync pull' first, or push --force to overwrite.")
+		return fmt.Errorf("remote has changes that are not merged locally")
+	}
+
+	// Only rewrite files whose content changed, so export timestamps and
+	// machine-specific paths alone do not produce commits.
+	for _, name := range changed {
+		if err := writeSyncFile(filepath.Join(dir, name), files[name]); err != nil {
+			return err
+		}
+	}
+	if _, err := git(dir, "add", "-A"); err != nil {
+		return err
+	}
+	status, err := git(dir, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		platform.PrintOK(w, "Remote is already up to date")
+	} else {
+		host, _ := os.Hostname()
+		if _, err := git(dir, "commit", "-m", "Sync memory from "+host); err != nil {
+			return err
+		}
+		branch, err := git(dir, "symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return err
+		}
+		if _, err := git(dir, "push", "-u", "origin", branch); err != nil {
+			return err
+		}
+		platform.PrintOK(w, "Pushed memory layers")
+	}
+
+	for name, data := range files {
+		for key, content := range syncEntries(name, data) {
+			state.Base[key] = hashContent(content)
+		}
+	}
+	return saveSyncState(dir, state)
+}
+
+// syncPull merges the remote memory layers into the local ones. Entries changed
+// on both sides are reported as conflicts and left alone, unless force is set,
+// in which case the remote content wins.
+func syncPull(w io.Writer, dir, projectFile string, layers []Layer, force bool) error {
+	if err := syncFetch(dir); err != nil {
+		return err
+	}
+	state := loadSyncState(dir)
+	// The MCP graph is not exported here: provider imports are additive, so a
+	// remote graph is imported whenever it changed since the last sync.
+	userData, projectData := syncSnapshot(layers, false)
+	files := map[string]*ExportData{syncUserFile: userData, projectFile: projectData}
+
+	platform.PrintBanner(w, "Memory Sync Pull")
+	updated, conflicts := 0, 0
+	for _, name := range sortedKeys(files) {
+		remote, err := readSyncFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if remote == nil {
+			continue
+		}
+		local := syncEntries(name, files[name])
+		for key, content := range syncEntries(name, remote) {
+			action := mergeAction(local[key], content, state.Base[key])
+			switch {
+			case action == syncNone:
+				if hashContent(local[key]) == hashContent(content) {
+					state.Base[key] = hashContent(content)
+				}
+				continue
+			case action == syncConflict && !force:
+				platform.PrintWarn(w, "Conflict (changed on both machines, kept local): "+key)
+				conflicts++
+				continue
+			}
+			if err := applySyncEntry(w, key, content, layers, remote); err != nil {
+				platform.PrintFail(w, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+			state.Base[key] = hashContent(content)
+			updated++
+		}
+	}
+
+	if err := saveSyncState(dir, state); err != nil {
+		return err
+	}
+	if updated == 0 && conflicts == 0 {
+		platform.PrintOK(w, "Local memory is up to date")
+	}
+	if conflicts > 0 {
+		fmt.Fprintf(w, "\n  %d conflict(s). Resolve locally and push --force, or pull --force to take the remote copy.\n", conflicts)
+	}
+	return nil
+}
+
+// syncFetch fast-forwards the clone to the remote branch, if the remote has one.
+func syncFetch(dir string) error {
+	if !platform.FileExists(filepath.Join(dir, ".git")) {
+		return fmt.Errorf("memory sync is not set up; run: claude-workspace memory sync init --remote <git-url>")
+	}
+	if _, err := git(dir, "fetch", "origin"); err != nil {
+		return err
+	}
+	branch, err := git(dir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", "origin/"+branch); err != nil {
+		return nil // remote is still empty
+	}
+	_, err = git(dir, "merge", "--ff-only", "origin/"+branch)
+	return err
+}
+
+// syncSnapshot splits the discovered layers into the user-wide and
+// per-project ExportData files. The memory MCP graph is only exported when
+// withMCP is set, since that may invoke the provider or claude CLI.
+func syncSnapshot(layers []Layer, withMCP bool) (user, project *ExportData) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	user = &ExportData{Version: 1, ExportedAt: now, Platform: "claude-workspace"}
+	project = &ExportData{Version: 1, ExportedAt: now, Platform: "claude-workspace"}
+	for i := range layers {
+		l := &layers[i]
+		switch l.Name {
+		case LayerUserClaudeMD:
+			user.Layers.UserClaudeMD = exportFileLayer(l, "")
+		case LayerMemoryMCP:
+			if withMCP && l.Provider != "" && l.Provider != providerNone {
+				user.Layers.MemoryMCP = exportMCPLayer(l)
+			}
+		case LayerLocalMD:
+			project.Layers.LocalMD = exportFileLayer(l, "")
+		case LayerAutoMemory:
+			project.Layers.AutoMemory = &ExportAutoMem{BasePath: l.Path, Files: l.Files}
+		}
+	}
+	return user, project
+}
+
+// syncEntries flattens the synced layers of data into entries keyed by
+// "<file>#<layer>" or "<file>#auto_memory/<name>". A nil value means the
+// layer is absent.
+func syncEntries(file string, data *ExportData) map[string]*string {
+	entries := map[string]*string{}
+	if data == nil {
+		return entries
+	}
+	prefix := file + "#"
+	if f := data.Layers.UserClaudeMD; f != nil {
+		entries[prefix+string(LayerUserClaudeMD)] = f.Content
+	}
+	if f := data.Layers.LocalMD; f != nil {
+		entries[prefix+string(LayerLocalMD)] = f.Content
+	}
+	if am := data.Layers.AutoMemory; am != nil {
+		for name, content := range am.Files {
+			entries[prefix+string(LayerAutoMemory)+"/"+name] = &content
+		}
+	}
+	if m := data.Layers.MemoryMCP; m != nil && m.Data != nil {
+		s := string(*m.Data)
+		entries[prefix+string(LayerMemoryMCP)] = &s
+	}
+	return entries
+}
+
+// mergeAction decides how to reconcile one entry given its local and remote
+// content and the hash recorded at the last sync. Deletions are not
+// propagated: a missing remote entry never removes local content.
+func mergeAction(local, remote *string, base string) syncAction {
+	if remote == nil {
+		return syncNone
+	}
+	remoteHash := hashContent(remote)
+	switch {
+	case local != nil && *local == *remote:
+		return syncNone
+	case remoteHash == base:
+		return syncNone
+	case local == nil || hashContent(local) == base:
+		return syncTake
+	default:
+		return syncConflict
+	}
+}
+
+// applySyncEntry writes a remote entry to its local location. Paths are always
+// taken from the local layers, never from the remote file, since home and
+// project directories differ between machines.
+func applySyncEntry(w io.Writer, key string, content *string, layers []Layer, remote *ExportData) error {
+	_, layerKey, _ := strings.Cut(key, "#")
+	layerName, fileName, _ := strings.Cut(layerKey, "/")
+	var local *Layer
+	for i := range layers {
+		if layers[i].Name == LayerName(layerName) {
+			local = &layers[i]
+		}
+	}
+	if local == nil {
+		return fmt.Errorf("layer not found")
+	}
+
+	switch LayerName(layerName) {
+	case LayerUserClaudeMD, LayerLocalMD:
+		if err := writeFileContent(local.Path, *content); err != nil {
+			return err
+		}
+		platform.PrintOK(w, local.Label+" updated")
+	case LayerAutoMemory:
+		if err := writeFileContent(filepath.Join(local.Path, fileName), *content); err != nil {
+			return err
+		}
+		platform.PrintOK(w, "Auto-memory "+fileName+" updated")
+	case LayerMemoryMCP:
+		provider := remote.Layers.MemoryMCP.Provider
+		if provider != local.Provider {
+			return fmt.Errorf("remote uses %s but this machine uses %q", provider, local.Provider)
+		}
+		importMemoryMCP(w, map[LayerName]bool{LayerMemoryMCP: true}, remote.Layers.MemoryMCP)
+	}
+	return nil
+}
+
+func hashContent(s *string) string {
+	if s == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(*s))
+	return hex.EncodeToString(sum[:])
+}
+
+func readSyncFile(path string) (*ExportData, error) {
+	if !platform.FileExists(path) {
+		return nil, nil
+	}
+	return readExportFile(path, encryption{})
+}
+
+func writeSyncFile(path string, data *ExportData) error {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", filepath.Base(path), err)
+	}
+	return writeFileContent(path, string(jsonData)+"\n")
+}
+
+func loadSyncState(dir string) *syncState {
+	state := &syncState{}
+	if raw, err := os.ReadFile(filepath.Join(dir, ".git", syncStateFile)); err == nil {
+		_ = json.Unmarshal(raw, state)
+	}
+	if state.Base == nil {
+		state.Base = map[string]string{}
+	}
+	return state
+}
+
+func saveSyncState(dir string, state *syncState) error {
+	return platform.WriteJSONFile(filepath.Join(dir, ".git", syncStateFile), state)
+}
+
+// git runs a git command in dir and returns its trimmed stdout. Errors include
+// git's stderr.
+func git(dir string, args ...string) (string, error) {
+	out, stderr, err := platform.RunDirWithStdinCapture(context.Background(), dir, "", nil, "git", args...)
+	if err != nil {
+		if stderr != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], stderr)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
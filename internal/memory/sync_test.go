@@ -0,0 +1,205 @@
+package memory
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestMergeAction(t *testing.T) {
+	a, b := strPtr("a"), strPtr("b")
+	tests := []struct {
+		name          string
+		local, remote *string
+		base          string
+		want          syncAction
+	}{
+		{"remote missing", a, nil, "", syncNone},
+		{"identical", a, strPtr("a"), "", syncNone},
+		{"only local changed", b, a, hashContent(a), syncNone},
+		{"only remote changed", a, b, hashContent(a), syncTake},
+		{"missing locally", nil, b, "", syncTake},
+		{"both changed", a, b, hashContent(strPtr("c")), syncConflict},
+		{"never synced and different", a, b, "", syncConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeAction(tt.local, tt.remote, tt.base); got != tt.want {
+				t.Errorf("mergeAction() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncProjectFile(t *testing.T) {
+	tests := []struct {
+		cwd  string
+		want string
+	}{
+		{"/home/lam/code/app", "projects/code-app.json"},
+		{"/home/lam", "projects/home.json"},
+		{"/srv/app", "projects/srv-app.json"},
+	}
+	for _, tt := range tests {
+		if got := syncProjectFile("/home/lam", tt.cwd); got != tt.want {
+			t.Errorf("syncProjectFile(%q) = %q, want %q", tt.cwd, got, tt.want)
+		}
+	}
+}
+
+func TestSyncEntries(t *testing.T) {
+	data := &ExportData{Layers: ExportLayers{
+		UserClaudeMD: &ExportFile{Content: strPtr("user")},
+		LocalMD:      &ExportFile{},
+		AutoMemory:   &ExportAutoMem{Files: map[string]string{"MEMORY.md": "notes"}},
+	}}
+	entries := syncEntries("user.json", data)
+	if got := entries["user.json#user_claude_md"]; got == nil || *got != "user" {
+		t.Errorf("user_claude_md entry = %v", got)
+	}
+	if got, ok := entries["user.json#local_md"]; !ok || got != nil {
+		t.Errorf("local_md entry = %v, %v; want present and nil", got, ok)
+	}
+	if got := entries["user.json#auto_memory/MEMORY.md"]; got == nil || *got != "notes" {
+		t.Errorf("auto_memory entry = %v", got)
+	}
+	if len(syncEntries("x", nil)) != 0 {
+		t.Error("syncEntries(nil) should be empty")
+	}
+}
+
+// syncMachine is one machine taking part in a sync test.
+type syncMachine struct {
+	dir    string // sync clone
+	userMD string
+	auto   string
+}
+
+func (m *syncMachine) layers() []Layer {
+	return []Layer{
+		discoverFileLayer(LayerUserClaudeMD, "User CLAUDE.md", m.userMD),
+		discoverFileLayer(LayerLocalMD, "CLAUDE.local.md", filepath.Join(filepath.Dir(m.userMD), "CLAUDE.local.md")),
+		discoverAutoMemory(m.auto),
+		{Name: LayerMemoryMCP, Label: "Memory MCP", Provider: providerNone},
+	}
+}
+
+func newSyncMachine(t *testing.T, remote string) *syncMachine {
+	t.Helper()
+	root := t.TempDir()
+	m := &syncMachine{
+		dir:    filepath.Join(root, "memory-sync"),
+		userMD: filepath.Join(root, "CLAUDE.md"),
+		auto:   filepath.Join(root, "memory"),
+	}
+	if err := syncInit(io.Discard, m.dir, remote); err != nil {
+		t.Fatalf("syncInit: %v", err)
+	}
+	return m
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSyncPushPull(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "test"}, {"GIT_AUTHOR_EMAIL", "test@example.com"},
+		{"GIT_COMMITTER_NAME", "test"}, {"GIT_COMMITTER_EMAIL", "test@example.com"},
+		{"GIT_CONFIG_GLOBAL", os.DevNull},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+
+	laptop := newSyncMachine(t, remote)
+	desktop := newSyncMachine(t, remote)
+	const project = "projects/code-app.json"
+
+	// Laptop pushes its memory; desktop pulls it.
+	if err := writeFileContent(laptop.userMD, "laptop prefs\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileContent(filepath.Join(laptop.auto, "MEMORY.md"), "laptop notes\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncPush(io.Discard, laptop.dir, project, laptop.layers(), false); err != nil {
+		t.Fatalf("laptop push: %v", err)
+	}
+	if err := syncPull(io.Discard, desktop.dir, project, desktop.layers(), false); err != nil {
+		t.Fatalf("desktop pull: %v", err)
+	}
+	if got := readFile(t, desktop.userMD); got != "laptop prefs\n" {
+		t.Errorf("desktop CLAUDE.md = %q", got)
+	}
+	if got := readFile(t, filepath.Join(desktop.auto, "MEMORY.md")); got != "laptop notes\n" {
+		t.Errorf("desktop MEMORY.md = %q", got)
+	}
+
+	// Desktop edits and pushes; laptop has not changed, so it takes the update.
+	if err := writeFileContent(desktop.userMD, "desktop prefs\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncPush(io.Discard, desktop.dir, project, desktop.layers(), false); err != nil {
+		t.Fatalf("desktop push: %v", err)
+	}
+
+	// Laptop edits the same file before pulling: push is refused and pull
+	// reports a conflict, keeping the local copy.
+	if err := writeFileContent(laptop.userMD, "laptop edit\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncPush(io.Discard, laptop.dir, project, laptop.layers(), false); err == nil {
+		t.Fatal("laptop push with unpulled remote changes succeeded, want error")
+	}
+	var out strings.Builder
+	if err := syncPull(&out, laptop.dir, project, laptop.layers(), false); err != nil {
+		t.Fatalf("laptop pull: %v", err)
+	}
+	if !strings.Contains(out.String(), "Conflict") {
+		t.Errorf("pull output missing conflict:\n%s", out.String())
+	}
+	if got := readFile(t, laptop.userMD); got != "laptop edit\n" {
+		t.Errorf("conflicting CLAUDE.md was overwritten: %q", got)
+	}
+
+	// --force on pull takes the remote copy.
+	if err := syncPull(io.Discard, laptop.dir, project, laptop.layers(), true); err != nil {
+		t.Fatalf("laptop pull --force: %v", err)
+	}
+	if got := readFile(t, laptop.userMD); got != "desktop prefs\n" {
+		t.Errorf("CLAUDE.md after pull --force = %q", got)
+	}
+
+	// Now in sync: a push has nothing to commit.
+	out.Reset()
+	if err := syncPush(&out, laptop.dir, project, laptop.layers(), false); err != nil {
+		t.Fatalf("laptop push after pull: %v", err)
+	}
+	if !strings.Contains(out.String(), "already up to date") {
+		t.Errorf("push output = %q, want already up to date", out.String())
+	}
+}
+
+func TestSyncNotInitialized(t *testing.T) {
+	err := syncPull(io.Discard, filepath.Join(t.TempDir(), "missing"), "projects/x.json", nil, false)
+	if err == nil || !strings.Contains(err.Error(), "sync init") {
+		t.Errorf("syncPull without a clone = %v, want init hint", err)
+	}
+}
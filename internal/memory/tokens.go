@@ -0,0 +1,460 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const (
+	// defaultTokenBudget is the always-loaded context "memory tokens" allows
+	// when neither --budget nor workspace.memoryTokenBudget is set.
+	defaultTokenBudget = 10000
+
+	// memoryIndexLines is how much of the auto-memory MEMORY.md Claude loads
+	// at startup.
+	memoryIndexLines = 200
+
+	// maxImportDepth is how many @import hops Claude follows.
+	maxImportDepth = 5
+)
+
+// contextFile is an instruction or memory file and the tokens it adds to a
+// session.
+type contextFile struct {
+	Label      string
+	Path       string
+	Content    string // the part Claude loads
+	Lines      int    // lines in the whole file
+	Tokens     int
+	OnDemand   bool   // loaded only when Claude works on matching files
+	ImportedBy string // path of the file whose @import loads it
+}
+
+// importRe matches an @path import: "@" at the start of a word, followed by a
+// path, as in "@README.md" or "@~/notes.md". Paths that do not exist, such
+// as @mentions, are dropped when the file is read.
+var importRe = regexp.MustCompile(`(?:^|\s)@((?:~/|\.{0,2}/)?[\w.-]+(?:/[\w.-]+)*)`)
+
+func runTokens(args []string) error {
+	budget := 0
+	f := cli.New("memory tokens", "memory tokens [--budget <tokens>]")
+	f.Func("--budget", "tokens", "Warn above this many tokens (default: workspace.memoryTokenBudget)", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid --budget %q: expected a positive number of tokens", v)
+		}
+		budget = n
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	if budget == 0 {
+		budget = configuredTokenBudget()
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	tokensReport(os.Stdout, contextFiles(home, cwd), budget)
+	return nil
+}
+
+// configuredTokenBudget returns the workspace.memoryTokenBudget setting, or
+// defaultTokenBudget.
+func configuredTokenBudget() int {
+	if n, err := strconv.Atoi(platform.ConfigString(platform.ConfigMemoryTokenBudget)); err == nil && n > 0 {
+		return n
+	}
+	return defaultTokenBudget
+}
+
+// contextFiles returns the instruction and memory files a session started
+// in cwd loads, in load order: the user CLAUDE.md and rules, CLAUDE.md files
+// from the outermost parent directory down to cwd, the project rules,
+// CLAUDE.local.md, the auto-memory index, and the files they @import.
+// Path-scoped rules and auto-memory topic files are marked OnDemand.
+func contextFiles(home, cwd string) []contextFile {
+	var files []contextFile
+	seen := map[string]bool{}
+	add := func(label, path string, onDemand bool) {
+		if seen[path] || !platform.FileExists(path) {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		seen[path] = true
+		f := contextFile{Label: label, Path: path, Content: string(data), Lines: countLines(string(data)), OnDemand: onDemand}
+		if filepath.Base(path) == "MEMORY.md" && f.Lines > memoryIndexLines {
+			f.Content = strings.Join(strings.SplitAfter(f.Content, "\n")[:memoryIndexLines], "")
+		}
+		files = append(files, f)
+	}
+	addRules := func(label, dir string) {
+		for _, path := range markdownFiles(dir) {
+			add(label, path, hasPathsFrontmatter(path))
+		}
+	}
+
+	add("User CLAUDE.md", filepath.Join(home, ".claude", "CLAUDE.md"), false)
+	addRules("User rule", filepath.Join(home, ".claude", "rules"))
+
+	var dirs []string
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	for _, dir := range dirs {
+		label := "Parent CLAUDE.md"
+		if dir == cwd {
+			label = "Project CLAUDE.md"
+		}
+		add(label, filepath.Join(dir, "CLAUDE.md"), false)
+		add(label, filepath.Join(dir, ".claude", "CLAUDE.md"), false)
+	}
+	addRules("Project rule", filepath.Join(cwd, ".claude", "rules"))
+	add("CLAUDE.local.md", filepath.Join(cwd, "CLAUDE.local.md"), false)
+
+	autoDir := autoMemoryDir(home, cwd)
+	add("Auto-memory index", filepath.Join(autoDir, "MEMORY.md"), false)
+	for _, path := range markdownFiles(autoDir) {
+		add("Auto-memory topic", path, true)
+	}
+
+	// Follow @imports breadth-first, so each file is credited to the nearest
+	// file that imports it.
+	next := files
+	for depth := 0; depth < maxImportDepth && len(next) > 0; depth++ {
+		start := len(files)
+		for _, f := range next {
+			for _, target := range imports(f.Content, filepath.Dir(f.Path), home) {
+				before := len(files)
+				add("Import", target, f.OnDemand)
+				if len(files) > before {
+					files[len(files)-1].ImportedBy = f.Path
+				}
+			}
+		}
+		next = files[start:]
+	}
+
+	for i := range files {
+		files[i].Tokens = estimateTokens(files[i].Content)
+	}
+	return files
+}
+
+// markdownFiles returns the .md files below dir, sorted.
+func markdownFiles(dir string) []string {
+	var paths []string
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(d.Name(), ".md") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// hasPathsFrontmatter reports whether the rule at path has a "paths:" key in
+// its frontmatter, so Claude loads it only for matching files.
+func hasPathsFrontmatter(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	rest, ok := strings.CutPrefix(string(data), "---\n")
+	if !ok {
+		return false
+	}
+	front, _, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return false
+	}
+	for _, line := range strings.Split(front, "\n") {
+		if strings.HasPrefix(line, "paths:") {
+			return true
+		}
+	}
+	return false
+}
+
+// imports returns the paths content imports with @path, resolved against dir
+// and home. Imports inside code spans and fenced code blocks are ignored, as
+// Claude ignores them.
+func imports(content, dir, home string) []string {
+	var paths []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for i, part := range strings.Split(line, "`") {
+			if i%2 == 1 {
+				continue
+			}
+			for _, m := range importRe.FindAllStringSubmatch(part, -1) {
+				p := strings.TrimRight(m[1], ".") // sentence-ending period
+				switch {
+				case strings.HasPrefix(p, "~/"):
+					p = filepath.Join(home, p[2:])
+				case !filepath.IsAbs(p):
+					p = filepath.Join(dir, p)
+				}
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// estimateTokens approximates how many tokens Claude's tokenizer produces for
+// s. Words cost one token per six letters, numbers one per three digits, and
+// each run of punctuation or line breaks one token; a space before a word is
+// part of the word's token. Han, kana, and Hangul characters cost one token
+// each. For English Markdown this comes to three to five characters a token,
+// close enough to size instruction files without shipping the vocabulary.
+func estimateTokens(s string) int {
+	tokens := 0
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		j := i + 1
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			tokens++
+		case unicode.IsLetter(r):
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '\'') && !unicode.In(runes[j], unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+				j++
+			}
+			tokens += (j - i + 5) / 6
+		case unicode.IsDigit(r):
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens += (j - i + 2) / 3
+		case r == ' ' || r == '\t':
+			for j < len(runes) && (runes[j] == ' ' || runes[j] == '\t') {
+				j++
+			}
+			if j-i > 1 || j == len(runes) || !unicode.IsLetter(runes[j]) {
+				tokens++ // indentation or spacing not absorbed by a word
+			}
+		case r == '\n' || r == '\r':
+			for j < len(runes) && (runes[j] == '\n' || runes[j] == '\r') {
+				j++
+			}
+			tokens++
+		default:
+			for j < len(runes) && runes[j] == r && j-i < 4 {
+				j++
+			}
+			tokens++
+		}
+		i = j
+	}
+	return tokens
+}
+
+// tokensReport prints the token estimate of each file, the always-loaded
+// total against budget, and, over budget, which files to trim.
+func tokensReport(w io.Writer, files []contextFile, budget int) {
+	platform.PrintBanner(w, "Memory Tokens")
+
+	var always, onDemand []contextFile
+	total := 0
+	for _, f := range files {
+		if f.OnDemand {
+			onDemand = append(onDemand, f)
+			continue
+		}
+		always = append(always, f)
+		total += f.Tokens
+	}
+
+	platform.PrintSection(w, "Always Loaded")
+	if len(always) == 0 {
+		fmt.Fprintln(w, "  No CLAUDE.md, rules, or auto-memory files found.")
+	}
+	printTokenTable(w, always)
+	pct := total * 100 / budget
+	fmt.Fprintf(w, "\n  Total: ~%s tokens of a %s budget (%d%%)\n", platform.FormatCount(int64(total)), platform.FormatCount(int64(budget)), pct)
+
+	if len(onDemand) > 0 {
+		platform.PrintSection(w, "Loaded On Demand")
+		printTokenTable(w, onDemand)
+	}
+
+	notes := memoryIndexNotes(files)
+	fmt.Fprintln(w)
+	if total > budget {
+		platform.PrintWarningLine(w, fmt.Sprintf("Always-loaded context is ~%s tokens over the budget", platform.FormatCount(int64(total-budget))))
+		notes = append(trimSuggestions(always, total), notes...)
+	} else {
+		platform.PrintSuccess(w, "Always-loaded context is within the budget")
+	}
+	if len(notes) > 0 {
+		platform.PrintSection(w, "Suggestions")
+		for _, n := range notes {
+			fmt.Fprintf(w, "  - %s\n", n)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Token counts are estimates. Change the budget with --budget or %s.\n",
+		platform.Bold("config set workspace.memoryTokenBudget <tokens>"))
+}
+
+func printTokenTable(w io.Writer, files []contextFile) {
+	for _, f := range files {
+		label := f.Label
+		if f.ImportedBy != "" {
+			label = "Imported by " + shortenHome(f.ImportedBy)
+		}
+		fmt.Fprintf(w, "  %8s  %-52s %s\n", platform.FormatCount(int64(f.Tokens)), shortenHome(f.Path), label)
+	}
+}
+
+// memoryIndexNotes warns about auto-memory indexes longer than Claude loads.
+func memoryIndexNotes(files []contextFile) []string {
+	var notes []string
+	for _, f := range files {
+		if f.Label == "Auto-memory index" && f.Lines > memoryIndexLines {
+			notes = append(notes, fmt.Sprintf("%s has %d lines, but only the first %d load; move the rest into topic files it links to",
+				shortenHome(f.Path), f.Lines, memoryIndexLines))
+		}
+	}
+	return notes
+}
+
+// trimSuggestions names the always-loaded files that make up at least a tenth
+// of total, largest first, with what to move out of each, and lines repeated
+// across files.
+func trimSuggestions(files []contextFile, total int) []string {
+	sorted := append([]contextFile(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Tokens > sorted[j].Tokens })
+
+	var notes []string
+	for _, f := range sorted {
+		if f.Tokens*10 < total {
+			break
+		}
+		note := fmt.Sprintf("Trim %s (~%s tokens, %d%% of the total)", shortenHome(f.Path), platform.FormatCount(int64(f.Tokens)), f.Tokens*100/total)
+		if code := codeBlockTokens(f.Content); code*4 >= f.Tokens {
+			note += fmt.Sprintf(": ~%s tokens are code blocks; point to example files instead of inlining them", platform.FormatCount(int64(code)))
+		} else if heading, tokens := largestSection(f.Content); tokens*10 >= f.Tokens*3 {
+			note += fmt.Sprintf(": its %q section is ~%s tokens; move it to a rule with a paths: frontmatter so it loads only for matching files", heading, platform.FormatCount(int64(tokens)))
+		} else if f.ImportedBy != "" {
+			note += fmt.Sprintf(": drop its @import from %s, or import it from a path-scoped rule", shortenHome(f.ImportedBy))
+		}
+		notes = append(notes, note)
+	}
+	return append(notes, duplicateLineNotes(files)...)
+}
+
+// codeBlockTokens returns the tokens inside fenced code blocks in content.
+func codeBlockTokens(content string) int {
+	var sb strings.Builder
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+		}
+	}
+	return estimateTokens(sb.String())
+}
+
+// largestSection returns the "## " heading of content with the most tokens
+// under it, and that count.
+func largestSection(content string) (string, int) {
+	best, bestTokens := "", 0
+	heading := ""
+	var body strings.Builder
+	flush := func() {
+		if t := estimateTokens(body.String()); heading != "" && t > bestTokens {
+			best, bestTokens = heading, t
+		}
+		body.Reset()
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if h, ok := strings.CutPrefix(line, "## "); ok {
+			flush()
+			heading = strings.TrimSpace(h)
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	flush()
+	return best, bestTokens
+}
+
+// duplicateLineNotes reports files that repeat substantial lines of an
+// earlier file, which Claude then reads twice.
+func duplicateLineNotes(files []contextFile) []string {
+	firstSeen := map[string]string{}
+	repeats := map[[2]string]int{}
+	var order [][2]string
+	for _, f := range files {
+		for _, line := range strings.Split(f.Content, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) < 30 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") {
+				continue
+			}
+			first, ok := firstSeen[line]
+			if !ok {
+				firstSeen[line] = f.Path
+				continue
+			}
+			if first == f.Path {
+				continue
+			}
+			pair := [2]string{first, f.Path}
+			if repeats[pair] == 0 {
+				order = append(order, pair)
+			}
+			repeats[pair]++
+		}
+	}
+	var notes []string
+	for _, pair := range order {
+		lines := fmt.Sprintf("%d lines of %s repeat", repeats[pair], shortenHome(pair[1]))
+		if repeats[pair] == 1 {
+			lines = fmt.Sprintf("1 line of %s repeats", shortenHome(pair[1]))
+		}
+		notes = append(notes, fmt.Sprintf("%s %s; keep one copy", lines, shortenHome(pair[0])))
+	}
+	return notes
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"hello world", 2},
+		{"internationalization", 4},
+		{"## Build\n", 3},
+		{"2025", 2},
+		{"日本語", 3},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.in); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	prose := strings.Repeat("- Use `platform.PrintSuccess` for user-facing output, and wrap errors with context.\n", 20)
+	if ratio := float64(len(prose)) / float64(estimateTokens(prose)); ratio < 3 || ratio > 5 {
+		t.Errorf("characters per token = %.1f, want 3-5", ratio)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContextFiles(t *testing.T) {
+	home := t.TempDir()
+	cwd := filepath.Join(t.TempDir(), "app")
+	writeFile(t, filepath.Join(home, ".claude", "CLAUDE.md"), "Be brief.\nSee @~/notes/style.md and `@ignored.md`.\n")
+	writeFile(t, filepath.Join(home, "notes", "style.md"), "Use tabs.\n")
+	writeFile(t, filepath.Join(cwd, "CLAUDE.md"), "# App\n@docs/arch.md\n```\n@docs/missing.md\n```\n")
+	writeFile(t, filepath.Join(cwd, "docs", "arch.md"), "Layers.\n")
+	writeFile(t, filepath.Join(cwd, ".claude", "rules", "go.md"), "Wrap errors.\n")
+	writeFile(t, filepath.Join(cwd, ".claude", "rules", "api.md"), "---\npaths:\n  - \"api/**\"\n---\nREST only.\n")
+	autoDir := autoMemoryDir(home, cwd)
+	writeFile(t, filepath.Join(autoDir, "MEMORY.md"), strings.Repeat("note\n", 250))
+	writeFile(t, filepath.Join(autoDir, "debugging.md"), "Flaky test.\n")
+
+	var got []string
+	for _, f := range contextFiles(home, cwd) {
+		rel := strings.TrimPrefix(strings.TrimPrefix(f.Path, home), filepath.Dir(cwd))
+		got = append(got, rel)
+		switch rel {
+		case "/notes/style.md":
+			if f.ImportedBy != filepath.Join(home, ".claude", "CLAUDE.md") {
+				t.Errorf("style.md ImportedBy = %q", f.ImportedBy)
+			}
+		case "/app/.claude/rules/api.md", "/.claude/projects/" + encodeProjectPath(cwd) + "/memory/debugging.md":
+			if !f.OnDemand {
+				t.Errorf("%s should be on demand", rel)
+			}
+		}
+		if filepath.Base(f.Path) == "MEMORY.md" && (f.Lines != 250 || countLines(f.Content) != memoryIndexLines) {
+			t.Errorf("MEMORY.md: %d lines, %d loaded", f.Lines, countLines(f.Content))
+		}
+	}
+	want := []string{
+		"/.claude/CLAUDE.md",
+		"/app/CLAUDE.md",
+		"/app/.claude/rules/api.md",
+		"/app/.claude/rules/go.md",
+		"/.claude/projects/" + encodeProjectPath(cwd) + "/memory/MEMORY.md",
+		"/.claude/projects/" + encodeProjectPath(cwd) + "/memory/debugging.md",
+		"/notes/style.md",
+		"/app/docs/arch.md",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("contextFiles =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTokensReport(t *testing.T) {
+	shared := "Always run the full test suite before you open a pull request.\n"
+	files := []contextFile{
+		{Label: "User CLAUDE.md", Path: "/u/CLAUDE.md", Content: shared},
+		{Label: "Project CLAUDE.md", Path: "/p/CLAUDE.md", Content: "## Intro\nHi.\n## API\n" + strings.Repeat("Every handler validates its input and returns typed errors.\n", 40) + shared},
+		{Label: "Project rule", Path: "/p/.claude/rules/ui.md", Content: strings.Repeat("x ", 500), OnDemand: true},
+	}
+	for i := range files {
+		files[i].Tokens = estimateTokens(files[i].Content)
+	}
+
+	var out bytes.Buffer
+	tokensReport(&out, files, 100)
+	report := out.String()
+	for _, want := range []string{
+		"Always Loaded",
+		"Loaded On Demand",
+		"tokens over the budget",
+		`Trim /p/CLAUDE.md`,
+		`its "API" section is`,
+		"1 line of /p/CLAUDE.md repeats /u/CLAUDE.md",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "Trim /p/.claude/rules/ui.md") {
+		t.Errorf("on-demand file counted against the budget:\n%s", report)
+	}
+
+	out.Reset()
+	tokensReport(&out, files, 100000)
+	if !strings.Contains(out.String(), "within the budget") || strings.Contains(out.String(), "Suggestions") {
+		t.Errorf("report within budget:\n%s", out.String())
+	}
+
+	for _, args := range [][]string{{"--budget", "0"}, {"--budget=lots"}, {"--verbose"}} {
+		if err := runTokens(args); err == nil {
+			t.Errorf("runTokens(%q): expected error", args)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+// Package models implements the "models" command, which manages the models
+// Claude Code routes work to, its reasoning effort, and when it compacts
+// context, through named presets and validated settings.
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const (
+	synopsis = "models [show|presets|use <preset>|set <name> <value>|reset] [--scope global|project|local]"
+	usage    = "Usage: claude-workspace " + synopsis
+)
+
+// Env locates the settings files the command reads and writes.
+type Env struct {
+	Home string
+	Cwd  string
+}
+
+// setting is one value the command manages, stored either as a settings.json
+// key or as an env var in the settings env block.
+type setting struct {
+	Name  string // name used on the command line
+	Key   string
+	Env   bool
+	Desc  string
+	check func(string) error
+}
+
+var settings = []setting{
+	{Name: "main", Key: "model", Desc: "Model for the main conversation", check: CheckModel},
+	{Name: "subagent", Key: "CLAUDE_CODE_SUBAGENT_MODEL", Env: true, Desc: "Model for spawned subagents", check: CheckModel},
+	{Name: "effort", Key: "effortLevel", Desc: "Reasoning effort (low, medium, high)", check: checkEffort},
+	{Name: "autocompact", Key: "CLAUDE_AUTOCOMPACT_PCT_OVERRIDE", Env: true, Desc: "Context % that triggers auto-compaction", check: checkPercent},
+}
+
+// preset is a named set of values for every setting.
+type preset struct {
+	Name   string
+	Desc   string
+	Values map[string]string // setting name -> value
+}
+
+var presets = []preset{
+	{Name: "platform", Desc: "Platform defaults: Opus throughout, compact at 80%",
+		Values: map[string]string{"main": "opus", "subagent": "opus", "effort": "high", "autocompact": "80"}},
+	{Name: "balanced", Desc: "Opus plans and Sonnet executes; Sonnet subagents",
+		Values: map[string]string{"main": "opusplan", "subagent": "sonnet", "effort": "high", "autocompact": "80"}},
+	{Name: "cost-saver", Desc: "Sonnet with Haiku subagents at medium effort; compact early",
+		Values: map[string]string{"main": "sonnet", "subagent": "haiku", "effort": "medium", "autocompact": "70"}},
+	{Name: "max-quality", Desc: "Opus throughout at high effort; compact late to keep context",
+		Values: map[string]string{"main": "opus", "subagent": "opus", "effort": "high", "autocompact": "90"}},
+}
+
+// modelAliases are the model names Claude Code accepts besides full IDs.
+var modelAliases = []string{"default", "sonnet", "opus", "haiku", "opusplan", "sonnet[1m]", "opus[1m]"}
+
+// CheckModel returns an error unless v is a model Claude Code accepts: an
+// alias, or anything naming a Claude model, which covers API IDs
+// (claude-sonnet-4-5) and Bedrock/Vertex IDs and ARNs.
+func CheckModel(v string) error {
+	for _, a := range modelAliases {
+		if v == a {
+			return nil
+		}
+	}
+	if strings.Contains(strings.ToLower(v), "claude") && !strings.ContainsAny(v, " \t") {
+		return nil
+	}
+	return fmt.Errorf("unknown model %q (use %s, or a full model ID such as claude-sonnet-4-5)", v, strings.Join(modelAliases, ", "))
+}
+
+func checkEffort(v string) error {
+	switch v {
+	case "low", "medium", "high":
+		return nil
+	}
+	return fmt.Errorf("invalid effort %q: must be low, medium, or high", v)
+}
+
+func checkPercent(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > 100 {
+		return fmt.Errorf("invalid autocompact threshold %q: must be a percentage from 1 to 100", v)
+	}
+	return nil
+}
+
+func findSetting(name string) (setting, error) {
+	for _, s := range settings {
+		if s.Name == name || s.Key == name {
+			return s, nil
+		}
+	}
+	names := make([]string, len(settings))
+	for i, s := range settings {
+		names[i] = s.Name
+	}
+	return setting{}, fmt.Errorf("unknown setting %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+func findPreset(name string) (preset, error) {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		if p.Name == name {
+			return p, nil
+		}
+		names[i] = p.Name
+	}
+	return preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Run routes the models subcommand.
+func Run(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	return run(os.Stdout, args, Env{Home: home, Cwd: cwd})
+}
+
+func run(w io.Writer, args []string, env Env) error {
+	subcmd := "show"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcmd, args = args[0], args[1:]
+	}
+	positional, scope, err := parseArgs(subcmd, args)
+	if err != nil {
+		return err
+	}
+	switch subcmd {
+	case "show":
+		if len(positional) > 0 {
+			return fmt.Errorf("unexpected argument: %s\n%s", positional[0], usage)
+		}
+		printEffective(w, resolve(env))
+		return nil
+	case "presets", "list":
+		if len(positional) > 0 {
+			return fmt.Errorf("unexpected argument: %s\n%s", positional[0], usage)
+		}
+		printPresets(w)
+		return nil
+	case "use":
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claude-workspace models use <preset> [--scope global|project|local]")
+		}
+		p, err := findPreset(positional[0])
+		if err != nil {
+			return err
+		}
+		return write(w, env, scope, p.Values, fmt.Sprintf("preset '%s'", p.Name))
+	case "set":
+		if len(positional) != 2 {
+			return fmt.Errorf("usage: claude-workspace models set <name> <value> [--scope global|project|local]")
+		}
+		s, err := findSetting(positional[0])
+		if err != nil {
+			return err
+		}
+		return write(w, env, scope, map[string]string{s.Name: positional[1]}, s.Name)
+	case "reset":
+		if len(positional) > 0 {
+			return fmt.Errorf("unexpected argument: %s\n%s", positional[0], usage)
+		}
+		return reset(w, env, scope)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown models subcommand: %s\n", subcmd)
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown subcommand: %s", subcmd)
+	}
+}
+
+// parseArgs splits the arguments of subcmd into positional arguments and the
+// --scope value, which defaults to the user (global) layer.
+func parseArgs(subcmd string, args []string) ([]string, config.ConfigScope, error) {
+	scope := config.ScopeUser
+	f := cli.New("models "+subcmd, synopsis)
+	f.Func("--scope", "global|project|local", "Settings layer to write", func(v string) error {
+		switch v {
+		case "user", "global":
+			scope = config.ScopeUser
+		case "project":
+			scope = config.ScopeProject
+		case "local":
+			scope = config.ScopeLocal
+		default:
+			return fmt.Errorf("invalid scope %q: must be global (user), project, or local", v)
+		}
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return nil, "", err
+	}
+	return positional, scope, nil
+}
+
+// readSettings returns the settings file for scope, or an empty map.
+func readSettings(path string) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	if path == "" || !platform.FileExists(path) {
+		return root, nil
+	}
+	if err := platform.ReadJSONFile(path, &root); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if root == nil {
+		root = map[string]interface{}{}
+	}
+	return root, nil
+}
+
+func get(root map[string]interface{}, s setting) (string, bool) {
+	m := root
+	if s.Env {
+		m, _ = root["env"].(map[string]interface{})
+	}
+	switch v := m[s.Key].(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// write validates values and sets them in the scope's settings file.
+func write(w io.Writer, env Env, scope config.ConfigScope, values map[string]string, what string) error {
+	for _, s := range settings {
+		if v, ok := values[s.Name]; ok {
+			if err := s.check(v); err != nil {
+				return err
+			}
+		}
+	}
+	path := config.SettingsFile(scope, env.Home, env.Cwd)
+	return platform.WithFileLock(path, func() error {
+		root, err := readSettings(path)
+		if err != nil {
+			return err
+		}
+
+		var changes []string
+		for _, s := range settings {
+			v, ok := values[s.Name]
+			if !ok {
+				continue
+			}
+			old, had := get(root, s)
+			if had && old == v {
+				continue
+			}
+			if s.Env {
+				envBlock, _ := root["env"].(map[string]interface{})
+				if envBlock == nil {
+					envBlock = map[string]interface{}{}
+					root["env"] = envBlock
+				}
+				// Env values are always strings in settings.json.
+				envBlock[s.Key] = v
+			} else {
+				root[s.Key] = v
+			}
+			if !had {
+				old = "(unset)"
+			}
+			changes = append(changes, fmt.Sprintf("  %-12s %s -> %s", s.Name, old, v))
+		}
+		if len(changes) == 0 {
+			fmt.Fprintf(w, "%s is already set in %s.\n", what, shortPath(path, env))
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", path, err)
+		}
+		if err := platform.WriteSettingsFile(path, root); err != nil {
+			return err
+		}
+		platform.PrintSuccess(w, fmt.Sprintf("Applied %s to %s:", what, shortPath(path, env)))
+		for _, c := range changes {
+			fmt.Fprintln(w, c)
+		}
+		printOverrides(w, resolve(env), scope)
+		return nil
+	})
+}
+
+// reset removes every managed setting from the scope's settings file.
+func reset(w io.Writer, env Env, scope config.ConfigScope) error {
+	path := config.SettingsFile(scope, env.Home, env.Cwd)
+	return platform.WithFileLock(path, func() error {
+		root, err := readSettings(path)
+		if err != nil {
+			return err
+		}
+		var removed []string
+		for _, s := range settings {
+			if _, ok := get(root, s); !ok {
+				continue
+			}
+			if s.Env {
+				envBlock := root["env"].(map[string]interface{})
+				delete(envBlock, s.Key)
+				if len(envBlock) == 0 {
+					delete(root, "env")
+				}
+			} else {
+				delete(root, s.Key)
+			}
+			removed = append(removed, s.Name)
+		}
+		if len(removed) == 0 {
+			fmt.Fprintf(w, "No model settings in %s.\n", shortPath(path, env))
+			return nil
+		}
+		if err := platform.WriteSettingsFile(path, root); err != nil {
+			return err
+		}
+		platform.PrintSuccess(w, fmt.Sprintf("Removed %s from %s.", strings.Join(removed, ", "), shortPath(path, env)))
+		return nil
+	})
+}
+
+// value is the effective value of a setting and the layer it comes from.
+type value struct {
+	Value  string
+	Source config.ConfigScope // ScopeDefault when no layer sets it
+}
+
+// effective holds the resolved settings, plus ANTHROPIC_MODEL, which
+// overrides the main model when set.
+type effective struct {
+	Values         map[string]value
+	AnthropicModel value
+}
+
+// precedence lists the settings layers from highest to lowest priority.
+var precedence = []config.ConfigScope{config.ScopeManaged, config.ScopeLocal, config.ScopeProject, config.ScopeUser}
+
+// resolve computes the effective value of each setting across the settings
+// layers, with the process environment winning for env vars.
+func resolve(env Env) effective {
+	layers := map[config.ConfigScope]map[string]interface{}{}
+	for _, scope := range precedence {
+		root, err := readSettings(config.SettingsFile(scope, env.Home, env.Cwd))
+		if err == nil {
+			layers[scope] = root
+		}
+	}
+	lookup := func(s setting) value {
+		if s.Env {
+			if v := os.Getenv(s.Key); v != "" {
+				return value{v, config.ScopeEnv}
+			}
+		}
+		for _, scope := range precedence {
+			if v, ok := get(layers[scope], s); ok {
+				return value{v, scope}
+			}
+		}
+		return value{Source: config.ScopeDefault}
+	}
+	e := effective{Values: map[string]value{}}
+	for _, s := range settings {
+		e.Values[s.Name] = lookup(s)
+	}
+	e.AnthropicModel = lookup(setting{Key: "ANTHROPIC_MODEL", Env: true})
+	return e
+}
+
+// matchingPreset returns the preset whose values are all in effect, or "".
+func (e effective) matchingPreset() string {
+	for _, p := range presets {
+		match := true
+		for name, v := range p.Values {
+			match = match && e.Values[name].Value == v
+		}
+		if match {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// claudeDefaults are shown for settings no layer sets.
+var claudeDefaults = map[string]string{
+	"main":        "Claude Code default",
+	"subagent":    "same as main",
+	"effort":      "high",
+	"autocompact": "95",
+}
+
+func printEffective(w io.Writer, e effective) {
+	platform.PrintBanner(w, "Model Routing")
+	fmt.Fprintln(w)
+	for _, s := range settings {
+		v := e.Values[s.Name]
+		shown, source := v.Value, string(v.Source)
+		if v.Source == config.ScopeDefault {
+			shown = claudeDefaults[s.Name]
+		}
+		fmt.Fprintf(w, "  %-12s %-22s %-10s %s\n", s.Name, shown, "("+source+")", s.Desc)
+	}
+	fmt.Fprintln(w)
+	if p := e.matchingPreset(); p != "" {
+		fmt.Fprintf(w, "  Matches preset: %s\n", p)
+	} else {
+		fmt.Fprintln(w, "  Matches no preset. List them with: claude-workspace models presets")
+	}
+	if e.AnthropicModel.Source != config.ScopeDefault {
+		platform.PrintWarn(w, fmt.Sprintf("ANTHROPIC_MODEL=%s (%s) overrides the main model", e.AnthropicModel.Value, e.AnthropicModel.Source))
+	}
+}
+
+// printOverrides warns about settings that a higher-priority layer still
+// overrides after writing to scope.
+func printOverrides(w io.Writer, e effective, scope config.ConfigScope) {
+	for _, s := range settings {
+		v := e.Values[s.Name]
+		if v.Source != scope && v.Source != config.ScopeDefault && outranks(v.Source, scope) {
+			platform.PrintWarn(w, fmt.Sprintf("%s is still %s, set in the %s layer, which takes precedence", s.Name, v.Value, v.Source))
+		}
+	}
+	if e.AnthropicModel.Source != config.ScopeDefault {
+		platform.PrintWarn(w, fmt.Sprintf("ANTHROPIC_MODEL=%s (%s) overrides the main model", e.AnthropicModel.Value, e.AnthropicModel.Source))
+	}
+}
+
+// outranks reports whether layer a takes precedence over layer b.
+func outranks(a, b config.ConfigScope) bool {
+	if a == config.ScopeEnv {
+		return true
+	}
+	for _, scope := range precedence {
+		switch scope {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+	return false
+}
+
+func printPresets(w io.Writer) {
+	platform.PrintBanner(w, "Model Presets")
+	fmt.Fprintln(w)
+	names := make([]string, len(settings))
+	for i, s := range settings {
+		names[i] = s.Name
+	}
+	for _, p := range presets {
+		fmt.Fprintf(w, "  %s\n", platform.Bold(p.Name))
+		fmt.Fprintf(w, "    %s\n", p.Desc)
+		var parts []string
+		for _, name := range names {
+			parts = append(parts, name+"="+p.Values[name])
+		}
+		fmt.Fprintf(w, "    %s\n\n", strings.Join(parts, "  "))
+	}
+	fmt.Fprintln(w, "  Apply one with: claude-workspace models use <preset> [--scope global|project|local]")
+}
+
+func shortPath(path string, env Env) string {
+	if rel, ok := strings.CutPrefix(path, env.Cwd+string(os.PathSeparator)); ok {
+		return rel
+	}
+	if rel, ok := strings.CutPrefix(path, env.Home); ok {
+		return "~" + rel
+	}
+	return path
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func testEnv(t *testing.T) Env {
+	t.Helper()
+	for _, s := range settings {
+		if s.Env {
+			t.Setenv(s.Key, "")
+		}
+	}
+	t.Setenv("ANTHROPIC_MODEL", "")
+	return Env{Home: t.TempDir(), Cwd: t.TempDir()}
+}
+
+func writeSettings(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUsePreset(t *testing.T) {
+	env := testEnv(t)
+	userSettings := filepath.Join(env.Home, ".claude", "settings.json")
+	writeSettings(t, userSettings, `{"env": {"CLAUDE_CODE_ENABLE_TASKS": "true", "CLAUDE_CODE_SUBAGENT_MODEL": "opus"}, "model": "opus"}`)
+
+	var out bytes.Buffer
+	if err := run(&out, []string{"use", "cost-saver"}, env); err != nil {
+		t.Fatal(err)
+	}
+	var root struct {
+		Model       string            `json:"model"`
+		EffortLevel string            `json:"effortLevel"`
+		Env         map[string]string `json:"env"`
+	}
+	if err := platform.ReadJSONFile(userSettings, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root.Model != "sonnet" || root.EffortLevel != "medium" || root.Env["CLAUDE_CODE_SUBAGENT_MODEL"] != "haiku" ||
+		root.Env["CLAUDE_AUTOCOMPACT_PCT_OVERRIDE"] != "70" || root.Env["CLAUDE_CODE_ENABLE_TASKS"] != "true" {
+		t.Errorf("settings after use cost-saver = %+v", root)
+	}
+	if !strings.Contains(out.String(), "main         opus -> sonnet") || !strings.Contains(out.String(), "effort       (unset) -> medium") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	e := resolve(env)
+	if e.matchingPreset() != "cost-saver" || e.Values["main"].Source != config.ScopeUser {
+		t.Errorf("resolve = %+v", e)
+	}
+
+	// A project setting outranks the user layer, and is reported.
+	writeSettings(t, filepath.Join(env.Cwd, ".claude", "settings.json"), `{"model": "opus"}`)
+	out.Reset()
+	if err := run(&out, []string{"use", "max-quality", "--scope", "global"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(&out, []string{"set", "main", "haiku"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "main is still opus, set in the project layer") {
+		t.Errorf("override not reported:\n%s", out.String())
+	}
+}
+
+func TestSetValidates(t *testing.T) {
+	env := testEnv(t)
+	for _, args := range [][]string{
+		{"set", "main", "gpt-4"},
+		{"set", "autocompact", "150"},
+		{"set", "effort", "max"},
+		{"set", "speed", "fast"},
+		{"use", "cheap"},
+		{"use", "platform", "--scope", "team"},
+	} {
+		if err := run(&bytes.Buffer{}, args, env); err == nil {
+			t.Errorf("models %q: expected error", args)
+		}
+	}
+	if platform.FileExists(filepath.Join(env.Home, ".claude", "settings.json")) {
+		t.Error("invalid values were written")
+	}
+
+	for _, ok := range []string{"opusplan", "claude-sonnet-4-5", "us.anthropic.claude-sonnet-4-5-20250929-v1:0", "sonnet[1m]"} {
+		if err := CheckModel(ok); err != nil {
+			t.Errorf("CheckModel(%q) = %v", ok, err)
+		}
+	}
+}
+
+func TestResetAndEnvOverride(t *testing.T) {
+	env := testEnv(t)
+	local := filepath.Join(env.Cwd, ".claude", "settings.local.json")
+	if err := run(&bytes.Buffer{}, []string{"use", "balanced", "--scope=local"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if e := resolve(env); e.matchingPreset() != "balanced" || e.Values["subagent"].Source != config.ScopeLocal {
+		t.Errorf("resolve after use --scope local = %+v", e)
+	}
+
+	t.Setenv("CLAUDE_CODE_SUBAGENT_MODEL", "haiku")
+	t.Setenv("ANTHROPIC_MODEL", "opus")
+	var out bytes.Buffer
+	if err := run(&out, nil, env); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "haiku") || !strings.Contains(out.String(), "(env)") || !strings.Contains(out.String(), "ANTHROPIC_MODEL=opus") {
+		t.Errorf("show:\n%s", out.String())
+	}
+
+	if err := run(&bytes.Buffer{}, []string{"reset", "--scope", "local"}, env); err != nil {
+		t.Fatal(err)
+	}
+	root, _ := readSettings(local)
+	if len(root) != 0 {
+		t.Errorf("settings.local.json after reset = %v", root)
+	}
+}
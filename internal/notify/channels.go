@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+)
+
+// Channel types.
+const (
+	TypeDesktop = "desktop"
+	TypeSlack   = "slack"
+	TypeWebhook = "webhook"
+)
+
+// Types lists the channel types.
+var Types = []string{TypeDesktop, TypeSlack, TypeWebhook}
+
+// sendTimeout bounds each webhook request, so an unreachable endpoint does not
+// hold up the command that finished.
+const sendTimeout = 10 * time.Second
+
+// runDesktop shows a desktop notification; tests replace it.
+var runDesktop = desktopNotify
+
+// deliver sends e to ch.
+func deliver(ch Channel, e Event) error {
+	switch ch.Type {
+	case TypeDesktop:
+		return runDesktop(e.Title, e.Message)
+	case TypeSlack:
+		text := fmt.Sprintf("*%s*\n%s", e.Title, e.Message)
+		if e.Host != "" {
+			text += fmt.Sprintf("\n_%s on %s_", e.Event, e.Host)
+		}
+		return post(ch, map[string]string{"text": text})
+	case TypeWebhook:
+		return post(ch, e)
+	default:
+		return fmt.Errorf("unknown channel type %q", ch.Type)
+	}
+}
+
+// desktopNotify shows a notification with osascript on macOS and notify-send
+// on Linux.
+func desktopNotify(title, message string) error {
+	message = strings.Join(strings.Fields(message), " ")
+	switch {
+	case runtime.GOOS == "darwin":
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		script := fmt.Sprintf(`display notification "%s" with title "%s"`, quote.Replace(message), quote.Replace(title))
+		return platform.RunQuiet("osascript", "-e", script)
+	case platform.Exists("notify-send"):
+		return platform.RunQuiet("notify-send", "--app-name=claude-workspace", title, message)
+	case runtime.GOOS == "linux":
+		return errors.New("notify-send not found (install libnotify)")
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+}
+
+// post sends payload as JSON to the channel's webhook URL.
+func post(ch Channel, payload any) error {
+	target, err := resolveURL(ch.URL)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claude-workspace")
+	resp, err := platform.HTTPClient(sendTimeout).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s returned %s: %s", RedactURL(ch.URL), resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// varRef matches a URL given as a ${NAME} reference.
+var varRef = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// resolveURL returns the webhook URL a channel points at. A ${NAME} reference
+// is read from the environment, or else from the secrets store, so webhook
+// URLs, which are credentials, need not be kept in config.json.
+func resolveURL(raw string) (string, error) {
+	if m := varRef.FindStringSubmatch(raw); m != nil {
+		name := m[1]
+		if v := os.Getenv(name); v != "" {
+			return v, nil
+		}
+		store, err := secrets.Open()
+		if err != nil {
+			return "", err
+		}
+		v, err := store.Get(name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return "", fmt.Errorf("%s is not set and not in the secrets store", name)
+		}
+		return v, err
+	}
+	return raw, nil
+}
+
+// ValidateURL checks a webhook URL given to "notify add".
+func ValidateURL(raw string) error {
+	if varRef.MatchString(raw) {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("invalid webhook URL %q: use an http(s) URL or a ${NAME} reference", raw)
+	}
+	return nil
+}
+
+// RedactURL shortens a webhook URL for display to its scheme and host, since
+// the path of a Slack webhook is its secret.
+func RedactURL(raw string) string {
+	if raw == "" || varRef.MatchString(raw) {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(invalid URL)"
+	}
+	if u.Path == "" || u.Path == "/" {
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host + "/..."
+}
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+const usage = `Usage: claude-workspace notify [list|add|remove|test|min-duration]
+  list                                   Show channels and the events they receive (default)
+  add <name> --type desktop|slack|webhook [--url <url>] [--events <a,b>] [--force]
+  remove <name>                          Remove a channel
+  test [<name>]                          Send a test notification to one or every channel
+  min-duration <duration>                Only report operations that run at least this long (default: 30s)`
+
+// Run implements the notify command.
+func Run(args []string) error {
+	w := platform.Stdout()
+	if len(args) == 0 {
+		return list(w)
+	}
+	switch args[0] {
+	case "list":
+		if _, err := cli.Operands("notify list", "notify list", args[1:], 0, 0); err != nil {
+			return err
+		}
+		return list(w)
+	case "add":
+		return add(w, args[1:])
+	case "remove", "rm":
+		args, err := cli.Operands("notify remove", "notify remove <name>", args[1:], 1, 1)
+		if err != nil {
+			return err
+		}
+		return remove(w, args[0])
+	case "test":
+		args, err := cli.Operands("notify test", "notify test [<name>]", args[1:], 0, 1)
+		if err != nil {
+			return err
+		}
+		return test(w, args)
+	case "min-duration":
+		args, err := cli.Operands("notify min-duration", "notify min-duration <duration>", args[1:], 1, 1)
+		if err != nil {
+			return err
+		}
+		return setMinDuration(w, args[0])
+	case "--help", "-h", "help":
+		fmt.Fprintln(w, usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown notify subcommand: %s\n%s", args[0], usage)
+	}
+}
+
+func list(w io.Writer) error {
+	c, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "Notifications")
+	fmt.Fprintln(w)
+	if len(c.Channels) == 0 {
+		fmt.Fprintln(w, "  No channels configured.")
+		fmt.Fprintln(w, "  Add one with: claude-workspace notify add desktop --type desktop")
+		fmt.Fprintln(w)
+		return nil
+	}
+	fmt.Fprintf(w, "  %-16s %-8s %-34s %s\n", "NAME", "TYPE", "URL", "EVENTS")
+	for _, ch := range c.Channels {
+		events := "all"
+		if len(ch.Events) > 0 {
+			events = strings.Join(ch.Events, ",")
+		}
+		target := RedactURL(ch.URL)
+		if target == "" {
+			target = "-"
+		}
+		fmt.Fprintf(w, "  %-16s %-8s %-34s %s\n", ch.Name, ch.Type, target, events)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Operations are reported when they run at least %s.\n", c.minDuration())
+	fmt.Fprintln(w)
+	return nil
+}
+
+type addOptions struct {
+	name   string
+	typ    string
+	url    string
+	events []string
+	force  bool
+}
+
+func parseAddArgs(args []string) (addOptions, error) {
+	var opts addOptions
+	f := cli.New("notify add", "notify add <name> --type desktop|slack|webhook [--url <url>] [--events <a,b>] [--force]")
+	f.StringVar(&opts.typ, "--type", "desktop|slack|webhook", "Channel type")
+	f.StringVar(&opts.url, "--url", "url", "Slack or webhook URL")
+	f.Func("--events", "a,b", "Events the channel receives (default: all)", func(v string) error {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				opts.events = append(opts.events, e)
+			}
+		}
+		return nil
+	})
+	f.BoolVar(&opts.force, "--force", "Replace a channel of the same name")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return opts, err
+	}
+	opts.name = positional[0]
+	if !slices.Contains(Types, opts.typ) {
+		return opts, fmt.Errorf("--type must be one of %s", strings.Join(Types, ", "))
+	}
+	switch {
+	case opts.typ == TypeDesktop && opts.url != "":
+		return opts, fmt.Errorf("desktop channels take no --url")
+	case opts.typ != TypeDesktop && opts.url == "":
+		return opts, fmt.Errorf("%s channels need --url", opts.typ)
+	case opts.url != "":
+		if err := ValidateURL(opts.url); err != nil {
+			return opts, err
+		}
+	}
+	for _, e := range opts.events {
+		if !slices.Contains(Events, e) {
+			return opts, fmt.Errorf("unknown event %q (valid: %s)", e, strings.Join(Events, ", "))
+		}
+	}
+	return opts, nil
+}
+
+func add(w io.Writer, args []string) error {
+	opts, err := parseAddArgs(args)
+	if err != nil {
+		return err
+	}
+	c, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	ch := Channel{Name: opts.name, Type: opts.typ, URL: opts.url, Events: opts.events}
+	replaced := false
+	for i := range c.Channels {
+		if c.Channels[i].Name == opts.name {
+			if !opts.force {
+				return fmt.Errorf("channel %q already exists; use --force to replace it", opts.name)
+			}
+			c.Channels[i], replaced = ch, true
+		}
+	}
+	if !replaced {
+		c.Channels = append(c.Channels, ch)
+	}
+	if err := SaveConfig(c); err != nil {
+		return err
+	}
+	verb := "Added"
+	if replaced {
+		verb = "Replaced"
+	}
+	platform.PrintOK(w, fmt.Sprintf("%s %s channel %q", verb, opts.typ, opts.name))
+	if opts.url != "" && !varRef.MatchString(opts.url) {
+		platform.PrintInfo(w, "The webhook URL is saved in ~/.claude-workspace/config.json. To keep it out of the file, store it with 'claude-workspace secrets set NAME' and use --url '${NAME}'.")
+	}
+	platform.PrintManual(w, "Send a test notification: claude-workspace notify test "+opts.name)
+	return nil
+}
+
+func remove(w io.Writer, name string) error {
+	c, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	kept := c.Channels[:0]
+	for _, ch := range c.Channels {
+		if ch.Name != name {
+			kept = append(kept, ch)
+		}
+	}
+	if len(kept) == len(c.Channels) {
+		return fmt.Errorf("no channel named %q", name)
+	}
+	c.Channels = kept
+	if err := SaveConfig(c); err != nil {
+		return err
+	}
+	platform.PrintOK(w, fmt.Sprintf("Removed channel %q", name))
+	return nil
+}
+
+func test(w io.Writer, args []string) error {
+	c, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	channels := c.Channels
+	if len(args) == 1 {
+		channels = nil
+		for _, ch := range c.Channels {
+			if ch.Name == args[0] {
+				channels = append(channels, ch)
+			}
+		}
+		if len(channels) == 0 {
+			return fmt.Errorf("no channel named %q", args[0])
+		}
+	}
+	if len(channels) == 0 {
+		return fmt.Errorf("no channels configured; add one with 'claude-workspace notify add'")
+	}
+	errs := send(channels, Event{
+		Event:   EventTest,
+		Status:  StatusOK,
+		Title:   "claude-workspace test notification",
+		Message: "Notifications from claude-workspace will arrive here.",
+	})
+	if sent := len(channels) - len(errs); sent > 0 {
+		platform.PrintOK(w, fmt.Sprintf("Sent a test notification to %d channel(s)", sent))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d notification(s) failed", len(errs), len(channels))
+	}
+	return nil
+}
+
+func setMinDuration(w io.Writer, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid duration %q: use a value like 30s, 2m, or 0s", value)
+	}
+	c, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	c.MinDuration = d.String()
+	if err := SaveConfig(c); err != nil {
+		return err
+	}
+	platform.PrintOK(w, fmt.Sprintf("Operations are reported when they run at least %s", d))
+	return nil
+}
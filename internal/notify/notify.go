@@ -0,0 +1,278 @@
+// Package notify sends notifications when long-running operations finish and
+// when thresholds are crossed, to the channels configured with the "notify"
+// command: desktop notifications, Slack incoming webhooks, and generic JSON
+// webhooks. Channels are kept under "notify" in ~/.claude-workspace/config.json.
+package notify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Events a channel can subscribe to.
+const (
+	EventEnrich           = "enrich"
+	EventFleet            = "fleet"
+	EventSandboxBatch     = "sandbox-batch"
+	EventUpgrade          = "upgrade"
+	EventCostBudget       = "cost-budget"
+	EventDoctorRegression = "doctor-regression"
+	EventTest             = "test" // sent by "notify test" to every channel
+)
+
+// Events lists the events channels can subscribe to.
+var Events = []string{EventEnrich, EventFleet, EventSandboxBatch, EventUpgrade, EventCostBudget, EventDoctorRegression}
+
+// Status values for an Event.
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+	StatusAlert  = "alert"
+)
+
+// configKey is the key in config.json holding the Config.
+const configKey = "notify"
+
+// DefaultMinDuration is how long an operation must run before its completion
+// is reported, unless the config sets minDuration.
+const DefaultMinDuration = 30 * time.Second
+
+// Config is the notification setup.
+type Config struct {
+	Channels    []Channel `json:"channels,omitempty"`
+	MinDuration string    `json:"minDuration,omitempty"` // e.g. "1m"; operations that finish sooner are not reported
+}
+
+// Channel is one place notifications are sent.
+type Channel struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`             // one of the Type* constants
+	URL    string   `json:"url,omitempty"`    // webhook URL, may be a ${NAME} reference
+	Events []string `json:"events,omitempty"` // empty means every event
+}
+
+// Wants reports whether c subscribes to event.
+func (c Channel) Wants(event string) bool {
+	if event == EventTest || len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is one notification.
+type Event struct {
+	Event           string    `json:"event"`
+	Status          string    `json:"status"`
+	Title           string    `json:"title"`
+	Message         string    `json:"message"`
+	Host            string    `json:"host"`
+	Time            time.Time `json:"time"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+}
+
+// LoadConfig reads the notification setup. A missing setup is not an error.
+func LoadConfig() (Config, error) {
+	var c Config
+	_, err := platform.ReadConfig(configKey, &c)
+	return c, err
+}
+
+// SaveConfig writes c to config.json, removing the key when c is empty.
+func SaveConfig(c Config) error {
+	if len(c.Channels) == 0 && c.MinDuration == "" {
+		return platform.DeleteConfig(configKey)
+	}
+	return platform.WriteConfig(configKey, c)
+}
+
+// minDuration returns the configured minimum duration, or the default when it
+// is unset or invalid.
+func (c Config) minDuration() time.Duration {
+	if d, err := time.ParseDuration(c.MinDuration); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultMinDuration
+}
+
+// channelsFor returns the channels subscribed to event.
+func (c Config) channelsFor(event string) []Channel {
+	var out []Channel
+	for _, ch := range c.Channels {
+		if ch.Wants(event) {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// Enabled reports whether any channel subscribes to event, so callers can skip
+// work, such as recording state, that only matters for notifications.
+func Enabled(event string) bool {
+	c, err := LoadConfig()
+	return err == nil && len(c.channelsFor(event)) > 0
+}
+
+// Send delivers e to every channel subscribed to it. Delivery failures are
+// printed as warnings to stderr and logged; they never fail the caller.
+func Send(e Event) {
+	c, err := LoadConfig()
+	if err != nil {
+		platform.Logger().Warn("notify: reading config", "error", err.Error())
+		return
+	}
+	send(c.channelsFor(e.Event), e)
+}
+
+func send(channels []Channel, e Event) []error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC().Truncate(time.Second)
+	}
+	if e.Host == "" {
+		e.Host, _ = os.Hostname()
+	}
+	var errs []error
+	for _, ch := range channels {
+		if err := deliver(ch, e); err != nil {
+			err = fmt.Errorf("notification to %s failed: %w", ch.Name, err)
+			platform.Logger().Warn("notify", "channel", ch.Name, "error", err.Error())
+			platform.PrintWarningLine(os.Stderr, err.Error())
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Finished reports that the operation command, run for event, took elapsed
+// and ended with err. Operations shorter than the configured minimum duration
+// are not reported.
+func Finished(event, command string, elapsed time.Duration, err error) {
+	c, cerr := LoadConfig()
+	if cerr != nil || elapsed < c.minDuration() {
+		return
+	}
+	channels := c.channelsFor(event)
+	if len(channels) == 0 {
+		return
+	}
+	e := Event{
+		Event:           event,
+		Status:          StatusOK,
+		Title:           command + " finished",
+		Message:         "Took " + elapsed.Round(time.Second).String() + ".",
+		DurationSeconds: elapsed.Round(time.Second).Seconds(),
+	}
+	if err != nil {
+		e.Status = StatusFailed
+		e.Title = command + " failed"
+		e.Message += " " + firstLine(err.Error())
+	}
+	send(channels, e)
+}
+
+// Alert sends e once for key: a threshold that stays crossed, such as a
+// budget for a month, is reported the first time it is seen and not again.
+func Alert(key string, e Event) {
+	if !Enabled(e.Event) {
+		return
+	}
+	sent := false
+	err := updateState(func(s state) {
+		if _, ok := s[key]; ok {
+			return
+		}
+		s[key] = stateEntry{Time: time.Now().UTC()}
+		sent = true
+	})
+	if err != nil {
+		platform.Logger().Warn("notify: recording state", "error", err.Error())
+		return
+	}
+	if sent {
+		e.Status = StatusAlert
+		Send(e)
+	}
+}
+
+// Regressed records current, the items failing now, under key and returns
+// those that were not failing when it was last called with key. The first
+// call for a key records a baseline and returns nothing.
+func Regressed(key string, current []string) ([]string, error) {
+	var regressed []string
+	err := updateState(func(s state) {
+		prev, seen := s[key]
+		s[key] = stateEntry{Time: time.Now().UTC(), Items: current}
+		if !seen {
+			return
+		}
+		was := make(map[string]bool, len(prev.Items))
+		for _, item := range prev.Items {
+			was[item] = true
+		}
+		for _, item := range current {
+			if !was[item] {
+				regressed = append(regressed, item)
+			}
+		}
+	})
+	return regressed, err
+}
+
+// state is what Alert and Regressed remember between runs, by key.
+type state map[string]stateEntry
+
+type stateEntry struct {
+	Time  time.Time `json:"time"`
+	Items []string  `json:"items,omitempty"`
+}
+
+// stateTTL is how long a state entry is kept after it was last written.
+const stateTTL = 90 * 24 * time.Hour
+
+func statePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "notify-state.json"), nil
+}
+
+// updateState applies fn to the saved state under its lock, dropping entries
+// older than stateTTL.
+func updateState(fn func(state)) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	return platform.WithFileLock(path, func() error {
+		s := state{}
+		if platform.FileExists(path) {
+			if err := platform.ReadJSONFile(path, &s); err != nil {
+				return err
+			}
+		}
+		fn(s)
+		for key, e := range s {
+			if time.Since(e.Time) > stateTTL {
+				delete(s, key)
+			}
+		}
+		return platform.WriteJSONFile(path, s)
+	})
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
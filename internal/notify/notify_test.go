@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a webhook endpoint that keeps the bodies it receives.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (r *recorder) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(body))
+		r.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func setup(t *testing.T, c Config) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	if err := SaveConfig(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFinished(t *testing.T) {
+	var hook, slack recorder
+	hookSrv, slackSrv := hook.server(t), slack.server(t)
+	t.Setenv("TEST_SLACK_WEBHOOK", slackSrv.URL+"/services/T/B/x")
+	var desktop []string
+	runDesktop = func(title, message string) error {
+		desktop = append(desktop, title)
+		return errors.New("no display")
+	}
+	t.Cleanup(func() { runDesktop = desktopNotify })
+
+	setup(t, Config{MinDuration: "1m", Channels: []Channel{
+		{Name: "ops", Type: TypeWebhook, URL: hookSrv.URL},
+		{Name: "team", Type: TypeSlack, URL: "${TEST_SLACK_WEBHOOK}", Events: []string{EventFleet}},
+		{Name: "me", Type: TypeDesktop, Events: []string{EventUpgrade}},
+	}})
+
+	Finished(EventFleet, "claude-workspace fleet upgrade", 30*time.Second, nil)
+	if len(hook.bodies) != 0 || len(slack.bodies) != 0 {
+		t.Fatal("an operation shorter than minDuration was reported")
+	}
+
+	Finished(EventFleet, "claude-workspace fleet upgrade", 90*time.Second, errors.New("2 of 5 repositories failed\ndetails"))
+	if len(hook.bodies) != 1 || len(slack.bodies) != 1 || len(desktop) != 0 {
+		t.Fatalf("webhook %d, slack %d, desktop %d notification(s)", len(hook.bodies), len(slack.bodies), len(desktop))
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(hook.bodies[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Event != EventFleet || e.Status != StatusFailed || e.Title != "claude-workspace fleet upgrade failed" ||
+		e.Message != "Took 1m30s. 2 of 5 repositories failed" || e.DurationSeconds != 90 || e.Host == "" {
+		t.Errorf("webhook event = %+v", e)
+	}
+	if !strings.HasPrefix(slack.bodies[0], `{"text":"*claude-workspace fleet upgrade failed*\nTook 1m30s.`) {
+		t.Errorf("slack payload = %s", slack.bodies[0])
+	}
+
+	// A failed delivery does not stop the others.
+	Finished(EventUpgrade, "claude-workspace upgrade", time.Hour, nil)
+	if len(desktop) != 1 || len(hook.bodies) != 2 {
+		t.Errorf("desktop %v, webhook %d notification(s)", desktop, len(hook.bodies))
+	}
+}
+
+func TestAlert(t *testing.T) {
+	var hook recorder
+	setup(t, Config{Channels: []Channel{{Name: "ops", Type: TypeWebhook, URL: hook.server(t).URL}}})
+
+	e := Event{Event: EventCostBudget, Title: "Monthly budget exceeded"}
+	Alert("cost-budget:Monthly:2026-03", e)
+	Alert("cost-budget:Monthly:2026-03", e)
+	Alert("cost-budget:Monthly:2026-04", e)
+	if len(hook.bodies) != 2 || !strings.Contains(hook.bodies[0], `"status":"alert"`) {
+		t.Errorf("alerts sent = %q, want one per key", hook.bodies)
+	}
+}
+
+func TestRegressed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	steps := []struct {
+		failing []string
+		want    []string
+	}{
+		{[]string{"claude-cli"}, nil}, // baseline
+		{[]string{"claude-cli", "hook:guard.sh"}, []string{"hook:guard.sh"}},
+		{[]string{"hook:guard.sh"}, nil},
+		{nil, nil},
+		{[]string{"claude-cli"}, []string{"claude-cli"}},
+	}
+	for i, s := range steps {
+		got, err := Regressed("doctor:/p", s.failing)
+		if err != nil || !reflect.DeepEqual(got, s.want) {
+			t.Errorf("step %d: Regressed = %v, %v, want %v", i, got, err, s.want)
+		}
+	}
+	if got, _ := Regressed("doctor:/other", []string{"claude-cli"}); got != nil {
+		t.Errorf("another key's baseline: %v", got)
+	}
+}
+
+func TestParseAddArgs(t *testing.T) {
+	opts, err := parseAddArgs([]string{"team", "--type", "slack", "--url", "${SLACK_WEBHOOK}", "--events", "fleet, cost-budget"})
+	if err != nil || opts.name != "team" || !reflect.DeepEqual(opts.events, []string{"fleet", "cost-budget"}) {
+		t.Errorf("parseAddArgs = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{
+		{"--type", "desktop"},
+		{"me", "--type", "email"},
+		{"me", "--type", "desktop", "--url", "https://example.com"},
+		{"ops", "--type", "webhook"},
+		{"ops", "--type", "webhook", "--url", "example.com/hook"},
+		{"ops", "--type", "webhook", "--url", "https://example.com", "--events", "deploy"},
+		{"ops", "other", "--type", "desktop"},
+	} {
+		if _, err := parseAddArgs(args); err == nil {
+			t.Errorf("parseAddArgs(%q): expected error", args)
+		}
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://hooks.slack.com/services/T0/B0/secret": "https://hooks.slack.com/...",
+		"https://ops.example.com":                       "https://ops.example.com",
+		"${SLACK_WEBHOOK}":                              "${SLACK_WEBHOOK}",
+	} {
+		if got := RedactURL(in); got != want {
+			t.Errorf("RedactURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
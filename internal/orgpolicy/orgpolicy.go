@@ -0,0 +1,474 @@
+// Package orgpolicy enforces an organization's signed settings policy. A
+// platform team publishes a JSON policy document, and its signature next to it
+// as <url>.sig; claude-workspace verifies the document against the
+// organization's public key and merges its mandatory deny rules and env
+// settings into ~/.claude/settings.json. The settings it adds are recorded as
+// managed, so doctor can flag them when they are removed.
+package orgpolicy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// maxDocumentSize bounds how much of a policy or signature response is read.
+const maxDocumentSize = 1 << 20
+
+// httpClient fetches policy documents. Tests replace it.
+var httpClient = platform.HTTPClient(10 * time.Second)
+
+// Document is a signed organization policy:
+//
+//	{
+//	  "name": "acme-baseline",
+//	  "version": "2026-10-01",
+//	  "permissions": {"deny": ["Bash(kubectl delete *)", "Read(./.env)"]},
+//	  "env": {"DISABLE_TELEMETRY": "1"}
+//	}
+type Document struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version,omitempty"`
+	Permissions Permissions       `json:"permissions"`
+	Env         map[string]string `json:"env,omitempty"`
+}
+
+// Permissions holds the rules a policy makes mandatory.
+type Permissions struct {
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Managed is the set of settings a policy puts in ~/.claude/settings.json.
+type Managed struct {
+	Deny []string          `json:"deny,omitempty"`
+	Env  map[string]string `json:"env,omitempty"`
+}
+
+// Config is the saved policy source and what was last applied from it.
+type Config struct {
+	URL       string  `json:"url"`
+	PublicKey string  `json:"publicKey"`
+	Name      string  `json:"name,omitempty"`
+	Version   string  `json:"version,omitempty"`
+	SHA256    string  `json:"sha256,omitempty"`
+	SyncedAt  string  `json:"syncedAt,omitempty"`
+	Managed   Managed `json:"managed"`
+}
+
+// ErrSignatureInvalid is returned when a policy document does not match its
+// signature.
+var ErrSignatureInvalid = errors.New("org policy signature verification failed")
+
+// ParseDocument decodes and validates a policy document. Unknown fields are
+// rejected: a setting the organization believes is enforced must not be
+// silently ignored by an older claude-workspace.
+func ParseDocument(data []byte) (*Document, error) {
+	var d Document
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return nil, fmt.Errorf("parsing org policy: %w (upgrade claude-workspace if the policy uses newer fields)", err)
+	}
+	if d.Name == "" {
+		return nil, fmt.Errorf("org policy has no name")
+	}
+	for _, rule := range d.Permissions.Deny {
+		if strings.TrimSpace(rule) == "" || strings.TrimSpace(rule) != rule {
+			return nil, fmt.Errorf("org policy %s: invalid deny rule %q", d.Name, rule)
+		}
+	}
+	for key := range d.Env {
+		if !platform.IsEnvVarName(key) {
+			return nil, fmt.Errorf("org policy %s: invalid env name %q", d.Name, key)
+		}
+	}
+	return &d, nil
+}
+
+// managed returns the settings the document makes mandatory.
+func (d *Document) managed() Managed {
+	return Managed{Deny: d.Permissions.Deny, Env: d.Env}
+}
+
+// Fetch reads the policy at location (an https URL or a local path) and its
+// signature at location+".sig", and verifies the signature against publicKey.
+func Fetch(location, publicKey string) (*Document, []byte, error) {
+	data, err := read(location)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := read(location + ".sig")
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading org policy signature: %w", err)
+	}
+	if err := platform.VerifySignature(publicKey, data, sig); err != nil {
+		return nil, nil, fmt.Errorf("%w for %s: %v", ErrSignatureInvalid, location, err)
+	}
+	d, err := ParseDocument(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, data, nil
+}
+
+func read(location string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(location, "http://"):
+		return nil, fmt.Errorf("refusing to fetch %s over plain http; use https", location)
+	case strings.HasPrefix(location, "https://"):
+		resp, err := httpClient.Get(location)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", location, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: status %d", location, resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	default:
+		return os.ReadFile(strings.TrimPrefix(location, "file://"))
+	}
+}
+
+// configPath returns ~/.claude-workspace/org-policy.json.
+func configPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "org-policy.json"), nil
+}
+
+// settingsPath returns ~/.claude/settings.json.
+func settingsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", "settings.json"), nil
+}
+
+// Load returns the saved policy configuration, or nil if none is set.
+func Load() (*Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	if !platform.FileExists(path) {
+		return nil, nil
+	}
+	var cfg Config
+	if err := platform.ReadJSONFile(path, &cfg); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func save(cfg *Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	return platform.WriteJSONFile(path, cfg)
+}
+
+// Clear removes the saved policy configuration. The settings it applied stay
+// in ~/.claude/settings.json but are no longer managed.
+func Clear() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Set fetches and verifies the policy at location, enforces it, and saves it
+// as the organization policy. keyFile holds the PEM (or base64 DER) public key;
+// when empty, the key saved for the current policy is reused.
+func Set(w io.Writer, location, keyFile string) error {
+	prev, err := Load()
+	if err != nil {
+		return err
+	}
+	var key string
+	switch {
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("reading public key: %w", err)
+		}
+		key = strings.TrimSpace(string(data))
+	case prev != nil:
+		key = prev.PublicKey
+	default:
+		return fmt.Errorf("a public key file is required to verify the org policy")
+	}
+	if !strings.HasPrefix(location, "https://") && !strings.HasPrefix(location, "http://") {
+		abs, err := filepath.Abs(strings.TrimPrefix(location, "file://"))
+		if err != nil {
+			return err
+		}
+		location = abs
+	}
+
+	doc, data, err := Fetch(location, key)
+	if err != nil {
+		return err
+	}
+	cfg := &Config{URL: location, PublicKey: key}
+	var applied Managed
+	if prev != nil {
+		applied = prev.Managed
+	}
+	if err := apply(w, cfg, applied, doc, data); err != nil {
+		return err
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Org policy set: %s", cfg.Label()))
+	return nil
+}
+
+// Sync enforces the saved policy, fetching the latest version first unless
+// offline is set. When the policy cannot be fetched or verified, the settings
+// last applied are enforced again, so a reset settings.json does not drop
+// them. It does nothing when no policy is configured.
+func Sync(w io.Writer, offline bool) error {
+	cfg, err := Load()
+	if err != nil || cfg == nil {
+		return err
+	}
+	if !offline {
+		doc, data, err := Fetch(cfg.URL, cfg.PublicKey)
+		if err == nil {
+			return apply(w, cfg, cfg.Managed, doc, data)
+		}
+		platform.PrintWarningLine(w, fmt.Sprintf("Could not update the org policy: %v", err))
+	}
+	changes, err := enforce(cfg.Managed, cfg.Managed)
+	if err != nil {
+		return err
+	}
+	printChanges(w, changes)
+	fmt.Fprintf(w, "  Org policy %s enforced (last synced %s)\n", cfg.Label(), cfg.SyncedAt)
+	return nil
+}
+
+// apply enforces doc, replacing the settings applied from the previous
+// version, and records it in cfg.
+func apply(w io.Writer, cfg *Config, applied Managed, doc *Document, data []byte) error {
+	next := doc.managed()
+	changes, err := enforce(applied, next)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	cfg.Name, cfg.Version = doc.Name, doc.Version
+	cfg.SHA256 = hex.EncodeToString(sum[:])
+	cfg.SyncedAt = time.Now().UTC().Format(time.RFC3339)
+	cfg.Managed = next
+	if err := save(cfg); err != nil {
+		return err
+	}
+	printChanges(w, changes)
+	fmt.Fprintf(w, "  Org policy %s enforced in ~/.claude/settings.json\n", cfg.Label())
+	return nil
+}
+
+// enforce writes next into ~/.claude/settings.json, dropping settings that
+// prev applied and next no longer includes. Env values the user changed since
+// prev applied them are overwritten; removed ones are left alone. It returns
+// a description of each change.
+func enforce(prev, next Managed) ([]string, error) {
+	path, err := settingsPath()
+	if err != nil {
+		return nil, err
+	}
+	var changes []string
+	err = platform.WithFileLock(path, func() error {
+		settings := map[string]interface{}{}
+		if platform.FileExists(path) {
+			if err := platform.ReadJSONFile(path, &settings); err != nil {
+				return fmt.Errorf("reading %s: %w", path, err)
+			}
+			if settings == nil {
+				settings = map[string]interface{}{}
+			}
+		}
+		if changes = enforceSettings(settings, prev, next); len(changes) == 0 {
+			return nil
+		}
+		return platform.WriteSettingsFile(path, settings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func enforceSettings(settings map[string]interface{}, prev, next Managed) []string {
+	var changes []string
+
+	perms, _ := settings["permissions"].(map[string]interface{})
+	if perms == nil {
+		perms = map[string]interface{}{}
+	}
+	wanted := make(map[string]bool, len(next.Deny))
+	for _, rule := range next.Deny {
+		wanted[rule] = true
+	}
+	dropped := make(map[string]bool)
+	for _, rule := range prev.Deny {
+		if !wanted[rule] {
+			dropped[rule] = true
+		}
+	}
+	deny := []interface{}{}
+	have := make(map[string]bool)
+	if list, ok := perms["deny"].([]interface{}); ok {
+		for _, item := range list {
+			if rule, ok := item.(string); ok {
+				if dropped[rule] {
+					changes = append(changes, "- deny "+rule)
+					continue
+				}
+				have[rule] = true
+			}
+			deny = append(deny, item)
+		}
+	}
+	for _, rule := range next.Deny {
+		if !have[rule] {
+			deny = append(deny, rule)
+			have[rule] = true
+			changes = append(changes, "+ deny "+rule)
+		}
+	}
+	if len(deny) > 0 || perms["deny"] != nil {
+		perms["deny"] = deny
+		settings["permissions"] = perms
+	}
+
+	env, _ := settings["env"].(map[string]interface{})
+	if env == nil {
+		env = map[string]interface{}{}
+	}
+	for _, key := range sortedKeys(prev.Env) {
+		if _, ok := next.Env[key]; !ok && env[key] == prev.Env[key] {
+			delete(env, key)
+			changes = append(changes, "- env "+key)
+		}
+	}
+	for _, key := range sortedKeys(next.Env) {
+		if env[key] != next.Env[key] {
+			env[key] = next.Env[key]
+			changes = append(changes, fmt.Sprintf("+ env %s=%s", key, next.Env[key]))
+		}
+	}
+	if len(env) > 0 || settings["env"] != nil {
+		settings["env"] = env
+	}
+	return changes
+}
+
+// Drift returns the managed settings missing from, or changed in,
+// ~/.claude/settings.json.
+func Drift(cfg *Config) ([]string, error) {
+	path, err := settingsPath()
+	if err != nil {
+		return nil, err
+	}
+	var settings struct {
+		Permissions struct {
+			Deny []string `json:"deny"`
+		} `json:"permissions"`
+		Env map[string]interface{} `json:"env"`
+	}
+	if platform.FileExists(path) {
+		if err := platform.ReadJSONFile(path, &settings); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	have := make(map[string]bool)
+	for _, rule := range settings.Permissions.Deny {
+		have[rule] = true
+	}
+	var drift []string
+	for _, rule := range cfg.Managed.Deny {
+		if !have[rule] {
+			drift = append(drift, "deny rule removed: "+rule)
+		}
+	}
+	for _, key := range sortedKeys(cfg.Managed.Env) {
+		switch v, ok := settings.Env[key]; {
+		case !ok:
+			drift = append(drift, "env removed: "+key)
+		case v != cfg.Managed.Env[key]:
+			drift = append(drift, fmt.Sprintf("env changed: %s=%v (policy: %s)", key, v, cfg.Managed.Env[key]))
+		}
+	}
+	return drift, nil
+}
+
+// CheckLatest fetches the policy and reports whether the version last applied
+// is current, and the latest document.
+func CheckLatest(cfg *Config) (current bool, doc *Document, err error) {
+	doc, data, err := Fetch(cfg.URL, cfg.PublicKey)
+	if err != nil {
+		return false, nil, err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) == cfg.SHA256, doc, nil
+}
+
+// IsManagedDeny reports whether cfg enforces rule.
+func (cfg *Config) IsManagedDeny(rule string) bool {
+	if cfg == nil {
+		return false
+	}
+	for _, r := range cfg.Managed.Deny {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Label formats the policy name and version for display.
+func (cfg *Config) Label() string {
+	if cfg.Version == "" {
+		return cfg.Name
+	}
+	return cfg.Name + " " + cfg.Version
+}
+
+func printChanges(w io.Writer, changes []string) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,252 @@
+package orgpolicy
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// signer publishes signed policy documents in a temp directory.
+type signer struct {
+	t       *testing.T
+	priv    *ecdsa.PrivateKey
+	keyFile string
+	dir     string
+}
+
+func newSigner(t *testing.T) *signer {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "org.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &signer{t: t, priv: priv, keyFile: keyFile, dir: dir}
+}
+
+func (s *signer) sign(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, s.priv, digest[:])
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+// publish writes doc and its signature, returning the document path.
+func (s *signer) publish(doc string) string {
+	s.t.Helper()
+	path := filepath.Join(s.dir, "policy.json")
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		s.t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".sig", s.sign([]byte(doc)), 0644); err != nil {
+		s.t.Fatal(err)
+	}
+	return path
+}
+
+func useHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	return home
+}
+
+type userSettings struct {
+	Model       string              `json:"model"`
+	Permissions map[string][]string `json:"permissions"`
+	Env         map[string]string   `json:"env"`
+}
+
+func readSettings(t *testing.T, home string) userSettings {
+	t.Helper()
+	var s userSettings
+	if err := platform.ReadJSONFile(filepath.Join(home, ".claude", "settings.json"), &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func writeSettings(t *testing.T, home, content string) {
+	t.Helper()
+	path := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetSyncAndDrift(t *testing.T) {
+	home := useHome(t)
+	writeSettings(t, home, `{"model": "opus", "permissions": {"deny": ["Bash(rm -rf /)"]}, "env": {"MINE": "1"}}`)
+	s := newSigner(t)
+	path := s.publish(`{"name": "acme", "version": "1",
+		"permissions": {"deny": ["Bash(kubectl delete *)", "Read(./.env)"]},
+		"env": {"DISABLE_TELEMETRY": "1", "OLD": "x"}}`)
+
+	if err := Set(io.Discard, path, s.keyFile); err != nil {
+		t.Fatal(err)
+	}
+	got := readSettings(t, home)
+	if got.Model != "opus" ||
+		!reflect.DeepEqual(got.Permissions["deny"], []string{"Bash(rm -rf /)", "Bash(kubectl delete *)", "Read(./.env)"}) ||
+		!reflect.DeepEqual(got.Env, map[string]string{"MINE": "1", "DISABLE_TELEMETRY": "1", "OLD": "x"}) {
+		t.Errorf("settings after set = %+v", got)
+	}
+	cfg, err := Load()
+	if err != nil || cfg == nil || cfg.Label() != "acme 1" || cfg.SHA256 == "" {
+		t.Fatalf("Load() = %+v, %v", cfg, err)
+	}
+
+	// The user removes a managed rule and changes a managed env value.
+	writeSettings(t, home, `{"model": "opus", "permissions": {"deny": ["Read(./.env)"]}, "env": {"DISABLE_TELEMETRY": "0", "OLD": "x"}}`)
+	drift, err := Drift(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"deny rule removed: Bash(kubectl delete *)", "env changed: DISABLE_TELEMETRY=0 (policy: 1)"}
+	if !reflect.DeepEqual(drift, want) {
+		t.Errorf("Drift() = %q, want %q", drift, want)
+	}
+
+	// Version 2 drops Read(./.env) and OLD. Syncing restores what the user
+	// removed and cleans up what the policy no longer requires.
+	s.publish(`{"name": "acme", "version": "2",
+		"permissions": {"deny": ["Bash(kubectl delete *)"]},
+		"env": {"DISABLE_TELEMETRY": "1"}}`)
+	if current, _, err := CheckLatest(cfg); err != nil || current {
+		t.Errorf("CheckLatest() = %v, %v; want a newer version", current, err)
+	}
+	if err := Sync(io.Discard, false); err != nil {
+		t.Fatal(err)
+	}
+	got = readSettings(t, home)
+	if !reflect.DeepEqual(got.Permissions["deny"], []string{"Bash(kubectl delete *)"}) ||
+		!reflect.DeepEqual(got.Env, map[string]string{"DISABLE_TELEMETRY": "1"}) {
+		t.Errorf("settings after sync = %+v", got)
+	}
+	cfg, _ = Load()
+	if drift, _ := Drift(cfg); len(drift) != 0 || cfg.Version != "2" {
+		t.Errorf("after sync: version %s, drift %q", cfg.Version, drift)
+	}
+	if current, _, err := CheckLatest(cfg); err != nil || !current {
+		t.Errorf("CheckLatest() after sync = %v, %v", current, err)
+	}
+}
+
+func TestSync_TamperedPolicyKeepsLastVersion(t *testing.T) {
+	home := useHome(t)
+	s := newSigner(t)
+	path := s.publish(`{"name": "acme", "permissions": {"deny": ["Bash(kubectl delete *)"]}}`)
+	if err := Set(io.Discard, path, s.keyFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone swaps the document without re-signing it, and settings.json is
+	// reset (as setup --force does).
+	if err := os.WriteFile(path, []byte(`{"name": "acme"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeSettings(t, home, `{}`)
+	cfg, _ := Load()
+	if _, _, err := CheckLatest(cfg); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("CheckLatest() error = %v, want ErrSignatureInvalid", err)
+	}
+
+	var out bytes.Buffer
+	if err := Sync(&out, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "signature verification failed") {
+		t.Errorf("Sync() should warn about the signature, got:\n%s", out.String())
+	}
+	if got := readSettings(t, home); !reflect.DeepEqual(got.Permissions["deny"], []string{"Bash(kubectl delete *)"}) {
+		t.Errorf("Sync() should enforce the last verified policy, got %+v", got)
+	}
+}
+
+func TestFetch_HTTPS(t *testing.T) {
+	s := newSigner(t)
+	doc := []byte(`{"name": "acme", "env": {"A": "1"}}`)
+	sig := s.sign(doc)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/policy.json":
+			_, _ = w.Write(doc)
+		case "/policy.json.sig":
+			_, _ = w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	old := httpClient
+	httpClient = server.Client()
+	t.Cleanup(func() { httpClient = old })
+
+	key, _ := os.ReadFile(s.keyFile)
+	d, _, err := Fetch(server.URL+"/policy.json", string(key))
+	if err != nil || d.Env["A"] != "1" {
+		t.Fatalf("Fetch() = %+v, %v", d, err)
+	}
+	if _, _, err := Fetch(server.URL+"/missing.json", string(key)); err == nil {
+		t.Error("Fetch() of a missing policy should fail")
+	}
+	if _, _, err := Fetch(strings.Replace(server.URL, "https://", "http://", 1)+"/policy.json", string(key)); err == nil {
+		t.Error("Fetch() over plain http should fail")
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown field":  `{"name": "acme", "permissions": {"allow": ["Bash"]}}`,
+		"no name":        `{"permissions": {"deny": ["Bash"]}}`,
+		"empty rule":     `{"name": "acme", "permissions": {"deny": [""]}}`,
+		"bad env name":   `{"name": "acme", "env": {"A-B": "1"}}`,
+		"not json":       `name: acme`,
+		"non-string env": `{"name": "acme", "env": {"A": 1}}`,
+	} {
+		if _, err := ParseDocument([]byte(data)); err == nil {
+			t.Errorf("%s: ParseDocument(%s) should fail", name, data)
+		}
+	}
+}
+
+func TestSet_RequiresKey(t *testing.T) {
+	useHome(t)
+	s := newSigner(t)
+	path := s.publish(`{"name": "acme"}`)
+	if err := Set(io.Discard, path, ""); err == nil {
+		t.Error("Set() without a key should fail when none is saved")
+	}
+	if err := Sync(io.Discard, false); err != nil {
+		t.Errorf("Sync() without a configured policy should do nothing, got %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package plans
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Plan statuses, as the plan-and-execute and plan-resume skills write them.
+const (
+	StatusDraft      = "Draft"
+	StatusApproved   = "Approved"
+	StatusInProgress = "In Progress"
+	StatusComplete   = "Complete"
+)
+
+// Statuses lists the plan statuses in workflow order.
+var Statuses = []string{StatusDraft, StatusApproved, StatusInProgress, StatusComplete}
+
+// Plan is a plan document: YAML-style frontmatter followed by a Markdown body
+// whose first "# " line is the title.
+//
+// Plans written before "plans new" existed have no frontmatter and instead
+// carry "Date:" and "Status:" lines under the title; those are read as
+// created and status.
+type Plan struct {
+	Path     string
+	Title    string
+	Status   string
+	Owner    string
+	Created  string   // YYYY-MM-DD
+	Updated  string   // YYYY-MM-DD
+	Archived string   // YYYY-MM-DD; empty for active plans
+	Sessions []string // IDs of the Claude Code sessions that worked on it
+	Done     int      // checked "- [x]" items
+	Total    int      // all "- [ ]" and "- [x]" items
+
+	// fields holds the frontmatter keys in file order, including ones this
+	// package does not know, so rewriting the frontmatter keeps them.
+	fields []field
+	body   string // everything after the frontmatter
+}
+
+type field struct {
+	key    string
+	value  string
+	values []string // list items, for keys written as "key:" followed by "- item" lines
+}
+
+// Name returns the plan's file name without the .md extension.
+func (p *Plan) Name() string {
+	return strings.TrimSuffix(filepath.Base(p.Path), ".md")
+}
+
+// Read reads and parses the plan at path.
+func Read(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := Parse(string(data))
+	p.Path = path
+	return p, nil
+}
+
+// Parse parses a plan document.
+func Parse(content string) *Plan {
+	p := &Plan{}
+	p.fields, p.body = splitFrontmatter(content)
+	for _, f := range p.fields {
+		switch f.key {
+		case "status":
+			p.Status = NormalizeStatus(f.value)
+		case "owner":
+			p.Owner = f.value
+		case "created":
+			p.Created = f.value
+		case "updated":
+			p.Updated = f.value
+		case "archived":
+			p.Archived = f.value
+		case "sessions":
+			p.Sessions = f.values
+		}
+	}
+
+	// Legacy header lines are only read between the title and the first
+	// section, so a "Status:" line in the body is left alone.
+	header := true
+	for _, line := range strings.Split(p.body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "## ") {
+			header = false
+		}
+		switch {
+		case p.Title == "" && strings.HasPrefix(trimmed, "# "):
+			p.Title = strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+		case header && p.Status == "" && strings.HasPrefix(trimmed, "Status:"):
+			p.Status = NormalizeStatus(strings.TrimPrefix(trimmed, "Status:"))
+		case header && p.Created == "" && strings.HasPrefix(trimmed, "Date:"):
+			p.Created = strings.TrimSpace(strings.TrimPrefix(trimmed, "Date:"))
+		case header && p.Updated == "" && strings.HasPrefix(trimmed, "Last Updated:"):
+			p.Updated = strings.TrimSpace(strings.TrimPrefix(trimmed, "Last Updated:"))
+		case strings.HasPrefix(trimmed, "- [ ]"):
+			p.Total++
+		case strings.HasPrefix(trimmed, "- [x]"), strings.HasPrefix(trimmed, "- [X]"):
+			p.Total++
+			p.Done++
+		}
+	}
+	if p.Status == "" {
+		p.Status = StatusDraft
+	}
+	return p
+}
+
+// NormalizeStatus returns the canonical spelling of a status such as
+// "in-progress" or "COMPLETE", or s trimmed if it is not a known status.
+func NormalizeStatus(s string) string {
+	s = strings.Trim(strings.TrimSpace(s), `"'`)
+	key := strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(s))
+	for _, status := range Statuses {
+		if strings.ToLower(status) == key {
+			return status
+		}
+	}
+	if key == "done" || key == "completed" {
+		return StatusComplete
+	}
+	return s
+}
+
+// set sets a frontmatter key, adding it at the end if it is not present.
+func (p *Plan) set(key, value string, values []string) {
+	for i := range p.fields {
+		if p.fields[i].key == key {
+			p.fields[i].value, p.fields[i].values = value, values
+			return
+		}
+	}
+	p.fields = append(p.fields, field{key: key, value: value, values: values})
+}
+
+// SetStatus sets the plan's status in its frontmatter and, for plans that
+// keep it in a "Status:" line under the title, there too.
+func (p *Plan) SetStatus(status string) {
+	p.Status = status
+	p.set("status", status, nil)
+	p.body = replaceHeaderLine(p.body, "Status:", status)
+}
+
+// Touch records date as the plan's last update.
+func (p *Plan) Touch(date string) {
+	p.Updated = date
+	p.set("updated", date, nil)
+	p.body = replaceHeaderLine(p.body, "Last Updated:", date)
+}
+
+// Archive marks the plan archived on date.
+func (p *Plan) Archive(date string) {
+	p.Archived = date
+	p.set("archived", date, nil)
+}
+
+// Link records the given session IDs on the plan, skipping ones already
+// linked. It reports how many were added.
+func (p *Plan) Link(ids ...string) int {
+	added := 0
+	for _, id := range ids {
+		if !slices.Contains(p.Sessions, id) {
+			p.Sessions = append(p.Sessions, id)
+			added++
+		}
+	}
+	if added > 0 {
+		p.set("sessions", "", p.Sessions)
+	}
+	return added
+}
+
+// String formats the plan as a document: the frontmatter, then the body.
+func (p *Plan) String() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, f := range p.fields {
+		if f.values != nil || f.value == "" {
+			fmt.Fprintf(&b, "%s:\n", f.key)
+			for _, v := range f.values {
+				fmt.Fprintf(&b, "  - %s\n", v)
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", f.key, f.value)
+	}
+	b.WriteString("---\n")
+	b.WriteString(p.body)
+	return b.String()
+}
+
+// Write saves the plan to its path.
+func (p *Plan) Write() error {
+	return os.WriteFile(p.Path, []byte(p.String()), 0644)
+}
+
+// splitFrontmatter splits a document into its frontmatter fields and the
+// body after them. A document without a closed --- block has no fields.
+func splitFrontmatter(content string) ([]field, string) {
+	rest, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return nil, content
+	}
+	front, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		if front, ok = strings.CutSuffix(rest, "\n---"); !ok {
+			return nil, content
+		}
+		body = ""
+	}
+
+	var fields []field
+	for _, line := range strings.Split(front, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && len(fields) > 0 && line != trimmed {
+			last := &fields[len(fields)-1]
+			last.values = append(last.values, strings.Trim(strings.TrimSpace(item), `"'`))
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		f := field{key: strings.TrimSpace(key), value: strings.Trim(value, `"'`)}
+		if value == "[]" {
+			f.value, f.values = "", []string{}
+		}
+		fields = append(fields, f)
+	}
+	return fields, body
+}
+
+// replaceHeaderLine replaces the value of the header line of body that starts
+// with prefix, such as "Status:", if there is one before the first section.
+func replaceHeaderLine(body, prefix, value string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "## ") {
+			break
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			lines[i] = prefix + " " + value
+			return strings.Join(lines, "\n")
+		}
+	}
+	return body
+}
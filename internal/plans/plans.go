@@ -0,0 +1,519 @@
+// Package plans implements the "plans" command, which scaffolds, lists,
+// shows, archives, and links plan documents in the project's plans directory
+// (./.claude/plans unless settings.json sets plansDirectory). Plans are the
+// files the plan-and-execute and plan-resume skills write and resume from.
+package plans
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// defaultDir is where plans live when settings.json does not set
+// plansDirectory.
+const defaultDir = ".claude/plans"
+
+// archiveDir is the subdirectory of the plans directory archived plans move to.
+const archiveDir = "archive"
+
+// templateFile is an optional plan template in the plans directory that
+// "plans new" uses instead of the built-in one.
+const templateFile = ".template.md"
+
+// defaultTemplate is the body of a new plan. It follows the sections the
+// plan-and-execute skill asks for.
+const defaultTemplate = `# {{title}}
+
+## Goal
+
+## Implementation Steps
+
+- [ ]
+
+## Affected Files
+
+## Risks
+
+## Test Strategy
+
+## Documentation Updates
+`
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Run routes the plans subcommand.
+func Run(args []string) error {
+	subcmd := "list"
+	if len(args) > 0 {
+		subcmd = args[0]
+		args = args[1:]
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot determine working directory: %w", err)
+	}
+	dir := Dir(cwd)
+	today := time.Now().Format("2006-01-02")
+	w := platform.Stdout()
+
+	switch subcmd {
+	case "list":
+		return runList(w, dir, args)
+	case "show":
+		args, err := cli.Operands("plans show", "plans show <plan>", args, 1, 1)
+		if err != nil {
+			return err
+		}
+		return show(w, dir, args[0])
+	case "new":
+		return runNew(w, dir, args, today)
+	case "archive":
+		return runArchive(w, dir, args, today)
+	case "link":
+		return runLink(w, cwd, dir, args, today)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown plans subcommand: %s\n", subcmd)
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace plans [list|show|new|archive|link]")
+		return fmt.Errorf("unknown subcommand: %s", subcmd)
+	}
+}
+
+// Dir returns the plans directory of the project at projectDir: the
+// plansDirectory set in its .claude/settings.json, resolved against
+// projectDir, or .claude/plans.
+func Dir(projectDir string) string {
+	var settings struct {
+		PlansDirectory string `json:"plansDirectory"`
+	}
+	if data, err := os.ReadFile(filepath.Join(projectDir, ".claude", "settings.json")); err == nil {
+		_ = json.Unmarshal(data, &settings)
+	}
+	dir := settings.PlansDirectory
+	if dir == "" {
+		dir = defaultDir
+	}
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, dir[2:])
+		}
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(projectDir, filepath.FromSlash(dir))
+}
+
+// List returns the plans in dir, newest first by creation date. With
+// archived set it includes the plans in dir/archive.
+func List(dir string, archived bool) ([]*Plan, error) {
+	dirs := []string{dir}
+	if archived {
+		dirs = append(dirs, filepath.Join(dir, archiveDir))
+	}
+	var plans []*Plan
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			p, err := Read(filepath.Join(d, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, p)
+		}
+	}
+	sort.SliceStable(plans, func(i, j int) bool {
+		if plans[i].Created != plans[j].Created {
+			return plans[i].Created > plans[j].Created
+		}
+		return plans[i].Name() < plans[j].Name()
+	})
+	return plans, nil
+}
+
+// Resolve finds the plan ref names in dir or dir/archive: a path, a file
+// name with or without .md, or a part of one file name.
+func Resolve(dir, ref string) (*Plan, error) {
+	if strings.ContainsRune(ref, filepath.Separator) && platform.FileExists(ref) {
+		return Read(ref)
+	}
+	all, err := List(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(ref, ".md")
+	var matches []*Plan
+	for _, p := range all {
+		if p.Name() == name {
+			return p, nil
+		}
+		if strings.Contains(p.Name(), name) {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no plan matching %q in %s", ref, dir)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, p := range matches {
+		names[i] = p.Name()
+	}
+	return nil, fmt.Errorf("plan %q is ambiguous (matches %s)", ref, strings.Join(names, ", "))
+}
+
+// runList handles "plans list [--all] [--status <status>]".
+func runList(w io.Writer, dir string, args []string) error {
+	all := false
+	status := ""
+	f := cli.New("plans list", "plans list [--all] [--status <status>]")
+	f.BoolVar(&all, "--all", "Include archived plans")
+	f.StringVar(&status, "--status", "status", "Only plans with this status")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	status = NormalizeStatus(status)
+
+	plans, err := List(dir, all)
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		filtered := plans[:0]
+		for _, p := range plans {
+			if p.Status == status {
+				filtered = append(filtered, p)
+			}
+		}
+		plans = filtered
+	}
+
+	platform.PrintBanner(w, "Plans")
+	if len(plans) == 0 {
+		fmt.Fprintf(w, "\n  No plans in %s. Create one with: claude-workspace plans new \"<title>\"\n\n", dir)
+		return nil
+	}
+	printTable(w, plans)
+	return nil
+}
+
+// printTable prints plans as a table of status, creation date, checklist
+// progress, owner, and name.
+func printTable(w io.Writer, plans []*Plan) {
+	fmt.Fprintf(w, "\n  %-12s  %-10s  %-8s  %-12s  %s\n", "STATUS", "CREATED", "STEPS", "OWNER", "PLAN")
+	fmt.Fprintf(w, "  %-12s  %-10s  %-8s  %-12s  %s\n", "------------", "----------", "--------", "------------", strings.Repeat("-", 40))
+	for _, p := range plans {
+		status := p.Status
+		if p.Archived != "" {
+			status = "Archived"
+		}
+		steps := "-"
+		if p.Total > 0 {
+			steps = fmt.Sprintf("%d/%d", p.Done, p.Total)
+		}
+		fmt.Fprintf(w, "  %-12s  %-10s  %-8s  %-12s  %s\n", status, dash(p.Created), steps, dash(truncate(p.Owner, 12)), p.Name())
+	}
+	fmt.Fprintln(w)
+}
+
+// show prints a plan's metadata, the sessions linked to it, and its body.
+func show(w io.Writer, dir, ref string) error {
+	p, err := Resolve(dir, ref)
+	if err != nil {
+		return err
+	}
+	title := p.Title
+	if title == "" {
+		title = p.Name()
+	}
+	platform.PrintBanner(w, title)
+	fmt.Fprintf(w, "\n  File:     %s\n", p.Path)
+	fmt.Fprintf(w, "  Status:   %s\n", p.Status)
+	fmt.Fprintf(w, "  Owner:    %s\n", dash(p.Owner))
+	fmt.Fprintf(w, "  Created:  %s\n", dash(p.Created))
+	fmt.Fprintf(w, "  Updated:  %s\n", dash(p.Updated))
+	if p.Archived != "" {
+		fmt.Fprintf(w, "  Archived: %s\n", p.Archived)
+	}
+	if p.Total > 0 {
+		fmt.Fprintf(w, "  Steps:    %d of %d done\n", p.Done, p.Total)
+	}
+
+	if len(p.Sessions) > 0 {
+		platform.PrintSection(w, "Sessions")
+		for _, id := range p.Sessions {
+			s, err := sessions.Find(id)
+			if err != nil {
+				fmt.Fprintf(w, "  %-10s  (not found in ~/.claude/projects)\n", truncate(id, 10))
+				continue
+			}
+			fmt.Fprintf(w, "  %-10s  %-16s  %s\n", truncate(s.ID, 10), s.StartTime.Local().Format("2006-01-02 15:04"), s.Title)
+		}
+		fmt.Fprintln(w, "\n  Review one with: claude-workspace sessions show <session-id>")
+	}
+
+	platform.PrintSection(w, "Plan")
+	fmt.Fprintln(w, strings.TrimRight(p.body, "\n"))
+	return nil
+}
+
+// runNew handles "plans new <title> [--owner <name>] [--status <status>]".
+func runNew(w io.Writer, dir string, args []string, today string) error {
+	var owner, status string
+	const usage = "plans new <title> [--owner <name>] [--status <status>]"
+	f := cli.New("plans new", usage)
+	f.StringVar(&owner, "--owner", "name", "Plan owner (default: git user.name)")
+	f.StringVar(&status, "--status", "status", "Initial status")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	title := strings.Join(positional, " ")
+	if strings.TrimSpace(title) == "" {
+		return fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+	if owner == "" {
+		owner = defaultOwner()
+	}
+
+	p, err := New(dir, title, owner, status, today)
+	if err != nil {
+		return err
+	}
+	platform.PrintSuccess(w, "Created "+p.Path)
+	fmt.Fprintln(w, "  Fill in the plan, then have Claude resume it with /plan-resume.")
+	return nil
+}
+
+// New writes a plan titled title to dir as plan-<today>-<slug>.md, from
+// dir/.template.md if there is one. Status defaults to Draft.
+func New(dir, title, owner, status, today string) (*Plan, error) {
+	if status == "" {
+		status = StatusDraft
+	}
+	status = NormalizeStatus(status)
+	if !slices.Contains(Statuses, status) {
+		return nil, fmt.Errorf("unknown status %q (valid: %s)", status, strings.Join(Statuses, ", "))
+	}
+	slug := Slug(title)
+	if slug == "" {
+		return nil, fmt.Errorf("title %q has no letters or digits to name the file after", title)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("plan-%s-%s.md", today, slug))
+	if platform.FileExists(path) {
+		return nil, fmt.Errorf("%s already exists", path)
+	}
+
+	template := defaultTemplate
+	if data, err := os.ReadFile(filepath.Join(dir, templateFile)); err == nil {
+		template = string(data)
+	}
+	content := strings.NewReplacer(
+		"{{title}}", title, "{{owner}}", owner, "{{status}}", status, "{{date}}", today,
+	).Replace(template)
+
+	p := Parse(content)
+	p.Path = path
+	// A custom template may carry frontmatter of its own; these keys are
+	// always set, in this order, ahead of the template's.
+	fields := p.fields
+	p.fields = nil
+	p.set("status", status, nil)
+	p.set("owner", owner, nil)
+	p.set("created", today, nil)
+	p.set("updated", today, nil)
+	for _, f := range fields {
+		p.set(f.key, f.value, f.values)
+	}
+	p.set("sessions", "", []string{})
+	reparsed := Parse(p.String())
+	reparsed.Path = path
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := reparsed.Write(); err != nil {
+		return nil, err
+	}
+	return reparsed, nil
+}
+
+// runArchive handles "plans archive <plan>... | --completed [--force]".
+func runArchive(w io.Writer, dir string, args []string, today string) error {
+	completed, force := false, false
+	const usage = "plans archive <plan>... | --completed [--force]"
+	f := cli.New("plans archive", usage)
+	f.BoolVar(&completed, "--completed", "Archive every completed plan")
+	f.BoolVar(&force, "--force", "Archive plans that are not completed")
+	refs, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if completed == (len(refs) > 0) {
+		return fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+
+	var targets []*Plan
+	if completed {
+		active, err := List(dir, false)
+		if err != nil {
+			return err
+		}
+		for _, p := range active {
+			if p.Status == StatusComplete {
+				targets = append(targets, p)
+			}
+		}
+		if len(targets) == 0 {
+			platform.PrintInfo(w, "No completed plans to archive")
+			return nil
+		}
+	}
+	for _, ref := range refs {
+		p, err := Resolve(dir, ref)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, p)
+	}
+
+	for _, p := range targets {
+		dest, err := Archive(dir, p, today, force)
+		if err != nil {
+			return err
+		}
+		platform.PrintOK(w, "Archived "+p.Name()+" to "+dest)
+	}
+	return nil
+}
+
+// Archive moves p into dir/archive and records the date. Plans that are not
+// Complete are refused unless force is set.
+func Archive(dir string, p *Plan, today string, force bool) (string, error) {
+	if p.Archived != "" || filepath.Base(filepath.Dir(p.Path)) == archiveDir {
+		return "", fmt.Errorf("%s is already archived", p.Name())
+	}
+	if p.Status != StatusComplete && !force {
+		return "", fmt.Errorf("%s is %s, not %s; finish it or pass --force", p.Name(), p.Status, StatusComplete)
+	}
+	dest := filepath.Join(dir, archiveDir, filepath.Base(p.Path))
+	if platform.FileExists(dest) {
+		return "", fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	p.Archive(today)
+	p.Touch(today)
+	if err := p.Write(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(p.Path, dest); err != nil {
+		return "", err
+	}
+	p.Path = dest
+	return dest, nil
+}
+
+// runLink handles "plans link <plan> [session-id...]". Without session IDs it
+// links the project's most recent session.
+func runLink(w io.Writer, cwd, dir string, args []string, today string) error {
+	args, err := cli.Operands("plans link", "plans link <plan> [session-id...]", args, 1, -1)
+	if err != nil {
+		return err
+	}
+	p, err := Resolve(dir, args[0])
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, prefix := range args[1:] {
+		s, err := sessions.Find(prefix)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, s.ID)
+	}
+	if len(ids) == 0 {
+		recent, err := sessions.ForProject(cwd)
+		if err != nil {
+			return err
+		}
+		if len(recent) == 0 {
+			return fmt.Errorf("no sessions found for %s; pass a session ID", cwd)
+		}
+		ids = append(ids, recent[0].ID)
+	}
+
+	if p.Link(ids...) == 0 {
+		platform.PrintInfo(w, "Already linked to "+p.Name())
+		return nil
+	}
+	p.Touch(today)
+	if err := p.Write(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		platform.PrintOK(w, "Linked session "+id+" to "+p.Name())
+	}
+	return nil
+}
+
+// Slug turns a title into the kebab-case part of a plan file name, keeping
+// at most five words.
+func Slug(title string) string {
+	words := strings.Fields(nonSlug.ReplaceAllString(strings.ToLower(title), " "))
+	if len(words) > 5 {
+		words = words[:5]
+	}
+	return strings.Join(words, "-")
+}
+
+// defaultOwner returns the git user name, or $USER.
+func defaultOwner() string {
+	if name, err := platform.Output("git", "config", "user.name"); err == nil && strings.TrimSpace(name) != "" {
+		return strings.TrimSpace(name)
+	}
+	return os.Getenv("USER")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
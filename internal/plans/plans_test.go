@@ -0,0 +1,242 @@
+package plans
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse_Frontmatter(t *testing.T) {
+	p := Parse(`---
+status: in-progress
+owner: Dana
+created: 2026-03-01
+updated: 2026-03-04
+sessions:
+  - 3f2a9c1e-0000
+  - 7b1d4e2f-0000
+reviewer: sam
+---
+# Add auth middleware
+
+## Implementation Steps
+
+- [x] Write middleware
+- [ ] Wire routes
+- [X] Add tests
+`)
+	if p.Title != "Add auth middleware" || p.Status != StatusInProgress || p.Owner != "Dana" ||
+		p.Created != "2026-03-01" || p.Updated != "2026-03-04" {
+		t.Errorf("Parse() = %+v", p)
+	}
+	if want := []string{"3f2a9c1e-0000", "7b1d4e2f-0000"}; !reflect.DeepEqual(p.Sessions, want) {
+		t.Errorf("Sessions = %q, want %q", p.Sessions, want)
+	}
+	if p.Done != 2 || p.Total != 3 {
+		t.Errorf("progress = %d/%d, want 2/3", p.Done, p.Total)
+	}
+}
+
+func TestParse_LegacyHeader(t *testing.T) {
+	p := Parse(`# Refactor cache
+
+Date: 2026-02-27
+Status: Complete
+Last Updated: 2026-02-28
+
+## Notes
+
+Status: this line is body text
+`)
+	if p.Title != "Refactor cache" || p.Status != StatusComplete || p.Created != "2026-02-27" || p.Updated != "2026-02-28" {
+		t.Errorf("Parse() = %+v", p)
+	}
+
+	p.SetStatus(StatusInProgress)
+	p.Touch("2026-03-01")
+	got := p.String()
+	for _, want := range []string{"status: In Progress\n", "Status: In Progress\n", "Last Updated: 2026-03-01\n", "Status: this line is body text\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestString_RoundTrip(t *testing.T) {
+	in := "---\nstatus: Draft\nowner: Dana\nreviewer: sam\nsessions:\n---\n# Title\n\nBody\n"
+	p := Parse(in)
+	if got := p.String(); got != in {
+		t.Errorf("String() = %q, want %q", got, in)
+	}
+	if p.Link("abc", "abc", "def") != 2 || p.Link("abc") != 0 {
+		t.Error("Link() should add each session once")
+	}
+	if got := Parse(p.String()).Sessions; !reflect.DeepEqual(got, []string{"abc", "def"}) {
+		t.Errorf("Sessions after Link = %q", got)
+	}
+}
+
+func TestNormalizeStatus(t *testing.T) {
+	tests := map[string]string{
+		"draft":       StatusDraft,
+		" APPROVED ":  StatusApproved,
+		"in_progress": StatusInProgress,
+		`"Complete"`:  StatusComplete,
+		"done":        StatusComplete,
+		"Blocked":     "Blocked",
+	}
+	for in, want := range tests {
+		if got := NormalizeStatus(in); got != want {
+			t.Errorf("NormalizeStatus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Add auth middleware":                    "add-auth-middleware",
+		"Fix: N+1 queries in /orders":            "fix-n-1-queries-in",
+		"  Migrate to Go 1.22 — part two of it ": "migrate-to-go-1-22",
+		"???": "",
+	}
+	for in, want := range tests {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	project := t.TempDir()
+	if got, want := Dir(project), filepath.Join(project, ".claude", "plans"); got != want {
+		t.Errorf("Dir() without settings = %q, want %q", got, want)
+	}
+	writeFile(t, filepath.Join(project, ".claude", "settings.json"), `{"plansDirectory": "./docs/plans"}`)
+	if got, want := Dir(project), filepath.Join(project, "docs", "plans"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	p, err := New(dir, "Add auth middleware", "Dana", "", "2026-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "plan-2026-03-01-add-auth-middleware.md"); p.Path != want {
+		t.Errorf("Path = %q, want %q", p.Path, want)
+	}
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHead := "---\nstatus: Draft\nowner: Dana\ncreated: 2026-03-01\nupdated: 2026-03-01\nsessions:\n---\n# Add auth middleware\n\n## Goal\n"
+	if !strings.HasPrefix(string(data), wantHead) {
+		t.Errorf("new plan =\n%s\nwant prefix\n%s", data, wantHead)
+	}
+
+	if _, err := New(dir, "Add auth middleware", "Dana", "", "2026-03-01"); err == nil {
+		t.Error("New() over an existing plan should fail")
+	}
+	if _, err := New(dir, "Other", "Dana", "Someday", "2026-03-01"); err == nil {
+		t.Error("New() with an unknown status should fail")
+	}
+}
+
+func TestNew_Template(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, templateFile), "---\nticket: \n---\n# {{title}}\n\nOwner: {{owner}}\n\n## Steps\n")
+	p, err := New(dir, "Rotate keys", "Dana", "approved", "2026-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\nstatus: Approved\nowner: Dana\ncreated: 2026-03-01\nupdated: 2026-03-01\nticket:\nsessions:\n---\n# Rotate keys\n\nOwner: Dana\n\n## Steps\n"
+	if got := p.String(); got != want {
+		t.Errorf("new plan =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestResolveAndList(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plan-2026-02-01-add-auth.md"), "# Add auth\n\nDate: 2026-02-01\nStatus: Complete\n")
+	writeFile(t, filepath.Join(dir, "plan-2026-03-01-add-cache.md"), "---\nstatus: Draft\ncreated: 2026-03-01\n---\n# Add cache\n")
+	writeFile(t, filepath.Join(dir, archiveDir, "plan-2026-01-01-old.md"), "---\nstatus: Complete\ncreated: 2026-01-01\narchived: 2026-01-09\n---\n# Old\n")
+	writeFile(t, filepath.Join(dir, templateFile), "# {{title}}\n")
+
+	active, err := List(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range active {
+		names = append(names, p.Name())
+	}
+	if want := []string{"plan-2026-03-01-add-cache", "plan-2026-02-01-add-auth"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %q, want %q", names, want)
+	}
+	if all, _ := List(dir, true); len(all) != 3 {
+		t.Errorf("List(archived) returned %d plans, want 3", len(all))
+	}
+
+	for ref, want := range map[string]string{
+		"plan-2026-02-01-add-auth.md": "plan-2026-02-01-add-auth",
+		"add-cache":                   "plan-2026-03-01-add-cache",
+		"old":                         "plan-2026-01-01-old",
+	} {
+		p, err := Resolve(dir, ref)
+		if err != nil || p.Name() != want {
+			t.Errorf("Resolve(%q) = %v, %v; want %s", ref, p, err, want)
+		}
+	}
+	if _, err := Resolve(dir, "add-"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Resolve(ambiguous) = %v", err)
+	}
+	if _, err := Resolve(dir, "missing"); err == nil {
+		t.Error("Resolve(missing) should fail")
+	}
+}
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plan-2026-02-01-done.md"), "# Done\n\nDate: 2026-02-01\nStatus: Complete\nLast Updated: 2026-02-03\n")
+	writeFile(t, filepath.Join(dir, "plan-2026-02-02-wip.md"), "---\nstatus: In Progress\n---\n# WIP\n")
+
+	var buf bytes.Buffer
+	if err := runArchive(&buf, dir, []string{"wip"}, "2026-03-01"); err == nil {
+		t.Error("archiving an unfinished plan without --force should fail")
+	}
+	if err := runArchive(&buf, dir, []string{"--completed"}, "2026-03-01"); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Read(filepath.Join(dir, archiveDir, "plan-2026-02-01-done.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Archived != "2026-03-01" || p.Updated != "2026-03-01" || p.Status != StatusComplete {
+		t.Errorf("archived plan = %+v", p)
+	}
+	if active, _ := List(dir, false); len(active) != 1 || active[0].Name() != "plan-2026-02-02-wip" {
+		t.Errorf("active plans after archive = %v", active)
+	}
+
+	if err := runArchive(&buf, dir, []string{"wip", "--force"}, "2026-03-01"); err != nil {
+		t.Errorf("archive --force = %v", err)
+	}
+	if err := runArchive(&buf, dir, []string{"done"}, "2026-03-01"); err == nil {
+		t.Error("archiving an archived plan should fail")
+	}
+}
@@ -68,34 +68,33 @@ func ExtractTo(efs fs.FS, srcDir, destDir string, force bool) error {
 	})
 }
 
-// ExtractForSymlink extracts embedded assets to ~/.claude-workspace/assets/
-// and returns the path. Used by attach --symlink to create a shared cache.
-func ExtractForSymlink() (string, error) {
+// AssetCacheDir returns the shared asset cache directory (~/.claude-workspace/assets)
+// that attach --symlink points project files at.
+func AssetCacheDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
+	return filepath.Join(home, ".claude-workspace", "assets"), nil
+}
 
-	cacheDir := filepath.Join(home, ".claude-workspace", "assets")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", err
-	}
-
-	// Always re-extract to update cached assets
-	if err := ExtractTo(FS, ".claude", filepath.Join(cacheDir, ".claude"), true); err != nil {
-		return "", fmt.Errorf("extracting .claude assets: %w", err)
-	}
-
-	// Extract .mcp.json
-	data, err := fs.ReadFile(FS, ".mcp.json")
+// ExtractForSymlink extracts the embedded assets of version to
+// ~/.claude-workspace/assets/ and returns the path. Used by attach --symlink
+// to create a shared cache, kept in version directories (see assetversions.go).
+func ExtractForSymlink(version string) (string, error) {
+	cacheDir, err := AssetCacheDir()
 	if err != nil {
-		return "", fmt.Errorf("reading embedded .mcp.json: %w", err)
-	}
-	if err := os.WriteFile(filepath.Join(cacheDir, ".mcp.json"), data, 0644); err != nil {
-		return "", fmt.Errorf("writing .mcp.json: %w", err)
+		return "", err
 	}
+	return cacheDir, ExtractForSymlinkTo(cacheDir, version)
+}
 
-	return cacheDir, nil
+// ExtractForSymlinkTo extracts the current FS (.claude/ and .mcp.json) to a
+// new version directory of cacheDir and switches cacheDir to it, for attach
+// --symlink with a template other than the embedded one. version names the
+// directory: the binary's version, or the template's revision.
+func ExtractForSymlinkTo(cacheDir, version string) error {
+	return extractVersioned(cacheDir, version)
 }
 
 // ReadAsset reads a file from the embedded FS and returns its contents.
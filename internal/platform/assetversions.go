@@ -0,0 +1,319 @@
+package platform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// An asset cache, such as ~/.claude-workspace/assets, keeps every set of
+// assets it extracts in a directory of its own, named for the version that
+// extracted it:
+//
+//	v1.4.0/              .claude/ and .mcp.json as released in v1.4.0
+//	v1.5.0/
+//	current -> v1.5.0    switched atomically by each extraction
+//	previous -> v1.4.0   the set current replaced, for upgrade --rollback
+//	.claude -> current/.claude
+//	.mcp.json -> current/.mcp.json
+//
+// Projects link through .claude and .mcp.json, which never change, so
+// switching current moves every project to the new set at once, and no
+// project ever sees a set that is half written or mixes two versions. A
+// version directory is complete before it is renamed into place and is not
+// written to afterwards.
+const (
+	currentAssets  = "current"
+	previousAssets = "previous"
+	legacyAssets   = "legacy"
+	assetDigest    = ".digest"
+)
+
+// assetEntries are the cache entries projects link through.
+var assetEntries = []string{".claude", ".mcp.json"}
+
+// extractVersioned extracts the current FS to a version directory of cacheDir,
+// unless the cache already holds the same assets, and makes it current. It
+// holds the cache's lock throughout, so concurrent extractions, even by
+// different versions of the binary, take turns.
+func extractVersioned(cacheDir, version string) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+	return WithFileLock(cacheDir, func() error {
+		if err := migrateFlatAssets(cacheDir); err != nil {
+			return fmt.Errorf("moving unversioned assets aside: %w", err)
+		}
+		digest, err := digestAssets(FS)
+		if err != nil {
+			return err
+		}
+		name, err := versionDir(cacheDir, version, digest)
+		if err != nil {
+			return err
+		}
+		if err := switchAssets(cacheDir, name); err != nil {
+			return err
+		}
+		pruneAssetVersions(cacheDir)
+		return nil
+	})
+}
+
+// versionDir returns the name of the directory of cacheDir that holds the
+// assets with digest, extracting them first if needed. The directory is
+// named for version; assets that differ from an earlier extraction by the
+// same version, such as a development build or changed overrides, get the
+// digest appended.
+func versionDir(cacheDir, version, digest string) (string, error) {
+	base := assetVersionName(version)
+	for _, name := range []string{base, base + "-" + digest[:12]} {
+		dir := filepath.Join(cacheDir, name)
+		data, err := os.ReadFile(filepath.Join(dir, assetDigest))
+		if err == nil && strings.TrimSpace(string(data)) == digest {
+			return name, nil
+		}
+		if !FileExists(dir) {
+			return name, extractVersionDir(cacheDir, name, digest)
+		}
+	}
+	// The digest-named directory holds other assets, which only a cache
+	// edited by hand can cause: replace it.
+	name := base + "-" + digest[:12]
+	if err := os.RemoveAll(filepath.Join(cacheDir, name)); err != nil {
+		return "", err
+	}
+	return name, extractVersionDir(cacheDir, name, digest)
+}
+
+// extractVersionDir extracts the current FS to a temporary directory of
+// cacheDir and renames it to name once it is complete.
+func extractVersionDir(cacheDir, name, digest string) error {
+	tmp, err := os.MkdirTemp(cacheDir, ".extract-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := ExtractTo(FS, ".claude", filepath.Join(tmp, ".claude"), true); err != nil {
+		return fmt.Errorf("extracting .claude assets: %w", err)
+	}
+	data, err := fs.ReadFile(FS, ".mcp.json")
+	if err != nil {
+		return fmt.Errorf("reading embedded .mcp.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, ".mcp.json"), data, 0644); err != nil {
+		return fmt.Errorf("writing .mcp.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, assetDigest), []byte(digest+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(cacheDir, name))
+}
+
+// assetVersionName returns version as a directory name: "dev" when it is
+// empty, with path separators and leading dots replaced, and never one of
+// the names the cache itself uses.
+func assetVersionName(version string) string {
+	name := strings.NewReplacer("/", "-", `\`, "-").Replace(strings.TrimLeft(version, "."))
+	switch name {
+	case "":
+		return "dev"
+	case currentAssets, previousAssets, legacyAssets:
+		return "version-" + name
+	}
+	return name
+}
+
+// isDigestName reports whether name is base with a digest appended, as
+// versionDir names a second set of assets for the same version.
+func isDigestName(name, base string) bool {
+	suffix, ok := strings.CutPrefix(name, base+"-")
+	if !ok || len(suffix) != 12 {
+		return false
+	}
+	_, err := hex.DecodeString(suffix)
+	return err == nil
+}
+
+// digestAssets returns the SHA-256 of the .claude tree and .mcp.json of fsys,
+// covering both the paths and the contents of the files.
+func digestAssets(fsys fs.FS) (string, error) {
+	h := sha256.New()
+	add := func(path string) error {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("reading embedded %s: %w", path, err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", path, len(data))
+		h.Write(data)
+		return nil
+	}
+	err := fs.WalkDir(fsys, ".claude", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		return add(path)
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading embedded .claude assets: %w", err)
+	}
+	if err := add(".mcp.json"); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// switchAssets points current at the version directory name, and previous
+// at the one current pointed at before, and moves the projects attached with
+// --hardlink to the new files. It then makes sure the entries symlinked
+// projects link through lead to current.
+func switchAssets(cacheDir, name string) error {
+	old, _ := os.Readlink(filepath.Join(cacheDir, currentAssets))
+	if old != name {
+		if err := replaceSymlink(filepath.Join(cacheDir, currentAssets), name); err != nil {
+			return fmt.Errorf("switching to %s: %w", name, err)
+		}
+		if old != "" {
+			if err := replaceSymlink(filepath.Join(cacheDir, previousAssets), old); err != nil {
+				return err
+			}
+			relinkProjects(cacheDir, old, name)
+		}
+	}
+	for _, entry := range assetEntries {
+		target := currentAssets + "/" + entry
+		if link, _ := os.Readlink(filepath.Join(cacheDir, entry)); link == target {
+			continue
+		}
+		if err := replaceSymlink(filepath.Join(cacheDir, entry), target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSymlink atomically makes path a symlink to target, by renaming a
+// new link over whatever link path was.
+func replaceSymlink(path, target string) error {
+	tmp := path + ".new"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// migrateFlatAssets moves the .claude directory and .mcp.json file of a
+// cache written before caches were versioned into the version directory
+// "legacy" and makes it current, so the links of existing projects keep
+// resolving and the next extraction records it as previous.
+func migrateFlatAssets(cacheDir string) error {
+	info, err := os.Lstat(filepath.Join(cacheDir, ".claude"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	legacy := filepath.Join(cacheDir, legacyAssets)
+	if err := os.RemoveAll(legacy); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		return err
+	}
+	for _, entry := range assetEntries {
+		info, err := os.Lstat(filepath.Join(cacheDir, entry))
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if err := os.Rename(filepath.Join(cacheDir, entry), filepath.Join(legacy, entry)); err != nil {
+			return err
+		}
+	}
+	return switchAssets(cacheDir, legacyAssets)
+}
+
+// pruneAssetVersions removes the version directories of cacheDir other than
+// current and previous, and whatever failed extractions left behind. It is
+// best effort: a directory that cannot be removed is tried again next time.
+func pruneAssetVersions(cacheDir string) {
+	keep := map[string]bool{}
+	for _, link := range []string{currentAssets, previousAssets} {
+		if name, err := os.Readlink(filepath.Join(cacheDir, link)); err == nil {
+			keep[name] = true
+		}
+	}
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() && !keep[e.Name()] {
+			_ = os.RemoveAll(filepath.Join(cacheDir, e.Name()))
+		}
+	}
+}
+
+// UseAssetVersion makes the assets that version extracted to cacheDir current
+// again, as upgrade --rollback does after restoring the binary of version.
+// With version "", the set current replaced is used. It reports false when
+// cacheDir keeps no such set; current is then left as it is.
+func UseAssetVersion(cacheDir, version string) (bool, error) {
+	if !FileExists(cacheDir) {
+		return false, nil
+	}
+	var found bool
+	err := WithFileLock(cacheDir, func() error {
+		name := assetVersionFor(cacheDir, version)
+		if name == "" {
+			return nil
+		}
+		found = true
+		return switchAssets(cacheDir, name)
+	})
+	return found, err
+}
+
+// assetVersionFor returns the newest version directory of cacheDir extracted
+// by version, or the target of previous when version is "".
+func assetVersionFor(cacheDir, version string) string {
+	if version == "" {
+		name, err := os.Readlink(filepath.Join(cacheDir, previousAssets))
+		if err != nil || !FileExists(filepath.Join(cacheDir, name)) {
+			return ""
+		}
+		return name
+	}
+	base := assetVersionName(version)
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return ""
+	}
+	var newest string
+	var newestTime int64
+	for _, e := range entries {
+		if !e.IsDir() || (e.Name() != base && !isDigestName(e.Name(), base)) {
+			continue
+		}
+		if !FileExists(filepath.Join(cacheDir, e.Name(), assetDigest)) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if t := info.ModTime().UnixNano(); newest == "" || t > newestTime {
+			newest, newestTime = e.Name(), t
+		}
+	}
+	return newest
+}
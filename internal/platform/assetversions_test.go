@@ -0,0 +1,219 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// useAssets sets FS to a template whose planner agent says agent.
+func useAssets(t *testing.T, agent string) {
+	t.Helper()
+	oldFS := FS
+	t.Cleanup(func() { FS = oldFS })
+	FS = fstest.MapFS{
+		".claude/agents/planner.md": {Data: []byte(agent)},
+		".mcp.json":                 {Data: []byte("{}")},
+	}
+}
+
+func readLinkedAgent(t *testing.T, cacheDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(cacheDir, ".claude", "agents", "planner.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// versionDirs returns the version directories of cacheDir.
+func versionDirs(t *testing.T, cacheDir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func TestExtractForSymlinkTo_Versions(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "assets")
+
+	useAssets(t, "v1")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.0.0"); err != nil {
+		t.Fatalf("ExtractForSymlinkTo() error = %v", err)
+	}
+	if got, _ := os.Readlink(filepath.Join(cacheDir, "current")); got != "v1.0.0" {
+		t.Errorf("current -> %q, want v1.0.0", got)
+	}
+	for _, entry := range []string{".claude", ".mcp.json"} {
+		if got, _ := os.Readlink(filepath.Join(cacheDir, entry)); got != "current/"+entry {
+			t.Errorf("%s -> %q, want current/%s", entry, got, entry)
+		}
+	}
+	if got := readLinkedAgent(t, cacheDir); got != "v1" {
+		t.Errorf("planner.md = %q, want v1", got)
+	}
+
+	// A link made through the cache follows each switch.
+	link := filepath.Join(t.TempDir(), "planner.md")
+	if err := os.Symlink(filepath.Join(cacheDir, ".claude", "agents", "planner.md"), link); err != nil {
+		t.Fatal(err)
+	}
+
+	useAssets(t, "v2")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(link); string(got) != "v2" {
+		t.Errorf("project link reads %q after the switch, want v2", got)
+	}
+	if got, _ := os.Readlink(filepath.Join(cacheDir, "previous")); got != "v1.0.0" {
+		t.Errorf("previous -> %q, want v1.0.0", got)
+	}
+
+	// The same version with other assets gets a directory of its own.
+	useAssets(t, "v2 with overrides")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	current, _ := os.Readlink(filepath.Join(cacheDir, "current"))
+	if !isDigestName(current, "v1.1.0") {
+		t.Errorf("current -> %q, want v1.1.0 with a digest appended", current)
+	}
+	if got := readLinkedAgent(t, cacheDir); got != "v2 with overrides" {
+		t.Errorf("planner.md = %q, want the overridden agent", got)
+	}
+
+	// Only current and previous are kept.
+	if got := versionDirs(t, cacheDir); len(got) != 2 || got[0] != "v1.1.0" || got[1] != current {
+		t.Errorf("version directories = %v, want [v1.1.0 %s]", got, current)
+	}
+
+	// Extracting assets the cache already holds switches back to them.
+	useAssets(t, "v2")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.Readlink(filepath.Join(cacheDir, "current")); got != "v1.1.0" {
+		t.Errorf("current -> %q, want v1.1.0", got)
+	}
+}
+
+func TestExtractForSymlinkTo_MigratesFlatCache(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "assets")
+	agent := filepath.Join(cacheDir, ".claude", "agents", "planner.md")
+	if err := os.MkdirAll(filepath.Dir(agent), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agent, []byte("flat"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, ".mcp.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	useAssets(t, "v2")
+	if err := ExtractForSymlinkTo(cacheDir, "v2.0.0"); err != nil {
+		t.Fatalf("ExtractForSymlinkTo() error = %v", err)
+	}
+	if got := readLinkedAgent(t, cacheDir); got != "v2" {
+		t.Errorf("planner.md = %q, want v2", got)
+	}
+	if got, _ := os.Readlink(filepath.Join(cacheDir, "previous")); got != "legacy" {
+		t.Errorf("previous -> %q, want legacy", got)
+	}
+
+	found, err := UseAssetVersion(cacheDir, "")
+	if err != nil || !found {
+		t.Fatalf("UseAssetVersion(\"\") = %v, %v", found, err)
+	}
+	if got := readLinkedAgent(t, cacheDir); got != "flat" {
+		t.Errorf("planner.md after switching back = %q, want flat", got)
+	}
+}
+
+func TestExtractForSymlinkTo_Concurrent(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "assets")
+	useAssets(t, "shared")
+
+	versions := []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0"}
+	errs := make([]error, len(versions))
+	var wg sync.WaitGroup
+	for i, v := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = ExtractForSymlinkTo(cacheDir, v)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("ExtractForSymlinkTo(%s) error = %v", versions[i], err)
+		}
+	}
+
+	if got := readLinkedAgent(t, cacheDir); got != "shared" {
+		t.Errorf("planner.md = %q, want shared", got)
+	}
+	if got := versionDirs(t, cacheDir); len(got) != 2 {
+		t.Errorf("version directories = %v, want current and previous only", got)
+	}
+}
+
+func TestUseAssetVersion(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "assets")
+	if found, err := UseAssetVersion(cacheDir, "v1.0.0"); err != nil || found {
+		t.Fatalf("UseAssetVersion() without a cache = %v, %v", found, err)
+	}
+
+	useAssets(t, "v1")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	useAssets(t, "v2")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := UseAssetVersion(cacheDir, "v1.0.0"); err != nil || !found {
+		t.Fatalf("UseAssetVersion(v1.0.0) = %v, %v", found, err)
+	}
+	if got := readLinkedAgent(t, cacheDir); got != "v1" {
+		t.Errorf("planner.md = %q, want v1", got)
+	}
+	if got, _ := os.Readlink(filepath.Join(cacheDir, "previous")); got != "v1.1.0" {
+		t.Errorf("previous -> %q, want v1.1.0", got)
+	}
+	if found, _ := UseAssetVersion(cacheDir, "v1"); found {
+		t.Error("UseAssetVersion(v1) matched v1.0.0")
+	}
+}
+
+func TestAssetVersionName(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.3":      "v1.2.3",
+		"":            "dev",
+		"feature/x":   "feature-x",
+		"../../etc":   "-..-etc",
+		"current":     "version-current",
+		"1.0.0-rc.1":  "1.0.0-rc.1",
+		".hidden-ref": "hidden-ref",
+	}
+	for version, want := range tests {
+		if got := assetVersionName(version); got != want {
+			t.Errorf("assetVersionName(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
@@ -350,15 +350,104 @@ func detectCppMakeProject(dir string, cfg *projectConfig) {
 	cfg.lintCmd = "clang-tidy"
 }
 
-// GenerateClaudeMdScaffold builds the static scaffold content for a project.
-// Returns the markdown string (caller handles file I/O and force logic).
-func GenerateClaudeMdScaffold(projectDir string) string {
-	projectName := filepath.Base(projectDir)
+// detectMonorepo describes a workspace root by the tech stacks of its
+// packages, and builds, tests, and lints through Nx or Turborepo when the
+// repository uses one.
+func detectMonorepo(dir string, cfg *projectConfig) {
+	ws := DetectWorkspace(dir)
+	if ws == nil {
+		return
+	}
+	var stacks []string
+	seen := map[string]bool{"Unknown": true}
+	for _, m := range ws.Members {
+		for _, s := range strings.Split(detectProject(filepath.Join(dir, filepath.FromSlash(m))).techStack, ", ") {
+			if !seen[s] {
+				seen[s] = true
+				stacks = append(stacks, s)
+			}
+		}
+	}
+	stack := cfg.techStack
+	if len(stacks) > 0 {
+		stack = strings.Join(stacks, ", ")
+	}
+	kind := ws.Tool
+	if kind == "" {
+		kind = ws.Kind
+	}
+	cfg.techStack = fmt.Sprintf("%s (%s workspace, %d packages)", stack, kind, len(ws.Members))
+	for _, c := range []struct {
+		target string
+		cmd    *string
+	}{
+		{"build", &cfg.buildCmd},
+		{"test", &cfg.testCmd},
+		{"lint", &cfg.lintCmd},
+	} {
+		if cmd := workspaceCommand(dir, ws, c.target); cmd != "" {
+			*c.cmd = cmd
+		}
+	}
+}
+
+// detectTaskRunner prefers the project's own build, test, and lint targets in
+// a justfile, Makefile, or Taskfile over the language defaults, since they are
+// how the project is actually built and often set flags or run extra steps.
+func detectTaskRunner(dir string, cfg *projectConfig) {
+	tasks := DetectTasks(dir)
+	for _, c := range []struct {
+		name string
+		cmd  *string
+	}{
+		{"build", &cfg.buildCmd},
+		{"test", &cfg.testCmd},
+		{"lint", &cfg.lintCmd},
+	} {
+		if t, ok := FindTask(tasks, c.name); ok && t.Source != "package.json" {
+			*c.cmd = t.Command
+		}
+	}
+}
 
+// detectProject runs every project detector against dir. Workspace and task
+// runner commands are applied last, so they win over the language defaults.
+func detectProject(dir string) projectConfig {
 	cfg := projectConfig{techStack: "Unknown"}
 	for _, detect := range projectDetectors {
-		detect(projectDir, &cfg)
+		detect(dir, &cfg)
 	}
+	detectMonorepo(dir, &cfg)
+	detectTaskRunner(dir, &cfg)
+	return cfg
+}
+
+// DetectTestCommand returns the test command the project detectors find for
+// dir, such as "go test ./...", or "" if none applies.
+func DetectTestCommand(dir string) string {
+	return detectProject(dir).testCmd
+}
+
+// DetectCommands returns the build, test, and lint commands the project
+// detectors find for dir, each "" if none applies.
+func DetectCommands(dir string) (build, test, lint string) {
+	cfg := detectProject(dir)
+	return cfg.buildCmd, cfg.testCmd, cfg.lintCmd
+}
+
+// DetectTechStack returns the Tech Stack the project detectors find for dir,
+// such as "Go" or "TypeScript, React", or "Unknown" if none applies.
+func DetectTechStack(dir string) string {
+	return detectProject(dir).techStack
+}
+
+// GenerateClaudeMdScaffold builds the static scaffold content for a project.
+// A monorepo root also gets a "Key Packages" section listing each workspace
+// member with its own stack and commands. Returns the markdown string (caller
+// handles file I/O and force logic).
+func GenerateClaudeMdScaffold(projectDir string) string {
+	projectName := filepath.Base(projectDir)
+	cfg := detectProject(projectDir)
 
 	var sb strings.Builder
 	sb.WriteString("# Project Instructions\n\n")
@@ -394,6 +483,45 @@ func GenerateClaudeMdScaffold(projectDir string) string {
 <!-- Modes: sequential (default) | solo team (task tracking + hooks) | multi-agent team (parallel agents) -->
 <!-- Tools: TeamCreate, TaskCreate/TaskUpdate/TaskList, Agent (with team_name), SendMessage, TeamDelete -->
 <!-- Configure hooks in settings.json: TaskCompleted (verify between phases), TeammateIdle (nudge stalled agents) -->
+`)
+
+	if ws := DetectWorkspace(projectDir); ws != nil {
+		return SetMarkdownSection(sb.String(), "Key Packages", KeyPackagesSection(projectDir, ws, nil))
+	}
+	return sb.String()
+}
+
+// GeneratePackageScaffold builds the static CLAUDE.md scaffold for a monorepo
+// member at pkgDir. Repository-wide instructions stay in the root CLAUDE.md, so
+// the scaffold only covers what is specific to the package.
+func GeneratePackageScaffold(rootDir, pkgDir string) string {
+	rel, _ := filepath.Rel(rootDir, pkgDir)
+	cfg := detectProject(pkgDir)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Package Instructions: %s\n\n", filepath.ToSlash(rel))
+	fmt.Fprintf(&sb, "Part of the %s monorepo. Repository-wide instructions live in the root .claude/CLAUDE.md.\n\n", filepath.Base(rootDir))
+	sb.WriteString("## Package\n")
+	fmt.Fprintf(&sb, "Name: %s\n", PackageName(pkgDir))
+	fmt.Fprintf(&sb, "Tech Stack: %s\n", cfg.techStack)
+	if cfg.buildCmd != "" {
+		fmt.Fprintf(&sb, "Build: `%s`\n", cfg.buildCmd)
+	}
+	if cfg.testCmd != "" {
+		fmt.Fprintf(&sb, "Test: `%s`\n", cfg.testCmd)
+	}
+	if cfg.lintCmd != "" {
+		fmt.Fprintf(&sb, "Lint: `%s`\n", cfg.lintCmd)
+	}
+	sb.WriteString(`
+## Conventions
+<!-- Conventions that differ from the rest of the repository -->
+
+## Key Directories
+<!-- Map this package's important directories -->
+
+## Important Notes
+<!-- Package-specific notes for Claude -->
 `)
 
 	return sb.String()
@@ -516,33 +644,75 @@ Rules:
 - Output raw markdown only — no wrapping code fences, no commentary`, projectDir, targetPath)
 }
 
+// BuildPackageEnrichmentPrompt constructs the LLM prompt for enriching the
+// CLAUDE.md of a single monorepo member.
+func BuildPackageEnrichmentPrompt(rootDir, pkgDir, targetPath string) string {
+	rel, _ := filepath.Rel(rootDir, pkgDir)
+	return fmt.Sprintf(`You are analyzing one package of a monorepo to generate its CLAUDE.md file with real context.
+
+The package is located at: %s (%s within the repository)
+There is an existing scaffold at: %s
+
+Repository-wide instructions are kept in the root CLAUDE.md, so focus only on this package.
+
+Your task:
+1. Read the existing scaffold at the path above
+2. Explore the package: README, dependency file, directory layout, config files, and source files
+3. Output ONLY raw markdown (no code fences, no explanations, no preamble) following this exact structure:
+
+# Package Instructions: %s
+
+## Package
+Name: <package name>
+Purpose: <one-line description of what this package does>
+Tech Stack: <detected languages/frameworks>
+Build: `+"`<build command>`"+`
+Test: `+"`<test command>`"+`
+
+## Key Directories
+- <dir>/ - <description>
+
+## Conventions
+- <convention specific to this package>
+
+## Important Files
+- <file path> - <why it matters>
+(list 3-8 files)
+
+## Dependencies
+- <other workspace packages this package uses or is used by, if any>
+
+Rules:
+- Only include information you can verify from the package files
+- Do not repeat repository-wide conventions
+- Keep the total output under 80 lines
+- Output raw markdown only — no wrapping code fences, no commentary`, pkgDir, filepath.ToSlash(rel), targetPath, filepath.ToSlash(rel))
+}
+
 // EnrichClaudeMd runs claude opus to analyze the project and enrich the target file.
 // targetPath is the absolute path to the file to enrich (CLAUDE.md or rules/platform.md).
 func EnrichClaudeMd(projectDir, targetPath string) error {
-	if !Exists("claude") {
-		return fmt.Errorf("claude CLI not found. Install with `claude-workspace setup`")
-	}
-
-	prompt := BuildEnrichmentPrompt(projectDir, targetPath)
+	return enrichWithPrompt(projectDir, targetPath, BuildEnrichmentPrompt(projectDir, targetPath))
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
-	defer cancel()
+// EnrichPackageClaudeMd runs claude opus in the monorepo member at pkgDir and
+// rewrites its CLAUDE.md at targetPath.
+func EnrichPackageClaudeMd(rootDir, pkgDir, targetPath string) error {
+	return enrichWithPrompt(pkgDir, targetPath, BuildPackageEnrichmentPrompt(rootDir, pkgDir, targetPath))
+}
 
-	spinner := StartSpinner(os.Stderr, "Analyzing project with claude opus (up to 180s)...")
-	stdout, stderr, err := RunDirWithStdinCapture(ctx, projectDir, prompt, []string{"CLAUDECODE"}, "claude", "-p",
-		"--strict-mcp-config", "--mcp-config", `{"mcpServers":{}}`,
-		"--output-format", "text",
-		"--model", "opus")
-	spinner.Stop()
+func enrichWithPrompt(projectDir, targetPath, prompt string) error {
+	// A target outside the project, such as attach's staged copy, is only
+	// readable by claude when its directory is added to the session.
+	var addDirs []string
+	relPath, err := filepath.Rel(projectDir, targetPath)
+	outside := err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+	if outside {
+		addDirs = append(addDirs, filepath.Dir(targetPath))
+	}
+	stdout, stderr, err := RunClaudeAnalysis(projectDir, prompt, addDirs...)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("enrichment timed out after 180s")
-		}
-		detail := ""
-		if stderr != "" {
-			detail = fmt.Sprintf(": %s", stderr)
-		}
-		return fmt.Errorf("claude exited with error (check API key with `claude-workspace setup`)%s", detail)
+		return err
 	}
 
 	// Find the start of markdown content (skip any preamble lines)
@@ -553,17 +723,50 @@ func EnrichClaudeMd(projectDir, targetPath string) error {
 		}
 		return fmt.Errorf("enrichment produced no markdown output")
 	}
-	content := stdout[idx:]
+	content := MarkGeneratedSections(stdout[idx:])
 
 	if err := os.WriteFile(targetPath, []byte(content+"\n"), 0644); err != nil {
 		return fmt.Errorf("writing enriched file: %w", err)
 	}
 
 	// Show relative path from project dir for cleaner output
-	relPath, _ := filepath.Rel(projectDir, targetPath)
-	if relPath == "" {
+	if relPath == "" || outside {
 		relPath = filepath.Base(targetPath)
 	}
 	PrintSuccess(os.Stdout, fmt.Sprintf("Enriched %s with project context", relPath))
 	return nil
 }
+
+// RunClaudeAnalysis runs claude opus in print mode in projectDir with prompt on
+// stdin, with MCP servers disabled and a 180s timeout, and returns its stdout
+// and stderr. addDirs are directories outside projectDir claude may also read.
+func RunClaudeAnalysis(projectDir, prompt string, addDirs ...string) (stdout, stderr string, err error) {
+	if !Exists("claude") {
+		return "", "", fmt.Errorf("claude CLI not found. Install with `claude-workspace setup`")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
+	defer cancel()
+
+	spinner := StartSpinner(os.Stderr, "Analyzing project with claude opus (up to 180s)...")
+	args := []string{"-p",
+		"--strict-mcp-config", "--mcp-config", `{"mcpServers":{}}`,
+		"--output-format", "text",
+		"--model", "opus"}
+	for _, dir := range addDirs {
+		args = append(args, "--add-dir", dir)
+	}
+	stdout, stderr, err = RunDirWithStdinCapture(ctx, projectDir, prompt, []string{"CLAUDECODE"}, "claude", args...)
+	spinner.Stop()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", "", fmt.Errorf("enrichment timed out after 180s")
+		}
+		detail := ""
+		if stderr != "" {
+			detail = fmt.Sprintf(": %s", stderr)
+		}
+		return "", "", fmt.Errorf("claude exited with error (check API key with `claude-workspace setup`)%s", detail)
+	}
+	return stdout, stderr, nil
+}
@@ -591,6 +591,28 @@ func TestGenerateClaudeMdScaffold_AllLanguages(t *testing.T) {
 			wantTest:  "make test",
 			wantLint:  "clang-tidy",
 		},
+		{
+			name: "Go with Makefile targets",
+			setup: func(dir string) {
+				_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test"), 0644)
+				_ = os.WriteFile(filepath.Join(dir, "Makefile"), []byte(".PHONY: test lint\ntest:\n\tgo test -race ./...\nlint:\n\tgolangci-lint run\n"), 0644)
+			},
+			wantStack: "Go",
+			wantBuild: "go build ./...",
+			wantTest:  "make test",
+			wantLint:  "make lint",
+		},
+		{
+			name: "Node with justfile and Taskfile",
+			setup: func(dir string) {
+				_ = os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"scripts": {"build": "tsc", "test": "vitest"}}`), 0644)
+				_ = os.WriteFile(filepath.Join(dir, "justfile"), []byte("test:\n    pnpm vitest run\n"), 0644)
+				_ = os.WriteFile(filepath.Join(dir, "Taskfile.yml"), []byte("version: '3'\ntasks:\n  build:\n    cmds: [pnpm tsc]\n  test: pnpm test\n"), 0644)
+			},
+			wantStack: "Node.js",
+			wantBuild: "task build",
+			wantTest:  "just test",
+		},
 		{
 			name: "Makefile without C++ sources",
 			setup: func(dir string) {
@@ -0,0 +1,9 @@
+package platform
+
+import "golang.org/x/sys/unix"
+
+// cloneFile creates dst as a copy-on-write clone of src with clonefile(2),
+// which APFS supports.
+func cloneFile(src, dst string) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package platform
+
+import "errors"
+
+// cloneFile is not supported on this platform; LinkFile falls back to a hard
+// link.
+func cloneFile(src, dst string) error {
+	return errors.ErrUnsupported
+}
@@ -87,7 +87,9 @@ func BoldCyan(s string) string { return apply(ansiBold+ansiCyan, s) }
 
 // PrintBanner prints a bold cyan banner line: "\n=== title ===\n"
 func PrintBanner(w io.Writer, title string) {
-	fmt.Fprintf(w, "\n%s\n", BoldCyan("=== "+title+" ==="))
+	report(w, Event{Event: EventBanner, Message: title}, func(w io.Writer) {
+		fmt.Fprintf(w, "\n%s\n", BoldCyan("=== "+title+" ==="))
+	})
 }
 
 // PrintLayerBanner prints a prominent layer header with a full-width horizontal rule
@@ -96,72 +98,102 @@ func PrintBanner(w io.Writer, title string) {
 //	──────────────────────────────────────────────────
 //	▶ title
 func PrintLayerBanner(w io.Writer, title string) {
-	fmt.Fprintf(w, "\n%s\n  %s\n", BoldCyan(strings.Repeat("─", 50)), Bold("▶ "+title))
+	report(w, Event{Event: EventSection, Message: title}, func(w io.Writer) {
+		fmt.Fprintf(w, "\n%s\n  %s\n", BoldCyan(strings.Repeat("─", 50)), Bold("▶ "+title))
+	})
 }
 
 // PrintSection prints a cyan section header: "\n--- title ---\n"
 func PrintSection(w io.Writer, title string) {
-	fmt.Fprintf(w, "\n%s\n", Cyan("--- "+title+" ---"))
+	report(w, Event{Event: EventSection, Message: title}, func(w io.Writer) {
+		fmt.Fprintf(w, "\n%s\n", Cyan("--- "+title+" ---"))
+	})
 }
 
 // PrintSectionLabel prints a bold section label: "\n[label]\n"
 func PrintSectionLabel(w io.Writer, label string) {
-	fmt.Fprintf(w, "\n%s\n", Bold("["+label+"]"))
+	report(w, Event{Event: EventSection, Message: label}, func(w io.Writer) {
+		fmt.Fprintf(w, "\n%s\n", Bold("["+label+"]"))
+	})
 }
 
 // PrintStep prints a bold blue step label: "\n[n/total] label\n"
 func PrintStep(w io.Writer, n, total int, label string) {
-	fmt.Fprintf(w, "\n%s %s\n", BoldBlue(fmt.Sprintf("[%d/%d]", n, total)), label)
+	report(w, Event{Event: EventStep, Message: label, Step: n, Total: total}, func(w io.Writer) {
+		fmt.Fprintf(w, "\n%s %s\n", BoldBlue(fmt.Sprintf("[%d/%d]", n, total)), label)
+	})
 }
 
 // PrintOK prints a bold green OK status: "  [OK] msg\n"
 func PrintOK(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s %s\n", BoldGreen("[OK]"), msg)
+	report(w, Event{Event: EventOK, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s %s\n", BoldGreen("[OK]"), msg)
+	})
 }
 
 // PrintFail prints a bold red FAIL status: "  [FAIL] msg\n"
 func PrintFail(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s %s\n", BoldRed("[FAIL]"), msg)
+	report(w, Event{Event: EventFail, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s %s\n", BoldRed("[FAIL]"), msg)
+	})
 }
 
 // PrintWarn prints a yellow WARN status: "  [WARN] msg\n"
 func PrintWarn(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s %s\n", Yellow("[WARN]"), msg)
+	report(w, Event{Event: EventWarning, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s %s\n", Yellow("[WARN]"), msg)
+	})
 }
 
 // PrintInfo prints a plain INFO status: "  [INFO] msg\n"
 func PrintInfo(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  [INFO] %s\n", msg)
+	report(w, Event{Event: EventInfo, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  [INFO] %s\n", msg)
+	})
 }
 
 // PrintSuccess prints a green message: "  msg\n"
 func PrintSuccess(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s\n", Green(msg))
+	report(w, Event{Event: EventSuccess, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s\n", Green(msg))
+	})
 }
 
 // PrintWarningLine prints a yellow message: "  msg\n"
 func PrintWarningLine(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s\n", Yellow(msg))
+	report(w, Event{Event: EventWarning, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s\n", Yellow(msg))
+	})
 }
 
 // PrintErrorLine prints a red message: "  msg\n"
 func PrintErrorLine(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s\n", Red(msg))
+	report(w, Event{Event: EventError, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s\n", Red(msg))
+	})
 }
 
-// PrintPrompt prints a bold prompt without a trailing newline.
+// PrintPrompt prints a bold prompt without a trailing newline. Outside text
+// mode the prompt goes to stderr so it is still seen.
 func PrintPrompt(w io.Writer, prompt string) {
+	if outputMode != OutputText {
+		w = os.Stderr
+	}
 	fmt.Fprint(w, Bold(prompt))
 }
 
 // PrintCommand prints a command hint: "  $ command" (bold cyan $ + bold command)
 func PrintCommand(w io.Writer, cmd string) {
-	fmt.Fprintf(w, "  %s %s\n", BoldCyan("$"), Bold(cmd))
+	report(w, Event{Event: EventCommand, Message: cmd}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s %s\n", BoldCyan("$"), Bold(cmd))
+	})
 }
 
 // PrintManual prints a manual action hint: "  → description" (yellow arrow + message)
 func PrintManual(w io.Writer, msg string) {
-	fmt.Fprintf(w, "  %s %s\n", Yellow("→"), msg)
+	report(w, Event{Event: EventManual, Message: msg}, func(w io.Writer) {
+		fmt.Fprintf(w, "  %s %s\n", Yellow("→"), msg)
+	})
 }
 
 // --- Spinner ---
@@ -180,6 +212,11 @@ func StartSpinner(w io.Writer, msg string) *Spinner {
 		done: make(chan struct{}),
 	}
 
+	if outputMode != OutputText {
+		report(w, Event{Event: EventProgress, Message: msg}, func(io.Writer) {})
+		close(s.done)
+		return s
+	}
 	if !colorEnabled {
 		fmt.Fprintf(w, "  ... %s\n", msg)
 		close(s.done)
@@ -224,3 +261,123 @@ func (s *Spinner) Stop() {
 	}
 	<-s.done
 }
+
+// --- Progress bar ---
+
+// progressInterval is how often a ProgressBar redraws on a TTY.
+const progressInterval = 100 * time.Millisecond
+
+// ProgressBar shows how much of a download is done. On a TTY it redraws one
+// line with a bar, bytes, percent, rate, and time remaining; elsewhere it
+// prints the size when started and "done." when finished. It is an io.Writer
+// that counts the bytes written to it, for use with io.MultiWriter.
+type ProgressBar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	label    string
+	total    int64 // 0 when the size is unknown
+	done     int64
+	resumed  int64 // bytes already done at start, left out of the rate
+	start    time.Time
+	lastDraw time.Time
+	live     bool
+}
+
+// StartProgress starts a progress bar for label, with done of total bytes
+// already transferred (total is 0 when unknown).
+func StartProgress(w io.Writer, label string, done, total int64) *ProgressBar {
+	p := &ProgressBar{w: w, label: label, total: total, done: done, resumed: done, start: time.Now()}
+	switch {
+	case outputMode != OutputText:
+		report(w, Event{Event: EventProgress, Message: label}, func(io.Writer) {})
+	case colorEnabled:
+		p.live = true
+		p.draw()
+	default:
+		size := fmt.Sprintf("%.1f MB", megabytes(total))
+		if total <= 0 {
+			size = "size unknown"
+		}
+		if done > 0 {
+			size += fmt.Sprintf(", resuming at %.1f MB", megabytes(done))
+		}
+		fmt.Fprintf(w, "  %s [%s] ", label, size)
+	}
+	return p
+}
+
+// Write counts len(b) bytes as transferred.
+func (p *ProgressBar) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += int64(len(b))
+	if p.live && time.Since(p.lastDraw) >= progressInterval {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+// Finish draws the completed bar and ends its line.
+func (p *ProgressBar) Finish() {
+	p.end("done.")
+}
+
+// Stop ends the bar's line after an interrupted transfer, so a message can
+// follow it.
+func (p *ProgressBar) Stop() {
+	p.end("interrupted.")
+}
+
+func (p *ProgressBar) end(word string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case outputMode != OutputText:
+	case p.live:
+		p.draw()
+		fmt.Fprintln(p.w)
+	default:
+		fmt.Fprintln(p.w, word)
+	}
+}
+
+// draw rewrites the bar's line. The caller holds p.mu.
+func (p *ProgressBar) draw() {
+	p.lastDraw = time.Now()
+	line := progressLine(p.label, p.done, p.total, p.done-p.resumed, time.Since(p.start))
+	fmt.Fprintf(p.w, "\r\033[K  %s", line)
+}
+
+// progressLine formats a progress bar for done of total bytes, where
+// transferred bytes took elapsed: "name [=====>    ] 42% 5.0/12.0 MB 1.2 MB/s ETA 6s".
+func progressLine(label string, done, total, transferred int64, elapsed time.Duration) string {
+	var rate float64
+	if secs := elapsed.Seconds(); secs > 0 {
+		rate = float64(transferred) / secs
+	}
+	speed := ""
+	if rate > 0 {
+		speed = fmt.Sprintf(" %.1f MB/s", megabytes(int64(rate)))
+	}
+	if total <= 0 {
+		return fmt.Sprintf("%s %.1f MB%s", label, megabytes(done), speed)
+	}
+
+	const width = 24
+	frac := min(float64(done)/float64(total), 1)
+	filled := int(frac * width)
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	line := fmt.Sprintf("%s [%s] %3.0f%% %.1f/%.1f MB%s", label, Cyan(bar), frac*100, megabytes(done), megabytes(total), speed)
+	if rate > 0 && done < total {
+		eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+		line += " ETA " + eta.Round(time.Second).String()
+	}
+	return line
+}
+
+func megabytes(n int64) float64 {
+	return float64(n) / 1024 / 1024
+}
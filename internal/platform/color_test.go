@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestApplyColorEnabled(t *testing.T) {
@@ -274,3 +275,35 @@ func TestPrintPrompt(t *testing.T) {
 		t.Errorf("PrintPrompt colored = %q, want %q", got, want)
 	}
 }
+
+func TestProgressLine(t *testing.T) {
+	colorEnabled = false
+	const mb = 1024 * 1024
+
+	got := progressLine("app.tar.gz", 6*mb, 12*mb, 4*mb, 2*time.Second)
+	want := "app.tar.gz [============>           ]  50% 6.0/12.0 MB 2.0 MB/s ETA 3s"
+	if got != want {
+		t.Errorf("progressLine = %q, want %q", got, want)
+	}
+	if got := progressLine("app.tar.gz", 12*mb, 12*mb, 12*mb, 4*time.Second); !strings.Contains(got, "[========================] 100%") || strings.Contains(got, "ETA") {
+		t.Errorf("progressLine complete = %q", got)
+	}
+	if got := progressLine("app.tar.gz", 3*mb, 0, 0, 0); got != "app.tar.gz 3.0 MB" {
+		t.Errorf("progressLine unknown size = %q", got)
+	}
+}
+
+func TestProgressBarPlain(t *testing.T) {
+	var buf bytes.Buffer
+	colorEnabled = false
+
+	p := StartProgress(&buf, "app.tar.gz", 0, 2*1024*1024)
+	p.Write(make([]byte, 1024))
+	p.Stop()
+	p = StartProgress(&buf, "app.tar.gz", 1024*1024, 2*1024*1024)
+	p.Finish()
+	want := "  app.tar.gz [2.0 MB] interrupted.\n  app.tar.gz [2.0 MB, resuming at 1.0 MB] done.\n"
+	if buf.String() != want {
+		t.Errorf("plain progress = %q, want %q", buf.String(), want)
+	}
+}
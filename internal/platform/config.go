@@ -0,0 +1,134 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigPath returns ~/.claude-workspace/config.json, where claude-workspace
+// keeps its own settings (upgrade channel, custom CA certificate, cost
+// budgets, and the preferences set with "config set workspace.<key>").
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "config.json"), nil
+}
+
+// ReadConfig decodes the value of key in config.json into v. It reports
+// false, without error, when the file or key does not exist.
+func ReadConfig(key string, v any) (bool, error) {
+	path, err := ConfigPath()
+	if err != nil || !FileExists(path) {
+		return false, err
+	}
+	cfg, err := ReadJSONFileRaw(path)
+	if err != nil {
+		return false, err
+	}
+	raw, ok := cfg[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("parsing %q in %s: %w", key, path, err)
+	}
+	return true, nil
+}
+
+// WriteConfig sets key to v in config.json, keeping the other settings.
+func WriteConfig(key string, v any) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg := map[string]json.RawMessage{}
+	if FileExists(path) {
+		if cfg, err = ReadJSONFileRaw(path); err != nil {
+			return err
+		}
+		if cfg == nil {
+			cfg = map[string]json.RawMessage{}
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	cfg[key] = data
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	return WriteJSONFile(path, cfg)
+}
+
+// Keys in config.json for the preferences edited with
+// "config set workspace.<key>".
+const (
+	ConfigTemplateSource = "templateSource" // default attach/detach --template
+	ConfigAttachFlags    = "attachFlags"    // flags added to every attach
+	ConfigColor          = "color"          // auto, always, or never
+	ConfigProxy          = "proxy"          // HTTPS_PROXY/HTTP_PROXY when unset
+	ConfigNoProxy        = "noProxy"        // NO_PROXY when unset
+	ConfigCACert         = "caCert"         // extra root CAs, see ConfigureHTTP
+
+	ConfigMemoryTokenBudget = "memoryTokenBudget" // "memory tokens" budget
+)
+
+// ConfigString returns the string value of key in config.json, or "" when it
+// is unset or the file cannot be read.
+func ConfigString(key string) string {
+	var s string
+	if ok, err := ReadConfig(key, &s); !ok || err != nil {
+		return ""
+	}
+	return s
+}
+
+// DeleteConfig removes key from config.json, keeping the other settings.
+func DeleteConfig(key string) error {
+	path, err := ConfigPath()
+	if err != nil || !FileExists(path) {
+		return err
+	}
+	cfg, err := ReadJSONFileRaw(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg[key]; !ok {
+		return nil
+	}
+	delete(cfg, key)
+	return WriteJSONFile(path, cfg)
+}
+
+// ApplyConfig applies the color and proxy preferences saved in config.json.
+// Environment variables win: NO_COLOR over color, and the proxy variables
+// over proxy and noProxy. Call it after InitColor and before ConfigureHTTP.
+func ApplyConfig() {
+	switch ConfigString(ConfigColor) {
+	case "never":
+		colorEnabled = false
+	case "always":
+		colorEnabled = os.Getenv("NO_COLOR") == ""
+	}
+	if proxy := ConfigString(ConfigProxy); proxy != "" {
+		setenvDefault("HTTPS_PROXY", proxy)
+		setenvDefault("HTTP_PROXY", proxy)
+	}
+	if noProxy := ConfigString(ConfigNoProxy); noProxy != "" {
+		setenvDefault("NO_PROXY", noProxy)
+	}
+}
+
+// setenvDefault sets name to value unless name is already set in upper or
+// lower case, as proxy variables may be.
+func setenvDefault(name, value string) {
+	if os.Getenv(name) == "" && os.Getenv(strings.ToLower(name)) == "" {
+		os.Setenv(name, value)
+	}
+}
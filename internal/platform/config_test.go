@@ -0,0 +1,92 @@
+package platform
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var v string
+	if ok, err := ReadConfig("caCert", &v); ok || err != nil {
+		t.Fatalf("ReadConfig() without a config file = %v, %v", ok, err)
+	}
+
+	if err := WriteConfig("caCert", "/etc/ca.pem"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConfig("upgradeChannel", "beta"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ReadConfig("caCert", &v); !ok || err != nil || v != "/etc/ca.pem" {
+		t.Errorf("ReadConfig(caCert) = %q, %v, %v", v, ok, err)
+	}
+
+	path, _ := ConfigPath()
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"upgradeChannel": "beta"`) {
+		t.Errorf("config.json lost a key:\n%s", data)
+	}
+
+	var n int
+	if _, err := ReadConfig("caCert", &n); err == nil {
+		t.Error("ReadConfig() into the wrong type should fail")
+	}
+}
+
+func TestDeleteConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := DeleteConfig(ConfigColor); err != nil {
+		t.Fatalf("DeleteConfig() without a config file: %v", err)
+	}
+	if err := WriteConfig(ConfigColor, "never"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConfig(ConfigProxy, "http://proxy:8080"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteConfig(ConfigColor); err != nil {
+		t.Fatal(err)
+	}
+	if got := ConfigString(ConfigColor); got != "" {
+		t.Errorf("color = %q after DeleteConfig", got)
+	}
+	if got := ConfigString(ConfigProxy); got != "http://proxy:8080" {
+		t.Errorf("DeleteConfig removed another key: proxy = %q", got)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy", "NO_COLOR"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("http_proxy", "http://from-env:3128")
+	t.Cleanup(func() { colorEnabled = false })
+
+	_ = WriteConfig(ConfigColor, "always")
+	_ = WriteConfig(ConfigProxy, "http://proxy:8080")
+	_ = WriteConfig(ConfigNoProxy, "localhost,.internal")
+	ApplyConfig()
+
+	if !colorEnabled {
+		t.Error(`color "always" should enable color`)
+	}
+	if got := os.Getenv("HTTPS_PROXY"); got != "http://proxy:8080" {
+		t.Errorf("HTTPS_PROXY = %q, want the configured proxy", got)
+	}
+	if got := os.Getenv("HTTP_PROXY"); got != "" {
+		t.Errorf("HTTP_PROXY = %q; http_proxy from the environment should win", got)
+	}
+	if got := os.Getenv("NO_PROXY"); got != "localhost,.internal" {
+		t.Errorf("NO_PROXY = %q", got)
+	}
+
+	_ = WriteConfig(ConfigColor, "never")
+	ApplyConfig()
+	if colorEnabled {
+		t.Error(`color "never" should disable color`)
+	}
+}
@@ -0,0 +1,89 @@
+package platform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineOp is one line of a line-level diff.
+type LineOp struct {
+	Kind byte // ' ' in both, '-' only in the old text, '+' only in the new text
+	Line string
+}
+
+// SplitLines splits s into lines, ignoring a trailing newline.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// DiffLines returns a minimal line diff turning old into new, computed from the
+// longest common subsequence. It is meant for small files such as memory and
+// config files; the table is quadratic in their length.
+func DiffLines(old, new []string) []LineOp {
+	n, m := len(old), len(new)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]LineOp, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case old[i] == new[j]:
+			ops = append(ops, LineOp{' ', old[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, LineOp{'-', old[i]})
+			i++
+		default:
+			ops = append(ops, LineOp{'+', new[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, LineOp{'-', old[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, LineOp{'+', new[j]})
+	}
+	return ops
+}
+
+// WriteLineDiff prints the changed lines between before and after, with a
+// header giving the line number in after where each run of changes starts.
+func WriteLineDiff(w io.Writer, before, after string) {
+	line := 1
+	inHunk := false
+	for _, op := range DiffLines(SplitLines(before), SplitLines(after)) {
+		if op.Kind == ' ' {
+			line++
+			inHunk = false
+			continue
+		}
+		if !inHunk {
+			fmt.Fprintf(w, "  %s\n", Cyan(fmt.Sprintf("@@ line %d @@", line)))
+			inHunk = true
+		}
+		if op.Kind == '-' {
+			fmt.Fprintf(w, "  %s\n", Red("- "+op.Line))
+		} else {
+			fmt.Fprintf(w, "  %s\n", Green("+ "+op.Line))
+			line++
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	ops := DiffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	var got []string
+	for _, op := range ops {
+		got = append(got, string(op.Kind)+op.Line)
+	}
+	want := "' a' '-b' '+x' ' c' '+d'"
+	if s := "'" + strings.Join(got, "' '") + "'"; s != want {
+		t.Errorf("DiffLines = %s, want %s", s, want)
+	}
+}
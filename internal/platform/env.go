@@ -1,11 +1,22 @@
 package platform
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
+// envVarName matches a portable environment variable name.
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsEnvVarName reports whether name is a portable environment variable name:
+// letters, digits, and underscores, not starting with a digit.
+func IsEnvVarName(name string) bool {
+	return envVarName.MatchString(name)
+}
+
 // DetectShellRC determines the user's shell RC file path and shell name.
 // It checks $SHELL first, then falls back to file-existence checks.
 func DetectShellRC(home string) (rcPath, shellName string) {
@@ -29,10 +40,31 @@ func DetectShellRC(home string) (rcPath, shellName string) {
 	return filepath.Join(home, ".bashrc"), "bash"
 }
 
+// RCMarker starts the comment line written above each line claude-workspace
+// adds to a shell RC file, so RemoveRCAdditions can find them again.
+const RCMarker = "# Added by claude-workspace"
+
+// ErrRCEditsDisabled is returned by AppendPathToRC after SetRCEdits(false).
+var ErrRCEditsDisabled = errors.New("shell RC edits are disabled")
+
+// rcEditsDisabled is set by SetRCEdits(false), for fleet provisioning where
+// shell startup files are managed centrally.
+var rcEditsDisabled bool
+
+// SetRCEdits enables or disables changes to shell RC files by AppendPathToRC.
+func SetRCEdits(enabled bool) {
+	rcEditsDisabled = !enabled
+}
+
 // AppendPathToRC adds ~/.local/bin to PATH in the given shell RC file.
 // For fish, it uses fish_add_path. For bash/zsh, it appends an export line.
-// Returns (true, nil) if the file was modified, (false, nil) if already configured.
+// Returns (true, nil) if the file was modified, (false, nil) if already configured,
+// and ErrRCEditsDisabled when RC edits are turned off.
 func AppendPathToRC(home, shellName, rcPath string) (modified bool, err error) {
+	if rcEditsDisabled {
+		return false, ErrRCEditsDisabled
+	}
+
 	// Fish uses a different mechanism
 	if shellName == "fish" {
 		fishPath := filepath.Join(home, ".local", "bin")
@@ -43,7 +75,7 @@ func AppendPathToRC(home, shellName, rcPath string) (modified bool, err error) {
 	}
 
 	// bash/zsh: check idempotency
-	pathLine := "\n# Added by claude-workspace setup\nexport PATH=\"$HOME/.local/bin:$PATH\"\n"
+	pathLine := "\n" + RCMarker + " setup\nexport PATH=\"$HOME/.local/bin:$PATH\"\n"
 
 	content, err := os.ReadFile(rcPath)
 	if err != nil && !os.IsNotExist(err) {
@@ -65,6 +97,71 @@ func AppendPathToRC(home, shellName, rcPath string) (modified bool, err error) {
 	return true, nil
 }
 
+// AppendLineToRC appends line to the shell RC file at rcPath unless the file
+// already contains it. It returns (true, nil) if the file was modified and
+// ErrRCEditsDisabled when RC edits are turned off.
+func AppendLineToRC(rcPath, line string) (modified bool, err error) {
+	if rcEditsDisabled {
+		return false, ErrRCEditsDisabled
+	}
+
+	content, err := os.ReadFile(rcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if strings.Contains(string(content), line) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rcPath), 0755); err != nil {
+		return false, err
+	}
+	addition := "\n" + RCMarker + "\n" + line + "\n"
+	if err := os.WriteFile(rcPath, append(content, []byte(addition)...), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveRCAdditions removes the lines AppendPathToRC and AppendLineToRC added
+// to the shell RC file at rcPath, with their marker comments and the blank
+// lines before them. Lines for which keep returns true stay, marker and all.
+// It returns the removed lines; a missing file has none.
+func RemoveRCAdditions(rcPath string, keep func(line string) bool) ([]string, error) {
+	content, err := os.ReadFile(rcPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var out, removed []string
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], RCMarker) || i+1 >= len(lines) || keep(lines[i+1]) {
+			out = append(out, lines[i])
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1] == "" {
+			out = out[:n-1]
+		}
+		removed = append(removed, lines[i+1])
+		i++
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	info, err := os.Stat(rcPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(rcPath, []byte(strings.Join(out, "\n")), info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
 // AsdfDataDir returns the asdf data directory, checking $ASDF_DATA_DIR first
 // and falling back to ~/.asdf.
 func AsdfDataDir() string {
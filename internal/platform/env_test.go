@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -154,6 +155,82 @@ func TestAppendPathToRC_CreatesFile(t *testing.T) {
 	}
 }
 
+func TestAppendPathToRC_Disabled(t *testing.T) {
+	SetRCEdits(false)
+	t.Cleanup(func() { SetRCEdits(true) })
+
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".bashrc")
+	modified, err := AppendPathToRC(home, shellBash, rcPath)
+	if !errors.Is(err, ErrRCEditsDisabled) || modified {
+		t.Fatalf("AppendPathToRC() = %v, %v; want false, ErrRCEditsDisabled", modified, err)
+	}
+	if FileExists(rcPath) {
+		t.Error("RC file should not be created when edits are disabled")
+	}
+}
+
+func TestAppendLineToRC(t *testing.T) {
+	rcPath := filepath.Join(t.TempDir(), ".zshrc")
+	_ = os.WriteFile(rcPath, []byte("alias ll='ls -l'\n"), 0644)
+	line := `eval "$(claude-workspace completion zsh)"`
+
+	for i, want := range []bool{true, false} {
+		modified, err := AppendLineToRC(rcPath, line)
+		if err != nil || modified != want {
+			t.Fatalf("call %d: AppendLineToRC() = %v, %v; want %v, nil", i+1, modified, err, want)
+		}
+	}
+	content, _ := os.ReadFile(rcPath)
+	if !strings.HasPrefix(string(content), "alias ll") || strings.Count(string(content), line) != 1 {
+		t.Errorf("RC file after two calls:\n%s", content)
+	}
+
+	SetRCEdits(false)
+	t.Cleanup(func() { SetRCEdits(true) })
+	if _, err := AppendLineToRC(filepath.Join(t.TempDir(), ".bashrc"), line); !errors.Is(err, ErrRCEditsDisabled) {
+		t.Errorf("AppendLineToRC() with edits disabled = %v, want ErrRCEditsDisabled", err)
+	}
+}
+
+func TestRemoveRCAdditions(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	_ = os.WriteFile(rcPath, []byte("alias ll='ls -l'\n"), 0600)
+	line := `eval "$(claude-workspace completion zsh)"`
+	if _, err := AppendPathToRC(home, "zsh", rcPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AppendLineToRC(rcPath, line); err != nil {
+		t.Fatal(err)
+	}
+
+	keepPath := func(l string) bool { return strings.Contains(l, ".local/bin") }
+	removed, err := RemoveRCAdditions(rcPath, keepPath)
+	if err != nil || len(removed) != 1 || removed[0] != line {
+		t.Fatalf("RemoveRCAdditions(keep PATH) = %q, %v", removed, err)
+	}
+	content, _ := os.ReadFile(rcPath)
+	if want := "alias ll='ls -l'\n\n# Added by claude-workspace setup\nexport PATH=\"$HOME/.local/bin:$PATH\"\n"; string(content) != want {
+		t.Errorf("RC file = %q, want %q", content, want)
+	}
+
+	if _, err := RemoveRCAdditions(rcPath, func(string) bool { return false }); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(rcPath)
+	if string(content) != "alias ll='ls -l'\n" {
+		t.Errorf("RC file = %q, want only the alias", content)
+	}
+	if info, _ := os.Stat(rcPath); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if removed, err := RemoveRCAdditions(filepath.Join(home, "missing"), keepPath); err != nil || removed != nil {
+		t.Errorf("RemoveRCAdditions(missing) = %q, %v", removed, err)
+	}
+}
+
 func TestAsdfDataDir_FromEnv(t *testing.T) {
 	t.Setenv("ASDF_DATA_DIR", "/custom/asdf")
 	dir := AsdfDataDir()
@@ -171,3 +248,11 @@ func TestAsdfDataDir_Default(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, dir)
 	}
 }
+
+func TestIsEnvVarName(t *testing.T) {
+	for name, want := range map[string]bool{"GH_TOKEN": true, "_x1": true, "path": true, "": false, "1A": false, "A-B": false, "A B": false} {
+		if got := IsEnvVarName(name); got != want {
+			t.Errorf("IsEnvVarName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
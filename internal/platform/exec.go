@@ -3,19 +3,24 @@ package platform
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
+	"time"
 )
 
-// Run executes a command with stdin/stdout/stderr inherited.
+// Run executes a command with stdin/stdout/stderr inherited. Like every
+// helper here that inherits I/O, it keeps stdout clean in --json and --quiet
+// modes (see childStdout).
 func Run(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = childStdout()
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return run(cmd)
 }
 
 // RunDir executes a command in a specific directory with inherited I/O.
@@ -23,9 +28,9 @@ func RunDir(dir, name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = childStdout()
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return run(cmd)
 }
 
 // RunQuiet executes a command and discards stdout/stderr.
@@ -33,7 +38,7 @@ func RunQuiet(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
-	return cmd.Run()
+	return run(cmd)
 }
 
 // RunQuietWithEnv executes a command with extra environment variables, discarding output.
@@ -42,7 +47,7 @@ func RunQuietWithEnv(extraEnv []string, name string, args ...string) error {
 	cmd.Env = append(os.Environ(), extraEnv...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
-	return cmd.Run()
+	return run(cmd)
 }
 
 // RunQuietDir executes a command in a specific directory, discarding output.
@@ -51,7 +56,7 @@ func RunQuietDir(dir, name string, args ...string) error {
 	cmd.Dir = dir
 	cmd.Stdout = nil
 	cmd.Stderr = nil
-	return cmd.Run()
+	return run(cmd)
 }
 
 // Output executes a command and returns its stdout as a trimmed string.
@@ -60,7 +65,7 @@ func Output(name string, args ...string) (string, error) {
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
-	err := cmd.Run()
+	err := run(cmd)
 	return strings.TrimSpace(out.String()), err
 }
 
@@ -70,7 +75,7 @@ func OutputContext(ctx context.Context, name string, args ...string) (string, er
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
-	err := cmd.Run()
+	err := run(cmd)
 	return strings.TrimSpace(out.String()), err
 }
 
@@ -81,7 +86,7 @@ func OutputDir(dir, name string, args ...string) (string, error) {
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
-	err := cmd.Run()
+	err := run(cmd)
 	return strings.TrimSpace(out.String()), err
 }
 
@@ -91,6 +96,25 @@ func Exists(name string) bool {
 	return err == nil
 }
 
+// OpenBrowser opens url in the default browser. It fails without trying when
+// no local browser can be shown: over SSH, or on Linux without a display.
+func OpenBrowser(url string) error {
+	if os.Getenv("SSH_CONNECTION") != "" {
+		return errors.New("running over SSH")
+	}
+	name := "open"
+	if runtime.GOOS != "darwin" {
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return errors.New("no display")
+		}
+		name = "xdg-open"
+	}
+	if !Exists(name) {
+		return fmt.Errorf("%s not found", name)
+	}
+	return exec.Command(name, url).Start()
+}
+
 // RunDirWithStdin executes a command in a specific directory with stdin from a string.
 // Returns trimmed stdout. Stderr is discarded.
 func RunDirWithStdin(ctx context.Context, dir string, stdin string, name string, args ...string) (string, error) {
@@ -100,7 +124,7 @@ func RunDirWithStdin(ctx context.Context, dir string, stdin string, name string,
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil
-	err := cmd.Run()
+	err := run(cmd)
 	return strings.TrimSpace(out.String()), err
 }
 
@@ -128,7 +152,7 @@ func RunDirWithStdinCapture(ctx context.Context, dir string, stdin string, unset
 	var outBuf, errBuf bytes.Buffer
 	cmd.Stdout = &outBuf
 	cmd.Stderr = &errBuf
-	err = cmd.Run()
+	err = run(cmd)
 	return strings.TrimSpace(outBuf.String()), strings.TrimSpace(errBuf.String()), err
 }
 
@@ -146,10 +170,12 @@ func RunWithSpinner(msg string, name string, args ...string) error {
 	cmd.Stdout = pw
 
 	spinner := StartSpinner(os.Stderr, msg)
+	start := time.Now()
 	if err := cmd.Start(); err != nil {
 		spinner.Stop()
 		pr.Close()
 		pw.Close()
+		logCommand(cmd, start, err, "")
 		return err
 	}
 	pw.Close()
@@ -163,7 +189,7 @@ func RunWithSpinner(msg string, name string, args ...string) error {
 				spinner.Stop()
 				spinnerStopped = true
 			}
-			os.Stdout.Write(buf[:n])
+			_, _ = childStdout().Write(buf[:n])
 		}
 		if readErr != nil {
 			break
@@ -173,16 +199,18 @@ func RunWithSpinner(msg string, name string, args ...string) error {
 		spinner.Stop()
 	}
 	pr.Close()
-	return cmd.Wait()
+	err = cmd.Wait()
+	logCommand(cmd, start, err, "")
+	return err
 }
 
 // RunSpawn runs a command with full I/O passthrough and returns the exit code.
 func RunSpawn(name string, args ...string) (int, error) {
 	cmd := exec.Command(name, args...)
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = childStdout()
 	cmd.Stderr = os.Stderr
-	err := cmd.Run()
+	err := run(cmd)
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return exitErr.ExitCode(), nil
@@ -191,3 +219,24 @@ func RunSpawn(name string, args ...string) (int, error) {
 	}
 	return 0, nil
 }
+
+// run runs cmd and logs the invocation. Stderr that would otherwise be
+// discarded is captured so a failure's output reaches the log.
+func run(cmd *exec.Cmd) error {
+	var stderr *bytes.Buffer
+	switch w := cmd.Stderr.(type) {
+	case nil:
+		stderr = &bytes.Buffer{}
+		cmd.Stderr = stderr
+	case *bytes.Buffer:
+		stderr = w
+	}
+	start := time.Now()
+	err := cmd.Run()
+	var captured string
+	if stderr != nil {
+		captured = stderr.String()
+	}
+	logCommand(cmd, start, err, captured)
+	return err
+}
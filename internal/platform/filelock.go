@@ -0,0 +1,65 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// errLocked is returned by tryLock when another process holds the lock.
+var errLocked = errors.New("locked by another process")
+
+// lockTimeout is how long LockFile waits for another process to release a
+// lock before giving up.
+var lockTimeout = 10 * time.Second
+
+// LockFile takes an exclusive advisory lock for path and returns a function
+// that releases it. The lock is held on a path+".lock" file beside path rather
+// than on path itself, so it survives path being replaced by WriteFileAtomic;
+// the lock file is left in place. Only processes that also call LockFile are
+// kept out: it guards read-modify-write cycles of ~/.claude.json and
+// settings.json between concurrent claude-workspace commands.
+func LockFile(path string) (unlock func(), err error) {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock %s: %w", lockPath, err)
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err = tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) {
+			f.Close()
+			return nil, fmt.Errorf("locking %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for another claude-workspace command to finish with %s", lockTimeout, path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return func() {
+		releaseLock(f)
+		f.Close()
+	}, nil
+}
+
+// WithFileLock runs fn while holding the LockFile lock for path. Wrap the whole
+// read-modify-write of a shared config file in it, so a concurrent command
+// cannot write between the read and the write.
+func WithFileLock(path string, fn func() error) error {
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
@@ -0,0 +1,62 @@
+package platform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "config", ".claude.json")
+
+	unlock, err := LockFile(path)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	if _, err := LockFile(path); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("second LockFile() error = %v, want a timeout", err)
+	}
+	unlock()
+	unlock, err = LockFile(path)
+	if err != nil {
+		t.Fatalf("LockFile() after unlock error = %v", err)
+	}
+	unlock()
+}
+
+func TestWithFileLock_SerializesUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".claude.json")
+	WriteJSONFile(path, map[string]int{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithFileLock(path, func() error {
+				var counts map[string]int
+				if err := ReadJSONFile(path, &counts); err != nil {
+					return err
+				}
+				counts["n"]++
+				return WriteJSONFile(path, counts)
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	var counts map[string]int
+	if err := json.Unmarshal(data, &counts); err != nil || counts["n"] != 20 {
+		t.Errorf("after 20 locked updates: %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,12 @@
+package platform
+
+import "testing"
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -1234: "-1,234", -123: "-123"}
+	for n, want := range tests {
+		if got := FormatCount(n); got != want {
+			t.Errorf("FormatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -40,6 +40,35 @@ func CopyFile(src, dst string) error {
 	return err
 }
 
+// WriteFileAtomic writes data to path through a temporary file in the same
+// directory, so readers see either the old contents or the new, never a
+// partial write. When path is a symlink, the file it points to is replaced.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 // WalkFiles walks a directory and calls fn for each regular file,
 // passing the relative path from root.
 func WalkFiles(root string, fn func(relPath string) error) error {
@@ -97,6 +126,17 @@ func EnsureGitignoreEntries(gitignorePath string, requiredEntries string) (modif
 	return true, nil
 }
 
+// MissingGitignoreEntries returns the non-empty, non-comment lines of
+// requiredEntries that are not present in the .gitignore at gitignorePath.
+// A missing file is treated as empty.
+func MissingGitignoreEntries(gitignorePath string, requiredEntries string) ([]string, error) {
+	existing, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return findMissingEntries(parseGitignoreEntries(string(existing)), requiredEntries), nil
+}
+
 // parseGitignoreEntries parses gitignore content into a set of non-empty, non-comment lines.
 func parseGitignoreEntries(content string) map[string]bool {
 	have := make(map[string]bool)
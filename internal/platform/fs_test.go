@@ -105,6 +105,26 @@ func TestCopyFile_OverwritesExisting(t *testing.T) {
 	}
 }
 
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	_ = os.WriteFile(path, []byte("old"), 0644)
+
+	if err := WriteFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "new" {
+		t.Errorf("contents = %q, want %q", got, "new")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
 func TestWalkFiles(t *testing.T) {
 	dir := t.TempDir()
 	_ = os.MkdirAll(filepath.Join(dir, "sub", "deep"), 0755)
@@ -389,6 +409,29 @@ func TestEnsureGitignoreEntries_PreservesUserEntries(t *testing.T) {
 	}
 }
 
+func TestMissingGitignoreEntries(t *testing.T) {
+	dir := t.TempDir()
+	gitignorePath := filepath.Join(dir, ".gitignore")
+	required := "*.log\n# comment\nnode_modules/\n"
+
+	missing, err := MissingGitignoreEntries(gitignorePath, required)
+	if err != nil {
+		t.Fatalf("MissingGitignoreEntries() error = %v", err)
+	}
+	if len(missing) != 2 {
+		t.Errorf("missing file: got %v, want both entries", missing)
+	}
+
+	_ = os.WriteFile(gitignorePath, []byte("*.log\n"), 0644)
+	missing, err = MissingGitignoreEntries(gitignorePath, required)
+	if err != nil {
+		t.Fatalf("MissingGitignoreEntries() error = %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "node_modules/" {
+		t.Errorf("got %v, want [node_modules/]", missing)
+	}
+}
+
 func TestHasDenyAllPattern(t *testing.T) {
 	dir := t.TempDir()
 
@@ -0,0 +1,203 @@
+package platform
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// The ways LinkFile can share a file, best first. Hard links are not used:
+// a project file that shares its inode with the cache would let an edit made
+// in place change the cached assets, and every project linked to them.
+const (
+	LinkReflink = "reflink"
+	LinkCopy    = "copy"
+)
+
+// LinkedAssetDirs are the project directories whose files attach --hardlink
+// links to an asset cache, as attach --symlink symlinks them.
+var LinkedAssetDirs = []string{".claude/agents", ".claude/skills", ".claude/hooks"}
+
+// linkedProjectsFile lists, in an asset cache, the projects attached with
+// --hardlink. Their files are linked again whenever the cache switches to
+// other assets, which is what keeps them updated centrally.
+const linkedProjectsFile = "linked-projects.json"
+
+// LinkFile atomically replaces dst with a copy-on-write clone of src (a
+// reflink: clonefile on macOS, FICLONE on Linux), or where the filesystem
+// cannot clone, for example across filesystems, a copy. Either way dst is a
+// file of its own, which the project can edit without touching src. It
+// returns the method used.
+func LinkFile(src, dst string) (string, error) {
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-link")
+	_ = os.Remove(tmp)
+	method := LinkReflink
+	if err := cloneFile(src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		method = LinkCopy
+		if err := CopyFile(src, tmp); err != nil {
+			_ = os.Remove(tmp)
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return method, nil
+}
+
+// SymlinksSupported reports whether symlinks can be created in dir, which
+// some filesystems and locked-down machines refuse.
+func SymlinksSupported(dir string) bool {
+	probe := filepath.Join(dir, fmt.Sprintf(".claude-workspace-symlink-%d", os.Getpid()))
+	_ = os.Remove(probe)
+	if err := os.Symlink(".", probe); err != nil {
+		return false
+	}
+	_ = os.Remove(probe)
+	return true
+}
+
+// LinkProjectAssets links each file under LinkedAssetDirs of projectDir that
+// has the content of the same file in the current assets of cacheDir to it,
+// and records the project in the cache, so that the files are linked to the
+// new assets whenever the cache switches. It returns how many files each
+// method linked.
+func LinkProjectAssets(cacheDir, projectDir string) (map[string]int, error) {
+	var counts map[string]int
+	err := WithFileLock(cacheDir, func() error {
+		projects := readLinkedProjects(cacheDir)
+		if !slices.Contains(projects, projectDir) {
+			projects = append(projects, projectDir)
+			if err := writeLinkedProjects(cacheDir, projects); err != nil {
+				return fmt.Errorf("recording %s: %w", projectDir, err)
+			}
+		}
+		var err error
+		counts, err = linkProject(cacheDir, projectDir, "", currentAssets)
+		return err
+	})
+	return counts, err
+}
+
+// UnregisterLinkedProject stops updating projectDir when cacheDir switches
+// assets, as after detach. It does nothing if the project is not recorded.
+func UnregisterLinkedProject(cacheDir, projectDir string) error {
+	if !FileExists(filepath.Join(cacheDir, linkedProjectsFile)) {
+		return nil
+	}
+	return WithFileLock(cacheDir, func() error {
+		projects := readLinkedProjects(cacheDir)
+		kept := projects[:0]
+		for _, p := range projects {
+			if p != projectDir {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == len(projects) {
+			return nil
+		}
+		return writeLinkedProjects(cacheDir, kept)
+	})
+}
+
+// relinkProjects links the files of every recorded project that still match
+// the version directory from to the same files in to, after the cache has
+// switched from one to the other. The caller holds the cache's lock. Projects
+// that are gone are dropped; other failures are left for the next switch.
+func relinkProjects(cacheDir, from, to string) {
+	projects := readLinkedProjects(cacheDir)
+	if len(projects) == 0 {
+		return
+	}
+	var kept []string
+	for _, p := range projects {
+		if !FileExists(filepath.Join(p, ".claude")) {
+			continue
+		}
+		kept = append(kept, p)
+		_, _ = linkProject(cacheDir, p, from, to)
+	}
+	if len(kept) != len(projects) {
+		_ = writeLinkedProjects(cacheDir, kept)
+	}
+}
+
+// linkProject links each file under LinkedAssetDirs of projectDir that is
+// unchanged from the version directory from ("" for none) or already has
+// the content of the version directory to, to the file in to. Files the
+// project has changed, or that to does not have, are left alone.
+func linkProject(cacheDir, projectDir, from, to string) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, dir := range LinkedAssetDirs {
+		root := filepath.Join(projectDir, filepath.FromSlash(dir))
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, _ := filepath.Rel(projectDir, path)
+			target := filepath.Join(cacheDir, to, rel)
+			if !FileExists(target) {
+				return nil
+			}
+			// Project files never share an inode with the cache, so their
+			// content alone tells whether the project changed them.
+			if !sameFileContent(path, target) && (from == "" || !sameFileContent(path, filepath.Join(cacheDir, from, rel))) {
+				return nil
+			}
+			method, err := LinkFile(target, path)
+			if err != nil {
+				return fmt.Errorf("linking %s: %w", rel, err)
+			}
+			counts[method]++
+			return nil
+		})
+		if err != nil {
+			return counts, err
+		}
+	}
+	return counts, nil
+}
+
+// sameFileContent reports whether the files a and b both exist and have the
+// same content.
+func sameFileContent(a, b string) bool {
+	da, err := os.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	db, err := os.ReadFile(b)
+	return err == nil && bytes.Equal(da, db)
+}
+
+// readLinkedProjects returns the projects recorded in cacheDir, or none if
+// the list is missing or unreadable.
+func readLinkedProjects(cacheDir string) []string {
+	var list struct {
+		Projects []string `json:"projects"`
+	}
+	if err := ReadJSONFile(filepath.Join(cacheDir, linkedProjectsFile), &list); err != nil {
+		return nil
+	}
+	return list.Projects
+}
+
+// writeLinkedProjects records projects, sorted, in cacheDir.
+func writeLinkedProjects(cacheDir string, projects []string) error {
+	sort.Strings(projects)
+	list := struct {
+		Projects []string `json:"projects"`
+	}{projects}
+	return WriteJSONFile(filepath.Join(cacheDir, linkedProjectsFile), list)
+}
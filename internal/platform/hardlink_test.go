@@ -0,0 +1,133 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLinkFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.md")
+	dst := filepath.Join(dir, "dst.md")
+	writeTestFile(t, src, "asset")
+	writeTestFile(t, dst, "old")
+
+	method, err := LinkFile(src, dst)
+	if err != nil {
+		t.Fatalf("LinkFile() error = %v", err)
+	}
+	if !slices.Contains([]string{LinkReflink, LinkCopy}, method) {
+		t.Errorf("LinkFile() method = %q", method)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "asset" {
+		t.Errorf("dst = %q, want asset", data)
+	}
+	si, _ := os.Stat(src)
+	di, _ := os.Stat(dst)
+	if os.SameFile(si, di) {
+		t.Error("LinkFile() made dst share src's inode")
+	}
+	if FileExists(filepath.Join(dir, ".dst.md.tmp-link")) {
+		t.Error("LinkFile() left its temporary file")
+	}
+}
+
+func TestSymlinksSupported(t *testing.T) {
+	dir := t.TempDir()
+	if !SymlinksSupported(dir) {
+		t.Skip("symlinks are not supported here")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("SymlinksSupported() left %v", entries)
+	}
+}
+
+func TestLinkProjectAssets_FollowsSwitch(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "assets")
+	projectDir := t.TempDir()
+	agent := filepath.Join(projectDir, ".claude", "agents", "planner.md")
+	custom := filepath.Join(projectDir, ".claude", "agents", "custom.md")
+
+	useAssets(t, "v1")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, agent, "v1")
+	writeTestFile(t, custom, "mine")
+	counts, err := LinkProjectAssets(cacheDir, projectDir)
+	if err != nil {
+		t.Fatalf("LinkProjectAssets() error = %v", err)
+	}
+	if total := counts[LinkReflink] + counts[LinkCopy]; total != 1 {
+		t.Errorf("LinkProjectAssets() counts = %v, want one file", counts)
+	}
+	if got := readLinkedProjects(cacheDir); !slices.Equal(got, []string{projectDir}) {
+		t.Errorf("linked projects = %q, want %q", got, projectDir)
+	}
+
+	// An unchanged file moves to the new assets; the project's own does not.
+	useAssets(t, "v2")
+	if err := ExtractForSymlinkTo(cacheDir, "v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(agent); string(data) != "v2" {
+		t.Errorf("planner.md after switch = %q, want v2", data)
+	}
+	if data, _ := os.ReadFile(custom); string(data) != "mine" {
+		t.Errorf("custom.md after switch = %q, want mine", data)
+	}
+
+	// A file edited in place is left alone, including by a rollback, and
+	// the cached assets are not changed by the edit.
+	if err := os.WriteFile(agent, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLinkedAgent(t, cacheDir); got != "v2" {
+		t.Errorf("cached planner.md after edit = %q, want v2", got)
+	}
+	if _, err := UseAssetVersion(cacheDir, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(agent); string(data) != "edited" {
+		t.Errorf("edited planner.md after rollback = %q, want edited", data)
+	}
+
+	if err := UnregisterLinkedProject(cacheDir, projectDir); err != nil {
+		t.Fatalf("UnregisterLinkedProject() error = %v", err)
+	}
+	if got := readLinkedProjects(cacheDir); len(got) != 0 {
+		t.Errorf("linked projects after unregister = %q, want none", got)
+	}
+}
+
+func TestRelinkProjects_DropsMissing(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "assets")
+	gone := filepath.Join(t.TempDir(), "gone")
+
+	useAssets(t, "v1")
+	if err := ExtractForSymlinkTo(cacheDir, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLinkedProjects(cacheDir, []string{gone}); err != nil {
+		t.Fatal(err)
+	}
+	useAssets(t, "v2")
+	if err := ExtractForSymlinkTo(cacheDir, "v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLinkedProjects(cacheDir); len(got) != 0 {
+		t.Errorf("linked projects = %q, want the missing project dropped", got)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,171 @@
+package platform
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// CACertEnv names the environment variable pointing at a PEM file of extra
+// trusted root certificates, for networks that intercept HTTPS.
+const CACertEnv = "CLAUDE_WORKSPACE_CA_CERT"
+
+// baseTransport performs requests for every client returned by HTTPClient.
+// ConfigureHTTP replaces it once the custom CA, if any, is known.
+var baseTransport http.RoundTripper = newTransport(nil)
+
+// caCertFile is the CA certificate file applied by ConfigureHTTP, or "".
+var caCertFile string
+
+// HTTPClient returns a client for outbound requests. It honors HTTPS_PROXY,
+// HTTP_PROXY, and NO_PROXY, trusts the CA certificate set up by ConfigureHTTP,
+// and explains certificate and proxy failures. It is safe to call before
+// ConfigureHTTP: requests use the configuration current when they are sent.
+func HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: explainingTransport{}}
+}
+
+// Download writes the body of a GET of rawURL with client to out and returns
+// its hex SHA-256. Bodies over maxSize bytes are an error.
+func Download(client *http.Client, rawURL string, out io.Writer, maxSize int64) (string, error) {
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if n > maxSize {
+		return "", fmt.Errorf("larger than %d MB", maxSize>>20)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ConfigureHTTP adds the certificates in the first CA file found to the
+// system trust roots: flagPath (--ca-cert), then $CLAUDE_WORKSPACE_CA_CERT,
+// then "caCert" in ~/.claude-workspace/config.json. The file is also exported
+// as NODE_EXTRA_CA_CERTS, unless already set, so Claude Code and npx-launched
+// MCP servers trust it too. With no CA file configured it changes nothing.
+func ConfigureHTTP(flagPath string) error {
+	path, source := flagPath, "--ca-cert"
+	if path == "" {
+		path, source = os.Getenv(CACertEnv), CACertEnv
+	}
+	if path == "" {
+		if _, err := ReadConfig(ConfigCACert, &path); err != nil {
+			return err
+		}
+		source = `"caCert" in ~/.claude-workspace/config.json`
+	}
+	if path == "" {
+		return nil
+	}
+
+	pool, err := loadCertPool(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	baseTransport = newTransport(pool)
+	caCertFile = path
+	if os.Getenv("NODE_EXTRA_CA_CERTS") == "" {
+		os.Setenv("NODE_EXTRA_CA_CERTS", path)
+	}
+	return nil
+}
+
+// CACertFile returns the CA certificate file in use, or "" when only the
+// system trust roots are used.
+func CACertFile() string {
+	return caCertFile
+}
+
+// loadCertPool returns the system roots plus the PEM certificates in path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// newTransport returns a transport that uses the proxy environment variables
+// and, when roots is non-nil, verifies server certificates against roots.
+func newTransport(roots *x509.CertPool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if roots != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+	return t
+}
+
+// explainingTransport sends requests through baseTransport, logs them, and
+// rewrites certificate and proxy errors into actionable ones.
+type explainingTransport struct{}
+
+func (explainingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := baseTransport.RoundTrip(req)
+	// The query string and credentials are left out; they can carry tokens.
+	url := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	if err != nil {
+		logger.Debug("http", "method", req.Method, "url", url, "duration", time.Since(start).Round(time.Millisecond), "error", err.Error())
+		return nil, explainNetworkError(req.URL.Host, err)
+	}
+	logger.Debug("http", "method", req.Method, "url", url, "status", resp.StatusCode, "duration", time.Since(start).Round(time.Millisecond))
+	return resp, nil
+}
+
+// explainNetworkError adds a hint to TLS verification and proxy errors. Other
+// errors are returned unchanged.
+func explainNetworkError(host string, err error) error {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalidCert x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	var verifyErr *tls.CertificateVerificationError
+	switch {
+	case errors.As(err, &hostname), errors.As(err, &invalidCert):
+		return fmt.Errorf("TLS certificate for %s is invalid (%w). A proxy may be intercepting HTTPS; check HTTPS_PROXY and NO_PROXY", host, err)
+	case errors.As(err, &unknownAuthority), errors.As(err, &verifyErr):
+		hint := "If your network inspects HTTPS traffic, trust your organization's root CA with --ca-cert <file.pem>, " +
+			CACertEnv + `, or "caCert" in ~/.claude-workspace/config.json.`
+		if caCertFile != "" {
+			hint = fmt.Sprintf("The certificate is not signed by the system roots or by %s; check that the file holds the CA your proxy uses.", caCertFile)
+		}
+		return fmt.Errorf("TLS certificate for %s is not trusted (%w). %s", host, err, hint)
+	case strings.Contains(err.Error(), "proxyconnect"):
+		return fmt.Errorf("could not connect through the proxy (%w). Check HTTPS_PROXY (currently %q) and NO_PROXY", err, proxyEnv())
+	}
+	return err
+}
+
+// proxyEnv returns the HTTPS proxy setting in effect.
+func proxyEnv() string {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package platform
+
+import (
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetHTTP restores the default transport after a test configures a CA.
+func resetHTTP(t *testing.T) {
+	t.Helper()
+	t.Setenv("NODE_EXTRA_CA_CERTS", "")
+	t.Setenv(CACertEnv, "")
+	t.Cleanup(func() {
+		baseTransport = newTransport(nil)
+		caCertFile = ""
+	})
+}
+
+// serverCA writes the certificate of a TLS test server to a PEM file.
+func serverCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigureHTTP(t *testing.T) {
+	resetHTTP(t)
+	t.Setenv("HOME", t.TempDir())
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	client := HTTPClient(5 * time.Second)
+
+	_, err := client.Get(srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "not trusted") || !strings.Contains(err.Error(), "--ca-cert") {
+		t.Fatalf("Get() with an untrusted certificate error = %v, want a --ca-cert hint", err)
+	}
+
+	ca := serverCA(t, srv)
+	if err := ConfigureHTTP(ca); err != nil {
+		t.Fatalf("ConfigureHTTP() error = %v", err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() after ConfigureHTTP error = %v", err)
+	}
+	resp.Body.Close()
+	if CACertFile() != ca {
+		t.Errorf("CACertFile() = %q, want %q", CACertFile(), ca)
+	}
+	if got := os.Getenv("NODE_EXTRA_CA_CERTS"); got != ca {
+		t.Errorf("NODE_EXTRA_CA_CERTS = %q, want %q", got, ca)
+	}
+}
+
+func TestConfigureHTTP_Sources(t *testing.T) {
+	resetHTTP(t)
+	t.Setenv("HOME", t.TempDir())
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	ca := serverCA(t, srv)
+
+	if err := ConfigureHTTP(""); err != nil || CACertFile() != "" {
+		t.Fatalf("ConfigureHTTP() with nothing configured = %q, %v", CACertFile(), err)
+	}
+
+	if err := WriteConfig("caCert", ca); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureHTTP(""); err != nil || CACertFile() != ca {
+		t.Errorf("ConfigureHTTP() from config.json = %q, %v; want %q", CACertFile(), err, ca)
+	}
+
+	t.Setenv(CACertEnv, filepath.Join(t.TempDir(), "missing.pem"))
+	if err := ConfigureHTTP(""); err == nil || !strings.Contains(err.Error(), CACertEnv) {
+		t.Errorf("ConfigureHTTP() with a missing file error = %v, want it to name %s", err, CACertEnv)
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	_ = os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+	if err := ConfigureHTTP(notPEM); err == nil {
+		t.Error("ConfigureHTTP() accepted a file without certificates")
+	}
+}
+
+func TestExplainNetworkError(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy.corp:3128")
+	err := explainNetworkError("api.github.com", errors.New("proxyconnect tcp: dial tcp 10.0.0.1:3128: connect: connection refused"))
+	if !strings.Contains(err.Error(), "proxy.corp:3128") {
+		t.Errorf("proxy error = %v, want the HTTPS_PROXY value", err)
+	}
+
+	plain := errors.New("connection reset")
+	if got := explainNetworkError("api.github.com", plain); got != plain {
+		t.Errorf("explainNetworkError() changed an unrelated error: %v", got)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	var buf strings.Builder
+	sum, err := Download(srv.Client(), srv.URL+"/file", &buf, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello" || sum != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Download() = %q, body %q", sum, buf.String())
+	}
+	if _, err := Download(srv.Client(), srv.URL+"/file", io.Discard, 4); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Download() over the limit: err = %v", err)
+	}
+	if _, err := Download(srv.Client(), srv.URL+"/missing", io.Discard, 1<<20); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Download() of a missing file: err = %v", err)
+	}
+}
@@ -18,14 +18,21 @@ func ReadJSONFile(path string, v interface{}) error {
 	return nil
 }
 
-// WriteJSONFile marshals v as indented JSON and writes it to path.
+// WriteJSONFile marshals v as indented JSON and writes it to path with
+// WriteFileAtomic. An existing file keeps its permissions; a new one is 0644.
+// Callers updating a file other commands may update concurrently, such as
+// ~/.claude.json or settings.json, hold WithFileLock around the read and write.
 func WriteJSONFile(path string, v interface{}) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling JSON: %w", err)
 	}
 	data = append(data, '\n')
-	return os.WriteFile(path, data, 0644)
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return WriteFileAtomic(path, data, perm)
 }
 
 // ReadJSONFileRaw reads a JSON file into a map to preserve unknown fields.
@@ -80,6 +80,31 @@ func TestWriteJSONFile_Permissions(t *testing.T) {
 	}
 }
 
+func TestWriteJSONFile_KeepsModeAndLink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "claude.json")
+	os.MkdirAll(filepath.Dir(target), 0755)
+	os.WriteFile(target, []byte("{}"), 0600)
+	link := filepath.Join(dir, ".claude.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteJSONFile(link, map[string]string{"theme": "dark"}); err != nil {
+		t.Fatalf("WriteJSONFile() error = %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("link was replaced by a file: %v", err)
+	}
+	info, _ := os.Stat(target)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %o, want 0600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(target); string(data) != "{\n  \"theme\": \"dark\"\n}\n" {
+		t.Errorf("target content = %q", data)
+	}
+}
+
 func TestWriteJSONFile_NestedStruct(t *testing.T) {
 	dir := t.TempDir()
 	f := filepath.Join(dir, "nested.json")
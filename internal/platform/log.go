@@ -0,0 +1,190 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLogFiles is how many command logs are kept in the logs directory; older
+// ones are removed when a new log starts.
+const maxLogFiles = 50
+
+// logger receives debug records for the current command. It discards them
+// until StartLog is called.
+var logger = slog.New(slog.DiscardHandler)
+
+// Logger returns the logger for the current command.
+func Logger() *slog.Logger { return logger }
+
+// LogDir returns ~/.claude-workspace/logs.
+func LogDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "logs"), nil
+}
+
+// StartLog writes debug logs for command to a new file in LogDir, named
+// <command>-<timestamp>.log, and also to stderr when verbose is set. It
+// returns the log file's path and a function that closes it. If the file
+// cannot be created, verbose logging still goes to stderr and the error is
+// returned.
+func StartLog(command string, verbose bool) (string, func(), error) {
+	var writers []io.Writer
+	if verbose {
+		writers = append(writers, os.Stderr)
+	}
+	path, f, err := createLogFile(command)
+	if f != nil {
+		writers = append(writers, f)
+	}
+	if len(writers) > 0 {
+		logger = slog.New(slog.NewTextHandler(io.MultiWriter(writers...), &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	closeLog := func() {
+		if f != nil {
+			f.Close()
+		}
+	}
+	return path, closeLog, err
+}
+
+func createLogFile(command string) (string, *os.File, error) {
+	dir, err := LogDir()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+	pruneLogs(dir, maxLogFiles-1)
+	name := fmt.Sprintf("%s-%s.log", command, time.Now().Format("20060102-150405.000"))
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", nil, fmt.Errorf("creating log file: %w", err)
+	}
+	return path, f, nil
+}
+
+// pruneLogs removes the oldest .log files in dir so that at most keep remain.
+func pruneLogs(dir string, keep int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type logFile struct {
+		path string
+		mod  time.Time
+	}
+	var logs []logFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".log") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, logFile{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	if len(logs) <= keep {
+		return
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].mod.After(logs[j].mod) })
+	for _, l := range logs[keep:] {
+		os.Remove(l.path)
+	}
+}
+
+// maxLoggedStderr bounds how much of a failed command's captured stderr is
+// logged.
+const maxLoggedStderr = 2048
+
+// logCommand records a finished subprocess: its masked command line,
+// directory, exit code, and duration. stderr is the output the command wrote
+// to a captured stderr, logged only when it failed.
+func logCommand(cmd *exec.Cmd, start time.Time, err error, stderr string) {
+	attrs := []any{
+		"cmd", strings.Join(MaskArgs(cmd.Args), " "),
+		"exit", exitCode(err),
+		"duration", time.Since(start).Round(time.Millisecond),
+	}
+	if cmd.Dir != "" {
+		attrs = append(attrs, "dir", cmd.Dir)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			if len(stderr) > maxLoggedStderr {
+				stderr = "..." + stderr[len(stderr)-maxLoggedStderr:]
+			}
+			attrs = append(attrs, "stderr", stderr)
+		}
+	}
+	logger.Debug("exec", attrs...)
+}
+
+// exitCode returns the exit code for a command's error: 0 on success, the
+// process's code if it exited, and -1 if it could not be run.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// MaskArgs returns a copy of a command line with secrets replaced by "****":
+// the values of "-e KEY=value" and "--env KEY=value", of every "--header",
+// and of "--client-secret", each also in its "--flag=value" form. Use it
+// before printing or logging a command.
+func MaskArgs(args []string) []string {
+	safeArgs := make([]string, len(args))
+	for idx, arg := range args {
+		safeArgs[idx] = arg
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(name, "-") {
+			if masked, secret := maskFlagValue(name, value); secret {
+				safeArgs[idx] = name + "=" + masked
+			}
+			continue
+		}
+		if idx > 0 {
+			if masked, secret := maskFlagValue(args[idx-1], arg); secret {
+				safeArgs[idx] = masked
+			}
+		}
+	}
+	return safeArgs
+}
+
+// maskFlagValue returns value with its secret part masked when flag is one
+// that carries a secret.
+func maskFlagValue(flag, value string) (masked string, secret bool) {
+	switch flag {
+	case "-e", "--env":
+		if key, _, ok := strings.Cut(value, "="); ok {
+			return key + "=****", true
+		}
+	case "--header":
+		if name, _, ok := strings.Cut(value, ":"); ok {
+			return name + ": ****", true
+		}
+		return "****", true
+	case "--client-secret":
+		return "****", true
+	}
+	return value, false
+}
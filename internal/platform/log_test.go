@@ -0,0 +1,134 @@
+package platform
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaskArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "env values masked",
+			args: []string{"mcp", "add", "-e", "API_KEY=secret123", "my-server"},
+			want: []string{"mcp", "add", "-e", "API_KEY=****", "my-server"},
+		},
+		{
+			name: "bearer headers masked",
+			args: []string{"mcp", "add", "--header", "Authorization: Bearer mysecrettoken", "my-server"},
+			want: []string{"mcp", "add", "--header", "Authorization: ****", "my-server"},
+		},
+		{
+			name: "non-sensitive args preserved",
+			args: []string{"mcp", "add", "--transport", "http", "--scope", "user", "my-server"},
+			want: []string{"mcp", "add", "--transport", "http", "--scope", "user", "my-server"},
+		},
+		{
+			name: "multiple env vars all masked",
+			args: []string{"mcp", "add", "-e", "KEY1=val1", "-e", "KEY2=val2", "srv"},
+			want: []string{"mcp", "add", "-e", "KEY1=****", "-e", "KEY2=****", "srv"},
+		},
+		{
+			name: "every header value masked",
+			args: []string{"mcp", "add", "--header", "X-API-Key: abc123", "--header", "token", "srv"},
+			want: []string{"mcp", "add", "--header", "X-API-Key: ****", "--header", "****", "srv"},
+		},
+		{
+			name: "--env values masked",
+			args: []string{"mcp", "add", "--env", "API_KEY=secret123", "srv"},
+			want: []string{"mcp", "add", "--env", "API_KEY=****", "srv"},
+		},
+		{
+			name: "= forms masked",
+			args: []string{"mcp", "update", "--env=API_KEY=secret123", "-e=TOKEN=t", "--header=X-API-Key: abc", "--client-secret=s3cret", "srv"},
+			want: []string{"mcp", "update", "--env=API_KEY=****", "-e=TOKEN=****", "--header=X-API-Key: ****", "--client-secret=****", "srv"},
+		},
+		{
+			name: "= values of other flags preserved",
+			args: []string{"mcp", "add", "--scope=user", "--transport", "http", "KEY=value"},
+			want: []string{"mcp", "add", "--scope=user", "--transport", "http", "KEY=value"},
+		},
+		{
+			name: "client-secret value masked",
+			args: []string{"mcp", "add", "--client-id", "my-id", "--client-secret", "supersensitivevalue", "srv"},
+			want: []string{"mcp", "add", "--client-id", "my-id", "--client-secret", "****", "srv"},
+		},
+		{
+			name: "empty args",
+			args: []string{},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MaskArgs(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartLog(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { logger = slog.New(slog.DiscardHandler) })
+
+	path, closeLog, err := StartLog("attach", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir := filepath.Join(home, ".claude-workspace", "logs"); filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "attach-") {
+		t.Errorf("log path = %s, want attach-<timestamp>.log in %s", path, dir)
+	}
+	_, _ = Output("sh", "-c", "echo boom >&2; exit 3", "-e", "API_KEY=secret123")
+	closeLog()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	for _, want := range []string{"msg=exec", "exit=3", "API_KEY=****", "stderr=boom", "duration="} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log is missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "secret123") {
+		t.Errorf("log contains a secret:\n%s", log)
+	}
+}
+
+func TestPruneLogs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("setup-%d.log", i))
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		mod := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pruneLogs(dir, 2)
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"setup-3.log", "setup-4.log"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("kept %v, want the newest %v", names, want)
+	}
+}
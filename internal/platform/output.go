@@ -0,0 +1,190 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// OutputMode selects how commands report what they are doing.
+type OutputMode int
+
+const (
+	// OutputText is human-readable text, colored on a terminal.
+	OutputText OutputMode = iota
+	// OutputJSON writes one JSON event per line to stdout (--json).
+	OutputJSON
+	// OutputQuiet prints errors only, to stderr (--quiet).
+	OutputQuiet
+)
+
+// Event kinds written in JSON mode. Each print helper maps to one kind;
+// plain text written to Stdout() becomes a "message".
+const (
+	EventBanner   = "banner"
+	EventSection  = "section"
+	EventStep     = "step"
+	EventOK       = "ok"
+	EventFail     = "fail"
+	EventWarning  = "warning"
+	EventInfo     = "info"
+	EventSuccess  = "success"
+	EventError    = "error"
+	EventCommand  = "command"
+	EventManual   = "manual"
+	EventProgress = "progress"
+	EventMessage  = "message"
+	EventOutcome  = "outcome"
+)
+
+// Event is one line of JSON output.
+type Event struct {
+	Event   string `json:"event"`
+	Message string `json:"message,omitempty"`
+	Step    int    `json:"step,omitempty"`
+	Total   int    `json:"total,omitempty"`
+}
+
+var (
+	outputMode = OutputText
+	stdoutMu   sync.Mutex
+	stdout     io.Writer // cached Stdout() writer for the current mode
+)
+
+// SetOutputMode switches the output mode for the rest of the process. JSON
+// and quiet modes also turn off color.
+func SetOutputMode(m OutputMode) {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	outputMode = m
+	stdout = nil
+	if m != OutputText {
+		colorEnabled = false
+	}
+}
+
+// JSONOutput reports whether --json output is on.
+func JSONOutput() bool { return outputMode == OutputJSON }
+
+// QuietOutput reports whether --quiet output is on.
+func QuietOutput() bool { return outputMode == OutputQuiet }
+
+// DisableColor turns off ANSI colors, as --no-color does.
+func DisableColor() { colorEnabled = false }
+
+// Stdout returns the writer commands print their output to. In text mode it
+// is os.Stdout; in JSON mode each line written becomes a "message" event; in
+// quiet mode output is discarded.
+func Stdout() io.Writer {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	if stdout == nil {
+		switch outputMode {
+		case OutputJSON:
+			stdout = &eventWriter{out: os.Stdout}
+		case OutputQuiet:
+			stdout = io.Discard
+		default:
+			stdout = os.Stdout
+		}
+	}
+	return stdout
+}
+
+// FlushOutput writes any partial line buffered by Stdout() in JSON mode.
+// Call it before exiting.
+func FlushOutput() {
+	if ew, ok := Stdout().(*eventWriter); ok {
+		ew.mu.Lock()
+		ew.flush()
+		ew.mu.Unlock()
+	}
+}
+
+// childStdout returns where subprocesses run with inherited output write
+// their stdout: the terminal in text mode, stderr in JSON mode so stdout
+// stays machine-readable, and nowhere in quiet mode.
+func childStdout() io.Writer {
+	switch outputMode {
+	case OutputJSON:
+		return os.Stderr
+	case OutputQuiet:
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// report writes a status line in the current output mode. In text mode human
+// writes it to w; in JSON mode it becomes ev; in quiet mode only errors are
+// kept, written as text to stderr.
+func report(w io.Writer, ev Event, human func(io.Writer)) {
+	switch outputMode {
+	case OutputJSON:
+		emit(w, ev)
+	case OutputQuiet:
+		if ev.Event == EventFail || ev.Event == EventError {
+			human(os.Stderr)
+		}
+	default:
+		human(w)
+	}
+}
+
+// PrintOutcome reports how a command ended, as an "outcome" event in JSON
+// mode, for scripts that act on it rather than on the wording of the text.
+// The text output already says the same in words, so nothing else is printed.
+func PrintOutcome(w io.Writer, outcome string) {
+	report(w, Event{Event: EventOutcome, Message: outcome}, func(io.Writer) {})
+}
+
+// emit writes ev as a JSON line to w, or to the stream underneath w when w
+// is the JSON-mode Stdout().
+func emit(w io.Writer, ev Event) {
+	if ew, ok := w.(*eventWriter); ok {
+		ew.mu.Lock()
+		defer ew.mu.Unlock()
+		ew.flush()
+		w = ew.out
+	}
+	_ = json.NewEncoder(w).Encode(ev)
+}
+
+// eventWriter turns lines of text into "message" events. Blank lines are
+// dropped and indentation is trimmed.
+type eventWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	buf []byte
+}
+
+func (ew *eventWriter) Write(p []byte) (int, error) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	ew.buf = append(ew.buf, p...)
+	for {
+		i := bytes.IndexByte(ew.buf, '\n')
+		if i < 0 {
+			break
+		}
+		ew.message(string(ew.buf[:i]))
+		ew.buf = ew.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush emits the buffered partial line, if any. The caller holds ew.mu.
+func (ew *eventWriter) flush() {
+	if len(ew.buf) > 0 {
+		ew.message(string(ew.buf))
+		ew.buf = nil
+	}
+}
+
+func (ew *eventWriter) message(line string) {
+	if line = strings.TrimSpace(line); line != "" {
+		_ = json.NewEncoder(ew.out).Encode(Event{Event: EventMessage, Message: line})
+	}
+}
@@ -0,0 +1,108 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func useOutputMode(t *testing.T, m OutputMode) {
+	t.Helper()
+	SetOutputMode(m)
+	t.Cleanup(func() {
+		SetOutputMode(OutputText)
+		colorEnabled = false
+	})
+}
+
+func decodeEvents(t *testing.T, data string) []Event {
+	t.Helper()
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var ev Event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("not a JSON event: %q", line)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestJSONOutput_Events(t *testing.T) {
+	useOutputMode(t, OutputJSON)
+	var buf bytes.Buffer
+	w := &eventWriter{out: &buf}
+
+	PrintBanner(w, "Setup")
+	PrintStep(w, 2, 10, "Installing...")
+	_, _ = io.WriteString(w, "  Worktree created at: /tmp/wt\n\n  partial")
+	PrintOK(w, "installed")
+	PrintWarningLine(w, "Node.js is old")
+	PrintCommand(w, "claude-workspace attach .")
+	_, _ = io.WriteString(w, "trailing")
+	PrintOutcome(w, "up-to-date")
+
+	want := []Event{
+		{Event: EventBanner, Message: "Setup"},
+		{Event: EventStep, Message: "Installing...", Step: 2, Total: 10},
+		{Event: EventMessage, Message: "Worktree created at: /tmp/wt"},
+		{Event: EventMessage, Message: "partial"},
+		{Event: EventOK, Message: "installed"},
+		{Event: EventWarning, Message: "Node.js is old"},
+		{Event: EventCommand, Message: "claude-workspace attach ."},
+		{Event: EventMessage, Message: "trailing"},
+		{Event: EventOutcome, Message: "up-to-date"},
+	}
+	if got := decodeEvents(t, buf.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("events:\n got %+v\nwant %+v", got, want)
+	}
+	if colorEnabled {
+		t.Error("JSON mode should turn off color")
+	}
+}
+
+func TestJSONOutput_OtherWriters(t *testing.T) {
+	useOutputMode(t, OutputJSON)
+	var buf bytes.Buffer
+	PrintFail(&buf, "settings.json is invalid")
+	if got := decodeEvents(t, buf.String()); !reflect.DeepEqual(got, []Event{{Event: EventFail, Message: "settings.json is invalid"}}) {
+		t.Errorf("PrintFail to a plain writer = %q", buf.String())
+	}
+	if childStdout() != os.Stderr {
+		t.Error("subprocess output should go to stderr in JSON mode")
+	}
+}
+
+func TestQuietOutput(t *testing.T) {
+	useOutputMode(t, OutputQuiet)
+	var buf bytes.Buffer
+	PrintBanner(&buf, "Setup")
+	PrintStep(&buf, 1, 2, "Checking...")
+	PrintOK(&buf, "done")
+	PrintWarn(&buf, "careful")
+	StartSpinner(&buf, "working").Stop()
+	_, _ = io.WriteString(Stdout(), "progress\n")
+	if buf.Len() != 0 {
+		t.Errorf("quiet mode should print nothing but errors, got %q", buf.String())
+	}
+	if Stdout() != io.Discard || childStdout() != io.Discard {
+		t.Error("quiet mode should discard command and subprocess output")
+	}
+}
+
+func TestTextOutput(t *testing.T) {
+	useOutputMode(t, OutputText)
+	if Stdout() != os.Stdout || childStdout() != os.Stdout {
+		t.Error("text mode should write to os.Stdout")
+	}
+	var buf bytes.Buffer
+	PrintStep(&buf, 1, 2, "Checking...")
+	PrintOutcome(&buf, "up-to-date")
+	if buf.String() != "\n[1/2] Checking...\n" {
+		t.Errorf("PrintStep() = %q", buf.String())
+	}
+}
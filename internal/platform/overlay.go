@@ -0,0 +1,52 @@
+package platform
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+)
+
+// OverlayFS layers an upper filesystem over a lower one. Files in upper shadow
+// files with the same path in lower, and directory listings are merged. It is
+// used to apply a template profile's extra assets on top of the base project
+// template.
+type OverlayFS struct {
+	Upper fs.FS
+	Lower fs.FS
+}
+
+// Open opens name from the upper filesystem, falling back to the lower one.
+func (o OverlayFS) Open(name string) (fs.File, error) {
+	f, err := o.Upper.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.Lower.Open(name)
+}
+
+// ReadDir returns the merged, name-sorted entries of name in both layers.
+// Entries from the upper layer win on name collisions.
+func (o OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	upper, upperErr := fs.ReadDir(o.Upper, name)
+	lower, lowerErr := fs.ReadDir(o.Lower, name)
+	if upperErr != nil && lowerErr != nil {
+		return nil, lowerErr
+	}
+
+	seen := make(map[string]bool, len(upper))
+	merged := make([]fs.DirEntry, 0, len(upper)+len(lower))
+	for _, e := range upper {
+		seen[e.Name()] = true
+		merged = append(merged, e)
+	}
+	for _, e := range lower {
+		if !seen[e.Name()] {
+			merged = append(merged, e)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
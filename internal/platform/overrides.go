@@ -0,0 +1,120 @@
+package platform
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// OverridesEnv names the environment variable that points at the template
+// overrides directory, replacing the default ~/.claude-workspace/overrides.
+const OverridesEnv = "CLAUDE_WORKSPACE_OVERRIDES"
+
+// overridesDir is the overrides directory applied by ApplyOverrides, or "".
+var overridesDir string
+
+// embeddedFS and embeddedGlobalFS keep the embedded templates that
+// ApplyOverrides shadowed, so ListOverrides can tell additions from replacements.
+var embeddedFS, embeddedGlobalFS fs.FS
+
+// OverrideFile is a file in the overrides directory.
+type OverrideFile struct {
+	Layer    string // "project" or "global"
+	Path     string // slash-separated path within the layer, e.g. ".claude/agents/planner.md"
+	Replaces bool   // true when it shadows an embedded asset, false when it adds one
+}
+
+// OverridesDir returns the template overrides directory: $CLAUDE_WORKSPACE_OVERRIDES
+// when set, otherwise ~/.claude-workspace/overrides. It need not exist.
+func OverridesDir() (string, error) {
+	if dir := os.Getenv(OverridesEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude-workspace", "overrides"), nil
+}
+
+// ApplyOverrides layers the overrides directory over the embedded templates so
+// organizations can add or replace assets without rebuilding the binary. Its
+// project/ subdirectory mirrors _template/project (agents, skills, hooks,
+// settings.json, .mcp.json) and shadows FS; its global/ subdirectory mirrors
+// _template/global and shadows GlobalFS. Called by main after the embedded
+// filesystems are wired; it is a no-op when the directory does not exist.
+func ApplyOverrides() {
+	dir, err := OverridesDir()
+	if err != nil || !FileExists(dir) {
+		return
+	}
+	overridesDir = dir
+	embeddedFS, embeddedGlobalFS = FS, GlobalFS
+	FS = withOverrides(FS, "project")
+	GlobalFS = withOverrides(GlobalFS, "global")
+}
+
+// withOverrides returns base with the named layer of the applied overrides
+// directory on top, or base unchanged when no overrides are applied.
+func withOverrides(base fs.FS, layer string) fs.FS {
+	if overridesDir == "" {
+		return base
+	}
+	return OverlayFS{Upper: os.DirFS(filepath.Join(overridesDir, layer)), Lower: base}
+}
+
+// WithOverrides returns a project template filesystem, such as a remote
+// template, with the applied overrides on top.
+func WithOverrides(base fs.FS) fs.FS {
+	return withOverrides(base, "project")
+}
+
+// AppliedOverridesDir returns the overrides directory in effect, or "" when
+// only embedded assets are used.
+func AppliedOverridesDir() string {
+	return overridesDir
+}
+
+// ListOverrides returns the files in the applied overrides directory, noting
+// which replace an embedded asset. It returns nil when no overrides are applied.
+func ListOverrides() []OverrideFile {
+	if overridesDir == "" {
+		return nil
+	}
+	return listOverrides(overridesDir, embeddedFS, embeddedGlobalFS)
+}
+
+// listOverrides returns the files in the project and global layers of dir,
+// sorted by layer and path, checking each against the matching embedded
+// filesystem.
+func listOverrides(dir string, embeddedProject, embeddedGlobal fs.FS) []OverrideFile {
+	var files []OverrideFile
+	for _, l := range []struct {
+		name     string
+		embedded fs.FS
+	}{{"project", embeddedProject}, {"global", embeddedGlobal}} {
+		root := filepath.Join(dir, l.name)
+		if !FileExists(root) {
+			continue
+		}
+		_ = WalkFiles(root, func(rel string) error {
+			rel = filepath.ToSlash(rel)
+			f := OverrideFile{Layer: l.name, Path: rel}
+			if l.embedded != nil {
+				if _, err := fs.Stat(l.embedded, rel); err == nil {
+					f.Replaces = true
+				}
+			}
+			files = append(files, f)
+			return nil
+		})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Layer != files[j].Layer {
+			return files[i].Layer > files[j].Layer // project before global
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
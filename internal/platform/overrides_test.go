@@ -0,0 +1,127 @@
+package platform
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func setupOverrides(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldFS, oldGlobal, oldProfiles := FS, GlobalFS, ProfilesFS
+	oldDir, oldEmbedded, oldEmbeddedGlobal := overridesDir, embeddedFS, embeddedGlobalFS
+	FS = fstest.MapFS{
+		".claude/agents/planner.md": &fstest.MapFile{Data: []byte("base planner")},
+		".claude/settings.json":     &fstest.MapFile{Data: []byte(`{"base":true}`)},
+	}
+	GlobalFS = fstest.MapFS{"CLAUDE.md": &fstest.MapFile{Data: []byte("base global")}}
+	ProfilesFS = fstest.MapFS{
+		"data/profile.yaml":              &fstest.MapFile{Data: []byte("agents: [planner]\n")},
+		"data/.claude/agents/planner.md": &fstest.MapFile{Data: []byte("profile planner")},
+		"data/.claude/agents/analyst.md": &fstest.MapFile{Data: []byte("profile analyst")},
+	}
+	t.Cleanup(func() {
+		FS, GlobalFS, ProfilesFS = oldFS, oldGlobal, oldProfiles
+		overridesDir, embeddedFS, embeddedGlobalFS = oldDir, oldEmbedded, oldEmbeddedGlobal
+	})
+
+	t.Setenv(OverridesEnv, dir)
+	return dir
+}
+
+func TestOverridesDir(t *testing.T) {
+	t.Setenv(OverridesEnv, "")
+	t.Setenv("HOME", "/home/tester")
+	if got, _ := OverridesDir(); got != filepath.Join("/home/tester", ".claude-workspace", "overrides") {
+		t.Errorf("OverridesDir() = %q", got)
+	}
+	t.Setenv(OverridesEnv, "/etc/acme/claude")
+	if got, _ := OverridesDir(); got != "/etc/acme/claude" {
+		t.Errorf("OverridesDir() with %s = %q", OverridesEnv, got)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	setupOverrides(t, map[string]string{
+		"project/.claude/agents/planner.md":  "org planner",
+		"project/.claude/agents/security.md": "org security",
+		"global/CLAUDE.md":                   "org global",
+	})
+	ApplyOverrides()
+
+	for path, want := range map[string]string{
+		".claude/agents/planner.md":  "org planner",
+		".claude/agents/security.md": "org security",
+		".claude/settings.json":      `{"base":true}`,
+	} {
+		if data, err := ReadAsset(path); err != nil || string(data) != want {
+			t.Errorf("ReadAsset(%s) = %q, %v; want %q", path, data, err, want)
+		}
+	}
+	if data, _ := ReadGlobalAsset("CLAUDE.md"); string(data) != "org global" {
+		t.Errorf("ReadGlobalAsset(CLAUDE.md) = %q", data)
+	}
+
+	var agents []string
+	_ = WalkAssets(".claude/agents", func(path string, d fs.DirEntry) error {
+		agents = append(agents, path)
+		return nil
+	})
+	if len(agents) != 2 {
+		t.Errorf("WalkAssets(.claude/agents) = %v, want planner and security", agents)
+	}
+
+	// Overrides also win over a profile's assets.
+	pfs, err := ProfileFS("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		".claude/agents/planner.md": "org planner",
+		".claude/agents/analyst.md": "profile analyst",
+	} {
+		if data, _ := fs.ReadFile(pfs, path); string(data) != want {
+			t.Errorf("profile %s = %q, want %q", path, data, want)
+		}
+	}
+
+	got := ListOverrides()
+	want := []OverrideFile{
+		{Layer: "project", Path: ".claude/agents/planner.md", Replaces: true},
+		{Layer: "project", Path: ".claude/agents/security.md"},
+		{Layer: "global", Path: "CLAUDE.md", Replaces: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListOverrides() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListOverrides()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestApplyOverrides_MissingDir(t *testing.T) {
+	setupOverrides(t, nil)
+	t.Setenv(OverridesEnv, filepath.Join(t.TempDir(), "missing"))
+	ApplyOverrides()
+	if AppliedOverridesDir() != "" || ListOverrides() != nil {
+		t.Error("missing overrides directory should not be applied")
+	}
+	if data, _ := ReadAsset(".claude/agents/planner.md"); string(data) != "base planner" {
+		t.Errorf("ReadAsset() = %q, want embedded content", data)
+	}
+}
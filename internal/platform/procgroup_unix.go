@@ -0,0 +1,32 @@
+//go:build unix
+
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// SetProcessGroup makes cmd start in a process group of its own, so
+// KillProcessGroup also reaches the processes it starts, as launchers like npx
+// do for the real server.
+func SetProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// KillProcessGroup kills p and the rest of the process group SetProcessGroup
+// gave it.
+func KillProcessGroup(p *os.Process) {
+	syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+// ProcessAlive reports whether a process with the given PID exists.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,62 @@
+package platform
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// ProfilesFS is set by main to the embedded template profiles filesystem (_template/profiles).
+var ProfilesFS fs.FS
+
+// ProfileFile is the selection manifest every profile directory must contain.
+const ProfileFile = "profile.yaml"
+
+// ListProfiles returns the sorted names of all embedded template profiles.
+// A profile is any top-level directory in ProfilesFS containing profile.yaml.
+func ListProfiles() []string {
+	if ProfilesFS == nil {
+		return nil
+	}
+	entries, err := fs.ReadDir(ProfilesFS, ".")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := fs.Stat(ProfilesFS, e.Name()+"/"+ProfileFile); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadProfile returns the profile.yaml content for the named profile. Unknown
+// names produce an error listing the available profiles.
+func ReadProfile(name string) ([]byte, error) {
+	for _, p := range ListProfiles() {
+		if p == name {
+			return fs.ReadFile(ProfilesFS, name+"/"+ProfileFile)
+		}
+	}
+	return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ListProfiles(), ", "))
+}
+
+// ProfileFS returns the project template with the named profile's extra assets
+// overlaid on top, so profile files shadow base files with the same path.
+// Template overrides (see ApplyOverrides) still take precedence over both.
+func ProfileFS(name string) (fs.FS, error) {
+	if _, err := ReadProfile(name); err != nil {
+		return nil, err
+	}
+	sub, err := fs.Sub(ProfilesFS, name)
+	if err != nil {
+		return nil, err
+	}
+	return withOverrides(OverlayFS{Upper: sub, Lower: FS}, "project"), nil
+}
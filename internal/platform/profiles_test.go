@@ -0,0 +1,70 @@
+package platform
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func setupProfileFS(t *testing.T) {
+	t.Helper()
+	oldFS, oldProfiles := FS, ProfilesFS
+	FS = fstest.MapFS{
+		".claude/agents/planner.md":  &fstest.MapFile{Data: []byte("base planner")},
+		".claude/agents/explorer.md": &fstest.MapFile{Data: []byte("base explorer")},
+	}
+	ProfilesFS = fstest.MapFS{
+		"data/profile.yaml":               &fstest.MapFile{Data: []byte("agents: [analyst]\n")},
+		"data/.claude/agents/analyst.md":  &fstest.MapFile{Data: []byte("analyst")},
+		"data/.claude/agents/explorer.md": &fstest.MapFile{Data: []byte("profile explorer")},
+		"minimal/profile.yaml":            &fstest.MapFile{Data: []byte("agents: [planner]\n")},
+		"not-a-profile/README.md":         &fstest.MapFile{Data: []byte("x")},
+	}
+	t.Cleanup(func() { FS, ProfilesFS = oldFS, oldProfiles })
+}
+
+func TestListProfiles(t *testing.T) {
+	setupProfileFS(t)
+	got := strings.Join(ListProfiles(), ",")
+	if got != "data,minimal" {
+		t.Errorf("ListProfiles() = %q, want %q", got, "data,minimal")
+	}
+}
+
+func TestReadProfile_Unknown(t *testing.T) {
+	setupProfileFS(t)
+	_, err := ReadProfile("nope")
+	if err == nil || !strings.Contains(err.Error(), "available: data, minimal") {
+		t.Errorf("ReadProfile() error = %v, want list of available profiles", err)
+	}
+}
+
+func TestProfileFS_Overlay(t *testing.T) {
+	setupProfileFS(t)
+	pfs, err := ProfileFS("data")
+	if err != nil {
+		t.Fatalf("ProfileFS() error = %v", err)
+	}
+
+	entries, err := fs.ReadDir(pfs, ".claude/agents")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ","); got != "analyst.md,explorer.md,planner.md" {
+		t.Errorf("merged entries = %q", got)
+	}
+
+	data, _ := fs.ReadFile(pfs, ".claude/agents/explorer.md")
+	if string(data) != "profile explorer" {
+		t.Errorf("explorer.md = %q, want profile version to shadow base", data)
+	}
+	data, _ = fs.ReadFile(pfs, ".claude/agents/planner.md")
+	if string(data) != "base planner" {
+		t.Errorf("planner.md = %q, want base version", data)
+	}
+}
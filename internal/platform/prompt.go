@@ -0,0 +1,61 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// PromptSecret prints prompt and reads a line from the terminal with each
+// typed character echoed as '*'. It falls back to hidden input when raw mode
+// is unavailable.
+func PromptSecret(prompt string) (string, error) {
+	PrintPrompt(os.Stdout, prompt)
+
+	fd := int(syscall.Stdin)
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		// Fall back to hidden input if raw mode is unavailable.
+		password, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		return strings.TrimSpace(string(password)), nil
+	}
+	defer func() { _ = term.Restore(fd, oldState) }()
+
+	var buf []byte
+	b := make([]byte, 1)
+	for {
+		_, err := os.Stdin.Read(b)
+		if err != nil {
+			fmt.Println()
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		switch b[0] {
+		case '\r', '\n': // Enter
+			fmt.Println()
+			return strings.TrimSpace(string(buf)), nil
+		case '\x03': // Ctrl+C
+			fmt.Println()
+			return "", fmt.Errorf("interrupted")
+		case '\x04': // Ctrl+D / EOF
+			fmt.Println()
+			return strings.TrimSpace(string(buf)), nil
+		case '\x7f', '\x08': // Backspace / Delete
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				fmt.Print("\b \b")
+			}
+		default:
+			if b[0] >= 0x20 && b[0] < 0x7f { // printable ASCII
+				buf = append(buf, b[0])
+				fmt.Print("*")
+			}
+		}
+	}
+}
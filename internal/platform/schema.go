@@ -0,0 +1,405 @@
+package platform
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// settingsSchemaJSON is the official claude-code-settings JSON Schema, from
+// https://json.schemastore.org/claude-code-settings.json.
+//
+//go:embed schema/claude-code-settings.schema.json
+var settingsSchemaJSON []byte
+
+// schema is the part of JSON Schema (draft-07) the settings schema uses.
+// Keywords it does not know, such as format, are ignored.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 interface{}        `json:"type"` // a type name or a list of them
+	Properties           map[string]*schema `json:"properties"`
+	PatternProperties    map[string]*schema `json:"patternProperties"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	UniqueItems          bool               `json:"uniqueItems"`
+	Enum                 []interface{}      `json:"enum"`
+	Const                json.RawMessage    `json:"const"`
+	MinLength            *int               `json:"minLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum"`
+	Maximum              *float64           `json:"maximum"`
+	AnyOf                []*schema          `json:"anyOf"`
+	Not                  *schema            `json:"not"`
+	Defs                 map[string]*schema `json:"$defs"`
+	Definitions          map[string]*schema `json:"definitions"`
+
+	// boolean is set for the schemas true (anything) and false (nothing).
+	boolean *bool
+}
+
+// UnmarshalJSON accepts a boolean schema as well as an object.
+func (s *schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if json.Unmarshal(data, &b) == nil {
+		s.boolean = &b
+		return nil
+	}
+	type plain schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// settingsSchemaAdditions describes settings Claude Code reads that the
+// vendored schema predates, inside objects that reject keys it does not list.
+// Drop an entry once a refreshed schema covers it.
+var settingsSchemaAdditions = map[string]string{
+	"sandbox.filesystem": `{"type": "object", "additionalProperties": false, "properties": {
+		"allowWrite": {"type": "array", "items": {"type": "string"}},
+		"denyWrite": {"type": "array", "items": {"type": "string"}},
+		"denyRead": {"type": "array", "items": {"type": "string"}}}}`,
+}
+
+var settingsSchema = func() *schema {
+	var s schema
+	if err := json.Unmarshal(settingsSchemaJSON, &s); err != nil {
+		panic("platform: invalid embedded settings schema: " + err.Error())
+	}
+	for path, data := range settingsSchemaAdditions {
+		parent := &s
+		keys := strings.Split(path, ".")
+		for _, key := range keys[:len(keys)-1] {
+			parent = parent.Properties[key]
+		}
+		var add schema
+		if err := json.Unmarshal([]byte(data), &add); err != nil {
+			panic("platform: invalid settings schema addition " + path + ": " + err.Error())
+		}
+		if _, ok := parent.Properties[keys[len(keys)-1]]; !ok {
+			parent.Properties[keys[len(keys)-1]] = &add
+		}
+	}
+	return &s
+}()
+
+// schemaPatterns caches compiled pattern and patternProperties regexps; a
+// nil entry is a pattern Go cannot compile (e.g. one with a lookahead), which
+// is not checked.
+var schemaPatterns sync.Map
+
+func schemaPattern(pattern string) *regexp.Regexp {
+	if re, ok := schemaPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	schemaPatterns.Store(pattern, re)
+	return re
+}
+
+// SchemaViolation is a value in a settings file that the schema rejects.
+type SchemaViolation struct {
+	Path    string // e.g. "permissions.allow[2]"; empty for the whole document
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// InvalidSettingsError is returned by WriteSettingsFile and
+// WriteSettingsData when the content would break the settings schema.
+type InvalidSettingsError struct {
+	File       string
+	Violations []SchemaViolation
+}
+
+func (e *InvalidSettingsError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return fmt.Sprintf("refusing to write invalid settings to %s: %s", e.File, strings.Join(parts, "; "))
+}
+
+// ValidateSettings checks a settings.json document against the embedded
+// claude-code-settings schema and returns every violation, ordered by path.
+// The schema allows top-level settings it does not describe, since Claude
+// Code ignores them.
+func ValidateSettings(data []byte) []SchemaViolation {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []SchemaViolation{{Message: "not valid JSON: " + err.Error()}}
+	}
+	var violations []SchemaViolation
+	settingsSchema.validate(settingsSchema, "", doc, &violations)
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+	return violations
+}
+
+// WriteSettingsFile marshals v and writes it to path like WriteJSONFile,
+// unless the result breaks the settings schema: then nothing is written and
+// an *InvalidSettingsError names each violating path.
+func WriteSettingsFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	return WriteSettingsData(path, append(data, '\n'))
+}
+
+// WriteSettingsData writes settings content to path as is, keeping its
+// formatting, unless it breaks the settings schema.
+func WriteSettingsData(path string, data []byte) error {
+	if violations := ValidateSettings(data); len(violations) > 0 {
+		return &InvalidSettingsError{File: path, Violations: violations}
+	}
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return WriteFileAtomic(path, data, perm)
+}
+
+// validate appends to violations each way value, at path, breaks s. root
+// resolves $ref.
+func (s *schema) validate(root *schema, path string, value interface{}, violations *[]SchemaViolation) {
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			fail("is not allowed here")
+		}
+		return
+	}
+	if s.Ref != "" {
+		if ref := root.resolve(s.Ref); ref != nil {
+			ref.validate(root, path, value, violations)
+		}
+		return
+	}
+
+	if types := s.types(); len(types) > 0 && !matchesAny(types, value) {
+		fail("must be %s, not %s", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of %s, not %s", enumList(s.Enum), compactJSON(value))
+		return
+	}
+	if len(s.Const) > 0 {
+		var want interface{}
+		if err := json.Unmarshal(s.Const, &want); err == nil && !reflect.DeepEqual(want, value) {
+			fail("must be %s, not %s", compactJSON(want), compactJSON(value))
+			return
+		}
+	}
+	if len(s.AnyOf) > 0 {
+		// Report the alternative value comes closest to, so a hook with a
+		// mistyped "type" is told about the type rather than every form.
+		var best []SchemaViolation
+		for i, alt := range s.AnyOf {
+			var vs []SchemaViolation
+			alt.validate(root, path, value, &vs)
+			if len(vs) == 0 {
+				best = nil
+				break
+			}
+			if i == 0 || len(vs) < len(best) {
+				best = vs
+			}
+		}
+		if len(best) > 0 {
+			*violations = append(*violations, best...)
+			return
+		}
+	}
+	if s.Not != nil {
+		var vs []SchemaViolation
+		s.Not.validate(root, path, value, &vs)
+		if len(vs) == 0 {
+			fail("is not allowed here")
+			return
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			fail("must be more than %v", *s.ExclusiveMinimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	case string:
+		if s.MinLength != nil && utf8.RuneCountInString(v) < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.Pattern != "" {
+			if re := schemaPattern(s.Pattern); re != nil && !re.MatchString(v) {
+				fail("must match %s", s.Pattern)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.UniqueItems {
+			seen := make(map[string]bool, len(v))
+			for _, item := range v {
+				key := compactJSON(item)
+				if seen[key] {
+					fail("has duplicate item %s", key)
+					break
+				}
+				seen[key] = true
+			}
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				fail("missing required %q", key)
+			}
+		}
+		for key, item := range v {
+			for _, child := range s.propertySchemas(key) {
+				child.validate(root, joinPath(path, key), item, violations)
+			}
+		}
+	}
+}
+
+// resolve returns the definition a local $ref such as "#/$defs/hook" names,
+// or nil.
+func (s *schema) resolve(ref string) *schema {
+	if name, ok := strings.CutPrefix(ref, "#/$defs/"); ok {
+		return s.Defs[name]
+	}
+	if name, ok := strings.CutPrefix(ref, "#/definitions/"); ok {
+		return s.Definitions[name]
+	}
+	return nil
+}
+
+// propertySchemas returns the schemas an object property named key must
+// match: its properties entry, else those of the patternProperties it
+// matches, else additionalProperties.
+func (s *schema) propertySchemas(key string) []*schema {
+	if child := s.Properties[key]; child != nil {
+		return []*schema{child}
+	}
+	var matched []*schema
+	for pattern, child := range s.PatternProperties {
+		if re := schemaPattern(pattern); re != nil && re.MatchString(key) {
+			matched = append(matched, child)
+		}
+	}
+	if len(matched) == 0 && s.AdditionalProperties != nil {
+		matched = append(matched, s.AdditionalProperties)
+	}
+	return matched
+}
+
+// types returns the JSON types s allows, or nil when any is allowed.
+func (s *schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// matchesAny reports whether value is of one of the JSON types.
+func matchesAny(types []string, value interface{}) bool {
+	for _, t := range types {
+		if t == jsonType(value) || (t == "number" && jsonType(value) == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = compactJSON(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func compactJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// joinPath appends key to a violation path, quoting keys that would be
+// ambiguous in dotted form.
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]\" ") || key == "" {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
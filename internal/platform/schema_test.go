@@ -0,0 +1,86 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSettings_Template(t *testing.T) {
+	data, err := os.ReadFile("../../_template/project/.claude/settings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := ValidateSettings(data); len(v) > 0 {
+		t.Errorf("template settings.json: %v", v)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string // violation paths, in order
+	}{
+		{"empty", `{}`, nil},
+		{"unknown keys allowed", `{"someFutureSetting": {"x": 1}}`, nil},
+		{"valid", `{"model":"sonnet","env":{"A":"1"},"permissions":{"allow":["Read"],"defaultMode":"plan"},` +
+			`"hooks":{"PreToolUse":[{"matcher":"Bash","hooks":[{"type":"command","command":"x"}]}]}}`, nil},
+		{"not JSON", `{`, []string{""}},
+		{"not an object", `[]`, []string{""}},
+		{"wrong type", `{"model": 4}`, []string{"model"}},
+		{"array item", `{"permissions":{"allow":["Read", 3]}}`, []string{"permissions.allow[1]"}},
+		{"enum", `{"permissions":{"defaultMode":"yolo"}}`, []string{"permissions.defaultMode"}},
+		{"env value", `{"env":{"PORT":8080}}`, []string{"env.PORT"}},
+		{"hook type", `{"hooks":{"Stop":[{"hooks":[{"type":"shell","command":"x"}]}]}}`, []string{"hooks.Stop[0].hooks[0].type"}},
+		{"prompt hook", `{"hooks":{"Stop":[{"hooks":[{"type":"prompt","prompt":"check"}]}]}}`, nil},
+		{"unknown nested key", `{"permissions":{"alow":["Read"]}}`, []string{"permissions.alow"}},
+		{"duplicate item", `{"permissions":{"allow":["Read","Read"]}}`, []string{"permissions.allow"}},
+		{"sandbox filesystem", `{"sandbox":{"filesystem":{"allowWrite":["/tmp"]}}}`, nil},
+		{"required", `{"hooks":{"Stop":[{"matcher":""}]}}`, []string{"hooks.Stop[0]"}},
+		{"several", `{"model":1,"env":{"a.b":2}}`, []string{`env["a.b"]`, "model"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range ValidateSettings([]byte(tt.data)) {
+				got = append(got, v.Path)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("violation paths = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSettingsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteSettingsFile(path, map[string]interface{}{"permissions": map[string]interface{}{"deny": "Bash"}})
+	var invalid *InvalidSettingsError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("err = %v, want an *InvalidSettingsError", err)
+	}
+	if !strings.Contains(err.Error(), "permissions.deny: must be array, not string") {
+		t.Errorf("err = %v, want the violating path", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{}\n" {
+		t.Errorf("invalid settings were written: %s", data)
+	}
+
+	if err := WriteSettingsFile(path, map[string]interface{}{"model": "opus"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"model": "opus"`) {
+		t.Errorf("settings = %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want the existing 0600 kept", info.Mode().Perm())
+	}
+}
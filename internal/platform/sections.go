@@ -0,0 +1,217 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratedSections are the headings of the CLAUDE.md sections enrich can
+// regenerate on their own, in the order they are written. Their generated
+// bodies are wrapped in begin and end markers (see SectionMarkers) so a later
+// "enrich --section" replaces only what it wrote.
+var GeneratedSections = []string{"Key Directories", "Conventions", "Important Files"}
+
+// SectionKey returns the key that names heading on the command line and in
+// its markers: "Key Directories" is "key-directories".
+func SectionKey(heading string) string {
+	return strings.ToLower(strings.ReplaceAll(heading, " ", "-"))
+}
+
+// SectionHeading returns the generated section whose key is key, or "".
+func SectionHeading(key string) string {
+	for _, h := range GeneratedSections {
+		if SectionKey(h) == key {
+			return h
+		}
+	}
+	return ""
+}
+
+// SectionMarkers returns the comment lines that enclose the generated body of
+// the section heading.
+func SectionMarkers(heading string) (begin, end string) {
+	key := SectionKey(heading)
+	return "<!-- claude-workspace:begin " + key + " -->", "<!-- claude-workspace:end " + key + " -->"
+}
+
+// SetGeneratedSection replaces the generated body of the section heading with
+// body, leaving every other byte of content as it is. Within a marked section
+// only the text between the markers changes, so notes added after the end
+// marker survive. An unmarked section has everything between its heading and
+// the next one replaced by the marked body. A missing section is added as
+// SetMarkdownSection adds one.
+func SetGeneratedSection(content, heading, body string) string {
+	begin, end := SectionMarkers(heading)
+	body = strings.TrimRight(body, "\n") + "\n"
+	if i := markerIndex(content, begin); i >= 0 && i+len(begin) < len(content) {
+		start := i + len(begin) + 1
+		if j := markerIndex(content[start:], end); j >= 0 {
+			return content[:start] + body + content[start+j:]
+		}
+	}
+	block := begin + "\n" + body + end + "\n"
+	start, stop, ok := sectionBounds(content, heading)
+	if !ok {
+		return SetMarkdownSection(content, heading, "## "+heading+"\n"+block)
+	}
+	old := content[start:stop]
+	// Keep the blank lines that separate the section from the next heading.
+	trailing := strings.TrimPrefix(old[len(strings.TrimRight(old, "\n")):], "\n")
+	if trailing == "" && stop < len(content) {
+		trailing = "\n"
+	}
+	return content[:start] + block + trailing + content[stop:]
+}
+
+// MarkGeneratedSections wraps the body of each of GeneratedSections in
+// content that is not marked yet, as after claude has written the whole file.
+// Empty sections are left alone.
+func MarkGeneratedSections(content string) string {
+	for _, heading := range GeneratedSections {
+		begin, _ := SectionMarkers(heading)
+		start, stop, ok := sectionBounds(content, heading)
+		if !ok || markerIndex(content, begin) >= 0 {
+			continue
+		}
+		if body := strings.TrimSpace(content[start:stop]); body != "" {
+			content = SetGeneratedSection(content, heading, body)
+		}
+	}
+	return content
+}
+
+// sectionBounds returns the offsets of the body of the section heading in
+// content: from the line after "## heading" to the next "## " heading or the
+// end of content.
+func sectionBounds(content, heading string) (start, stop int, ok bool) {
+	marker := "## " + heading
+	at := headingIndex(content, marker)
+	if at < 0 {
+		return 0, 0, false
+	}
+	nl := strings.IndexByte(content[at:], '\n')
+	if nl < 0 {
+		return len(content), len(content), true
+	}
+	start = at + nl + 1
+	stop = len(content)
+	if next := headingIndex(content[start:], "## "); next >= 0 {
+		stop = start + next
+	}
+	return start, stop, true
+}
+
+// markerIndex returns the offset of the line of content that is marker, or -1.
+func markerIndex(content, marker string) int {
+	for offset := 0; offset < len(content); {
+		line, _, _ := strings.Cut(content[offset:], "\n")
+		if line == marker {
+			return offset
+		}
+		offset += len(line) + 1
+	}
+	return -1
+}
+
+// sectionBody returns the body of the section heading in content, without
+// its markers, or "" if there is no such section.
+func sectionBody(content, heading string) string {
+	start, stop, ok := sectionBounds(content, heading)
+	if !ok {
+		return ""
+	}
+	body := content[start:stop]
+	begin, end := SectionMarkers(heading)
+	if i := markerIndex(body, begin); i >= 0 {
+		body = body[i+len(begin):]
+		if j := markerIndex(body, end); j >= 0 {
+			body = body[:j]
+		}
+	}
+	return strings.TrimSpace(body)
+}
+
+// EnrichSectionsStatic regenerates only the named GeneratedSections of the
+// file at targetPath from a static analysis of projectDir, as
+// EnrichClaudeMdStatic does for all of them.
+func EnrichSectionsStatic(projectDir, targetPath string, headings []string) error {
+	var sections staticSections
+	for _, h := range headings {
+		switch h {
+		case "Key Directories":
+			sections.directories = true
+		case "Important Files":
+			sections.files = true
+		case "Conventions":
+			sections.conventions, sections.commits = true, true
+		}
+	}
+	return enrichStatic(projectDir, targetPath, sections)
+}
+
+// EnrichSections runs claude opus to regenerate only the named
+// GeneratedSections of the file at targetPath. The rest of the file is kept
+// byte for byte.
+func EnrichSections(projectDir, targetPath string, headings []string) error {
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", filepath.Base(targetPath), err)
+	}
+	stdout, stderr, err := RunClaudeAnalysis(projectDir, BuildSectionsPrompt(projectDir, targetPath, headings))
+	if err != nil {
+		return err
+	}
+	content := string(data)
+	for _, h := range headings {
+		body := sectionBody(stdout, h)
+		if body == "" {
+			if stderr != "" {
+				return fmt.Errorf("enrichment produced no %s section (stderr: %s)", h, stderr)
+			}
+			return fmt.Errorf("enrichment produced no %s section", h)
+		}
+		content = SetGeneratedSection(content, h, body)
+	}
+	if err := os.WriteFile(targetPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing enriched file: %w", err)
+	}
+	relPath, _ := filepath.Rel(projectDir, targetPath)
+	PrintSuccess(os.Stdout, fmt.Sprintf("Regenerated %s in %s", strings.Join(headings, ", "), relPath))
+	return nil
+}
+
+// sectionGuidance is what the enrichment prompt asks for in each of
+// GeneratedSections.
+var sectionGuidance = map[string]string{
+	"Key Directories": "- <dir>/ - <description>\n(list actual directories found in the project)",
+	"Conventions":     "- <convention discovered from code>\n(e.g., naming patterns, file organization, import style, error handling patterns)",
+	"Important Files": "- <file path> - <why it matters>\n(list 5-10 files a new developer should read first)",
+}
+
+// BuildSectionsPrompt constructs the LLM prompt for regenerating only the
+// named sections of the CLAUDE.md at targetPath.
+func BuildSectionsPrompt(projectDir, targetPath string, headings []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `You are analyzing a software project to update selected sections of its CLAUDE.md file.
+
+The project is located at: %s
+The current CLAUDE.md is at: %s
+
+Your task:
+1. Read the current CLAUDE.md at the path above for context
+2. Explore the project: README, dependency files, directory layout, config files, and source files
+3. Output ONLY raw markdown (no code fences, no explanations, no preamble) containing exactly these sections, in this order:
+`, projectDir, targetPath)
+	for _, h := range headings {
+		fmt.Fprintf(&sb, "\n## %s\n%s\n", h, sectionGuidance[h])
+	}
+	sb.WriteString(`
+Rules:
+- Output only the sections listed above; the rest of the file is kept as it is
+- Only include information you can verify from the project files
+- Do not hallucinate or guess — if unsure, omit the item
+- Output raw markdown only — no wrapping code fences, no commentary`)
+	return sb.String()
+}
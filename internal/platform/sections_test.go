@@ -0,0 +1,86 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetGeneratedSection(t *testing.T) {
+	marked := "# P\n\n## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- old/\n<!-- claude-workspace:end key-directories -->\nMy note.\n\n## Notes\nkeep  \n"
+	tests := []struct {
+		name, content, want string
+	}{
+		{
+			name:    "marked section keeps text outside the markers",
+			content: marked,
+			want:    "# P\n\n## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- new/\n<!-- claude-workspace:end key-directories -->\nMy note.\n\n## Notes\nkeep  \n",
+		},
+		{
+			name:    "unmarked section body is replaced and marked",
+			content: "# P\n\n## Key Directories\n<!-- Map your dirs -->\n\n\n## Notes\nkeep\n",
+			want:    "# P\n\n## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- new/\n<!-- claude-workspace:end key-directories -->\n\n\n## Notes\nkeep\n",
+		},
+		{
+			name:    "empty section before the next heading",
+			content: "## Key Directories\n## Notes\n",
+			want:    "## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- new/\n<!-- claude-workspace:end key-directories -->\n\n## Notes\n",
+		},
+		{
+			name:    "last section",
+			content: "## Notes\nx\n\n## Key Directories\n- old/",
+			want:    "## Notes\nx\n\n## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- new/\n<!-- claude-workspace:end key-directories -->\n",
+		},
+		{
+			name:    "missing section is added",
+			content: "# P\n",
+			want:    "# P\n\n## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- new/\n<!-- claude-workspace:end key-directories -->\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SetGeneratedSection(tt.content, "Key Directories", "- new/\n"); got != tt.want {
+				t.Errorf("SetGeneratedSection() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkGeneratedSections(t *testing.T) {
+	in := "# P\n\n## Key Directories\n- cmd/\n\n## Conventions\n\n## Important Files\n- go.mod\n"
+	got := MarkGeneratedSections(in)
+	want := "# P\n\n## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- cmd/\n<!-- claude-workspace:end key-directories -->\n\n## Conventions\n\n## Important Files\n<!-- claude-workspace:begin important-files -->\n- go.mod\n<!-- claude-workspace:end important-files -->\n"
+	if got != want {
+		t.Errorf("MarkGeneratedSections() =\n%q\nwant\n%q", got, want)
+	}
+	if again := MarkGeneratedSections(got); again != got {
+		t.Errorf("MarkGeneratedSections is not idempotent:\n%q", again)
+	}
+}
+
+func TestSectionBody(t *testing.T) {
+	out := "Here you go:\n\n## Conventions\n<!-- claude-workspace:begin conventions -->\n- Tabs\n<!-- claude-workspace:end conventions -->\n\n## Important Files\n- main.go - Entry point\n"
+	if got := sectionBody(out, "Conventions"); got != "- Tabs" {
+		t.Errorf("sectionBody(Conventions) = %q", got)
+	}
+	if got := sectionBody(out, "Important Files"); got != "- main.go - Entry point" {
+		t.Errorf("sectionBody(Important Files) = %q", got)
+	}
+	if got := sectionBody(out, "Key Directories"); got != "" {
+		t.Errorf("sectionBody(Key Directories) = %q, want empty", got)
+	}
+}
+
+func TestSectionKeys(t *testing.T) {
+	for _, h := range GeneratedSections {
+		if got := SectionHeading(SectionKey(h)); got != h {
+			t.Errorf("SectionHeading(SectionKey(%q)) = %q", h, got)
+		}
+	}
+	if got := SectionHeading("notes"); got != "" {
+		t.Errorf("SectionHeading(notes) = %q, want empty", got)
+	}
+	prompt := BuildSectionsPrompt("/p", "/p/.claude/CLAUDE.md", []string{"Conventions"})
+	if !strings.Contains(prompt, "## Conventions\n") || strings.Contains(prompt, "## Key Directories") {
+		t.Errorf("prompt does not ask for just Conventions:\n%s", prompt)
+	}
+}
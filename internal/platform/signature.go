@@ -0,0 +1,62 @@
+package platform
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// VerifySignature checks a base64 cosign signature of data against key.
+// ECDSA keys (the cosign default) sign the SHA-256 of data; Ed25519 keys sign
+// data directly.
+func VerifySignature(key string, data, sig []byte) error {
+	pub, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(pub, digest[:], raw) {
+			return errors.New("signature does not match")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, raw) {
+			return errors.New("signature does not match")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil
+}
+
+// parsePublicKey parses a PKIX public key given as PEM or base64 DER.
+func parsePublicKey(key string) (crypto.PublicKey, error) {
+	key = strings.TrimSpace(key)
+	var der []byte
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		der = block.Bytes
+	} else {
+		var err error
+		if der, err = base64.StdEncoding.DecodeString(key); err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+	return pub, nil
+}
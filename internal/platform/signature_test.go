@@ -0,0 +1,45 @@
+package platform
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform/signtest"
+)
+
+func TestVerifySignatureKeyFormats(t *testing.T) {
+	data := []byte("checksums")
+
+	priv, pemKey := signtest.Key(t)
+	sig := signtest.Signer(t, priv)(data)
+	block, _ := pem.Decode([]byte(pemKey))
+	for name, key := range map[string]string{
+		"pem":        pemKey,
+		"base64 der": base64.StdEncoding.EncodeToString(block.Bytes),
+	} {
+		if err := VerifySignature(key, data, sig); err != nil {
+			t.Errorf("VerifySignature(%s) error = %v", name, err)
+		}
+	}
+	if err := VerifySignature(pemKey, []byte("tampered"), sig); err == nil {
+		t.Error("VerifySignature() accepted tampered data")
+	}
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(edPub)
+	edSig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(edPriv, data)))
+	if err := VerifySignature(base64.StdEncoding.EncodeToString(der), data, edSig); err != nil {
+		t.Errorf("VerifySignature(ed25519) error = %v", err)
+	}
+
+	if err := VerifySignature("not a key", data, sig); err == nil {
+		t.Error("VerifySignature() accepted an invalid key")
+	}
+}
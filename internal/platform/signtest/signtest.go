@@ -0,0 +1,41 @@
+// Package signtest provides cosign-style signing keys for tests of
+// signature verification.
+package signtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+)
+
+// Key returns a cosign-style ECDSA P-256 key pair, the public key as PEM.
+func Key(t testing.TB) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// Signer returns a function that signs data with priv as cosign sign-blob
+// does: a base64 ASN.1 signature of its SHA-256.
+func Signer(t testing.TB, priv *ecdsa.PrivateKey) func(data []byte) []byte {
+	return func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []byte(base64.StdEncoding.EncodeToString(sig))
+	}
+}
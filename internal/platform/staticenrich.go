@@ -0,0 +1,713 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Limits on how much of a project the static analysis reports, so the
+// sections stay about as long as the AI-enriched ones.
+const (
+	maxKeyDirectories  = 30
+	maxChildren        = 12
+	maxImportantFiles  = 20
+	maxWorkflowFiles   = 5
+	maxSummaryDepth    = 3
+	commitSampleSize   = 100
+	minCommitsForStyle = 5
+)
+
+// skipDirs are directories that hold dependencies or build output rather than
+// project code.
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true, "target": true,
+	"out": true, "bin": true, "obj": true, "coverage": true, "tmp": true,
+	"__pycache__": true, "venv": true, "site-packages": true,
+}
+
+// dirPurposes describes directories by their conventional name.
+var dirPurposes = map[string]string{
+	".github":     "GitHub workflows and repository settings",
+	"api":         "API definitions",
+	"app":         "Application code",
+	"apps":        "Applications",
+	"assets":      "Static assets",
+	"benchmarks":  "Benchmarks",
+	"cmd":         "Command entry points",
+	"components":  "UI components",
+	"config":      "Configuration",
+	"configs":     "Configuration",
+	"deploy":      "Deployment configuration",
+	"deployments": "Deployment configuration",
+	"docs":        "Documentation",
+	"e2e":         "End-to-end tests",
+	"examples":    "Examples",
+	"fixtures":    "Test fixtures",
+	"helm":        "Helm charts",
+	"infra":       "Infrastructure as code",
+	"internal":    "Private packages",
+	"k8s":         "Kubernetes manifests",
+	"lib":         "Library code",
+	"libs":        "Shared libraries",
+	"migrations":  "Database migrations",
+	"packages":    "Workspace packages",
+	"pages":       "Page routes",
+	"pkg":         "Library packages",
+	"public":      "Static files served as-is",
+	"scripts":     "Development and CI scripts",
+	"services":    "Services",
+	"spec":        "Tests",
+	"src":         "Application source code",
+	"static":      "Static assets",
+	"templates":   "Templates",
+	"terraform":   "Terraform configuration",
+	"test":        "Tests",
+	"testdata":    "Test fixtures",
+	"tests":       "Tests",
+	"tools":       "Developer tooling",
+	"web":         "Web front-end",
+	"__tests__":   "Tests",
+}
+
+// containerDirs hold one subdirectory per component, so their children are
+// listed as well.
+var containerDirs = map[string]bool{
+	"apps": true, "cmd": true, "internal": true, "libs": true, "packages": true,
+	"pkg": true, "services": true, "src": true,
+}
+
+// languageExts maps source file extensions to the language named in
+// directory summaries.
+var languageExts = map[string]string{
+	".go": "Go", ".ts": "TypeScript", ".tsx": "TypeScript", ".js": "JavaScript", ".jsx": "JavaScript",
+	".mjs": "JavaScript", ".py": "Python", ".rs": "Rust", ".java": "Java", ".kt": "Kotlin",
+	".rb": "Ruby", ".php": "PHP", ".cs": "C#", ".swift": "Swift", ".c": "C", ".h": "C",
+	".cpp": "C++", ".cc": "C++", ".hpp": "C++", ".scala": "Scala", ".ex": "Elixir", ".exs": "Elixir",
+	".md": "Markdown", ".sql": "SQL", ".sh": "Shell", ".proto": "Protocol Buffers", ".tf": "Terraform",
+	".yaml": "YAML", ".yml": "YAML", ".css": "CSS", ".scss": "CSS", ".html": "HTML", ".vue": "Vue", ".svelte": "Svelte",
+}
+
+// EnrichClaudeMdStatic fills the Key Directories, Important Files, and
+// Conventions sections of the file at targetPath from a static analysis of
+// projectDir, without calling claude. Other sections are left as they are.
+// A rules/platform.md target only gets Conventions, as with BuildEnrichmentPrompt.
+func EnrichClaudeMdStatic(projectDir, targetPath string) error {
+	if strings.HasSuffix(targetPath, filepath.Join("rules", "platform.md")) {
+		return enrichStatic(projectDir, targetPath, staticSections{conventions: true, commits: true})
+	}
+	return enrichStatic(projectDir, targetPath, staticSections{directories: true, files: true, conventions: true, commits: true})
+}
+
+// EnrichPackageClaudeMdStatic is EnrichClaudeMdStatic for the CLAUDE.md of a
+// monorepo member. The commit style is left to the root CLAUDE.md.
+func EnrichPackageClaudeMdStatic(pkgDir, targetPath string) error {
+	return enrichStatic(pkgDir, targetPath, staticSections{directories: true, files: true, conventions: true})
+}
+
+// staticSections selects what enrichStatic writes.
+type staticSections struct {
+	directories, files, conventions, commits bool
+}
+
+func enrichStatic(projectDir, targetPath string, sections staticSections) error {
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", filepath.Base(targetPath), err)
+	}
+	content := string(data)
+	if sections.conventions {
+		conventions := projectConventions(projectDir)
+		if sections.commits {
+			conventions = append(conventions, commitStyle(gitSubjects(projectDir))...)
+		}
+		content = setListSection(content, "Conventions", conventions, "")
+	}
+	if sections.directories {
+		content = setListSection(content, "Key Directories", keyDirectories(projectDir), "")
+	}
+	if sections.files {
+		content = setListSection(content, "Important Files", importantFiles(projectDir), "Important Notes")
+	}
+	if err := os.WriteFile(targetPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing enriched file: %w", err)
+	}
+	relPath, _ := filepath.Rel(projectDir, targetPath)
+	PrintSuccess(os.Stdout, fmt.Sprintf("Enriched %s from static analysis", relPath))
+	return nil
+}
+
+// setListSection replaces the generated body of the section named heading
+// with a bullet list of items (see SetGeneratedSection). A missing section
+// goes before the one named before, if there is one. With no items, content
+// is returned unchanged.
+func setListSection(content, heading string, items []string, before string) string {
+	if len(items) == 0 {
+		return content
+	}
+	list := "- " + strings.Join(items, "\n- ") + "\n"
+	if before != "" && headingIndex(content, "## "+heading) < 0 {
+		if at := headingIndex(content, "## "+before); at >= 0 {
+			section := SetGeneratedSection("## "+heading+"\n", heading, list)
+			return content[:at] + section + "\n" + content[at:]
+		}
+	}
+	return SetGeneratedSection(content, heading, list)
+}
+
+// keyDirectories returns one "dir/ - description" line for each notable
+// directory of the project. Directories named in containerDirs also have
+// their subdirectories listed. A directory is described by its Go package
+// comment, its conventional name, or the language of the files in it.
+func keyDirectories(projectDir string) []string {
+	var lines []string
+	for _, name := range listDirs(projectDir) {
+		if len(lines) >= maxKeyDirectories {
+			break
+		}
+		dir := filepath.Join(projectDir, name)
+		desc := goPackageSynopsis(dir)
+		if desc == "" {
+			desc = dirPurposes[name]
+		}
+		if desc == "" {
+			desc = summarizeDir(dir)
+		}
+		if desc == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s/ - %s", name, desc))
+		if !containerDirs[name] {
+			continue
+		}
+		children := listDirs(dir)
+		for i, child := range children {
+			if i == maxChildren {
+				lines = append(lines, fmt.Sprintf("%s/... - %d more", name, len(children)-i))
+				break
+			}
+			childDir := filepath.Join(dir, child)
+			desc := goPackageSynopsis(childDir)
+			if desc == "" && name == "cmd" && hasGlobMatch(childDir, "*.go") {
+				desc = fmt.Sprintf("Entry point for the %s command", child)
+			}
+			if desc == "" {
+				desc = dirPurposes[child]
+			}
+			if desc == "" {
+				desc = summarizeDir(childDir)
+			}
+			if desc != "" {
+				lines = append(lines, fmt.Sprintf("%s/%s/ - %s", name, child, desc))
+			}
+		}
+	}
+	return lines
+}
+
+// listDirs returns the sorted subdirectories of dir, leaving out hidden
+// directories (except .github) and those in skipDirs.
+func listDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || skipDirs[name] || (strings.HasPrefix(name, ".") && name != ".github") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// goPackageSynopsis returns the first sentence of the package comment of the
+// Go package in dir, without its "Package name" prefix, or "".
+func goPackageSynopsis(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || f.Doc == nil {
+			continue
+		}
+		text := strings.Join(strings.Fields(f.Doc.Text()), " ")
+		if end := strings.Index(text, ". "); end >= 0 {
+			text = text[:end+1]
+		}
+		for _, prefix := range []string{"Package " + f.Name.Name + " ", "Command " + f.Name.Name + " "} {
+			text = strings.TrimPrefix(text, prefix)
+		}
+		text = strings.TrimSuffix(text, ".")
+		if len(text) > 100 {
+			cut := strings.LastIndex(text[:97], " ")
+			text = strings.TrimRight(text[:cut], ",;:") + "..."
+		}
+		if text == "" {
+			continue
+		}
+		r := []rune(text)
+		r[0] = unicode.ToUpper(r[0])
+		return string(r)
+	}
+	return ""
+}
+
+// summarizeDir describes dir by the language most of its files are written
+// in, e.g. "Python (12 files)". It returns "" for a directory without files.
+func summarizeDir(dir string) string {
+	counts := map[string]int{}
+	total := 0
+	var walk func(path string, depth int)
+	walk = func(path string, depth int) {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				if depth < maxSummaryDepth && !skipDirs[e.Name()] && !strings.HasPrefix(e.Name(), ".") {
+					walk(filepath.Join(path, e.Name()), depth+1)
+				}
+				continue
+			}
+			total++
+			if lang := languageExts[strings.ToLower(filepath.Ext(e.Name()))]; lang != "" {
+				counts[lang]++
+			}
+		}
+	}
+	walk(dir, 1)
+	if total == 0 {
+		return ""
+	}
+	best := ""
+	for lang, n := range counts {
+		if n > counts[best] || (n == counts[best] && lang < best) {
+			best = lang
+		}
+	}
+	files := fmt.Sprintf("%d files", total)
+	if total == 1 {
+		files = "1 file"
+	}
+	if best == "" {
+		return files
+	}
+	return fmt.Sprintf("%s (%s)", best, files)
+}
+
+// importantFile is a file worth reading first, and why.
+type importantFile struct {
+	pattern string // path or glob relative to the project root
+	desc    string
+}
+
+// importantFileCandidates are listed in this order: docs, entry points,
+// manifests, build and container files, configuration, then CI.
+var importantFileCandidates = []importantFile{
+	{"README.md", "Project overview"},
+	{"CONTRIBUTING.md", "Contribution guidelines"},
+	{"ARCHITECTURE.md", "Architecture overview"},
+	{"main.go", "Program entry point"},
+	{"cmd/*/main.go", "Entry point for the command"},
+	{"src/main.rs", "Binary crate entry point"},
+	{"src/lib.rs", "Library crate root"},
+	{"src/index.ts", "Module entry point"},
+	{"src/index.js", "Module entry point"},
+	{"src/main.ts", "Application entry point"},
+	{"src/main.tsx", "Application entry point"},
+	{"index.ts", "Module entry point"},
+	{"index.js", "Module entry point"},
+	{"main.py", "Program entry point"},
+	{"app.py", "Application entry point"},
+	{"manage.py", "Django management entry point"},
+	{"go.mod", "Go module definition and dependencies"},
+	{"package.json", "Package manifest, scripts, and dependencies"},
+	{"Cargo.toml", "Crate manifest and dependencies"},
+	{"pyproject.toml", "Python project metadata and tool settings"},
+	{"requirements.txt", "Python dependencies"},
+	{"Gemfile", "Ruby dependencies"},
+	{"pom.xml", "Maven build and dependencies"},
+	{"build.gradle", "Gradle build and dependencies"},
+	{"build.gradle.kts", "Gradle build and dependencies"},
+	{"composer.json", "PHP dependencies"},
+	{"mix.exs", "Mix project and dependencies"},
+	{"Makefile", "Build and development tasks"},
+	{"justfile", "Build and development tasks"},
+	{"Taskfile.yml", "Build and development tasks"},
+	{"Dockerfile", "Container image build"},
+	{"docker-compose.yml", "Local service stack"},
+	{"compose.yaml", "Local service stack"},
+	{"tsconfig.json", "TypeScript compiler settings"},
+	{".env.example", "Environment variables the application reads"},
+	{".github/workflows/*.yml", "CI workflow"},
+	{".github/workflows/*.yaml", "CI workflow"},
+	{".gitlab-ci.yml", "GitLab CI pipeline"},
+	{".circleci/config.yml", "CircleCI pipeline"},
+	{"Jenkinsfile", "Jenkins pipeline"},
+	{"azure-pipelines.yml", "Azure Pipelines definition"},
+	{"bitbucket-pipelines.yml", "Bitbucket Pipelines definition"},
+}
+
+// importantFiles returns one "path - description" line for each entry
+// point, manifest, build or config file, and CI definition in the project.
+func importantFiles(projectDir string) []string {
+	var lines []string
+	workflows := 0
+	for _, f := range importantFileCandidates {
+		matches, _ := filepath.Glob(filepath.Join(projectDir, filepath.FromSlash(f.pattern)))
+		sort.Strings(matches)
+		for _, path := range matches {
+			if len(lines) >= maxImportantFiles {
+				return lines
+			}
+			rel, _ := filepath.Rel(projectDir, path)
+			rel = filepath.ToSlash(rel)
+			desc := f.desc
+			switch {
+			case strings.HasPrefix(f.pattern, "cmd/"):
+				desc = fmt.Sprintf("Entry point for the %s command", filepath.Base(filepath.Dir(path)))
+			case strings.HasPrefix(f.pattern, ".github/workflows/"):
+				if workflows++; workflows > maxWorkflowFiles {
+					continue
+				}
+				if name := yamlTopLevelValue(path, "name"); name != "" {
+					desc = fmt.Sprintf("CI workflow: %s", name)
+				}
+			}
+			lines = append(lines, fmt.Sprintf("%s - %s", rel, desc))
+		}
+	}
+	return lines
+}
+
+// yamlTopLevelValue returns the unquoted value of the top-level "key:" line
+// of the YAML file at path, or "".
+func yamlTopLevelValue(path, key string) string {
+	value := ""
+	forEachLine(path, func(line string) {
+		if v, ok := strings.CutPrefix(line, key+":"); ok && value == "" {
+			value = UnquoteYAML(strings.TrimSpace(v))
+		}
+	})
+	return value
+}
+
+// toolConfig is a linter, formatter, or hook manager found by its config file.
+type toolConfig struct {
+	files []string // first one present is reported
+	desc  string   // %s is the config file
+}
+
+var toolConfigs = []toolConfig{
+	{[]string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}, "Go code is linted with golangci-lint (%s)"},
+	{[]string{"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts", ".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml"}, "JavaScript/TypeScript is linted with ESLint (%s)"},
+	{[]string{"biome.json", "biome.jsonc"}, "Code is linted and formatted with Biome (%s)"},
+	{[]string{"ruff.toml", ".ruff.toml"}, "Python is linted with Ruff (%s)"},
+	{[]string{".flake8"}, "Python is linted with Flake8 (%s)"},
+	{[]string{".pylintrc", "pylintrc"}, "Python is linted with Pylint (%s)"},
+	{[]string{"mypy.ini", ".mypy.ini"}, "Python is type-checked with mypy (%s)"},
+	{[]string{".rubocop.yml"}, "Ruby is linted with RuboCop (%s)"},
+	{[]string{"clippy.toml", ".clippy.toml"}, "Rust is linted with Clippy (%s)"},
+	{[]string{".swiftlint.yml"}, "Swift is linted with SwiftLint (%s)"},
+	{[]string{".stylelintrc", ".stylelintrc.json", ".stylelintrc.js", "stylelint.config.js"}, "Styles are linted with Stylelint (%s)"},
+	{[]string{".markdownlint.json", ".markdownlint.yaml", ".markdownlint.yml", ".markdownlint.jsonc"}, "Markdown is linted with markdownlint (%s)"},
+	{[]string{".pre-commit-config.yaml"}, "pre-commit runs hooks before each commit (%s)"},
+	{[]string{"lefthook.yml", "lefthook.yaml"}, "Lefthook runs Git hooks (%s)"},
+	{[]string{".husky"}, "Husky runs Git hooks (%s/)"},
+}
+
+// projectConventions returns the conventions the project's lint, format, and
+// editor configuration files establish, and where its tests live.
+func projectConventions(projectDir string) []string {
+	var lines []string
+	for _, tc := range toolConfigs {
+		for _, name := range tc.files {
+			if FileExists(filepath.Join(projectDir, name)) {
+				lines = append(lines, fmt.Sprintf(tc.desc, name))
+				break
+			}
+		}
+	}
+	pyproject := filepath.Join(projectDir, "pyproject.toml")
+	if fileContains(pyproject, "[tool.ruff") && !hasAnyFile(projectDir, "ruff.toml", ".ruff.toml") {
+		lines = append(lines, "Python is linted with Ruff (pyproject.toml [tool.ruff])")
+	}
+	lines = append(lines, formatterConventions(projectDir)...)
+	lines = append(lines, testLayout(projectDir)...)
+	return lines
+}
+
+// formatterConventions describes the formatters the project configures and
+// the settings that differ between projects.
+func formatterConventions(projectDir string) []string {
+	var lines []string
+	if FileExists(filepath.Join(projectDir, "go.mod")) {
+		lines = append(lines, "Go code is formatted with gofmt")
+	}
+	if line := prettierConvention(projectDir); line != "" {
+		lines = append(lines, line)
+	}
+	for _, name := range []string{"rustfmt.toml", ".rustfmt.toml"} {
+		if path := filepath.Join(projectDir, name); FileExists(path) {
+			lines = append(lines, withSettings(fmt.Sprintf("Rust is formatted with rustfmt (%s)", name), tomlSettings(path, "", 4)))
+			break
+		}
+	}
+	if pyproject := filepath.Join(projectDir, "pyproject.toml"); fileContains(pyproject, "[tool.black]") {
+		lines = append(lines, withSettings("Python is formatted with Black (pyproject.toml)", tomlSettings(pyproject, "tool.black", 3)))
+	}
+	if path := filepath.Join(projectDir, ".clang-format"); FileExists(path) {
+		line := "C/C++ is formatted with clang-format (.clang-format)"
+		if style := yamlTopLevelValue(path, "BasedOnStyle"); style != "" {
+			line += fmt.Sprintf(": based on the %s style", style)
+		}
+		lines = append(lines, line)
+	}
+	if path := filepath.Join(projectDir, ".editorconfig"); FileExists(path) {
+		lines = append(lines, withSettings("Editor settings come from .editorconfig", editorconfigDefaults(path)))
+	}
+	return lines
+}
+
+// prettierConvention describes the project's Prettier setup, reading its
+// settings from a JSON .prettierrc or the "prettier" key of package.json.
+func prettierConvention(projectDir string) string {
+	var settings map[string]any
+	source := ""
+	for _, name := range []string{".prettierrc", ".prettierrc.json", ".prettierrc.yml", ".prettierrc.yaml", ".prettierrc.js", ".prettierrc.cjs", ".prettierrc.mjs", "prettier.config.js", "prettier.config.mjs"} {
+		path := filepath.Join(projectDir, name)
+		if FileExists(path) {
+			source = name
+			if data, err := os.ReadFile(path); err == nil {
+				_ = json.Unmarshal(data, &settings)
+			}
+			break
+		}
+	}
+	if source == "" {
+		var pkg struct {
+			Prettier json.RawMessage `json:"prettier"`
+		}
+		data, err := os.ReadFile(filepath.Join(projectDir, "package.json"))
+		if err != nil || json.Unmarshal(data, &pkg) != nil || pkg.Prettier == nil {
+			return ""
+		}
+		source = `package.json "prettier"`
+		_ = json.Unmarshal(pkg.Prettier, &settings)
+	}
+
+	var parts []string
+	if v, ok := settings["singleQuote"].(bool); ok {
+		parts = append(parts, map[bool]string{true: "single quotes", false: "double quotes"}[v])
+	}
+	if v, ok := settings["semi"].(bool); ok {
+		parts = append(parts, map[bool]string{true: "semicolons", false: "no semicolons"}[v])
+	}
+	if v, ok := settings["useTabs"].(bool); ok && v {
+		parts = append(parts, "tabs")
+	} else if v, ok := settings["tabWidth"].(float64); ok {
+		parts = append(parts, fmt.Sprintf("%d-space indent", int(v)))
+	}
+	if v, ok := settings["printWidth"].(float64); ok {
+		parts = append(parts, fmt.Sprintf("%d-column lines", int(v)))
+	}
+	if v, ok := settings["trailingComma"].(string); ok {
+		parts = append(parts, fmt.Sprintf("trailing commas: %s", v))
+	}
+	return withSettings(fmt.Sprintf("Code is formatted with Prettier (%s)", source), parts)
+}
+
+// withSettings appends ": a, b, c" to line when there are settings.
+func withSettings(line string, settings []string) string {
+	if len(settings) == 0 {
+		return line
+	}
+	return line + ": " + strings.Join(settings, ", ")
+}
+
+// tomlSettings returns up to limit "key = value" lines of the TOML file at
+// path, from the table named table ("" for the top level).
+func tomlSettings(path, table string, limit int) []string {
+	var settings []string
+	current := ""
+	forEachLine(path, func(line string) {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "["):
+			current = strings.Trim(line, "[]")
+		case current == table && strings.Contains(line, "=") && !strings.HasPrefix(line, "#") && len(settings) < limit:
+			key, value, _ := strings.Cut(line, "=")
+			settings = append(settings, strings.TrimSpace(key)+" = "+strings.TrimSpace(value))
+		}
+	})
+	return settings
+}
+
+// editorconfigDefaults returns the settings of the [*] section of the
+// .editorconfig at path.
+func editorconfigDefaults(path string) []string {
+	var settings []string
+	inDefault := false
+	forEachLine(path, func(line string) {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "["):
+			inDefault = line == "[*]"
+		case inDefault && strings.Contains(line, "="):
+			key, value, _ := strings.Cut(line, "=")
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			switch key {
+			case "indent_style":
+				settings = append(settings, "indent with "+value+"s")
+			case "indent_size":
+				settings = append(settings, "indent size "+value)
+			case "end_of_line":
+				settings = append(settings, value+" line endings")
+			case "max_line_length":
+				settings = append(settings, "max line length "+value)
+			}
+		}
+	})
+	return settings
+}
+
+var jsTestFileRe = regexp.MustCompile(`\.(test|spec)\.[cm]?[jt]sx?$`)
+
+// testLayout describes where the project's Go, JavaScript/TypeScript, and
+// Python tests live.
+func testLayout(projectDir string) []string {
+	var goTests, jsTests, jsTestDirs, pyTests int
+	walkProject(projectDir, func(rel string, isDir bool) {
+		name := filepath.Base(rel)
+		switch {
+		case isDir && name == "__tests__":
+			jsTestDirs++
+		case isDir:
+		case strings.HasSuffix(name, "_test.go"):
+			goTests++
+		case jsTestFileRe.MatchString(name):
+			jsTests++
+		case strings.HasPrefix(name, "test_") && strings.HasSuffix(name, ".py"):
+			pyTests++
+		}
+	})
+	var lines []string
+	if goTests > 0 {
+		lines = append(lines, "Go tests sit next to the code they test, in *_test.go files")
+	}
+	switch {
+	case jsTestDirs > 0:
+		lines = append(lines, "JavaScript/TypeScript tests live in __tests__/ directories")
+	case jsTests > 0:
+		lines = append(lines, "JavaScript/TypeScript tests sit next to the code, in *.test.* or *.spec.* files")
+	}
+	if pyTests > 0 {
+		if FileExists(filepath.Join(projectDir, "tests")) {
+			lines = append(lines, "Python tests live in tests/, in test_*.py files")
+		} else {
+			lines = append(lines, "Python tests are in test_*.py files")
+		}
+	}
+	return lines
+}
+
+// walkProject calls fn with each file and directory below projectDir,
+// relative to it, skipping hidden directories and those in skipDirs.
+func walkProject(projectDir string, fn func(rel string, isDir bool)) {
+	_ = filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == projectDir {
+			return nil
+		}
+		if d.IsDir() && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(projectDir, path)
+		fn(rel, d.IsDir())
+		return nil
+	})
+}
+
+// hasAnyFile reports whether dir contains any of names.
+func hasAnyFile(dir string, names ...string) bool {
+	for _, name := range names {
+		if FileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitSubjects returns the subjects of the latest non-merge commits of the
+// repository at dir, or nil outside a repository.
+func gitSubjects(dir string) []string {
+	out, err := OutputDir(dir, "git", "log", "--no-merges", "--format=%s", "-n", fmt.Sprint(commitSampleSize))
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+var (
+	conventionalCommitRe = regexp.MustCompile(`^(feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(\([^)]*\))?!?: `)
+	ticketCommitRe       = regexp.MustCompile(`^\[?[A-Z][A-Z0-9]+-\d+\]?[: ]`)
+	tagCommitRe          = regexp.MustCompile(`^\[[^\]]+\] `)
+	prNumberCommitRe     = regexp.MustCompile(`\(#\d+\)$`)
+)
+
+// commitStyle describes the style of the commit subjects: Conventional
+// Commits, ticket keys, bracketed tags, PR numbers, and capitalization. It
+// needs a handful of subjects to go on and returns nil otherwise.
+func commitStyle(subjects []string) []string {
+	if len(subjects) < minCommitsForStyle {
+		return nil
+	}
+	share := func(match func(s string) bool) (float64, string) {
+		n, example := 0, ""
+		for _, s := range subjects {
+			if match(s) {
+				if n++; example == "" {
+					example = s
+				}
+			}
+		}
+		return float64(n) / float64(len(subjects)), example
+	}
+	example := func(s string) string {
+		if len(s) > 60 {
+			s = s[:57] + "..."
+		}
+		return "`" + s + "`"
+	}
+
+	var lines []string
+	if f, ex := share(conventionalCommitRe.MatchString); f >= 0.6 {
+		lines = append(lines, "Commit messages follow Conventional Commits, e.g. "+example(ex))
+	} else if f, ex := share(ticketCommitRe.MatchString); f >= 0.6 {
+		lines = append(lines, "Commit subjects start with a ticket key, e.g. "+example(ex))
+	} else if f, ex := share(tagCommitRe.MatchString); f >= 0.6 {
+		lines = append(lines, "Commit subjects start with a bracketed tag, e.g. "+example(ex))
+	} else if f, _ := share(func(s string) bool {
+		r := []rune(s)
+		return len(r) > 0 && unicode.IsUpper(r[0]) && !strings.HasSuffix(s, ".")
+	}); f >= 0.8 {
+		lines = append(lines, "Commit subjects are capitalized, with no trailing period")
+	}
+	if f, _ := share(prNumberCommitRe.MatchString); f >= 0.5 {
+		lines = append(lines, "Commits are squash-merged with the pull request number in the subject, e.g. (#123)")
+	}
+	return lines
+}
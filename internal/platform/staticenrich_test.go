@@ -0,0 +1,134 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStaticAnalysis(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                         "module example.com/shop\n",
+		"README.md":                      "# Shop\n",
+		"cmd/shopd/main.go":              "package main\n",
+		"internal/cart/cart.go":          "// Package cart implements the shopping cart. It is safe for concurrent use.\npackage cart\n",
+		"internal/cart/cart_test.go":     "package cart\n",
+		"internal/billing/invoice.go":    "package billing\n",
+		"web/src/app.test.ts":            "",
+		"web/src/app.ts":                 "",
+		"web/node_modules/x/index.js":    "",
+		"docs/guide.md":                  "",
+		"migrations/001_init.sql":        "",
+		".github/workflows/ci.yml":       "name: CI\non: push\n",
+		".prettierrc":                    `{"singleQuote": true, "semi": false, "tabWidth": 2}`,
+		".editorconfig":                  "root = true\n\n[*]\nindent_style = space\nindent_size = 2\n\n[Makefile]\nindent_style = tab\n",
+		"node_modules/left-pad/index.js": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	wantDirs := []string{
+		".github/ - GitHub workflows and repository settings",
+		"cmd/ - Command entry points",
+		"cmd/shopd/ - Entry point for the shopd command",
+		"docs/ - Documentation",
+		"internal/ - Private packages",
+		"internal/billing/ - Go (1 file)",
+		"internal/cart/ - Implements the shopping cart",
+		"migrations/ - Database migrations",
+		"web/ - Web front-end",
+	}
+	if got := keyDirectories(dir); !reflect.DeepEqual(got, wantDirs) {
+		t.Errorf("keyDirectories =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantDirs, "\n"))
+	}
+
+	wantFiles := []string{
+		"README.md - Project overview",
+		"cmd/shopd/main.go - Entry point for the shopd command",
+		"go.mod - Go module definition and dependencies",
+		".github/workflows/ci.yml - CI workflow: CI",
+	}
+	if got := importantFiles(dir); !reflect.DeepEqual(got, wantFiles) {
+		t.Errorf("importantFiles =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantFiles, "\n"))
+	}
+
+	wantConventions := []string{
+		"Go code is formatted with gofmt",
+		"Code is formatted with Prettier (.prettierrc): single quotes, no semicolons, 2-space indent",
+		"Editor settings come from .editorconfig: indent with spaces, indent size 2",
+		"Go tests sit next to the code they test, in *_test.go files",
+		"JavaScript/TypeScript tests sit next to the code, in *.test.* or *.spec.* files",
+	}
+	if got := projectConventions(dir); !reflect.DeepEqual(got, wantConventions) {
+		t.Errorf("projectConventions =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantConventions, "\n"))
+	}
+
+	target := filepath.Join(dir, "CLAUDE.md")
+	os.WriteFile(target, []byte(GenerateClaudeMdScaffold(dir)), 0644)
+	if err := EnrichClaudeMdStatic(dir, target); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(target)
+	content := string(data)
+	for _, want := range []string{
+		"## Conventions\n<!-- claude-workspace:begin conventions -->\n- Go code is formatted with gofmt\n",
+		"## Key Directories\n<!-- claude-workspace:begin key-directories -->\n- .github/",
+		"- web/ - Web front-end\n<!-- claude-workspace:end key-directories -->\n\n## Important Files\n<!-- claude-workspace:begin important-files -->\n- README.md - Project overview\n",
+		".github/workflows/ci.yml - CI workflow: CI\n<!-- claude-workspace:end important-files -->\n\n## Important Notes\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("enriched CLAUDE.md missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "Map your project's important directories") {
+		t.Errorf("Key Directories placeholder not replaced:\n%s", content)
+	}
+}
+
+func TestCommitStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		want     []string
+	}{
+		{
+			name:     "too few commits",
+			subjects: []string{"feat: a", "fix: b"},
+		},
+		{
+			name:     "conventional commits",
+			subjects: []string{"feat(api): add orders endpoint", "fix: handle empty cart", "chore: bump deps", "docs: fix typo", "Merge stuff"},
+			want:     []string{"Commit messages follow Conventional Commits, e.g. `feat(api): add orders endpoint`"},
+		},
+		{
+			name:     "ticket keys with PR numbers",
+			subjects: []string{"SHOP-12 Add cart (#40)", "SHOP-13: Fix totals (#41)", "[SHOP-14] Rename field (#42)", "SHOP-15 Drop table (#43)", "Update README"},
+			want: []string{
+				"Commit subjects start with a ticket key, e.g. `SHOP-12 Add cart (#40)`",
+				"Commits are squash-merged with the pull request number in the subject, e.g. (#123)",
+			},
+		},
+		{
+			name:     "capitalized",
+			subjects: []string{"Add cart", "Fix totals", "Rename field", "Drop table", "Update README"},
+			want:     []string{"Commit subjects are capitalized, with no trailing period"},
+		},
+		{
+			name:     "no pattern",
+			subjects: []string{"wip", "more", "fix.", "Stuff", "ok"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitStyle(tt.subjects); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commitStyle = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package platform
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Task is a named command defined by a project's own tooling: a justfile
+// recipe, a Makefile target, a Taskfile task, or a package.json script.
+type Task struct {
+	Name    string // e.g. "test"
+	Command string // how to run it from the project root, e.g. "make test"
+	Source  string // file it is defined in, e.g. "Makefile"
+}
+
+// taskSources are the files tasks are read from, in order of precedence: a
+// name defined in more than one keeps the first.
+var taskSources = []struct {
+	files []string
+	parse func(path string) []Task
+}{
+	{files: []string{"justfile", "Justfile", ".justfile"}, parse: justfileTasks},
+	{files: []string{"GNUmakefile", "makefile", "Makefile"}, parse: makefileTasks},
+	{files: []string{"Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml"}, parse: taskfileTasks},
+	{files: []string{"package.json"}, parse: packageScriptTasks},
+}
+
+// DetectTasks returns the tasks defined in dir, in source order.
+func DetectTasks(dir string) []Task {
+	var tasks []Task
+	seen := map[string]bool{}
+	for _, src := range taskSources {
+		for _, name := range src.files {
+			path := filepath.Join(dir, name)
+			if !FileExists(path) {
+				continue
+			}
+			for _, t := range src.parse(path) {
+				if !seen[t.Name] {
+					seen[t.Name] = true
+					tasks = append(tasks, t)
+				}
+			}
+			break
+		}
+	}
+	return tasks
+}
+
+// FindTask returns the task named name, if tasks has one.
+func FindTask(tasks []Task, name string) (Task, bool) {
+	for _, t := range tasks {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Task{}, false
+}
+
+// makeTargetRe matches a Makefile rule line and captures its targets. Rules
+// for files (with a slash or dot), pattern rules, and variable assignments
+// ("X := y", "X ::= y") do not match.
+var makeTargetRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_-]*(?:\s+[A-Za-z0-9][A-Za-z0-9_-]*)*)\s*::?(?:[^=:]|$)`)
+
+// makefileTasks returns the targets in the Makefile at path.
+func makefileTasks(path string) []Task {
+	base := filepath.Base(path)
+	var tasks []Task
+	forEachLine(path, func(line string) {
+		if strings.HasPrefix(line, "\t") {
+			return
+		}
+		m := makeTargetRe.FindStringSubmatch(line)
+		if m == nil {
+			return
+		}
+		for _, name := range strings.Fields(m[1]) {
+			tasks = append(tasks, Task{Name: name, Command: "make " + name, Source: base})
+		}
+	})
+	return tasks
+}
+
+// justRecipeRe matches a justfile recipe header and captures its name.
+// Parameters may follow the name; "name := value" assignments do not match.
+var justRecipeRe = regexp.MustCompile(`^@?([A-Za-z][A-Za-z0-9_-]*)(?:\s+[^:]*)?:(?:[^=]|$)`)
+
+// justfileTasks returns the public recipes in the justfile at path. Recipes
+// marked [private] or named with a leading underscore are left out.
+func justfileTasks(path string) []Task {
+	base := filepath.Base(path)
+	var tasks []Task
+	private := false
+	forEachLine(path, func(line string) {
+		switch {
+		case strings.HasPrefix(line, "[") && strings.Contains(line, "private"):
+			private = true
+			return
+		case strings.HasPrefix(line, "["), strings.HasPrefix(line, "#"), strings.TrimSpace(line) == "":
+			return
+		}
+		m := justRecipeRe.FindStringSubmatch(line)
+		if m != nil && !private && !isJustKeyword(m[1]) {
+			tasks = append(tasks, Task{Name: m[1], Command: "just " + m[1], Source: base})
+		}
+		private = false
+	})
+	return tasks
+}
+
+// taskfileKeyRe matches a "name:" mapping key, optionally quoted, and
+// captures its indentation and name.
+var taskfileKeyRe = regexp.MustCompile(`^( *)(?:"([^"]+)"|'([^']+)'|([A-Za-z0-9][A-Za-z0-9_:.-]*)):(?:\s|$)`)
+
+// taskfileTasks returns the tasks in the Taskfile at path: the keys of its
+// top-level "tasks:" mapping. Tasks marked "internal: true" are left out. The
+// file is scanned line by line, which covers the block-style YAML Taskfiles
+// are written in.
+func taskfileTasks(path string) []Task {
+	base := filepath.Base(path)
+	var tasks []Task
+	inTasks := false
+	indent := -1
+	forEachLine(path, func(line string) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return
+		}
+		if !strings.HasPrefix(line, " ") {
+			inTasks = strings.HasPrefix(line, "tasks:")
+			return
+		}
+		if !inTasks {
+			return
+		}
+		m := taskfileKeyRe.FindStringSubmatch(line)
+		switch {
+		case m != nil && (indent < 0 || len(m[1]) == indent):
+			indent = len(m[1])
+			tasks = append(tasks, Task{Name: m[2] + m[3] + m[4], Command: "task " + m[2] + m[3] + m[4], Source: base})
+		case len(tasks) > 0 && strings.ReplaceAll(trimmed, " ", "") == "internal:true":
+			tasks = tasks[:len(tasks)-1]
+		}
+	})
+	return tasks
+}
+
+// isJustKeyword reports whether a line starting with word is a justfile
+// setting or directive rather than a recipe.
+func isJustKeyword(word string) bool {
+	switch word {
+	case "set", "alias", "export", "import", "mod":
+		return true
+	}
+	return false
+}
+
+// packageScriptTasks returns the scripts in the package.json at path, sorted
+// by name. Lifecycle hooks of another script (pretest, postbuild) are left
+// out.
+func packageScriptTasks(path string) []Task {
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		if hook, ok := strings.CutPrefix(name, "pre"); ok && pkg.Scripts[hook] != "" {
+			continue
+		}
+		if hook, ok := strings.CutPrefix(name, "post"); ok && pkg.Scripts[hook] != "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tasks := make([]Task, 0, len(names))
+	for _, name := range names {
+		cmd := "npm run " + name
+		if name == "test" || name == "start" {
+			cmd = "npm " + name
+		}
+		tasks = append(tasks, Task{Name: name, Command: cmd, Source: "package.json"})
+	}
+	return tasks
+}
+
+// forEachLine calls fn with each line of the file at path, joining lines
+// continued with a trailing backslash.
+func forEachLine(path string, fn func(line string)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var cont strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, "\\") {
+			cont.WriteString(strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		if cont.Len() > 0 {
+			cont.WriteString(line)
+			line = cont.String()
+			cont.Reset()
+		}
+		fn(line)
+	}
+}
@@ -0,0 +1,110 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectTasks(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte(`GO ?= go
+VERSION := $(shell git describe)
+OUT ::= bin
+
+.PHONY: build test lint
+build: deps
+	$(GO) build ./...
+
+test lint: build
+	$(GO) test ./...
+
+bin/app: main.go
+	$(GO) build -o $@
+
+%.o: %.c
+	cc -c $<
+
+deploy:: \
+    build
+	./deploy.sh
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "justfile"), []byte(`set dotenv-load
+alias t := test
+image := "app"
+
+# Run the tests
+test *args:
+    go test {{args}} ./...
+
+[private]
+helper:
+    echo hi
+
+_internal:
+    echo hi
+
+@migrate env="dev": build
+    ./migrate {{env}}
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"scripts": {
+		"test": "vitest", "pretest": "tsc", "start": "node .", "prepare": "husky", "typecheck": "tsc --noEmit"
+	}}`), 0644)
+
+	var got []string
+	for _, task := range DetectTasks(dir) {
+		got = append(got, task.Command)
+	}
+	want := []string{
+		"just test", "just migrate",
+		"make build", "make lint", "make deploy",
+		"npm run prepare", "npm start", "npm run typecheck",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectTasks commands = %q, want %q", got, want)
+	}
+
+	if task, ok := FindTask(DetectTasks(dir), "deploy"); !ok || task.Source != "Makefile" {
+		t.Errorf("FindTask(deploy) = %+v, %v", task, ok)
+	}
+	if tasks := DetectTasks(t.TempDir()); len(tasks) != 0 {
+		t.Errorf("empty dir: %v", tasks)
+	}
+}
+
+func TestTaskfileTasks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Taskfile.yml")
+	os.WriteFile(path, []byte(`version: '3'
+
+vars:
+  APP: api
+
+tasks:
+  build:
+    desc: Build the binary
+    cmds:
+      - go build ./...
+  test: go test ./...
+  "db:migrate":
+    cmds:
+      - ./migrate up
+  setup-tools:
+    internal: true
+    cmds:
+      - go install ./tools
+
+includes:
+  docs: ./docs
+`), 0644)
+
+	var got []string
+	for _, task := range taskfileTasks(path) {
+		got = append(got, task.Command)
+	}
+	want := []string{"task build", "task test", "task db:migrate"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("taskfileTasks = %q, want %q", got, want)
+	}
+}
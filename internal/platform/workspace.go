@@ -0,0 +1,594 @@
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Workspace kinds reported by DetectWorkspace.
+const (
+	WorkspacePnpm  = "pnpm"
+	WorkspaceNpm   = "npm" // package.json "workspaces", also used by yarn and bun
+	WorkspaceGo    = "go.work"
+	WorkspaceCargo = "cargo"
+	WorkspaceNx    = "nx" // Nx projects (project.json) without package manager workspaces
+)
+
+// Monorepo build tools reported in Workspace.Tool.
+const (
+	ToolNx        = "Nx"
+	ToolTurborepo = "Turborepo"
+)
+
+// Workspace describes a monorepo and the member packages it declares.
+type Workspace struct {
+	Kind    string   // WorkspacePnpm, WorkspaceNpm, WorkspaceGo, WorkspaceCargo, or WorkspaceNx
+	Config  string   // workspace file name, relative to the root
+	Members []string // member directories relative to the root, slash-separated and sorted
+	Tool    string   // ToolNx or ToolTurborepo when nx.json or turbo.json is present, else ""
+}
+
+// workspaceSkipDirs are never searched when expanding "**" member patterns.
+var workspaceSkipDirs = map[string]bool{
+	"node_modules": true,
+	"target":       true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+}
+
+// DetectWorkspace reports the monorepo declared at projectDir by a
+// pnpm-workspace.yaml, package.json "workspaces", go.work, Cargo.toml
+// [workspace] table, or, failing those, the project.json files of an Nx
+// workspace. It returns nil when there is no workspace file or it declares no
+// members other than the root.
+func DetectWorkspace(projectDir string) *Workspace {
+	detectors := []struct {
+		kind, config, marker string
+		parse                func([]byte) (include, exclude []string)
+	}{
+		{WorkspacePnpm, "pnpm-workspace.yaml", "package.json", parsePnpmWorkspace},
+		{WorkspaceNpm, "package.json", "package.json", parsePackageWorkspaces},
+		{WorkspaceGo, "go.work", "go.mod", parseGoWork},
+		{WorkspaceCargo, "Cargo.toml", "Cargo.toml", parseCargoWorkspace},
+		{WorkspaceNx, "nx.json", "project.json", func([]byte) ([]string, []string) { return []string{"**"}, nil }},
+	}
+	for _, d := range detectors {
+		data, err := os.ReadFile(filepath.Join(projectDir, d.config))
+		if err != nil {
+			continue
+		}
+		include, exclude := d.parse(data)
+		members := expandMembers(projectDir, include, exclude, d.marker)
+		if len(members) > 0 {
+			return &Workspace{Kind: d.kind, Config: d.config, Members: members, Tool: monorepoTool(projectDir)}
+		}
+	}
+	return nil
+}
+
+// monorepoTool returns the build tool that runs tasks across the workspace at
+// projectDir, or "".
+func monorepoTool(projectDir string) string {
+	switch {
+	case FileExists(filepath.Join(projectDir, "nx.json")):
+		return ToolNx
+	case FileExists(filepath.Join(projectDir, "turbo.json")):
+		return ToolTurborepo
+	}
+	return ""
+}
+
+// parsePackageWorkspaces reads the "workspaces" of a package.json, either an
+// array of globs or an object with a "packages" array (yarn).
+func parsePackageWorkspaces(data []byte) (include, exclude []string) {
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || len(pkg.Workspaces) == 0 {
+		return nil, nil
+	}
+	var patterns []string
+	if json.Unmarshal(pkg.Workspaces, &patterns) != nil {
+		var obj struct {
+			Packages []string `json:"packages"`
+		}
+		_ = json.Unmarshal(pkg.Workspaces, &obj)
+		patterns = obj.Packages
+	}
+	for _, p := range patterns {
+		include, exclude = addPattern(include, exclude, p)
+	}
+	return include, exclude
+}
+
+// parsePnpmWorkspace reads the "packages" list of a pnpm-workspace.yaml.
+// Entries starting with "!" are exclusions.
+func parsePnpmWorkspace(data []byte) (include, exclude []string) {
+	entries, err := ParseYAML(data)
+	if err != nil {
+		return nil, nil
+	}
+	for _, e := range entries {
+		if e.Key != "packages" {
+			continue
+		}
+		patterns, _ := e.Values()
+		for _, p := range patterns {
+			include, exclude = addPattern(include, exclude, p)
+		}
+	}
+	return include, exclude
+}
+
+func addPattern(include, exclude []string, item string) ([]string, []string) {
+	item = strings.Trim(strings.TrimSpace(item), `"'`)
+	switch {
+	case item == "":
+	case strings.HasPrefix(item, "!"):
+		exclude = append(exclude, strings.TrimPrefix(item, "!"))
+	default:
+		include = append(include, item)
+	}
+	return include, exclude
+}
+
+// parseGoWork reads the "use" directives of a go.work file, in both the
+// single-line and block forms.
+func parseGoWork(data []byte) (include, exclude []string) {
+	inUse := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case inUse && line == ")":
+			inUse = false
+		case inUse:
+			include, _ = addPattern(include, nil, line)
+		case line == "use (":
+			inUse = true
+		case strings.HasPrefix(line, "use "):
+			include, _ = addPattern(include, nil, strings.TrimPrefix(line, "use "))
+		}
+	}
+	return include, nil
+}
+
+var tomlStringRe = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+// parseCargoWorkspace reads the members and exclude arrays of the [workspace]
+// table in a Cargo.toml.
+func parseCargoWorkspace(data []byte) (include, exclude []string) {
+	inWorkspace := false
+	var key string
+	var value strings.Builder
+	flush := func() {
+		for _, m := range tomlStringRe.FindAllStringSubmatch(value.String(), -1) {
+			s := m[1] + m[2]
+			if key == "members" {
+				include = append(include, s)
+			} else {
+				exclude = append(exclude, s)
+			}
+		}
+		key = ""
+		value.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if key != "" {
+			value.WriteString(line)
+			if strings.Contains(line, "]") {
+				flush()
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inWorkspace = line == "[workspace]"
+			continue
+		}
+		if !inWorkspace {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		if !ok || (k != "members" && k != "exclude") {
+			continue
+		}
+		key = k
+		value.WriteString(v)
+		if strings.Contains(v, "]") {
+			flush()
+		}
+	}
+	return include, exclude
+}
+
+// expandMembers resolves member patterns to the directories under root that
+// contain marker, dropping the root itself and anything matched by exclude.
+func expandMembers(root string, include, exclude []string, marker string) []string {
+	excluded := make([]*regexp.Regexp, 0, len(exclude))
+	for _, p := range exclude {
+		excluded = append(excluded, globRegexp(p))
+	}
+
+	seen := map[string]bool{}
+	var members []string
+	add := func(rel string) {
+		rel = filepath.ToSlash(filepath.Clean(rel))
+		if rel == "." || strings.HasPrefix(rel, "../") || rel == ".." || seen[rel] {
+			return
+		}
+		for _, re := range excluded {
+			if re.MatchString(rel) {
+				return
+			}
+		}
+		if !FileExists(filepath.Join(root, filepath.FromSlash(rel), marker)) {
+			return
+		}
+		seen[rel] = true
+		members = append(members, rel)
+	}
+
+	for _, p := range include {
+		p = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(p), "./"), "/")
+		switch {
+		case strings.Contains(p, "**"):
+			re := globRegexp(p)
+			_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || !d.IsDir() {
+					return nil
+				}
+				if path != root && (strings.HasPrefix(d.Name(), ".") || workspaceSkipDirs[d.Name()]) {
+					return filepath.SkipDir
+				}
+				rel, _ := filepath.Rel(root, path)
+				if re.MatchString(filepath.ToSlash(rel)) {
+					add(rel)
+				}
+				return nil
+			})
+		case strings.ContainsAny(p, "*?["):
+			matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(p)))
+			for _, m := range matches {
+				rel, _ := filepath.Rel(root, m)
+				add(rel)
+			}
+		default:
+			add(p)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// globRegexp converts a slash-separated glob, where "**" matches any number of
+// path segments, to an anchored regular expression.
+func globRegexp(pattern string) *regexp.Regexp {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				sb.WriteString("(?:.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// PackageName returns the name a workspace member declares in its
+// package.json, Cargo.toml, or go.mod, falling back to the directory name.
+func PackageName(dir string) string {
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if ReadJSONFile(filepath.Join(dir, "package.json"), &pkg) == nil && pkg.Name != "" {
+		return pkg.Name
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml")); err == nil {
+		inPackage := false
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "[") {
+				inPackage = line == "[package]"
+				continue
+			}
+			if k, v, ok := strings.Cut(line, "="); inPackage && ok && strings.TrimSpace(k) == "name" {
+				return strings.Trim(strings.TrimSpace(v), `"'`)
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if mod, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+				return strings.Trim(strings.TrimSpace(mod), `"`)
+			}
+		}
+	}
+	return filepath.Base(dir)
+}
+
+// KeyPackagesSection renders the "## Key Packages" section of a monorepo's
+// root CLAUDE.md. purposes maps a member path to a one-line description; members
+// without one are described by their detected tech stack. Each member is
+// followed by the commands that build and test it from the repository root.
+func KeyPackagesSection(projectDir string, ws *Workspace, purposes map[string]string) string {
+	return keyPackagesSection(projectDir, projectDir, ws, purposes)
+}
+
+// keyPackagesSection is KeyPackagesSection with the members' CLAUDE.md files
+// looked up under docsDir, a tree laid out like projectDir.
+func keyPackagesSection(projectDir, docsDir string, ws *Workspace, purposes map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("## Key Packages\n")
+	fmt.Fprintf(&sb, "Workspace: %s (%s)", ws.Config, ws.Kind)
+	if ws.Tool != "" {
+		fmt.Fprintf(&sb, ", tasks run with %s", ws.Tool)
+	}
+	sb.WriteString(".")
+	ownClaudeMd := true
+	for _, m := range ws.Members {
+		if !FileExists(filepath.Join(docsDir, filepath.FromSlash(m), "CLAUDE.md")) {
+			ownClaudeMd = false
+		}
+	}
+	if ownClaudeMd {
+		sb.WriteString(" Each package has its own CLAUDE.md.")
+	}
+	sb.WriteString("\n")
+	for _, m := range ws.Members {
+		dir := filepath.Join(projectDir, filepath.FromSlash(m))
+		desc := purposes[m]
+		if desc == "" {
+			desc = detectProject(dir).techStack
+		}
+		fmt.Fprintf(&sb, "- `%s/` - %s: %s\n", m, PackageName(dir), desc)
+		var cmds []string
+		for _, target := range []string{"build", "test"} {
+			if c := packageCommand(projectDir, ws, m, target); c != "" {
+				cmds = append(cmds, fmt.Sprintf("%s: `%s`", strings.ToUpper(target[:1])+target[1:], c))
+			}
+		}
+		if len(cmds) > 0 {
+			fmt.Fprintf(&sb, "  %s\n", strings.Join(cmds, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// workspaceCommand returns the command that runs target (build, test, or
+// lint) across every package with the workspace's Nx or Turborepo, or "" when
+// there is no such tool or the Turborepo config does not declare target.
+func workspaceCommand(projectDir string, ws *Workspace, target string) string {
+	switch ws.Tool {
+	case ToolNx:
+		return "npx nx run-many -t " + target
+	case ToolTurborepo:
+		if turboTasks(projectDir)[target] {
+			return "npx turbo run " + target
+		}
+	}
+	return ""
+}
+
+// turboTasks returns the task names declared in turbo.json, under "tasks"
+// (Turborepo 2) or "pipeline" (Turborepo 1). A package-specific task such as
+// "web#build" counts as "build".
+func turboTasks(projectDir string) map[string]bool {
+	var cfg struct {
+		Tasks    map[string]json.RawMessage `json:"tasks"`
+		Pipeline map[string]json.RawMessage `json:"pipeline"`
+	}
+	tasks := map[string]bool{}
+	if ReadJSONFile(filepath.Join(projectDir, "turbo.json"), &cfg) != nil {
+		return tasks
+	}
+	for _, m := range []map[string]json.RawMessage{cfg.Tasks, cfg.Pipeline} {
+		for name := range m {
+			if _, task, ok := strings.Cut(name, "#"); ok {
+				name = task
+			}
+			tasks[name] = true
+		}
+	}
+	return tasks
+}
+
+// packageTargets returns the targets a workspace member defines: its
+// package.json scripts and the targets of its Nx project.json.
+func packageTargets(dir string) map[string]bool {
+	targets := map[string]bool{}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if ReadJSONFile(filepath.Join(dir, "package.json"), &pkg) == nil {
+		for name := range pkg.Scripts {
+			targets[name] = true
+		}
+	}
+	var project struct {
+		Targets map[string]json.RawMessage `json:"targets"`
+	}
+	if ReadJSONFile(filepath.Join(dir, "project.json"), &project) == nil {
+		for name := range project.Targets {
+			targets[name] = true
+		}
+	}
+	return targets
+}
+
+// nxProjectName returns the name Nx knows the member at dir by: the "name" in
+// its project.json, else its package name.
+func nxProjectName(dir string) string {
+	var project struct {
+		Name string `json:"name"`
+	}
+	if ReadJSONFile(filepath.Join(dir, "project.json"), &project) == nil && project.Name != "" {
+		return project.Name
+	}
+	return PackageName(dir)
+}
+
+// packageCommand returns the command, run from the repository root, that runs
+// target (build, test, or lint) for one workspace member, or "" when the
+// member has no such target.
+func packageCommand(projectDir string, ws *Workspace, member, target string) string {
+	dir := filepath.Join(projectDir, filepath.FromSlash(member))
+	hasTarget := packageTargets(dir)[target]
+	switch {
+	case ws.Tool == ToolNx && hasTarget:
+		return fmt.Sprintf("npx nx run %s:%s", nxProjectName(dir), target)
+	case ws.Tool == ToolTurborepo && hasTarget:
+		return fmt.Sprintf("npx turbo run %s --filter=%s", target, PackageName(dir))
+	case ws.Kind == WorkspacePnpm && hasTarget:
+		return fmt.Sprintf("pnpm --filter %s run %s", PackageName(dir), target)
+	case ws.Kind == WorkspaceNpm && hasTarget:
+		if FileExists(filepath.Join(projectDir, "yarn.lock")) {
+			return fmt.Sprintf("yarn workspace %s run %s", PackageName(dir), target)
+		}
+		return fmt.Sprintf("npm run %s -w %s", target, member)
+	case ws.Kind == WorkspaceCargo:
+		if target == "lint" {
+			target = "clippy"
+		}
+		return fmt.Sprintf("cargo %s -p %s", target, PackageName(dir))
+	case ws.Kind == WorkspaceGo:
+		if target == "lint" {
+			target = "vet"
+		}
+		return fmt.Sprintf("go %s ./%s/...", target, member)
+	}
+	cfg := detectProject(dir)
+	cmd := map[string]string{"build": cfg.buildCmd, "test": cfg.testCmd, "lint": cfg.lintCmd}[target]
+	if cmd == "" {
+		return ""
+	}
+	return fmt.Sprintf("cd %s && %s", member, cmd)
+}
+
+// SetMarkdownSection replaces the "## <heading>" section of content with
+// section, or inserts it after the "## Project" section (or at the end) when
+// content has no such heading.
+func SetMarkdownSection(content, heading, section string) string {
+	section = strings.TrimRight(section, "\n") + "\n"
+	marker := "## " + heading
+	if start := headingIndex(content, marker); start >= 0 {
+		end := len(content)
+		if next := headingIndex(content[start+len(marker):], "## "); next >= 0 {
+			end = start + len(marker) + next
+			section += "\n"
+		}
+		return content[:start] + section + content[end:]
+	}
+	if start := headingIndex(content, "## Project"); start >= 0 {
+		if next := headingIndex(content[start+len("## Project"):], "## "); next >= 0 {
+			at := start + len("## Project") + next
+			return content[:at] + section + "\n" + content[at:]
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n\n") {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	return content + section
+}
+
+// headingIndex returns the offset of the first line of content that starts
+// with prefix, or -1.
+func headingIndex(content, prefix string) int {
+	for offset := 0; offset < len(content); {
+		line, _, _ := strings.Cut(content[offset:], "\n")
+		if strings.HasPrefix(line, prefix) && (len(line) == len(prefix) || prefix == "## " || line[len(prefix)] == ' ') {
+			return offset
+		}
+		offset += len(line) + 1
+	}
+	return -1
+}
+
+// MarkdownField returns the value of the first "<name>: value" line in content.
+func MarkdownField(content, name string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), name+":"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// SetMarkdownField sets the value of the first "<name>: value" line in
+// content, as MarkdownField reads it, keeping the line's indentation. A
+// missing field is added on the line after the field named after; when that
+// is missing too, content is returned unchanged.
+func SetMarkdownField(content, name, value, after string) string {
+	lines := strings.Split(content, "\n")
+	at := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if strings.HasPrefix(trimmed, name+":") {
+			lines[i] = indent + name + ": " + value
+			return strings.Join(lines, "\n")
+		}
+		if at < 0 && after != "" && strings.HasPrefix(trimmed, after+":") {
+			at = i
+		}
+	}
+	if at < 0 {
+		return content
+	}
+	indent := lines[at][:len(lines[at])-len(strings.TrimLeft(lines[at], " \t"))]
+	lines = append(lines[:at+1], append([]string{indent + name + ": " + value}, lines[at+1:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// UpdateKeyPackages rewrites the "## Key Packages" section of the root
+// CLAUDE.md at claudeMdPath, describing each member by the Purpose line of its
+// own CLAUDE.md when it has one.
+func UpdateKeyPackages(projectDir string, ws *Workspace, claudeMdPath string) error {
+	return UpdateKeyPackagesFrom(projectDir, projectDir, ws, claudeMdPath)
+}
+
+// UpdateKeyPackagesFrom is UpdateKeyPackages with the members' CLAUDE.md files
+// read from docsDir, a tree laid out like projectDir, such as a staged copy of
+// the files attach writes.
+func UpdateKeyPackagesFrom(projectDir, docsDir string, ws *Workspace, claudeMdPath string) error {
+	content, err := os.ReadFile(claudeMdPath)
+	if err != nil {
+		return err
+	}
+	purposes := make(map[string]string, len(ws.Members))
+	for _, m := range ws.Members {
+		if data, err := os.ReadFile(filepath.Join(docsDir, filepath.FromSlash(m), "CLAUDE.md")); err == nil {
+			purposes[m] = MarkdownField(string(data), "Purpose")
+		}
+	}
+	updated := SetMarkdownSection(string(content), "Key Packages", keyPackagesSection(projectDir, docsDir, ws, purposes))
+	return os.WriteFile(claudeMdPath, []byte(updated), 0644)
+}
@@ -0,0 +1,322 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectWorkspace(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantKind string
+		want     []string
+	}{
+		{
+			name: "pnpm with globs and exclusion",
+			files: map[string]string{
+				"pnpm-workspace.yaml":                     "packages:\n  - 'apps/*'\n  - \"packages/**\" # nested\n  - '!**/fixtures/**'\ncatalog:\n  react: ^18\n",
+				"apps/web/package.json":                   `{"name":"@acme/web"}`,
+				"apps/docs/README.md":                     "no package.json",
+				"packages/ui/package.json":                `{}`,
+				"packages/ui/icons/package.json":          `{}`,
+				"packages/ui/fixtures/a/package.json":     `{}`,
+				"packages/ui/node_modules/x/package.json": `{}`,
+			},
+			wantKind: WorkspacePnpm,
+			want:     []string{"apps/web", "packages/ui", "packages/ui/icons"},
+		},
+		{
+			name: "pnpm flow sequence",
+			files: map[string]string{
+				"pnpm-workspace.yaml":   "packages: [apps/*]\n",
+				"apps/api/package.json": `{}`,
+			},
+			wantKind: WorkspacePnpm,
+			want:     []string{"apps/api"},
+		},
+		{
+			name: "go.work block and single use",
+			files: map[string]string{
+				"go.work":         "go 1.22\n\nuse (\n\t.\n\t./cmd/tool // the CLI\n\t./lib\n)\nuse ./extra\n",
+				"go.mod":          "module example.com/root\n",
+				"cmd/tool/go.mod": "module example.com/tool\n",
+				"lib/go.mod":      "module example.com/lib\n",
+				"extra/go.mod":    "module example.com/extra\n",
+			},
+			wantKind: WorkspaceGo,
+			want:     []string{"cmd/tool", "extra", "lib"},
+		},
+		{
+			name: "cargo multi-line members with exclude",
+			files: map[string]string{
+				"Cargo.toml":             "[package]\nname = \"root\"\n\n[workspace]\nmembers = [\n  \"crates/*\", # all crates\n  \"cli\",\n]\nexclude = [\"crates/old\"]\n",
+				"crates/core/Cargo.toml": "[package]\nname = \"core\"\n",
+				"crates/old/Cargo.toml":  "[package]\nname = \"old\"\n",
+				"cli/Cargo.toml":         "[package]\nname = \"cli\"\n",
+			},
+			wantKind: WorkspaceCargo,
+			want:     []string{"cli", "crates/core"},
+		},
+		{
+			name: "package.json workspaces",
+			files: map[string]string{
+				"package.json":             `{"name":"root","workspaces":["apps/*","!apps/legacy"]}`,
+				"apps/web/package.json":    `{}`,
+				"apps/legacy/package.json": `{}`,
+				"packages/ui/package.json": `{}`,
+				"packages/ui/project.json": `{}`,
+				"apps/admin/README.md":     "no package.json",
+			},
+			wantKind: WorkspaceNpm,
+			want:     []string{"apps/web"},
+		},
+		{
+			name: "yarn workspaces object",
+			files: map[string]string{
+				"package.json":             `{"workspaces":{"packages":["packages/*"],"nohoist":["**/react"]}}`,
+				"packages/ui/package.json": `{}`,
+			},
+			wantKind: WorkspaceNpm,
+			want:     []string{"packages/ui"},
+		},
+		{
+			name: "Nx projects",
+			files: map[string]string{
+				"nx.json":                     `{}`,
+				"package.json":                `{"name":"root"}`,
+				"apps/api/project.json":       `{"name":"api"}`,
+				"libs/auth/project.json":      `{"name":"auth"}`,
+				"node_modules/x/project.json": `{}`,
+			},
+			wantKind: WorkspaceNx,
+			want:     []string{"apps/api", "libs/auth"},
+		},
+		{
+			name:  "plain Cargo package",
+			files: map[string]string{"Cargo.toml": "[package]\nname = \"solo\"\n"},
+		},
+		{
+			name:  "no workspace file",
+			files: map[string]string{"package.json": `{}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, tt.files)
+			ws := DetectWorkspace(dir)
+			if tt.wantKind == "" {
+				if ws != nil {
+					t.Fatalf("DetectWorkspace() = %+v, want nil", ws)
+				}
+				return
+			}
+			if ws == nil {
+				t.Fatal("DetectWorkspace() = nil")
+			}
+			if ws.Kind != tt.wantKind || !reflect.DeepEqual(ws.Members, tt.want) {
+				t.Errorf("DetectWorkspace() = %s %v, want %s %v", ws.Kind, ws.Members, tt.wantKind, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"packages/*", "packages/ui", true},
+		{"packages/*", "packages/ui/icons", false},
+		{"packages/**", "packages/ui/icons", true},
+		{"**/fixtures/**", "packages/ui/fixtures/a", true},
+		{"**/fixtures/**", "fixtures/a", true},
+		{"./apps/?pi", "apps/api", true},
+	}
+	for _, tt := range tests {
+		if got := globRegexp(tt.pattern).MatchString(tt.path); got != tt.want {
+			t.Errorf("globRegexp(%q) matches %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"js/package.json": `{"name":"@acme/web"}`,
+		"rs/Cargo.toml":   "[dependencies]\nname = \"no\"\n[package]\nname = \"core\"\n",
+		"go/go.mod":       "module example.com/lib\n\ngo 1.22\n",
+		"plain/README.md": "",
+	})
+	for sub, want := range map[string]string{"js": "@acme/web", "rs": "core", "go": "example.com/lib", "plain": "plain"} {
+		if got := PackageName(filepath.Join(dir, sub)); got != want {
+			t.Errorf("PackageName(%s) = %q, want %q", sub, got, want)
+		}
+	}
+}
+
+func TestSetMarkdownSection(t *testing.T) {
+	section := "## Key Packages\n- `a/` - a: A\n"
+	tests := []struct {
+		name, content, want string
+	}{
+		{
+			name:    "inserts after Project",
+			content: "# T\n\n## Project\nName: x\n\n## Conventions\n- c\n",
+			want:    "# T\n\n## Project\nName: x\n\n## Key Packages\n- `a/` - a: A\n\n## Conventions\n- c\n",
+		},
+		{
+			name:    "replaces existing",
+			content: "# T\n\n## Key Packages\n- old\n\n### Sub\n\n## Notes\n",
+			want:    "# T\n\n## Key Packages\n- `a/` - a: A\n\n## Notes\n",
+		},
+		{
+			name:    "replaces trailing",
+			content: "# T\n\n## Key Packages\n- old\n",
+			want:    "# T\n\n## Key Packages\n- `a/` - a: A\n",
+		},
+		{
+			name:    "appends",
+			content: "# T\nbody\n",
+			want:    "# T\nbody\n\n## Key Packages\n- `a/` - a: A\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SetMarkdownSection(tt.content, "Key Packages", section); got != tt.want {
+				t.Errorf("SetMarkdownSection() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateKeyPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"pnpm-workspace.yaml":   "packages:\n  - apps/*\n",
+		"apps/web/package.json": `{"name":"web","dependencies":{"next":"14"}}`,
+		"apps/api/package.json": `{"name":"api"}`,
+		"apps/api/CLAUDE.md":    "# Package Instructions: apps/api\n\n## Package\nPurpose: REST API for orders\n",
+	})
+	ws := DetectWorkspace(dir)
+	claudeMd := filepath.Join(dir, ".claude", "CLAUDE.md")
+	writeTree(t, dir, map[string]string{".claude/CLAUDE.md": GenerateClaudeMdScaffold(dir)})
+
+	if err := UpdateKeyPackages(dir, ws, claudeMd); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(claudeMd)
+	got := string(data)
+	for _, want := range []string{
+		"## Key Packages\nWorkspace: pnpm-workspace.yaml (pnpm)",
+		"- `apps/api/` - api: REST API for orders\n",
+		"- `apps/web/` - web: Next.js\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CLAUDE.md missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "## Key Packages") > strings.Index(got, "## Conventions") {
+		t.Error("Key Packages should come before Conventions")
+	}
+
+	// Updating again replaces rather than duplicates the section.
+	if err := UpdateKeyPackages(dir, ws, claudeMd); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(claudeMd)
+	if strings.Count(string(data), "## Key Packages") != 1 {
+		t.Errorf("Key Packages duplicated:\n%s", data)
+	}
+}
+
+func TestGenerateClaudeMdScaffold_Monorepo(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"package.json":          `{"name":"acme","workspaces":["apps/*"],"scripts":{"build":"turbo run build"}}`,
+		"turbo.json":            `{"tasks":{"build":{},"web#test":{}}}`,
+		"apps/web/package.json": `{"name":"web","dependencies":{"next":"14"},"scripts":{"build":"next build","test":"vitest"}}`,
+		"apps/api/package.json": `{"name":"api","dependencies":{"express":"4"},"scripts":{"test":"jest"}}`,
+		"apps/api/Makefile":     "test:\n\tjest\n",
+		"apps/svc/go.mod":       "module example.com/svc\n",
+		"apps/svc/package.json": `{"name":"svc"}`,
+	})
+	got := GenerateClaudeMdScaffold(dir)
+	for _, want := range []string{
+		"Tech Stack: Express, Go, Next.js (Turborepo workspace, 3 packages)\n",
+		"Build: `npx turbo run build`\n",
+		"Test: `npx turbo run test`\n",
+		"## Key Packages\nWorkspace: package.json (npm), tasks run with Turborepo.\n",
+		"- `apps/web/` - web: Next.js\n  Build: `npx turbo run build --filter=web`, Test: `npx turbo run test --filter=web`\n",
+		"- `apps/api/` - api: Express\n  Test: `npx turbo run test --filter=api`\n",
+		"- `apps/svc/` - svc: Go\n  Build: `cd apps/svc && go build ./...`, Test: `cd apps/svc && go test ./...`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("scaffold missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Lint:") {
+		t.Errorf("turbo.json declares no lint task:\n%s", got)
+	}
+
+	// Without Turborepo, members are built through the package manager and
+	// each package's own tooling.
+	os.Remove(filepath.Join(dir, "turbo.json"))
+	ws := DetectWorkspace(dir)
+	for member, want := range map[string]string{
+		"apps/web": "npm run test -w apps/web",
+		"apps/svc": "cd apps/svc && go test ./...",
+	} {
+		if got := packageCommand(dir, ws, member, "test"); got != want {
+			t.Errorf("packageCommand(%s) = %q, want %q", member, got, want)
+		}
+	}
+	ws = &Workspace{Kind: WorkspaceGo, Members: []string{"lib"}}
+	if got := packageCommand(dir, ws, "lib", "lint"); got != "go vet ./lib/..." {
+		t.Errorf("go.work lint = %q", got)
+	}
+}
+
+func TestGeneratePackageScaffold(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"crates/core/Cargo.toml": "[package]\nname = \"core\"\n"})
+	got := GeneratePackageScaffold(root, filepath.Join(root, "crates", "core"))
+	for _, want := range []string{"# Package Instructions: crates/core", "Name: core", "Tech Stack: Rust", "Build: `cargo build`"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("scaffold missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Team Execution") {
+		t.Error("package scaffold should not repeat root-level sections")
+	}
+}
+
+func TestSetMarkdownField(t *testing.T) {
+	content := "## Project\nName: app\n  Build: `go build`\nTest: `go test`\n"
+	if got := SetMarkdownField(content, "Build", "`make`", ""); got != "## Project\nName: app\n  Build: `make`\nTest: `go test`\n" {
+		t.Errorf("replace = %q", got)
+	}
+	if got := SetMarkdownField(content, "Lint", "`go vet`", "Test"); got != "## Project\nName: app\n  Build: `go build`\nTest: `go test`\nLint: `go vet`\n" {
+		t.Errorf("insert = %q", got)
+	}
+	if got := SetMarkdownField(content, "Lint", "`go vet`", "Purpose"); got != content {
+		t.Errorf("insert without anchor = %q", got)
+	}
+}
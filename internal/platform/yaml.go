@@ -0,0 +1,289 @@
+package platform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// YAMLEntry is a top-level key of a document read by ParseYAML. List is
+// non-nil, possibly empty, when the key holds a list and Map is non-nil when
+// it holds a mapping; otherwise Value holds its scalar.
+type YAMLEntry struct {
+	Key   string
+	Line  int
+	Value string
+	List  []YAMLItem
+	Map   []YAMLField
+}
+
+// YAMLItem is one element of a list: a scalar Value, or the Fields of a
+// mapping written as "- key: value" with further "key: value" lines indented
+// below it.
+type YAMLItem struct {
+	Line   int
+	Value  string
+	Fields []YAMLField
+}
+
+// YAMLField is one "key: value" pair of a mapping.
+type YAMLField struct {
+	Key   string
+	Line  int
+	Value string
+}
+
+// Values returns the entry's list of scalars. The result is non-nil for an
+// empty list, so callers can tell it from an absent one.
+func (e YAMLEntry) Values() ([]string, error) {
+	if e.List == nil {
+		return nil, fmt.Errorf("line %d: %s must be a list", e.Line, e.Key)
+	}
+	values := make([]string, 0, len(e.List))
+	for _, item := range e.List {
+		if item.Fields != nil {
+			return nil, fmt.Errorf("line %d: %s items must be plain values", item.Line, e.Key)
+		}
+		values = append(values, item.Value)
+	}
+	return values, nil
+}
+
+// ParseYAML reads the small YAML subset used by the workspace's own files:
+// top-level "key: value" scalars, and "key:" lists written either as block
+// sequences ("  - item") or flow sequences ("[a, b]"). Block list items may be
+// one-level mappings:
+//
+//	tasks:
+//	  - feature-auth
+//	  - branch: feature-api
+//	    prompt: Add pagination
+//
+// A top-level key may also hold a one-level mapping; lines indented below its
+// fields are skipped, since no caller reads them. Comments and "---" lines are
+// ignored and quoted scalars are unquoted; anchors and multi-line scalars are
+// not supported. Entries are returned in file order, and errors carry the
+// 1-based line number.
+func ParseYAML(data []byte) ([]YAMLEntry, error) {
+	var entries []YAMLEntry
+	seen := map[string]bool{}
+	var cur *YAMLEntry // entry whose block list or mapping is being filled
+	itemIndent := 0    // indentation of the last list item's "-"
+	mapIndent := 0     // indentation of the fields of cur.Map
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimRight(StripYAMLComment(raw), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		switch {
+		case cur != nil && cur.Map != nil && indent > 0:
+			if indent > mapIndent {
+				continue
+			}
+			if indent < mapIndent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNo)
+			}
+			f, err := parseYAMLField(trimmed, lineNo, cur.Map)
+			if err != nil {
+				return nil, err
+			}
+			cur.Map = append(cur.Map, f)
+			continue
+
+		case trimmed == "-" || strings.HasPrefix(trimmed, "- "):
+			switch {
+			case cur == nil && indent > 0:
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNo)
+			case cur == nil:
+				return nil, fmt.Errorf("line %d: list item outside of a list", lineNo)
+			}
+			rest := strings.TrimSpace(trimmed[1:])
+			if rest == "" {
+				return nil, fmt.Errorf("line %d: empty list item", lineNo)
+			}
+			item := YAMLItem{Line: lineNo}
+			if key, value, ok := cutYAMLField(rest); ok {
+				item.Fields = []YAMLField{{Key: key, Line: lineNo, Value: value}}
+			} else {
+				item.Value = UnquoteYAML(rest)
+			}
+			cur.List = append(cur.List, item)
+			itemIndent = indent
+			continue
+
+		case indent > 0:
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNo)
+			}
+			if len(cur.List) == 0 {
+				// The first line below the key is a field: it holds a mapping.
+				f, err := parseYAMLField(trimmed, lineNo, nil)
+				if err != nil {
+					return nil, err
+				}
+				cur.List, cur.Map, mapIndent = nil, []YAMLField{f}, indent
+				continue
+			}
+			last := &cur.List[len(cur.List)-1]
+			if last.Fields == nil || indent <= itemIndent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNo)
+			}
+			f, err := parseYAMLField(trimmed, lineNo, last.Fields)
+			if err != nil {
+				return nil, err
+			}
+			last.Fields = append(last.Fields, f)
+			continue
+		}
+		cur = nil
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+		seen[key] = true
+
+		entry := YAMLEntry{Key: key, Line: lineNo}
+		switch {
+		case value == "":
+			entry.List = []YAMLItem{}
+		case strings.HasPrefix(value, "["):
+			if !strings.HasSuffix(value, "]") {
+				return nil, fmt.Errorf("line %d: unterminated flow sequence", lineNo)
+			}
+			entry.List = []YAMLItem{}
+			for _, part := range splitFlowSequence(value[1 : len(value)-1]) {
+				if item := UnquoteYAML(strings.TrimSpace(part)); item != "" {
+					entry.List = append(entry.List, YAMLItem{Line: lineNo, Value: item})
+				}
+			}
+		default:
+			entry.Value = UnquoteYAML(value)
+		}
+		entries = append(entries, entry)
+		if value == "" {
+			cur = &entries[len(entries)-1]
+		}
+	}
+	return entries, nil
+}
+
+// parseYAMLField parses a "key: value" line of a mapping whose fields so far
+// are fields.
+func parseYAMLField(s string, lineNo int, fields []YAMLField) (YAMLField, error) {
+	key, value, ok := cutYAMLField(s)
+	if !ok {
+		return YAMLField{}, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+	}
+	for _, f := range fields {
+		if f.Key == key {
+			return YAMLField{}, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+	}
+	return YAMLField{Key: key, Line: lineNo, Value: value}, nil
+}
+
+// cutYAMLField splits a mapping field "key: value" and unquotes both parts.
+// The key is quoted or a plain word, and the colon is followed by a space or
+// ends the line, so values such as "ticket:[0-9]+" or a regular expression
+// with a colon in it read as scalars.
+func cutYAMLField(s string) (key, value string, ok bool) {
+	end := -1
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		if i := strings.IndexByte(s[1:], s[0]); i >= 0 {
+			end = i + 2
+		}
+	} else {
+		end = strings.IndexAny(s, ": \t\"'\\()[]{}|^$*+?")
+	}
+	if end <= 0 || end >= len(s) || s[end] != ':' {
+		return "", "", false
+	}
+	if rest := s[end+1:]; rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", "", false
+	}
+	return UnquoteYAML(s[:end]), UnquoteYAML(strings.TrimSpace(s[end+1:])), true
+}
+
+// splitFlowSequence splits the inside of a [a, b] list on commas that are not
+// within quotes or parentheses, since rules like "Bash(a, b)" contain them.
+func splitFlowSequence(s string) []string {
+	var items []string
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// UnquoteYAML returns the value of a YAML scalar that may be quoted: the
+// escapes of a double-quoted one are decoded, and in a single-quoted one each
+// doubled quote stands for one. Anything else is returned as is.
+func UnquoteYAML(s string) string {
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return s
+	}
+	switch s[0] {
+	case '"':
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	case '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+// StripYAMLComment removes a trailing "# comment" from a line. A "#" only
+// starts a comment at the beginning of the line or after whitespace, and not
+// inside a quoted scalar, so values like "Bash(git commit -m \"fix #12\")"
+// keep their hashes. A quote only opens a scalar where one can begin, which
+// leaves apostrophes in plain text such as "don't" alone.
+func StripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" \t[,:-", line[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
@@ -0,0 +1,121 @@
+package platform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnquoteYAML(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`plain`, "plain"},
+		{`"double"`, "double"},
+		{`'single'`, "single"},
+		{`"tab\there"`, "tab\there"},
+		{`'it''s'`, "it's"},
+		{`"bad \q escape"`, `bad \q escape`},
+		{`"mismatched'`, `"mismatched'`},
+		{`"`, `"`},
+		{``, ``},
+	}
+	for _, tt := range tests {
+		if got := UnquoteYAML(tt.in); got != tt.want {
+			t.Errorf("UnquoteYAML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStripYAMLComment(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`key: value # comment`, `key: value `},
+		{`# full line`, ``},
+		{"  - item\t# tabbed", "  - item\t"},
+		{`url: https://example.com/#anchor`, `url: https://example.com/#anchor`},
+		{`- "Bash(git commit -m \"fix #12\")" # note`, `- "Bash(git commit -m \"fix #12\")" `},
+		{`- 'issue #3' # note`, `- 'issue #3' `},
+		{`ask: ["a #1", 'b #2'] # note`, `ask: ["a #1", 'b #2'] `},
+		{`prompt: don't stop # note`, `prompt: don't stop `},
+		{`name: "unterminated # kept`, `name: "unterminated # kept`},
+	}
+	for _, tt := range tests {
+		if got := StripYAMLComment(tt.in); got != tt.want {
+			t.Errorf("StripYAMLComment(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	entries, err := ParseYAML([]byte(`---
+# comment
+name: "acme" # trailing
+empty: []
+flow: ["Bash(a, b)", 'c', d]
+block:
+  - one
+  - "two # kept"
+- three
+tasks:
+  - feature-auth
+  - branch: feature-api
+    prompt: "fix #12" # quoted hash
+  - 'ticket:[0-9]+'
+catalog:
+  react: ^18
+  '@types/node': ^20
+  nested:
+    skipped: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []YAMLEntry{
+		{Key: "name", Line: 3, Value: "acme"},
+		{Key: "empty", Line: 4, List: []YAMLItem{}},
+		{Key: "flow", Line: 5, List: []YAMLItem{{Line: 5, Value: "Bash(a, b)"}, {Line: 5, Value: "c"}, {Line: 5, Value: "d"}}},
+		{Key: "block", Line: 6, List: []YAMLItem{{Line: 7, Value: "one"}, {Line: 8, Value: "two # kept"}, {Line: 9, Value: "three"}}},
+		{Key: "tasks", Line: 10, List: []YAMLItem{
+			{Line: 11, Value: "feature-auth"},
+			{Line: 12, Fields: []YAMLField{{Key: "branch", Line: 12, Value: "feature-api"}, {Key: "prompt", Line: 13, Value: "fix #12"}}},
+			{Line: 14, Value: "ticket:[0-9]+"},
+		}},
+		{Key: "catalog", Line: 15, Map: []YAMLField{
+			{Key: "react", Line: 16, Value: "^18"},
+			{Key: "@types/node", Line: 17, Value: "^20"},
+			{Key: "nested", Line: 18},
+		}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ParseYAML() = %+v, want %+v", entries, want)
+	}
+	if got, err := entries[1].Values(); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("Values() of an empty list = %#v, %v; want empty and non-nil", got, err)
+	}
+	if _, err := entries[0].Values(); err == nil || !strings.Contains(err.Error(), "name must be a list") {
+		t.Errorf("Values() of a scalar error = %v", err)
+	}
+	if _, err := entries[4].Values(); err == nil {
+		t.Error("Values() of a list of mappings should fail")
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"- item\n", "line 1: list item outside of a list"},
+		{"  - item\n", "line 1: unexpected indentation"},
+		{"key: value\n  - item\n", "line 2: unexpected indentation"},
+		{"key: value\n  nested: value\n", "line 2: unexpected indentation"},
+		{"key:\n  - a\n    b: c\n", "line 3: unexpected indentation"},
+		{"key:\n  - a: 1\n    not a field\n", `line 3: expected "key: value"`},
+		{"key:\n  - a: 1\n    a: 2\n", `line 3: duplicate key "a"`},
+		{"key:\n    a: 1\n  b: 2\n", "line 3: unexpected indentation"},
+		{"key:\n  -\n", "line 2: empty list item"},
+		{"no colon\n", `line 1: expected "key: value"`},
+		{"a: 1\na: 2\n", `line 2: duplicate key "a"`},
+		{"a: [x, y\n", "line 1: unterminated flow sequence"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseYAML([]byte(tt.input)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseYAML(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
@@ -77,30 +78,28 @@ func ListTo(w io.Writer) error {
 
 // Add installs a plugin via the Claude CLI.
 func Add(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("usage: claude-workspace plugins add <plugin[@marketplace]> [--scope user|project]")
-	}
-	return runPluginCmd(args, "install", "Installing", "installing")
+	return runPluginCmd("add", args, "install", "Installing", "installing")
 }
 
 // Remove uninstalls a plugin via the Claude CLI.
 func Remove(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("usage: claude-workspace plugins remove <plugin[@marketplace]> [--scope user|project]")
-	}
-	return runPluginCmd(args, "uninstall", "Removing", "removing")
+	return runPluginCmd("remove", args, "uninstall", "Removing", "removing")
 }
 
-// runPluginCmd executes a claude plugin <action> command with scope parsing.
-func runPluginCmd(args []string, action, progressVerb, errVerb string) error {
-	plugin := args[0]
+// runPluginCmd executes a claude plugin <action> command for "plugins <verb>",
+// passing its --scope on.
+func runPluginCmd(verb string, args []string, action, progressVerb, errVerb string) error {
 	scope := scopeDefault
-	for i := 1; i < len(args); i++ {
-		if args[i] == "--scope" && i+1 < len(args) {
-			scope = args[i+1]
-			i++
-		}
+	f := cli.New("plugins "+verb, "plugins "+verb+" <plugin[@marketplace]> [--scope user|project]")
+	f.StringVar(&scope, "--scope", "user|project", "Where the plugin is installed")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
 	}
+	plugin := positional[0]
 
 	fmt.Printf("%s %s (scope: %s)...\n", progressVerb, plugin, scope)
 	if err := platform.Run("claude", "plugin", action, plugin, "--scope", scope); err != nil {
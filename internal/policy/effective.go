@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// kinds lists the permission lists in evaluation order: deny beats ask, and
+// ask beats allow, whichever layer a rule comes from.
+var kinds = []Decision{DecisionDeny, DecisionAsk, DecisionAllow}
+
+// Layer is the permissions block of one settings file.
+type Layer struct {
+	Scope       config.ConfigScope
+	Path        string
+	Exists      bool
+	Rules       map[Decision][]string
+	DefaultMode string
+}
+
+// Entry is one rule of the effective policy and the layer it comes from.
+type Entry struct {
+	Kind  Decision
+	Rule  string
+	Scope config.ConfigScope
+	Path  string
+}
+
+// Policy is the merged set of rules Claude Code enforces for a project.
+type Policy struct {
+	Layers      []Layer // highest precedence first
+	Entries     []Entry // deny, then ask, then allow; deduplicated
+	DefaultMode string
+	ModeScope   config.ConfigScope
+}
+
+// precedence lists the settings layers from highest to lowest precedence.
+var precedence = []config.ConfigScope{config.ScopeManaged, config.ScopeLocal, config.ScopeProject, config.ScopeUser}
+
+// Load reads the permissions block of every settings layer for the project in
+// cwd and merges them. Permission lists are combined across layers; the
+// defaultMode of the highest-precedence layer that sets one wins.
+func Load(home, cwd string) (*Policy, error) {
+	p := &Policy{}
+	for _, scope := range precedence {
+		layer, err := readLayer(scope, home, cwd)
+		if err != nil {
+			return nil, err
+		}
+		p.Layers = append(p.Layers, layer)
+		if p.DefaultMode == "" && layer.DefaultMode != "" {
+			p.DefaultMode, p.ModeScope = layer.DefaultMode, scope
+		}
+	}
+	for _, kind := range kinds {
+		seen := make(map[string]bool)
+		for _, layer := range p.Layers {
+			for _, rule := range layer.Rules[kind] {
+				if seen[rule] {
+					continue
+				}
+				seen[rule] = true
+				p.Entries = append(p.Entries, Entry{Kind: kind, Rule: rule, Scope: layer.Scope, Path: layer.Path})
+			}
+		}
+	}
+	return p, nil
+}
+
+func readLayer(scope config.ConfigScope, home, cwd string) (Layer, error) {
+	layer := Layer{Scope: scope, Path: config.SettingsFile(scope, home, cwd), Rules: map[Decision][]string{}}
+	if !platform.FileExists(layer.Path) {
+		return layer, nil
+	}
+	var settings struct {
+		Permissions struct {
+			Allow       []string `json:"allow"`
+			Ask         []string `json:"ask"`
+			Deny        []string `json:"deny"`
+			DefaultMode string   `json:"defaultMode"`
+		} `json:"permissions"`
+	}
+	if err := platform.ReadJSONFile(layer.Path, &settings); err != nil {
+		if scope == config.ScopeManaged {
+			// Managed settings may need elevated privileges to read.
+			return layer, nil
+		}
+		return layer, fmt.Errorf("reading %s: %w", layer.Path, err)
+	}
+	layer.Exists = true
+	layer.Rules[DecisionAllow] = settings.Permissions.Allow
+	layer.Rules[DecisionAsk] = settings.Permissions.Ask
+	layer.Rules[DecisionDeny] = settings.Permissions.Deny
+	layer.DefaultMode = settings.Permissions.DefaultMode
+	return layer, nil
+}
+
+// Verdict is the result of evaluating one tool call, or one part of a
+// compound Bash command.
+type Verdict struct {
+	Call     string
+	Decision Decision
+	Entry    *Entry // the rule that decided it; nil for DecisionDefault
+}
+
+// Evaluate decides a tool call such as "Bash(git push origin main)" against
+// the policy. Compound Bash commands are split and each part evaluated: the
+// call is denied or needs approval if any part does, and is allowed only if
+// every part is. The overall verdict comes first, followed by one per part
+// when the command was split.
+func (p *Policy) Evaluate(call string, env Env) ([]Verdict, error) {
+	parsed, err := ParseRule(call)
+	if err != nil {
+		return nil, err
+	}
+	calls := []Rule{parsed}
+	if parsed.Tool == "Bash" && parsed.Specifier != "" {
+		if parts := splitCommand(parsed.Specifier); len(parts) > 1 {
+			calls = calls[:0]
+			for _, part := range parts {
+				calls = append(calls, Rule{Tool: "Bash", Specifier: part})
+			}
+		}
+	}
+
+	var parts []Verdict
+	for _, c := range calls {
+		parts = append(parts, p.decide(c, env))
+	}
+	if len(parts) == 1 {
+		return parts, nil
+	}
+	overall := Verdict{Call: parsed.String(), Decision: DecisionAllow}
+	for _, v := range parts {
+		if rank(v.Decision) < rank(overall.Decision) {
+			overall.Decision, overall.Entry = v.Decision, v.Entry
+		}
+	}
+	return append([]Verdict{overall}, parts...), nil
+}
+
+// decide returns the first entry matching call, checking deny, ask, and
+// allow rules in that order.
+func (p *Policy) decide(call Rule, env Env) Verdict {
+	for i := range p.Entries {
+		e := &p.Entries[i]
+		rule, err := ParseRule(e.Rule)
+		if err != nil {
+			continue
+		}
+		if rule.Matches(call, env) {
+			return Verdict{Call: call.String(), Decision: e.Kind, Entry: e}
+		}
+	}
+	return Verdict{Call: call.String(), Decision: DecisionDefault}
+}
+
+// rank orders decisions from most to least restrictive when combining the
+// parts of a compound command.
+func rank(d Decision) int {
+	switch d {
+	case DecisionDeny:
+		return 0
+	case DecisionAsk:
+		return 1
+	case DecisionDefault:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// File is a policy document applied with "policy apply --from". It uses a
+// small YAML subset:
+//
+//	name: acme-baseline
+//	scope: user
+//	deny:
+//	  - "Bash(kubectl delete *)"
+//	  - Read(./.env)
+//	ask: ["Bash(git push *)"]
+//	allow: []
+type File struct {
+	Name  string
+	Scope string
+	Rules map[Decision][]string
+}
+
+// ReadFile reads and validates a policy document.
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseFile(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+func parseFile(data string) (*File, error) {
+	entries, err := platform.ParseYAML([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Rules: map[Decision][]string{DecisionAllow: nil, DecisionAsk: nil, DecisionDeny: nil}}
+	for _, e := range entries {
+		switch e.Key {
+		case "name":
+			f.Name = e.Value
+		case "scope":
+			f.Scope = e.Value
+		case "allow", "ask", "deny":
+			rules, err := e.Values()
+			if err != nil {
+				return nil, err
+			}
+			f.Rules[Decision(e.Key)] = rules
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q (valid: name, scope, allow, ask, deny)", e.Line, e.Key)
+		}
+	}
+	for kind, rules := range f.Rules {
+		for _, rule := range rules {
+			if _, err := ParseRule(rule); err != nil {
+				return nil, fmt.Errorf("%s: %w", kind, err)
+			}
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/lamchakchan/claude-workspace/internal/orgpolicy"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// org handles "policy org [show|set|sync|unset]", which manages the signed
+// organization policy enforced in ~/.claude/settings.json.
+func org(w io.Writer, args []string) error {
+	subcmd := "show"
+	if len(args) > 0 {
+		subcmd = args[0]
+		args = args[1:]
+	}
+	if subcmd == "show" || subcmd == "sync" || subcmd == "unset" {
+		positional, _, err := parseArgs("org "+subcmd, "policy org "+subcmd, args)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 0 {
+			return fmt.Errorf("unexpected argument: %s (usage: claude-workspace policy org %s)", positional[0], subcmd)
+		}
+	}
+	switch subcmd {
+	case "show":
+		return orgShow(w)
+	case "set":
+		const usage = "policy org set <url> --key <public-key.pem>"
+		positional, flags, err := parseArgs("org set", usage, args, "--key")
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claude-workspace %s", usage)
+		}
+		return orgpolicy.Set(w, positional[0], flags["--key"])
+	case "sync":
+		cfg, err := orgpolicy.Load()
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			return fmt.Errorf("no org policy configured (run: claude-workspace policy org set <url> --key <file>)")
+		}
+		return orgpolicy.Sync(w, false)
+	case "unset":
+		if err := orgpolicy.Clear(); err != nil {
+			return err
+		}
+		platform.PrintSuccess(w, "Org policy removed. Its rules stay in ~/.claude/settings.json but are no longer managed.")
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown policy org subcommand: %s\n", subcmd)
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace policy org [show|set|sync|unset]")
+		return fmt.Errorf("unknown subcommand: %s", subcmd)
+	}
+}
+
+func orgShow(w io.Writer) error {
+	cfg, err := orgpolicy.Load()
+	if err != nil {
+		return err
+	}
+	platform.PrintBanner(w, "Org Policy")
+	fmt.Fprintln(w)
+	if cfg == nil {
+		fmt.Fprintln(w, "  No org policy configured.")
+		fmt.Fprintln(w)
+		platform.PrintCommand(w, "claude-workspace policy org set <url> --key <public-key.pem>")
+		fmt.Fprintln(w)
+		return nil
+	}
+	fmt.Fprintf(w, "  Policy:      %s\n", cfg.Label())
+	fmt.Fprintf(w, "  Source:      %s\n", cfg.URL)
+	fmt.Fprintf(w, "  Last synced: %s\n", cfg.SyncedAt)
+
+	platform.PrintSection(w, "Managed Settings")
+	for _, rule := range cfg.Managed.Deny {
+		fmt.Fprintf(w, "  deny   %s\n", rule)
+	}
+	for _, key := range sortedKeys(cfg.Managed.Env) {
+		fmt.Fprintf(w, "  env    %s=%s\n", key, cfg.Managed.Env[key])
+	}
+	if len(cfg.Managed.Deny) == 0 && len(cfg.Managed.Env) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+
+	drift, err := orgpolicy.Drift(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	if len(drift) == 0 {
+		platform.PrintSuccess(w, "All managed settings are in place.")
+	} else {
+		for _, d := range drift {
+			platform.PrintWarningLine(w, d)
+		}
+		platform.PrintCommand(w, "claude-workspace policy org sync")
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,411 @@
+// Package policy implements the "policy" command, which shows, edits, tests,
+// simulates, and applies the permission allow/ask/deny rules across Claude
+// Code's settings layers.
+package policy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/agents"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/orgpolicy"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/setup"
+)
+
+const usage = "Usage: claude-workspace policy [show|add-allow|add-ask|add-deny|remove|test|simulate|apply|org]"
+
+// Run routes the policy subcommand.
+func Run(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	return run(os.Stdout, args, Env{Home: home, Cwd: cwd})
+}
+
+func run(w io.Writer, args []string, env Env) error {
+	subcmd := "show"
+	if len(args) > 0 {
+		subcmd = args[0]
+		args = args[1:]
+	}
+	switch subcmd {
+	case "show":
+		return show(w, args, env)
+	case "add-allow", "add-ask", "add-deny":
+		return add(w, Decision(strings.TrimPrefix(subcmd, "add-")), args, env)
+	case "remove":
+		return remove(w, args, env)
+	case "test":
+		return test(w, args, env)
+	case "simulate":
+		return simulate(w, args, env)
+	case "apply":
+		return apply(w, args, env)
+	case "org":
+		return org(w, args)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown policy subcommand: %s\n", subcmd)
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown subcommand: %s", subcmd)
+	}
+}
+
+// parseScope maps a --scope value to a writable settings layer. "global" is
+// accepted as another name for the user layer.
+func parseScope(s string) (config.ConfigScope, error) {
+	switch s {
+	case "user", "global":
+		return config.ScopeUser, nil
+	case "project":
+		return config.ScopeProject, nil
+	case "local":
+		return config.ScopeLocal, nil
+	default:
+		return "", fmt.Errorf("invalid scope %q: must be global (user), project, or local", s)
+	}
+}
+
+// parseArgs parses the arguments of "policy <command>" (usage is its usage
+// line) and returns the positional arguments and the values of the given
+// value flags.
+func parseArgs(command, usage string, args []string, valueFlags ...string) (positional []string, flags map[string]string, err error) {
+	flags = make(map[string]string)
+	f := cli.New("policy "+command, usage)
+	for _, name := range valueFlags {
+		f.Func(name, policyFlags[name][0], policyFlags[name][1], func(v string) error {
+			flags[name] = v
+			return nil
+		})
+	}
+	positional, err = f.Parse(args)
+	return positional, flags, err
+}
+
+// policyFlags holds the placeholder and help of each flag parseArgs takes.
+var policyFlags = map[string][2]string{
+	"--scope": {"global|project|local", "Settings layer to change (default: project)"},
+	"--from":  {"policy.yaml", "Policy file to apply"},
+	"--with":  {"policy.yaml", "Policy file whose rules to simulate"},
+	"--key":   {"public-key.pem", "Public key the policy is signed with"},
+}
+
+// show handles "policy show [--effective]".
+func show(w io.Writer, args []string, env Env) error {
+	effective := false
+	f := cli.New("policy show", "policy show [--effective]")
+	f.BoolVar(&effective, "--effective", "Show the merged rules and the layer each comes from")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	p, err := Load(env.Home, env.Cwd)
+	if err != nil {
+		return err
+	}
+	if effective {
+		orgCfg, err := orgpolicy.Load()
+		if err != nil {
+			return err
+		}
+		printEffective(w, p, orgCfg)
+		return nil
+	}
+
+	platform.PrintBanner(w, "Permission Rules")
+	for _, layer := range p.Layers {
+		platform.PrintSection(w, fmt.Sprintf("%s (%s)", layer.Scope, layer.Path))
+		if !layer.Exists {
+			fmt.Fprintln(w, "  (no settings file)")
+			continue
+		}
+		empty := true
+		for _, kind := range kinds {
+			for _, rule := range layer.Rules[kind] {
+				fmt.Fprintf(w, "  %-6s %s\n", kind, rule)
+				empty = false
+			}
+		}
+		if layer.DefaultMode != "" {
+			fmt.Fprintf(w, "  %-6s %s\n", "mode", layer.DefaultMode)
+			empty = false
+		}
+		if empty {
+			fmt.Fprintln(w, "  (no permission rules)")
+		}
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func printEffective(w io.Writer, p *Policy, orgCfg *orgpolicy.Config) {
+	platform.PrintBanner(w, "Effective Permissions")
+	stricter := make(map[string]Decision)
+	for _, kind := range kinds {
+		platform.PrintSection(w, titleCase(string(kind)))
+		n := 0
+		for _, e := range p.Entries {
+			if e.Kind != kind {
+				continue
+			}
+			n++
+			note := ""
+			if d, ok := stricter[e.Rule]; ok {
+				note = fmt.Sprintf("  (overridden by %s)", d)
+			} else {
+				stricter[e.Rule] = kind
+			}
+			if _, err := ParseRule(e.Rule); err != nil {
+				note = "  (invalid: ignored)"
+			} else if kind == DecisionDeny && e.Scope == config.ScopeUser && orgCfg.IsManagedDeny(e.Rule) {
+				note = "  (org policy " + orgCfg.Label() + ")"
+			}
+			fmt.Fprintf(w, "  %-40s %s%s\n", e.Rule, e.Scope, note)
+		}
+		if n == 0 {
+			fmt.Fprintln(w, "  (none)")
+		}
+	}
+	platform.PrintSection(w, "Default Mode")
+	if p.DefaultMode == "" {
+		fmt.Fprintln(w, "  default  (prompt for anything not allowed)")
+	} else {
+		fmt.Fprintf(w, "  %s  (%s)\n", p.DefaultMode, p.ModeScope)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  Deny rules are checked first, then ask, then allow. Lists are combined")
+	fmt.Fprintln(w, "  across all layers; a deny in any layer cannot be overridden.")
+	fmt.Fprintln(w)
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// add handles "policy add-allow|add-ask|add-deny <rule> [--scope S]".
+func add(w io.Writer, kind Decision, args []string, env Env) error {
+	usage := fmt.Sprintf("policy add-%s <rule> [--scope global|project|local]", kind)
+	positional, flags, err := parseArgs("add-"+string(kind), usage, args, "--scope")
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+	rule, err := ParseRule(positional[0])
+	if err != nil {
+		return err
+	}
+	scope, err := parseScope(valueOr(flags["--scope"], "project"))
+	if err != nil {
+		return err
+	}
+	if !isKnownTool(rule.Tool) {
+		platform.PrintWarningLine(w, fmt.Sprintf("%s is not a known Claude Code tool", rule.Tool))
+	}
+
+	layer, err := readLayer(scope, env.Home, env.Cwd)
+	if err != nil {
+		return err
+	}
+	for _, existing := range layer.Rules[kind] {
+		if existing == rule.String() {
+			fmt.Fprintf(w, "%s is already in the %s list (%s)\n", rule, kind, scope)
+			return nil
+		}
+	}
+	if err := config.AppendToArray("permissions."+string(kind), rule.String(), scope, env.Home, env.Cwd); err != nil {
+		return err
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Added %s rule %s (%s: %s)", kind, rule, scope, layer.Path))
+	return nil
+}
+
+// remove handles "policy remove <rule> [--scope S]".
+func remove(w io.Writer, args []string, env Env) error {
+	const usage = "policy remove <rule> [--scope global|project|local]"
+	positional, flags, err := parseArgs("remove", usage, args, "--scope")
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+	rule := strings.TrimSpace(positional[0])
+	scope, err := parseScope(valueOr(flags["--scope"], "project"))
+	if err != nil {
+		return err
+	}
+
+	layer, err := readLayer(scope, env.Home, env.Cwd)
+	if err != nil {
+		return err
+	}
+	removed := false
+	for _, kind := range kinds {
+		for _, existing := range layer.Rules[kind] {
+			if existing != rule {
+				continue
+			}
+			if err := config.RemoveFromArray("permissions."+string(kind), rule, scope, env.Home, env.Cwd); err != nil {
+				return err
+			}
+			platform.PrintSuccess(w, fmt.Sprintf("Removed %s rule %s (%s: %s)", kind, rule, scope, layer.Path))
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return fmt.Errorf("rule %q not found in %s scope (%s)", rule, scope, layer.Path)
+	}
+	if scope == config.ScopeUser {
+		orgCfg, err := orgpolicy.Load()
+		if err != nil {
+			return err
+		}
+		if orgCfg.IsManagedDeny(rule) {
+			platform.PrintWarningLine(w, fmt.Sprintf("%s is required by org policy %s: doctor will report it missing and the next sync restores it.", rule, orgCfg.Label()))
+		}
+	}
+	return nil
+}
+
+// test handles "policy test <call>...", printing the decision for each tool
+// call and the rule that made it.
+func test(w io.Writer, args []string, env Env) error {
+	args, _, err := parseArgs("test", "policy test '<Tool(argument)>'...", args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: claude-workspace policy test '<Tool(argument)>'...\nExample: claude-workspace policy test 'Bash(git push --force origin main)'")
+	}
+	p, err := Load(env.Home, env.Cwd)
+	if err != nil {
+		return err
+	}
+	for _, call := range args {
+		verdicts, err := p.Evaluate(call, env)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %s\n", platform.Bold(verdicts[0].Call))
+		fmt.Fprintf(w, "  Decision: %s\n", describe(verdicts[0], p))
+		if len(verdicts) > 1 {
+			for _, v := range verdicts[1:] {
+				fmt.Fprintf(w, "    %-36s %s\n", v.Call, describe(v, p))
+			}
+		}
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func describe(v Verdict, p *Policy) string {
+	if v.Entry == nil {
+		mode := valueOr(p.DefaultMode, "default")
+		return fmt.Sprintf("%s (no rule matched; defaultMode %s applies)", v.Decision, mode)
+	}
+	return fmt.Sprintf("%s by %s (%s: %s)", v.Decision, v.Entry.Rule, v.Entry.Scope, v.Entry.Path)
+}
+
+// apply handles "policy apply --from <file> [--scope S]". Rules are merged
+// into the layer's existing lists; nothing is removed.
+func apply(w io.Writer, args []string, env Env) error {
+	const usage = "policy apply --from <policy.yaml> [--scope global|project|local]"
+	positional, flags, err := parseArgs("apply", usage, args, "--from", "--scope")
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || flags["--from"] == "" {
+		return fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+	f, err := ReadFile(flags["--from"])
+	if err != nil {
+		return err
+	}
+	scope, err := parseScope(valueOr(flags["--scope"], valueOr(f.Scope, "project")))
+	if err != nil {
+		return err
+	}
+
+	layer, err := readLayer(scope, env.Home, env.Cwd)
+	if err != nil {
+		return err
+	}
+	perms := make(map[string]interface{})
+	added, present := 0, 0
+	for _, kind := range kinds {
+		if len(f.Rules[kind]) == 0 {
+			continue
+		}
+		perms[string(kind)] = f.Rules[kind]
+		have := make(map[string]bool)
+		for _, rule := range layer.Rules[kind] {
+			have[rule] = true
+		}
+		for _, rule := range f.Rules[kind] {
+			if have[rule] {
+				present++
+				continue
+			}
+			have[rule] = true
+			added++
+			fmt.Fprintf(w, "  + %-6s %s\n", kind, rule)
+		}
+	}
+
+	err = platform.WithFileLock(layer.Path, func() error {
+		existing := make(map[string]interface{})
+		if platform.FileExists(layer.Path) {
+			if err := platform.ReadJSONFile(layer.Path, &existing); err != nil {
+				return fmt.Errorf("reading %s: %w", layer.Path, err)
+			}
+		}
+		merged := setup.MergeSettings(existing, map[string]interface{}{"permissions": perms})
+		return platform.WriteSettingsFile(layer.Path, merged)
+	})
+	if err != nil {
+		return err
+	}
+
+	name := valueOr(f.Name, filepath.Base(flags["--from"]))
+	platform.PrintSuccess(w, fmt.Sprintf("Applied policy %s to %s (%s): %d rule(s) added, %d already present", name, scope, layer.Path, added, present))
+	return nil
+}
+
+func isKnownTool(tool string) bool {
+	if strings.HasPrefix(tool, "mcp__") || strings.Contains(tool, "*") {
+		return true
+	}
+	for _, known := range agents.KnownTools {
+		if known == tool {
+			return true
+		}
+	}
+	return false
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
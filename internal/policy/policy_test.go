@@ -0,0 +1,214 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func testEnv(t *testing.T) Env {
+	t.Helper()
+	env := Env{Home: t.TempDir(), Cwd: t.TempDir()}
+	t.Setenv("HOME", env.Home)
+	return env
+}
+
+func writeSettings(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAndEvaluate(t *testing.T) {
+	env := testEnv(t)
+	writeSettings(t, filepath.Join(env.Home, ".claude", "settings.json"), `{
+		"permissions": {"allow": ["Bash(git *)"], "deny": ["Bash(git push --force *)"], "defaultMode": "acceptEdits"},
+		"model": "opus"
+	}`)
+	writeSettings(t, filepath.Join(env.Cwd, ".claude", "settings.json"), `{
+		"permissions": {"allow": ["Bash(make *)", "Bash(git *)"], "ask": ["Bash(git push *)"]}
+	}`)
+
+	p, err := Load(env.Home, env.Cwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.DefaultMode != "acceptEdits" || p.ModeScope != config.ScopeUser {
+		t.Errorf("default mode = %q from %s", p.DefaultMode, p.ModeScope)
+	}
+	var got []string
+	for _, e := range p.Entries {
+		got = append(got, string(e.Kind)+" "+e.Rule+" "+string(e.Scope))
+	}
+	want := []string{
+		"deny Bash(git push --force *) user",
+		"ask Bash(git push *) project",
+		"allow Bash(make *) project",
+		"allow Bash(git *) project",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		call string
+		want []Decision
+	}{
+		{"Bash(git push --force origin main)", []Decision{DecisionDeny}},
+		{"Bash(git push origin main)", []Decision{DecisionAsk}},
+		{"Bash(git status)", []Decision{DecisionAllow}},
+		{"Bash(npm install)", []Decision{DecisionDefault}},
+		{"Bash(make && git status)", []Decision{DecisionAllow, DecisionAllow, DecisionAllow}},
+		{"Bash(make && npm install)", []Decision{DecisionDefault, DecisionAllow, DecisionDefault}},
+		{"Bash(git status; git push -f origin)", []Decision{DecisionAsk, DecisionAllow, DecisionAsk}},
+		{"Bash(git status; git push --force x)", []Decision{DecisionDeny, DecisionAllow, DecisionDeny}},
+	}
+	for _, tt := range tests {
+		verdicts, err := p.Evaluate(tt.call, env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decisions []Decision
+		for _, v := range verdicts {
+			decisions = append(decisions, v.Decision)
+		}
+		if !reflect.DeepEqual(decisions, tt.want) {
+			t.Errorf("Evaluate(%s) = %v, want %v", tt.call, decisions, tt.want)
+		}
+	}
+}
+
+func TestAddAndRemove(t *testing.T) {
+	env := testEnv(t)
+	userPath := filepath.Join(env.Home, ".claude", "settings.json")
+	writeSettings(t, userPath, `{"model": "opus", "permissions": {"allow": ["Read"]}}`)
+
+	var out bytes.Buffer
+	if err := run(&out, []string{"add-deny", "Bash(kubectl delete *)", "--scope", "global"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(&out, []string{"add-deny", "--scope=global", "Bash(kubectl delete *)"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "already in the deny list") {
+		t.Errorf("adding a rule twice should be a no-op, got:\n%s", out.String())
+	}
+	if err := run(&out, []string{"add-ask", "Bash(git push *)"}, env); err != nil {
+		t.Fatal(err)
+	}
+
+	var settings struct {
+		Model       string              `json:"model"`
+		Permissions map[string][]string `json:"permissions"`
+	}
+	if err := platform.ReadJSONFile(userPath, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Model != "opus" || !reflect.DeepEqual(settings.Permissions["deny"], []string{"Bash(kubectl delete *)"}) {
+		t.Errorf("user settings = %+v", settings)
+	}
+	projectLayer, _ := readLayer(config.ScopeProject, env.Home, env.Cwd)
+	if !reflect.DeepEqual(projectLayer.Rules[DecisionAsk], []string{"Bash(git push *)"}) {
+		t.Errorf("add-ask should default to the project scope, got %v", projectLayer.Rules)
+	}
+
+	if err := run(&out, []string{"remove", "Bash(kubectl delete *)", "--scope", "user"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(&out, []string{"remove", "Bash(kubectl delete *)", "--scope", "user"}, env); err == nil {
+		t.Error("removing a missing rule should fail")
+	}
+	for _, args := range [][]string{
+		{"add-deny", "Bash(ls", "--scope", "user"},
+		{"add-deny", "Bash", "--scope", "managed"},
+		{"add-deny", "Bash", "--force"},
+		{"add-deny"},
+	} {
+		if err := run(&out, args, env); err == nil {
+			t.Errorf("policy %v should fail", args)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	env := testEnv(t)
+	projectPath := filepath.Join(env.Cwd, ".claude", "settings.json")
+	writeSettings(t, projectPath, `{"permissions": {"deny": ["Read(./.env)"], "allow": ["Bash(make *)"]}, "env": {"A": "1"}}`)
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	writeSettings(t, policyPath, `# Org baseline
+name: acme-baseline
+deny:
+  - "Bash(kubectl delete *)"
+  - Read(./.env)
+ask: ["Bash(git push *)", "Bash(echo a, b)"]
+`)
+
+	var out bytes.Buffer
+	if err := run(&out, []string{"apply", "--from", policyPath}, env); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "acme-baseline") || !strings.Contains(out.String(), "3 rule(s) added, 1 already present") {
+		t.Errorf("output:\n%s", out.String())
+	}
+	layer, err := readLayer(config.ScopeProject, env.Home, env.Cwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[Decision][]string{
+		DecisionDeny:  {"Read(./.env)", "Bash(kubectl delete *)"},
+		DecisionAsk:   {"Bash(git push *)", "Bash(echo a, b)"},
+		DecisionAllow: {"Bash(make *)"},
+	}
+	if !reflect.DeepEqual(layer.Rules, want) {
+		t.Errorf("rules = %v, want %v", layer.Rules, want)
+	}
+	var settings map[string]interface{}
+	if err := platform.ReadJSONFile(projectPath, &settings); err != nil || settings["env"] == nil {
+		t.Errorf("apply should keep other settings: %v, %v", settings, err)
+	}
+}
+
+func TestParseFile_Comments(t *testing.T) {
+	f, err := parseFile(`# team baseline
+name: acme # inline comment
+deny:
+  - "Bash(git commit -m \"fix #12\")" # quoted hash
+  - 'Bash(echo #tag)'
+ask: ["Bash(gh issue view #3)"] # flow list
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[Decision][]string{
+		DecisionAllow: nil,
+		DecisionAsk:   {"Bash(gh issue view #3)"},
+		DecisionDeny:  {`Bash(git commit -m "fix #12")`, "Bash(echo #tag)"},
+	}
+	if f.Name != "acme" || !reflect.DeepEqual(f.Rules, want) {
+		t.Errorf("parseFile = %q %q, want %q", f.Name, f.Rules, want)
+	}
+}
+
+func TestParseFile_Errors(t *testing.T) {
+	for _, data := range []string{
+		"deny: Bash",
+		"deny:\n  - Bash(ls\n",
+		"allowed: []",
+		"name: a\nname: b",
+		"- Bash",
+	} {
+		if _, err := parseFile(data); err == nil {
+			t.Errorf("parseFile(%q) should fail", data)
+		}
+	}
+}
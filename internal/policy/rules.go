@@ -0,0 +1,253 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Decision is the outcome of evaluating a tool call against permission rules.
+type Decision string
+
+const (
+	DecisionDeny    Decision = "deny"
+	DecisionAsk     Decision = "ask"
+	DecisionAllow   Decision = "allow"
+	DecisionDefault Decision = "default" // no rule matched; defaultMode applies
+)
+
+// Rule is a parsed permission rule such as "Bash(npm run test:*)" or "Read".
+type Rule struct {
+	Tool      string
+	Specifier string // empty when the rule covers every use of the tool
+}
+
+// ParseRule parses a permission rule or a tool call written in the same
+// Tool(specifier) form.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	tool, spec, hasSpec := strings.Cut(s, "(")
+	if hasSpec {
+		if !strings.HasSuffix(spec, ")") {
+			return Rule{}, fmt.Errorf("invalid rule %q: missing closing parenthesis", s)
+		}
+		spec = spec[:len(spec)-1]
+	}
+	if tool == "" {
+		return Rule{}, fmt.Errorf("invalid rule %q: missing tool name", s)
+	}
+	for _, r := range tool {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '*') {
+			return Rule{}, fmt.Errorf("invalid rule %q: tool name may only contain letters, digits, '_', '-', and '*'", s)
+		}
+	}
+	if spec == "*" {
+		spec = ""
+	}
+	return Rule{Tool: tool, Specifier: spec}, nil
+}
+
+// String formats r the way it is written in settings.json.
+func (r Rule) String() string {
+	if r.Specifier == "" {
+		return r.Tool
+	}
+	return r.Tool + "(" + r.Specifier + ")"
+}
+
+// toolFamilies lists the tools a Read or Edit rule also applies to.
+var toolFamilies = map[string][]string{
+	"Read": {"Read", "Grep", "Glob", "LS", "NotebookRead"},
+	"Edit": {"Edit", "Write", "MultiEdit", "NotebookEdit"},
+}
+
+// Env holds the directories path rules are resolved against.
+type Env struct {
+	Home string
+	Cwd  string
+}
+
+// Matches reports whether rule applies to the tool call call.
+func (r Rule) Matches(call Rule, env Env) bool {
+	if !r.matchesTool(call.Tool) {
+		return false
+	}
+	if r.Specifier == "" {
+		return true
+	}
+	if call.Specifier == "" {
+		return false
+	}
+	switch {
+	case r.Tool == "Bash":
+		return matchCommand(r.Specifier, call.Specifier)
+	case toolFamilies[r.Tool] != nil:
+		return matchPath(r.Specifier, call.Specifier, env)
+	case r.Tool == "WebFetch":
+		return matchDomain(r.Specifier, call.Specifier)
+	default:
+		return wildcardMatch(r.Specifier, call.Specifier)
+	}
+}
+
+func (r Rule) matchesTool(tool string) bool {
+	if family, ok := toolFamilies[r.Tool]; ok {
+		for _, t := range family {
+			if t == tool {
+				return true
+			}
+		}
+		return false
+	}
+	// "mcp__server" covers every tool the server provides.
+	if strings.HasPrefix(r.Tool, "mcp__") && !strings.Contains(strings.TrimPrefix(r.Tool, "mcp__"), "__") {
+		return tool == r.Tool || strings.HasPrefix(tool, r.Tool+"__")
+	}
+	return wildcardMatch(r.Tool, tool)
+}
+
+// matchCommand matches a Bash rule specifier against a single command.
+// "prefix:*" is the legacy spelling of "prefix *". A trailing " *" also
+// matches the bare command, so "git push *" matches "git push".
+func matchCommand(pattern, command string) bool {
+	command = strings.TrimSpace(command)
+	if prefix, ok := strings.CutSuffix(pattern, ":*"); ok {
+		pattern = prefix + " *"
+	}
+	if prefix, ok := strings.CutSuffix(pattern, " *"); ok && command == prefix {
+		return true
+	}
+	return wildcardMatch(pattern, command)
+}
+
+// wildcardMatch reports whether s matches pattern, where '*' matches any run
+// of characters and everything else is literal.
+func wildcardMatch(pattern, s string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == s
+	}
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	re, err := regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+	return err == nil && re.MatchString(s)
+}
+
+// splitCommand splits a shell command on &&, ||, ;, |, and newlines outside
+// of quotes, so that every part of a compound command is checked.
+func splitCommand(command string) []string {
+	var parts []string
+	var cur strings.Builder
+	var quote rune
+	flush := func() {
+		if p := strings.TrimSpace(cur.String()); p != "" {
+			parts = append(parts, p)
+		}
+		cur.Reset()
+	}
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ';' || c == '\n':
+			flush()
+			continue
+		case c == '&' && i+1 < len(runes) && runes[i+1] == '&',
+			c == '|':
+			if i+1 < len(runes) && runes[i+1] == c {
+				i++
+			}
+			flush()
+			continue
+		}
+		cur.WriteRune(c)
+	}
+	flush()
+	return parts
+}
+
+// matchPath matches a Read or Edit rule against a file path using gitignore
+// conventions: "//p" is absolute, "~/p" is under the home directory, "/p" is
+// relative to the project root, and a pattern without a slash matches a file
+// name at any depth. A pattern that matches a directory covers its contents.
+func matchPath(pattern, target string, env Env) bool {
+	target = resolvePath(target, env)
+	anyDepth := !strings.Contains(pattern, "/")
+	switch {
+	case strings.HasPrefix(pattern, "//"):
+		pattern = pattern[1:]
+	case strings.HasPrefix(pattern, "~/"):
+		pattern = path.Join(filepath.ToSlash(env.Home), pattern[2:])
+	case strings.HasPrefix(pattern, "/"):
+		pattern = path.Join(filepath.ToSlash(env.Cwd), pattern[1:])
+	case anyDepth:
+		pattern = "**/" + pattern
+		fallthrough
+	default:
+		pattern = path.Join(filepath.ToSlash(env.Cwd), pattern)
+	}
+	re, err := regexp.Compile("^" + globRegexp(pattern) + "(/.*)?$")
+	return err == nil && re.MatchString(target)
+}
+
+// resolvePath makes target absolute and slash-separated.
+func resolvePath(target string, env Env) string {
+	target = filepath.ToSlash(target)
+	switch {
+	case strings.HasPrefix(target, "~/"):
+		target = path.Join(filepath.ToSlash(env.Home), target[2:])
+	case !path.IsAbs(target):
+		target = path.Join(filepath.ToSlash(env.Cwd), target)
+	}
+	return path.Clean(target)
+}
+
+// globRegexp translates a glob with '**' support into a regular expression.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// matchDomain matches a "domain:host" WebFetch rule against a URL or a
+// "domain:host" call. '*' in the host matches any run of characters.
+func matchDomain(pattern, target string) bool {
+	domain, ok := strings.CutPrefix(pattern, "domain:")
+	if !ok {
+		return wildcardMatch(pattern, target)
+	}
+	host, ok := strings.CutPrefix(target, "domain:")
+	if !ok {
+		u, err := url.Parse(target)
+		if err != nil || u.Hostname() == "" {
+			return false
+		}
+		host = u.Hostname()
+	}
+	return wildcardMatch(strings.ToLower(domain), strings.ToLower(host))
+}
@@ -0,0 +1,107 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rule
+		wantErr bool
+	}{
+		{in: "Bash", want: Rule{Tool: "Bash"}},
+		{in: "Bash(*)", want: Rule{Tool: "Bash"}},
+		{in: "Bash(npm run test:*)", want: Rule{Tool: "Bash", Specifier: "npm run test:*"}},
+		{in: "Bash(echo (hi))", want: Rule{Tool: "Bash", Specifier: "echo (hi)"}},
+		{in: "mcp__github__create_issue", want: Rule{Tool: "mcp__github__create_issue"}},
+		{in: "Bash(ls", wantErr: true},
+		{in: "(ls)", wantErr: true},
+		{in: "Bash Tool", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRule(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	env := Env{Home: "/home/dev", Cwd: "/work/app"}
+	tests := []struct {
+		rule, call string
+		want       bool
+	}{
+		{"Bash", "Bash(rm -rf /)", true},
+		{"Bash(npm run test:*)", "Bash(npm run test -- --watch)", true},
+		{"Bash(npm run test:*)", "Bash(npm run test)", true},
+		{"Bash(npm run test:*)", "Bash(npm run testing)", false},
+		{"Bash(ls *)", "Bash(ls -la)", true},
+		{"Bash(ls *)", "Bash(lsof)", false},
+		{"Bash(git push * main)", "Bash(git push --force origin main)", true},
+		{"Bash(git push * main)", "Bash(git push origin dev)", false},
+		{"Bash(git status)", "Bash(git status)", true},
+		{"Bash(git status)", "Bash(git status -s)", false},
+		{"Bash(git status)", "Bash", false},
+
+		{"Read(./.env)", "Read(.env)", true},
+		{"Read(./.env)", "Read(config/.env)", false},
+		{"Read(.env)", "Read(config/.env)", true},
+		{"Read(*.pem)", "Read(/work/app/certs/server.pem)", true},
+		{"Read(~/.ssh/**)", "Read(/home/dev/.ssh/id_ed25519)", true},
+		{"Read(//etc/**)", "Read(/etc/passwd)", true},
+		{"Read(/secrets)", "Read(secrets/prod/key.json)", true},
+		{"Read(/secrets)", "Grep(secrets/prod/key.json)", true},
+		{"Read(/secrets)", "Edit(secrets/prod/key.json)", false},
+		{"Edit(src/**/*.ts)", "Write(src/a/b/c.ts)", true},
+		{"Edit(src/**/*.ts)", "Write(src/c.ts)", true},
+		{"Edit(src/*.ts)", "Edit(src/a/c.ts)", false},
+
+		{"WebFetch(domain:github.com)", "WebFetch(https://github.com/org/repo)", true},
+		{"WebFetch(domain:github.com)", "WebFetch(https://api.github.com/)", false},
+		{"WebFetch(domain:*.github.com)", "WebFetch(https://api.github.com/)", true},
+		{"WebFetch(domain:github.com)", "WebFetch(domain:GitHub.com)", true},
+
+		{"mcp__github", "mcp__github__create_issue", true},
+		{"mcp__github", "mcp__githubx__create_issue", false},
+		{"mcp__github__*", "mcp__github__create_issue", true},
+		{"mcp__github__get_issue", "mcp__github__create_issue", false},
+		{"Task(Explore)", "Task(Explore)", true},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRule(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		call, err := ParseRule(tt.call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rule.Matches(call, env); got != tt.want {
+			t.Errorf("%s matches %s = %v, want %v", tt.rule, tt.call, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"git status", []string{"git status"}},
+		{"make && make test || echo fail; ls | wc -l", []string{"make", "make test", "echo fail", "ls", "wc -l"}},
+		{`echo "a && b" && ls`, []string{`echo "a && b"`, "ls"}},
+		{"go test ./... 2>&1", []string{"go test ./... 2>&1"}},
+	}
+	for _, tt := range tests {
+		if got := splitCommand(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/config"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+// simulation is the outcome of replaying one distinct tool call.
+type simulation struct {
+	Call     string
+	Count    int // times the session made the call
+	Current  Verdict
+	Proposed Verdict
+}
+
+// simulate handles "policy simulate <session-id> [--with <policy.yaml>]",
+// replaying a recorded session's tool calls through the effective rules and,
+// with --with, through the rules a policy file would add.
+func simulate(w io.Writer, args []string, env Env) error {
+	const usage = "policy simulate <session-id> [--with <policy.yaml>]"
+	positional, flags, err := parseArgs("simulate", usage, args, "--with")
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: claude-workspace %s", usage)
+	}
+	s, err := sessions.Find(positional[0])
+	if err != nil {
+		return err
+	}
+	t, err := sessions.ParseTranscript(s.Path)
+	if err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+	// Project settings and relative path rules belong to the directory the
+	// session ran in.
+	if t.Project != "" && platform.FileExists(t.Project) {
+		env.Cwd = t.Project
+	}
+
+	current, err := Load(env.Home, env.Cwd)
+	if err != nil {
+		return err
+	}
+	proposed := current
+	var f *File
+	if flags["--with"] != "" {
+		if f, err = ReadFile(flags["--with"]); err != nil {
+			return err
+		}
+		scope, err := parseScope(valueOr(f.Scope, "project"))
+		if err != nil {
+			return err
+		}
+		proposed = current.with(f, scope, config.SettingsFile(scope, env.Home, env.Cwd))
+	}
+
+	results := replay(toolCalls(t), current, proposed, env)
+
+	platform.PrintBanner(w, "Permission Simulation: "+s.ID)
+	fmt.Fprintf(w, "  Session:  %s\n", valueOr(s.Title, "(untitled)"))
+	fmt.Fprintf(w, "  Project:  %s\n", env.Cwd)
+	if f != nil {
+		fmt.Fprintf(w, "  Proposed: %s (%s)\n", valueOr(f.Name, filepath.Base(flags["--with"])), flags["--with"])
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "  The session made no tool calls.")
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	printSimulationSummary(w, results, f != nil)
+	if f != nil {
+		printChanged(w, results, proposed)
+	} else {
+		printNotAllowed(w, results, current)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// toolCalls returns the session's tool calls in Tool(specifier) form, in the
+// order they were made.
+func toolCalls(t *sessions.Transcript) []string {
+	var calls []string
+	for _, m := range t.Messages {
+		for _, b := range m.Blocks {
+			if b.Type == "tool_use" && b.Tool != "" {
+				calls = append(calls, CallString(b.Tool, b.Input))
+			}
+		}
+	}
+	return calls
+}
+
+// CallString formats a tool call the way permission rules match it: the
+// command for Bash, the file for file tools, the URL for WebFetch, and the
+// tool name alone for everything else.
+func CallString(tool string, input json.RawMessage) string {
+	var in struct {
+		Command      string `json:"command"`
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+		Path         string `json:"path"`
+		URL          string `json:"url"`
+	}
+	_ = json.Unmarshal(input, &in)
+	spec := ""
+	switch tool {
+	case "Bash":
+		spec = in.Command
+	case "Read", "Write", "Edit", "MultiEdit":
+		spec = in.FilePath
+	case "NotebookRead", "NotebookEdit":
+		spec = in.NotebookPath
+	case "Glob", "Grep", "LS":
+		spec = in.Path
+	case "WebFetch":
+		spec = in.URL
+	}
+	return Rule{Tool: tool, Specifier: spec}.String()
+}
+
+// replay evaluates each distinct call against both policies, keeping the
+// order in which calls first appear.
+func replay(calls []string, current, proposed *Policy, env Env) []simulation {
+	var results []simulation
+	index := make(map[string]int)
+	for _, call := range calls {
+		if i, ok := index[call]; ok {
+			results[i].Count++
+			continue
+		}
+		// Calls that rule syntax cannot express, such as a tool name with
+		// unusual characters, are skipped.
+		cur, err := current.Evaluate(call, env)
+		if err != nil {
+			continue
+		}
+		prop, err := proposed.Evaluate(call, env)
+		if err != nil {
+			continue
+		}
+		index[call] = len(results)
+		results = append(results, simulation{Call: call, Count: 1, Current: cur[0], Proposed: prop[0]})
+	}
+	return results
+}
+
+// with returns a copy of p with the rules of f added to the layer at scope,
+// the way "policy apply" would merge them.
+func (p *Policy) with(f *File, scope config.ConfigScope, path string) *Policy {
+	out := &Policy{Layers: p.Layers, DefaultMode: p.DefaultMode, ModeScope: p.ModeScope}
+	for _, kind := range kinds {
+		seen := make(map[string]bool)
+		for _, e := range p.Entries {
+			if e.Kind == kind {
+				seen[e.Rule] = true
+				out.Entries = append(out.Entries, e)
+			}
+		}
+		for _, rule := range f.Rules[kind] {
+			if !seen[rule] {
+				seen[rule] = true
+				out.Entries = append(out.Entries, Entry{Kind: kind, Rule: rule, Scope: scope, Path: path})
+			}
+		}
+	}
+	return out
+}
+
+func printSimulationSummary(w io.Writer, results []simulation, proposed bool) {
+	current := make(map[Decision]int)
+	after := make(map[Decision]int)
+	total := 0
+	for _, r := range results {
+		current[r.Current.Decision] += r.Count
+		after[r.Proposed.Decision] += r.Count
+		total += r.Count
+	}
+	platform.PrintSection(w, fmt.Sprintf("Summary (%d tool calls, %d distinct)", total, len(results)))
+	if proposed {
+		fmt.Fprintf(w, "  %-10s %8s %9s\n", "DECISION", "CURRENT", "PROPOSED")
+	} else {
+		fmt.Fprintf(w, "  %-10s %8s\n", "DECISION", "CALLS")
+	}
+	for _, d := range []Decision{DecisionDeny, DecisionAsk, DecisionAllow, DecisionDefault} {
+		if proposed {
+			fmt.Fprintf(w, "  %-10s %8d %9d\n", d, current[d], after[d])
+		} else {
+			fmt.Fprintf(w, "  %-10s %8d\n", d, current[d])
+		}
+	}
+}
+
+// printChanged lists the calls whose decision the proposed rules change.
+func printChanged(w io.Writer, results []simulation, p *Policy) {
+	var changed []simulation
+	for _, r := range results {
+		if r.Current.Decision != r.Proposed.Decision {
+			changed = append(changed, r)
+		}
+	}
+	platform.PrintSection(w, fmt.Sprintf("Changed (%d)", len(changed)))
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "  The proposed rules decide every call the same way.")
+		return
+	}
+	for _, r := range changed {
+		fmt.Fprintf(w, "  %s%s\n", oneLine(r.Call), times(r.Count))
+		fmt.Fprintf(w, "    %s → %s\n", r.Current.Decision, describe(r.Proposed, p))
+	}
+}
+
+// printNotAllowed lists the calls the current rules deny, ask about, or leave
+// to the default mode.
+func printNotAllowed(w io.Writer, results []simulation, p *Policy) {
+	n := 0
+	platform.PrintSection(w, "Not Allowed")
+	for _, r := range results {
+		if r.Current.Decision == DecisionAllow {
+			continue
+		}
+		n++
+		fmt.Fprintf(w, "  %s%s\n", oneLine(r.Call), times(r.Count))
+		fmt.Fprintf(w, "    %s\n", describe(r.Current, p))
+	}
+	if n == 0 {
+		fmt.Fprintln(w, "  Every call is allowed by a rule.")
+	}
+}
+
+// oneLine shortens a call for display, since Bash commands can span many
+// lines.
+func oneLine(call string) string {
+	call = strings.Join(strings.Fields(call), " ")
+	if len(call) > 120 {
+		call = call[:117] + "..."
+	}
+	return call
+}
+
+func times(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return fmt.Sprintf("  (×%d)", n)
+}
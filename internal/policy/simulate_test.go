@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCallString(t *testing.T) {
+	tests := []struct {
+		tool, input, want string
+	}{
+		{"Bash", `{"command":"git status","description":"x"}`, "Bash(git status)"},
+		{"Edit", `{"file_path":"/p/main.go","old_string":"a"}`, "Edit(/p/main.go)"},
+		{"NotebookEdit", `{"notebook_path":"a.ipynb"}`, "NotebookEdit(a.ipynb)"},
+		{"Grep", `{"pattern":"TODO"}`, "Grep"},
+		{"WebFetch", `{"url":"https://docs.example.com/a","prompt":"x"}`, "WebFetch(https://docs.example.com/a)"},
+		{"mcp__github__create_issue", `{"title":"x"}`, "mcp__github__create_issue"},
+		{"TodoWrite", `not json`, "TodoWrite"},
+	}
+	for _, tt := range tests {
+		if got := CallString(tt.tool, json.RawMessage(tt.input)); got != tt.want {
+			t.Errorf("CallString(%s, %s) = %q, want %q", tt.tool, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	env := testEnv(t)
+	writeSettings(t, filepath.Join(env.Cwd, ".claude", "settings.json"), `{
+		"permissions": {"allow": ["Bash(git *)", "Read"], "deny": ["Read(./.env)"]}
+	}`)
+	records := []string{
+		`{"type":"user","cwd":"` + env.Cwd + `","timestamp":"2026-01-02T10:00:00Z","message":{"role":"user","content":"ship it"}}`,
+		`{"type":"assistant","timestamp":"2026-01-02T10:00:01Z","message":{"id":"m1","role":"assistant","content":[` +
+			`{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"git status"}},` +
+			`{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"` + env.Cwd + `/.env"}},` +
+			`{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"git push origin main"}},` +
+			`{"type":"tool_use","id":"t4","name":"Bash","input":{"command":"git push origin main"}},` +
+			`{"type":"tool_use","id":"t5","name":"Bash","input":{"command":"npm test"}}]}}`,
+	}
+	writeSettings(t, filepath.Join(env.Home, ".claude", "projects", strings.ReplaceAll(env.Cwd, "/", "-"), "8a3f1b2c-0000-4000-8000-000000000000.jsonl"),
+		strings.Join(records, "\n")+"\n")
+
+	var out bytes.Buffer
+	if err := run(&out, []string{"simulate", "8a3f1b2c"}, env); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Summary (5 tool calls, 4 distinct)",
+		"Read(" + env.Cwd + "/.env)\n    deny by Read(./.env)",
+		"Bash(npm test)\n    default",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("simulate output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "git status") {
+		t.Errorf("allowed calls should not be listed:\n%s", out.String())
+	}
+
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	writeSettings(t, policyPath, "name: no-push\ndeny:\n  - \"Bash(git push *)\"\nallow: [\"Bash(git status)\"]\n")
+	out.Reset()
+	if err := run(&out, []string{"simulate", "8a3f1b2c", "--with", policyPath}, env); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Proposed: no-push",
+		"deny              1         3",
+		"Changed (1)",
+		"Bash(git push origin main)  (×2)\n    allow → deny by Bash(git push *) (project: ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("simulate --with output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	for _, args := range [][]string{
+		{"simulate"},
+		{"simulate", "ffffffff"},
+		{"simulate", "8a3f1b2c", "--with", filepath.Join(t.TempDir(), "missing.yaml")},
+	} {
+		if err := run(&out, args, env); err == nil {
+			t.Errorf("policy %v should fail", args)
+		}
+	}
+}
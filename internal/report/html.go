@@ -0,0 +1,95 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Workspace report: {{.Host}}</title>
+<style>
+body { font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 920px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+header dl { display: grid; grid-template-columns: max-content 1fr; gap: .2em 1em; color: #59636e; }
+header dd { margin: 0; font-family: ui-monospace, monospace; }
+h2 { font-size: 1.1em; border-top: 1px solid #d1d9e0; padding-top: .75em; margin-top: 1.5em; }
+table { border-collapse: collapse; width: 100%; font-size: 14px; }
+th, td { text-align: left; padding: .3em .75em .3em 0; border-bottom: 1px solid #eef1f4; vertical-align: top; }
+th { color: #59636e; font-weight: 600; }
+code, .path { font-family: ui-monospace, monospace; font-size: 13px; }
+.muted { color: #59636e; }
+.ok { color: #1a7f37; }
+.warn { color: #9a6700; }
+.fail { color: #cf222e; }
+</style>
+</head>
+<body>
+<header>
+<h1>Workspace report</h1>
+<dl>
+<dt>Host</dt><dd>{{.Host}} ({{.OS}}/{{.Arch}})</dd>
+<dt>Version</dt><dd>claude-workspace {{.Version}}</dd>
+<dt>Generated</dt><dd>{{date .GeneratedAt}}</dd>
+</dl>
+</header>
+
+<h2>Attached projects ({{len .Projects}})</h2>
+{{- if .Projects}}
+<table>
+<tr><th>Project</th><th>Version</th><th>Profile</th><th>Attached</th></tr>
+{{- range .Projects}}
+<tr><td class="path">{{.Path}}</td>{{if .Version}}<td>{{.Version}}</td><td>{{.Profile}}</td><td>{{.AttachedAt}}</td>{{else}}<td colspan="3" class="muted">no lock file</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- end}}
+<p class="muted">Scanned: {{range $i, $d := .ScannedDirs}}{{if $i}}, {{end}}<code>{{$d}}</code>{{else}}no code directories found{{end}}</p>
+
+<h2>MCP servers ({{len .MCPServers}})</h2>
+{{- if .MCPServers}}
+<table>
+<tr><th>Name</th><th>Scope</th></tr>
+{{- range .MCPServers}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Scope}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+<h2>Memory</h2>
+<p>Provider: <code>{{.Memory.Provider}}</code></p>
+
+<h2>Cost (last {{.Cost.Days}} days)</h2>
+{{- if .Cost.Error}}
+<p class="warn">Unavailable: {{.Cost.Error}}</p>
+{{- else}}
+<p><strong>${{printf "%.2f" .Cost.Total}}</strong> since {{.Cost.Since}} ({{.Cost.Active}} active day(s))</p>
+{{- end}}
+
+<h2>Doctor</h2>
+{{- if .Doctor.Error}}
+<p class="fail">{{.Doctor.Error}}</p>
+{{- else if .Doctor.Healthy}}
+<p class="ok">Healthy ({{.Doctor.Warnings}} warning(s))</p>
+{{- else}}
+<p class="fail">{{.Doctor.Issues}} issue(s), {{.Doctor.Warnings}} warning(s)</p>
+{{- end}}
+{{- if .Doctor.Problems}}
+<table>
+<tr><th>Status</th><th>Section</th><th>Message</th></tr>
+{{- range .Doctor.Problems}}
+<tr><td class="{{.Status}}">{{.Status}}</td><td>{{.Section}}</td><td>{{.Message}}{{if .Remediation}}<br><span class="muted">{{.Remediation}}</span>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders r as a self-contained HTML page.
+func WriteHTML(w io.Writer, r *Report) error {
+	return htmlTemplate.Execute(w, r)
+}
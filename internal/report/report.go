@@ -0,0 +1,359 @@
+// Package report implements the "report" command, which takes an inventory
+// of the platform on this machine: the claude-workspace version, attached
+// projects, MCP servers, memory provider, recent spend, and doctor status. It
+// is printed as text or written as JSON or a self-contained HTML page for
+// compliance snapshots and onboarding audits.
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/attach"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/cost"
+	"github.com/lamchakchan/claude-workspace/internal/doctor"
+	"github.com/lamchakchan/claude-workspace/internal/fleet"
+	"github.com/lamchakchan/claude-workspace/internal/mcp"
+	"github.com/lamchakchan/claude-workspace/internal/memory"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// costDays is how far back the report's spend total goes.
+const costDays = 30
+
+// costTimeout bounds the ccusage run, which may download ccusage first.
+const costTimeout = 2 * time.Minute
+
+// codeDirs are the directories under $HOME searched for attached projects
+// when --scan is not given.
+var codeDirs = []string{"code", "src", "projects", "Projects", "git", "repos", "workspace", "dev", "Developer", "go/src"}
+
+// Report is the machine inventory written by "report".
+type Report struct {
+	GeneratedAt time.Time   `json:"generatedAt"`
+	Host        string      `json:"host"`
+	OS          string      `json:"os"`
+	Arch        string      `json:"arch"`
+	Version     string      `json:"version"`
+	ScannedDirs []string    `json:"scannedDirs"`
+	Projects    []Project   `json:"projects"`
+	MCPServers  []MCPServer `json:"mcpServers"`
+	Memory      Memory      `json:"memory"`
+	Cost        Cost        `json:"cost"`
+	Doctor      Doctor      `json:"doctor"`
+}
+
+// Project is a repository with a .claude directory. The attach fields come
+// from its lock file and are empty when it has none.
+type Project struct {
+	Path       string `json:"path"`
+	Version    string `json:"version,omitempty"`
+	Profile    string `json:"profile,omitempty"`
+	Template   string `json:"template,omitempty"`
+	AttachedAt string `json:"attachedAt,omitempty"`
+}
+
+// MCPServer is a configured MCP server and the scope it is configured in.
+type MCPServer struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+}
+
+// Memory describes the memory MCP provider.
+type Memory struct {
+	Provider string `json:"provider"`
+	Path     string `json:"path,omitempty"`
+}
+
+// Cost is the total spend reported by ccusage since Since.
+type Cost struct {
+	Since  string  `json:"since"`
+	Days   int     `json:"days"`
+	Total  float64 `json:"totalUSD"`
+	Active int     `json:"activeDays"` // days with any spend
+	Error  string  `json:"error,omitempty"`
+}
+
+// Doctor summarizes the doctor checks. Problems lists the checks that did not
+// pass.
+type Doctor struct {
+	Healthy  bool            `json:"healthy"`
+	Issues   int             `json:"issues"`
+	Warnings int             `json:"warnings"`
+	Problems []doctor.Result `json:"problems,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Run implements "report [--format text|json|html] [--output path] [--scan dir]...".
+func Run(version string, args []string) error {
+	opts, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(opts.scanDirs) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("getting home directory: %w", err)
+		}
+		opts.scanDirs = defaultScanDirs(home)
+	}
+
+	if opts.format == "text" && opts.output == "" {
+		platform.PrintInfo(os.Stderr, "Collecting inventory (running doctor and ccusage)...")
+	}
+	r := Collect(version, opts.scanDirs)
+
+	w := io.Writer(platform.Stdout())
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", opts.output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch opts.format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(r)
+	case "html":
+		err = WriteHTML(w, r)
+	default:
+		writeText(w, r)
+	}
+	if err != nil {
+		return err
+	}
+	if opts.output != "" {
+		platform.PrintOK(os.Stderr, fmt.Sprintf("Wrote report to %s", opts.output))
+	}
+	return nil
+}
+
+// options holds the parsed report flags.
+type options struct {
+	format   string
+	output   string
+	scanDirs []string
+}
+
+// parseArgs parses the report arguments. Without --format, the format is taken
+// from the --output extension, defaulting to text.
+func parseArgs(args []string) (options, error) {
+	var opts options
+	f := cli.New("report", "report [--format text|json|html] [--output <path>] [--scan <dir>]...")
+	f.Func("--json", "", "Same as --format json", func(string) error {
+		opts.format = "json"
+		return nil
+	})
+	f.StringVar(&opts.format, "--format", "text|json|html", "Output format")
+	f.StringVar(&opts.output, "--output", "path", "Write the report to a file")
+	f.StringsVar(&opts.scanDirs, "--scan", "dir", "Directory to scan for projects")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	if opts.format == "" {
+		switch strings.ToLower(filepath.Ext(opts.output)) {
+		case ".json":
+			opts.format = "json"
+		case ".html", ".htm":
+			opts.format = "html"
+		default:
+			opts.format = "text"
+		}
+	}
+	if opts.format != "text" && opts.format != "json" && opts.format != "html" {
+		return opts, fmt.Errorf("--format must be text, json, or html")
+	}
+	return opts, nil
+}
+
+// defaultScanDirs returns the common code directories under home that exist.
+func defaultScanDirs(home string) []string {
+	dirs := []string{}
+	for _, d := range codeDirs {
+		path := filepath.Join(home, filepath.FromSlash(d))
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs = append(dirs, path)
+		}
+	}
+	return dirs
+}
+
+// Collect gathers the report. Sections that cannot be collected record the
+// error instead of failing the whole report.
+func Collect(version string, scanDirs []string) *Report {
+	host, _ := os.Hostname()
+	r := &Report{
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Host:        host,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Version:     version,
+		ScannedDirs: scanDirs,
+		Projects:    findProjects(scanDirs),
+		MCPServers:  []MCPServer{},
+		Memory:      Memory{Provider: "none"},
+	}
+
+	if servers, err := mcp.DiscoverServers(); err == nil {
+		for _, s := range servers {
+			r.MCPServers = append(r.MCPServers, MCPServer{Name: s.Name, Scope: s.Scope})
+		}
+	}
+	if layers, err := memory.DiscoverLayers(); err == nil {
+		for _, l := range layers {
+			if l.Name == memory.LayerMemoryMCP && l.Provider != "" {
+				r.Memory = Memory{Provider: l.Provider, Path: l.Path}
+			}
+		}
+	}
+	r.Cost = collectCost(time.Now())
+	r.Doctor = collectDoctor()
+	return r
+}
+
+// findProjects returns the git repositories under dirs that have a .claude
+// directory, sorted by path.
+func findProjects(dirs []string) []Project {
+	projects := []Project{}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		repos, err := fleet.Scan(dir)
+		if err != nil {
+			continue
+		}
+		for _, repo := range repos {
+			if seen[repo] || !platform.FileExists(filepath.Join(repo, ".claude")) {
+				continue
+			}
+			seen[repo] = true
+			p := Project{Path: repo}
+			if lock, err := attach.ReadLock(repo); err == nil && lock != nil {
+				p.Version, p.Profile, p.Template, p.AttachedAt = lock.Version, lock.Profile, lock.Template, lock.AttachedAt
+			}
+			projects = append(projects, p)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Path < projects[j].Path })
+	return projects
+}
+
+// collectCost totals the ccusage daily spend for the costDays days up to now.
+func collectCost(now time.Time) Cost {
+	since := now.AddDate(0, 0, -(costDays - 1))
+	c := Cost{Since: since.Format("2006-01-02"), Days: costDays}
+
+	ctx, cancel := context.WithTimeout(context.Background(), costTimeout)
+	defer cancel()
+	out, err := cost.RunCaptureContext(ctx, []string{"daily", "--json", "--since", since.Format("20060102")})
+	if err != nil {
+		c.Error = err.Error()
+		return c
+	}
+	if err := sumCost(&c, out); err != nil {
+		c.Error = err.Error()
+	}
+	return c
+}
+
+// sumCost adds up ccusage "daily --json" output into c.
+func sumCost(c *Cost, data string) error {
+	days, err := cost.ParseCostJSON("daily", data)
+	if err != nil {
+		return err
+	}
+	for _, d := range days {
+		c.Total += d.Value
+		if d.Value > 0 {
+			c.Active++
+		}
+	}
+	return nil
+}
+
+func collectDoctor() Doctor {
+	report, err := doctor.Check()
+	if err != nil {
+		return Doctor{Error: err.Error()}
+	}
+	d := Doctor{Healthy: report.Healthy, Issues: report.Issues, Warnings: report.Warnings}
+	for _, res := range report.Results {
+		if res.Status == doctor.StatusFail || res.Status == doctor.StatusWarn {
+			d.Problems = append(d.Problems, res)
+		}
+	}
+	return d
+}
+
+// writeText prints r for a terminal.
+func writeText(w io.Writer, r *Report) {
+	platform.PrintBanner(w, "Workspace Report")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Host:      %s (%s/%s)\n", r.Host, r.OS, r.Arch)
+	fmt.Fprintf(w, "  Version:   claude-workspace %s\n", r.Version)
+	fmt.Fprintf(w, "  Generated: %s\n", r.GeneratedAt.Format(time.RFC3339))
+
+	platform.PrintSection(w, fmt.Sprintf("Attached Projects (%d)", len(r.Projects)))
+	if len(r.ScannedDirs) == 0 {
+		platform.PrintWarn(w, "No code directories found; use --scan <dir>")
+	}
+	for _, p := range r.Projects {
+		detail := "no lock file"
+		if p.Version != "" {
+			detail = fmt.Sprintf("%s, attached %s", p.Version, p.AttachedAt)
+			if p.Profile != "" {
+				detail += ", profile " + p.Profile
+			}
+		}
+		fmt.Fprintf(w, "  %s  (%s)\n", p.Path, detail)
+	}
+
+	platform.PrintSection(w, fmt.Sprintf("MCP Servers (%d)", len(r.MCPServers)))
+	for _, s := range r.MCPServers {
+		fmt.Fprintf(w, "  %-30s %s\n", s.Name, s.Scope)
+	}
+
+	platform.PrintSection(w, "Memory")
+	fmt.Fprintf(w, "  Provider: %s\n", r.Memory.Provider)
+
+	platform.PrintSection(w, fmt.Sprintf("Cost (last %d days)", r.Cost.Days))
+	if r.Cost.Error != "" {
+		platform.PrintWarn(w, "Unavailable: "+r.Cost.Error)
+	} else {
+		fmt.Fprintf(w, "  $%.2f since %s (%d active day(s))\n", r.Cost.Total, r.Cost.Since, r.Cost.Active)
+	}
+
+	platform.PrintSection(w, "Doctor")
+	switch {
+	case r.Doctor.Error != "":
+		platform.PrintFail(w, r.Doctor.Error)
+	case r.Doctor.Healthy:
+		platform.PrintOK(w, fmt.Sprintf("Healthy (%d warning(s))", r.Doctor.Warnings))
+	default:
+		platform.PrintFail(w, fmt.Sprintf("%d issue(s), %d warning(s)", r.Doctor.Issues, r.Doctor.Warnings))
+	}
+	for _, p := range r.Doctor.Problems {
+		if p.Status == doctor.StatusFail {
+			platform.PrintFail(w, fmt.Sprintf("%s: %s", p.Section, p.Message))
+		} else {
+			platform.PrintWarn(w, fmt.Sprintf("%s: %s", p.Section, p.Message))
+		}
+	}
+	fmt.Fprintln(w)
+}
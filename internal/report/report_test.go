@@ -0,0 +1,123 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/doctor"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		format  string
+		wantErr bool
+	}{
+		{nil, "text", false},
+		{[]string{"--output", "report.html"}, "html", false},
+		{[]string{"--output", "report.JSON"}, "json", false},
+		{[]string{"--json"}, "json", false},
+		{[]string{"--output", "report.txt", "--format", "html"}, "html", false},
+		{[]string{"--format", "pdf"}, "", true},
+		{[]string{"--scan"}, "", true},
+		{[]string{"extra"}, "", true},
+	}
+	for _, tt := range tests {
+		opts, err := parseArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && opts.format != tt.format {
+			t.Errorf("parseArgs(%q) format = %q, want %q", tt.args, opts.format, tt.format)
+		}
+	}
+
+	opts, _ := parseArgs([]string{"--scan", "a", "--scan", "b"})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(opts.scanDirs, want) {
+		t.Errorf("scanDirs = %q, want %q", opts.scanDirs, want)
+	}
+}
+
+func TestDefaultScanDirs(t *testing.T) {
+	home := t.TempDir()
+	for _, d := range []string{"code", "go/src", "Music"} {
+		if err := os.MkdirAll(filepath.Join(home, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{filepath.Join(home, "code"), filepath.Join(home, "go", "src")}
+	if got := defaultScanDirs(home); !reflect.DeepEqual(got, want) {
+		t.Errorf("defaultScanDirs() = %q, want %q", got, want)
+	}
+}
+
+func TestFindProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"api/.git", "api/.claude", "web/.git", "web/.claude", "plain/.git"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock := `{"version":"v1.4.0","profile":"backend","attachedAt":"2026-03-01T10:00:00Z","files":{}}`
+	if err := os.WriteFile(filepath.Join(root, "api", ".claude", ".claude-workspace-lock.json"), []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listing the same directory twice must not duplicate projects.
+	got := findProjects([]string{root, root})
+	want := []Project{
+		{Path: filepath.Join(root, "api"), Version: "v1.4.0", Profile: "backend", AttachedAt: "2026-03-01T10:00:00Z"},
+		{Path: filepath.Join(root, "web")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findProjects() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSumCost(t *testing.T) {
+	var c Cost
+	data := `{"daily":[{"date":"2026-03-01","totalCost":1.25},{"date":"2026-03-02","totalCost":0},{"date":"2026-03-03","totalCost":2.5}]}`
+	if err := sumCost(&c, data); err != nil {
+		t.Fatal(err)
+	}
+	if c.Total != 3.75 || c.Active != 2 {
+		t.Errorf("sumCost() = %+v, want total 3.75 over 2 active days", c)
+	}
+	if err := sumCost(&c, "not json"); err == nil {
+		t.Error("sumCost() with invalid JSON should fail")
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	r := &Report{
+		GeneratedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Host:        "dev-01",
+		Version:     "v1.4.0",
+		Projects:    []Project{{Path: "/home/dana/code/api", Version: "v1.4.0"}},
+		MCPServers:  []MCPServer{{Name: "<script>", Scope: "user"}},
+		Memory:      Memory{Provider: "engram"},
+		Cost:        Cost{Days: 30, Since: "2026-01-31", Total: 12.5, Active: 4},
+		Doctor: Doctor{Issues: 1, Problems: []doctor.Result{
+			{Section: "Git", Status: doctor.StatusFail, Message: "git not found", Remediation: "Install git"},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{"<title>Workspace report: dev-01</title>", "/home/dana/code/api", "&lt;script&gt;", "$12.50", "1 issue(s)", "Install git"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(got, "<script>") {
+		t.Error("HTML should escape server names")
+	}
+}
@@ -0,0 +1,293 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// defaultJobs is the number of sandboxes a batch sets up at once.
+const defaultJobs = 4
+
+// Task is one sandbox to create in a batch, with an optional prompt to start
+// Claude Code with.
+type Task struct {
+	Branch string
+	Prompt string
+}
+
+// ParseTasks parses a batch task file. Tasks are listed under a top-level
+// "tasks:" key, either as plain branch names or as mappings with "branch" and
+// an optional "prompt":
+//
+//	tasks:
+//	  - feature-auth
+//	  - branch: feature-api
+//	    prompt: Add pagination to the /users endpoint
+//
+// A "#" after a space starts a comment, so quote prompts that contain one.
+func ParseTasks(data []byte) ([]Task, error) {
+	entries, err := platform.ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	for _, e := range entries {
+		if e.Key != "tasks" {
+			return nil, fmt.Errorf("line %d: expected \"tasks:\"", e.Line)
+		}
+		if e.List == nil {
+			return nil, fmt.Errorf("line %d: tasks must be a list", e.Line)
+		}
+		for _, item := range e.List {
+			t := Task{Branch: item.Value}
+			for _, f := range item.Fields {
+				switch f.Key {
+				case "branch":
+					t.Branch = f.Value
+				case "prompt":
+					t.Prompt = f.Value
+				default:
+					return nil, fmt.Errorf("line %d: unknown task field %q (valid: branch, prompt)", f.Line, f.Key)
+				}
+			}
+			tasks = append(tasks, t)
+		}
+	}
+
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks found")
+	}
+	seen := make(map[string]bool, len(tasks))
+	for i, t := range tasks {
+		if err := validateBranch(t.Branch); err != nil {
+			return nil, fmt.Errorf("task %d: %w", i+1, err)
+		}
+		if seen[t.Branch] {
+			return nil, fmt.Errorf("task %d: branch %q is listed more than once", i+1, t.Branch)
+		}
+		seen[t.Branch] = true
+	}
+	return tasks, nil
+}
+
+// validateBranch rejects names that cannot be used as both a branch and a
+// directory under <project>-worktrees.
+func validateBranch(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("branch name is empty")
+	case strings.HasPrefix(name, "-"), strings.Contains(name, ".."), strings.ContainsAny(name, " \t~^:?*[\\"):
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// batchResult is the outcome of setting up one task.
+type batchResult struct {
+	Task    Task
+	Dir     string
+	Status  string // created, exists, or failed
+	Err     error
+	Elapsed time.Duration
+	Log     bytes.Buffer
+}
+
+// batchUsage is the sandbox batch usage line.
+const batchUsage = "sandbox batch <project-path> --tasks <tasks.yaml> [--jobs N]"
+
+// Batch implements "sandbox batch <project-path> --tasks <file> [--jobs N]".
+// It creates a sandbox for every task concurrently and prints a summary.
+func Batch(args []string) error {
+	projectPath, tasksFile, jobs, err := parseBatchArgs(args)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(tasksFile)
+	if err != nil {
+		return fmt.Errorf("reading tasks file: %w", err)
+	}
+	tasks, err := ParseTasks(data)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", tasksFile, err)
+	}
+
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(worktreeBase, 0755); err != nil {
+		return fmt.Errorf("creating worktrees directory: %w", err)
+	}
+
+	out := platform.Stdout()
+	platform.PrintBanner(out, fmt.Sprintf("Creating %d Sandboxes: %s", len(tasks), filepath.Base(projectDir)))
+	fmt.Fprintf(out, "\n  Running up to %d at a time...\n", jobs)
+
+	results := runBatch(projectDir, worktreeBase, tasks, jobs)
+	failed := printBatchSummary(results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sandbox(es) failed", failed, len(results))
+	}
+	return nil
+}
+
+func parseBatchArgs(args []string) (projectPath, tasksFile string, jobs int, err error) {
+	jobs = defaultJobs
+	f := cli.New("sandbox batch", batchUsage)
+	f.StringVar(&tasksFile, "--tasks", "file", "Task file listing the sandboxes to create")
+	f.Func("--jobs", "N", "Number of sandboxes to set up at once (default: 4)", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--jobs must be a positive number")
+		}
+		jobs = n
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return "", "", 0, err
+	}
+	if tasksFile == "" {
+		return "", "", 0, fmt.Errorf("--tasks is required (usage: claude-workspace %s)", batchUsage)
+	}
+	projectPath = positional[0]
+	return projectPath, tasksFile, jobs, nil
+}
+
+// runBatch sets up every task with a pool of jobs workers. Results are
+// returned in task order. Each worker writes to its own log so output does not
+// interleave.
+func runBatch(projectDir, worktreeBase string, tasks []Task, jobs int) []*batchResult {
+	results := make([]*batchResult, len(tasks))
+	queue := make(chan int)
+	// git takes locks on shared repository state (refs, worktree metadata)
+	// while adding a worktree, so additions are serialized. Copying config and
+	// installing dependencies, the slow part, runs in parallel.
+	var gitMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < jobs && w < len(tasks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = setupTask(projectDir, worktreeBase, tasks[i], &gitMu)
+			}
+		}()
+	}
+	for i := range tasks {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+func setupTask(projectDir, worktreeBase string, task Task, gitMu *sync.Mutex) *batchResult {
+	start := time.Now()
+	r := &batchResult{Task: task, Dir: filepath.Join(worktreeBase, task.Branch)}
+	defer func() { r.Elapsed = time.Since(start) }()
+
+	if platform.FileExists(r.Dir) {
+		r.Status = "exists"
+		return r
+	}
+
+	gitMu.Lock()
+	err := addWorktreeQuiet(projectDir, r.Dir, task.Branch)
+	gitMu.Unlock()
+	if err != nil {
+		r.Status = "failed"
+		r.Err = err
+		return r
+	}
+
+	copyClaudeConfig(&r.Log, projectDir, r.Dir)
+	copyMCPConfig(&r.Log, projectDir, r.Dir)
+	installWorktreeDeps(&r.Log, r.Dir)
+	r.Status = "created"
+	return r
+}
+
+// addWorktreeQuiet runs "git worktree add" with its output captured.
+func addWorktreeQuiet(projectDir, worktreeDir, branchName string) error {
+	args := worktreeAddArgs(projectDir, worktreeDir, branchName)
+	_, stderr, err := platform.RunDirWithStdinCapture(context.Background(), projectDir, "", nil, "git", args...)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return fmt.Errorf("creating worktree: %s", msg)
+		}
+		return fmt.Errorf("creating worktree: %w", err)
+	}
+	return nil
+}
+
+// printBatchSummary prints a table of results and the commands to start each
+// sandbox, and returns the number of failures.
+func printBatchSummary(results []*batchResult) int {
+	maxBranch := len("BRANCH")
+	for _, r := range results {
+		if len(r.Task.Branch) > maxBranch {
+			maxBranch = len(r.Task.Branch)
+		}
+	}
+
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Batch Summary")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  %-*s  %-8s  %6s  %s\n", maxBranch, "BRANCH", "STATUS", "TIME", "DIRECTORY")
+	failed := 0
+	for _, r := range results {
+		status := r.Status
+		switch r.Status {
+		case "created":
+			status = platform.Green(fmt.Sprintf("%-8s", status))
+		case "failed":
+			status = platform.Red(fmt.Sprintf("%-8s", status))
+			failed++
+		default:
+			status = platform.Yellow(fmt.Sprintf("%-8s", status))
+		}
+		fmt.Fprintf(out, "  %-*s  %s  %5.1fs  %s\n", maxBranch, r.Task.Branch, status, r.Elapsed.Seconds(), r.Dir)
+	}
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintln(out)
+			platform.PrintErrorLine(out, fmt.Sprintf("%s: %v", r.Task.Branch, r.Err))
+		}
+		if strings.Contains(r.Log.String(), "Could not") {
+			fmt.Fprintln(out)
+			platform.PrintWarningLine(out, r.Task.Branch+":")
+			fmt.Fprint(out, r.Log.String())
+		}
+	}
+
+	platform.PrintSection(out, "Start Claude Code")
+	for _, r := range results {
+		if r.Status == "failed" {
+			continue
+		}
+		cmd := fmt.Sprintf("cd %s && claude", r.Dir)
+		if r.Task.Prompt != "" {
+			cmd += " " + shellQuote(r.Task.Prompt)
+		}
+		platform.PrintCommand(out, cmd)
+	}
+	fmt.Fprintln(out)
+	return failed
+}
@@ -0,0 +1,126 @@
+package sandbox
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseTasks(t *testing.T) {
+	data := []byte(`# sandboxes for the sprint
+tasks:
+  - feature-auth
+  - branch: feature-api
+    prompt: "Add pagination to the #users endpoint" # quoted hash
+  - branch: 'bugfix-login'
+- fix/typo
+`)
+	got, err := ParseTasks(data)
+	if err != nil {
+		t.Fatalf("ParseTasks() error = %v", err)
+	}
+	want := []Task{
+		{Branch: "feature-auth"},
+		{Branch: "feature-api", Prompt: "Add pagination to the #users endpoint"},
+		{Branch: "bugfix-login"},
+		{Branch: "fix/typo"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTasks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTasks_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":           "tasks:\n",
+		"missing key":     "  - feature\n",
+		"other top key":   "branches:\n  - a\n",
+		"unknown field":   "tasks:\n  - branch: a\n    model: opus\n",
+		"duplicate":       "tasks:\n  - a\n  - branch: a\n",
+		"invalid branch":  "tasks:\n  - bad..name\n",
+		"prompt only":     "tasks:\n  - prompt: do things\n",
+		"orphan field":    "tasks:\n  prompt: x\n",
+		"leading dash":    "tasks:\n  - -rf\n",
+		"space in branch": "tasks:\n  - my branch\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseTasks([]byte(data)); err == nil {
+				t.Errorf("ParseTasks(%q) expected error", data)
+			}
+		})
+	}
+}
+
+func TestParseBatchArgs(t *testing.T) {
+	path, file, jobs, err := parseBatchArgs([]string{"./app", "--tasks", "t.yaml", "--jobs", "8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "./app" || file != "t.yaml" || jobs != 8 {
+		t.Errorf("got (%q, %q, %d)", path, file, jobs)
+	}
+
+	if _, _, jobs, _ := parseBatchArgs([]string{"./app", "--tasks", "t.yaml"}); jobs != defaultJobs {
+		t.Errorf("default jobs = %d, want %d", jobs, defaultJobs)
+	}
+
+	for _, args := range [][]string{
+		{"./app"},
+		{"--tasks", "t.yaml"},
+		{"./app", "--tasks"},
+		{"./app", "--tasks", "t.yaml", "--jobs", "0"},
+		{"./app", "./other", "--tasks", "t.yaml"},
+		{"./app", "--tasks", "t.yaml", "--bogus"},
+	} {
+		if _, _, _, err := parseBatchArgs(args); err == nil {
+			t.Errorf("parseBatchArgs(%v) expected error", args)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	parent, projectDir := newProject(t)
+	worktreeBase := filepath.Join(parent, "myproject-worktrees")
+	tasks := []Task{{Branch: "a"}, {Branch: "b"}, {Branch: "c", Prompt: "go"}}
+	t.Cleanup(func() {
+		for _, task := range tasks {
+			_ = exec.Command("git", "-C", projectDir, "worktree", "remove", "--force", filepath.Join(worktreeBase, task.Branch)).Run()
+		}
+	})
+
+	if err := Create(projectDir, "b"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	results := runBatch(projectDir, worktreeBase, tasks, 2)
+	want := []string{"created", "exists", "created"}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("%s: status = %q (err %v), want %q", r.Task.Branch, r.Status, r.Err, want[i])
+		}
+	}
+	for _, b := range []string{"a", "c"} {
+		if !branchExists(t, projectDir, b) {
+			t.Errorf("branch %s was not created", b)
+		}
+	}
+}
+
+func TestSetupTask_Failure(t *testing.T) {
+	parent, projectDir := newProject(t)
+	// A branch checked out in the main worktree cannot be added again.
+	out, err := exec.Command("git", "-C", projectDir, "branch", "--show-current").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := string(out[:len(out)-1])
+
+	var mu sync.Mutex
+	r := setupTask(projectDir, filepath.Join(parent, "myproject-worktrees"), Task{Branch: current}, &mu)
+	if r.Status != "failed" || r.Err == nil {
+		t.Errorf("status = %q, err = %v, want failure", r.Status, r.Err)
+	}
+}
@@ -0,0 +1,173 @@
+package sandbox
+
+import (
+	"fmt"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+)
+
+// Run executes the sandbox command. Without a known subcommand, args are
+// those of create, as in "sandbox <path> <branch>".
+func Run(args []string) error {
+	subcmd := ""
+	if len(args) > 0 {
+		subcmd = args[0]
+	}
+	switch subcmd {
+	case "create":
+		return runCreate(args[1:])
+	case "remove":
+		return runRemove(args[1:])
+	case "list":
+		return runList(args[1:])
+	case "status":
+		return runStatus(args[1:])
+	case "batch":
+		return Batch(args[1:])
+	case "finish":
+		return Finish(args[1:])
+	case "prune":
+		return runPrune(args[1:])
+	default:
+		return runCreate(args)
+	}
+}
+
+// createOptions holds the parsed sandbox create flags.
+type createOptions struct {
+	launch    bool
+	container *ContainerOptions // nil without --container
+}
+
+// parseCreateArgs parses "<path> <branch> [--launch] [--container ...]".
+func parseCreateArgs(args []string) (projectPath, branchName string, opts createOptions, err error) {
+	f := cli.New("sandbox create", "sandbox create <project-path> <branch-name> [--launch] [--container [--engine docker|podman] [--allow-host <host>] [--env <NAME>]]")
+	f.BoolVar(&opts.launch, "--launch", "Open it in tmux (or a subshell) with claude running")
+	container := containerFlags(f)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", "", opts, err
+	}
+	if err := f.CheckArgs(positional, 2, 2); err != nil {
+		return "", "", opts, err
+	}
+	if opts.container, err = container(); err != nil {
+		return "", "", opts, err
+	}
+	return positional[0], positional[1], opts, nil
+}
+
+// runCreate implements sandbox create.
+func runCreate(args []string) error {
+	projectPath, branchName, opts, err := parseCreateArgs(args)
+	if err != nil {
+		return err
+	}
+	if err := Create(projectPath, branchName); err != nil {
+		return err
+	}
+	if opts.container != nil {
+		if err := StartContainer(projectPath, branchName, *opts.container); err != nil {
+			return err
+		}
+		if opts.launch {
+			return EnterContainer(projectPath, branchName, *opts.container)
+		}
+		return nil
+	}
+	if opts.launch {
+		return Launch(projectPath, branchName)
+	}
+	return nil
+}
+
+// runRemove implements sandbox remove.
+func runRemove(args []string) error {
+	var opts RemoveOptions
+	f := cli.New("sandbox remove", "sandbox remove <project-path> <branch-name> [--delete-branch] [--force]")
+	f.BoolVar(&opts.DeleteBranch, "--delete-branch", "Also delete the sandbox's branch")
+	f.BoolVar(&opts.Force, "--force", "Discard uncommitted changes / unmerged branch")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 2, 2); err != nil {
+		return err
+	}
+	return Remove(positional[0], positional[1], opts)
+}
+
+// runList implements sandbox list.
+func runList(args []string) error {
+	positional, err := cli.Operands("sandbox list", "sandbox list <project-path>", args, 1, 1)
+	if err != nil {
+		return err
+	}
+	return List(positional[0])
+}
+
+// runStatus implements sandbox status.
+func runStatus(args []string) error {
+	positional, err := cli.Operands("sandbox status", "sandbox status <project-path> <branch-name>", args, 2, 2)
+	if err != nil {
+		return err
+	}
+	return Status(positional[0], positional[1])
+}
+
+// runPrune implements sandbox prune.
+func runPrune(args []string) error {
+	var dryRun bool
+	f := cli.New("sandbox prune", "sandbox prune <project-path> [--dry-run]")
+	f.BoolVar(&dryRun, "--dry-run", "Show what would be removed")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
+	}
+	return Prune(positional[0], dryRun)
+}
+
+// containerFlags declares --container and its options (--engine, and
+// --allow-host and --env, which may repeat or take a comma-separated list)
+// on f. After f.Parse, the returned function gives the options, or nil when
+// --container was not given.
+func containerFlags(f *cli.FlagSet) func() (*ContainerOptions, error) {
+	var o ContainerOptions
+	var container bool
+	withOptions := ""
+	f.BoolVar(&container, "--container", "Also run it in a docker/podman container with egress limited")
+	f.Func("--engine", "docker|podman", "Container engine (default: docker if installed)", func(v string) error {
+		if v != EngineDocker && v != EnginePodman {
+			return fmt.Errorf("unknown engine %q (valid: %s, %s)", v, EngineDocker, EnginePodman)
+		}
+		o.Engine, withOptions = v, "--engine"
+		return nil
+	})
+	f.Func("--allow-host", "host", "Extra host the container may reach (repeatable)", func(v string) error {
+		o.AllowHosts = append(o.AllowHosts, splitList(v)...)
+		withOptions = "--allow-host"
+		return nil
+	})
+	f.Func("--env", "NAME", "Host variable to pass into the container (repeatable)", func(v string) error {
+		for _, name := range splitList(v) {
+			if err := validateEnvName(name); err != nil {
+				return err
+			}
+			o.Env = append(o.Env, name)
+		}
+		withOptions = "--env"
+		return nil
+	})
+	return func() (*ContainerOptions, error) {
+		if !container {
+			if withOptions != "" {
+				return nil, fmt.Errorf("%s requires --container", withOptions)
+			}
+			return nil, nil
+		}
+		return &o, nil
+	}
+}
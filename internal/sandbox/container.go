@@ -0,0 +1,331 @@
+package sandbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Container engines "sandbox create --container" can use.
+const (
+	EngineDocker = "docker"
+	EnginePodman = "podman"
+)
+
+// containerWorkdir is where the worktree is mounted in the container.
+const containerWorkdir = "/workspace"
+
+// containerUser is the unprivileged user Claude Code runs as in the
+// container. The firewall is set up as root before it starts.
+const containerUser = "node"
+
+// defaultAllowedHosts are the hosts a sandbox container can always reach:
+// the Anthropic API and Claude Code's telemetry and error reporting, and the
+// npm registry, which MCP servers launched with npx install from.
+var defaultAllowedHosts = []string{
+	"api.anthropic.com",
+	"statsig.anthropic.com",
+	"sentry.io",
+	"registry.npmjs.org",
+}
+
+// defaultContainerEnv are the host variables always passed into the
+// container, when set.
+var defaultContainerEnv = []string{"ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL"}
+
+const containerfile = `FROM node:20
+RUN apt-get update \
+ && apt-get install -y --no-install-recommends iptables \
+ && rm -rf /var/lib/apt/lists/*
+RUN npm install -g @anthropic-ai/claude-code
+COPY sandbox-firewall.sh /usr/local/bin/sandbox-firewall.sh
+RUN chmod 0755 /usr/local/bin/sandbox-firewall.sh
+WORKDIR /workspace
+ENTRYPOINT ["/usr/local/bin/sandbox-firewall.sh"]
+CMD ["sleep", "infinity"]
+`
+
+// firewallScript drops all outbound traffic except DNS to the container's
+// resolvers and connections to the addresses SANDBOX_ALLOWED_HOSTS resolve to
+// when the container starts, then runs the container command.
+const firewallScript = `#!/bin/sh
+# Restrict outbound traffic to SANDBOX_ALLOWED_HOSTS. Installed by
+# claude-workspace sandbox create --container.
+set -eu
+
+iptables -F OUTPUT
+iptables -A OUTPUT -o lo -j ACCEPT
+iptables -A OUTPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT
+for ns in $(awk '/^nameserver/ { print $2 }' /etc/resolv.conf); do
+    case "$ns" in *:*) continue ;; esac
+    iptables -A OUTPUT -p udp -d "$ns" --dport 53 -j ACCEPT
+    iptables -A OUTPUT -p tcp -d "$ns" --dport 53 -j ACCEPT
+done
+for host in $SANDBOX_ALLOWED_HOSTS; do
+    ips=$(getent ahostsv4 "$host" | awk '{ print $1 }' | sort -u)
+    if [ -z "$ips" ]; then
+        echo "sandbox-firewall: cannot resolve $host; it will be unreachable" >&2
+        continue
+    fi
+    for ip in $ips; do
+        iptables -A OUTPUT -d "$ip" -j ACCEPT
+    done
+done
+iptables -P OUTPUT DROP
+if command -v ip6tables >/dev/null 2>&1; then
+    ip6tables -A OUTPUT -o lo -j ACCEPT 2>/dev/null || true
+    ip6tables -P OUTPUT DROP 2>/dev/null || true
+fi
+echo "sandbox-firewall: outbound traffic limited to: $SANDBOX_ALLOWED_HOSTS" >&2
+
+exec "$@"
+`
+
+// ContainerOptions configures "sandbox create --container".
+type ContainerOptions struct {
+	// Engine is docker or podman; "" uses whichever is installed, preferring
+	// docker.
+	Engine string
+	// AllowHosts are hosts the container may reach in addition to
+	// defaultAllowedHosts.
+	AllowHosts []string
+	// Env names host environment variables to pass into the container in
+	// addition to defaultContainerEnv.
+	Env []string
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func validateEnvName(name string) error {
+	if name == "" {
+		return fmt.Errorf("environment variable name is empty")
+	}
+	if !platform.IsEnvVarName(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	return nil
+}
+
+// containerName returns the name of the sandbox container for branchName.
+func containerName(projectDir, branchName string) string {
+	return "claude-sandbox-" + strings.ToLower(sessionName(filepath.Base(projectDir), branchName))
+}
+
+// StartContainer builds the sandbox image if needed and starts a container
+// for the sandbox worktree of branchName, with the worktree mounted at
+// /workspace, outbound traffic limited to the allowed hosts, and the API key
+// and opts.Env passed from the host environment. It prints the command that
+// opens Claude Code in it.
+func StartContainer(projectPath, branchName string, opts ContainerOptions) error {
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+	worktreeDir := filepath.Join(worktreeBase, branchName)
+	if !platform.FileExists(worktreeDir) {
+		return fmt.Errorf("sandbox not found: %s", worktreeDir)
+	}
+	engine, err := containerEngine(opts.Engine)
+	if err != nil {
+		return err
+	}
+	// The worktree's .git file points at the main repository's git directory
+	// by absolute path, so that directory is mounted at the same path.
+	gitDir, err := platform.OutputDir(projectDir, "git", "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return fmt.Errorf("locating git directory: %w", err)
+	}
+
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Starting Sandbox Container")
+	fmt.Fprintln(out)
+
+	platform.PrintStep(out, 1, 2, "Building sandbox image...")
+	image, err := buildSandboxImage(engine)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "  Image: %s\n", image)
+
+	platform.PrintStep(out, 2, 2, "Starting container...")
+	name := containerName(projectDir, branchName)
+	if platform.RunQuiet(engine, "container", "inspect", name) == nil {
+		fmt.Fprintf(out, "  Container %s already exists.\n", name)
+		if err := platform.RunQuiet(engine, "start", name); err != nil {
+			return fmt.Errorf("starting container %s: %w", name, err)
+		}
+	} else {
+		hosts := allowedHosts(opts.AllowHosts, os.Getenv("ANTHROPIC_BASE_URL"))
+		env := containerEnv(opts.Env, os.LookupEnv)
+		args := runArgs(engine, name, image, worktreeDir, gitDir, hosts, env)
+		if err := platform.Run(engine, args...); err != nil {
+			return fmt.Errorf("starting container: %w", err)
+		}
+		platform.PrintSuccess(out, fmt.Sprintf("Started %s", name))
+		fmt.Fprintf(out, "  Outbound traffic allowed to: %s\n", strings.Join(hosts, ", "))
+		if len(env) > 0 {
+			fmt.Fprintf(out, "  Environment passed in: %s\n", strings.Join(env, ", "))
+		}
+	}
+
+	platform.PrintSection(out, "Enter the sandbox")
+	platform.PrintCommand(out, strings.Join(execArgs(engine, name, "claude"), " "))
+	fmt.Fprintln(out, "  The worktree is mounted at /workspace; commits made inside land on the sandbox branch.")
+	fmt.Fprintf(out, "  'claude-workspace sandbox remove %s %s' also removes the container.\n", projectDir, branchName)
+	fmt.Fprintln(out)
+	return nil
+}
+
+// EnterContainer opens Claude Code in the running sandbox container.
+func EnterContainer(projectPath, branchName string, opts ContainerOptions) error {
+	projectDir, _, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+	engine, err := containerEngine(opts.Engine)
+	if err != nil {
+		return err
+	}
+	argv := execArgs(engine, containerName(projectDir, branchName), "claude")
+	if _, err := platform.RunSpawn(argv[0], argv[1:]...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// removeContainer removes the sandbox container for branchName, if there is
+// one, with whichever engine has it. It reports whether one was removed.
+func removeContainer(projectDir, branchName string) bool {
+	name := containerName(projectDir, branchName)
+	for _, engine := range []string{EngineDocker, EnginePodman} {
+		if !platform.Exists(engine) || platform.RunQuiet(engine, "container", "inspect", name) != nil {
+			continue
+		}
+		return platform.RunQuiet(engine, "rm", "-f", name) == nil
+	}
+	return false
+}
+
+// containerEngine returns engine if it is installed, or else the first of
+// docker and podman that is.
+func containerEngine(engine string) (string, error) {
+	if engine != "" {
+		if !platform.Exists(engine) {
+			return "", fmt.Errorf("%s not found in PATH", engine)
+		}
+		return engine, nil
+	}
+	for _, e := range []string{EngineDocker, EnginePodman} {
+		if platform.Exists(e) {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("--container needs docker or podman, and neither was found in PATH")
+}
+
+// buildSandboxImage builds the sandbox image unless it exists. The tag is
+// derived from the image definition, so a claude-workspace upgrade that
+// changes it builds a new image.
+func buildSandboxImage(engine string) (string, error) {
+	sum := sha256.Sum256([]byte(containerfile + firewallScript))
+	image := "claude-workspace-sandbox:" + hex.EncodeToString(sum[:])[:12]
+	if platform.RunQuiet(engine, "image", "inspect", image) == nil {
+		return image, nil
+	}
+
+	dir, err := os.MkdirTemp("", "claude-sandbox-image-")
+	if err != nil {
+		return "", fmt.Errorf("creating build directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "Containerfile"), []byte(containerfile), 0644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sandbox-firewall.sh"), []byte(firewallScript), 0755); err != nil {
+		return "", err
+	}
+	if err := platform.Run(engine, "build", "-t", image, "-f", filepath.Join(dir, "Containerfile"), dir); err != nil {
+		return "", fmt.Errorf("building sandbox image: %w", err)
+	}
+	return image, nil
+}
+
+// allowedHosts returns the hosts the container may reach: the defaults,
+// extra, and the host of baseURL (ANTHROPIC_BASE_URL) when it is set, sorted
+// and without duplicates.
+func allowedHosts(extra []string, baseURL string) []string {
+	set := map[string]bool{}
+	for _, h := range defaultAllowedHosts {
+		set[h] = true
+	}
+	for _, h := range extra {
+		set[strings.ToLower(h)] = true
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		set[u.Hostname()] = true
+	}
+	hosts := make([]string, 0, len(set))
+	for h := range set {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// containerEnv returns the names of the variables to pass into the container:
+// defaultContainerEnv and extra, those that are set on the host.
+func containerEnv(extra []string, lookup func(string) (string, bool)) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range append(append([]string{}, defaultContainerEnv...), extra...) {
+		if _, ok := lookup(name); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// runArgs returns the arguments that start the sandbox container. Variables
+// in env are passed by name so their values never appear on a command line.
+// The firewall needs NET_ADMIN; it runs as root and Claude Code runs as
+// containerUser. Podman maps the calling user to containerUser so files
+// written in the worktree stay owned by them.
+func runArgs(engine, name, image, worktreeDir, gitDir string, hosts, env []string) []string {
+	args := []string{
+		"run", "-d", "--name", name, "--hostname", name,
+		"--label", "claude-workspace.sandbox=" + worktreeDir,
+		"--cap-add", "NET_ADMIN", "--cap-add", "NET_RAW",
+		"-v", worktreeDir + ":" + containerWorkdir,
+		"-v", gitDir + ":" + gitDir,
+		"-w", containerWorkdir,
+		"-e", "SANDBOX_ALLOWED_HOSTS=" + strings.Join(hosts, " "),
+	}
+	if engine == EnginePodman {
+		args = append(args, "--userns=keep-id:uid=1000,gid=1000", "--user", "root")
+	}
+	for _, e := range env {
+		args = append(args, "-e", e)
+	}
+	return append(args, image)
+}
+
+// execArgs returns the command that runs command in the sandbox container.
+func execArgs(engine, name string, command ...string) []string {
+	return append([]string{engine, "exec", "-it", "-u", containerUser, "-w", containerWorkdir, name}, command...)
+}
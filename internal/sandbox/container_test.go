@@ -0,0 +1,101 @@
+package sandbox
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCreateArgs(t *testing.T) {
+	path, branch, opts, err := parseCreateArgs([]string{
+		"./app", "--container", "feat", "--launch",
+		"--engine", "podman", "--allow-host", "github.com, api.github.com", "--allow-host=pypi.org",
+		"--env", "GITHUB_TOKEN", "--env", "NPM_TOKEN,SENTRY_DSN",
+	})
+	if err != nil {
+		t.Fatalf("parseCreateArgs() error: %v", err)
+	}
+	if path != "./app" || branch != "feat" || !opts.launch {
+		t.Errorf("parseCreateArgs() = %q, %q, launch %v", path, branch, opts.launch)
+	}
+	want := &ContainerOptions{
+		Engine:     EnginePodman,
+		AllowHosts: []string{"github.com", "api.github.com", "pypi.org"},
+		Env:        []string{"GITHUB_TOKEN", "NPM_TOKEN", "SENTRY_DSN"},
+	}
+	if !reflect.DeepEqual(opts.container, want) {
+		t.Errorf("container = %+v, want %+v", opts.container, want)
+	}
+
+	_, _, opts, err = parseCreateArgs([]string{"./app", "feat", "--launch"})
+	if err != nil || opts.container != nil || !opts.launch {
+		t.Errorf("without --container = %+v, %v; want nil container options", opts, err)
+	}
+
+	for _, args := range [][]string{
+		{"./app"},
+		{"./app", "feat", "extra"},
+		{"./app", "feat", "--bogus"},
+		{"./app", "feat", "--allow-host", "github.com"},
+		{"./app", "feat", "--container", "--engine", "lxc"},
+		{"./app", "feat", "--container", "--env"},
+		{"./app", "feat", "--container", "--env", "--launch"},
+		{"./app", "feat", "--container", "--env", "GITHUB_TOKEN=secret"},
+	} {
+		if _, _, _, err := parseCreateArgs(args); err == nil {
+			t.Errorf("parseCreateArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestAllowedHosts(t *testing.T) {
+	got := allowedHosts([]string{"GitHub.com", "sentry.io"}, "https://llm-gateway.internal:8443/v1")
+	want := []string{"api.anthropic.com", "github.com", "llm-gateway.internal", "registry.npmjs.org", "sentry.io", "statsig.anthropic.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("allowedHosts() = %q, want %q", got, want)
+	}
+	if got := allowedHosts(nil, ""); len(got) != len(defaultAllowedHosts) {
+		t.Errorf("allowedHosts(nil, \"\") = %q, want the defaults", got)
+	}
+}
+
+func TestContainerEnv(t *testing.T) {
+	set := map[string]string{"ANTHROPIC_API_KEY": "sk-ant", "GITHUB_TOKEN": "ghp"}
+	lookup := func(name string) (string, bool) {
+		v, ok := set[name]
+		return v, ok
+	}
+	got := containerEnv([]string{"GITHUB_TOKEN", "MISSING", "ANTHROPIC_API_KEY"}, lookup)
+	if want := []string{"ANTHROPIC_API_KEY", "GITHUB_TOKEN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("containerEnv() = %q, want %q", got, want)
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	hosts := []string{"api.anthropic.com", "github.com"}
+	env := []string{"ANTHROPIC_API_KEY"}
+	got := runArgs(EngineDocker, "claude-sandbox-app-feat", "img:1", "/w/feat", "/src/app/.git", hosts, env)
+	want := []string{
+		"run", "-d", "--name", "claude-sandbox-app-feat", "--hostname", "claude-sandbox-app-feat",
+		"--label", "claude-workspace.sandbox=/w/feat",
+		"--cap-add", "NET_ADMIN", "--cap-add", "NET_RAW",
+		"-v", "/w/feat:/workspace",
+		"-v", "/src/app/.git:/src/app/.git",
+		"-w", "/workspace",
+		"-e", "SANDBOX_ALLOWED_HOSTS=api.anthropic.com github.com",
+		"-e", "ANTHROPIC_API_KEY",
+		"img:1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runArgs(docker) =\n%q\nwant\n%q", got, want)
+	}
+
+	podman := strings.Join(runArgs(EnginePodman, "n", "img:1", "/w/feat", "/src/app/.git", hosts, env), " ")
+	if !strings.Contains(podman, "--userns=keep-id:uid=1000,gid=1000 --user root") {
+		t.Errorf("runArgs(podman) = %s, want the user namespace mapped", podman)
+	}
+
+	if got := containerName("/src/My.App", "feat/login"); got != "claude-sandbox-my-app-feat-login" {
+		t.Errorf("containerName() = %q", got)
+	}
+}
@@ -0,0 +1,439 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Code hosts "sandbox finish" can open pull requests on.
+const (
+	hostGitHub = "github"
+	hostGitLab = "gitlab"
+)
+
+// FinishOptions controls "sandbox finish".
+type FinishOptions struct {
+	// SkipTests pushes without running the project's tests.
+	SkipTests bool
+	// TestCmd overrides the detected test command.
+	TestCmd string
+	// Base is the branch the pull request targets; empty for the
+	// repository's default branch.
+	Base string
+	// Draft opens the pull request as a draft.
+	Draft bool
+	// Remove removes the sandbox and deletes its branch instead of
+	// finishing it again when its pull request is already merged.
+	Remove bool
+}
+
+// remoteRepo is a git remote on a known code host.
+type remoteRepo struct {
+	Kind string // hostGitHub, hostGitLab, or "" when unknown
+	Host string // e.g. github.com
+	Path string // e.g. owner/repo, without .git
+}
+
+// pullRequest is an existing pull (or merge) request for a branch.
+type pullRequest struct {
+	URL    string
+	Merged bool
+	Open   bool
+}
+
+// finishUsage is the sandbox finish usage line.
+const finishUsage = "sandbox finish [<project-path>] [<branch-name>] [--skip-tests] [--test-cmd <cmd>] [--base <branch>] [--draft] [--remove]"
+
+// Finish implements "sandbox finish [<project-path>] [<branch-name>]". It runs
+// the sandbox's tests, pushes its branch, and opens a pull request with gh or
+// glab, or prints the URL to open one. With opts.Remove, a sandbox whose pull
+// request is merged is removed instead.
+//
+// Without a project path the project is found from the working directory,
+// which may be the project or any of its sandboxes; without a branch it is the
+// sandbox the working directory is in.
+func Finish(args []string) error {
+	pos, opts, err := parseFinishArgs(args)
+	if err != nil {
+		return err
+	}
+
+	projectPath, branchName := ".", ""
+	switch len(pos) {
+	case 1:
+		branchName = pos[0]
+	case 2:
+		projectPath, branchName = pos[0], pos[1]
+	}
+	if len(pos) < 2 {
+		if projectPath, err = mainWorktree(projectPath); err != nil {
+			return err
+		}
+	}
+
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+	sandboxes, err := listSandboxes(projectDir, worktreeBase)
+	if err != nil {
+		return err
+	}
+	var wt *worktree
+	if branchName == "" {
+		cwd, _ := os.Getwd()
+		if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+			cwd = resolved
+		}
+		for i := range sandboxes {
+			if cwd == sandboxes[i].Dir || strings.HasPrefix(cwd, sandboxes[i].Dir+string(filepath.Separator)) {
+				wt = &sandboxes[i]
+			}
+		}
+		if wt == nil {
+			return fmt.Errorf("not inside a sandbox; pass the branch: claude-workspace sandbox finish <branch-name>")
+		}
+	} else if wt = findSandbox(sandboxes, branchName); wt == nil {
+		return fmt.Errorf("sandbox not found: %s (run: claude-workspace sandbox list %s)", branchName, projectDir)
+	}
+	if wt.Branch == "" {
+		return fmt.Errorf("sandbox %s is on a detached HEAD; check out a branch first", filepath.Base(wt.Dir))
+	}
+
+	return finish(platform.Stdout(), projectDir, *wt, opts)
+}
+
+func parseFinishArgs(args []string) (pos []string, opts FinishOptions, err error) {
+	f := cli.New("sandbox finish", finishUsage)
+	f.StringVar(&opts.TestCmd, "--test-cmd", "cmd", "Test command (default: detected from the project)")
+	f.BoolVar(&opts.SkipTests, "--skip-tests", "Push without running tests")
+	f.StringVar(&opts.Base, "--base", "branch", "PR target branch (default: the repository default)")
+	f.BoolVar(&opts.Draft, "--draft", "Open the PR as a draft")
+	f.BoolVar(&opts.Remove, "--remove", "Remove the sandbox and branch once the PR is merged")
+	pos, err = f.Parse(args)
+	if err != nil {
+		return nil, opts, err
+	}
+	if err := f.CheckArgs(pos, 0, 2); err != nil {
+		return nil, opts, err
+	}
+	if f.Changed("--test-cmd") && opts.TestCmd == "" {
+		return nil, opts, fmt.Errorf("--test-cmd requires a value")
+	}
+	if f.Changed("--base") && opts.Base == "" {
+		return nil, opts, fmt.Errorf("--base requires a value")
+	}
+	if opts.SkipTests && opts.TestCmd != "" {
+		return nil, opts, fmt.Errorf("--skip-tests and --test-cmd cannot be used together")
+	}
+	return pos, opts, nil
+}
+
+// mainWorktree returns the main worktree of the repository containing dir, so
+// that "sandbox finish" run inside a sandbox finds its project.
+func mainWorktree(dir string) (string, error) {
+	common, err := platform.OutputDir(dir, "git", "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %s", dir)
+	}
+	return filepath.Dir(common), nil
+}
+
+// finish runs the tests, push, and pull request steps for the sandbox wt.
+// With opts.Remove and a branch that is already merged, it skips them and
+// removes the sandbox instead.
+func finish(out io.Writer, projectDir string, wt worktree, opts FinishOptions) error {
+	platform.PrintBanner(out, fmt.Sprintf("Finishing Sandbox: %s", wt.Branch))
+	fmt.Fprintln(out)
+
+	remote := pushRemote(wt.Dir, wt.Branch)
+	if remote == "" {
+		return fmt.Errorf("the repository has no remote to push to; add one with: git remote add origin <url>")
+	}
+	rawURL, _ := platform.OutputDir(wt.Dir, "git", "remote", "get-url", remote)
+	repo := parseRemoteURL(rawURL)
+
+	if opts.Remove {
+		pr := findPullRequest(wt.Dir, repo, wt.Branch)
+		if (pr != nil && pr.Merged) || (pr == nil && mergedUpstream(projectDir, remote, wt)) {
+			if pr != nil {
+				platform.PrintSuccess(out, "Merged: "+pr.URL)
+			} else {
+				platform.PrintSuccess(out, fmt.Sprintf("%s is merged", wt.Branch))
+			}
+			// A squash or rebase merge leaves the local branch unmerged in
+			// git's eyes, so the branch is deleted with -D once the host
+			// reports the merge.
+			if err := Remove(projectDir, filepath.Base(wt.Dir), RemoveOptions{DeleteBranch: true, Force: pr != nil}); err != nil {
+				return err
+			}
+			if cwd, err := os.Getwd(); err == nil && !platform.FileExists(cwd) {
+				fmt.Fprintf(out, "The sandbox directory is gone; cd %s\n\n", projectDir)
+			}
+			return nil
+		}
+	}
+
+	changes, err := changedFiles(wt.Dir)
+	if err != nil {
+		return fmt.Errorf("reading worktree status: %w", err)
+	}
+	if len(changes) > 0 {
+		return fmt.Errorf("%s has %d uncommitted change(s); commit or stash them first (see: claude-workspace sandbox status %s %s)",
+			wt.Dir, len(changes), projectDir, wt.Branch)
+	}
+
+	platform.PrintStep(out, 1, 3, "Running tests...")
+	testCmd := opts.TestCmd
+	if testCmd == "" && !opts.SkipTests {
+		testCmd = platform.DetectTestCommand(wt.Dir)
+	}
+	switch {
+	case opts.SkipTests:
+		fmt.Fprintln(out, "  Skipped (--skip-tests).")
+	case testCmd == "":
+		platform.PrintWarningLine(out, "No test command detected; pass --test-cmd to run one")
+	default:
+		platform.PrintCommand(out, testCmd)
+		if err := platform.RunDir(wt.Dir, "sh", "-c", testCmd); err != nil {
+			return fmt.Errorf("tests failed (%s): %w\nFix them, or pass --skip-tests to push anyway", testCmd, err)
+		}
+		platform.PrintSuccess(out, "Tests passed")
+	}
+
+	platform.PrintStep(out, 2, 3, "Pushing branch...")
+	if err := platform.RunDir(wt.Dir, "git", "push", "-u", remote, wt.Branch); err != nil {
+		return fmt.Errorf("pushing %s to %s: %w", wt.Branch, remote, err)
+	}
+	platform.PrintSuccess(out, fmt.Sprintf("Pushed %s to %s", wt.Branch, remote))
+
+	platform.PrintStep(out, 3, 3, "Opening pull request...")
+	pr := openPullRequest(out, wt.Dir, repo, wt.Branch, opts)
+
+	platform.PrintBanner(out, "Sandbox Finished")
+	fmt.Fprintf(out, "\nBranch:       %s\n", wt.Branch)
+	if pr != nil && pr.URL != "" {
+		fmt.Fprintf(out, "Pull request: %s\n", pr.URL)
+	}
+	if opts.Remove {
+		fmt.Fprintln(out, "\nNot merged yet, so the sandbox was kept.")
+	}
+	fmt.Fprintln(out, "\nOnce it is merged, remove the sandbox and its branch with:")
+	fmt.Fprintf(out, "  claude-workspace sandbox finish %s %s --remove\n", projectDir, wt.Branch)
+	fmt.Fprintln(out)
+	return nil
+}
+
+// pushRemote returns the remote to push branch to: its configured remote,
+// else origin, else the only remote.
+func pushRemote(dir, branch string) string {
+	if remote, err := platform.OutputDir(dir, "git", "config", "branch."+branch+".remote"); err == nil && remote != "" {
+		return remote
+	}
+	out, err := platform.OutputDir(dir, "git", "remote")
+	if err != nil {
+		return ""
+	}
+	remotes := strings.Fields(out)
+	for _, r := range remotes {
+		if r == "origin" {
+			return r
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0]
+	}
+	return ""
+}
+
+// hostCLI returns the code host CLI for repo, gh or glab, if it is installed
+// and logged in.
+func hostCLI(dir string, repo remoteRepo) string {
+	cli := map[string]string{hostGitHub: "gh", hostGitLab: "glab"}[repo.Kind]
+	if cli == "" || !platform.Exists(cli) || platform.RunQuietDir(dir, cli, "auth", "status") != nil {
+		return ""
+	}
+	return cli
+}
+
+// findPullRequest returns branch's open or merged pull request, or nil when
+// there is none or no host CLI to ask.
+func findPullRequest(dir string, repo remoteRepo, branch string) *pullRequest {
+	cli := hostCLI(dir, repo)
+	if cli == "" {
+		return nil
+	}
+	viewOut, err := platform.OutputDir(dir, cli, viewArgs(repo.Kind, branch)...)
+	if err != nil {
+		return nil
+	}
+	if pr, ok := parsePullRequest(repo.Kind, viewOut); ok && (pr.Merged || pr.Open) {
+		return &pr
+	}
+	return nil
+}
+
+// openPullRequest finds or creates the pull request for branch with gh or
+// glab. When neither is installed and logged in, or the host is unknown, it
+// prints how to open one by hand and returns nil.
+func openPullRequest(out io.Writer, dir string, repo remoteRepo, branch string, opts FinishOptions) *pullRequest {
+	cli := hostCLI(dir, repo)
+	if cli == "" {
+		if u := newPullRequestURL(repo, branch, opts.Base); u != "" {
+			platform.PrintInfo(out, "gh/glab is not installed or not logged in; open the pull request in your browser:")
+			fmt.Fprintf(out, "  %s\n", u)
+		} else {
+			platform.PrintInfo(out, fmt.Sprintf("Unrecognized code host; open a pull request for %s by hand", branch))
+		}
+		return nil
+	}
+
+	if pr := findPullRequest(dir, repo, branch); pr != nil {
+		if pr.Merged {
+			platform.PrintSuccess(out, "Already merged: "+pr.URL)
+		} else {
+			platform.PrintSuccess(out, "Already open: "+pr.URL)
+		}
+		return pr
+	}
+
+	createOut, err := platform.OutputDir(dir, cli, createArgs(repo.Kind, branch, opts)...)
+	if err != nil {
+		platform.PrintWarningLine(out, fmt.Sprintf("%s could not open the pull request: %v", cli, err))
+		if u := newPullRequestURL(repo, branch, opts.Base); u != "" {
+			fmt.Fprintf(out, "  Open it in your browser: %s\n", u)
+		}
+		return nil
+	}
+	pr := &pullRequest{URL: lastURL(createOut), Open: true}
+	platform.PrintSuccess(out, "Opened "+pr.URL)
+	return pr
+}
+
+// viewArgs returns the gh or glab arguments that describe branch's pull
+// request as JSON.
+func viewArgs(kind, branch string) []string {
+	if kind == hostGitLab {
+		return []string{"mr", "view", branch, "--output", "json"}
+	}
+	return []string{"pr", "view", branch, "--json", "url,state"}
+}
+
+// createArgs returns the gh or glab arguments that open a pull request for
+// branch, titled and described from its commits.
+func createArgs(kind, branch string, opts FinishOptions) []string {
+	var args []string
+	if kind == hostGitLab {
+		args = []string{"mr", "create", "--source-branch", branch, "--fill", "--yes"}
+		if opts.Base != "" {
+			args = append(args, "--target-branch", opts.Base)
+		}
+	} else {
+		args = []string{"pr", "create", "--head", branch, "--fill"}
+		if opts.Base != "" {
+			args = append(args, "--base", opts.Base)
+		}
+	}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+	return args
+}
+
+// parsePullRequest decodes "gh pr view --json url,state" or "glab mr view
+// --output json" output.
+func parsePullRequest(kind, out string) (pullRequest, bool) {
+	var view struct {
+		URL    string `json:"url"`
+		WebURL string `json:"web_url"`
+		State  string `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(out), &view); err != nil || view.State == "" {
+		return pullRequest{}, false
+	}
+	pr := pullRequest{URL: view.URL}
+	if kind == hostGitLab {
+		pr.URL = view.WebURL
+	}
+	switch strings.ToLower(view.State) {
+	case "merged":
+		pr.Merged = true
+	case "open", "opened":
+		pr.Open = true
+	}
+	return pr, true
+}
+
+// lastURL returns the last http(s) URL in out, which is where gh and glab
+// print the new pull request.
+func lastURL(out string) string {
+	fields := strings.Fields(out)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if strings.HasPrefix(fields[i], "https://") || strings.HasPrefix(fields[i], "http://") {
+			return fields[i]
+		}
+	}
+	return strings.TrimSpace(out)
+}
+
+// parseRemoteURL recognizes scp-style (git@host:path), ssh://, and https://
+// remote URLs. Hosts whose name contains "github" or "gitlab" are taken to be
+// GitHub (including Enterprise) or GitLab.
+func parseRemoteURL(raw string) remoteRepo {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(raw, "@"); ok && !strings.Contains(at, "/") {
+		host, path, _ = strings.Cut(rest, ":")
+	} else {
+		return remoteRepo{}
+	}
+	repo := remoteRepo{Host: host, Path: strings.TrimSuffix(strings.Trim(path, "/"), ".git")}
+	switch {
+	case strings.Contains(host, "github"):
+		repo.Kind = hostGitHub
+	case strings.Contains(host, "gitlab"):
+		repo.Kind = hostGitLab
+	}
+	return repo
+}
+
+// newPullRequestURL returns the web page that opens a pull request for branch,
+// or "" for an unknown host.
+func newPullRequestURL(repo remoteRepo, branch, base string) string {
+	if repo.Path == "" {
+		return ""
+	}
+	prefix := "https://" + repo.Host + "/" + repo.Path
+	switch repo.Kind {
+	case hostGitHub:
+		if base != "" {
+			return prefix + "/compare/" + base + "..." + branch + "?expand=1"
+		}
+		return prefix + "/pull/new/" + branch
+	case hostGitLab:
+		q := url.Values{"merge_request[source_branch]": {branch}}
+		if base != "" {
+			q.Set("merge_request[target_branch]", base)
+		}
+		return prefix + "/-/merge_requests/new?" + q.Encode()
+	}
+	return ""
+}
+
+// mergedUpstream reports, without a code host CLI, whether wt's branch has
+// been merged: after fetching, its upstream branch was deleted or it is
+// merged into the project's branch.
+func mergedUpstream(projectDir, remote string, wt worktree) bool {
+	_ = platform.RunQuietDir(projectDir, "git", "fetch", "--prune", remote)
+	return pruneReason(projectDir, wt, mergedBranches(projectDir), goneBranches(projectDir)) != ""
+}
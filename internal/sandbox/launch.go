@@ -0,0 +1,81 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+// Launch opens the sandbox for branchName with Claude Code already running:
+// in a new tmux window when run inside tmux, in a new tmux session when tmux is
+// installed, and otherwise in a subshell rooted at the worktree.
+func Launch(projectPath, branchName string) error {
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+	worktreeDir := filepath.Join(worktreeBase, branchName)
+	if !platform.FileExists(worktreeDir) {
+		return fmt.Errorf("sandbox not found: %s", worktreeDir)
+	}
+	if !platform.Exists("claude") {
+		return fmt.Errorf("claude not found in PATH\nInstall: %s", tools.ClaudeInstallCmd)
+	}
+
+	inTmux := os.Getenv("TMUX") != ""
+	hasTmux := platform.Exists("tmux")
+	name := sessionName(filepath.Base(projectDir), branchName)
+	argv := launchCommand(worktreeDir, name, inTmux, hasTmux, os.Getenv("SHELL"))
+
+	out := platform.Stdout()
+	switch {
+	case inTmux && hasTmux:
+		if err := platform.Run(argv[0], argv[1:]...); err != nil {
+			return fmt.Errorf("opening tmux window: %w", err)
+		}
+		platform.PrintSuccess(out, fmt.Sprintf("Opened tmux window %q with claude running in %s", name, worktreeDir))
+		return nil
+	case hasTmux:
+		fmt.Fprintf(out, "\nStarting tmux session %q (detach with Ctrl-b d)...\n", name)
+	default:
+		fmt.Fprintf(out, "\ntmux not found; starting claude in a subshell at %s (exit to return)...\n", worktreeDir)
+	}
+
+	if _, err := platform.RunSpawn(argv[0], argv[1:]...); err != nil {
+		return err
+	}
+	return nil
+}
+
+// launchCommand returns the command that opens dir with claude running.
+func launchCommand(dir, name string, inTmux, hasTmux bool, shell string) []string {
+	switch {
+	case inTmux && hasTmux:
+		return []string{"tmux", "new-window", "-n", name, "-c", dir, "claude"}
+	case hasTmux:
+		// -A attaches to the session if it is already running.
+		return []string{"tmux", "new-session", "-A", "-s", name, "-c", dir, "claude"}
+	default:
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		// Run claude, then leave the user in a shell inside the worktree.
+		script := fmt.Sprintf("cd %s && claude; exec %s", shellQuote(dir), shellQuote(shell))
+		return []string{shell, "-c", script}
+	}
+}
+
+// sessionName builds a tmux session/window name; tmux does not allow '.' or
+// ':' in session names.
+func sessionName(project, branch string) string {
+	return strings.NewReplacer(".", "-", ":", "-", "/", "-").Replace(project + "-" + branch)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,53 @@
+package sandbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLaunchCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		inTmux  bool
+		hasTmux bool
+		shell   string
+		want    []string
+	}{
+		{
+			name: "inside tmux", inTmux: true, hasTmux: true,
+			want: []string{"tmux", "new-window", "-n", "app-feat", "-c", "/w/feat", "claude"},
+		},
+		{
+			name: "tmux installed", hasTmux: true,
+			want: []string{"tmux", "new-session", "-A", "-s", "app-feat", "-c", "/w/feat", "claude"},
+		},
+		{
+			name: "no tmux", shell: "/bin/zsh",
+			want: []string{"/bin/zsh", "-c", "cd '/w/feat' && claude; exec '/bin/zsh'"},
+		},
+		{
+			name: "no tmux or shell",
+			want: []string{"/bin/sh", "-c", "cd '/w/feat' && claude; exec '/bin/sh'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := launchCommand("/w/feat", "app-feat", tt.inTmux, tt.hasTmux, tt.shell)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("launchCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	if got := sessionName("my.app", "fix:login/ui"); got != "my-app-fix-login-ui" {
+		t.Errorf("sessionName() = %q", got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("it's"); got != `'it'\''s'` {
+		t.Errorf("shellQuote() = %q", got)
+	}
+}
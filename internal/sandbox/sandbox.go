@@ -8,7 +8,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
@@ -16,12 +15,13 @@ import (
 // Create creates a git worktree sandbox for the given project path and branch name.
 // It copies Claude configuration and installs dependencies in the new worktree.
 func Create(projectPath, branchName string) error {
+	out := platform.Stdout()
 	if projectPath == "" || branchName == "" {
 		fmt.Fprintln(os.Stderr, "Usage: claude-workspace sandbox create <project-path> <branch-name>")
-		fmt.Println("\nExamples:")
-		fmt.Println("  claude-workspace sandbox create ./my-project feature-auth")
-		fmt.Println("  claude-workspace sandbox create ./my-project feature-api")
-		fmt.Println("  claude-workspace sandbox create ./my-project bugfix-login")
+		fmt.Fprintln(out, "\nExamples:")
+		fmt.Fprintln(out, "  claude-workspace sandbox create ./my-project feature-auth")
+		fmt.Fprintln(out, "  claude-workspace sandbox create ./my-project feature-api")
+		fmt.Fprintln(out, "  claude-workspace sandbox create ./my-project bugfix-login")
 		os.Exit(1)
 	}
 
@@ -43,8 +43,8 @@ func Create(projectPath, branchName string) error {
 	worktreeBase := filepath.Join(filepath.Dir(projectDir), projectName+"-worktrees")
 	worktreeDir := filepath.Join(worktreeBase, branchName)
 
-	platform.PrintBanner(os.Stdout, fmt.Sprintf("Creating Sandboxed Branch: %s", branchName))
-	fmt.Println()
+	platform.PrintBanner(out, fmt.Sprintf("Creating Sandboxed Branch: %s", branchName))
+	fmt.Fprintln(out)
 
 	// Create worktrees directory
 	if err := os.MkdirAll(worktreeBase, 0755); err != nil {
@@ -53,60 +53,62 @@ func Create(projectPath, branchName string) error {
 
 	// Check if worktree already exists
 	if platform.FileExists(worktreeDir) {
-		fmt.Printf("Worktree already exists at: %s\n", worktreeDir)
-		fmt.Printf("To use it: cd %s && claude\n", worktreeDir)
+		fmt.Fprintf(out, "Worktree already exists at: %s\n", worktreeDir)
+		fmt.Fprintf(out, "To use it: cd %s && claude\n", worktreeDir)
 		return nil
 	}
 
 	// Create the worktree
-	platform.PrintStep(os.Stdout, 1, 4, "Creating git worktree...")
+	platform.PrintStep(out, 1, 4, "Creating git worktree...")
 	if err := createWorktree(projectDir, worktreeDir, branchName); err != nil {
 		return err
 	}
-	fmt.Printf("  Worktree created at: %s\n", worktreeDir)
+	fmt.Fprintf(out, "  Worktree created at: %s\n", worktreeDir)
 
 	// Copy .claude configuration to worktree if it exists in main project
-	platform.PrintStep(os.Stdout, 2, 4, "Setting up Claude configuration...")
-	copyClaudeConfig(projectDir, worktreeDir)
+	platform.PrintStep(out, 2, 4, "Setting up Claude configuration...")
+	copyClaudeConfig(out, projectDir, worktreeDir)
 
 	// Copy .mcp.json if not tracked by git
-	platform.PrintStep(os.Stdout, 3, 4, "Setting up MCP configuration...")
-	copyMCPConfig(projectDir, worktreeDir)
+	platform.PrintStep(out, 3, 4, "Setting up MCP configuration...")
+	copyMCPConfig(out, projectDir, worktreeDir)
 
 	// Install dependencies if needed
-	platform.PrintStep(os.Stdout, 4, 4, "Setting up dependencies...")
-	installWorktreeDeps(worktreeDir)
-
-	platform.PrintBanner(os.Stdout, "Sandbox Ready")
-	fmt.Printf("\nBranch:    %s\n", branchName)
-	fmt.Printf("Directory: %s\n", worktreeDir)
-	fmt.Println("\nTo start working:")
-	fmt.Printf("  cd %s\n", worktreeDir)
-	fmt.Println("  claude")
-	fmt.Println("\nTo list all worktrees:")
-	fmt.Printf("  git -C %s worktree list\n", projectDir)
-	fmt.Println("\nTo remove this sandbox when done:")
-	fmt.Printf("  git -C %s worktree remove %s\n", projectDir, worktreeDir)
-	fmt.Println()
+	platform.PrintStep(out, 4, 4, "Setting up dependencies...")
+	installWorktreeDeps(out, worktreeDir)
+
+	platform.PrintBanner(out, "Sandbox Ready")
+	fmt.Fprintf(out, "\nBranch:    %s\n", branchName)
+	fmt.Fprintf(out, "Directory: %s\n", worktreeDir)
+	fmt.Fprintln(out, "\nTo start working:")
+	fmt.Fprintf(out, "  cd %s\n", worktreeDir)
+	fmt.Fprintln(out, "  claude")
+	fmt.Fprintln(out, "\nTo list all sandboxes:")
+	fmt.Fprintf(out, "  claude-workspace sandbox list %s\n", projectDir)
+	fmt.Fprintln(out, "\nTo remove this sandbox when done:")
+	fmt.Fprintf(out, "  claude-workspace sandbox remove %s %s --delete-branch\n", projectDir, branchName)
+	fmt.Fprintln(out)
 
 	return nil
 }
 
 func createWorktree(projectDir, worktreeDir, branchName string) error {
-	branchExists := platform.RunQuietDir(projectDir, "git", "rev-parse", "--verify", branchName) == nil
-	if branchExists {
-		if err := platform.RunDir(projectDir, "git", "worktree", "add", worktreeDir, branchName); err != nil {
-			return fmt.Errorf("creating worktree: %w", err)
-		}
-	} else {
-		if err := platform.RunDir(projectDir, "git", "worktree", "add", "-b", branchName, worktreeDir); err != nil {
-			return fmt.Errorf("creating worktree: %w", err)
-		}
+	if err := platform.RunDir(projectDir, "git", worktreeAddArgs(projectDir, worktreeDir, branchName)...); err != nil {
+		return fmt.Errorf("creating worktree: %w", err)
 	}
 	return nil
 }
 
-func copyClaudeConfig(projectDir, worktreeDir string) {
+// worktreeAddArgs returns the "git worktree add" arguments that check out
+// branchName at worktreeDir, creating the branch if it does not exist.
+func worktreeAddArgs(projectDir, worktreeDir, branchName string) []string {
+	if platform.RunQuietDir(projectDir, "git", "rev-parse", "--verify", branchName) == nil {
+		return []string{"worktree", "add", worktreeDir, branchName}
+	}
+	return []string{"worktree", "add", "-b", branchName, worktreeDir}
+}
+
+func copyClaudeConfig(w io.Writer, projectDir, worktreeDir string) {
 	claudeDir := filepath.Join(projectDir, ".claude")
 	if !platform.FileExists(claudeDir) {
 		return
@@ -117,25 +119,25 @@ func copyClaudeConfig(projectDir, worktreeDir string) {
 	localSettings := filepath.Join(claudeDir, "settings.local.json")
 	if platform.FileExists(localSettings) {
 		if err := platform.CopyFile(localSettings, filepath.Join(worktreeClaudeDir, "settings.local.json")); err == nil {
-			platform.PrintSuccess(os.Stdout, "Copied local settings to worktree")
+			platform.PrintSuccess(w, "Copied local settings to worktree")
 		}
 	}
 
 }
 
-func copyMCPConfig(projectDir, worktreeDir string) {
+func copyMCPConfig(w io.Writer, projectDir, worktreeDir string) {
 	mcpJSON := filepath.Join(projectDir, ".mcp.json")
 	worktreeMcp := filepath.Join(worktreeDir, ".mcp.json")
 	if platform.FileExists(mcpJSON) && !platform.FileExists(worktreeMcp) {
 		if err := platform.CopyFile(mcpJSON, worktreeMcp); err == nil {
-			platform.PrintSuccess(os.Stdout, "Copied .mcp.json to worktree")
+			platform.PrintSuccess(w, "Copied .mcp.json to worktree")
 		}
 	}
 }
 
 // depInstallers is the ordered list of dependency installers for worktrees.
 // Each installer returns true if it handled the language (even if install failed).
-var depInstallers = []func(string) bool{
+var depInstallers = []func(io.Writer, string) bool{
 	installJSDeps,
 	installRubyDeps,
 	installPythonDeps,
@@ -148,99 +150,99 @@ var depInstallers = []func(string) bool{
 	installScalaDeps,
 }
 
-func installWorktreeDeps(worktreeDir string) {
+func installWorktreeDeps(w io.Writer, worktreeDir string) {
 	installed := false
 	for _, install := range depInstallers {
-		if install(worktreeDir) {
+		if install(w, worktreeDir) {
 			installed = true
 		}
 	}
 	if !installed {
-		fmt.Println("  No recognized dependency files found. Skipping dependency installation.")
+		fmt.Fprintln(w, "  No recognized dependency files found. Skipping dependency installation.")
 	}
 }
 
-func installJSDeps(dir string) bool {
+func installJSDeps(w io.Writer, dir string) bool {
 	switch {
 	case platform.FileExists(filepath.Join(dir, "bun.lockb")) || platform.FileExists(filepath.Join(dir, "bun.lock")):
 		if err := platform.RunQuietDir(dir, "bun", "install"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies installed (bun)")
+			platform.PrintSuccess(w, "Dependencies installed (bun)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not install bun dependencies")
+			platform.PrintWarningLine(w, "Could not install bun dependencies")
 		}
 	case platform.FileExists(filepath.Join(dir, "package-lock.json")):
 		if err := platform.RunQuietDir(dir, "npm", "ci"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies installed (npm)")
+			platform.PrintSuccess(w, "Dependencies installed (npm)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not install npm dependencies")
+			platform.PrintWarningLine(w, "Could not install npm dependencies")
 		}
 	case platform.FileExists(filepath.Join(dir, "yarn.lock")):
 		if err := platform.RunQuietDir(dir, "yarn", "install", "--frozen-lockfile"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies installed (yarn)")
+			platform.PrintSuccess(w, "Dependencies installed (yarn)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not install yarn dependencies")
+			platform.PrintWarningLine(w, "Could not install yarn dependencies")
 		}
 	case platform.FileExists(filepath.Join(dir, "pnpm-lock.yaml")):
 		if err := platform.RunQuietDir(dir, "pnpm", "install", "--frozen-lockfile"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies installed (pnpm)")
+			platform.PrintSuccess(w, "Dependencies installed (pnpm)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not install pnpm dependencies")
+			platform.PrintWarningLine(w, "Could not install pnpm dependencies")
 		}
 	case platform.FileExists(filepath.Join(dir, "package.json")):
-		fmt.Println("  No lockfile found. Run your package manager to install dependencies.")
+		fmt.Fprintln(w, "  No lockfile found. Run your package manager to install dependencies.")
 	default:
 		return false
 	}
 	return true
 }
 
-func installRubyDeps(dir string) bool {
+func installRubyDeps(w io.Writer, dir string) bool {
 	switch {
 	case platform.FileExists(filepath.Join(dir, "Gemfile.lock")):
 		if platform.Exists("bundle") {
 			if err := platform.RunQuietDir(dir, "bundle", "install"); err == nil {
-				platform.PrintSuccess(os.Stdout, "Dependencies installed (bundler)")
+				platform.PrintSuccess(w, "Dependencies installed (bundler)")
 			} else {
-				platform.PrintWarningLine(os.Stdout, "Could not install bundler dependencies")
+				platform.PrintWarningLine(w, "Could not install bundler dependencies")
 			}
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Gemfile.lock found but bundler not installed")
+			platform.PrintWarningLine(w, "Gemfile.lock found but bundler not installed")
 		}
 	case platform.FileExists(filepath.Join(dir, "Gemfile")):
-		fmt.Println("  Gemfile found but no Gemfile.lock. Run `bundle install` to install dependencies.")
+		fmt.Fprintln(w, "  Gemfile found but no Gemfile.lock. Run `bundle install` to install dependencies.")
 	default:
 		return false
 	}
 	return true
 }
 
-func installPythonDeps(dir string) bool {
+func installPythonDeps(w io.Writer, dir string) bool {
 	switch {
 	case platform.FileExists(filepath.Join(dir, "poetry.lock")):
 		if platform.Exists("poetry") {
 			if err := platform.RunQuietDir(dir, "poetry", "install"); err == nil {
-				platform.PrintSuccess(os.Stdout, "Dependencies installed (poetry)")
+				platform.PrintSuccess(w, "Dependencies installed (poetry)")
 			} else {
-				platform.PrintWarningLine(os.Stdout, "Could not install poetry dependencies")
+				platform.PrintWarningLine(w, "Could not install poetry dependencies")
 			}
 		}
 	case platform.FileExists(filepath.Join(dir, "uv.lock")):
 		if platform.Exists("uv") {
 			if err := platform.RunQuietDir(dir, "uv", "sync"); err == nil {
-				platform.PrintSuccess(os.Stdout, "Dependencies installed (uv)")
+				platform.PrintSuccess(w, "Dependencies installed (uv)")
 			} else {
-				platform.PrintWarningLine(os.Stdout, "Could not install uv dependencies")
+				platform.PrintWarningLine(w, "Could not install uv dependencies")
 			}
 		}
 	case platform.FileExists(filepath.Join(dir, "requirements.txt")):
 		if platform.Exists("pip") {
 			if err := platform.RunQuietDir(dir, "pip", "install", "-r", "requirements.txt"); err == nil {
-				platform.PrintSuccess(os.Stdout, "Dependencies installed (pip)")
+				platform.PrintSuccess(w, "Dependencies installed (pip)")
 			} else {
-				platform.PrintWarningLine(os.Stdout, "Could not install pip dependencies")
+				platform.PrintWarningLine(w, "Could not install pip dependencies")
 			}
 		} else {
-			fmt.Println("  requirements.txt found. Run `pip install -r requirements.txt` to install dependencies.")
+			fmt.Fprintln(w, "  requirements.txt found. Run `pip install -r requirements.txt` to install dependencies.")
 		}
 	default:
 		return false
@@ -248,46 +250,46 @@ func installPythonDeps(dir string) bool {
 	return true
 }
 
-func installMavenDeps(dir string) bool {
+func installMavenDeps(w io.Writer, dir string) bool {
 	if !platform.FileExists(filepath.Join(dir, "pom.xml")) {
 		return false
 	}
 	if platform.Exists("mvn") {
 		if err := platform.RunQuietDir(dir, "mvn", "dependency:resolve", "-q"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies resolved (Maven)")
+			platform.PrintSuccess(w, "Dependencies resolved (Maven)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not resolve Maven dependencies")
+			platform.PrintWarningLine(w, "Could not resolve Maven dependencies")
 		}
 	} else {
-		fmt.Println("  pom.xml found but mvn not installed.")
+		fmt.Fprintln(w, "  pom.xml found but mvn not installed.")
 	}
 	return true
 }
 
-func installGradleDeps(dir string) bool {
+func installGradleDeps(w io.Writer, dir string) bool {
 	if !platform.FileExists(filepath.Join(dir, "build.gradle")) && !platform.FileExists(filepath.Join(dir, "build.gradle.kts")) {
 		return false
 	}
 	switch {
 	case platform.FileExists(filepath.Join(dir, "gradlew")):
 		if err := platform.RunQuietDir(dir, "./gradlew", "dependencies", "--quiet"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies resolved (Gradle)")
+			platform.PrintSuccess(w, "Dependencies resolved (Gradle)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not resolve Gradle dependencies")
+			platform.PrintWarningLine(w, "Could not resolve Gradle dependencies")
 		}
 	case platform.Exists("gradle"):
 		if err := platform.RunQuietDir(dir, "gradle", "dependencies", "--quiet"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies resolved (Gradle)")
+			platform.PrintSuccess(w, "Dependencies resolved (Gradle)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not resolve Gradle dependencies")
+			platform.PrintWarningLine(w, "Could not resolve Gradle dependencies")
 		}
 	default:
-		fmt.Println("  Gradle project found but no gradlew wrapper or gradle binary.")
+		fmt.Fprintln(w, "  Gradle project found but no gradlew wrapper or gradle binary.")
 	}
 	return true
 }
 
-func installDotNetDeps(dir string) bool {
+func installDotNetDeps(w io.Writer, dir string) bool {
 	csprojMatches, _ := filepath.Glob(filepath.Join(dir, "*.csproj"))
 	slnMatches, _ := filepath.Glob(filepath.Join(dir, "*.sln"))
 	if len(csprojMatches) == 0 && len(slnMatches) == 0 {
@@ -295,179 +297,176 @@ func installDotNetDeps(dir string) bool {
 	}
 	if platform.Exists("dotnet") {
 		if err := platform.RunQuietDir(dir, "dotnet", "restore"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies restored (dotnet)")
+			platform.PrintSuccess(w, "Dependencies restored (dotnet)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not restore dotnet dependencies")
+			platform.PrintWarningLine(w, "Could not restore dotnet dependencies")
 		}
 	}
 	return true
 }
 
-func installElixirDeps(dir string) bool {
+func installElixirDeps(w io.Writer, dir string) bool {
 	if !platform.FileExists(filepath.Join(dir, "mix.exs")) {
 		return false
 	}
 	if platform.Exists("mix") {
 		if err := platform.RunQuietDir(dir, "mix", "deps.get"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies installed (mix)")
+			platform.PrintSuccess(w, "Dependencies installed (mix)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not install mix dependencies")
+			platform.PrintWarningLine(w, "Could not install mix dependencies")
 		}
 	}
 	return true
 }
 
-func installPHPDeps(dir string) bool {
+func installPHPDeps(w io.Writer, dir string) bool {
 	switch {
 	case platform.FileExists(filepath.Join(dir, "composer.lock")):
 		if platform.Exists("composer") {
 			if err := platform.RunQuietDir(dir, "composer", "install"); err == nil {
-				platform.PrintSuccess(os.Stdout, "Dependencies installed (composer)")
+				platform.PrintSuccess(w, "Dependencies installed (composer)")
 			} else {
-				platform.PrintWarningLine(os.Stdout, "Could not install composer dependencies")
+				platform.PrintWarningLine(w, "Could not install composer dependencies")
 			}
 		}
 	case platform.FileExists(filepath.Join(dir, "composer.json")):
-		fmt.Println("  composer.json found but no composer.lock. Run `composer install` to install dependencies.")
+		fmt.Fprintln(w, "  composer.json found but no composer.lock. Run `composer install` to install dependencies.")
 	default:
 		return false
 	}
 	return true
 }
 
-func installSwiftDeps(dir string) bool {
+func installSwiftDeps(w io.Writer, dir string) bool {
 	if !platform.FileExists(filepath.Join(dir, "Package.swift")) {
 		return false
 	}
 	if platform.Exists("swift") {
 		if err := platform.RunQuietDir(dir, "swift", "package", "resolve"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies resolved (Swift PM)")
+			platform.PrintSuccess(w, "Dependencies resolved (Swift PM)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not resolve Swift package dependencies")
+			platform.PrintWarningLine(w, "Could not resolve Swift package dependencies")
 		}
 	}
 	return true
 }
 
-func installScalaDeps(dir string) bool {
+func installScalaDeps(w io.Writer, dir string) bool {
 	if !platform.FileExists(filepath.Join(dir, "build.sbt")) {
 		return false
 	}
 	if platform.Exists("sbt") {
 		if err := platform.RunQuietDir(dir, "sbt", "update"); err == nil {
-			platform.PrintSuccess(os.Stdout, "Dependencies resolved (sbt)")
+			platform.PrintSuccess(w, "Dependencies resolved (sbt)")
 		} else {
-			platform.PrintWarningLine(os.Stdout, "Could not resolve sbt dependencies")
+			platform.PrintWarningLine(w, "Could not resolve sbt dependencies")
 		}
 	}
 	return true
 }
 
+// RemoveOptions controls sandbox removal.
+type RemoveOptions struct {
+	// DeleteBranch also deletes the sandbox's branch once the worktree is gone.
+	DeleteBranch bool
+	// Force removes a worktree with uncommitted changes and deletes an
+	// unmerged branch.
+	Force bool
+}
+
 // Remove removes a git worktree sandbox for the given project path and branch name.
-func Remove(projectPath, branchName string) error {
+func Remove(projectPath, branchName string, opts RemoveOptions) error {
 	if projectPath == "" || branchName == "" {
-		fmt.Fprintln(os.Stderr, "Usage: claude-workspace sandbox remove <project-path> <branch-name>")
-		fmt.Println("\nExamples:")
-		fmt.Println("  claude-workspace sandbox remove ./my-project feature-auth")
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace sandbox remove <project-path> <branch-name> [--delete-branch] [--force]")
+		fmt.Fprintln(platform.Stdout(), "\nExamples:")
+		fmt.Fprintln(platform.Stdout(), "  claude-workspace sandbox remove ./my-project feature-auth")
+		fmt.Fprintln(platform.Stdout(), "  claude-workspace sandbox remove ./my-project feature-auth --delete-branch")
 		os.Exit(1)
 	}
 
-	projectDir, err := filepath.Abs(projectPath)
+	projectDir, worktreeBase, err := resolveProject(projectPath)
 	if err != nil {
-		return fmt.Errorf("resolving path: %w", err)
+		return err
 	}
 
-	if !platform.FileExists(projectDir) {
-		return fmt.Errorf("project directory not found: %s", projectDir)
+	worktreeDir := filepath.Join(worktreeBase, branchName)
+	if !platform.FileExists(worktreeDir) {
+		return fmt.Errorf("sandbox not found: %s", worktreeDir)
 	}
 
-	if err := platform.RunQuietDir(projectDir, "git", "rev-parse", "--git-dir"); err != nil {
-		return fmt.Errorf("not a git repository: %s", projectDir)
+	// Look up the branch checked out in the worktree before it is removed.
+	branch := branchName
+	if sandboxes, err := listSandboxes(projectDir, worktreeBase); err == nil {
+		if wt := findSandbox(sandboxes, branchName); wt != nil && wt.Branch != "" {
+			branch = wt.Branch
+		}
 	}
 
-	projectName := filepath.Base(projectDir)
-	worktreeBase := filepath.Join(filepath.Dir(projectDir), projectName+"-worktrees")
-	worktreeDir := filepath.Join(worktreeBase, branchName)
+	platform.PrintBanner(platform.Stdout(), fmt.Sprintf("Removing Sandbox: %s", branchName))
+	fmt.Fprintln(platform.Stdout())
 
-	if !platform.FileExists(worktreeDir) {
-		return fmt.Errorf("sandbox not found: %s", worktreeDir)
+	total := 3
+	if opts.DeleteBranch {
+		total = 4
 	}
 
-	platform.PrintBanner(os.Stdout, fmt.Sprintf("Removing Sandbox: %s", branchName))
-	fmt.Println()
-
-	platform.PrintStep(os.Stdout, 1, 3, "Removing git worktree...")
-	if err := platform.RunDir(projectDir, "git", "worktree", "remove", worktreeDir); err != nil {
-		return fmt.Errorf("removing worktree: %w\nIf the worktree has uncommitted changes, commit or discard them first", err)
+	platform.PrintStep(platform.Stdout(), 1, total, "Removing git worktree...")
+	removeArgs := []string{"worktree", "remove", worktreeDir}
+	if opts.Force {
+		removeArgs = []string{"worktree", "remove", "--force", worktreeDir}
+	}
+	if err := platform.RunDir(projectDir, "git", removeArgs...); err != nil {
+		return fmt.Errorf("removing worktree: %w\nIf the worktree has uncommitted changes, commit or discard them first, or pass --force", err)
 	}
 
-	platform.PrintStep(os.Stdout, 2, 3, "Pruning worktree references...")
+	platform.PrintStep(platform.Stdout(), 2, total, "Pruning worktree references...")
 	_ = platform.RunQuietDir(projectDir, "git", "worktree", "prune")
 
-	platform.PrintStep(os.Stdout, 3, 3, "Cleaning up...")
+	platform.PrintStep(platform.Stdout(), 3, total, "Cleaning up...")
 	removeEmptyDir(worktreeBase)
+	if removeContainer(projectDir, branchName) {
+		platform.PrintSuccess(platform.Stdout(), fmt.Sprintf("Removed container %s", containerName(projectDir, branchName)))
+	}
+
+	if opts.DeleteBranch {
+		platform.PrintStep(platform.Stdout(), 4, total, "Deleting branch...")
+		deleteBranch(platform.Stdout(), projectDir, branch, opts.Force)
+	}
 
-	platform.PrintBanner(os.Stdout, "Sandbox Removed")
-	fmt.Printf("\nBranch: %s\n", branchName)
-	fmt.Println()
+	platform.PrintBanner(platform.Stdout(), "Sandbox Removed")
+	fmt.Fprintf(platform.Stdout(), "\nBranch: %s\n", branchName)
+	fmt.Fprintln(platform.Stdout())
 
 	return nil
 }
 
 // List lists all sandboxed worktrees for the given project, writing to stdout.
 func List(projectPath string) error {
-	return ListTo(os.Stdout, projectPath)
+	return ListTo(platform.Stdout(), projectPath)
 }
 
-// ListTo lists all sandboxed worktrees for the given project, writing to w.
+// ListTo lists all sandboxed worktrees for the given project with their
+// branch, age, and uncommitted-change status, writing to w.
 func ListTo(w io.Writer, projectPath string) error {
+	out := platform.Stdout()
 	if projectPath == "" {
 		fmt.Fprintln(os.Stderr, "Usage: claude-workspace sandbox list <project-path>")
-		fmt.Println("\nExamples:")
-		fmt.Println("  claude-workspace sandbox list ./my-project")
+		fmt.Fprintln(out, "\nExamples:")
+		fmt.Fprintln(out, "  claude-workspace sandbox list ./my-project")
 		os.Exit(1)
 	}
 
-	projectDir, err := filepath.Abs(projectPath)
+	projectDir, worktreeBase, err := resolveProject(projectPath)
 	if err != nil {
-		return fmt.Errorf("resolving path: %w", err)
-	}
-
-	if !platform.FileExists(projectDir) {
-		return fmt.Errorf("project directory not found: %s", projectDir)
-	}
-
-	if err := platform.RunQuietDir(projectDir, "git", "rev-parse", "--git-dir"); err != nil {
-		return fmt.Errorf("not a git repository: %s", projectDir)
-	}
-
-	// Resolve symlinks so paths match git worktree list output (macOS /var -> /private/var)
-	if resolved, err := filepath.EvalSymlinks(projectDir); err == nil {
-		projectDir = resolved
+		return err
 	}
 
-	projectName := filepath.Base(projectDir)
-	worktreeBase := filepath.Join(filepath.Dir(projectDir), projectName+"-worktrees")
-
-	platform.PrintBanner(w, fmt.Sprintf("Sandboxes: %s", projectName))
+	platform.PrintBanner(w, fmt.Sprintf("Sandboxes: %s", filepath.Base(projectDir)))
 	fmt.Fprintln(w)
 
-	out, err := platform.OutputDir(projectDir, "git", "worktree", "list")
+	sandboxes, err := listSandboxes(projectDir, worktreeBase)
 	if err != nil {
-		return fmt.Errorf("listing worktrees: %w", err)
-	}
-
-	// Filter to only worktrees under <project>-worktrees/
-	var sandboxes []string
-	for _, line := range strings.Split(out, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// git worktree list format: <path> <commit> [<branch>]
-		if strings.HasPrefix(line, worktreeBase+string(filepath.Separator)) {
-			sandboxes = append(sandboxes, line)
-		}
+		return err
 	}
 
 	if len(sandboxes) == 0 {
@@ -477,15 +476,17 @@ func ListTo(w io.Writer, projectPath string) error {
 		return nil
 	}
 
-	for _, line := range sandboxes {
-		fields := strings.Fields(line)
-		if len(fields) < 3 {
-			continue
+	for _, wt := range sandboxes {
+		branch := wt.Branch
+		if branch == "" {
+			branch = filepath.Base(wt.Dir) + " (detached)"
 		}
-		dir := fields[0]
-		branch := strings.Trim(fields[2], "[]")
 		fmt.Fprintf(w, "  %s\n", platform.Bold(branch))
-		fmt.Fprintf(w, "    Directory: %s\n", dir)
+		fmt.Fprintf(w, "    Directory: %s\n", wt.Dir)
+		if age, ok := sandboxAge(wt.Dir); ok {
+			fmt.Fprintf(w, "    Age:       %s\n", formatAge(age))
+		}
+		fmt.Fprintf(w, "    Status:    %s\n", describeChanges(wt.Dir))
 		fmt.Fprintln(w)
 	}
 
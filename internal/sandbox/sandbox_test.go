@@ -182,7 +182,7 @@ func TestCreate_RelativePath(t *testing.T) {
 }
 
 func TestRemove_NonexistentProject(t *testing.T) {
-	err := Remove("/nonexistent/project/path/xyz", "feature-branch")
+	err := Remove("/nonexistent/project/path/xyz", "feature-branch", RemoveOptions{})
 	if err == nil {
 		t.Fatal("Remove() expected error for nonexistent project")
 	}
@@ -193,7 +193,7 @@ func TestRemove_NonexistentProject(t *testing.T) {
 
 func TestRemove_NotGitRepo(t *testing.T) {
 	dir := t.TempDir()
-	err := Remove(dir, "feature-branch")
+	err := Remove(dir, "feature-branch", RemoveOptions{})
 	if err == nil {
 		t.Fatal("Remove() expected error for non-git directory")
 	}
@@ -209,7 +209,7 @@ func TestRemove_MissingSandbox(t *testing.T) {
 
 	initGitRepo(t, projectDir)
 
-	err := Remove(projectDir, "nonexistent-branch")
+	err := Remove(projectDir, "nonexistent-branch", RemoveOptions{})
 	if err == nil {
 		t.Fatal("Remove() expected error for missing sandbox")
 	}
@@ -296,7 +296,7 @@ func TestRemove_RemovesWorktree(t *testing.T) {
 	}
 
 	// Remove it
-	if err := Remove(projectDir, "remove-branch"); err != nil {
+	if err := Remove(projectDir, "remove-branch", RemoveOptions{}); err != nil {
 		t.Fatalf("Remove() error = %v", err)
 	}
 
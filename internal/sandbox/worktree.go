@@ -0,0 +1,387 @@
+package sandbox
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// worktree is one entry from "git worktree list --porcelain".
+type worktree struct {
+	Dir    string
+	Head   string
+	Branch string // short branch name; empty when detached
+}
+
+// parseWorktrees parses "git worktree list --porcelain" output.
+func parseWorktrees(out string) []worktree {
+	var list []worktree
+	var cur *worktree
+	for _, line := range strings.Split(out, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch key {
+		case "worktree":
+			list = append(list, worktree{Dir: value})
+			cur = &list[len(list)-1]
+		case "HEAD":
+			if cur != nil {
+				cur.Head = value
+			}
+		case "branch":
+			if cur != nil {
+				cur.Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		}
+	}
+	return list
+}
+
+// resolveProject validates projectPath as a git repository and returns its
+// absolute directory (symlinks resolved, so paths match git output) and the
+// <project>-worktrees directory that holds its sandboxes.
+func resolveProject(projectPath string) (projectDir, worktreeBase string, err error) {
+	projectDir, err = filepath.Abs(projectPath)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving path: %w", err)
+	}
+
+	if !platform.FileExists(projectDir) {
+		return "", "", fmt.Errorf("project directory not found: %s", projectDir)
+	}
+
+	if err := platform.RunQuietDir(projectDir, "git", "rev-parse", "--git-dir"); err != nil {
+		return "", "", fmt.Errorf("not a git repository: %s", projectDir)
+	}
+
+	// Resolve symlinks so paths match git worktree list output (macOS /var -> /private/var)
+	if resolved, err := filepath.EvalSymlinks(projectDir); err == nil {
+		projectDir = resolved
+	}
+
+	projectName := filepath.Base(projectDir)
+	worktreeBase = filepath.Join(filepath.Dir(projectDir), projectName+"-worktrees")
+	return projectDir, worktreeBase, nil
+}
+
+// listSandboxes returns the project's worktrees that live under worktreeBase.
+func listSandboxes(projectDir, worktreeBase string) ([]worktree, error) {
+	out, err := platform.OutputDir(projectDir, "git", "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("listing worktrees: %w", err)
+	}
+	var sandboxes []worktree
+	for _, wt := range parseWorktrees(out) {
+		if strings.HasPrefix(wt.Dir, worktreeBase+string(filepath.Separator)) {
+			sandboxes = append(sandboxes, wt)
+		}
+	}
+	return sandboxes, nil
+}
+
+// Sandbox summarizes a sandboxed worktree for callers that render their own
+// listing, such as the TUI dashboard.
+type Sandbox struct {
+	Branch  string // branch name, or "<dir> (detached)"
+	Dir     string
+	Age     string // e.g. "3d"; empty when unknown
+	Changes int    // uncommitted changes; -1 when git status fails
+}
+
+// Sandboxes returns the name of the project at projectPath and its sandboxes.
+func Sandboxes(projectPath string) (string, []Sandbox, error) {
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return "", nil, err
+	}
+	worktrees, err := listSandboxes(projectDir, worktreeBase)
+	if err != nil {
+		return "", nil, err
+	}
+	list := make([]Sandbox, 0, len(worktrees))
+	for _, wt := range worktrees {
+		sb := Sandbox{Branch: wt.Branch, Dir: wt.Dir, Changes: -1}
+		if sb.Branch == "" {
+			sb.Branch = filepath.Base(wt.Dir) + " (detached)"
+		}
+		if age, ok := sandboxAge(wt.Dir); ok {
+			sb.Age = formatAge(age)
+		}
+		if changes, err := changedFiles(wt.Dir); err == nil {
+			sb.Changes = len(changes)
+		}
+		list = append(list, sb)
+	}
+	return filepath.Base(projectDir), list, nil
+}
+
+// findSandbox returns the sandbox for branchName, matching either the branch
+// or the worktree directory name.
+func findSandbox(sandboxes []worktree, branchName string) *worktree {
+	for i, wt := range sandboxes {
+		if wt.Branch == branchName || filepath.Base(wt.Dir) == branchName {
+			return &sandboxes[i]
+		}
+	}
+	return nil
+}
+
+// changedFiles returns "git status --porcelain" lines for the worktree at dir.
+func changedFiles(dir string) ([]string, error) {
+	out, err := platform.OutputDir(dir, "git", "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// sandboxAge returns how long ago the worktree at dir was created, based on
+// its .git link file, which git writes once at "worktree add".
+func sandboxAge(dir string) (time.Duration, bool) {
+	info, err := os.Stat(filepath.Join(dir, ".git"))
+	if err != nil {
+		return 0, false
+	}
+	return time.Since(info.ModTime()), true
+}
+
+// formatAge renders a duration as a short age such as "3d" or "5h".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// describeChanges summarizes a worktree's uncommitted changes.
+func describeChanges(dir string) string {
+	changes, err := changedFiles(dir)
+	switch {
+	case err != nil:
+		return "unknown"
+	case len(changes) == 0:
+		return "clean"
+	default:
+		return platform.Yellow(fmt.Sprintf("dirty (%d uncommitted change(s))", len(changes)))
+	}
+}
+
+// mergedBranches returns the local branches merged into the project's HEAD.
+func mergedBranches(projectDir string) map[string]bool {
+	merged := make(map[string]bool)
+	out, err := platform.OutputDir(projectDir, "git", "branch", "--merged", "HEAD", "--format=%(refname:short)")
+	if err != nil {
+		return merged
+	}
+	for _, name := range strings.Split(out, "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			merged[name] = true
+		}
+	}
+	return merged
+}
+
+// goneBranches returns the local branches whose upstream branch was deleted.
+func goneBranches(projectDir string) map[string]bool {
+	gone := make(map[string]bool)
+	out, err := platform.OutputDir(projectDir, "git", "for-each-ref", "--format=%(refname:short) %(upstream:track)", "refs/heads")
+	if err != nil {
+		return gone
+	}
+	for _, line := range strings.Split(out, "\n") {
+		name, track, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if track == "[gone]" {
+			gone[name] = true
+		}
+	}
+	return gone
+}
+
+// branchHasCommits reports whether a branch has moved since it was created.
+// A fresh sandbox branch points at an ancestor of HEAD, so "merged" alone
+// would treat it as done; its reflog shows whether any work happened on it.
+func branchHasCommits(projectDir, branch string) bool {
+	out, err := platform.OutputDir(projectDir, "git", "reflog", "show", "--format=%H", "refs/heads/"+branch, "--")
+	if err != nil {
+		return false
+	}
+	return len(strings.Split(strings.TrimSpace(out), "\n")) > 1
+}
+
+// pruneReason returns why a sandbox can be pruned, or "" if it should be kept.
+func pruneReason(projectDir string, wt worktree, merged, gone map[string]bool) string {
+	switch {
+	case wt.Branch == "":
+		return ""
+	case gone[wt.Branch]:
+		return "upstream branch deleted"
+	case merged[wt.Branch] && branchHasCommits(projectDir, wt.Branch):
+		return "branch merged"
+	default:
+		return ""
+	}
+}
+
+// Prune removes sandboxes whose branches have been merged into the project's
+// current branch or whose upstream branch was deleted, then deletes merged
+// branches. Sandboxes with uncommitted changes are kept. With dryRun, it only
+// reports what would be removed.
+func Prune(projectPath string, dryRun bool) error {
+	if projectPath == "" {
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace sandbox prune <project-path> [--dry-run]")
+		os.Exit(1)
+	}
+
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+
+	out := platform.Stdout()
+	platform.PrintBanner(out, fmt.Sprintf("Pruning Sandboxes: %s", filepath.Base(projectDir)))
+	fmt.Fprintln(out)
+
+	// Drop references to worktrees whose directories were deleted by hand.
+	if !dryRun {
+		_ = platform.RunQuietDir(projectDir, "git", "worktree", "prune")
+	}
+
+	sandboxes, err := listSandboxes(projectDir, worktreeBase)
+	if err != nil {
+		return err
+	}
+
+	merged := mergedBranches(projectDir)
+	gone := goneBranches(projectDir)
+	pruned := 0
+	for _, wt := range sandboxes {
+		reason := pruneReason(projectDir, wt, merged, gone)
+		if reason == "" {
+			continue
+		}
+		if changes, err := changedFiles(wt.Dir); err != nil || len(changes) > 0 {
+			platform.PrintWarn(out, fmt.Sprintf("%s: %s, but has uncommitted changes. Kept.", wt.Branch, reason))
+			continue
+		}
+		if dryRun {
+			platform.PrintInfo(out, fmt.Sprintf("Would remove %s (%s)", wt.Branch, reason))
+			pruned++
+			continue
+		}
+		if err := platform.RunQuietDir(projectDir, "git", "worktree", "remove", wt.Dir); err != nil {
+			platform.PrintFail(out, fmt.Sprintf("%s: could not remove worktree: %v", wt.Branch, err))
+			continue
+		}
+		platform.PrintOK(out, fmt.Sprintf("Removed %s (%s)", wt.Branch, reason))
+		removeContainer(projectDir, filepath.Base(wt.Dir))
+		pruned++
+		deleteBranch(out, projectDir, wt.Branch, false)
+	}
+
+	if !dryRun {
+		removeEmptyDir(worktreeBase)
+	}
+
+	fmt.Fprintln(out)
+	switch {
+	case pruned == 0:
+		fmt.Fprintln(out, "  Nothing to prune.")
+	case dryRun:
+		fmt.Fprintf(out, "  %d sandbox(es) would be removed. Run without --dry-run to remove them.\n", pruned)
+	default:
+		fmt.Fprintf(out, "  Pruned %d sandbox(es).\n", pruned)
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// deleteBranch deletes a local branch, refusing unmerged branches unless force
+// is set. Failures are reported as warnings with the command to finish by hand.
+func deleteBranch(w io.Writer, projectDir, branch string, force bool) {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if err := platform.RunQuietDir(projectDir, "git", "branch", flag, branch); err != nil {
+		platform.PrintWarningLine(w, fmt.Sprintf("Kept branch %s (not fully merged). Delete it with: git -C %s branch -D %s", branch, projectDir, branch))
+		return
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Deleted branch %s", branch))
+}
+
+// Status prints details for one sandbox: directory, age, uncommitted changes,
+// and how its branch compares to its upstream and the project's branch.
+func Status(projectPath, branchName string) error {
+	return StatusTo(platform.Stdout(), projectPath, branchName)
+}
+
+// StatusTo writes sandbox status to w.
+func StatusTo(w io.Writer, projectPath, branchName string) error {
+	if projectPath == "" || branchName == "" {
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace sandbox status <project-path> <branch-name>")
+		os.Exit(1)
+	}
+
+	projectDir, worktreeBase, err := resolveProject(projectPath)
+	if err != nil {
+		return err
+	}
+	sandboxes, err := listSandboxes(projectDir, worktreeBase)
+	if err != nil {
+		return err
+	}
+	wt := findSandbox(sandboxes, branchName)
+	if wt == nil {
+		return fmt.Errorf("sandbox not found: %s (run: claude-workspace sandbox list %s)", branchName, projectPath)
+	}
+
+	platform.PrintBanner(w, fmt.Sprintf("Sandbox: %s", branchName))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Branch:    %s\n", wt.Branch)
+	fmt.Fprintf(w, "  Directory: %s\n", wt.Dir)
+	if age, ok := sandboxAge(wt.Dir); ok {
+		fmt.Fprintf(w, "  Age:       %s\n", formatAge(age))
+	}
+	if wt.Branch != "" {
+		if counts, err := platform.OutputDir(wt.Dir, "git", "rev-list", "--left-right", "--count", "@{upstream}...HEAD"); err == nil {
+			if behind, ahead, ok := strings.Cut(counts, "\t"); ok {
+				fmt.Fprintf(w, "  Upstream:  %s ahead, %s behind\n", ahead, behind)
+			}
+		} else {
+			fmt.Fprintln(w, "  Upstream:  none")
+		}
+		if mergedBranches(projectDir)[wt.Branch] && branchHasCommits(projectDir, wt.Branch) {
+			fmt.Fprintln(w, "  Merged:    yes (prune with: claude-workspace sandbox prune "+projectPath+")")
+		}
+	}
+
+	changes, err := changedFiles(wt.Dir)
+	if err != nil {
+		return fmt.Errorf("reading worktree status: %w", err)
+	}
+	platform.PrintSection(w, "Uncommitted Changes")
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, line := range changes {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
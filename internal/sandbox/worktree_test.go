@@ -0,0 +1,194 @@
+package sandbox
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	out := `worktree /src/app
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /src/app-worktrees/feature-auth
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature-auth
+
+worktree /src/app-worktrees/spike
+HEAD 3333333333333333333333333333333333333333
+detached
+`
+	got := parseWorktrees(out)
+	if len(got) != 3 {
+		t.Fatalf("got %d worktrees, want 3", len(got))
+	}
+	want := worktree{Dir: "/src/app-worktrees/feature-auth", Head: "2222222222222222222222222222222222222222", Branch: "feature-auth"}
+	if got[1] != want {
+		t.Errorf("got[1] = %+v, want %+v", got[1], want)
+	}
+	if got[2].Branch != "" {
+		t.Errorf("detached worktree branch = %q, want empty", got[2].Branch)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{5 * time.Minute, "5m"},
+		{3 * time.Hour, "3h"},
+		{50 * time.Hour, "2d"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func branchExists(t *testing.T, dir, branch string) bool {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "branch", "--list", branch).Output()
+	if err != nil {
+		t.Fatalf("git branch --list: %v", err)
+	}
+	return strings.TrimSpace(string(out)) != ""
+}
+
+func newProject(t *testing.T) (parent, projectDir string) {
+	t.Helper()
+	parent = t.TempDir()
+	projectDir = filepath.Join(parent, "myproject")
+	_ = os.MkdirAll(projectDir, 0755)
+	initGitRepo(t, projectDir)
+	return parent, projectDir
+}
+
+func TestListTo_ShowsDirtyStatus(t *testing.T) {
+	parent, projectDir := newProject(t)
+	if err := Create(projectDir, "dirty-branch"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	worktreeDir := filepath.Join(parent, "myproject-worktrees", "dirty-branch")
+	t.Cleanup(func() {
+		_ = exec.Command("git", "-C", projectDir, "worktree", "remove", "--force", worktreeDir).Run()
+	})
+	_ = os.WriteFile(filepath.Join(worktreeDir, "new.txt"), []byte("x"), 0644)
+
+	var buf bytes.Buffer
+	if err := ListTo(&buf, projectDir); err != nil {
+		t.Fatalf("ListTo() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "1 uncommitted change(s)") {
+		t.Errorf("ListTo() output missing dirty status, got %q", out)
+	}
+	if !strings.Contains(out, "Age:") {
+		t.Errorf("ListTo() output missing age, got %q", out)
+	}
+
+	project, list, err := Sandboxes(projectDir)
+	if err != nil {
+		t.Fatalf("Sandboxes() error = %v", err)
+	}
+	if project != "myproject" || len(list) != 1 || list[0].Branch != "dirty-branch" || list[0].Changes != 1 || list[0].Age == "" {
+		t.Errorf("Sandboxes() = %q, %+v", project, list)
+	}
+}
+
+func TestRemove_DeleteBranch(t *testing.T) {
+	_, projectDir := newProject(t)
+	if err := Create(projectDir, "done-branch"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := Remove(projectDir, "done-branch", RemoveOptions{DeleteBranch: true}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if branchExists(t, projectDir, "done-branch") {
+		t.Error("branch still exists after Remove with DeleteBranch")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	parent, projectDir := newProject(t)
+	worktrees := filepath.Join(parent, "myproject-worktrees")
+	t.Cleanup(func() {
+		for _, b := range []string{"merged-branch", "fresh-branch", "open-branch"} {
+			_ = exec.Command("git", "-C", projectDir, "worktree", "remove", "--force", filepath.Join(worktrees, b)).Run()
+		}
+	})
+
+	for _, b := range []string{"merged-branch", "fresh-branch", "open-branch"} {
+		if err := Create(projectDir, b); err != nil {
+			t.Fatalf("Create(%s) error = %v", b, err)
+		}
+	}
+	gitRun(t, filepath.Join(worktrees, "merged-branch"), "commit", "--allow-empty", "-m", "work")
+	gitRun(t, filepath.Join(worktrees, "open-branch"), "commit", "--allow-empty", "-m", "wip")
+	gitRun(t, projectDir, "merge", "--ff-only", "merged-branch")
+
+	if err := Prune(projectDir, true); err != nil {
+		t.Fatalf("Prune(dry run) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktrees, "merged-branch")); err != nil {
+		t.Fatal("dry run removed a sandbox")
+	}
+
+	if err := Prune(projectDir, false); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktrees, "merged-branch")); !os.IsNotExist(err) {
+		t.Error("merged sandbox was not pruned")
+	}
+	if branchExists(t, projectDir, "merged-branch") {
+		t.Error("merged branch was not deleted")
+	}
+	for _, kept := range []string{"fresh-branch", "open-branch"} {
+		if _, err := os.Stat(filepath.Join(worktrees, kept)); err != nil {
+			t.Errorf("%s was pruned: %v", kept, err)
+		}
+	}
+}
+
+func TestStatusTo(t *testing.T) {
+	parent, projectDir := newProject(t)
+	if err := Create(projectDir, "status-branch"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	worktreeDir := filepath.Join(parent, "myproject-worktrees", "status-branch")
+	t.Cleanup(func() {
+		_ = exec.Command("git", "-C", projectDir, "worktree", "remove", "--force", worktreeDir).Run()
+	})
+	_ = os.WriteFile(filepath.Join(worktreeDir, "notes.md"), []byte("x"), 0644)
+
+	var buf bytes.Buffer
+	if err := StatusTo(&buf, projectDir, "status-branch"); err != nil {
+		t.Fatalf("StatusTo() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Branch:    status-branch", "Upstream:  none", "notes.md"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("StatusTo() output missing %q, got %q", want, out)
+		}
+	}
+
+	if err := StatusTo(&buf, projectDir, "missing"); err == nil {
+		t.Error("expected error for missing sandbox")
+	}
+}
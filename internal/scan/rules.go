@@ -0,0 +1,80 @@
+package scan
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rule matches one kind of credential. When Pattern has a capture group,
+// the first group is the secret and the rest is context, so
+// "GITHUB_TOKEN=abc123" is reported (or redacted) as "abc123".
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Generic rules recognize a secret by its surroundings, such as a
+	// variable name, rather than by its format. The scanner reports them
+	// only when the value looks random; redaction applies them regardless.
+	Generic bool
+}
+
+// Rules are the built-in credential rules, in order: specific token formats
+// come before the generic ones so matches are labeled by their format.
+var Rules = []Rule{
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(?:-----END [A-Z ]*PRIVATE KEY-----|$)`)},
+	{Name: "anthropic-key", Pattern: regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]{20,}`)},
+	{Name: "openai-key", Pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{32,}`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}|\bgithub_pat_[A-Za-z0-9_]{40,}`)},
+	{Name: "gitlab-token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "aws-secret-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{Name: "stripe-key", Pattern: regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{20,}`)},
+	{Name: "sendgrid-key", Pattern: regexp.MustCompile(`\bSG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43}`)},
+	{Name: "square-token", Pattern: regexp.MustCompile(`\bsq0[a-z]{3}-[A-Za-z0-9_-]{22,}`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{Name: "authorization", Pattern: regexp.MustCompile(`(?i)\b(?:bearer|basic|token)\s+([A-Za-z0-9._~+/=-]{16,})`), Generic: true},
+	{Name: "url-password", Pattern: regexp.MustCompile(`://[^/\s:@]+:([^/\s@]+)@`), Generic: true},
+	{Name: "secret-assignment", Pattern: regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:API_?KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIALS?)[A-Z0-9_]*["']?\s*[=:]\s*["']?([^\s"'$<{\[][^\s"',]{7,})`), Generic: true},
+}
+
+// minEntropy is the Shannon entropy, in bits per character, above which a
+// generic match counts as a secret. Random tokens score about 4 and above;
+// words and repeated phrases like "password123" score below 3.5.
+const minEntropy = 3.5
+
+// codeReference matches values that name something rather than hold it, such
+// as "config.Password" or "os.Getenv(".
+var codeReference = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)+$|\(`)
+
+// looksRandom reports whether a generic match's value is likely a real
+// credential rather than a placeholder, variable, or code reference.
+func looksRandom(v string) bool {
+	if len(v) < 8 || codeReference.MatchString(v) {
+		return false
+	}
+	lower := strings.ToLower(v)
+	for _, placeholder := range []string{"your", "changeme", "xxx", "placeholder", "example", "redacted", "dummy", "<", "${"} {
+		if strings.Contains(lower, placeholder) {
+			return false
+		}
+	}
+	return entropy(v) >= minEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,290 @@
+// Package scan implements the "scan" command, a secret and credential scanner.
+// It matches known token formats and, for values found by context such as
+// "PASSWORD=...", an entropy check that tells real credentials from
+// placeholders. "scan [path]" checks a project; "scan --stdin-json" reads a
+// Claude Code PreToolUse event and blocks Write and Edit calls that would add
+// a secret.
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// ErrBlocked is returned by "scan --stdin-json" when the tool call would write
+// a secret. The process exits 2, which tells Claude Code to block the call and
+// show the message on stderr to the agent.
+var ErrBlocked = errors.New("write blocked: potential secret")
+
+// AllowMarker on a line suppresses findings on it, for test fixtures and
+// documentation that must contain a credential-shaped value.
+const AllowMarker = "scan:allow"
+
+// maxFileSize is the largest file scanned; larger files are generated or
+// data files.
+const maxFileSize = 1 << 20
+
+// skipDirs are never descended into when walking a directory that is not a
+// git repository.
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// lockFiles hold integrity hashes that look random but are not secrets.
+var lockFiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "bun.lock": true,
+	"go.sum": true, "Cargo.lock": true, "poetry.lock": true, "uv.lock": true,
+	"composer.lock": true, "Gemfile.lock": true,
+}
+
+// Finding is one potential secret.
+type Finding struct {
+	File   string `json:"file"` // slash-separated, relative to the scanned directory
+	Line   int    `json:"line"`
+	Rule   string `json:"rule"`
+	Secret string `json:"secret"` // masked
+}
+
+// Run implements "scan [path]" and "scan --stdin-json".
+func Run(args []string) error {
+	hook := false
+	f := cli.New("scan", "scan [path] | scan --stdin-json")
+	f.BoolVar(&hook, "--stdin-json", "Scan the tool input of a PreToolUse hook on stdin")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 1); err != nil {
+		return err
+	}
+	dir := "."
+	if len(positional) == 1 {
+		dir = positional[0]
+	}
+	if hook {
+		return runHook(os.Stdin, os.Stderr)
+	}
+
+	findings, files, err := Dir(dir)
+	if err != nil {
+		return err
+	}
+	out := platform.Stdout()
+	abs, _ := filepath.Abs(dir)
+	platform.PrintBanner(out, "Secret Scan: "+abs)
+	fmt.Fprintln(out)
+	if len(findings) == 0 {
+		platform.PrintOK(out, fmt.Sprintf("No secrets found in %d file(s)", files))
+		fmt.Fprintln(out)
+		return nil
+	}
+	affected := make(map[string]bool)
+	for _, f := range findings {
+		affected[f.File] = true
+		platform.PrintFail(out, fmt.Sprintf("%s:%d: %s: %s", f.File, f.Line, f.Rule, f.Secret))
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  Move these values to environment variables or a secret store, or add %q\n", AllowMarker)
+	fmt.Fprintln(out, "  to a line that is a deliberate example.")
+	fmt.Fprintln(out)
+	return fmt.Errorf("%d potential secret(s) found in %d file(s)", len(findings), len(affected))
+}
+
+// Content returns the findings in content, which is labeled file.
+func Content(file, content string) []Finding {
+	var findings []Finding
+	// A value can match several rules; it is reported once, under the first.
+	seen := make(map[int]bool)
+	for _, rule := range Rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			secret := content[start:end]
+			if seen[start] || (rule.Generic && !looksRandom(secret)) || allowed(content, start) {
+				continue
+			}
+			seen[start] = true
+			findings = append(findings, Finding{
+				File:   file,
+				Line:   strings.Count(content[:start], "\n") + 1,
+				Rule:   rule.Name,
+				Secret: mask(secret),
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// allowed reports whether the line containing offset i carries AllowMarker.
+func allowed(content string, i int) bool {
+	start := strings.LastIndexByte(content[:i], '\n') + 1
+	end := strings.IndexByte(content[i:], '\n')
+	if end < 0 {
+		end = len(content)
+	} else {
+		end += i
+	}
+	return strings.Contains(content[start:end], AllowMarker)
+}
+
+// mask keeps enough of a secret to find it without revealing it.
+func mask(s string) string {
+	if len(s) <= 8 {
+		return "****"
+	}
+	return s[:4] + "****"
+}
+
+// Dir scans the files of a project and returns the findings and the number of
+// files scanned. In a git repository, tracked and untracked files are
+// scanned and ignored files are not.
+func Dir(dir string) ([]Finding, int, error) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return nil, 0, fmt.Errorf("not a directory: %s", dir)
+	}
+	files, err := listFiles(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var findings []Finding
+	scanned := 0
+	for _, rel := range files {
+		if SkipFile(rel) {
+			continue
+		}
+		data, err := readText(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil || data == nil {
+			continue
+		}
+		scanned++
+		findings = append(findings, Content(rel, string(data))...)
+	}
+	return findings, scanned, nil
+}
+
+// listFiles returns the slash-separated paths of the files under dir.
+func listFiles(dir string) ([]string, error) {
+	if out, err := platform.OutputDir(dir, "git", "ls-files", "-z", "--cached", "--others", "--exclude-standard"); err == nil {
+		var files []string
+		for _, f := range strings.Split(out, "\x00") {
+			if f != "" {
+				files = append(files, f)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, err
+}
+
+// SkipFile reports whether a file is left unscanned: lock files, and
+// .example, .template, and .sample files, which hold placeholders on purpose.
+func SkipFile(path string) bool {
+	if lockFiles[filepath.Base(path)] {
+		return true
+	}
+	switch filepath.Ext(path) {
+	case ".example", ".template", ".sample":
+		return true
+	}
+	return false
+}
+
+// readText returns the content of a regular text file, or nil for binary and
+// oversized files.
+func readText(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// hookEvent is the part of a PreToolUse event the hook reads.
+type hookEvent struct {
+	ToolInput struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+		Content      string `json:"content"`
+		NewString    string `json:"new_string"`
+		NewSource    string `json:"new_source"`
+		Edits        []struct {
+			NewString string `json:"new_string"`
+		} `json:"edits"`
+	} `json:"tool_input"`
+}
+
+// runHook checks the content a Write, Edit, MultiEdit, or NotebookEdit call
+// would add. Input that is not an event is let through, so a malformed event
+// never blocks the agent.
+func runHook(stdin io.Reader, stderr io.Writer) error {
+	var event hookEvent
+	if err := json.NewDecoder(stdin).Decode(&event); err != nil {
+		return nil
+	}
+	in := event.ToolInput
+	path := in.FilePath
+	if path == "" {
+		path = in.NotebookPath
+	}
+	if SkipFile(path) {
+		return nil
+	}
+	parts := []string{in.Content, in.NewString, in.NewSource}
+	for _, e := range in.Edits {
+		parts = append(parts, e.NewString)
+	}
+
+	findings := Content(path, strings.Join(parts, "\n"))
+	if len(findings) == 0 {
+		return nil
+	}
+	f := findings[0]
+	fmt.Fprintf(stderr, "Blocked: potential secret (%s: %s) in content for %s.\n", f.Rule, f.Secret, path)
+	fmt.Fprintln(stderr, "Reference an environment variable or use a placeholder value instead.")
+	fmt.Fprintf(stderr, "If this is a deliberate example, add %q in a comment on the same line.\n", AllowMarker)
+	return ErrBlocked
+}
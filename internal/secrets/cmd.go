@@ -0,0 +1,198 @@
+package secrets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"golang.org/x/term"
+)
+
+// Run is the entry point for the secrets command.
+func Run(args []string) error {
+	if len(args) == 0 {
+		return list(os.Stdout)
+	}
+
+	switch args[0] {
+	case "list":
+		if _, err := cli.Operands("secrets list", "secrets list", args[1:], 0, 0); err != nil {
+			return err
+		}
+		return list(os.Stdout)
+	case "set":
+		args, err := cli.Operands("secrets set", "secrets set <NAME>", args[1:], 1, 1)
+		if err != nil {
+			return err
+		}
+		return set(args[0])
+	case "rm", "remove":
+		args, err := cli.Operands("secrets rm", "secrets rm <NAME>", args[1:], 1, 1)
+		if err != nil {
+			return err
+		}
+		return remove(args[0])
+	case "exec":
+		return execWithSecrets(args[1:])
+	default:
+		return fmt.Errorf("unknown secrets subcommand: %s\nAvailable: list, set, rm, exec", args[0])
+	}
+}
+
+func list(w io.Writer) error {
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+	names, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	platform.PrintBanner(w, "Secrets")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Backend: %s\n", store.Backend())
+	platform.PrintSection(w, "Stored Secrets")
+	if len(names) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// set stores a secret read with masked input, or from stdin when it is not a
+// terminal (for example: echo "$TOKEN" | claude-workspace secrets set NAME).
+func set(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+
+	var value string
+	if term.IsTerminal(int(syscall.Stdin)) {
+		value, err = platform.PromptSecret(fmt.Sprintf("Enter %s: ", name))
+	} else {
+		var line string
+		line, err = bufio.NewReader(os.Stdin).ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		value = strings.TrimSpace(line)
+	}
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("no value provided for %s", name)
+	}
+
+	if err := store.Set(name, value); err != nil {
+		return err
+	}
+	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Stored %s (%s).", name, store.Backend()))
+	return nil
+}
+
+func remove(name string) error {
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(name); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("no secret named %s", name)
+		}
+		return err
+	}
+	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Removed %s.", name))
+	return nil
+}
+
+// execWithSecrets runs "NAME... -- command [args...]" with each named secret
+// exported as an env var. MCP servers registered through claude-workspace use
+// this as their launch command so that keys never appear in ~/.claude.json.
+// The command's exit code is propagated.
+func execWithSecrets(args []string) error {
+	names, command, err := splitExecArgs(args)
+	if err != nil {
+		return err
+	}
+	store, err := Open()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		value, err := store.Get(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w (run: claude-workspace secrets set %s)", name, err, name)
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+	}
+
+	code, err := platform.RunSpawn(command[0], command[1:]...)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+	return nil
+}
+
+// splitExecArgs splits "NAME... -- command [args...]".
+func splitExecArgs(args []string) (names, command []string, err error) {
+	for i, arg := range args {
+		if arg == "--" {
+			names, command = args[:i], args[i+1:]
+			break
+		}
+	}
+	if len(command) == 0 {
+		return nil, nil, fmt.Errorf("usage: claude-workspace secrets exec <NAME>... -- <command> [args...]")
+	}
+	for _, name := range names {
+		if err := ValidateName(name); err != nil {
+			return nil, nil, err
+		}
+	}
+	return names, command, nil
+}
+
+// WrapCommand returns a launch command that runs command with the named
+// secrets injected as env vars.
+func WrapCommand(names, command []string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locating claude-workspace binary: %w", err)
+	}
+	wrapped := append([]string{exe, "secrets", "exec"}, names...)
+	wrapped = append(wrapped, "--")
+	return append(wrapped, command...), nil
+}
+
+// WrappedNames returns the secret names injected by a server whose args were
+// produced by WrapCommand, or nil if args are not wrapped.
+func WrappedNames(args []string) []string {
+	if len(args) < 2 || args[0] != "secrets" || args[1] != "exec" {
+		return nil
+	}
+	names, _, err := splitExecArgs(args[2:])
+	if err != nil {
+		return nil
+	}
+	return names
+}
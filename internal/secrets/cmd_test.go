@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitExecArgs(t *testing.T) {
+	names, command, err := splitExecArgs([]string{"A_KEY", "B_KEY", "--", "npx", "-y", "pkg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "A_KEY,B_KEY" || strings.Join(command, " ") != "npx -y pkg" {
+		t.Errorf("got names %v command %v", names, command)
+	}
+
+	for _, args := range [][]string{
+		{"A_KEY"},
+		{"A_KEY", "--"},
+		{"bad-name", "--", "npx"},
+	} {
+		if _, _, err := splitExecArgs(args); err == nil {
+			t.Errorf("splitExecArgs(%v) = nil error", args)
+		}
+	}
+}
+
+func TestWrapCommand_RoundTrip(t *testing.T) {
+	wrapped, err := WrapCommand([]string{"API_KEY"}, []string{"npx", "-y", "server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(wrapped[1:], " "); got != "secrets exec API_KEY -- npx -y server" {
+		t.Errorf("WrapCommand args = %q", got)
+	}
+	if names := WrappedNames(wrapped[1:]); strings.Join(names, ",") != "API_KEY" {
+		t.Errorf("WrappedNames = %v", names)
+	}
+	if names := WrappedNames([]string{"-y", "server"}); names != nil {
+		t.Errorf("WrappedNames(unwrapped) = %v, want nil", names)
+	}
+}
@@ -0,0 +1,150 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// fileStore keeps secrets in ~/.claude-workspace/secrets.enc, encrypted with
+// AES-256-GCM under a random key in secrets.key. Both files are mode 0600. This
+// keeps credentials out of ~/.claude.json and anything that copies it, but it
+// is only as strong as the permissions on the key file.
+type fileStore struct {
+	dir string
+}
+
+func (f *fileStore) Backend() string { return BackendFile }
+
+func (f *fileStore) dataPath() string { return filepath.Join(f.dir, "secrets.enc") }
+func (f *fileStore) keyPath() string  { return filepath.Join(f.dir, "secrets.key") }
+
+func (f *fileStore) Get(name string) (string, error) {
+	values, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *fileStore) Set(name, value string) error {
+	values, err := f.load()
+	if err != nil {
+		return err
+	}
+	values[name] = value
+	return f.save(values)
+}
+
+func (f *fileStore) Delete(name string) error {
+	values, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[name]; !ok {
+		return ErrNotFound
+	}
+	delete(values, name)
+	return f.save(values)
+}
+
+func (f *fileStore) List() ([]string, error) {
+	values, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// load decrypts the secrets file. A missing file yields an empty map.
+func (f *fileStore) load() (map[string]string, error) {
+	values := make(map[string]string)
+	sealed, err := os.ReadFile(f.dataPath())
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", f.dataPath(), err)
+	}
+
+	gcm, err := f.cipher(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is corrupt", f.dataPath())
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: wrong key or corrupt file", f.dataPath())
+	}
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", f.dataPath(), err)
+	}
+	return values, nil
+}
+
+// save encrypts values with a fresh nonce and writes the secrets file.
+func (f *fileStore) save(values map[string]string) error {
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return fmt.Errorf("creating %s: %w", f.dir, err)
+	}
+	gcm, err := f.cipher(true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	if err := os.WriteFile(f.dataPath(), sealed, 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", f.dataPath(), err)
+	}
+	return nil
+}
+
+// cipher returns an AES-GCM cipher using the key file, creating the key when
+// create is true and none exists yet.
+func (f *fileStore) cipher(create bool) (cipher.AEAD, error) {
+	key, err := os.ReadFile(f.keyPath())
+	if os.IsNotExist(err) && create {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, fmt.Errorf("generating key: %w", err)
+		}
+		if err := os.WriteFile(f.keyPath(), key, 0600); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", f.keyPath(), err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", f.keyPath(), err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s must contain a 32-byte key", f.keyPath())
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// runner executes a command with stdin and returns its trimmed stdout.
+type runner func(stdin, name string, args ...string) (string, error)
+
+func runWithStdin(stdin, name string, args ...string) (string, error) {
+	return platform.RunDirWithStdin(context.Background(), "", stdin, name, args...)
+}
+
+// keychainStore keeps secrets in the macOS login Keychain as generic passwords
+// via the security CLI.
+type keychainStore struct {
+	index string
+	run   runner
+}
+
+func (k *keychainStore) Backend() string { return BackendKeychain }
+
+func (k *keychainStore) Get(name string) (string, error) {
+	out, err := k.run("", "security", "find-generic-password", "-s", service, "-a", name, "-w")
+	if err != nil {
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+// Set writes the secret through "security -i" so the value is passed on stdin
+// rather than appearing in the process list.
+func (k *keychainStore) Set(name, value string) error {
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", QuoteSecurityArg(service), QuoteSecurityArg(name), QuoteSecurityArg(value))
+	if _, err := k.run(cmd, "security", "-i"); err != nil {
+		return fmt.Errorf("storing %s in the Keychain: %w", name, err)
+	}
+	return updateIndex(k.index, name, true)
+}
+
+func (k *keychainStore) Delete(name string) error {
+	if _, err := k.run("", "security", "delete-generic-password", "-s", service, "-a", name); err != nil {
+		return ErrNotFound
+	}
+	return updateIndex(k.index, name, false)
+}
+
+func (k *keychainStore) List() ([]string, error) {
+	return readIndex(k.index)
+}
+
+// QuoteSecurityArg double-quotes s for the command parser of "security -i",
+// which reads commands, and the secrets in them, from stdin rather than argv.
+func QuoteSecurityArg(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,156 @@
+// Package secrets stores MCP credentials outside of ~/.claude.json. Values are
+// kept in the macOS Keychain, the Linux Secret Service, or an encrypted file
+// under ~/.claude-workspace, and are injected into MCP server processes as env
+// vars at launch by "claude-workspace secrets exec".
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// EnvBackend overrides automatic backend selection when set to one of the
+// Backend* names.
+const EnvBackend = "CLAUDE_WORKSPACE_SECRETS_BACKEND"
+
+// Backend names.
+const (
+	BackendKeychain      = "keychain"
+	BackendSecretService = "secret-service"
+	BackendFile          = "file"
+)
+
+// service is the service/attribute name secrets are filed under in OS stores.
+const service = "claude-workspace"
+
+// ErrNotFound is returned by Get when no secret exists under the name.
+var ErrNotFound = errors.New("secret not found")
+
+// Store is a credential backend.
+type Store interface {
+	// Backend returns the backend name (one of the Backend* constants).
+	Backend() string
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	// List returns the stored secret names, sorted.
+	List() ([]string, error)
+}
+
+// Open returns the store selected by EnvBackend, or else the best backend
+// available on this machine: the Keychain on macOS, the Secret Service on Linux
+// when secret-tool is installed, and the encrypted file otherwise.
+func Open() (Store, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	backend := os.Getenv(EnvBackend)
+	if backend == "" {
+		backend = detectBackend(runtime.GOOS, platform.Exists)
+	}
+
+	switch backend {
+	case BackendKeychain:
+		return &keychainStore{index: indexPath(dir), run: runWithStdin}, nil
+	case BackendSecretService:
+		return &secretServiceStore{index: indexPath(dir), run: runWithStdin}, nil
+	case BackendFile:
+		return &fileStore{dir: dir}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q (valid: %s, %s, %s)", EnvBackend, backend, BackendKeychain, BackendSecretService, BackendFile)
+	}
+}
+
+// detectBackend picks a backend for goos given a lookup for installed binaries.
+func detectBackend(goos string, exists func(string) bool) string {
+	switch {
+	case goos == "darwin" && exists("security"):
+		return BackendKeychain
+	case goos == "linux" && exists("secret-tool"):
+		return BackendSecretService
+	default:
+		return BackendFile
+	}
+}
+
+// ValidateName reports whether name can be used as a secret (and env var) name.
+func ValidateName(name string) error {
+	// Secrets are consumed as environment variables.
+	if !platform.IsEnvVarName(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, and underscores (e.g. BRAVE_API_KEY)", name)
+	}
+	return nil
+}
+
+// StateFiles lists the files in ~/.claude-workspace that hold secrets or the
+// index of their names.
+var StateFiles = []string{"secrets.enc", "secrets.key", "secrets-index.json"}
+
+// stateDir returns ~/.claude-workspace.
+func stateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace"), nil
+}
+
+func indexPath(dir string) string {
+	return filepath.Join(dir, "secrets-index.json")
+}
+
+// The OS keychains cannot enumerate entries portably, so keychain-backed stores
+// keep the list of names (never values) in an index file.
+
+func readIndex(path string) ([]string, error) {
+	var names []string
+	if !platform.FileExists(path) {
+		return names, nil
+	}
+	if err := platform.ReadJSONFile(path, &names); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func updateIndex(path, name string, present bool) error {
+	names, err := readIndex(path)
+	if err != nil {
+		return err
+	}
+	set := make(map[string]bool, len(names)+1)
+	for _, n := range names {
+		set[n] = true
+	}
+	if set[name] == present {
+		return nil
+	}
+	if present {
+		set[name] = true
+	} else {
+		delete(set, name)
+	}
+	updated := make([]string, 0, len(set))
+	for n := range set {
+		updated = append(updated, n)
+	}
+	sort.Strings(updated)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,211 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectBackend(t *testing.T) {
+	has := func(names ...string) func(string) bool {
+		return func(n string) bool {
+			for _, name := range names {
+				if name == n {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	tests := []struct {
+		goos   string
+		exists func(string) bool
+		want   string
+	}{
+		{"darwin", has("security"), BackendKeychain},
+		{"darwin", has(), BackendFile},
+		{"linux", has("secret-tool"), BackendSecretService},
+		{"linux", has("security"), BackendFile},
+		{"windows", has("security", "secret-tool"), BackendFile},
+	}
+	for _, tt := range tests {
+		if got := detectBackend(tt.goos, tt.exists); got != tt.want {
+			t.Errorf("detectBackend(%q) = %q, want %q", tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestOpen_EnvOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	t.Setenv(EnvBackend, BackendFile)
+	store, err := Open()
+	if err != nil || store.Backend() != BackendFile {
+		t.Fatalf("Open() = %v, %v; want file backend", store, err)
+	}
+
+	t.Setenv(EnvBackend, "vault")
+	if _, err := Open(); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"BRAVE_API_KEY", "_x", "a1"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "1ABC", "MY-KEY", "A B", "A=B"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".claude-workspace")
+	store := &fileStore{dir: dir}
+
+	if _, err := store.Get("MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on empty store = %v, want ErrNotFound", err)
+	}
+	if err := store.Set("B_KEY", "two"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("A_KEY", "one"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := store.Get("A_KEY"); err != nil || got != "one" {
+		t.Errorf("Get(A_KEY) = %q, %v", got, err)
+	}
+	names, err := store.List()
+	if err != nil || strings.Join(names, ",") != "A_KEY,B_KEY" {
+		t.Errorf("List() = %v, %v", names, err)
+	}
+
+	sealed, err := os.ReadFile(store.dataPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "one") || strings.Contains(string(sealed), "A_KEY") {
+		t.Error("secrets file contains plaintext")
+	}
+	for _, p := range []string{store.dataPath(), store.keyPath()} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(p), perm)
+		}
+	}
+
+	if err := store.Delete("A_KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("A_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStore_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	store := &fileStore{dir: dir}
+	if err := store.Set("A", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.keyPath(), make([]byte, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("A"); err == nil || !strings.Contains(err.Error(), "decrypting") {
+		t.Errorf("Get with wrong key = %v, want decrypt error", err)
+	}
+}
+
+// fakeRunner records invocations and serves a canned keychain.
+type fakeRunner struct {
+	calls  []string
+	stdins []string
+	values map[string]string
+}
+
+func (f *fakeRunner) run(stdin, name string, args ...string) (string, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	f.stdins = append(f.stdins, stdin)
+	last := args[len(args)-1]
+	switch {
+	case len(args) > 0 && (args[0] == "find-generic-password" || args[0] == "lookup"):
+		key := args[len(args)-2]
+		if args[0] == "lookup" {
+			key = last
+		}
+		if v, ok := f.values[key]; ok {
+			return v, nil
+		}
+		return "", errors.New("exit status 44")
+	case len(args) > 0 && args[0] == "delete-generic-password":
+		if _, ok := f.values[last]; !ok {
+			return "", errors.New("exit status 44")
+		}
+	}
+	return "", nil
+}
+
+func TestKeychainStore(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "secrets-index.json")
+	fr := &fakeRunner{values: map[string]string{"TOKEN": "s3cret"}}
+	store := &keychainStore{index: index, run: fr.run}
+
+	if err := store.Set("TOKEN", `a"b\c`); err != nil {
+		t.Fatal(err)
+	}
+	if fr.calls[0] != "security -i" {
+		t.Errorf("Set ran %q, want security -i", fr.calls[0])
+	}
+	if strings.Contains(strings.Join(fr.calls, " "), "a\"b") {
+		t.Error("secret value leaked into command arguments")
+	}
+	want := `add-generic-password -U -s "claude-workspace" -a "TOKEN" -w "a\"b\\c"` + "\n"
+	if fr.stdins[0] != want {
+		t.Errorf("stdin = %q, want %q", fr.stdins[0], want)
+	}
+
+	if got, err := store.Get("TOKEN"); err != nil || got != "s3cret" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if _, err := store.Get("NOPE"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(NOPE) = %v, want ErrNotFound", err)
+	}
+	if names, _ := store.List(); strings.Join(names, ",") != "TOKEN" {
+		t.Errorf("List() = %v", names)
+	}
+	if err := store.Delete("TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := store.List(); len(names) != 0 {
+		t.Errorf("List() after Delete = %v", names)
+	}
+}
+
+func TestSecretServiceStore(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "secrets-index.json")
+	fr := &fakeRunner{values: map[string]string{"TOKEN": "s3cret"}}
+	store := &secretServiceStore{index: index, run: fr.run}
+
+	if err := store.Set("TOKEN", "new-value"); err != nil {
+		t.Fatal(err)
+	}
+	if fr.stdins[0] != "new-value" || strings.Contains(fr.calls[0], "new-value") {
+		t.Errorf("Set must pass the value on stdin only: call %q stdin %q", fr.calls[0], fr.stdins[0])
+	}
+	if got, err := store.Get("TOKEN"); err != nil || got != "s3cret" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if err := store.Delete("MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete(MISSING) = %v, want ErrNotFound", err)
+	}
+}
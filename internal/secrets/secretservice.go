@@ -0,0 +1,43 @@
+package secrets
+
+import "fmt"
+
+// secretServiceStore keeps secrets in the freedesktop Secret Service (GNOME
+// Keyring, KWallet) via the secret-tool CLI.
+type secretServiceStore struct {
+	index string
+	run   runner
+}
+
+func (s *secretServiceStore) Backend() string { return BackendSecretService }
+
+func (s *secretServiceStore) Get(name string) (string, error) {
+	out, err := s.run("", "secret-tool", "lookup", "service", service, "name", name)
+	if err != nil || out == "" {
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+// Set passes the value on stdin, which secret-tool reads when not on a terminal.
+func (s *secretServiceStore) Set(name, value string) error {
+	label := fmt.Sprintf("--label=%s: %s", service, name)
+	if _, err := s.run(value, "secret-tool", "store", label, "service", service, "name", name); err != nil {
+		return fmt.Errorf("storing %s in the Secret Service: %w", name, err)
+	}
+	return updateIndex(s.index, name, true)
+}
+
+func (s *secretServiceStore) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	if _, err := s.run("", "secret-tool", "clear", "service", service, "name", name); err != nil {
+		return fmt.Errorf("removing %s from the Secret Service: %w", name, err)
+	}
+	return updateIndex(s.index, name, false)
+}
+
+func (s *secretServiceStore) List() ([]string, error) {
+	return readIndex(s.index)
+}
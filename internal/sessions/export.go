@@ -0,0 +1,469 @@
+package sessions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Transcript is the full conversation of a session, as rendered by
+// "sessions export".
+type Transcript struct {
+	ID        string    `json:"id"`
+	Slug      string    `json:"slug,omitempty"`
+	Project   string    `json:"project"`
+	GitBranch string    `json:"gitBranch,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	Messages  []Message `json:"messages"`
+}
+
+// Message is one user or assistant turn.
+type Message struct {
+	Role      string    `json:"role"`
+	Model     string    `json:"model,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Blocks    []Block   `json:"blocks"`
+}
+
+// Block is a piece of message content: text, thinking, or a tool call. A tool
+// call carries its result, which Claude Code records in the following user
+// record.
+type Block struct {
+	Type    string          `json:"type"` // text, thinking, or tool_use
+	Text    string          `json:"text,omitempty"`
+	Tool    string          `json:"tool,omitempty"`
+	Input   json.RawMessage `json:"input,omitempty"`
+	Output  string          `json:"output,omitempty"`
+	IsError bool            `json:"isError,omitempty"`
+}
+
+// transcriptRecord is a session JSONL line with the assistant and tool fields
+// that the prompt-only record type omits.
+type transcriptRecord struct {
+	Type      string `json:"type"`
+	Slug      string `json:"slug"`
+	Timestamp string `json:"timestamp"`
+	CWD       string `json:"cwd"`
+	GitBranch string `json:"gitBranch"`
+	IsMeta    bool   `json:"isMeta"`
+	Message   struct {
+		ID      string          `json:"id"`
+		Role    string          `json:"role"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// contentBlock is an element of a message's content array.
+type contentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	Thinking  string          `json:"thinking"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// ParseTranscript reads the full conversation from a session file.
+// Consecutive assistant records with the same message ID (Claude Code writes
+// one record per content block) are merged into one Message.
+func ParseTranscript(path string) (*Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readTranscript(f, strings.TrimSuffix(filepath.Base(path), ".jsonl"))
+}
+
+// readTranscript reads the conversation of session id from session JSONL.
+func readTranscript(r io.Reader, id string) (*Transcript, error) {
+	t := &Transcript{ID: id}
+	type blockRef struct{ msg, block int }
+	toolCalls := make(map[string]blockRef)
+	lastAssistantID := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024) // tool results can be large
+	for scanner.Scan() {
+		var rec transcriptRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Slug != "" && t.Slug == "" {
+			t.Slug = rec.Slug
+		}
+		if rec.CWD != "" && t.Project == "" {
+			t.Project = rec.CWD
+		}
+		if rec.GitBranch != "" && t.GitBranch == "" {
+			t.GitBranch = rec.GitBranch
+		}
+		if (rec.Type != roleUser && rec.Type != "assistant") || rec.IsMeta {
+			continue
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, rec.Timestamp)
+		if t.StartTime.IsZero() {
+			t.StartTime = ts
+		}
+
+		if rec.Type == "assistant" {
+			blocks, calls := assistantBlocks(rec.Message.Content)
+			if len(blocks) == 0 {
+				continue
+			}
+			if rec.Message.ID == "" || rec.Message.ID != lastAssistantID || len(t.Messages) == 0 {
+				t.Messages = append(t.Messages, Message{Role: "assistant", Model: rec.Message.Model, Timestamp: ts})
+			}
+			lastAssistantID = rec.Message.ID
+			m := len(t.Messages) - 1
+			for i, b := range blocks {
+				if calls[i] != "" {
+					toolCalls[calls[i]] = blockRef{m, len(t.Messages[m].Blocks)}
+				}
+				t.Messages[m].Blocks = append(t.Messages[m].Blocks, b)
+			}
+			continue
+		}
+
+		lastAssistantID = ""
+		text, results := userContent(rec.Message.Content)
+		for _, r := range results {
+			if ref, ok := toolCalls[r.ToolUseID]; ok {
+				b := &t.Messages[ref.msg].Blocks[ref.block]
+				b.Output = toolResultText(r.Content)
+				b.IsError = r.IsError
+			}
+		}
+		if text != "" {
+			t.Messages = append(t.Messages, Message{
+				Role:      roleUser,
+				Timestamp: ts,
+				Blocks:    []Block{{Type: "text", Text: text}},
+			})
+		}
+	}
+	if t.Messages == nil {
+		t.Messages = []Message{}
+	}
+	return t, scanner.Err()
+}
+
+// assistantBlocks converts assistant content into Blocks. calls holds the
+// tool_use ID for each block that is a tool call.
+func assistantBlocks(raw json.RawMessage) (blocks []Block, calls []string) {
+	var content []contentBlock
+	if err := json.Unmarshal(raw, &content); err != nil {
+		if text := extractContent(raw); text != "" {
+			return []Block{{Type: "text", Text: text}}, []string{""}
+		}
+		return nil, nil
+	}
+	for _, c := range content {
+		switch c.Type {
+		case "text":
+			if strings.TrimSpace(c.Text) == "" {
+				continue
+			}
+			blocks = append(blocks, Block{Type: "text", Text: strings.TrimSpace(c.Text)})
+			calls = append(calls, "")
+		case "thinking":
+			if strings.TrimSpace(c.Thinking) == "" {
+				continue
+			}
+			blocks = append(blocks, Block{Type: "thinking", Text: strings.TrimSpace(c.Thinking)})
+			calls = append(calls, "")
+		case "tool_use":
+			blocks = append(blocks, Block{Type: "tool_use", Tool: c.Name, Input: c.Input})
+			calls = append(calls, c.ID)
+		}
+	}
+	return blocks, calls
+}
+
+// userContent splits user content into the typed prompt text and any tool
+// results it carries.
+func userContent(raw json.RawMessage) (string, []contentBlock) {
+	var content []contentBlock
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return extractContent(raw), nil
+	}
+	var results []contentBlock
+	for _, c := range content {
+		if c.Type == "tool_result" {
+			results = append(results, c)
+		}
+	}
+	return extractContent(raw), results
+}
+
+// toolResultText returns the text of a tool result, whose content is either a
+// string or an array of text and image blocks.
+func toolResultText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var parts []contentBlock
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var out []string
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			out = append(out, p.Text)
+		case "image":
+			out = append(out, "[image]")
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// title returns the transcript's display name.
+func (t *Transcript) title() string {
+	if t.Slug != "" {
+		return t.Slug
+	}
+	return t.ID
+}
+
+// export implements "sessions export <session-id> [--format md|json|html] [--output path]".
+func export(args []string) error {
+	idPrefix, format, output, err := parseExportArgs(args)
+	if err != nil {
+		return err
+	}
+
+	path, _, project, err := findSession(idPrefix)
+	if err != nil {
+		return err
+	}
+	t, err := ParseTranscript(path)
+	if err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+	if t.Project == "" {
+		t.Project = project
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := renderTranscript(w, t, format); err != nil {
+		return err
+	}
+	if output != "" {
+		platform.PrintOK(os.Stderr, fmt.Sprintf("Exported %d message(s) to %s", len(t.Messages), output))
+	}
+	return nil
+}
+
+// renderTranscript renders t as md, json, or html.
+func renderTranscript(w io.Writer, t *Transcript, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t)
+	case "html":
+		return WriteHTML(w, t)
+	default:
+		return WriteMarkdown(w, t)
+	}
+}
+
+// parseExportArgs parses the export arguments. Without --format, the format is
+// taken from the --output extension, defaulting to Markdown.
+func parseExportArgs(args []string) (idPrefix, format, output string, err error) {
+	f := cli.New("sessions export", "sessions export <session-id> [--format md|json|html] [--output path]")
+	f.StringVar(&format, "--format", "md|json|html", "Output format (default: md, or from --output extension)")
+	f.StringVar(&output, "--output", "path", "Write to a file instead of stdout")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return "", "", "", err
+	}
+	idPrefix = positional[0]
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".json":
+			format = "json"
+		case ".html", ".htm":
+			format = "html"
+		default:
+			format = "md"
+		}
+	}
+	if format != "md" && format != "json" && format != "html" {
+		return "", "", "", fmt.Errorf("--format must be md, json, or html")
+	}
+	return idPrefix, format, output, nil
+}
+
+// WriteMarkdown renders t as a Markdown document. Tool calls show their input
+// as JSON and their output in a collapsible block.
+func WriteMarkdown(w io.Writer, t *Transcript) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", t.title())
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", t.ID)
+	fmt.Fprintf(&b, "- **Project:** `%s`\n", t.Project)
+	if t.GitBranch != "" {
+		fmt.Fprintf(&b, "- **Branch:** `%s`\n", t.GitBranch)
+	}
+	if !t.StartTime.IsZero() {
+		fmt.Fprintf(&b, "- **Started:** %s\n", t.StartTime.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	for _, m := range t.Messages {
+		heading := "User"
+		if m.Role == "assistant" {
+			heading = "Assistant"
+		}
+		if !m.Timestamp.IsZero() {
+			heading += " · " + m.Timestamp.Local().Format("15:04:05")
+		}
+		fmt.Fprintf(&b, "\n---\n\n## %s\n", heading)
+		for _, blk := range m.Blocks {
+			b.WriteString("\n")
+			switch blk.Type {
+			case "thinking":
+				fmt.Fprintf(&b, "<details>\n<summary>Thinking</summary>\n\n%s\n\n</details>\n", blk.Text)
+			case "tool_use":
+				fmt.Fprintf(&b, "**Tool call:** `%s`\n\n", blk.Tool)
+				b.WriteString(codeBlock("json", prettyJSON(blk.Input)))
+				if blk.Output != "" {
+					summary := "Output"
+					if blk.IsError {
+						summary = "Error"
+					}
+					fmt.Fprintf(&b, "\n<details>\n<summary>%s</summary>\n\n%s\n</details>\n", summary, codeBlock("", blk.Output))
+				}
+			default:
+				b.WriteString(blk.Text + "\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// codeBlock fences s, using a fence longer than any backtick run inside it.
+func codeBlock(lang, s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(s, "\n") + "\n" + fence + "\n"
+}
+
+// prettyJSON indents raw JSON, returning it unchanged if it is invalid.
+func prettyJSON(raw json.RawMessage) string {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return string(raw)
+	}
+	return string(out)
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"pretty": prettyJSON,
+	"clock": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("15:04:05")
+	},
+	"date": func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 920px; margin: 2em auto; padding: 0 1em; color: #1f2328; }
+header dl { display: grid; grid-template-columns: max-content 1fr; gap: .2em 1em; color: #59636e; }
+header dd { margin: 0; font-family: ui-monospace, monospace; }
+.msg { border-top: 1px solid #d1d9e0; padding: .5em 0 1em; }
+.msg h2 { font-size: 1em; margin: .5em 0; }
+.user h2 { color: #0969da; }
+.assistant h2 { color: #8250df; }
+.msg h2 time { color: #59636e; font-weight: normal; margin-left: .5em; }
+.text { white-space: pre-wrap; }
+pre { background: #f6f8fa; padding: .75em; overflow-x: auto; border-radius: 6px; font-size: 13px; }
+details { margin: .5em 0; }
+summary { cursor: pointer; color: #59636e; }
+.tool { border-left: 3px solid #d1d9e0; padding-left: .75em; margin: .75em 0; }
+.error summary { color: #cf222e; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<dl>
+<dt>Session</dt><dd>{{.T.ID}}</dd>
+<dt>Project</dt><dd>{{.T.Project}}</dd>
+{{- if .T.GitBranch}}
+<dt>Branch</dt><dd>{{.T.GitBranch}}</dd>
+{{- end}}
+{{- if not .T.StartTime.IsZero}}
+<dt>Started</dt><dd>{{date .T.StartTime}}</dd>
+{{- end}}
+</dl>
+</header>
+{{range .T.Messages}}
+<section class="msg {{.Role}}">
+<h2>{{if eq .Role "assistant"}}Assistant{{else}}User{{end}}<time>{{clock .Timestamp}}</time></h2>
+{{- range .Blocks}}
+{{- if eq .Type "thinking"}}
+<details><summary>Thinking</summary><div class="text">{{.Text}}</div></details>
+{{- else if eq .Type "tool_use"}}
+<div class="tool">
+<strong>Tool call:</strong> <code>{{.Tool}}</code>
+<pre>{{pretty .Input}}</pre>
+{{- if .Output}}
+<details{{if .IsError}} class="error"{{end}}><summary>{{if .IsError}}Error{{else}}Output{{end}}</summary><pre>{{.Output}}</pre></details>
+{{- end}}
+</div>
+{{- else}}
+<div class="text">{{.Text}}</div>
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders t as a self-contained HTML page.
+func WriteHTML(w io.Writer, t *Transcript) error {
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		T     *Transcript
+	}{t.title(), t})
+}
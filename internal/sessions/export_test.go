@@ -0,0 +1,149 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// transcriptJSONL is a session with a prompt, a streamed assistant message
+// containing thinking and a tool call, the tool result, and a final answer.
+const transcriptJSONL = `{"type":"file-history-snapshot","messageId":"snap-1"}
+{"type":"user","slug":"fix-tests","cwd":"/home/me/app","gitBranch":"main","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"Run the tests"}}
+{"type":"user","isMeta":true,"timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"<meta>"}}
+{"type":"assistant","timestamp":"2026-03-01T10:00:02Z","message":{"id":"msg_1","role":"assistant","model":"claude-opus-4","content":[{"type":"thinking","thinking":"Use go test."}]}}
+{"type":"assistant","timestamp":"2026-03-01T10:00:03Z","message":{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"tu_1","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","timestamp":"2026-03-01T10:00:05Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu_1","content":"FAIL ` + "```" + `x` + "```" + `","is_error":true}]}}
+{"type":"assistant","timestamp":"2026-03-01T10:00:06Z","message":{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"One test fails."}]}}
+`
+
+func writeTranscript(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "abc12345-0000.jsonl")
+	if err := os.WriteFile(path, []byte(transcriptJSONL), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseTranscript(t *testing.T) {
+	tr, err := ParseTranscript(writeTranscript(t))
+	if err != nil {
+		t.Fatalf("ParseTranscript: %v", err)
+	}
+	if tr.ID != "abc12345-0000" || tr.Slug != "fix-tests" || tr.Project != "/home/me/app" || tr.GitBranch != "main" {
+		t.Errorf("metadata = %+v", tr)
+	}
+	if len(tr.Messages) != 3 {
+		t.Fatalf("got %d messages, want 3: %+v", len(tr.Messages), tr.Messages)
+	}
+
+	if tr.Messages[0].Role != "user" || tr.Messages[0].Blocks[0].Text != "Run the tests" {
+		t.Errorf("messages[0] = %+v", tr.Messages[0])
+	}
+
+	// Streamed records with the same message ID are merged.
+	m := tr.Messages[1]
+	if m.Role != "assistant" || m.Model != "claude-opus-4" || len(m.Blocks) != 2 {
+		t.Fatalf("messages[1] = %+v", m)
+	}
+	if m.Blocks[0].Type != "thinking" {
+		t.Errorf("blocks[0].Type = %q, want thinking", m.Blocks[0].Type)
+	}
+	call := m.Blocks[1]
+	if call.Type != "tool_use" || call.Tool != "Bash" || !strings.HasPrefix(call.Output, "FAIL") || !call.IsError {
+		t.Errorf("tool call = %+v", call)
+	}
+
+	if tr.Messages[2].Blocks[0].Text != "One test fails." {
+		t.Errorf("messages[2] = %+v", tr.Messages[2])
+	}
+}
+
+func TestToolResultText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`"plain"`, "plain"},
+		{`[{"type":"text","text":"a"},{"type":"image"},{"type":"text","text":"b"}]`, "a\n[image]\nb"},
+		{`42`, ""},
+	}
+	for _, tt := range tests {
+		if got := toolResultText(json.RawMessage(tt.raw)); got != tt.want {
+			t.Errorf("toolResultText(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	tr, _ := ParseTranscript(writeTranscript(t))
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, tr); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# fix-tests",
+		"- **Branch:** `main`",
+		"## User",
+		"## Assistant",
+		"<summary>Thinking</summary>",
+		"**Tool call:** `Bash`",
+		`"command": "go test ./..."`,
+		"<summary>Error</summary>",
+		"````\nFAIL ```x```\n````",
+		"One test fails.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteHTML_EscapesContent(t *testing.T) {
+	tr := &Transcript{ID: "x", Messages: []Message{
+		{Role: "user", Blocks: []Block{{Type: "text", Text: "<script>alert(1)</script>"}}},
+	}}
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, tr); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<script>alert") {
+		t.Error("message text should be HTML-escaped")
+	}
+	if !strings.Contains(out, "&lt;script&gt;") {
+		t.Errorf("escaped text not found:\n%s", out)
+	}
+}
+
+func TestParseExportArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantFormat string
+		wantErr    bool
+	}{
+		{[]string{"abc"}, "md", false},
+		{[]string{"abc", "--format", "json"}, "json", false},
+		{[]string{"abc", "--output", "out.html"}, "html", false},
+		{[]string{"abc", "--output", "out.json", "--format", "md"}, "md", false},
+		{[]string{}, "", true},
+		{[]string{"abc", "--format", "pdf"}, "", true},
+		{[]string{"abc", "--output"}, "", true},
+		{[]string{"abc", "def"}, "", true},
+	}
+	for _, tt := range tests {
+		_, format, _, err := parseExportArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseExportArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if format != tt.wantFormat {
+			t.Errorf("parseExportArgs(%v) format = %q, want %q", tt.args, format, tt.wantFormat)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package sessions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/scan"
+)
+
+// ParseRedactRules parses a rules file of custom patterns, listed under a
+// top-level "rules:" key as plain regular expressions or as mappings with a
+// "name" and "pattern":
+//
+//	rules:
+//	  - 'corp\.example\.com'
+//	  - name: employee-id
+//	    pattern: 'EMP-[0-9]{6}'
+//
+// Single-quote patterns so backslashes are kept as written.
+func ParseRedactRules(data []byte) ([]scan.Rule, error) {
+	entries, err := platform.ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	var rules []scan.Rule
+	for _, e := range entries {
+		if e.Key != "rules" {
+			return nil, fmt.Errorf("line %d: expected \"rules:\"", e.Line)
+		}
+		if e.List == nil {
+			return nil, fmt.Errorf("line %d: rules must be a list", e.Line)
+		}
+		for _, item := range e.List {
+			name, pattern := "", item.Value
+			for _, f := range item.Fields {
+				switch f.Key {
+				case "name":
+					name = f.Value
+				case "pattern":
+					pattern = f.Value
+				default:
+					return nil, fmt.Errorf("line %d: unknown rule field %q (valid: name, pattern)", f.Line, f.Key)
+				}
+			}
+			if pattern == "" {
+				return nil, fmt.Errorf("line %d: rule has no pattern", item.Line)
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", item.Line, err)
+			}
+			if name == "" {
+				name = fmt.Sprintf("custom-%d", len(rules)+1)
+			}
+			rules = append(rules, scan.Rule{Name: name, Pattern: re})
+		}
+	}
+	return rules, nil
+}
+
+// Redactor replaces each match of its rules with "[REDACTED:<rule>]" and
+// counts the matches of each. When a rule's pattern has a capture group, only
+// the first group is replaced, so "GITHUB_TOKEN=abc123" keeps the variable
+// name. Generic rules apply without the scanner's entropy check: a shared
+// copy should err toward removing too much.
+type Redactor struct {
+	rules  []scan.Rule
+	Counts map[string]int
+}
+
+// NewRedactor returns a Redactor with the scanner's built-in rules followed
+// by extra.
+func NewRedactor(extra []scan.Rule) *Redactor {
+	return &Redactor{
+		rules:  append(append([]scan.Rule{}, scan.Rules...), extra...),
+		Counts: make(map[string]int),
+	}
+}
+
+// String returns s with every match replaced.
+func (r *Redactor) String(s string) string {
+	for _, rule := range r.rules {
+		matches := rule.Pattern.FindAllStringSubmatchIndex(s, -1)
+		if matches == nil {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if start == end {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString("[REDACTED:" + rule.Name + "]")
+			last = end
+			r.Counts[rule.Name]++
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
+
+// Line redacts one session JSONL record. Every string value is redacted
+// after JSON decoding, so escapes such as "\n" do not hide a match. Records
+// without a match are returned unchanged; lines that are not JSON are
+// redacted as plain text.
+func (r *Redactor) Line(line []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return []byte(r.String(string(line)))
+	}
+	v, changed := r.value(v)
+	if !changed {
+		return line
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return []byte(r.String(string(line)))
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func (r *Redactor) value(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case string:
+		out := r.String(t)
+		return out, out != t
+	case map[string]interface{}:
+		changed := false
+		for k, child := range t {
+			if out, c := r.value(child); c {
+				t[k] = out
+				changed = true
+			}
+		}
+		return t, changed
+	case []interface{}:
+		changed := false
+		for i, child := range t {
+			if out, c := r.value(child); c {
+				t[i] = out
+				changed = true
+			}
+		}
+		return t, changed
+	}
+	return v, false
+}
+
+// Redact copies session JSONL from src to dst with every record redacted.
+func (r *Redactor) Redact(dst io.Writer, src io.Reader) error {
+	reader := bufio.NewReaderSize(src, 1024*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			trimmed := bytes.TrimRight(line, "\r\n")
+			if _, werr := dst.Write(append(r.Line(trimmed), '\n')); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// summary describes the redactions made, e.g. "3 secret(s): jwt 2, aws-access-key 1".
+func (r *Redactor) summary() string {
+	total := 0
+	names := make([]string, 0, len(r.Counts))
+	for name, n := range r.Counts {
+		total += n
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.Counts[names[i]] != r.Counts[names[j]] {
+			return r.Counts[names[i]] > r.Counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, r.Counts[name])
+	}
+	return fmt.Sprintf("%d secret(s): %s", total, strings.Join(parts, ", "))
+}
+
+// redact implements "sessions redact <session-id> [--rules file] [--format jsonl|md|json|html] [--output path]".
+func redact(args []string) error {
+	idPrefix, rulesFile, format, output, err := parseRedactArgs(args)
+	if err != nil {
+		return err
+	}
+
+	var extra []scan.Rule
+	if rulesFile != "" {
+		data, err := os.ReadFile(rulesFile)
+		if err != nil {
+			return fmt.Errorf("reading rules file: %w", err)
+		}
+		if extra, err = ParseRedactRules(data); err != nil {
+			return fmt.Errorf("parsing %s: %w", rulesFile, err)
+		}
+	}
+
+	path, id, project, err := findSession(idPrefix)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+	defer f.Close()
+
+	r := NewRedactor(extra)
+	var redacted bytes.Buffer
+	if err := r.Redact(&redacted, f); err != nil {
+		return fmt.Errorf("reading session: %w", err)
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		out, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", output, err)
+		}
+		defer out.Close()
+		w = out
+	}
+
+	if format == "jsonl" {
+		_, err = w.Write(redacted.Bytes())
+	} else {
+		var t *Transcript
+		if t, err = readTranscript(&redacted, id); err != nil {
+			return fmt.Errorf("reading session: %w", err)
+		}
+		if t.Project == "" {
+			t.Project = project
+		}
+		err = renderTranscript(w, t, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(r.Counts) == 0 {
+		platform.PrintInfo(os.Stderr, "No secrets found")
+	} else {
+		platform.PrintOK(os.Stderr, "Redacted "+r.summary())
+	}
+	if output != "" {
+		platform.PrintOK(os.Stderr, fmt.Sprintf("Wrote redacted copy to %s", output))
+	}
+	platform.PrintWarn(os.Stderr, "Redaction is pattern-based; review the copy before sharing it")
+	return nil
+}
+
+// parseRedactArgs parses the redact arguments. Without --format, the format
+// is taken from the --output extension, defaulting to JSONL.
+func parseRedactArgs(args []string) (idPrefix, rulesFile, format, output string, err error) {
+	f := cli.New("sessions redact", "sessions redact <session-id> [--rules <file>] [--format jsonl|md|json|html] [--output path]")
+	f.StringVar(&rulesFile, "--rules", "file", "Extra regex patterns to redact")
+	f.StringVar(&format, "--format", "jsonl|md|json|html", "Output format (default: jsonl, or from --output extension)")
+	f.StringVar(&output, "--output", "path", "Write to a file instead of stdout")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return "", "", "", "", err
+	}
+	idPrefix = positional[0]
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".md", ".markdown":
+			format = "md"
+		case ".json":
+			format = "json"
+		case ".html", ".htm":
+			format = "html"
+		default:
+			format = "jsonl"
+		}
+	}
+	if format != "jsonl" && format != "md" && format != "json" && format != "html" {
+		return "", "", "", "", fmt.Errorf("--format must be jsonl, md, json, or html")
+	}
+	return idPrefix, rulesFile, format, output, nil
+}
@@ -0,0 +1,58 @@
+package sessions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+// ResumeTarget resolves a session ID prefix to Claude Code's full session ID
+// and the directory the session ran in. Claude Code only finds a session to
+// resume from the project directory it was started in.
+func ResumeTarget(idPrefix string) (dir, id string, err error) {
+	path, id, project, err := findSession(idPrefix)
+	if err != nil {
+		return "", "", err
+	}
+	// The recorded cwd is exact; the decoded directory name is ambiguous for
+	// paths that contain "-".
+	dir = project
+	if meta, err := parseSessionMeta(path, id, ""); err == nil && meta.Project != "" {
+		dir = meta.Project
+	}
+	if !platform.FileExists(dir) {
+		return "", "", fmt.Errorf("project directory for session %s no longer exists: %s", id, dir)
+	}
+	return dir, id, nil
+}
+
+// resume runs "claude --resume <id>" in the session's project directory.
+func resume(idPrefix string) error {
+	dir, id, err := ResumeTarget(idPrefix)
+	if err != nil {
+		return err
+	}
+	if !platform.Exists("claude") {
+		return fmt.Errorf("claude not found in PATH\nInstall: %s", tools.ClaudeInstallCmd)
+	}
+	fmt.Fprintf(os.Stderr, "  Resuming %s in %s\n", id, dir)
+	return platform.RunDir(dir, "claude", "--resume", id)
+}
+
+// Delete removes a session's transcript and the directory Claude Code keeps
+// alongside it (subagent transcripts and large tool results), if present.
+func Delete(path string) error {
+	if !strings.HasSuffix(path, ".jsonl") {
+		return fmt.Errorf("not a session file: %s", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing session: %w", err)
+	}
+	if err := os.RemoveAll(strings.TrimSuffix(path, ".jsonl")); err != nil {
+		return fmt.Errorf("removing session data: %w", err)
+	}
+	return nil
+}
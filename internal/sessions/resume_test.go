@@ -0,0 +1,82 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupProjects creates ~/.claude/projects under a temporary HOME and returns
+// the projects directory.
+func setupProjects(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".claude", "projects")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFindSession_Ambiguous(t *testing.T) {
+	projects := setupProjects(t)
+	dir := filepath.Join(projects, "-tmp-app")
+	_ = os.MkdirAll(dir, 0755)
+	writeTestSession(t, dir, "abc111", nil)
+	writeTestSession(t, dir, "abc222", nil)
+	writeTestSession(t, dir, "abc", nil)
+
+	if _, _, _, err := findSession("abc1"); err != nil {
+		t.Errorf("unique prefix: %v", err)
+	}
+	if _, id, _, err := findSession("abc"); err != nil || id != "abc" {
+		t.Errorf("exact match: id=%q err=%v", id, err)
+	}
+
+	_ = os.Remove(filepath.Join(dir, "abc.jsonl"))
+	_, _, _, err := findSession("abc")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguous prefix error, got %v", err)
+	}
+	if _, _, _, err := findSession("zzz"); err == nil {
+		t.Error("expected error for unknown prefix")
+	}
+}
+
+func TestResumeTarget_UsesRecordedCWD(t *testing.T) {
+	projects := setupProjects(t)
+	// A project path containing "-" cannot be recovered from the directory name.
+	projectDir := filepath.Join(t.TempDir(), "my-app")
+	_ = os.MkdirAll(projectDir, 0755)
+	dir := filepath.Join(projects, encodeProjectPath(projectDir))
+	_ = os.MkdirAll(dir, 0755)
+	writeTestSession(t, dir, "d4e5f6-full-id", []record{
+		makeUserRecord("hello", "2026-03-01T10:00:00Z", projectDir, false),
+	})
+
+	gotDir, id, err := ResumeTarget("d4e5")
+	if err != nil {
+		t.Fatalf("ResumeTarget: %v", err)
+	}
+	if id != "d4e5f6-full-id" {
+		t.Errorf("id = %q, want full session ID", id)
+	}
+	if gotDir != projectDir {
+		t.Errorf("dir = %q, want %q", gotDir, projectDir)
+	}
+}
+
+func TestResumeTarget_MissingProjectDir(t *testing.T) {
+	projects := setupProjects(t)
+	dir := filepath.Join(projects, "-nonexistent-project")
+	_ = os.MkdirAll(dir, 0755)
+	writeTestSession(t, dir, "0a0b0c", []record{
+		makeUserRecord("hello", "2026-03-01T10:00:00Z", "/nonexistent/project", false),
+	})
+
+	if _, _, err := ResumeTarget("0a0b"); err == nil || !strings.Contains(err.Error(), "no longer exists") {
+		t.Errorf("expected missing directory error, got %v", err)
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
@@ -37,6 +39,7 @@ type record struct {
 // Session holds parsed metadata for one session.
 type Session struct {
 	ID        string // filename UUID
+	Path      string // session JSONL file
 	Slug      string // human-readable name
 	Project   string // decoded project path
 	StartTime time.Time
@@ -58,39 +61,68 @@ func Run(args []string) error {
 
 	switch args[0] {
 	case "list":
-		limit := 20
-		all := false
-		for i := 1; i < len(args); i++ {
-			switch args[i] {
-			case "--all":
-				all = true
-			case "--limit":
-				if i+1 < len(args) {
-					i++
-					n := 0
-					for _, c := range args[i] {
-						if c >= '0' && c <= '9' {
-							n = n*10 + int(c-'0')
-						}
-					}
-					if n > 0 {
-						limit = n
-					}
-				}
-			}
-		}
-		return list(limit, all)
+		return runList(args[1:])
 	case "show":
-		if len(args) < 2 {
-			return fmt.Errorf("usage: claude-workspace sessions show <session-id>")
+		return runShow(args[1:])
+	case "export":
+		return export(args[1:])
+	case "redact":
+		return redact(args[1:])
+	case "stats":
+		return stats(args[1:])
+	case "resume":
+		f := cli.New("sessions resume", "sessions resume <session-id>")
+		positional, err := f.Parse(args[1:])
+		if err != nil {
+			return err
 		}
-		return show(args[1])
+		if err := f.CheckArgs(positional, 1, 1); err != nil {
+			return err
+		}
+		return resume(positional[0])
 	default:
 		// Treat unknown arg as a session ID for show
-		return show(args[0])
+		return runShow(args)
 	}
 }
 
+// runList implements "sessions list [--all] [--limit N]".
+func runList(args []string) error {
+	limit := 20
+	all := false
+	f := cli.New("sessions list", "sessions list [--all] [--limit N]")
+	f.BoolVar(&all, "--all", "List sessions across all projects")
+	f.Func("--limit", "N", "Limit results (default: 20)", func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--limit must be a positive number, got %q", v)
+		}
+		limit = n
+		return nil
+	})
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	return list(limit, all)
+}
+
+// runShow implements "sessions show <session-id>".
+func runShow(args []string) error {
+	f := cli.New("sessions show", "sessions show <session-id>")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
+	}
+	return show(positional[0])
+}
+
 // ResolveProjectDirs returns the session directories to scan.
 func ResolveProjectDirs(projectsDir string, all bool) ([]string, error) {
 	if all {
@@ -145,7 +177,7 @@ func printSessionTable(w *os.File, sessions []Session, all bool) {
 		fmt.Fprintf(w, "  %-10s  %-12s  %s\n", shortID, date, title)
 	}
 
-	fmt.Fprintf(w, "\n  %d session(s) shown. Use 'sessions show <id>' to view prompts.\n\n", len(sessions))
+	fmt.Fprintf(w, "\n  %d session(s) shown. Use 'sessions show <id>' to view prompts, 'sessions resume <id>' to continue.\n\n", len(sessions))
 }
 
 // list displays sessions for the current project or all projects.
@@ -192,11 +224,102 @@ func list(limit int, all bool) error {
 	return nil
 }
 
+// RecentIDs returns the IDs of up to limit sessions across all projects, most
+// recently modified first. It reads only directory listings, so it is cheap
+// enough for shell completion.
+func RecentIDs(limit int) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	projectDirs, err := ResolveProjectDirs(filepath.Join(home, ".claude", "projects"), true)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		id      string
+		modTime time.Time
+	}
+	var found []entry
+	for _, dir := range projectDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasSuffix(name, ".jsonl") || e.IsDir() {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			found = append(found, entry{id: strings.TrimSuffix(name, ".jsonl"), modTime: info.ModTime()})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].modTime.After(found[j].modTime)
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	ids := make([]string, len(found))
+	for i, e := range found {
+		ids[i] = e.id
+	}
+	return ids, nil
+}
+
+// Find returns the session whose ID starts with idPrefix, in any project.
+func Find(idPrefix string) (Session, error) {
+	path, id, project, err := findSession(idPrefix)
+	if err != nil {
+		return Session{}, err
+	}
+	return parseSessionMeta(path, id, project)
+}
+
+// ForProject returns the sessions Claude Code recorded for the project at
+// projectDir, most recent first.
+func ForProject(projectDir string) ([]Session, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".claude", "projects", encodeProjectPath(projectDir))
+	if !platform.FileExists(dir) {
+		return nil, nil
+	}
+	found, err := ScanProjectSessions(dir, projectDir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].StartTime.After(found[j].StartTime)
+	})
+	return found, nil
+}
+
 // show displays all user prompts from a specific session.
 func show(idPrefix string) error {
+	path, id, project, err := findSession(idPrefix)
+	if err != nil {
+		return err
+	}
+	return showSession(path, id, project)
+}
+
+// findSession searches all project directories for a session file whose ID
+// starts with idPrefix, returning its path, full ID, and decoded project path.
+// A prefix that matches more than one session is an error unless one of them
+// is an exact match.
+func findSession(idPrefix string) (path, id, project string, err error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("cannot determine home directory: %w", err)
+		return "", "", "", fmt.Errorf("cannot determine home directory: %w", err)
 	}
 
 	projectsDir := filepath.Join(home, ".claude", "projects")
@@ -204,9 +327,10 @@ func show(idPrefix string) error {
 	// Search all project dirs for a matching session file
 	projectEntries, err := os.ReadDir(projectsDir)
 	if err != nil {
-		return fmt.Errorf("reading projects directory: %w", err)
+		return "", "", "", fmt.Errorf("reading projects directory: %w", err)
 	}
 
+	var matches []string
 	for _, pe := range projectEntries {
 		if !pe.IsDir() {
 			continue
@@ -221,14 +345,24 @@ func show(idPrefix string) error {
 			if !strings.HasSuffix(name, ".jsonl") || e.IsDir() {
 				continue
 			}
-			id := strings.TrimSuffix(name, ".jsonl")
-			if strings.HasPrefix(id, idPrefix) {
-				return showSession(filepath.Join(dir, name), id, DecodeProjectPath(pe.Name()))
+			sid := strings.TrimSuffix(name, ".jsonl")
+			if !strings.HasPrefix(sid, idPrefix) {
+				continue
+			}
+			if sid == idPrefix || path == "" {
+				path, id, project = filepath.Join(dir, name), sid, DecodeProjectPath(pe.Name())
 			}
+			matches = append(matches, sid)
 		}
 	}
 
-	return fmt.Errorf("no session found matching ID prefix %q", idPrefix)
+	switch {
+	case len(matches) == 0:
+		return "", "", "", fmt.Errorf("no session found matching ID prefix %q", idPrefix)
+	case len(matches) > 1 && id != idPrefix:
+		return "", "", "", fmt.Errorf("session ID prefix %q is ambiguous (matches %s); use more characters", idPrefix, strings.Join(matches, ", "))
+	}
+	return path, id, project, nil
 }
 
 // showSession reads and displays all user prompts from a session file.
@@ -301,6 +435,7 @@ func parseSessionMeta(path, id, project string) (Session, error) {
 
 	s := Session{
 		ID:      id,
+		Path:    path,
 		Project: project,
 	}
 
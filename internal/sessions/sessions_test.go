@@ -375,3 +375,36 @@ func TestScanProjectSessions_IgnoresSubdirectories(t *testing.T) {
 		t.Fatalf("got %d sessions, want 1", len(sessions))
 	}
 }
+
+func TestRecentIDs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	projects := filepath.Join(home, ".claude", "projects")
+	now := time.Now()
+	for i, s := range []struct{ project, id string }{
+		{"-src-api", "old-session"},
+		{"-src-web", "new-session"},
+		{"-src-api", "mid-session"},
+	} {
+		dir := filepath.Join(projects, s.project)
+		if err := os.MkdirAll(filepath.Join(dir, s.id), 0755); err != nil { // subagent dir, ignored
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, s.id+".jsonl")
+		if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mod := now.Add(time.Duration([]int{-3, 0, -1}[i]) * time.Hour)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids, err := RecentIDs(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "new-session" || ids[1] != "mid-session" {
+		t.Errorf("RecentIDs(2) = %v, want [new-session mid-session]", ids)
+	}
+}
@@ -0,0 +1,353 @@
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// interruptMarker starts the user message Claude Code records when the user
+// stops a response.
+const interruptMarker = "[Request interrupted by user"
+
+// rejectMarker starts the tool result Claude Code records when the user
+// declines a tool call.
+const rejectMarker = "The user doesn't want to proceed with this tool use"
+
+// editTools maps the tools that change files to the input field naming the file.
+var editTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// Stats aggregates the sessions of one project, or of all projects, as
+// reported by "sessions stats".
+type Stats struct {
+	Since         string       `json:"since"`
+	Projects      int          `json:"projects"`
+	Sessions      int          `json:"sessions"`
+	Prompts       int          `json:"prompts"`
+	AvgTurns      float64      `json:"avgTurns"`
+	PerDay        []DayCount   `json:"sessionsPerDay"`
+	ToolCalls     int          `json:"toolCalls"`
+	Tools         []NamedCount `json:"tools"`
+	ToolErrors    int          `json:"toolErrors"`
+	ErrorRate     float64      `json:"errorRate"`
+	Retries       int          `json:"retries"`
+	RetryRate     float64      `json:"retryRate"`
+	Interruptions int          `json:"interruptions"`
+	Rejections    int          `json:"rejectedToolCalls"`
+	Interrupted   int          `json:"interruptedSessions"`
+	EditedFiles   []NamedCount `json:"mostEditedFiles"`
+}
+
+// DayCount is the number of sessions started on a local date.
+type DayCount struct {
+	Date     string `json:"date"`
+	Sessions int    `json:"sessions"`
+}
+
+// NamedCount is a tool or file with how often it occurred.
+type NamedCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// statsAccumulator collects counts while transcripts are read.
+type statsAccumulator struct {
+	projects map[string]bool
+	days     map[string]int
+	tools    map[string]int
+	files    map[string]int
+	s        Stats
+}
+
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{
+		projects: make(map[string]bool),
+		days:     make(map[string]int),
+		tools:    make(map[string]int),
+		files:    make(map[string]int),
+	}
+}
+
+// add counts one session. A tool call that failed and is followed by another
+// call of the same tool counts as a retry; declined tool calls count as
+// rejections rather than errors.
+func (a *statsAccumulator) add(t *Transcript) {
+	a.s.Sessions++
+	a.projects[t.Project] = true
+	a.days[t.StartTime.Local().Format("2006-01-02")]++
+
+	interrupted := false
+	var lastFailed string
+	for _, m := range t.Messages {
+		for _, b := range m.Blocks {
+			switch {
+			case m.Role == roleUser && strings.HasPrefix(b.Text, interruptMarker):
+				a.s.Interruptions++
+				interrupted = true
+			case m.Role == roleUser:
+				a.s.Prompts++
+			case b.Type == "tool_use":
+				a.s.ToolCalls++
+				a.tools[b.Tool]++
+				if lastFailed != "" && lastFailed == b.Tool {
+					a.s.Retries++
+				}
+				lastFailed = ""
+				if field, ok := editTools[b.Tool]; ok {
+					if path := inputString(b.Input, field); path != "" {
+						a.files[path]++
+					}
+				}
+				switch {
+				case b.IsError && strings.HasPrefix(b.Output, rejectMarker):
+					a.s.Rejections++
+					interrupted = true
+				case b.IsError:
+					a.s.ToolErrors++
+					lastFailed = b.Tool
+				}
+			}
+		}
+	}
+	if interrupted {
+		a.s.Interrupted++
+	}
+}
+
+// result returns the totals, with the top entries of each ranking.
+func (a *statsAccumulator) result(top int) *Stats {
+	s := a.s
+	s.Projects = len(a.projects)
+	s.PerDay = []DayCount{}
+	for day, n := range a.days {
+		s.PerDay = append(s.PerDay, DayCount{Date: day, Sessions: n})
+	}
+	sort.Slice(s.PerDay, func(i, j int) bool { return s.PerDay[i].Date < s.PerDay[j].Date })
+	s.Tools = ranked(a.tools, top)
+	s.EditedFiles = ranked(a.files, top)
+	if s.Sessions > 0 {
+		s.AvgTurns = round2(float64(s.Prompts) / float64(s.Sessions))
+	}
+	if s.ToolCalls > 0 {
+		s.ErrorRate = round2(float64(s.ToolErrors) / float64(s.ToolCalls))
+	}
+	if s.ToolErrors > 0 {
+		s.RetryRate = round2(float64(s.Retries) / float64(s.ToolErrors))
+	}
+	return &s
+}
+
+// ranked returns the top entries of counts, most frequent first.
+func ranked(counts map[string]int, top int) []NamedCount {
+	out := make([]NamedCount, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, NamedCount{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
+
+func round2(f float64) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', 2, 64), 64)
+	return v
+}
+
+// inputString returns a string field of a tool call's input.
+func inputString(raw json.RawMessage, field string) string {
+	var input map[string]interface{}
+	if json.Unmarshal(raw, &input) != nil {
+		return ""
+	}
+	s, _ := input[field].(string)
+	return s
+}
+
+type statsOptions struct {
+	all  bool
+	days int
+	top  int
+	json bool
+}
+
+func parseStatsArgs(args []string) (statsOptions, error) {
+	opts := statsOptions{days: 30, top: 10}
+	f := cli.New("sessions stats", "sessions stats [--all] [--days N] [--top N] [--json]")
+	f.BoolVar(&opts.all, "--all", "Include all projects (default: current project)")
+	f.Func("--days", "N", "Sessions started in the last N days; 0 for all (default: 30)", countFlag(&opts.days, "--days"))
+	f.Func("--top", "N", "Length of the tool and file rankings (default: 10)", countFlag(&opts.top, "--top"))
+	f.BoolVar(&opts.json, "--json", "Print the stats as one JSON document")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	return opts, f.CheckArgs(positional, 0, 0)
+}
+
+// countFlag returns the handler of a flag (name) whose value, a non-negative
+// number, is stored in *p.
+func countFlag(p *int, name string) func(string) error {
+	return func(v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s must be a non-negative number, got %q", name, v)
+		}
+		*p = n
+		return nil
+	}
+}
+
+// stats prints usage metrics across the sessions of the current project, or
+// of all projects with --all, started in the last --days days (0 for all).
+func stats(args []string) error {
+	opts, err := parseStatsArgs(args)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	projectsDir := filepath.Join(home, ".claude", "projects")
+	if !platform.FileExists(projectsDir) {
+		return fmt.Errorf("no Claude Code session data found at %s", projectsDir)
+	}
+	dirs, err := ResolveProjectDirs(projectsDir, opts.all)
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+	if opts.days > 0 {
+		y, m, d := time.Now().AddDate(0, 0, -(opts.days - 1)).Date()
+		since = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+	s := collectStats(dirs, since, opts.top)
+
+	if opts.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	writeStats(platform.Stdout(), s, opts.all, home)
+	return nil
+}
+
+// collectStats reads every session file in dirs started at or after since.
+// Files that cannot be read are skipped.
+func collectStats(dirs []string, since time.Time, top int) *Stats {
+	acc := newStatsAccumulator()
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+				continue
+			}
+			// A session written before since cannot have started after it.
+			if info, err := e.Info(); err != nil || (!since.IsZero() && info.ModTime().Before(since)) {
+				continue
+			}
+			t, err := ParseTranscript(filepath.Join(dir, e.Name()))
+			if err != nil || len(t.Messages) == 0 || t.StartTime.Before(since) {
+				continue
+			}
+			if t.Project == "" {
+				t.Project = DecodeProjectPath(filepath.Base(dir))
+			}
+			acc.add(t)
+		}
+	}
+	s := acc.result(top)
+	if !since.IsZero() {
+		s.Since = since.Format("2006-01-02")
+	}
+	return s
+}
+
+// writeStats prints the stats as text.
+func writeStats(w io.Writer, s *Stats, all bool, home string) {
+	if all {
+		platform.PrintBanner(w, "Session Stats (all projects)")
+	} else {
+		platform.PrintBanner(w, "Session Stats")
+	}
+	period := "all time"
+	if s.Since != "" {
+		period = "since " + s.Since
+	}
+	fmt.Fprintf(w, "\n  %d session(s) in %d project(s), %s\n", s.Sessions, s.Projects, period)
+	if s.Sessions == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+
+	platform.PrintSection(w, "Activity")
+	fmt.Fprintf(w, "  %-22s %d\n", "Prompts", s.Prompts)
+	fmt.Fprintf(w, "  %-22s %.2f\n", "Avg turns per session", s.AvgTurns)
+	fmt.Fprintf(w, "  %-22s %d\n", "Tool calls", s.ToolCalls)
+	fmt.Fprintf(w, "  %-22s %d (%.0f%% of tool calls)\n", "Tool errors", s.ToolErrors, s.ErrorRate*100)
+	fmt.Fprintf(w, "  %-22s %d (%.0f%% of errors)\n", "Retried after error", s.Retries, s.RetryRate*100)
+	fmt.Fprintf(w, "  %-22s %d, plus %d rejected tool call(s), in %d session(s)\n", "Interruptions", s.Interruptions, s.Rejections, s.Interrupted)
+
+	platform.PrintSection(w, "Sessions per Day")
+	peak := 0
+	for _, d := range s.PerDay {
+		peak = max(peak, d.Sessions)
+	}
+	for _, d := range s.PerDay {
+		bar := strings.Repeat("#", (d.Sessions*30+peak-1)/peak)
+		fmt.Fprintf(w, "  %s  %4d  %s\n", d.Date, d.Sessions, bar)
+	}
+
+	if len(s.Tools) > 0 {
+		platform.PrintSection(w, "Tool Calls")
+		for _, t := range s.Tools {
+			fmt.Fprintf(w, "  %-30s %6d  %3.0f%%\n", t.Name, t.Count, float64(t.Count)*100/float64(s.ToolCalls))
+		}
+	}
+	if len(s.EditedFiles) > 0 {
+		platform.PrintSection(w, "Most Edited Files")
+		for _, f := range s.EditedFiles {
+			fmt.Fprintf(w, "  %6d  %s\n", f.Count, displayPath(f.Name, home))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// displayPath shortens an absolute path for display: relative to the current
+// directory when inside it, otherwise with the home directory as ~.
+func displayPath(path, home string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	if home != "" && strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
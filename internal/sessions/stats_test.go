@@ -0,0 +1,100 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statsJSONL is a session with an edit, a failed Bash call retried, an
+// interruption, and a declined Write.
+const statsJSONL = `{"type":"user","cwd":"/home/me/app","timestamp":"2026-03-02T09:00:00Z","message":{"role":"user","content":"Fix the build"}}
+{"type":"assistant","timestamp":"2026-03-02T09:00:01Z","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"/home/me/app/main.go"}}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:02Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"type":"assistant","timestamp":"2026-03-02T09:00:03Z","message":{"id":"m2","role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"go build"}}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:04Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"exit 1","is_error":true}]}}
+{"type":"assistant","timestamp":"2026-03-02T09:00:05Z","message":{"id":"m3","role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"go build ./..."}}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:06Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"ok"}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:07Z","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:08Z","message":{"role":"user","content":"Also update the README"}}
+{"type":"assistant","timestamp":"2026-03-02T09:00:09Z","message":{"id":"m4","role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Write","input":{"file_path":"/home/me/app/README.md"}}]}}
+{"type":"user","timestamp":"2026-03-02T09:00:10Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","content":"The user doesn't want to proceed with this tool use.","is_error":true}]}}
+`
+
+func TestStatsAccumulator(t *testing.T) {
+	a := newStatsAccumulator()
+	for _, jsonl := range []string{statsJSONL, transcriptJSONL} {
+		tr, err := readTranscript(strings.NewReader(jsonl), "s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.add(tr)
+	}
+	s := a.result(2)
+
+	if s.Sessions != 2 || s.Projects != 1 || s.Prompts != 3 || s.AvgTurns != 1.5 {
+		t.Errorf("sessions = %d, projects = %d, prompts = %d, avg turns = %v", s.Sessions, s.Projects, s.Prompts, s.AvgTurns)
+	}
+	if s.ToolCalls != 5 || s.ToolErrors != 2 || s.ErrorRate != 0.4 || s.Retries != 1 || s.RetryRate != 0.5 {
+		t.Errorf("tool calls = %d, errors = %d (%v), retries = %d (%v)", s.ToolCalls, s.ToolErrors, s.ErrorRate, s.Retries, s.RetryRate)
+	}
+	if s.Interruptions != 1 || s.Rejections != 1 || s.Interrupted != 1 {
+		t.Errorf("interruptions = %d, rejections = %d, interrupted sessions = %d", s.Interruptions, s.Rejections, s.Interrupted)
+	}
+	if len(s.Tools) != 2 || s.Tools[0] != (NamedCount{"Bash", 3}) || s.Tools[1] != (NamedCount{"Edit", 1}) {
+		t.Errorf("tools = %+v, want Bash first and ranked to 2", s.Tools)
+	}
+	if len(s.EditedFiles) != 2 || s.EditedFiles[0] != (NamedCount{"/home/me/app/README.md", 1}) {
+		t.Errorf("edited files = %+v", s.EditedFiles)
+	}
+	if len(s.PerDay) != 2 || s.PerDay[0].Sessions != 1 {
+		t.Errorf("per day = %+v", s.PerDay)
+	}
+}
+
+func TestCollectStats(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "-home-me-app")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "new.jsonl"), []byte(statsJSONL), 0644)
+	old := filepath.Join(dir, "old.jsonl")
+	os.WriteFile(old, []byte(transcriptJSONL), 0644)
+	os.WriteFile(filepath.Join(dir, "empty.jsonl"), []byte(`{"type":"summary"}`+"\n"), 0644)
+
+	if s := collectStats([]string{dir}, time.Time{}, 10); s.Sessions != 2 || s.Since != "" {
+		t.Errorf("all time: %d session(s), since %q", s.Sessions, s.Since)
+	}
+	since := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	os.Chtimes(old, since.Add(-time.Hour), since.Add(-time.Hour))
+	s := collectStats([]string{dir}, since, 10)
+	if s.Sessions != 1 || s.Since != "2026-03-02" {
+		t.Errorf("since 2026-03-02: %d session(s), since %q", s.Sessions, s.Since)
+	}
+
+	var buf bytes.Buffer
+	writeStats(&buf, s, false, "/home/me")
+	for _, want := range []string{"1 session(s) in 1 project(s), since 2026-03-02", "Tool errors            1 (25% of tool calls)", "~/app/main.go"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+	data, _ := json.Marshal(s)
+	if !strings.Contains(string(data), `"mostEditedFiles":[{"name":"/home/me/app/README.md","count":1}`) {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestParseStatsArgs(t *testing.T) {
+	opts, err := parseStatsArgs([]string{"--all", "--days", "7", "--top", "0", "--json"})
+	if err != nil || !opts.all || opts.days != 7 || opts.top != 0 || !opts.json {
+		t.Errorf("parseStatsArgs = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{"--days"}, {"--days", "x"}, {"--top", "-1"}, {"--since"}} {
+		if _, err := parseStatsArgs(args); err == nil {
+			t.Errorf("parseStatsArgs(%q): expected error", args)
+		}
+	}
+}
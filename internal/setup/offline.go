@@ -0,0 +1,159 @@
+package setup
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+// skippedStep is a part of setup that --offline left out, with what the user
+// can do to finish it.
+type skippedStep struct {
+	what string
+	hint string
+}
+
+// offlineReport collects the steps skipped by setup --offline so they can be
+// listed together at the end.
+type offlineReport struct {
+	skipped []skippedStep
+}
+
+func (r *offlineReport) skip(what, hint string) {
+	r.skipped = append(r.skipped, skippedStep{what: what, hint: hint})
+}
+
+// printTo lists the skipped steps. It prints nothing when none were skipped.
+func (r *offlineReport) printTo(w io.Writer) {
+	if len(r.skipped) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nSkipped (offline):")
+	for _, s := range r.skipped {
+		platform.PrintWarn(w, s.what)
+		if s.hint != "" {
+			fmt.Fprintf(w, "      %s\n", s.hint)
+		}
+	}
+	fmt.Fprintln(w, "\n  Re-run 'claude-workspace setup' with network access to complete these steps.")
+}
+
+// ensureClaudeCLIOfflineTo checks for the Claude Code CLI without running the
+// installer, installing claudeBinary when one is given.
+func ensureClaudeCLIOfflineTo(w io.Writer, claudeBinary string, report *offlineReport) error {
+	claudeTool := tools.Claude()
+	if claudeTool.IsInstalled() {
+		if DetectNpmClaude().Detected {
+			fmt.Fprintln(w, "  Keeping the npm-installed Claude Code; replacing it requires the network installer.")
+			report.skip("Claude Code migration from npm to the native binary", "Run 'claude-workspace upgrade --cli-only' when online.")
+		}
+		ver, _ := platform.Output("claude", "--version")
+		fmt.Fprintf(w, "  Claude Code CLI found: %s\n", ver)
+		return nil
+	}
+
+	if claudeBinary == "" {
+		fmt.Fprintln(w, "  Claude Code CLI not found.")
+		report.skip("Claude Code CLI install", "Pass --claude-binary <path> with a binary from your internal mirror, or run: "+tools.ClaudeInstallCmd)
+		return nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	return installClaudeFromFileTo(w, home, claudeBinary)
+}
+
+// installClaudeFromFileTo copies a local Claude Code binary to
+// ~/.local/bin/claude, where the official installer puts it, after checking
+// that it runs.
+func installClaudeFromFileTo(w io.Writer, home, src string) error {
+	ver, err := platform.Output(src, "--version")
+	if err != nil {
+		return fmt.Errorf("%s is not a working Claude Code binary: %w", src, err)
+	}
+
+	localBin := filepath.Join(home, ".local", "bin")
+	dest := filepath.Join(localBin, "claude")
+	if err := os.MkdirAll(localBin, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", localBin, err)
+	}
+	if err := platform.CopyFile(src, dest); err != nil {
+		return fmt.Errorf("installing Claude Code: %w", err)
+	}
+	if err := os.Chmod(dest, 0755); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+	os.Setenv("PATH", localBin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	fmt.Fprintf(w, "  Claude Code installed from %s: %s\n", src, strings.TrimSpace(ver))
+
+	rcPath, shellName := platform.DetectShellRC(home)
+	if modified, err := platform.AppendPathToRC(home, shellName, rcPath); err != nil {
+		platform.PrintWarningLine(w, fmt.Sprintf("could not update PATH in %s: %v", rcPath, err))
+		fmt.Fprintln(w, `  Add manually: export PATH="$HOME/.local/bin:$PATH"`)
+	} else if modified {
+		fmt.Fprintf(w, "  Added ~/.local/bin to PATH in %s\n", filepath.Base(rcPath))
+		fmt.Fprintf(w, "  Restart your shell or run: source %s\n", rcPath)
+	}
+	return nil
+}
+
+// ensureNodeOfflineTo reports whether Node.js is available without
+// downloading it.
+func ensureNodeOfflineTo(w io.Writer, report *offlineReport) {
+	nodeTool := tools.Node()
+	if nodeTool.IsInstalled() {
+		ver, _ := platform.Output("node", "--version")
+		fmt.Fprintf(w, "  Node.js found: %s\n", ver)
+		return
+	}
+	fmt.Fprintln(w, "  Node.js not found or below minimum version.")
+	report.skip("Node.js install", "Install Node.js from your internal package mirror; MCP servers need it.")
+}
+
+// npmBased reports whether an MCP server config is launched through npx or
+// npm, which download the server package on first run.
+func npmBased(server interface{}) bool {
+	cfg, _ := server.(map[string]interface{})
+	command, _ := cfg["command"].(string)
+	return command == "npx" || command == "npm"
+}
+
+// checkOptionalToolsOfflineTo reports which of optional are missing without
+// installing them.
+func checkOptionalToolsOfflineTo(w io.Writer, optional []tools.Tool, report *offlineReport) {
+	var found []string
+	for _, t := range optional {
+		if t.IsInstalled() {
+			found = append(found, t.Name)
+			continue
+		}
+		report.skip(fmt.Sprintf("Optional tool %s (%s)", t.Name, t.Purpose), "Install: "+t.InstallHint())
+	}
+	if len(found) > 0 {
+		platform.PrintSuccess(w, fmt.Sprintf("Found: %s", strings.Join(found, ", ")))
+	}
+}
+
+// skipPluginsOfflineTo reports the platform plugins that are not installed;
+// installing them needs the plugin marketplace.
+func skipPluginsOfflineTo(w io.Writer, report *offlineReport) {
+	installed := map[string]bool{}
+	if platform.Exists("claude") {
+		installed = installedPlugins()
+	}
+	for _, plugin := range platformPlugins {
+		if installed[plugin] {
+			fmt.Fprintf(w, "  %s already installed.\n", plugin)
+			continue
+		}
+		fmt.Fprintf(w, "  Skipping %s: plugins are installed from the marketplace.\n", plugin)
+		report.skip("Plugin "+plugin, fmt.Sprintf("Run: claude plugin install %s --scope user", plugin))
+	}
+}
@@ -0,0 +1,96 @@
+package setup
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOfflineReport(t *testing.T) {
+	var buf bytes.Buffer
+	(&offlineReport{}).printTo(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("empty report printed %q", buf.String())
+	}
+
+	r := &offlineReport{}
+	r.skip("Node.js install", "Install Node.js from your internal package mirror.")
+	r.skip("Plugin skill-creator", "")
+	r.printTo(&buf)
+	out := buf.String()
+	for _, want := range []string{"Skipped (offline):", "Node.js install", "internal package mirror", "Plugin skill-creator"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNpmBased(t *testing.T) {
+	for name, server := range platformMCPServers("/home/u") {
+		if !npmBased(server) {
+			t.Errorf("platform server %s should be npm-based", name)
+		}
+	}
+	local := map[string]interface{}{"command": "/usr/local/bin/engram", "args": []string{"mcp"}}
+	if npmBased(local) {
+		t.Error("a local binary server should not be npm-based")
+	}
+}
+
+func TestSetupUserMCPServersOffline(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	origConfig := claudeConfig
+	claudeConfig = filepath.Join(home, ".claude.json")
+	t.Cleanup(func() { claudeConfig = origConfig })
+
+	var buf bytes.Buffer
+	report := &offlineReport{}
+	if err := setupUserMCPServersTo(&buf, options{offline: true}, report); err != nil {
+		t.Fatalf("setupUserMCPServersTo(offline) error = %v", err)
+	}
+	if len(report.skipped) != 1 || !strings.Contains(report.skipped[0].what, "mcp-memory-libsql") {
+		t.Errorf("skipped = %+v, want mcp-memory-libsql", report.skipped)
+	}
+	if _, err := os.Stat(claudeConfig); !os.IsNotExist(err) {
+		t.Errorf("~/.claude.json should not be written when every server is skipped (stat err = %v)", err)
+	}
+}
+
+func TestInstallClaudeFromFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("PATH", os.Getenv("PATH"))
+
+	src := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(src, []byte("#!/bin/sh\necho '2.1.0 (Claude Code)'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := installClaudeFromFileTo(&buf, home, src); err != nil {
+		t.Fatalf("installClaudeFromFileTo() error = %v", err)
+	}
+	dest := filepath.Join(home, ".local", "bin", "claude")
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("claude not installed: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("installed claude is not executable: %v", info.Mode())
+	}
+	if !strings.Contains(buf.String(), "2.1.0 (Claude Code)") {
+		t.Errorf("output missing version:\n%s", buf.String())
+	}
+
+	broken := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(broken, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := installClaudeFromFileTo(&buf, home, broken); err == nil {
+		t.Error("installClaudeFromFileTo() should reject a binary that fails --version")
+	}
+}
@@ -0,0 +1,211 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+// options holds parsed flags for the setup command. With an answers file
+// (--config), the file supplies defaults and flags override them.
+type options struct {
+	force          bool
+	offline        bool     // --offline: make no network calls
+	claudeBinary   string   // --claude-binary: install Claude Code from this file
+	nonInteractive bool     // --non-interactive, implied by --config: never prompt
+	configPath     string   // --config: answers file
+	apiKeyEnv      string   // --api-key-env: variable holding the API key
+	noModifyRC     bool     // --no-modify-rc: leave shell RC files alone
+	tools          []string // --tools: optional tools to install; nil is all, empty is none
+	mcpServers     []string // --mcp-servers: platform MCP servers; nil is all, empty is none
+	orgPolicy      string   // --org-policy: signed organization policy URL or path
+	orgPolicyKey   string   // --org-policy-key: public key file verifying the org policy
+}
+
+// parseOptions parses setup command arguments, loading the answers file when
+// --config is given.
+func parseOptions(args []string) (options, error) {
+	var o options
+	f := cli.New("setup", "setup [--offline] [--claude-binary <path>] [--force] [--non-interactive] [--config <setup.yaml>] [options]")
+	f.BoolVar(&o.force, "--force", "Overwrite existing settings")
+	f.BoolVar(&o.offline, "--offline", "Make no network calls")
+	f.BoolVar(&o.nonInteractive, "--non-interactive", "Never prompt")
+	f.BoolVar(&o.noModifyRC, "--no-modify-rc", "Leave shell RC files alone")
+	f.StringVar(&o.claudeBinary, "--claude-binary", "path", "Install Claude Code from this file")
+	f.StringVar(&o.configPath, "--config", "setup.yaml", "Answers file (implies --non-interactive)")
+	f.StringVar(&o.apiKeyEnv, "--api-key-env", "VAR", "Variable holding the API key")
+	f.Func("--tools", "a,b|none", "Comma-separated optional tools to install, or none", func(v string) error {
+		o.tools = splitList(v)
+		return nil
+	})
+	f.Func("--mcp-servers", "a,b|none", "Comma-separated platform MCP servers to register, or none", func(v string) error {
+		o.mcpServers = splitList(v)
+		return nil
+	})
+	f.StringVar(&o.orgPolicy, "--org-policy", "url", "Signed organization policy")
+	f.StringVar(&o.orgPolicyKey, "--org-policy-key", "public-key.pem", "Public key verifying the organization policy")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return o, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return o, err
+	}
+
+	if o.configPath != "" {
+		answers, err := loadAnswers(o.configPath)
+		if err != nil {
+			return o, err
+		}
+		o = o.over(answers)
+		o.nonInteractive = true
+	}
+	return o, o.validate()
+}
+
+// over returns o with unset fields filled in from base.
+func (o options) over(base options) options {
+	o.force = o.force || base.force
+	o.offline = o.offline || base.offline
+	o.nonInteractive = o.nonInteractive || base.nonInteractive
+	o.noModifyRC = o.noModifyRC || base.noModifyRC
+	if o.claudeBinary == "" {
+		o.claudeBinary = base.claudeBinary
+	}
+	if o.apiKeyEnv == "" {
+		o.apiKeyEnv = base.apiKeyEnv
+	}
+	if o.tools == nil {
+		o.tools = base.tools
+	}
+	if o.mcpServers == nil {
+		o.mcpServers = base.mcpServers
+	}
+	if o.orgPolicy == "" {
+		o.orgPolicy = base.orgPolicy
+	}
+	if o.orgPolicyKey == "" {
+		o.orgPolicyKey = base.orgPolicyKey
+	}
+	return o
+}
+
+// validate rejects unknown tool and MCP server names and malformed variable
+// names, so a typo in an answers file fails before anything is changed.
+func (o options) validate() error {
+	if o.apiKeyEnv != "" && !platform.IsEnvVarName(o.apiKeyEnv) {
+		return fmt.Errorf("invalid API key variable name %q", o.apiKeyEnv)
+	}
+	if o.orgPolicyKey != "" && o.orgPolicy == "" {
+		return fmt.Errorf("--org-policy-key requires --org-policy")
+	}
+	var toolNames []string
+	for _, t := range tools.Optional() {
+		toolNames = append(toolNames, t.Name)
+	}
+	if err := checkNames("tool", o.tools, toolNames); err != nil {
+		return err
+	}
+	var serverNames []string
+	for name := range platformMCPServers("") {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
+	return checkNames("MCP server", o.mcpServers, serverNames)
+}
+
+func checkNames(kind string, names, valid []string) error {
+	for _, name := range names {
+		if !slices.Contains(valid, name) {
+			return fmt.Errorf("unknown %s %q (valid: %s)", kind, name, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// splitList parses a comma-separated flag value; "none" is the empty list.
+func splitList(value string) []string {
+	items := []string{}
+	if value == "none" {
+		return items
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadAnswers reads a setup answers file. It accepts a small YAML subset:
+// "key: value" scalars and lists written as "[a, b]" or as "  - item" lines:
+//
+//	apiKeyEnv: ANTHROPIC_API_KEY
+//	tools: [jq, shellcheck]
+//	mcpServers: []
+//	modifyRC: false
+//	orgPolicy: https://policy.example.com/claude.json
+func loadAnswers(path string) (options, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return options{}, fmt.Errorf("reading answers file: %w", err)
+	}
+	o, err := parseAnswers(string(data))
+	if err != nil {
+		return options{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return o, nil
+}
+
+func parseAnswers(data string) (options, error) {
+	var o options
+	entries, err := platform.ParseYAML([]byte(data))
+	if err != nil {
+		return o, err
+	}
+	for _, e := range entries {
+		switch e.Key {
+		case "apiKeyEnv":
+			o.apiKeyEnv = e.Value
+		case "claudeBinary":
+			o.claudeBinary = e.Value
+		case "orgPolicy":
+			o.orgPolicy = e.Value
+		case "orgPolicyKey":
+			o.orgPolicyKey = e.Value
+		case "tools", "mcpServers":
+			names, err := e.Values()
+			if err != nil {
+				return o, err
+			}
+			if e.Key == "tools" {
+				o.tools = names
+			} else {
+				o.mcpServers = names
+			}
+		case "modifyRC", "offline", "force":
+			b, err := strconv.ParseBool(e.Value)
+			if err != nil {
+				return o, fmt.Errorf("line %d: %s must be true or false", e.Line, e.Key)
+			}
+			switch e.Key {
+			case "modifyRC":
+				o.noModifyRC = !b
+			case "offline":
+				o.offline = b
+			case "force":
+				o.force = b
+			}
+		default:
+			return o, fmt.Errorf("line %d: unknown key %q (valid: apiKeyEnv, tools, mcpServers, modifyRC, offline, claudeBinary, force, orgPolicy, orgPolicyKey)", e.Line, e.Key)
+		}
+	}
+	return o, nil
+}
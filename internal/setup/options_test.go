@@ -0,0 +1,170 @@
+package setup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/tools"
+)
+
+func TestParseOptions(t *testing.T) {
+	got, err := parseOptions([]string{"--offline", "--claude-binary", "/mnt/usb/claude", "--force", "--tools", "jq,shellcheck", "--mcp-servers", "none"})
+	if err != nil {
+		t.Fatalf("parseOptions() error = %v", err)
+	}
+	want := options{force: true, offline: true, claudeBinary: "/mnt/usb/claude", tools: []string{"jq", "shellcheck"}, mcpServers: []string{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseOptions() = %+v, want %+v", got, want)
+	}
+
+	for _, args := range [][]string{
+		{"--claude-binary"},
+		{"--tools", "jq,nano"},
+		{"--mcp-servers", "github"},
+		{"--api-key-env", "MY-KEY"},
+		{"--org-policy-key", "org.pem"},
+		{"--ofline"},
+	} {
+		if _, err := parseOptions(args); err == nil {
+			t.Errorf("parseOptions(%v) should fail", args)
+		}
+	}
+}
+
+func TestParseOptions_AnswersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "setup.yaml")
+	answers := `# fleet defaults
+apiKeyEnv: CORP_ANTHROPIC_KEY
+tools:
+  - jq
+  - shellcheck
+mcpServers: []
+modifyRC: false
+`
+	if err := os.WriteFile(path, []byte(answers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseOptions([]string{"--config", path, "--tools", "tmux"})
+	if err != nil {
+		t.Fatalf("parseOptions(--config) error = %v", err)
+	}
+	want := options{
+		nonInteractive: true,
+		configPath:     path,
+		apiKeyEnv:      "CORP_ANTHROPIC_KEY",
+		noModifyRC:     true,
+		tools:          []string{"tmux"}, // the flag overrides the file
+		mcpServers:     []string{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseOptions(--config) = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseOptions([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Error("parseOptions() with a missing answers file should fail")
+	}
+}
+
+func TestParseAnswers_Comments(t *testing.T) {
+	got, err := parseAnswers(`orgPolicy: "https://policy.example.com/claude.json#v2" # pinned
+claudeBinary: '/opt/claude #1/claude'
+tools: [jq] # just jq
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := options{
+		orgPolicy:    "https://policy.example.com/claude.json#v2",
+		claudeBinary: "/opt/claude #1/claude",
+		tools:        []string{"jq"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAnswers() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseAnswers_Errors(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":   "apiKey: sk-123\n",
+		"duplicate key": "offline: true\noffline: false\n",
+		"bad bool":      "modifyRC: sometimes\n",
+		"scalar list":   "tools: jq\n",
+		"orphan item":   "- jq\n",
+		"missing colon": "offline\n",
+	}
+	for name, data := range cases {
+		if _, err := parseAnswers(data); err == nil {
+			t.Errorf("%s: parseAnswers(%q) should fail", name, data)
+		}
+	}
+}
+
+func TestUseAPIKeyEnv(t *testing.T) {
+	origHome := claudeHome
+	claudeHome = t.TempDir()
+	t.Cleanup(func() { claudeHome = origHome })
+	t.Setenv("CORP_ANTHROPIC_KEY", "sk-test")
+
+	var buf strings.Builder
+	if err := useAPIKeyEnvTo(&buf, "CORP_ANTHROPIC_KEY"); err != nil {
+		t.Fatalf("useAPIKeyEnvTo() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(claudeHome, "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings["apiKeyHelper"] != "printenv CORP_ANTHROPIC_KEY" {
+		t.Errorf("apiKeyHelper = %v, want printenv CORP_ANTHROPIC_KEY", settings["apiKeyHelper"])
+	}
+	if _, ok := settings["permissions"]; !ok {
+		t.Error("new settings.json should start from the platform defaults")
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	buf.Reset()
+	if err := useAPIKeyEnvTo(&buf, "ANTHROPIC_API_KEY"); err != nil {
+		t.Fatalf("useAPIKeyEnvTo(ANTHROPIC_API_KEY) error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "not set") {
+		t.Errorf("expected a warning for an unset variable, got:\n%s", buf.String())
+	}
+}
+
+func TestSelectTools(t *testing.T) {
+	all := []tools.Tool{{Name: "jq"}, {Name: "tmux"}}
+	if got := selectTools(all, nil); len(got) != 2 {
+		t.Errorf("selectTools(nil) = %v, want all", got)
+	}
+	if got := selectTools(all, []string{}); len(got) != 0 {
+		t.Errorf("selectTools(none) = %v, want empty", got)
+	}
+	got := selectTools(all, []string{"jq"})
+	if len(got) != 1 || got[0].Name != "jq" {
+		t.Errorf("selectTools(jq) = %v", got)
+	}
+}
+
+func TestSetupUserMCPServers_NoneSelected(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	origConfig := claudeConfig
+	claudeConfig = filepath.Join(home, ".claude.json")
+	t.Cleanup(func() { claudeConfig = origConfig })
+
+	var buf strings.Builder
+	if err := setupUserMCPServersTo(&buf, options{mcpServers: []string{}}, &offlineReport{}); err != nil {
+		t.Fatalf("setupUserMCPServersTo() error = %v", err)
+	}
+	if _, err := os.Stat(claudeConfig); !os.IsNotExist(err) {
+		t.Errorf("~/.claude.json should not be written when no servers are selected (stat err = %v)", err)
+	}
+}
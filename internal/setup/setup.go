@@ -5,14 +5,20 @@
 package setup
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
 	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/completion"
+	"github.com/lamchakchan/claude-workspace/internal/orgpolicy"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 	"github.com/lamchakchan/claude-workspace/internal/statusline"
 	"github.com/lamchakchan/claude-workspace/internal/tools"
@@ -33,44 +39,72 @@ func init() {
 var knownMemoryProviders = []string{"mcp-memory-libsql", "engram", "memory"}
 
 // Run executes the setup command, performing first-time platform configuration.
-// Pass --force in args to overwrite existing settings.
+// Pass --force in args to overwrite existing settings, and --offline to make
+// no network calls.
 func Run(args []string) error {
-	force := false
-	for _, a := range args {
-		if a == "--force" {
-			force = true
-		}
+	opts, err := parseOptions(args)
+	if err != nil {
+		return err
 	}
-	return runTo(os.Stdout, force, true)
+	return runTo(platform.Stdout(), opts, !opts.nonInteractive)
 }
 
 // RunTo is like Run but writes all output to w instead of os.Stdout and skips
 // interactive steps (e.g., API key provisioning that requires stdin).
 func RunTo(w io.Writer, args []string) error {
-	force := false
-	for _, a := range args {
-		if a == "--force" {
-			force = true
-		}
+	opts, err := parseOptions(args)
+	if err != nil {
+		return err
 	}
-	return runTo(w, force, false)
+	return runTo(w, opts, false)
 }
 
-func runTo(w io.Writer, force, interactive bool) error {
+func runTo(w io.Writer, opts options, interactive bool) error {
 	platform.PrintBanner(w, "Claude Code Platform Setup")
+	report := &offlineReport{}
+	if opts.configPath != "" {
+		fmt.Fprintf(w, "\n  Using answers from %s\n", opts.configPath)
+	}
+	if opts.offline {
+		fmt.Fprintln(w, "\n  Offline mode: no installers, downloads, or registry lookups will run.")
+	}
+	platform.SetRCEdits(!opts.noModifyRC)
+	defer platform.SetRCEdits(true)
 
 	platform.PrintStep(w, 1, 10, "Checking Claude Code installation...")
-	if err := ensureClaudeCLITo(w); err != nil {
-		return err
+	switch {
+	case opts.offline:
+		if err := ensureClaudeCLIOfflineTo(w, opts.claudeBinary, report); err != nil {
+			return err
+		}
+	case opts.claudeBinary != "" && !platform.Exists("claude"):
+		home, _ := os.UserHomeDir()
+		if err := installClaudeFromFileTo(w, home, opts.claudeBinary); err != nil {
+			return err
+		}
+	default:
+		if err := ensureClaudeCLITo(w); err != nil {
+			return err
+		}
 	}
 
 	platform.PrintStep(w, 2, 10, "API Key provisioning...")
-	if err := provisionAPIKeyTo(w, interactive); err != nil {
+	if opts.apiKeyEnv != "" {
+		if err := useAPIKeyEnvTo(w, opts.apiKeyEnv); err != nil {
+			return err
+		}
+	} else if opts.offline && !platform.IsClaudeAuthenticated() {
+		fmt.Fprintln(w, "  Skipping the login flow; it needs to reach Anthropic.")
+		report.skip("API key provisioning", "Set ANTHROPIC_API_KEY (and ANTHROPIC_BASE_URL for an internal gateway) in your environment.")
+	} else if err := provisionAPIKeyTo(w, interactive); err != nil {
 		return err
 	}
 
 	platform.PrintStep(w, 3, 10, "Setting up global user configuration...")
-	if err := setupGlobalSettingsTo(w, force); err != nil {
+	if err := setupGlobalSettingsTo(w, opts.force); err != nil {
+		return err
+	}
+	if err := setupOrgPolicyTo(w, opts, report); err != nil {
 		return err
 	}
 
@@ -80,21 +114,39 @@ func runTo(w io.Writer, force, interactive bool) error {
 	}
 
 	platform.PrintStep(w, 5, 10, "Installing claude-workspace to PATH...")
-	installBinaryToPathTo(w)
+	installBinaryToPathTo(w, opts.nonInteractive)
+	home, _ := os.UserHomeDir()
+	offerCompletionTo(w, os.Stdin, home, opts, interactive)
 
 	platform.PrintStep(w, 6, 10, "Checking Node.js (required for filesystem MCP server)...")
-	ensureNodeTo(w)
+	if opts.offline {
+		ensureNodeOfflineTo(w, report)
+	} else {
+		ensureNodeTo(w)
+	}
 
 	platform.PrintStep(w, 7, 10, "Registering user-scoped MCP servers...")
-	if err := setupUserMCPServersTo(w, force); err != nil {
+	if err := setupUserMCPServersTo(w, opts, report); err != nil {
 		platform.PrintWarningLine(w, fmt.Sprintf("MCP server registration skipped: %v", err))
 	}
 
 	platform.PrintStep(w, 8, 10, "Checking optional system tools...")
-	tools.CheckAndInstallTo(w, tools.Optional())
+	optional := selectTools(tools.Optional(), opts.tools)
+	switch {
+	case len(optional) == 0:
+		fmt.Fprintln(w, "  No optional tools selected.")
+	case opts.offline:
+		checkOptionalToolsOfflineTo(w, optional, report)
+	default:
+		tools.CheckAndInstallTo(w, optional)
+	}
 
 	platform.PrintStep(w, 9, 10, "Installing recommended plugins...")
-	setupPluginsTo(w)
+	if opts.offline {
+		skipPluginsOfflineTo(w, report)
+	} else {
+		setupPluginsTo(w)
+	}
 
 	platform.PrintStep(w, 10, 10, "Statusline setup (cost & context display)...")
 	if err := statusline.RunTo(w, []string{}); err != nil {
@@ -102,6 +154,7 @@ func runTo(w io.Writer, force, interactive bool) error {
 	}
 
 	platform.PrintBanner(w, "Setup Complete")
+	report.printTo(w)
 	fmt.Fprintln(w, "\nNext steps:")
 	fmt.Fprintln(w)
 	platform.PrintCommand(w, "claude-workspace attach /path/to/project")
@@ -160,6 +213,41 @@ func ensureNodeTo(w io.Writer) {
 	}
 }
 
+// useAPIKeyEnvTo points Claude Code at the API key in the environment
+// variable name instead of running the login flow. ANTHROPIC_API_KEY is read
+// by Claude Code directly; any other variable is wired up as an apiKeyHelper
+// in ~/.claude/settings.json.
+func useAPIKeyEnvTo(w io.Writer, name string) error {
+	if os.Getenv(name) == "" {
+		platform.PrintWarningLine(w, fmt.Sprintf("$%s is not set in this environment; Claude Code will not authenticate until it is.", name))
+	}
+	if name == "ANTHROPIC_API_KEY" {
+		fmt.Fprintln(w, "  Using ANTHROPIC_API_KEY from the environment.")
+		return nil
+	}
+
+	settingsPath := filepath.Join(claudeHome, "settings.json")
+	err := platform.WithFileLock(settingsPath, func() error {
+		settings := GetDefaultGlobalSettings()
+		if platform.FileExists(settingsPath) {
+			settings = nil
+			if err := platform.ReadJSONFile(settingsPath, &settings); err != nil {
+				return fmt.Errorf("reading global settings: %w", err)
+			}
+		}
+		settings["apiKeyHelper"] = "printenv " + name
+		if err := platform.WriteSettingsFile(settingsPath, settings); err != nil {
+			return fmt.Errorf("writing global settings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  API key read from $%s (apiKeyHelper in ~/.claude/settings.json).\n", name)
+	return nil
+}
+
 func provisionAPIKeyTo(w io.Writer, interactive bool) error {
 	if platform.IsClaudeAuthenticated() {
 		fmt.Fprintln(w, "  Already authenticated. Skipping API key provisioning.")
@@ -193,36 +281,49 @@ func setupGlobalSettingsTo(w io.Writer, force bool) error {
 
 	defaults := GetDefaultGlobalSettings()
 
-	if platform.FileExists(settingsPath) {
-		fmt.Fprintln(w, "  Global settings already exist. Merging platform defaults...")
-		var existing map[string]interface{}
-		if err := platform.ReadJSONFile(settingsPath, &existing); err != nil {
-			fmt.Fprintln(w, "  Could not merge settings. Skipping global settings update.")
+	// Taking the lock creates ~/.claude/ if needed
+	return platform.WithFileLock(settingsPath, func() error {
+		if platform.FileExists(settingsPath) {
+			fmt.Fprintln(w, "  Global settings already exist. Merging platform defaults...")
+			var existing map[string]interface{}
+			if err := platform.ReadJSONFile(settingsPath, &existing); err != nil {
+				fmt.Fprintln(w, "  Could not merge settings. Skipping global settings update.")
+				return nil
+			}
+			var merged map[string]interface{}
+			if force {
+				merged = MergeSettingsForce(existing, defaults)
+			} else {
+				merged = MergeSettings(existing, defaults)
+			}
+			if err := platform.WriteSettingsFile(settingsPath, merged); err != nil {
+				return fmt.Errorf("writing global settings: %w", err)
+			}
+			fmt.Fprintln(w, "  Global settings updated.")
 			return nil
 		}
-		var merged map[string]interface{}
-		if force {
-			merged = MergeSettingsForce(existing, defaults)
-		} else {
-			merged = MergeSettings(existing, defaults)
-		}
-		if err := platform.WriteJSONFile(settingsPath, merged); err != nil {
+
+		if err := platform.WriteSettingsFile(settingsPath, defaults); err != nil {
 			return fmt.Errorf("writing global settings: %w", err)
 		}
-		fmt.Fprintln(w, "  Global settings updated.")
+		fmt.Fprintln(w, "  Global settings created at ~/.claude/settings.json")
 		return nil
-	}
+	})
+}
 
-	// Create ~/.claude/ directory if needed
-	if err := os.MkdirAll(claudeHome, 0755); err != nil {
-		return fmt.Errorf("creating ~/.claude: %w", err)
+// setupOrgPolicyTo enforces the organization policy once the global settings
+// are written, so that --force cannot drop its mandatory rules. --org-policy
+// sets a new policy source; otherwise a saved one is synced.
+func setupOrgPolicyTo(w io.Writer, opts options, report *offlineReport) error {
+	if opts.orgPolicy == "" {
+		return orgpolicy.Sync(w, opts.offline)
 	}
-
-	if err := platform.WriteJSONFile(settingsPath, defaults); err != nil {
-		return fmt.Errorf("writing global settings: %w", err)
+	if opts.offline && strings.HasPrefix(opts.orgPolicy, "https://") {
+		fmt.Fprintln(w, "  Skipping the org policy download.")
+		report.skip("Org policy", "Run: claude-workspace policy org set "+opts.orgPolicy+" --key <public-key.pem>")
+		return orgpolicy.Sync(w, true)
 	}
-	fmt.Fprintln(w, "  Global settings created at ~/.claude/settings.json")
-	return nil
+	return orgpolicy.Set(w, opts.orgPolicy, opts.orgPolicyKey)
 }
 
 // GetDefaultGlobalSettings returns the default global settings map parsed from
@@ -292,14 +393,11 @@ func mergeSettings(existing, defaults map[string]interface{}, force bool) map[st
 				mergedPerms[k] = v
 			}
 
-			// Merge deny list (union)
-			if defaultPerms["deny"] != nil {
-				mergedPerms["deny"] = mergeStringList(existingPerms["deny"], extractStrings(defaultPerms["deny"]))
-			}
-
-			// Merge allow list (union)
-			if defaultPerms["allow"] != nil {
-				mergedPerms["allow"] = mergeStringList(existingPerms["allow"], extractStrings(defaultPerms["allow"]))
+			// Merge deny, ask, and allow lists (union)
+			for _, key := range []string{"deny", "ask", "allow"} {
+				if defaultPerms[key] != nil {
+					mergedPerms[key] = mergeStringList(existingPerms[key], extractStrings(defaultPerms[key]))
+				}
 			}
 
 			merged["permissions"] = mergedPerms
@@ -370,6 +468,123 @@ func mergeStringList(existing interface{}, defaults []string) []string {
 	return combined
 }
 
+// MergeSettingsThreeWay merges a new platform template (theirs) into a file
+// (mine) that was created from an older template (base). Keys the user left
+// alone take the new template's value, keys the template left alone keep the
+// user's value, and objects changed on both sides are merged key by key.
+// String lists such as permissions.allow keep the user's entries, gain the
+// template's new entries, and lose the entries the template dropped. Where
+// both sides changed the same value differently the user's value is kept and
+// its dotted path is returned in conflicts.
+func MergeSettingsThreeWay(base, mine, theirs map[string]interface{}) (merged map[string]interface{}, conflicts []string) {
+	merged = mergeObjects3("", base, mine, theirs, &conflicts)
+	sort.Strings(conflicts)
+	return merged, conflicts
+}
+
+func mergeObjects3(prefix string, base, mine, theirs map[string]interface{}, conflicts *[]string) map[string]interface{} {
+	keys := make(map[string]bool)
+	for _, m := range []map[string]interface{}{base, mine, theirs} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	merged := make(map[string]interface{})
+	for k := range keys {
+		b, inBase := base[k]
+		m, inMine := mine[k]
+		t, inTheirs := theirs[k]
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		var v interface{}
+		var present bool
+		switch {
+		case sameValue(m, inMine, t, inTheirs):
+			v, present = m, inMine
+		case sameValue(b, inBase, m, inMine):
+			v, present = t, inTheirs
+		case sameValue(b, inBase, t, inTheirs):
+			v, present = m, inMine
+		default:
+			v, present = mergeChanged3(path, b, m, t, inMine, inTheirs, conflicts)
+		}
+		if present {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// mergeChanged3 merges a value that both sides changed. Objects are merged key
+// by key and string lists as sets; anything else is a conflict, resolved in
+// favor of mine.
+func mergeChanged3(path string, base, mine, theirs interface{}, inMine, inTheirs bool, conflicts *[]string) (interface{}, bool) {
+	if inMine && inTheirs {
+		mineObj, mineIsObj := mine.(map[string]interface{})
+		theirsObj, theirsIsObj := theirs.(map[string]interface{})
+		if mineIsObj && theirsIsObj {
+			baseObj, _ := base.(map[string]interface{})
+			return mergeObjects3(path, baseObj, mineObj, theirsObj, conflicts), true
+		}
+		if isStringList(mine) && isStringList(theirs) && (base == nil || isStringList(base)) {
+			return mergeStringLists3(extractStrings(base), extractStrings(mine), extractStrings(theirs)), true
+		}
+	}
+	*conflicts = append(*conflicts, path)
+	return mine, inMine
+}
+
+// mergeStringLists3 applies the template's additions and removals since base
+// to mine, preserving mine's order.
+func mergeStringLists3(base, mine, theirs []string) []string {
+	inBase := make(map[string]bool)
+	for _, s := range base {
+		inBase[s] = true
+	}
+	inTheirs := make(map[string]bool)
+	var added []string
+	for _, s := range theirs {
+		inTheirs[s] = true
+		if !inBase[s] {
+			added = append(added, s)
+		}
+	}
+	var kept []string
+	for _, s := range mine {
+		if inBase[s] && !inTheirs[s] {
+			continue // dropped from the template
+		}
+		kept = append(kept, s)
+	}
+	return mergeStringList(kept, added)
+}
+
+// sameValue reports whether two possibly absent JSON values are equal.
+func sameValue(a interface{}, aPresent bool, b interface{}, bPresent bool) bool {
+	if aPresent != bPresent {
+		return false
+	}
+	return !aPresent || reflect.DeepEqual(a, b)
+}
+
+// isStringList reports whether v is a JSON array of strings.
+func isStringList(v interface{}) bool {
+	switch list := v.(type) {
+	case []string:
+		return true
+	case []interface{}:
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 func setupGlobalClaudeMdTo(w io.Writer) error {
 	claudeMdPath := filepath.Join(claudeHome, "CLAUDE.md")
 
@@ -402,6 +617,17 @@ func platformMCPServers(home string) map[string]interface{} {
 	}
 }
 
+// PlatformMCPServerNames returns the names of the user-scoped MCP servers
+// setup registers, sorted.
+func PlatformMCPServerNames() []string {
+	var names []string
+	for name := range platformMCPServers("") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // RemoveUserMCPServers removes the given server keys from the mcpServers map in config.
 // Returns the modified config. Does not write to disk.
 func RemoveUserMCPServers(config map[string]interface{}, keys []string) map[string]interface{} {
@@ -451,50 +677,88 @@ func MergeUserMCPServers(config map[string]interface{}, servers map[string]inter
 	return merged
 }
 
-func setupUserMCPServersTo(w io.Writer, force bool) error {
+// setupUserMCPServersTo registers the platform MCP servers selected by
+// opts.mcpServers. Offline, servers launched through npx are skipped and
+// added to report.
+func setupUserMCPServersTo(w io.Writer, opts options, report *offlineReport) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("getting home directory: %w", err)
 	}
 
-	var config map[string]interface{}
-	if platform.FileExists(claudeConfig) {
-		if err := platform.ReadJSONFile(claudeConfig, &config); err != nil {
-			return fmt.Errorf("reading %s: %w", claudeConfig, err)
+	servers := platformMCPServers(home)
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch {
+		case opts.mcpServers != nil && !slices.Contains(opts.mcpServers, name):
+			delete(servers, name)
+		case opts.offline && npmBased(servers[name]):
+			delete(servers, name)
+			fmt.Fprintf(w, "  Skipping %s: it is installed from the npm registry.\n", name)
+			report.skip(fmt.Sprintf("MCP server %s (npm)", name), "Register it with 'claude-workspace memory configure' once npm can reach a registry.")
 		}
 	}
-	if config == nil {
-		config = make(map[string]interface{})
+	if len(servers) == 0 {
+		if opts.mcpServers != nil && len(opts.mcpServers) == 0 {
+			fmt.Fprintln(w, "  No MCP servers selected.")
+		}
+		return nil
 	}
+	return registerUserMCPServersTo(w, opts.force, servers)
+}
 
-	if force {
-		config = RemoveUserMCPServers(config, knownMemoryProviders)
-	} else {
-		existing, _ := config["mcpServers"].(map[string]interface{})
-		for _, key := range knownMemoryProviders {
-			if existing != nil {
-				if _, found := existing[key]; found {
-					fmt.Fprintf(w, "  Memory MCP already configured (provider: %s). Run 'claude-workspace memory configure' to change providers.\n", key)
-					return nil
+// registerUserMCPServersTo adds servers to ~/.claude.json unless a memory
+// provider is already configured. With force, existing memory providers are
+// replaced.
+func registerUserMCPServersTo(w io.Writer, force bool, servers map[string]interface{}) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+
+	return platform.WithFileLock(claudeConfig, func() error {
+		var config map[string]interface{}
+		if platform.FileExists(claudeConfig) {
+			if err := platform.ReadJSONFile(claudeConfig, &config); err != nil {
+				return fmt.Errorf("reading %s: %w", claudeConfig, err)
+			}
+		}
+		if config == nil {
+			config = make(map[string]interface{})
+		}
+
+		if force {
+			config = RemoveUserMCPServers(config, knownMemoryProviders)
+		} else {
+			existing, _ := config["mcpServers"].(map[string]interface{})
+			for _, key := range knownMemoryProviders {
+				if existing != nil {
+					if _, found := existing[key]; found {
+						fmt.Fprintf(w, "  Memory MCP already configured (provider: %s). Run 'claude-workspace memory configure' to change providers.\n", key)
+						return nil
+					}
 				}
 			}
 		}
-	}
 
-	dbDir := filepath.Join(home, ".config", "claude-workspace")
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		platform.PrintWarningLine(w, fmt.Sprintf("could not create %s: %v", dbDir, err))
-	}
+		dbDir := filepath.Join(home, ".config", "claude-workspace")
+		if err := os.MkdirAll(dbDir, 0755); err != nil {
+			platform.PrintWarningLine(w, fmt.Sprintf("could not create %s: %v", dbDir, err))
+		}
 
-	servers := platformMCPServers(home)
-	merged := MergeUserMCPServers(config, servers)
+		merged := MergeUserMCPServers(config, servers)
 
-	if err := platform.WriteJSONFile(claudeConfig, merged); err != nil {
-		return fmt.Errorf("writing %s: %w", claudeConfig, err)
-	}
+		if err := platform.WriteJSONFile(claudeConfig, merged); err != nil {
+			return fmt.Errorf("writing %s: %w", claudeConfig, err)
+		}
 
-	reportMCPRegistrationTo(w, config, servers)
-	return nil
+		reportMCPRegistrationTo(w, config, servers)
+		return nil
+	})
 }
 
 func reportMCPRegistrationTo(w io.Writer, config map[string]interface{}, servers map[string]interface{}) {
@@ -519,7 +783,12 @@ func reportMCPRegistrationTo(w io.Writer, config map[string]interface{}, servers
 	}
 }
 
-func installBinaryToPathTo(w io.Writer) {
+// InstallDir is where setup installs the claude-workspace binary.
+const InstallDir = "/usr/local/bin"
+
+// installBinaryToPathTo copies the running binary to InstallDir, falling
+// back to sudo. With noPrompt, sudo fails instead of asking for a password.
+func installBinaryToPathTo(w io.Writer, noPrompt bool) {
 	execPath, err := os.Executable()
 	if err != nil {
 		fmt.Fprintln(w, "  Could not determine binary path. Skipping PATH installation.")
@@ -532,33 +801,60 @@ func installBinaryToPathTo(w io.Writer) {
 		return
 	}
 
-	installDir := "/usr/local/bin"
-	destPath := filepath.Join(installDir, "claude-workspace")
+	destPath := filepath.Join(InstallDir, "claude-workspace")
 
 	// Try to copy the binary
 	fmt.Fprintf(w, "  Installing to %s...\n", destPath)
 	if err := platform.CopyFile(execPath, destPath); err != nil {
 		// Try with sudo
-		if err := platform.Run("sudo", "cp", execPath, destPath); err != nil {
-			fmt.Fprintf(w, "  Could not install to %s (permission denied).\n", installDir)
+		sudo := []string{"cp", execPath, destPath}
+		if noPrompt {
+			sudo = append([]string{"-n"}, sudo...)
+		}
+		if err := platform.Run("sudo", sudo...); err != nil {
+			fmt.Fprintf(w, "  Could not install to %s (permission denied).\n", InstallDir)
 			fmt.Fprintf(w, "  To install manually:\n")
 			fmt.Fprintf(w, "    sudo cp %s %s\n", execPath, destPath)
 			return
 		}
 		// Make executable
-		_ = platform.RunQuiet("sudo", "chmod", "+x", destPath)
+		_ = platform.RunQuiet("sudo", "-n", "chmod", "+x", destPath)
 	} else {
 		_ = os.Chmod(destPath, 0755)
 	}
 	fmt.Fprintln(w, "  Installed: claude-workspace is now available globally.")
 }
 
+// offerCompletionTo asks whether to install tab completion for the login
+// shell. Non-interactive runs and --no-modify-rc only print how to install it.
+func offerCompletionTo(w io.Writer, in io.Reader, home string, opts options, interactive bool) {
+	if completion.Installed(home, "") {
+		fmt.Fprintln(w, "  Shell completion is already installed.")
+		return
+	}
+	if !interactive || opts.noModifyRC {
+		fmt.Fprintln(w, "  Enable tab completion with: claude-workspace completion install")
+		return
+	}
+	_, shell := platform.DetectShellRC(home)
+	platform.PrintPrompt(w, fmt.Sprintf("  Install tab completion for %s? [Y/n] ", shell))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	if answer != "" && answer != "y" && answer != "yes" {
+		fmt.Fprintln(w, "  Skipped. Run 'claude-workspace completion install' to enable it later.")
+		return
+	}
+	if err := completion.Install(w, home, ""); err != nil {
+		platform.PrintWarningLine(w, fmt.Sprintf("shell completion not installed: %v", err))
+	}
+}
+
 // ensureLocalBinClaude creates ~/.local/bin/claude as a symlink to claudePath when it doesn't
 // already exist, then ensures ~/.local/bin is present in the shell RC file.
 // We do not resolve symlinks in claudePath so that package-manager managed paths (e.g.
 // /opt/homebrew/bin/claude) remain valid across version upgrades.
 func ensureLocalBinClaude(home, claudePath string) error {
-	return ensureLocalBinClaudeTo(os.Stdout, home, claudePath)
+	return ensureLocalBinClaudeTo(platform.Stdout(), home, claudePath)
 }
 
 func ensureLocalBinClaudeTo(w io.Writer, home, claudePath string) error {
@@ -586,6 +882,20 @@ func ensureLocalBinClaudeTo(w io.Writer, home, claudePath string) error {
 	return nil
 }
 
+// selectTools returns the tools named in names, or all of them when names is nil.
+func selectTools(all []tools.Tool, names []string) []tools.Tool {
+	if names == nil {
+		return all
+	}
+	var selected []tools.Tool
+	for _, t := range all {
+		if slices.Contains(names, t.Name) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
 // platformPlugins is the list of plugins that setup installs by default.
 var platformPlugins = []string{"skill-creator@claude-plugins-official"}
 
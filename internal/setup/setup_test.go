@@ -2,6 +2,7 @@ package setup
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -40,6 +41,16 @@ func toStringSlice(v interface{}) []string {
 	return nil
 }
 
+func TestGetDefaultGlobalSettings_Valid(t *testing.T) {
+	data, err := json.Marshal(GetDefaultGlobalSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := platform.ValidateSettings(data); len(v) > 0 {
+		t.Errorf("default global settings break the settings schema: %v", v)
+	}
+}
+
 func TestMergeSettings_EmptyExisting(t *testing.T) {
 	defaults := map[string]interface{}{
 		"env": map[string]interface{}{
@@ -391,6 +402,68 @@ func TestMergeSettings_ForcePermissionsReplace(t *testing.T) {
 	}
 }
 
+func TestMergeSettingsThreeWay(t *testing.T) {
+	parse := func(s string) map[string]interface{} {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	base := parse(`{
+		"permissions": {"allow": ["Read", "Bash(npm test)"], "deny": ["Bash(rm -rf *)"]},
+		"env": {"MAX_TOKENS": "8000", "TELEMETRY": "0"},
+		"model": "sonnet",
+		"hooks": {"PreToolUse": [{"matcher": "Bash"}]},
+		"statusLine": {"type": "command"}
+	}`)
+	// The project added its own allow rule and env var, dropped a base allow
+	// rule, changed the model, and edited the hooks.
+	mine := parse(`{
+		"permissions": {"allow": ["Read", "Bash(make *)"], "deny": ["Bash(rm -rf *)"]},
+		"env": {"MAX_TOKENS": "8000", "TELEMETRY": "0", "PROJECT": "x"},
+		"model": "opus",
+		"hooks": {"PreToolUse": [{"matcher": "Bash|Write"}]},
+		"statusLine": {"type": "command"}
+	}`)
+	// The new template adds a deny rule, drops Read, raises MAX_TOKENS, drops
+	// TELEMETRY and statusLine, and also edits the hooks.
+	theirs := parse(`{
+		"permissions": {"allow": ["Bash(npm test)"], "deny": ["Bash(rm -rf *)", "Bash(curl *)"]},
+		"env": {"MAX_TOKENS": "16000"},
+		"model": "sonnet",
+		"hooks": {"PreToolUse": [{"matcher": "Edit"}]}
+	}`)
+
+	merged, conflicts := MergeSettingsThreeWay(base, mine, theirs)
+
+	perms := merged["permissions"].(map[string]interface{})
+	if got := toStringSlice(perms["allow"]); !reflect.DeepEqual(got, []string{"Bash(make *)"}) {
+		t.Errorf("allow = %v, want the project's rule without the dropped Read", got)
+	}
+	if got := toStringSlice(perms["deny"]); !reflect.DeepEqual(got, []string{"Bash(rm -rf *)", "Bash(curl *)"}) {
+		t.Errorf("deny = %v, want the new template rule added", got)
+	}
+	env := merged["env"].(map[string]interface{})
+	want := map[string]interface{}{"MAX_TOKENS": "16000", "PROJECT": "x"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("env = %v, want %v", env, want)
+	}
+	if merged["model"] != "opus" {
+		t.Errorf("model = %v, want the project's value", merged["model"])
+	}
+	if _, ok := merged["statusLine"]; ok {
+		t.Error("statusLine was removed from the template and untouched locally; it should be dropped")
+	}
+	hooks := merged["hooks"].(map[string]interface{})
+	if got := hooks["PreToolUse"].([]interface{})[0].(map[string]interface{})["matcher"]; got != "Bash|Write" {
+		t.Errorf("conflicting hooks should keep the project's value, got matcher %v", got)
+	}
+	if !reflect.DeepEqual(conflicts, []string{"hooks.PreToolUse"}) {
+		t.Errorf("conflicts = %v, want [hooks.PreToolUse]", conflicts)
+	}
+}
+
 func TestMergeUserMCPServers_AddsWhenAbsent(t *testing.T) {
 	config := map[string]interface{}{}
 	servers := map[string]interface{}{
@@ -563,6 +636,36 @@ func TestEnsureLocalBinClaude_AppendPathToRC(t *testing.T) {
 	}
 }
 
+func TestOfferCompletion(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	tests := []struct {
+		name        string
+		answer      string
+		opts        options
+		interactive bool
+		installed   bool
+	}{
+		{"accepted", "\n", options{}, true, true},
+		{"declined", "n\n", options{}, true, false},
+		{"non-interactive", "", options{}, false, false},
+		{"no-modify-rc", "y\n", options{noModifyRC: true}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			var out bytes.Buffer
+			offerCompletionTo(&out, strings.NewReader(tt.answer), home, tt.opts, tt.interactive)
+			content, _ := os.ReadFile(filepath.Join(home, ".bashrc"))
+			if got := strings.Contains(string(content), "completion bash"); got != tt.installed {
+				t.Errorf("installed = %v, want %v; output:\n%s", got, tt.installed, out.String())
+			}
+			if !tt.installed && !strings.Contains(out.String(), "claude-workspace completion install") {
+				t.Errorf("output should say how to install completion later:\n%s", out.String())
+			}
+		})
+	}
+}
+
 func TestPlatformMCPServers_ContainsLibsql(t *testing.T) {
 	home := t.TempDir()
 	servers := platformMCPServers(home)
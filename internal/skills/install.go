@@ -0,0 +1,414 @@
+package skills
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// MetadataFile records where an installed skill came from. It is written into
+// the skill's directory by "skills install".
+const MetadataFile = ".claude-workspace-skill.json"
+
+// maxBundleSize caps the size of a skill bundle, compressed or extracted.
+const maxBundleSize = 50 << 20
+
+// httpClient downloads skill bundles. Tests replace it.
+var httpClient = platform.HTTPClient(2 * time.Minute)
+
+// skillNamePattern matches skill names Claude Code accepts as slash commands.
+var skillNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Metadata is the provenance of an installed skill.
+type Metadata struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Source      string `json:"source"`
+	SHA256      string `json:"sha256,omitempty"` // of the bundle archive; empty for directories
+	InstalledAt string `json:"installedAt"`
+}
+
+// InstallOptions controls "skills install".
+type InstallOptions struct {
+	Source string // directory, .tar.gz/.tgz/.zip file, or https URL of an archive
+	SHA256 string // expected sha256 of the archive, in hex
+	Force  bool   // replace an existing skill of the same name
+}
+
+// Install unpacks the skill bundle at opts.Source into skillsDir/<name>,
+// where name comes from the bundle's SKILL.md, and records its provenance in
+// MetadataFile. A bundle holds SKILL.md and its supporting files at its root,
+// or inside a single top-level directory.
+func Install(w io.Writer, skillsDir string, opts InstallOptions) (*Metadata, error) {
+	if err := os.MkdirAll(skillsDir, 0755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(skillsDir, ".staging-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	sum, source, err := fetchBundle(w, opts, staging)
+	if err != nil {
+		return nil, err
+	}
+	root, err := bundleRoot(staging)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "SKILL.md"))
+	if err != nil {
+		return nil, err
+	}
+	name, desc := parseFrontmatterBytes(data)
+	if name == "" && root != staging {
+		name = filepath.Base(root)
+	}
+	switch {
+	case name == "":
+		return nil, errors.New("SKILL.md has no name in its frontmatter")
+	case !skillNamePattern.MatchString(name):
+		return nil, fmt.Errorf("invalid skill name %q: use lowercase letters, digits, and hyphens", name)
+	case desc == "":
+		return nil, fmt.Errorf("SKILL.md for %s has no description; Claude uses it to decide when to apply the skill", name)
+	}
+
+	meta := &Metadata{
+		Name:        name,
+		Version:     frontmatterValue(data, "version"),
+		Source:      source,
+		SHA256:      sum,
+		InstalledAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	dest := filepath.Join(skillsDir, name)
+	if platform.FileExists(dest) {
+		if !opts.Force {
+			return nil, collisionError(dest, name)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return nil, fmt.Errorf("replacing %s: %w", dest, err)
+		}
+	}
+	if err := platform.WriteJSONFile(filepath.Join(root, MetadataFile), meta); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(root, dest); err != nil {
+		return nil, fmt.Errorf("installing skill: %w", err)
+	}
+	return meta, nil
+}
+
+// collisionError explains that a skill named name is already in dest.
+func collisionError(dest, name string) error {
+	if existing, err := ReadMetadata(dest); err == nil {
+		version := ""
+		if existing.Version != "" {
+			version = " " + existing.Version
+		}
+		return fmt.Errorf("skill %q%s is already installed from %s; use --force to replace it", name, version, existing.Source)
+	}
+	return fmt.Errorf("a skill named %q already exists at %s and was not installed by 'skills install'; use --force to replace it", name, dest)
+}
+
+// fetchBundle copies or extracts the bundle named by opts.Source into dir. It
+// returns the archive's sha256 (empty for a directory) and the source as it
+// should be recorded.
+func fetchBundle(w io.Writer, opts InstallOptions, dir string) (sum, source string, err error) {
+	src := opts.Source
+	if u, perr := url.Parse(src); perr == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		if u.Scheme != "https" {
+			return "", "", fmt.Errorf("skill bundles must be fetched over https: %s", src)
+		}
+		kind := archiveKind(u.Path)
+		if kind == "" {
+			return "", "", fmt.Errorf("unsupported bundle URL %s (expected a .tar.gz, .tgz, or .zip archive)", src)
+		}
+		platform.PrintInfo(w, "Downloading "+src)
+		tmp, err := os.CreateTemp("", "claude-workspace-skill-*")
+		if err != nil {
+			return "", "", err
+		}
+		defer os.Remove(tmp.Name())
+		sum, err = platform.Download(httpClient, src, tmp, maxBundleSize)
+		tmp.Close()
+		if err != nil {
+			return "", "", fmt.Errorf("downloading skill bundle: %w", err)
+		}
+		if err := verifySum(w, sum, opts.SHA256); err != nil {
+			return "", "", err
+		}
+		return sum, src, extractArchive(kind, tmp.Name(), dir)
+	}
+
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return "", "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", "", fmt.Errorf("skill bundle not found: %s", src)
+	}
+	if info.IsDir() {
+		if opts.SHA256 != "" {
+			return "", "", errors.New("--sha256 applies to archives, not directories")
+		}
+		// Keep the directory name so a SKILL.md without a name falls back to it.
+		return "", abs, copyTree(abs, filepath.Join(dir, filepath.Base(abs)))
+	}
+	kind := archiveKind(abs)
+	if kind == "" {
+		return "", "", fmt.Errorf("unsupported bundle %s (expected a directory or a .tar.gz, .tgz, or .zip archive)", src)
+	}
+	sum, err = fileSHA256(abs)
+	if err != nil {
+		return "", "", err
+	}
+	if err := verifySum(w, sum, opts.SHA256); err != nil {
+		return "", "", err
+	}
+	return sum, abs, extractArchive(kind, abs, dir)
+}
+
+// verifySum compares an archive's sha256 with the expected one, if given.
+func verifySum(w io.Writer, sum, want string) error {
+	switch {
+	case want == "":
+		platform.PrintWarn(w, fmt.Sprintf("Bundle not verified (sha256 %s). Pass --sha256 to pin it.", sum))
+	case !strings.EqualFold(sum, want):
+		return fmt.Errorf("skill bundle checksum mismatch: expected %s, got %s", want, sum)
+	default:
+		platform.PrintOK(w, "Checksum verified")
+	}
+	return nil
+}
+
+// archiveKind returns "tar.gz" or "zip" for a supported archive name, or "".
+func archiveKind(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	}
+	return ""
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// safeEntry cleans an archive entry name and rejects names that would land
+// outside the extraction directory. It returns "" for the root entry.
+func safeEntry(name string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, `\`, "/"), "./"))
+	if clean == "." {
+		return "", nil
+	}
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe path in skill bundle: %s", name)
+	}
+	return clean, nil
+}
+
+// extractArchive extracts the regular files and directories of a tar.gz or
+// zip archive into dest. Links and other entry types are skipped.
+func extractArchive(kind, archivePath, dest string) error {
+	var total int64
+	write := func(name string, mode fs.FileMode, r io.Reader) error {
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		perm := os.FileMode(0644)
+		if mode&0111 != 0 {
+			perm = 0755
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(out, io.LimitReader(r, maxBundleSize-total+1))
+		out.Close()
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", name, err)
+		}
+		if total += n; total > maxBundleSize {
+			return fmt.Errorf("skill bundle exceeds %d MB when extracted", maxBundleSize>>20)
+		}
+		return nil
+	}
+
+	if kind == "zip" {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("opening zip: %w", err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			name, err := safeEntry(f.Name)
+			if err != nil {
+				return err
+			}
+			if name == "" || !f.Mode().IsRegular() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = write(name, f.Mode(), rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("opening gzip: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar: %w", err)
+		}
+		name, err := safeEntry(header.Name)
+		if err != nil {
+			return err
+		}
+		if name == "" || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := write(name, fs.FileMode(header.Mode), tr); err != nil {
+			return err
+		}
+	}
+}
+
+// copyTree copies the regular files under src to dst, skipping .git and
+// symlinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return platform.CopyFile(p, filepath.Join(dst, rel))
+	})
+}
+
+// bundleRoot finds the directory holding SKILL.md in an unpacked bundle: dir
+// itself, or its only subdirectory.
+func bundleRoot(dir string) (string, error) {
+	if platform.FileExists(filepath.Join(dir, "SKILL.md")) {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e.Name())
+		} else if e.Name() != ".DS_Store" {
+			dirs = append(dirs, "") // a loose file: not a wrapped bundle
+		}
+	}
+	if len(dirs) == 1 && dirs[0] != "" && platform.FileExists(filepath.Join(dir, dirs[0], "SKILL.md")) {
+		return filepath.Join(dir, dirs[0]), nil
+	}
+	return "", errors.New("skill bundle has no SKILL.md (expected it at the bundle root or in a single top-level directory)")
+}
+
+// frontmatterValue returns the value of key in SKILL.md frontmatter, or "".
+func frontmatterValue(data []byte, key string) string {
+	content := strings.TrimSpace(string(data))
+	rest, ok := strings.CutPrefix(content, "---")
+	if !ok {
+		return ""
+	}
+	frontmatter, _, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return ""
+	}
+	for _, line := range strings.Split(frontmatter, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), key+":"); ok {
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return ""
+}
+
+// ReadMetadata returns the provenance recorded for the skill in dir.
+func ReadMetadata(dir string) (*Metadata, error) {
+	var meta Metadata
+	if err := platform.ReadJSONFile(filepath.Join(dir, MetadataFile), &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// Remove deletes the skill named name from skillsDir. Skills not installed by
+// "skills install" are only removed with force, so hand-written and
+// platform skills are not deleted by accident.
+func Remove(skillsDir, name string, force bool) error {
+	if !skillNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid skill name %q", name)
+	}
+	dir := filepath.Join(skillsDir, name)
+	if !platform.FileExists(filepath.Join(dir, "SKILL.md")) {
+		return fmt.Errorf("no skill named %q in %s", name, skillsDir)
+	}
+	if _, err := ReadMetadata(dir); err != nil && !force {
+		return fmt.Errorf("skill %q was not installed by 'skills install'; use --force to remove it anyway", name)
+	}
+	return os.RemoveAll(dir)
+}
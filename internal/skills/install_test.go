@@ -0,0 +1,251 @@
+package skills
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const reviewSkill = "---\nname: review-pr\ndescription: Review a pull request\nversion: 1.2.0\n---\n\nSteps...\n"
+
+// bundleFiles is a skill bundle wrapped in a top-level directory, as produced
+// by "tar czf review-pr.tar.gz review-pr/".
+var bundleFiles = map[string]string{
+	"review-pr/SKILL.md":             reviewSkill,
+	"review-pr/scripts/checklist.sh": "#!/bin/sh\necho ok\n",
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		mode := int64(0644)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0755
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	zw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sumOf(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func assertInstalled(t *testing.T, skillsDir string, meta *Metadata, source string) {
+	t.Helper()
+	if meta.Name != "review-pr" || meta.Version != "1.2.0" || meta.Source != source {
+		t.Errorf("metadata = %+v", meta)
+	}
+	dir := filepath.Join(skillsDir, "review-pr")
+	if _, err := os.Stat(filepath.Join(dir, "scripts", "checklist.sh")); err != nil {
+		t.Errorf("supporting file not installed: %v", err)
+	}
+	onDisk, err := ReadMetadata(dir)
+	if err != nil || *onDisk != *meta {
+		t.Errorf("ReadMetadata = %+v, %v; want %+v", onDisk, err, meta)
+	}
+	if got := DiscoverSkills(skillsDir); len(got) != 1 {
+		t.Errorf("DiscoverSkills found %d skills, want 1 (staging left behind?)", len(got))
+	}
+}
+
+func TestInstall_Archives(t *testing.T) {
+	for _, kind := range []string{"tar.gz", "zip"} {
+		t.Run(kind, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "review-pr."+kind)
+			if kind == "zip" {
+				writeZip(t, archive, bundleFiles)
+			} else {
+				writeTarGz(t, archive, bundleFiles)
+			}
+			skillsDir := filepath.Join(t.TempDir(), ".claude", "skills")
+
+			meta, err := Install(io.Discard, skillsDir, InstallOptions{Source: archive, SHA256: sumOf(t, archive)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertInstalled(t, skillsDir, meta, archive)
+			if meta.SHA256 != sumOf(t, archive) {
+				t.Errorf("SHA256 = %q", meta.SHA256)
+			}
+		})
+	}
+}
+
+func TestInstall_Directory(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "review-pr")
+	for name, content := range bundleFiles {
+		path := filepath.Join(filepath.Dir(src), name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	skillsDir := t.TempDir()
+
+	meta, err := Install(io.Discard, skillsDir, InstallOptions{Source: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInstalled(t, skillsDir, meta, src)
+
+	if _, err := Install(io.Discard, skillsDir, InstallOptions{Source: src, SHA256: "abc"}); err == nil {
+		t.Error("--sha256 should be rejected for directories")
+	}
+}
+
+func TestInstall_URL(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "review-pr.tar.gz")
+	writeTarGz(t, archive, bundleFiles)
+	data, _ := os.ReadFile(archive)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+	oldClient := httpClient
+	httpClient = srv.Client()
+	defer func() { httpClient = oldClient }()
+
+	skillsDir := t.TempDir()
+	url := srv.URL + "/skills/review-pr.tar.gz"
+	meta, err := Install(io.Discard, skillsDir, InstallOptions{Source: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInstalled(t, skillsDir, meta, url)
+
+	if _, err := Install(io.Discard, t.TempDir(), InstallOptions{Source: "http://example.com/x.tar.gz"}); err == nil {
+		t.Error("plain http should be rejected")
+	}
+}
+
+func TestInstall_Collisions(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "review-pr.tar.gz")
+	writeTarGz(t, archive, bundleFiles)
+	skillsDir := t.TempDir()
+
+	if _, err := Install(io.Discard, skillsDir, InstallOptions{Source: archive}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Install(io.Discard, skillsDir, InstallOptions{Source: archive})
+	if err == nil || !strings.Contains(err.Error(), "already installed from "+archive) {
+		t.Errorf("reinstall error = %v", err)
+	}
+	if _, err := Install(io.Discard, skillsDir, InstallOptions{Source: archive, Force: true}); err != nil {
+		t.Errorf("--force reinstall: %v", err)
+	}
+
+	mkSkill(t, skillsDir, "onboarding", "onboarding", "Hand-written")
+	own := filepath.Join(t.TempDir(), "onboarding.tar.gz")
+	writeTarGz(t, own, map[string]string{"SKILL.md": "---\nname: onboarding\ndescription: Imported\n---\n"})
+	_, err = Install(io.Discard, skillsDir, InstallOptions{Source: own})
+	if err == nil || !strings.Contains(err.Error(), "not installed by 'skills install'") {
+		t.Errorf("collision with a hand-written skill: %v", err)
+	}
+}
+
+func TestInstall_Rejects(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		files map[string]string
+		sha   string
+		want  string
+	}{
+		{"checksum mismatch", bundleFiles, strings.Repeat("0", 64), "checksum mismatch"},
+		{"no SKILL.md", map[string]string{"README.md": "hi"}, "", "no SKILL.md"},
+		{"path traversal", map[string]string{"../evil/SKILL.md": reviewSkill}, "", "unsafe path"},
+		{"bad name", map[string]string{"SKILL.md": "---\nname: Review PR\ndescription: x\n---\n"}, "", "invalid skill name"},
+		{"no description", map[string]string{"SKILL.md": "---\nname: review-pr\n---\n"}, "", "no description"},
+		{"no name at root", map[string]string{"SKILL.md": "---\ndescription: x\n---\n"}, "", "no name"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(dir, string(rune('a'+i))+".tar.gz")
+			writeTarGz(t, archive, tt.files)
+			skillsDir := t.TempDir()
+			_, err := Install(io.Discard, skillsDir, InstallOptions{Source: archive, SHA256: tt.sha})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+			if entries, _ := os.ReadDir(skillsDir); len(entries) != 0 {
+				t.Errorf("failed install left %d entries behind", len(entries))
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "review-pr.tar.gz")
+	writeTarGz(t, archive, bundleFiles)
+	skillsDir := t.TempDir()
+	if _, err := Install(io.Discard, skillsDir, InstallOptions{Source: archive}); err != nil {
+		t.Fatal(err)
+	}
+	mkSkill(t, skillsDir, "onboarding", "onboarding", "Hand-written")
+
+	if err := Remove(skillsDir, "review-pr", false); err != nil {
+		t.Errorf("Remove installed skill: %v", err)
+	}
+	if err := Remove(skillsDir, "onboarding", false); err == nil {
+		t.Error("Remove should refuse a skill without install metadata")
+	}
+	if err := Remove(skillsDir, "onboarding", true); err != nil {
+		t.Errorf("Remove --force: %v", err)
+	}
+	if err := Remove(skillsDir, "missing", false); err == nil {
+		t.Error("Remove should fail for an unknown skill")
+	}
+	if err := Remove(skillsDir, "../escape", true); err == nil {
+		t.Error("Remove should reject path-like names")
+	}
+}
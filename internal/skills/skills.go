@@ -1,4 +1,5 @@
-// Package skills discovers and lists available Claude Code skills and personal commands.
+// Package skills discovers, lists, installs, and removes Claude Code skills and
+// lists personal commands.
 package skills
 
 import (
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
@@ -23,17 +25,96 @@ func Run(args []string) error {
 	subcmd := "list"
 	if len(args) > 0 {
 		subcmd = args[0]
+		args = args[1:]
 	}
 	switch subcmd {
 	case "list":
+		if _, err := cli.Operands("skills list", "skills list", args, 0, 0); err != nil {
+			return err
+		}
 		return list()
+	case "install":
+		return install(args)
+	case "remove":
+		return remove(args)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown skills subcommand: %s\n", subcmd)
-		fmt.Fprintln(os.Stderr, "Usage: claude-workspace skills [list]")
+		fmt.Fprintln(os.Stderr, "Usage: claude-workspace skills [list|install|remove]")
 		return fmt.Errorf("unknown subcommand: %s", subcmd)
 	}
 }
 
+// projectSkillsDir returns .claude/skills under the current directory.
+func projectSkillsDir() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cwd, ".claude", "skills"), nil
+}
+
+// install handles "skills install <path-or-url> [--sha256 <hex>] [--force]".
+func install(args []string) error {
+	var opts InstallOptions
+	f := cli.New("skills install", "skills install <path-or-url> [--sha256 <hex>] [--force]")
+	f.BoolVar(&opts.Force, "--force", "Replace an installed skill of the same name")
+	f.StringVar(&opts.SHA256, "--sha256", "hex", "Expected SHA-256 of the downloaded archive")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
+	}
+	opts.Source = positional[0]
+	opts.SHA256 = strings.ToLower(opts.SHA256)
+
+	skillsDir, err := projectSkillsDir()
+	if err != nil {
+		return err
+	}
+	meta, err := Install(os.Stdout, skillsDir, opts)
+	if err != nil {
+		return err
+	}
+	label := meta.Name
+	if meta.Version != "" {
+		label += " " + meta.Version
+	}
+	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Installed %s to .claude/skills/%s", label, meta.Name))
+	if home, err := os.UserHomeDir(); err == nil {
+		if cmd := filepath.Join(home, ".claude", "commands", meta.Name+".md"); platform.FileExists(cmd) {
+			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("your personal command %s also answers to /%s", cmd, meta.Name))
+		}
+	}
+	fmt.Printf("  Invoke with /%s inside Claude Code. Commit .claude/skills/%s to share it.\n", meta.Name, meta.Name)
+	return nil
+}
+
+// remove handles "skills remove <name> [--force]".
+func remove(args []string) error {
+	force := false
+	f := cli.New("skills remove", "skills remove <name> [--force]")
+	f.BoolVar(&force, "--force", "Remove a skill that was not installed by skills install")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return err
+	}
+	name := positional[0]
+	skillsDir, err := projectSkillsDir()
+	if err != nil {
+		return err
+	}
+	if err := Remove(skillsDir, name, force); err != nil {
+		return err
+	}
+	platform.PrintSuccess(os.Stdout, "Removed .claude/skills/"+name)
+	return nil
+}
+
 // list discovers skills from project, personal, and platform sources and prints them.
 func list() error {
 	platform.PrintBanner(os.Stdout, "Skills")
@@ -51,6 +132,7 @@ func list() error {
 				anyFound = true
 				platform.PrintSection(os.Stdout, "Project Skills (.claude/skills/)")
 				printSkillTable(projectSkills)
+				printInstalledTable(projectSkills)
 			}
 		}
 	}
@@ -85,6 +167,7 @@ func list() error {
 	fmt.Println("  Invoke with: /skill-name inside Claude Code")
 	fmt.Println("  Create new:  .claude/skills/my-skill/SKILL.md (project, shared)")
 	fmt.Println("               ~/.claude/commands/my-command.md (personal, local)")
+	fmt.Println("  Install:     claude-workspace skills install <path-or-url>")
 	fmt.Println()
 
 	return nil
@@ -110,7 +193,8 @@ func DiscoverSkills(root string) []Skill {
 	return skills
 }
 
-// DiscoverCommands walks a directory for .md files and uses filename + first line as description.
+// DiscoverCommands walks a directory for .md files and uses filename + the
+// frontmatter description, or else the first line, as description.
 func DiscoverCommands(root string) []Skill {
 	var commands []Skill
 	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
@@ -124,7 +208,10 @@ func DiscoverCommands(root string) []Skill {
 			return nil
 		}
 		name := strings.TrimSuffix(d.Name(), ".md")
-		desc := firstNonEmptyLine(path)
+		_, desc := parseFrontmatter(path)
+		if desc == "" {
+			desc = firstNonEmptyLine(path)
+		}
 		commands = append(commands, Skill{Name: name, Description: desc, Path: path})
 		return nil
 	})
@@ -232,3 +319,37 @@ func printSkillTable(skills []Skill) {
 	}
 	fmt.Println()
 }
+
+// printInstalledTable prints the version and source of the skills that were
+// installed with "skills install".
+func printInstalledTable(skills []Skill) {
+	var installed []*Metadata
+	for _, s := range skills {
+		if meta, err := ReadMetadata(filepath.Dir(s.Path)); err == nil {
+			installed = append(installed, meta)
+		}
+	}
+	if len(installed) == 0 {
+		return
+	}
+
+	maxName, maxVersion := len("NAME"), len("VERSION")
+	for _, m := range installed {
+		if len(m.Name) > maxName {
+			maxName = len(m.Name)
+		}
+		if len(m.Version) > maxVersion {
+			maxVersion = len(m.Version)
+		}
+	}
+	platform.PrintSection(os.Stdout, "Installed Skills")
+	fmt.Printf("  %-*s  %-*s  %s\n", maxName, "NAME", maxVersion, "VERSION", "SOURCE")
+	for _, m := range installed {
+		version := m.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Printf("  %-*s  %-*s  %s\n", maxName, m.Name, maxVersion, version, m.Source)
+	}
+	fmt.Println()
+}
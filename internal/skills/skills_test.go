@@ -180,6 +180,15 @@ func TestDiscoverCommands(t *testing.T) {
 				{Name: "valid", Description: "A valid command"},
 			},
 		},
+		{
+			name: "frontmatter description preferred",
+			setup: func(_ *testing.T, root string) {
+				_ = os.WriteFile(filepath.Join(root, "test.md"), []byte("---\ndescription: Run the test suite\n---\n\nRun `make test`."), 0644)
+			},
+			want: []Skill{
+				{Name: "test", Description: "Run the test suite"},
+			},
+		},
 		{
 			name: "file with leading blank lines",
 			setup: func(_ *testing.T, root string) {
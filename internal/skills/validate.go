@@ -0,0 +1,72 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Issue severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Limits Claude Code places on SKILL.md frontmatter fields.
+const (
+	maxNameLength        = 64
+	maxDescriptionLength = 1024
+)
+
+// Issue is a problem found in a skill definition.
+type Issue struct {
+	Path     string
+	Severity string
+	Message  string
+}
+
+// Validate checks a SKILL.md file: a frontmatter block with a valid name and
+// a description, within Claude Code's length limits.
+func Validate(path string) []Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Issue{{Path: path, Severity: SeverityError, Message: err.Error()}}
+	}
+	return validateBytes(path, data)
+}
+
+func validateBytes(path string, data []byte) []Issue {
+	var issues []Issue
+	add := func(severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	content := strings.TrimLeft(string(data), "\ufeff \t\r\n")
+	rest, ok := strings.CutPrefix(content, "---")
+	if ok {
+		_, _, ok = strings.Cut(rest, "\n---")
+	}
+	if !ok {
+		add(SeverityError, "missing frontmatter: SKILL.md must start with a --- block and close it with ---")
+		return issues
+	}
+
+	dirName := filepath.Base(filepath.Dir(path))
+	switch name := frontmatterValue(data, "name"); {
+	case name == "":
+		add(SeverityError, "missing required field: name")
+	case len(name) > maxNameLength || !skillNamePattern.MatchString(name):
+		add(SeverityError, "invalid name %q: use up to %d lowercase letters, digits, and hyphens", name, maxNameLength)
+	case name != dirName:
+		add(SeverityWarning, "name %q does not match the directory name %s", name, dirName)
+	}
+
+	switch description := frontmatterValue(data, "description"); {
+	case description == "":
+		add(SeverityError, "missing required field: description (Claude uses it to decide when to load the skill)")
+	case len(description) > maxDescriptionLength:
+		add(SeverityError, "description is %d characters; the limit is %d", len(description), maxDescriptionLength)
+	}
+	return issues
+}
@@ -0,0 +1,64 @@
+package skills
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		input string
+		want  []string // substrings of the expected issues, in order
+	}{
+		{
+			name:  "valid",
+			path:  "skills/pdf-forms/SKILL.md",
+			input: "---\nname: pdf-forms\ndescription: Fill in PDF forms\n---\n\n# PDF forms\n",
+		},
+		{
+			name:  "no frontmatter",
+			path:  "skills/notes/SKILL.md",
+			input: "# Notes\n",
+			want:  []string{"missing frontmatter"},
+		},
+		{
+			name:  "unclosed frontmatter",
+			path:  "skills/broken/SKILL.md",
+			input: "---\nname: broken\ndescription: x\n",
+			want:  []string{"missing frontmatter"},
+		},
+		{
+			name:  "missing required fields",
+			path:  "skills/empty/SKILL.md",
+			input: "---\nallowed-tools: Read\n---\n",
+			want:  []string{"missing required field: name", "missing required field: description"},
+		},
+		{
+			name:  "bad name and long description",
+			path:  "skills/PDF/SKILL.md",
+			input: "---\nname: PDF Forms\ndescription: " + strings.Repeat("x", maxDescriptionLength+1) + "\n---\n",
+			want:  []string{`invalid name "PDF Forms"`, "the limit is 1024"},
+		},
+		{
+			name:  "name differs from directory",
+			path:  "skills/forms/SKILL.md",
+			input: "---\nname: pdf-forms\ndescription: x\n---\n",
+			want:  []string{"does not match the directory name forms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := validateBytes(tt.path, []byte(tt.input))
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got %d issues %v, want %d", len(issues), issues, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(issues[i].Message, want) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, issues[i].Message, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package slashcommands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// workflow is a task name that gets a slash command of its own when a
+// project is attached. Workflows marked careful change shared state, so
+// Claude only runs them when asked and confirms first.
+type workflow struct {
+	name        string
+	description string
+	careful     bool
+}
+
+// workflows are generated in this order, for the tasks a project defines.
+var workflows = []workflow{
+	{name: "build", description: "Build the project"},
+	{name: "test", description: "Run the tests"},
+	{name: "e2e", description: "Run the end-to-end tests"},
+	{name: "lint", description: "Run the linters"},
+	{name: "fmt", description: "Format the code"},
+	{name: "format", description: "Format the code"},
+	{name: "typecheck", description: "Type-check the code"},
+	{name: "migrate", description: "Run the database migrations", careful: true},
+	{name: "deploy", description: "Deploy the project", careful: true},
+	{name: "release", description: "Cut a release", careful: true},
+}
+
+// Spec is a slash command to write under .claude/commands.
+type Spec struct {
+	Name        string
+	Description string
+	Command     string // shell command the slash command runs
+	Careful     bool
+}
+
+// Path returns the location of the command file, relative to the project.
+func (s Spec) Path() string {
+	return filepath.Join(".claude", "commands", s.Name+".md")
+}
+
+// Content renders the command file.
+func (s Spec) Content() string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "description: %s (%s)\n", s.Description, s.Command)
+	sb.WriteString("argument-hint: [arguments]\n")
+	if s.Careful {
+		sb.WriteString("disable-model-invocation: true\n")
+	} else {
+		fmt.Fprintf(&sb, "allowed-tools: Bash(%s:*)\n", s.Command)
+	}
+	sb.WriteString("---\n\n")
+	fmt.Fprintf(&sb, "Run `%s` from the project root. If arguments were given ($ARGUMENTS), pass them through.\n\n", s.Command)
+	if s.Careful {
+		sb.WriteString("This changes shared state. Before running it, show the exact command and wait for confirmation. ")
+		sb.WriteString("If it fails, show the relevant output and stop; do not retry or work around the failure.\n")
+	} else {
+		sb.WriteString("Report the result. If it fails, read the output, find the cause, and summarize what failed and where before changing anything.\n")
+	}
+	return sb.String()
+}
+
+// Detect returns the slash commands generated for the project at dir: one
+// per workflow whose name is a justfile recipe, Makefile target, Taskfile
+// task, or package.json script.
+func Detect(dir string) []Spec {
+	tasks := platform.DetectTasks(dir)
+	var specs []Spec
+	for _, wf := range workflows {
+		if t, ok := platform.FindTask(tasks, wf.name); ok {
+			specs = append(specs, Spec{Name: wf.name, Description: wf.description, Command: t.Command, Careful: wf.careful})
+		}
+	}
+	return specs
+}
+
+// Generate writes the slash commands Detect finds for projectDir. Existing
+// files are kept unless force is set. It returns the paths written, relative
+// to projectDir.
+func Generate(w io.Writer, projectDir string, force bool) ([]string, error) {
+	return GenerateTo(w, projectDir, projectDir, force)
+}
+
+// GenerateTo is Generate with the commands written under destDir instead of
+// projectDir, which is still where the tasks are detected.
+func GenerateTo(w io.Writer, projectDir, destDir string, force bool) ([]string, error) {
+	specs := Detect(projectDir)
+	if len(specs) == 0 {
+		platform.PrintInfo(w, "No build, test, or deploy tasks found in a justfile, Makefile, Taskfile, or package.json")
+		return nil, nil
+	}
+	var written []string
+	for _, s := range specs {
+		rel := s.Path()
+		path := filepath.Join(destDir, rel)
+		if platform.FileExists(path) && !force {
+			platform.PrintWarningLine(w, fmt.Sprintf("Skipping (exists): %s", rel))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("creating %s: %w", filepath.Dir(rel), err)
+		}
+		if err := os.WriteFile(path, []byte(s.Content()), 0644); err != nil {
+			return written, fmt.Errorf("writing %s: %w", rel, err)
+		}
+		platform.PrintSuccess(w, fmt.Sprintf("Created: %s (/%s runs %s)", rel, s.Name, s.Command))
+		written = append(written, rel)
+	}
+	return written, nil
+}
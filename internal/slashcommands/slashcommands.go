@@ -0,0 +1,206 @@
+// Package slashcommands implements the "commands" command, which lists and
+// adds a project's Claude Code slash commands (.claude/commands/*.md), and
+// generates them from the tasks the project's justfile, Makefile, Taskfile,
+// or package.json defines.
+package slashcommands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/skills"
+)
+
+const usage = "Usage: claude-workspace commands [list|add <name> [--run <command>] [--description <text>] [--careful] [--force]]"
+
+// Env holds the directories the commands command works in.
+type Env struct {
+	Home string
+	Cwd  string
+}
+
+var nameRe = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// Run routes the commands subcommand.
+func Run(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	return run(os.Stdout, args, Env{Home: home, Cwd: cwd})
+}
+
+func run(w io.Writer, args []string, env Env) error {
+	subcmd := "list"
+	if len(args) > 0 {
+		subcmd = args[0]
+		args = args[1:]
+	}
+	switch subcmd {
+	case "list":
+		if _, err := cli.Operands("commands list", "commands list", args, 0, 0); err != nil {
+			return err
+		}
+		return list(w, env)
+	case "add":
+		return add(w, args, env)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown commands subcommand: %s\n", subcmd)
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown subcommand: %s", subcmd)
+	}
+}
+
+// list prints the project and personal slash commands, and the project tasks
+// that do not have one yet.
+func list(w io.Writer, env Env) error {
+	platform.PrintBanner(w, "Slash Commands")
+	fmt.Fprintln(w)
+
+	project := skills.DiscoverCommands(filepath.Join(env.Cwd, ".claude", "commands"))
+	personal := skills.DiscoverCommands(filepath.Join(env.Home, ".claude", "commands"))
+	if len(project) > 0 {
+		platform.PrintSection(w, "Project Commands (.claude/commands/)")
+		printTable(w, project)
+	}
+	if len(personal) > 0 {
+		platform.PrintSection(w, "Personal Commands (~/.claude/commands/)")
+		printTable(w, personal)
+	}
+	if len(project) == 0 && len(personal) == 0 {
+		fmt.Fprintln(w, "  No slash commands found.")
+		fmt.Fprintln(w)
+	}
+
+	have := map[string]bool{}
+	for _, c := range project {
+		have[c.Name] = true
+	}
+	var missing []skills.Skill
+	for _, t := range platform.DetectTasks(env.Cwd) {
+		if !have[t.Name] && nameRe.MatchString(t.Name) {
+			missing = append(missing, skills.Skill{Name: t.Name, Description: fmt.Sprintf("%s (%s)", t.Command, t.Source)})
+		}
+	}
+	if len(missing) > 0 {
+		platform.PrintSection(w, "Project Tasks Without a Command")
+		printTable(w, missing)
+	}
+
+	platform.PrintSection(w, "Tips")
+	fmt.Fprintln(w, "  Invoke with: /name inside Claude Code")
+	fmt.Fprintln(w, "  Add a task:  claude-workspace commands add <task>")
+	fmt.Fprintln(w, "  Add any:     claude-workspace commands add <name> --run \"<command>\"")
+	fmt.Fprintln(w)
+	return nil
+}
+
+// printTable prints slash commands in aligned columns.
+func printTable(w io.Writer, cmds []skills.Skill) {
+	width := 0
+	for _, c := range cmds {
+		width = max(width, len(c.Name)+1)
+	}
+	for _, c := range cmds {
+		desc := c.Description
+		if len(desc) > 70 {
+			desc = desc[:67] + "..."
+		}
+		fmt.Fprintf(w, "  %-*s  %s\n", width, "/"+c.Name, desc)
+	}
+	fmt.Fprintln(w)
+}
+
+// addOptions are the flags of "commands add".
+type addOptions struct {
+	Spec
+	Force bool
+}
+
+func parseAddArgs(args []string) (addOptions, error) {
+	var opts addOptions
+	f := cli.New("commands add", "commands add <name> [--run <command>] [--description <text>] [--careful] [--force]")
+	f.StringVar(&opts.Command, "--run", "command", "Shell command to run instead of the project task of the same name")
+	f.StringVar(&opts.Description, "--description", "text", "Description shown in the / menu")
+	f.BoolVar(&opts.Careful, "--careful", "Run only when typed, and confirm first")
+	f.BoolVar(&opts.Force, "--force", "Replace an existing command")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 1, 1); err != nil {
+		return opts, err
+	}
+	opts.Name = positional[0]
+	if !nameRe.MatchString(opts.Name) {
+		return opts, fmt.Errorf("invalid command name %q: use lowercase letters, digits, '-' and '_'", opts.Name)
+	}
+	return opts, nil
+}
+
+// add writes .claude/commands/<name>.md. Without --run, the command runs the
+// project task of the same name.
+func add(w io.Writer, args []string, env Env) error {
+	opts, err := parseAddArgs(args)
+	if err != nil {
+		return err
+	}
+	spec := opts.Spec
+	if spec.Command == "" {
+		tasks := platform.DetectTasks(env.Cwd)
+		t, ok := platform.FindTask(tasks, spec.Name)
+		if !ok {
+			var names []string
+			for _, t := range tasks {
+				names = append(names, t.Name)
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("no task named %q: no justfile, Makefile, Taskfile, or package.json scripts found; pass --run <command>", spec.Name)
+			}
+			return fmt.Errorf("no task named %q (found: %s); pass --run <command>", spec.Name, strings.Join(names, ", "))
+		}
+		spec.Command = t.Command
+	}
+	for _, wf := range workflows {
+		if wf.name == spec.Name {
+			spec.Description = valueOr(spec.Description, wf.description)
+			spec.Careful = spec.Careful || wf.careful
+		}
+	}
+	spec.Description = valueOr(spec.Description, "Run "+spec.Name)
+
+	rel := spec.Path()
+	path := filepath.Join(env.Cwd, rel)
+	if platform.FileExists(path) && !opts.Force {
+		return fmt.Errorf("%s already exists (use --force to replace it)", rel)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(rel), err)
+	}
+	if err := os.WriteFile(path, []byte(spec.Content()), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", rel, err)
+	}
+	platform.PrintSuccess(w, fmt.Sprintf("Created %s: /%s runs %s", rel, spec.Name, spec.Command))
+	if spec.Careful {
+		fmt.Fprintln(w, "  Claude runs it only when you type the command, and confirms first.")
+	}
+	fmt.Fprintf(w, "  Commit %s to share it with the team.\n", rel)
+	return nil
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
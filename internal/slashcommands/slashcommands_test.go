@@ -0,0 +1,102 @@
+package slashcommands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testEnv(t *testing.T) Env {
+	t.Helper()
+	env := Env{Home: t.TempDir(), Cwd: t.TempDir()}
+	os.WriteFile(filepath.Join(env.Cwd, "Makefile"), []byte("test:\n\tgo test ./...\ndeploy:\n\t./deploy.sh\ndocs:\n\tmkdocs build\n"), 0644)
+	os.WriteFile(filepath.Join(env.Cwd, "package.json"), []byte(`{"scripts": {"test": "vitest", "lint": "eslint ."}}`), 0644)
+	return env
+}
+
+func readCommand(t *testing.T, env Env, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(env.Cwd, ".claude", "commands", name+".md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGenerate(t *testing.T) {
+	env := testEnv(t)
+	os.MkdirAll(filepath.Join(env.Cwd, ".claude", "commands"), 0755)
+	os.WriteFile(filepath.Join(env.Cwd, ".claude", "commands", "lint.md"), []byte("my lint"), 0644)
+
+	var out bytes.Buffer
+	written, err := Generate(&out, env.Cwd, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 2 || written[0] != filepath.Join(".claude", "commands", "test.md") {
+		t.Errorf("written = %v", written)
+	}
+	if test := readCommand(t, env, "test"); !strings.Contains(test, "Run `make test`") || !strings.Contains(test, "allowed-tools: Bash(make test:*)") {
+		t.Errorf("test.md:\n%s", test)
+	}
+	if deploy := readCommand(t, env, "deploy"); !strings.Contains(deploy, "disable-model-invocation: true") || strings.Contains(deploy, "allowed-tools") {
+		t.Errorf("deploy.md:\n%s", deploy)
+	}
+	if lint := readCommand(t, env, "lint"); lint != "my lint" || !strings.Contains(out.String(), "Skipping (exists)") {
+		t.Errorf("existing lint.md replaced: %q\n%s", lint, out.String())
+	}
+
+	if _, err := Generate(&out, env.Cwd, true); err != nil {
+		t.Fatal(err)
+	}
+	if lint := readCommand(t, env, "lint"); !strings.Contains(lint, "npm run lint") {
+		t.Errorf("lint.md with force:\n%s", lint)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	env := testEnv(t)
+	var out bytes.Buffer
+	if err := run(&out, []string{"add", "docs"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if docs := readCommand(t, env, "docs"); !strings.Contains(docs, "description: Run docs (make docs)") {
+		t.Errorf("docs.md:\n%s", docs)
+	}
+	if err := run(&out, []string{"add", "seed", "--run", "./scripts/seed.sh --dev", "--description=Seed the dev database", "--careful"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if seed := readCommand(t, env, "seed"); !strings.Contains(seed, "Seed the dev database (./scripts/seed.sh --dev)") || !strings.Contains(seed, "disable-model-invocation") {
+		t.Errorf("seed.md:\n%s", seed)
+	}
+
+	for _, args := range [][]string{
+		{"add", "docs"},
+		{"add", "bench"},
+		{"add", "Docs", "--run", "make docs"},
+		{"add", "--run", "make docs"},
+		{"add", "x", "--run"},
+		{"add", "x", "y"},
+		{"remove", "x"},
+	} {
+		if err := run(&bytes.Buffer{}, args, env); err == nil {
+			t.Errorf("commands %q: expected error", args)
+		}
+	}
+
+	out.Reset()
+	if err := run(&out, nil, env); err != nil {
+		t.Fatal(err)
+	}
+	list := out.String()
+	for _, want := range []string{"/docs", "Seed the dev database", "/deploy", "make deploy (Makefile)"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("list missing %q:\n%s", want, list)
+		}
+	}
+	if strings.Contains(list, "make docs (Makefile)") {
+		t.Errorf("list shows a task that already has a command:\n%s", list)
+	}
+}
@@ -0,0 +1,120 @@
+package statusline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultContextSize is the context window, in tokens, assumed when
+	// Claude Code does not report one.
+	defaultContextSize = 200_000
+	// extendedContextSize is the window of models run with the "[1m]" suffix.
+	extendedContextSize = 1_000_000
+	// transcriptTailBytes bounds how much of the transcript is read per render.
+	transcriptTailBytes = 512 * 1024
+)
+
+// transcriptContextTokens returns the number of tokens in the context window
+// after the last main-thread assistant message in the transcript at path: its
+// input tokens plus cache reads and writes. It reads only the end of the file
+// and returns 0 if no usage is found.
+func transcriptContextTokens(path string) int64 {
+	if path == "" {
+		return 0
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0
+	}
+	offset := info.Size() - transcriptTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return 0
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:] // the first line is likely cut off
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		var entry struct {
+			Type              string `json:"type"`
+			IsSidechain       bool   `json:"isSidechain"`
+			IsAPIErrorMessage bool   `json:"isApiErrorMessage"`
+			Message           struct {
+				Usage *struct {
+					InputTokens              int64 `json:"input_tokens"`
+					CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+					CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+		}
+		if !bytes.Contains(lines[i], []byte(`"usage"`)) || json.Unmarshal(lines[i], &entry) != nil {
+			continue
+		}
+		if entry.Type != "assistant" || entry.IsSidechain || entry.IsAPIErrorMessage || entry.Message.Usage == nil {
+			continue
+		}
+		u := entry.Message.Usage
+		if total := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens; total > 0 {
+			return total
+		}
+	}
+	return 0
+}
+
+// contextSize returns the context window size for a session: the size Claude
+// Code reports, or the default for the model.
+func contextSize(reported int64, modelID string) int64 {
+	switch {
+	case reported > 0:
+		return reported
+	case strings.HasSuffix(strings.ToLower(modelID), "[1m]"):
+		return extendedContextSize
+	default:
+		return defaultContextSize
+	}
+}
+
+// fallbackLine stands in for ccusage's line when neither ccusage nor jq is
+// available, using the session cost Claude Code reports and the context usage
+// from the transcript. It keeps ccusage's icons so compaction treats it alike.
+func fallbackLine(s session) string {
+	model := s.Model
+	if model == "" {
+		model = "Claude"
+	}
+	line := fmt.Sprintf("🤖 %s | 💰 $%.2f session", model, s.Cost)
+	if s.ContextTokens > 0 {
+		line += fmt.Sprintf(" | 🧠 %s (%.0f%%)", formatThousands(s.ContextTokens), s.ContextPct)
+	} else if s.ContextPct > 0 {
+		line += fmt.Sprintf(" | 🧠 %.0f%%", s.ContextPct)
+	}
+	return line
+}
+
+// formatThousands formats n with comma separators, like ccusage.
+func formatThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package statusline
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func assistantLine(input, cacheCreate, cacheRead int64, extra string) string {
+	return fmt.Sprintf(`{"type":"assistant",%s"message":{"role":"assistant","usage":{"input_tokens":%d,"cache_creation_input_tokens":%d,"cache_read_input_tokens":%d,"output_tokens":500}}}`,
+		extra, input, cacheCreate, cacheRead)
+}
+
+func TestTranscriptContextTokens(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"hi"}}`,
+		assistantLine(10, 1000, 20000, ""),
+		assistantLine(5, 2000, 40000, ""),
+		`{"type":"user","message":{"role":"user","content":"go on"}}`,
+		assistantLine(1, 1, 1, `"isSidechain":true,`),
+		assistantLine(0, 0, 0, `"isApiErrorMessage":true,`),
+		`not json`,
+	}
+	writeFile(t, path, strings.Join(lines, "\n")+"\n")
+	if got := transcriptContextTokens(path); got != 42005 {
+		t.Errorf("transcriptContextTokens() = %d, want 42005", got)
+	}
+
+	// Only the tail of a large transcript is read.
+	var big bytes.Buffer
+	filler := `{"type":"user","message":{"content":"` + strings.Repeat("x", 1000) + `"}}` + "\n"
+	for big.Len() < transcriptTailBytes+4096 {
+		big.WriteString(filler)
+	}
+	big.WriteString(assistantLine(100, 0, 900, "") + "\n")
+	bigPath := filepath.Join(dir, "big.jsonl")
+	if err := os.WriteFile(bigPath, big.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := transcriptContextTokens(bigPath); got != 1000 {
+		t.Errorf("transcriptContextTokens(big) = %d, want 1000", got)
+	}
+
+	if got := transcriptContextTokens(filepath.Join(dir, "missing.jsonl")); got != 0 {
+		t.Errorf("transcriptContextTokens(missing) = %d", got)
+	}
+}
+
+func TestParseSession_ContextFromTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	writeFile(t, path, assistantLine(0, 10000, 40000, "")+"\n")
+
+	tests := []struct {
+		name  string
+		input string
+		want  float64
+	}{
+		{"transcript", `{"transcript_path":"` + path + `","context_window":{"used_percentage":5}}`, 25},
+		{"reported window size", `{"transcript_path":"` + path + `","context_window":{"context_window_size":500000}}`, 10},
+		{"1m model", `{"transcript_path":"` + path + `","model":{"id":"claude-sonnet-4-5[1m]"}}`, 5},
+		{"no transcript", `{"transcript_path":"/nonexistent","context_window":{"used_percentage":12}}`, 12},
+	}
+	for _, tt := range tests {
+		if got := parseSession([]byte(tt.input)).ContextPct; got != tt.want {
+			t.Errorf("%s: ContextPct = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackLine(t *testing.T) {
+	tests := []struct {
+		s    session
+		want string
+	}{
+		{session{Model: "Opus", Cost: 0.234, ContextTokens: 45210, ContextPct: 22.6}, "🤖 Opus | 💰 $0.23 session | 🧠 45,210 (23%)"},
+		{session{Cost: 1, ContextPct: 12}, "🤖 Claude | 💰 $1.00 session | 🧠 12%"},
+		{session{Model: "Sonnet"}, "🤖 Sonnet | 💰 $0.00 session"},
+	}
+	for _, tt := range tests {
+		if got := fallbackLine(tt.s); got != tt.want {
+			t.Errorf("fallbackLine(%+v) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+	if got := formatThousands(1234567); got != "1,234,567" {
+		t.Errorf("formatThousands() = %q", got)
+	}
+}
+
+func TestRender_FallbackWithoutCcusage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	inputJSON := `{"cost":{"total_cost_usd":0.42},"model":{"display_name":"Opus"},"context_window":{"used_percentage":30}}`
+	for _, base := range []string{"", "❌ ccusage failed"} {
+		var buf bytes.Buffer
+		if err := Render(strings.NewReader(inputJSON), &buf, base, "200", "95"); err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		if !strings.Contains(buf.String(), "🤖 Opus | 💰 $0.42 session | 🧠 30%") {
+			t.Errorf("Render(base=%q) should show the built-in line, got %q", base, buf.String())
+		}
+	}
+}
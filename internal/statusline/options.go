@@ -0,0 +1,217 @@
+package statusline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// configKey is the key under which statusline options are stored in
+// ~/.claude-workspace/config.json.
+const configKey = "statusline"
+
+// Segment names accepted by --segments, in their default display order.
+const (
+	segCcusage  = "ccusage"  // ccusage's own line (session/today/block cost, burn rate, tokens)
+	segModel    = "model"    // model display name
+	segCost     = "cost"     // session cost
+	segToday    = "today"    // today's spend in the current project, across sessions
+	segContext  = "context"  // context window usage
+	segGit      = "git"      // current git branch
+	segDuration = "duration" // session duration
+	segReset    = "reset"    // weekly subscription reset countdown
+)
+
+var segmentNames = []string{segCcusage, segModel, segCost, segToday, segContext, segGit, segDuration, segReset}
+
+// defaultSegments reproduces the original statusline: the ccusage line
+// followed by the weekly reset countdown.
+var defaultSegments = []string{segCcusage, segReset}
+
+// Default warning and critical thresholds for context usage, in percent.
+const (
+	defaultContextWarn     = 70
+	defaultContextCritical = 90
+)
+
+// Options selects which segments the statusline shows, the thresholds at
+// which values change color, and the theme. The zero value is the default
+// ccusage layout.
+type Options struct {
+	Segments   []string   `json:"segments,omitempty"`
+	Theme      string     `json:"theme,omitempty"`
+	Thresholds Thresholds `json:"thresholds,omitempty"`
+}
+
+// Thresholds are the values at which a segment turns yellow (warn) or red
+// (critical). Zero means the default: 70/90 for context usage, and 80%/100%
+// of the per-session budget for cost when one is set.
+type Thresholds struct {
+	CostWarn        float64 `json:"costWarn,omitempty"`
+	CostCritical    float64 `json:"costCritical,omitempty"`
+	ContextWarn     float64 `json:"contextWarn,omitempty"`
+	ContextCritical float64 `json:"contextCritical,omitempty"`
+}
+
+// segments returns the configured segments, or the default layout.
+func (o Options) segments() []string {
+	if len(o.Segments) == 0 {
+		return defaultSegments
+	}
+	return o.Segments
+}
+
+// has reports whether segment name is shown.
+func (o Options) has(name string) bool {
+	for _, s := range o.segments() {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isDefault reports whether o renders the original ccusage layout, which
+// keeps its own width compaction.
+func (o Options) isDefault() bool {
+	if o.Theme != "" && o.Theme != themeDefault {
+		return false
+	}
+	segs := o.segments()
+	if len(segs) != len(defaultSegments) {
+		return false
+	}
+	for i := range segs {
+		if segs[i] != defaultSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks segment names, the theme, and that each warn threshold is
+// below its critical threshold.
+func (o Options) validate() error {
+	seen := map[string]bool{}
+	for _, s := range o.Segments {
+		if !isSegment(s) {
+			return fmt.Errorf("unknown segment %q (valid: %s)", s, strings.Join(segmentNames, ", "))
+		}
+		if seen[s] {
+			return fmt.Errorf("segment %q listed twice", s)
+		}
+		seen[s] = true
+	}
+	if o.Theme != "" {
+		if _, ok := themes[o.Theme]; !ok {
+			return fmt.Errorf("unknown theme %q (valid: %s)", o.Theme, strings.Join(themeNames, ", "))
+		}
+	}
+	t := o.Thresholds
+	if t.CostWarn > 0 && t.CostCritical > 0 && t.CostWarn >= t.CostCritical {
+		return fmt.Errorf("--cost-warn (%g) must be below --cost-critical (%g)", t.CostWarn, t.CostCritical)
+	}
+	if ctxWarn, ctxCrit := t.contextLevels(); ctxWarn >= ctxCrit {
+		return fmt.Errorf("--context-warn (%g) must be below --context-critical (%g)", ctxWarn, ctxCrit)
+	}
+	return nil
+}
+
+// contextLevels returns the context warn and critical percentages with
+// defaults applied.
+func (t Thresholds) contextLevels() (warn, critical float64) {
+	warn, critical = t.ContextWarn, t.ContextCritical
+	if warn <= 0 {
+		warn = defaultContextWarn
+	}
+	if critical <= 0 {
+		critical = defaultContextCritical
+	}
+	return warn, critical
+}
+
+// costLevels returns the cost warn and critical amounts in USD. Unset levels
+// fall back to 80% and 100% of perSession; both are 0 (no coloring) when
+// neither is configured.
+func (t Thresholds) costLevels(perSession float64) (warn, critical float64) {
+	warn, critical = t.CostWarn, t.CostCritical
+	if warn <= 0 && perSession > 0 {
+		warn = perSession * 0.8
+	}
+	if critical <= 0 && perSession > 0 {
+		critical = perSession
+	}
+	return warn, critical
+}
+
+func isSegment(name string) bool {
+	for _, s := range segmentNames {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadOptions reads the saved statusline options. Missing options are the
+// zero value (the default layout).
+func LoadOptions() (Options, error) {
+	var o Options
+	_, err := platform.ReadConfig(configKey, &o)
+	return o, err
+}
+
+func saveOptions(o Options) error {
+	return platform.WriteConfig(configKey, o)
+}
+
+// customFlags declares the customization flags (--segments, --theme, and the
+// thresholds) on f, applied on top of base. After f.Parse, the returned
+// function gives the options and whether any customization flag was given.
+func customFlags(f *cli.FlagSet, base Options) func() (Options, bool, error) {
+	opts := base
+	changed := false
+	f.Func("--segments", "list", "Comma-separated segments in display order, or default", func(v string) error {
+		opts.Segments = nil
+		if v != "default" {
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					opts.Segments = append(opts.Segments, s)
+				}
+			}
+		}
+		changed = true
+		return nil
+	})
+	f.Func("--theme", "name", "default, minimal, or mono", func(v string) error {
+		opts.Theme, changed = v, true
+		return nil
+	})
+	for _, t := range []struct {
+		name, help string
+		p          *float64
+	}{
+		{"--cost-warn", "Session cost in USD at which cost turns yellow", &opts.Thresholds.CostWarn},
+		{"--cost-critical", "Session cost in USD at which cost turns red", &opts.Thresholds.CostCritical},
+		{"--context-warn", "Context usage percent at which context turns yellow", &opts.Thresholds.ContextWarn},
+		{"--context-critical", "Context usage percent at which context turns red", &opts.Thresholds.ContextCritical},
+	} {
+		f.Func(t.name, "n", t.help, func(v string) error {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s: invalid value %q", t.name, v)
+			}
+			*t.p, changed = n, true
+			return nil
+		})
+	}
+	return func() (Options, bool, error) {
+		if err := opts.validate(); err != nil {
+			return opts, false, err
+		}
+		return opts, changed, nil
+	}
+}
@@ -0,0 +1,73 @@
+package statusline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/cost"
+)
+
+// sampleCcusage stands in for ccusage's output in previews.
+const sampleCcusage = "🤖 Opus | 💰 $0.23 session / $1.23 today / $0.45 block (2h 45m left) | 🔥 $0.12/hr | 🧠 25,000 (12%)"
+
+// previewTo implements "statusline preview": it renders sample lines with the
+// saved options plus any flags in args, without saving them or touching
+// settings.json.
+func previewTo(w io.Writer, args []string) error {
+	saved, err := LoadOptions()
+	if err != nil {
+		return err
+	}
+	f := cli.New("statusline preview", "statusline preview [--segments <list>] [--theme <name>] [threshold flags]")
+	custom := customFlags(f, saved)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	opts, _, err := custom()
+	if err != nil {
+		return err
+	}
+	budget, _ := cost.LoadBudget()
+
+	fmt.Fprintf(w, "Segments: %s  Theme: %s\n\n", strings.Join(opts.segments(), ", "), opts.themeName())
+	for _, line := range previewLines(opts, budget.PerSession) {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// previewLines renders the statusline for a sample session. When cost or
+// context is shown, it also renders the session at the warning and critical
+// thresholds so their colors can be compared.
+func previewLines(opts Options, perSession float64) []string {
+	s := session{Model: "Opus", Cost: 0.42, DurationMS: 47 * 60000, ContextPct: 35, Branch: "main"}
+	render := func(s session) string {
+		parts, sep := lineParts(s, sampleCcusage, "resets in 3d", opts, perSession)
+		return strings.Join(parts, sep)
+	}
+	if !opts.has(segCost) && !opts.has(segContext) {
+		return []string{render(s)}
+	}
+
+	costWarn, costCritical := opts.Thresholds.costLevels(perSession)
+	ctxWarn, ctxCritical := opts.Thresholds.contextLevels()
+	warn, critical := s, s
+	warn.ContextPct, critical.ContextPct = ctxWarn, ctxCritical
+	if costWarn > 0 {
+		warn.Cost = costWarn
+	}
+	if costCritical > 0 {
+		critical.Cost = costCritical
+	}
+	return []string{
+		"  normal    " + render(s),
+		"  warning   " + render(warn),
+		"  critical  " + render(critical),
+	}
+}
@@ -12,6 +12,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/cost"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
 const (
@@ -93,10 +97,7 @@ func formatDuration(t time.Time) string {
 	if mins <= 0 {
 		return ""
 	}
-	if mins < 60 {
-		return strconv.Itoa(mins) + "m"
-	}
-	return strconv.Itoa(mins/60) + "h " + strconv.Itoa(mins%60) + "m"
+	return formatMinutes(mins)
 }
 
 // parseTime attempts RFC3339Nano then RFC3339; returns zero time on failure.
@@ -113,10 +114,14 @@ func parseTime(s string) time.Time {
 // It reads the Claude Code JSON blob from stdin and writes the formatted statusline to stdout.
 func RunRender(args []string) error {
 	base := ""
-	for _, a := range args {
-		if strings.HasPrefix(a, "--base=") {
-			base = strings.TrimPrefix(a, "--base=")
-		}
+	f := cli.New("statusline render", "statusline render [--base=<line>]")
+	f.StringVar(&base, "--base", "line", "Pre-computed ccusage base line")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
 	}
 	return Render(os.Stdin, os.Stdout, base, os.Getenv("COLS"), os.Getenv("CLAUDE_AUTOCOMPACT_PCT_OVERRIDE"))
 }
@@ -132,13 +137,50 @@ func Render(r io.Reader, w io.Writer, base, colsStr, autocompactPct string) erro
 
 	home, _ := os.UserHomeDir()
 	cacheDir := filepath.Join(os.TempDir(), "claude-statusline")
+	opts, _ := LoadOptions()
 
-	reset := computeWeeklyReset(home)
+	reset := ""
+	if opts.has(segReset) {
+		reset = computeWeeklyReset(home)
+	}
 	alerts := newServiceChecker(cacheDir, nil).check()
-	// Combine base + reset
-	result := strings.TrimRight(base, "\n")
-	if result != "" && reset != "" {
-		result = result + " | " + reset
+	budget, err := cost.LoadBudget()
+	if err == nil {
+		if a := budgetAlert(inputJSON, budget); a != "" {
+			alerts = strings.TrimSpace(a + "  " + alerts)
+		}
+	}
+
+	// Without ccusage (no bun or npx) or when it fails, build its line from
+	// the session so the statusline never comes up empty.
+	s := parseSession(inputJSON)
+	base = strings.TrimRight(base, "\n")
+	if base == "" || strings.HasPrefix(base, "❌") {
+		base = fallbackLine(s)
+	}
+
+	// ccusage's "today" covers every project; show the current project's.
+	if opts.has(segToday) || (opts.has(segCcusage) && todayRE.MatchString(base)) {
+		s.Today, s.TodayKnown = projectToday(cacheDir, s.ProjectDir, time.Now())
+	}
+
+	// Combine the configured segments. The default layout is base + reset.
+	var parts []string
+	var sep string
+	result := base
+	if opts.isDefault() {
+		if s.TodayKnown {
+			result = replaceToday(result, s.Today)
+		}
+		if reset != "" {
+			result = result + " | " + reset
+		}
+	} else {
+		if opts.has(segGit) {
+			s.Branch = gitBranch(s.Dir)
+		}
+		parts, sep = lineParts(s, base, reset, opts, budget.PerSession)
+		result = strings.Join(parts, sep)
 	}
 
 	// Parse terminal width and autocompact threshold
@@ -157,7 +199,7 @@ func Render(r io.Reader, w io.Writer, base, colsStr, autocompactPct string) erro
 
 	// Width compaction: CC places its autocompact indicator on the first output line.
 	// Only compact the line that shares space with the indicator.
-	ccReserve := ccReserveWidth(inputJSON, threshold)
+	ccReserve := ccReserveWidth(s.ContextPct, threshold)
 	if ccReserve > 0 {
 		firstLineMaxW := cols - ccReserve
 		if firstLineMaxW < 20 {
@@ -166,10 +208,15 @@ func Render(r io.Reader, w io.Writer, base, colsStr, autocompactPct string) erro
 		switch {
 		case alerts != "":
 			alerts = compactAlerts(alerts, firstLineMaxW)
-		default:
+		case opts.isDefault():
 			result = compactResult(result, reset, inputJSON, firstLineMaxW)
+		default:
+			result = fitParts(parts, sep, firstLineMaxW)
 		}
 	}
+	if !opts.theme().color {
+		alerts = ansiRE.ReplaceAllString(alerts, "")
+	}
 
 	for _, line := range []string{alerts, result} {
 		if line != "" {
@@ -214,6 +261,30 @@ func computeWeeklyReset(home string) string {
 	}
 }
 
+// budgetAlert returns an alert when the session cost in inputJSON crosses the
+// per-session budget's warning threshold, or "" otherwise. Monthly budgets are
+// checked by "claude-workspace cost", since they need a full ccusage scan.
+func budgetAlert(inputJSON []byte, budget cost.Budget) string {
+	var data struct {
+		Cost struct {
+			TotalCostUSD float64 `json:"total_cost_usd"`
+		} `json:"cost"`
+	}
+	if len(inputJSON) > 0 {
+		_ = json.Unmarshal(inputJSON, &data)
+	}
+	alerts := cost.CheckBudget(cost.Budget{PerSession: budget.PerSession}, 0, data.Cost.TotalCostUSD)
+	if len(alerts) == 0 {
+		return ""
+	}
+	a := alerts[0]
+	text := fmt.Sprintf("Session budget: $%.2f / $%.2f", a.Spent, a.Limit)
+	if a.Exceeded {
+		return alertColor(severityMajor, text)
+	}
+	return alertColor(severityMinor, text)
+}
+
 // serviceChecker fetches and caches cloud service status pages.
 type serviceChecker struct {
 	client   *http.Client
@@ -222,7 +293,7 @@ type serviceChecker struct {
 
 func newServiceChecker(cacheDir string, client *http.Client) *serviceChecker {
 	if client == nil {
-		client = &http.Client{Timeout: 2 * time.Second}
+		client = platform.HTTPClient(2 * time.Second)
 	}
 	return &serviceChecker{client: client, cacheDir: cacheDir}
 }
@@ -476,19 +547,11 @@ func (sc *serviceChecker) azureOnset(onsetFile string) time.Time {
 
 // ccReserveWidth returns the terminal columns reserved by CC's autocompact indicator,
 // or 0 if the indicator is not active (context usage below threshold).
-func ccReserveWidth(inputJSON []byte, threshold float64) int {
-	var data struct {
-		ContextWindow struct {
-			UsedPercentage float64 `json:"used_percentage"`
-		} `json:"context_window"`
-	}
-	if len(inputJSON) > 0 {
-		_ = json.Unmarshal(inputJSON, &data)
-	}
-	if data.ContextWindow.UsedPercentage < threshold {
+func ccReserveWidth(usedPct, threshold float64) int {
+	if usedPct < threshold {
 		return 0
 	}
-	left := int(math.Round(100 - data.ContextWindow.UsedPercentage))
+	left := int(math.Round(100 - usedPct))
 	return len(fmt.Sprintf("  Context left until auto-compact: %d%%", left))
 }
 
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cost"
 )
 
 // --- formatDuration ---
@@ -334,15 +336,13 @@ const testModelCostTokens = "🤖 Opus 4.6 | 💰 $16.84 session | 🧠 5,803 (3
 // --- ccReserveWidth ---
 
 func TestCCReserveWidth_BelowThreshold(t *testing.T) {
-	inputJSON := []byte(`{"context_window":{"used_percentage":50}}`)
-	if got := ccReserveWidth(inputJSON, 95.0); got != 0 {
+	if got := ccReserveWidth(50, 95.0); got != 0 {
 		t.Errorf("expected 0 below threshold, got %d", got)
 	}
 }
 
 func TestCCReserveWidth_AtThreshold(t *testing.T) {
-	inputJSON := []byte(`{"context_window":{"used_percentage":96}}`)
-	got := ccReserveWidth(inputJSON, 95.0)
+	got := ccReserveWidth(96, 95.0)
 	// "  Context left until auto-compact: 4%" = 37 chars
 	if got != 37 {
 		t.Errorf("expected 37, got %d", got)
@@ -615,3 +615,25 @@ func TestRender_BasePassedThrough(t *testing.T) {
 		t.Errorf("expected base line in output, got %q", out)
 	}
 }
+
+// --- budgetAlert ---
+
+func TestBudgetAlert(t *testing.T) {
+	input := func(c float64) []byte {
+		return []byte(fmt.Sprintf(`{"cost":{"total_cost_usd":%g}}`, c))
+	}
+	budget := cost.Budget{Monthly: 1, PerSession: 5}
+
+	if got := budgetAlert(input(2), budget); got != "" {
+		t.Errorf("under threshold: got %q, want empty", got)
+	}
+	if got := budgetAlert(input(4.5), budget); !strings.Contains(got, ansiYellow) || !strings.Contains(got, "$4.50 / $5.00") {
+		t.Errorf("warning: got %q", got)
+	}
+	if got := budgetAlert(input(6), budget); !strings.Contains(got, ansiRed) {
+		t.Errorf("exceeded: got %q, want red alert", got)
+	}
+	if got := budgetAlert(input(100), cost.Budget{Monthly: 1}); got != "" {
+		t.Errorf("no per-session budget: got %q, want empty", got)
+	}
+}
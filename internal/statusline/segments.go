@@ -0,0 +1,290 @@
+package statusline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Theme names accepted by --theme.
+const (
+	themeDefault = "default" // icons, " | " separators, colored thresholds
+	themeMinimal = "minimal" // no icons, " · " separators, colored thresholds
+	themeMono    = "mono"    // icons, no colors; thresholds marked with ! and !!
+)
+
+var themeNames = []string{themeDefault, themeMinimal, themeMono}
+
+// theme controls how segments are decorated and joined.
+type theme struct {
+	icons map[string]string
+	sep   string
+	color bool
+}
+
+var themes = map[string]theme{
+	themeDefault: {
+		icons: map[string]string{segModel: "🤖", segCost: "💰", segToday: "📅", segContext: "🧠", segGit: "🌿", segDuration: "🕐"},
+		sep:   " | ",
+		color: true,
+	},
+	themeMinimal: {sep: " · ", color: true},
+	themeMono: {
+		icons: map[string]string{segModel: "🤖", segCost: "💰", segToday: "📅", segContext: "🧠", segGit: "🌿", segDuration: "🕐"},
+		sep:   " | ",
+	},
+}
+
+// themeName returns the configured theme, or "default".
+func (o Options) themeName() string {
+	if o.Theme == "" {
+		return themeDefault
+	}
+	return o.Theme
+}
+
+func (o Options) theme() theme {
+	if t, ok := themes[o.Theme]; ok {
+		return t
+	}
+	return themes[themeDefault]
+}
+
+// level is how close a value is to its thresholds.
+type level int
+
+const (
+	levelOK level = iota
+	levelWarn
+	levelCritical
+)
+
+// levelOf returns the level of v for the given thresholds. A zero warn or
+// critical threshold is never reached.
+func levelOf(v, warn, critical float64) level {
+	switch {
+	case critical > 0 && v >= critical:
+		return levelCritical
+	case warn > 0 && v >= warn:
+		return levelWarn
+	default:
+		return levelOK
+	}
+}
+
+// paint colors text by level, or marks it with "!" / "!!" when the theme has
+// no colors.
+func (t theme) paint(text string, l level) string {
+	if !t.color {
+		switch l {
+		case levelWarn:
+			return text + " !"
+		case levelCritical:
+			return text + " !!"
+		}
+		return text
+	}
+	switch l {
+	case levelWarn:
+		return ansiYellow + text + ansiReset
+	case levelCritical:
+		return ansiRed + text + ansiReset
+	}
+	return ansiGreen + text + ansiReset
+}
+
+// decorate prefixes text with the theme's icon for segment name.
+func (t theme) decorate(name, text string) string {
+	if icon := t.icons[name]; icon != "" {
+		return icon + " " + text
+	}
+	return text
+}
+
+// session holds the fields of Claude Code's statusline JSON that the
+// built-in segments use.
+type session struct {
+	Model         string
+	Cost          float64
+	DurationMS    float64
+	ContextTokens int64
+	ContextPct    float64
+	Dir           string
+	ProjectDir    string
+	Branch        string
+	Today         float64 // today's spend in ProjectDir, when TodayKnown
+	TodayKnown    bool
+}
+
+// parseSession extracts the session from Claude Code's statusline JSON.
+// Context usage is computed from the transcript when it can be read, and
+// otherwise taken from context_window.used_percentage.
+func parseSession(inputJSON []byte) session {
+	var data struct {
+		Cwd            string `json:"cwd"`
+		TranscriptPath string `json:"transcript_path"`
+		Model          struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"display_name"`
+		} `json:"model"`
+		Workspace struct {
+			CurrentDir string `json:"current_dir"`
+			ProjectDir string `json:"project_dir"`
+		} `json:"workspace"`
+		Cost struct {
+			TotalCostUSD    float64 `json:"total_cost_usd"`
+			TotalDurationMS float64 `json:"total_duration_ms"`
+		} `json:"cost"`
+		ContextWindow struct {
+			UsedPercentage    float64 `json:"used_percentage"`
+			ContextWindowSize int64   `json:"context_window_size"`
+		} `json:"context_window"`
+	}
+	if len(inputJSON) > 0 {
+		_ = json.Unmarshal(inputJSON, &data)
+	}
+	s := session{
+		Model:      data.Model.DisplayName,
+		Cost:       data.Cost.TotalCostUSD,
+		DurationMS: data.Cost.TotalDurationMS,
+		ContextPct: data.ContextWindow.UsedPercentage,
+		Dir:        data.Workspace.CurrentDir,
+	}
+	if s.Dir == "" {
+		s.Dir = data.Cwd
+	}
+	if s.ProjectDir = data.Workspace.ProjectDir; s.ProjectDir == "" {
+		s.ProjectDir = s.Dir
+	}
+	if tokens := transcriptContextTokens(data.TranscriptPath); tokens > 0 {
+		s.ContextTokens = tokens
+		s.ContextPct = 100 * float64(tokens) / float64(contextSize(data.ContextWindow.ContextWindowSize, data.Model.ID))
+	}
+	return s
+}
+
+// lineParts renders each configured segment for s and returns the non-empty
+// parts along with the theme's separator. ccusage is the base line produced
+// by ccusage (or the jq fallback); perSession is the per-session budget used
+// for default cost thresholds.
+func lineParts(s session, ccusage, reset string, opts Options, perSession float64) ([]string, string) {
+	t := opts.theme()
+	var parts []string
+	for _, name := range opts.segments() {
+		text := ""
+		switch name {
+		case segCcusage:
+			text = strings.TrimRight(ccusage, "\n")
+			if s.TodayKnown {
+				text = replaceToday(text, s.Today)
+			}
+			if !t.color {
+				text = ansiRE.ReplaceAllString(text, "")
+			}
+		case segModel:
+			if text = s.Model; text == "" {
+				text = "Claude"
+			}
+			text = t.decorate(name, text)
+		case segCost:
+			warn, critical := opts.Thresholds.costLevels(perSession)
+			text = t.decorate(name, t.paint(fmt.Sprintf("$%.2f", s.Cost), levelOf(s.Cost, warn, critical)))
+		case segToday:
+			if s.TodayKnown {
+				text = t.decorate(name, fmt.Sprintf("$%.2f today", s.Today))
+			}
+		case segContext:
+			warn, critical := opts.Thresholds.contextLevels()
+			text = t.decorate(name, t.paint(fmt.Sprintf("%.0f%% ctx", s.ContextPct), levelOf(s.ContextPct, warn, critical)))
+		case segGit:
+			if s.Branch != "" {
+				text = t.decorate(name, s.Branch)
+			}
+		case segDuration:
+			if s.DurationMS > 0 {
+				text = t.decorate(name, formatMinutes(int(s.DurationMS/60000)))
+			}
+		case segReset:
+			text = reset
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return parts, t.sep
+}
+
+// formatMinutes formats a number of minutes as "Xh Ym" or "Ym".
+func formatMinutes(mins int) string {
+	if mins < 60 {
+		return strconv.Itoa(mins) + "m"
+	}
+	return strconv.Itoa(mins/60) + "h " + strconv.Itoa(mins%60) + "m"
+}
+
+// fitParts joins parts with sep, dropping segments from the end until the
+// line fits in maxW display columns, then truncating the first segment.
+func fitParts(parts []string, sep string, maxW int) string {
+	if maxW < 20 {
+		maxW = 20
+	}
+	for n := len(parts); n > 0; n-- {
+		line := strings.Join(parts[:n], sep)
+		if displayWidth(ansiRE.ReplaceAllString(line, "")) <= maxW {
+			return line
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return truncateDisplay(ansiRE.ReplaceAllString(parts[0], ""), maxW)
+}
+
+// gitBranch returns the branch checked out in the repository containing dir,
+// the short commit hash for a detached HEAD, or "" outside a repository. It
+// reads .git/HEAD directly so rendering never spawns git.
+func gitBranch(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			if !info.IsDir() {
+				// Worktrees and submodules use a "gitdir: <path>" file.
+				data, err := os.ReadFile(gitPath)
+				if err != nil {
+					return ""
+				}
+				gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !ok {
+					return ""
+				}
+				if !filepath.IsAbs(gitdir) {
+					gitdir = filepath.Join(dir, gitdir)
+				}
+				gitPath = gitdir
+			}
+			head, err := os.ReadFile(filepath.Join(gitPath, "HEAD"))
+			if err != nil {
+				return ""
+			}
+			ref := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+				return branch
+			}
+			if len(ref) >= 7 {
+				return ref[:7]
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
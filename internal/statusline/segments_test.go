@@ -0,0 +1,163 @@
+package statusline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+)
+
+// parseCustom parses args with only the customization flags declared.
+func parseCustom(args []string, base Options) (Options, bool, error) {
+	f := cli.New("statusline preview", "")
+	custom := customFlags(f, base)
+	if _, err := f.Parse(args); err != nil {
+		return base, false, err
+	}
+	return custom()
+}
+
+func TestCustomFlags(t *testing.T) {
+	saved := Options{Theme: themeMono, Thresholds: Thresholds{ContextWarn: 60}}
+	opts, changed, err := parseCustom([]string{"--segments", "model, cost,git", "--cost-warn=2.5"}, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Options{Segments: []string{"model", "cost", "git"}, Theme: themeMono, Thresholds: Thresholds{ContextWarn: 60, CostWarn: 2.5}}
+	if !changed || !reflect.DeepEqual(opts, want) {
+		t.Errorf("customFlags() = %+v, %v", opts, changed)
+	}
+
+	if opts, _, _ := parseCustom([]string{"--segments", "default", "--theme", "default"}, want); !opts.isDefault() {
+		t.Errorf("--segments default --theme default should restore the default layout, got %+v", opts)
+	}
+	if _, changed, _ := parseCustom(nil, saved); changed {
+		t.Error("no flags is not a customization")
+	}
+
+	for _, args := range [][]string{
+		{"--segments", "model,tokens"},
+		{"--segments", "model,model"},
+		{"--theme", "neon"},
+		{"--cost-warn", "five"},
+		{"--context-warn", "95"},
+		{"--cost-warn", "5", "--cost-critical", "2"},
+		{"--theme"},
+		{"--force"},
+	} {
+		if _, _, err := parseCustom(args, Options{}); err == nil {
+			t.Errorf("customFlags(%q) should fail", args)
+		}
+	}
+}
+
+func TestLineParts(t *testing.T) {
+	s := session{Model: "Opus", Cost: 4.2, DurationMS: 75 * 60000, ContextPct: 92, Branch: "main"}
+	segs := []string{"model", "cost", "context", "git", "duration", "reset"}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		perSession float64
+		want       string
+	}{
+		{
+			name: "default theme",
+			opts: Options{Segments: segs},
+			want: "🤖 Opus | 💰 " + ansiGreen + "$4.20" + ansiReset + " | 🧠 " + ansiRed + "92% ctx" + ansiReset +
+				" | 🌿 main | 🕐 1h 15m | resets in 3d",
+		},
+		{
+			name:       "cost thresholds from per-session budget",
+			opts:       Options{Segments: []string{"cost"}},
+			perSession: 5,
+			want:       "💰 " + ansiYellow + "$4.20" + ansiReset,
+		},
+		{
+			name: "minimal theme",
+			opts: Options{Segments: []string{"model", "git"}, Theme: themeMinimal},
+			want: "Opus · main",
+		},
+		{
+			name: "mono theme marks thresholds",
+			opts: Options{Segments: []string{"cost", "context", "ccusage"}, Theme: themeMono, Thresholds: Thresholds{CostWarn: 1, CostCritical: 4, ContextCritical: 95}},
+			want: "💰 $4.20 !! | 🧠 92% ctx ! | Opus $1",
+		},
+	}
+	for _, tt := range tests {
+		parts, sep := lineParts(s, "Opus "+ansiBold+"$1"+ansiReset, "resets in 3d", tt.opts, tt.perSession)
+		if got := strings.Join(parts, sep); got != tt.want {
+			t.Errorf("%s:\n got  %q\n want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Segments without data are left out.
+	parts, _ := lineParts(session{}, "", "", Options{Segments: []string{"model", "git", "duration", "reset", "ccusage"}}, 0)
+	if !reflect.DeepEqual(parts, []string{"🤖 Claude"}) {
+		t.Errorf("empty session parts = %q", parts)
+	}
+}
+
+func TestFitParts(t *testing.T) {
+	parts := []string{"🤖 Opus", ansiGreen + "$0.42" + ansiReset, "feature/a-very-long-branch-name"}
+	if got := fitParts(parts, " | ", 80); got != strings.Join(parts, " | ") {
+		t.Errorf("fitParts() should keep a line that fits, got %q", got)
+	}
+	if got := fitParts(parts, " | ", 30); got != "🤖 Opus | "+ansiGreen+"$0.42"+ansiReset {
+		t.Errorf("fitParts() should drop trailing segments, got %q", got)
+	}
+	if got := fitParts([]string{strings.Repeat("x", 40)}, " | ", 20); displayWidth(got) != 20 || !strings.HasSuffix(got, "…") {
+		t.Errorf("fitParts() should truncate the first segment, got %q", got)
+	}
+}
+
+func TestGitBranch(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/feature/x\n")
+	sub := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitBranch(sub); got != "feature/x" {
+		t.Errorf("gitBranch(subdir) = %q", got)
+	}
+
+	worktree := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "worktrees", "wt", "HEAD"), "0123456789abcdef0123456789abcdef01234567\n")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+filepath.Join(repo, ".git", "worktrees", "wt")+"\n")
+	if got := gitBranch(worktree); got != "0123456" {
+		t.Errorf("gitBranch(detached worktree) = %q", got)
+	}
+
+	if got := gitBranch(""); got != "" {
+		t.Errorf("gitBranch(\"\") = %q", got)
+	}
+}
+
+func TestPreviewLines(t *testing.T) {
+	lines := previewLines(Options{Segments: []string{"context"}, Theme: themeMono}, 0)
+	want := []string{
+		"  normal    🧠 35% ctx",
+		"  warning   🧠 70% ctx !",
+		"  critical  🧠 90% ctx !!",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("previewLines() = %q, want %q", lines, want)
+	}
+	if lines := previewLines(Options{}, 0); len(lines) != 1 || !strings.HasPrefix(lines[0], sampleCcusage+" | resets") {
+		t.Errorf("previewLines(default) = %q", lines)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
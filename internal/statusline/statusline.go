@@ -1,6 +1,7 @@
 // Package statusline implements the "statusline" command, which configures the
 // Claude Code status bar to display session cost, context usage, model name,
-// and weekly subscription reset countdown.
+// and weekly subscription reset countdown. Segments, color thresholds, and the
+// theme are configurable with flags and previewed with "statusline preview".
 package statusline
 
 import (
@@ -8,41 +9,54 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
 // Run is the entry point for the statusline command.
 // args is os.Args[2:] (everything after "statusline").
 func Run(args []string) error {
-	if len(args) > 0 && args[0] == "render" {
-		return RunRender(args[1:])
-	}
-	force := false
-	for _, a := range args {
-		if a == "--force" {
-			force = true
-		}
-	}
-	return configureTo(os.Stdout, force)
+	return RunTo(os.Stdout, args)
 }
 
 // RunTo is like Run but writes all output to w instead of os.Stdout.
 func RunTo(w io.Writer, args []string) error {
-	if len(args) > 0 && args[0] == "render" {
-		return RunRender(args[1:])
+	if len(args) > 0 {
+		switch args[0] {
+		case "render":
+			return RunRender(args[1:])
+		case "preview":
+			return previewTo(w, args[1:])
+		}
+	}
+	saved, err := LoadOptions()
+	if err != nil {
+		return err
 	}
 	force := false
-	for _, a := range args {
-		if a == "--force" {
-			force = true
-		}
+	f := cli.New("statusline", "statusline [--force] [--segments <list>] [--theme <name>] [threshold flags]")
+	f.BoolVar(&force, "--force", "Overwrite existing statusLine configuration")
+	custom := customFlags(f, saved)
+	positional, err := f.Parse(args)
+	if err != nil {
+		return err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return err
+	}
+	opts, changed, err := custom()
+	if err != nil {
+		return err
 	}
-	return configureTo(w, force)
+	return configureTo(w, force, opts, changed)
 }
 
-// configureTo writes ~/.claude/statusline.sh and registers it in ~/.claude/settings.json.
-func configureTo(w io.Writer, force bool) error {
+// configureTo writes ~/.claude/statusline.sh for opts and registers it in
+// ~/.claude/settings.json. When changed is set, opts are saved and an existing
+// claude-workspace statusline is rewritten without --force.
+func configureTo(w io.Writer, force bool, opts Options, changed bool) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("getting home directory: %w", err)
@@ -55,50 +69,93 @@ func configureTo(w io.Writer, force bool) error {
 
 	settingsPath := filepath.Join(claudeDir, "settings.json")
 
-	var settings map[string]interface{}
-	if platform.FileExists(settingsPath) {
-		if err := platform.ReadJSONFile(settingsPath, &settings); err != nil {
-			return fmt.Errorf("reading settings: %w", err)
+	return platform.WithFileLock(settingsPath, func() error {
+		var settings map[string]interface{}
+		if platform.FileExists(settingsPath) {
+			if err := platform.ReadJSONFile(settingsPath, &settings); err != nil {
+				return fmt.Errorf("reading settings: %w", err)
+			}
+		} else {
+			settings = make(map[string]interface{})
 		}
-	} else {
-		settings = make(map[string]interface{})
-	}
 
-	if !force {
-		if _, exists := settings["statusLine"]; exists {
-			platform.PrintOK(w, "statusLine already configured in ~/.claude/settings.json (use --force to overwrite)")
-			return nil
+		scriptPath := filepath.Join(claudeDir, "statusline.sh")
+		if existing, exists := settings["statusLine"]; exists && !force {
+			if !changed || !isManaged(existing, scriptPath) {
+				platform.PrintOK(w, "statusLine already configured in ~/.claude/settings.json (use --force to overwrite)")
+				return nil
+			}
 		}
-	}
 
-	script, err := platform.ReadGlobalAsset("statusline.sh")
-	if err != nil {
-		return fmt.Errorf("reading statusline template: %w", err)
-	}
+		if changed {
+			if err := saveOptions(opts); err != nil {
+				return fmt.Errorf("saving statusline options: %w", err)
+			}
+		}
 
-	scriptPath := filepath.Join(claudeDir, "statusline.sh")
-	if err := writeWrapperScript(scriptPath, script); err != nil {
-		return fmt.Errorf("writing statusline script: %w", err)
-	}
-	fmt.Fprintf(w, "  Script written: %s\n", scriptPath)
+		// The ccusage segment needs the template's runtime detection; the
+		// built-in segments are rendered entirely by claude-workspace.
+		script := []byte(builtinScript)
+		if opts.has(segCcusage) {
+			if script, err = platform.ReadGlobalAsset("statusline.sh"); err != nil {
+				return fmt.Errorf("reading statusline template: %w", err)
+			}
+		}
 
-	cmd := "bash " + scriptPath
-	settings["statusLine"] = map[string]interface{}{
-		"type":    "command",
-		"command": cmd,
-		"padding": 0,
-	}
+		if err := writeWrapperScript(scriptPath, script); err != nil {
+			return fmt.Errorf("writing statusline script: %w", err)
+		}
+		fmt.Fprintf(w, "  Script written: %s\n", scriptPath)
 
-	if err := platform.WriteJSONFile(settingsPath, settings); err != nil {
-		return fmt.Errorf("writing settings: %w", err)
-	}
+		cmd := "bash " + scriptPath
+		settings["statusLine"] = map[string]interface{}{
+			"type":    "command",
+			"command": cmd,
+			"padding": 0,
+		}
 
-	platform.PrintOK(w, "statusLine configured in ~/.claude/settings.json")
-	fmt.Fprintln(w, "  Restart Claude Code to activate the statusline.")
-	return nil
+		if err := platform.WriteSettingsFile(settingsPath, settings); err != nil {
+			return fmt.Errorf("writing settings: %w", err)
+		}
+
+		platform.PrintOK(w, "statusLine configured in ~/.claude/settings.json")
+		fmt.Fprintf(w, "  Segments: %s  Theme: %s\n", strings.Join(opts.segments(), ", "), opts.themeName())
+		fmt.Fprintln(w, "  Restart Claude Code to activate the statusline.")
+		return nil
+	})
+}
+
+// isManaged reports whether the statusLine setting runs the script at
+// scriptPath written by this command.
+func isManaged(statusLine interface{}, scriptPath string) bool {
+	m, ok := statusLine.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	cmd, _ := m["command"].(string)
+	return cmd == "bash "+scriptPath
 }
 
 // writeWrapperScript writes the statusline shell script content to the given path.
 func writeWrapperScript(path string, content []byte) error {
 	return os.WriteFile(path, content, 0755)
 }
+
+// builtinScript is written when the ccusage segment is not selected, so each
+// refresh skips the ccusage runtime and renders entirely in claude-workspace.
+const builtinScript = `#!/usr/bin/env bash
+# Managed by claude-workspace — re-run "claude-workspace statusline" to regenerate.
+# Segments, thresholds, and theme are read from ~/.claude-workspace/config.json.
+
+input=$(cat)
+
+if command -v claude-workspace &>/dev/null; then
+    printf '%s' "$input" | \
+        COLS="$(tput cols 2>/dev/null || echo 120)" \
+        claude-workspace statusline render
+else
+    printf '%s' "$input" | jq -r \
+        '"\(.model.display_name) | $\(.cost.total_cost_usd | . * 1000 | round / 1000) | \(.context_window.used_percentage // 0 | round)% ctx"' \
+        2>/dev/null
+fi
+`
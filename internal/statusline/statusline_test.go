@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"testing/fstest"
@@ -68,7 +69,7 @@ func TestConfigure_WritesScriptAndSettings(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 
-	if err := configureTo(io.Discard, false); err != nil {
+	if err := configureTo(io.Discard, false, Options{}, false); err != nil {
 		t.Fatalf("configure: %v", err)
 	}
 
@@ -86,3 +87,56 @@ func TestConfigure_WritesScriptAndSettings(t *testing.T) {
 		t.Errorf("settings.json does not reference statusline.sh: %s", data)
 	}
 }
+
+func TestRunTo_CustomSegments(t *testing.T) {
+	platform.GlobalFS = fstest.MapFS{
+		"statusline.sh": {Data: []byte("#!/usr/bin/env bash\nbun x ccusage statusline\n"), Mode: 0755},
+	}
+	t.Cleanup(func() { platform.GlobalFS = nil })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	scriptPath := filepath.Join(home, ".claude", "statusline.sh")
+
+	if err := RunTo(io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(scriptPath); !strings.Contains(string(data), "ccusage") {
+		t.Errorf("default statusline should use the ccusage template, got:\n%s", data)
+	}
+
+	// Customizing rewrites our own statusline without --force.
+	if err := RunTo(io.Discard, []string{"--segments", "model,context,git", "--theme", "minimal"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(scriptPath)
+	if strings.Contains(string(data), "ccusage") || !strings.Contains(string(data), "claude-workspace statusline render") {
+		t.Errorf("built-in segments should not run ccusage, got:\n%s", data)
+	}
+	opts, err := LoadOptions()
+	if err != nil || !reflect.DeepEqual(opts, Options{Segments: []string{"model", "context", "git"}, Theme: "minimal"}) {
+		t.Errorf("LoadOptions() = %+v, %v", opts, err)
+	}
+
+	// Preview does not save its flags.
+	if err := RunTo(io.Discard, []string{"preview", "--theme", "mono"}); err != nil {
+		t.Fatal(err)
+	}
+	if opts, _ := LoadOptions(); opts.Theme != "minimal" {
+		t.Errorf("preview should not save options, theme = %q", opts.Theme)
+	}
+
+	// A statusLine configured by someone else needs --force.
+	settingsPath := filepath.Join(home, ".claude", "settings.json")
+	if err := platform.WriteJSONFile(settingsPath, map[string]interface{}{"statusLine": map[string]interface{}{"type": "command", "command": "my-statusline"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunTo(io.Discard, []string{"--theme", "mono"}); err != nil {
+		t.Fatal(err)
+	}
+	if opts, _ := LoadOptions(); opts.Theme != "minimal" {
+		t.Errorf("options should not change when the statusLine is not ours, theme = %q", opts.Theme)
+	}
+	if err := RunTo(io.Discard, []string{"--bogus"}); err == nil {
+		t.Error("unknown flags should fail")
+	}
+}
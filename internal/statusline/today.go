@@ -0,0 +1,73 @@
+package statusline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cost"
+)
+
+// projectSpendTTL is how long today's per-project spend is cached. Working
+// it out takes a full ccusage scan, far too slow for every refresh.
+const projectSpendTTL = 5 * time.Minute
+
+// projectSpendTimeout bounds the ccusage scan run when the cache is stale.
+const projectSpendTimeout = 10 * time.Second
+
+// loadProjectSpend returns spend by project since a day; tests replace it.
+var loadProjectSpend = cost.ProjectSpend
+
+// projectSpendCache is the cached result of loadProjectSpend for one day.
+type projectSpendCache struct {
+	Date     string             `json:"date"` // YYYYMMDD
+	Projects []cost.ProjectCost `json:"projects"`
+}
+
+// projectToday returns today's spend in project across all of its sessions,
+// from a cache in cacheDir refreshed every projectSpendTTL. When the refresh
+// fails, the cached figure for today is used; ok is false when there is none.
+func projectToday(cacheDir, project string, now time.Time) (spend float64, ok bool) {
+	if project == "" {
+		return 0, false
+	}
+	today := now.Format("20060102")
+	path := filepath.Join(cacheDir, "project-spend.json")
+
+	var cached projectSpendCache
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil && cached.Date == today {
+		if info, err := os.Stat(path); err == nil && now.Sub(info.ModTime()) < projectSpendTTL {
+			return cost.SpendFor(cached.Projects, project), true
+		}
+	} else {
+		cached = projectSpendCache{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), projectSpendTimeout)
+	defer cancel()
+	costs, err := loadProjectSpend(ctx, today)
+	if err != nil {
+		if cached.Date == today {
+			return cost.SpendFor(cached.Projects, project), true
+		}
+		return 0, false
+	}
+	if data, err := json.Marshal(projectSpendCache{Date: today, Projects: costs}); err == nil {
+		_ = os.MkdirAll(cacheDir, 0755)
+		_ = os.WriteFile(path, data, 0644)
+	}
+	return cost.SpendFor(costs, project), true
+}
+
+// todayRE matches the "$1.23 today" part of ccusage's cost segment.
+var todayRE = regexp.MustCompile(`\$[0-9][0-9,]*(?:\.[0-9]+)? today`)
+
+// replaceToday swaps ccusage's spend for today, which covers every project,
+// for spend, the current project's.
+func replaceToday(line string, spend float64) string {
+	return todayRE.ReplaceAllLiteralString(line, fmt.Sprintf("$%.2f today", spend))
+}
@@ -0,0 +1,82 @@
+package statusline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/cost"
+)
+
+func TestReplaceToday(t *testing.T) {
+	line := "🤖 Opus | 💰 $0.42 session / $1,234.50 today / $3.10 block (2h left)"
+	want := "🤖 Opus | 💰 $0.42 session / $7.25 today / $3.10 block (2h left)"
+	if got := replaceToday(line, 7.25); got != want {
+		t.Errorf("replaceToday() =\n %q\nwant\n %q", got, want)
+	}
+	if got := replaceToday("🤖 Opus | 💰 $0.42 session", 7.25); got != "🤖 Opus | 💰 $0.42 session" {
+		t.Errorf("line without today changed: %q", got)
+	}
+}
+
+func TestProjectToday(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	calls := 0
+	var loadErr error
+	saved := loadProjectSpend
+	t.Cleanup(func() { loadProjectSpend = saved })
+	loadProjectSpend = func(_ context.Context, since string) ([]cost.ProjectCost, error) {
+		calls++
+		if since != now.Format("20060102") {
+			t.Errorf("since = %q, want today", since)
+		}
+		return []cost.ProjectCost{{Project: "/srv/api", Cost: 2}, {Project: "/srv/web", Cost: 3}}, loadErr
+	}
+
+	if got, ok := projectToday(dir, "/srv/web", now); !ok || got != 3 || calls != 1 {
+		t.Errorf("first call = %v, %v after %d loads", got, ok, calls)
+	}
+	// A fresh cache answers without another scan.
+	if got, ok := projectToday(dir, "/srv/api", now); !ok || got != 2 || calls != 1 {
+		t.Errorf("cached call = %v, %v after %d loads", got, ok, calls)
+	}
+	if _, ok := projectToday(dir, "", now); ok {
+		t.Error("empty project should be unknown")
+	}
+
+	// A stale cache is refreshed, and kept when the refresh fails.
+	path := filepath.Join(dir, "project-spend.json")
+	old := now.Add(-2 * projectSpendTTL)
+	os.Chtimes(path, old, old)
+	loadErr = errors.New("npx not found")
+	if got, ok := projectToday(dir, "/srv/web", now); !ok || got != 3 || calls != 2 {
+		t.Errorf("failed refresh = %v, %v after %d loads", got, ok, calls)
+	}
+
+	// Nothing cached for today and no ccusage: unknown.
+	os.Remove(path)
+	if _, ok := projectToday(dir, "/srv/web", now); ok {
+		t.Error("failed load without a cache should be unknown")
+	}
+}
+
+func TestLineParts_Today(t *testing.T) {
+	s := session{Model: "Opus", Today: 3.5, TodayKnown: true}
+	opts := Options{Segments: []string{"model", "today", "ccusage"}}
+	parts, sep := lineParts(s, "💰 $0.42 session / $9.99 today", "", opts, 0)
+	want := "🤖 Opus | 📅 $3.50 today | 💰 $0.42 session / $3.50 today"
+	if got := strings.Join(parts, sep); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	s.TodayKnown = false
+	parts, _ = lineParts(s, "", "", opts, 0)
+	if len(parts) != 1 {
+		t.Errorf("unknown spend should drop the segment, got %q", parts)
+	}
+}
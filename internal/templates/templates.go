@@ -0,0 +1,435 @@
+// Package templates fetches remote project templates for "attach --template":
+// a git repository (optionally at a ref) or an https .tar.gz archive whose
+// layout mirrors the embedded _template/project directory. Fetched templates
+// are cached under ~/.claude-workspace/templates and used in place of the
+// embedded assets.
+package templates
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Source kinds.
+const (
+	KindGit     = "git"
+	KindTarball = "tarball"
+)
+
+// maxArchiveSize caps the size of a downloaded template archive.
+const maxArchiveSize = 100 << 20
+
+// metadataFile records where a cached tarball template came from.
+const metadataFile = ".claude-workspace-template.json"
+
+// httpClient downloads tarball templates. Tests replace it.
+var httpClient = platform.HTTPClient(5 * time.Minute)
+
+var (
+	sha256Re = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	commitRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// Source is a parsed --template value.
+type Source struct {
+	Kind string // KindGit or KindTarball
+	URL  string // repository or archive URL
+	Ref  string // git branch, tag, or commit; empty for the remote's default branch
+}
+
+// String returns the source in --template syntax.
+func (s Source) String() string {
+	if s.Ref != "" {
+		return s.URL + "@" + s.Ref
+	}
+	return s.URL
+}
+
+// Options controls verification of a fetched template.
+type Options struct {
+	SHA256          string // expected sha256 of a tarball archive, in hex
+	VerifySignature bool   // require a valid signature on the git commit (git verify-commit)
+}
+
+// Template is a fetched template on disk.
+type Template struct {
+	Source   Source
+	Dir      string // project template root: contains .claude/ and usually .mcp.json
+	Revision string // git commit or archive sha256
+	Assets   string // directory attach --symlink extracts this template to
+}
+
+// FS returns the template as a filesystem to use in place of platform.FS.
+func (t *Template) FS() fs.FS {
+	return os.DirFS(t.Dir)
+}
+
+// ParseSource parses a --template value. Values whose path ends in .tar.gz or
+// .tgz are https archives; anything else is a git URL, optionally followed by
+// "@<ref>" (for example git@github.com:org/assets.git@v2).
+func ParseSource(s string) (Source, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Source{}, fmt.Errorf("--template requires a git URL or https .tar.gz URL")
+	}
+	if u, err := url.Parse(s); err == nil && (u.Scheme == "http" || u.Scheme == "https") &&
+		(strings.HasSuffix(u.Path, ".tar.gz") || strings.HasSuffix(u.Path, ".tgz")) {
+		if u.Scheme != "https" {
+			return Source{}, fmt.Errorf("template archives must be fetched over https: %s", s)
+		}
+		return Source{Kind: KindTarball, URL: s}, nil
+	}
+
+	src := Source{Kind: KindGit, URL: s}
+	if i := strings.LastIndex(s, "@"); i > 0 {
+		repo, ref := s[:i], s[i+1:]
+		// "git@host:org/repo.git" and "https://user@host/repo" have an "@"
+		// before the path; a ref separator only follows a repository path.
+		rest := repo
+		if _, after, ok := strings.Cut(repo, "://"); ok {
+			rest = after
+		}
+		if ref != "" && strings.ContainsAny(rest, "/:") && !strings.Contains(ref, ":") {
+			src.URL, src.Ref = repo, ref
+		}
+	}
+	return src, nil
+}
+
+// CacheDir returns the template cache directory (~/.claude-workspace/templates).
+func CacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude-workspace", "templates"), nil
+}
+
+// cacheKey names the cache directory for src: a readable repository or
+// archive name plus a hash of the full source, so different refs and
+// checksums never share a directory.
+func cacheKey(src Source, opts Options) string {
+	name := path.Base(strings.TrimSuffix(strings.ReplaceAll(src.URL, ":", "/"), "/"))
+	if u, err := url.Parse(src.URL); err == nil && u.Path != "" && src.Kind == KindTarball {
+		name = path.Base(u.Path)
+	}
+	for _, suffix := range []string{".git", ".tar.gz", ".tgz"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, name)
+	sum := sha256.Sum256([]byte(src.Kind + "\n" + src.String() + "\n" + opts.SHA256))
+	return name + "-" + hex.EncodeToString(sum[:6])
+}
+
+// Fetch downloads or updates the template for src in the cache and verifies
+// it. A git template whose fetch fails falls back to the cached checkout with a
+// warning, so attach keeps working offline.
+func Fetch(w io.Writer, src Source, opts Options) (*Template, error) {
+	opts.SHA256 = strings.ToLower(opts.SHA256)
+	if err := checkOptions(src, opts); err != nil {
+		return nil, err
+	}
+	cache, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(cache, cacheKey(src, opts))
+	if src.Kind == KindTarball {
+		return fetchTarball(w, src, opts, dir)
+	}
+	return fetchGit(w, src, opts, dir)
+}
+
+// Lookup returns the cached template for src without fetching it.
+func Lookup(src Source, opts Options) (*Template, error) {
+	opts.SHA256 = strings.ToLower(opts.SHA256)
+	if err := checkOptions(src, opts); err != nil {
+		return nil, err
+	}
+	cache, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(cache, cacheKey(src, opts))
+	if !platform.FileExists(dir) {
+		return nil, fmt.Errorf("template %s is not cached; run attach --template first", src)
+	}
+	t := &Template{Source: src, Revision: opts.SHA256, Assets: dir + ".assets"}
+	if src.Kind == KindGit {
+		if rev, err := git(dir, "rev-parse", "HEAD"); err == nil {
+			t.Revision = rev
+		}
+	}
+	t.Dir, err = templateRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func checkOptions(src Source, opts Options) error {
+	switch {
+	case src.Kind == KindGit && opts.SHA256 != "":
+		return fmt.Errorf("--template-sha256 applies to archive templates; pin a git template to a commit with @<sha> instead")
+	case src.Kind == KindTarball && opts.VerifySignature:
+		return fmt.Errorf("--verify-signature applies to git templates; verify archives with --template-sha256")
+	case opts.SHA256 != "" && !sha256Re.MatchString(opts.SHA256):
+		return fmt.Errorf("--template-sha256 must be 64 hex characters")
+	}
+	return nil
+}
+
+func fetchGit(w io.Writer, src Source, opts Options, dir string) (*Template, error) {
+	if !platform.Exists("git") {
+		return nil, fmt.Errorf("git is required for git templates")
+	}
+	cached := platform.FileExists(filepath.Join(dir, ".git"))
+	if !cached {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", dir, err)
+		}
+		if _, err := git(dir, "init", "-q"); err != nil {
+			return nil, err
+		}
+		if _, err := git(dir, "remote", "add", "origin", src.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	ref := src.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	platform.PrintInfo(w, "Fetching template "+src.String())
+	_, fetchErr := git(dir, "fetch", "-q", "--depth", "1", "origin", ref)
+	if fetchErr == nil {
+		_, fetchErr = git(dir, "checkout", "-q", "--force", "--detach", "FETCH_HEAD")
+	}
+	if fetchErr != nil {
+		if _, err := git(dir, "rev-parse", "--verify", "-q", "HEAD"); !cached || err != nil {
+			if !cached {
+				_ = os.RemoveAll(dir)
+			}
+			return nil, fmt.Errorf("fetching template: %w", fetchErr)
+		}
+		platform.PrintWarn(w, fmt.Sprintf("Could not update template (%v); using cached copy", fetchErr))
+	}
+
+	rev, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if commitRe.MatchString(src.Ref) && rev != src.Ref {
+		return nil, fmt.Errorf("template checkout is at %s, expected %s", rev, src.Ref)
+	}
+	if opts.VerifySignature {
+		if _, err := git(dir, "verify-commit", "HEAD"); err != nil {
+			return nil, fmt.Errorf("template commit %s has no valid signature: %w", shortRev(rev), err)
+		}
+		platform.PrintOK(w, "Signature verified for "+shortRev(rev))
+	}
+
+	root, err := templateRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	platform.PrintOK(w, fmt.Sprintf("Template at %s", shortRev(rev)))
+	return &Template{Source: src, Dir: root, Revision: rev, Assets: dir + ".assets"}, nil
+}
+
+func fetchTarball(w io.Writer, src Source, opts Options, dir string) (*Template, error) {
+	want := opts.SHA256
+	// A verified archive never changes, so a complete cache entry is reused.
+	if want != "" && platform.FileExists(filepath.Join(dir, metadataFile)) {
+		root, err := templateRoot(dir)
+		if err == nil {
+			platform.PrintOK(w, "Using cached template "+src.URL)
+			return &Template{Source: src, Dir: root, Revision: want, Assets: dir + ".assets"}, nil
+		}
+	}
+
+	platform.PrintInfo(w, "Downloading template "+src.URL)
+	tmp, err := os.CreateTemp("", "claude-workspace-template-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	sum, err := platform.Download(httpClient, src.URL, tmp, maxArchiveSize)
+	tmp.Close()
+	if err != nil {
+		return nil, fmt.Errorf("downloading template: %w", err)
+	}
+	switch {
+	case want == "":
+		platform.PrintWarn(w, fmt.Sprintf("Archive not verified (sha256 %s). Pass --template-sha256 to pin it.", sum))
+	case sum != want:
+		return nil, fmt.Errorf("template checksum mismatch: expected %s, got %s", want, sum)
+	default:
+		platform.PrintOK(w, "Checksum verified")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(dir), ".staging-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	if err := extractTarGz(tmp.Name(), staging); err != nil {
+		return nil, err
+	}
+	if _, err := templateRoot(staging); err != nil {
+		return nil, err
+	}
+	meta, _ := json.MarshalIndent(map[string]string{
+		"url":       src.URL,
+		"sha256":    sum,
+		"fetchedAt": time.Now().UTC().Format(time.RFC3339),
+	}, "", "  ")
+	if err := os.WriteFile(filepath.Join(staging, metadataFile), append(meta, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return nil, fmt.Errorf("caching template: %w", err)
+	}
+
+	root, err := templateRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{Source: src, Dir: root, Revision: sum, Assets: dir + ".assets"}, nil
+}
+
+// extractTarGz extracts the regular files and directories of a .tar.gz into
+// dest, rejecting entries that would land outside it.
+func extractTarGz(archivePath, dest string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("opening gzip: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar: %w", err)
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			if name == "." {
+				continue
+			}
+			return fmt.Errorf("unsafe path in template archive: %s", header.Name)
+		}
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			perm := os.FileMode(0644)
+			if header.Mode&0111 != 0 {
+				perm = 0755
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, io.LimitReader(tr, maxArchiveSize))
+			out.Close()
+			if err != nil {
+				return fmt.Errorf("extracting %s: %w", name, err)
+			}
+		}
+	}
+}
+
+// templateRoot finds the project template inside a fetched tree: a project/
+// directory when the tree mirrors _template, _template/project/ when it is a
+// claude-workspace source tree, otherwise the tree itself. An archive with a
+// single top-level directory (as GitHub produces) is unwrapped.
+func templateRoot(dir string) (string, error) {
+	root := dir
+	if entries, err := os.ReadDir(dir); err == nil {
+		var dirs []os.DirEntry
+		files := 0
+		for _, e := range entries {
+			switch {
+			case e.Name() == ".git" || e.Name() == metadataFile:
+			case e.IsDir():
+				dirs = append(dirs, e)
+			default:
+				files++
+			}
+		}
+		if len(dirs) == 1 && files == 0 && dirs[0].Name() != ".claude" && dirs[0].Name() != "project" {
+			root = filepath.Join(dir, dirs[0].Name())
+		}
+	}
+	if platform.FileExists(filepath.Join(root, "project", ".claude")) {
+		root = filepath.Join(root, "project")
+	} else if platform.FileExists(filepath.Join(root, "_template", "project", ".claude")) {
+		root = filepath.Join(root, "_template", "project")
+	}
+	if !platform.FileExists(filepath.Join(root, ".claude")) {
+		return "", errors.New("template has no .claude directory (expected .claude/ or project/.claude/ at its root)")
+	}
+	return root, nil
+}
+
+func shortRev(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+func git(dir string, args ...string) (string, error) {
+	out, stderr, err := platform.RunDirWithStdinCapture(context.Background(), dir, "", nil, "git", args...)
+	if err != nil {
+		if stderr != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], stderr)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
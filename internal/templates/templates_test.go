@@ -0,0 +1,269 @@
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Source
+		wantErr bool
+	}{
+		{in: "git@github.com:org/assets.git", want: Source{Kind: KindGit, URL: "git@github.com:org/assets.git"}},
+		{in: "git@github.com:org/assets.git@v2.1", want: Source{Kind: KindGit, URL: "git@github.com:org/assets.git", Ref: "v2.1"}},
+		{in: "https://github.com/org/assets.git@release/1.0", want: Source{Kind: KindGit, URL: "https://github.com/org/assets.git", Ref: "release/1.0"}},
+		{in: "https://token@github.com/org/assets.git", want: Source{Kind: KindGit, URL: "https://token@github.com/org/assets.git"}},
+		{in: "ssh://git@host:22/assets.git", want: Source{Kind: KindGit, URL: "ssh://git@host:22/assets.git"}},
+		{in: "https://example.com/assets-v2.tar.gz", want: Source{Kind: KindTarball, URL: "https://example.com/assets-v2.tar.gz"}},
+		{in: "https://example.com/dl/assets.tgz?token=x", want: Source{Kind: KindTarball, URL: "https://example.com/dl/assets.tgz?token=x"}},
+		{in: "http://example.com/assets.tar.gz", wantErr: true},
+		{in: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSource(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSource(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSource(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := cacheKey(Source{Kind: KindGit, URL: "git@github.com:org/assets.git"}, Options{})
+	b := cacheKey(Source{Kind: KindGit, URL: "git@github.com:org/assets.git", Ref: "v2"}, Options{})
+	c := cacheKey(Source{Kind: KindTarball, URL: "https://example.com/dl/assets.tgz?x=1"}, Options{})
+	if !strings.HasPrefix(a, "assets-") || !strings.HasPrefix(c, "assets-") {
+		t.Errorf("cache keys %q, %q should start with the repository name", a, c)
+	}
+	if a == b {
+		t.Error("different refs share a cache directory")
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	sum := strings.Repeat("a", 64)
+	if err := checkOptions(Source{Kind: KindGit}, Options{SHA256: sum}); err == nil {
+		t.Error("sha256 on a git template should be rejected")
+	}
+	if err := checkOptions(Source{Kind: KindTarball}, Options{VerifySignature: true}); err == nil {
+		t.Error("--verify-signature on an archive should be rejected")
+	}
+	if err := checkOptions(Source{Kind: KindTarball}, Options{SHA256: "abc"}); err == nil {
+		t.Error("short sha256 should be rejected")
+	}
+	if err := checkOptions(Source{Kind: KindTarball}, Options{SHA256: sum}); err != nil {
+		t.Errorf("valid options rejected: %v", err)
+	}
+}
+
+func makeTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		mode := int64(0644)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0755
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestExtractTarGz_RejectsTraversal(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+	if err := os.WriteFile(archive, makeTarGz(t, map[string]string{"../escape.txt": "x"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := extractTarGz(archive, t.TempDir()); err == nil {
+		t.Error("expected an error for a path outside the destination")
+	}
+}
+
+func TestTemplateRoot(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"project layout", []string{".claude/agents/a.md"}, "."},
+		{"mirrors _template", []string{"project/.claude/agents/a.md", "global/CLAUDE.md"}, "project"},
+		{"archive wrapper", []string{"assets-1.0/project/.claude/agents/a.md"}, "assets-1.0/project"},
+		{"claude-workspace source", []string{"claude-workspace-1.4.0/_template/project/.claude/agents/a.md", "claude-workspace-1.4.0/main.go"}, "claude-workspace-1.4.0/_template/project"},
+		{"no .claude", []string{"README.md"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(f))
+				_ = os.MkdirAll(filepath.Dir(path), 0755)
+				_ = os.WriteFile(path, nil, 0644)
+			}
+			got, err := templateRoot(dir)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("templateRoot() = %q, want error", got)
+				}
+				return
+			}
+			if want := filepath.Join(dir, filepath.FromSlash(tt.want)); err != nil || got != want {
+				t.Errorf("templateRoot() = %q, %v; want %q", got, err, want)
+			}
+		})
+	}
+}
+
+func TestFetchTarball(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	archive := makeTarGz(t, map[string]string{
+		"assets-main/.claude/agents/planner.md": "remote planner",
+		"assets-main/.claude/hooks/guard.sh":    "#!/bin/sh\n",
+		"assets-main/.mcp.json":                 "{}",
+	})
+	sum := sha256.Sum256(archive)
+	want := hex.EncodeToString(sum[:])
+
+	requests := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write(archive)
+	}))
+	defer srv.Close()
+	oldClient := httpClient
+	httpClient = srv.Client()
+	defer func() { httpClient = oldClient }()
+
+	src, err := ParseSource(srv.URL + "/assets.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Fetch(io.Discard, src, Options{SHA256: strings.Repeat("0", 64)}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Fetch() with wrong checksum error = %v", err)
+	}
+
+	tmpl, err := Fetch(io.Discard, src, Options{SHA256: strings.ToUpper(want)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Revision != want {
+		t.Errorf("Revision = %s, want %s", tmpl.Revision, want)
+	}
+	if data, err := fs.ReadFile(tmpl.FS(), ".claude/agents/planner.md"); err != nil || string(data) != "remote planner" {
+		t.Errorf("planner.md = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(tmpl.Dir, ".claude", "hooks", "guard.sh")); err != nil || info.Mode()&0111 == 0 {
+		t.Errorf("guard.sh should be executable: %v", err)
+	}
+
+	// A verified archive is served from the cache, including by Lookup.
+	before := requests
+	if _, err := Fetch(io.Discard, src, Options{SHA256: want}); err != nil {
+		t.Fatal(err)
+	}
+	if requests != before {
+		t.Error("verified archive was downloaded again")
+	}
+	if cached, err := Lookup(src, Options{SHA256: want}); err != nil || cached.Dir != tmpl.Dir {
+		t.Errorf("Lookup() = %+v, %v", cached, err)
+	}
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "test"}, {"GIT_AUTHOR_EMAIL", "test@example.com"},
+		{"GIT_COMMITTER_NAME", "test"}, {"GIT_COMMITTER_EMAIL", "test@example.com"},
+		{"GIT_CONFIG_GLOBAL", os.DevNull},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte(content), 0644)
+	}
+	run("init", "-q", "-b", "main")
+	write("project/.claude/agents/planner.md", "v1")
+	run("add", "-A")
+	run("commit", "-qm", "v1")
+	run("tag", "v1")
+	write("project/.claude/agents/planner.md", "v2")
+	run("commit", "-qam", "v2")
+	head := run("rev-parse", "HEAD")
+
+	src, _ := ParseSource(repo)
+	tmpl, err := Fetch(io.Discard, src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Revision != head || filepath.Base(tmpl.Dir) != "project" {
+		t.Errorf("Fetch() = %+v, want HEAD %s under project/", tmpl, head)
+	}
+	if data, _ := fs.ReadFile(tmpl.FS(), ".claude/agents/planner.md"); string(data) != "v2" {
+		t.Errorf("default branch planner.md = %q, want v2", data)
+	}
+
+	tagged, err := Fetch(io.Discard, Source{Kind: KindGit, URL: repo, Ref: "v1"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := fs.ReadFile(tagged.FS(), ".claude/agents/planner.md"); string(data) != "v1" {
+		t.Errorf("v1 planner.md = %q, want v1", data)
+	}
+
+	if _, err := Fetch(io.Discard, src, Options{VerifySignature: true}); err == nil {
+		t.Error("unsigned commit passed --verify-signature")
+	}
+
+	// Offline: the cached checkout is still usable.
+	if err := os.RemoveAll(repo); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := Fetch(&out, src, Options{}); err != nil {
+		t.Fatalf("Fetch() with unreachable remote: %v", err)
+	}
+	if !strings.Contains(out.String(), "using cached copy") {
+		t.Errorf("output = %q, want cached-copy warning", out.String())
+	}
+}
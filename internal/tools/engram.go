@@ -6,35 +6,96 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
+// EngramMinVersion is the oldest engram release whose export and import
+// formats the memory commands read and write.
+const EngramMinVersion = "1.0.0"
+
+// engramFormula is the Homebrew formula engram is published as.
+const engramFormula = "gentleman-programming/tap/engram"
+
 // Engram returns the engram tool definition.
 func Engram() Tool {
 	return Tool{
 		Name:       "engram",
 		Purpose:    "Optional legacy memory provider (FTS5 SQLite); default provider is now mcp-memory-libsql",
 		Required:   false,
-		InstallCmd: "brew install gentleman-programming/tap/engram",
+		InstallCmd: "brew install " + engramFormula,
 		CheckFn: func() bool {
-			return platform.Exists("engram")
+			if !platform.Exists("engram") {
+				return false
+			}
+			ver, err := EngramVersion()
+			return err == nil && EngramCompatible(ver)
 		},
 		InstallFn: installEngram,
-		VersionFn: func() (string, error) {
-			return platform.Output("engram", "--version")
-		},
+		VersionFn: EngramVersion,
+	}
+}
+
+// engramVersionRe matches the version in "engram --version" output, such as
+// "engram v1.2.3" or "engram version 1.2.3".
+var engramVersionRe = regexp.MustCompile(`v?(\d+\.\d+(?:\.\d+)?)`)
+
+// EngramVersion returns the installed engram version, without a leading "v".
+func EngramVersion() (string, error) {
+	out, err := platform.Output("engram", "--version")
+	if err != nil {
+		return "", err
+	}
+	return parseEngramVersion(out)
+}
+
+func parseEngramVersion(out string) (string, error) {
+	m := engramVersionRe.FindStringSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("unrecognized engram version %q", strings.TrimSpace(out))
+	}
+	return m[1], nil
+}
+
+// EngramCompatible reports whether engram version ver is EngramMinVersion or
+// newer.
+func EngramCompatible(ver string) bool {
+	have, want := versionParts(ver), versionParts(EngramMinVersion)
+	for i := range want {
+		if have[i] != want[i] {
+			return have[i] > want[i]
+		}
 	}
+	return true
+}
+
+// versionParts returns the major, minor and patch numbers of ver, treating
+// missing or non-numeric parts as 0.
+func versionParts(ver string) [3]int {
+	var parts [3]int
+	for i, p := range strings.SplitN(strings.TrimPrefix(ver, "v"), ".", 3) {
+		p, _, _ = strings.Cut(p, "-")
+		parts[i], _ = strconv.Atoi(p)
+	}
+	return parts
 }
 
 // installEngram tries Homebrew first, then falls back to GitHub binary download.
+// An engram too old for the memory commands is upgraded the same way.
 func installEngram() error {
 	// Priority 1: Homebrew (macOS or Linux with brew)
 	if platform.Exists("brew") {
-		fmt.Println("  Installing engram via Homebrew...")
-		if err := platform.RunQuiet("brew", "install", "gentleman-programming/tap/engram"); err == nil {
+		verb := "install"
+		if platform.RunQuiet("brew", "list", "--formula", engramFormula) == nil {
+			verb = "upgrade"
+		}
+		fmt.Printf("  Running brew %s %s...\n", verb, engramFormula)
+		if err := platform.RunQuiet("brew", verb, engramFormula); err == nil {
 			return nil
 		}
 		fmt.Println("  Homebrew install failed, trying binary download...")
@@ -118,7 +179,7 @@ func installEngramBinary() error {
 
 // engramLatestVersion fetches the latest release tag from GitHub.
 func engramLatestVersion() (string, error) {
-	resp, err := http.Get("https://api.github.com/repos/Gentleman-Programming/engram/releases/latest")
+	resp, err := platform.HTTPClient(10 * time.Second).Get("https://api.github.com/repos/Gentleman-Programming/engram/releases/latest")
 	if err != nil {
 		return "", err
 	}
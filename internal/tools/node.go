@@ -255,7 +255,7 @@ func configureNodePath(home, localBin string) {
 
 // downloadFile downloads a URL to a local file path.
 func downloadFile(url, dest string) error {
-	client := &http.Client{Timeout: 5 * time.Minute}
+	client := platform.HTTPClient(5 * time.Minute)
 	resp, err := client.Get(url)
 	if err != nil {
 		return err
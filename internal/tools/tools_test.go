@@ -185,3 +185,38 @@ func TestSimpleToolsHaveNoCustomFns(t *testing.T) {
 		}
 	}
 }
+
+func TestParseEngramVersion(t *testing.T) {
+	tests := []struct {
+		out, want string
+	}{
+		{"engram v1.2.3", "1.2.3"},
+		{"engram version 1.10.0\n", "1.10.0"},
+		{"1.4", "1.4"},
+	}
+	for _, tt := range tests {
+		got, err := parseEngramVersion(tt.out)
+		if err != nil || got != tt.want {
+			t.Errorf("parseEngramVersion(%q) = %q, %v; want %q", tt.out, got, err, tt.want)
+		}
+	}
+	if _, err := parseEngramVersion("engram dev"); err == nil {
+		t.Error("expected error for output without a version")
+	}
+}
+
+func TestEngramCompatible(t *testing.T) {
+	tests := map[string]bool{
+		EngramMinVersion: true,
+		"1.0":            true,
+		"1.0.1":          true,
+		"2.0.0-rc.1":     true,
+		"0.9.9":          false,
+		"0.12.0":         false,
+	}
+	for ver, want := range tests {
+		if got := EngramCompatible(ver); got != want {
+			t.Errorf("EngramCompatible(%q) = %v, want %v", ver, got, want)
+		}
+	}
+}
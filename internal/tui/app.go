@@ -24,10 +24,10 @@ func Run(version string) error {
 	}
 
 	theme := DefaultTheme()
-	launcher := newLauncher(version, &theme)
+	dashboard := newDashboard(version, &theme)
 
 	app := &appModel{
-		stack:   []tea.Model{launcher},
+		stack:   []tea.Model{dashboard},
 		theme:   theme,
 		version: version,
 	}
@@ -44,15 +44,26 @@ func Run(version string) error {
 // bypassing the launcher. It is called when "claude-workspace config" is invoked
 // with no arguments on a TTY.
 func RunConfig(version string) error {
+	theme := DefaultTheme()
+	return runView(version, theme, NewConfigView(&theme))
+}
+
+// RunSessions starts the interactive TUI with the sessions browser as the
+// initial view. It is called by "claude-workspace sessions browse".
+func RunSessions(version string) error {
+	theme := DefaultTheme()
+	return runView(version, theme, NewSessions(&theme))
+}
+
+// runView runs the TUI with initial as the only view on the stack, so leaving
+// it exits the program.
+func runView(version string, theme Theme, initial tea.Model) error {
 	if IsAccessible() {
 		return nil
 	}
 
-	theme := DefaultTheme()
-	configView := NewConfigView(&theme)
-
 	app := &appModel{
-		stack:   []tea.Model{configView},
+		stack:   []tea.Model{initial},
 		theme:   theme,
 		version: version,
 	}
@@ -106,6 +117,16 @@ func (m *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case dashboardMsg:
+		// Background refreshes go to the dashboard wherever it is in the stack.
+		for i, view := range m.stack {
+			if d, ok := view.(*dashboardModel); ok {
+				updated, cmd := d.Update(msg)
+				m.stack[i] = updated
+				return m, cmd
+			}
+		}
+		return m, nil
 	}
 
 	// Forward all other messages to the current view
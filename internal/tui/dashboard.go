@@ -0,0 +1,448 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/lamchakchan/claude-workspace/internal/cost"
+	"github.com/lamchakchan/claude-workspace/internal/doctor"
+	"github.com/lamchakchan/claude-workspace/internal/sandbox"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+const (
+	// dashboardRefresh is how often the dashboard reloads its panels.
+	dashboardRefresh = time.Minute
+	// dashboardCostTimeout bounds the ccusage call behind the cost panel.
+	dashboardCostTimeout = 30 * time.Second
+	// dashboardPanelLines is the number of content lines in each panel.
+	dashboardPanelLines = 6
+	// dashboardTwoColumnWidth is the terminal width from which panels are
+	// laid out in a 2x2 grid instead of a single column.
+	dashboardTwoColumnWidth = 90
+)
+
+// dashPanel identifies one of the dashboard's panels.
+type dashPanel int
+
+const (
+	panelHealth dashPanel = iota
+	panelCost
+	panelSessions
+	panelSandboxes
+	panelCount // sentinel for modular arithmetic
+)
+
+var panelTitles = []string{"Health", "Cost", "Recent Sessions", "Sandboxes"}
+
+// dashboardMsg is implemented by the dashboard's background messages. appModel
+// delivers them to the dashboard even while another view is on top, so
+// refreshes keep running.
+type dashboardMsg interface {
+	dashboardMsg()
+}
+
+// dashboardLoadedMsg carries the result of loading one panel.
+type dashboardLoadedMsg struct {
+	panel     dashPanel
+	health    *doctor.Report
+	today     float64
+	budget    cost.Budget
+	sessions  []sessions.Session
+	project   string
+	sandboxes []sandbox.Sandbox
+	err       string
+}
+
+// dashboardTickMsg triggers a background refresh.
+type dashboardTickMsg struct{}
+
+func (dashboardLoadedMsg) dashboardMsg() {}
+func (dashboardTickMsg) dashboardMsg()   {}
+
+// dashboardModel is the home screen shown when claude-workspace is run with no
+// arguments: panels for platform health, today's cost, recent sessions, and
+// the current project's sandboxes, each opening the matching detailed view.
+// The full command menu is one key away.
+type dashboardModel struct {
+	theme    *Theme
+	version  string
+	cwd      string
+	focus    dashPanel
+	width    int
+	height   int
+	quitting bool
+
+	loading [panelCount]bool
+	loaded  [panelCount]bool // loaded successfully at least once
+	errs    [panelCount]string
+	updated time.Time
+
+	health    *doctor.Report
+	today     float64
+	budget    cost.Budget
+	recent    []sessions.Session
+	project   string
+	sandboxes []sandbox.Sandbox
+}
+
+func newDashboard(version string, theme *Theme) *dashboardModel {
+	cwd, _ := os.Getwd()
+	return &dashboardModel{theme: theme, version: version, cwd: cwd}
+}
+
+func (m *dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.refresh(), dashboardTick())
+}
+
+func dashboardTick() tea.Cmd {
+	return tea.Tick(dashboardRefresh, func(time.Time) tea.Msg { return dashboardTickMsg{} })
+}
+
+// refresh starts loading every panel that is not already loading.
+func (m *dashboardModel) refresh() tea.Cmd {
+	var cmds []tea.Cmd
+	for p := panelHealth; p < panelCount; p++ {
+		if m.loading[p] {
+			continue
+		}
+		m.loading[p] = true
+		cmds = append(cmds, m.loadPanel(p))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *dashboardModel) loadPanel(p dashPanel) tea.Cmd {
+	cwd := m.cwd
+	switch p {
+	case panelHealth:
+		return func() tea.Msg {
+			report, err := doctor.Check()
+			if err != nil {
+				return dashboardLoadedMsg{panel: p, err: err.Error()}
+			}
+			return dashboardLoadedMsg{panel: p, health: report}
+		}
+	case panelCost:
+		return func() tea.Msg {
+			budget, _ := cost.LoadBudget()
+			ctx, cancel := context.WithTimeout(context.Background(), dashboardCostTimeout)
+			defer cancel()
+			today, err := cost.TodaySpend(ctx)
+			if err != nil {
+				return dashboardLoadedMsg{panel: p, budget: budget, err: err.Error()}
+			}
+			return dashboardLoadedMsg{panel: p, today: today, budget: budget}
+		}
+	case panelSessions:
+		return func() tea.Msg {
+			loaded, _ := loadSessions().(sessionsLoadedMsg)
+			if loaded.err != "" {
+				return dashboardLoadedMsg{panel: p, err: loaded.err}
+			}
+			recent := loaded.sessions
+			if len(recent) > dashboardPanelLines {
+				recent = recent[:dashboardPanelLines]
+			}
+			return dashboardLoadedMsg{panel: p, sessions: recent}
+		}
+	default:
+		return func() tea.Msg {
+			project, list, err := sandbox.Sandboxes(cwd)
+			if err != nil {
+				return dashboardLoadedMsg{panel: p, err: err.Error()}
+			}
+			return dashboardLoadedMsg{panel: p, project: project, sandboxes: list}
+		}
+	}
+}
+
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case dashboardTickMsg:
+		return m, tea.Batch(m.refresh(), dashboardTick())
+
+	case dashboardLoadedMsg:
+		m.apply(msg)
+		return m, nil
+
+	case tea.KeyPressMsg:
+		if IsQuit(msg) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		switch msg.String() {
+		case keyTab, "l", keyRight:
+			m.focus = (m.focus + 1) % panelCount
+		case keyShiftTab, "h", keyLeft:
+			m.focus = (m.focus + panelCount - 1) % panelCount
+		case keyDown, "j":
+			m.focus = m.verticalNeighbor(1)
+		case keyUp, "k":
+			m.focus = m.verticalNeighbor(-1)
+		case "1", "2", "3", "4":
+			m.focus = dashPanel(msg.String()[0] - '1')
+		case keyEnter:
+			return m, m.open(m.focus)
+		case "c", "m":
+			return m, pushView(newCommandMenu(m.version, m.theme))
+		case "r":
+			return m, m.refresh()
+		case "?":
+			return m, pushView(NewHelp(m.theme))
+		}
+	}
+	return m, nil
+}
+
+// verticalNeighbor returns the panel above (dir -1) or below (dir 1) the
+// focused one, wrapping around.
+func (m *dashboardModel) verticalNeighbor(dir int) dashPanel {
+	step := 1
+	if m.twoColumns() {
+		step = 2
+	}
+	return dashPanel((int(m.focus) + dir*step + int(panelCount)) % int(panelCount))
+}
+
+func (m *dashboardModel) apply(msg dashboardLoadedMsg) {
+	p := msg.panel
+	m.loading[p] = false
+	m.errs[p] = msg.err
+	m.updated = time.Now()
+	if msg.err != "" {
+		return // keep showing the last good data
+	}
+	m.loaded[p] = true
+	switch p {
+	case panelHealth:
+		m.health = msg.health
+	case panelCost:
+		m.today = msg.today
+		m.budget = msg.budget
+	case panelSessions:
+		m.recent = msg.sessions
+	case panelSandboxes:
+		m.project = msg.project
+		m.sandboxes = msg.sandboxes
+	}
+}
+
+// open returns the command that pushes the detailed view for panel p.
+func (m *dashboardModel) open(p dashPanel) tea.Cmd {
+	switch p {
+	case panelHealth:
+		return pushView(NewDoctor(m.theme))
+	case panelCost:
+		return pushView(NewCost(m.theme))
+	case panelSessions:
+		return pushView(NewSessions(m.theme))
+	default:
+		if m.errs[panelSandboxes] != "" {
+			return pushView(NewSandboxList(m.theme))
+		}
+		cwd := m.cwd
+		return pushView(NewLoadingViewer("Sandboxes", func() (string, error) {
+			var buf bytes.Buffer
+			if err := sandbox.ListTo(&buf, cwd); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}, m.theme))
+	}
+}
+
+func (m *dashboardModel) twoColumns() bool {
+	return m.width >= dashboardTwoColumnWidth
+}
+
+func (m *dashboardModel) View() tea.View {
+	if m.quitting {
+		return tea.NewView("")
+	}
+
+	var b strings.Builder
+	title := m.theme.Title.Render(fmt.Sprintf("claude-workspace  %s", m.version))
+	subtitle := m.theme.Subtitle.Render("Claude Code Platform Engineering Kit")
+	b.WriteString(m.theme.Banner.Render(title + "\n" + subtitle))
+	b.WriteString("\n")
+
+	width := max(m.width, 40)
+	panels := make([]string, panelCount)
+	colWidth := width
+	if m.twoColumns() {
+		colWidth = width / 2
+	}
+	for p := panelHealth; p < panelCount; p++ {
+		panels[p] = m.renderPanel(p, colWidth)
+	}
+	if m.twoColumns() {
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, panels[panelHealth], panels[panelCost]))
+		b.WriteString("\n")
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, panels[panelSessions], panels[panelSandboxes]))
+	} else {
+		b.WriteString(lipgloss.JoinVertical(lipgloss.Left, panels...))
+	}
+	b.WriteString("\n\n")
+
+	help := fmt.Sprintf(
+		"%s switch panel  %s open  %s commands  %s refresh  %s help  %s quit",
+		m.theme.HelpKey.Render("tab/arrows"),
+		m.theme.HelpKey.Render(keyEnter),
+		m.theme.HelpKey.Render("c"),
+		m.theme.HelpKey.Render("r"),
+		m.theme.HelpKey.Render("?"),
+		m.theme.HelpKey.Render("q"),
+	)
+	b.WriteString(help)
+	if !m.updated.IsZero() {
+		b.WriteString("  " + m.theme.HelpDesc.Render("updated "+m.updated.Format("15:04:05")))
+	}
+	b.WriteString("\n")
+	return tea.NewView(b.String())
+}
+
+// renderPanel draws panel p as a bordered box of the given total width.
+func (m *dashboardModel) renderPanel(p dashPanel, width int) string {
+	inner := max(width-4, 10) // border + padding on each side
+	muted := lipgloss.NewStyle().Foreground(m.theme.Muted)
+
+	heading := fmt.Sprintf("%d %s", p+1, panelTitles[p])
+	switch {
+	case m.loading[p]:
+		heading += " …"
+	case m.loaded[p] && m.errs[p] != "":
+		heading += " (refresh failed)"
+	}
+	border := m.theme.Muted
+	headStyle := m.theme.SectionHead
+	if p == m.focus {
+		border = m.theme.Primary
+		headStyle = lipgloss.NewStyle().Bold(true).Foreground(m.theme.Primary)
+	}
+
+	var lines []string
+	switch {
+	case !m.loaded[p] && m.errs[p] != "":
+		lines = []string{lipgloss.NewStyle().Foreground(m.theme.Warning).Render(truncateRunes(m.errs[p], inner))}
+	case !m.loaded[p]:
+		lines = []string{muted.Render("Loading...")}
+	default:
+		lines = m.panelLines(p, inner)
+	}
+	if len(lines) > dashboardPanelLines {
+		lines = lines[:dashboardPanelLines]
+	}
+	for len(lines) < dashboardPanelLines {
+		lines = append(lines, "")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Padding(0, 1).
+		Width(width).
+		Render(headStyle.Render(heading) + "\n" + strings.Join(lines, "\n"))
+}
+
+// panelLines renders the content of panel p, each line at most width runes.
+func (m *dashboardModel) panelLines(p dashPanel, width int) []string {
+	muted := lipgloss.NewStyle().Foreground(m.theme.Muted)
+	switch p {
+	case panelHealth:
+		return healthLines(m.health, m.theme, width)
+	case panelCost:
+		lines := []string{fmt.Sprintf("Today   %s", m.theme.Title.Render(fmt.Sprintf("$%.2f", m.today)))}
+		if m.budget.Monthly > 0 {
+			lines = append(lines, muted.Render(fmt.Sprintf("Monthly budget   $%.2f", m.budget.Monthly)))
+		}
+		if m.budget.PerSession > 0 {
+			lines = append(lines, muted.Render(fmt.Sprintf("Session budget   $%.2f", m.budget.PerSession)))
+		}
+		return lines
+	case panelSessions:
+		if len(m.recent) == 0 {
+			return []string{muted.Render("No sessions yet.")}
+		}
+		lines := make([]string, 0, len(m.recent))
+		for _, s := range m.recent {
+			when := formatSince(time.Since(s.StartTime))
+			title := s.Title
+			if s.Project != "" {
+				title = fmt.Sprintf("[%s] %s", filepath.Base(s.Project), title)
+			}
+			lines = append(lines, muted.Render(fmt.Sprintf("%-4s", when))+" "+truncateRunes(title, width-5))
+		}
+		return lines
+	default:
+		if len(m.sandboxes) == 0 {
+			return []string{muted.Render(truncateRunes("No sandboxes for "+m.project+".", width))}
+		}
+		lines := []string{muted.Render(truncateRunes(fmt.Sprintf("%s: %d sandbox(es)", m.project, len(m.sandboxes)), width))}
+		for _, sb := range m.sandboxes {
+			status := lipgloss.NewStyle().Foreground(m.theme.Success).Render("clean")
+			switch {
+			case sb.Changes < 0:
+				status = muted.Render("unknown")
+			case sb.Changes > 0:
+				status = lipgloss.NewStyle().Foreground(m.theme.Warning).Render(fmt.Sprintf("%d changed", sb.Changes))
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s", truncateRunes(sb.Branch, width-16), muted.Render(sb.Age), status))
+		}
+		return lines
+	}
+}
+
+// healthLines summarizes a doctor report: overall status, then the failed
+// and warning checks.
+func healthLines(r *doctor.Report, theme *Theme, width int) []string {
+	var lines []string
+	switch {
+	case !r.Healthy:
+		lines = append(lines, theme.BadgeFail.Render(fmt.Sprintf("✗ %d issue(s), %d warning(s)", r.Issues, r.Warnings)))
+	case r.Warnings > 0:
+		lines = append(lines, theme.BadgeWarn.Render(fmt.Sprintf("✓ Healthy, %d warning(s)", r.Warnings)))
+	default:
+		lines = append(lines, theme.BadgeOK.Render(fmt.Sprintf("✓ Healthy (%d checks passed)", len(r.Results))))
+	}
+	for _, status := range []string{doctor.StatusFail, doctor.StatusWarn} {
+		for _, res := range r.Results {
+			if res.Status != status {
+				continue
+			}
+			mark := theme.BadgeWarn.Render("!")
+			if status == doctor.StatusFail {
+				mark = theme.BadgeFail.Render("✗")
+			}
+			lines = append(lines, mark+" "+truncateRunes(res.Message, width-2))
+		}
+	}
+	return lines
+}
+
+// formatSince renders an elapsed duration as a short age such as "5m" or "3d".
+func formatSince(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
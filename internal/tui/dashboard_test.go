@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/lamchakchan/claude-workspace/internal/doctor"
+	"github.com/lamchakchan/claude-workspace/internal/sandbox"
+	"github.com/lamchakchan/claude-workspace/internal/sessions"
+)
+
+func key(s string) tea.KeyPressMsg {
+	switch s {
+	case keyTab:
+		return tea.KeyPressMsg{Code: tea.KeyTab}
+	case keyDown:
+		return tea.KeyPressMsg{Code: tea.KeyDown}
+	case keyEnter:
+		return tea.KeyPressMsg{Code: tea.KeyEnter}
+	case keyEsc:
+		return tea.KeyPressMsg{Code: tea.KeyEscape}
+	}
+	return tea.KeyPressMsg{Code: rune(s[0]), Text: s}
+}
+
+func loadedDashboard(t *testing.T) *dashboardModel {
+	t.Helper()
+	theme := DefaultTheme()
+	m := newDashboard("v1.2.3", &theme)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, msg := range []dashboardLoadedMsg{
+		{panel: panelHealth, health: &doctor.Report{Issues: 1, Warnings: 1, Results: []doctor.Result{
+			{Status: doctor.StatusPass, Message: "Claude CLI installed"},
+			{Status: doctor.StatusWarn, Message: "Node.js is old"},
+			{Status: doctor.StatusFail, Message: "settings.json is invalid"},
+		}}},
+		{panel: panelCost, today: 4.5},
+		{panel: panelSessions, sessions: []sessions.Session{
+			{Title: "Fix the login bug", Project: "/src/webapp", StartTime: time.Now().Add(-3 * time.Hour)},
+		}},
+		{panel: panelSandboxes, project: "webapp", sandboxes: []sandbox.Sandbox{{Branch: "feature-x", Age: "2d", Changes: 3}}},
+	} {
+		m.Update(msg)
+	}
+	return m
+}
+
+func TestDashboardView(t *testing.T) {
+	m := loadedDashboard(t)
+	view := m.View().Content
+	for _, want := range []string{
+		"1 Health", "1 issue(s), 1 warning(s)", "settings.json is invalid", "Node.js is old",
+		"2 Cost", "$4.50",
+		"3 Recent Sessions", "3h", "[webapp] Fix the login bug",
+		"4 Sandboxes", "webapp: 1 sandbox(es)", "feature-x", "3 changed",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("dashboard view missing %q:\n%s", want, view)
+		}
+	}
+	// The failure is listed before the warning.
+	if strings.Index(view, "settings.json is invalid") > strings.Index(view, "Node.js is old") {
+		t.Error("failed checks should be listed before warnings")
+	}
+}
+
+func TestDashboardKeepsDataOnFailedRefresh(t *testing.T) {
+	m := loadedDashboard(t)
+	m.Update(dashboardLoadedMsg{panel: panelCost, err: "bun or npx not found"})
+	view := m.View().Content
+	if !strings.Contains(view, "$4.50") || !strings.Contains(view, "refresh failed") {
+		t.Errorf("a failed refresh should keep the last data and say so:\n%s", view)
+	}
+
+	theme := DefaultTheme()
+	fresh := newDashboard("v1", &theme)
+	fresh.Update(dashboardLoadedMsg{panel: panelSandboxes, err: "not a git repository: /tmp"})
+	if view := fresh.View().Content; !strings.Contains(view, "not a git repository") {
+		t.Errorf("a panel that never loaded should show its error:\n%s", view)
+	}
+	if _, ok := fresh.open(panelSandboxes)().(PushViewMsg).Model.(*SandboxListModel); !ok {
+		t.Error("outside a git repository, the sandboxes panel should open the project prompt")
+	}
+}
+
+func TestDashboardNavigation(t *testing.T) {
+	m := loadedDashboard(t)
+	steps := []struct {
+		key  string
+		want dashPanel
+	}{
+		{keyTab, panelCost},
+		{keyDown, panelSandboxes},
+		{keyDown, panelCost},
+		{"1", panelHealth},
+		{"h", panelSandboxes},
+	}
+	for _, s := range steps {
+		m.Update(key(s.key))
+		if m.focus != s.want {
+			t.Fatalf("after %q focus = %d, want %d", s.key, m.focus, s.want)
+		}
+	}
+
+	m.focus = panelSessions
+	_, cmd := m.Update(key(keyEnter))
+	if _, ok := cmd().(PushViewMsg).Model.(*SessionsModel); !ok {
+		t.Error("enter on the sessions panel should open the sessions browser")
+	}
+	_, cmd = m.Update(key("c"))
+	menu, ok := cmd().(PushViewMsg).Model.(*launcherModel)
+	if !ok || !menu.embedded {
+		t.Fatal("c should open the command menu")
+	}
+	if _, cmd := menu.Update(key(keyEsc)); cmd == nil {
+		t.Error("esc in the command menu should go back")
+	} else if _, ok := cmd().(PopViewMsg); !ok {
+		t.Error("esc in the command menu should pop back to the dashboard")
+	}
+}
+
+func TestAppRoutesDashboardMessages(t *testing.T) {
+	theme := DefaultTheme()
+	dash := newDashboard("v1", &theme)
+	app := &appModel{stack: []tea.Model{dash, NewHelp(&theme)}}
+
+	app.Update(dashboardLoadedMsg{panel: panelCost, today: 12})
+	if dash.today != 12 || !dash.loaded[panelCost] {
+		t.Error("dashboard messages should reach the dashboard while another view is on top")
+	}
+	if _, cmd := app.Update(dashboardTickMsg{}); cmd == nil {
+		t.Error("a tick should start a refresh and schedule the next tick")
+	}
+}
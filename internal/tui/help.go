@@ -51,6 +51,16 @@ func (m *HelpModel) View() tea.View {
 				{"q / ctrl+c", "Quit"},
 			},
 		},
+		{
+			title: "Dashboard",
+			binds: [][2]string{
+				{"tab / ← / →", "Switch panel"},
+				{"1-4", "Jump to panel"},
+				{keyEnter, "Open the panel's detailed view"},
+				{"c", "Command menu"},
+				{"r", "Refresh now (also every minute)"},
+			},
+		},
 		{
 			title: "Forms",
 			binds: [][2]string{
@@ -87,6 +97,16 @@ func (m *HelpModel) View() tea.View {
 				{"esc / q", "Close viewer"},
 			},
 		},
+		{
+			title: "Sessions browser",
+			binds: [][2]string{
+				{"/", "Fuzzy filter"},
+				{keyEnter, "View full transcript"},
+				{"e", "Export as Markdown"},
+				{"r", "Resume in claude"},
+				{"d", "Delete session"},
+			},
+		},
 		{
 			title: "Confirmation dialogs",
 			binds: [][2]string{
@@ -38,6 +38,7 @@ type launcherModel struct {
 	theme    *Theme
 	version  string
 	quitting bool
+	embedded bool // opened from the dashboard; esc/q go back instead of quitting
 }
 
 func newLauncher(version string, theme *Theme) *launcherModel {
@@ -85,7 +86,7 @@ func newLauncher(version string, theme *Theme) *launcherModel {
 				{name: "Skills", desc: "List available skills and personal commands", icon: "🛠 ", command: "skills"},
 				{name: "Agents", desc: "List configured agents", icon: "🤖", command: "agents"},
 				{name: "Hooks", desc: "List configured hooks", icon: "🪝 ", command: "hooks"},
-				{name: "Sessions", desc: "Browse and review session prompts", icon: "💬", command: "sessions"},
+				{name: "Sessions", desc: "Browse, preview, and resume sessions", icon: "💬", command: "sessions"},
 				{name: "Memory", desc: "Inspect and manage memory layers", icon: "🧠", command: "memory"},
 				{name: "Cost", desc: "View usage and costs", icon: "💰", command: "cost"},
 			},
@@ -112,6 +113,13 @@ func newLauncher(version string, theme *Theme) *launcherModel {
 	}
 }
 
+// newCommandMenu returns the launcher as a view pushed from the dashboard.
+func newCommandMenu(version string, theme *Theme) *launcherModel {
+	m := newLauncher(version, theme)
+	m.embedded = true
+	return m
+}
+
 // selectedItem returns the currently selected command item.
 func (m *launcherModel) selectedItem() commandItem {
 	idx := 0
@@ -138,6 +146,9 @@ func (m *launcherModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyPressMsg:
+		if m.embedded && (IsQuit(msg) || IsBack(msg)) {
+			return m, func() tea.Msg { return PopViewMsg{} }
+		}
 		if IsQuit(msg) {
 			m.quitting = true
 			return m, tea.Quit
@@ -291,12 +302,18 @@ func (m *launcherModel) View() tea.View {
 
 	// Footer
 	b.WriteString("\n")
+	quit := "q quit"
+	if m.embedded {
+		quit = "esc back"
+	}
+	key, action, _ := strings.Cut(quit, " ")
 	help := fmt.Sprintf(
-		"%s navigate  %s select  %s help  %s quit",
+		"%s navigate  %s select  %s help  %s %s",
 		m.theme.HelpKey.Render("↑/↓"),
 		m.theme.HelpKey.Render(keyEnter),
 		m.theme.HelpKey.Render("?"),
-		m.theme.HelpKey.Render("q"),
+		m.theme.HelpKey.Render(key),
+		action,
 	)
 	b.WriteString(help)
 	b.WriteString("\n")
@@ -1,11 +1,14 @@
 package tui
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
@@ -14,40 +17,68 @@ import (
 	"github.com/lamchakchan/claude-workspace/internal/sessions"
 )
 
+const (
+	// maxBrowseSessions caps how many recent sessions the browser loads.
+	maxBrowseSessions = 200
+	// previewMinWidth is the terminal width below which the preview pane is hidden.
+	previewMinWidth = 100
+	// previewBlockLines is how many lines of each message the preview shows.
+	previewBlockLines = 4
+)
+
 // sessionsLoadedMsg carries the loaded session list.
 type sessionsLoadedMsg struct {
 	sessions []sessions.Session
 	err      string
 }
 
-// sessionPromptsMsg carries prompts for a selected session.
-type sessionPromptsMsg struct {
-	session sessions.Session
-	prompts []sessions.Prompt
-	slug    string
+// sessionTranscriptMsg carries a parsed transcript for the preview pane or the
+// full viewer.
+type sessionTranscriptMsg struct {
+	session    sessions.Session
+	transcript *sessions.Transcript
+	open       bool // open in the full viewer once loaded
+	err        string
+}
+
+// sessionActionMsg reports the outcome of an export, resume, or delete.
+type sessionActionMsg struct {
+	status  string
 	err     string
+	deleted string // path of a deleted session
 }
 
-// SessionsModel shows a selectable session list with drill-down to prompts.
+// SessionsModel is an interactive session browser: a filterable list on the
+// left, a transcript preview on the right, and drill-down to the full
+// transcript.
 type SessionsModel struct {
 	theme    *Theme
-	sessions []sessions.Session
+	all      []sessions.Session // every loaded session
+	sessions []sessions.Session // sessions matching the filter
 	cursor   int
 	scroll   int // index of first visible session
 	loading  bool
 	err      string
+	status   string // one-line result of the last action
 	width    int
 	height   int
 
-	// Drill-down state: when non-nil, we're viewing a session's prompts.
+	filter     string
+	filterMode bool
+
+	previews map[string]*sessions.Transcript // by session path
+	confirm  *ConfirmModel                   // delete confirmation, when non-nil
+
+	// Drill-down state: when non-nil, we're viewing a session's transcript.
 	promptViewer *ViewerModel
 }
 
-// NewSessions creates a new sessions list/viewer.
+// NewSessions creates a new sessions browser.
 func NewSessions(theme *Theme) *SessionsModel {
 	return &SessionsModel{
-		theme:   theme,
-		loading: true,
+		theme:    theme,
+		loading:  true,
+		previews: make(map[string]*sessions.Transcript),
 	}
 }
 
@@ -85,44 +116,22 @@ func loadSessions() tea.Msg {
 		return allSessions[i].StartTime.After(allSessions[j].StartTime)
 	})
 
-	if len(allSessions) > 50 {
-		allSessions = allSessions[:50]
+	if len(allSessions) > maxBrowseSessions {
+		allSessions = allSessions[:maxBrowseSessions]
 	}
 
 	return sessionsLoadedMsg{sessions: allSessions}
 }
 
-func loadSessionPrompts(s *sessions.Session) tea.Cmd {
+// loadTranscript parses a session's transcript in the background.
+func loadTranscript(s *sessions.Session, open bool) tea.Cmd {
 	sess := *s // copy for goroutine safety
 	return func() tea.Msg {
-		home, err := os.UserHomeDir()
+		t, err := sessions.ParseTranscript(sess.Path)
 		if err != nil {
-			return sessionPromptsMsg{session: sess, err: err.Error()}
+			return sessionTranscriptMsg{session: sess, open: open, err: err.Error()}
 		}
-		projectsDir := filepath.Join(home, ".claude", "projects")
-
-		// Search all project dirs for this session file
-		projectEntries, err := os.ReadDir(projectsDir)
-		if err != nil {
-			return sessionPromptsMsg{session: sess, err: err.Error()}
-		}
-
-		for _, pe := range projectEntries {
-			if !pe.IsDir() {
-				continue
-			}
-			dir := filepath.Join(projectsDir, pe.Name())
-			path := filepath.Join(dir, sess.ID+".jsonl")
-			if platform.FileExists(path) {
-				prompts, slug, err := sessions.ParseSessionPrompts(path)
-				if err != nil {
-					return sessionPromptsMsg{session: sess, err: err.Error()}
-				}
-				return sessionPromptsMsg{session: sess, prompts: prompts, slug: slug}
-			}
-		}
-
-		return sessionPromptsMsg{session: sess, err: "session file not found"}
+		return sessionTranscriptMsg{session: sess, transcript: t, open: open}
 	}
 }
 
@@ -157,8 +166,65 @@ func (m *SessionsModel) clampScroll() {
 	}
 }
 
+// selected returns the session under the cursor, or nil if the list is empty.
+func (m *SessionsModel) selected() *sessions.Session {
+	if m.cursor < 0 || m.cursor >= len(m.sessions) {
+		return nil
+	}
+	return &m.sessions[m.cursor]
+}
+
+// showPreview reports whether the terminal is wide enough for the preview pane.
+func (m *SessionsModel) showPreview() bool {
+	return m.width >= previewMinWidth
+}
+
+// previewCmd loads the selected session's transcript for the preview pane if
+// it is not already cached.
+func (m *SessionsModel) previewCmd() tea.Cmd {
+	s := m.selected()
+	if s == nil || !m.showPreview() || s.Path == "" {
+		return nil
+	}
+	if _, ok := m.previews[s.Path]; ok {
+		return nil
+	}
+	return loadTranscript(s, false)
+}
+
+// applyFilter narrows all to the sessions fuzzily matching the filter.
+func (m *SessionsModel) applyFilter() {
+	if m.filter == "" {
+		m.sessions = m.all
+	} else {
+		filtered := make([]sessions.Session, 0, len(m.all))
+		for _, s := range m.all {
+			if fuzzyMatch(m.filter, s.Title+" "+s.Slug+" "+s.Project+" "+s.ID) {
+				filtered = append(filtered, s)
+			}
+		}
+		m.sessions = filtered
+	}
+	m.cursor = 0
+	m.scroll = 0
+}
+
+// fuzzyMatch reports whether every rune of query appears in s in order,
+// ignoring case.
+func fuzzyMatch(query, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(query) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
 func (m *SessionsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// If viewing prompts, delegate to the prompt viewer
+	// If viewing a transcript, delegate to the viewer
 	if m.promptViewer != nil {
 		return m.updatePromptViewer(msg)
 	}
@@ -167,7 +233,8 @@ func (m *SessionsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		return m, nil
+		m.clampScroll()
+		return m, m.previewCmd()
 
 	case sessionsLoadedMsg:
 		m.loading = false
@@ -175,14 +242,36 @@ func (m *SessionsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.err = msg.err
 			return m, nil
 		}
-		m.sessions = msg.sessions
-		return m, nil
+		m.all = msg.sessions
+		m.applyFilter()
+		return m, m.previewCmd()
 
-	case sessionPromptsMsg:
-		cmd := m.handlePromptsLoaded(&msg)
+	case sessionTranscriptMsg:
+		return m, m.handleTranscriptLoaded(&msg)
+
+	case sessionActionMsg:
+		m.handleAction(&msg)
+		return m, m.previewCmd()
+
+	case ConfirmResult:
+		cmd := m.handleConfirm(msg.Confirmed)
 		return m, cmd
 
 	case tea.KeyPressMsg:
+		if m.confirm != nil {
+			var cmd tea.Cmd
+			m.confirm, cmd = m.confirm.Update(msg)
+			return m, cmd
+		}
+		m.status = ""
+		if m.filterMode {
+			return m, m.handleFilterKey(msg)
+		}
+		if msg.String() == keyEsc && m.filter != "" {
+			m.filter = ""
+			m.applyFilter()
+			return m, m.previewCmd()
+		}
 		if IsQuit(msg) || IsBack(msg) {
 			return m, func() tea.Msg { return PopViewMsg{} }
 		}
@@ -193,7 +282,7 @@ func (m *SessionsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// updatePromptViewer delegates to the prompt viewer sub-view.
+// updatePromptViewer delegates to the transcript viewer sub-view.
 func (m *SessionsModel) updatePromptViewer(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
@@ -212,21 +301,55 @@ func (m *SessionsModel) updatePromptViewer(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// handlePromptsLoaded processes loaded session prompts.
-func (m *SessionsModel) handlePromptsLoaded(msg *sessionPromptsMsg) tea.Cmd {
+// handleTranscriptLoaded caches a loaded transcript and, if requested, opens
+// it in the full viewer.
+func (m *SessionsModel) handleTranscriptLoaded(msg *sessionTranscriptMsg) tea.Cmd {
 	if msg.err != "" {
-		m.err = msg.err
+		if msg.open {
+			m.status = "Error: " + msg.err
+		}
 		return nil
 	}
-	content := formatSessionPrompts(&msg.session, msg.prompts, msg.slug)
-	m.promptViewer = NewViewer("Session: "+msg.session.Title, content, m.theme)
+	m.previews[msg.session.Path] = msg.transcript
+	if !msg.open {
+		return nil
+	}
+	title := msg.session.Title
+	if msg.transcript.Slug != "" {
+		title = msg.transcript.Slug
+	}
+	m.promptViewer = NewViewer("Session: "+title, formatTranscript(msg.transcript, m.theme), m.theme)
 	if m.width > 0 && m.height > 0 {
 		m.promptViewer.SetSize(m.width, m.height)
 	}
 	return nil
 }
 
-// handleSessionKey handles navigation keys in the session list.
+// handleFilterKey handles key events while typing a filter.
+func (m *SessionsModel) handleFilterKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc:
+		m.filterMode = false
+		m.filter = ""
+		m.applyFilter()
+	case keyEnter:
+		m.filterMode = false
+	case keyBackspace:
+		if len(m.filter) > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.filter)
+			m.filter = m.filter[:len(m.filter)-size]
+			m.applyFilter()
+		}
+	default:
+		if len(msg.Text) > 0 && msg.Text[0] >= ' ' {
+			m.filter += msg.Text
+			m.applyFilter()
+		}
+	}
+	return m.previewCmd()
+}
+
+// handleSessionKey handles navigation and action keys in the session list.
 func (m *SessionsModel) handleSessionKey(msg tea.KeyPressMsg) tea.Cmd {
 	switch msg.String() {
 	case keyUp, "k":
@@ -239,13 +362,13 @@ func (m *SessionsModel) handleSessionKey(msg tea.KeyPressMsg) tea.Cmd {
 			m.cursor++
 		}
 		m.clampScroll()
-	case "pgup", "b":
+	case keyPgUp, "b":
 		m.cursor -= m.visibleRows()
 		if m.cursor < 0 {
 			m.cursor = 0
 		}
 		m.clampScroll()
-	case "pgdown", "f":
+	case keyPgDown, "f":
 		m.cursor += m.visibleRows()
 		if m.cursor > len(m.sessions)-1 {
 			m.cursor = len(m.sessions) - 1
@@ -259,12 +382,130 @@ func (m *SessionsModel) handleSessionKey(msg tea.KeyPressMsg) tea.Cmd {
 			m.cursor = len(m.sessions) - 1
 		}
 		m.clampScroll()
+	case "/":
+		m.filterMode = true
+		return nil
 	case keyEnter:
-		if len(m.sessions) > 0 {
-			return loadSessionPrompts(&m.sessions[m.cursor])
+		if s := m.selected(); s != nil {
+			if t, ok := m.previews[s.Path]; ok {
+				return m.handleTranscriptLoaded(&sessionTranscriptMsg{session: *s, transcript: t, open: true})
+			}
+			return loadTranscript(s, true)
+		}
+	case "e":
+		if s := m.selected(); s != nil {
+			return exportSession(*s)
+		}
+	case "r":
+		if s := m.selected(); s != nil {
+			return resumeSession(*s)
 		}
+	case "d":
+		if s := m.selected(); s != nil {
+			body := fmt.Sprintf("%s\n%s\n\nThe transcript file is removed permanently.", s.Title, s.Path)
+			m.confirm = NewConfirm("Delete this session?", body, false, m.theme)
+		}
+		return nil
 	}
-	return nil
+	return m.previewCmd()
+}
+
+// handleConfirm deletes the selected session once the user confirms.
+func (m *SessionsModel) handleConfirm(confirmed bool) tea.Cmd {
+	m.confirm = nil
+	s := m.selected()
+	if !confirmed || s == nil {
+		return nil
+	}
+	path := s.Path
+	return func() tea.Msg {
+		if err := sessions.Delete(path); err != nil {
+			return sessionActionMsg{err: err.Error()}
+		}
+		return sessionActionMsg{status: "Deleted " + filepath.Base(path), deleted: path}
+	}
+}
+
+// handleAction records an action's outcome and drops a deleted session.
+func (m *SessionsModel) handleAction(msg *sessionActionMsg) {
+	if msg.err != "" {
+		m.status = "Error: " + msg.err
+		return
+	}
+	m.status = msg.status
+	if msg.deleted == "" {
+		return
+	}
+	delete(m.previews, msg.deleted)
+	kept := m.all[:0]
+	for _, s := range m.all {
+		if s.Path != msg.deleted {
+			kept = append(kept, s)
+		}
+	}
+	m.all = kept
+	cursor := m.cursor
+	m.applyFilter()
+	m.cursor = min(cursor, max(0, len(m.sessions)-1))
+	m.clampScroll()
+}
+
+// exportSession writes the session's transcript as Markdown to the current
+// directory.
+func exportSession(s sessions.Session) tea.Cmd {
+	return func() tea.Msg {
+		t, err := sessions.ParseTranscript(s.Path)
+		if err != nil {
+			return sessionActionMsg{err: err.Error()}
+		}
+		name := t.Slug
+		if name == "" {
+			name = shortSessionID(t.ID)
+		}
+		path, err := filepath.Abs("session-" + name + ".md")
+		if err != nil {
+			return sessionActionMsg{err: err.Error()}
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return sessionActionMsg{err: err.Error()}
+		}
+		defer f.Close()
+		if err := sessions.WriteMarkdown(f, t); err != nil {
+			return sessionActionMsg{err: err.Error()}
+		}
+		return sessionActionMsg{status: "Exported to " + path}
+	}
+}
+
+// resumeSession suspends the TUI and runs "claude --resume" in the session's
+// project directory.
+func resumeSession(s sessions.Session) tea.Cmd {
+	dir, id, err := sessions.ResumeTarget(s.ID)
+	if err != nil {
+		return func() tea.Msg { return sessionActionMsg{err: err.Error()} }
+	}
+	if !platform.Exists("claude") {
+		return func() tea.Msg { return sessionActionMsg{err: "claude not found in PATH"} }
+	}
+	cmd := exec.Command("claude", "--resume", id)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return sessionActionMsg{err: err.Error()}
+		}
+		return sessionActionMsg{status: "Returned from session " + shortSessionID(id)}
+	})
+}
+
+func shortSessionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
 }
 
 func (m *SessionsModel) View() tea.View {
@@ -275,6 +516,12 @@ func (m *SessionsModel) View() tea.View {
 	var b strings.Builder
 	b.WriteString(m.theme.SectionBanner("Sessions"))
 
+	if m.confirm != nil {
+		b.WriteString("\n")
+		b.WriteString(m.confirm.View())
+		return tea.NewView(b.String())
+	}
+
 	if m.loading {
 		b.WriteString("\n  Loading...")
 		return tea.NewView(b.String())
@@ -287,47 +534,72 @@ func (m *SessionsModel) View() tea.View {
 		return tea.NewView(b.String())
 	}
 
-	if len(m.sessions) == 0 {
+	if len(m.all) == 0 {
 		b.WriteString("\n  No sessions found.\n")
 		return tea.NewView(b.String())
 	}
 
-	// Table header
-	fmt.Fprintf(&b, "\n  %-10s  %-12s  %s\n", "ID", "DATE", "TITLE")
-	mutedLine := lipgloss.NewStyle().Foreground(m.theme.Muted)
-	b.WriteString(mutedLine.Render(fmt.Sprintf("  %-10s  %-12s  %s", "──────────", "────────────", strings.Repeat("─", 50))))
 	b.WriteString("\n")
+	list := m.renderList()
+	if m.showPreview() {
+		leftW := m.width * 2 / 5
+		previewW := m.width - leftW - 3
+		preview := m.renderPreview(previewW, len(list))
+		sep := lipgloss.NewStyle().Foreground(m.theme.Muted).Render("│")
+		for i, line := range list {
+			pad := max(0, leftW-lipgloss.Width(line))
+			b.WriteString(line + strings.Repeat(" ", pad) + " " + sep + " " + preview[i] + "\n")
+		}
+	} else {
+		b.WriteString(strings.Join(list, "\n"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.footer())
+
+	return tea.NewView(b.String())
+}
+
+// renderList returns the table header, separator, and visible session rows.
+func (m *SessionsModel) renderList() []string {
+	titleW := 60
+	if m.showPreview() {
+		titleW = max(10, m.width*2/5-28)
+	}
+	mutedLine := lipgloss.NewStyle().Foreground(m.theme.Muted)
+	lines := []string{
+		fmt.Sprintf("  %-10s  %-12s  %s", "ID", "DATE", "TITLE"),
+		mutedLine.Render(fmt.Sprintf("  %-10s  %-12s  %s", "──────────", "────────────", strings.Repeat("─", min(50, titleW)))),
+	}
 
 	visible := m.visibleRows()
-	end := m.scroll + visible
-	if end > len(m.sessions) {
-		end = len(m.sessions)
+	if len(m.sessions) == 0 {
+		lines = append(lines, "    No sessions match the filter.")
+		for len(lines) < visible+2 {
+			lines = append(lines, "")
+		}
+		return lines
 	}
 
-	var rows strings.Builder
+	end := min(m.scroll+visible, len(m.sessions))
+	rows := make([]string, 0, end-m.scroll)
 	for i := m.scroll; i < end; i++ {
 		s := m.sessions[i]
-		shortID := s.ID
-		if len(shortID) > 8 {
-			shortID = shortID[:8]
-		}
 		date := s.StartTime.Local().Format("2006-01-02")
 		title := s.Title
-		if len(title) > 60 {
-			title = title[:57] + "..."
+		if s.Project != "" {
+			title = fmt.Sprintf("[%s] %s", filepath.Base(s.Project), title)
 		}
+		title = truncateRunes(title, titleW)
 
-		line := fmt.Sprintf("%-10s  %-12s  %s", shortID, date, title)
-
+		line := fmt.Sprintf("%-10s  %-12s  %s", shortSessionID(s.ID), date, title)
 		if i == m.cursor {
 			cursor := lipgloss.NewStyle().Foreground(m.theme.Primary).Bold(true).Render("> ")
 			line = lipgloss.NewStyle().Foreground(m.theme.Primary).Bold(true).Render(line)
-			rows.WriteString("  " + cursor + line)
+			rows = append(rows, "  "+cursor+line)
 		} else {
-			rows.WriteString("    " + line)
-		}
-		if i < end-1 {
-			rows.WriteString("\n")
+			rows = append(rows, "    "+line)
 		}
 	}
 
@@ -336,52 +608,270 @@ func (m *SessionsModel) View() tea.View {
 	if total > visible {
 		scrollPct = float64(m.scroll) / float64(total-visible)
 	}
-	bar := renderScrollbar(end-m.scroll, total, visible, scrollPct, m.theme)
-	if bar != "" {
-		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, rows.String(), " ", bar))
-	} else {
-		b.WriteString(rows.String())
+	if bar := renderScrollbar(len(rows), total, visible, scrollPct, m.theme); bar != "" {
+		joined := lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(rows, "\n"), " ", bar)
+		rows = strings.Split(joined, "\n")
 	}
-	b.WriteString("\n")
+	lines = append(lines, rows...)
+	for len(lines) < visible+2 {
+		lines = append(lines, "")
+	}
+	return lines
+}
 
-	b.WriteString("\n")
-	help := fmt.Sprintf(
-		"%s navigate  %s page  %s/%s top/bottom  %s view prompts  %s back  %d/%d",
+// renderPreview returns exactly height lines, each at most width columns,
+// summarizing the selected session's conversation.
+func (m *SessionsModel) renderPreview(width, height int) []string {
+	lines := make([]string, 0, height)
+	s := m.selected()
+	switch {
+	case s == nil:
+	case m.previews[s.Path] == nil:
+		lines = append(lines, lipgloss.NewStyle().Foreground(m.theme.Muted).Render("Loading preview..."))
+	default:
+		lines = previewLines(m.previews[s.Path], width, m.theme)
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// previewLines renders a condensed transcript: metadata, then the first few
+// lines of each message and a one-line summary of each tool call.
+func previewLines(t *sessions.Transcript, width int, theme *Theme) []string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(theme.Secondary)
+	muted := lipgloss.NewStyle().Foreground(theme.Muted)
+	user := lipgloss.NewStyle().Bold(true).Foreground(theme.Primary)
+	assistant := lipgloss.NewStyle().Bold(true).Foreground(theme.Secondary)
+
+	prompts, calls := 0, 0
+	for _, msg := range t.Messages {
+		if msg.Role != "assistant" {
+			prompts++
+		}
+		for _, blk := range msg.Blocks {
+			if blk.Type == "tool_use" {
+				calls++
+			}
+		}
+	}
+
+	name := t.ID
+	if t.Slug != "" {
+		name = t.Slug
+	}
+	meta := t.Project
+	if t.GitBranch != "" {
+		meta += " · " + t.GitBranch
+	}
+	lines := []string{
+		title.Render(truncateRunes(name, width)),
+		muted.Render(truncateRunes(meta, width)),
+		muted.Render(fmt.Sprintf("%d prompt(s) · %d tool call(s)", prompts, calls)),
+	}
+
+	for _, msg := range t.Messages {
+		lines = append(lines, "")
+		heading := user.Render("You")
+		if msg.Role == "assistant" {
+			heading = assistant.Render("Claude")
+		}
+		if !msg.Timestamp.IsZero() {
+			heading += "  " + muted.Render(msg.Timestamp.Local().Format("15:04:05"))
+		}
+		lines = append(lines, heading)
+		for _, blk := range msg.Blocks {
+			switch blk.Type {
+			case "thinking":
+				continue
+			case "tool_use":
+				call := "⚙ " + blk.Tool
+				if summary := toolInputSummary(blk.Input); summary != "" {
+					call += "  " + summary
+				}
+				style := muted
+				if blk.IsError {
+					style = lipgloss.NewStyle().Foreground(theme.Error)
+				}
+				lines = append(lines, style.Render(truncateRunes("  "+call, width)))
+			default:
+				wrapped := wrapText(blk.Text, width-2)
+				if len(wrapped) > previewBlockLines {
+					wrapped = append(wrapped[:previewBlockLines], "…")
+				}
+				for _, l := range wrapped {
+					lines = append(lines, "  "+l)
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// toolInputSummary picks the most descriptive field of a tool call's input,
+// such as a Bash command or a file path.
+func toolInputSummary(input json.RawMessage) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	for _, key := range []string{"command", "file_path", "path", "pattern", "url", "query", "description", "prompt"} {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return strings.Join(strings.Fields(v), " ")
+		}
+	}
+	return ""
+}
+
+// wrapText splits text into lines of at most width runes, breaking at spaces
+// where possible.
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var out []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					out = append(out, line)
+					line = ""
+				}
+				r := []rune(word)
+				out = append(out, string(r[:width]))
+				word = string(r[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				out = append(out, line)
+				line = word
+			}
+		}
+		out = append(out, line)
+	}
+	// Drop trailing blank lines.
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// truncateRunes shortens s to at most n runes, ending with "…" when cut.
+func truncateRunes(s string, n int) string {
+	if n < 1 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
+
+// footer returns the status, filter prompt, or key help line.
+func (m *SessionsModel) footer() string {
+	if m.filterMode {
+		return "  Filter: " + lipgloss.NewStyle().Foreground(m.theme.Primary).Render(m.filter+"_") +
+			"  " + m.theme.HelpDesc.Render("enter apply  esc clear")
+	}
+	if m.status != "" {
+		style := lipgloss.NewStyle().Foreground(m.theme.Success)
+		if strings.HasPrefix(m.status, "Error:") {
+			style = lipgloss.NewStyle().Foreground(m.theme.Error)
+		}
+		return "  " + style.Render(m.status)
+	}
+	count := fmt.Sprintf("%d/%d", min(m.cursor+1, len(m.sessions)), len(m.sessions))
+	if m.filter != "" {
+		count += fmt.Sprintf(" matching %q", m.filter)
+	}
+	return fmt.Sprintf(
+		"%s navigate  %s filter  %s view  %s export  %s resume  %s delete  %s back  %s",
 		m.theme.HelpKey.Render("j/k"),
-		m.theme.HelpKey.Render("pgup/pgdn"),
-		m.theme.HelpKey.Render("g"),
-		m.theme.HelpKey.Render("G"),
+		m.theme.HelpKey.Render("/"),
 		m.theme.HelpKey.Render(keyEnter),
+		m.theme.HelpKey.Render("e"),
+		m.theme.HelpKey.Render("r"),
+		m.theme.HelpKey.Render("d"),
 		m.theme.HelpKey.Render("esc"),
-		m.cursor+1, len(m.sessions),
+		count,
 	)
-	b.WriteString(help)
-
-	return tea.NewView(b.String())
 }
 
-func formatSessionPrompts(s *sessions.Session, prompts []sessions.Prompt, slug string) string {
+// formatTranscript renders a full transcript for the scrollable viewer. Tool
+// output is trimmed; "sessions export" keeps it in full.
+func formatTranscript(t *sessions.Transcript, theme *Theme) string {
+	const maxOutputLines = 10
 	var b strings.Builder
-	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#06B6D4"))
-	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280"))
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Secondary)
+	userStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Primary)
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
 
-	title := s.ID
-	if slug != "" {
-		title = fmt.Sprintf("%s (%s)", slug, s.ID[:min(8, len(s.ID))])
+	title := t.ID
+	if t.Slug != "" {
+		title = fmt.Sprintf("%s (%s)", t.Slug, shortSessionID(t.ID))
 	}
-
 	b.WriteString("  " + titleStyle.Render(title) + "\n")
-	b.WriteString("  Project: " + mutedStyle.Render(s.Project) + "\n")
-	fmt.Fprintf(&b, "  Prompts: %s\n\n", mutedStyle.Render(fmt.Sprintf("%d", len(prompts))))
+	b.WriteString("  Project: " + mutedStyle.Render(t.Project) + "\n")
+	if t.GitBranch != "" {
+		b.WriteString("  Branch:  " + mutedStyle.Render(t.GitBranch) + "\n")
+	}
+	b.WriteString("\n")
 
-	for i, p := range prompts {
-		ts := p.Timestamp.Local().Format("15:04:05")
-		fmt.Fprintf(&b, "  %s\n", titleStyle.Render(fmt.Sprintf("[%d] %s", i+1, ts)))
-		for _, line := range strings.Split(p.Content, "\n") {
-			b.WriteString("  " + line + "\n")
+	for _, msg := range t.Messages {
+		heading := userStyle.Render("You")
+		if msg.Role == "assistant" {
+			heading = titleStyle.Render("Claude")
+		}
+		if !msg.Timestamp.IsZero() {
+			heading += " " + mutedStyle.Render(msg.Timestamp.Local().Format("15:04:05"))
+		}
+		b.WriteString("  " + heading + "\n")
+		for _, blk := range msg.Blocks {
+			switch blk.Type {
+			case "thinking":
+				b.WriteString("  " + mutedStyle.Render("(thinking) "+firstLineOf(blk.Text)) + "\n")
+			case "tool_use":
+				b.WriteString("  " + mutedStyle.Render("⚙ "+blk.Tool+"  "+toolInputSummary(blk.Input)) + "\n")
+				out := strings.Split(strings.TrimRight(blk.Output, "\n"), "\n")
+				if blk.Output == "" {
+					out = nil
+				}
+				extra := 0
+				if len(out) > maxOutputLines {
+					extra = len(out) - maxOutputLines
+					out = out[:maxOutputLines]
+				}
+				for _, line := range out {
+					b.WriteString("    " + mutedStyle.Render("│ "+line) + "\n")
+				}
+				if extra > 0 {
+					b.WriteString("    " + mutedStyle.Render(fmt.Sprintf("│ … %d more line(s)", extra)) + "\n")
+				}
+			default:
+				for _, line := range strings.Split(blk.Text, "\n") {
+					b.WriteString("  " + line + "\n")
+				}
+			}
 		}
 		b.WriteString("\n")
 	}
 
 	return b.String()
 }
+
+func firstLineOf(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
@@ -1,5 +1,6 @@
 // Package tui provides the Bubble Tea terminal UI for claude-workspace.
-// It implements an interactive launcher and animated views for all commands.
+// It implements the home dashboard, an interactive command launcher, and
+// animated views for all commands.
 package tui
 
 import (
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -440,6 +441,163 @@ func TestSessionsScrollClamp(t *testing.T) {
 	}
 }
 
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query, s string
+		want     bool
+	}{
+		{"", "anything", true},
+		{"auth", "Add authentication middleware", true},
+		{"adm", "Add authentication middleware", true},
+		{"AMW", "add middleware", true},
+		{"wm", "add middleware", false},
+		{"zzz", "add middleware", false},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.query, tt.s); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.query, tt.s, got, tt.want)
+		}
+	}
+}
+
+func newTestSessionsModel(n int) *SessionsModel {
+	theme := DefaultTheme()
+	m := NewSessions(&theme)
+	m.loading = false
+	m.height = 18
+	m.width = 80
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		m.all = append(m.all, sessions.Session{
+			ID:        fmt.Sprintf("sess-%04d", i),
+			Path:      fmt.Sprintf("/tmp/p/sess-%04d.jsonl", i),
+			Project:   "/work/app",
+			Title:     fmt.Sprintf("Session %d", i),
+			StartTime: now.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+	m.applyFilter()
+	return m
+}
+
+func TestSessionsFilterMode(t *testing.T) {
+	m := newTestSessionsModel(12)
+
+	m.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	if !m.filterMode {
+		t.Fatal("expected filter mode after /")
+	}
+	for _, r := range "11" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	var titles []string
+	for _, s := range m.sessions {
+		titles = append(titles, s.Title)
+	}
+	got := strings.Join(titles, ",")
+	if !strings.Contains(got, "Session 11") || strings.Contains(got, "Session 2") {
+		t.Errorf("filtered sessions = %s, want Session 11 and not Session 2", got)
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.filterMode {
+		t.Error("enter should leave filter mode")
+	}
+	if m.filter != "11" {
+		t.Errorf("filter = %q, want it kept after enter", m.filter)
+	}
+
+	// esc clears the filter before leaving the view.
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.filter != "" || len(m.sessions) != 12 {
+		t.Errorf("after esc: filter=%q sessions=%d, want cleared", m.filter, len(m.sessions))
+	}
+	if cmd != nil {
+		if _, pop := cmd().(PopViewMsg); pop {
+			t.Error("esc with an active filter should not pop the view")
+		}
+	}
+}
+
+func TestSessionsHandleAction_Delete(t *testing.T) {
+	m := newTestSessionsModel(3)
+	m.cursor = 2
+	m.handleAction(&sessionActionMsg{status: "Deleted", deleted: "/tmp/p/sess-0002.jsonl"})
+	if len(m.all) != 2 || len(m.sessions) != 2 {
+		t.Fatalf("got %d/%d sessions, want 2", len(m.all), len(m.sessions))
+	}
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1 (clamped to last)", m.cursor)
+	}
+	if m.status != "Deleted" {
+		t.Errorf("status = %q", m.status)
+	}
+}
+
+func TestSessionsDeleteAsksForConfirmation(t *testing.T) {
+	m := newTestSessionsModel(2)
+	m.Update(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	if m.confirm == nil {
+		t.Fatal("expected a confirmation dialog after d")
+	}
+	m.Update(ConfirmResult{Confirmed: false})
+	if m.confirm != nil || len(m.all) != 2 {
+		t.Error("declining should close the dialog and keep the session")
+	}
+}
+
+func TestSessionsPreviewPane(t *testing.T) {
+	m := newTestSessionsModel(3)
+	m.width = 140
+	m.height = 20
+	m.previews[m.sessions[0].Path] = &sessions.Transcript{
+		ID:      "sess-0000",
+		Slug:    "fix-tests",
+		Project: "/work/app",
+		Messages: []sessions.Message{
+			{Role: "user", Blocks: []sessions.Block{{Type: "text", Text: "Run the tests"}}},
+			{Role: "assistant", Blocks: []sessions.Block{
+				{Type: "tool_use", Tool: "Bash", Input: json.RawMessage(`{"command":"go test ./..."}`)},
+				{Type: "text", Text: "All tests pass."},
+			}},
+		},
+	}
+
+	out := m.View().Content
+	for _, want := range []string{"fix-tests", "1 prompt(s) · 1 tool call(s)", "⚙ Bash  go test ./...", "All tests pass."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("preview missing %q:\n%s", want, out)
+		}
+	}
+
+	lines := m.renderPreview(40, 5)
+	if len(lines) != 5 {
+		t.Errorf("renderPreview returned %d lines, want 5", len(lines))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("the quick brown fox\n\njumps", 10)
+	want := []string{"the quick", "brown fox", "", "jumps"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapText = %q, want %q", got, want)
+	}
+	if got := wrapText("abcdefghij", 4); strings.Join(got, "|") != "abcd|efgh|ij" {
+		t.Errorf("long word: %q", got)
+	}
+}
+
+func TestToolInputSummary(t *testing.T) {
+	if got := toolInputSummary(json.RawMessage(`{"file_path":"/a/b.go","content":"x"}`)); got != "/a/b.go" {
+		t.Errorf("got %q, want file path", got)
+	}
+	if got := toolInputSummary(json.RawMessage(`{"command":"go  test\n./..."}`)); got != "go test ./..." {
+		t.Errorf("got %q, want collapsed whitespace", got)
+	}
+	if got := toolInputSummary(json.RawMessage(`{"other":1}`)); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+}
+
 func TestNewDoctor(t *testing.T) {
 	theme := DefaultTheme()
 	m := NewDoctor(&theme)
@@ -41,9 +41,10 @@ func (m *UpgradeModel) Init() tea.Cmd {
 	return fetchRelease
 }
 
-// fetchRelease is a Cmd that fetches the latest release in the background.
+// fetchRelease is a Cmd that fetches the latest release on the saved channel
+// in the background.
 func fetchRelease() tea.Msg {
-	r, err := upgrade.FetchLatest()
+	r, err := upgrade.FetchChannel(upgrade.LoadChannel())
 	return releaseInfo{release: r, err: err}
 }
 
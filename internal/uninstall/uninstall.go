@@ -0,0 +1,401 @@
+// Package uninstall implements the "uninstall" command, which removes
+// claude-workspace from the machine: the binary setup installed, the shared
+// state in ~/.claude-workspace, the MCP servers setup registered in
+// ~/.claude.json, and, with --strip-rc, the lines setup and "completion
+// install" added to shell RC files. Claude Code itself, its settings, API
+// keys, and stored secrets are left alone and listed at the end.
+package uninstall
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/lamchakchan/claude-workspace/internal/backup"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/completion"
+	"github.com/lamchakchan/claude-workspace/internal/memory"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
+	"github.com/lamchakchan/claude-workspace/internal/setup"
+)
+
+// ErrNotConfirmed is returned when uninstall needs confirmation but cannot
+// ask for it.
+var ErrNotConfirmed = errors.New("uninstall needs confirmation; run it in a terminal or pass --yes")
+
+// options holds the parsed uninstall flags.
+type options struct {
+	yes     bool // do not ask for confirmation
+	stripRC bool // remove the lines added to shell RC files
+	dryRun  bool // print what would be removed and stop
+}
+
+// env holds the locations uninstall works on, so tests can point them at a
+// temporary directory.
+type env struct {
+	home       string
+	installDir string // where setup installed the binary
+	executable string // the running binary, symlinks resolved; "" if unknown
+}
+
+// removal is one thing uninstall deletes or edits.
+type removal struct {
+	desc  string
+	apply func() error
+}
+
+// Run is the entry point for the uninstall command.
+func Run(args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("getting home directory: %w", err)
+	}
+	e := env{home: home, installDir: setup.InstallDir}
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			e.executable = resolved
+		}
+	}
+	var in io.Reader
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		in = os.Stdin
+	}
+	return run(platform.Stdout(), in, e, args)
+}
+
+// run is like Run but writes to w, reads the confirmation from in (nil when
+// there is no terminal to ask on), and works on the locations in e.
+func run(w io.Writer, in io.Reader, e env, args []string) error {
+	opts, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	removals, kept, err := plan(e, opts)
+	if err != nil {
+		return err
+	}
+
+	title := "Uninstall claude-workspace"
+	if opts.dryRun {
+		title += " (dry run)"
+	}
+	platform.PrintBanner(w, title)
+	if len(removals) == 0 {
+		fmt.Fprintln(w, "  Nothing to remove: claude-workspace is not installed for this user.")
+	} else {
+		platform.PrintSection(w, "Will remove")
+		for _, r := range removals {
+			fmt.Fprintf(w, "  %s\n", r.desc)
+		}
+	}
+	platform.PrintSection(w, "Left untouched")
+	for _, k := range kept {
+		fmt.Fprintf(w, "  %s\n", k)
+	}
+	fmt.Fprintln(w)
+
+	if opts.dryRun {
+		fmt.Fprintln(w, "  Nothing was changed. Run uninstall without --dry-run to remove these.")
+		return nil
+	}
+	if len(removals) == 0 {
+		return nil
+	}
+	if !opts.yes {
+		if in == nil {
+			return ErrNotConfirmed
+		}
+		platform.PrintPrompt(w, "  Remove claude-workspace from this machine? [y/N] ")
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Fprintln(w, "  Cancelled. Nothing was changed.")
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, r := range removals {
+		if err := r.apply(); err != nil {
+			platform.PrintFail(w, fmt.Sprintf("%s: %v", r.desc, err))
+			failed++
+			continue
+		}
+		platform.PrintOK(w, r.desc)
+	}
+	fmt.Fprintln(w)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d removals failed", failed, len(removals))
+	}
+	platform.PrintSuccess(w, "claude-workspace has been uninstalled.")
+	fmt.Fprintln(w, "  Open a new shell so it no longer loads completion or PATH changes.")
+	fmt.Fprintln(w)
+	return nil
+}
+
+func parseFlags(args []string) (options, error) {
+	var opts options
+	f := cli.New("uninstall", "uninstall [--strip-rc] [--dry-run] [--yes]")
+	f.BoolVar(&opts.stripRC, "--strip-rc", "Also remove the lines setup added to shell RC files")
+	f.BoolVar(&opts.dryRun, "--dry-run", "Show what would be removed")
+	f.BoolVar(&opts.yes, "--yes", "Do not ask for confirmation")
+	f.Alias("-y", "--yes")
+	positional, err := f.Parse(args)
+	if err != nil {
+		return opts, err
+	}
+	if err := f.CheckArgs(positional, 0, 0); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// plan returns what uninstall would remove and descriptions of what it
+// leaves in place, without changing anything.
+func plan(e env, opts options) ([]removal, []string, error) {
+	var removals []removal
+	var kept []string
+
+	r, k := planBinary(e, opts.yes)
+	removals, kept = append(removals, r...), append(kept, k...)
+
+	via, err := memory.SnapshotSchedule(e.home)
+	if err != nil {
+		return nil, nil, err
+	}
+	if via != "" {
+		removals = append(removals, removal{
+			desc:  "Remove the memory snapshot schedule (" + via + ")",
+			apply: func() error { return memory.DisableSnapshots(e.home) },
+		})
+	}
+
+	r, k, err = planState(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	removals, kept = append(removals, r...), append(kept, k...)
+
+	r, k, err = planMCPServers(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	removals, kept = append(removals, r...), append(kept, k...)
+
+	r, k = planShell(e, opts.stripRC)
+	removals, kept = append(removals, r...), append(kept, k...)
+
+	if dir := filepath.Join(e.home, ".config", "claude-workspace"); platform.FileExists(dir) {
+		kept = append(kept, dir+" (memory database; delete it to erase stored memories)")
+	}
+	kept = append(kept,
+		filepath.Join(e.home, ".claude")+" (your Claude Code settings, CLAUDE.md, agents, skills, and sessions)",
+		"API keys and the Claude Code login (ANTHROPIC_API_KEY, apiKeyHelper, and Claude Code's own credentials)",
+		"Claude Code itself (remove it with its own uninstaller)",
+		"Projects set up with attach (run 'claude-workspace detach <path>' first to remove their platform files)",
+	)
+	return removals, kept, nil
+}
+
+// planBinary removes the binary setup installed, and the copy upgrade keeps
+// for --rollback. It falls back to sudo when the directory is not writable.
+func planBinary(e env, noPrompt bool) ([]removal, []string) {
+	var removals []removal
+	var kept []string
+	binary := filepath.Join(e.installDir, "claude-workspace")
+	for _, path := range []string{binary, binary + ".old", binary + ".old.version"} {
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		path := path
+		removals = append(removals, removal{
+			desc:  "Remove " + path,
+			apply: func() error { return removeFile(path, noPrompt) },
+		})
+	}
+	if e.executable != "" && e.executable != binary && platform.FileExists(e.executable) {
+		kept = append(kept, e.executable+" (not installed by setup; delete it yourself)")
+	}
+	return removals, kept
+}
+
+func removeFile(path string, noPrompt bool) error {
+	err := os.Remove(path)
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+	if !os.IsPermission(err) {
+		return err
+	}
+	sudo := []string{"rm", "-f", path}
+	if noPrompt {
+		sudo = append([]string{"-n"}, sudo...)
+	}
+	if err := platform.Run("sudo", sudo...); err != nil {
+		return fmt.Errorf("permission denied; remove it with: sudo rm %s", path)
+	}
+	return nil
+}
+
+// planState removes everything in ~/.claude-workspace except stored secrets,
+// which may hold credentials the user has no other copy of, memory
+// snapshots, which may be the only copy of what Claude learned, and
+// configuration backups, which may be the only copy of what they saved.
+func planState(e env) ([]removal, []string, error) {
+	dir := filepath.Join(e.home, ".claude-workspace")
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	preserved := map[string]string{
+		filepath.Base(memory.SnapshotDir): "memory snapshots; delete them to erase the saved memory",
+		filepath.Base(backup.Dir):         "configuration backups; restore one with 'claude-workspace backup restore <file>' after reinstalling",
+	}
+	var remove, keep []string
+	var kept []string
+	for _, entry := range entries {
+		switch {
+		case isSecretsFile(entry.Name()):
+			keep = append(keep, entry.Name())
+		case preserved[entry.Name()] != "":
+			kept = append(kept, fmt.Sprintf("%s (%s)", filepath.Join(dir, entry.Name()), preserved[entry.Name()]))
+		default:
+			remove = append(remove, entry.Name())
+		}
+	}
+
+	var removals []removal
+	empty := len(keep) == 0 && len(kept) == 0
+	if len(keep) > 0 {
+		kept = append(kept, fmt.Sprintf("Stored secrets in %s (%s; delete them to erase the secrets)", dir, strings.Join(keep, ", ")))
+		if slices.Contains(keep, "secrets-index.json") {
+			kept = append(kept, "Secrets in the OS keychain (remove them with 'claude-workspace secrets rm <name>' before uninstalling)")
+		}
+	}
+	switch {
+	case len(remove) == 0 && empty:
+		removals = append(removals, removal{desc: "Remove " + dir, apply: func() error { return os.Remove(dir) }})
+	case len(remove) > 0:
+		removals = append(removals, removal{
+			desc: fmt.Sprintf("Remove %s (%s)", dir, strings.Join(remove, ", ")),
+			apply: func() error {
+				for _, name := range remove {
+					if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+						return err
+					}
+				}
+				if empty {
+					return os.Remove(dir)
+				}
+				return nil
+			},
+		})
+	}
+	return removals, kept, nil
+}
+
+func isSecretsFile(name string) bool {
+	return slices.Contains(secrets.StateFiles, name)
+}
+
+// planMCPServers removes the MCP servers setup registers from ~/.claude.json,
+// leaving every other server and setting in the file as it is.
+func planMCPServers(e env) ([]removal, []string, error) {
+	path := filepath.Join(e.home, ".claude.json")
+	if !platform.FileExists(path) {
+		return nil, nil, nil
+	}
+	var config map[string]interface{}
+	if err := platform.ReadJSONFile(path, &config); err != nil {
+		return nil, nil, err
+	}
+	servers, _ := config["mcpServers"].(map[string]interface{})
+
+	var names []string
+	for _, name := range setup.PlatformMCPServerNames() {
+		if _, ok := servers[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var removals []removal
+	if len(names) > 0 {
+		removals = append(removals, removal{
+			desc: fmt.Sprintf("Remove MCP server %s from %s", strings.Join(names, ", "), path),
+			apply: func() error {
+				// Re-read under the lock: Claude Code and other commands may
+				// have changed the file since the plan was made.
+				return platform.WithFileLock(path, func() error {
+					var current map[string]interface{}
+					if err := platform.ReadJSONFile(path, &current); err != nil {
+						return err
+					}
+					return platform.WriteJSONFile(path, setup.RemoveUserMCPServers(current, names))
+				})
+			},
+		})
+	}
+	kept := []string{path + " (other MCP servers and Claude Code state)"}
+	return removals, kept, nil
+}
+
+// planShell removes the lines setup and "completion install" added to shell
+// RC files when stripRC is set, and the fish completion loader. The PATH line
+// for ~/.local/bin stays when Claude Code is installed there.
+func planShell(e env, stripRC bool) ([]removal, []string) {
+	localBin := filepath.Join(e.home, ".local", "bin")
+	claudeInLocalBin := platform.FileExists(filepath.Join(localBin, "claude"))
+	keep := func(line string) bool {
+		return claudeInLocalBin && strings.Contains(line, ".local/bin")
+	}
+
+	var removals []removal
+	var kept []string
+	rcFiles := []string{
+		filepath.Join(e.home, ".bashrc"),
+		filepath.Join(e.home, ".zshrc"),
+		filepath.Join(e.home, ".config", "fish", "config.fish"),
+	}
+	for _, rc := range rcFiles {
+		data, err := os.ReadFile(rc)
+		if err != nil || !strings.Contains(string(data), platform.RCMarker) {
+			continue
+		}
+		if !stripRC {
+			kept = append(kept, fmt.Sprintf("Lines claude-workspace added to %s (pass --strip-rc to remove them)", rc))
+			continue
+		}
+		rc := rc
+		removals = append(removals, removal{
+			desc: "Remove the lines claude-workspace added to " + rc,
+			apply: func() error {
+				_, err := platform.RemoveRCAdditions(rc, keep)
+				return err
+			},
+		})
+		if claudeInLocalBin && strings.Contains(string(data), ".local/bin") {
+			kept = append(kept, fmt.Sprintf("The PATH entry for %s in %s (Claude Code is installed there)", localBin, rc))
+		}
+	}
+
+	if fish := completion.FishFile(e.home); platform.FileExists(fish) {
+		if stripRC {
+			removals = append(removals, removal{desc: "Remove " + fish, apply: func() error { return os.Remove(fish) }})
+		} else {
+			kept = append(kept, fish+" (pass --strip-rc to remove it)")
+		}
+	}
+	return removals, kept
+}
@@ -0,0 +1,225 @@
+package uninstall
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/completion"
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// installed sets up a home directory and install directory the way setup,
+// upgrade, and "completion install" leave them.
+func installed(t *testing.T) env {
+	t.Helper()
+	root := t.TempDir()
+	e := env{home: filepath.Join(root, "home"), installDir: filepath.Join(root, "bin")}
+
+	writeFile(t, filepath.Join(e.installDir, "claude-workspace"), "binary")
+	writeFile(t, filepath.Join(e.installDir, "claude-workspace.old"), "previous binary")
+	writeFile(t, filepath.Join(e.installDir, "claude-workspace.old.version"), "v1.0.0")
+
+	state := filepath.Join(e.home, ".claude-workspace")
+	writeFile(t, filepath.Join(state, "assets", "agents", "planner.md"), "agent")
+	writeFile(t, filepath.Join(state, "config.json"), "{}")
+	writeFile(t, filepath.Join(state, "logs", "setup.log"), "log")
+	writeFile(t, filepath.Join(state, "secrets.enc"), "secret")
+	writeFile(t, filepath.Join(state, "secrets.key"), "key")
+
+	writeFile(t, filepath.Join(e.home, ".claude.json"), `{
+  "numStartups": 3,
+  "mcpServers": {
+    "mcp-memory-libsql": {"command": "npx"},
+    "github": {"command": "gh-mcp"}
+  }
+}`)
+	writeFile(t, filepath.Join(e.home, ".zshrc"), "alias ll='ls -l'\n")
+	if _, err := platform.AppendPathToRC(e.home, "zsh", filepath.Join(e.home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := completion.Install(&bytes.Buffer{}, e.home, "zsh"); err != nil {
+		t.Fatal(err)
+	}
+	if err := completion.Install(&bytes.Buffer{}, e.home, "fish"); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestRun_RemovesPlatformFiles(t *testing.T) {
+	e := installed(t)
+	var buf bytes.Buffer
+	if err := run(&buf, strings.NewReader("y\n"), e, []string{"--strip-rc"}); err != nil {
+		t.Fatalf("run() error: %v\n%s", err, buf.String())
+	}
+
+	for _, path := range []string{
+		filepath.Join(e.installDir, "claude-workspace"),
+		filepath.Join(e.installDir, "claude-workspace.old"),
+		filepath.Join(e.installDir, "claude-workspace.old.version"),
+		filepath.Join(e.home, ".claude-workspace", "assets"),
+		filepath.Join(e.home, ".claude-workspace", "config.json"),
+		filepath.Join(e.home, ".claude-workspace", "logs"),
+		completion.FishFile(e.home),
+	} {
+		if platform.FileExists(path) {
+			t.Errorf("%s should be removed", path)
+		}
+	}
+	for _, name := range []string{"secrets.enc", "secrets.key"} {
+		if !platform.FileExists(filepath.Join(e.home, ".claude-workspace", name)) {
+			t.Errorf("%s should be kept", name)
+		}
+	}
+
+	var config map[string]interface{}
+	if err := platform.ReadJSONFile(filepath.Join(e.home, ".claude.json"), &config); err != nil {
+		t.Fatal(err)
+	}
+	servers := config["mcpServers"].(map[string]interface{})
+	if _, ok := servers["mcp-memory-libsql"]; ok {
+		t.Error("platform MCP server should be removed")
+	}
+	if _, ok := servers["github"]; !ok || config["numStartups"] != float64(3) {
+		t.Errorf("other settings in ~/.claude.json should be kept, got %v", config)
+	}
+
+	rc, _ := os.ReadFile(filepath.Join(e.home, ".zshrc"))
+	if string(rc) != "alias ll='ls -l'\n" {
+		t.Errorf(".zshrc = %q, want the added lines stripped", rc)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Left untouched", "Stored secrets in", "API keys", "claude-workspace has been uninstalled"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_KeepsMemorySnapshots(t *testing.T) {
+	e := installed(t)
+	snapshots := filepath.Join(e.home, ".claude-workspace", "snapshots")
+	writeFile(t, filepath.Join(snapshots, "memory-20260101-090000.json"), `{"version":1}`)
+	writeFile(t, filepath.Join(snapshots, "schedule.json"), `{"interval":"daily","keep":14,"via":"hook"}`)
+	settings := filepath.Join(e.home, ".claude", "settings.json")
+	writeFile(t, settings, `{"hooks":{"SessionEnd":[{"hooks":[{"type":"command","command":"claude-workspace memory snapshot run --if-due"}]}]}}`)
+
+	var buf bytes.Buffer
+	if err := run(&buf, nil, e, []string{"--yes"}); err != nil {
+		t.Fatalf("run() error: %v\n%s", err, buf.String())
+	}
+	if !platform.FileExists(filepath.Join(snapshots, "memory-20260101-090000.json")) {
+		t.Error("memory snapshots should be kept")
+	}
+	if platform.FileExists(filepath.Join(snapshots, "schedule.json")) {
+		t.Error("snapshot schedule should be removed")
+	}
+	if data, _ := os.ReadFile(settings); strings.Contains(string(data), "memory snapshot") {
+		t.Errorf("snapshot hook left in settings.json: %s", data)
+	}
+	if !strings.Contains(buf.String(), "memory snapshots; delete them") {
+		t.Errorf("output does not list the kept snapshots:\n%s", buf.String())
+	}
+}
+
+func TestRun_KeepsBackups(t *testing.T) {
+	e := installed(t)
+	backups := filepath.Join(e.home, ".claude-workspace", "backups")
+	writeFile(t, filepath.Join(backups, "claude-backup-20260101-090000.tar.gz"), "backup")
+
+	var buf bytes.Buffer
+	if err := run(&buf, nil, e, []string{"--dry-run"}); err != nil {
+		t.Fatalf("run() error: %v\n%s", err, buf.String())
+	}
+	if out := buf.String(); strings.Contains(out, "backups,") || strings.Contains(out, "backups)") || !strings.Contains(out, "configuration backups") {
+		t.Errorf("--dry-run should list the backups as kept, not removed:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := run(&buf, nil, e, []string{"--yes"}); err != nil {
+		t.Fatalf("run() error: %v\n%s", err, buf.String())
+	}
+	if !platform.FileExists(filepath.Join(backups, "claude-backup-20260101-090000.tar.gz")) {
+		t.Error("backups should be kept")
+	}
+}
+
+func TestRun_KeepsRCWithoutStripRC(t *testing.T) {
+	e := installed(t)
+	writeFile(t, filepath.Join(e.home, ".local", "bin", "claude"), "claude")
+	before, _ := os.ReadFile(filepath.Join(e.home, ".zshrc"))
+
+	var buf bytes.Buffer
+	if err := run(&buf, nil, e, []string{"--yes"}); err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	after, _ := os.ReadFile(filepath.Join(e.home, ".zshrc"))
+	if !bytes.Equal(before, after) || !platform.FileExists(completion.FishFile(e.home)) {
+		t.Error("shell files should be untouched without --strip-rc")
+	}
+	if !strings.Contains(buf.String(), "pass --strip-rc") {
+		t.Errorf("output should mention --strip-rc:\n%s", buf.String())
+	}
+}
+
+func TestRun_StripRCKeepsClaudePath(t *testing.T) {
+	e := installed(t)
+	writeFile(t, filepath.Join(e.home, ".local", "bin", "claude"), "claude")
+
+	if err := run(&bytes.Buffer{}, nil, e, []string{"--yes", "--strip-rc"}); err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	rc, _ := os.ReadFile(filepath.Join(e.home, ".zshrc"))
+	if !strings.Contains(string(rc), ".local/bin") || strings.Contains(string(rc), "completion zsh") {
+		t.Errorf(".zshrc = %q, want the PATH line kept and completion removed", rc)
+	}
+}
+
+func TestRun_DryRunAndConfirmation(t *testing.T) {
+	e := installed(t)
+	binary := filepath.Join(e.installDir, "claude-workspace")
+
+	var buf bytes.Buffer
+	if err := run(&buf, nil, e, []string{"--dry-run", "--strip-rc"}); err != nil {
+		t.Fatalf("dry run error: %v", err)
+	}
+	if !platform.FileExists(binary) || !strings.Contains(buf.String(), "Remove "+binary) {
+		t.Errorf("dry run should list the binary and keep it:\n%s", buf.String())
+	}
+
+	if err := run(&bytes.Buffer{}, nil, e, nil); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("run() without a terminal = %v, want ErrNotConfirmed", err)
+	}
+	if err := run(&bytes.Buffer{}, strings.NewReader("n\n"), e, nil); err != nil || !platform.FileExists(binary) {
+		t.Errorf("answering no should change nothing (err %v)", err)
+	}
+	if err := run(&bytes.Buffer{}, nil, e, []string{"--purge"}); err == nil {
+		t.Error("unknown flag should be rejected")
+	}
+}
+
+func TestRun_NothingInstalled(t *testing.T) {
+	e := env{home: t.TempDir(), installDir: t.TempDir()}
+	var buf bytes.Buffer
+	if err := run(&buf, nil, e, nil); err != nil {
+		t.Fatalf("run() error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Nothing to remove") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -0,0 +1,216 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// Release channels. Stable follows GitHub's latest release; beta also accepts
+// prereleases such as v1.6.0-beta.1 and v1.6.0-rc.1; nightly accepts any
+// release, including the per-commit v1.6.1-alpha.12.abc1234 builds from main.
+const (
+	ChannelStable  = "stable"
+	ChannelBeta    = "beta"
+	ChannelNightly = "nightly"
+)
+
+// Channels lists the valid release channels.
+var Channels = []string{ChannelStable, ChannelBeta, ChannelNightly}
+
+// ReleasesListURL is the GitHub API endpoint listing recent releases, newest
+// first. Like ReleasesURL, tests override it with a local server.
+var ReleasesListURL = "https://api.github.com/repos/lamchakchan/claude-workspace/releases?per_page=50"
+
+// configKey is the ~/.claude-workspace/config.json key holding the channel.
+const configKey = "upgradeChannel"
+
+// ValidChannel reports whether name is a release channel.
+func ValidChannel(name string) bool {
+	for _, c := range Channels {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadChannel returns the release channel saved by "upgrade --channel", or
+// stable when none is saved or the config cannot be read.
+func LoadChannel() string {
+	var channel string
+	if ok, err := platform.ReadConfig(configKey, &channel); !ok || err != nil || !ValidChannel(channel) {
+		return ChannelStable
+	}
+	return channel
+}
+
+// SaveChannel records channel in ~/.claude-workspace/config.json, keeping any
+// other settings in the file.
+func SaveChannel(channel string) error {
+	if !ValidChannel(channel) {
+		return fmt.Errorf("unknown channel %q (valid: %s)", channel, strings.Join(Channels, ", "))
+	}
+	return platform.WriteConfig(configKey, channel)
+}
+
+// FetchChannel fetches the newest release on channel. Stable uses GitHub's
+// latest release; beta and nightly pick the most recently published
+// non-draft release the channel accepts.
+func FetchChannel(channel string) (*Release, error) {
+	if channel == "" || channel == ChannelStable {
+		return FetchLatest()
+	}
+	if !ValidChannel(channel) {
+		return nil, fmt.Errorf("unknown channel %q (valid: %s)", channel, strings.Join(Channels, ", "))
+	}
+
+	client := platform.HTTPClient(10 * time.Second)
+	req, err := http.NewRequest("GET", ReleasesListURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 403 || resp.StatusCode == 429 {
+		return nil, fmt.Errorf("GitHub API rate limited. Try again later")
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("parsing releases response: %w", err)
+	}
+
+	var newest *Release
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || !channelAccepts(channel, r.TagName) {
+			continue
+		}
+		if newest == nil || r.PublishedAt > newest.PublishedAt {
+			newest = r
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("no %s release found", channel)
+	}
+	return newest, nil
+}
+
+// channelAccepts reports whether a release tagged tag belongs on channel.
+func channelAccepts(channel, tag string) bool {
+	pre := prerelease(tag)
+	switch channel {
+	case ChannelNightly:
+		return true
+	case ChannelBeta:
+		return pre == "" || strings.HasPrefix(pre, "beta") || strings.HasPrefix(pre, "rc")
+	default:
+		return pre == ""
+	}
+}
+
+// prerelease returns the prerelease part of a version tag ("beta.1" for
+// v1.6.0-beta.1), or "" for a stable version.
+func prerelease(tag string) string {
+	v, _, _ := strings.Cut(tag, "+")
+	_, pre, _ := strings.Cut(v, "-")
+	return pre
+}
+
+// UpdateAvailable reports whether latest is a newer version than current.
+// A dev build always has an update available; a version ahead of the
+// channel, such as a beta after switching back to stable, does not.
+func UpdateAvailable(current, latest string) bool {
+	if current == "dev" || current == "" {
+		return true
+	}
+	return compareVersions(latest, current) > 0
+}
+
+// compareVersions compares two vX.Y.Z[-pre] versions by semver precedence,
+// returning -1, 0, or 1.
+func compareVersions(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	if c := compareIdentifiers(strings.Split(aCore, "."), strings.Split(bCore, "."), true); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareIdentifiers(strings.Split(aPre, "."), strings.Split(bPre, "."), false)
+}
+
+// compareIdentifiers compares dot-separated version identifiers: numeric ones
+// numerically, others lexically, and numeric before alphanumeric. When core
+// is set, missing identifiers count as 0; otherwise the shorter list is lower.
+func compareIdentifiers(a, b []string, core bool) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i >= len(a) || i >= len(b) {
+			if core {
+				x, y := "0", "0"
+				if i < len(a) {
+					x = a[i]
+				}
+				if i < len(b) {
+					y = b[i]
+				}
+				if c := compareIdentifier(x, y); c != 0 {
+					return c
+				}
+				continue
+			}
+			if i >= len(a) {
+				return -1
+			}
+			return 1
+		}
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareIdentifier(x, y string) int {
+	xn, xErr := strconv.Atoi(x)
+	yn, yErr := strconv.Atoi(y)
+	switch {
+	case xErr == nil && yErr == nil:
+		switch {
+		case xn < yn:
+			return -1
+		case xn > yn:
+			return 1
+		}
+		return 0
+	case xErr == nil:
+		return -1
+	case yErr == nil:
+		return 1
+	}
+	return strings.Compare(x, y)
+}
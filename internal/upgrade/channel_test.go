@@ -0,0 +1,144 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChannelConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if got := LoadChannel(); got != ChannelStable {
+		t.Errorf("LoadChannel() with no config = %q, want stable", got)
+	}
+
+	path := filepath.Join(home, ".claude-workspace", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"other": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveChannel(ChannelBeta); err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+	if got := LoadChannel(); got != ChannelBeta {
+		t.Errorf("LoadChannel() = %q, want beta", got)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"other": true`) {
+		t.Errorf("SaveChannel() dropped other settings:\n%s", data)
+	}
+
+	if err := SaveChannel("canary"); err == nil {
+		t.Error("SaveChannel(canary) expected an error")
+	}
+	if err := os.WriteFile(path, []byte(`{"upgradeChannel": "canary"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadChannel(); got != ChannelStable {
+		t.Errorf("LoadChannel() with an invalid channel = %q, want stable", got)
+	}
+}
+
+func TestFetchChannel(t *testing.T) {
+	releases := []Release{
+		{TagName: "v1.7.0-alpha.14.abc1234", PublishedAt: "2026-03-02T00:00:00Z", Prerelease: true},
+		{TagName: "v1.7.0-beta.2", PublishedAt: "2026-03-03T00:00:00Z", Draft: true},
+		{TagName: "v1.7.0-beta.1", PublishedAt: "2026-03-01T00:00:00Z", Prerelease: true},
+		{TagName: "v1.6.0", PublishedAt: "2026-02-20T00:00:00Z"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(releases)
+	}))
+	defer server.Close()
+
+	origURL := ReleasesListURL
+	ReleasesListURL = server.URL
+	defer func() { ReleasesListURL = origURL }()
+
+	for channel, want := range map[string]string{
+		ChannelBeta:    "v1.7.0-beta.1",
+		ChannelNightly: "v1.7.0-alpha.14.abc1234",
+	} {
+		got, err := FetchChannel(channel)
+		if err != nil {
+			t.Fatalf("FetchChannel(%s) error = %v", channel, err)
+		}
+		if got.TagName != want {
+			t.Errorf("FetchChannel(%s) = %s, want %s", channel, got.TagName, want)
+		}
+	}
+
+	if _, err := FetchChannel("canary"); err == nil {
+		t.Error("FetchChannel(canary) expected an error")
+	}
+}
+
+func TestChannelAccepts(t *testing.T) {
+	cases := []struct {
+		channel, tag string
+		want         bool
+	}{
+		{ChannelStable, "v1.6.0", true},
+		{ChannelStable, "v1.7.0-beta.1", false},
+		{ChannelBeta, "v1.6.0", true},
+		{ChannelBeta, "v1.7.0-rc.1", true},
+		{ChannelBeta, "v1.7.0-alpha.3.abc1234", false},
+		{ChannelNightly, "v1.7.0-alpha.3.abc1234", true},
+	}
+	for _, c := range cases {
+		if got := channelAccepts(c.channel, c.tag); got != c.want {
+			t.Errorf("channelAccepts(%s, %s) = %v, want %v", c.channel, c.tag, got, c.want)
+		}
+	}
+}
+
+func TestUpdateAvailable(t *testing.T) {
+	cases := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"1.5.0", "v1.5.0", false},
+		{"v1.5.0", "v1.6.0", true},
+		{"1.5.9", "v1.5.10", true},
+		{"v1.6.0-beta.2", "v1.5.0", false},
+		{"v1.6.0-beta.2", "v1.6.0", true},
+		{"v1.6.0-beta.2", "v1.6.0-beta.10", true},
+		{"v1.6.0-beta.2", "v1.6.0-rc.1", true},
+		{"v1.6.0-rc.1", "v1.6.0-beta.3", false},
+		{"dev", "v1.0.0", true},
+	}
+	for _, c := range cases {
+		if got := UpdateAvailable(c.current, c.latest); got != c.want {
+			t.Errorf("UpdateAvailable(%s, %s) = %v, want %v", c.current, c.latest, got, c.want)
+		}
+	}
+}
+
+func TestRunCheckDoesNotSaveChannel(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Release{{TagName: "v1.1.0-beta.1", PublishedAt: "2026-03-01T00:00:00Z"}})
+	}))
+	defer server.Close()
+
+	origURL := ReleasesListURL
+	ReleasesListURL = server.URL
+	defer func() { ReleasesListURL = origURL }()
+
+	if err := Run("1.1.0-beta.1", []string{"--self-only", "--check", "--channel", "beta"}); err != nil {
+		t.Errorf("Run(--check --channel beta, up-to-date) error = %v, want nil", err)
+	}
+	if got := LoadChannel(); got != ChannelStable {
+		t.Errorf("--check saved channel %q", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -24,6 +25,8 @@ type Release struct {
 	TagName     string         `json:"tag_name"`
 	Body        string         `json:"body"`
 	PublishedAt string         `json:"published_at"`
+	Draft       bool           `json:"draft"`
+	Prerelease  bool           `json:"prerelease"`
 	Assets      []ReleaseAsset `json:"assets"`
 }
 
@@ -36,7 +39,7 @@ type ReleaseAsset struct {
 
 // FetchLatest fetches the latest release metadata from GitHub.
 func FetchLatest() (*Release, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := platform.HTTPClient(10 * time.Second)
 	req, err := http.NewRequest("GET", ReleasesURL, nil)
 	if err != nil {
 		return nil, err
@@ -81,47 +84,118 @@ func FindAsset(release *Release) (*ReleaseAsset, error) {
 	return nil, fmt.Errorf("no release asset found for %s/%s (expected %s)", osName, archName, expected)
 }
 
-// DownloadAsset downloads a release asset to the given destination path.
-func DownloadAsset(asset ReleaseAsset, dest string) error {
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Get(asset.BrowserDownloadURL)
-	if err != nil {
-		return fmt.Errorf("downloading %s: %w", asset.Name, err)
-	}
-	defer resp.Body.Close()
+// downloadAttempts is how many times DownloadAsset tries before giving up.
+const downloadAttempts = 5
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("download returned status %d", resp.StatusCode)
-	}
+// retryBackoff is the wait before the first retry of a download; it doubles
+// after each failed attempt. It is a variable so tests can shorten it.
+var retryBackoff = 2 * time.Second
 
+// DownloadAsset downloads a release asset to the given destination path,
+// showing progress. An interrupted download is retried with backoff and
+// resumed where it stopped when the server supports range requests.
+func DownloadAsset(asset ReleaseAsset, dest string) error {
 	out, err := os.Create(dest)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	sizeMB := float64(asset.Size) / 1024 / 1024
-	fmt.Printf("  %s [%.1f MB] ", asset.Name, sizeMB)
+	client := platform.HTTPClient(5 * time.Minute)
+	w := platform.Stdout()
+	var written int64
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		written, err = downloadFrom(client, asset, out, written, w)
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt == downloadAttempts {
+			out.Close()
+			os.Remove(dest)
+			if permanent != nil {
+				return permanent.err
+			}
+			return fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+		}
+		platform.PrintWarningLine(w, fmt.Sprintf("Download interrupted: %v. Retrying in %s...", err, backoff))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// permanentError is a download failure that retrying cannot fix.
+type permanentError struct{ err error }
 
-	// Copy with progress indicator
-	written, err := io.Copy(out, resp.Body)
+func (e *permanentError) Error() string { return e.err.Error() }
+
+// downloadFrom fetches asset into out, asking for the bytes after offset when
+// offset > 0, and returns how many bytes out holds. A server that ignores the
+// range sends the whole file, which replaces what out held.
+func downloadFrom(client *http.Client, asset ReleaseAsset, out *os.File, offset int64, w io.Writer) (int64, error) {
+	req, err := http.NewRequest("GET", asset.BrowserDownloadURL, nil)
+	if err != nil {
+		return offset, &permanentError{err}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
 	if err != nil {
-		os.Remove(dest)
-		return fmt.Errorf("writing download: %w", err)
+		return offset, fmt.Errorf("downloading %s: %w", asset.Name, err)
 	}
+	defer resp.Body.Close()
 
-	fmt.Println("done.")
+	start := int64(0)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)):
+		start = offset
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable || resp.StatusCode == http.StatusPartialContent:
+		// The range no longer matches the file; start over.
+		return 0, fmt.Errorf("download range rejected (status %d)", resp.StatusCode)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return offset, fmt.Errorf("download returned status %d", resp.StatusCode)
+	default:
+		return offset, &permanentError{fmt.Errorf("download returned status %d", resp.StatusCode)}
+	}
+	if _, err := out.Seek(start, io.SeekStart); err != nil {
+		return start, &permanentError{fmt.Errorf("writing download: %w", err)}
+	}
+	if err := out.Truncate(start); err != nil {
+		return start, &permanentError{fmt.Errorf("writing download: %w", err)}
+	}
 
+	bar := platform.StartProgress(w, asset.Name, start, asset.Size)
+	n, err := io.Copy(io.MultiWriter(out, bar), resp.Body)
+	written := start + n
+	if err != nil {
+		bar.Stop()
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return written, &permanentError{fmt.Errorf("writing download: %w", err)}
+		}
+		return written, fmt.Errorf("reading download: %w", err)
+	}
 	if asset.Size > 0 && written != asset.Size {
-		os.Remove(dest)
-		return fmt.Errorf("download incomplete: got %d bytes, expected %d", written, asset.Size)
+		bar.Stop()
+		err := fmt.Errorf("download incomplete: got %d bytes, expected %d", written, asset.Size)
+		if written > asset.Size {
+			return written, &permanentError{err}
+		}
+		return written, err
 	}
-
-	return nil
+	bar.Finish()
+	return written, nil
 }
 
-// VerifyChecksum verifies the downloaded file against checksums.txt from the release.
-func VerifyChecksum(release *Release, filePath, assetName string) error {
+// VerifyChecksum verifies the downloaded file against checksums.txt from the
+// release, after checking the signature of checksums.txt against PublicKey.
+// skipSignature (--skip-signature) bypasses the signature check.
+func VerifyChecksum(release *Release, filePath, assetName string, skipSignature bool) error {
+	verifySig := signatureRequired(skipSignature)
+
 	// Find checksums.txt asset
 	var checksumAsset *ReleaseAsset
 	for i := range release.Assets {
@@ -130,27 +204,36 @@ func VerifyChecksum(release *Release, filePath, assetName string) error {
 			break
 		}
 	}
+	out := platform.Stdout()
 	if checksumAsset == nil {
-		platform.PrintWarningLine(os.Stdout, "No checksums.txt found in release, skipping verification.")
+		if verifySig {
+			return fmt.Errorf("release %s has no checksums.txt; refusing to install an unverified release (use --skip-signature to override)", release.TagName)
+		}
+		platform.PrintWarningLine(out, "No checksums.txt found in release, skipping verification.")
 		return nil
 	}
 
-	// Download checksums.txt
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(checksumAsset.BrowserDownloadURL)
+	body, err := fetchAsset(*checksumAsset)
 	if err != nil {
-		return fmt.Errorf("fetching checksums.txt: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading checksums.txt: %w", err)
+	if verifySig {
+		if err := verifyChecksumsSignature(release, body); err != nil {
+			return err
+		}
+		platform.PrintSuccess(out, "Signature verified.")
 	}
 
+	return matchChecksum(body, filePath, assetName)
+}
+
+// matchChecksum checks the SHA-256 of filePath against the entry for
+// assetName in checksums (the content of checksums.txt).
+func matchChecksum(checksums []byte, filePath, assetName string) error {
 	// Parse checksums.txt: each line is "HASH  FILENAME"
 	var expectedHash string
-	for _, line := range strings.Split(string(body), "\n") {
+	for _, line := range strings.Split(string(checksums), "\n") {
 		parts := strings.Fields(line)
 		if len(parts) == 2 && parts[1] == assetName {
 			expectedHash = parts[0]
@@ -178,6 +261,38 @@ func VerifyChecksum(release *Release, filePath, assetName string) error {
 		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedHash, actualHash)
 	}
 
-	platform.PrintSuccess(os.Stdout, "Checksum verified.")
+	platform.PrintSuccess(platform.Stdout(), "Checksum verified.")
 	return nil
 }
+
+// signatureRequired reports whether checksums.txt must carry a valid
+// signature, warning when verification is skipped.
+func signatureRequired(skipSignature bool) bool {
+	out := platform.Stdout()
+	switch {
+	case skipSignature:
+		platform.PrintWarningLine(out, "Skipping release signature verification (--skip-signature).")
+	case PublicKey == "":
+		platform.PrintWarningLine(out, "This build has no release signing key, skipping signature verification.")
+	}
+	return PublicKey != "" && !skipSignature
+}
+
+// fetchAsset downloads a small release asset, such as checksums.txt, into memory.
+func fetchAsset(asset ReleaseAsset) ([]byte, error) {
+	client := platform.HTTPClient(10 * time.Second)
+	resp, err := client.Get(asset.BrowserDownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("fetching %s: status %d", asset.Name, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", asset.Name, err)
+	}
+	return body, nil
+}
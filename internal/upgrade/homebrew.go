@@ -0,0 +1,138 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// brewFormula is the Homebrew formula claude-workspace is installed from.
+const brewFormula = "claude-workspace"
+
+// executable returns the path of the running binary; tests replace it.
+var executable = os.Executable
+
+// isHomebrewBinary reports whether the given path belongs to a Homebrew-managed
+// installation: one under a Cellar, the default prefixes on macOS and Linux,
+// or the prefix and Cellar the HOMEBREW_PREFIX and HOMEBREW_CELLAR variables
+// name.
+func isHomebrewBinary(path string) bool {
+	if strings.Contains(path, "/opt/homebrew/") ||
+		strings.Contains(path, "/Cellar/") ||
+		strings.Contains(path, "/usr/local/opt/") ||
+		strings.Contains(path, "/home/linuxbrew/.linuxbrew/") {
+		return true
+	}
+	for _, env := range []string{"HOMEBREW_PREFIX", "HOMEBREW_CELLAR"} {
+		dir := os.Getenv(env)
+		if dir != "" && strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelfHomebrew reports whether the currently running claude-workspace binary
+// was installed via Homebrew.
+func isSelfHomebrew() bool {
+	exe, err := executable()
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(exe)
+	if err != nil {
+		return false
+	}
+	return isHomebrewBinary(resolved)
+}
+
+// brewStatus is what Homebrew knows about the installed formula.
+type brewStatus struct {
+	Outdated bool
+	Latest   string // version brew would upgrade to, if outdated
+	Pinned   bool   // brew pin holds the formula back
+}
+
+// brewOutdated asks Homebrew whether the formula has a newer version.
+func brewOutdated() (brewStatus, error) {
+	// brew outdated exits non-zero when a named formula is outdated, so its
+	// output decides.
+	out, err := platform.Output("brew", "outdated", "--json=v2", brewFormula)
+	if out == "" && err != nil {
+		return brewStatus{}, err
+	}
+	return parseBrewOutdated([]byte(out))
+}
+
+// parseBrewOutdated reads the output of brew outdated --json=v2 for the
+// formula. A formula that is not listed is up to date.
+func parseBrewOutdated(data []byte) (brewStatus, error) {
+	var doc struct {
+		Formulae []struct {
+			Name           string `json:"name"`
+			CurrentVersion string `json:"current_version"`
+			Pinned         bool   `json:"pinned"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return brewStatus{}, fmt.Errorf("reading brew outdated output: %w", err)
+	}
+	for _, f := range doc.Formulae {
+		if f.Name != brewFormula && !strings.HasSuffix(f.Name, "/"+brewFormula) {
+			continue
+		}
+		return brewStatus{Outdated: true, Latest: f.CurrentVersion, Pinned: f.Pinned}, nil
+	}
+	return brewStatus{}, nil
+}
+
+// upgradeHomebrew upgrades a Homebrew installation with brew, which owns the
+// binary, rather than replacing it in place (steps 1-4). Homebrew has no
+// channels: the formula tracks stable releases.
+func upgradeHomebrew(version string, f upgradeFlags, s *stepper) error {
+	out := platform.Stdout()
+	if f.channel != ChannelStable {
+		platform.PrintWarningLine(out, fmt.Sprintf("Homebrew installs follow the stable channel; ignoring channel %s.", f.channel))
+	}
+
+	platform.PrintStep(out, s.next(), s.total, "Checking Homebrew for updates...")
+	fmt.Fprintf(out, "  Current: %s\n", version)
+	// brew outdated only reads the local tap, which can be days old.
+	if _, err := platform.Output("brew", "update", "--quiet"); err != nil {
+		platform.PrintWarningLine(out, fmt.Sprintf("brew update failed (%v); Homebrew's formula data may be stale", err))
+	}
+	status, err := brewOutdated()
+	if err != nil {
+		return fmt.Errorf("checking Homebrew for updates: %w (to upgrade manually: brew upgrade %s)", err, brewFormula)
+	}
+	if !status.Outdated {
+		fmt.Fprintln(out, "\n  Already up to date.")
+		return nil
+	}
+	fmt.Fprintf(out, "  Latest:  %s\n", status.Latest)
+	if status.Pinned {
+		return fmt.Errorf("%s is pinned in Homebrew; run 'brew unpin %s' to allow upgrades", brewFormula, brewFormula)
+	}
+
+	if f.checkOnly {
+		return handleCheckOnly(f, s)
+	}
+
+	if !f.autoYes && !confirm("Upgrade cancelled.") {
+		return nil
+	}
+
+	platform.PrintStep(out, s.next(), s.total, fmt.Sprintf("Running brew upgrade %s...", brewFormula))
+	if err := platform.Run("brew", "upgrade", brewFormula); err != nil {
+		return fmt.Errorf("brew upgrade %s: %w", brewFormula, err)
+	}
+	fmt.Fprintf(out, "  claude-workspace upgraded with Homebrew (%s → %s)\n", version, status.Latest)
+
+	refreshAssets(s, version)
+	mergeSettings(s, false)
+	return nil
+}
@@ -0,0 +1,152 @@
+package upgrade
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+func TestParseBrewOutdated(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    brewStatus
+		wantErr bool
+	}{
+		{"up to date", `{"formulae":[],"casks":[]}`, brewStatus{}, false},
+		{"outdated", `{"formulae":[{"name":"claude-workspace","installed_versions":["1.4.0"],"current_version":"1.5.0","pinned":false}],"casks":[]}`,
+			brewStatus{Outdated: true, Latest: "1.5.0"}, false},
+		{"tap name", `{"formulae":[{"name":"lamchakchan/tap/claude-workspace","current_version":"1.5.0","pinned":true}]}`,
+			brewStatus{Outdated: true, Latest: "1.5.0", Pinned: true}, false},
+		{"other formula", `{"formulae":[{"name":"claude-code","current_version":"2.0.0"}]}`, brewStatus{}, false},
+		{"not json", `Error: No available formula`, brewStatus{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseBrewOutdated([]byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseBrewOutdated() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: parseBrewOutdated() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fakeHomebrew makes the running binary look installed by Homebrew, and puts
+// a brew on PATH that answers outdated with outdated and records the
+// arguments of every call in the returned log. The shared assets an upgrade
+// refreshes and the settings it merges come from stand-in templates.
+func fakeHomebrew(t *testing.T, outdated string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on Windows")
+	}
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "Cellar", "claude-workspace", "1.4.0", "bin", "claude-workspace")
+	if err := os.MkdirAll(filepath.Dir(exe), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exe, []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	origExecutable := executable
+	executable = func() (string, error) { return exe, nil }
+	t.Cleanup(func() { executable = origExecutable })
+
+	binDir := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "brew.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\nif [ \"$1\" = outdated ]; then\n  cat <<'JSON'\n" + outdated + "\nJSON\n  exit 1\nfi\n"
+	if err := os.WriteFile(filepath.Join(binDir, "brew"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", t.TempDir())
+	oldFS := platform.FS
+	platform.FS = fstest.MapFS{
+		".claude/agents/planner.md": {Data: []byte("planner")},
+		".mcp.json":                 {Data: []byte("{}")},
+	}
+	oldGlobalFS := platform.GlobalFS
+	platform.GlobalFS = fstest.MapFS{"settings.json": {Data: []byte("{}")}}
+	t.Cleanup(func() { platform.FS, platform.GlobalFS = oldFS, oldGlobalFS })
+	return log
+}
+
+func brewCalls(t *testing.T, log string) string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+const brewOutdatedJSON = `{"formulae":[{"name":"claude-workspace","installed_versions":["1.4.0"],"current_version":"1.5.0","pinned":false}],"casks":[]}`
+
+func TestRunHomebrewUpgrade(t *testing.T) {
+	log := fakeHomebrew(t, brewOutdatedJSON)
+
+	if err := Run("1.4.0", []string{"--self-only", "--yes"}); err != nil {
+		t.Fatalf("Run(--self-only, Homebrew) error = %v", err)
+	}
+	calls := brewCalls(t, log)
+	if !strings.Contains(calls, "upgrade claude-workspace") {
+		t.Errorf("brew calls = %q, want brew upgrade claude-workspace", calls)
+	}
+}
+
+func TestRunHomebrewCheck(t *testing.T) {
+	log := fakeHomebrew(t, brewOutdatedJSON)
+
+	if err := Run("1.4.0", []string{"--self-only", "--check"}); err != ErrUpdateAvailable {
+		t.Errorf("Run(--check, Homebrew) error = %v, want %v", err, ErrUpdateAvailable)
+	}
+	calls := brewCalls(t, log)
+	if strings.Contains(calls, "upgrade") {
+		t.Errorf("--check ran %q, want only brew update and outdated", calls)
+	}
+	if !strings.HasPrefix(calls, "update") {
+		t.Errorf("brew calls = %q, want brew update before outdated", calls)
+	}
+}
+
+func TestRunHomebrewPinned(t *testing.T) {
+	log := fakeHomebrew(t, strings.Replace(brewOutdatedJSON, `"pinned":false`, `"pinned":true`, 1))
+
+	err := Run("1.4.0", []string{"--self-only", "--yes"})
+	if err == nil || !strings.Contains(err.Error(), "brew unpin") {
+		t.Errorf("Run(pinned formula) error = %v, want a brew unpin hint", err)
+	}
+	if calls := brewCalls(t, log); strings.Contains(calls, "upgrade") {
+		t.Errorf("pinned formula ran %q, want no brew upgrade", calls)
+	}
+}
+
+func TestRunHomebrewUpToDate(t *testing.T) {
+	log := fakeHomebrew(t, `{"formulae":[],"casks":[]}`)
+
+	if err := Run("1.5.0", []string{"--self-only", "--yes"}); err != nil {
+		t.Errorf("Run(up to date, Homebrew) error = %v", err)
+	}
+	if calls := brewCalls(t, log); strings.Contains(calls, "upgrade") {
+		t.Errorf("up-to-date formula ran %q, want no brew upgrade", calls)
+	}
+}
+
+func TestRunHomebrewRefusesFromFile(t *testing.T) {
+	fakeHomebrew(t, brewOutdatedJSON)
+
+	err := Run("1.4.0", []string{"--from-file", filepath.Join(t.TempDir(), "claude-workspace_1.5.0_linux_amd64.tar.gz")})
+	if err == nil || !strings.Contains(err.Error(), "Homebrew") {
+		t.Errorf("Run(--from-file, Homebrew) error = %v, want a Homebrew error", err)
+	}
+}
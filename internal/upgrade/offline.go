@@ -0,0 +1,123 @@
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// ErrFromFileExclusive is returned when --from-file is combined with flags
+// that need GitHub or the Claude Code installer.
+var ErrFromFileExclusive = fmt.Errorf("--from-file cannot be combined with --check, --cli-only, --channel, or --rollback")
+
+// archivePattern matches release archive names,
+// claude-workspace_VERSION_OS_ARCH.tar.gz.
+var archivePattern = regexp.MustCompile(`^claude-workspace_(.+)_([a-z0-9]+)_([a-z0-9]+)\.tar\.gz$`)
+
+// archiveVersion returns the version of a release archive from its file name
+// and checks that it was built for this platform. The name must be kept as
+// published, since checksums.txt lists archives by name.
+func archiveVersion(name string) (string, error) {
+	m := archivePattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", fmt.Errorf("%s is not a release archive (expected claude-workspace_VERSION_%s_%s.tar.gz as published on the release page)", name, runtime.GOOS, runtime.GOARCH)
+	}
+	if m[2] != runtime.GOOS || m[3] != runtime.GOARCH {
+		return "", fmt.Errorf("%s is built for %s/%s, not %s/%s", name, m[2], m[3], runtime.GOOS, runtime.GOARCH)
+	}
+	return "v" + m[1], nil
+}
+
+// verifyLocalArchive checks an archive on disk against the checksums.txt, and
+// the checksums.txt.sig, copied into the same directory from the release.
+func verifyLocalArchive(archivePath string, skipSignature bool) error {
+	dir := filepath.Dir(archivePath)
+	checksumsPath := filepath.Join(dir, "checksums.txt")
+	verifySig := signatureRequired(skipSignature)
+
+	out := platform.Stdout()
+	if !platform.FileExists(checksumsPath) {
+		if verifySig {
+			return fmt.Errorf("no checksums.txt next to %s; copy checksums.txt and %s from the release alongside the archive (use --skip-signature to override)", filepath.Base(archivePath), signatureAsset)
+		}
+		platform.PrintWarningLine(out, "No checksums.txt next to the archive, skipping verification.")
+		return nil
+	}
+	checksums, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		return err
+	}
+
+	if verifySig {
+		sig, err := os.ReadFile(filepath.Join(dir, signatureAsset))
+		if err != nil {
+			return fmt.Errorf("no %s next to %s; refusing to install an unsigned release (use --skip-signature to override)", signatureAsset, filepath.Base(archivePath))
+		}
+		if err := platform.VerifySignature(PublicKey, checksums, sig); err != nil {
+			return fmt.Errorf("%w for %s: %v. Do not install this archive; fetch the release files again", ErrSignatureInvalid, checksumsPath, err)
+		}
+		platform.PrintSuccess(out, "Signature verified.")
+	}
+
+	return matchChecksum(checksums, archivePath, filepath.Base(archivePath))
+}
+
+// installFromFile upgrades claude-workspace from a release archive on disk
+// (--from-file), for machines that cannot reach GitHub. The Claude Code CLI is
+// left as is, since its installer downloads from claude.ai.
+func installFromFile(version, archivePath string, f upgradeFlags, s *stepper) error {
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Upgrading claude-workspace from file")
+
+	if f.homebrew {
+		return fmt.Errorf("--from-file is not available for Homebrew installations; install the archive's binary over the Homebrew one or use brew")
+	}
+
+	platform.PrintStep(out, s.next(), s.total, "Verifying archive...")
+	if !platform.FileExists(archivePath) {
+		return fmt.Errorf("archive not found: %s", archivePath)
+	}
+	newVersion, err := archiveVersion(filepath.Base(archivePath))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "  Current: %s\n", version)
+	fmt.Fprintf(out, "  Archive: %s (%s)\n", archivePath, newVersion)
+
+	if err := verifyLocalArchive(archivePath, f.skipSig); err != nil {
+		return err
+	}
+
+	if !f.autoYes && !confirm("Upgrade cancelled.") {
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "claude-workspace-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("creating temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	platform.PrintStep(out, s.next(), s.total, "Extracting binary...")
+	binaryPath, err := extractBinary(archivePath, tmpDir)
+	if err != nil {
+		return fmt.Errorf("extracting binary: %w", err)
+	}
+
+	platform.PrintStep(out, s.next(), s.total, "Replacing binary...")
+	if err := ReplaceBinary(binaryPath, version); err != nil {
+		return fmt.Errorf("replacing binary: %w", err)
+	}
+	installPath, _ := installedBinary()
+	fmt.Fprintf(out, "  %s updated (%s → %s)\n", installPath, version, newVersion)
+
+	refreshAssets(s, version)
+	mergeSettings(s, true)
+
+	fmt.Fprintln(out, "\n  Claude Code CLI not upgraded: its installer needs network access.")
+	return nil
+}
@@ -0,0 +1,111 @@
+package upgrade
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform/signtest"
+)
+
+// localRelease writes a release archive and its checksums.txt to a temp dir,
+// plus checksums.txt.sig unless sign is nil. It returns the archive path.
+func localRelease(t *testing.T, sign func(checksums []byte) []byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	name := fmt.Sprintf("claude-workspace_1.6.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	archive := filepath.Join(dir, name)
+	createTestArchive(t, archive, "claude-workspace", "binary-content")
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	checksums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name))
+	if err := os.WriteFile(filepath.Join(dir, "checksums.txt"), checksums, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if sign != nil {
+		if err := os.WriteFile(filepath.Join(dir, signatureAsset), sign(checksums), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return archive
+}
+
+func TestArchiveVersion(t *testing.T) {
+	native := fmt.Sprintf("claude-workspace_1.6.0-beta.1_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	if got, err := archiveVersion(native); err != nil || got != "v1.6.0-beta.1" {
+		t.Errorf("archiveVersion(%q) = %q, %v; want v1.6.0-beta.1", native, got, err)
+	}
+
+	foreign := "claude-workspace_1.6.0_plan9_mips.tar.gz"
+	if _, err := archiveVersion(foreign); err == nil || !strings.Contains(err.Error(), "plan9/mips") {
+		t.Errorf("archiveVersion(%q) error = %v, want a platform mismatch", foreign, err)
+	}
+
+	if _, err := archiveVersion("upgrade.tar.gz"); err == nil {
+		t.Error("archiveVersion() should reject a renamed archive")
+	}
+}
+
+func TestVerifyLocalArchive(t *testing.T) {
+	priv, pub := signtest.Key(t)
+	withPublicKey(t, pub)
+
+	if err := verifyLocalArchive(localRelease(t, signtest.Signer(t, priv)), false); err != nil {
+		t.Fatalf("verifyLocalArchive() with a valid signature error = %v", err)
+	}
+
+	other, _ := signtest.Key(t)
+	if err := verifyLocalArchive(localRelease(t, signtest.Signer(t, other)), false); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("verifyLocalArchive() signed with another key error = %v, want ErrSignatureInvalid", err)
+	}
+
+	unsigned := localRelease(t, nil)
+	if err := verifyLocalArchive(unsigned, false); err == nil || !strings.Contains(err.Error(), "--skip-signature") {
+		t.Errorf("verifyLocalArchive() unsigned error = %v, want a hint about --skip-signature", err)
+	}
+	if err := verifyLocalArchive(unsigned, true); err != nil {
+		t.Errorf("verifyLocalArchive() unsigned with skipSignature error = %v", err)
+	}
+
+	tampered := localRelease(t, signtest.Signer(t, priv))
+	createTestArchive(t, tampered, "claude-workspace", "other-content")
+	if err := verifyLocalArchive(tampered, false); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("verifyLocalArchive() for a modified archive error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestVerifyLocalArchiveNoChecksums(t *testing.T) {
+	archive := localRelease(t, nil)
+	if err := os.Remove(filepath.Join(filepath.Dir(archive), "checksums.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	withPublicKey(t, "")
+	if err := verifyLocalArchive(archive, false); err != nil {
+		t.Errorf("verifyLocalArchive() without a key or checksums error = %v, want nil", err)
+	}
+
+	_, pub := signtest.Key(t)
+	withPublicKey(t, pub)
+	if err := verifyLocalArchive(archive, false); err == nil {
+		t.Error("verifyLocalArchive() without checksums.txt should fail when a key is configured")
+	}
+}
+
+func TestRunFromFileMissingArchive(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	err := Run("1.5.0", []string{"--from-file", filepath.Join(t.TempDir(), "missing.tar.gz"), "--yes"})
+	if err == nil || !strings.Contains(err.Error(), "archive not found") {
+		t.Errorf("Run(--from-file missing) error = %v, want archive not found", err)
+	}
+}
@@ -4,24 +4,46 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 )
 
-// ReplaceBinary replaces the currently installed binary with a new one using
-// atomic rename. If the install directory requires elevated permissions,
-// it falls back to sudo mv.
-func ReplaceBinary(newBinaryPath string) error {
-	// Resolve the actual install path (follow symlinks)
+// ErrNoRollback is returned by --rollback when no previous binary was kept.
+var ErrNoRollback = fmt.Errorf("no previous binary to roll back to; one is kept after each self-upgrade")
+
+// installedBinary returns the resolved path of the running binary.
+func installedBinary() (string, error) {
 	currentExec, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("determining current executable: %w", err)
+		return "", fmt.Errorf("determining current executable: %w", err)
 	}
 	installPath, err := filepath.EvalSymlinks(currentExec)
 	if err != nil {
-		return fmt.Errorf("resolving symlinks: %w", err)
+		return "", fmt.Errorf("resolving symlinks: %w", err)
 	}
+	return installPath, nil
+}
 
+// previousPath returns where the binary replaced by the last upgrade is kept,
+// and the marker file recording its version.
+func previousPath(installPath string) (binary, marker string) {
+	return installPath + ".old", installPath + ".old.version"
+}
+
+// ReplaceBinary replaces the currently installed binary with a new one using
+// atomic rename. The replaced binary is kept as claude-workspace.old, with
+// currentVersion in claude-workspace.old.version, for upgrade --rollback. If
+// the install directory requires elevated permissions, it falls back to sudo.
+func ReplaceBinary(newBinaryPath, currentVersion string) error {
+	installPath, err := installedBinary()
+	if err != nil {
+		return err
+	}
+	return replaceBinaryAt(installPath, newBinaryPath, currentVersion)
+}
+
+func replaceBinaryAt(installPath, newBinaryPath, currentVersion string) error {
 	// Stage new binary next to install path (same filesystem for atomic rename)
 	tmpPath := installPath + ".upgrade-tmp"
 	defer os.Remove(tmpPath) // clean up on any failure path
@@ -38,16 +60,124 @@ func ReplaceBinary(newBinaryPath string) error {
 	if err != nil {
 		return fmt.Errorf("new binary failed --version check: %w", err)
 	}
-	platform.PrintSuccess(os.Stdout, fmt.Sprintf("Verified new binary: %s", ver))
+	out := platform.Stdout()
+	platform.PrintSuccess(out, fmt.Sprintf("Verified new binary: %s", ver))
+
+	// Keep the current binary for --rollback. A failure here is not fatal:
+	// the upgrade itself is still safe, only the way back is lost.
+	if err := keepPrevious(installPath, currentVersion); err != nil {
+		platform.PrintWarningLine(out, fmt.Sprintf("could not keep the previous binary for rollback: %v", err))
+	}
+
+	return moveFile(tmpPath, installPath)
+}
+
+// keepPrevious copies the binary at installPath to claude-workspace.old and
+// records its version.
+func keepPrevious(installPath, version string) error {
+	oldPath, markerPath := previousPath(installPath)
+	if err := platform.CopyFile(installPath, oldPath); err != nil {
+		if sudoErr := platform.Run("sudo", "cp", "-p", installPath, oldPath); sudoErr != nil {
+			return err
+		}
+	}
+	return writeMarker(markerPath, version)
+}
+
+// writeMarker writes version to the marker file at path, using sudo when the
+// install directory is not writable.
+func writeMarker(path, version string) error {
+	data := []byte(version + "\n")
+	if err := os.WriteFile(path, data, 0644); err == nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp("", "claude-workspace-version-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+	if err := platform.Run("sudo", "cp", tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
 
-	// Atomic rename
-	if err := os.Rename(tmpPath, installPath); err != nil {
-		// Fallback to sudo mv for permission-restricted directories
-		fmt.Println("  Attempting elevated install (sudo)...")
-		if sudoErr := platform.Run("sudo", "mv", tmpPath, installPath); sudoErr != nil {
+// moveFile renames src to dst, falling back to sudo mv for
+// permission-restricted directories.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		fmt.Fprintln(platform.Stdout(), "  Attempting elevated install (sudo)...")
+		if sudoErr := platform.Run("sudo", "mv", src, dst); sudoErr != nil {
 			return fmt.Errorf("could not replace binary (tried rename and sudo mv): %w", err)
 		}
 	}
+	return nil
+}
+
+// PreviousVersion returns the version of the binary kept by the last upgrade,
+// or ErrNoRollback when there is none. The version is "" if its marker is missing.
+func PreviousVersion() (string, error) {
+	installPath, err := installedBinary()
+	if err != nil {
+		return "", err
+	}
+	return previousVersionAt(installPath)
+}
 
+func previousVersionAt(installPath string) (string, error) {
+	oldPath, markerPath := previousPath(installPath)
+	if !platform.FileExists(oldPath) {
+		return "", ErrNoRollback
+	}
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		return "", nil
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// RollbackBinary swaps the installed binary with the one kept by the last
+// upgrade, so the binary rolled back from (currentVersion) becomes the new
+// claude-workspace.old and running --rollback again rolls forward.
+func RollbackBinary(currentVersion string) error {
+	installPath, err := installedBinary()
+	if err != nil {
+		return err
+	}
+	return rollbackBinaryAt(installPath, currentVersion)
+}
+
+func rollbackBinaryAt(installPath, currentVersion string) error {
+	oldPath, markerPath := previousPath(installPath)
+	if !platform.FileExists(oldPath) {
+		return ErrNoRollback
+	}
+
+	ver, err := platform.Output(oldPath, "--version")
+	if err != nil {
+		return fmt.Errorf("previous binary failed --version check: %w", err)
+	}
+	out := platform.Stdout()
+	platform.PrintSuccess(out, fmt.Sprintf("Verified previous binary: %s", ver))
+
+	swapPath := installPath + ".rollback-tmp"
+	if err := moveFile(installPath, swapPath); err != nil {
+		return err
+	}
+	if err := moveFile(oldPath, installPath); err != nil {
+		_ = moveFile(swapPath, installPath)
+		return err
+	}
+	if err := moveFile(swapPath, oldPath); err != nil {
+		return err
+	}
+	if err := writeMarker(markerPath, currentVersion); err != nil {
+		platform.PrintWarningLine(out, fmt.Sprintf("could not record the rolled-back version: %v", err))
+	}
 	return nil
 }
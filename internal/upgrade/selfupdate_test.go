@@ -0,0 +1,143 @@
+package upgrade
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// writeFakeBinary writes a script that prints a claude-workspace version.
+func writeFakeBinary(t *testing.T, path, version string) {
+	t.Helper()
+	script := "#!/bin/sh\necho claude-workspace " + version + "\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReplaceAndRollbackBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as fake binaries")
+	}
+	dir := t.TempDir()
+	installPath := filepath.Join(dir, "claude-workspace")
+	newBinary := filepath.Join(dir, "download", "claude-workspace")
+	_ = os.MkdirAll(filepath.Dir(newBinary), 0755)
+	writeFakeBinary(t, installPath, "v1.0.0")
+	writeFakeBinary(t, newBinary, "v1.1.0")
+
+	if _, err := previousVersionAt(installPath); err != ErrNoRollback {
+		t.Fatalf("previousVersionAt() before upgrade error = %v, want ErrNoRollback", err)
+	}
+	if err := rollbackBinaryAt(installPath, "v1.0.0"); err != ErrNoRollback {
+		t.Fatalf("rollbackBinaryAt() before upgrade error = %v, want ErrNoRollback", err)
+	}
+
+	if err := replaceBinaryAt(installPath, newBinary, "v1.0.0"); err != nil {
+		t.Fatalf("replaceBinaryAt() error = %v", err)
+	}
+	if got := readFile(t, installPath); !strings.Contains(got, "v1.1.0") {
+		t.Errorf("installed binary = %q, want v1.1.0", got)
+	}
+	oldPath, _ := previousPath(installPath)
+	if got := readFile(t, oldPath); !strings.Contains(got, "v1.0.0") {
+		t.Errorf("kept binary = %q, want v1.0.0", got)
+	}
+	if got, err := previousVersionAt(installPath); err != nil || got != "v1.0.0" {
+		t.Errorf("previousVersionAt() = %q, %v; want v1.0.0", got, err)
+	}
+
+	if err := rollbackBinaryAt(installPath, "v1.1.0"); err != nil {
+		t.Fatalf("rollbackBinaryAt() error = %v", err)
+	}
+	if got := readFile(t, installPath); !strings.Contains(got, "v1.0.0") {
+		t.Errorf("installed binary after rollback = %q, want v1.0.0", got)
+	}
+	if got, err := previousVersionAt(installPath); err != nil || got != "v1.1.0" {
+		t.Errorf("previousVersionAt() after rollback = %q, %v; want v1.1.0", got, err)
+	}
+	if info, err := os.Stat(installPath); err != nil || info.Mode()&0111 == 0 {
+		t.Errorf("restored binary is not executable: %v", err)
+	}
+}
+
+func TestRollbackBinaryBrokenPrevious(t *testing.T) {
+	dir := t.TempDir()
+	installPath := filepath.Join(dir, "claude-workspace")
+	writeFakeBinary(t, installPath, "v1.1.0")
+	oldPath, _ := previousPath(installPath)
+	if err := os.WriteFile(oldPath, []byte("not a binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rollbackBinaryAt(installPath, "v1.1.0"); err == nil {
+		t.Fatal("rollbackBinaryAt() expected an error for a broken previous binary")
+	}
+	if got := readFile(t, installPath); !strings.Contains(got, "v1.1.0") {
+		t.Errorf("installed binary changed to %q after a failed rollback", got)
+	}
+}
+
+func TestRestoreAssets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	agent := filepath.Join(home, ".claude-workspace", "assets", ".claude", "agents", "planner.md")
+
+	if restored, err := restoreAssets("v1.0.0"); err != nil || restored {
+		t.Fatalf("restoreAssets() without a cache = %v, %v", restored, err)
+	}
+
+	oldFS := platform.FS
+	defer func() { platform.FS = oldFS }()
+	for _, release := range []struct{ version, agent string }{{"v1.0.0", "old"}, {"v1.1.0", "new"}} {
+		platform.FS = fstest.MapFS{
+			".claude/agents/planner.md": {Data: []byte(release.agent)},
+			".mcp.json":                 {Data: []byte("{}")},
+		}
+		if _, err := platform.ExtractForSymlink(release.version); err != nil {
+			t.Fatalf("ExtractForSymlink(%s) error = %v", release.version, err)
+		}
+	}
+
+	restored, err := restoreAssets("v1.0.0")
+	if err != nil || !restored {
+		t.Fatalf("restoreAssets() = %v, %v", restored, err)
+	}
+	if got := readFile(t, agent); got != "old" {
+		t.Errorf("planner.md after rollback = %q, want old", got)
+	}
+
+	// A second rollback, to the newer binary, rolls forward again.
+	if _, err := restoreAssets("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, agent); got != "new" {
+		t.Errorf("planner.md after second rollback = %q, want new", got)
+	}
+
+	// Without the restored binary's version, the replaced set is used.
+	if restored, err := restoreAssets(""); err != nil || !restored {
+		t.Fatalf("restoreAssets(\"\") = %v, %v", restored, err)
+	}
+	if got := readFile(t, agent); got != "old" {
+		t.Errorf("planner.md after rollback to an unknown version = %q, want old", got)
+	}
+
+	if restored, err := restoreAssets("v0.9.0"); err != nil || restored {
+		t.Errorf("restoreAssets(v0.9.0) = %v, %v, want false for assets that are not kept", restored, err)
+	}
+}
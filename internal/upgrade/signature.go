@@ -0,0 +1,48 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform"
+)
+
+// PublicKey is the cosign public key that release checksums are signed with,
+// as PEM or as the base64 DER it wraps. main sets it from the key compiled
+// into release builds; it is empty in builds made without one, such as
+// "go install", which then skip signature verification with a warning.
+var PublicKey string
+
+// signatureAsset is the release asset holding the cosign signature of
+// checksums.txt ("cosign sign-blob --output-signature").
+const signatureAsset = "checksums.txt.sig"
+
+// ErrSignatureInvalid is returned when checksums.txt does not match its
+// signature.
+var ErrSignatureInvalid = errors.New("release signature verification failed")
+
+// verifyChecksumsSignature checks the signature of the release's checksums.txt
+// (content) against PublicKey. Because checksums.txt covers every archive, a
+// valid signature together with a matching checksum authenticates the
+// download.
+func verifyChecksumsSignature(release *Release, content []byte) error {
+	var sigAsset *ReleaseAsset
+	for i := range release.Assets {
+		if release.Assets[i].Name == signatureAsset {
+			sigAsset = &release.Assets[i]
+			break
+		}
+	}
+	if sigAsset == nil {
+		return fmt.Errorf("release %s has no %s; refusing to install an unsigned release (use --skip-signature to override)", release.TagName, signatureAsset)
+	}
+
+	sig, err := fetchAsset(*sigAsset)
+	if err != nil {
+		return err
+	}
+	if err := platform.VerifySignature(PublicKey, content, sig); err != nil {
+		return fmt.Errorf("%w for checksums.txt of %s: %v. The release may have been tampered with; do not install it. Report it at https://github.com/lamchakchan/claude-workspace/issues", ErrSignatureInvalid, release.TagName, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package upgrade
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lamchakchan/claude-workspace/internal/platform/signtest"
+)
+
+// signedRelease serves an archive's checksums.txt and, unless sign is nil,
+// checksums.txt.sig. It returns the release and the archive path.
+func signedRelease(t *testing.T, sign func(checksums []byte) []byte) (*Release, string) {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "test.tar.gz")
+	content := []byte("test-archive-content")
+	if err := os.WriteFile(archive, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	checksums := []byte(fmt.Sprintf("%s  test.tar.gz\n", hex.EncodeToString(sum[:])))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(checksums) })
+	release := &Release{
+		TagName: "v1.5.0",
+		Assets:  []ReleaseAsset{{Name: "test.tar.gz"}},
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	release.Assets = append(release.Assets, ReleaseAsset{Name: "checksums.txt", BrowserDownloadURL: server.URL + "/checksums.txt"})
+	if sign != nil {
+		sig := sign(checksums)
+		mux.HandleFunc("/checksums.txt.sig", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(sig) })
+		release.Assets = append(release.Assets, ReleaseAsset{Name: signatureAsset, BrowserDownloadURL: server.URL + "/checksums.txt.sig"})
+	}
+	return release, archive
+}
+
+func withPublicKey(t *testing.T, key string) {
+	t.Helper()
+	orig := PublicKey
+	PublicKey = key
+	t.Cleanup(func() { PublicKey = orig })
+}
+
+func TestVerifyChecksumSigned(t *testing.T) {
+	priv, pub := signtest.Key(t)
+	withPublicKey(t, pub)
+
+	release, archive := signedRelease(t, signtest.Signer(t, priv))
+	if err := VerifyChecksum(release, archive, "test.tar.gz", false); err != nil {
+		t.Fatalf("VerifyChecksum() with a valid signature error = %v", err)
+	}
+}
+
+func TestVerifyChecksumBadSignature(t *testing.T) {
+	_, pub := signtest.Key(t)
+	other, _ := signtest.Key(t)
+	withPublicKey(t, pub)
+
+	release, archive := signedRelease(t, signtest.Signer(t, other))
+	err := VerifyChecksum(release, archive, "test.tar.gz", false)
+	if !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("VerifyChecksum() signed with another key error = %v, want ErrSignatureInvalid", err)
+	}
+
+	if err := VerifyChecksum(release, archive, "test.tar.gz", true); err != nil {
+		t.Errorf("VerifyChecksum() with skipSignature error = %v", err)
+	}
+}
+
+func TestVerifyChecksumUnsigned(t *testing.T) {
+	_, pub := signtest.Key(t)
+	withPublicKey(t, pub)
+
+	release, archive := signedRelease(t, nil)
+	err := VerifyChecksum(release, archive, "test.tar.gz", false)
+	if err == nil || !strings.Contains(err.Error(), "--skip-signature") {
+		t.Fatalf("VerifyChecksum() for an unsigned release error = %v, want a hint about --skip-signature", err)
+	}
+
+	noChecksums := &Release{TagName: "v1.5.0", Assets: []ReleaseAsset{{Name: "test.tar.gz"}}}
+	if err := VerifyChecksum(noChecksums, archive, "test.tar.gz", false); err == nil {
+		t.Error("VerifyChecksum() without checksums.txt should fail when a key is configured")
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/orgpolicy"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 	"github.com/lamchakchan/claude-workspace/internal/setup"
 	"github.com/lamchakchan/claude-workspace/internal/tools"
@@ -21,6 +23,9 @@ import (
 // ErrMutuallyExclusive is returned when --self-only and --cli-only are both set.
 var ErrMutuallyExclusive = fmt.Errorf("--self-only and --cli-only are mutually exclusive")
 
+// ErrRollbackExclusive is returned when --rollback is combined with other upgrade flags.
+var ErrRollbackExclusive = fmt.Errorf("--rollback cannot be combined with --check, --self-only, --cli-only, or --channel")
+
 // ErrUpdateAvailable is returned when --check detects an available update (exit 1).
 var ErrUpdateAvailable = fmt.Errorf("update available")
 
@@ -30,22 +35,47 @@ type upgradeFlags struct {
 	autoYes   bool
 	selfOnly  bool
 	cliOnly   bool
+	rollback  bool
+	skipSig   bool
+	channel   string // from --channel; "" uses the saved channel
+	fromFile  string // from --from-file; install this archive without GitHub
+	homebrew  bool   // not a flag: the running binary is managed by Homebrew
 }
 
+// usage is the upgrade command's usage line.
+const usage = "upgrade [--check] [--yes] [--self-only|--cli-only] [--channel <name>] [--rollback] [--from-file <archive>] [--skip-signature]"
+
 // parseFlags parses upgrade command arguments into an upgradeFlags struct.
 func parseFlags(args []string) (upgradeFlags, error) {
 	var f upgradeFlags
-	for _, arg := range args {
-		switch arg {
-		case "--check":
-			f.checkOnly = true
-		case "--yes", "-y":
-			f.autoYes = true
-		case "--self-only":
-			f.selfOnly = true
-		case "--cli-only":
-			f.cliOnly = true
+	fs := cli.New("upgrade", usage)
+	fs.BoolVar(&f.checkOnly, "--check", "Only check for updates (exit 1 if one is available)")
+	fs.BoolVar(&f.autoYes, "--yes", "Do not ask for confirmation")
+	fs.Alias("-y", "--yes")
+	fs.BoolVar(&f.selfOnly, "--self-only", "Upgrade only claude-workspace")
+	fs.BoolVar(&f.cliOnly, "--cli-only", "Upgrade only the Claude Code CLI")
+	fs.BoolVar(&f.rollback, "--rollback", "Restore the binary replaced by the last upgrade")
+	fs.BoolVar(&f.skipSig, "--skip-signature", "Install without checking the release signature")
+	fs.StringVar(&f.fromFile, "--from-file", "archive", "Install a downloaded release archive without GitHub")
+	fs.Func("--channel", "name", "Follow the "+strings.Join(Channels, ", ")+" releases (saved)", func(v string) error {
+		if !ValidChannel(v) {
+			return fmt.Errorf("unknown channel %q (valid: %s)", v, strings.Join(Channels, ", "))
 		}
+		f.channel = v
+		return nil
+	})
+	positional, err := fs.Parse(args)
+	if err != nil {
+		return f, err
+	}
+	if err := fs.CheckArgs(positional, 0, 0); err != nil {
+		return f, err
+	}
+	if f.rollback && (f.checkOnly || f.selfOnly || f.cliOnly || f.channel != "") {
+		return f, ErrRollbackExclusive
+	}
+	if f.fromFile != "" && (f.checkOnly || f.cliOnly || f.rollback || f.channel != "") {
+		return f, ErrFromFileExclusive
 	}
 	if f.selfOnly && f.cliOnly {
 		return f, ErrMutuallyExclusive
@@ -55,13 +85,18 @@ func parseFlags(args []string) (upgradeFlags, error) {
 
 // stepCount returns the total number of upgrade steps based on flags.
 func stepCount(f upgradeFlags) int {
-	if f.selfOnly {
-		return 5
-	}
 	if f.cliOnly {
 		return 1
 	}
-	return 6
+	self := 5
+	if f.homebrew {
+		// brew downloads and replaces the binary in one step.
+		self = 4
+	}
+	if f.selfOnly || f.fromFile != "" {
+		return self
+	}
+	return self + 1
 }
 
 // stepper tracks step progress for multi-step commands.
@@ -85,9 +120,31 @@ func Run(version string, args []string) error {
 	if err != nil {
 		return err
 	}
+	f.homebrew = isSelfHomebrew()
+
+	if f.rollback {
+		return rollback(version, f.autoYes)
+	}
 
 	s := newStepper(f)
 
+	if f.fromFile != "" {
+		if err := installFromFile(version, f.fromFile, f, s); err != nil {
+			return err
+		}
+		printUpgradeComplete(false)
+		return nil
+	}
+
+	// An explicit --channel sticks for later upgrades; --check only peeks at it.
+	if f.channel == "" {
+		f.channel = LoadChannel()
+	} else if !f.checkOnly {
+		if err := SaveChannel(f.channel); err != nil {
+			platform.PrintWarningLine(platform.Stdout(), fmt.Sprintf("could not save channel: %v", err))
+		}
+	}
+
 	if !f.cliOnly {
 		if err := upgradeSelf(version, f, s); err != nil {
 			return err
@@ -108,18 +165,14 @@ func Run(version string, args []string) error {
 
 // upgradeSelf handles the self-update portion (steps 1-5).
 func upgradeSelf(version string, f upgradeFlags, s *stepper) error {
-	platform.PrintBanner(os.Stdout, "Upgrading claude-workspace")
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Upgrading claude-workspace")
 
-	if isSelfHomebrew() {
-		fmt.Println("  Detected Homebrew installation. Running: brew upgrade claude-workspace...")
-		if err := platform.Run("brew", "upgrade", "claude-workspace"); err != nil {
-			fmt.Println("  claude-workspace is already up to date (or brew upgrade failed).")
-			fmt.Println("  To upgrade manually: brew upgrade claude-workspace")
-		}
-		return nil
+	if f.homebrew {
+		return upgradeHomebrew(version, f, s)
 	}
 
-	release, upToDate, err := checkForUpdates(version, s)
+	release, upToDate, err := checkForUpdates(version, f.channel, s)
 	if err != nil {
 		return err
 	}
@@ -137,20 +190,24 @@ func upgradeSelf(version string, f upgradeFlags, s *stepper) error {
 		return handleCheckOnly(f, s)
 	}
 
-	if !f.autoYes && !confirmUpgrade() {
+	if !f.autoYes && !confirm("Upgrade cancelled.") {
 		return nil
 	}
 
-	return downloadAndInstall(version, release, s)
+	return downloadAndInstall(version, release, f.skipSig, s)
 }
 
-// checkForUpdates fetches the latest release and compares versions.
+// checkForUpdates fetches the latest release on channel and compares versions.
 // Returns the release, whether the current version is up to date, and any error.
-func checkForUpdates(version string, s *stepper) (*Release, bool, error) {
-	platform.PrintStep(os.Stdout, s.next(), s.total, "Checking for updates...")
-	fmt.Printf("  Current: %s\n", version)
+func checkForUpdates(version, channel string, s *stepper) (*Release, bool, error) {
+	out := platform.Stdout()
+	platform.PrintStep(out, s.next(), s.total, "Checking for updates...")
+	fmt.Fprintf(out, "  Current: %s\n", version)
+	if channel != ChannelStable {
+		fmt.Fprintf(out, "  Channel: %s\n", channel)
+	}
 
-	release, err := FetchLatest()
+	release, err := FetchChannel(channel)
 	if err != nil {
 		return nil, false, fmt.Errorf("checking for updates: %w", err)
 	}
@@ -158,25 +215,23 @@ func checkForUpdates(version string, s *stepper) (*Release, bool, error) {
 	latestVersion := release.TagName
 	publishedDate := extractDate(release.PublishedAt)
 
-	fmt.Printf("  Latest:  %s", latestVersion)
+	fmt.Fprintf(out, "  Latest:  %s", latestVersion)
 	if publishedDate != "" {
-		fmt.Printf(" (%s)", publishedDate)
-	}
-	fmt.Println()
-
-	currentNormalized := version
-	if !strings.HasPrefix(currentNormalized, "v") {
-		currentNormalized = "v" + currentNormalized
+		fmt.Fprintf(out, " (%s)", publishedDate)
 	}
+	fmt.Fprintln(out)
 
 	if version == "dev" {
-		platform.PrintWarningLine(os.Stdout, "You are running a dev build.")
-		fmt.Println("  Upgrading will install the latest stable release.")
+		platform.PrintWarningLine(out, "You are running a dev build.")
+		fmt.Fprintf(out, "  Upgrading will install the latest %s release.\n", channel)
 		return release, false, nil
 	}
 
-	if currentNormalized == latestVersion {
-		fmt.Println("\n  Already up to date.")
+	if !UpdateAvailable(version, latestVersion) {
+		fmt.Fprintln(out, "\n  Already up to date.")
+		if compareVersions(version, latestVersion) > 0 {
+			fmt.Fprintf(out, "  %s is newer than the latest %s release; it will be kept until %s catches up.\n", version, channel, channel)
+		}
 		return release, true, nil
 	}
 
@@ -196,48 +251,53 @@ func printChangelog(release *Release) {
 	if release.Body == "" {
 		return
 	}
-	fmt.Println("\n  Changelog:")
+	out := platform.Stdout()
+	fmt.Fprintln(out, "\n  Changelog:")
 	for _, line := range strings.Split(release.Body, "\n") {
 		line = strings.TrimSpace(line)
 		if line != "" {
-			fmt.Printf("    %s\n", line)
+			fmt.Fprintf(out, "    %s\n", line)
 		}
 	}
 }
 
 // handleCheckOnly handles the --check flag behavior.
 func handleCheckOnly(f upgradeFlags, s *stepper) error {
+	out := platform.Stdout()
 	if f.selfOnly {
-		fmt.Println()
+		fmt.Fprintln(out)
 		return ErrUpdateAvailable
 	}
-	fmt.Println()
-	fmt.Println("  Update available for claude-workspace.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "  Update available for claude-workspace.")
 	if err := upgradeCLI(s, f.autoYes, f.checkOnly); err != nil {
 		return err
 	}
 	return ErrUpdateAvailable
 }
 
-// confirmUpgrade prompts the user and returns true if they accept.
-func confirmUpgrade() bool {
-	fmt.Print("\n")
-	platform.PrintPrompt(os.Stdout, "  Proceed? [Y/n] ")
+// confirm prompts the user and returns true if they accept, printing
+// cancelled otherwise.
+func confirm(cancelled string) bool {
+	out := platform.Stdout()
+	fmt.Fprint(out, "\n")
+	platform.PrintPrompt(out, "  Proceed? [Y/n] ")
 	reader := bufio.NewReader(os.Stdin)
 	answer, _ := reader.ReadString('\n')
 	answer = strings.TrimSpace(strings.ToLower(answer))
 	if answer != "" && answer != "y" && answer != "yes" {
-		fmt.Println("  Upgrade cancelled.")
+		fmt.Fprintln(out, "  "+cancelled)
 		return false
 	}
 	return true
 }
 
 // downloadAndInstall downloads, verifies, and installs the new binary (steps 2-5).
-func downloadAndInstall(version string, release *Release, s *stepper) error {
+func downloadAndInstall(version string, release *Release, skipSignature bool, s *stepper) error {
 	latestVersion := release.TagName
 
-	platform.PrintStep(os.Stdout, s.next(), s.total, fmt.Sprintf("Downloading claude-workspace %s...", latestVersion))
+	out := platform.Stdout()
+	platform.PrintStep(out, s.next(), s.total, fmt.Sprintf("Downloading claude-workspace %s...", latestVersion))
 
 	asset, err := FindAsset(release)
 	if err != nil {
@@ -255,7 +315,7 @@ func downloadAndInstall(version string, release *Release, s *stepper) error {
 		return err
 	}
 
-	if err := VerifyChecksum(release, archivePath, asset.Name); err != nil {
+	if err := VerifyChecksum(release, archivePath, asset.Name, skipSignature); err != nil {
 		return err
 	}
 
@@ -264,47 +324,118 @@ func downloadAndInstall(version string, release *Release, s *stepper) error {
 		return fmt.Errorf("extracting binary: %w", err)
 	}
 
-	platform.PrintStep(os.Stdout, s.next(), s.total, "Replacing binary...")
-	if err := ReplaceBinary(binaryPath); err != nil {
+	platform.PrintStep(out, s.next(), s.total, "Replacing binary...")
+	if err := ReplaceBinary(binaryPath, version); err != nil {
 		return fmt.Errorf("replacing binary: %w", err)
 	}
 	currentExec, _ := os.Executable()
 	installPath, _ := filepath.EvalSymlinks(currentExec)
-	fmt.Printf("  %s updated (%s → %s)\n", installPath, version, latestVersion)
+	fmt.Fprintf(out, "  %s updated (%s → %s)\n", installPath, version, latestVersion)
 
-	refreshAssets(s)
-	mergeSettings(s)
+	refreshAssets(s, version)
+	mergeSettings(s, false)
 
 	return nil
 }
 
-// refreshAssets updates shared symlinked assets (step 4).
-func refreshAssets(s *stepper) {
-	platform.PrintStep(os.Stdout, s.next(), s.total, "Refreshing shared assets...")
-	if _, err := platform.ExtractForSymlink(); err != nil {
-		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("could not refresh shared assets: %v", err))
+// refreshAssets updates shared symlinked assets (step 4). The assets are
+// those of the running binary, version.
+func refreshAssets(s *stepper, version string) {
+	out := platform.Stdout()
+	platform.PrintStep(out, s.next(), s.total, "Refreshing shared assets...")
+	if _, err := platform.ExtractForSymlink(version); err != nil {
+		platform.PrintWarningLine(out, fmt.Sprintf("could not refresh shared assets: %v", err))
 	} else {
-		fmt.Println("  ~/.claude-workspace/assets/ updated")
-		fmt.Println("  Symlinked projects will pick up changes automatically.")
+		fmt.Fprintln(out, "  ~/.claude-workspace/assets/ updated")
+		fmt.Fprintln(out, "  Symlinked and hard-linked projects will pick up changes automatically.")
 	}
 }
 
-// mergeSettings merges platform defaults into global settings (step 5).
-func mergeSettings(s *stepper) {
-	platform.PrintStep(os.Stdout, s.next(), s.total, "Merging global settings...")
+// rollback restores the binary and shared assets replaced by the last
+// self-upgrade.
+func rollback(version string, autoYes bool) error {
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Rolling back claude-workspace")
+
+	if isSelfHomebrew() {
+		return fmt.Errorf("--rollback is not available for Homebrew installations; use brew to reinstall an earlier version")
+	}
+
+	previous, err := PreviousVersion()
+	if err != nil {
+		return err
+	}
+	assetVersion := previous
+	if previous == "" {
+		previous = "unknown version"
+	}
+
+	platform.PrintStep(out, 1, 2, "Restoring previous binary...")
+	fmt.Fprintf(out, "  Current:  %s\n", version)
+	fmt.Fprintf(out, "  Previous: %s\n", previous)
+
+	if !autoYes && !confirm("Rollback cancelled.") {
+		return nil
+	}
+
+	if err := RollbackBinary(version); err != nil {
+		return fmt.Errorf("restoring previous binary: %w", err)
+	}
+	fmt.Fprintf(out, "  claude-workspace restored (%s → %s)\n", version, previous)
+
+	platform.PrintStep(out, 2, 2, "Restoring shared assets...")
+	restored, err := restoreAssets(assetVersion)
+	switch {
+	case err != nil:
+		platform.PrintWarningLine(out, fmt.Sprintf("could not restore shared assets: %v", err))
+	case restored:
+		fmt.Fprintln(out, "  ~/.claude-workspace/assets/ restored")
+		fmt.Fprintln(out, "  Symlinked and hard-linked projects will pick up the previous assets automatically.")
+	default:
+		fmt.Fprintln(out, "  No saved shared assets; ~/.claude-workspace/assets/ left unchanged.")
+	}
+
+	platform.PrintBanner(out, "Rollback Complete")
+	fmt.Fprintln(out, "\n  Run 'claude-workspace upgrade --rollback' again to return to "+version+".")
+	fmt.Fprintln(out)
+	return nil
+}
+
+// restoreAssets switches ~/.claude-workspace/assets back to the assets of
+// version, the binary a rollback restored, or to the set the last extraction
+// replaced when the version is unknown. It reports false when neither is
+// kept.
+func restoreAssets(version string) (bool, error) {
+	cacheDir, err := platform.AssetCacheDir()
+	if err != nil {
+		return false, err
+	}
+	return platform.UseAssetVersion(cacheDir, version)
+}
+
+// mergeSettings merges platform defaults into global settings and re-syncs
+// the org policy, fetching its latest version unless offline (step 5).
+func mergeSettings(s *stepper, offline bool) {
+	out := platform.Stdout()
+	platform.PrintStep(out, s.next(), s.total, "Merging global settings...")
 	if err := mergeGlobalSettings(); err != nil {
-		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("could not merge settings: %v", err))
+		platform.PrintWarningLine(out, fmt.Sprintf("could not merge settings: %v", err))
+	}
+	if err := orgpolicy.Sync(out, offline); err != nil {
+		platform.PrintWarningLine(out, fmt.Sprintf("could not sync org policy: %v", err))
 	}
 }
 
 // printUpgradeComplete prints the final upgrade banner.
 func printUpgradeComplete(showTip bool) {
-	platform.PrintBanner(os.Stdout, "Upgrade Complete")
+	out := platform.Stdout()
+	platform.PrintBanner(out, "Upgrade Complete")
 	if showTip {
-		fmt.Println("\n  Tip: For projects using copied (non-symlinked) assets,")
-		fmt.Println("       run 'claude-workspace attach --force' to refresh.")
+		fmt.Fprintln(out, "\n  Tip: For projects using copied (non-symlinked) assets,")
+		fmt.Fprintln(out, "       run 'claude-workspace attach <project> --reconcile' to refresh")
+		fmt.Fprintln(out, "       the files you have not edited.")
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // cliInfo holds detected Claude Code CLI state.
@@ -340,31 +471,32 @@ func detectClaudeBinary() cliInfo {
 
 // runCLIInstall executes the appropriate CLI install/upgrade command.
 func runCLIInstall(info cliInfo) {
+	out := platform.Stdout()
 	if info.IsHomebrew {
-		fmt.Println("  Detected Homebrew installation. Running: brew upgrade claude-code...")
+		fmt.Fprintln(out, "  Detected Homebrew installation. Running: brew upgrade claude-code...")
 		if err := platform.Run("brew", "upgrade", "claude-code"); err != nil {
-			fmt.Println("  Claude Code is already up to date (or brew upgrade failed).")
-			fmt.Println("  To upgrade manually: brew upgrade claude-code")
+			fmt.Fprintln(out, "  Claude Code is already up to date (or brew upgrade failed).")
+			fmt.Fprintln(out, "  To upgrade manually: brew upgrade claude-code")
 		}
 		return
 	}
 
 	npmInfo := setup.DetectNpmClaude()
 	if npmInfo.Detected {
-		fmt.Printf("  Detected Claude Code installed via npm (source: %s).\n", npmInfo.Source)
-		fmt.Println("  Removing npm version before upgrading...")
+		fmt.Fprintf(out, "  Detected Claude Code installed via npm (source: %s).\n", npmInfo.Source)
+		fmt.Fprintln(out, "  Removing npm version before upgrading...")
 		if err := setup.UninstallNpmClaude(npmInfo); err != nil {
-			platform.PrintWarningLine(os.Stdout, fmt.Sprintf("could not remove npm Claude: %v", err))
-			fmt.Println("  You may need to run: npm uninstall -g @anthropic-ai/claude-code")
+			platform.PrintWarningLine(out, fmt.Sprintf("could not remove npm Claude: %v", err))
+			fmt.Fprintln(out, "  You may need to run: npm uninstall -g @anthropic-ai/claude-code")
 		} else {
-			fmt.Println("  npm Claude Code removed successfully.")
+			fmt.Fprintln(out, "  npm Claude Code removed successfully.")
 		}
 	}
 
-	fmt.Println("  Running official installer...")
+	fmt.Fprintln(out, "  Running official installer...")
 	if err := platform.Run("bash", "-c", tools.ClaudeInstallCmd); err != nil {
-		platform.PrintWarningLine(os.Stdout, fmt.Sprintf("Claude Code CLI upgrade failed: %v", err))
-		fmt.Println("  You can upgrade manually: curl -fsSL https://claude.ai/install.sh | bash")
+		platform.PrintWarningLine(out, fmt.Sprintf("Claude Code CLI upgrade failed: %v", err))
+		fmt.Fprintln(out, "  You can upgrade manually: curl -fsSL https://claude.ai/install.sh | bash")
 		return
 	}
 
@@ -378,35 +510,37 @@ func runCLIInstall(info cliInfo) {
 // reportCLIVersion prints a before/after version comparison.
 func reportCLIVersion(info cliInfo) {
 	newVer, err := platform.Output(info.BinPath, "--version")
+	out := platform.Stdout()
 	if err != nil {
-		fmt.Println("  Claude Code CLI installed successfully.")
+		fmt.Fprintln(out, "  Claude Code CLI installed successfully.")
 		return
 	}
 	newVersion := strings.TrimSpace(newVer)
 	if info.OldVersion != "" {
-		fmt.Printf("  Claude Code CLI: %s → %s\n", info.OldVersion, newVersion)
+		fmt.Fprintf(out, "  Claude Code CLI: %s → %s\n", info.OldVersion, newVersion)
 	} else {
-		fmt.Printf("  Claude Code CLI installed: %s\n", newVersion)
+		fmt.Fprintf(out, "  Claude Code CLI installed: %s\n", newVersion)
 	}
 }
 
 // upgradeCLI detects the current Claude Code CLI and runs the official installer to upgrade it.
 func upgradeCLI(s *stepper, autoYes, checkOnly bool) error {
-	platform.PrintStep(os.Stdout, s.next(), s.total, "Upgrading Claude Code CLI...")
+	out := platform.Stdout()
+	platform.PrintStep(out, s.next(), s.total, "Upgrading Claude Code CLI...")
 
 	info := detectClaudeBinary()
 
 	if info.Installed {
-		fmt.Printf("  Current Claude Code CLI: %s\n", info.OldVersion)
+		fmt.Fprintf(out, "  Current Claude Code CLI: %s\n", info.OldVersion)
 	} else {
-		fmt.Println("  Claude Code CLI not found.")
+		fmt.Fprintln(out, "  Claude Code CLI not found.")
 	}
 
 	if checkOnly {
 		if info.Installed {
-			fmt.Println("  (Cannot check latest version remotely; run without --check to upgrade.)")
+			fmt.Fprintln(out, "  (Cannot check latest version remotely; run without --check to upgrade.)")
 		} else {
-			fmt.Println("  Claude Code CLI is not installed.")
+			fmt.Fprintln(out, "  Claude Code CLI is not installed.")
 		}
 		return nil
 	}
@@ -416,13 +550,13 @@ func upgradeCLI(s *stepper, autoYes, checkOnly bool) error {
 		if info.Installed {
 			action = "Upgrade"
 		}
-		fmt.Print("\n")
-		platform.PrintPrompt(os.Stdout, fmt.Sprintf("  %s Claude Code CLI? [Y/n] ", action))
+		fmt.Fprint(out, "\n")
+		platform.PrintPrompt(out, fmt.Sprintf("  %s Claude Code CLI? [Y/n] ", action))
 		reader := bufio.NewReader(os.Stdin)
 		answer, _ := reader.ReadString('\n')
 		answer = strings.TrimSpace(strings.ToLower(answer))
 		if answer != "" && answer != "y" && answer != "yes" {
-			fmt.Println("  Skipped Claude Code CLI upgrade.")
+			fmt.Fprintln(out, "  Skipped Claude Code CLI upgrade.")
 			return nil
 		}
 	}
@@ -443,46 +577,32 @@ func mergeGlobalSettings() error {
 	settingsPath := filepath.Join(home, ".claude", "settings.json")
 	defaults := setup.GetDefaultGlobalSettings()
 
+	out := platform.Stdout()
 	if !platform.FileExists(settingsPath) {
-		fmt.Println("  No global settings found, skipping merge.")
+		fmt.Fprintln(out, "  No global settings found, skipping merge.")
 		return nil
 	}
 
-	var existing map[string]interface{}
-	if err := platform.ReadJSONFile(settingsPath, &existing); err != nil {
-		return fmt.Errorf("reading settings: %w", err)
-	}
+	err = platform.WithFileLock(settingsPath, func() error {
+		var existing map[string]interface{}
+		if err := platform.ReadJSONFile(settingsPath, &existing); err != nil {
+			return fmt.Errorf("reading settings: %w", err)
+		}
 
-	merged := setup.MergeSettings(existing, defaults)
-	if err := platform.WriteJSONFile(settingsPath, merged); err != nil {
-		return fmt.Errorf("writing settings: %w", err)
+		merged := setup.MergeSettings(existing, defaults)
+		if err := platform.WriteSettingsFile(settingsPath, merged); err != nil {
+			return fmt.Errorf("writing settings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("  ~/.claude/settings.json: defaults merged")
+	fmt.Fprintln(out, "  ~/.claude/settings.json: defaults merged")
 	return nil
 }
 
-// isHomebrewBinary reports whether the given path belongs to a Homebrew-managed installation.
-func isHomebrewBinary(path string) bool {
-	return strings.Contains(path, "/opt/homebrew/") ||
-		strings.Contains(path, "/usr/local/Cellar/") ||
-		strings.Contains(path, "/usr/local/opt/")
-}
-
-// isSelfHomebrew reports whether the currently running claude-workspace binary
-// was installed via Homebrew.
-func isSelfHomebrew() bool {
-	exe, err := os.Executable()
-	if err != nil {
-		return false
-	}
-	resolved, err := filepath.EvalSymlinks(exe)
-	if err != nil {
-		return false
-	}
-	return isHomebrewBinary(resolved)
-}
-
 // extractBinary extracts the claude-workspace binary from a .tar.gz archive.
 func extractBinary(archivePath, destDir string) (string, error) {
 	f, err := os.Open(archivePath)
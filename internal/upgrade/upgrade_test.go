@@ -6,14 +6,17 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFindAsset(t *testing.T) {
@@ -164,7 +167,9 @@ func TestDownloadAsset(t *testing.T) {
 }
 
 func TestDownloadAssetServerError(t *testing.T) {
+	requests := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
 		w.WriteHeader(404)
 	}))
 	defer server.Close()
@@ -185,6 +190,111 @@ func TestDownloadAssetServerError(t *testing.T) {
 	if !strings.Contains(err.Error(), "status 404") {
 		t.Errorf("expected status 404 error, got: %v", err)
 	}
+	if requests != 1 {
+		t.Errorf("a 404 should not be retried, got %d requests", requests)
+	}
+}
+
+// fastRetries shortens the download retry backoff for the test.
+func fastRetries(t *testing.T) {
+	t.Helper()
+	old := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = old })
+}
+
+// cutOff sends the first half of content with the full Content-Length, then
+// drops the connection.
+func cutOff(w http.ResponseWriter, content string) {
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, _ = w.Write([]byte(content[:len(content)/2]))
+	w.(http.Flusher).Flush()
+}
+
+func TestDownloadAssetResumes(t *testing.T) {
+	fastRetries(t)
+	content := strings.Repeat("0123456789", 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if len(ranges) == 1 {
+			cutOff(w, content)
+			return
+		}
+		var from int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &from)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", from, len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(content[from:]))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "download.tar.gz")
+	asset := ReleaseAsset{Name: "test.tar.gz", BrowserDownloadURL: server.URL, Size: int64(len(content))}
+	if err := DownloadAsset(asset, dest); err != nil {
+		t.Fatalf("DownloadAsset() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != content {
+		t.Errorf("downloaded %d bytes, want the %d-byte content", len(got), len(content))
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=500-" {
+		t.Errorf("Range headers = %q, want no range then bytes=500-", ranges)
+	}
+}
+
+func TestDownloadAssetRangeIgnored(t *testing.T) {
+	fastRetries(t)
+	content := strings.Repeat("abcdefghij", 100)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests == 1 {
+			cutOff(w, content)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "download.tar.gz")
+	asset := ReleaseAsset{Name: "test.tar.gz", BrowserDownloadURL: server.URL, Size: int64(len(content))}
+	if err := DownloadAsset(asset, dest); err != nil {
+		t.Fatalf("DownloadAsset() error = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != content {
+		t.Errorf("a full response to a range request should replace the partial file, got %d bytes", len(got))
+	}
+}
+
+func TestDownloadAssetRetries(t *testing.T) {
+	fastRetries(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "download.tar.gz")
+	if err := DownloadAsset(ReleaseAsset{Name: "test.tar.gz", BrowserDownloadURL: server.URL, Size: 2}, dest); err != nil {
+		t.Fatalf("DownloadAsset() error = %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+
+	requests = -100
+	err := DownloadAsset(ReleaseAsset{Name: "test.tar.gz", BrowserDownloadURL: server.URL, Size: 2}, dest)
+	if err == nil || !strings.Contains(err.Error(), "gave up after 5 attempts") {
+		t.Errorf("DownloadAsset() error = %v, want give-up error", err)
+	}
+	if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+		t.Error("failed download should remove the partial file")
+	}
 }
 
 func TestVerifyChecksum(t *testing.T) {
@@ -210,7 +320,7 @@ func TestVerifyChecksum(t *testing.T) {
 		},
 	}
 
-	if err := VerifyChecksum(release, filePath, "test.tar.gz"); err != nil {
+	if err := VerifyChecksum(release, filePath, "test.tar.gz", false); err != nil {
 		t.Fatalf("VerifyChecksum() error = %v", err)
 	}
 }
@@ -233,7 +343,7 @@ func TestVerifyChecksumMismatch(t *testing.T) {
 		},
 	}
 
-	err := VerifyChecksum(release, filePath, "test.tar.gz")
+	err := VerifyChecksum(release, filePath, "test.tar.gz", false)
 	if err == nil {
 		t.Fatal("expected checksum mismatch error")
 	}
@@ -250,7 +360,7 @@ func TestVerifyChecksumNoChecksumFile(t *testing.T) {
 	}
 
 	// Should not error — just prints a skip message
-	if err := VerifyChecksum(release, "/whatever", "test.tar.gz"); err != nil {
+	if err := VerifyChecksum(release, "/whatever", "test.tar.gz", false); err != nil {
 		t.Fatalf("expected nil error when no checksums.txt, got: %v", err)
 	}
 }
@@ -358,15 +468,71 @@ func TestParseFlags(t *testing.T) {
 			want: upgradeFlags{autoYes: true},
 		},
 		{
-			name: "unknown flags ignored",
-			args: []string{"--verbose", "--self-only", "--unknown"},
-			want: upgradeFlags{selfOnly: true},
+			name: "skip signature",
+			args: []string{"--skip-signature", "--self-only"},
+			want: upgradeFlags{skipSig: true, selfOnly: true},
+		},
+		{
+			name: "rollback",
+			args: []string{"--rollback", "--yes"},
+			want: upgradeFlags{rollback: true, autoYes: true},
+		},
+		{
+			name:    "rollback with check",
+			args:    []string{"--rollback", "--check"},
+			wantErr: ErrRollbackExclusive,
+		},
+		{
+			name: "channel",
+			args: []string{"--channel", "beta", "--check"},
+			want: upgradeFlags{checkOnly: true, channel: "beta"},
+		},
+		{
+			name:    "unknown channel",
+			args:    []string{"--channel", "canary"},
+			wantErr: errAny,
+		},
+		{
+			name:    "channel without value",
+			args:    []string{"--channel"},
+			wantErr: errAny,
+		},
+		{
+			name: "from file",
+			args: []string{"--from-file", "/tmp/claude-workspace_1.6.0_linux_amd64.tar.gz", "--yes"},
+			want: upgradeFlags{fromFile: "/tmp/claude-workspace_1.6.0_linux_amd64.tar.gz", autoYes: true},
+		},
+		{
+			name:    "from file with channel",
+			args:    []string{"--from-file", "a.tar.gz", "--channel", "beta"},
+			wantErr: ErrFromFileExclusive,
+		},
+		{
+			name:    "from file without value",
+			args:    []string{"--from-file"},
+			wantErr: errAny,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"--check", "--bogus"},
+			wantErr: errAny,
+		},
+		{
+			name: "flag with equals",
+			args: []string{"--channel=beta", "-y"},
+			want: upgradeFlags{channel: "beta", autoYes: true},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := parseFlags(tt.args)
+			if tt.wantErr == errAny {
+				if err == nil {
+					t.Errorf("parseFlags(%v) expected an error", tt.args)
+				}
+				return
+			}
 			if tt.wantErr != nil {
 				if err != tt.wantErr {
 					t.Errorf("parseFlags(%v) error = %v, want %v", tt.args, err, tt.wantErr)
@@ -383,6 +549,9 @@ func TestParseFlags(t *testing.T) {
 	}
 }
 
+// errAny marks parseFlags cases that should fail with any error.
+var errAny = errors.New("any error")
+
 func TestStepCount(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -392,6 +561,7 @@ func TestStepCount(t *testing.T) {
 		{name: "default", flags: upgradeFlags{}, want: 6},
 		{name: "self-only", flags: upgradeFlags{selfOnly: true}, want: 5},
 		{name: "cli-only", flags: upgradeFlags{cliOnly: true}, want: 1},
+		{name: "from-file", flags: upgradeFlags{fromFile: "a.tar.gz"}, want: 5},
 	}
 
 	for _, tt := range tests {
@@ -420,7 +590,13 @@ func TestIsHomebrewBinary(t *testing.T) {
 		{"/usr/local/opt/claude-code/bin/claude", true},
 		{"/home/user/.local/bin/claude", false},
 		{"/usr/bin/claude", false},
+		{"/home/linuxbrew/.linuxbrew/bin/claude-workspace", true},
+		{"/custom/brew/Cellar/claude-workspace/1.5.0/bin/claude-workspace", true},
+		{"/srv/brew/bin/claude-workspace", true},
+		{"/srv/brewery/bin/claude-workspace", false},
 	}
+	t.Setenv("HOMEBREW_PREFIX", "/srv/brew")
+	t.Setenv("HOMEBREW_CELLAR", "")
 	for _, c := range cases {
 		if got := isHomebrewBinary(c.path); got != c.want {
 			t.Errorf("isHomebrewBinary(%q) = %v, want %v", c.path, got, c.want)
@@ -429,6 +605,7 @@ func TestIsHomebrewBinary(t *testing.T) {
 }
 
 func TestRunSelfOnlyUpToDate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	release := Release{
 		TagName: "v1.0.0",
 		Assets:  []ReleaseAsset{},
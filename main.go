@@ -8,46 +8,98 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/lamchakchan/claude-workspace/internal/agents"
+	"github.com/lamchakchan/claude-workspace/internal/assets"
 	"github.com/lamchakchan/claude-workspace/internal/attach"
+	"github.com/lamchakchan/claude-workspace/internal/audit"
+	"github.com/lamchakchan/claude-workspace/internal/auth"
+	"github.com/lamchakchan/claude-workspace/internal/backup"
+	"github.com/lamchakchan/claude-workspace/internal/bugreport"
+	"github.com/lamchakchan/claude-workspace/internal/ci"
+	"github.com/lamchakchan/claude-workspace/internal/claudemdlint"
+	"github.com/lamchakchan/claude-workspace/internal/cli"
+	"github.com/lamchakchan/claude-workspace/internal/completion"
 	"github.com/lamchakchan/claude-workspace/internal/config"
 	"github.com/lamchakchan/claude-workspace/internal/cost"
+	"github.com/lamchakchan/claude-workspace/internal/detach"
 	"github.com/lamchakchan/claude-workspace/internal/doctor"
 	"github.com/lamchakchan/claude-workspace/internal/enrich"
+	"github.com/lamchakchan/claude-workspace/internal/events"
+	"github.com/lamchakchan/claude-workspace/internal/fleet"
 	"github.com/lamchakchan/claude-workspace/internal/hooks"
+	"github.com/lamchakchan/claude-workspace/internal/localize"
 	"github.com/lamchakchan/claude-workspace/internal/mcp"
 	"github.com/lamchakchan/claude-workspace/internal/memory"
+	"github.com/lamchakchan/claude-workspace/internal/models"
+	"github.com/lamchakchan/claude-workspace/internal/notify"
+	"github.com/lamchakchan/claude-workspace/internal/plans"
 	"github.com/lamchakchan/claude-workspace/internal/platform"
 	"github.com/lamchakchan/claude-workspace/internal/plugins"
+	"github.com/lamchakchan/claude-workspace/internal/policy"
+	"github.com/lamchakchan/claude-workspace/internal/report"
 	"github.com/lamchakchan/claude-workspace/internal/sandbox"
+	"github.com/lamchakchan/claude-workspace/internal/scan"
+	"github.com/lamchakchan/claude-workspace/internal/secrets"
 	"github.com/lamchakchan/claude-workspace/internal/sessions"
 	"github.com/lamchakchan/claude-workspace/internal/setup"
+	"github.com/lamchakchan/claude-workspace/internal/skills"
+	"github.com/lamchakchan/claude-workspace/internal/slashcommands"
 	"github.com/lamchakchan/claude-workspace/internal/statusline"
 	"github.com/lamchakchan/claude-workspace/internal/tui"
+	"github.com/lamchakchan/claude-workspace/internal/uninstall"
 	"github.com/lamchakchan/claude-workspace/internal/upgrade"
 )
 
 // version is set via -ldflags at build time
 var version = "dev"
 
+// releaseKey is the cosign public key (base64 DER) that release checksums are
+// signed with, set via -ldflags in release builds.
+var releaseKey = ""
+
 // commands maps CLI command names to their handler functions.
 var commands = map[string]func([]string) error{
-	"setup":      runSetup,
-	"attach":     runAttach,
-	"enrich":     runEnrich,
-	"sandbox":    runSandbox,
-	"mcp":        runMCP,
-	"upgrade":    runUpgrade,
-	"config":     runConfig,
-	"doctor":     func(_ []string) error { return doctor.Run() },
-	"agents":     func(a []string) error { return agents.Run(a[1:]) },
-	"hooks":      func(a []string) error { return hooks.Run(a[1:]) },
-	"statusline": func(a []string) error { return statusline.Run(a[1:]) },
-	"memory":     func(a []string) error { return memory.Run(a[1:]) },
-	"sessions":   func(a []string) error { return sessions.Run(a[1:]) },
-	"cost":       func(a []string) error { return cost.Run(a[1:]) },
-	"plugins":    func(a []string) error { return plugins.Run(a[1:]) },
+	"setup":         runSetup,
+	"attach":        runAttach,
+	"detach":        runDetach,
+	"enrich":        runEnrich,
+	"assets":        func(a []string) error { return assets.Run(version, a[1:]) },
+	"localize":      func(a []string) error { return localize.Run(a[1:]) },
+	"sandbox":       runSandbox,
+	"mcp":           runMCP,
+	"upgrade":       runUpgrade,
+	"config":        runConfig,
+	"doctor":        func(a []string) error { return doctor.Run(a[1:]) },
+	"ci":            func(a []string) error { return ci.Run(a[1:]) },
+	"lint-claudemd": func(a []string) error { return claudemdlint.Run(a[1:]) },
+	"fleet":         func(a []string) error { return fleet.Run(a[1:]) },
+	"report":        func(a []string) error { return report.Run(version, a[1:]) },
+	"bugreport":     func(a []string) error { return bugreport.Run(version, a[1:]) },
+	"agents":        func(a []string) error { return agents.Run(a[1:]) },
+	"hooks":         func(a []string) error { return hooks.Run(a[1:]) },
+	"hook-relay":    func(a []string) error { return hooks.Relay(a[1:]) },
+	"events":        func(a []string) error { return events.Run(a[1:]) },
+	"statusline":    func(a []string) error { return statusline.Run(a[1:]) },
+	"memory":        func(a []string) error { return memory.Run(a[1:]) },
+	"sessions":      runSessions,
+	"plans":         func(a []string) error { return plans.Run(a[1:]) },
+	"cost":          func(a []string) error { return cost.Run(a[1:]) },
+	"plugins":       func(a []string) error { return plugins.Run(a[1:]) },
+	"secrets":       func(a []string) error { return secrets.Run(a[1:]) },
+	"auth":          func(a []string) error { return auth.Run(a[1:]) },
+	"backup":        func(a []string) error { return backup.Run(version, a[1:]) },
+	"scan":          func(a []string) error { return scan.Run(a[1:]) },
+	"skills":        func(a []string) error { return skills.Run(a[1:]) },
+	"commands":      func(a []string) error { return slashcommands.Run(a[1:]) },
+	"policy":        func(a []string) error { return policy.Run(a[1:]) },
+	"models":        func(a []string) error { return models.Run(a[1:]) },
+	"notify":        func(a []string) error { return notify.Run(a[1:]) },
+	"completion":    func(a []string) error { return completion.Run(a[1:]) },
+	"uninstall":     func(a []string) error { return uninstall.Run(a[1:]) },
 }
 
 const helpText = `
@@ -58,42 +110,272 @@ Usage:
 
 Commands:
   setup                          First-time setup & API key provisioning
+    [--offline]                  Make no network calls; report what was skipped
+    [--claude-binary <path>]     Install Claude Code from a local binary
+    [--non-interactive]          Never prompt; use flags and defaults
+    [--config <setup.yaml>]      Read answers from a file (implies --non-interactive)
+    [--api-key-env <VAR>]        Read the API key from VAR instead of logging in
+    [--org-policy <url>]         Enforce a signed org policy (with --org-policy-key <file>)
+    [--tools <a,b|none>]         Optional tools to install (default: all)
+    [--mcp-servers <a,b|none>]   Platform MCP servers to register (default: all)
+    [--no-modify-rc]             Leave shell RC files unchanged
   attach <project-path>          Attach platform config to a project
     [--symlink]                  Use symlinks instead of copying assets
     [--force]                    Overwrite existing files
     [--no-enrich]                Skip AI-powered CLAUDE.md enrichment
+    [--profile <name>]           Use a template profile (minimal, backend, data-science)
+    [--list-profiles]            List available template profiles
+    [--monorepo]                 Add a CLAUDE.md to each workspace package
+    [--devcontainer]             Install claude-workspace and Claude CLI in .devcontainer/devcontainer.json
+    [--governance]               Add .gitattributes entries for platform files (asks before changing existing files)
+    [--owners <list>]            With --governance, assign .claude/** to these owners in CODEOWNERS
+    [--template <source>]        Use a git repo[@ref] or https tarball as the template
+    [--template-sha256 <sum>]    Require the tarball to match this SHA-256
+    [--verify-signature]         Require a valid signature on the git template commit
+    [--dry-run]                  Show what would be created, overwritten, merged, and skipped; change nothing
+    [--check]                    Report files that are stale, modified, or missing vs. the template
+    [--reconcile]                Update stale and missing files, keeping local edits
+    [--pin]                      Pin the project's assets to this release
+    [--upgrade-assets]           Reconcile a pinned project with this release and move the pin
+  detach <project-path>          Remove platform config from a project
+    [--force]                    Also remove locally modified files
+    [--keep-claude-md]           Keep .claude/CLAUDE.md
+    [--profile <name>]           Profile used at attach time
+    [--template <source>]        Remote template used at attach time
   enrich [project-path]          Re-generate .claude/CLAUDE.md with AI analysis
     [--scaffold-only]            Generate static scaffold only (skip AI enrichment)
+    [--static-deep]              Fill directories, files, and conventions without AI
+    [--section <name>]           Regenerate only key-directories, conventions, or
+                                 important-files, keeping the rest of CLAUDE.md
+    [--monorepo]                 Enrich each workspace package and list them at the root
+    [--agents] [--skills]        Propose project-specific agents/skills for review instead
+    [--stack]                    Update Tech Stack, Build, Test, and Lint lines that drifted
+    [--yes]                      Write every proposal or update without asking
+  assets [list|show|diff]        Inspect the template attach lays down
+    list [--kind <kind>] [--json]  List agents, skills, hooks, settings, and instructions
+    show <asset>                 Print an asset's content
+    diff [asset...]              Diff the template against a project's copy
+      [--project <dir>]          Project to compare (default: current directory)
+      [--version <version>]      Compare against another release's template instead
+  localize [project-path]        Set up .claude/settings.local.json by answering questions
+    [--dry-run]                  Show the resulting file without writing it
   sandbox create <path> <name>   Create a sandboxed branch worktree
-  sandbox list <path>            List sandboxes for a project
+    [--launch]                   Open it in tmux (or a subshell) with claude running
+    [--container]                Also run it in a docker/podman container with egress limited
+    [--engine docker|podman]     Container engine (default: docker if installed)
+    [--allow-host <host>]        Extra host the container may reach (repeatable)
+    [--env <NAME>]               Host variable to pass into the container (repeatable)
+  sandbox batch <path> --tasks <file>  Create many sandboxes in parallel from a task file
+    [--jobs N]                   Number of sandboxes to set up at once (default: 4)
+  sandbox list <path>            List sandboxes with branch, age, and dirty status
+  sandbox status <path> <name>   Show a sandbox's changes and upstream status
+  sandbox finish [<path>] [<name>]  Test, push, and open a PR for a sandbox's branch
+    [--test-cmd <cmd>]           Test command (default: detected from the project)
+    [--skip-tests]               Push without running tests
+    [--base <branch>]            PR target branch (default: the repository default)
+    [--draft]                    Open the PR as a draft
+    [--remove]                   Remove the sandbox and branch once the PR is merged
   sandbox remove <path> <name>   Remove a sandboxed branch worktree
+    [--delete-branch]            Also delete the sandbox's branch
+    [--force]                    Discard uncommitted changes / unmerged branch
+  sandbox prune <path>           Remove sandboxes whose branches are merged or gone
+    [--dry-run]                  Show what would be removed
   mcp add <name> [options]       Add an MCP server (local or remote)
   mcp remote <url>               Connect to a remote MCP server/gateway
   mcp list                       List all configured MCP servers
   mcp remove <name>              Remove an MCP server
+  mcp update <name> [options]    Change an MCP server's URL, headers, env, or keys
+  mcp registry <set|show|unset>  Manage the organization registry of approved MCP servers
+  mcp add --from-registry <name> Add an approved server from the registry
+  mcp serve <name> -- <command>  Run an MCP server under a supervisor (used as its launch command)
+    [--max-restarts <n>]         Give up after n restarts in a row (default: 5)
+  mcp ps                         List supervised MCP servers and their health
+  mcp logs <name>                Show a supervised MCP server's log
+    [--lines <n>]                Number of lines to show (default: 50)
+    [--follow]                   Keep printing new lines
+  mcp gateway                    Serve the configured stdio servers through one authenticated HTTP endpoint
+    [--listen <host:port>]       Address to listen on (default: 127.0.0.1:8900)
+    [--servers <a,b>]            Servers to expose (default: all stdio servers)
+    [--allow <pattern>]          Only expose matching <server>__<tool> tools (repeatable)
+    [--token-env <VAR>]          Env var with the accepted bearer tokens (default: MCP_GATEWAY_TOKENS)
+  mcp export                     Write the configured servers to a shareable bundle (no secrets)
+    [--output <file>]            Bundle file to write (default: stdout)
+    [--scope <scope>]            Only export servers from this scope
+  mcp import <bundle.json|url>   Add the servers in a bundle, prompting for their secrets
   upgrade [--self-only|--cli-only]  Upgrade claude-workspace and Claude Code CLI
-  doctor                         Check platform configuration health
-  agents [list]                  List configured agents
-  hooks [list]                   List configured hooks and hook scripts
+    [--channel <name>]           Follow the stable, beta, or nightly releases (saved)
+    [--rollback]                 Restore the binary replaced by the last upgrade
+    [--from-file <archive>]      Install a downloaded release archive without GitHub
+    [--skip-signature]           Install without checking the release signature
+  uninstall                      Remove claude-workspace from this machine (keeps your Claude Code settings and keys)
+    [--strip-rc]                 Also remove the lines it added to shell RC files
+    [--dry-run]                  Show what would be removed; change nothing
+    [--yes]                      Do not ask for confirmation
+  doctor [path...]               Check platform configuration health (project checks: cwd or each path)
+    [--project <path>]           Check this project (repeatable)
+    [--all]                      Check every attached project recorded in ~/.claude.json
+    [--json]                     Print machine-readable results (exit 1 on failures)
+    [--fix]                      Apply safe fixes for failed checks
+    [--dry-run]                  With --fix, show fixes without applying them
+    [--offline]                  Skip MCP server and npm registry reachability checks
+    [--timeout <duration>]       Most time all checks may take (default: 10s)
+  ci verify [path]               Check a repository's platform config for CI (exit 1 on errors)
+    [--format text|github]       Finding format (default: github under GitHub Actions)
+    [--strict]                   Also fail on warnings
+    [--max-claude-md <bytes>]    CLAUDE.md size limit (default: 40000)
+  lint-claudemd [path]           Check CLAUDE.md structure, size, and commands (exit 1 on errors)
+    [--global]                   Check ~/.claude/CLAUDE.md instead
+    [--fix]                      Fix whitespace, code-block wrapping, missing sections, and commands
+    [--format text|github]       Finding format (default: github under GitHub Actions)
+    [--strict]                   Also fail on warnings
+    [--max-bytes <bytes>]        CLAUDE.md size limit (default: 40000)
+  fleet <attach|upgrade|check|doctor>  Run a command across many repositories in parallel
+    [--repos <file>]             Repositories to use, one path per line
+    [--scan <dir>]               Use every git repository under a directory
+    [--max-parallel N]           Number of repositories processed at once (default: 4)
+    [-- <attach flags>]          Extra flags for each command, e.g. -- --profile backend
+  report                         Inventory this machine: version, projects, MCP servers, memory, cost, doctor
+    [--output <file>]            Write to a file; .json or .html picks the format
+    [--format text|json|html]    Output format (default: text, or from --output extension)
+    [--scan <dir>]               Directory to search for attached projects (repeatable; default: ~/code, ~/src, ...)
+  bugreport                      Collect a redacted report for filing an issue
+    [--output <file>]            Write to a file; .tar.gz or .tgz picks the tarball
+    [--format markdown|tar]      Gist-ready Markdown (default) or a tarball with whole logs
+    [--logs N]                   Recent command logs to include (default: 5)
+    [--no-doctor]                Skip the doctor checks
+  agents [list|show|validate]    List, inspect, and validate agents
+    list                           List agents and the effective set (default)
+    show <name>                    Show an agent's effective definition
+    validate [<name>...]           Check agent frontmatter, models, and tools
+  skills [list|install|remove]   List, install, and remove skills
+    list                           List skills, installed versions, and personal commands (default)
+    install <path-or-url>          Install a skill bundle (directory, .tar.gz, or .zip) into .claude/skills
+      [--sha256 <hex>]             Verify the archive checksum before installing
+      [--force]                    Replace an existing skill with the same name
+    remove <name>                  Remove an installed skill
+      [--force]                    Also remove skills not installed with 'skills install'
+  commands [list|add]            List and add project slash commands
+    list                           List slash commands and project tasks without one (default)
+    add <name>                     Add .claude/commands/<name>.md for the project task <name>
+      [--run <command>]            Run this command instead of a project task
+      [--description <text>]       Description shown in the / menu
+      [--careful]                  Only run when typed, and confirm first
+      [--force]                    Replace an existing command
+  hooks [list|enable|disable|add|run]  List, toggle, scaffold, and test hooks
+    list                           List hook scripts and configured hooks (default)
+    enable|disable <name>          Restore or remove a hook in settings.json
+      [--event <event>]            Only change the hook's entries for one event
+    add <name> --event <event>     Scaffold .claude/hooks/<name>.sh and register it
+      [--matcher <tools>]          Tool matcher for PreToolUse/PostToolUse (e.g. Bash)
+    run <name>                     Run a hook locally with sample event JSON
+      [--event <event>]            Event to simulate (default: first registered)
+      [--input <file|->]           Read event JSON from a file or stdin
+  hook-relay                     Forward hook events from stdin to an HTTP endpoint or SQLite
+    --url <url>                    POST each event as JSON
+    --sqlite <path>                Append each event to a SQLite database
+    --local                        Append each event to the local store read by events
+      [--token-env <VAR>]          Send $VAR as a bearer token
+      [--no-content]               Leave out tool input, output, and prompts
+    install                        Register the relay in settings.json (same flags)
+      [--events <a,b>]             Events to relay (default: PreToolUse,PostToolUse,Stop)
+      [--scope <scope>]            global, project (default), or local
+  events [list|query]            Query hook events recorded with hook-relay --local
+    list                           Sessions with recorded events (default)
+    query                          Recorded events, oldest first
+      [--project <dir>]            Only events in a project (. for the current directory)
+      [--since <24h|7d|date>]      Only events since a time
+      [--tool <name>]              Only calls of a tool (wildcards: mcp__*)
+      [--event <event>]            Only one hook event
+      [--session <id>]             Only one session
+      [--limit <n>]                Most recent n sessions (default: 20) or events (default: 100)
+      [--json]                     Print JSON
   statusline                     Configure Claude Code statusline (cost & context display)
     [--force]                    Overwrite existing statusLine configuration
-  sessions [list|show] [options]   Browse and review session prompts
+    [--segments <list>]          Segments to show: ccusage,model,cost,today,context,git,duration,reset
+    [--theme <name>]             Theme: default, minimal, or mono
+    [--cost-warn|--cost-critical <usd>]        Session cost thresholds for yellow/red
+    [--context-warn|--context-critical <pct>]  Context usage thresholds (default: 70/90)
+    preview [options]            Render sample lines without changing settings
+  sessions [list|show|export|redact|stats|audit|resume|browse] [options]  Browse, review, export, redact, audit, and resume sessions
     list                           List sessions for current project (default)
     list --all                     List sessions across all projects
     list --limit N                 Limit results (default: 20)
     show <session-id>              Show all user prompts from a session
+    export <session-id>            Export the full transcript, including tool calls
+      [--format md|json|html]      Output format (default: md, or from --output extension)
+      [--output path]              Write to a file instead of stdout
+    redact <session-id>            Write a copy with secrets replaced by [REDACTED:<rule>]
+      [--rules <file>]             Extra regex patterns to redact
+      [--format jsonl|md|json|html]  Output format (default: jsonl, or from --output extension)
+      [--output path]              Write to a file instead of stdout
+    stats                          Sessions per day, turns, tool calls, errors, retries, and edited files
+      [--all]                      Include all projects (default: current project)
+      [--days N]                   Sessions started in the last N days; 0 for all (default: 30)
+      [--top N]                    Length of the tool and file rankings (default: 10)
+      [--json]                     Print the stats as one JSON document
+    audit [<session-id>]           Chronological Bash commands, file writes, and MCP calls, flagging
+                                   deny-rule matches and sensitive paths (default: current project)
+      [--all]                      Audit all projects
+      [--since YYYY-MM-DD]         Only calls made on or after the date
+      [--flagged]                  Only flagged calls
+      [--format text|json|csv]     Output format (default: text, or from --output extension)
+      [--output path]              Write to a file instead of stdout
+    resume <session-id>            Resume a session with claude in its project directory
+    browse                         Interactive browser with preview, filter, export, resume, delete
+  plans [list|show|new|archive|link]  Scaffold, track, and archive plan files
+    list                           List active plans with status and step progress (default)
+      [--all]                      Include archived plans
+      [--status <status>]          Only plans with this status (draft, approved, in-progress, complete)
+    show <plan>                    Show a plan's metadata, linked sessions, and body
+    new <title>                    Scaffold plan-YYYY-MM-DD-<slug>.md with frontmatter
+      [--owner <name>]             Plan owner (default: git user.name)
+      [--status <status>]          Initial status (default: Draft)
+    archive <plan>...              Move Complete plans to the plans archive/ directory
+      [--completed]                Archive every Complete plan
+      [--force]                    Archive plans that are not Complete
+    link <plan> [session-id...]    Record the sessions that implemented a plan (default: latest)
   memory [subcommand] [options]  Inspect and manage memory layers
     (no args)                    Overview of all layers
+    configure [--provider mcp-memory-libsql|engram|none] [--db-path <file>] [--yes]
+      [--migrate]                Move existing memories to the new provider
+      [--dry-run]                Report what --migrate would transfer; change nothing
     show [--scope=user|project|local|auto|mcp|all]
     export [--output=path]       Export all layers to structured JSON
-    import <file> [--scope=...] [--confirm]
+      [--encrypt]                Encrypt with a passphrase (AES-256-GCM)
+      [--passphrase-env <var>]   Read the passphrase from an env var
+      [--recipient <key|file>]   Encrypt to age recipients instead (repeatable)
+    import <file> [--scope=...] [--merge] [--confirm]
+    diff <file> [--scope=...]    Compare an export against the current layers
+      [--passphrase-env <var>]   import, diff: passphrase of an encrypted export
+      [--identity <file>]        import, diff: age identity to decrypt with
+    prune [--older-than 90d] [--scope auto|mcp] [--confirm]
+    tokens [--budget <tokens>]   Estimate tokens of always-loaded CLAUDE.md and memory files
+    sync init --remote <url>     Clone a private repo to sync memory between machines
+    sync push [--force]          Upload memory layers (refuses if remote has unpulled changes)
+    sync pull [--force]          Merge remote memory layers; --force takes remote on conflicts
+    snapshot enable              Take snapshots on a schedule (launchd, systemd, or a SessionEnd hook)
+      [--interval daily] [--keep 14] [--via launchd|systemd|hook]
+    snapshot run [--if-due]      Take a snapshot now into ~/.claude-workspace/snapshots
+    snapshot list|disable        List snapshots, or remove the schedule
+    snapshot restore <id|latest> [--scope=...] [--merge] [--confirm]
   cost [subcommand] [options]    View Claude Code usage and costs (via ccusage)
     daily|weekly|monthly         Usage by time period (default: daily)
     session                      Usage by conversation session
     blocks                       Usage by 5-hour billing window
+      [--watch]                  Redraw the current block's burn rate and projection live
+      [--interval <seconds>]     Refresh interval for --watch (default: 10)
     [--breakdown]                Per-model cost breakdown
     [--since YYYYMMDD]           Filter from date
     [--json]                     JSON output
+    [--enforce]                  Exit 1 if a budget is exceeded
+    budget [show]                Show budgets and current spending
+    budget set [--monthly USD] [--per-session USD]
+    budget clear                 Remove all budgets
+    export [--format csv|json]   Export spend by project and model
+      [--since D] [--until D] [--output path]
+    report [--since D] [--until D]  Spend grouped by project and by model
+      [--input export.json]...   Combine team members' JSON exports
+    by-project [--since D] [--until D]  Spend by project directory, across sessions
   plugins [subcommand]           Manage Claude Code plugins
     (no args) / list             List installed plugins
     add <plugin[@marketplace]>   Install a plugin
@@ -115,16 +397,86 @@ Commands:
     claude-workspace plugins marketplace add /home/user/git/org/repo
     claude-workspace plugins marketplace remove claude-plugins-official
 
+  secrets [subcommand]           Manage MCP credentials in the OS credential store
+    (no args) / list             List stored secret names and the backend in use
+    set <NAME>                   Store a secret (masked input, or read from stdin)
+    rm <NAME>                    Remove a stored secret
+
+  auth rotate                    Replace the Anthropic API key, with validation and backups
+    [--from-env <VAR>]           Read the new key from an environment variable
+    [--no-validate]              Skip checking the key against the API
+    [--oauth]                    Sign in to Claude Code again instead
+  auth profiles                  List saved sign-ins (* marks the active one)
+    save <name>                  Save the current sign-in as a profile
+    rm <name>                    Delete a saved profile
+  auth use [<profile>]           Switch Claude Code to a profile (default: this project's pin)
+    [--pin | --unpin]            Pin this project to the profile, or remove the pin
+    [--discard]                  Replace a sign-in that is not saved in any profile
+
+  backup create                  Archive Claude config, memory, and known projects' .claude dirs
+    [--output <file>]            Where to write (default: ~/.claude-workspace/backups/)
+    [--exclude-secrets]          Leave out API keys and credential-like env values
+    [--no-memory]                Skip the memory export
+  backup restore <file>          Restore a backup, saving the current state first
+    [--dry-run]                  Show what would change
+  backup list                    List saved backups
+
+  scan [path]                    Scan a project for secrets and credentials (exit 1 on findings)
+    [--stdin-json]               Check a PreToolUse event on stdin; exit 2 blocks the write
+
   config [subcommand]            View and edit all Claude Code configuration
     (no args)                    Launch interactive TUI config viewer/editor
     view                         Non-interactive formatted output of all config
     get <key>                    Show a single key with all scope layers
     set <key> <value>            Set a config value
       [--scope user|project|local]  Which settings.json to write (default: user)
+    list                         Show claude-workspace's own preferences
+    get|set|unset workspace.<name>  Read or change a preference (templateSource, attachFlags, color, proxy, ...)
+
+  models [subcommand]            Manage model routing: main and subagent models, effort, auto-compaction
+    (no args) / show             Show the effective values, where each is set, and the matching preset
+    presets                      List presets (platform, balanced, cost-saver, max-quality)
+    use <preset>                 Apply a preset
+    set <name> <value>           Set one of main, subagent, effort, autocompact (validated)
+    reset                        Remove the model settings from a scope
+      [--scope global|project|local]  Which settings.json to edit (default: global)
+
+  policy [subcommand]            Manage permission allow/ask/deny rules across settings layers
+    (no args) / show             List the rules in each settings file
+      [--effective]              Show the merged rules Claude Code enforces, with their source
+    add-allow|add-ask|add-deny <rule>  Add a rule, e.g. 'Bash(kubectl delete *)'
+    remove <rule>                Remove a rule from every list in a scope
+      [--scope global|project|local]  Which settings.json to edit (default: project)
+    test '<Tool(argument)>'      Show whether a tool call is allowed, asked, or denied, and why
+    simulate <session-id>        Replay a session's tool calls through the rules and report the decisions
+      [--with <policy.yaml>]     Also apply a proposed policy file and list the calls it changes
+    apply --from <policy.yaml>   Merge an org policy file's rules into a scope
+    org [show|set|sync|unset]    Manage the signed org policy enforced in ~/.claude/settings.json
+      set <url> --key <file>     Verify, enforce, and save the org policy
+
+  notify [subcommand]            Send desktop, Slack, or webhook notifications when long operations finish
+    (no args) / list             List channels and the events they receive
+    add <name> --type desktop|slack|webhook  Add a channel
+      [--url <url|${NAME}>]      Webhook URL, or a variable/secret holding it
+      [--events <a,b>]           Only these events: enrich, fleet, sandbox-batch, upgrade,
+                                 cost-budget, doctor-regression (default: all)
+      [--force]                  Replace a channel with the same name
+    remove <name>                Remove a channel
+    test [<name>]                Send a test notification
+    min-duration <duration>      Only report operations that run at least this long (default: 30s)
+
+  completion <bash|zsh|fish>     Print a shell completion script
+    install [shell]              Load completion in new shells (default: your login shell)
 
 Options:
   --help, -h       Show this help message
   --version, -v    Show version
+  --ca-cert <file> Trust extra root CAs (PEM) for HTTPS, e.g. behind a TLS-inspecting proxy
+  --json           Print one JSON event per line (doctor, cost, report, sessions stats, events, and assets list print their own JSON)
+  --quiet          Print errors only
+  --no-color       Disable colored output (same as NO_COLOR=1)
+  --verbose        Also print debug logs (commands run, exit codes, durations) to stderr.
+                   Every command logs them to ~/.claude-workspace/logs.
 
 MCP Authentication:
   --api-key ENV_NAME     Securely prompt for API key (masked input)
@@ -133,26 +485,69 @@ MCP Authentication:
   --client-id <id>       OAuth client ID for pre-registered apps
   --client-secret        Prompt for OAuth client secret (masked input)
   --header 'Key: Value'  Add custom HTTP header (repeatable)
+  --no-secret-store      Keep --api-key values in ~/.claude.json instead of the OS credential store
 
 Examples:
   claude-workspace setup
   claude-workspace attach /path/to/my-project
+  claude-workspace attach /path/to/my-project --profile backend
+  claude-workspace assets diff --version 1.4.0
+  claude-workspace localize
+  claude-workspace detach /path/to/my-project --keep-claude-md
   claude-workspace sandbox create /path/to/my-project feature-auth
+  claude-workspace sandbox /path/to/my-project feature-api --launch
+  claude-workspace sandbox create /path/to/my-project spike --container --allow-host github.com
+  claude-workspace sandbox batch /path/to/my-project --tasks tasks.yaml
   claude-workspace sandbox list /path/to/my-project
+  claude-workspace sandbox finish /path/to/my-project feature-auth --draft
+  claude-workspace sandbox prune /path/to/my-project --dry-run
+  claude-workspace fleet check --scan ~/code
+  claude-workspace fleet upgrade --repos repos.txt --max-parallel 8
+  claude-workspace report --output report.html
+  claude-workspace bugreport --output bugreport.md
+  claude-workspace scan /path/to/my-project
+  claude-workspace commands add docs
+  claude-workspace models use cost-saver
+  claude-workspace auth rotate
+  claude-workspace auth use customer-x --pin
   claude-workspace mcp add postgres --scope user --api-key DATABASE_URL -- npx -y @bytebase/dbhub
   claude-workspace mcp add brave --scope user --api-key BRAVE_API_KEY -- npx -y @modelcontextprotocol/server-brave-search
   claude-workspace mcp remote https://mcp.sentry.dev/mcp --scope user --name sentry
   claude-workspace mcp remote https://mcp-gateway.company.com --scope user --bearer
   claude-workspace mcp remote https://mcp.example.com --scope user --header 'X-API-Key: mykey'
   claude-workspace mcp remove brave-search
+  claude-workspace mcp update brave-search --api-key BRAVE_API_KEY
+  claude-workspace mcp registry set https://platform.example.com/mcp-registry.json
+  claude-workspace mcp add --from-registry sentry
+  claude-workspace mcp update postgres --supervise
+  claude-workspace mcp logs postgres --follow
+  claude-workspace mcp gateway --listen :8900 --allow 'github__get_*' --allow 'postgres__*'
+  claude-workspace mcp export --output mcp-bundle.json
+  claude-workspace mcp import mcp-bundle.json
   claude-workspace statusline
   claude-workspace statusline --force
+  claude-workspace statusline --segments model,cost,context,git --theme minimal
+  claude-workspace statusline preview --context-warn 60 --theme mono
   claude-workspace sessions
   claude-workspace sessions list --all --limit 50
   claude-workspace sessions show 8a3f1b2c
+  claude-workspace sessions export 8a3f1b2c --output review.html
+  claude-workspace sessions redact 8a3f1b2c --output bug-report.md
+  claude-workspace sessions resume 8a3f1b2c
+  claude-workspace plans new "Add auth middleware"
+  claude-workspace plans archive --completed
   claude-workspace cost
   claude-workspace cost monthly --breakdown
   claude-workspace cost blocks --active
+  claude-workspace cost budget set --monthly 200 --per-session 5
+  claude-workspace cost export --format csv --since 20260101 --until 20260131
+  claude-workspace cost by-project --since 20260301
+  claude-workspace --json attach /path/to/my-project --no-enrich
+  claude-workspace ci verify . --strict
+  claude-workspace lint-claudemd --fix
+  claude-workspace config set workspace.attachFlags "--symlink --no-enrich"
+  claude-workspace completion install
+  eval "$(claude-workspace completion bash)"
 `
 
 func main() {
@@ -171,6 +566,13 @@ func main() {
 	}
 	platform.GlobalFS = globalSub
 
+	profilesSub, err := fs.Sub(PlatformFS, "_template/profiles")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing embedded template profiles: %v\n", err)
+		os.Exit(1)
+	}
+	platform.ProfilesFS = profilesSub
+
 	mcpConfigSub, err := fs.Sub(McpConfigFS, "docs/mcp-configs")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing embedded MCP configs: %v\n", err)
@@ -184,12 +586,24 @@ func main() {
 		os.Exit(1)
 	}
 	platform.MarketplaceRegistryFS = marketplaceRegistrySub
+	upgrade.PublicKey = releaseKey
+	platform.ApplyOverrides()
 	platform.InitColor()
+	platform.ApplyConfig()
 
-	args := os.Args[1:]
+	args, caCert, err := takeFlag(os.Args[1:], "--ca-cert")
+	if err == nil {
+		err = platform.ConfigureHTTP(caCert)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	args = setOutputMode(args)
+	args, verbose := takeBool(args, "--verbose")
 
 	if len(args) == 0 {
-		if platform.IsTTY() {
+		if platform.IsTTY() && !platform.JSONOutput() && !platform.QuietOutput() {
 			if err := tui.Run(version); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -216,97 +630,272 @@ func main() {
 		fmt.Print(helpText)
 		os.Exit(1)
 	}
+	if len(args) > 1 && (args[1] == "--help" || args[1] == "-h") && !ownHelp[command] {
+		if help := commandHelp(command); help != "" {
+			fmt.Print(help)
+			os.Exit(0)
+		}
+	}
 
-	if err := cmd(args); err != nil {
-		if errors.Is(err, upgrade.ErrUpdateAvailable) {
+	logPath, closeLog := startLog(command, args, verbose)
+	start := time.Now()
+	err = cmd(args)
+	platform.FlushOutput()
+	if err != nil {
+		platform.Logger().Error("command failed", "error", err.Error())
+	}
+	if event := longRunningEvent(args); event != "" {
+		notify.Finished(event, "claude-workspace "+strings.Join(args[:min(2, len(args))], " "), time.Since(start), err)
+	}
+	closeLog()
+	if errors.Is(err, cli.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		if errors.Is(err, scan.ErrBlocked) {
+			os.Exit(2)
+		}
+		if errors.Is(err, upgrade.ErrUpdateAvailable) || errors.Is(err, doctor.ErrUnhealthy) ||
+			errors.Is(err, cost.ErrBudgetExceeded) || errors.Is(err, ci.ErrFindings) {
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if platform.JSONOutput() {
+			platform.PrintErrorLine(platform.Stdout(), err.Error())
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		if logPath != "" {
+			fmt.Fprintf(os.Stderr, "Details were logged to %s\n", logPath)
+		}
 		os.Exit(1)
 	}
 }
 
-func runSetup(args []string) error {
-	return setup.Run(args[1:])
+// ownHelp lists commands that print their own help for "<command> --help";
+// for the others it is their part of helpText.
+var ownHelp = map[string]bool{
+	"attach": true, "assets": true, "auth": true, "backup": true, "completion": true,
+	"events": true, "hook-relay": true, "localize": true, "notify": true,
 }
 
-func runAttach(args []string) error {
-	var target string
-	if len(args) > 1 {
-		target = args[1]
+// commandHelp returns the lines of helpText that describe command, with its
+// examples, or "" if it has none.
+func commandHelp(command string) string {
+	var usage, examples []string
+	in, inExamples := false, false
+	for _, line := range strings.Split(helpText, "\n") {
+		switch {
+		case line == "Examples:":
+			inExamples = true
+		case inExamples:
+			if ex := strings.TrimSpace(line); ex == "claude-workspace "+command || strings.HasPrefix(ex, "claude-workspace "+command+" ") {
+				examples = append(examples, line)
+			}
+		case strings.HasPrefix(line, "    "):
+			if in {
+				usage = append(usage, line)
+			}
+		case strings.HasPrefix(line, "  "):
+			in = line[2:] == command || strings.HasPrefix(line[2:], command+" ")
+			if in {
+				usage = append(usage, line)
+			}
+		default:
+			in = false
+		}
+	}
+	if len(usage) == 0 {
+		return ""
+	}
+	help := "Usage:\n" + strings.Join(usage, "\n") + "\n"
+	if len(examples) > 0 {
+		help += "\nExamples:\n" + strings.Join(examples, "\n") + "\n"
 	}
-	return attach.Run(target, args)
+	return help
 }
 
-func runEnrich(args []string) error {
-	var target string
-	if len(args) > 1 && args[1][0] != '-' {
-		target = args[1]
+// unlogged lists commands that write no log file: completion runs on every
+// Tab press, scan and hook-relay run as hooks on every file write or tool
+// call, statusline renders the status line on every prompt refresh, and
+// uninstall deletes the logs directory.
+var unlogged = map[string]bool{"completion": true, "scan": true, "hook-relay": true, "statusline": true, "uninstall": true}
+
+// isMCPServe reports whether args run "mcp serve", which Claude Code launches
+// for every supervised server in every session; the supervisor keeps its own
+// per-server log instead.
+func isMCPServe(args []string) bool {
+	return len(args) > 1 && args[0] == "mcp" && args[1] == "serve"
+}
+
+// isStackHook reports whether args run "enrich --stack --stdin-json", the
+// hook that runs after every Write, Edit, and Bash call.
+func isStackHook(args []string) bool {
+	if args[0] != "enrich" {
+		return false
+	}
+	for _, a := range args[1:] {
+		if a == "--stdin-json" {
+			return true
+		}
 	}
-	return enrich.Run(target, args[1:])
+	return false
 }
 
-func runSandbox(args []string) error {
-	subcmd := ""
-	if len(args) > 1 {
-		subcmd = args[1]
+// startLog starts the debug log for command and records how it was invoked.
+// It returns the log file's path ("" if there is none) and a function that
+// closes it.
+func startLog(command string, args []string, verbose bool) (string, func()) {
+	if (unlogged[command] || isMCPServe(args) || isStackHook(args)) && !verbose {
+		return "", func() {}
 	}
+	path, closeLog, err := platform.StartLog(command, verbose)
+	if err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	platform.Logger().Debug("start", "version", version, "os", runtime.GOOS, "arch", runtime.GOARCH,
+		"args", strings.Join(platform.MaskArgs(args), " "))
+	return path, closeLog
+}
 
-	switch subcmd {
-	case "create":
-		var projectPath, branchName string
-		if len(args) > 2 {
-			projectPath = args[2]
+// longRunning maps commands, and subcommands as "command subcommand", that
+// report their completion through the notify channels to the event they send.
+var longRunning = map[string]string{
+	"enrich":        notify.EventEnrich,
+	"fleet":         notify.EventFleet,
+	"sandbox batch": notify.EventSandboxBatch,
+	"upgrade":       notify.EventUpgrade,
+}
+
+// longRunningEvent returns the notify event for the command in args, or "".
+func longRunningEvent(args []string) string {
+	if len(args) > 1 {
+		if event, ok := longRunning[args[0]+" "+args[1]]; ok {
+			return event
 		}
-		if len(args) > 3 {
-			branchName = args[3]
+	}
+	return longRunning[args[0]]
+}
+
+// nativeJSON lists commands, and subcommands as "command subcommand", whose
+// own --json flag prints a single JSON document; for them the global --json
+// is passed through unchanged.
+var nativeJSON = map[string]bool{"doctor": true, "cost": true, "report": true, "sessions stats": true, "events": true, "assets list": true}
+
+// setOutputMode applies the global --json, --quiet, and --no-color options
+// and returns args without them.
+func setOutputMode(args []string) []string {
+	args, noColor := takeBool(args, "--no-color")
+	args, quiet := takeBool(args, "--quiet")
+	args, jsonOut := takeBool(args, "--json")
+	if noColor {
+		// Also honored by the TUI and by child processes.
+		os.Setenv("NO_COLOR", "1")
+		platform.DisableColor()
+	}
+	switch {
+	case jsonOut && len(args) > 0 && (nativeJSON[args[0]] || (len(args) > 1 && nativeJSON[args[0]+" "+args[1]])):
+		args = append(args, "--json")
+	case jsonOut:
+		platform.SetOutputMode(platform.OutputJSON)
+	case quiet:
+		platform.SetOutputMode(platform.OutputQuiet)
+	}
+	return args
+}
+
+// takeFlag removes a global "--name value" option from args and returns its
+// value. Arguments after "--" belong to another program and are left alone.
+func takeFlag(args []string, name string) ([]string, string, error) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
 		}
-		return sandbox.Create(projectPath, branchName)
-	case "remove":
-		var projectPath, branchName string
-		if len(args) > 2 {
-			projectPath = args[2]
+		if arg != name {
+			continue
 		}
-		if len(args) > 3 {
-			branchName = args[3]
+		if i+1 >= len(args) {
+			return nil, "", fmt.Errorf("%s requires a value", name)
 		}
-		return sandbox.Remove(projectPath, branchName)
-	case "list":
-		var projectPath string
-		if len(args) > 2 {
-			projectPath = args[2]
+		rest := append(append([]string{}, args[:i]...), args[i+2:]...)
+		return rest, args[i+1], nil
+	}
+	return args, "", nil
+}
+
+// takeBool removes every global "--name" option from args and reports whether
+// it was present. Arguments after "--" are left alone.
+func takeBool(args []string, name string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for i, arg := range args {
+		if arg == "--" {
+			out = append(out, args[i:]...)
+			break
 		}
-		return sandbox.List(projectPath)
-	default:
-		// Backward compat: sandbox <path> <branch> defaults to create
-		var branchName string
-		if len(args) > 2 {
-			branchName = args[2]
+		if arg == name {
+			found = true
+			continue
 		}
-		return sandbox.Create(subcmd, branchName)
+		out = append(out, arg)
 	}
+	return out, found
+}
+
+func runSetup(args []string) error {
+	return setup.Run(args[1:])
+}
+
+func runAttach(args []string) error {
+	// Flags saved with "config set workspace.attachFlags" go first, so the
+	// value of a flag given on the command line wins.
+	saved := strings.Fields(platform.ConfigString(platform.ConfigAttachFlags))
+	return attach.Run(version, append(saved, args[1:]...))
+}
+
+func runDetach(args []string) error {
+	return detach.Run(args[1:])
+}
+
+func runEnrich(args []string) error {
+	return enrich.Run("", args[1:])
+}
+
+func runSandbox(args []string) error {
+	return sandbox.Run(args[1:])
 }
 
 func runMCP(args []string) error {
 	if len(args) < 2 {
-		return fmt.Errorf("usage: claude-workspace mcp <add|remote|remove|list>")
+		return fmt.Errorf("usage: claude-workspace mcp <add|remote|remove|update|list|registry|serve|ps|logs|gateway|export|import>")
 	}
 	subcmd := args[1]
 	switch subcmd {
 	case "add":
 		return mcp.Add(args[2:])
 	case "remote":
-		var url string
-		if len(args) > 2 {
-			url = args[2]
-		}
-		return mcp.Remote(url, args[3:])
+		return mcp.Remote("", args[2:])
 	case "list":
 		return mcp.List()
 	case "remove":
 		return mcp.Remove(args[2:])
+	case "update":
+		return mcp.Update(args[2:])
+	case "registry":
+		return mcp.Registry(args[2:])
+	case "serve":
+		return mcp.Serve(args[2:])
+	case "ps":
+		return mcp.PS(args[2:])
+	case "logs":
+		return mcp.Logs(args[2:])
+	case "gateway":
+		return mcp.Gateway(version, args[2:])
+	case "export":
+		return mcp.Export(args[2:])
+	case "import":
+		return mcp.Import(args[2:])
 	default:
-		return fmt.Errorf("unknown mcp subcommand: %s (available: add, remote, remove, list)", subcmd)
+		return fmt.Errorf("unknown mcp subcommand: %s (available: add, remote, remove, update, list, registry, serve, ps, logs, gateway, export, import)", subcmd)
 	}
 }
 
@@ -314,6 +903,22 @@ func runUpgrade(args []string) error {
 	return upgrade.Run(version, args[1:])
 }
 
+func runSessions(args []string) error {
+	if len(args) > 1 && args[1] == "audit" {
+		return audit.Run(args[2:])
+	}
+	if len(args) > 1 && args[1] == "browse" {
+		if !platform.IsTTY() {
+			return fmt.Errorf("sessions browse requires an interactive terminal")
+		}
+		if tui.IsAccessible() {
+			return sessions.Run([]string{"list", "--all"})
+		}
+		return tui.RunSessions(version)
+	}
+	return sessions.Run(args[1:])
+}
+
 func runConfig(args []string) error {
 	subArgs := args[1:]
 	// No subcommand and connected to a TTY: launch config TUI directly
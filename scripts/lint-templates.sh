@@ -128,7 +128,11 @@ vet "project .mcp.json" "MCP" \
 echo -e "\n${BOLD}Validating agent definitions...${NC}"
 
 AGENTS_YAML="$TMPDIR/agents.yaml"
-extract_frontmatter "$AGENTS_YAML" "Agents" "$TEMPLATE_DIR/project/.claude/agents/"*.md
+shopt -s nullglob
+extract_frontmatter "$AGENTS_YAML" "Agents" \
+    "$TEMPLATE_DIR/project/.claude/agents/"*.md \
+    "$TEMPLATE_DIR/profiles/"*/.claude/agents/*.md
+shopt -u nullglob
 
 if [[ -s "$AGENTS_YAML" ]]; then
     AGENT_COUNT=$(grep -c '^---$' "$AGENTS_YAML")